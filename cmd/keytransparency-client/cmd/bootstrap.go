@@ -0,0 +1,85 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/keytransparency/core/client/grpcc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var bootstrapYes bool
+
+// bootstrapCmd pins a domain after the user verifies its fingerprint.
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Verify and pin the domain's keys",
+	Long: `Fetch the domain's keys and current roots from the server, show their
+fingerprint, and pin them in --trust-dir once you confirm that the fingerprint
+matches the one published by the domain's operator. Later commands verify all
+responses against the pinned keys.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := viper.GetString("trust-dir")
+		if dir == "" {
+			return fmt.Errorf("--trust-dir must be set")
+		}
+		domainID := viper.GetString("domain")
+		timeout := viper.GetDuration("timeout")
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		cc, err := dial(ctx, viper.GetString("kt-url"), false)
+		if err != nil {
+			return fmt.Errorf("error connecting: %w", err)
+		}
+		defer cc.Close()
+		b, err := grpcc.StartBootstrap(ctx, pb.NewKeyTransparencyClient(cc), domainID)
+		if err != nil {
+			return fmt.Errorf("bootstrap failed: %w", err)
+		}
+
+		fmt.Printf("Fingerprint of domain %v:\n\n", domainID)
+		fmt.Printf("  %v\n  %v\n  (%v)\n\n", b.Fingerprint.Numeric, b.Fingerprint.Emoji, b.Fingerprint.Words)
+		if !bootstrapYes {
+			fmt.Print("Does this match the fingerprint published by the domain operator? [y/N] ")
+			answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("reading answer: %w", err)
+			}
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				return fmt.Errorf("fingerprint not confirmed; nothing was pinned")
+			}
+		}
+		if _, err := b.Confirm(ctx, grpcc.FileTrustStore{Dir: dir}, b.Fingerprint.Numeric); err != nil {
+			return err
+		}
+		fmt.Printf("Pinned domain %v in %v\n", domainID, dir)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(bootstrapCmd)
+	bootstrapCmd.Flags().BoolVar(&bootstrapYes, "yes", false, "Pin without asking for confirmation, e.g. after checking the fingerprint by other means")
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/google/keytransparency/core/client/grpcc"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var archiveFile string
+
+// exportCmd exports a verifiable archive of a user's proof history.
+var exportCmd = &cobra.Command{
+	Use:   "export [user email] [app]",
+	Short: "Export all proofs for this account to an archive",
+	Long: `Retrieve and verify every epoch's proof material for this account and
+write it to a portable archive that can be verified offline with verify-archive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("user email and application need to be provided")
+		}
+		userID := args[0]
+		appID := args[1]
+		timeout := viper.GetDuration("timeout")
+
+		c, err := GetClient(false)
+		if err != nil {
+			return fmt.Errorf("Error connecting: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if end == 0 {
+			// Get the current epoch.
+			_, smh, err := c.GetEntry(ctx, userID, appID)
+			if err != nil && err != grpcc.ErrPurged {
+				return fmt.Errorf("GetEntry failed: %w", err)
+			}
+			end = smh.MapRevision
+		}
+
+		archive, err := c.ExportHistory(ctx, userID, appID, start, end)
+		if err != nil {
+			return fmt.Errorf("ExportHistory failed: %w", err)
+		}
+		b, err := proto.Marshal(archive)
+		if err != nil {
+			return fmt.Errorf("proto.Marshal(): %w", err)
+		}
+		if err := ioutil.WriteFile(archiveFile, b, 0644); err != nil {
+			return fmt.Errorf("WriteFile(%v): %w", archiveFile, err)
+		}
+		fmt.Printf("Exported %v epochs to %v\n", len(archive.GetEpochs()), archiveFile)
+		return nil
+	},
+}
+
+// verifyArchiveCmd verifies an archive produced by exportCmd without
+// contacting the server.
+var verifyArchiveCmd = &cobra.Command{
+	Use:   "verify-archive [file]",
+	Short: "Verify an exported proof archive offline",
+	Long: `Verify an archive produced by export without contacting the server. The
+archive is verified under the keys of the domain pinned in --trust-dir, or of
+the config given by --vrf, --log-key and --map-key.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("archive file needs to be provided")
+		}
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("ReadFile(%v): %w", args[0], err)
+		}
+		var archive pb.UserProofArchive
+		if err := proto.Unmarshal(b, &archive); err != nil {
+			return fmt.Errorf("proto.Unmarshal(): %w", err)
+		}
+		ctx := context.Background()
+		config, err := offlineConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("Error reading config: %w", err)
+		}
+		root, err := grpcc.VerifyArchive(ctx, config, &archive)
+		if err != nil {
+			return fmt.Errorf("VerifyArchive failed: %w", err)
+		}
+		fmt.Printf("✓ Verified %v epochs for %v/%v up to log size %v\n",
+			len(archive.GetEpochs()), archive.GetAppId(), archive.GetUserId(), root.GetTreeSize())
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(verifyArchiveCmd)
+
+	exportCmd.PersistentFlags().Int64Var(&start, "start", 1, "Start epoch")
+	exportCmd.PersistentFlags().Int64Var(&end, "end", 0, "End epoch")
+	exportCmd.PersistentFlags().StringVar(&archiveFile, "out", "archive.pb", "Path to write the archive to")
+}
@@ -16,8 +16,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/google/keytransparency/core/client/grpcc"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,13 +41,17 @@ results are consistent.`,
 
 		c, err := GetClient(false)
 		if err != nil {
-			return fmt.Errorf("error connecting: %v", err)
+			return fmt.Errorf("error connecting: %w", err)
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 		profile, _, err := c.GetEntry(ctx, userID, appID)
+		if errors.Is(err, grpcc.ErrPurged) {
+			fmt.Printf("Profile for %v has been purged\n", userID)
+			return nil
+		}
 		if err != nil {
-			return fmt.Errorf("GetEntry failed: %v", err)
+			return fmt.Errorf("GetEntry failed: %w", err)
 		}
 		fmt.Printf("Profile for %v: %+v\n", userID, profile)
 		return nil
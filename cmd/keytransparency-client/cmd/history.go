@@ -22,6 +22,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/google/keytransparency/core/client/grpcc"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -48,15 +50,15 @@ and verify that the results are consistent.`,
 
 		c, err := GetClient(false)
 		if err != nil {
-			return fmt.Errorf("Error connecting: %v", err)
+			return fmt.Errorf("Error connecting: %w", err)
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 		if end == 0 {
 			// Get the current epoch.
 			_, smh, err := c.GetEntry(ctx, userID, appID)
-			if err != nil {
-				return fmt.Errorf("GetEntry failed: %v", err)
+			if err != nil && err != grpcc.ErrPurged {
+				return fmt.Errorf("GetEntry failed: %w", err)
 			}
 			if verbose {
 				fmt.Printf("Got current epoch: %v\n", smh.MapRevision)
@@ -66,7 +68,7 @@ and verify that the results are consistent.`,
 
 		profiles, err := c.ListHistory(ctx, userID, appID, start, end)
 		if err != nil {
-			return fmt.Errorf("ListHistory failed: %v", err)
+			return fmt.Errorf("ListHistory failed: %w", err)
 		}
 
 		// Sort map heads.
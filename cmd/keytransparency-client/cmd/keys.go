@@ -236,13 +236,13 @@ func readKeyStoreFile() error {
 	if _, err := os.Stat(keyStoreFile); err == nil {
 		data, err := ioutil.ReadFile(keyStoreFile)
 		if err != nil {
-			return fmt.Errorf("reading keystore file failed: %v", err)
+			return fmt.Errorf("reading keystore file failed: %w", err)
 		}
 		if err = keymaster.Unmarshal(data, store); err != nil {
-			return fmt.Errorf("keystore.Unmarshak() failed: %v", err)
+			return fmt.Errorf("keystore.Unmarshak() failed: %w", err)
 		}
 	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("error checking if keystore file exists: %v", err)
+		return fmt.Errorf("error checking if keystore file exists: %w", err)
 	}
 	return nil
 }
@@ -61,7 +61,7 @@ User email MUST match the OAuth account used to authorize the update.
 		}
 		profileData, err := base64.StdEncoding.DecodeString(data)
 		if err != nil {
-			return fmt.Errorf("hex.Decode(%v): %v", data, err)
+			return fmt.Errorf("hex.Decode(%v): %w", data, err)
 		}
 		userID := args[0]
 		appID := args[1]
@@ -70,7 +70,7 @@ User email MUST match the OAuth account used to authorize the update.
 		// Create client.
 		c, err := GetClient(true)
 		if err != nil {
-			return fmt.Errorf("error connecting: %v", err)
+			return fmt.Errorf("error connecting: %w", err)
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
@@ -81,16 +81,21 @@ User email MUST match the OAuth account used to authorize the update.
 		signers := store.Signers()
 		authorizedKeys, err := store.PublicKeys()
 		if err != nil {
-			return fmt.Errorf("store.PublicKeys() failed: %v", err)
+			return fmt.Errorf("store.PublicKeys() failed: %w", err)
 		}
 		if err != nil {
-			return fmt.Errorf("updateKeys() failed: %v", err)
+			return fmt.Errorf("updateKeys() failed: %w", err)
 		}
 		// TODO: fill signers and authorizedKeys.
-		if _, err := c.Update(ctx, userID, appID, profileData, signers, authorizedKeys); err != nil {
-			return fmt.Errorf("update failed: %v", err)
+		result, err := c.Update(ctx, userID, appID, profileData, signers, authorizedKeys)
+		if err != nil {
+			return fmt.Errorf("update failed: %w", err)
 		}
 		fmt.Printf("New key for %v: %x\n", userID, data)
+		if verbose {
+			fmt.Printf("Included in epoch %v after %v retries (%v, %v bytes)\n",
+				result.Revision, result.Retries, result.Duration, result.BytesDownloaded)
+		}
 		return nil
 	},
 }
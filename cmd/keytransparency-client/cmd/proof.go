@@ -0,0 +1,87 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/keytransparency/core/client/ktdebug"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var proofDOT bool
+
+// proofCmd prints the proofs the server returns for an entry.
+var proofCmd = &cobra.Command{
+	Use:   "proof [user email] [app]",
+	Short: "Print the proofs of an entry for debugging",
+	Long: `Print the proofs the key server returns for an entry in human
+readable form, followed by the result of verifying them. With --dot, the
+Merkle paths of the proofs are printed as DOT graphs instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return fmt.Errorf("user email and app name need to be provided")
+		}
+		userID := args[0]
+		appID := args[1]
+		timeout := viper.GetDuration("timeout")
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		cc, err := dial(ctx, viper.GetString("kt-url"), false)
+		if err != nil {
+			return fmt.Errorf("error connecting: %w", err)
+		}
+		defer cc.Close()
+		resp, err := pb.NewKeyTransparencyClient(cc).GetEntry(ctx, &pb.GetEntryRequest{
+			DomainId: viper.GetString("domain"),
+			UserId:   userID,
+			AppId:    appID,
+		})
+		if err != nil {
+			return fmt.Errorf("GetEntry failed: %w", err)
+		}
+
+		if proofDOT {
+			ktdebug.MapInclusionDOT(os.Stdout, resp.GetLeafProof(), resp.GetSmr().GetRootHash())
+			ktdebug.LogInclusionDOT(os.Stdout, resp.GetSmr().GetMapRevision(), resp.GetLogRoot().GetTreeSize(), resp.GetLogInclusion())
+			return nil
+		}
+		ktdebug.Entry(os.Stdout, resp, 0)
+
+		c, err := GetClient(false)
+		if err != nil {
+			return fmt.Errorf("error connecting: %w", err)
+		}
+		if _, _, err := c.GetEntry(ctx, userID, appID); err != nil {
+			fmt.Printf("Verification failed: %v\n", err)
+			return nil
+		}
+		fmt.Printf("Verification succeeded\n")
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(proofCmd)
+
+	proofCmd.Flags().BoolVar(&proofDOT, "dot", false, "Print the Merkle paths of the proofs as DOT graphs")
+}
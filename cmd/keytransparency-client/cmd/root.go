@@ -17,6 +17,7 @@ package cmd
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -82,6 +83,9 @@ func init() {
 	RootCmd.PersistentFlags().String("kt-url", "35.184.134.53:8080", "URL of Key Transparency server")
 	RootCmd.PersistentFlags().String("kt-cert", "genfiles/server.crt", "Path to public key for Key Transparency")
 	RootCmd.PersistentFlags().Bool("autoconfig", true, "Fetch config info from the server's /v1/domain/info")
+	RootCmd.PersistentFlags().String("config-key", "", "Path to public key PEM of the server's domain config key. If set, autoconfig only accepts configs signed with it")
+	RootCmd.PersistentFlags().String("trust-dir", "", "Directory of domains pinned with the bootstrap command. Pinned domains take precedence over autoconfig")
+	RootCmd.PersistentFlags().String("root-dir", "", "Directory to keep the trusted log root of each domain in, so that log consistency is checked across runs")
 	RootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS checks")
 
 	RootCmd.PersistentFlags().String("vrf", "genfiles/vrf-pubkey.pem", "path to vrf public key")
@@ -237,21 +241,106 @@ func dial(ctx context.Context, ktURL string, useClientSecret bool) (*grpc.Client
 }
 
 // GetClient connects to the server and returns a key transparency verification
-// client.
+// client. The client keeps its trusted log root in --root-dir, if set.
 func GetClient(useClientSecret bool) (*grpcc.Client, error) {
 	ctx := context.Background()
+	c, err := newClient(ctx, useClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if dir := viper.GetString("root-dir"); dir != "" {
+		if err := c.UseTrustedRootStore(ctx, grpcc.FileRootStore{Dir: dir}); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func newClient(ctx context.Context, useClientSecret bool) (*grpcc.Client, error) {
 	ktURL := viper.GetString("kt-url")
 	cc, err := dial(ctx, ktURL, useClientSecret)
 	if err != nil {
-		return nil, fmt.Errorf("Error Dialing: %v", err)
+		return nil, fmt.Errorf("Error Dialing: %w", err)
+	}
+
+	ktClient := pb.NewKeyTransparencyClient(cc)
+	if dir := viper.GetString("trust-dir"); dir != "" {
+		c, err := pinnedClient(ctx, grpcc.FileTrustStore{Dir: dir}, ktClient, useClientSecret)
+		if !errors.Is(err, grpcc.ErrNotPinned) {
+			return c, err
+		}
 	}
 
 	config, err := config(ctx, cc)
 	if err != nil {
-		return nil, fmt.Errorf("Error reading config: %v", err)
+		return nil, fmt.Errorf("Error reading config: %w", err)
+	}
+
+	// Configs read from disk are trusted as is.
+	if keyFile := viper.GetString("config-key"); keyFile != "" && viper.GetBool("autoconfig") {
+		configKey, err := pem.ReadPublicKeyFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open config public key %v: %w", keyFile, err)
+		}
+		return grpcc.NewFromConfig(ktClient, config, configKey)
+	}
+	return grpcc.NewFromConfig(ktClient, config)
+}
+
+// pinnedClient returns a client for the domain pinned in store. Migrations
+// announced for the domain are followed first, and the client connects to
+// the key server the domain migrated to.
+func pinnedClient(ctx context.Context, store grpcc.TrustStore, ktClient pb.KeyTransparencyClient, useClientSecret bool) (*grpcc.Client, error) {
+	anchor, err := store.Load(ctx, viper.GetString("domain"))
+	if err != nil {
+		return nil, err
 	}
+	addr := ""
+	for {
+		if anchor.GetAddress() != addr {
+			addr = anchor.GetAddress()
+			cc, err := dial(ctx, addr, useClientSecret)
+			if err != nil {
+				return nil, fmt.Errorf("Error Dialing: %w", err)
+			}
+			ktClient = pb.NewKeyTransparencyClient(cc)
+		}
+		next, err := grpcc.FollowMigration(ctx, ktClient, store, anchor)
+		if errors.Is(err, grpcc.ErrMigrationNotLogged) {
+			// The pinned keys stay valid until the migration is logged.
+			fmt.Printf("Migration of domain %v is not logged yet\n", anchor.GetDomain().GetDomainId())
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if next == anchor {
+			break
+		}
+		fmt.Printf("Followed migration of domain %v at revision %v\n",
+			next.GetDomain().GetDomainId(), next.GetStartRevision())
+		anchor = next
+	}
+	return grpcc.NewFromTrustStore(ctx, ktClient, store, anchor.GetDomain().GetDomainId())
+}
 
-	return grpcc.NewFromConfig(pb.NewKeyTransparencyClient(cc), config)
+// offlineConfig returns the domain config that offline verification trusts:
+// the domain pinned in --trust-dir, if any, and otherwise the config read from
+// disk. The domain recorded in the material being verified is never trusted.
+func offlineConfig(ctx context.Context) (*pb.Domain, error) {
+	if dir := viper.GetString("trust-dir"); dir != "" {
+		anchor, err := grpcc.FileTrustStore{Dir: dir}.Load(ctx, viper.GetString("domain"))
+		switch {
+		case err == nil:
+			if grpcc.DomainFingerprint(anchor.GetDomain()).Numeric != anchor.GetFingerprint() {
+				return nil, grpcc.ErrFingerprintMismatch
+			}
+			return anchor.GetDomain(), nil
+		case !errors.Is(err, grpcc.ErrNotPinned):
+			return nil, err
+		}
+	}
+	return readConfigFromDisk()
 }
 
 // config selects a source for and returns the client configuration.
@@ -277,31 +366,31 @@ func readConfigFromDisk() (*pb.Domain, error) {
 	// Log PubKey.
 	logPubKey, err := pem.ReadPublicKeyFile(logPEMFile)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to open log public key %v: %v", logPEMFile, err)
+		return nil, fmt.Errorf("Failed to open log public key %v: %w", logPEMFile, err)
 	}
 	logPubPB, err := der.ToPublicProto(logPubKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize log public key: %v", err)
+		return nil, fmt.Errorf("failed to serialize log public key: %w", err)
 	}
 
 	// VRF PubKey
 	vrfPubKey, err := pem.ReadPublicKeyFile(vrfPubFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read: %s. %v", vrfPubFile, err)
+		return nil, fmt.Errorf("failed to read: %s. %w", vrfPubFile, err)
 	}
 	vrfPubPB, err := der.ToPublicProto(vrfPubKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize vrf public key: %v", err)
+		return nil, fmt.Errorf("failed to serialize vrf public key: %w", err)
 	}
 
 	// MapPubKey.
 	mapPubKey, err := pem.ReadPublicKeyFile(mapPEMFile)
 	if err != nil {
-		return nil, fmt.Errorf("error reading map public key %v: %v", mapPEMFile, err)
+		return nil, fmt.Errorf("error reading map public key %v: %w", mapPEMFile, err)
 	}
 	mapPubPB, err := der.ToPublicProto(mapPubKey)
 	if err != nil {
-		return nil, fmt.Errorf("error seralizeing map public key: %v", err)
+		return nil, fmt.Errorf("error seralizeing map public key: %w", err)
 	}
 
 	return &pb.Domain{
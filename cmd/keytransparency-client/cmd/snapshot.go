@@ -0,0 +1,132 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/google/keytransparency/core/client/grpcc"
+	"github.com/google/keytransparency/core/client/snapshot"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var siteDir string
+
+// snapshotCmd generates a static site from a verified snapshot of entries.
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [app/user ...]",
+	Short: "Generate a static transparency snapshot site",
+	Long: `Retrieve and verify the latest entries of the given users and write a
+static site containing the entries, their proofs and the roots they were
+verified against. The snapshot in the site can be verified offline with
+verify-snapshot.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("at least one app/user needs to be provided")
+		}
+		users := make([]grpcc.SnapshotUser, 0, len(args))
+		for _, arg := range args {
+			parts := strings.SplitN(arg, "/", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("%q is not of the form app/user", arg)
+			}
+			users = append(users, grpcc.SnapshotUser{AppID: parts[0], UserID: parts[1]})
+		}
+		timeout := viper.GetDuration("timeout")
+
+		c, err := GetClient(false)
+		if err != nil {
+			return fmt.Errorf("Error connecting: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		s, err := c.ExportSnapshot(ctx, users)
+		if err != nil {
+			return fmt.Errorf("ExportSnapshot failed: %w", err)
+		}
+		if err := snapshot.WriteSite(siteDir, s); err != nil {
+			return fmt.Errorf("WriteSite failed: %w", err)
+		}
+		fmt.Printf("Wrote snapshot of %v entries at revision %v to %v\n",
+			len(s.GetEntries()), s.GetEpoch().GetSmr().GetMapRevision(), siteDir)
+		return nil
+	},
+}
+
+// verifySnapshotCmd verifies a snapshot produced by snapshotCmd without
+// contacting the server.
+var verifySnapshotCmd = &cobra.Command{
+	Use:   "verify-snapshot [file]",
+	Short: "Verify a transparency snapshot offline",
+	Long: `Verify a snapshot produced by snapshot without contacting the server. The
+snapshot is verified under the keys of the domain pinned in --trust-dir, or of
+the config given by --vrf, --log-key and --map-key. If --root-dir is set, the
+snapshot must be consistent with the log root saved there by the previous
+verification, and its own log root is saved in its place.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("snapshot file needs to be provided")
+		}
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("ReadFile(%v): %w", args[0], err)
+		}
+		var s pb.TransparencySnapshot
+		if err := proto.Unmarshal(b, &s); err != nil {
+			return fmt.Errorf("proto.Unmarshal(): %w", err)
+		}
+		ctx := context.Background()
+		config, err := offlineConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("Error reading config: %w", err)
+		}
+		var roots grpcc.TrustedRootStore
+		trusted := &trillian.SignedLogRoot{}
+		if dir := viper.GetString("root-dir"); dir != "" {
+			roots = grpcc.FileRootStore{Dir: dir}
+			if trusted, err = roots.LoadRoot(ctx, config.GetDomainId()); err != nil {
+				return fmt.Errorf("LoadRoot failed: %w", err)
+			}
+		}
+		root, err := grpcc.VerifySnapshot(ctx, config, trusted, &s)
+		if err != nil {
+			return fmt.Errorf("VerifySnapshot failed: %w", err)
+		}
+		if roots != nil {
+			if err := roots.SaveRoot(ctx, config.GetDomainId(), root); err != nil {
+				return fmt.Errorf("SaveRoot failed: %w", err)
+			}
+		}
+		fmt.Printf("✓ Verified %v entries of %v at revision %v\n",
+			len(s.GetEntries()), s.GetDomain().GetDomainId(), s.GetEpoch().GetSmr().GetMapRevision())
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(snapshotCmd)
+	RootCmd.AddCommand(verifySnapshotCmd)
+
+	snapshotCmd.PersistentFlags().StringVar(&siteDir, "site", "snapshot", "Directory to write the site to")
+}
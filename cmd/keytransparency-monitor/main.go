@@ -17,20 +17,24 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
+	"encoding/hex"
 	"flag"
+	"fmt"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/keytransparency/cmd/serverutil"
-	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/crypto/secrets"
 	"github.com/google/keytransparency/core/monitor"
+	"github.com/google/keytransparency/core/monitor/alert"
 	"github.com/google/keytransparency/core/monitorserver"
+	"github.com/google/keytransparency/impl/sql/engine"
+	"github.com/google/keytransparency/impl/sql/monitorstorage"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
-	"github.com/google/trillian/crypto"
-	"github.com/google/trillian/crypto/keys/pem"
-
 	"github.com/golang/glog"
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
@@ -39,8 +43,9 @@ import (
 
 	mopb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
-	_ "github.com/google/trillian/merkle/coniks"    // Register coniks
-	_ "github.com/google/trillian/merkle/objhasher" // Register objhasher
+	_ "github.com/google/keytransparency/impl/google/secretmanager" // Register gcpsm
+	_ "github.com/google/trillian/merkle/coniks"                    // Register coniks
+	_ "github.com/google/trillian/merkle/objhasher"                 // Register objhasher
 )
 
 var (
@@ -48,14 +53,32 @@ var (
 	keyFile  = flag.String("tls-key", "genfiles/server.key", "TLS private key file")
 	certFile = flag.String("tls-cert", "genfiles/server.pem", "TLS cert file")
 
-	signingKey         = flag.String("sign-key", "genfiles/monitor_sign-key.pem", "Path to private key PEM for SMH signing")
-	signingKeyPassword = flag.String("password", "towel", "Password of the private key PEM file for SMH signing")
+	signingKey         = flag.String("sign-key", "genfiles/monitor_sign-key.pem", "Path to private key PEM for SMH signing, or a secret provider spec such as env:NAME, vault://host/path#field, gcpsm:projects/p/secrets/s or pkcs11:///module.so?token=t&pin=p&pubkey=pub.pem")
+	signingKeyPassword = flag.String("password", "towel", "Password of the private key PEM for SMH signing")
+	signingKeyRefresh  = flag.Duration("sign-key-refresh", time.Minute, "Time between checks of the secret provider for a rotated signing key. 0 disables rotation")
 	ktURL              = flag.String("kt-url", "localhost:8080", "URL of key-server.")
 	insecure           = flag.Bool("insecure", false, "Skip TLS checks")
 	domainID           = flag.String("domainid", "", "KT Domain identifier to monitor")
+	serverDBPath       = flag.String("db", "test:zaphod@tcp(localhost:3306)/test", "Database connection string for the monitor's results and progress")
 
 	pollPeriod = flag.Duration("poll-period", time.Second*5, "Maximum time between polling the key-server. Ideally, this is equal to the min-period of paramerter of the keyserver.")
 
+	// Soak mode.
+	soak           = flag.Bool("soak", false, "Run with resource ceilings")
+	maxHeapMB      = flag.Uint64("max-heap-mb", 0, "Soak mode: hard ceiling on heap usage in MiB. 0 means no ceiling")
+	maxProcs       = flag.Int("max-procs", 0, "Soak mode: maximum number of CPUs to use. 0 means no limit")
+	maxConcurrency = flag.Int("max-concurrency", 4, "Soak mode: maximum number of proofs to verify in parallel")
+
+	// Alerting.
+	alertWebhook     = flag.String("alert-webhook", "", "URL to post alerts to as JSON")
+	alertSMTPAddr    = flag.String("alert-smtp-addr", "", "host:port of the SMTP server to mail alerts through")
+	alertEmailFrom   = flag.String("alert-email-from", "", "Sender address of alert mails")
+	alertEmailTo     = flag.String("alert-email-to", "", "Comma separated recipients of alert mails")
+	pagerDutyKey     = flag.String("pagerduty-routing-key", "", "PagerDuty integration key to trigger incidents for alerts with")
+	alertSeverity    = flag.String("alert-min-severity", "warning", "Least severe alerts to send: info, warning or critical")
+	alertDedupWindow = flag.Duration("alert-dedup-window", time.Hour, "Time during which repeats of an alert are suppressed")
+	watch            = flag.String("watch", "", "Comma separated name=index pairs, with hex encoded map indexes, of entries whose key changes raise alerts")
+
 	// TODO(ismail): expose prometheus metrics: a variable that tracks valid/invalid MHs
 	// metricsAddr = flag.String("metrics-addr", ":8081", "The ip:port to publish metrics on")
 )
@@ -77,22 +100,63 @@ func main() {
 	}
 
 	// Read signing key:
-	key, err := pem.ReadPrivateKeyFile(*signingKey, *signingKeyPassword)
+	provider, err := secrets.Open(*signingKey, *signingKeyPassword)
+	if err != nil {
+		glog.Exitf("Invalid signing key %v: %v", *signingKey, err)
+	}
+	signer, err := secrets.NewKeyring(ctx, provider)
 	if err != nil {
 		glog.Exitf("Could not create signer from %v: %v", *signingKey, err)
 	}
-	signer := crypto.NewSHA256Signer(key)
-	store := fake.NewMonitorStorage()
+	if *signingKeyRefresh > 0 {
+		go signer.Run(ctx, *signingKeyRefresh)
+	}
+	db, err := sql.Open(engine.DriverName, *serverDBPath)
+	if err != nil {
+		glog.Exitf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	store, err := monitorstorage.NewStorage(db, *domainID)
+	if err != nil {
+		glog.Exitf("Failed to create monitor storage: %v", err)
+	}
 
 	// Create monitoring background process.
 	mon, err := monitor.NewFromConfig(ktClient, config, signer, store)
 	if err != nil {
 		glog.Exitf("Failed to initialize monitor: %v", err)
 	}
-	go mon.ProcessLoop(ctx, *domainID, store.LatestEpoch(), *pollPeriod)
+	if routes, err := alertRoutes(); err != nil {
+		glog.Exitf("Invalid alerting flags: %v", err)
+	} else if len(routes) > 0 {
+		watched, err := watchedEntries(*watch)
+		if err != nil {
+			glog.Exitf("Invalid --watch: %v", err)
+		}
+		alerts := alert.NewDispatcher(*alertDedupWindow, routes...)
+		go alerts.Run(ctx)
+		mon.SetAlerts(alerts, watched...)
+	}
+	startEpoch, err := mon.Resume()
+	if err != nil {
+		glog.Exitf("Failed to resume monitor: %v", err)
+	}
+	glog.Infof("Resuming monitoring after epoch %v", startEpoch)
+	if *soak {
+		mon.SetSoakMode(monitor.SoakConfig{
+			MaxHeapBytes:   *maxHeapMB << 20,
+			MaxProcs:       *maxProcs,
+			MaxConcurrency: *maxConcurrency,
+		})
+	}
+	go func() {
+		if err := mon.ProcessLoop(ctx, *domainID, startEpoch, *pollPeriod); err != nil {
+			glog.Exitf("ProcessLoop: %v", err)
+		}
+	}()
 
 	// Monitor Server.
-	srv := monitorserver.New(store)
+	srv := monitorserver.New(store, signer)
 
 	// Create gRPC server.
 	creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
@@ -133,6 +197,53 @@ func main() {
 	}
 }
 
+// alertRoutes returns the alert sinks configured by flags.
+func alertRoutes() ([]alert.Route, error) {
+	minSeverity, err := alert.ParseSeverity(*alertSeverity)
+	if err != nil {
+		return nil, err
+	}
+	var sinks []alert.Sink
+	if *alertWebhook != "" {
+		sinks = append(sinks, &alert.Webhook{URL: *alertWebhook})
+	}
+	if *alertSMTPAddr != "" {
+		sinks = append(sinks, &alert.Email{
+			Addr: *alertSMTPAddr,
+			From: *alertEmailFrom,
+			To:   strings.Split(*alertEmailTo, ","),
+		})
+	}
+	if *pagerDutyKey != "" {
+		sinks = append(sinks, &alert.PagerDuty{RoutingKey: *pagerDutyKey, Source: *domainID})
+	}
+	routes := make([]alert.Route, 0, len(sinks))
+	for _, s := range sinks {
+		routes = append(routes, alert.Route{Sink: s, MinSeverity: minSeverity})
+	}
+	return routes, nil
+}
+
+// watchedEntries parses a comma separated list of name=index pairs.
+func watchedEntries(list string) ([]monitor.Watched, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var watched []monitor.Watched
+	for _, pair := range strings.Split(list, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q: want name=index", pair)
+		}
+		index, err := hex.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", pair, err)
+		}
+		watched = append(watched, monitor.Watched{Name: parts[0], Index: index})
+	}
+	return watched, nil
+}
+
 func dial(url string, insecure bool) (*grpc.ClientConn, error) {
 	tcreds, err := transportCreds(url, insecure)
 	if err != nil {
@@ -18,23 +18,41 @@ import (
 	"context"
 	"database/sql"
 	"flag"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/keytransparency/core/adminserver"
+	"github.com/google/keytransparency/core/crypto/secrets"
+	"github.com/google/keytransparency/core/endorsement"
+	"github.com/google/keytransparency/core/monitor/alert"
 	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/reconcile"
+	"github.com/google/keytransparency/core/runtimeconfig"
 	"github.com/google/keytransparency/core/sequencer"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/keytransparency/core/trillianpool"
+	"github.com/google/keytransparency/impl/google/kms"
+	"github.com/google/keytransparency/impl/sql/acl"
+	"github.com/google/keytransparency/impl/sql/appindex"
+	"github.com/google/keytransparency/impl/sql/audit"
 	"github.com/google/keytransparency/impl/sql/domain"
+	"github.com/google/keytransparency/impl/sql/election"
 	"github.com/google/keytransparency/impl/sql/engine"
 	"github.com/google/keytransparency/impl/sql/mutationstorage"
+	"github.com/google/keytransparency/impl/sql/purge"
 
 	"github.com/golang/glog"
-	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
 	"google.golang.org/grpc"
 
-	"github.com/google/trillian/crypto/keys/der"
-	"github.com/google/trillian/crypto/keyspb"
+	acldef "github.com/google/keytransparency/core/acl"
+	_ "github.com/google/keytransparency/impl/google/secretmanager" // Register gcpsm
+	sqlendorsement "github.com/google/keytransparency/impl/sql/endorsement"
+	tcrypto "github.com/google/trillian/crypto"
 	_ "github.com/google/trillian/merkle/objhasher" // Register objhasher
 )
 
@@ -45,6 +63,51 @@ var (
 	mapURL  = flag.String("map-url", "", "URL of Trillian Map Server")
 	logURL  = flag.String("log-url", "", "URL of Trillian Log Server for Signed Map Heads")
 	refresh = flag.Duration("domain-refresh", 5*time.Second, "Time to detect new domain")
+
+	configRefresh = flag.Duration("config-refresh", 5*time.Second, "How often WatchRuntimeConfig checks for configuration changes")
+
+	logBackends = flag.String("log-backends", "", "Comma separated name=address pairs of Trillian Log Servers that domains can anchor their Signed Map Heads in instead of log-url")
+
+	// Detection and remediation of stalled domains.
+	stallCheck       = flag.Duration("stall-check", time.Minute, "How often to check for domains without an epoch past their max interval. Zero disables the check")
+	stallGrace       = flag.Duration("stall-grace", time.Minute, "Time past a domain's max interval before it is considered stalled")
+	stallMaxRestarts = flag.Int("stall-max-restarts", 3, "Receiver restarts attempted before a stalled domain is escalated")
+
+	// Alerting of stalled domains. Stalls are always logged.
+	alertWebhook     = flag.String("alert-webhook", "", "URL to post stall alerts to as JSON")
+	alertSMTPAddr    = flag.String("alert-smtp-addr", "", "host:port of the SMTP server to mail stall alerts through")
+	alertEmailFrom   = flag.String("alert-email-from", "", "Sender address of alert mails")
+	alertEmailTo     = flag.String("alert-email-to", "", "Comma separated recipients of alert mails")
+	pagerDutyKey     = flag.String("pagerduty-routing-key", "", "PagerDuty integration key to trigger incidents for stall alerts with")
+	alertDedupWindow = flag.Duration("alert-dedup-window", time.Hour, "Time during which repeats of an alert are suppressed")
+
+	// Leader election between replicated sequencers.
+	leaderElection = flag.Bool("leader-election", false, "Only create the epochs of domains this sequencer leads, so that sequencers can be replicated")
+	leaseTTL       = flag.Duration("lease-ttl", 10*time.Minute, "Term of domain leadership leases. Must be longer than the max interval of every domain")
+	sequencerID    = flag.String("sequencer-id", "", "Unique name of this sequencer in leader election. Defaults to the host name and process ID")
+
+	deleteRetention = flag.Duration("delete-retention", 30*24*time.Hour, "Time after DeleteDomain during which a domain can be undeleted. Negative keeps deleted domains forever")
+
+	// Reconciliation of domain storage with Trillian.
+	reconcileMode     = flag.String("reconcile", reconcile.Degrade, "What to do with domains whose trees do not match domain storage: off, degrade (do not sequence them) or strict (refuse to start)")
+	reconcileInterval = flag.Duration("reconcile-interval", 10*time.Minute, "How often domains are checked against their trees after startup. Zero checks only at startup")
+
+	// Access control for the admin API.
+	adminACL  = flag.Bool("admin-acl", false, "Restrict admin callers to the domains they have been granted")
+	aclGrants = flag.String("acl-grants", "", "Comma separated identity=domain grants to add at startup. Domain * grants all domains")
+
+	// Keys for exporting and importing domains.
+	bundleKey         = flag.String("bundle-key", "", "Path to private key PEM, or secret provider spec, for signing exported domains. Empty disables ExportDomain")
+	bundleKeyPassword = flag.String("bundle-key-password", "", "Password of the bundle-key PEM")
+	bundleTrusted     = flag.String("bundle-trusted-keys", "", "Comma separated paths to public key PEMs of instances whose exported domains can be imported. Defaults to the public key of bundle-key")
+
+	// Keys of endorsers run by this sequencer.
+	endorserKeys        = flag.String("endorser-keys", "", "Comma separated paths to private key PEMs, or secret provider specs, of endorsers that co-sign map roots")
+	endorserKeyPassword = flag.String("endorser-key-password", "", "Password of the endorser-keys PEMs")
+
+	// Cloud KMS keys for new domains.
+	kmsVRFKey  = flag.String("kms-vrf-key", "", "Cloud KMS symmetric key that encrypts the VRF keys of new domains. Empty stores VRF keys unencrypted")
+	kmsKeyRing = flag.String("kms-key-ring", "", "Cloud KMS key ring in which the signing keys of new trees are created. Empty lets Trillian generate them")
 )
 
 func openDB() *sql.DB {
@@ -58,8 +121,159 @@ func openDB() *sql.DB {
 	return db
 }
 
+// alertRoutes returns the alert sinks configured by flags.
+func alertRoutes() []alert.Route {
+	var sinks []alert.Sink
+	if *alertWebhook != "" {
+		sinks = append(sinks, &alert.Webhook{URL: *alertWebhook})
+	}
+	if *alertSMTPAddr != "" {
+		sinks = append(sinks, &alert.Email{
+			Addr: *alertSMTPAddr,
+			From: *alertEmailFrom,
+			To:   strings.Split(*alertEmailTo, ","),
+		})
+	}
+	if *pagerDutyKey != "" {
+		sinks = append(sinks, &alert.PagerDuty{RoutingKey: *pagerDutyKey, Source: "keytransparency-sequencer"})
+	}
+	routes := make([]alert.Route, 0, len(sinks))
+	for _, s := range sinks {
+		routes = append(routes, alert.Route{Sink: s, MinSeverity: alert.Critical})
+	}
+	return routes
+}
+
+// grantAll adds the comma separated identity=domain grants in grants to policy.
+func grantAll(policy acldef.Storage, grants string) error {
+	if grants == "" {
+		return nil
+	}
+	for _, g := range strings.Split(grants, ",") {
+		parts := strings.SplitN(g, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid grant %q, want identity=domain", g)
+		}
+		if err := policy.Grant(context.Background(), parts[0], parts[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBundleKeys sets the keys that sign and verify domain bundles in opts.
+func loadBundleKeys(ctx context.Context, opts *adminserver.Options) error {
+	if *bundleKey != "" {
+		provider, err := secrets.Open(*bundleKey, *bundleKeyPassword)
+		if err != nil {
+			return fmt.Errorf("secrets.Open(%v): %w", *bundleKey, err)
+		}
+		key, err := provider.PrivateKey(ctx)
+		if err != nil {
+			return fmt.Errorf("PrivateKey(%v): %w", *bundleKey, err)
+		}
+		opts.BundleSigner = tcrypto.NewSHA256Signer(key)
+	}
+	if *bundleTrusted == "" {
+		return nil
+	}
+	for _, f := range strings.Split(*bundleTrusted, ",") {
+		pub, err := pem.ReadPublicKeyFile(f)
+		if err != nil {
+			return fmt.Errorf("ReadPublicKeyFile(%v): %w", f, err)
+		}
+		opts.BundleKeys = append(opts.BundleKeys, pub)
+	}
+	return nil
+}
+
+// loadEndorsers returns an endorser for each key in endorserKeys.
+func loadEndorsers(ctx context.Context) ([]endorsement.Endorser, error) {
+	if *endorserKeys == "" {
+		return nil, nil
+	}
+	var endorsers []endorsement.Endorser
+	for _, spec := range strings.Split(*endorserKeys, ",") {
+		provider, err := secrets.Open(spec, *endorserKeyPassword)
+		if err != nil {
+			return nil, fmt.Errorf("secrets.Open(%v): %w", spec, err)
+		}
+		key, err := provider.PrivateKey(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("PrivateKey(%v): %w", spec, err)
+		}
+		e, err := endorsement.NewSigner(tcrypto.NewSHA256Signer(key))
+		if err != nil {
+			return nil, err
+		}
+		endorsers = append(endorsers, e)
+	}
+	return endorsers, nil
+}
+
+// useKMS configures opts to generate the keys of new domains in Cloud KMS.
+func useKMS(ctx context.Context, opts *adminserver.Options) error {
+	if *kmsVRFKey == "" && *kmsKeyRing == "" {
+		return nil
+	}
+	client, err := kms.NewDefault(ctx)
+	if err != nil {
+		return err
+	}
+	kms.RegisterHandlers(client)
+	if *kmsVRFKey != "" {
+		opts.KeyGen = client.VRFKeyGen(*kmsVRFKey)
+	}
+	if *kmsKeyRing != "" {
+		opts.TreeKeyGen = client.TreeKeyGen(*kmsKeyRing)
+	}
+	return nil
+}
+
+// dialLogBackends connects to the Trillian instances of the comma separated
+// name=address pairs in addrs, which serve the SMH logs of domains that name
+// them.
+func dialLogBackends(addrs string) (smhlog.Backends, error) {
+	parsed, err := smhlog.ParseAddrs(addrs)
+	if err != nil {
+		return nil, err
+	}
+	backends := make(smhlog.Backends)
+	for name, addr := range parsed {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("grpc.Dial(%v): %v", addr, err)
+		}
+		backends[name] = &smhlog.Backend{
+			Log:   trillian.NewTrillianLogClient(conn),
+			Admin: trillian.NewTrillianAdminClient(conn),
+		}
+	}
+	return backends, nil
+}
+
+// reconcileDomains checks all domains against their trees with checker and
+// exits in strict mode if any of them is degraded.
+func reconcileDomains(ctx context.Context, checker *reconcile.Checker) {
+	result, err := checker.Reconcile(ctx)
+	if err != nil {
+		glog.Exitf("Failed to reconcile domains: %v", err)
+	}
+	n := reconcile.CountDegraded(result)
+	if n == 0 {
+		return
+	}
+	if *reconcileMode == reconcile.Strict {
+		glog.Exitf("%v domains do not match their trees in Trillian: %v", n, result)
+	}
+	glog.Warningf("Not sequencing %v degraded domains", n)
+}
+
 func main() {
 	flag.Parse()
+	if err := reconcile.ParseMode(*reconcileMode); err != nil {
+		glog.Exitf("Invalid --reconcile: %v", err)
+	}
 
 	// Connect to trillian log and map backends.
 	mconn, err := grpc.Dial(*mapURL, grpc.WithInsecure())
@@ -74,6 +288,15 @@ func main() {
 	tmap := trillian.NewTrillianMapClient(mconn)
 	logAdmin := trillian.NewTrillianAdminClient(lconn)
 	mapAdmin := trillian.NewTrillianAdminClient(mconn)
+	logs, err := dialLogBackends(*logBackends)
+	if err != nil {
+		glog.Exitf("Failed to connect to log backends: %v", err)
+	}
+	// Domains hosted on other Trillian clusters are dialed on first use.
+	pool := trillianpool.New(func(addr string) (*grpc.ClientConn, error) {
+		return grpc.Dial(addr, grpc.WithInsecure())
+	})
+	defer pool.Close()
 
 	// Database tables
 	sqldb := openDB()
@@ -87,24 +310,132 @@ func main() {
 	if err != nil {
 		glog.Exitf("Failed to create domain storage object: %v", err)
 	}
+	purgeStorage, err := purge.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create purge storage object: %v", err)
+	}
+	auditStorage, err := audit.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create audit storage object: %v", err)
+	}
+	apps, err := appindex.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create app index storage object: %v", err)
+	}
+	endorsements, err := sqlendorsement.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create endorsement storage object: %v", err)
+	}
+	endorsers, err := loadEndorsers(context.Background())
+	if err != nil {
+		glog.Exitf("Failed to load endorser keys: %v", err)
+	}
 	queue := mutator.MutationQueue(mutations)
 
 	// Create servers
-	signer := sequencer.New(tlog, tmap, entry.New(), domainStorage, mutations, queue)
-	keygen := func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
-		return der.NewProtoFromSpec(spec)
+	signer := sequencer.New(tlog, tmap, entry.NewRegistry(), domainStorage, mutations, queue, endorsers, endorsements, logs)
+	if *leaderElection {
+		id := *sequencerID
+		if id == "" {
+			host, err := os.Hostname()
+			if err != nil {
+				glog.Exitf("Failed to name sequencer: %v", err)
+			}
+			id = fmt.Sprintf("%v-%v", host, os.Getpid())
+		}
+		leases, err := election.New(sqldb, id, *leaseTTL)
+		if err != nil {
+			glog.Exitf("Failed to create election: %v", err)
+		}
+		signer.SetElection(leases)
+	}
+	adminOpts := adminserver.Options{
+		Log:       tlog,
+		Map:       tmap,
+		LogAdmin:  logAdmin,
+		MapAdmin:  mapAdmin,
+		Domains:   domainStorage,
+		Purged:    purgeStorage,
+		Audits:    auditStorage,
+		Epochs:    signer,
+		Intervals: signer,
+		Queue:     mutations,
+		Mutations: mutations,
+		Replayer:  signer,
+		Apps:      apps,
+
+		LogBackends:     logs,
+		TrillianPool:    pool,
+		DeleteRetention: *deleteRetention,
+	}
+	if err := loadBundleKeys(context.Background(), &adminOpts); err != nil {
+		glog.Exitf("Failed to load bundle keys: %v", err)
+	}
+	if err := useKMS(context.Background(), &adminOpts); err != nil {
+		glog.Exitf("Failed to use Cloud KMS: %v", err)
+	}
+	adminServer, err := adminserver.New(adminOpts)
+	if err != nil {
+		glog.Exitf("Failed to create admin server: %v", err)
+	}
+	if *reconcileMode != reconcile.Off {
+		reconcileDomains(context.Background(), adminServer.Reconciler())
+		signer.SkipDomains(adminServer.Reconciler().Degraded)
+	}
+	// Calls made through the REST gateway are audited and authorized as
+	// their REST caller.
+	gateway, err := adminserver.NewGateway()
+	if err != nil {
+		glog.Exitf("Failed to create REST gateway credentials: %v", err)
+	}
+	interceptors := []grpc.UnaryServerInterceptor{gateway.UnaryInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{gateway.StreamInterceptor()}
+	if *adminACL {
+		policy, err := acl.NewStorage(sqldb)
+		if err != nil {
+			glog.Exitf("Failed to create ACL storage object: %v", err)
+		}
+		if err := grantAll(policy, *aclGrants); err != nil {
+			glog.Exitf("Failed to add ACL grants: %v", err)
+		}
+		interceptors = append(interceptors, adminserver.ACLInterceptor(policy))
+		streamInterceptors = append(streamInterceptors, adminserver.ACLStreamInterceptor(policy))
 	}
-	adminServer := adminserver.New(tlog, tmap, logAdmin, mapAdmin, domainStorage, keygen)
 	glog.Infof("Signer starting")
 
 	// Run servers
 	cctx, cancel := context.WithCancel(context.Background())
+	if *reconcileMode != reconcile.Off && *reconcileInterval > 0 {
+		go func() {
+			if err := adminServer.Reconciler().Run(cctx, *reconcileInterval); err != nil {
+				glog.Errorf("Reconciler: %v", err)
+			}
+		}()
+	}
 	go func() {
 		if err := signer.ListenForNewDomains(cctx, *refresh); err != nil {
 			glog.Errorf("StartSequencingAll(): %v", err)
 		}
 	}()
-	run(adminServer)
+	if *stallCheck > 0 {
+		var alerter sequencer.Alerter
+		if routes := alertRoutes(); len(routes) > 0 {
+			alerter = sequencer.DispatcherAlerter(alert.NewDispatcher(*alertDedupWindow, routes...))
+		}
+		supervisor := sequencer.NewSupervisor(signer, sequencer.SupervisorOptions{
+			CheckInterval: *stallCheck,
+			Grace:         *stallGrace,
+			MaxRestarts:   *stallMaxRestarts,
+			Alerter:       alerter,
+			Pending:       mutations,
+		})
+		go func() {
+			if err := supervisor.Run(cctx); err != nil {
+				glog.Errorf("Supervisor: %v", err)
+			}
+		}()
+	}
+	run(adminServer, runtimeconfig.New(domainStorage, *configRefresh), gateway, interceptors, streamInterceptors)
 	cancel()
 
 	glog.Errorf("Signer exiting")
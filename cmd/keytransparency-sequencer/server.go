@@ -19,8 +19,10 @@ import (
 	"net/http"
 
 	"github.com/google/keytransparency/cmd/serverutil"
+	"github.com/google/keytransparency/core/adminserver"
 
 	"github.com/golang/glog"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -37,7 +39,7 @@ var (
 	certFile = flag.String("tls-cert", "genfiles/server.crt", "TLS cert file")
 )
 
-func run(svr pb.KeyTransparencyAdminServer) {
+func run(svr pb.KeyTransparencyAdminServer, config pb.KeyTransparencyConfigServer, gateway *adminserver.Gateway, interceptors []grpc.UnaryServerInterceptor, streamInterceptors []grpc.StreamServerInterceptor) {
 	// Wire up gRPC and HTTP servers.
 	creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
 	if err != nil {
@@ -45,14 +47,18 @@ func run(svr pb.KeyTransparencyAdminServer) {
 	}
 	grpcServer := grpc.NewServer(
 		grpc.Creds(creds),
-		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
+		grpc.StreamInterceptor(serverutil.ChainStreamInterceptors(
+			append([]grpc.StreamServerInterceptor{grpc_prometheus.StreamServerInterceptor}, streamInterceptors...)...)),
+		grpc.UnaryInterceptor(serverutil.ChainUnaryInterceptors(
+			append([]grpc.UnaryServerInterceptor{grpc_prometheus.UnaryServerInterceptor}, interceptors...)...)),
 	)
 	tcreds, err := credentials.NewClientTLSFromFile(*certFile, "")
 	if err != nil {
 		glog.Exitf("Failed opening cert file %v: %v", *certFile, err)
 	}
-	gwmux, err := serverutil.GrpcGatewayMux(*addr, tcreds,
+	// The gateway forwards the identity of REST callers to the admin server.
+	gwmux, err := serverutil.GrpcGatewayMuxWithOptions(*addr, tcreds,
+		[]runtime.ServeMuxOption{runtime.WithMetadata(gateway.Metadata)},
 		pb.RegisterKeyTransparencyAdminHandlerFromEndpoint)
 	if err != nil {
 		glog.Exitf("Failed setting up REST proxy: %v", err)
@@ -62,6 +68,7 @@ func run(svr pb.KeyTransparencyAdminServer) {
 	mux.Handle("/", gwmux)
 
 	pb.RegisterKeyTransparencyAdminServer(grpcServer, svr)
+	pb.RegisterKeyTransparencyConfigServer(grpcServer, config)
 	reflection.Register(grpcServer)
 	grpc_prometheus.Register(grpcServer)
 	grpc_prometheus.EnableHandlingTimeHistogram()
@@ -15,20 +15,33 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/google/keytransparency/cmd/serverutil"
 	"github.com/google/keytransparency/core/authentication"
+	"github.com/google/keytransparency/core/crypto/secrets"
 	"github.com/google/keytransparency/core/keyserver"
+	"github.com/google/keytransparency/core/mirror"
 	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/reconcile"
+	"github.com/google/keytransparency/core/smhlog"
 	"github.com/google/keytransparency/impl/authorization"
+	"github.com/google/keytransparency/impl/google/kms"
+	"github.com/google/keytransparency/impl/sql/appindex"
 	"github.com/google/keytransparency/impl/sql/domain"
+	"github.com/google/keytransparency/impl/sql/endorsement"
 	"github.com/google/keytransparency/impl/sql/engine"
+	"github.com/google/keytransparency/impl/sql/keychange"
 	"github.com/google/keytransparency/impl/sql/mutationstorage"
+	"github.com/google/keytransparency/impl/sql/purge"
+	"github.com/google/keytransparency/impl/sql/quota"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian"
@@ -38,7 +51,11 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	coredomain "github.com/google/keytransparency/core/domain"
+	corekeychange "github.com/google/keytransparency/core/keychange"
 	gauth "github.com/google/keytransparency/impl/google/authentication"
+	_ "github.com/google/keytransparency/impl/google/secretmanager" // Register gcpsm
+	tcrypto "github.com/google/trillian/crypto"
 	_ "github.com/google/trillian/crypto/keys/der/proto"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 )
@@ -53,6 +70,23 @@ var (
 
 	mapURL = flag.String("map-url", "", "URL of Trillian Map Server")
 	logURL = flag.String("log-url", "", "URL of Trillian Log Server for Signed Map Heads")
+
+	logBackends = flag.String("log-backends", "", "Comma separated name=address pairs of Trillian Log Servers that domains can anchor their Signed Map Heads in instead of log-url")
+
+	mirrorMapURL          = flag.String("mirror-map-url", "", "URL of a read-only mirror of the Trillian Map Server")
+	mirrorLogURL          = flag.String("mirror-log-url", "", "URL of a read-only mirror of the Trillian Log Server")
+	mirrorMaxLag          = flag.Int64("mirror-max-lag", 10, "Maximum number of epochs the mirror may be behind before reads go to the primary")
+	mirrorRefreshInterval = flag.Duration("mirror-refresh-interval", 10*time.Second, "How often the mirror's latest log roots are checked against the primary")
+
+	useKMS = flag.Bool("kms", false, "Decrypt VRF keys encrypted with Cloud KMS")
+
+	configKey         = flag.String("config-key", "", "Path to private key PEM, or secret provider spec, for signing domain configs returned by GetDomain. Empty disables signing")
+	configKeyPassword = flag.String("config-key-password", "", "Password of the config-key PEM")
+
+	webhookInterval = flag.Duration("keychange-webhook-interval", 0, "How often key change events are delivered to user webhooks. Zero disables webhooks")
+
+	reconcileMode     = flag.String("reconcile", reconcile.Degrade, "What to do with domains whose trees do not match domain storage: off, degrade (do not serve them) or strict (refuse to start)")
+	reconcileInterval = flag.Duration("reconcile-interval", 10*time.Minute, "How often domains are checked against their trees after startup. Zero checks only at startup")
 )
 
 func openDB() *sql.DB {
@@ -66,8 +100,65 @@ func openDB() *sql.DB {
 	return db
 }
 
+// dialLogBackends connects to the Trillian instances of the comma separated
+// name=address pairs in addrs, which serve the SMH logs of domains that name
+// them.
+func dialLogBackends(addrs string) (smhlog.Backends, error) {
+	parsed, err := smhlog.ParseAddrs(addrs)
+	if err != nil {
+		return nil, err
+	}
+	backends := make(smhlog.Backends)
+	for name, addr := range parsed {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("grpc.Dial(%v): %v", addr, err)
+		}
+		backends[name] = &smhlog.Backend{
+			Log:   trillian.NewTrillianLogClient(conn),
+			Admin: trillian.NewTrillianAdminClient(conn),
+		}
+	}
+	return backends, nil
+}
+
+// newReconciler returns a checker of the domains in domains against the trees
+// that this server reads them from, and checks them once. It exits in strict
+// mode if any domain is degraded.
+func newReconciler(ctx context.Context, domains coredomain.Storage, tmap trillian.TrillianMapClient, mapAdmin trillian.TrillianAdminClient, logs smhlog.Backends) *reconcile.Checker {
+	checker := reconcile.New(domains, func(d *coredomain.Domain) (*reconcile.Trees, error) {
+		backend, err := logs.Get(d.LogBackend)
+		if err != nil {
+			return nil, err
+		}
+		return &reconcile.Trees{Map: tmap, MapAdmin: mapAdmin, Log: backend}, nil
+	})
+	result, err := checker.Reconcile(ctx)
+	if err != nil {
+		glog.Exitf("Failed to reconcile domains: %v", err)
+	}
+	if n := reconcile.CountDegraded(result); n > 0 {
+		if *reconcileMode == reconcile.Strict {
+			glog.Exitf("%v domains do not match their trees in Trillian: %v", n, result)
+		}
+		glog.Warningf("Not serving %v degraded domains", n)
+	}
+	return checker
+}
+
 func main() {
 	flag.Parse()
+	if err := reconcile.ParseMode(*reconcileMode); err != nil {
+		glog.Exitf("Invalid --reconcile: %v", err)
+	}
+
+	if *useKMS {
+		client, err := kms.NewDefault(context.Background())
+		if err != nil {
+			glog.Exitf("Failed to create Cloud KMS client: %v", err)
+		}
+		kms.RegisterHandlers(client)
+	}
 
 	// Open Resources.
 	sqldb := openDB()
@@ -103,6 +194,29 @@ func main() {
 	if err != nil {
 		glog.Exitf("Failed to create mutations object: %v", err)
 	}
+	purged, err := purge.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create purge storage: %v", err)
+	}
+	apps, err := appindex.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create app index storage: %v", err)
+	}
+	quotas, err := quota.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create quota storage: %v", err)
+	}
+	endorsements, err := endorsement.NewStorage(sqldb)
+	if err != nil {
+		glog.Exitf("Failed to create endorsement storage: %v", err)
+	}
+	var webhooks corekeychange.Storage
+	if *webhookInterval > 0 {
+		webhooks, err = keychange.NewStorage(sqldb)
+		if err != nil {
+			glog.Exitf("Failed to create key change webhook storage: %v", err)
+		}
+	}
 
 	// Connect to log and map server.
 	tconn, err := grpc.Dial(*logURL, grpc.WithInsecure())
@@ -115,17 +229,92 @@ func main() {
 	}
 	tlog := trillian.NewTrillianLogClient(tconn)
 	tmap := trillian.NewTrillianMapClient(mconn)
+	if *mirrorLogURL != "" {
+		conn, err := grpc.Dial(*mirrorLogURL, grpc.WithInsecure())
+		if err != nil {
+			glog.Exitf("grpc.Dial(%v): %v", *mirrorLogURL, err)
+		}
+		mirrorLog := mirror.NewLogClient(tlog, trillian.NewTrillianLogClient(conn), *mirrorMaxLag)
+		go mirrorLog.Run(context.Background(), *mirrorRefreshInterval)
+		tlog = mirrorLog
+	}
+	if *mirrorMapURL != "" {
+		conn, err := grpc.Dial(*mirrorMapURL, grpc.WithInsecure())
+		if err != nil {
+			glog.Exitf("grpc.Dial(%v): %v", *mirrorMapURL, err)
+		}
+		tmap = mirror.NewMapClient(tmap, trillian.NewTrillianMapClient(conn))
+	}
 	logAdmin := trillian.NewTrillianAdminClient(tconn)
 	mapAdmin := trillian.NewTrillianAdminClient(mconn)
+	logs, err := dialLogBackends(*logBackends)
+	if err != nil {
+		glog.Exitf("Failed to connect to log backends: %v", err)
+	}
+
+	var configSigner *tcrypto.Signer
+	if *configKey != "" {
+		provider, err := secrets.Open(*configKey, *configKeyPassword)
+		if err != nil {
+			glog.Exitf("secrets.Open(%v): %v", *configKey, err)
+		}
+		key, err := provider.PrivateKey(context.Background())
+		if err != nil {
+			glog.Exitf("PrivateKey(%v): %v", *configKey, err)
+		}
+		configSigner = tcrypto.NewSHA256Signer(key)
+	}
 
 	// Create gRPC server.
 	queue := mutator.MutationQueue(mutations)
-	ksvr := keyserver.New(tlog, tmap, logAdmin, mapAdmin,
-		entry.New(), auth, authz, domains, queue, mutations)
+	ksvr, err := keyserver.New(keyserver.Options{
+		Log:          tlog,
+		Map:          tmap,
+		LogAdmin:     logAdmin,
+		MapAdmin:     mapAdmin,
+		LogBackends:  logs,
+		Mutator:      entry.NewRegistry(),
+		Auth:         auth,
+		Authz:        authz,
+		Domains:      domains,
+		Purged:       purged,
+		Apps:         apps,
+		Quotas:       quotas,
+		Queue:        queue,
+		Mutations:    mutations,
+		ConfigSigner: configSigner,
+		Webhooks:     webhooks,
+		Endorsements: endorsements,
+	})
+	if err != nil {
+		glog.Exitf("keyserver.New(): %v", err)
+	}
+	if *webhookInterval > 0 {
+		go func() {
+			client := keyserver.NewWebhookClient(time.Minute)
+			if err := ksvr.RunKeyChangeWebhooks(context.Background(), *webhookInterval, client); err != nil {
+				glog.Errorf("RunKeyChangeWebhooks(): %v", err)
+			}
+		}()
+	}
+	interceptors := []grpc.UnaryServerInterceptor{grpc_prometheus.UnaryServerInterceptor}
+	streamInterceptors := []grpc.StreamServerInterceptor{grpc_prometheus.StreamServerInterceptor}
+	if *reconcileMode != reconcile.Off {
+		checker := newReconciler(context.Background(), domains, tmap, mapAdmin, logs.WithDefault(tlog, logAdmin))
+		if *reconcileInterval > 0 {
+			go func() {
+				if err := checker.Run(context.Background(), *reconcileInterval); err != nil {
+					glog.Errorf("Reconciler: %v", err)
+				}
+			}()
+		}
+		interceptors = append(interceptors, checker.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, checker.StreamInterceptor())
+	}
 	grpcServer := grpc.NewServer(
 		grpc.Creds(creds),
-		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
+		grpc.StreamInterceptor(serverutil.ChainStreamInterceptors(streamInterceptors...)),
+		grpc.UnaryInterceptor(serverutil.ChainUnaryInterceptors(interceptors...)),
 	)
 	pb.RegisterKeyTransparencyServer(grpcServer, ksvr)
 	reflection.Register(grpcServer)
@@ -0,0 +1,75 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// keytransparency-sim projects epoch lag and storage growth for a domain.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/google/keytransparency/core/sim"
+
+	"github.com/golang/glog"
+)
+
+var (
+	rate          = flag.Float64("rate", 10, "Mean mutations per second")
+	users         = flag.Int64("users", 1000000, "Number of distinct users")
+	minInterval   = flag.Duration("min-interval", time.Second, "Domain MinInterval")
+	maxInterval   = flag.Duration("max-interval", time.Hour, "Domain MaxInterval")
+	batchSize     = flag.Int("batch-size", 0, "Maximum mutations per epoch. 0 uses the sequencer default")
+	epochLatency  = flag.Duration("epoch-latency", 200*time.Millisecond, "Fixed Trillian cost of an epoch")
+	leafLatency   = flag.Duration("leaf-latency", time.Millisecond, "Trillian cost per mutated map leaf")
+	logLatency    = flag.Duration("log-latency", time.Second, "Time for the log to integrate a map root")
+	mutationBytes = flag.Int64("mutation-bytes", 2048, "Storage used per mutation")
+	epochBytes    = flag.Int64("epoch-bytes", 1024, "Storage used per epoch")
+	duration      = flag.Duration("duration", 24*time.Hour, "Simulated time")
+	seed          = flag.Int64("seed", 1, "Random seed")
+)
+
+func main() {
+	flag.Parse()
+
+	r, err := sim.Run(sim.Config{
+		MutationsPerSecond: *rate,
+		Users:              *users,
+		MinInterval:        *minInterval,
+		MaxInterval:        *maxInterval,
+		BatchSize:          int32(*batchSize),
+		Latency: sim.Latency{
+			Epoch:          *epochLatency,
+			PerMutation:    *leafLatency,
+			LogIntegration: *logLatency,
+		},
+		MutationBytes: *mutationBytes,
+		EpochBytes:    *epochBytes,
+		Duration:      *duration,
+		Seed:          *seed,
+	})
+	if err != nil {
+		glog.Exitf("sim.Run(): %v", err)
+	}
+
+	fmt.Printf("Epochs:          %v (%v empty)\n", r.Epochs, r.EmptyEpochs)
+	fmt.Printf("Mutations:       %v (%v pending, max queue %v)\n", r.Mutations, r.Pending, r.MaxQueueDepth)
+	fmt.Printf("Epoch lag:       mean %v, p99 %v, max %v\n", r.MeanLag, r.P99Lag, r.MaxLag)
+	fmt.Printf("Utilization:     %.1f%%\n", 100*r.Utilization)
+	fmt.Printf("Storage:         %v bytes (%.0f bytes/day)\n", r.StorageBytes, r.StorageBytesPerDay)
+	fmt.Printf("GetEntry proofs: %v bytes\n", r.ProofBytes)
+	if r.Pending > 0 && r.Utilization > 0.99 {
+		fmt.Println("Warning: the sequencer cannot keep up with this load.")
+	}
+}
@@ -44,11 +44,17 @@ type RegisterServiceFromEndpoint func(context.Context, *runtime.ServeMux, string
 // GrpcGatewayMux registers multiple gRPC services with a gRPC ServeMux
 func GrpcGatewayMux(addr string, transportCreds credentials.TransportCredentials,
 	services ...RegisterServiceFromEndpoint) (*runtime.ServeMux, error) {
+	return GrpcGatewayMuxWithOptions(addr, transportCreds, nil, services...)
+}
+
+// GrpcGatewayMuxWithOptions is GrpcGatewayMux with options for the ServeMux.
+func GrpcGatewayMuxWithOptions(addr string, transportCreds credentials.TransportCredentials,
+	muxOpts []runtime.ServeMuxOption, services ...RegisterServiceFromEndpoint) (*runtime.ServeMux, error) {
 	ctx := context.Background()
 
 	dopts := []grpc.DialOption{grpc.WithTransportCredentials(transportCreds)}
 
-	gwmux := runtime.NewServeMux()
+	gwmux := runtime.NewServeMux(muxOpts...)
 	for _, s := range services {
 		if err := s(ctx, gwmux, addr, dopts); err != nil {
 			return nil, err
@@ -57,3 +63,33 @@ func GrpcGatewayMux(addr string, transportCreds credentials.TransportCredentials
 
 	return gwmux, nil
 }
+
+// ChainUnaryInterceptors returns an interceptor that calls interceptors in
+// order before calling the handler.
+func ChainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// ChainStreamInterceptors returns a stream interceptor that calls
+// interceptors in order before calling the handler.
+func ChainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return chained(srv, ss)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package acl scopes admin credentials to domains.
+package acl
+
+import "context"
+
+// AllDomains is a domain ID that grants access to every domain.
+const AllDomains = "*"
+
+// Storage records which domains each admin identity may operate on.
+type Storage interface {
+	// Domains returns the domain IDs identity may operate on.
+	Domains(ctx context.Context, identity string) ([]string, error)
+	// Grant allows identity to operate on domainID.
+	Grant(ctx context.Context, identity, domainID string) error
+	// Revoke removes a grant made by Grant.
+	Revoke(ctx context.Context, identity, domainID string) error
+}
+
+// Permitted returns true if domains, as returned by Storage.Domains,
+// include domainID.
+func Permitted(domains []string, domainID string) bool {
+	for _, d := range domains {
+		if d == domainID || d == AllDomains {
+			return true
+		}
+	}
+	return false
+}
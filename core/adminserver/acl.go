@@ -0,0 +1,131 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/google/keytransparency/core/acl"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// domainRequest is implemented by requests that operate on a single domain.
+type domainRequest interface {
+	GetDomainId() string
+}
+
+// ACLInterceptor returns a gRPC interceptor that restricts callers to the
+// domains they have been granted in policy. Callers are identified in the same
+// way as in the audit log, so calls made through the REST gateway are
+// attributed to their REST caller if the Gateway interceptors run first, and
+// to the gateway otherwise. RPCs on other domains fail with PermissionDenied,
+// and ListDomains only returns permitted domains. RPCs that do not name a
+// domain require a grant for acl.AllDomains.
+func ACLInterceptor(policy acl.Storage) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity := callerIdentity(ctx)
+		domains, err := policy.Domains(ctx, identity)
+		if err != nil {
+			glog.Errorf("acl.Domains(%v): %v", identity, err)
+			return nil, status.Errorf(codes.Internal, "Cannot read access policy")
+		}
+
+		switch r := req.(type) {
+		case *pb.ListDomainsRequest:
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return filterDomains(resp.(*pb.ListDomainsResponse), domains), nil
+		case domainRequest:
+			if !acl.Permitted(domains, r.GetDomainId()) {
+				glog.Warningf("ACL: %v denied %v on domain %v", identity, info.FullMethod, r.GetDomainId())
+				return nil, status.Errorf(codes.PermissionDenied, "%v may not operate on domain %v", identity, r.GetDomainId())
+			}
+		default:
+			if !acl.Permitted(domains, acl.AllDomains) {
+				glog.Warningf("ACL: %v denied %v", identity, info.FullMethod)
+				return nil, status.Errorf(codes.PermissionDenied, "%v may not call %v", identity, info.FullMethod)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// filterDomains returns the domains in resp that are permitted by domains.
+func filterDomains(resp *pb.ListDomainsResponse, domains []string) *pb.ListDomainsResponse {
+	ret := &pb.ListDomainsResponse{
+		Domains: make([]*pb.Domain, 0, len(resp.GetDomains())),
+	}
+	for _, d := range resp.GetDomains() {
+		if acl.Permitted(domains, d.GetDomainId()) {
+			ret.Domains = append(ret.Domains, d)
+		}
+	}
+	return ret
+}
+
+// ACLStreamInterceptor returns a gRPC stream interceptor that applies policy
+// to streaming RPCs. Callers only receive the DomainEvents and
+// RuntimeConfigEvents of domains they have been granted. Other streams
+// require a grant for acl.AllDomains.
+func ACLStreamInterceptor(policy acl.Storage) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		identity := callerIdentity(ctx)
+		domains, err := policy.Domains(ctx, identity)
+		if err != nil {
+			glog.Errorf("acl.Domains(%v): %v", identity, err)
+			return status.Errorf(codes.Internal, "Cannot read access policy")
+		}
+		if acl.Permitted(domains, acl.AllDomains) {
+			return handler(srv, ss)
+		}
+		return handler(srv, &aclStream{ServerStream: ss, identity: identity, method: info.FullMethod, domains: domains})
+	}
+}
+
+// aclStream filters the messages sent on a stream to those permitted by
+// domains.
+type aclStream struct {
+	grpc.ServerStream
+	identity string
+	method   string
+	domains  []string
+}
+
+// SendMsg sends m if it is a DomainEvent or RuntimeConfigEvent for a
+// permitted domain, and drops it if it is for another domain.
+func (s *aclStream) SendMsg(m interface{}) error {
+	var domainID string
+	switch e := m.(type) {
+	case *pb.DomainEvent:
+		domainID = e.GetDomain().GetDomainId()
+	case *pb.RuntimeConfigEvent:
+		domainID = e.GetConfig().GetDomainId()
+	default:
+		glog.Warningf("ACL: %v denied %v", s.identity, s.method)
+		return status.Errorf(codes.PermissionDenied, "%v may not call %v", s.identity, s.method)
+	}
+	if !acl.Permitted(s.domains, domainID) {
+		return nil
+	}
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/google/keytransparency/core/acl"
+	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// withIdentity returns a context for a caller with a verified client
+// certificate for identity.
+func withIdentity(ctx context.Context, identity string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: identity}}
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestACLInterceptor(t *testing.T) {
+	ctx := context.Background()
+	policy := fake.NewACLStorage()
+	for _, g := range []struct{ identity, domainID string }{
+		{"alice", "domain1"},
+		{"root", acl.AllDomains},
+	} {
+		if err := policy.Grant(ctx, g.identity, g.domainID); err != nil {
+			t.Fatalf("Grant(): %v", err)
+		}
+	}
+	interceptor := ACLInterceptor(policy)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, ok := req.(*pb.ListDomainsRequest); ok {
+			return &pb.ListDomainsResponse{Domains: []*pb.Domain{
+				{DomainId: "domain1"},
+				{DomainId: "domain2"},
+			}}, nil
+		}
+		return &pb.Domain{}, nil
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		identity string
+		req      interface{}
+		want     codes.Code
+		wantList int
+	}{
+		{desc: "permitted", identity: "alice", req: &pb.GetDomainRequest{DomainId: "domain1"}, want: codes.OK},
+		{desc: "other domain", identity: "alice", req: &pb.DeleteDomainRequest{DomainId: "domain2"}, want: codes.PermissionDenied},
+		{desc: "create other", identity: "alice", req: &pb.CreateDomainRequest{DomainId: "domain3"}, want: codes.PermissionDenied},
+		{desc: "unknown caller", identity: "mallory", req: &pb.GetDomainRequest{DomainId: "domain1"}, want: codes.PermissionDenied},
+		{desc: "all domains", identity: "root", req: &pb.CreateDomainRequest{DomainId: "domain3"}, want: codes.OK},
+		{desc: "no domain", identity: "alice", req: &pb.GetServerVersionRequest{}, want: codes.PermissionDenied},
+		{desc: "no domain root", identity: "root", req: &pb.GetServerVersionRequest{}, want: codes.OK},
+		{desc: "list filtered", identity: "alice", req: &pb.ListDomainsRequest{}, want: codes.OK, wantList: 1},
+		{desc: "list all", identity: "root", req: &pb.ListDomainsRequest{}, want: codes.OK, wantList: 2},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := interceptor(withIdentity(ctx, tc.identity), tc.req, info, handler)
+			if got := status.Code(err); got != tc.want {
+				t.Fatalf("interceptor(): %v, want %v", err, tc.want)
+			}
+			if list, ok := resp.(*pb.ListDomainsResponse); ok {
+				if got := len(list.GetDomains()); got != tc.wantList {
+					t.Errorf("ListDomains(): %v domains, want %v", got, tc.wantList)
+				}
+			}
+		})
+	}
+}
+
+// recordingStream records the messages sent on a stream.
+type recordingStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []interface{}
+}
+
+func (r *recordingStream) Context() context.Context { return r.ctx }
+
+func (r *recordingStream) SendMsg(m interface{}) error {
+	r.sent = append(r.sent, m)
+	return nil
+}
+
+func TestACLStreamInterceptor(t *testing.T) {
+	ctx := context.Background()
+	policy := fake.NewACLStorage()
+	for _, g := range []struct{ identity, domainID string }{
+		{"alice", "domain1"},
+		{"root", acl.AllDomains},
+	} {
+		if err := policy.Grant(ctx, g.identity, g.domainID); err != nil {
+			t.Fatalf("Grant(): %v", err)
+		}
+	}
+	interceptor := ACLStreamInterceptor(policy)
+	info := &grpc.StreamServerInfo{FullMethod: "/test", IsServerStream: true}
+
+	for _, tc := range []struct {
+		desc     string
+		identity string
+		msgs     []interface{}
+		want     codes.Code
+		wantSent int
+	}{
+		{desc: "filtered", identity: "alice", msgs: []interface{}{
+			&pb.DomainEvent{Domain: &pb.Domain{DomainId: "domain1"}},
+			&pb.DomainEvent{Domain: &pb.Domain{DomainId: "domain2"}},
+		}, want: codes.OK, wantSent: 1},
+		{desc: "all domains", identity: "root", msgs: []interface{}{
+			&pb.DomainEvent{Domain: &pb.Domain{DomainId: "domain1"}},
+			&pb.DomainEvent{Domain: &pb.Domain{DomainId: "domain2"}},
+		}, want: codes.OK, wantSent: 2},
+		{desc: "runtime config", identity: "alice", msgs: []interface{}{
+			&pb.RuntimeConfigEvent{Config: &pb.RuntimeConfig{DomainId: "domain1"}},
+			&pb.RuntimeConfigEvent{Config: &pb.RuntimeConfig{DomainId: "domain2"}},
+		}, want: codes.OK, wantSent: 1},
+		{desc: "other stream", identity: "alice", msgs: []interface{}{&pb.Domain{}}, want: codes.PermissionDenied},
+		{desc: "other stream root", identity: "root", msgs: []interface{}{&pb.Domain{}}, want: codes.OK, wantSent: 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ss := &recordingStream{ctx: withIdentity(ctx, tc.identity)}
+			err := interceptor(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+				for _, m := range tc.msgs {
+					if err := stream.SendMsg(m); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if got := status.Code(err); got != tc.want {
+				t.Fatalf("interceptor(): %v, want %v", err, tc.want)
+			}
+			if got := len(ss.sent); got != tc.wantSent {
+				t.Errorf("interceptor() sent %v messages, want %v", got, tc.wantSent)
+			}
+		})
+	}
+}
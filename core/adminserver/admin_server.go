@@ -16,25 +16,48 @@
 package adminserver
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto"
+	"errors"
 	"fmt"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/duration"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
-	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/appindex"
+	"github.com/google/keytransparency/core/audit"
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/crypto/vrf/ed25519"
+	"github.com/google/keytransparency/core/crypto/vrf/factory"
 	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/endorsement"
+	"github.com/google/keytransparency/core/migration"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/purge"
+	"github.com/google/keytransparency/core/reconcile"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/keytransparency/core/trillianpool"
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/crypto/sigpb"
+	"google.golang.org/genproto/protobuf/field_mask"
 
 	google_protobuf "github.com/golang/protobuf/ptypes/empty"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 	tpb "github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
 )
 
 var (
@@ -83,6 +106,51 @@ var (
 	}
 )
 
+// defaultVRFOverlap is how long the previous VRF key remains valid after
+// RotateDomainVRF when the request does not specify an overlap.
+const defaultVRFOverlap = 24 * time.Hour
+
+// defaultTreeKeyOverlap is how long the previous signing keys of the trees
+// remain valid after RotateTreeKeys when the request does not specify an
+// overlap.
+const defaultTreeKeyOverlap = 24 * time.Hour
+
+// defaultDeleteRetention is how long a deleted domain can be undeleted when
+// Options.DeleteRetention is not set.
+const defaultDeleteRetention = 30 * 24 * time.Hour
+
+// deleteTreesTimeout bounds the cleanup of the trees of a failed
+// CreateDomain call.
+const deleteTreesTimeout = time.Minute
+
+// EpochForcer creates epochs on demand.
+type EpochForcer interface {
+	// ForceEpoch creates a new epoch for domainID containing the queued
+	// mutations. It returns domain.ErrFrozen if the domain is frozen.
+	ForceEpoch(ctx context.Context, domainID string) error
+}
+
+// IntervalUpdater applies changed epoch intervals to running domains.
+type IntervalUpdater interface {
+	// UpdateIntervals makes the sequencing of domainID use the epoch
+	// intervals currently in storage.
+	UpdateIntervals(ctx context.Context, domainID string) error
+}
+
+// QueueInspector reports the mutations that are waiting to be sequenced.
+type QueueInspector interface {
+	// PendingMutations returns the number of queued mutations for domainID
+	// and the time the oldest of them was queued.
+	PendingMutations(ctx context.Context, domainID string) (int64, time.Time, error)
+}
+
+// MapReplayer replays the mutations of domains into other maps.
+type MapReplayer interface {
+	// ReplayRevision applies the mutations stored for revision of domainID
+	// to the map mapID of tmap and returns the new map root.
+	ReplayRevision(ctx context.Context, domainID string, tmap tpb.TrillianMapClient, mapID, revision int64) (*tpb.SignedMapRoot, error)
+}
+
 // Server implements pb.KeyTransparencyAdminServer
 type Server struct {
 	tlog     tpb.TrillianLogClient
@@ -90,29 +158,191 @@ type Server struct {
 	logAdmin tpb.TrillianAdminClient
 	mapAdmin tpb.TrillianAdminClient
 	domains  domain.Storage
+	purged   purge.Storage
+	audits   audit.Storage
 	keygen   keys.ProtoGenerator
+	treeGen  keys.ProtoGenerator
+	// bundleSigner and bundleKeys sign and verify domain bundles.
+	bundleSigner *tcrypto.Signer
+	bundleKeys   []crypto.PublicKey
+	// epochs creates epochs for ForceEpoch.
+	epochs EpochForcer
+	// intervals applies the intervals set by SetDomainIntervals.
+	intervals IntervalUpdater
+	// queue reports pending mutations for GetDomainStatus.
+	queue QueueInspector
+	// mutations reads applied mutations for EvaluateKeyPolicy.
+	mutations mutator.MutationStorage
+	// replayer replays mutations for RebuildDomainMap.
+	replayer MapReplayer
+	// apps reports which apps have entries for AddAppVRF.
+	apps appindex.Storage
+	// retention is how long deleted domains can be undeleted. Negative
+	// means forever.
+	retention time.Duration
+	// watchInterval is how often WatchDomains polls for changes.
+	watchInterval time.Duration
+	// trees caches tree metadata for fetchDomain.
+	trees *treeCache
+	// logs are the backends that domains can anchor map roots in instead of
+	// tlog.
+	logs smhlog.Backends
+	// pool connects to the Trillian clusters of domains that are not hosted
+	// on tlog and tmap.
+	pool *trillianpool.Pool
+	// reconciler checks domains against their trees for GetReconciliation.
+	reconciler *reconcile.Checker
+}
+
+// Options configures a Server.
+type Options struct {
+	// Log and Map serve the trees of existing domains.
+	Log tpb.TrillianLogClient
+	Map tpb.TrillianMapClient
+	// LogAdmin and MapAdmin create and manage the trees of domains.
+	LogAdmin tpb.TrillianAdminClient
+	MapAdmin tpb.TrillianAdminClient
+	// LogBackends are alternate services that domains can anchor map roots
+	// in instead of Log. Log and LogAdmin are the default backend.
+	LogBackends smhlog.Backends
+	// TrillianPool connects to the Trillian clusters of domains whose trees
+	// are not hosted on Log and Map. Such domains cannot be created or
+	// managed if it is nil.
+	TrillianPool *trillianpool.Pool
+	// Domains stores the configuration of domains.
+	Domains domain.Storage
+	// Purged records which committed profile data has been purged.
+	Purged purge.Storage
+	// Audits records every mutating admin RPC.
+	Audits audit.Storage
+	// KeyGen generates new VRF keys. Defaults to generating keys in-process.
+	KeyGen keys.ProtoGenerator
+	// TreeKeyGen generates the signing keys of new trees. If it is nil,
+	// Trillian generates them from the key specification.
+	TreeKeyGen keys.ProtoGenerator
+	// BundleSigner signs the bundles returned by ExportDomain. ExportDomain
+	// is disabled if it is nil.
+	BundleSigner *tcrypto.Signer
+	// BundleKeys verify the bundles accepted by ImportDomain. Defaults to the
+	// public key of BundleSigner. ImportDomain is disabled if there are none.
+	BundleKeys []crypto.PublicKey
+	// Epochs creates epochs for ForceEpoch. ForceEpoch is disabled if it is
+	// nil.
+	Epochs EpochForcer
+	// Intervals applies the intervals set by SetDomainIntervals to the
+	// running sequencer. If it is nil, new intervals take effect when the
+	// domain's receiver is next started.
+	Intervals IntervalUpdater
+	// Queue reports pending mutations in GetDomainStatus. Pending mutations
+	// are not reported if it is nil.
+	Queue QueueInspector
+	// Mutations reads the mutations applied to domains. EvaluateKeyPolicy is
+	// disabled if it is nil.
+	Mutations mutator.MutationStorage
+	// Replayer replays the mutations of domains for RebuildDomainMap.
+	// RebuildDomainMap is disabled if it is nil.
+	Replayer MapReplayer
+	// Apps records the apps that users have entries for. AddAppVRF refuses
+	// apps that have entries, and is disabled if Apps is nil.
+	Apps appindex.Storage
+	// DeleteRetention is how long a deleted domain can be undeleted. After
+	// it the domain is eligible for garbage collection. Defaults to 30 days.
+	// Negative keeps deleted domains forever.
+	DeleteRetention time.Duration
+	// WatchInterval is how often WatchDomains checks storage for changes to
+	// domains. Defaults to 5 seconds.
+	WatchInterval time.Duration
+	// TreeCacheTTL is how long the Trillian metadata of a tree is reused by
+	// GetDomain and ListDomains. Changes made through this server are seen
+	// immediately; changes made directly in Trillian are seen once the
+	// metadata expires. Defaults to 30 seconds. Negative disables caching.
+	TreeCacheTTL time.Duration
+}
+
+// validate returns an error if a required dependency is missing and fills in
+// defaults for the optional ones.
+func (o *Options) validate() error {
+	switch {
+	case o.Log == nil:
+		return errors.New("adminserver: missing Log client")
+	case o.Map == nil:
+		return errors.New("adminserver: missing Map client")
+	case o.LogAdmin == nil:
+		return errors.New("adminserver: missing LogAdmin client")
+	case o.MapAdmin == nil:
+		return errors.New("adminserver: missing MapAdmin client")
+	case o.Domains == nil:
+		return errors.New("adminserver: missing Domains storage")
+	case o.Purged == nil:
+		return errors.New("adminserver: missing Purged storage")
+	case o.Audits == nil:
+		return errors.New("adminserver: missing Audits storage")
+	}
+	if o.KeyGen == nil {
+		o.KeyGen = localKeyGen
+	}
+	if o.DeleteRetention == 0 {
+		o.DeleteRetention = defaultDeleteRetention
+	}
+	if o.WatchInterval == 0 {
+		o.WatchInterval = defaultWatchInterval
+	}
+	if o.TreeCacheTTL == 0 {
+		o.TreeCacheTTL = defaultTreeCacheTTL
+	}
+	if len(o.BundleKeys) == 0 && o.BundleSigner != nil {
+		o.BundleKeys = []crypto.PublicKey{o.BundleSigner.Public()}
+	}
+	return nil
+}
+
+// localKeyGen generates private keys in-process.
+func localKeyGen(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+	return der.NewProtoFromSpec(spec)
 }
 
 // New returns a KeyTransparencyAdmin implementation.
-func New(
-	tlog tpb.TrillianLogClient,
-	tmap tpb.TrillianMapClient,
-	logAdmin, mapAdmin tpb.TrillianAdminClient,
-	domains domain.Storage,
-	keygen keys.ProtoGenerator,
-) *Server {
-	return &Server{
-		tlog:     tlog,
-		tmap:     tmap,
-		logAdmin: logAdmin,
-		mapAdmin: mapAdmin,
-		domains:  domains,
-		keygen:   keygen,
-	}
-}
-
-// ListDomains produces a list of the configured domains
+func New(opts Options) (*Server, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	s := &Server{
+		tlog:     opts.Log,
+		tmap:     opts.Map,
+		logAdmin: opts.LogAdmin,
+		mapAdmin: opts.MapAdmin,
+		domains:  opts.Domains,
+		purged:   opts.Purged,
+		audits:   opts.Audits,
+		keygen:   opts.KeyGen,
+		treeGen:  opts.TreeKeyGen,
+
+		bundleSigner: opts.BundleSigner,
+		bundleKeys:   opts.BundleKeys,
+		epochs:       opts.Epochs,
+		intervals:    opts.Intervals,
+		queue:        opts.Queue,
+		mutations:    opts.Mutations,
+		replayer:     opts.Replayer,
+		apps:         opts.Apps,
+		retention:    opts.DeleteRetention,
+
+		watchInterval: opts.WatchInterval,
+		trees:         newTreeCache(opts.TreeCacheTTL),
+		logs:          opts.LogBackends,
+		pool:          opts.TrillianPool,
+	}
+	s.reconciler = reconcile.New(s.domains, s.domainTrees)
+	return s, nil
+}
+
+// ListDomains produces a list of the configured domains that match the
+// label selector of the request.
 func (s *Server) ListDomains(ctx context.Context, in *pb.ListDomainsRequest) (*pb.ListDomainsResponse, error) {
+	selector, err := domain.ParseSelector(in.GetLabelSelector())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "label_selector: %v", err)
+	}
 	domains, err := s.domains.List(ctx, in.GetShowDeleted())
 	if err != nil {
 		return nil, err
@@ -120,6 +350,9 @@ func (s *Server) ListDomains(ctx context.Context, in *pb.ListDomainsRequest) (*p
 
 	resp := make([]*pb.Domain, 0, len(domains))
 	for _, d := range domains {
+		if !selector.Matches(d.Labels) {
+			continue
+		}
 		info, err := s.fetchDomain(ctx, d)
 		if err != nil {
 			return nil, err
@@ -133,25 +366,168 @@ func (s *Server) ListDomains(ctx context.Context, in *pb.ListDomainsRequest) (*p
 }
 
 // fetchDomain converts an adminstorage.Domain object into a pb.Domain object
-// by fetching the relevant info from Trillian.
+// by fetching the relevant info from Trillian, or from the tree cache.
 func (s *Server) fetchDomain(ctx context.Context, d *domain.Domain) (*pb.Domain, error) {
-	logTree, err := s.logAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: d.LogID})
+	logTree, err := s.logTree(ctx, d)
 	if err != nil {
 		return nil, err
 	}
-	mapTree, err := s.mapAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: d.MapID})
+	_, mapAdmin, err := s.mapClients(d.MapAddress)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.Domain{
-		DomainId:    d.DomainID,
-		Log:         logTree,
-		Map:         mapTree,
-		Vrf:         d.VRF,
-		MinInterval: ptypes.DurationProto(d.MinInterval),
-		MaxInterval: ptypes.DurationProto(d.MaxInterval),
-		Deleted:     d.Deleted,
-	}, nil
+	mapTree, err := s.trees.get(ctx, mapAdmin, d.MapAddress, d.MapID)
+	if err != nil {
+		return nil, err
+	}
+	info := &pb.Domain{
+		DomainId:      d.DomainID,
+		Log:           logTree,
+		Map:           mapTree,
+		Vrf:           d.VRF,
+		MinInterval:   ptypes.DurationProto(d.MinInterval),
+		MaxInterval:   ptypes.DurationProto(d.MaxInterval),
+		Deleted:       d.Deleted,
+		AppVrfs:       appVRFs(d),
+		KeyPolicy:     d.KeyPolicy,
+		Frozen:        d.Frozen,
+		AppListing:    d.AppListing,
+		MutationQuota: d.MutationQuota,
+		VrfAlgorithm:  d.VRFAlgorithm(),
+		TreesDeleted:  d.TreesDeleted,
+		Labels:        d.Labels,
+		LogBackend:    d.LogBackend,
+		Migration:     d.Migration,
+
+		EndorsementPolicy: d.EndorsementPolicy,
+		LogKeyRotation:    d.LogKeyRotation,
+		MapKeyRotation:    d.MapKeyRotation,
+		TrillianBackend:   trillianBackend(d),
+		KeyPolicyHistory:  d.KeyPolicyHistory,
+	}
+	if d.Deleted && !d.DeleteTime.IsZero() {
+		deleteTime, err := ptypes.TimestampProto(d.DeleteTime)
+		if err != nil {
+			return nil, err
+		}
+		info.DeleteTime = deleteTime
+		if s.retention > 0 {
+			hardDeleteTime, err := ptypes.TimestampProto(d.HardDeleteTime(s.retention))
+			if err != nil {
+				return nil, err
+			}
+			info.HardDeleteTime = hardDeleteTime
+		}
+	}
+	// Only publish the previous VRF during its overlap window.
+	if p := d.PrevVRF; p != nil && time.Now().Before(p.Expiry) {
+		expiry, err := ptypes.TimestampProto(p.Expiry)
+		if err != nil {
+			return nil, err
+		}
+		info.PreviousVrf = p.VRF
+		info.PreviousVrfExpiry = expiry
+	}
+	return info, nil
+}
+
+// trillianBackend returns the addresses of the Trillian services of d, or nil
+// if d is hosted on the server's Trillian services.
+func trillianBackend(d *domain.Domain) *pb.TrillianBackend {
+	if d.LogAddress == "" && d.MapAddress == "" {
+		return nil
+	}
+	return &pb.TrillianBackend{LogAddress: d.LogAddress, MapAddress: d.MapAddress}
+}
+
+// mapClients returns the clients of the Trillian map service at addr. An
+// empty addr is the server's map service.
+func (s *Server) mapClients(addr string) (tpb.TrillianMapClient, tpb.TrillianAdminClient, error) {
+	if addr == "" {
+		return s.tmap, s.mapAdmin, nil
+	}
+	if s.pool == nil {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "Trillian map %v is not reachable: no Trillian pool configured", addr)
+	}
+	tmap, err := s.pool.Map(addr)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "Trillian map %v: %v", addr, err)
+	}
+	admin, err := s.pool.Admin(addr)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "Trillian map %v: %v", addr, err)
+	}
+	return tmap, admin, nil
+}
+
+// logClients returns the clients of the Trillian log service at addr. An
+// empty addr is the server's log service.
+func (s *Server) logClients(addr string) (tpb.TrillianLogClient, tpb.TrillianAdminClient, error) {
+	if addr == "" {
+		return s.tlog, s.logAdmin, nil
+	}
+	if s.pool == nil {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "Trillian log %v is not reachable: no Trillian pool configured", addr)
+	}
+	tlog, err := s.pool.Log(addr)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "Trillian log %v: %v", addr, err)
+	}
+	admin, err := s.pool.Admin(addr)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "Trillian log %v: %v", addr, err)
+	}
+	return tlog, admin, nil
+}
+
+// logBackend returns the SMH log backend called name. The default backend is
+// the Trillian log service at addr, which must be empty for other backends.
+func (s *Server) logBackend(name, addr string) (*smhlog.Backend, error) {
+	if name == smhlog.Default {
+		tlog, admin, err := s.logClients(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &smhlog.Backend{Log: tlog, Admin: admin}, nil
+	}
+	if addr != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "log_address cannot be combined with log_backend %q", name)
+	}
+	backend, err := s.logs.Get(name)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "log_backend: %v", err)
+	}
+	return backend, nil
+}
+
+// logTree returns the log tree of d. The trees of the default backend are
+// cached.
+func (s *Server) logTree(ctx context.Context, d *domain.Domain) (*tpb.Tree, error) {
+	backend, err := s.logBackend(d.LogBackend, d.LogAddress)
+	if err != nil {
+		return nil, err
+	}
+	if d.LogBackend == smhlog.Default {
+		return s.trees.get(ctx, backend.Admin, d.LogAddress, d.LogID)
+	}
+	return backend.Tree(ctx, d.LogID)
+}
+
+// vrfAlgorithm returns the algorithm of a VRF public key.
+func vrfAlgorithm(pub *keyspb.PublicKey) pb.VrfAlgorithm {
+	return (&domain.Domain{VRF: pub}).VRFAlgorithm()
+}
+
+// appVRFs returns the public keys of the app scoped VRFs in d.
+func appVRFs(d *domain.Domain) map[string]*keyspb.PublicKey {
+	if len(d.AppVRFs) == 0 {
+		return nil
+	}
+	ret := make(map[string]*keyspb.PublicKey, len(d.AppVRFs))
+	for appID, a := range d.AppVRFs {
+		ret[appID] = a.VRF
+	}
+	return ret
 }
 
 // GetDomain retrieves the domain info for a given domain.
@@ -168,49 +544,169 @@ func (s *Server) GetDomain(ctx context.Context, in *pb.GetDomainRequest) (*pb.Do
 }
 
 // CreateDomain reachs out to Trillian to produce new trees.
+//
+// CreateDomain is idempotent: if the domain already exists with the same
+// settings, labels, trees and tree parameters it is returned unchanged, and
+// AlreadyExists is returned if any of them differ. Trees left behind by a
+// previous attempt that failed before the domain was stored are reused if
+// they have the requested parameters. Trees created by an attempt that fails
+// are deleted.
 func (s *Server) CreateDomain(ctx context.Context, in *pb.CreateDomainRequest) (*pb.Domain, error) {
-	// TODO(gbelvin): Test whether the domain exists before creating trees.
+	// Validation changes nothing, so it is not audited.
+	if !in.GetValidateOnly() {
+		if err := s.audit(ctx, "CreateDomain", in.GetDomainId(), in); err != nil {
+			return nil, err
+		}
+	}
+	return s.createDomain(ctx, in)
+}
 
-	// Generate VRF key.
-	wrapped, err := s.keygen(ctx, vrfKeySpec)
+// createDomain implements CreateDomain without auditing the request.
+func (s *Server) createDomain(ctx context.Context, in *pb.CreateDomainRequest) (*pb.Domain, error) {
+	if err := validateDomainID(in.GetDomainId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "domain_id: %v", err)
+	}
+	minInterval, err := ptypes.Duration(in.MinInterval)
 	if err != nil {
-		return nil, fmt.Errorf("keygen: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Duration(%v): %v", in.MinInterval, err)
 	}
-	vrfPriv, err := p256.NewFromWrappedKey(ctx, wrapped)
+	maxInterval, err := ptypes.Duration(in.MaxInterval)
 	if err != nil {
-		return nil, fmt.Errorf("NewFromWrappedKey(): %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Duration(%v): %v", in.MaxInterval, err)
 	}
-	vrfPublicPB, err := der.ToPublicProto(vrfPriv.Public())
+	if err := validateIntervals(minInterval, maxInterval); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err := domain.ValidateLabels(in.GetLabels()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "labels: %v", err)
+	}
+	backend, err := s.logBackend(in.GetLogBackend(), in.GetTrillianBackend().GetLogAddress())
 	if err != nil {
 		return nil, err
 	}
-
-	// Create Trillian keys.
-	logTreeArgs := *logArgs
-	logTreeArgs.Tree.Description = fmt.Sprintf("KT domain %s's SMH Log", in.GetDomainId())
-	logTree, err := s.logAdmin.CreateTree(ctx, &logTreeArgs)
+	// Logs of other backends are managed by their operators.
+	if in.GetLogBackend() != smhlog.Default && (in.GetLogId() == 0 || in.GetMapId() == 0) {
+		return nil, status.Errorf(codes.InvalidArgument, "log_backend requires log_id and map_id")
+	}
+	tmap, mapAdmin, err := s.mapClients(in.GetTrillianBackend().GetMapAddress())
 	if err != nil {
-		return nil, fmt.Errorf("CreateTree(log): %v", err)
+		return nil, err
 	}
-	mapTreeArgs := *mapArgs
-	mapTreeArgs.Tree.Description = fmt.Sprintf("KT domain %s's Map", in.GetDomainId())
-	mapTree, err := client.CreateAndInitTree(ctx, &mapTreeArgs, s.mapAdmin, s.tmap)
+
+	// Apply the requested tree parameters before creating anything.
+	logTreeArgs, err := treeArgs(logArgs, in.GetLogSpec(),
+		fmt.Sprintf("KT domain %s's SMH Log", in.GetDomainId()))
 	if err != nil {
-		return nil, fmt.Errorf("CreateAndInitTree(map): %v", err)
+		return nil, err
 	}
-	minInterval, err := ptypes.Duration(in.MinInterval)
+	mapTreeArgs, err := treeArgs(mapArgs, in.GetMapSpec(),
+		fmt.Sprintf("KT domain %s's Map", in.GetDomainId()))
 	if err != nil {
-		return nil, fmt.Errorf("Duration(%v): %v", in.MinInterval, err)
+		return nil, err
+	}
+
+	// A read error means that the domain does not exist or that storage is
+	// unavailable. In the latter case writing the domain below fails too.
+	if d, err := s.domains.Read(ctx, in.GetDomainId(), true); err == nil {
+		switch {
+		case d.Deleted:
+			return nil, status.Errorf(codes.FailedPrecondition, "Domain %v is deleted", d.DomainID)
+		case d.MinInterval != minInterval || d.MaxInterval != maxInterval,
+			d.VRFAlgorithm() != in.GetVrfAlgorithm(),
+			d.LogBackend != in.GetLogBackend(),
+			d.LogAddress != in.GetTrillianBackend().GetLogAddress(),
+			d.MapAddress != in.GetTrillianBackend().GetMapAddress(),
+			!sameLabels(d.Labels, in.GetLabels()),
+			in.GetLogId() != 0 && in.GetLogId() != d.LogID,
+			in.GetMapId() != 0 && in.GetMapId() != d.MapID:
+			return nil, status.Errorf(codes.AlreadyExists, "Domain %v already exists with different settings", d.DomainID)
+		}
+		info, err := s.fetchDomain(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		// The parameters of caller-managed trees are not requested.
+		if in.GetLogId() == 0 && in.GetMapId() == 0 &&
+			(!sameTreeSpec(info.Log, logTreeArgs.Tree) || !sameTreeSpec(info.Map, mapTreeArgs.Tree)) {
+			return nil, status.Errorf(codes.AlreadyExists, "Domain %v already exists with different tree parameters", d.DomainID)
+		}
+		glog.Infof("Domain %v already exists", d.DomainID)
+		return info, nil
+	}
+
+	if _, ok := pb.VrfAlgorithm_name[int32(in.GetVrfAlgorithm())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Unknown vrf_algorithm %v", in.GetVrfAlgorithm())
+	}
+	if in.GetValidateOnly() {
+		return s.validateDomain(ctx, in, backend.Admin, mapAdmin, logTreeArgs, mapTreeArgs)
+	}
+
+	// Use the caller's VRF key, or generate a new one.
+	var wrapped proto.Message
+	var vrfPublicPB *keyspb.PublicKey
+	if k := in.GetVrfPrivateKey(); k != nil {
+		wrapped, vrfPublicPB, err = s.importVRF(ctx, k)
+		if got := vrfAlgorithm(vrfPublicPB); err == nil && got != in.GetVrfAlgorithm() {
+			return nil, status.Errorf(codes.InvalidArgument, "vrf_private_key is a %v key, want %v", got, in.GetVrfAlgorithm())
+		}
+	} else {
+		wrapped, vrfPublicPB, err = s.newVRF(ctx, in.GetVrfAlgorithm())
 	}
-	maxInterval, err := ptypes.Duration(in.MaxInterval)
 	if err != nil {
-		return nil, fmt.Errorf("Duration(%v): %v", in.MaxInterval, err)
+		return nil, err
+	}
+
+	// Use the caller's trees, create Trillian trees, or resume with the
+	// trees of a previous attempt. Only trees created here are deleted if
+	// a later step fails.
+	var created []*createdTree
+	fail := func(err error) (*pb.Domain, error) {
+		s.deleteTrees(created)
+		return nil, err
+	}
+	var logTree, mapTree *tpb.Tree
+	if in.GetLogId() != 0 || in.GetMapId() != 0 {
+		logTree, mapTree, err = s.existingTrees(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		logTree, err = s.findTree(ctx, backend.Admin, logTreeArgs.Tree)
+		if err != nil {
+			return nil, err
+		}
+		if logTree == nil {
+			args, err := withTreeKey(ctx, s.treeGen, logTreeArgs)
+			if err != nil {
+				return nil, err
+			}
+			logTree, err = backend.Admin.CreateTree(ctx, args)
+			if err != nil {
+				return nil, fmt.Errorf("CreateTree(log): %w", err)
+			}
+			created = append(created, &createdTree{admin: backend.Admin, tree: logTree})
+		}
+		mapTree, err = s.findTree(ctx, mapAdmin, mapTreeArgs.Tree)
+		if err != nil {
+			return fail(err)
+		}
+		if mapTree == nil {
+			args, err := withTreeKey(ctx, s.treeGen, mapTreeArgs)
+			if err != nil {
+				return fail(err)
+			}
+			mapTree, err = client.CreateAndInitTree(ctx, args, mapAdmin, tmap)
+			if err != nil {
+				return fail(fmt.Errorf("CreateAndInitTree(map): %w", err))
+			}
+			created = append(created, &createdTree{admin: mapAdmin, tree: mapTree})
+		}
 	}
 
 	// Initialize log with first map root.
-	if err := s.initialize(ctx, logTree, mapTree); err != nil {
-		return nil, fmt.Errorf("initialize of log %v and map %v failed: %v",
-			logTree.TreeId, mapTree.TreeId, err)
+	if err := s.initialize(ctx, backend, tmap, logTree, mapTree); err != nil {
+		return fail(fmt.Errorf("initialize of log %v and map %v failed: %w",
+			logTree.TreeId, mapTree.TreeId, err))
 	}
 
 	if err := s.domains.Write(ctx, &domain.Domain{
@@ -221,34 +717,300 @@ func (s *Server) CreateDomain(ctx context.Context, in *pb.CreateDomainRequest) (
 		VRFPriv:     wrapped,
 		MinInterval: minInterval,
 		MaxInterval: maxInterval,
+		Labels:      in.GetLabels(),
+		LogBackend:  in.GetLogBackend(),
+		LogAddress:  in.GetTrillianBackend().GetLogAddress(),
+		MapAddress:  in.GetTrillianBackend().GetMapAddress(),
 	}); err != nil {
-		return nil, fmt.Errorf("adminstorage.Write(): %v", err)
+		return fail(fmt.Errorf("adminstorage.Write(): %w", err))
+	}
+	// Updates of a domain whose map is still empty are all recorded by the
+	// app index from now on.
+	// Failures are only logged: AddAppVRF repeats the check while the map
+	// is empty.
+	if s.apps != nil {
+		if empty, err := mapEmpty(ctx, tmap, mapTree.TreeId); err != nil {
+			glog.Warningf("mapEmpty(%v): %v", mapTree.TreeId, err)
+		} else if empty {
+			if err := s.apps.SetComplete(ctx, in.GetDomainId()); err != nil {
+				glog.Warningf("apps.SetComplete(%v): %v", in.GetDomainId(), err)
+			}
+		}
 	}
 	glog.Infof("Created domain %v", in.GetDomainId())
 	return &pb.Domain{
-		DomainId: in.GetDomainId(),
-		Log:      logTree,
-		Map:      mapTree,
-		Vrf:      vrfPublicPB,
+		DomainId:     in.GetDomainId(),
+		Log:          logTree,
+		Map:          mapTree,
+		Vrf:          vrfPublicPB,
+		VrfAlgorithm: in.GetVrfAlgorithm(),
+		Labels:       in.GetLabels(),
+		LogBackend:   in.GetLogBackend(),
+
+		TrillianBackend: in.GetTrillianBackend(),
+	}, nil
+}
+
+// sameLabels returns true if a and b hold the same labels.
+func sameLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// validateDomain returns the domain that createDomain would create for in,
+// without creating trees or keys or writing storage. The trees that would be
+// reused are looked up with logAdmin and mapAdmin, which also checks that
+// Trillian is reachable.
+func (s *Server) validateDomain(ctx context.Context, in *pb.CreateDomainRequest, logAdmin, mapAdmin tpb.TrillianAdminClient, logTreeArgs, mapTreeArgs *tpb.CreateTreeRequest) (*pb.Domain, error) {
+	var vrfPublicPB *keyspb.PublicKey
+	if k := in.GetVrfPrivateKey(); k != nil {
+		_, pub, err := s.importVRF(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if got := vrfAlgorithm(pub); got != in.GetVrfAlgorithm() {
+			return nil, status.Errorf(codes.InvalidArgument, "vrf_private_key is a %v key, want %v", got, in.GetVrfAlgorithm())
+		}
+		vrfPublicPB = pub
+	}
+
+	var logTree, mapTree *tpb.Tree
+	var err error
+	if in.GetLogId() != 0 || in.GetMapId() != 0 {
+		logTree, mapTree, err = s.existingTrees(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if logTree, err = s.findTree(ctx, logAdmin, logTreeArgs.Tree); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "log admin: %v", err)
+		}
+		if logTree == nil {
+			logTree = logTreeArgs.Tree
+		}
+		if mapTree, err = s.findTree(ctx, mapAdmin, mapTreeArgs.Tree); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "map admin: %v", err)
+		}
+		if mapTree == nil {
+			mapTree = mapTreeArgs.Tree
+		}
+	}
+	return &pb.Domain{
+		DomainId:     in.GetDomainId(),
+		Log:          logTree,
+		Map:          mapTree,
+		Vrf:          vrfPublicPB,
+		MinInterval:  in.GetMinInterval(),
+		MaxInterval:  in.GetMaxInterval(),
+		VrfAlgorithm: in.GetVrfAlgorithm(),
+		Labels:       in.GetLabels(),
+		LogBackend:   in.GetLogBackend(),
+
+		TrillianBackend: in.GetTrillianBackend(),
 	}, nil
 }
 
+// maxDomainIDLen is the longest domain ID that storage accepts.
+const maxDomainIDLen = 40
+
+// validateDomainID checks that id can be stored and used in a URL path.
+func validateDomainID(id string) error {
+	switch {
+	case id == "":
+		return errors.New("missing")
+	case len(id) > maxDomainIDLen:
+		return fmt.Errorf("longer than %v bytes", maxDomainIDLen)
+	case !utf8.ValidString(id):
+		return errors.New("not valid UTF-8")
+	}
+	for _, r := range id {
+		if r == '/' || !unicode.IsPrint(r) {
+			return fmt.Errorf("contains %q", r)
+		}
+	}
+	return nil
+}
+
+// validateIntervals checks that the epoch intervals of a domain are not
+// negative and that min is no longer than max. A zero max disables periodic
+// epochs.
+func validateIntervals(min, max time.Duration) error {
+	switch {
+	case min < 0 || max < 0:
+		return errors.New("intervals must not be negative")
+	case max != 0 && min > max:
+		return fmt.Errorf("min_interval %v is longer than max_interval %v", min, max)
+	}
+	return nil
+}
+
+// existingTrees returns the caller-managed trees requested by in after
+// checking that they can back a new domain.
+func (s *Server) existingTrees(ctx context.Context, in *pb.CreateDomainRequest) (*tpb.Tree, *tpb.Tree, error) {
+	if in.GetLogId() == 0 || in.GetMapId() == 0 {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "log_id and map_id must be set together")
+	}
+	if in.GetLogSpec() != nil || in.GetMapSpec() != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "log_spec and map_spec cannot be used with existing trees")
+	}
+	backend, err := s.logBackend(in.GetLogBackend(), in.GetTrillianBackend().GetLogAddress())
+	if err != nil {
+		return nil, nil, err
+	}
+	_, mapAdmin, err := s.mapClients(in.GetTrillianBackend().GetMapAddress())
+	if err != nil {
+		return nil, nil, err
+	}
+	logTree, err := backend.Tree(ctx, in.GetLogId())
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetTree(log %v): %w", in.GetLogId(), err)
+	}
+	if err := checkTree(logTree, tpb.TreeType_LOG); err != nil {
+		return nil, nil, err
+	}
+	mapTree, err := mapAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: in.GetMapId()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetTree(map %v): %w", in.GetMapId(), err)
+	}
+	if err := checkTree(mapTree, tpb.TreeType_MAP); err != nil {
+		return nil, nil, err
+	}
+
+	// Trees cannot be shared between domains.
+	domains, err := s.domains.List(ctx, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range domains {
+		if (d.LogBackend == in.GetLogBackend() && d.LogID == logTree.TreeId) || d.MapID == mapTree.TreeId {
+			return nil, nil, status.Errorf(codes.AlreadyExists, "Trees are already used by domain %v", d.DomainID)
+		}
+	}
+	return logTree, mapTree, nil
+}
+
+// checkTree returns an error if t is not an active tree of treeType with a
+// hash strategy that clients can verify.
+func checkTree(t *tpb.Tree, treeType tpb.TreeType) error {
+	switch {
+	case t.GetTreeType() != treeType:
+		return status.Errorf(codes.InvalidArgument, "tree %v is a %v, want %v", t.GetTreeId(), t.GetTreeType(), treeType)
+	case t.GetTreeState() != tpb.TreeState_ACTIVE || t.GetDeleted():
+		return status.Errorf(codes.FailedPrecondition, "tree %v is not active", t.GetTreeId())
+	case !hashStrategies[treeType][t.GetHashStrategy()]:
+		return status.Errorf(codes.InvalidArgument, "tree %v uses unsupported hash strategy %v", t.GetTreeId(), t.GetHashStrategy())
+	}
+	return nil
+}
+
+// createdTree is a tree created by a CreateDomain call.
+type createdTree struct {
+	admin tpb.TrillianAdminClient
+	tree  *tpb.Tree
+}
+
+// findTree returns an active tree with the same type, description and
+// parameters as want, or nil if there is none. Tree descriptions include the
+// domain ID, which lets CreateDomain find the trees of an earlier failed
+// attempt.
+func (s *Server) findTree(ctx context.Context, admin tpb.TrillianAdminClient, want *tpb.Tree) (*tpb.Tree, error) {
+	resp, err := admin.ListTrees(ctx, &tpb.ListTreesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("ListTrees(): %w", err)
+	}
+	for _, t := range resp.GetTree() {
+		if t.GetTreeType() == want.GetTreeType() &&
+			t.GetDescription() == want.GetDescription() &&
+			sameTreeSpec(t, want) &&
+			t.GetTreeState() == tpb.TreeState_ACTIVE &&
+			!t.GetDeleted() {
+			glog.Infof("Reusing %v tree %v", t.GetTreeType(), t.GetTreeId())
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// deleteTrees deletes trees created by a CreateDomain call that failed.
+// Trees that cannot be deleted are logged and found by the next attempt. The
+// trees are deleted even if the request that created them was cancelled.
+func (s *Server) deleteTrees(trees []*createdTree) {
+	ctx, cancel := context.WithTimeout(context.Background(), deleteTreesTimeout)
+	defer cancel()
+	for _, t := range trees {
+		if _, err := t.admin.DeleteTree(ctx, &tpb.DeleteTreeRequest{TreeId: t.tree.GetTreeId()}); err != nil {
+			glog.Errorf("DeleteTree(%v): %v", t.tree.GetTreeId(), err)
+		}
+	}
+}
+
+// newVRF generates a new VRF key pair for alg. Ed25519 keys are always
+// generated in-process because KeyGen only supports Trillian key
+// specifications.
+func (s *Server) newVRF(ctx context.Context, alg pb.VrfAlgorithm) (proto.Message, *keyspb.PublicKey, error) {
+	var wrapped proto.Message
+	var err error
+	switch alg {
+	case pb.VrfAlgorithm_ED25519:
+		wrapped, err = ed25519.GenerateWrappedKey()
+	default:
+		wrapped, err = s.keygen(ctx, vrfKeySpec)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("keygen: %w", err)
+	}
+	vrfPriv, err := factory.NewFromWrappedKey(ctx, wrapped)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewFromWrappedKey(): %w", err)
+	}
+	vrfPublicPB, err := factory.ToPublicProto(vrfPriv.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrapped, vrfPublicPB, nil
+}
+
+// importVRF returns an existing VRF key pair. k must wrap a P256 or Ed25519
+// private key.
+func (s *Server) importVRF(ctx context.Context, k *any.Any) (proto.Message, *keyspb.PublicKey, error) {
+	var wrapped ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(k, &wrapped); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "vrf_private_key: %v", err)
+	}
+	vrfPriv, err := factory.NewFromWrappedKey(ctx, wrapped.Message)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "vrf_private_key: %v", err)
+	}
+	vrfPublicPB, err := factory.ToPublicProto(vrfPriv.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrapped.Message, vrfPublicPB, nil
+}
+
 // initialize inserts the first (empty) SignedMapRoot into the log if it is empty.
 // This keeps the log leaves in-sync with the map which starts off with an
 // empty log root at map revision 0.
-func (s *Server) initialize(ctx context.Context, logTree, mapTree *tpb.Tree) error {
+func (s *Server) initialize(ctx context.Context, backend *smhlog.Backend, tmap tpb.TrillianMapClient, logTree, mapTree *tpb.Tree) error {
 	logID := logTree.GetTreeId()
 	mapID := mapTree.GetTreeId()
 
-	logRoot, err := s.tlog.GetLatestSignedLogRoot(ctx,
+	logRoot, err := backend.Log.GetLatestSignedLogRoot(ctx,
 		&tpb.GetLatestSignedLogRootRequest{LogId: logID})
 	if err != nil {
-		return fmt.Errorf("GetLatestSignedLogRoot(%v): %v", logID, err)
+		return fmt.Errorf("GetLatestSignedLogRoot(%v): %w", logID, err)
 	}
-	mapRoot, err := s.tmap.GetSignedMapRoot(ctx,
+	mapRoot, err := tmap.GetSignedMapRoot(ctx,
 		&tpb.GetSignedMapRootRequest{MapId: mapID})
 	if err != nil {
-		return fmt.Errorf("GetSignedMapRoot(%v): %v", mapID, err)
+		return fmt.Errorf("GetSignedMapRoot(%v): %w", mapID, err)
 	}
 
 	// If the tree is empty and the map is empty,
@@ -260,34 +1022,691 @@ func (s *Server) initialize(ctx context.Context, logTree, mapTree *tpb.Tree) err
 
 	glog.Infof("Initializing Trillian Log %v with empty map root", logID)
 	// Non-blocking add leaf
-	smrJSON, err := json.Marshal(mapRoot.GetMapRoot())
-	if err != nil {
-		return err
-	}
-
-	logClient, err := client.NewFromTree(s.tlog, logTree)
-	if err != nil {
-		return fmt.Errorf("could not create log client: %v", err)
-	}
-	if err := logClient.QueueLeaf(ctx, smrJSON); err != nil {
-		return fmt.Errorf("trillianLog.QueueLeaf(logID: %v, leaf: %v): %v",
-			logID, smrJSON, err)
-	}
-	return nil
+	return smhlog.QueueRoot(ctx, backend.Log, logID, mapRoot.GetMapRoot())
 }
 
 // DeleteDomain marks a domain as deleted, but does not immediately delete it.
+// The log and map trees of the domain are deleted in Trillian as well.
+// Deleting a deleted domain does not extend its retention window, but retries
+// deleting trees that could not be deleted before.
 func (s *Server) DeleteDomain(ctx context.Context, in *pb.DeleteDomainRequest) (*google_protobuf.Empty, error) {
-	if err := s.domains.SetDelete(ctx, in.GetDomainId(), true); err != nil {
+	if err := s.audit(ctx, "DeleteDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), true)
+	if err != nil {
 		return nil, err
 	}
+	if !d.Deleted {
+		if err := s.domains.SetDelete(ctx, in.GetDomainId(), true); err != nil {
+			return nil, err
+		}
+	}
+	if !d.TreesDeleted {
+		if err := s.setTreesDeleted(ctx, d, true); err != nil {
+			return nil, err
+		}
+	}
 	return &google_protobuf.Empty{}, nil
 }
 
-// UndeleteDomain reactivates a deleted domain - provided that UndeleteDomain is called sufficiently soon after DeleteDomain.
-func (s *Server) UndeleteDomain(ctx context.Context, in *pb.UndeleteDomainRequest) (*google_protobuf.Empty, error) {
-	if err := s.domains.SetDelete(ctx, in.GetDomainId(), false); err != nil {
-		return nil, err
+// setTreesDeleted deletes or undeletes the log and map trees of d in
+// Trillian and records their new state. Trees that are already in the
+// requested state are left alone, as are logs of other backends.
+func (s *Server) setTreesDeleted(ctx context.Context, d *domain.Domain, deleted bool) error {
+	type tree struct {
+		admin tpb.TrillianAdminClient
+		addr  string
+		id    int64
+	}
+	_, mapAdmin, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return err
+	}
+	trees := []tree{{admin: mapAdmin, addr: d.MapAddress, id: d.MapID}}
+	if d.LogBackend == smhlog.Default {
+		_, logAdmin, err := s.logClients(d.LogAddress)
+		if err != nil {
+			return err
+		}
+		trees = append(trees, tree{admin: logAdmin, addr: d.LogAddress, id: d.LogID})
+	}
+	for _, t := range trees {
+		tree, err := t.admin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: t.id})
+		if err != nil {
+			glog.Errorf("GetTree(%v): %v", t.id, err)
+			return status.Errorf(codes.Internal, "Cannot fetch tree %v of domain %v", t.id, d.DomainID)
+		}
+		if tree.GetDeleted() == deleted {
+			continue
+		}
+		if deleted {
+			_, err = t.admin.DeleteTree(ctx, &tpb.DeleteTreeRequest{TreeId: t.id})
+		} else {
+			_, err = t.admin.UndeleteTree(ctx, &tpb.UndeleteTreeRequest{TreeId: t.id})
+		}
+		s.trees.invalidate(t.addr, t.id)
+		if err != nil {
+			glog.Errorf("Set deleted state of tree %v to %v: %v", t.id, deleted, err)
+			return status.Errorf(codes.Internal, "Cannot set deleted state of tree %v of domain %v", t.id, d.DomainID)
+		}
+		glog.Infof("Set deleted state of tree %v of domain %v to %v", t.id, d.DomainID, deleted)
+	}
+	return s.domains.SetTreesDeleted(ctx, d.DomainID, deleted)
+}
+
+// UndeleteDomain reactivates a deleted domain and its Trillian trees,
+// provided that its retention window has not expired. The trees are restored
+// first so that a domain is never active while its trees are deleted.
+func (s *Server) UndeleteDomain(ctx context.Context, in *pb.UndeleteDomainRequest) (*google_protobuf.Empty, error) {
+	if err := s.audit(ctx, "UndeleteDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), true)
+	if err != nil {
+		return nil, err
+	}
+	if s.retention > 0 && d.HardDeletable(time.Now(), s.retention) {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"Domain %v was deleted at %v and can no longer be undeleted", d.DomainID, d.DeleteTime)
+	}
+	if err := s.setTreesDeleted(ctx, d, false); err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetDelete(ctx, in.GetDomainId(), false); err != nil {
+		return nil, err
+	}
+	return &google_protobuf.Empty{}, nil
+}
+
+// FreezeDomain makes a domain read-only.
+func (s *Server) FreezeDomain(ctx context.Context, in *pb.FreezeDomainRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "FreezeDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	return s.setFrozen(ctx, in.GetDomainId(), true)
+}
+
+// UnfreezeDomain makes a frozen domain writable again.
+func (s *Server) UnfreezeDomain(ctx context.Context, in *pb.UnfreezeDomainRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "UnfreezeDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	return s.setFrozen(ctx, in.GetDomainId(), false)
+}
+
+func (s *Server) setFrozen(ctx context.Context, domainID string, frozen bool) (*pb.Domain, error) {
+	d, err := s.domains.Read(ctx, domainID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetFrozen(ctx, d.DomainID, frozen); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetFrozen(): %w", err)
+	}
+	glog.Infof("Set frozen state of domain %v to %v", d.DomainID, frozen)
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// SetAppListing enables or disables ListUserApps for a domain.
+func (s *Server) SetAppListing(ctx context.Context, in *pb.SetAppListingRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "SetAppListing", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetAppListing(ctx, d.DomainID, in.GetEnabled()); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetAppListing(): %w", err)
+	}
+	glog.Infof("Set app listing of domain %v to %v", d.DomainID, in.GetEnabled())
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// SetMutationQuota replaces the mutation quota of a domain.
+func (s *Server) SetMutationQuota(ctx context.Context, in *pb.SetMutationQuotaRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "SetMutationQuota", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	q := in.GetMutationQuota()
+	if q.GetMutationsPerEpoch() < 0 || q.GetMutationsPerUserPerDay() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Quotas must not be negative")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetMutationQuota(ctx, d.DomainID, q); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetMutationQuota(): %w", err)
+	}
+	glog.Infof("Set mutation quota of domain %v to %v", d.DomainID, q)
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// SetDomainIntervals changes the epoch intervals of a domain and notifies the
+// sequencer so that they take effect without a restart. The new intervals are
+// stored even if the sequencer cannot be notified.
+func (s *Server) SetDomainIntervals(ctx context.Context, in *pb.SetDomainIntervalsRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "SetDomainIntervals", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	minInterval, err := ptypes.Duration(in.GetMinInterval())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Duration(%v): %v", in.GetMinInterval(), err)
+	}
+	maxInterval, err := ptypes.Duration(in.GetMaxInterval())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Duration(%v): %v", in.GetMaxInterval(), err)
+	}
+	if err := validateIntervals(minInterval, maxInterval); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetIntervals(ctx, d.DomainID, minInterval, maxInterval); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetIntervals(): %w", err)
+	}
+	glog.Infof("Set intervals of domain %v to %v-%v", d.DomainID, minInterval, maxInterval)
+	if s.intervals != nil {
+		if err := s.intervals.UpdateIntervals(ctx, d.DomainID); err != nil {
+			glog.Errorf("UpdateIntervals(%v): %v", d.DomainID, err)
+			return nil, status.Errorf(codes.Unavailable, "Intervals of domain %v were saved but the sequencer could not apply them", d.DomainID)
+		}
+	}
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// AnnounceDomainMigration publishes an announcement that a domain moves to
+// another key server or other keys. The announcement must be signed by the
+// current map key of the domain. An unset migration withdraws the
+// announcement.
+func (s *Server) AnnounceDomainMigration(ctx context.Context, in *pb.AnnounceDomainMigrationRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "AnnounceDomainMigration", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if signed := in.GetMigration(); signed != nil {
+		current, err := s.fetchDomain(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		m, err := migration.Verify(signed, current)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid migration: %v", err)
+		}
+		glog.Infof("Domain %v migrates to %q at revision %v", d.DomainID, m.GetNewAddress(), m.GetRevision())
+	} else {
+		glog.Infof("Withdrew migration of domain %v", d.DomainID)
+	}
+	if err := s.domains.SetMigration(ctx, d.DomainID, in.GetMigration()); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetMigration(): %w", err)
+	}
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// ForceEpoch creates a new epoch for a domain without waiting for its
+// MinInterval.
+func (s *Server) ForceEpoch(ctx context.Context, in *pb.ForceEpochRequest) (*google_protobuf.Empty, error) {
+	if err := s.audit(ctx, "ForceEpoch", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if s.epochs == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Epoch creation is not configured")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	switch err := s.epochs.ForceEpoch(ctx, d.DomainID); {
+	case err == nil:
+	case errors.Is(err, domain.ErrFrozen):
+		return nil, status.Errorf(codes.FailedPrecondition, "Domain %v is frozen", d.DomainID)
+	default:
+		glog.Errorf("ForceEpoch(%v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Unavailable, "Cannot create epoch for domain %v", d.DomainID)
+	}
+	return &google_protobuf.Empty{}, nil
+}
+
+// AddAppVRF generates a VRF key that is scoped to a single app.
+func (s *Server) AddAppVRF(ctx context.Context, in *pb.AddAppVRFRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "AddAppVRF", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if s.apps == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "App index storage is not configured")
+	}
+	if in.GetAppId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Please specify an app_id")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.AppVRFs[in.GetAppId()]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "App %v already has a VRF", in.GetAppId())
+	}
+	// Existing entries are stored under indexes of the domain VRF, which a
+	// new VRF would no longer reach. The app index only answers this for
+	// domains whose every update it recorded.
+	complete, err := s.apps.Complete(ctx, d.DomainID)
+	if err != nil {
+		glog.Errorf("apps.Complete(%v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot check entries of app")
+	}
+	if !complete {
+		tmap, _, err := s.mapClients(d.MapAddress)
+		if err != nil {
+			return nil, err
+		}
+		empty, err := mapEmpty(ctx, tmap, d.MapID)
+		if err != nil {
+			glog.Errorf("mapEmpty(%v): %v", d.DomainID, err)
+			return nil, status.Errorf(codes.Unavailable, "Cannot check entries of domain %v", d.DomainID)
+		}
+		if !empty {
+			return nil, status.Errorf(codes.FailedPrecondition, "Domain %v has entries from before apps were recorded", d.DomainID)
+		}
+		if err := s.apps.SetComplete(ctx, d.DomainID); err != nil {
+			glog.Errorf("apps.SetComplete(%v): %v", d.DomainID, err)
+			return nil, status.Errorf(codes.Internal, "Cannot check entries of app")
+		}
+	}
+	hasEntries, err := s.apps.HasApp(ctx, d.DomainID, in.GetAppId())
+	if err != nil {
+		glog.Errorf("apps.HasApp(%v, %v): %v", d.DomainID, in.GetAppId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot check entries of app")
+	}
+	if hasEntries {
+		return nil, status.Errorf(codes.FailedPrecondition, "App %v already has entries", in.GetAppId())
+	}
+	wrapped, vrfPublicPB, err := s.newVRF(ctx, d.VRFAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	// Storage repeats the entries check atomically with the insert, as an
+	// update may have been recorded since HasApp.
+	switch err := s.domains.AddAppVRF(ctx, d.DomainID, in.GetAppId(), vrfPublicPB, wrapped); {
+	case err == nil:
+	case errors.Is(err, domain.ErrAppHasEntries):
+		return nil, status.Errorf(codes.FailedPrecondition, "App %v already has entries", in.GetAppId())
+	default:
+		return nil, fmt.Errorf("adminstorage.AddAppVRF(): %w", err)
+	}
+	glog.Infof("Added VRF for app %v in domain %v", in.GetAppId(), d.DomainID)
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// UpdateDomain sets the max root duration of the domain's log and map, and
+// the labels of the domain, as selected by the update mask of the request.
+func (s *Server) UpdateDomain(ctx context.Context, in *pb.UpdateDomainRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "UpdateDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	var updateDuration, updateLabels bool
+	paths := in.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"max_root_duration"}
+	}
+	for _, p := range paths {
+		switch p {
+		case "max_root_duration":
+			updateDuration = true
+		case "labels":
+			updateLabels = true
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: unsupported path %q", p)
+		}
+	}
+	maxRootDuration, err := ptypes.Duration(in.GetMaxRootDuration())
+	if updateDuration && err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "max_root_duration: %v", err)
+	}
+	if updateDuration && maxRootDuration < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_root_duration must not be negative")
+	}
+	if err := domain.ValidateLabels(in.GetLabels()); updateLabels && err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "labels: %v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	if updateLabels {
+		if err := s.domains.SetLabels(ctx, d.DomainID, in.GetLabels()); err != nil {
+			return nil, fmt.Errorf("adminstorage.SetLabels(): %w", err)
+		}
+		d.Labels = in.GetLabels()
+		glog.Infof("Set labels of domain %v to %v", d.DomainID, d.Labels)
+	}
+	if !updateDuration {
+		return s.fetchDomain(ctx, d)
+	}
+	mask := &field_mask.FieldMask{Paths: []string{"max_root_duration"}}
+	type tree struct {
+		admin  tpb.TrillianAdminClient
+		addr   string
+		treeID int64
+	}
+	_, mapAdmin, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return nil, err
+	}
+	// Logs of other backends are managed by their operators.
+	trees := []tree{{admin: mapAdmin, addr: d.MapAddress, treeID: d.MapID}}
+	if d.LogBackend == smhlog.Default {
+		_, logAdmin, err := s.logClients(d.LogAddress)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree{admin: logAdmin, addr: d.LogAddress, treeID: d.LogID})
+	}
+	for _, t := range trees {
+		_, err := t.admin.UpdateTree(ctx, &tpb.UpdateTreeRequest{
+			Tree: &tpb.Tree{
+				TreeId:          t.treeID,
+				MaxRootDuration: in.GetMaxRootDuration(),
+			},
+			UpdateMask: mask,
+		})
+		s.trees.invalidate(t.addr, t.treeID)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateTree(%v): %w", t.treeID, err)
+		}
+	}
+	glog.Infof("Set max root duration of domain %v to %v", in.GetDomainId(), maxRootDuration)
+	return s.fetchDomain(ctx, d)
+}
+
+// RotateDomainVRF generates a new VRF key for a domain. The replaced key
+// remains valid for the requested overlap so that clients and entries can
+// migrate to the new key.
+func (s *Server) RotateDomainVRF(ctx context.Context, in *pb.RotateDomainVRFRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "RotateDomainVRF", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	overlap, err := overlapArg(in.GetOverlap(), defaultVRFOverlap)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, vrfPublicPB, err := s.newVRF(ctx, d.VRFAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	overlapEnd := time.Now().Add(overlap)
+	if err := s.domains.RotateVRF(ctx, d.DomainID, vrfPublicPB, wrapped, overlapEnd); err != nil {
+		return nil, fmt.Errorf("adminstorage.RotateVRF(): %w", err)
+	}
+	glog.Infof("Rotated VRF of domain %v, previous key valid until %v", d.DomainID, overlapEnd)
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// overlapArg returns the overlap requested by d, or def if d is not set.
+func overlapArg(d *duration.Duration, def time.Duration) (time.Duration, error) {
+	if d == nil {
+		return def, nil
+	}
+	overlap, err := ptypes.Duration(d)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "overlap: %v", err)
+	}
+	if overlap < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "overlap must not be negative")
+	}
+	return overlap, nil
+}
+
+// RotateTreeKeys generates new signing keys for the log and map trees of a
+// domain and installs them with Trillian's UpdateTree. The replaced keys are
+// recorded with the domain so that clients accept roots signed with them
+// until the end of the requested overlap.
+func (s *Server) RotateTreeKeys(ctx context.Context, in *pb.RotateTreeKeysRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "RotateTreeKeys", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if !in.GetRotateLog() && !in.GetRotateMap() {
+		return nil, status.Errorf(codes.InvalidArgument, "rotate_log or rotate_map must be set")
+	}
+	overlap, err := overlapArg(in.GetOverlap(), defaultTreeKeyOverlap)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	type tree struct {
+		admin    tpb.TrillianAdminClient
+		addr     string
+		treeID   int64
+		rotation *pb.TreeKeyRotation
+	}
+	var trees []tree
+	if in.GetRotateLog() {
+		// Logs of other backends are managed by their operators.
+		if d.LogBackend != smhlog.Default {
+			return nil, status.Errorf(codes.FailedPrecondition, "log of domain %v is on backend %q", d.DomainID, d.LogBackend)
+		}
+		_, logAdmin, err := s.logClients(d.LogAddress)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree{admin: logAdmin, addr: d.LogAddress, treeID: d.LogID, rotation: d.LogKeyRotation})
+	}
+	if in.GetRotateMap() {
+		_, mapAdmin, err := s.mapClients(d.MapAddress)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree{admin: mapAdmin, addr: d.MapAddress, treeID: d.MapID, rotation: d.MapKeyRotation})
+	}
+
+	now := time.Now()
+	// Rotating again during an overlap would drop a key that clients still
+	// need.
+	for _, t := range trees {
+		if t.rotation == nil {
+			continue
+		}
+		overlapEnd, err := ptypes.Timestamp(t.rotation.GetOverlapEnd())
+		if err != nil {
+			return nil, err
+		}
+		if now.Before(overlapEnd) {
+			return nil, status.Errorf(codes.FailedPrecondition, "previous key of tree %v is valid until %v", t.treeID, overlapEnd)
+		}
+	}
+	rotateTime, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	overlapEnd, err := ptypes.TimestampProto(now.Add(overlap))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trees {
+		current, err := t.admin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: t.treeID})
+		if err != nil {
+			return nil, fmt.Errorf("GetTree(%v): %w", t.treeID, err)
+		}
+		privateKey, publicKey, err := newTreeKey(ctx, s.treeGen, current.GetSignatureAlgorithm())
+		if err != nil {
+			return nil, err
+		}
+		// Record the rotation before installing the new key so that clients
+		// never see roots signed by a key that is not published.
+		rotation := &pb.TreeKeyRotation{
+			PreviousKey: current.GetPublicKey(),
+			PublicKey:   publicKey,
+			RotateTime:  rotateTime,
+			OverlapEnd:  overlapEnd,
+		}
+		if err := s.domains.SetTreeKeyRotation(ctx, d.DomainID, t.treeID, rotation); err != nil {
+			return nil, fmt.Errorf("adminstorage.SetTreeKeyRotation(): %w", err)
+		}
+		_, err = t.admin.UpdateTree(ctx, &tpb.UpdateTreeRequest{
+			Tree: &tpb.Tree{
+				TreeId:     t.treeID,
+				PrivateKey: privateKey,
+				PublicKey:  publicKey,
+			},
+			UpdateMask: &field_mask.FieldMask{Paths: []string{"private_key", "public_key"}},
+		})
+		s.trees.invalidate(t.addr, t.treeID)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateTree(%v): %w", t.treeID, err)
+		}
+		glog.Infof("Rotated signing key of tree %v of domain %v, previous key valid until %v",
+			t.treeID, d.DomainID, now.Add(overlap))
+	}
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// SetKeyPolicy replaces the policy that the authorized keys of the domain's
+// entries must comply with. An empty policy allows all keys.
+func (s *Server) SetKeyPolicy(ctx context.Context, in *pb.SetKeyPolicyRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "SetKeyPolicy", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if err := checkKeyPolicy(in.GetKeyPolicy()); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.nextRevision(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetKeyPolicy(ctx, d.DomainID, in.GetKeyPolicy(), revision); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetKeyPolicy(): %w", err)
+	}
+	glog.Infof("Set key policy of domain %v to %v", d.DomainID, in.GetKeyPolicy())
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// nextRevision returns the revision of the next epoch of d.
+func (s *Server) nextRevision(ctx context.Context, d *domain.Domain) (int64, error) {
+	tmap, _, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return 0, fmt.Errorf("GetSignedMapRoot(%v): %w", d.MapID, err)
+	}
+	return resp.GetMapRoot().GetMapRevision() + 1, nil
+}
+
+// mapEmpty returns true if no leaves were ever set in map mapID, ie. if its
+// latest root hash is that of revision 0.
+func mapEmpty(ctx context.Context, tmap tpb.TrillianMapClient, mapID int64) (bool, error) {
+	latest, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: mapID})
+	if err != nil {
+		return false, fmt.Errorf("GetSignedMapRoot(%v): %w", mapID, err)
+	}
+	if latest.GetMapRoot().GetMapRevision() == 0 {
+		return true, nil
+	}
+	first, err := tmap.GetSignedMapRootByRevision(ctx, &tpb.GetSignedMapRootByRevisionRequest{MapId: mapID, Revision: 0})
+	if err != nil {
+		return false, fmt.Errorf("GetSignedMapRootByRevision(%v, 0): %w", mapID, err)
+	}
+	return bytes.Equal(first.GetMapRoot().GetRootHash(), latest.GetMapRoot().GetRootHash()), nil
+}
+
+// SetEndorsementPolicy replaces the endorsers that must co-sign the map roots
+// of the domain. An empty policy publishes map roots without endorsements.
+func (s *Server) SetEndorsementPolicy(ctx context.Context, in *pb.SetEndorsementPolicyRequest) (*pb.Domain, error) {
+	if err := s.audit(ctx, "SetEndorsementPolicy", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if err := endorsement.CheckPolicy(in.GetEndorsementPolicy()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid endorsement policy: %v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domains.SetEndorsementPolicy(ctx, d.DomainID, in.GetEndorsementPolicy()); err != nil {
+		return nil, fmt.Errorf("adminstorage.SetEndorsementPolicy(): %w", err)
+	}
+	glog.Infof("Set endorsement policy of domain %v to %v of %v keys",
+		d.DomainID, in.GetEndorsementPolicy().GetQuorum(), len(in.GetEndorsementPolicy().GetKeys()))
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+}
+
+// checkKeyPolicy returns an error if p cannot be enforced.
+func checkKeyPolicy(p *pb.KeyPolicy) error {
+	if p.GetMaxKeys() < 0 {
+		return status.Errorf(codes.InvalidArgument, "max_keys must not be negative")
+	}
+	for _, alg := range p.GetAllowedAlgorithms() {
+		if !entry.KeyAlgorithms[alg] {
+			return status.Errorf(codes.InvalidArgument, "Authorized keys cannot use %v", alg)
+		}
+	}
+	return nil
+}
+
+// PurgeEntryData marks the committed profile data of a user purged through
+// the requested revision. Both the index computed with the current VRF and,
+// during a rotation, the index computed with the previous VRF are purged.
+func (s *Server) PurgeEntryData(ctx context.Context, in *pb.PurgeEntryDataRequest) (*google_protobuf.Empty, error) {
+	if err := s.audit(ctx, "PurgeEntryData", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if in.GetUserId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Please specify a user_id")
+	}
+	if in.GetRevision() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Revision is %v, want >= 0", in.GetRevision())
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	revision := in.GetRevision()
+	if revision == 0 {
+		tmap, _, err := s.mapClients(d.MapAddress)
+		if err != nil {
+			return nil, err
+		}
+		root, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+		if err != nil {
+			glog.Errorf("GetSignedMapRoot(%v): %v", d.MapID, err)
+			return nil, status.Errorf(codes.Internal, "Cannot fetch latest map revision")
+		}
+		revision = root.GetMapRoot().GetMapRevision()
+	}
+
+	_, priv := d.VRFFor(in.GetAppId())
+	wrapped := []proto.Message{priv}
+	if _, priv, ok := d.PrevVRFFor(in.GetAppId(), time.Now()); ok {
+		wrapped = append(wrapped, priv)
+	}
+	for _, w := range wrapped {
+		vrfPriv, err := factory.NewFromWrappedKey(ctx, w)
+		if err != nil {
+			return nil, fmt.Errorf("factory.NewFromWrappedKey(): %w", err)
+		}
+		index, _ := vrfPriv.Evaluate(vrf.UniqueID(in.GetUserId(), in.GetAppId()))
+		if err := s.purged.Purge(ctx, d.DomainID, index[:], revision); err != nil {
+			return nil, fmt.Errorf("purge.Purge(): %w", err)
+		}
 	}
+	glog.Infof("Purged committed data of user %v in app %v of domain %v through revision %v",
+		in.GetUserId(), in.GetAppId(), d.DomainID, revision)
 	return &google_protobuf.Empty{}, nil
 }
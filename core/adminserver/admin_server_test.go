@@ -16,25 +16,88 @@ package adminserver
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
 	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/migration"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/keytransparency/core/trillianpool"
 	"github.com/google/trillian"
-	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/storage/testdb"
 	"github.com/google/trillian/testonly/integration"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
 	_ "github.com/google/trillian/merkle/coniks"    // Register hasher
 	_ "github.com/google/trillian/merkle/objhasher" // Register hasher
 )
 
-func vrfKeyGen(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
-	return der.NewProtoFromSpec(spec)
+func TestNewOptions(t *testing.T) {
+	tlog := fake.NewTrillianLogClient()
+	// admin is never called by New.
+	admin := struct{ trillian.TrillianAdminClient }{}
+	valid := func() Options {
+		return Options{
+			Log:      tlog,
+			Map:      fake.NewTrillianMapClient(),
+			LogAdmin: admin,
+			MapAdmin: admin,
+			Domains:  fake.NewDomainStorage(),
+			Purged:   fake.NewPurgeStorage(),
+			Audits:   fake.NewAuditStorage(),
+		}
+	}
+	for _, tc := range []struct {
+		desc    string
+		edit    func(o *Options)
+		wantErr bool
+	}{
+		{desc: "valid", edit: func(o *Options) {}},
+		{desc: "no log", edit: func(o *Options) { o.Log = nil }, wantErr: true},
+		{desc: "no map", edit: func(o *Options) { o.Map = nil }, wantErr: true},
+		{desc: "no log admin", edit: func(o *Options) { o.LogAdmin = nil }, wantErr: true},
+		{desc: "no map admin", edit: func(o *Options) { o.MapAdmin = nil }, wantErr: true},
+		{desc: "no domains", edit: func(o *Options) { o.Domains = nil }, wantErr: true},
+		{desc: "no purged", edit: func(o *Options) { o.Purged = nil }, wantErr: true},
+		{desc: "no audits", edit: func(o *Options) { o.Audits = nil }, wantErr: true},
+	} {
+		opts := valid()
+		tc.edit(&opts)
+		svr, err := New(opts)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: New(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && svr.keygen == nil {
+			t.Errorf("%v: New() did not default KeyGen", tc.desc)
+		}
+		if err == nil && svr.retention == 0 {
+			t.Errorf("%v: New() did not default DeleteRetention", tc.desc)
+		}
+		if err == nil && svr.watchInterval == 0 {
+			t.Errorf("%v: New() did not default WatchInterval", tc.desc)
+		}
+		if err == nil && svr.trees == nil {
+			t.Errorf("%v: New() did not default TreeCacheTTL", tc.desc)
+		}
+	}
 }
 
 func TestCreateRead(t *testing.T) {
@@ -52,7 +115,18 @@ func TestCreateRead(t *testing.T) {
 	}
 	tlog := fake.NewTrillianLogClient()
 
-	svr := New(tlog, mapEnv.Map, mapEnv.Admin, mapEnv.Admin, storage, vrfKeyGen)
+	svr, err := New(Options{
+		Log:      tlog,
+		Map:      mapEnv.Map,
+		LogAdmin: mapEnv.Admin,
+		MapAdmin: mapEnv.Admin,
+		Domains:  storage,
+		Purged:   fake.NewPurgeStorage(),
+		Audits:   fake.NewAuditStorage(),
+	})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
 
 	for _, tc := range []struct {
 		domainID                 string
@@ -64,14 +138,31 @@ func TestCreateRead(t *testing.T) {
 			maxInterval: 5 * time.Second,
 		},
 	} {
-		_, err := svr.CreateDomain(ctx, &pb.CreateDomainRequest{
+		req := &pb.CreateDomainRequest{
 			DomainId:    tc.domainID,
 			MinInterval: ptypes.DurationProto(tc.minInterval),
 			MaxInterval: ptypes.DurationProto(tc.maxInterval),
-		})
+		}
+		created, err := svr.CreateDomain(ctx, req)
 		if err != nil {
 			t.Fatalf("CreateDomain(): %v", err)
 		}
+		// Retries return the existing domain.
+		retried, err := svr.CreateDomain(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateDomain(retry): %v", err)
+		}
+		if got, want := retried.Log.TreeId, created.Log.TreeId; got != want {
+			t.Errorf("CreateDomain(retry).Log.TreeId: %v, want %v", got, want)
+		}
+		if got, want := retried.Map.TreeId, created.Map.TreeId; got != want {
+			t.Errorf("CreateDomain(retry).Map.TreeId: %v, want %v", got, want)
+		}
+		conflict := *req
+		conflict.MaxInterval = ptypes.DurationProto(2 * tc.maxInterval)
+		if _, err := svr.CreateDomain(ctx, &conflict); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("CreateDomain(different settings): %v, want %v", err, codes.AlreadyExists)
+		}
 		domain, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: tc.domainID})
 		if err != nil {
 			t.Fatalf("GetDomain(): %v", err)
@@ -84,3 +175,808 @@ func TestCreateRead(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateExistingDomain(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "existing")
+
+	for _, tc := range []struct {
+		desc   string
+		modify func(*pb.CreateDomainRequest)
+		want   codes.Code
+	}{
+		{desc: "same", modify: func(*pb.CreateDomainRequest) {}, want: codes.OK},
+		{desc: "same trees", modify: func(r *pb.CreateDomainRequest) { r.LogId, r.MapId = d.LogID, d.MapID }, want: codes.OK},
+		{desc: "interval", modify: func(r *pb.CreateDomainRequest) { r.MaxInterval = ptypes.DurationProto(time.Hour) }, want: codes.AlreadyExists},
+		{desc: "labels", modify: func(r *pb.CreateDomainRequest) { r.Labels = map[string]string{"env": "prod"} }, want: codes.AlreadyExists},
+		{desc: "log id", modify: func(r *pb.CreateDomainRequest) { r.LogId, r.MapId = 3, d.MapID }, want: codes.AlreadyExists},
+		{desc: "map id", modify: func(r *pb.CreateDomainRequest) { r.LogId, r.MapId = d.LogID, 3 }, want: codes.AlreadyExists},
+		{desc: "log hash strategy", modify: func(r *pb.CreateDomainRequest) {
+			r.LogSpec = &pb.TreeSpec{HashStrategy: trillian.HashStrategy_RFC6962_SHA256}
+		}, want: codes.AlreadyExists},
+		{desc: "map max root duration", modify: func(r *pb.CreateDomainRequest) {
+			r.MapSpec = &pb.TreeSpec{MaxRootDuration: ptypes.DurationProto(time.Hour)}
+		}, want: codes.AlreadyExists},
+	} {
+		req := &pb.CreateDomainRequest{
+			DomainId:    d.DomainID,
+			MinInterval: ptypes.DurationProto(d.MinInterval),
+			MaxInterval: ptypes.DurationProto(d.MaxInterval),
+		}
+		tc.modify(req)
+		got, err := svr.CreateDomain(ctx, req)
+		if status.Code(err) != tc.want {
+			t.Errorf("%v: CreateDomain(): %v, want %v", tc.desc, err, tc.want)
+			continue
+		}
+		if err == nil && got.GetLog().GetTreeId() != d.LogID {
+			t.Errorf("%v: CreateDomain().Log.TreeId: %v, want %v", tc.desc, got.GetLog().GetTreeId(), d.LogID)
+		}
+	}
+}
+
+func TestFindTree(t *testing.T) {
+	ctx := context.Background()
+	tree := proto.Clone(logArgs.Tree).(*trillian.Tree)
+	tree.TreeId = 1
+	tree.Description = "KT domain d's SMH Log"
+	admin := &treeAdmin{trees: map[int64]*trillian.Tree{1: tree}}
+	svr := &Server{}
+
+	for _, tc := range []struct {
+		desc   string
+		modify func(*trillian.Tree)
+		want   bool
+	}{
+		{desc: "same", modify: func(*trillian.Tree) {}, want: true},
+		{desc: "description", modify: func(w *trillian.Tree) { w.Description = "KT domain e's SMH Log" }},
+		{desc: "hash strategy", modify: func(w *trillian.Tree) { w.HashStrategy = trillian.HashStrategy_RFC6962_SHA256 }},
+		{desc: "signature algorithm", modify: func(w *trillian.Tree) { w.SignatureAlgorithm = sigpb.DigitallySigned_RSA }},
+		{desc: "max root duration", modify: func(w *trillian.Tree) { w.MaxRootDuration = ptypes.DurationProto(time.Hour) }},
+	} {
+		want := proto.Clone(tree).(*trillian.Tree)
+		want.TreeId = 0
+		tc.modify(want)
+		got, err := svr.findTree(ctx, admin, want)
+		if err != nil {
+			t.Fatalf("%v: findTree(): %v", tc.desc, err)
+		}
+		if (got != nil) != tc.want {
+			t.Errorf("%v: findTree(): %v, want found %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestCheckTree(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		tree     *trillian.Tree
+		treeType trillian.TreeType
+		want     codes.Code
+	}{
+		{
+			desc:     "log",
+			tree:     logArgs.Tree,
+			treeType: trillian.TreeType_LOG,
+			want:     codes.OK,
+		},
+		{
+			desc:     "map",
+			tree:     mapArgs.Tree,
+			treeType: trillian.TreeType_MAP,
+			want:     codes.OK,
+		},
+		{
+			desc:     "wrong type",
+			tree:     mapArgs.Tree,
+			treeType: trillian.TreeType_LOG,
+			want:     codes.InvalidArgument,
+		},
+		{
+			desc: "frozen",
+			tree: &trillian.Tree{
+				TreeType:     trillian.TreeType_LOG,
+				TreeState:    trillian.TreeState_FROZEN,
+				HashStrategy: trillian.HashStrategy_RFC6962_SHA256,
+			},
+			treeType: trillian.TreeType_LOG,
+			want:     codes.FailedPrecondition,
+		},
+		{
+			desc: "unsupported hash strategy",
+			tree: &trillian.Tree{
+				TreeType:     trillian.TreeType_MAP,
+				TreeState:    trillian.TreeState_ACTIVE,
+				HashStrategy: trillian.HashStrategy_RFC6962_SHA256,
+			},
+			treeType: trillian.TreeType_MAP,
+			want:     codes.InvalidArgument,
+		},
+	} {
+		if got := status.Code(checkTree(tc.tree, tc.treeType)); got != tc.want {
+			t.Errorf("%v: checkTree(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestSetKeyPolicy(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	ecdsaOnly := []sigpb.DigitallySigned_SignatureAlgorithm{sigpb.DigitallySigned_ECDSA}
+	for _, tc := range []struct {
+		desc    string
+		policy  *pb.KeyPolicy
+		wantErr codes.Code
+	}{
+		{desc: "max keys", policy: &pb.KeyPolicy{MaxKeys: 3}, wantErr: codes.OK},
+		{desc: "algorithms", policy: &pb.KeyPolicy{AllowedAlgorithms: ecdsaOnly}, wantErr: codes.OK},
+		{desc: "clear", policy: nil, wantErr: codes.OK},
+		{desc: "negative max keys", policy: &pb.KeyPolicy{MaxKeys: -1}, wantErr: codes.InvalidArgument},
+		{desc: "unsupported algorithm", policy: &pb.KeyPolicy{
+			AllowedAlgorithms: []sigpb.DigitallySigned_SignatureAlgorithm{sigpb.DigitallySigned_ANONYMOUS},
+		}, wantErr: codes.InvalidArgument},
+	} {
+		got, err := svr.SetKeyPolicy(ctx, &pb.SetKeyPolicyRequest{DomainId: d.DomainID, KeyPolicy: tc.policy})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: SetKeyPolicy(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !proto.Equal(got.GetKeyPolicy(), tc.policy) {
+			t.Errorf("%v: KeyPolicy: %v, want %v", tc.desc, got.GetKeyPolicy(), tc.policy)
+		}
+		// The map has no revisions yet, so the policy applies from
+		// revision 1 on.
+		h := got.GetKeyPolicyHistory()
+		if len(h) == 0 || h[len(h)-1].GetRevision() != 1 || !proto.Equal(h[len(h)-1].GetKeyPolicy(), tc.policy) {
+			t.Errorf("%v: KeyPolicyHistory: %v, want last change {1 %v}", tc.desc, h, tc.policy)
+		}
+	}
+}
+
+func TestSetEndorsementPolicy(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	pub := publicKeyPB(t, key.Public())
+	for _, tc := range []struct {
+		desc    string
+		policy  *pb.EndorsementPolicy
+		wantErr codes.Code
+	}{
+		{desc: "quorum", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{pub}, Quorum: 1}, wantErr: codes.OK},
+		{desc: "clear", policy: nil, wantErr: codes.OK},
+		{desc: "quorum too large", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{pub}, Quorum: 2}, wantErr: codes.InvalidArgument},
+		{desc: "bad key", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{{Der: []byte("bad")}}, Quorum: 1}, wantErr: codes.InvalidArgument},
+	} {
+		got, err := svr.SetEndorsementPolicy(ctx, &pb.SetEndorsementPolicyRequest{DomainId: d.DomainID, EndorsementPolicy: tc.policy})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: SetEndorsementPolicy(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !proto.Equal(got.GetEndorsementPolicy(), tc.policy) {
+			t.Errorf("%v: EndorsementPolicy: %v, want %v", tc.desc, got.GetEndorsementPolicy(), tc.policy)
+		}
+	}
+}
+
+func TestFreezeDomain(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	for _, tc := range []struct {
+		desc   string
+		freeze bool
+	}{
+		{desc: "freeze", freeze: true},
+		{desc: "freeze again", freeze: true},
+		{desc: "unfreeze", freeze: false},
+	} {
+		var got *pb.Domain
+		var err error
+		if tc.freeze {
+			got, err = svr.FreezeDomain(ctx, &pb.FreezeDomainRequest{DomainId: d.DomainID})
+		} else {
+			got, err = svr.UnfreezeDomain(ctx, &pb.UnfreezeDomainRequest{DomainId: d.DomainID})
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", tc.desc, err)
+		}
+		if got.GetFrozen() != tc.freeze {
+			t.Errorf("%v: Frozen: %v, want %v", tc.desc, got.GetFrozen(), tc.freeze)
+		}
+	}
+	if _, err := svr.FreezeDomain(ctx, &pb.FreezeDomainRequest{DomainId: "unknown"}); err == nil {
+		t.Errorf("FreezeDomain(unknown): nil, want error")
+	}
+}
+
+func TestDeleteRetention(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc      string
+		retention time.Duration
+		age       time.Duration
+		wantErr   bool
+	}{
+		{desc: "within window", retention: time.Hour, age: 30 * time.Minute},
+		{desc: "expired", retention: time.Hour, age: 2 * time.Hour, wantErr: true},
+		{desc: "forever", retention: -1, age: 24 * 365 * time.Hour},
+	} {
+		svr, d := bundleEnv(t, "domain")
+		svr.retention = tc.retention
+		if _, err := svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: d.DomainID}); err != nil {
+			t.Fatalf("%v: DeleteDomain(): %v", tc.desc, err)
+		}
+		stored, err := svr.domains.Read(ctx, d.DomainID, true)
+		if err != nil {
+			t.Fatalf("%v: Read(): %v", tc.desc, err)
+		}
+		// Deleting again must not extend the retention window.
+		stored.DeleteTime = stored.DeleteTime.Add(-tc.age)
+		if _, err := svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: d.DomainID}); err != nil {
+			t.Fatalf("%v: DeleteDomain(): %v", tc.desc, err)
+		}
+
+		got, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID, ShowDeleted: true})
+		if err != nil {
+			t.Fatalf("%v: GetDomain(): %v", tc.desc, err)
+		}
+		deleteTime, err := ptypes.Timestamp(got.GetDeleteTime())
+		if err != nil {
+			t.Fatalf("%v: DeleteTime: %v", tc.desc, err)
+		}
+		if tc.retention < 0 {
+			if got.GetHardDeleteTime() != nil {
+				t.Errorf("%v: HardDeleteTime: %v, want unset", tc.desc, got.GetHardDeleteTime())
+			}
+		} else {
+			hardDeleteTime, err := ptypes.Timestamp(got.GetHardDeleteTime())
+			if err != nil {
+				t.Fatalf("%v: HardDeleteTime: %v", tc.desc, err)
+			}
+			if got, want := hardDeleteTime.Sub(deleteTime), svr.retention; got != want {
+				t.Errorf("%v: HardDeleteTime - DeleteTime: %v, want %v", tc.desc, got, want)
+			}
+		}
+
+		want := codes.OK
+		if tc.wantErr {
+			want = codes.FailedPrecondition
+		}
+		if _, err := svr.UndeleteDomain(ctx, &pb.UndeleteDomainRequest{DomainId: d.DomainID}); status.Code(err) != want {
+			t.Errorf("%v: UndeleteDomain(): %v, want %v", tc.desc, err, want)
+		}
+	}
+}
+
+func TestDeleteTrees(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	for _, tc := range []struct {
+		desc   string
+		delete bool
+	}{
+		{desc: "delete", delete: true},
+		{desc: "delete again", delete: true},
+		{desc: "undelete", delete: false},
+		{desc: "undelete again", delete: false},
+	} {
+		var err error
+		if tc.delete {
+			_, err = svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: d.DomainID})
+		} else {
+			_, err = svr.UndeleteDomain(ctx, &pb.UndeleteDomainRequest{DomainId: d.DomainID})
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", tc.desc, err)
+		}
+		got, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID, ShowDeleted: true})
+		if err != nil {
+			t.Fatalf("%v: GetDomain(): %v", tc.desc, err)
+		}
+		if got.GetTreesDeleted() != tc.delete {
+			t.Errorf("%v: TreesDeleted: %v, want %v", tc.desc, got.GetTreesDeleted(), tc.delete)
+		}
+		if got.GetLog().GetDeleted() != tc.delete || got.GetMap().GetDeleted() != tc.delete {
+			t.Errorf("%v: log deleted: %v, map deleted: %v, want %v", tc.desc,
+				got.GetLog().GetDeleted(), got.GetMap().GetDeleted(), tc.delete)
+		}
+	}
+}
+
+func TestVRFAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	svr, _ := bundleEnv(t, "p256")
+	for _, alg := range []pb.VrfAlgorithm{pb.VrfAlgorithm_P256, pb.VrfAlgorithm_ED25519} {
+		wrapped, pub, err := svr.newVRF(ctx, alg)
+		if err != nil {
+			t.Fatalf("newVRF(%v): %v", alg, err)
+		}
+		if got := vrfAlgorithm(pub); got != alg {
+			t.Errorf("newVRF(%v): key algorithm %v", alg, got)
+		}
+		k, err := ptypes.MarshalAny(wrapped)
+		if err != nil {
+			t.Fatalf("MarshalAny(): %v", err)
+		}
+		if _, imported, err := svr.importVRF(ctx, k); err != nil || !proto.Equal(imported, pub) {
+			t.Errorf("importVRF(%v): %v, %v, want %v", alg, imported, err, pub)
+		}
+
+		// App VRFs use the algorithm of the domain.
+		domainID := alg.String()
+		if err := svr.domains.Write(ctx, &domain.Domain{
+			DomainID: domainID,
+			LogID:    1,
+			MapID:    2,
+			VRF:      pub,
+			VRFPriv:  wrapped,
+		}); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+		d, err := svr.AddAppVRF(ctx, &pb.AddAppVRFRequest{DomainId: domainID, AppId: "app"})
+		if err != nil {
+			t.Fatalf("AddAppVRF(): %v", err)
+		}
+		if got := d.GetVrfAlgorithm(); got != alg {
+			t.Errorf("VrfAlgorithm: %v, want %v", got, alg)
+		}
+		if got := vrfAlgorithm(d.GetAppVrfs()["app"]); got != alg {
+			t.Errorf("App VRF algorithm: %v, want %v", got, alg)
+		}
+	}
+}
+
+func TestAddAppVRF(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	if err := svr.apps.Add(ctx, d.DomainID, "alice", "used"); err != nil {
+		t.Fatalf("Add(): %v", err)
+	}
+	for _, tc := range []struct {
+		appID    string
+		wantCode codes.Code
+	}{
+		{appID: "", wantCode: codes.InvalidArgument},
+		{appID: "used", wantCode: codes.FailedPrecondition},
+		{appID: "new", wantCode: codes.OK},
+		{appID: "new", wantCode: codes.AlreadyExists},
+	} {
+		_, err := svr.AddAppVRF(ctx, &pb.AddAppVRFRequest{DomainId: d.DomainID, AppId: tc.appID})
+		if got := status.Code(err); got != tc.wantCode {
+			t.Errorf("AddAppVRF(%q): %v, want code %v", tc.appID, err, tc.wantCode)
+		}
+	}
+}
+
+// hashedMap is a map whose root hash changes with every revision.
+type hashedMap struct {
+	trillian.TrillianMapClient
+	revision int64
+}
+
+func (m *hashedMap) GetSignedMapRoot(ctx context.Context, in *trillian.GetSignedMapRootRequest, opts ...grpc.CallOption) (*trillian.GetSignedMapRootResponse, error) {
+	return m.GetSignedMapRootByRevision(ctx, &trillian.GetSignedMapRootByRevisionRequest{MapId: in.MapId, Revision: m.revision}, opts...)
+}
+
+func (m *hashedMap) GetSignedMapRootByRevision(ctx context.Context, in *trillian.GetSignedMapRootByRevisionRequest, opts ...grpc.CallOption) (*trillian.GetSignedMapRootResponse, error) {
+	return &trillian.GetSignedMapRootResponse{MapRoot: &trillian.SignedMapRoot{
+		MapRevision: in.Revision,
+		RootHash:    []byte{byte(in.Revision)},
+	}}, nil
+}
+
+func TestAddAppVRFIncompleteIndex(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc         string
+		revision     int64
+		wantCode     codes.Code
+		wantComplete bool
+	}{
+		{desc: "empty map", revision: 0, wantCode: codes.OK, wantComplete: true},
+		{desc: "entries before indexing", revision: 3, wantCode: codes.FailedPrecondition},
+	} {
+		svr, d := bundleEnv(t, "domain")
+		svr.tmap = &hashedMap{revision: tc.revision}
+		_, err := svr.AddAppVRF(ctx, &pb.AddAppVRFRequest{DomainId: d.DomainID, AppId: "app"})
+		if got := status.Code(err); got != tc.wantCode {
+			t.Errorf("%v: AddAppVRF(): %v, want code %v", tc.desc, err, tc.wantCode)
+		}
+		complete, err := svr.apps.Complete(ctx, d.DomainID)
+		if err != nil {
+			t.Fatalf("Complete(): %v", err)
+		}
+		if complete != tc.wantComplete {
+			t.Errorf("%v: Complete(): %v, want %v", tc.desc, complete, tc.wantComplete)
+		}
+	}
+}
+
+func TestCreateDomainValidateOnly(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc      string
+		domainID  string
+		min, max  time.Duration
+		logID     int64
+		mapID     int64
+		backend   string
+		listErr   error
+		wantCode  codes.Code
+		wantLogID int64
+	}{
+		{desc: "new", domainID: "new", min: time.Second, max: time.Minute},
+		{desc: "existing trees", domainID: "new", min: time.Second, max: time.Minute, logID: 3, mapID: 4, wantLogID: 3},
+		{desc: "existing domain", domainID: "domain", min: time.Second, max: time.Minute, wantLogID: 1},
+		{desc: "slash", domainID: "a/b", wantCode: codes.InvalidArgument},
+		{desc: "too long", domainID: strings.Repeat("a", 41), wantCode: codes.InvalidArgument},
+		{desc: "missing id", wantCode: codes.InvalidArgument},
+		{desc: "negative", domainID: "new", min: -time.Second, wantCode: codes.InvalidArgument},
+		{desc: "min above max", domainID: "new", min: time.Minute, max: time.Second, wantCode: codes.InvalidArgument},
+		{desc: "unreachable", domainID: "new", listErr: errors.New("unavailable"), wantCode: codes.Unavailable},
+		{desc: "log backend", domainID: "new", min: time.Second, max: time.Minute, logID: 5, mapID: 4, backend: "other", wantLogID: 5},
+		{desc: "log backend without trees", domainID: "new", min: time.Second, max: time.Minute, backend: "other", wantCode: codes.InvalidArgument},
+		{desc: "unknown log backend", domainID: "new", min: time.Second, max: time.Minute, logID: 5, mapID: 4, backend: "missing", wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			svr, _ := bundleEnv(t, "domain")
+			logAdmin, mapAdmin := svr.logAdmin.(*treeAdmin), svr.mapAdmin.(*treeAdmin)
+			logAdmin.trees[3] = &trillian.Tree{TreeId: 3, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE,
+				HashStrategy: trillian.HashStrategy_OBJECT_RFC6962_SHA256}
+			mapAdmin.trees[4] = &trillian.Tree{TreeId: 4, TreeType: trillian.TreeType_MAP, TreeState: trillian.TreeState_ACTIVE,
+				HashStrategy: trillian.HashStrategy_CONIKS_SHA512_256}
+			logAdmin.listErr = tc.listErr
+			svr.logs = smhlog.Backends{"other": {Trees: map[int64]*trillian.Tree{
+				5: {TreeId: 5, TreeType: trillian.TreeType_LOG, TreeState: trillian.TreeState_ACTIVE,
+					HashStrategy: trillian.HashStrategy_OBJECT_RFC6962_SHA256},
+			}}}
+
+			got, err := svr.CreateDomain(ctx, &pb.CreateDomainRequest{
+				DomainId:     tc.domainID,
+				MinInterval:  ptypes.DurationProto(tc.min),
+				MaxInterval:  ptypes.DurationProto(tc.max),
+				LogId:        tc.logID,
+				MapId:        tc.mapID,
+				LogBackend:   tc.backend,
+				ValidateOnly: true,
+			})
+			if status.Code(err) != tc.wantCode {
+				t.Fatalf("CreateDomain(): %v, want %v", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if got.GetDomainId() != tc.domainID || got.GetLog().GetTreeId() != tc.wantLogID {
+				t.Errorf("CreateDomain(): domain %v with log %v, want %v with log %v",
+					got.GetDomainId(), got.GetLog().GetTreeId(), tc.domainID, tc.wantLogID)
+			}
+			if tc.domainID != "domain" {
+				if _, err := svr.domains.Read(ctx, tc.domainID, true); err == nil {
+					t.Errorf("CreateDomain(validate_only) wrote domain %v", tc.domainID)
+				}
+			}
+			if entries, err := svr.audits.List(ctx, tc.domainID, 0, 10); err != nil || len(entries) != 0 {
+				t.Errorf("CreateDomain(validate_only) was audited: %v, %v", entries, err)
+			}
+		})
+	}
+}
+
+// epochForcer records forced epochs and fails with err.
+type epochForcer struct {
+	forced []string
+	err    error
+}
+
+func (f *epochForcer) ForceEpoch(ctx context.Context, domainID string) error {
+	f.forced = append(f.forced, domainID)
+	return f.err
+}
+
+func TestForceEpoch(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	for _, tc := range []struct {
+		desc       string
+		epochs     EpochForcer
+		domainID   string
+		wantErr    codes.Code
+		wantForced int
+	}{
+		{desc: "not configured", epochs: nil, domainID: d.DomainID, wantErr: codes.FailedPrecondition},
+		{desc: "forced", epochs: &epochForcer{}, domainID: d.DomainID, wantErr: codes.OK, wantForced: 1},
+		{desc: "frozen", epochs: &epochForcer{err: domain.ErrFrozen}, domainID: d.DomainID, wantErr: codes.FailedPrecondition, wantForced: 1},
+		{desc: "sequencer error", epochs: &epochForcer{err: errors.New("map unavailable")}, domainID: d.DomainID, wantErr: codes.Unavailable, wantForced: 1},
+		{desc: "unknown domain", epochs: &epochForcer{}, domainID: "unknown", wantErr: codes.Unknown},
+	} {
+		svr.epochs = tc.epochs
+		_, err := svr.ForceEpoch(ctx, &pb.ForceEpochRequest{DomainId: tc.domainID})
+		if got := status.Code(err); got != tc.wantErr {
+			t.Errorf("%v: ForceEpoch(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+		if f, ok := tc.epochs.(*epochForcer); ok && len(f.forced) != tc.wantForced {
+			t.Errorf("%v: forced %v epochs, want %v", tc.desc, len(f.forced), tc.wantForced)
+		}
+	}
+}
+
+// intervalUpdater records updated domains and fails with err.
+type intervalUpdater struct {
+	updated []string
+	err     error
+}
+
+func (u *intervalUpdater) UpdateIntervals(ctx context.Context, domainID string) error {
+	u.updated = append(u.updated, domainID)
+	return u.err
+}
+
+func TestSetDomainIntervals(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	for _, tc := range []struct {
+		desc        string
+		intervals   IntervalUpdater
+		min, max    time.Duration
+		wantErr     codes.Code
+		wantUpdated int
+	}{
+		{desc: "not notified", min: time.Second, max: time.Minute, wantErr: codes.OK},
+		{desc: "notified", intervals: &intervalUpdater{}, min: 2 * time.Second, max: time.Hour, wantErr: codes.OK, wantUpdated: 1},
+		{desc: "min after max", intervals: &intervalUpdater{}, min: time.Hour, max: time.Second, wantErr: codes.InvalidArgument},
+		{desc: "negative", intervals: &intervalUpdater{}, min: -time.Second, max: time.Second, wantErr: codes.InvalidArgument},
+		{desc: "sequencer error", intervals: &intervalUpdater{err: errors.New("receiver hung")}, min: 3 * time.Second, max: time.Hour, wantErr: codes.Unavailable, wantUpdated: 1},
+	} {
+		svr.intervals = tc.intervals
+		got, err := svr.SetDomainIntervals(ctx, &pb.SetDomainIntervalsRequest{
+			DomainId:    d.DomainID,
+			MinInterval: ptypes.DurationProto(tc.min),
+			MaxInterval: ptypes.DurationProto(tc.max),
+		})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: SetDomainIntervals(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if u, ok := tc.intervals.(*intervalUpdater); ok && len(u.updated) != tc.wantUpdated {
+			t.Errorf("%v: updated %v times, want %v", tc.desc, len(u.updated), tc.wantUpdated)
+		}
+		if err != nil {
+			continue
+		}
+		if min, _ := ptypes.Duration(got.GetMinInterval()); min != tc.min {
+			t.Errorf("%v: MinInterval: %v, want %v", tc.desc, min, tc.min)
+		}
+		if max, _ := ptypes.Duration(got.GetMaxInterval()); max != tc.max {
+			t.Errorf("%v: MaxInterval: %v, want %v", tc.desc, max, tc.max)
+		}
+	}
+}
+
+func TestAnnounceDomainMigration(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	mapSigner := newBundleSigner(t)
+	svr.mapAdmin.(*treeAdmin).trees[2].PublicKey = publicKeyPB(t, mapSigner.Public())
+	m := &pb.DomainMigration{
+		DomainId:   d.DomainID,
+		Revision:   10,
+		NewAddress: "new:443",
+		NewDomain:  &pb.Domain{DomainId: d.DomainID},
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		signer  *tcrypto.Signer
+		wantErr codes.Code
+	}{
+		{desc: "map key", signer: mapSigner},
+		{desc: "other key", signer: newBundleSigner(t), wantErr: codes.InvalidArgument},
+		{desc: "withdraw"},
+	} {
+		var signed *pb.SignedDomainMigration
+		if tc.signer != nil {
+			var err error
+			if signed, err = migration.Sign(tc.signer, m); err != nil {
+				t.Fatalf("%v: Sign(): %v", tc.desc, err)
+			}
+		}
+		got, err := svr.AnnounceDomainMigration(ctx, &pb.AnnounceDomainMigrationRequest{
+			DomainId:  d.DomainID,
+			Migration: signed,
+		})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: AnnounceDomainMigration(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !proto.Equal(got.GetMigration(), signed) {
+			t.Errorf("%v: Migration: %v, want %v", tc.desc, got.GetMigration(), signed)
+		}
+	}
+}
+
+func TestRotateTreeKeys(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	for _, tree := range []*trillian.Tree{
+		svr.logAdmin.(*treeAdmin).trees[1],
+		svr.mapAdmin.(*treeAdmin).trees[2],
+	} {
+		tree.SignatureAlgorithm = sigpb.DigitallySigned_ECDSA
+	}
+	oldLogKey := svr.logAdmin.(*treeAdmin).trees[1].PublicKey
+	oldMapKey := svr.mapAdmin.(*treeAdmin).trees[2].PublicKey
+
+	if _, err := svr.RotateTreeKeys(ctx, &pb.RotateTreeKeysRequest{DomainId: d.DomainID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RotateTreeKeys(no trees): %v, want %v", err, codes.InvalidArgument)
+	}
+	got, err := svr.RotateTreeKeys(ctx, &pb.RotateTreeKeysRequest{
+		DomainId:  d.DomainID,
+		RotateLog: true,
+		RotateMap: true,
+		Overlap:   ptypes.DurationProto(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("RotateTreeKeys(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc     string
+		tree     *trillian.Tree
+		rotation *pb.TreeKeyRotation
+		oldKey   *keyspb.PublicKey
+	}{
+		{desc: "log", tree: got.GetLog(), rotation: got.GetLogKeyRotation(), oldKey: oldLogKey},
+		{desc: "map", tree: got.GetMap(), rotation: got.GetMapKeyRotation(), oldKey: oldMapKey},
+	} {
+		if !proto.Equal(tc.rotation.GetPreviousKey(), tc.oldKey) {
+			t.Errorf("%v: PreviousKey: %v, want %v", tc.desc, tc.rotation.GetPreviousKey(), tc.oldKey)
+		}
+		if proto.Equal(tc.tree.GetPublicKey(), tc.oldKey) || !proto.Equal(tc.tree.GetPublicKey(), tc.rotation.GetPublicKey()) {
+			t.Errorf("%v: PublicKey: %v, want the new key %v", tc.desc, tc.tree.GetPublicKey(), tc.rotation.GetPublicKey())
+		}
+		if tc.tree.GetPrivateKey() == nil {
+			t.Errorf("%v: PrivateKey: nil, want the new key", tc.desc)
+		}
+		rotateTime, _ := ptypes.Timestamp(tc.rotation.GetRotateTime())
+		overlapEnd, _ := ptypes.Timestamp(tc.rotation.GetOverlapEnd())
+		if got := overlapEnd.Sub(rotateTime); got != time.Hour {
+			t.Errorf("%v: overlap: %v, want %v", tc.desc, got, time.Hour)
+		}
+	}
+
+	// The previous keys are still needed during the overlap.
+	if _, err := svr.RotateTreeKeys(ctx, &pb.RotateTreeKeysRequest{
+		DomainId:  d.DomainID,
+		RotateMap: true,
+	}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("RotateTreeKeys(during overlap): %v, want %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestTrillianBackend(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+
+	for _, tc := range []struct {
+		desc       string
+		backend    *pb.TrillianBackend
+		logBackend string
+		wantErr    codes.Code
+	}{
+		{desc: "no pool", backend: &pb.TrillianBackend{MapAddress: "cluster2:8090"}, wantErr: codes.FailedPrecondition},
+		{desc: "log address and log backend", backend: &pb.TrillianBackend{LogAddress: "cluster2:8090"}, logBackend: "other", wantErr: codes.InvalidArgument},
+	} {
+		_, err := svr.CreateDomain(ctx, &pb.CreateDomainRequest{
+			DomainId:        "remote",
+			MinInterval:     ptypes.DurationProto(time.Second),
+			MaxInterval:     ptypes.DurationProto(time.Minute),
+			LogBackend:      tc.logBackend,
+			TrillianBackend: tc.backend,
+		})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: CreateDomain(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+
+	// Domains on other clusters are reached through the pool.
+	var dialed []string
+	svr.pool = trillianpool.New(func(addr string) (*grpc.ClientConn, error) {
+		dialed = append(dialed, addr)
+		return nil, errors.New("connection refused")
+	})
+	if err := svr.domains.Write(ctx, &domain.Domain{
+		DomainID:   "remote",
+		LogID:      1,
+		MapID:      2,
+		VRF:        d.VRF,
+		VRFPriv:    d.VRFPriv,
+		MapAddress: "cluster2:8090",
+	}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if _, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: "remote"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetDomain(remote): %v, want %v", err, codes.Unavailable)
+	}
+	if want := []string{"cluster2:8090"}; !reflect.DeepEqual(dialed, want) {
+		t.Errorf("dialed: %v, want %v", dialed, want)
+	}
+	got, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID})
+	if err != nil {
+		t.Fatalf("GetDomain(): %v", err)
+	}
+	if got.GetTrillianBackend() != nil {
+		t.Errorf("TrillianBackend: %v, want nil for the default cluster", got.GetTrillianBackend())
+	}
+}
+
+func TestDomainLabels(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "a")
+	if err := svr.domains.Write(ctx, &domain.Domain{
+		DomainID: "b",
+		LogID:    1,
+		MapID:    2,
+		VRF:      d.VRF,
+		VRFPriv:  d.VRFPriv,
+		Labels:   map[string]string{"env": "dev"},
+	}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		labels  map[string]string
+		paths   []string
+		wantErr codes.Code
+	}{
+		{desc: "labels", labels: map[string]string{"env": "prod", "team": "kt"}, paths: []string{"labels"}},
+		{desc: "invalid label", labels: map[string]string{"env": "p r o d"}, paths: []string{"labels"}, wantErr: codes.InvalidArgument},
+		{desc: "unknown path", paths: []string{"vrf"}, wantErr: codes.InvalidArgument},
+	} {
+		got, err := svr.UpdateDomain(ctx, &pb.UpdateDomainRequest{
+			DomainId:   d.DomainID,
+			Labels:     tc.labels,
+			UpdateMask: &field_mask.FieldMask{Paths: tc.paths},
+		})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: UpdateDomain(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got.GetLabels(), tc.labels) {
+			t.Errorf("%v: Labels: %v, want %v", tc.desc, got.GetLabels(), tc.labels)
+		}
+	}
+
+	for _, tc := range []struct {
+		selector string
+		want     []string
+		wantErr  codes.Code
+	}{
+		{selector: "", want: []string{"a", "b"}},
+		{selector: "env=prod", want: []string{"a"}},
+		{selector: "env!=prod", want: []string{"b"}},
+		{selector: "env,!team", want: []string{"b"}},
+		{selector: "region"},
+		{selector: "env="},
+		{selector: "=prod", wantErr: codes.InvalidArgument},
+	} {
+		resp, err := svr.ListDomains(ctx, &pb.ListDomainsRequest{LabelSelector: tc.selector})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("ListDomains(%q): %v, want %v", tc.selector, err, tc.wantErr)
+			continue
+		}
+		var got []string
+		for _, d := range resp.GetDomains() {
+			got = append(got, d.GetDomainId())
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ListDomains(%q): %v, want %v", tc.selector, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/audit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+const (
+	// defaultAuditPageSize is the number of audit entries returned when
+	// the request does not specify a page size.
+	defaultAuditPageSize = 100
+	// maxAuditPageSize is the largest page of audit entries returned.
+	maxAuditPageSize = 1000
+)
+
+// audit records an admin RPC before it is executed. RPCs that cannot be
+// recorded are rejected so that the audit log is complete.
+func (s *Server) audit(ctx context.Context, method, domainID string, req proto.Message) error {
+	hash, err := requestHash(req)
+	if err != nil {
+		glog.Errorf("requestHash(%v): %v", method, err)
+		return status.Errorf(codes.Internal, "Cannot hash request")
+	}
+	e := &audit.Entry{
+		DomainID:    domainID,
+		Method:      method,
+		Caller:      callerIdentity(ctx),
+		Time:        time.Now(),
+		RequestHash: hash,
+	}
+	if err := s.audits.Append(ctx, e); err != nil {
+		glog.Errorf("audit.Append(%v, %v): %v", domainID, method, err)
+		return status.Errorf(codes.Internal, "Cannot write audit log")
+	}
+	glog.Infof("Audit: %v called %v on domain %v", e.Caller, method, domainID)
+	return nil
+}
+
+// requestHash returns the SHA256 hash of the deterministic serialization of
+// req.
+func requestHash(req proto.Message) ([]byte, error) {
+	b := proto.NewBuffer(nil)
+	b.SetDeterministic(true)
+	if err := b.Marshal(req); err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(b.Bytes())
+	return h[:], nil
+}
+
+// callerIdentity returns the identity forwarded by the REST gateway, or
+// else the subject of the caller's verified TLS client certificate, or the
+// caller's network address if there is none.
+func callerIdentity(ctx context.Context) string {
+	if id, ok := ctx.Value(forwardedCaller{}).(string); ok {
+		return id
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "unknown"
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		if chains := tlsInfo.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			return chains[0][0].Subject.CommonName
+		}
+	}
+	return p.Addr.String()
+}
+
+// ListAuditEntries returns a page of the audit log of a domain.
+func (s *Server) ListAuditEntries(ctx context.Context, in *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	if in.GetStart() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Start is %v, want >= 0", in.GetStart())
+	}
+	pageSize := in.GetPageSize()
+	switch {
+	case pageSize < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "Page size is %v, want >= 0", pageSize)
+	case pageSize == 0:
+		pageSize = defaultAuditPageSize
+	case pageSize > maxAuditPageSize:
+		pageSize = maxAuditPageSize
+	}
+	entries, err := s.audits.List(ctx, in.GetDomainId(), in.GetStart(), pageSize)
+	if err != nil {
+		glog.Errorf("audit.List(%v): %v", in.GetDomainId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot read audit log")
+	}
+	resp := &pb.ListAuditEntriesResponse{
+		Entries: make([]*pb.AuditEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		ts, err := ptypes.TimestampProto(e.Time)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Invalid audit entry time: %v", err)
+		}
+		resp.Entries = append(resp.Entries, &pb.AuditEntry{
+			Sequence:    e.Sequence,
+			Method:      e.Method,
+			Caller:      e.Caller,
+			Time:        ts,
+			RequestHash: e.RequestHash,
+		})
+	}
+	if int32(len(entries)) == pageSize {
+		resp.NextStart = entries[len(entries)-1].Sequence + 1
+	}
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	domainID := "auditdomain"
+	svr, _ := bundleEnv(t, domainID)
+
+	if _, err := svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: domainID}); err != nil {
+		t.Fatalf("DeleteDomain(): %v", err)
+	}
+	if _, err := svr.UndeleteDomain(ctx, &pb.UndeleteDomainRequest{DomainId: domainID}); err != nil {
+		t.Fatalf("UndeleteDomain(): %v", err)
+	}
+	if _, err := svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: domainID}); err != nil {
+		t.Fatalf("DeleteDomain(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc      string
+		start     int64
+		pageSize  int32
+		want      []string
+		nextStart int64
+	}{
+		{desc: "all", start: 0, pageSize: 10, want: []string{"DeleteDomain", "UndeleteDomain", "DeleteDomain"}},
+		{desc: "first page", start: 0, pageSize: 2, want: []string{"DeleteDomain", "UndeleteDomain"}, nextStart: 2},
+		{desc: "second page", start: 2, pageSize: 2, want: []string{"DeleteDomain"}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := svr.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{
+				DomainId: domainID,
+				Start:    tc.start,
+				PageSize: tc.pageSize,
+			})
+			if err != nil {
+				t.Fatalf("ListAuditEntries(): %v", err)
+			}
+			if got, want := len(resp.GetEntries()), len(tc.want); got != want {
+				t.Fatalf("ListAuditEntries(): %v entries, want %v", got, want)
+			}
+			for i, e := range resp.GetEntries() {
+				if got, want := e.GetMethod(), tc.want[i]; got != want {
+					t.Errorf("Entries[%v].Method: %v, want %v", i, got, want)
+				}
+				if got, want := e.GetSequence(), tc.start+int64(i); got != want {
+					t.Errorf("Entries[%v].Sequence: %v, want %v", i, got, want)
+				}
+			}
+			if got, want := resp.GetNextStart(), tc.nextStart; got != want {
+				t.Errorf("ListAuditEntries().NextStart: %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	a, err := requestHash(&pb.DeleteDomainRequest{DomainId: "domain1"})
+	if err != nil {
+		t.Fatalf("requestHash(): %v", err)
+	}
+	b, err := requestHash(&pb.DeleteDomainRequest{DomainId: "domain1"})
+	if err != nil {
+		t.Fatalf("requestHash(): %v", err)
+	}
+	c, err := requestHash(&pb.DeleteDomainRequest{DomainId: "domain2"})
+	if err != nil {
+		t.Fatalf("requestHash(): %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("requestHash() of equal requests: %x != %x", a, b)
+	}
+	if bytes.Equal(a, c) {
+		t.Errorf("requestHash() of different requests: %x == %x", a, c)
+	}
+}
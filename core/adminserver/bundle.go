@@ -0,0 +1,311 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// ExportDomain returns the definition of a domain, including its wrapped VRF
+// private keys, signed by the server's bundle key.
+func (s *Server) ExportDomain(ctx context.Context, in *pb.ExportDomainRequest) (*pb.SignedDomainBundle, error) {
+	if err := s.audit(ctx, "ExportDomain", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	if s.bundleSigner == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Domain export is not configured")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.fetchDomain(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	exportTime, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	vrfPriv, err := ptypes.MarshalAny(d.VRFPriv)
+	if err != nil {
+		return nil, fmt.Errorf("MarshalAny(vrf): %w", err)
+	}
+	bundle := &pb.DomainBundle{
+		DomainId:      d.DomainID,
+		Log:           info.Log,
+		Map:           info.Map,
+		VrfPrivateKey: vrfPriv,
+		MinInterval:   info.MinInterval,
+		MaxInterval:   info.MaxInterval,
+		ExportTime:    exportTime,
+		KeyPolicy:     d.KeyPolicy,
+		Labels:        d.Labels,
+		LogBackend:    d.LogBackend,
+
+		TrillianBackend:  info.TrillianBackend,
+		KeyPolicyHistory: d.KeyPolicyHistory,
+	}
+	if len(d.AppVRFs) > 0 {
+		bundle.AppVrfPrivateKeys = make(map[string]*any.Any, len(d.AppVRFs))
+	}
+	for appID, a := range d.AppVRFs {
+		k, err := ptypes.MarshalAny(a.VRFPriv)
+		if err != nil {
+			return nil, fmt.Errorf("MarshalAny(vrf of app %v): %w", appID, err)
+		}
+		bundle.AppVrfPrivateKeys[appID] = k
+	}
+	// Expired previous keys cannot be used and are not exported.
+	if p := d.PrevVRF; p != nil && now.Before(p.Expiry) {
+		k, err := ptypes.MarshalAny(p.VRFPriv)
+		if err != nil {
+			return nil, fmt.Errorf("MarshalAny(previous vrf): %w", err)
+		}
+		bundle.PreviousVrfPrivateKey = k
+		bundle.PreviousVrfExpiry = info.PreviousVrfExpiry
+	}
+
+	data, err := proto.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.bundleSigner.Sign(data)
+	if err != nil {
+		glog.Errorf("Sign(bundle of %v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot sign bundle")
+	}
+	glog.Infof("Exported domain %v", d.DomainID)
+	return &pb.SignedDomainBundle{Bundle: data, Signature: sig}, nil
+}
+
+// ImportDomain creates a domain from a bundle produced by ExportDomain.
+//
+// The domain uses the trees named in the bundle unless CreateTrees is set.
+// Existing trees must have the same signing keys as the exported trees so that
+// clients that trust the exported domain continue to verify its roots.
+func (s *Server) ImportDomain(ctx context.Context, in *pb.ImportDomainRequest) (*pb.Domain, error) {
+	bundle, err := s.verifyBundle(in.GetBundle())
+	if err != nil {
+		return nil, err
+	}
+	domainID := bundle.GetDomainId()
+	if err := s.audit(ctx, "ImportDomain", domainID, in); err != nil {
+		return nil, err
+	}
+	if domainID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Bundle has no domain_id")
+	}
+	if _, err := s.domains.Read(ctx, domainID, true); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "Domain %v already exists", domainID)
+	}
+
+	// Check all the keys before creating anything.
+	vrfPriv, vrfPub, err := s.importVRF(ctx, bundle.GetVrfPrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	// Clients verify every VRF of a domain with the domain's algorithm.
+	alg := vrfAlgorithm(vrfPub)
+	sameAlg := func(pub *keyspb.PublicKey) error {
+		if got := vrfAlgorithm(pub); got != alg {
+			return status.Errorf(codes.InvalidArgument, "Bundle mixes %v and %v VRF keys", alg, got)
+		}
+		return nil
+	}
+	appVRFs := make(map[string]*domain.AppVRF, len(bundle.GetAppVrfPrivateKeys()))
+	for appID, k := range bundle.GetAppVrfPrivateKeys() {
+		priv, pub, err := s.importVRF(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if err := sameAlg(pub); err != nil {
+			return nil, err
+		}
+		appVRFs[appID] = &domain.AppVRF{VRF: pub, VRFPriv: priv}
+	}
+	if err := checkKeyPolicy(bundle.GetKeyPolicy()); err != nil {
+		return nil, err
+	}
+	for _, c := range bundle.GetKeyPolicyHistory() {
+		if err := checkKeyPolicy(c.GetKeyPolicy()); err != nil {
+			return nil, err
+		}
+	}
+	var prev *domain.RotatedVRF
+	if k := bundle.GetPreviousVrfPrivateKey(); k != nil {
+		expiry, err := ptypes.Timestamp(bundle.GetPreviousVrfExpiry())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "previous_vrf_expiry: %v", err)
+		}
+		// The previous key may have expired since the export.
+		if time.Now().Before(expiry) {
+			priv, pub, err := s.importVRF(ctx, k)
+			if err != nil {
+				return nil, err
+			}
+			if err := sameAlg(pub); err != nil {
+				return nil, err
+			}
+			prev = &domain.RotatedVRF{VRF: pub, VRFPriv: priv, Expiry: expiry}
+		}
+	}
+
+	req := &pb.CreateDomainRequest{
+		DomainId:      domainID,
+		MinInterval:   bundle.GetMinInterval(),
+		MaxInterval:   bundle.GetMaxInterval(),
+		VrfPrivateKey: bundle.GetVrfPrivateKey(),
+		VrfAlgorithm:  alg,
+		Labels:        bundle.GetLabels(),
+		LogBackend:    bundle.GetLogBackend(),
+
+		TrillianBackend: bundle.GetTrillianBackend(),
+	}
+	if in.GetCreateTrees() {
+		req.LogSpec = treeSpec(bundle.GetLog())
+		req.MapSpec = treeSpec(bundle.GetMap())
+	} else {
+		if err := s.checkBundleTrees(ctx, bundle); err != nil {
+			return nil, err
+		}
+		req.LogId = bundle.GetLog().GetTreeId()
+		req.MapId = bundle.GetMap().GetTreeId()
+	}
+	// Domain storage can only record a previous VRF by rotating to a new one,
+	// so the domain is created with the previous key and then rotated.
+	if prev != nil {
+		req.VrfPrivateKey = bundle.GetPreviousVrfPrivateKey()
+	}
+	if _, err := s.createDomain(ctx, req); err != nil {
+		return nil, err
+	}
+	if prev != nil {
+		if err := s.domains.RotateVRF(ctx, domainID, vrfPub, vrfPriv, prev.Expiry); err != nil {
+			return nil, fmt.Errorf("adminstorage.RotateVRF(): %w", err)
+		}
+	}
+	for appID, a := range appVRFs {
+		if err := s.domains.AddAppVRF(ctx, domainID, appID, a.VRF, a.VRFPriv); err != nil {
+			return nil, fmt.Errorf("adminstorage.AddAppVRF(): %w", err)
+		}
+	}
+	if err := s.importKeyPolicy(ctx, domainID, bundle, in.GetCreateTrees()); err != nil {
+		return nil, err
+	}
+	glog.Infof("Imported domain %v", domainID)
+	return s.GetDomain(ctx, &pb.GetDomainRequest{DomainId: domainID})
+}
+
+// importKeyPolicy restores the key policy of an imported domain. The revisions
+// of the exported history only refer to the bundle's own trees, so new trees
+// get the current policy from their first revision on.
+func (s *Server) importKeyPolicy(ctx context.Context, domainID string, bundle *pb.DomainBundle, newTrees bool) error {
+	history := bundle.GetKeyPolicyHistory()
+	if newTrees || len(history) == 0 {
+		if bundle.GetKeyPolicy() == nil {
+			return nil
+		}
+		history = []*pb.KeyPolicyChange{{KeyPolicy: bundle.GetKeyPolicy()}}
+	}
+	for _, c := range history {
+		if err := s.domains.SetKeyPolicy(ctx, domainID, c.GetKeyPolicy(), c.GetRevision()); err != nil {
+			return fmt.Errorf("adminstorage.SetKeyPolicy(): %w", err)
+		}
+	}
+	return nil
+}
+
+// verifyBundle checks the signature of b against the trusted bundle keys and
+// returns the bundle it contains.
+func (s *Server) verifyBundle(b *pb.SignedDomainBundle) (*pb.DomainBundle, error) {
+	if len(s.bundleKeys) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "Domain import is not configured")
+	}
+	verified := false
+	for _, k := range s.bundleKeys {
+		if err := tcrypto.Verify(k, b.GetBundle(), b.GetSignature()); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, status.Errorf(codes.InvalidArgument, "Bundle is not signed by a trusted key")
+	}
+	bundle := &pb.DomainBundle{}
+	if err := proto.Unmarshal(b.GetBundle(), bundle); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Cannot parse bundle: %v", err)
+	}
+	return bundle, nil
+}
+
+// checkBundleTrees returns an error if the trees named in bundle do not exist
+// or are signed by different keys than the exported trees.
+func (s *Server) checkBundleTrees(ctx context.Context, bundle *pb.DomainBundle) error {
+	backend, err := s.logBackend(bundle.GetLogBackend(), bundle.GetTrillianBackend().GetLogAddress())
+	if err != nil {
+		return err
+	}
+	_, mapAdmin, err := s.mapClients(bundle.GetTrillianBackend().GetMapAddress())
+	if err != nil {
+		return err
+	}
+	mapTree := func(ctx context.Context, treeID int64) (*tpb.Tree, error) {
+		return mapAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: treeID})
+	}
+	for _, t := range []struct {
+		getTree func(ctx context.Context, treeID int64) (*tpb.Tree, error)
+		want    *tpb.Tree
+	}{
+		{getTree: backend.Tree, want: bundle.GetLog()},
+		{getTree: mapTree, want: bundle.GetMap()},
+	} {
+		got, err := t.getTree(ctx, t.want.GetTreeId())
+		if err != nil {
+			return status.Errorf(codes.FailedPrecondition, "Tree %v is not available, use create_trees to create new trees: %v", t.want.GetTreeId(), err)
+		}
+		if got.GetTreeType() != t.want.GetTreeType() ||
+			!bytes.Equal(got.GetPublicKey().GetDer(), t.want.GetPublicKey().GetDer()) {
+			return status.Errorf(codes.FailedPrecondition, "Tree %v does not match the exported tree", t.want.GetTreeId())
+		}
+	}
+	return nil
+}
+
+// treeSpec returns the parameters of t that CreateDomain accepts.
+func treeSpec(t *tpb.Tree) *pb.TreeSpec {
+	return &pb.TreeSpec{
+		HashStrategy:       t.GetHashStrategy(),
+		SignatureAlgorithm: t.GetSignatureAlgorithm(),
+		MaxRootDuration:    t.GetMaxRootDuration(),
+	}
+}
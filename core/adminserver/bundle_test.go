@@ -0,0 +1,285 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// treeAdmin serves GetTree, ListTrees, DeleteTree, UndeleteTree and key
+// rotations through UpdateTree from a fixed set of trees. ListTrees fails with listErr if it is set. gets counts
+// GetTree calls.
+type treeAdmin struct {
+	tpb.TrillianAdminClient
+	trees   map[int64]*tpb.Tree
+	listErr error
+	gets    int
+}
+
+func (a *treeAdmin) ListTrees(ctx context.Context, in *tpb.ListTreesRequest, opts ...grpc.CallOption) (*tpb.ListTreesResponse, error) {
+	if a.listErr != nil {
+		return nil, a.listErr
+	}
+	resp := &tpb.ListTreesResponse{}
+	for _, t := range a.trees {
+		resp.Tree = append(resp.Tree, t)
+	}
+	return resp, nil
+}
+
+func (a *treeAdmin) GetTree(ctx context.Context, in *tpb.GetTreeRequest, opts ...grpc.CallOption) (*tpb.Tree, error) {
+	a.gets++
+	t, ok := a.trees[in.GetTreeId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", in.GetTreeId())
+	}
+	return t, nil
+}
+
+func (a *treeAdmin) UpdateTree(ctx context.Context, in *tpb.UpdateTreeRequest, opts ...grpc.CallOption) (*tpb.Tree, error) {
+	t, ok := a.trees[in.GetTree().GetTreeId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", in.GetTree().GetTreeId())
+	}
+	for _, p := range in.GetUpdateMask().GetPaths() {
+		switch p {
+		case "private_key":
+			t.PrivateKey = in.GetTree().GetPrivateKey()
+		case "public_key":
+			t.PublicKey = in.GetTree().GetPublicKey()
+		default:
+			return nil, status.Errorf(codes.Unimplemented, "update of %v", p)
+		}
+	}
+	return t, nil
+}
+
+func (a *treeAdmin) DeleteTree(ctx context.Context, in *tpb.DeleteTreeRequest, opts ...grpc.CallOption) (*tpb.Tree, error) {
+	return a.setDeleted(in.GetTreeId(), true)
+}
+
+func (a *treeAdmin) UndeleteTree(ctx context.Context, in *tpb.UndeleteTreeRequest, opts ...grpc.CallOption) (*tpb.Tree, error) {
+	return a.setDeleted(in.GetTreeId(), false)
+}
+
+func (a *treeAdmin) setDeleted(treeID int64, deleted bool) (*tpb.Tree, error) {
+	t, ok := a.trees[treeID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	t.Deleted = deleted
+	return t, nil
+}
+
+func newBundleSigner(t *testing.T) *tcrypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	return tcrypto.NewSHA256Signer(key)
+}
+
+// bundleEnv creates a server with a single domain backed by trees 1 and 2,
+// which have the default tree parameters.
+func bundleEnv(t *testing.T, domainID string) (*Server, *domain.Domain) {
+	ctx := context.Background()
+	signer := newBundleSigner(t)
+	svr := &Server{
+		logAdmin: &treeAdmin{trees: map[int64]*tpb.Tree{
+			1: {TreeId: 1, TreeType: tpb.TreeType_LOG, PublicKey: &keyspb.PublicKey{Der: []byte("log key")},
+				HashStrategy: logArgs.Tree.HashStrategy, SignatureAlgorithm: logArgs.Tree.SignatureAlgorithm},
+		}},
+		mapAdmin: &treeAdmin{trees: map[int64]*tpb.Tree{
+			2: {TreeId: 2, TreeType: tpb.TreeType_MAP, PublicKey: &keyspb.PublicKey{Der: []byte("map key")},
+				HashStrategy: mapArgs.Tree.HashStrategy, SignatureAlgorithm: mapArgs.Tree.SignatureAlgorithm},
+		}},
+		tmap:         fake.NewTrillianMapClient(),
+		domains:      fake.NewDomainStorage(),
+		audits:       fake.NewAuditStorage(),
+		apps:         fake.NewAppIndexStorage(),
+		keygen:       localKeyGen,
+		bundleSigner: signer,
+		bundleKeys:   []crypto.PublicKey{signer.Public()},
+	}
+	wrapped, vrfPub, err := svr.newVRF(ctx, pb.VrfAlgorithm_P256)
+	if err != nil {
+		t.Fatalf("newVRF(): %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    domainID,
+		LogID:       1,
+		MapID:       2,
+		VRF:         vrfPub,
+		VRFPriv:     wrapped,
+		MinInterval: time.Second,
+		MaxInterval: time.Minute,
+	}
+	if err := svr.domains.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	return svr, d
+}
+
+func TestExportDomain(t *testing.T) {
+	ctx := context.Background()
+	domainID := "exported"
+	svr, d := bundleEnv(t, domainID)
+	appPriv, appPub, err := svr.newVRF(ctx, pb.VrfAlgorithm_P256)
+	if err != nil {
+		t.Fatalf("newVRF(): %v", err)
+	}
+	if err := svr.domains.AddAppVRF(ctx, domainID, "app", appPub, appPriv); err != nil {
+		t.Fatalf("AddAppVRF(): %v", err)
+	}
+
+	signed, err := svr.ExportDomain(ctx, &pb.ExportDomainRequest{DomainId: domainID})
+	if err != nil {
+		t.Fatalf("ExportDomain(): %v", err)
+	}
+	bundle, err := svr.verifyBundle(signed)
+	if err != nil {
+		t.Fatalf("verifyBundle(): %v", err)
+	}
+	if got, want := bundle.GetDomainId(), domainID; got != want {
+		t.Errorf("DomainId: %v, want %v", got, want)
+	}
+	if got, want := bundle.GetLog().GetTreeId(), d.LogID; got != want {
+		t.Errorf("Log.TreeId: %v, want %v", got, want)
+	}
+	if got, want := bundle.GetMap().GetTreeId(), d.MapID; got != want {
+		t.Errorf("Map.TreeId: %v, want %v", got, want)
+	}
+	if got, err := ptypes.Duration(bundle.GetMaxInterval()); err != nil || got != d.MaxInterval {
+		t.Errorf("MaxInterval: %v, %v, want %v", got, err, d.MaxInterval)
+	}
+	for _, tc := range []struct {
+		desc    string
+		wrapped *any.Any
+		want    *keyspb.PublicKey
+	}{
+		{desc: "domain", wrapped: bundle.GetVrfPrivateKey(), want: d.VRF},
+		{desc: "app", wrapped: bundle.GetAppVrfPrivateKeys()["app"], want: appPub},
+	} {
+		_, pub, err := svr.importVRF(ctx, tc.wrapped)
+		if err != nil {
+			t.Errorf("%v: importVRF(): %v", tc.desc, err)
+			continue
+		}
+		if !proto.Equal(pub, tc.want) {
+			t.Errorf("%v: exported VRF has public key %v, want %v", tc.desc, pub, tc.want)
+		}
+	}
+
+	svr.bundleSigner = nil
+	if _, err := svr.ExportDomain(ctx, &pb.ExportDomainRequest{DomainId: domainID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ExportDomain() without signer: %v, want %v", err, codes.FailedPrecondition)
+	}
+}
+
+func TestVerifyBundle(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "exported")
+	signed, err := svr.ExportDomain(ctx, &pb.ExportDomainRequest{DomainId: d.DomainID})
+	if err != nil {
+		t.Fatalf("ExportDomain(): %v", err)
+	}
+	tampered := proto.Clone(signed).(*pb.SignedDomainBundle)
+	tampered.Bundle[len(tampered.Bundle)-1] ^= 1
+	trusted := svr.bundleKeys
+	untrusted := []crypto.PublicKey{newBundleSigner(t).Public()}
+
+	for _, tc := range []struct {
+		desc    string
+		keys    []crypto.PublicKey
+		in      *pb.SignedDomainBundle
+		wantErr codes.Code
+	}{
+		{desc: "valid", keys: trusted, in: signed, wantErr: codes.OK},
+		{desc: "tampered", keys: trusted, in: tampered, wantErr: codes.InvalidArgument},
+		{desc: "untrusted", keys: untrusted, in: signed, wantErr: codes.InvalidArgument},
+		{desc: "second key", keys: append(untrusted, trusted...), in: signed, wantErr: codes.OK},
+		{desc: "not configured", keys: nil, in: signed, wantErr: codes.FailedPrecondition},
+	} {
+		svr.bundleKeys = tc.keys
+		if _, err := svr.verifyBundle(tc.in); status.Code(err) != tc.wantErr {
+			t.Errorf("%v: verifyBundle(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestImportDomainPreconditions(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "existing")
+	vrfPriv, err := ptypes.MarshalAny(d.VRFPriv)
+	if err != nil {
+		t.Fatalf("MarshalAny(): %v", err)
+	}
+	logTree := &tpb.Tree{TreeId: 1, TreeType: tpb.TreeType_LOG, PublicKey: &keyspb.PublicKey{Der: []byte("log key")}}
+	mapTree := &tpb.Tree{TreeId: 2, TreeType: tpb.TreeType_MAP, PublicKey: &keyspb.PublicKey{Der: []byte("map key")}}
+	otherKey := &tpb.Tree{TreeId: 2, TreeType: tpb.TreeType_MAP, PublicKey: &keyspb.PublicKey{Der: []byte("other key")}}
+	missing := &tpb.Tree{TreeId: 3, TreeType: tpb.TreeType_MAP}
+
+	for _, tc := range []struct {
+		desc     string
+		domainID string
+		mapTree  *tpb.Tree
+		wantErr  codes.Code
+	}{
+		{desc: "no domain id", domainID: "", mapTree: mapTree, wantErr: codes.InvalidArgument},
+		{desc: "domain exists", domainID: "existing", mapTree: mapTree, wantErr: codes.AlreadyExists},
+		{desc: "different tree key", domainID: "restored", mapTree: otherKey, wantErr: codes.FailedPrecondition},
+		{desc: "missing tree", domainID: "restored", mapTree: missing, wantErr: codes.FailedPrecondition},
+	} {
+		data, err := proto.Marshal(&pb.DomainBundle{
+			DomainId:      tc.domainID,
+			Log:           logTree,
+			Map:           tc.mapTree,
+			VrfPrivateKey: vrfPriv,
+		})
+		if err != nil {
+			t.Fatalf("Marshal(): %v", err)
+		}
+		sig, err := svr.bundleSigner.Sign(data)
+		if err != nil {
+			t.Fatalf("Sign(): %v", err)
+		}
+		_, err = svr.ImportDomain(ctx, &pb.ImportDomainRequest{
+			Bundle: &pb.SignedDomainBundle{Bundle: data, Signature: sig},
+		})
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: ImportDomain(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys that the REST gateway sets on the calls it makes.
+const (
+	gatewayCallerKey = "x-kt-gateway-caller"
+	gatewayTokenKey  = "x-kt-gateway-token"
+)
+
+// forwardedCaller is the context key of the identity forwarded by a Gateway.
+type forwardedCaller struct{}
+
+// Gateway forwards the identity of REST callers to the admin server. The
+// REST gateway calls the admin server over gRPC, so its calls would
+// otherwise be audited and authorized as the gateway's own. The gateway
+// authenticates the identities it forwards with a token that is generated
+// at startup and never leaves the process.
+type Gateway struct {
+	token string
+}
+
+// NewGateway returns a Gateway with a new random token.
+func NewGateway() (*Gateway, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &Gateway{token: hex.EncodeToString(b)}, nil
+}
+
+// Metadata returns the metadata that identifies the REST caller of r. It is
+// meant for runtime.WithMetadata.
+func (g *Gateway) Metadata(_ context.Context, r *http.Request) metadata.MD {
+	return metadata.Pairs(gatewayCallerKey, httpIdentity(r), gatewayTokenKey, g.token)
+}
+
+// httpIdentity identifies REST callers in the same way as callerIdentity
+// identifies gRPC callers.
+func httpIdentity(r *http.Request) string {
+	if r.TLS != nil {
+		if chains := r.TLS.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			return chains[0][0].Subject.CommonName
+		}
+	}
+	return r.RemoteAddr
+}
+
+// UnaryInterceptor returns a gRPC interceptor that attributes calls made by
+// the gateway to the REST caller they were made for. It must run before
+// ACLInterceptor.
+func (g *Gateway) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := g.forwarded(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming RPCs. It must run
+// before ACLStreamInterceptor.
+func (g *Gateway) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := g.forwarded(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &forwardedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// forwarded returns ctx with the identity forwarded by the gateway, if any.
+// Calls that carry a forwarded identity without the gateway's token fail
+// with Unauthenticated. REST callers cannot forge an identity by sending
+// the metadata themselves: the gateway forwards their values alongside its
+// own, which makes the call ambiguous.
+func (g *Gateway) forwarded(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	callers, tokens := md[gatewayCallerKey], md[gatewayTokenKey]
+	if len(callers) == 0 && len(tokens) == 0 {
+		return ctx, nil
+	}
+	if len(callers) != 1 || len(tokens) != 1 ||
+		subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(g.token)) != 1 {
+		return nil, status.Errorf(codes.Unauthenticated, "Invalid gateway credentials")
+	}
+	return context.WithValue(ctx, forwardedCaller{}, callers[0]), nil
+}
+
+// forwardedStream is a stream whose context holds a forwarded identity.
+type forwardedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context with the forwarded identity.
+func (s *forwardedStream) Context() context.Context {
+	return s.ctx
+}
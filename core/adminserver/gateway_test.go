@@ -0,0 +1,90 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/keytransparency/core/acl"
+	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestGatewayForwardsIdentity(t *testing.T) {
+	ctx := context.Background()
+	policy := fake.NewACLStorage()
+	if err := policy.Grant(ctx, "alice", "domain1"); err != nil {
+		t.Fatalf("Grant(): %v", err)
+	}
+	if err := policy.Grant(ctx, "gateway", acl.AllDomains); err != nil {
+		t.Fatalf("Grant(): %v", err)
+	}
+	gateway, err := NewGateway()
+	if err != nil {
+		t.Fatalf("NewGateway(): %v", err)
+	}
+	other, err := NewGateway()
+	if err != nil {
+		t.Fatalf("NewGateway(): %v", err)
+	}
+	aclInterceptor := ACLInterceptor(policy)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return callerIdentity(ctx), nil
+	}
+	// gatewayCall returns the metadata that g sets for a REST call by
+	// remoteAddr.
+	gatewayCall := func(g *Gateway, remoteAddr string) metadata.MD {
+		return g.Metadata(ctx, &http.Request{RemoteAddr: remoteAddr})
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		md       metadata.MD
+		domainID string
+		want     codes.Code
+		wantID   string
+	}{
+		{desc: "gateway", domainID: "domain2", want: codes.OK, wantID: "gateway"},
+		{desc: "forwarded", md: gatewayCall(gateway, "alice"), domainID: "domain1", want: codes.OK, wantID: "alice"},
+		{desc: "forwarded other domain", md: gatewayCall(gateway, "alice"), domainID: "domain2", want: codes.PermissionDenied},
+		{desc: "wrong token", md: gatewayCall(other, "alice"), domainID: "domain1", want: codes.Unauthenticated},
+		{desc: "no token", md: metadata.Pairs(gatewayCallerKey, "alice"), domainID: "domain1", want: codes.Unauthenticated},
+		{desc: "forged caller", md: metadata.Join(gatewayCall(gateway, "alice"), metadata.Pairs(gatewayCallerKey, "root")), domainID: "domain1", want: codes.Unauthenticated},
+	} {
+		callCtx := withIdentity(ctx, "gateway")
+		if tc.md != nil {
+			callCtx = metadata.NewIncomingContext(callCtx, tc.md)
+		}
+		resp, err := gateway.UnaryInterceptor()(callCtx, &pb.GetDomainRequest{DomainId: tc.domainID}, info,
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return aclInterceptor(ctx, req, info, handler)
+			})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%v: interceptors: %v, want %v", tc.desc, err, tc.want)
+			continue
+		}
+		if err == nil && resp != tc.wantID {
+			t.Errorf("%v: callerIdentity(): %v, want %v", tc.desc, resp, tc.wantID)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/keytransparency/core/mutator/entry"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+var (
+	// Number of mutations evaluated when the request does not specify one.
+	defaultEvaluateMutations = int32(1000)
+	// Maximum number of mutations evaluated per request to bound server work.
+	maxEvaluateMutations = int32(10000)
+	// Number of mutations read from storage at a time.
+	evaluatePageSize = int32(1000)
+)
+
+// EvaluateKeyPolicy applies a proposed key policy to the most recent
+// mutations of a domain and reports the ones it would reject. Mutations are
+// read newest revision first. Nothing is written, so the call is not audited.
+func (s *Server) EvaluateKeyPolicy(ctx context.Context, in *pb.EvaluateKeyPolicyRequest) (*pb.EvaluateKeyPolicyResponse, error) {
+	if s.mutations == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Mutation storage is not configured")
+	}
+	if in.GetMaxMutations() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "max_mutations must be >= 0")
+	}
+	limit := in.GetMaxMutations()
+	switch {
+	case limit == 0:
+		limit = defaultEvaluateMutations
+	case limit > maxEvaluateMutations:
+		limit = maxEvaluateMutations
+	}
+	if err := checkKeyPolicy(in.GetKeyPolicy()); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	tmap, _, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return nil, err
+	}
+	mapRoot, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetSignedMapRoot(%v): %v", d.MapID, err)
+	}
+
+	resp := &pb.EvaluateKeyPolicyResponse{}
+	for rev := mapRoot.GetMapRoot().GetMapRevision(); rev > 0 && resp.Evaluated < limit; rev-- {
+		if resp.LastRevision == 0 {
+			resp.LastRevision = rev
+		}
+		resp.FirstRevision = rev
+		if err := s.evaluateRevision(ctx, d.DomainID, rev, in.GetKeyPolicy(), limit, resp); err != nil {
+			return nil, err
+		}
+	}
+	glog.Infof("EvaluateKeyPolicy(%v): %v of %v mutations in revisions %v-%v rejected",
+		d.DomainID, len(resp.Rejected), resp.Evaluated, resp.FirstRevision, resp.LastRevision)
+	return resp, nil
+}
+
+// evaluateRevision applies policy to the mutations of revision until limit
+// mutations have been evaluated in total, and records the results in resp.
+func (s *Server) evaluateRevision(ctx context.Context, domainID string, rev int64, policy *pb.KeyPolicy, limit int32, resp *pb.EvaluateKeyPolicyResponse) error {
+	if err := mutator.ReadRevision(ctx, s.mutations, domainID, rev, evaluatePageSize, func(e *pb.Entry) bool {
+		if resp.Evaluated >= limit {
+			return false
+		}
+		resp.Evaluated++
+		if err := entry.CheckKeyPolicy(policy, e); err != nil {
+			resp.Rejected = append(resp.Rejected, &pb.RejectedMutation{
+				Revision: rev,
+				Index:    e.GetIndex(),
+				Reason:   err.Error(),
+			})
+		}
+		return true
+	}); err != nil {
+		glog.Errorf("EvaluateKeyPolicy(): %v", err)
+		return status.Error(codes.Internal, "Reading mutations failed")
+	}
+	return nil
+}
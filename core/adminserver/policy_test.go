@@ -0,0 +1,124 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+func publicKeyPB(t *testing.T, pub crypto.PublicKey) *keyspb.PublicKey {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	return &keyspb.PublicKey{Der: der}
+}
+
+func TestEvaluateKeyPolicy(t *testing.T) {
+	ctx := context.Background()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	ecPub, rsaPub := publicKeyPB(t, ecKey.Public()), publicKeyPB(t, rsaKey.Public())
+
+	svr, d := bundleEnv(t, "domain")
+	tmap := fake.NewTrillianMapClient()
+	mutations := fake.NewMutationStorage()
+	for rev, entries := range [][]*pb.Entry{
+		1: {{Index: []byte("rsa"), AuthorizedKeys: []*keyspb.PublicKey{rsaPub}}},
+		2: {
+			{Index: []byte("ec"), AuthorizedKeys: []*keyspb.PublicKey{ecPub}},
+			{Index: []byte("two"), AuthorizedKeys: []*keyspb.PublicKey{ecPub, ecPub}},
+		},
+		3: {{Index: []byte("ec"), AuthorizedKeys: []*keyspb.PublicKey{ecPub}}},
+	} {
+		if rev == 0 {
+			continue
+		}
+		tmap.SetLeaves(ctx, &tpb.SetMapLeavesRequest{})
+		if err := mutations.WriteBatch(ctx, d.DomainID, int64(rev), entries); err != nil {
+			t.Fatalf("WriteBatch(): %v", err)
+		}
+	}
+	svr.tmap = tmap
+
+	ecdsaOnly := &pb.KeyPolicy{
+		AllowedAlgorithms: []sigpb.DigitallySigned_SignatureAlgorithm{sigpb.DigitallySigned_ECDSA},
+		MaxKeys:           1,
+	}
+	for _, tc := range []struct {
+		desc      string
+		policy    *pb.KeyPolicy
+		max       int32
+		want      int32
+		wantFirst int64
+		wantIdx   []string
+	}{
+		{desc: "no policy", want: 4, wantFirst: 1},
+		{desc: "all", policy: ecdsaOnly, want: 4, wantFirst: 1, wantIdx: []string{"two", "rsa"}},
+		{desc: "recent", policy: ecdsaOnly, max: 2, want: 2, wantFirst: 2},
+		{desc: "partial revision", policy: ecdsaOnly, max: 3, want: 3, wantFirst: 2, wantIdx: []string{"two"}},
+	} {
+		svr.mutations = mutations
+		got, err := svr.EvaluateKeyPolicy(ctx, &pb.EvaluateKeyPolicyRequest{
+			DomainId:     d.DomainID,
+			KeyPolicy:    tc.policy,
+			MaxMutations: tc.max,
+		})
+		if err != nil {
+			t.Fatalf("%v: EvaluateKeyPolicy(): %v", tc.desc, err)
+		}
+		if got.GetEvaluated() != tc.want {
+			t.Errorf("%v: Evaluated: %v, want %v", tc.desc, got.GetEvaluated(), tc.want)
+		}
+		if got.GetFirstRevision() != tc.wantFirst || got.GetLastRevision() != 3 {
+			t.Errorf("%v: revisions %v-%v, want %v-3", tc.desc, got.GetFirstRevision(), got.GetLastRevision(), tc.wantFirst)
+		}
+		var idx []string
+		for _, r := range got.GetRejected() {
+			idx = append(idx, string(r.GetIndex()))
+		}
+		if !reflect.DeepEqual(idx, tc.wantIdx) {
+			t.Errorf("%v: rejected %v, want %v", tc.desc, idx, tc.wantIdx)
+		}
+	}
+
+	svr.mutations = nil
+	if _, err := svr.EvaluateKeyPolicy(ctx, &pb.EvaluateKeyPolicyRequest{DomainId: d.DomainID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("EvaluateKeyPolicy(no storage): %v, want %v", err, codes.FailedPrecondition)
+	}
+}
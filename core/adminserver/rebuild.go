@@ -0,0 +1,131 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/trillian/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// RebuildDomainMap replays the mutations of every revision of a domain into
+// a new map with the hash strategy of the domain's map, and compares the root
+// hash of each revision with the map root that was published in the SMH log.
+// Revisions created while the rebuild runs are not replayed. The new map is
+// deleted unless the caller keeps it.
+func (s *Server) RebuildDomainMap(ctx context.Context, in *pb.RebuildDomainMapRequest) (*pb.RebuildDomainMapResponse, error) {
+	if s.replayer == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Map rebuilding is not configured")
+	}
+	if err := s.audit(ctx, "RebuildDomainMap", in.GetDomainId(), in); err != nil {
+		return nil, err
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	tmap, mapAdmin, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := s.logBackend(d.LogBackend, d.LogAddress)
+	if err != nil {
+		return nil, err
+	}
+	mapRoot, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetSignedMapRoot(%v): %v", d.MapID, err)
+	}
+	published, err := smhlog.Roots(ctx, backend.Log, d.LogID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "Reading SMH log %v: %v", d.LogID, err)
+	}
+
+	live, err := s.trees.get(ctx, mapAdmin, d.MapAddress, d.MapID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetTree(%v): %v", d.MapID, err)
+	}
+	args, err := treeArgs(mapArgs, &pb.TreeSpec{
+		HashStrategy:       live.GetHashStrategy(),
+		SignatureAlgorithm: live.GetSignatureAlgorithm(),
+	}, fmt.Sprintf("KT domain %s's rebuilt Map", d.DomainID))
+	if err != nil {
+		return nil, err
+	}
+	if args, err = withTreeKey(ctx, s.treeGen, args); err != nil {
+		return nil, err
+	}
+	tree, err := client.CreateAndInitTree(ctx, args, mapAdmin, tmap)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAndInitTree(map): %w", err)
+	}
+
+	resp, err := s.rebuild(ctx, d, tmap, tree.GetTreeId(), mapRoot.GetMapRoot().GetMapRevision(), published)
+	if err != nil || !in.GetKeepMap() {
+		s.deleteTrees([]*createdTree{{admin: mapAdmin, tree: tree}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if in.GetKeepMap() {
+		resp.MapId = tree.GetTreeId()
+	}
+	glog.Infof("Rebuilt map of domain %v up to revision %v; %v revisions verified",
+		d.DomainID, resp.Revision, resp.VerifiedRevisions)
+	return resp, nil
+}
+
+// rebuild replays revisions 1 to last of d into the map mapID of tmap and
+// checks the root hash of each revision against published, which holds the
+// map roots of the SMH log by revision.
+func (s *Server) rebuild(ctx context.Context, d *domain.Domain, tmap tpb.TrillianMapClient, mapID, last int64, published map[int64]*tpb.SignedMapRoot) (*pb.RebuildDomainMapResponse, error) {
+	resp := &pb.RebuildDomainMapResponse{}
+	for rev := int64(1); rev <= last; rev++ {
+		root, err := s.replayer.ReplayRevision(ctx, d.DomainID, tmap, mapID, rev)
+		if err != nil {
+			glog.Errorf("RebuildDomainMap(%v): ReplayRevision(%v): %v", d.DomainID, rev, err)
+			return nil, status.Errorf(codes.Internal, "Replaying revision %v failed", rev)
+		}
+		if got := root.GetMapRevision(); got != rev {
+			return nil, status.Errorf(codes.Internal, "Replaying revision %v created revision %v", rev, got)
+		}
+		resp.Revision = rev
+		resp.RootHash = root.GetRootHash()
+
+		// The log may not have sequenced the newest map roots yet.
+		want, ok := published[rev]
+		if !ok {
+			continue
+		}
+		if !bytes.Equal(root.GetRootHash(), want.GetRootHash()) {
+			glog.Errorf("RebuildDomainMap(%v): revision %v has root hash %x, published %x",
+				d.DomainID, rev, root.GetRootHash(), want.GetRootHash())
+			return nil, status.Errorf(codes.DataLoss, "Rebuilt revision %v has root hash %x, published %x",
+				rev, root.GetRootHash(), want.GetRootHash())
+		}
+		resp.VerifiedRevisions++
+	}
+	return resp, nil
+}
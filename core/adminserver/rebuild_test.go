@@ -0,0 +1,84 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/keytransparency/core/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// rootReplayer returns map roots with fixed root hashes for each revision.
+// Replaying a revision without a hash fails.
+type rootReplayer struct {
+	hashes map[int64][]byte
+}
+
+func (r *rootReplayer) ReplayRevision(ctx context.Context, domainID string, tmap tpb.TrillianMapClient, mapID, revision int64) (*tpb.SignedMapRoot, error) {
+	h, ok := r.hashes[revision]
+	if !ok {
+		return nil, errors.New("no mutations")
+	}
+	return &tpb.SignedMapRoot{MapRevision: revision, RootHash: h}, nil
+}
+
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	d := &domain.Domain{DomainID: "rebuilt", MapID: 2}
+	// The log has not sequenced the root of revision 3 yet.
+	published := map[int64]*tpb.SignedMapRoot{
+		0: {MapRevision: 0, RootHash: []byte{0}},
+		1: {MapRevision: 1, RootHash: []byte{1}},
+		2: {MapRevision: 2, RootHash: []byte{2}},
+	}
+	for _, tc := range []struct {
+		desc         string
+		hashes       map[int64][]byte
+		wantErr      codes.Code
+		wantVerified int64
+	}{
+		{desc: "verified", hashes: map[int64][]byte{1: {1}, 2: {2}, 3: {3}}, wantVerified: 2},
+		{desc: "mismatch", hashes: map[int64][]byte{1: {1}, 2: {9}, 3: {3}}, wantErr: codes.DataLoss},
+		{desc: "replay failed", hashes: map[int64][]byte{1: {1}}, wantErr: codes.Internal},
+	} {
+		svr := &Server{replayer: &rootReplayer{hashes: tc.hashes}}
+		resp, err := svr.rebuild(ctx, d, nil, 3, 3, published)
+		if got := status.Code(err); got != tc.wantErr {
+			t.Errorf("%v: rebuild(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if resp.GetRevision() != 3 || string(resp.GetRootHash()) != string([]byte{3}) || resp.GetVerifiedRevisions() != tc.wantVerified {
+			t.Errorf("%v: rebuild(): %v, want revision 3 with root hash 03 and %v verified", tc.desc, resp, tc.wantVerified)
+		}
+	}
+}
+
+func TestRebuildDomainMapNotConfigured(t *testing.T) {
+	svr, d := bundleEnv(t, "rebuilt")
+	_, err := svr.RebuildDomainMap(context.Background(), &pb.RebuildDomainMapRequest{DomainId: d.DomainID})
+	if got, want := status.Code(err), codes.FailedPrecondition; got != want {
+		t.Errorf("RebuildDomainMap(): %v, want %v", err, want)
+	}
+}
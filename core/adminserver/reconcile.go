@@ -0,0 +1,53 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/reconcile"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Reconciler returns the checker that GetReconciliation reports, so that the
+// sequencer can run it at startup and skip the domains it finds degraded.
+func (s *Server) Reconciler() *reconcile.Checker {
+	return s.reconciler
+}
+
+// domainTrees returns the services that host the trees of d.
+func (s *Server) domainTrees(d *domain.Domain) (*reconcile.Trees, error) {
+	tmap, mapAdmin, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := s.logBackend(d.LogBackend, d.LogAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &reconcile.Trees{Map: tmap, MapAdmin: mapAdmin, Log: backend}, nil
+}
+
+// GetReconciliation returns the result of the last check of the domains
+// against their trees. The domains are checked if requested or if they have
+// not been checked yet.
+func (s *Server) GetReconciliation(ctx context.Context, in *pb.GetReconciliationRequest) (*pb.Reconciliation, error) {
+	if r := s.reconciler.Result(); r != nil && !in.GetRerun() {
+		return r, nil
+	}
+	return s.reconciler.Reconcile(ctx)
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/reconcile"
+	"github.com/google/keytransparency/core/smhlog"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+func TestGetReconciliation(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	svr.tlog, svr.tmap = fake.NewTrillianLogClient(), fake.NewTrillianMapClient()
+	svr.reconciler = reconcile.New(svr.domains, svr.domainTrees)
+
+	// The trees of bundleEnv are not active.
+	r, err := svr.GetReconciliation(ctx, &pb.GetReconciliationRequest{})
+	if err != nil {
+		t.Fatalf("GetReconciliation(): %v", err)
+	}
+	if got := r.GetDomains(); len(got) != 1 || got[0].GetDomainId() != d.DomainID || len(got[0].GetProblems()) == 0 {
+		t.Errorf("GetReconciliation(): %v, want problems with domain %v", r, d.DomainID)
+	}
+
+	logTree := svr.logAdmin.(*treeAdmin).trees[1]
+	logTree.TreeState, logTree.HashStrategy = tpb.TreeState_ACTIVE, tpb.HashStrategy_OBJECT_RFC6962_SHA256
+	mapTree := svr.mapAdmin.(*treeAdmin).trees[2]
+	mapTree.TreeState, mapTree.HashStrategy = tpb.TreeState_ACTIVE, tpb.HashStrategy_CONIKS_SHA512_256
+	if err := smhlog.QueueRoot(ctx, svr.tlog, d.LogID, &tpb.SignedMapRoot{}); err != nil {
+		t.Fatalf("QueueRoot(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		rerun        bool
+		wantDegraded int
+	}{
+		{rerun: false, wantDegraded: 1},
+		{rerun: true, wantDegraded: 0},
+	} {
+		r, err := svr.GetReconciliation(ctx, &pb.GetReconciliationRequest{Rerun: tc.rerun})
+		if err != nil {
+			t.Fatalf("GetReconciliation(rerun: %v): %v", tc.rerun, err)
+		}
+		if got := reconcile.CountDegraded(r); got != tc.wantDegraded {
+			t.Errorf("GetReconciliation(rerun: %v): %v degraded domains, want %v", tc.rerun, got, tc.wantDegraded)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// GetDomainStatus reports how far the sequencer is behind and the state of
+// the domain's trees, so that operators do not need to query Trillian.
+func (s *Server) GetDomainStatus(ctx context.Context, in *pb.GetDomainStatusRequest) (*pb.DomainStatus, error) {
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	resp := &pb.DomainStatus{
+		DomainId:         d.DomainID,
+		PendingMutations: -1,
+	}
+
+	if s.queue != nil {
+		pending, oldest, err := s.queue.PendingMutations(ctx, d.DomainID)
+		if err != nil {
+			glog.Errorf("PendingMutations(%v): %v", d.DomainID, err)
+			return nil, status.Errorf(codes.Internal, "Cannot read mutation queue")
+		}
+		resp.PendingMutations = pending
+		if pending > 0 {
+			if resp.OldestPendingMutation, err = ptypes.TimestampProto(oldest); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tmap, mapAdmin, err := s.mapClients(d.MapAddress)
+	if err != nil {
+		return nil, err
+	}
+	mapRoot, err := tmap.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetSignedMapRoot(%v): %v", d.MapID, err)
+	}
+	smr := mapRoot.GetMapRoot()
+	resp.MapRevision = smr.GetMapRevision()
+	mapTime := time.Unix(0, smr.GetTimestampNanos())
+	if resp.MapRootTime, err = ptypes.TimestampProto(mapTime); err != nil {
+		return nil, err
+	}
+	resp.TimeSinceMapRoot = ptypes.DurationProto(now.Sub(mapTime))
+
+	backend, err := s.logBackend(d.LogBackend, d.LogAddress)
+	if err != nil {
+		return nil, err
+	}
+	logRoot, err := backend.Log.GetLatestSignedLogRoot(ctx, &tpb.GetLatestSignedLogRootRequest{LogId: d.LogID})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetLatestSignedLogRoot(%v): %v", d.LogID, err)
+	}
+	sth := logRoot.GetSignedLogRoot()
+	resp.LogTreeSize = sth.GetTreeSize()
+	if resp.LogRootTime, err = ptypes.TimestampProto(time.Unix(0, sth.GetTimestampNanos())); err != nil {
+		return nil, err
+	}
+
+	logTree, err := backend.Tree(ctx, d.LogID)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetTree(log %v): %v", d.LogID, err)
+	}
+	mapTree, err := mapAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: d.MapID})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "GetTree(map %v): %v", d.MapID, err)
+	}
+	resp.LogTreeState = logTree.GetTreeState()
+	resp.MapTreeState = mapTree.GetTreeState()
+	return resp, nil
+}
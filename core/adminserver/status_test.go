@@ -0,0 +1,93 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/fake"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// pendingQueue reports a fixed number of pending mutations.
+type pendingQueue struct {
+	count  int64
+	oldest time.Time
+}
+
+func (q *pendingQueue) PendingMutations(ctx context.Context, domainID string) (int64, time.Time, error) {
+	return q.count, q.oldest, nil
+}
+
+func TestGetDomainStatus(t *testing.T) {
+	ctx := context.Background()
+	oldest := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		desc        string
+		queue       QueueInspector
+		wantPending int64
+		wantOldest  bool
+	}{
+		{desc: "no queue", wantPending: -1},
+		{desc: "empty queue", queue: &pendingQueue{}, wantPending: 0},
+		{desc: "pending", queue: &pendingQueue{count: 3, oldest: oldest}, wantPending: 3, wantOldest: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			svr, d := bundleEnv(t, "domain")
+			svr.logAdmin.(*treeAdmin).trees[1].TreeState = tpb.TreeState_ACTIVE
+			svr.mapAdmin.(*treeAdmin).trees[2].TreeState = tpb.TreeState_FROZEN
+			tlog := fake.NewTrillianLogClient()
+			tlog.TreeSize = 2
+			tmap := fake.NewTrillianMapClient()
+			for i := 0; i < 3; i++ {
+				tmap.SetLeaves(ctx, &tpb.SetMapLeavesRequest{})
+			}
+			svr.tlog, svr.tmap, svr.queue = tlog, tmap, tc.queue
+
+			got, err := svr.GetDomainStatus(ctx, &pb.GetDomainStatusRequest{DomainId: d.DomainID})
+			if err != nil {
+				t.Fatalf("GetDomainStatus(): %v", err)
+			}
+			if got.GetPendingMutations() != tc.wantPending {
+				t.Errorf("PendingMutations: %v, want %v", got.GetPendingMutations(), tc.wantPending)
+			}
+			if tc.wantOldest {
+				if ts, err := ptypes.Timestamp(got.GetOldestPendingMutation()); err != nil || !ts.Equal(oldest) {
+					t.Errorf("OldestPendingMutation: %v, %v, want %v", ts, err, oldest)
+				}
+			} else if got.GetOldestPendingMutation() != nil {
+				t.Errorf("OldestPendingMutation: %v, want nil", got.GetOldestPendingMutation())
+			}
+			if got, want := got.GetMapRevision(), int64(3); got != want {
+				t.Errorf("MapRevision: %v, want %v", got, want)
+			}
+			if got, want := got.GetLogTreeSize(), int64(2); got != want {
+				t.Errorf("LogTreeSize: %v, want %v", got, want)
+			}
+			if got.GetLogTreeState() != tpb.TreeState_ACTIVE || got.GetMapTreeState() != tpb.TreeState_FROZEN {
+				t.Errorf("TreeStates: %v, %v, want ACTIVE, FROZEN", got.GetLogTreeState(), got.GetMapTreeState())
+			}
+		})
+	}
+	svr, _ := bundleEnv(t, "domain")
+	if _, err := svr.GetDomainStatus(ctx, &pb.GetDomainStatusRequest{DomainId: "unknown"}); err == nil {
+		t.Errorf("GetDomainStatus(unknown): nil, want error")
+	}
+}
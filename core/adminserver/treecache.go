@@ -0,0 +1,113 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	tpb "github.com/google/trillian"
+)
+
+// defaultTreeCacheTTL is how long tree metadata fetched from Trillian is
+// reused when Options.TreeCacheTTL is not set.
+const defaultTreeCacheTTL = 30 * time.Second
+
+// treeCache holds the metadata of Trillian trees so that GetDomain and
+// ListDomains do not call GetTree for every domain on every request. Entries
+// expire after ttl and are dropped as soon as the server changes a tree. A
+// nil *treeCache caches nothing.
+//
+// Trees are keyed by the address of their Trillian cluster as well as their
+// ID, since tree IDs are only unique within a cluster.
+type treeCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	trees map[treeKey]cachedTree
+	// gen counts invalidations. A fetch that races with an invalidation
+	// is not cached, since it may have read the tree before the change.
+	gen uint64
+}
+
+// treeKey identifies a tree. addr is empty for trees of the server's own
+// Trillian cluster.
+type treeKey struct {
+	addr string
+	id   int64
+}
+
+type cachedTree struct {
+	tree   *tpb.Tree
+	expiry time.Time
+}
+
+// newTreeCache returns a cache whose entries live for ttl. It returns nil,
+// which disables caching, if ttl is negative.
+func newTreeCache(ttl time.Duration) *treeCache {
+	if ttl < 0 {
+		return nil
+	}
+	return &treeCache{
+		ttl:   ttl,
+		now:   time.Now,
+		trees: make(map[treeKey]cachedTree),
+	}
+}
+
+// get returns the metadata of tree treeID of the cluster at addr, fetching it
+// from admin if it is not cached or has expired. The returned tree may be
+// modified by the caller.
+func (c *treeCache) get(ctx context.Context, admin tpb.TrillianAdminClient, addr string, treeID int64) (*tpb.Tree, error) {
+	if c == nil {
+		return admin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: treeID})
+	}
+	key := treeKey{addr: addr, id: treeID}
+	c.mu.Lock()
+	e, ok := c.trees[key]
+	gen := c.gen
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expiry) {
+		return proto.Clone(e.tree).(*tpb.Tree), nil
+	}
+
+	tree, err := admin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: treeID})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.gen == gen {
+		c.trees[key] = cachedTree{tree: proto.Clone(tree).(*tpb.Tree), expiry: c.now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return tree, nil
+}
+
+// invalidate drops the cached metadata of treeIDs of the cluster at addr.
+func (c *treeCache) invalidate(addr string, treeIDs ...int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, id := range treeIDs {
+		delete(c.trees, treeKey{addr: addr, id: id})
+	}
+}
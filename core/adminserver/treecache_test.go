@@ -0,0 +1,117 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+func TestTreeCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	svr, d := bundleEnv(t, "domain")
+	svr.trees = newTreeCache(time.Minute)
+	svr.trees.now = func() time.Time { return now }
+	logAdmin := svr.logAdmin.(*treeAdmin)
+
+	for _, tc := range []struct {
+		desc     string
+		advance  time.Duration
+		mutate   func() error
+		wantGets int
+	}{
+		{desc: "cold", wantGets: 1},
+		{desc: "cached", wantGets: 1},
+		{desc: "still cached", advance: 59 * time.Second, wantGets: 1},
+		{desc: "expired", advance: time.Second, wantGets: 2},
+		{desc: "invalidated by delete", wantGets: 4, mutate: func() error {
+			// DeleteDomain reads the tree once to check its state.
+			_, err := svr.DeleteDomain(ctx, &pb.DeleteDomainRequest{DomainId: d.DomainID})
+			return err
+		}},
+	} {
+		now = now.Add(tc.advance)
+		if tc.mutate != nil {
+			if err := tc.mutate(); err != nil {
+				t.Fatalf("%v: %v", tc.desc, err)
+			}
+		}
+		got, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID, ShowDeleted: true})
+		if err != nil {
+			t.Fatalf("%v: GetDomain(): %v", tc.desc, err)
+		}
+		if logAdmin.gets != tc.wantGets {
+			t.Errorf("%v: GetTree calls: %v, want %v", tc.desc, logAdmin.gets, tc.wantGets)
+		}
+		if got.GetLog().GetDeleted() != got.GetTreesDeleted() {
+			t.Errorf("%v: Log.Deleted: %v, want %v", tc.desc, got.GetLog().GetDeleted(), got.GetTreesDeleted())
+		}
+	}
+}
+
+func TestTreeCacheDisabled(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "domain")
+	svr.trees = newTreeCache(-1)
+	for i := 1; i <= 2; i++ {
+		if _, err := svr.GetDomain(ctx, &pb.GetDomainRequest{DomainId: d.DomainID}); err != nil {
+			t.Fatalf("GetDomain(): %v", err)
+		}
+		if got := svr.logAdmin.(*treeAdmin).gets; got != i {
+			t.Errorf("GetTree calls: %v, want %v", got, i)
+		}
+	}
+}
+
+func TestTreeCacheClusters(t *testing.T) {
+	ctx := context.Background()
+	c := newTreeCache(time.Minute)
+	local := &treeAdmin{trees: map[int64]*tpb.Tree{1: {TreeId: 1, DisplayName: "local"}}}
+	remote := &treeAdmin{trees: map[int64]*tpb.Tree{1: {TreeId: 1, DisplayName: "remote"}}}
+
+	for _, tc := range []struct {
+		desc       string
+		invalidate string
+		admin      *treeAdmin
+		addr       string
+		want       string
+		wantGets   int
+	}{
+		{desc: "local", admin: local, want: "local", wantGets: 1},
+		{desc: "same ID on another cluster", admin: remote, addr: "cluster2:8090", want: "remote", wantGets: 1},
+		{desc: "local cached", admin: local, want: "local", wantGets: 1},
+		{desc: "remote invalidated", invalidate: "cluster2:8090", admin: remote, addr: "cluster2:8090", want: "remote", wantGets: 2},
+		{desc: "local still cached", admin: local, want: "local", wantGets: 1},
+	} {
+		if tc.invalidate != "" {
+			c.invalidate(tc.invalidate, 1)
+		}
+		got, err := c.get(ctx, tc.admin, tc.addr, 1)
+		if err != nil {
+			t.Fatalf("%v: get(): %v", tc.desc, err)
+		}
+		if got.GetDisplayName() != tc.want {
+			t.Errorf("%v: get(): %v, want %v", tc.desc, got.GetDisplayName(), tc.want)
+		}
+		if tc.admin.gets != tc.wantGets {
+			t.Errorf("%v: GetTree calls: %v, want %v", tc.desc, tc.admin.gets, tc.wantGets)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+var (
+	// hashStrategies lists the hash strategies that clients can verify for
+	// each tree type.
+	hashStrategies = map[tpb.TreeType]map[tpb.HashStrategy]bool{
+		tpb.TreeType_LOG: {
+			tpb.HashStrategy_RFC6962_SHA256:        true,
+			tpb.HashStrategy_OBJECT_RFC6962_SHA256: true,
+		},
+		tpb.TreeType_MAP: {
+			tpb.HashStrategy_CONIKS_SHA512_256: true,
+		},
+	}
+	// defaultRSABits is the key size used when RSA is requested without a
+	// key specification.
+	defaultRSABits = int32(2048)
+)
+
+// treeArgs returns a copy of defaults with the fields set in spec applied.
+func treeArgs(defaults *tpb.CreateTreeRequest, spec *pb.TreeSpec, description string) (*tpb.CreateTreeRequest, error) {
+	args := proto.Clone(defaults).(*tpb.CreateTreeRequest)
+	args.Tree.Description = description
+	if spec == nil {
+		return args, nil
+	}
+	treeType := args.Tree.TreeType
+
+	if h := spec.GetHashStrategy(); h != tpb.HashStrategy_UNKNOWN_HASH_STRATEGY {
+		if !hashStrategies[treeType][h] {
+			return nil, status.Errorf(codes.InvalidArgument, "hash strategy %v is not supported for %v trees", h, treeType)
+		}
+		args.Tree.HashStrategy = h
+	}
+
+	sigAlg := spec.GetSignatureAlgorithm()
+	keySpec := spec.GetKeySpec()
+	switch {
+	case keySpec != nil && sigAlg == sigpb.DigitallySigned_ANONYMOUS:
+		// Infer the signature algorithm from the key.
+		alg, err := signatureAlgorithm(keySpec)
+		if err != nil {
+			return nil, err
+		}
+		args.Tree.SignatureAlgorithm = alg
+		args.KeySpec = keySpec
+	case keySpec != nil:
+		alg, err := signatureAlgorithm(keySpec)
+		if err != nil {
+			return nil, err
+		}
+		if alg != sigAlg {
+			return nil, status.Errorf(codes.InvalidArgument, "key spec is for %v, not %v", alg, sigAlg)
+		}
+		args.Tree.SignatureAlgorithm = sigAlg
+		args.KeySpec = keySpec
+	case sigAlg != sigpb.DigitallySigned_ANONYMOUS:
+		// Use a default key for the requested signature algorithm.
+		ks, err := defaultKeySpec(sigAlg)
+		if err != nil {
+			return nil, err
+		}
+		args.Tree.SignatureAlgorithm = sigAlg
+		args.KeySpec = ks
+	}
+
+	if d := spec.GetMaxRootDuration(); d != nil {
+		maxRootDuration, err := ptypes.Duration(d)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "max_root_duration: %v", err)
+		}
+		if maxRootDuration < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "max_root_duration must not be negative")
+		}
+		args.Tree.MaxRootDuration = d
+	}
+	return args, nil
+}
+
+// sameTreeSpec returns true if t has the hash strategy, signature algorithm
+// and max root duration of want.
+func sameTreeSpec(t, want *tpb.Tree) bool {
+	return t.GetHashStrategy() == want.GetHashStrategy() &&
+		t.GetSignatureAlgorithm() == want.GetSignatureAlgorithm() &&
+		t.GetMaxRootDuration().GetSeconds() == want.GetMaxRootDuration().GetSeconds() &&
+		t.GetMaxRootDuration().GetNanos() == want.GetMaxRootDuration().GetNanos()
+}
+
+// withTreeKey returns a copy of args whose private key has been generated by
+// gen from args.KeySpec, so that Trillian does not generate it. args is
+// returned unchanged if gen is nil.
+func withTreeKey(ctx context.Context, gen keys.ProtoGenerator, args *tpb.CreateTreeRequest) (*tpb.CreateTreeRequest, error) {
+	if gen == nil || args.GetKeySpec() == nil {
+		return args, nil
+	}
+	key, err := gen(ctx, args.GetKeySpec())
+	if err != nil {
+		return nil, fmt.Errorf("tree keygen: %w", err)
+	}
+	anyKey, err := ptypes.MarshalAny(key)
+	if err != nil {
+		return nil, err
+	}
+	args = proto.Clone(args).(*tpb.CreateTreeRequest)
+	args.Tree.PrivateKey = anyKey
+	args.KeySpec = nil
+	return args, nil
+}
+
+// newTreeKey generates a signing key for a tree that signs with alg. The
+// key is generated by gen, or in-process if gen is nil. It returns the
+// private key in the form UpdateTree accepts, and its public key.
+func newTreeKey(ctx context.Context, gen keys.ProtoGenerator, alg sigpb.DigitallySigned_SignatureAlgorithm) (*any.Any, *keyspb.PublicKey, error) {
+	spec, err := defaultKeySpec(alg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if gen == nil {
+		gen = localKeyGen
+	}
+	key, err := gen(ctx, spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tree keygen: %w", err)
+	}
+	signer, err := keys.NewSigner(ctx, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keys.NewSigner(): %w", err)
+	}
+	publicKey, err := der.ToPublicProto(signer.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	anyKey, err := ptypes.MarshalAny(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return anyKey, publicKey, nil
+}
+
+// signatureAlgorithm returns the signature algorithm used by keys of spec.
+func signatureAlgorithm(spec *keyspb.Specification) (sigpb.DigitallySigned_SignatureAlgorithm, error) {
+	switch p := spec.GetParams().(type) {
+	case *keyspb.Specification_EcdsaParams:
+		if p.EcdsaParams.GetCurve() == keyspb.Specification_ECDSA_DEFAULT_CURVE {
+			return sigpb.DigitallySigned_ANONYMOUS, status.Errorf(codes.InvalidArgument, "key spec must specify an ECDSA curve")
+		}
+		return sigpb.DigitallySigned_ECDSA, nil
+	case *keyspb.Specification_RsaParams:
+		if p.RsaParams.GetBits() != 0 && p.RsaParams.GetBits() < defaultRSABits {
+			return sigpb.DigitallySigned_ANONYMOUS, status.Errorf(codes.InvalidArgument, "RSA keys must be at least %v bits", defaultRSABits)
+		}
+		return sigpb.DigitallySigned_RSA, nil
+	default:
+		return sigpb.DigitallySigned_ANONYMOUS, status.Errorf(codes.InvalidArgument, "unsupported key spec %v", spec)
+	}
+}
+
+// defaultKeySpec returns the key specification used for alg when none is given.
+func defaultKeySpec(alg sigpb.DigitallySigned_SignatureAlgorithm) (*keyspb.Specification, error) {
+	switch alg {
+	case sigpb.DigitallySigned_ECDSA:
+		return &keyspb.Specification{
+			Params: &keyspb.Specification_EcdsaParams{
+				EcdsaParams: &keyspb.Specification_ECDSA{
+					Curve: keyspb.Specification_ECDSA_P256,
+				},
+			},
+		}, nil
+	case sigpb.DigitallySigned_RSA:
+		return &keyspb.Specification{
+			Params: &keyspb.Specification_RsaParams{
+				RsaParams: &keyspb.Specification_RSA{
+					Bits: defaultRSABits,
+				},
+			},
+		}, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported signature algorithm %v", alg)
+	}
+}
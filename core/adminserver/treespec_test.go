@@ -0,0 +1,116 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+func TestTreeArgs(t *testing.T) {
+	p384 := &keyspb.Specification{
+		Params: &keyspb.Specification_EcdsaParams{
+			EcdsaParams: &keyspb.Specification_ECDSA{
+				Curve: keyspb.Specification_ECDSA_P384,
+			},
+		},
+	}
+	for _, tc := range []struct {
+		desc     string
+		defaults *tpb.CreateTreeRequest
+		spec     *pb.TreeSpec
+		wantErr  bool
+		wantAlg  sigpb.DigitallySigned_SignatureAlgorithm
+		wantHash tpb.HashStrategy
+	}{
+		{desc: "defaults", defaults: logArgs,
+			wantAlg: sigpb.DigitallySigned_ECDSA, wantHash: tpb.HashStrategy_OBJECT_RFC6962_SHA256},
+		{desc: "log hasher", defaults: logArgs,
+			spec:    &pb.TreeSpec{HashStrategy: tpb.HashStrategy_RFC6962_SHA256},
+			wantAlg: sigpb.DigitallySigned_ECDSA, wantHash: tpb.HashStrategy_RFC6962_SHA256},
+		{desc: "log hasher for map", defaults: mapArgs,
+			spec: &pb.TreeSpec{HashStrategy: tpb.HashStrategy_RFC6962_SHA256}, wantErr: true},
+		{desc: "curve", defaults: mapArgs, spec: &pb.TreeSpec{KeySpec: p384},
+			wantAlg: sigpb.DigitallySigned_ECDSA, wantHash: tpb.HashStrategy_CONIKS_SHA512_256},
+		{desc: "rsa default key", defaults: logArgs,
+			spec:    &pb.TreeSpec{SignatureAlgorithm: sigpb.DigitallySigned_RSA},
+			wantAlg: sigpb.DigitallySigned_RSA, wantHash: tpb.HashStrategy_OBJECT_RFC6962_SHA256},
+		{desc: "mismatched key", defaults: logArgs,
+			spec:    &pb.TreeSpec{SignatureAlgorithm: sigpb.DigitallySigned_RSA, KeySpec: p384},
+			wantErr: true},
+		{desc: "negative duration", defaults: logArgs,
+			spec:    &pb.TreeSpec{MaxRootDuration: ptypes.DurationProto(-time.Second)},
+			wantErr: true},
+	} {
+		before := proto.Clone(tc.defaults)
+		args, err := treeArgs(tc.defaults, tc.spec, "desc")
+		if !proto.Equal(before, tc.defaults) {
+			t.Errorf("%v: treeArgs() modified defaults", tc.desc)
+		}
+		if got, want := err != nil, tc.wantErr; got != want {
+			t.Errorf("%v: treeArgs(): %v, wantErr %v", tc.desc, err, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := args.Tree.SignatureAlgorithm, tc.wantAlg; got != want {
+			t.Errorf("%v: SignatureAlgorithm: %v, want %v", tc.desc, got, want)
+		}
+		if got, want := args.Tree.HashStrategy, tc.wantHash; got != want {
+			t.Errorf("%v: HashStrategy: %v, want %v", tc.desc, got, want)
+		}
+	}
+}
+
+func TestWithTreeKey(t *testing.T) {
+	ctx := context.Background()
+	gen := func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+		return &keyspb.PrivateKey{Der: []byte("key")}, nil
+	}
+	for _, tc := range []struct {
+		desc    string
+		gen     keys.ProtoGenerator
+		wantKey bool
+	}{
+		{desc: "trillian generated"},
+		{desc: "generated", gen: gen, wantKey: true},
+	} {
+		before := proto.Clone(logArgs)
+		args, err := withTreeKey(ctx, tc.gen, logArgs)
+		if err != nil {
+			t.Errorf("%v: withTreeKey(): %v", tc.desc, err)
+			continue
+		}
+		if !proto.Equal(before, logArgs) {
+			t.Errorf("%v: withTreeKey() modified args", tc.desc)
+		}
+		if got := args.Tree.PrivateKey != nil; got != tc.wantKey {
+			t.Errorf("%v: PrivateKey set: %v, want %v", tc.desc, got, tc.wantKey)
+		}
+		if got := args.KeySpec == nil; got != tc.wantKey {
+			t.Errorf("%v: KeySpec cleared: %v, want %v", tc.desc, got, tc.wantKey)
+		}
+	}
+}
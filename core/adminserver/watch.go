@@ -0,0 +1,154 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// defaultWatchInterval is how often WatchDomains polls storage when
+// Options.WatchInterval is not set.
+const defaultWatchInterval = 5 * time.Second
+
+// domainChange is a change to a domain between two snapshots of storage.
+type domainChange struct {
+	typ      pb.DomainEvent_Type
+	domainID string
+	// d is the new configuration of the domain. It is nil if the domain was
+	// removed from storage.
+	d *domain.Domain
+}
+
+// WatchDomains streams changes to the configuration of domains. Storage is
+// polled rather than notified so that changes made through any replica of the
+// admin server are observed.
+func (s *Server) WatchDomains(in *pb.WatchDomainsRequest, stream pb.KeyTransparencyAdmin_WatchDomainsServer) error {
+	ctx := stream.Context()
+	interval := s.watchInterval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	prev, err := s.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if in.GetInitial() {
+		if err := s.sendChanges(ctx, stream, domainChanges(nil, prev)); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		next, err := s.snapshot(ctx)
+		if err != nil {
+			// Keep watching through transient storage errors.
+			glog.Errorf("WatchDomains: %v", err)
+			continue
+		}
+		if err := s.sendChanges(ctx, stream, domainChanges(prev, next)); err != nil {
+			return err
+		}
+		prev = next
+	}
+}
+
+// snapshot returns copies of all domains, including deleted ones, by ID.
+// Copies are taken so that storage implementations which return shared
+// values can still be compared between snapshots. Stats are cleared because
+// they change with every epoch and are not configuration.
+func (s *Server) snapshot(ctx context.Context) (map[string]*domain.Domain, error) {
+	domains, err := s.domains.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]*domain.Domain, len(domains))
+	for _, d := range domains {
+		c := *d
+		c.Stats = domain.Stats{}
+		ret[d.DomainID] = &c
+	}
+	return ret, nil
+}
+
+// domainChanges returns the changes between the prev and next snapshots,
+// ordered by domain ID. Domains that become active, by being created or
+// undeleted, are CREATED. Active domains that change are UPDATED. Domains that
+// stop being active are DELETED. Changes to deleted domains are not reported.
+func domainChanges(prev, next map[string]*domain.Domain) []domainChange {
+	var changes []domainChange
+	for id, n := range next {
+		p, ok := prev[id]
+		wasActive := ok && !p.Deleted
+		switch {
+		case !n.Deleted && !wasActive:
+			changes = append(changes, domainChange{typ: pb.DomainEvent_CREATED, domainID: id, d: n})
+		case !n.Deleted && !reflect.DeepEqual(p, n):
+			changes = append(changes, domainChange{typ: pb.DomainEvent_UPDATED, domainID: id, d: n})
+		case n.Deleted && wasActive:
+			changes = append(changes, domainChange{typ: pb.DomainEvent_DELETED, domainID: id, d: n})
+		}
+	}
+	for id, p := range prev {
+		if _, ok := next[id]; !ok && !p.Deleted {
+			changes = append(changes, domainChange{typ: pb.DomainEvent_DELETED, domainID: id})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].domainID < changes[j].domainID })
+	return changes
+}
+
+// sendChanges sends an event on stream for each of changes.
+func (s *Server) sendChanges(ctx context.Context, stream pb.KeyTransparencyAdmin_WatchDomainsServer, changes []domainChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := ptypes.TimestampNow()
+	for _, c := range changes {
+		info := &pb.Domain{DomainId: c.domainID, Deleted: true}
+		if c.d != nil {
+			d, err := s.fetchDomain(ctx, c.d)
+			if err != nil && c.typ != pb.DomainEvent_DELETED {
+				glog.Errorf("WatchDomains: fetchDomain(%v): %v", c.domainID, err)
+				return err
+			}
+			// The trees of deleted domains may already be gone, in which
+			// case only the domain ID is sent.
+			if err == nil {
+				info = d
+			}
+		}
+		if err := stream.Send(&pb.DomainEvent{Type: c.typ, Domain: info, Time: now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
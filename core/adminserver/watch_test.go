@@ -0,0 +1,154 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/domain"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// watchStream records the events sent by WatchDomains.
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*pb.DomainEvent
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(e *pb.DomainEvent) error {
+	w.events = append(w.events, e)
+	return nil
+}
+
+func TestDomainChanges(t *testing.T) {
+	active := &domain.Domain{DomainID: "a", MinInterval: time.Second}
+	updated := &domain.Domain{DomainID: "a", MinInterval: time.Minute}
+	deleted := &domain.Domain{DomainID: "a", MinInterval: time.Second, Deleted: true}
+	other := &domain.Domain{DomainID: "b"}
+	for _, tc := range []struct {
+		desc       string
+		prev, next map[string]*domain.Domain
+		want       []pb.DomainEvent_Type
+	}{
+		{desc: "unchanged", prev: map[string]*domain.Domain{"a": active}, next: map[string]*domain.Domain{"a": active}},
+		{desc: "created", next: map[string]*domain.Domain{"a": active}, want: []pb.DomainEvent_Type{pb.DomainEvent_CREATED}},
+		{desc: "updated", prev: map[string]*domain.Domain{"a": active}, next: map[string]*domain.Domain{"a": updated}, want: []pb.DomainEvent_Type{pb.DomainEvent_UPDATED}},
+		{desc: "deleted", prev: map[string]*domain.Domain{"a": active}, next: map[string]*domain.Domain{"a": deleted}, want: []pb.DomainEvent_Type{pb.DomainEvent_DELETED}},
+		{desc: "undeleted", prev: map[string]*domain.Domain{"a": deleted}, next: map[string]*domain.Domain{"a": active}, want: []pb.DomainEvent_Type{pb.DomainEvent_CREATED}},
+		{desc: "removed", prev: map[string]*domain.Domain{"a": active}, want: []pb.DomainEvent_Type{pb.DomainEvent_DELETED}},
+		{desc: "removed deleted", prev: map[string]*domain.Domain{"a": deleted}},
+		{desc: "created deleted", next: map[string]*domain.Domain{"a": deleted}},
+		{desc: "ordered",
+			prev: map[string]*domain.Domain{"a": active},
+			next: map[string]*domain.Domain{"a": deleted, "b": other},
+			want: []pb.DomainEvent_Type{pb.DomainEvent_DELETED, pb.DomainEvent_CREATED}},
+	} {
+		var got []pb.DomainEvent_Type
+		for _, c := range domainChanges(tc.prev, tc.next) {
+			got = append(got, c.typ)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: domainChanges(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestWatchDomainsInitial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svr, _ := bundleEnv(t, "watched")
+	gone := &domain.Domain{DomainID: "gone", LogID: 3, MapID: 4}
+	if err := svr.domains.Write(ctx, gone); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if err := svr.domains.SetDelete(ctx, gone.DomainID, true); err != nil {
+		t.Fatalf("SetDelete(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		initial bool
+		want    []string
+	}{
+		{initial: false},
+		{initial: true, want: []string{"watched"}},
+	} {
+		stream := &watchStream{ctx: ctx}
+		err := svr.WatchDomains(&pb.WatchDomainsRequest{Initial: tc.initial}, stream)
+		if err != context.Canceled {
+			t.Errorf("WatchDomains(): %v, want %v", err, context.Canceled)
+		}
+		var got []string
+		for _, e := range stream.events {
+			if e.GetType() != pb.DomainEvent_CREATED {
+				t.Errorf("WatchDomains(%v): %v event, want CREATED", e.GetDomain().GetDomainId(), e.GetType())
+			}
+			if e.GetDomain().GetLog() == nil || e.GetDomain().GetMap() == nil {
+				t.Errorf("WatchDomains(%v): event is missing trees", e.GetDomain().GetDomainId())
+			}
+			got = append(got, e.GetDomain().GetDomainId())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("WatchDomains(initial: %v): %v, want %v", tc.initial, got, tc.want)
+		}
+	}
+}
+
+func TestSendChanges(t *testing.T) {
+	ctx := context.Background()
+	svr, d := bundleEnv(t, "watched")
+	missing := &domain.Domain{DomainID: "missing", LogID: 3, MapID: 4}
+	for _, tc := range []struct {
+		desc    string
+		change  domainChange
+		wantErr bool
+		want    *pb.Domain
+	}{
+		{desc: "created", change: domainChange{typ: pb.DomainEvent_CREATED, domainID: d.DomainID, d: d}},
+		{desc: "created without trees", change: domainChange{typ: pb.DomainEvent_CREATED, domainID: "missing", d: missing}, wantErr: true},
+		{desc: "deleted without trees", change: domainChange{typ: pb.DomainEvent_DELETED, domainID: "missing", d: missing},
+			want: &pb.Domain{DomainId: "missing", Deleted: true}},
+		{desc: "removed", change: domainChange{typ: pb.DomainEvent_DELETED, domainID: "removed"},
+			want: &pb.Domain{DomainId: "removed", Deleted: true}},
+	} {
+		stream := &watchStream{ctx: ctx}
+		err := svr.sendChanges(ctx, stream, []domainChange{tc.change})
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: sendChanges(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		if got := len(stream.events); got != 1 {
+			t.Errorf("%v: sendChanges() sent %v events, want 1", tc.desc, got)
+			continue
+		}
+		e := stream.events[0]
+		if e.GetType() != tc.change.typ || e.GetTime() == nil {
+			t.Errorf("%v: sendChanges(): %v, want type %v with a time", tc.desc, e, tc.change.typ)
+		}
+		if tc.want != nil && !reflect.DeepEqual(e.GetDomain(), tc.want) {
+			t.Errorf("%v: sendChanges(): %v, want %v", tc.desc, e.GetDomain(), tc.want)
+		}
+	}
+}
@@ -15,6 +15,13 @@ It is generated from these files:
 It has these top-level messages:
 	GetStateRequest
 	State
+	GetSigningKeysRequest
+	SigningKey
+	GetSigningKeysResponse
+	VerificationError
+	VerificationResult
+	ListFailuresRequest
+	ListFailuresResponse
 */
 package monitor_proto
 
@@ -22,9 +29,12 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "google.golang.org/genproto/googleapis/api/annotations"
-import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/any"
+import google_protobuf2 "github.com/golang/protobuf/ptypes/timestamp"
 import google_rpc "google.golang.org/genproto/googleapis/rpc/status"
 import trillian "github.com/google/trillian"
+import keyspb "github.com/google/trillian/crypto/keyspb"
+import google_keytransparency_v11 "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 
 import (
 	context "golang.org/x/net/context"
@@ -42,6 +52,80 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// Code identifies the check that failed.
+type VerificationError_Code int32
+
+const (
+	VerificationError_UNKNOWN VerificationError_Code = 0
+	// INCONSISTENT_PROOFS: the server returned different hashes for the
+	// same node in several map inclusion proofs.
+	VerificationError_INCONSISTENT_PROOFS           VerificationError_Code = 1
+	VerificationError_INVALID_LOG_CONSISTENCY_PROOF VerificationError_Code = 2
+	VerificationError_INVALID_LOG_INCLUSION         VerificationError_Code = 3
+	VerificationError_INVALID_MAP_SIGNATURE         VerificationError_Code = 4
+	VerificationError_INVALID_MAP_INCLUSION         VerificationError_Code = 5
+	// INVALID_LEAF: a leaf could not be decoded or encoded.
+	VerificationError_INVALID_LEAF         VerificationError_Code = 6
+	VerificationError_INVALID_MUTATION     VerificationError_Code = 7
+	VerificationError_KEY_POLICY_VIOLATION VerificationError_Code = 8
+	// NOT_MATCHING_MAP_ROOT: the map root recreated from the mutations
+	// differs from the one the server signed.
+	VerificationError_NOT_MATCHING_MAP_ROOT VerificationError_Code = 9
+	// STALE_MAP_ROOT: consecutive map roots are further apart than the
+	// map's max root duration.
+	VerificationError_STALE_MAP_ROOT VerificationError_Code = 10
+	// UNKNOWN_MUTATION_TYPE: the monitor cannot apply a mutation.
+	VerificationError_UNKNOWN_MUTATION_TYPE VerificationError_Code = 11
+	// UNVERIFIABLE_MAP_ROOT: the map root cannot be recreated because of
+	// mutations of unknown types.
+	VerificationError_UNVERIFIABLE_MAP_ROOT VerificationError_Code = 12
+	VerificationError_INVALID_LOG_SIGNATURE VerificationError_Code = 13
+	// CHANGED_MAP_ROOT: the server served a different map root for a
+	// revision the monitor already verified. Purging committed data must
+	// not change map roots, since only the data is withheld.
+	VerificationError_CHANGED_MAP_ROOT VerificationError_Code = 14
+)
+
+var VerificationError_Code_name = map[int32]string{
+	0:  "UNKNOWN",
+	1:  "INCONSISTENT_PROOFS",
+	2:  "INVALID_LOG_CONSISTENCY_PROOF",
+	3:  "INVALID_LOG_INCLUSION",
+	4:  "INVALID_MAP_SIGNATURE",
+	5:  "INVALID_MAP_INCLUSION",
+	6:  "INVALID_LEAF",
+	7:  "INVALID_MUTATION",
+	8:  "KEY_POLICY_VIOLATION",
+	9:  "NOT_MATCHING_MAP_ROOT",
+	10: "STALE_MAP_ROOT",
+	11: "UNKNOWN_MUTATION_TYPE",
+	12: "UNVERIFIABLE_MAP_ROOT",
+	13: "INVALID_LOG_SIGNATURE",
+	14: "CHANGED_MAP_ROOT",
+}
+var VerificationError_Code_value = map[string]int32{
+	"UNKNOWN":                       0,
+	"INCONSISTENT_PROOFS":           1,
+	"INVALID_LOG_CONSISTENCY_PROOF": 2,
+	"INVALID_LOG_INCLUSION":         3,
+	"INVALID_MAP_SIGNATURE":         4,
+	"INVALID_MAP_INCLUSION":         5,
+	"INVALID_LEAF":                  6,
+	"INVALID_MUTATION":              7,
+	"KEY_POLICY_VIOLATION":          8,
+	"NOT_MATCHING_MAP_ROOT":         9,
+	"STALE_MAP_ROOT":                10,
+	"UNKNOWN_MUTATION_TYPE":         11,
+	"UNVERIFIABLE_MAP_ROOT":         12,
+	"INVALID_LOG_SIGNATURE":         13,
+	"CHANGED_MAP_ROOT":              14,
+}
+
+func (x VerificationError_Code) String() string {
+	return proto.EnumName(VerificationError_Code_name, int32(x))
+}
+func (VerificationError_Code) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
+
 // GetStateRequest requests the verification state of a keytransparency domain
 // for a particular point in time.
 type GetStateRequest struct {
@@ -90,10 +174,13 @@ type State struct {
 	Smr *trillian.SignedMapRoot `protobuf:"bytes,1,opt,name=smr" json:"smr,omitempty"`
 	// seen_time contains the time when this particular signed map root was
 	// retrieved and processed.
-	SeenTime *google_protobuf1.Timestamp `protobuf:"bytes,2,opt,name=seen_time,json=seenTime" json:"seen_time,omitempty"`
+	SeenTime *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=seen_time,json=seenTime" json:"seen_time,omitempty"`
 	// errors contains a list of errors representing the verification checks
 	// that failed while monitoring the key-transparency server.
 	Errors []*google_rpc.Status `protobuf:"bytes,3,rep,name=errors" json:"errors,omitempty"`
+	// verification_errors describes the checks in errors with structured
+	// codes.
+	VerificationErrors []*VerificationError `protobuf:"bytes,4,rep,name=verification_errors,json=verificationErrors" json:"verification_errors,omitempty"`
 }
 
 func (m *State) Reset()                    { *m = State{} }
@@ -108,7 +195,7 @@ func (m *State) GetSmr() *trillian.SignedMapRoot {
 	return nil
 }
 
-func (m *State) GetSeenTime() *google_protobuf1.Timestamp {
+func (m *State) GetSeenTime() *google_protobuf2.Timestamp {
 	if m != nil {
 		return m.SeenTime
 	}
@@ -122,9 +209,257 @@ func (m *State) GetErrors() []*google_rpc.Status {
 	return nil
 }
 
+func (m *State) GetVerificationErrors() []*VerificationError {
+	if m != nil {
+		return m.VerificationErrors
+	}
+	return nil
+}
+
+// GetSigningKeysRequest requests the keys the monitor signs map roots with.
+type GetSigningKeysRequest struct {
+}
+
+func (m *GetSigningKeysRequest) Reset()                    { *m = GetSigningKeysRequest{} }
+func (m *GetSigningKeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetSigningKeysRequest) ProtoMessage()               {}
+func (*GetSigningKeysRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// SigningKey is a key the monitor has signed map roots with.
+type SigningKey struct {
+	// public_key verifies the map roots signed with this key.
+	PublicKey *keyspb.PublicKey `protobuf:"bytes,1,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+	// activated is the time the monitor started signing with this key.
+	Activated *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=activated" json:"activated,omitempty"`
+	// retired is the time the monitor stopped signing with this key. It is
+	// unset for the current key.
+	Retired *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=retired" json:"retired,omitempty"`
+}
+
+func (m *SigningKey) Reset()                    { *m = SigningKey{} }
+func (m *SigningKey) String() string            { return proto.CompactTextString(m) }
+func (*SigningKey) ProtoMessage()               {}
+func (*SigningKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *SigningKey) GetPublicKey() *keyspb.PublicKey {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *SigningKey) GetActivated() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Activated
+	}
+	return nil
+}
+
+func (m *SigningKey) GetRetired() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Retired
+	}
+	return nil
+}
+
+// GetSigningKeysResponse lists the keys the monitor has signed with.
+type GetSigningKeysResponse struct {
+	// keys are ordered from oldest to newest. The last key is the one the
+	// monitor currently signs with; map roots signed before a rotation verify
+	// with the key that was active at their seen_time.
+	Keys []*SigningKey `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *GetSigningKeysResponse) Reset()                    { *m = GetSigningKeysResponse{} }
+func (m *GetSigningKeysResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetSigningKeysResponse) ProtoMessage()               {}
+func (*GetSigningKeysResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *GetSigningKeysResponse) GetKeys() []*SigningKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+// VerificationError is a verification check that failed.
+type VerificationError struct {
+	Code VerificationError_Code `protobuf:"varint,1,opt,name=code,enum=google.keytransparency.monitor.v1.VerificationError_Code" json:"code,omitempty"`
+	// message describes the failure for humans.
+	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	// evidence holds the data the check failed on, so that others can repeat
+	// it.
+	Evidence []*google_protobuf1.Any `protobuf:"bytes,3,rep,name=evidence" json:"evidence,omitempty"`
+}
+
+func (m *VerificationError) Reset()                    { *m = VerificationError{} }
+func (m *VerificationError) String() string            { return proto.CompactTextString(m) }
+func (*VerificationError) ProtoMessage()               {}
+func (*VerificationError) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *VerificationError) GetCode() VerificationError_Code {
+	if m != nil {
+		return m.Code
+	}
+	return VerificationError_UNKNOWN
+}
+
+func (m *VerificationError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *VerificationError) GetEvidence() []*google_protobuf1.Any {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+// VerificationResult is the monitor's verification of the transition of a
+// map to a revision, in a form that can be stored, signed and exchanged.
+type VerificationResult struct {
+	// revision is the map revision that was verified.
+	Revision int64 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	// smr is the map root signed with the monitor's key if all checks passed,
+	// and unset otherwise.
+	Smr *trillian.SignedMapRoot `protobuf:"bytes,2,opt,name=smr" json:"smr,omitempty"`
+	// seen_time is the time the monitor received the mutations of revision.
+	SeenTime *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=seen_time,json=seenTime" json:"seen_time,omitempty"`
+	// errors lists the checks that failed.
+	Errors []*VerificationError `protobuf:"bytes,4,rep,name=errors" json:"errors,omitempty"`
+	// sequencer_version is the version of the sequencer that created the
+	// revision, as recorded in the map root's metadata.
+	SequencerVersion *google_keytransparency_v11.ServerVersion `protobuf:"bytes,5,opt,name=sequencer_version,json=sequencerVersion" json:"sequencer_version,omitempty"`
+}
+
+func (m *VerificationResult) Reset()                    { *m = VerificationResult{} }
+func (m *VerificationResult) String() string            { return proto.CompactTextString(m) }
+func (*VerificationResult) ProtoMessage()               {}
+func (*VerificationResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *VerificationResult) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *VerificationResult) GetSmr() *trillian.SignedMapRoot {
+	if m != nil {
+		return m.Smr
+	}
+	return nil
+}
+
+func (m *VerificationResult) GetSeenTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.SeenTime
+	}
+	return nil
+}
+
+func (m *VerificationResult) GetErrors() []*VerificationError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+func (m *VerificationResult) GetSequencerVersion() *google_keytransparency_v11.ServerVersion {
+	if m != nil {
+		return m.SequencerVersion
+	}
+	return nil
+}
+
+// ListFailuresRequest requests the monitor's results for the revisions that
+// failed verification.
+type ListFailuresRequest struct {
+	// kt_url is the URL of the keytransparency server being monitored.
+	KtUrl string `protobuf:"bytes,1,opt,name=kt_url,json=ktUrl" json:"kt_url,omitempty"`
+	// domain_id identifies the merkle tree being monitored.
+	DomainId string `protobuf:"bytes,2,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// start is the first revision to look for failures at.
+	Start int64 `protobuf:"varint,3,opt,name=start" json:"start,omitempty"`
+	// page_size is the maximum number of results to return.
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+}
+
+func (m *ListFailuresRequest) Reset()                    { *m = ListFailuresRequest{} }
+func (m *ListFailuresRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListFailuresRequest) ProtoMessage()               {}
+func (*ListFailuresRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ListFailuresRequest) GetKtUrl() string {
+	if m != nil {
+		return m.KtUrl
+	}
+	return ""
+}
+
+func (m *ListFailuresRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *ListFailuresRequest) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *ListFailuresRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+// ListFailuresResponse lists the monitor's results for failed revisions.
+type ListFailuresResponse struct {
+	// results are the failed verifications, ordered by revision.
+	Results []*VerificationResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	// next_start is the revision to request the next page from. It is 0 if
+	// there are no more results.
+	NextStart int64 `protobuf:"varint,2,opt,name=next_start,json=nextStart" json:"next_start,omitempty"`
+}
+
+func (m *ListFailuresResponse) Reset()                    { *m = ListFailuresResponse{} }
+func (m *ListFailuresResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListFailuresResponse) ProtoMessage()               {}
+func (*ListFailuresResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *ListFailuresResponse) GetResults() []*VerificationResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *ListFailuresResponse) GetNextStart() int64 {
+	if m != nil {
+		return m.NextStart
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*GetStateRequest)(nil), "google.keytransparency.monitor.v1.GetStateRequest")
 	proto.RegisterType((*State)(nil), "google.keytransparency.monitor.v1.State")
+	proto.RegisterType((*GetSigningKeysRequest)(nil), "google.keytransparency.monitor.v1.GetSigningKeysRequest")
+	proto.RegisterType((*SigningKey)(nil), "google.keytransparency.monitor.v1.SigningKey")
+	proto.RegisterType((*GetSigningKeysResponse)(nil), "google.keytransparency.monitor.v1.GetSigningKeysResponse")
+	proto.RegisterType((*VerificationError)(nil), "google.keytransparency.monitor.v1.VerificationError")
+	proto.RegisterType((*VerificationResult)(nil), "google.keytransparency.monitor.v1.VerificationResult")
+	proto.RegisterType((*ListFailuresRequest)(nil), "google.keytransparency.monitor.v1.ListFailuresRequest")
+	proto.RegisterType((*ListFailuresResponse)(nil), "google.keytransparency.monitor.v1.ListFailuresResponse")
+	proto.RegisterEnum("google.keytransparency.monitor.v1.VerificationError_Code", VerificationError_Code_name, VerificationError_Code_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -155,6 +490,12 @@ type MonitorClient interface {
 	// mutations from the previous to the current epoch it won't sign the map root
 	// and additional data will be provided to reproduce the failure.
 	GetStateByRevision(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*State, error)
+	// GetSigningKeys returns the keys the monitor signs map roots with,
+	// including retired keys. Clients poll it to learn about key rotations.
+	GetSigningKeys(ctx context.Context, in *GetSigningKeysRequest, opts ...grpc.CallOption) (*GetSigningKeysResponse, error)
+	// ListFailures returns the monitor's results for the revisions that failed
+	// verification, so that others can repeat the failed checks.
+	ListFailures(ctx context.Context, in *ListFailuresRequest, opts ...grpc.CallOption) (*ListFailuresResponse, error)
 }
 
 type monitorClient struct {
@@ -183,6 +524,24 @@ func (c *monitorClient) GetStateByRevision(ctx context.Context, in *GetStateRequ
 	return out, nil
 }
 
+func (c *monitorClient) GetSigningKeys(ctx context.Context, in *GetSigningKeysRequest, opts ...grpc.CallOption) (*GetSigningKeysResponse, error) {
+	out := new(GetSigningKeysResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.monitor.v1.Monitor/GetSigningKeys", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListFailures(ctx context.Context, in *ListFailuresRequest, opts ...grpc.CallOption) (*ListFailuresResponse, error) {
+	out := new(ListFailuresResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.monitor.v1.Monitor/ListFailures", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Monitor service
 
 type MonitorServer interface {
@@ -203,6 +562,12 @@ type MonitorServer interface {
 	// mutations from the previous to the current epoch it won't sign the map root
 	// and additional data will be provided to reproduce the failure.
 	GetStateByRevision(context.Context, *GetStateRequest) (*State, error)
+	// GetSigningKeys returns the keys the monitor signs map roots with,
+	// including retired keys. Clients poll it to learn about key rotations.
+	GetSigningKeys(context.Context, *GetSigningKeysRequest) (*GetSigningKeysResponse, error)
+	// ListFailures returns the monitor's results for the revisions that failed
+	// verification, so that others can repeat the failed checks.
+	ListFailures(context.Context, *ListFailuresRequest) (*ListFailuresResponse, error)
 }
 
 func RegisterMonitorServer(s *grpc.Server, srv MonitorServer) {
@@ -245,6 +610,42 @@ func _Monitor_GetStateByRevision_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Monitor_GetSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSigningKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.monitor.v1.Monitor/GetSigningKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetSigningKeys(ctx, req.(*GetSigningKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListFailures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailuresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListFailures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.monitor.v1.Monitor/ListFailures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListFailures(ctx, req.(*ListFailuresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Monitor_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "google.keytransparency.monitor.v1.Monitor",
 	HandlerType: (*MonitorServer)(nil),
@@ -257,6 +658,14 @@ var _Monitor_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetStateByRevision",
 			Handler:    _Monitor_GetStateByRevision_Handler,
 		},
+		{
+			MethodName: "GetSigningKeys",
+			Handler:    _Monitor_GetSigningKeys_Handler,
+		},
+		{
+			MethodName: "ListFailures",
+			Handler:    _Monitor_ListFailures_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "monitor/v1/monitor_proto/monitor.proto",
@@ -265,34 +674,76 @@ var _Monitor_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("monitor/v1/monitor_proto/monitor.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 451 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x53, 0xcd, 0xaa, 0xd3, 0x40,
-	0x14, 0x26, 0x0d, 0xad, 0xed, 0x14, 0x14, 0x06, 0xe5, 0x96, 0x2a, 0x58, 0xbb, 0x90, 0xea, 0x62,
-	0x86, 0x1b, 0x17, 0x82, 0x0b, 0x7f, 0xae, 0xe8, 0xc5, 0xc5, 0xdd, 0xa4, 0xba, 0xd1, 0x45, 0x99,
-	0xa6, 0xc7, 0xde, 0xa1, 0xc9, 0x4c, 0x3c, 0x73, 0x52, 0x28, 0xa5, 0x1b, 0x5f, 0xc1, 0x85, 0xaf,
-	0xe1, 0xde, 0xc7, 0xf0, 0x15, 0x7c, 0x0a, 0x57, 0x92, 0x99, 0xe4, 0x22, 0x17, 0x44, 0x11, 0xdc,
-	0x24, 0xf9, 0x72, 0xbe, 0x33, 0xf9, 0xce, 0x77, 0xbe, 0xb0, 0xbb, 0x85, 0x35, 0x9a, 0x2c, 0xca,
-	0xed, 0xb1, 0x6c, 0x1e, 0x17, 0x25, 0x5a, 0xb2, 0x2d, 0x12, 0x1e, 0xf1, 0x3b, 0x6b, 0x6b, 0xd7,
-	0x39, 0x88, 0x0d, 0xec, 0x08, 0x95, 0x71, 0xa5, 0x42, 0x30, 0xd9, 0x4e, 0xb4, 0xac, 0xed, 0xf1,
-	0xf8, 0x56, 0xa0, 0x48, 0x55, 0x6a, 0xa9, 0x8c, 0xb1, 0xa4, 0x48, 0x5b, 0xe3, 0xc2, 0x01, 0xe3,
-	0xdb, 0x4d, 0xd5, 0xa3, 0x65, 0xf5, 0x5e, 0x92, 0x2e, 0xc0, 0x91, 0x2a, 0xca, 0x86, 0x70, 0xd4,
-	0x10, 0xb0, 0xcc, 0xa4, 0x23, 0x45, 0x55, 0xdb, 0x79, 0x95, 0x50, 0xe7, 0xb9, 0x56, 0x26, 0xe0,
-	0xe9, 0x3b, 0x76, 0xed, 0x14, 0x68, 0x4e, 0x8a, 0x20, 0x85, 0x0f, 0x15, 0x38, 0xe2, 0x37, 0x58,
-	0x6f, 0x43, 0x8b, 0x0a, 0xf3, 0x51, 0x67, 0x12, 0xcd, 0x06, 0x69, 0x77, 0x43, 0x6f, 0x30, 0xe7,
-	0x37, 0xd9, 0x60, 0x65, 0x0b, 0xa5, 0xcd, 0x42, 0xaf, 0x46, 0xb1, 0xaf, 0xf4, 0xc3, 0x8b, 0x57,
-	0x2b, 0x7e, 0x9d, 0x75, 0xa1, 0xb4, 0xd9, 0xf9, 0x28, 0x9a, 0x44, 0xb3, 0x38, 0x0d, 0x60, 0xfa,
-	0x39, 0x62, 0x5d, 0x7f, 0x34, 0xbf, 0xc7, 0x62, 0x57, 0xa0, 0xaf, 0x0e, 0x93, 0x23, 0x71, 0x21,
-	0x62, 0xae, 0xd7, 0x06, 0x56, 0x67, 0xaa, 0x4c, 0xad, 0xa5, 0xb4, 0xe6, 0xf0, 0x87, 0x6c, 0xe0,
-	0x00, 0xcc, 0xa2, 0x1e, 0xc9, 0x2b, 0x18, 0x26, 0x63, 0xd1, 0x18, 0xd6, 0xce, 0x2b, 0x5e, 0xb7,
-	0xf3, 0xa6, 0xfd, 0x9a, 0x5c, 0x43, 0x7e, 0x9f, 0xf5, 0x00, 0xd1, 0xa2, 0x1b, 0xc5, 0x93, 0x78,
-	0x36, 0x4c, 0x78, 0xdb, 0x85, 0x65, 0x26, 0xe6, 0xde, 0x84, 0xb4, 0x61, 0x24, 0x3f, 0x3a, 0xec,
-	0xca, 0x59, 0x70, 0x9b, 0x7f, 0x89, 0x58, 0xbf, 0xf5, 0x80, 0x27, 0xe2, 0x8f, 0xbb, 0x11, 0x97,
-	0x0c, 0x1b, 0xcf, 0xfe, 0xa2, 0xc7, 0x37, 0x4c, 0x5f, 0x7e, 0xfc, 0xf6, 0xfd, 0x53, 0xe7, 0x29,
-	0x7f, 0x2c, 0x7f, 0x49, 0x8a, 0x03, 0xdc, 0x02, 0x3a, 0xb9, 0x0f, 0xae, 0x1f, 0x64, 0x70, 0xd5,
-	0xc9, 0xfd, 0x85, 0xdf, 0x07, 0xbf, 0x44, 0x70, 0x8f, 0xf2, 0xfa, 0x4a, 0xfc, 0x6b, 0xc4, 0x78,
-	0xab, 0xe2, 0x64, 0x97, 0xc2, 0x56, 0x3b, 0x6d, 0xcd, 0x7f, 0x16, 0x7f, 0xea, 0xc5, 0x3f, 0xe3,
-	0x4f, 0xfe, 0x51, 0xbc, 0xdc, 0xfb, 0x54, 0x1c, 0x4e, 0x5e, 0xbc, 0x7d, 0xbe, 0xd6, 0x74, 0x5e,
-	0x2d, 0x45, 0x66, 0x0b, 0xd9, 0x24, 0xf5, 0xd2, 0xe7, 0x65, 0x66, 0x31, 0xa4, 0xff, 0x77, 0xff,
-	0xd4, 0xb2, 0xe7, 0x6f, 0x0f, 0x7e, 0x06, 0x00, 0x00, 0xff, 0xff, 0x9b, 0xa9, 0x74, 0x52, 0x76,
-	0x03, 0x00, 0x00,
+	// 1131 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xcf, 0x6e, 0x23, 0x45,
+	0x13, 0xff, 0xc6, 0x7f, 0x12, 0xbb, 0xb3, 0x5f, 0x98, 0x74, 0x12, 0xe2, 0x35, 0xac, 0xc8, 0xce,
+	0x01, 0x19, 0x24, 0x66, 0x36, 0x66, 0x61, 0x37, 0x20, 0x01, 0x13, 0xaf, 0xe3, 0x1d, 0xc5, 0x99,
+	0x89, 0xc6, 0x76, 0x50, 0xd8, 0xc3, 0x68, 0x32, 0xee, 0x78, 0x5b, 0xb1, 0x67, 0x86, 0xee, 0xb6,
+	0x85, 0x37, 0xe4, 0xc2, 0x81, 0x17, 0xe0, 0x01, 0x38, 0x73, 0x41, 0x5c, 0x11, 0x37, 0x5e, 0x81,
+	0x27, 0x40, 0xe2, 0x21, 0x38, 0x21, 0xd4, 0x3d, 0x3d, 0xb6, 0xd7, 0x21, 0xe0, 0x44, 0x70, 0xb1,
+	0x5d, 0x55, 0xbf, 0xaa, 0xae, 0xfa, 0x55, 0xb9, 0xba, 0xc1, 0x9b, 0x83, 0x28, 0xc4, 0x2c, 0x22,
+	0xc6, 0x68, 0xc7, 0x90, 0x3f, 0xbd, 0x98, 0x44, 0x2c, 0x4a, 0x25, 0x5d, 0x48, 0xf0, 0x7e, 0x2f,
+	0x8a, 0x7a, 0x7d, 0xa4, 0x9f, 0xa3, 0x31, 0x23, 0x7e, 0x48, 0x63, 0x9f, 0xa0, 0x30, 0x18, 0xeb,
+	0x29, 0x6a, 0xb4, 0x53, 0x7e, 0x3d, 0x81, 0x18, 0x7e, 0x8c, 0x0d, 0x3f, 0x0c, 0x23, 0xe6, 0x33,
+	0x1c, 0x85, 0x34, 0x09, 0x50, 0xbe, 0x2b, 0xad, 0x42, 0x3a, 0x1d, 0x9e, 0x19, 0x7e, 0x38, 0x96,
+	0xa6, 0x37, 0xe6, 0x4d, 0x0c, 0x0f, 0x10, 0x65, 0xfe, 0x20, 0x96, 0x80, 0x2d, 0x09, 0x20, 0x71,
+	0x60, 0x50, 0xe6, 0xb3, 0x61, 0x1a, 0x74, 0x95, 0x11, 0xdc, 0xef, 0x63, 0x3f, 0x94, 0x72, 0x39,
+	0x20, 0xe3, 0x98, 0x45, 0xc6, 0x39, 0x1a, 0xd3, 0xf8, 0x54, 0x7e, 0x49, 0x9b, 0x3e, 0xda, 0x31,
+	0xe6, 0xb2, 0x97, 0x95, 0xce, 0xd7, 0x24, 0xb4, 0xda, 0x33, 0xf0, 0x4a, 0x03, 0xb1, 0x16, 0xf3,
+	0x19, 0x72, 0xd1, 0xe7, 0x43, 0x44, 0x19, 0xdc, 0x04, 0x4b, 0xe7, 0xcc, 0x1b, 0x92, 0x7e, 0x29,
+	0xb3, 0xad, 0x54, 0x8a, 0x6e, 0xfe, 0x9c, 0x75, 0x48, 0x1f, 0xbe, 0x06, 0x8a, 0xdd, 0x68, 0xe0,
+	0xe3, 0xd0, 0xc3, 0xdd, 0x52, 0x56, 0x58, 0x0a, 0x89, 0xc2, 0xea, 0xc2, 0x0d, 0x90, 0x47, 0x71,
+	0x14, 0x3c, 0x2f, 0x29, 0xdb, 0x4a, 0x25, 0xeb, 0x26, 0x82, 0xf6, 0x87, 0x02, 0xf2, 0x22, 0x34,
+	0x7c, 0x0b, 0x64, 0xe9, 0x80, 0x08, 0xeb, 0x4a, 0x75, 0x4b, 0x9f, 0x14, 0xd4, 0xc2, 0xbd, 0x10,
+	0x75, 0x0f, 0xfd, 0xd8, 0x8d, 0x22, 0xe6, 0x72, 0x0c, 0x7c, 0x04, 0x8a, 0x14, 0xa1, 0xd0, 0xe3,
+	0xf4, 0x88, 0x0c, 0x56, 0xaa, 0x65, 0x5d, 0xf6, 0x25, 0xe5, 0x4e, 0x6f, 0xa7, 0xdc, 0xb9, 0x05,
+	0x0e, 0xe6, 0x22, 0x7c, 0x1b, 0x2c, 0x21, 0x42, 0x22, 0x42, 0x4b, 0xd9, 0xed, 0x6c, 0x65, 0xa5,
+	0x0a, 0x53, 0x2f, 0x12, 0x07, 0x7a, 0x4b, 0x10, 0xea, 0x4a, 0x04, 0x44, 0x60, 0x7d, 0x84, 0x08,
+	0x3e, 0xc3, 0x81, 0x68, 0x9f, 0x27, 0x1d, 0x73, 0xc2, 0xf1, 0xa1, 0xfe, 0x8f, 0x63, 0xa0, 0x1f,
+	0xcf, 0x78, 0xd7, 0xb9, 0xb3, 0x0b, 0x47, 0xf3, 0x2a, 0xaa, 0x6d, 0x81, 0x4d, 0xce, 0x2e, 0xee,
+	0x85, 0x38, 0xec, 0x1d, 0xa0, 0x31, 0x95, 0x1c, 0x6b, 0xdf, 0x2b, 0x00, 0x4c, 0xd5, 0xf0, 0x01,
+	0x00, 0xf1, 0xf0, 0xb4, 0x8f, 0x03, 0xef, 0x1c, 0x8d, 0x25, 0x4b, 0x6b, 0xba, 0x6c, 0xec, 0x91,
+	0xb0, 0x1c, 0xa0, 0xb1, 0x5b, 0x8c, 0xd3, 0x9f, 0xf0, 0x31, 0x28, 0xfa, 0x01, 0xc3, 0x23, 0x9f,
+	0xa1, 0xee, 0x02, 0x2c, 0x4d, 0xc1, 0xf0, 0x21, 0x58, 0x26, 0x88, 0x61, 0x82, 0x92, 0x2e, 0xfe,
+	0xbd, 0x5f, 0x0a, 0xd5, 0x9e, 0x81, 0x57, 0xe7, 0x2b, 0xa1, 0x71, 0x14, 0x52, 0x04, 0x4d, 0x90,
+	0xe3, 0x89, 0x96, 0x14, 0xc1, 0xdd, 0x3b, 0x0b, 0x70, 0x37, 0x8d, 0xe2, 0x0a, 0x57, 0xed, 0xbb,
+	0x1c, 0x58, 0xbb, 0x42, 0x28, 0x3c, 0x04, 0xb9, 0x20, 0xea, 0x22, 0x41, 0xc7, 0x6a, 0x75, 0xf7,
+	0x36, 0x4d, 0xd1, 0x6b, 0x51, 0x17, 0xb9, 0x22, 0x0c, 0x2c, 0x81, 0xe5, 0x01, 0xa2, 0xd4, 0xef,
+	0x21, 0x39, 0xd7, 0xa9, 0x08, 0x1f, 0x80, 0x02, 0x1a, 0xe1, 0x2e, 0x0a, 0x03, 0x24, 0x47, 0x67,
+	0xe3, 0x0a, 0x25, 0x66, 0x38, 0x76, 0x27, 0x28, 0xed, 0xf7, 0x0c, 0xc8, 0xf1, 0xd0, 0x70, 0x05,
+	0x2c, 0x77, 0xec, 0x03, 0xdb, 0xf9, 0xd4, 0x56, 0xff, 0x07, 0xb7, 0xc0, 0xba, 0x65, 0xd7, 0x1c,
+	0xbb, 0x65, 0xb5, 0xda, 0x75, 0xbb, 0xed, 0x1d, 0xb9, 0x8e, 0xb3, 0xdf, 0x52, 0x15, 0x78, 0x1f,
+	0xdc, 0xb3, 0xec, 0x63, 0xb3, 0x69, 0x3d, 0xf1, 0x9a, 0x4e, 0xc3, 0x9b, 0x40, 0x6a, 0x27, 0x09,
+	0x46, 0xcd, 0xc0, 0xbb, 0x60, 0x73, 0x16, 0x62, 0xd9, 0xb5, 0x66, 0xa7, 0x65, 0x39, 0xb6, 0x9a,
+	0x9d, 0x35, 0x1d, 0x9a, 0x47, 0x5e, 0xcb, 0x6a, 0xd8, 0x66, 0xbb, 0xe3, 0xd6, 0xd5, 0xdc, 0xbc,
+	0x69, 0xea, 0x95, 0x87, 0x2a, 0xb8, 0x33, 0x09, 0x58, 0x37, 0xf7, 0xd5, 0x25, 0xb8, 0x01, 0xd4,
+	0x09, 0xb8, 0xd3, 0x36, 0xdb, 0x1c, 0xb7, 0x0c, 0x4b, 0x60, 0xe3, 0xa0, 0x7e, 0xe2, 0x1d, 0x39,
+	0x4d, 0xab, 0x76, 0xe2, 0x1d, 0x5b, 0x4e, 0x33, 0xb1, 0x14, 0x78, 0x70, 0xdb, 0x69, 0x7b, 0x87,
+	0x66, 0xbb, 0xf6, 0xd4, 0xb2, 0x1b, 0xe2, 0x04, 0xd7, 0x71, 0xda, 0x6a, 0x11, 0x42, 0xb0, 0xda,
+	0x6a, 0x9b, 0xcd, 0xfa, 0x54, 0x07, 0x38, 0x5c, 0x52, 0x31, 0x09, 0xef, 0xb5, 0x4f, 0x8e, 0xea,
+	0xea, 0x4a, 0x62, 0x3a, 0xae, 0xbb, 0xd6, 0xbe, 0x65, 0xee, 0xcd, 0x7a, 0xdd, 0x99, 0xaf, 0x7b,
+	0x5a, 0xdc, 0xff, 0x79, 0xbe, 0xb5, 0xa7, 0xa6, 0xdd, 0xa8, 0x3f, 0x99, 0x3a, 0xac, 0x6a, 0x3f,
+	0x66, 0x00, 0x9c, 0xed, 0xb3, 0x8b, 0xe8, 0xb0, 0xcf, 0x60, 0x19, 0x14, 0x08, 0x1a, 0x61, 0x8a,
+	0xa3, 0x50, 0xee, 0xa0, 0x89, 0x9c, 0x2e, 0x9f, 0xcc, 0x4d, 0x97, 0x4f, 0xf6, 0x06, 0xcb, 0xa7,
+	0x09, 0x96, 0xfe, 0x85, 0x1d, 0x92, 0xae, 0xa7, 0x0e, 0x58, 0xa3, 0x7c, 0x53, 0x84, 0x01, 0x22,
+	0xde, 0x08, 0x11, 0x51, 0x56, 0x5e, 0xa4, 0x53, 0xb9, 0x2e, 0x30, 0xff, 0x63, 0x21, 0x32, 0x42,
+	0xe4, 0x38, 0xc1, 0xbb, 0xea, 0x24, 0x84, 0xd4, 0x68, 0x5f, 0x82, 0xf5, 0x26, 0xa6, 0x6c, 0xdf,
+	0xc7, 0xfd, 0x21, 0x41, 0xf4, 0xea, 0xc2, 0x57, 0xae, 0x5d, 0xf8, 0x99, 0xab, 0x0b, 0x9f, 0x32,
+	0x9f, 0x30, 0x41, 0x52, 0xd6, 0x4d, 0x04, 0xee, 0x12, 0xfb, 0x3d, 0xe4, 0x51, 0xfc, 0x02, 0x95,
+	0x72, 0xdb, 0x4a, 0x25, 0xef, 0x16, 0xb8, 0xa2, 0x85, 0x5f, 0x20, 0xed, 0x6b, 0x05, 0x6c, 0xbc,
+	0x7c, 0xbc, 0xdc, 0x20, 0x0e, 0xdf, 0x48, 0xbc, 0x8b, 0xe9, 0x12, 0x79, 0xef, 0x86, 0xe4, 0x25,
+	0x33, 0xe0, 0xa6, 0x51, 0xe0, 0x3d, 0x00, 0x42, 0xf4, 0x05, 0xf3, 0x92, 0x0c, 0x33, 0x22, 0xc3,
+	0x22, 0xd7, 0xb4, 0xb8, 0xa2, 0xfa, 0x6b, 0x1e, 0x2c, 0x1f, 0x26, 0x91, 0xe0, 0x0f, 0x0a, 0x28,
+	0xa4, 0x17, 0x20, 0xac, 0x2e, 0x70, 0xee, 0xdc, 0x6d, 0x59, 0xae, 0x2c, 0xb2, 0xf0, 0xb8, 0x83,
+	0xb6, 0xff, 0xd5, 0x2f, 0xbf, 0x7d, 0x93, 0xf9, 0x04, 0x7e, 0x64, 0xcc, 0xbc, 0x46, 0xa8, 0x68,
+	0x19, 0x35, 0x2e, 0x92, 0x0e, 0x5c, 0x1a, 0x09, 0xc3, 0xd4, 0xb8, 0x98, 0x70, 0x7f, 0x29, 0x5e,
+	0x03, 0x88, 0x7e, 0xd0, 0xe7, 0x9f, 0x0c, 0xfe, 0xa4, 0x00, 0x98, 0x66, 0xb1, 0x37, 0x76, 0xd3,
+	0x29, 0xff, 0x6f, 0x93, 0x6f, 0x88, 0xe4, 0x4d, 0xf8, 0xf1, 0x2d, 0x93, 0x37, 0x2e, 0xc4, 0x93,
+	0xe0, 0x12, 0x7e, 0xab, 0x80, 0xd5, 0x97, 0x6f, 0x12, 0xf8, 0x78, 0xc1, 0xcc, 0xaf, 0x5c, 0xa3,
+	0xe5, 0xdd, 0x5b, 0x78, 0x26, 0x43, 0xa7, 0x95, 0x44, 0x41, 0x10, 0xaa, 0xb3, 0x05, 0xf1, 0xdb,
+	0x08, 0xfe, 0xac, 0x80, 0x3b, 0xb3, 0x73, 0x0a, 0xdf, 0x5f, 0xe0, 0x94, 0xbf, 0xf8, 0x5f, 0x95,
+	0x1f, 0xdd, 0xd8, 0x4f, 0xe6, 0x66, 0x8a, 0xdc, 0x3e, 0x84, 0xbb, 0x37, 0x26, 0xfb, 0x4c, 0x86,
+	0xda, 0xab, 0x7f, 0x56, 0xeb, 0x61, 0xf6, 0x7c, 0x78, 0xaa, 0x07, 0xd1, 0xc0, 0x90, 0x2f, 0xcb,
+	0xb9, 0x3c, 0x8c, 0x20, 0x22, 0xc9, 0x43, 0xf6, 0xba, 0xe7, 0xf1, 0xe9, 0x92, 0xf8, 0x7a, 0xf7,
+	0xcf, 0x01, 0x00, 0xb0, 0x27, 0x02, 0x3f, 0x41, 0x0b, 0x00, 0x00,
 }
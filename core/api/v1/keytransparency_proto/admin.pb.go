@@ -7,10 +7,14 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "google.golang.org/genproto/googleapis/api/annotations"
+import google_protobuf3 "github.com/golang/protobuf/ptypes/any"
 import google_protobuf4 "github.com/golang/protobuf/ptypes/empty"
-import google_protobuf2 "github.com/golang/protobuf/ptypes/duration"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/duration"
+import google_protobuf5 "google.golang.org/genproto/protobuf/field_mask"
+import google_protobuf2 "github.com/golang/protobuf/ptypes/timestamp"
 import trillian "github.com/google/trillian"
 import keyspb "github.com/google/trillian/crypto/keyspb"
+import sigpb "github.com/google/trillian/crypto/sigpb"
 
 import (
 	context "golang.org/x/net/context"
@@ -22,6 +26,60 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// VrfAlgorithm identifies the VRF construction used by a domain.
+type VrfAlgorithm int32
+
+const (
+	// P256 is the discrete log VRF from CONIKS on curve P-256.
+	VrfAlgorithm_P256 VrfAlgorithm = 0
+	// ED25519 is ECVRF-EDWARDS25519-SHA512-TAI.
+	VrfAlgorithm_ED25519 VrfAlgorithm = 1
+)
+
+var VrfAlgorithm_name = map[int32]string{
+	0: "P256",
+	1: "ED25519",
+}
+var VrfAlgorithm_value = map[string]int32{
+	"P256":    0,
+	"ED25519": 1,
+}
+
+func (x VrfAlgorithm) String() string {
+	return proto.EnumName(VrfAlgorithm_name, int32(x))
+}
+func (VrfAlgorithm) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+type DomainEvent_Type int32
+
+const (
+	DomainEvent_UNKNOWN_TYPE DomainEvent_Type = 0
+	// CREATED domains were created or undeleted.
+	DomainEvent_CREATED DomainEvent_Type = 1
+	// UPDATED domains have a new configuration.
+	DomainEvent_UPDATED DomainEvent_Type = 2
+	// DELETED domains were deleted or garbage collected.
+	DomainEvent_DELETED DomainEvent_Type = 3
+)
+
+var DomainEvent_Type_name = map[int32]string{
+	0: "UNKNOWN_TYPE",
+	1: "CREATED",
+	2: "UPDATED",
+	3: "DELETED",
+}
+var DomainEvent_Type_value = map[string]int32{
+	"UNKNOWN_TYPE": 0,
+	"CREATED":      1,
+	"UPDATED":      2,
+	"DELETED":      3,
+}
+
+func (x DomainEvent_Type) String() string {
+	return proto.EnumName(DomainEvent_Type_name, int32(x))
+}
+func (DomainEvent_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{42, 0} }
+
 // Domain contains information on a single domain
 type Domain struct {
 	// DomainId can be any URL safe string.
@@ -33,12 +91,80 @@ type Domain struct {
 	// Vrf contains the VRF public key.
 	Vrf *keyspb.PublicKey `protobuf:"bytes,4,opt,name=vrf" json:"vrf,omitempty"`
 	// min_interval is the minimum time between epochs.
-	MinInterval *google_protobuf2.Duration `protobuf:"bytes,5,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
+	MinInterval *google_protobuf1.Duration `protobuf:"bytes,5,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
 	// max_interval is the maximum time between epochs.
-	MaxInterval *google_protobuf2.Duration `protobuf:"bytes,6,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+	MaxInterval *google_protobuf1.Duration `protobuf:"bytes,6,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
 	// Deleted indicates whether the domain has been marked as deleted.
 	// By its presence in a response, this domain has not been garbage collected.
 	Deleted bool `protobuf:"varint,7,opt,name=deleted" json:"deleted,omitempty"`
+	// app_vrfs contains the VRF public keys of apps that have their own VRF.
+	// Apps that are not listed use vrf.
+	AppVrfs map[string]*keyspb.PublicKey `protobuf:"bytes,8,rep,name=app_vrfs,json=appVrfs" json:"app_vrfs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// previous_vrf contains the VRF public key that was replaced by the most
+	// recent call to RotateDomainVRF. Indexes computed with previous_vrf remain
+	// valid until previous_vrf_expiry.
+	PreviousVrf *keyspb.PublicKey `protobuf:"bytes,9,opt,name=previous_vrf,json=previousVrf" json:"previous_vrf,omitempty"`
+	// previous_vrf_expiry is the end of the overlap window for previous_vrf.
+	PreviousVrfExpiry *google_protobuf2.Timestamp `protobuf:"bytes,10,opt,name=previous_vrf_expiry,json=previousVrfExpiry" json:"previous_vrf_expiry,omitempty"`
+	// key_policy restricts the authorized keys of entries in the domain.
+	// Monitors verify that every mutation complies with it.
+	KeyPolicy *KeyPolicy `protobuf:"bytes,11,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+	// frozen indicates that the domain is read-only. Reads are served but
+	// updates are rejected. Empty epochs are still created once per
+	// max_interval, so that clients and monitors can check freshness.
+	Frozen bool `protobuf:"varint,12,opt,name=frozen" json:"frozen,omitempty"`
+	// app_listing allows users to list the apps they have entries for with
+	// ListUserApps.
+	AppListing bool `protobuf:"varint,13,opt,name=app_listing,json=appListing" json:"app_listing,omitempty"`
+	// mutation_quota limits the rate of updates to the domain.
+	MutationQuota *MutationQuota `protobuf:"bytes,14,opt,name=mutation_quota,json=mutationQuota" json:"mutation_quota,omitempty"`
+	// vrf_algorithm is the algorithm of vrf, previous_vrf and app_vrfs.
+	VrfAlgorithm VrfAlgorithm `protobuf:"varint,15,opt,name=vrf_algorithm,json=vrfAlgorithm,enum=google.keytransparency.v1.VrfAlgorithm" json:"vrf_algorithm,omitempty"`
+	// delete_time is when the domain was deleted. It is only set for deleted
+	// domains.
+	DeleteTime *google_protobuf2.Timestamp `protobuf:"bytes,16,opt,name=delete_time,json=deleteTime" json:"delete_time,omitempty"`
+	// hard_delete_time is the end of the retention window of a deleted domain.
+	// The domain cannot be undeleted after it and becomes eligible for garbage
+	// collection. It is not set if the server keeps deleted domains forever.
+	HardDeleteTime *google_protobuf2.Timestamp `protobuf:"bytes,17,opt,name=hard_delete_time,json=hardDeleteTime" json:"hard_delete_time,omitempty"`
+	// signed_config is this configuration signed by the key server's domain
+	// config key. Clients that trust the key verify it before trusting the
+	// keys above. It is only set by the KeyTransparency GetDomain API.
+	SignedConfig *SignedDomainConfig `protobuf:"bytes,18,opt,name=signed_config,json=signedConfig" json:"signed_config,omitempty"`
+	// trees_deleted indicates whether the log and map trees of a deleted
+	// domain have been deleted in Trillian. Trillian keeps deleted trees for a
+	// while before removing them for good.
+	TreesDeleted bool `protobuf:"varint,19,opt,name=trees_deleted,json=treesDeleted" json:"trees_deleted,omitempty"`
+	// endorsement_policy lists the endorsers that co-sign the map roots of the
+	// domain. Map roots are not endorsed if it is unset. Clients pin it along
+	// with the keys of the domain and reject served policies that are weaker.
+	EndorsementPolicy *EndorsementPolicy `protobuf:"bytes,20,opt,name=endorsement_policy,json=endorsementPolicy" json:"endorsement_policy,omitempty"`
+	// labels are free-form key/value pairs that organize domains. Keys are 1
+	// to 63 characters and values at most 63 characters of [A-Za-z0-9._-], and
+	// keys start with a letter or digit. Labels are only returned by the admin
+	// API.
+	Labels map[string]string `protobuf:"bytes,21,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// log_backend names the service that hosts the log. The log is a tree of
+	// the key server's Trillian instance if it is empty. Clients verify the
+	// log with the hash strategy and public key of log either way.
+	LogBackend string `protobuf:"bytes,22,opt,name=log_backend,json=logBackend" json:"log_backend,omitempty"`
+	// migration announces that the domain is moving to another key server,
+	// other keys, or both. It is signed with the map key of this domain.
+	Migration *SignedDomainMigration `protobuf:"bytes,23,opt,name=migration" json:"migration,omitempty"`
+	// log_key_rotation records the most recent rotation of the signing key of
+	// log. Clients accept log roots signed with either key during the overlap.
+	LogKeyRotation *TreeKeyRotation `protobuf:"bytes,24,opt,name=log_key_rotation,json=logKeyRotation" json:"log_key_rotation,omitempty"`
+	// map_key_rotation records the most recent rotation of the signing key of
+	// map. Clients accept map roots signed with either key during the overlap.
+	MapKeyRotation *TreeKeyRotation `protobuf:"bytes,25,opt,name=map_key_rotation,json=mapKeyRotation" json:"map_key_rotation,omitempty"`
+	// trillian_backend locates the Trillian cluster that hosts the trees of
+	// the domain. It is only returned by the admin API.
+	TrillianBackend *TrillianBackend `protobuf:"bytes,26,opt,name=trillian_backend,json=trillianBackend" json:"trillian_backend,omitempty"`
+	// key_policy_history lists the key policies of the domain with the first
+	// revision each applies to, oldest first. The last one is key_policy.
+	// Monitors check the mutations of each epoch against the policy in force
+	// at its revision; there is no policy before the first change.
+	KeyPolicyHistory []*KeyPolicyChange `protobuf:"bytes,27,rep,name=key_policy_history,json=keyPolicyHistory" json:"key_policy_history,omitempty"`
 }
 
 func (m *Domain) Reset()                    { *m = Domain{} }
@@ -74,14 +200,14 @@ func (m *Domain) GetVrf() *keyspb.PublicKey {
 	return nil
 }
 
-func (m *Domain) GetMinInterval() *google_protobuf2.Duration {
+func (m *Domain) GetMinInterval() *google_protobuf1.Duration {
 	if m != nil {
 		return m.MinInterval
 	}
 	return nil
 }
 
-func (m *Domain) GetMaxInterval() *google_protobuf2.Duration {
+func (m *Domain) GetMaxInterval() *google_protobuf1.Duration {
 	if m != nil {
 		return m.MaxInterval
 	}
@@ -95,18 +221,380 @@ func (m *Domain) GetDeleted() bool {
 	return false
 }
 
+func (m *Domain) GetAppVrfs() map[string]*keyspb.PublicKey {
+	if m != nil {
+		return m.AppVrfs
+	}
+	return nil
+}
+
+func (m *Domain) GetPreviousVrf() *keyspb.PublicKey {
+	if m != nil {
+		return m.PreviousVrf
+	}
+	return nil
+}
+
+func (m *Domain) GetPreviousVrfExpiry() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.PreviousVrfExpiry
+	}
+	return nil
+}
+
+func (m *Domain) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+func (m *Domain) GetFrozen() bool {
+	if m != nil {
+		return m.Frozen
+	}
+	return false
+}
+
+func (m *Domain) GetAppListing() bool {
+	if m != nil {
+		return m.AppListing
+	}
+	return false
+}
+
+func (m *Domain) GetMutationQuota() *MutationQuota {
+	if m != nil {
+		return m.MutationQuota
+	}
+	return nil
+}
+
+func (m *Domain) GetVrfAlgorithm() VrfAlgorithm {
+	if m != nil {
+		return m.VrfAlgorithm
+	}
+	return VrfAlgorithm_P256
+}
+
+func (m *Domain) GetDeleteTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.DeleteTime
+	}
+	return nil
+}
+
+func (m *Domain) GetHardDeleteTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.HardDeleteTime
+	}
+	return nil
+}
+
+func (m *Domain) GetSignedConfig() *SignedDomainConfig {
+	if m != nil {
+		return m.SignedConfig
+	}
+	return nil
+}
+
+func (m *Domain) GetTreesDeleted() bool {
+	if m != nil {
+		return m.TreesDeleted
+	}
+	return false
+}
+
+func (m *Domain) GetEndorsementPolicy() *EndorsementPolicy {
+	if m != nil {
+		return m.EndorsementPolicy
+	}
+	return nil
+}
+
+func (m *Domain) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Domain) GetLogBackend() string {
+	if m != nil {
+		return m.LogBackend
+	}
+	return ""
+}
+
+func (m *Domain) GetMigration() *SignedDomainMigration {
+	if m != nil {
+		return m.Migration
+	}
+	return nil
+}
+
+func (m *Domain) GetLogKeyRotation() *TreeKeyRotation {
+	if m != nil {
+		return m.LogKeyRotation
+	}
+	return nil
+}
+
+func (m *Domain) GetMapKeyRotation() *TreeKeyRotation {
+	if m != nil {
+		return m.MapKeyRotation
+	}
+	return nil
+}
+
+func (m *Domain) GetTrillianBackend() *TrillianBackend {
+	if m != nil {
+		return m.TrillianBackend
+	}
+	return nil
+}
+
+func (m *Domain) GetKeyPolicyHistory() []*KeyPolicyChange {
+	if m != nil {
+		return m.KeyPolicyHistory
+	}
+	return nil
+}
+
+// TrillianBackend holds the addresses of the Trillian services that host the
+// trees of a domain. Empty addresses refer to the Trillian services that the
+// server is configured with.
+type TrillianBackend struct {
+	// log_address is the address of the Trillian log service. It cannot be
+	// combined with a log_backend.
+	LogAddress string `protobuf:"bytes,1,opt,name=log_address,json=logAddress" json:"log_address,omitempty"`
+	// map_address is the address of the Trillian map service.
+	MapAddress string `protobuf:"bytes,2,opt,name=map_address,json=mapAddress" json:"map_address,omitempty"`
+}
+
+func (m *TrillianBackend) Reset()                    { *m = TrillianBackend{} }
+func (m *TrillianBackend) String() string            { return proto.CompactTextString(m) }
+func (*TrillianBackend) ProtoMessage()               {}
+func (*TrillianBackend) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+func (m *TrillianBackend) GetLogAddress() string {
+	if m != nil {
+		return m.LogAddress
+	}
+	return ""
+}
+
+func (m *TrillianBackend) GetMapAddress() string {
+	if m != nil {
+		return m.MapAddress
+	}
+	return ""
+}
+
+// TreeKeyRotation records a rotation of the signing key of a tree.
+type TreeKeyRotation struct {
+	// previous_key is the key that was replaced. It is valid for roots with
+	// timestamps before overlap_end.
+	PreviousKey *keyspb.PublicKey `protobuf:"bytes,1,opt,name=previous_key,json=previousKey" json:"previous_key,omitempty"`
+	// public_key is the key that replaced previous_key. It is valid for roots
+	// with timestamps from rotate_time on.
+	PublicKey *keyspb.PublicKey `protobuf:"bytes,2,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+	// rotate_time is when the tree started signing roots with public_key.
+	RotateTime *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=rotate_time,json=rotateTime" json:"rotate_time,omitempty"`
+	// overlap_end is the end of the overlap window of previous_key.
+	OverlapEnd *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=overlap_end,json=overlapEnd" json:"overlap_end,omitempty"`
+}
+
+func (m *TreeKeyRotation) Reset()                    { *m = TreeKeyRotation{} }
+func (m *TreeKeyRotation) String() string            { return proto.CompactTextString(m) }
+func (*TreeKeyRotation) ProtoMessage()               {}
+func (*TreeKeyRotation) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+func (m *TreeKeyRotation) GetPreviousKey() *keyspb.PublicKey {
+	if m != nil {
+		return m.PreviousKey
+	}
+	return nil
+}
+
+func (m *TreeKeyRotation) GetPublicKey() *keyspb.PublicKey {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *TreeKeyRotation) GetRotateTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.RotateTime
+	}
+	return nil
+}
+
+func (m *TreeKeyRotation) GetOverlapEnd() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.OverlapEnd
+	}
+	return nil
+}
+
+// DomainMigration announces that a domain is served by another key server,
+// with other keys, from a map revision on. Revisions before it remain
+// verifiable with the keys of the domain before the migration.
+type DomainMigration struct {
+	// domain_id identifies the domain that is migrating.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// revision is the first map revision that is verified with new_domain.
+	Revision int64 `protobuf:"varint,2,opt,name=revision" json:"revision,omitempty"`
+	// new_address is the address of the key server that serves the domain
+	// after the migration. The address does not change if it is empty.
+	NewAddress string `protobuf:"bytes,3,opt,name=new_address,json=newAddress" json:"new_address,omitempty"`
+	// new_domain contains the public keys and tree parameters of the domain
+	// after the migration.
+	NewDomain *Domain `protobuf:"bytes,4,opt,name=new_domain,json=newDomain" json:"new_domain,omitempty"`
+	// announce_time is when the migration was announced.
+	AnnounceTime *google_protobuf2.Timestamp `protobuf:"bytes,5,opt,name=announce_time,json=announceTime" json:"announce_time,omitempty"`
+}
+
+func (m *DomainMigration) Reset()                    { *m = DomainMigration{} }
+func (m *DomainMigration) String() string            { return proto.CompactTextString(m) }
+func (*DomainMigration) ProtoMessage()               {}
+func (*DomainMigration) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *DomainMigration) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *DomainMigration) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *DomainMigration) GetNewAddress() string {
+	if m != nil {
+		return m.NewAddress
+	}
+	return ""
+}
+
+func (m *DomainMigration) GetNewDomain() *Domain {
+	if m != nil {
+		return m.NewDomain
+	}
+	return nil
+}
+
+func (m *DomainMigration) GetAnnounceTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.AnnounceTime
+	}
+	return nil
+}
+
+// SignedDomainMigration is a serialized DomainMigration and its signature.
+type SignedDomainMigration struct {
+	// migration is a serialized DomainMigration.
+	Migration []byte `protobuf:"bytes,1,opt,name=migration,proto3" json:"migration,omitempty"`
+	// signature is the signature over migration by the map key of the domain
+	// before the migration.
+	Signature *sigpb.DigitallySigned `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *SignedDomainMigration) Reset()                    { *m = SignedDomainMigration{} }
+func (m *SignedDomainMigration) String() string            { return proto.CompactTextString(m) }
+func (*SignedDomainMigration) ProtoMessage()               {}
+func (*SignedDomainMigration) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+
+func (m *SignedDomainMigration) GetMigration() []byte {
+	if m != nil {
+		return m.Migration
+	}
+	return nil
+}
+
+func (m *SignedDomainMigration) GetSignature() *sigpb.DigitallySigned {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// DomainConfig is the configuration of a domain at a point in time.
+type DomainConfig struct {
+	// domain is the configuration of the domain, without signed_config.
+	Domain *Domain `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
+	// timestamp is when the configuration was signed.
+	Timestamp *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *DomainConfig) Reset()                    { *m = DomainConfig{} }
+func (m *DomainConfig) String() string            { return proto.CompactTextString(m) }
+func (*DomainConfig) ProtoMessage()               {}
+func (*DomainConfig) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *DomainConfig) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *DomainConfig) GetTimestamp() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// SignedDomainConfig is a serialized DomainConfig and its signature.
+type SignedDomainConfig struct {
+	// config is a serialized DomainConfig.
+	Config []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// signature is the key server's signature over config.
+	Signature *sigpb.DigitallySigned `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *SignedDomainConfig) Reset()                    { *m = SignedDomainConfig{} }
+func (m *SignedDomainConfig) String() string            { return proto.CompactTextString(m) }
+func (*SignedDomainConfig) ProtoMessage()               {}
+func (*SignedDomainConfig) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+func (m *SignedDomainConfig) GetConfig() []byte {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+func (m *SignedDomainConfig) GetSignature() *sigpb.DigitallySigned {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 // ListDomains request.
 // No pagination options are provided.
 type ListDomainsRequest struct {
 	// showDeleted requests domains that have been marked for deletion
 	// but have not been garbage collected.
 	ShowDeleted bool `protobuf:"varint,1,opt,name=show_deleted,json=showDeleted" json:"show_deleted,omitempty"`
+	// label_selector restricts the response to domains whose labels match it.
+	// It is a comma separated list of requirements of the form key=value,
+	// key!=value, key (the label is present) or !key (the label is absent).
+	// Domains match if they meet every requirement.
+	LabelSelector string `protobuf:"bytes,2,opt,name=label_selector,json=labelSelector" json:"label_selector,omitempty"`
 }
 
 func (m *ListDomainsRequest) Reset()                    { *m = ListDomainsRequest{} }
 func (m *ListDomainsRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListDomainsRequest) ProtoMessage()               {}
-func (*ListDomainsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*ListDomainsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
 
 func (m *ListDomainsRequest) GetShowDeleted() bool {
 	if m != nil {
@@ -115,6 +603,13 @@ func (m *ListDomainsRequest) GetShowDeleted() bool {
 	return false
 }
 
+func (m *ListDomainsRequest) GetLabelSelector() string {
+	if m != nil {
+		return m.LabelSelector
+	}
+	return ""
+}
+
 // ListDomains response contains domains.
 type ListDomainsResponse struct {
 	Domains []*Domain `protobuf:"bytes,1,rep,name=domains" json:"domains,omitempty"`
@@ -123,7 +618,7 @@ type ListDomainsResponse struct {
 func (m *ListDomainsResponse) Reset()                    { *m = ListDomainsResponse{} }
 func (m *ListDomainsResponse) String() string            { return proto.CompactTextString(m) }
 func (*ListDomainsResponse) ProtoMessage()               {}
-func (*ListDomainsResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*ListDomainsResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
 
 func (m *ListDomainsResponse) GetDomains() []*Domain {
 	if m != nil {
@@ -143,7 +638,7 @@ type GetDomainRequest struct {
 func (m *GetDomainRequest) Reset()                    { *m = GetDomainRequest{} }
 func (m *GetDomainRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetDomainRequest) ProtoMessage()               {}
-func (*GetDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*GetDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{9} }
 
 func (m *GetDomainRequest) GetDomainId() string {
 	if m != nil {
@@ -162,14 +657,44 @@ func (m *GetDomainRequest) GetShowDeleted() bool {
 // CreateDomainRequest creates a new domain
 type CreateDomainRequest struct {
 	DomainId    string                     `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
-	MinInterval *google_protobuf2.Duration `protobuf:"bytes,2,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
-	MaxInterval *google_protobuf2.Duration `protobuf:"bytes,3,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+	MinInterval *google_protobuf1.Duration `protobuf:"bytes,2,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
+	MaxInterval *google_protobuf1.Duration `protobuf:"bytes,3,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+	// log_spec overrides the default parameters of the domain's log.
+	LogSpec *TreeSpec `protobuf:"bytes,4,opt,name=log_spec,json=logSpec" json:"log_spec,omitempty"`
+	// map_spec overrides the default parameters of the domain's map.
+	MapSpec *TreeSpec `protobuf:"bytes,5,opt,name=map_spec,json=mapSpec" json:"map_spec,omitempty"`
+	// log_id and map_id are the IDs of existing Trillian trees to use instead
+	// of creating new ones. Either both or neither must be set, and they cannot
+	// be combined with log_spec or map_spec.
+	LogId int64 `protobuf:"varint,6,opt,name=log_id,json=logId" json:"log_id,omitempty"`
+	MapId int64 `protobuf:"varint,7,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	// vrf_private_key is an existing VRF private key to use instead of
+	// generating a new one. It is wrapped in the same way as Trillian signing
+	// keys, e.g. as a keyspb.PrivateKey or keyspb.PEMKeyFile.
+	VrfPrivateKey *google_protobuf3.Any `protobuf:"bytes,8,opt,name=vrf_private_key,json=vrfPrivateKey" json:"vrf_private_key,omitempty"`
+	// vrf_algorithm selects the algorithm of the domain's VRF keys. It must
+	// match vrf_private_key if that is set.
+	VrfAlgorithm VrfAlgorithm `protobuf:"varint,9,opt,name=vrf_algorithm,json=vrfAlgorithm,enum=google.keytransparency.v1.VrfAlgorithm" json:"vrf_algorithm,omitempty"`
+	// validate_only checks the request, including that Trillian is reachable,
+	// without creating trees, keys or the domain. The domain that would be
+	// created is returned. Trees that would be created have no tree_id, and
+	// vrf is only set if vrf_private_key is.
+	ValidateOnly bool `protobuf:"varint,10,opt,name=validate_only,json=validateOnly" json:"validate_only,omitempty"`
+	// labels are the initial labels of the domain.
+	Labels map[string]string `protobuf:"bytes,11,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// log_backend names a log backend configured on the server to anchor map
+	// roots in instead of the server's Trillian instance. The log must already
+	// exist, so log_id and map_id must be set.
+	LogBackend string `protobuf:"bytes,12,opt,name=log_backend,json=logBackend" json:"log_backend,omitempty"`
+	// trillian_backend hosts the trees of the domain on another Trillian
+	// cluster than the server's.
+	TrillianBackend *TrillianBackend `protobuf:"bytes,13,opt,name=trillian_backend,json=trillianBackend" json:"trillian_backend,omitempty"`
 }
 
 func (m *CreateDomainRequest) Reset()                    { *m = CreateDomainRequest{} }
 func (m *CreateDomainRequest) String() string            { return proto.CompactTextString(m) }
 func (*CreateDomainRequest) ProtoMessage()               {}
-func (*CreateDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*CreateDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
 
 func (m *CreateDomainRequest) GetDomainId() string {
 	if m != nil {
@@ -178,257 +703,2715 @@ func (m *CreateDomainRequest) GetDomainId() string {
 	return ""
 }
 
-func (m *CreateDomainRequest) GetMinInterval() *google_protobuf2.Duration {
+func (m *CreateDomainRequest) GetMinInterval() *google_protobuf1.Duration {
 	if m != nil {
 		return m.MinInterval
 	}
 	return nil
 }
 
-func (m *CreateDomainRequest) GetMaxInterval() *google_protobuf2.Duration {
+func (m *CreateDomainRequest) GetMaxInterval() *google_protobuf1.Duration {
 	if m != nil {
 		return m.MaxInterval
 	}
 	return nil
 }
 
-// DeleteDomainRequest deletes a domain
-type DeleteDomainRequest struct {
-	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+func (m *CreateDomainRequest) GetLogSpec() *TreeSpec {
+	if m != nil {
+		return m.LogSpec
+	}
+	return nil
 }
 
-func (m *DeleteDomainRequest) Reset()                    { *m = DeleteDomainRequest{} }
-func (m *DeleteDomainRequest) String() string            { return proto.CompactTextString(m) }
-func (*DeleteDomainRequest) ProtoMessage()               {}
-func (*DeleteDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+func (m *CreateDomainRequest) GetMapSpec() *TreeSpec {
+	if m != nil {
+		return m.MapSpec
+	}
+	return nil
+}
 
-func (m *DeleteDomainRequest) GetDomainId() string {
+func (m *CreateDomainRequest) GetLogId() int64 {
 	if m != nil {
-		return m.DomainId
+		return m.LogId
 	}
-	return ""
+	return 0
 }
 
-// UndeleteDomainRequest deletes a domain
-type UndeleteDomainRequest struct {
-	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+func (m *CreateDomainRequest) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
 }
 
-func (m *UndeleteDomainRequest) Reset()                    { *m = UndeleteDomainRequest{} }
-func (m *UndeleteDomainRequest) String() string            { return proto.CompactTextString(m) }
-func (*UndeleteDomainRequest) ProtoMessage()               {}
-func (*UndeleteDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+func (m *CreateDomainRequest) GetVrfPrivateKey() *google_protobuf3.Any {
+	if m != nil {
+		return m.VrfPrivateKey
+	}
+	return nil
+}
 
-func (m *UndeleteDomainRequest) GetDomainId() string {
+func (m *CreateDomainRequest) GetVrfAlgorithm() VrfAlgorithm {
 	if m != nil {
-		return m.DomainId
+		return m.VrfAlgorithm
 	}
-	return ""
+	return VrfAlgorithm_P256
 }
 
-func init() {
+func (m *CreateDomainRequest) GetValidateOnly() bool {
+	if m != nil {
+		return m.ValidateOnly
+	}
+	return false
+}
+
+func (m *CreateDomainRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *CreateDomainRequest) GetLogBackend() string {
+	if m != nil {
+		return m.LogBackend
+	}
+	return ""
+}
+
+func (m *CreateDomainRequest) GetTrillianBackend() *TrillianBackend {
+	if m != nil {
+		return m.TrillianBackend
+	}
+	return nil
+}
+
+// DeleteDomainRequest deletes a domain
+type DeleteDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *DeleteDomainRequest) Reset()                    { *m = DeleteDomainRequest{} }
+func (m *DeleteDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteDomainRequest) ProtoMessage()               {}
+func (*DeleteDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
+
+func (m *DeleteDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// UndeleteDomainRequest deletes a domain
+type UndeleteDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *UndeleteDomainRequest) Reset()                    { *m = UndeleteDomainRequest{} }
+func (m *UndeleteDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*UndeleteDomainRequest) ProtoMessage()               {}
+func (*UndeleteDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
+
+func (m *UndeleteDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// AddAppVRFRequest creates a VRF that is scoped to a single app.
+type AddAppVRFRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	AppId    string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+}
+
+func (m *AddAppVRFRequest) Reset()                    { *m = AddAppVRFRequest{} }
+func (m *AddAppVRFRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddAppVRFRequest) ProtoMessage()               {}
+func (*AddAppVRFRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+
+func (m *AddAppVRFRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *AddAppVRFRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+// TreeSpec specifies the parameters of a Trillian tree.
+// Unset fields take the server's default values.
+type TreeSpec struct {
+	// hash_strategy is the hashing strategy of the tree.
+	HashStrategy trillian.HashStrategy `protobuf:"varint,1,opt,name=hash_strategy,json=hashStrategy,enum=trillian.HashStrategy" json:"hash_strategy,omitempty"`
+	// signature_algorithm is the algorithm used to sign tree roots.
+	SignatureAlgorithm sigpb.DigitallySigned_SignatureAlgorithm `protobuf:"varint,2,opt,name=signature_algorithm,json=signatureAlgorithm,enum=sigpb.DigitallySigned_SignatureAlgorithm" json:"signature_algorithm,omitempty"`
+	// key_spec specifies the key used to sign tree roots. It must match
+	// signature_algorithm.
+	KeySpec *keyspb.Specification `protobuf:"bytes,3,opt,name=key_spec,json=keySpec" json:"key_spec,omitempty"`
+	// max_root_duration is the maximum time between signed tree roots.
+	MaxRootDuration *google_protobuf1.Duration `protobuf:"bytes,4,opt,name=max_root_duration,json=maxRootDuration" json:"max_root_duration,omitempty"`
+}
+
+func (m *TreeSpec) Reset()                    { *m = TreeSpec{} }
+func (m *TreeSpec) String() string            { return proto.CompactTextString(m) }
+func (*TreeSpec) ProtoMessage()               {}
+func (*TreeSpec) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
+
+func (m *TreeSpec) GetHashStrategy() trillian.HashStrategy {
+	if m != nil {
+		return m.HashStrategy
+	}
+	return trillian.HashStrategy_UNKNOWN_HASH_STRATEGY
+}
+
+func (m *TreeSpec) GetSignatureAlgorithm() sigpb.DigitallySigned_SignatureAlgorithm {
+	if m != nil {
+		return m.SignatureAlgorithm
+	}
+	return sigpb.DigitallySigned_ANONYMOUS
+}
+
+func (m *TreeSpec) GetKeySpec() *keyspb.Specification {
+	if m != nil {
+		return m.KeySpec
+	}
+	return nil
+}
+
+func (m *TreeSpec) GetMaxRootDuration() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MaxRootDuration
+	}
+	return nil
+}
+
+// UpdateDomainRequest changes the mutable settings of a domain.
+type UpdateDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// max_root_duration is the maximum time between signed roots of the
+	// domain's log and map. Trillian signs a new root when this time elapses
+	// without any updates. Zero disables periodic signing.
+	MaxRootDuration *google_protobuf1.Duration `protobuf:"bytes,2,opt,name=max_root_duration,json=maxRootDuration" json:"max_root_duration,omitempty"`
+	// labels replace the labels of the domain.
+	Labels map[string]string `protobuf:"bytes,3,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// update_mask lists the fields above to change, either max_root_duration
+	// or labels. If it is empty only max_root_duration is changed.
+	UpdateMask *google_protobuf5.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask" json:"update_mask,omitempty"`
+}
+
+func (m *UpdateDomainRequest) Reset()                    { *m = UpdateDomainRequest{} }
+func (m *UpdateDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateDomainRequest) ProtoMessage()               {}
+func (*UpdateDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+
+func (m *UpdateDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *UpdateDomainRequest) GetMaxRootDuration() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MaxRootDuration
+	}
+	return nil
+}
+
+func (m *UpdateDomainRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *UpdateDomainRequest) GetUpdateMask() *google_protobuf5.FieldMask {
+	if m != nil {
+		return m.UpdateMask
+	}
+	return nil
+}
+
+// RotateDomainVRFRequest replaces the VRF key of a domain.
+type RotateDomainVRFRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// overlap is how long indexes computed with the previous VRF key remain
+	// valid.
+	Overlap *google_protobuf1.Duration `protobuf:"bytes,2,opt,name=overlap" json:"overlap,omitempty"`
+}
+
+func (m *RotateDomainVRFRequest) Reset()                    { *m = RotateDomainVRFRequest{} }
+func (m *RotateDomainVRFRequest) String() string            { return proto.CompactTextString(m) }
+func (*RotateDomainVRFRequest) ProtoMessage()               {}
+func (*RotateDomainVRFRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+
+func (m *RotateDomainVRFRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *RotateDomainVRFRequest) GetOverlap() *google_protobuf1.Duration {
+	if m != nil {
+		return m.Overlap
+	}
+	return nil
+}
+
+// RotateTreeKeysRequest rotates the signing keys of the trees of a domain.
+type RotateTreeKeysRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// rotate_log rotates the signing key of the log.
+	RotateLog bool `protobuf:"varint,2,opt,name=rotate_log,json=rotateLog" json:"rotate_log,omitempty"`
+	// rotate_map rotates the signing key of the map.
+	RotateMap bool `protobuf:"varint,3,opt,name=rotate_map,json=rotateMap" json:"rotate_map,omitempty"`
+	// overlap is how long roots signed with the previous keys are accepted.
+	Overlap *google_protobuf1.Duration `protobuf:"bytes,4,opt,name=overlap" json:"overlap,omitempty"`
+}
+
+func (m *RotateTreeKeysRequest) Reset()                    { *m = RotateTreeKeysRequest{} }
+func (m *RotateTreeKeysRequest) String() string            { return proto.CompactTextString(m) }
+func (*RotateTreeKeysRequest) ProtoMessage()               {}
+func (*RotateTreeKeysRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+
+func (m *RotateTreeKeysRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *RotateTreeKeysRequest) GetRotateLog() bool {
+	if m != nil {
+		return m.RotateLog
+	}
+	return false
+}
+
+func (m *RotateTreeKeysRequest) GetRotateMap() bool {
+	if m != nil {
+		return m.RotateMap
+	}
+	return false
+}
+
+func (m *RotateTreeKeysRequest) GetOverlap() *google_protobuf1.Duration {
+	if m != nil {
+		return m.Overlap
+	}
+	return nil
+}
+
+// PurgeEntryDataRequest purges the committed profile data of a user.
+type PurgeEntryDataRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	AppId    string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	UserId   string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// revision is the latest map revision to purge. All earlier revisions are
+	// also purged. Zero purges through the latest revision.
+	Revision int64 `protobuf:"varint,4,opt,name=revision" json:"revision,omitempty"`
+}
+
+func (m *PurgeEntryDataRequest) Reset()                    { *m = PurgeEntryDataRequest{} }
+func (m *PurgeEntryDataRequest) String() string            { return proto.CompactTextString(m) }
+func (*PurgeEntryDataRequest) ProtoMessage()               {}
+func (*PurgeEntryDataRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+
+func (m *PurgeEntryDataRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *PurgeEntryDataRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *PurgeEntryDataRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *PurgeEntryDataRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+// ListAuditEntriesRequest requests the audit log of a domain.
+type ListAuditEntriesRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// start is the sequence number of the first entry to return.
+	Start int64 `protobuf:"varint,2,opt,name=start" json:"start,omitempty"`
+	// page_size is the maximum number of entries to return.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+}
+
+func (m *ListAuditEntriesRequest) Reset()                    { *m = ListAuditEntriesRequest{} }
+func (m *ListAuditEntriesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListAuditEntriesRequest) ProtoMessage()               {}
+func (*ListAuditEntriesRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
+
+func (m *ListAuditEntriesRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *ListAuditEntriesRequest) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *ListAuditEntriesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+// AuditEntry records an admin RPC.
+type AuditEntry struct {
+	// sequence is the position of the entry in the domain's audit log.
+	Sequence int64 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	// method is the name of the RPC.
+	Method string `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
+	// caller identifies the client that made the RPC.
+	Caller string `protobuf:"bytes,3,opt,name=caller" json:"caller,omitempty"`
+	// time is when the RPC was received.
+	Time *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=time" json:"time,omitempty"`
+	// request_hash is the SHA256 hash of the serialized request.
+	RequestHash []byte `protobuf:"bytes,5,opt,name=request_hash,json=requestHash,proto3" json:"request_hash,omitempty"`
+}
+
+func (m *AuditEntry) Reset()                    { *m = AuditEntry{} }
+func (m *AuditEntry) String() string            { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()               {}
+func (*AuditEntry) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{20} }
+
+func (m *AuditEntry) GetSequence() int64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetCaller() string {
+	if m != nil {
+		return m.Caller
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetRequestHash() []byte {
+	if m != nil {
+		return m.RequestHash
+	}
+	return nil
+}
+
+// ListAuditEntriesResponse contains a page of audit entries.
+type ListAuditEntriesResponse struct {
+	Entries []*AuditEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	// next_start is the start of the next page. Zero if there are no more
+	// entries.
+	NextStart int64 `protobuf:"varint,2,opt,name=next_start,json=nextStart" json:"next_start,omitempty"`
+}
+
+func (m *ListAuditEntriesResponse) Reset()                    { *m = ListAuditEntriesResponse{} }
+func (m *ListAuditEntriesResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListAuditEntriesResponse) ProtoMessage()               {}
+func (*ListAuditEntriesResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{21} }
+
+func (m *ListAuditEntriesResponse) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *ListAuditEntriesResponse) GetNextStart() int64 {
+	if m != nil {
+		return m.NextStart
+	}
+	return 0
+}
+
+// DomainBundle is the definition of a domain as exported by ExportDomain.
+// It contains the domain's private VRF keys and must be stored securely.
+type DomainBundle struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// log and map are the configurations of the domain's trees.
+	Log *trillian.Tree `protobuf:"bytes,2,opt,name=log" json:"log,omitempty"`
+	Map *trillian.Tree `protobuf:"bytes,3,opt,name=map" json:"map,omitempty"`
+	// vrf_private_key is the domain's VRF private key, wrapped in the same way
+	// as Trillian signing keys.
+	VrfPrivateKey *google_protobuf3.Any      `protobuf:"bytes,4,opt,name=vrf_private_key,json=vrfPrivateKey" json:"vrf_private_key,omitempty"`
+	MinInterval   *google_protobuf1.Duration `protobuf:"bytes,5,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
+	MaxInterval   *google_protobuf1.Duration `protobuf:"bytes,6,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+	// app_vrf_private_keys contains the wrapped VRF private keys of apps that
+	// have their own VRF.
+	AppVrfPrivateKeys map[string]*google_protobuf3.Any `protobuf:"bytes,7,rep,name=app_vrf_private_keys,json=appVrfPrivateKeys" json:"app_vrf_private_keys,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// previous_vrf_private_key is the wrapped VRF private key that was replaced
+	// by the most recent call to RotateDomainVRF. It is only set while the key
+	// is still valid.
+	PreviousVrfPrivateKey *google_protobuf3.Any `protobuf:"bytes,8,opt,name=previous_vrf_private_key,json=previousVrfPrivateKey" json:"previous_vrf_private_key,omitempty"`
+	// previous_vrf_expiry is the end of the overlap window for
+	// previous_vrf_private_key.
+	PreviousVrfExpiry *google_protobuf2.Timestamp `protobuf:"bytes,9,opt,name=previous_vrf_expiry,json=previousVrfExpiry" json:"previous_vrf_expiry,omitempty"`
+	// export_time is when the bundle was created.
+	ExportTime *google_protobuf2.Timestamp `protobuf:"bytes,10,opt,name=export_time,json=exportTime" json:"export_time,omitempty"`
+	// key_policy is the domain's key policy.
+	KeyPolicy *KeyPolicy `protobuf:"bytes,11,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+	// labels are the domain's labels.
+	Labels map[string]string `protobuf:"bytes,12,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// log_backend names the backend of the domain's log.
+	LogBackend string `protobuf:"bytes,13,opt,name=log_backend,json=logBackend" json:"log_backend,omitempty"`
+	// trillian_backend locates the Trillian cluster of the domain's trees.
+	TrillianBackend *TrillianBackend `protobuf:"bytes,14,opt,name=trillian_backend,json=trillianBackend" json:"trillian_backend,omitempty"`
+	// key_policy_history lists the key policies of the domain with the first
+	// revision each applies to. It is replayed when the bundle is imported onto
+	// its existing trees.
+	KeyPolicyHistory []*KeyPolicyChange `protobuf:"bytes,15,rep,name=key_policy_history,json=keyPolicyHistory" json:"key_policy_history,omitempty"`
+}
+
+func (m *DomainBundle) Reset()                    { *m = DomainBundle{} }
+func (m *DomainBundle) String() string            { return proto.CompactTextString(m) }
+func (*DomainBundle) ProtoMessage()               {}
+func (*DomainBundle) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{22} }
+
+func (m *DomainBundle) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *DomainBundle) GetLog() *trillian.Tree {
+	if m != nil {
+		return m.Log
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetMap() *trillian.Tree {
+	if m != nil {
+		return m.Map
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetVrfPrivateKey() *google_protobuf3.Any {
+	if m != nil {
+		return m.VrfPrivateKey
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetMinInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MinInterval
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetMaxInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MaxInterval
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetAppVrfPrivateKeys() map[string]*google_protobuf3.Any {
+	if m != nil {
+		return m.AppVrfPrivateKeys
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetPreviousVrfPrivateKey() *google_protobuf3.Any {
+	if m != nil {
+		return m.PreviousVrfPrivateKey
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetPreviousVrfExpiry() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.PreviousVrfExpiry
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetExportTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.ExportTime
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetLogBackend() string {
+	if m != nil {
+		return m.LogBackend
+	}
+	return ""
+}
+
+func (m *DomainBundle) GetTrillianBackend() *TrillianBackend {
+	if m != nil {
+		return m.TrillianBackend
+	}
+	return nil
+}
+
+func (m *DomainBundle) GetKeyPolicyHistory() []*KeyPolicyChange {
+	if m != nil {
+		return m.KeyPolicyHistory
+	}
+	return nil
+}
+
+// SignedDomainBundle is a serialized DomainBundle and its signature.
+type SignedDomainBundle struct {
+	// bundle is a serialized DomainBundle.
+	Bundle []byte `protobuf:"bytes,1,opt,name=bundle,proto3" json:"bundle,omitempty"`
+	// signature is the exporting server's signature over bundle.
+	Signature *sigpb.DigitallySigned `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *SignedDomainBundle) Reset()                    { *m = SignedDomainBundle{} }
+func (m *SignedDomainBundle) String() string            { return proto.CompactTextString(m) }
+func (*SignedDomainBundle) ProtoMessage()               {}
+func (*SignedDomainBundle) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{23} }
+
+func (m *SignedDomainBundle) GetBundle() []byte {
+	if m != nil {
+		return m.Bundle
+	}
+	return nil
+}
+
+func (m *SignedDomainBundle) GetSignature() *sigpb.DigitallySigned {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// ExportDomainRequest exports the definition of a domain.
+type ExportDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *ExportDomainRequest) Reset()                    { *m = ExportDomainRequest{} }
+func (m *ExportDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportDomainRequest) ProtoMessage()               {}
+func (*ExportDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{24} }
+
+func (m *ExportDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// ImportDomainRequest restores a domain from a bundle.
+type ImportDomainRequest struct {
+	Bundle *SignedDomainBundle `protobuf:"bytes,1,opt,name=bundle" json:"bundle,omitempty"`
+	// create_trees creates new, empty trees with the parameters of the trees
+	// in the bundle instead of using the trees named by the bundle. Use it when
+	// the Trillian trees of the domain were not restored.
+	CreateTrees bool `protobuf:"varint,2,opt,name=create_trees,json=createTrees" json:"create_trees,omitempty"`
+}
+
+func (m *ImportDomainRequest) Reset()                    { *m = ImportDomainRequest{} }
+func (m *ImportDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportDomainRequest) ProtoMessage()               {}
+func (*ImportDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{25} }
+
+func (m *ImportDomainRequest) GetBundle() *SignedDomainBundle {
+	if m != nil {
+		return m.Bundle
+	}
+	return nil
+}
+
+func (m *ImportDomainRequest) GetCreateTrees() bool {
+	if m != nil {
+		return m.CreateTrees
+	}
+	return false
+}
+
+// KeyPolicy restricts the authorized keys of the entries in a domain.
+type KeyPolicy struct {
+	// allowed_algorithms lists the signature algorithms that authorized keys
+	// may use. Empty allows every supported algorithm.
+	AllowedAlgorithms []sigpb.DigitallySigned_SignatureAlgorithm `protobuf:"varint,1,rep,packed,name=allowed_algorithms,json=allowedAlgorithms,enum=sigpb.DigitallySigned_SignatureAlgorithm" json:"allowed_algorithms,omitempty"`
+	// max_keys is the maximum number of authorized keys in an entry. Zero
+	// means no limit.
+	MaxKeys int32 `protobuf:"varint,2,opt,name=max_keys,json=maxKeys" json:"max_keys,omitempty"`
+}
+
+func (m *KeyPolicy) Reset()                    { *m = KeyPolicy{} }
+func (m *KeyPolicy) String() string            { return proto.CompactTextString(m) }
+func (*KeyPolicy) ProtoMessage()               {}
+func (*KeyPolicy) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{26} }
+
+func (m *KeyPolicy) GetAllowedAlgorithms() []sigpb.DigitallySigned_SignatureAlgorithm {
+	if m != nil {
+		return m.AllowedAlgorithms
+	}
+	return nil
+}
+
+func (m *KeyPolicy) GetMaxKeys() int32 {
+	if m != nil {
+		return m.MaxKeys
+	}
+	return 0
+}
+
+// KeyPolicyChange records a change of the key policy of a domain.
+type KeyPolicyChange struct {
+	// revision is the first map revision the policy applies to.
+	Revision int64 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	// key_policy is the new policy. Unset removes all restrictions.
+	KeyPolicy *KeyPolicy `protobuf:"bytes,2,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+}
+
+func (m *KeyPolicyChange) Reset()                    { *m = KeyPolicyChange{} }
+func (m *KeyPolicyChange) String() string            { return proto.CompactTextString(m) }
+func (*KeyPolicyChange) ProtoMessage()               {}
+func (*KeyPolicyChange) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{27} }
+
+func (m *KeyPolicyChange) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *KeyPolicyChange) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+// SetKeyPolicyRequest replaces the key policy of a domain.
+type SetKeyPolicyRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// key_policy is the new policy. An unset policy removes all restrictions.
+	KeyPolicy *KeyPolicy `protobuf:"bytes,2,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+}
+
+func (m *SetKeyPolicyRequest) Reset()                    { *m = SetKeyPolicyRequest{} }
+func (m *SetKeyPolicyRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetKeyPolicyRequest) ProtoMessage()               {}
+func (*SetKeyPolicyRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{28} }
+
+func (m *SetKeyPolicyRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetKeyPolicyRequest) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+// FreezeDomainRequest makes a domain read-only.
+type FreezeDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *FreezeDomainRequest) Reset()                    { *m = FreezeDomainRequest{} }
+func (m *FreezeDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*FreezeDomainRequest) ProtoMessage()               {}
+func (*FreezeDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{29} }
+
+func (m *FreezeDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// UnfreezeDomainRequest makes a frozen domain writable again.
+type UnfreezeDomainRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *UnfreezeDomainRequest) Reset()                    { *m = UnfreezeDomainRequest{} }
+func (m *UnfreezeDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*UnfreezeDomainRequest) ProtoMessage()               {}
+func (*UnfreezeDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{30} }
+
+func (m *UnfreezeDomainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// ForceEpochRequest creates a new epoch for a domain.
+type ForceEpochRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *ForceEpochRequest) Reset()                    { *m = ForceEpochRequest{} }
+func (m *ForceEpochRequest) String() string            { return proto.CompactTextString(m) }
+func (*ForceEpochRequest) ProtoMessage()               {}
+func (*ForceEpochRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{31} }
+
+func (m *ForceEpochRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// SetAppListingRequest enables or disables ListUserApps for a domain.
+type SetAppListingRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	Enabled  bool   `protobuf:"varint,2,opt,name=enabled" json:"enabled,omitempty"`
+}
+
+func (m *SetAppListingRequest) Reset()                    { *m = SetAppListingRequest{} }
+func (m *SetAppListingRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetAppListingRequest) ProtoMessage()               {}
+func (*SetAppListingRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{32} }
+
+func (m *SetAppListingRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetAppListingRequest) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+// MutationQuota limits the number of mutations the key server accepts for a
+// domain. Zero values mean no limit.
+type MutationQuota struct {
+	// mutations_per_epoch is the maximum number of mutations accepted while a
+	// single map revision is the latest.
+	MutationsPerEpoch int32 `protobuf:"varint,1,opt,name=mutations_per_epoch,json=mutationsPerEpoch" json:"mutations_per_epoch,omitempty"`
+	// mutations_per_user_per_day is the maximum number of mutations accepted for
+	// a single user in any 24 hour period.
+	MutationsPerUserPerDay int32 `protobuf:"varint,2,opt,name=mutations_per_user_per_day,json=mutationsPerUserPerDay" json:"mutations_per_user_per_day,omitempty"`
+}
+
+func (m *MutationQuota) Reset()                    { *m = MutationQuota{} }
+func (m *MutationQuota) String() string            { return proto.CompactTextString(m) }
+func (*MutationQuota) ProtoMessage()               {}
+func (*MutationQuota) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{33} }
+
+func (m *MutationQuota) GetMutationsPerEpoch() int32 {
+	if m != nil {
+		return m.MutationsPerEpoch
+	}
+	return 0
+}
+
+func (m *MutationQuota) GetMutationsPerUserPerDay() int32 {
+	if m != nil {
+		return m.MutationsPerUserPerDay
+	}
+	return 0
+}
+
+// SetMutationQuotaRequest replaces the mutation quota of a domain.
+type SetMutationQuotaRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// mutation_quota is the new quota. An unset quota removes all limits.
+	MutationQuota *MutationQuota `protobuf:"bytes,2,opt,name=mutation_quota,json=mutationQuota" json:"mutation_quota,omitempty"`
+}
+
+func (m *SetMutationQuotaRequest) Reset()                    { *m = SetMutationQuotaRequest{} }
+func (m *SetMutationQuotaRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetMutationQuotaRequest) ProtoMessage()               {}
+func (*SetMutationQuotaRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{34} }
+
+func (m *SetMutationQuotaRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetMutationQuotaRequest) GetMutationQuota() *MutationQuota {
+	if m != nil {
+		return m.MutationQuota
+	}
+	return nil
+}
+
+// EndorsementPolicy requires map roots to be co-signed by a quorum of
+// endorsers before they are appended to the log.
+type EndorsementPolicy struct {
+	// keys are the public keys of the endorsers.
+	Keys []*keyspb.PublicKey `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+	// quorum is the number of distinct endorsers that must sign each map root.
+	// Zero disables endorsement.
+	Quorum int32 `protobuf:"varint,2,opt,name=quorum" json:"quorum,omitempty"`
+}
+
+func (m *EndorsementPolicy) Reset()                    { *m = EndorsementPolicy{} }
+func (m *EndorsementPolicy) String() string            { return proto.CompactTextString(m) }
+func (*EndorsementPolicy) ProtoMessage()               {}
+func (*EndorsementPolicy) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{35} }
+
+func (m *EndorsementPolicy) GetKeys() []*keyspb.PublicKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *EndorsementPolicy) GetQuorum() int32 {
+	if m != nil {
+		return m.Quorum
+	}
+	return 0
+}
+
+// SetEndorsementPolicyRequest replaces the endorsement policy of a domain.
+type SetEndorsementPolicyRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// endorsement_policy is the new policy. An unset policy disables
+	// endorsement.
+	EndorsementPolicy *EndorsementPolicy `protobuf:"bytes,2,opt,name=endorsement_policy,json=endorsementPolicy" json:"endorsement_policy,omitempty"`
+}
+
+func (m *SetEndorsementPolicyRequest) Reset()                    { *m = SetEndorsementPolicyRequest{} }
+func (m *SetEndorsementPolicyRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetEndorsementPolicyRequest) ProtoMessage()               {}
+func (*SetEndorsementPolicyRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{36} }
+
+func (m *SetEndorsementPolicyRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetEndorsementPolicyRequest) GetEndorsementPolicy() *EndorsementPolicy {
+	if m != nil {
+		return m.EndorsementPolicy
+	}
+	return nil
+}
+
+// SetDomainIntervalsRequest changes the epoch timing policy of a domain.
+type SetDomainIntervalsRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// min_interval is the new minimum time between epochs.
+	MinInterval *google_protobuf1.Duration `protobuf:"bytes,2,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
+	// max_interval is the new maximum time between epochs. Zero disables
+	// periodic epochs.
+	MaxInterval *google_protobuf1.Duration `protobuf:"bytes,3,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+}
+
+func (m *SetDomainIntervalsRequest) Reset()                    { *m = SetDomainIntervalsRequest{} }
+func (m *SetDomainIntervalsRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetDomainIntervalsRequest) ProtoMessage()               {}
+func (*SetDomainIntervalsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{37} }
+
+func (m *SetDomainIntervalsRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetDomainIntervalsRequest) GetMinInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MinInterval
+	}
+	return nil
+}
+
+func (m *SetDomainIntervalsRequest) GetMaxInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MaxInterval
+	}
+	return nil
+}
+
+// AnnounceDomainMigrationRequest publishes a migration of a domain.
+type AnnounceDomainMigrationRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// migration is the signed announcement. An unset migration withdraws the
+	// announcement of the domain.
+	Migration *SignedDomainMigration `protobuf:"bytes,2,opt,name=migration" json:"migration,omitempty"`
+}
+
+func (m *AnnounceDomainMigrationRequest) Reset()         { *m = AnnounceDomainMigrationRequest{} }
+func (m *AnnounceDomainMigrationRequest) String() string { return proto.CompactTextString(m) }
+func (*AnnounceDomainMigrationRequest) ProtoMessage()    {}
+func (*AnnounceDomainMigrationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor1, []int{38}
+}
+
+func (m *AnnounceDomainMigrationRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *AnnounceDomainMigrationRequest) GetMigration() *SignedDomainMigration {
+	if m != nil {
+		return m.Migration
+	}
+	return nil
+}
+
+// GetDomainStatusRequest requests the health of a domain.
+type GetDomainStatusRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *GetDomainStatusRequest) Reset()                    { *m = GetDomainStatusRequest{} }
+func (m *GetDomainStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetDomainStatusRequest) ProtoMessage()               {}
+func (*GetDomainStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{39} }
+
+func (m *GetDomainStatusRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// DomainStatus describes the health of a domain and its trees.
+type DomainStatus struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// pending_mutations is the number of mutations waiting to be sequenced, or
+	// -1 if the server cannot inspect the mutation queue.
+	PendingMutations int64 `protobuf:"varint,2,opt,name=pending_mutations,json=pendingMutations" json:"pending_mutations,omitempty"`
+	// oldest_pending_mutation is when the oldest pending mutation was queued.
+	OldestPendingMutation *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=oldest_pending_mutation,json=oldestPendingMutation" json:"oldest_pending_mutation,omitempty"`
+	// map_revision is the revision of the latest signed map root.
+	MapRevision int64 `protobuf:"varint,4,opt,name=map_revision,json=mapRevision" json:"map_revision,omitempty"`
+	// map_root_time is when the latest map root was signed.
+	MapRootTime *google_protobuf2.Timestamp `protobuf:"bytes,5,opt,name=map_root_time,json=mapRootTime" json:"map_root_time,omitempty"`
+	// time_since_map_root is the time elapsed since map_root_time.
+	TimeSinceMapRoot *google_protobuf1.Duration `protobuf:"bytes,6,opt,name=time_since_map_root,json=timeSinceMapRoot" json:"time_since_map_root,omitempty"`
+	// log_tree_size is the size of the latest signed log root. The log holds
+	// one leaf per map revision, so map revisions at or above log_tree_size
+	// have not yet been published in the log.
+	LogTreeSize int64 `protobuf:"varint,7,opt,name=log_tree_size,json=logTreeSize" json:"log_tree_size,omitempty"`
+	// log_root_time is when the latest log root was signed.
+	LogRootTime *google_protobuf2.Timestamp `protobuf:"bytes,8,opt,name=log_root_time,json=logRootTime" json:"log_root_time,omitempty"`
+	// log_tree_state is the Trillian state of the log tree.
+	LogTreeState trillian.TreeState `protobuf:"varint,9,opt,name=log_tree_state,json=logTreeState,enum=trillian.TreeState" json:"log_tree_state,omitempty"`
+	// map_tree_state is the Trillian state of the map tree.
+	MapTreeState trillian.TreeState `protobuf:"varint,10,opt,name=map_tree_state,json=mapTreeState,enum=trillian.TreeState" json:"map_tree_state,omitempty"`
+}
+
+func (m *DomainStatus) Reset()                    { *m = DomainStatus{} }
+func (m *DomainStatus) String() string            { return proto.CompactTextString(m) }
+func (*DomainStatus) ProtoMessage()               {}
+func (*DomainStatus) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{40} }
+
+func (m *DomainStatus) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *DomainStatus) GetPendingMutations() int64 {
+	if m != nil {
+		return m.PendingMutations
+	}
+	return 0
+}
+
+func (m *DomainStatus) GetOldestPendingMutation() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.OldestPendingMutation
+	}
+	return nil
+}
+
+func (m *DomainStatus) GetMapRevision() int64 {
+	if m != nil {
+		return m.MapRevision
+	}
+	return 0
+}
+
+func (m *DomainStatus) GetMapRootTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.MapRootTime
+	}
+	return nil
+}
+
+func (m *DomainStatus) GetTimeSinceMapRoot() *google_protobuf1.Duration {
+	if m != nil {
+		return m.TimeSinceMapRoot
+	}
+	return nil
+}
+
+func (m *DomainStatus) GetLogTreeSize() int64 {
+	if m != nil {
+		return m.LogTreeSize
+	}
+	return 0
+}
+
+func (m *DomainStatus) GetLogRootTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.LogRootTime
+	}
+	return nil
+}
+
+func (m *DomainStatus) GetLogTreeState() trillian.TreeState {
+	if m != nil {
+		return m.LogTreeState
+	}
+	return trillian.TreeState_UNKNOWN_TREE_STATE
+}
+
+func (m *DomainStatus) GetMapTreeState() trillian.TreeState {
+	if m != nil {
+		return m.MapTreeState
+	}
+	return trillian.TreeState_UNKNOWN_TREE_STATE
+}
+
+// WatchDomainsRequest subscribes to changes to the configuration of domains.
+type WatchDomainsRequest struct {
+	// initial requests a CREATED event for every active domain before changes
+	// are streamed.
+	Initial bool `protobuf:"varint,1,opt,name=initial" json:"initial,omitempty"`
+}
+
+func (m *WatchDomainsRequest) Reset()                    { *m = WatchDomainsRequest{} }
+func (m *WatchDomainsRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchDomainsRequest) ProtoMessage()               {}
+func (*WatchDomainsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{41} }
+
+func (m *WatchDomainsRequest) GetInitial() bool {
+	if m != nil {
+		return m.Initial
+	}
+	return false
+}
+
+// DomainEvent describes a change to the configuration of a domain.
+type DomainEvent struct {
+	Type DomainEvent_Type `protobuf:"varint,1,opt,name=type,enum=google.keytransparency.v1.DomainEvent_Type" json:"type,omitempty"`
+	// domain is the configuration of the domain after the change.
+	Domain *Domain `protobuf:"bytes,2,opt,name=domain" json:"domain,omitempty"`
+	// time is when the change was observed.
+	Time *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *DomainEvent) Reset()                    { *m = DomainEvent{} }
+func (m *DomainEvent) String() string            { return proto.CompactTextString(m) }
+func (*DomainEvent) ProtoMessage()               {}
+func (*DomainEvent) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{42} }
+
+func (m *DomainEvent) GetType() DomainEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return DomainEvent_UNKNOWN_TYPE
+}
+
+func (m *DomainEvent) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *DomainEvent) GetTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+// EvaluateKeyPolicyRequest applies a proposed key policy to the recent
+// mutations of a domain without enforcing it.
+type EvaluateKeyPolicyRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// key_policy is the proposed policy.
+	KeyPolicy *KeyPolicy `protobuf:"bytes,2,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+	// max_mutations is the number of recent mutations to evaluate. Defaults to
+	// 1000.
+	MaxMutations int32 `protobuf:"varint,3,opt,name=max_mutations,json=maxMutations" json:"max_mutations,omitempty"`
+}
+
+func (m *EvaluateKeyPolicyRequest) Reset()                    { *m = EvaluateKeyPolicyRequest{} }
+func (m *EvaluateKeyPolicyRequest) String() string            { return proto.CompactTextString(m) }
+func (*EvaluateKeyPolicyRequest) ProtoMessage()               {}
+func (*EvaluateKeyPolicyRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{43} }
+
+func (m *EvaluateKeyPolicyRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *EvaluateKeyPolicyRequest) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+func (m *EvaluateKeyPolicyRequest) GetMaxMutations() int32 {
+	if m != nil {
+		return m.MaxMutations
+	}
+	return 0
+}
+
+// RejectedMutation is a mutation that a proposed key policy would reject.
+type RejectedMutation struct {
+	// revision is the map revision the mutation was applied in.
+	Revision int64 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	// index is the map index of the entry the mutation changed.
+	Index []byte `protobuf:"bytes,2,opt,name=index,proto3" json:"index,omitempty"`
+	// reason describes why the policy rejects the mutation.
+	Reason string `protobuf:"bytes,3,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *RejectedMutation) Reset()                    { *m = RejectedMutation{} }
+func (m *RejectedMutation) String() string            { return proto.CompactTextString(m) }
+func (*RejectedMutation) ProtoMessage()               {}
+func (*RejectedMutation) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{44} }
+
+func (m *RejectedMutation) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *RejectedMutation) GetIndex() []byte {
+	if m != nil {
+		return m.Index
+	}
+	return nil
+}
+
+func (m *RejectedMutation) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+// EvaluateKeyPolicyResponse reports which recent mutations a proposed key
+// policy would reject.
+type EvaluateKeyPolicyResponse struct {
+	// evaluated is the number of mutations the policy was applied to.
+	Evaluated int32 `protobuf:"varint,1,opt,name=evaluated" json:"evaluated,omitempty"`
+	// rejected lists the evaluated mutations that the policy would reject.
+	Rejected []*RejectedMutation `protobuf:"bytes,2,rep,name=rejected" json:"rejected,omitempty"`
+	// first_revision and last_revision are the oldest and newest map revisions
+	// that mutations were evaluated from.
+	FirstRevision int64 `protobuf:"varint,3,opt,name=first_revision,json=firstRevision" json:"first_revision,omitempty"`
+	LastRevision  int64 `protobuf:"varint,4,opt,name=last_revision,json=lastRevision" json:"last_revision,omitempty"`
+}
+
+func (m *EvaluateKeyPolicyResponse) Reset()                    { *m = EvaluateKeyPolicyResponse{} }
+func (m *EvaluateKeyPolicyResponse) String() string            { return proto.CompactTextString(m) }
+func (*EvaluateKeyPolicyResponse) ProtoMessage()               {}
+func (*EvaluateKeyPolicyResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{45} }
+
+func (m *EvaluateKeyPolicyResponse) GetEvaluated() int32 {
+	if m != nil {
+		return m.Evaluated
+	}
+	return 0
+}
+
+func (m *EvaluateKeyPolicyResponse) GetRejected() []*RejectedMutation {
+	if m != nil {
+		return m.Rejected
+	}
+	return nil
+}
+
+func (m *EvaluateKeyPolicyResponse) GetFirstRevision() int64 {
+	if m != nil {
+		return m.FirstRevision
+	}
+	return 0
+}
+
+func (m *EvaluateKeyPolicyResponse) GetLastRevision() int64 {
+	if m != nil {
+		return m.LastRevision
+	}
+	return 0
+}
+
+// RebuildDomainMapRequest replays the mutations of a domain into a new map.
+type RebuildDomainMapRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// keep_map keeps the rebuilt map once it has been verified. By default it
+	// is deleted, and the call only checks the map of the domain.
+	KeepMap bool `protobuf:"varint,2,opt,name=keep_map,json=keepMap" json:"keep_map,omitempty"`
+}
+
+func (m *RebuildDomainMapRequest) Reset()                    { *m = RebuildDomainMapRequest{} }
+func (m *RebuildDomainMapRequest) String() string            { return proto.CompactTextString(m) }
+func (*RebuildDomainMapRequest) ProtoMessage()               {}
+func (*RebuildDomainMapRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{46} }
+
+func (m *RebuildDomainMapRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *RebuildDomainMapRequest) GetKeepMap() bool {
+	if m != nil {
+		return m.KeepMap
+	}
+	return false
+}
+
+// RebuildDomainMapResponse describes a map rebuilt from the mutations of a
+// domain.
+type RebuildDomainMapResponse struct {
+	// map_id is the ID of the rebuilt map. It is only set if keep_map was
+	// requested.
+	MapId int64 `protobuf:"varint,1,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	// revision is the last revision that was replayed.
+	Revision int64 `protobuf:"varint,2,opt,name=revision" json:"revision,omitempty"`
+	// root_hash is the root hash of the rebuilt map at revision.
+	RootHash []byte `protobuf:"bytes,3,opt,name=root_hash,json=rootHash,proto3" json:"root_hash,omitempty"`
+	// verified_revisions is the number of replayed revisions whose root hash
+	// was checked against the map root published in the SMH log. Revisions
+	// that the log has not sequenced yet are not checked.
+	VerifiedRevisions int64 `protobuf:"varint,4,opt,name=verified_revisions,json=verifiedRevisions" json:"verified_revisions,omitempty"`
+}
+
+func (m *RebuildDomainMapResponse) Reset()                    { *m = RebuildDomainMapResponse{} }
+func (m *RebuildDomainMapResponse) String() string            { return proto.CompactTextString(m) }
+func (*RebuildDomainMapResponse) ProtoMessage()               {}
+func (*RebuildDomainMapResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{47} }
+
+func (m *RebuildDomainMapResponse) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+func (m *RebuildDomainMapResponse) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *RebuildDomainMapResponse) GetRootHash() []byte {
+	if m != nil {
+		return m.RootHash
+	}
+	return nil
+}
+
+func (m *RebuildDomainMapResponse) GetVerifiedRevisions() int64 {
+	if m != nil {
+		return m.VerifiedRevisions
+	}
+	return 0
+}
+
+// GetReconciliationRequest requests the result of checking the domains in
+// domain storage against their trees in Trillian.
+type GetReconciliationRequest struct {
+	// rerun checks the domains again instead of returning the result of the
+	// last check.
+	Rerun bool `protobuf:"varint,1,opt,name=rerun" json:"rerun,omitempty"`
+}
+
+func (m *GetReconciliationRequest) Reset()                    { *m = GetReconciliationRequest{} }
+func (m *GetReconciliationRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetReconciliationRequest) ProtoMessage()               {}
+func (*GetReconciliationRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{48} }
+
+func (m *GetReconciliationRequest) GetRerun() bool {
+	if m != nil {
+		return m.Rerun
+	}
+	return false
+}
+
+// DomainReconciliation is the result of checking a domain against its trees.
+type DomainReconciliation struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// problems describes the mismatches that were found. A domain with
+	// problems is degraded.
+	Problems []string `protobuf:"bytes,2,rep,name=problems" json:"problems,omitempty"`
+}
+
+func (m *DomainReconciliation) Reset()                    { *m = DomainReconciliation{} }
+func (m *DomainReconciliation) String() string            { return proto.CompactTextString(m) }
+func (*DomainReconciliation) ProtoMessage()               {}
+func (*DomainReconciliation) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{49} }
+
+func (m *DomainReconciliation) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *DomainReconciliation) GetProblems() []string {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+// Reconciliation is the result of checking all active domains against their
+// trees in Trillian.
+type Reconciliation struct {
+	// time is when the check ran.
+	Time    *google_protobuf2.Timestamp `protobuf:"bytes,1,opt,name=time" json:"time,omitempty"`
+	Domains []*DomainReconciliation     `protobuf:"bytes,2,rep,name=domains" json:"domains,omitempty"`
+}
+
+func (m *Reconciliation) Reset()                    { *m = Reconciliation{} }
+func (m *Reconciliation) String() string            { return proto.CompactTextString(m) }
+func (*Reconciliation) ProtoMessage()               {}
+func (*Reconciliation) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{50} }
+
+func (m *Reconciliation) GetTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Reconciliation) GetDomains() []*DomainReconciliation {
+	if m != nil {
+		return m.Domains
+	}
+	return nil
+}
+
+// RuntimeConfig is the operational configuration of a domain that replicas
+// apply at runtime. It holds no private keys.
+type RuntimeConfig struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	LogId    int64  `protobuf:"varint,2,opt,name=log_id,json=logId" json:"log_id,omitempty"`
+	MapId    int64  `protobuf:"varint,3,opt,name=map_id,json=mapId" json:"map_id,omitempty"`
+	// log_backend names the smhlog backend that hosts the log.
+	LogBackend string `protobuf:"bytes,4,opt,name=log_backend,json=logBackend" json:"log_backend,omitempty"`
+	// trillian_backend locates the Trillian services that host the trees.
+	TrillianBackend   *TrillianBackend           `protobuf:"bytes,5,opt,name=trillian_backend,json=trillianBackend" json:"trillian_backend,omitempty"`
+	MinInterval       *google_protobuf1.Duration `protobuf:"bytes,6,opt,name=min_interval,json=minInterval" json:"min_interval,omitempty"`
+	MaxInterval       *google_protobuf1.Duration `protobuf:"bytes,7,opt,name=max_interval,json=maxInterval" json:"max_interval,omitempty"`
+	Frozen            bool                       `protobuf:"varint,8,opt,name=frozen" json:"frozen,omitempty"`
+	AppListing        bool                       `protobuf:"varint,9,opt,name=app_listing,json=appListing" json:"app_listing,omitempty"`
+	MutationQuota     *MutationQuota             `protobuf:"bytes,10,opt,name=mutation_quota,json=mutationQuota" json:"mutation_quota,omitempty"`
+	KeyPolicy         *KeyPolicy                 `protobuf:"bytes,11,opt,name=key_policy,json=keyPolicy" json:"key_policy,omitempty"`
+	EndorsementPolicy *EndorsementPolicy         `protobuf:"bytes,12,opt,name=endorsement_policy,json=endorsementPolicy" json:"endorsement_policy,omitempty"`
+	// deleted is true once the domain is deleted. Replicas stop serving it.
+	Deleted bool              `protobuf:"varint,13,opt,name=deleted" json:"deleted,omitempty"`
+	Labels  map[string]string `protobuf:"bytes,14,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// version identifies the content of the other fields. It changes whenever
+	// any of them changes.
+	Version string `protobuf:"bytes,15,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *RuntimeConfig) Reset()                    { *m = RuntimeConfig{} }
+func (m *RuntimeConfig) String() string            { return proto.CompactTextString(m) }
+func (*RuntimeConfig) ProtoMessage()               {}
+func (*RuntimeConfig) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{51} }
+
+func (m *RuntimeConfig) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *RuntimeConfig) GetLogId() int64 {
+	if m != nil {
+		return m.LogId
+	}
+	return 0
+}
+
+func (m *RuntimeConfig) GetMapId() int64 {
+	if m != nil {
+		return m.MapId
+	}
+	return 0
+}
+
+func (m *RuntimeConfig) GetLogBackend() string {
+	if m != nil {
+		return m.LogBackend
+	}
+	return ""
+}
+
+func (m *RuntimeConfig) GetTrillianBackend() *TrillianBackend {
+	if m != nil {
+		return m.TrillianBackend
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetMinInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MinInterval
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetMaxInterval() *google_protobuf1.Duration {
+	if m != nil {
+		return m.MaxInterval
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetFrozen() bool {
+	if m != nil {
+		return m.Frozen
+	}
+	return false
+}
+
+func (m *RuntimeConfig) GetAppListing() bool {
+	if m != nil {
+		return m.AppListing
+	}
+	return false
+}
+
+func (m *RuntimeConfig) GetMutationQuota() *MutationQuota {
+	if m != nil {
+		return m.MutationQuota
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetKeyPolicy() *KeyPolicy {
+	if m != nil {
+		return m.KeyPolicy
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetEndorsementPolicy() *EndorsementPolicy {
+	if m != nil {
+		return m.EndorsementPolicy
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
+func (m *RuntimeConfig) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *RuntimeConfig) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+// GetRuntimeConfigRequest asks for the runtime configuration of a domain.
+type GetRuntimeConfigRequest struct {
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+}
+
+func (m *GetRuntimeConfigRequest) Reset()                    { *m = GetRuntimeConfigRequest{} }
+func (m *GetRuntimeConfigRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRuntimeConfigRequest) ProtoMessage()               {}
+func (*GetRuntimeConfigRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{52} }
+
+func (m *GetRuntimeConfigRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+// WatchRuntimeConfigRequest subscribes to changes of runtime configuration.
+type WatchRuntimeConfigRequest struct {
+	// domain_ids restricts the stream to the listed domains. All domains are
+	// watched if it is empty.
+	DomainIds []string `protobuf:"bytes,1,rep,name=domain_ids,json=domainIds" json:"domain_ids,omitempty"`
+	// initial sends the current configuration of the watched domains before
+	// any changes.
+	Initial bool `protobuf:"varint,2,opt,name=initial" json:"initial,omitempty"`
+}
+
+func (m *WatchRuntimeConfigRequest) Reset()                    { *m = WatchRuntimeConfigRequest{} }
+func (m *WatchRuntimeConfigRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchRuntimeConfigRequest) ProtoMessage()               {}
+func (*WatchRuntimeConfigRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{53} }
+
+func (m *WatchRuntimeConfigRequest) GetDomainIds() []string {
+	if m != nil {
+		return m.DomainIds
+	}
+	return nil
+}
+
+func (m *WatchRuntimeConfigRequest) GetInitial() bool {
+	if m != nil {
+		return m.Initial
+	}
+	return false
+}
+
+// RuntimeConfigEvent carries the new runtime configuration of a domain.
+type RuntimeConfigEvent struct {
+	Config *RuntimeConfig `protobuf:"bytes,1,opt,name=config" json:"config,omitempty"`
+	// time is when the change was observed.
+	Time *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *RuntimeConfigEvent) Reset()                    { *m = RuntimeConfigEvent{} }
+func (m *RuntimeConfigEvent) String() string            { return proto.CompactTextString(m) }
+func (*RuntimeConfigEvent) ProtoMessage()               {}
+func (*RuntimeConfigEvent) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{54} }
+
+func (m *RuntimeConfigEvent) GetConfig() *RuntimeConfig {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+func (m *RuntimeConfigEvent) GetTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func init() {
 	proto.RegisterType((*Domain)(nil), "google.keytransparency.v1.Domain")
+	proto.RegisterType((*TrillianBackend)(nil), "google.keytransparency.v1.TrillianBackend")
+	proto.RegisterType((*TreeKeyRotation)(nil), "google.keytransparency.v1.TreeKeyRotation")
+	proto.RegisterType((*DomainMigration)(nil), "google.keytransparency.v1.DomainMigration")
+	proto.RegisterType((*SignedDomainMigration)(nil), "google.keytransparency.v1.SignedDomainMigration")
+	proto.RegisterType((*DomainConfig)(nil), "google.keytransparency.v1.DomainConfig")
+	proto.RegisterType((*SignedDomainConfig)(nil), "google.keytransparency.v1.SignedDomainConfig")
 	proto.RegisterType((*ListDomainsRequest)(nil), "google.keytransparency.v1.ListDomainsRequest")
 	proto.RegisterType((*ListDomainsResponse)(nil), "google.keytransparency.v1.ListDomainsResponse")
 	proto.RegisterType((*GetDomainRequest)(nil), "google.keytransparency.v1.GetDomainRequest")
 	proto.RegisterType((*CreateDomainRequest)(nil), "google.keytransparency.v1.CreateDomainRequest")
 	proto.RegisterType((*DeleteDomainRequest)(nil), "google.keytransparency.v1.DeleteDomainRequest")
 	proto.RegisterType((*UndeleteDomainRequest)(nil), "google.keytransparency.v1.UndeleteDomainRequest")
+	proto.RegisterType((*AddAppVRFRequest)(nil), "google.keytransparency.v1.AddAppVRFRequest")
+	proto.RegisterType((*TreeSpec)(nil), "google.keytransparency.v1.TreeSpec")
+	proto.RegisterType((*UpdateDomainRequest)(nil), "google.keytransparency.v1.UpdateDomainRequest")
+	proto.RegisterType((*RotateDomainVRFRequest)(nil), "google.keytransparency.v1.RotateDomainVRFRequest")
+	proto.RegisterType((*RotateTreeKeysRequest)(nil), "google.keytransparency.v1.RotateTreeKeysRequest")
+	proto.RegisterType((*PurgeEntryDataRequest)(nil), "google.keytransparency.v1.PurgeEntryDataRequest")
+	proto.RegisterType((*ListAuditEntriesRequest)(nil), "google.keytransparency.v1.ListAuditEntriesRequest")
+	proto.RegisterType((*AuditEntry)(nil), "google.keytransparency.v1.AuditEntry")
+	proto.RegisterType((*ListAuditEntriesResponse)(nil), "google.keytransparency.v1.ListAuditEntriesResponse")
+	proto.RegisterType((*DomainBundle)(nil), "google.keytransparency.v1.DomainBundle")
+	proto.RegisterType((*SignedDomainBundle)(nil), "google.keytransparency.v1.SignedDomainBundle")
+	proto.RegisterType((*ExportDomainRequest)(nil), "google.keytransparency.v1.ExportDomainRequest")
+	proto.RegisterType((*ImportDomainRequest)(nil), "google.keytransparency.v1.ImportDomainRequest")
+	proto.RegisterType((*KeyPolicy)(nil), "google.keytransparency.v1.KeyPolicy")
+	proto.RegisterType((*KeyPolicyChange)(nil), "google.keytransparency.v1.KeyPolicyChange")
+	proto.RegisterType((*SetKeyPolicyRequest)(nil), "google.keytransparency.v1.SetKeyPolicyRequest")
+	proto.RegisterType((*FreezeDomainRequest)(nil), "google.keytransparency.v1.FreezeDomainRequest")
+	proto.RegisterType((*UnfreezeDomainRequest)(nil), "google.keytransparency.v1.UnfreezeDomainRequest")
+	proto.RegisterType((*ForceEpochRequest)(nil), "google.keytransparency.v1.ForceEpochRequest")
+	proto.RegisterType((*SetAppListingRequest)(nil), "google.keytransparency.v1.SetAppListingRequest")
+	proto.RegisterType((*MutationQuota)(nil), "google.keytransparency.v1.MutationQuota")
+	proto.RegisterType((*SetMutationQuotaRequest)(nil), "google.keytransparency.v1.SetMutationQuotaRequest")
+	proto.RegisterType((*EndorsementPolicy)(nil), "google.keytransparency.v1.EndorsementPolicy")
+	proto.RegisterType((*SetEndorsementPolicyRequest)(nil), "google.keytransparency.v1.SetEndorsementPolicyRequest")
+	proto.RegisterType((*SetDomainIntervalsRequest)(nil), "google.keytransparency.v1.SetDomainIntervalsRequest")
+	proto.RegisterType((*AnnounceDomainMigrationRequest)(nil), "google.keytransparency.v1.AnnounceDomainMigrationRequest")
+	proto.RegisterType((*GetDomainStatusRequest)(nil), "google.keytransparency.v1.GetDomainStatusRequest")
+	proto.RegisterType((*DomainStatus)(nil), "google.keytransparency.v1.DomainStatus")
+	proto.RegisterType((*WatchDomainsRequest)(nil), "google.keytransparency.v1.WatchDomainsRequest")
+	proto.RegisterType((*DomainEvent)(nil), "google.keytransparency.v1.DomainEvent")
+	proto.RegisterType((*EvaluateKeyPolicyRequest)(nil), "google.keytransparency.v1.EvaluateKeyPolicyRequest")
+	proto.RegisterType((*RejectedMutation)(nil), "google.keytransparency.v1.RejectedMutation")
+	proto.RegisterType((*EvaluateKeyPolicyResponse)(nil), "google.keytransparency.v1.EvaluateKeyPolicyResponse")
+	proto.RegisterType((*RebuildDomainMapRequest)(nil), "google.keytransparency.v1.RebuildDomainMapRequest")
+	proto.RegisterType((*RebuildDomainMapResponse)(nil), "google.keytransparency.v1.RebuildDomainMapResponse")
+	proto.RegisterType((*GetReconciliationRequest)(nil), "google.keytransparency.v1.GetReconciliationRequest")
+	proto.RegisterType((*DomainReconciliation)(nil), "google.keytransparency.v1.DomainReconciliation")
+	proto.RegisterType((*Reconciliation)(nil), "google.keytransparency.v1.Reconciliation")
+	proto.RegisterType((*RuntimeConfig)(nil), "google.keytransparency.v1.RuntimeConfig")
+	proto.RegisterType((*GetRuntimeConfigRequest)(nil), "google.keytransparency.v1.GetRuntimeConfigRequest")
+	proto.RegisterType((*WatchRuntimeConfigRequest)(nil), "google.keytransparency.v1.WatchRuntimeConfigRequest")
+	proto.RegisterType((*RuntimeConfigEvent)(nil), "google.keytransparency.v1.RuntimeConfigEvent")
+	proto.RegisterEnum("google.keytransparency.v1.VrfAlgorithm", VrfAlgorithm_name, VrfAlgorithm_value)
+	proto.RegisterEnum("google.keytransparency.v1.DomainEvent_Type", DomainEvent_Type_name, DomainEvent_Type_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for KeyTransparencyAdmin service
+
+type KeyTransparencyAdminClient interface {
+	// ListDomains returns a list of all domains this Key Transparency server
+	// operates on.
+	ListDomains(ctx context.Context, in *ListDomainsRequest, opts ...grpc.CallOption) (*ListDomainsResponse, error)
+	// GetDomain returns the confiuration information for a given domain.
+	GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// CreateDomain creates a new Trillian log/map pair.  A unique domainId must
+	// be provided.  To create a new domain with the same name as a previously
+	// deleted domain, a user must wait X days until the domain is garbage
+	// collected.
+	CreateDomain(ctx context.Context, in *CreateDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// DeleteDomain marks a domain as deleted.  Domains become eligible for
+	// garbage collection once the retention window of the server has expired.
+	DeleteDomain(ctx context.Context, in *DeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
+	// UndeleteDomain marks a previously deleted domain as active if its
+	// retention window has not expired.
+	UndeleteDomain(ctx context.Context, in *UndeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
+	// AddAppVRF generates a new VRF key that is used to compute the indexes of
+	// a single app. Compromise of an app's VRF key does not reveal the indexes
+	// of users in other apps. Apps that already have entries are refused, as
+	// their entries are stored under indexes of the domain's VRF. Domains that
+	// held entries before the key server recorded the app of each update are
+	// refused, as the apps of those entries are unknown.
+	AddAppVRF(ctx context.Context, in *AddAppVRFRequest, opts ...grpc.CallOption) (*Domain, error)
+	// UpdateDomain changes the max root duration of a domain's trees.
+	UpdateDomain(ctx context.Context, in *UpdateDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// RotateDomainVRF replaces the VRF key of a domain. Both the old and new
+	// public keys are published until the overlap window ends so that clients
+	// can verify indexes computed under either key while entries migrate.
+	RotateDomainVRF(ctx context.Context, in *RotateDomainVRFRequest, opts ...grpc.CallOption) (*Domain, error)
+	// RotateTreeKeys generates new signing keys for the log and map trees of a
+	// domain and installs them in Trillian. The replaced keys are published
+	// with the domain so that clients accept roots signed with them until the
+	// end of the requested overlap.
+	RotateTreeKeys(ctx context.Context, in *RotateTreeKeysRequest, opts ...grpc.CallOption) (*Domain, error)
+	// PurgeEntryData withholds the committed profile data of a user for past
+	// revisions. The commitments remain in the map, so map and log roots are
+	// unchanged, and GetEntry reports the data as purged rather than absent.
+	// The data is not deleted: it remains in the leaves of the Trillian map,
+	// which the key server no longer serves it from.
+	PurgeEntryData(ctx context.Context, in *PurgeEntryDataRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
+	// ListAuditEntries returns the admin RPCs that have been performed on a
+	// domain, in the order they were received.
+	ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error)
+	// ExportDomain returns a signed bundle containing the definition of a
+	// domain, including its VRF private keys, for disaster recovery.
+	ExportDomain(ctx context.Context, in *ExportDomainRequest, opts ...grpc.CallOption) (*SignedDomainBundle, error)
+	// ImportDomain restores a domain from a bundle produced by ExportDomain on
+	// this or another Key Transparency instance.
+	ImportDomain(ctx context.Context, in *ImportDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// SetKeyPolicy replaces the key policy of a domain. The policy applies to
+	// new mutations from the next revision on, which is recorded in
+	// key_policy_history; existing entries are not affected until they are
+	// updated.
+	SetKeyPolicy(ctx context.Context, in *SetKeyPolicyRequest, opts ...grpc.CallOption) (*Domain, error)
+	// FreezeDomain makes a domain read-only. Entries and history can still be
+	// read, but UpdateEntry fails with FAILED_PRECONDITION and the sequencer
+	// only creates empty epochs until the domain is unfrozen. Queued mutations
+	// are sequenced once the domain is unfrozen.
+	FreezeDomain(ctx context.Context, in *FreezeDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// UnfreezeDomain makes a frozen domain writable again.
+	UnfreezeDomain(ctx context.Context, in *UnfreezeDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// ForceEpoch creates a new epoch containing the queued mutations of a
+	// domain without waiting for min_interval or max_interval.
+	ForceEpoch(ctx context.Context, in *ForceEpochRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
+	// SetAppListing controls whether users can list the apps they have entries
+	// for in a domain.
+	SetAppListing(ctx context.Context, in *SetAppListingRequest, opts ...grpc.CallOption) (*Domain, error)
+	// SetMutationQuota replaces the mutation quota of a domain. UpdateEntry
+	// fails with RESOURCE_EXHAUSTED once a quota is used up.
+	SetMutationQuota(ctx context.Context, in *SetMutationQuotaRequest, opts ...grpc.CallOption) (*Domain, error)
+	// GetDomainStatus returns the sequencing lag and tree health of a domain.
+	GetDomainStatus(ctx context.Context, in *GetDomainStatusRequest, opts ...grpc.CallOption) (*DomainStatus, error)
+	// WatchDomains streams changes to the configuration of domains, so that
+	// servers can react to new and deleted domains without polling ListDomains.
+	WatchDomains(ctx context.Context, in *WatchDomainsRequest, opts ...grpc.CallOption) (KeyTransparencyAdmin_WatchDomainsClient, error)
+	// EvaluateKeyPolicy reports which recent mutations of a domain a proposed
+	// key policy would reject. Nothing is changed; use it to assess a policy
+	// before setting it with SetKeyPolicy.
+	EvaluateKeyPolicy(ctx context.Context, in *EvaluateKeyPolicyRequest, opts ...grpc.CallOption) (*EvaluateKeyPolicyResponse, error)
+	// SetEndorsementPolicy replaces the endorsers that co-sign the map roots of
+	// a domain. The policy applies to map roots created after the call.
+	SetEndorsementPolicy(ctx context.Context, in *SetEndorsementPolicyRequest, opts ...grpc.CallOption) (*Domain, error)
+	// SetDomainIntervals changes the minimum and maximum time between the epochs
+	// of a domain. The sequencer applies the new intervals without a restart.
+	SetDomainIntervals(ctx context.Context, in *SetDomainIntervalsRequest, opts ...grpc.CallOption) (*Domain, error)
+	// AnnounceDomainMigration publishes a signed announcement that a domain
+	// moves to another key server or other keys at a map revision. GetDomain
+	// returns the announcement, and clients follow it once.
+	AnnounceDomainMigration(ctx context.Context, in *AnnounceDomainMigrationRequest, opts ...grpc.CallOption) (*Domain, error)
+	// RebuildDomainMap replays the full mutation history of a domain into a new
+	// map and checks each revision against the map roots published in the SMH
+	// log. It fails with DATA_LOSS at the first revision whose root hash does
+	// not match. The map of the domain is not changed.
+	RebuildDomainMap(ctx context.Context, in *RebuildDomainMapRequest, opts ...grpc.CallOption) (*RebuildDomainMapResponse, error)
+	// GetReconciliation returns the result of checking that the tree IDs of
+	// every active domain name active trees with the expected hash strategies,
+	// and that the latest map root of the domain is in its SMH log. Servers run
+	// the check at startup and do not serve degraded domains.
+	GetReconciliation(ctx context.Context, in *GetReconciliationRequest, opts ...grpc.CallOption) (*Reconciliation, error)
+}
+
+type keyTransparencyAdminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewKeyTransparencyAdminClient(cc *grpc.ClientConn) KeyTransparencyAdminClient {
+	return &keyTransparencyAdminClient{cc}
+}
+
+func (c *keyTransparencyAdminClient) ListDomains(ctx context.Context, in *ListDomainsRequest, opts ...grpc.CallOption) (*ListDomainsResponse, error) {
+	out := new(ListDomainsResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ListDomains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) CreateDomain(ctx context.Context, in *CreateDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/CreateDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) DeleteDomain(ctx context.Context, in *DeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
+	out := new(google_protobuf4.Empty)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/DeleteDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) UndeleteDomain(ctx context.Context, in *UndeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
+	out := new(google_protobuf4.Empty)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/UndeleteDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) AddAppVRF(ctx context.Context, in *AddAppVRFRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/AddAppVRF", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) UpdateDomain(ctx context.Context, in *UpdateDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/UpdateDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) RotateDomainVRF(ctx context.Context, in *RotateDomainVRFRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/RotateDomainVRF", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) RotateTreeKeys(ctx context.Context, in *RotateTreeKeysRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/RotateTreeKeys", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) PurgeEntryData(ctx context.Context, in *PurgeEntryDataRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
+	out := new(google_protobuf4.Empty)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/PurgeEntryData", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error) {
+	out := new(ListAuditEntriesResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ListAuditEntries", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) ExportDomain(ctx context.Context, in *ExportDomainRequest, opts ...grpc.CallOption) (*SignedDomainBundle, error) {
+	out := new(SignedDomainBundle)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ExportDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) ImportDomain(ctx context.Context, in *ImportDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ImportDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) SetKeyPolicy(ctx context.Context, in *SetKeyPolicyRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/SetKeyPolicy", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) FreezeDomain(ctx context.Context, in *FreezeDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/FreezeDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) UnfreezeDomain(ctx context.Context, in *UnfreezeDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/UnfreezeDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) ForceEpoch(ctx context.Context, in *ForceEpochRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
+	out := new(google_protobuf4.Empty)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ForceEpoch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) SetAppListing(ctx context.Context, in *SetAppListingRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/SetAppListing", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) SetMutationQuota(ctx context.Context, in *SetMutationQuotaRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/SetMutationQuota", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) GetDomainStatus(ctx context.Context, in *GetDomainStatusRequest, opts ...grpc.CallOption) (*DomainStatus, error) {
+	out := new(DomainStatus)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomainStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) WatchDomains(ctx context.Context, in *WatchDomainsRequest, opts ...grpc.CallOption) (KeyTransparencyAdmin_WatchDomainsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparencyAdmin_serviceDesc.Streams[0], c.cc, "/google.keytransparency.v1.KeyTransparencyAdmin/WatchDomains", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyAdminWatchDomainsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparencyAdmin_WatchDomainsClient interface {
+	Recv() (*DomainEvent, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyAdminWatchDomainsClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyAdminWatchDomainsClient) Recv() (*DomainEvent, error) {
+	m := new(DomainEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keyTransparencyAdminClient) EvaluateKeyPolicy(ctx context.Context, in *EvaluateKeyPolicyRequest, opts ...grpc.CallOption) (*EvaluateKeyPolicyResponse, error) {
+	out := new(EvaluateKeyPolicyResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/EvaluateKeyPolicy", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyAdminClient) SetEndorsementPolicy(ctx context.Context, in *SetEndorsementPolicyRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/SetEndorsementPolicy", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (c *keyTransparencyAdminClient) SetDomainIntervals(ctx context.Context, in *SetDomainIntervalsRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/SetDomainIntervals", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (c *keyTransparencyAdminClient) AnnounceDomainMigration(ctx context.Context, in *AnnounceDomainMigrationRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/AnnounceDomainMigration", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-// Client API for KeyTransparencyAdmin service
+func (c *keyTransparencyAdminClient) RebuildDomainMap(ctx context.Context, in *RebuildDomainMapRequest, opts ...grpc.CallOption) (*RebuildDomainMapResponse, error) {
+	out := new(RebuildDomainMapResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/RebuildDomainMap", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-type KeyTransparencyAdminClient interface {
+func (c *keyTransparencyAdminClient) GetReconciliation(ctx context.Context, in *GetReconciliationRequest, opts ...grpc.CallOption) (*Reconciliation, error) {
+	out := new(Reconciliation)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/GetReconciliation", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for KeyTransparencyAdmin service
+
+type KeyTransparencyAdminServer interface {
 	// ListDomains returns a list of all domains this Key Transparency server
 	// operates on.
-	ListDomains(ctx context.Context, in *ListDomainsRequest, opts ...grpc.CallOption) (*ListDomainsResponse, error)
+	ListDomains(context.Context, *ListDomainsRequest) (*ListDomainsResponse, error)
 	// GetDomain returns the confiuration information for a given domain.
-	GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	GetDomain(context.Context, *GetDomainRequest) (*Domain, error)
 	// CreateDomain creates a new Trillian log/map pair.  A unique domainId must
 	// be provided.  To create a new domain with the same name as a previously
 	// deleted domain, a user must wait X days until the domain is garbage
 	// collected.
-	CreateDomain(ctx context.Context, in *CreateDomainRequest, opts ...grpc.CallOption) (*Domain, error)
-	// DeleteDomain marks a domain as deleted.  Domains will be garbage collected
-	// after X days.
-	DeleteDomain(ctx context.Context, in *DeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
-	// UndeleteDomain marks a previously deleted domain as active if it has not
-	// already been garbage collected.
-	UndeleteDomain(ctx context.Context, in *UndeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error)
+	CreateDomain(context.Context, *CreateDomainRequest) (*Domain, error)
+	// DeleteDomain marks a domain as deleted.  Domains become eligible for
+	// garbage collection once the retention window of the server has expired.
+	DeleteDomain(context.Context, *DeleteDomainRequest) (*google_protobuf4.Empty, error)
+	// UndeleteDomain marks a previously deleted domain as active if its
+	// retention window has not expired.
+	UndeleteDomain(context.Context, *UndeleteDomainRequest) (*google_protobuf4.Empty, error)
+	// AddAppVRF generates a new VRF key that is used to compute the indexes of
+	// a single app. Compromise of an app's VRF key does not reveal the indexes
+	// of users in other apps. Apps that already have entries are refused, as
+	// their entries are stored under indexes of the domain's VRF. Domains that
+	// held entries before the key server recorded the app of each update are
+	// refused, as the apps of those entries are unknown.
+	AddAppVRF(context.Context, *AddAppVRFRequest) (*Domain, error)
+	// UpdateDomain changes the max root duration of a domain's trees.
+	UpdateDomain(context.Context, *UpdateDomainRequest) (*Domain, error)
+	// RotateDomainVRF replaces the VRF key of a domain. Both the old and new
+	// public keys are published until the overlap window ends so that clients
+	// can verify indexes computed under either key while entries migrate.
+	RotateDomainVRF(context.Context, *RotateDomainVRFRequest) (*Domain, error)
+	// RotateTreeKeys generates new signing keys for the log and map trees of a
+	// domain and installs them in Trillian. The replaced keys are published
+	// with the domain so that clients accept roots signed with them until the
+	// end of the requested overlap.
+	RotateTreeKeys(context.Context, *RotateTreeKeysRequest) (*Domain, error)
+	// PurgeEntryData withholds the committed profile data of a user for past
+	// revisions. The commitments remain in the map, so map and log roots are
+	// unchanged, and GetEntry reports the data as purged rather than absent.
+	// The data is not deleted: it remains in the leaves of the Trillian map,
+	// which the key server no longer serves it from.
+	PurgeEntryData(context.Context, *PurgeEntryDataRequest) (*google_protobuf4.Empty, error)
+	// ListAuditEntries returns the admin RPCs that have been performed on a
+	// domain, in the order they were received.
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+	// ExportDomain returns a signed bundle containing the definition of a
+	// domain, including its VRF private keys, for disaster recovery.
+	ExportDomain(context.Context, *ExportDomainRequest) (*SignedDomainBundle, error)
+	// ImportDomain restores a domain from a bundle produced by ExportDomain on
+	// this or another Key Transparency instance.
+	ImportDomain(context.Context, *ImportDomainRequest) (*Domain, error)
+	// SetKeyPolicy replaces the key policy of a domain. The policy applies to
+	// new mutations from the next revision on, which is recorded in
+	// key_policy_history; existing entries are not affected until they are
+	// updated.
+	SetKeyPolicy(context.Context, *SetKeyPolicyRequest) (*Domain, error)
+	// FreezeDomain makes a domain read-only. Entries and history can still be
+	// read, but UpdateEntry fails with FAILED_PRECONDITION and the sequencer
+	// only creates empty epochs until the domain is unfrozen. Queued mutations
+	// are sequenced once the domain is unfrozen.
+	FreezeDomain(context.Context, *FreezeDomainRequest) (*Domain, error)
+	// UnfreezeDomain makes a frozen domain writable again.
+	UnfreezeDomain(context.Context, *UnfreezeDomainRequest) (*Domain, error)
+	// ForceEpoch creates a new epoch containing the queued mutations of a
+	// domain without waiting for min_interval or max_interval.
+	ForceEpoch(context.Context, *ForceEpochRequest) (*google_protobuf4.Empty, error)
+	// SetAppListing controls whether users can list the apps they have entries
+	// for in a domain.
+	SetAppListing(context.Context, *SetAppListingRequest) (*Domain, error)
+	// SetMutationQuota replaces the mutation quota of a domain. UpdateEntry
+	// fails with RESOURCE_EXHAUSTED once a quota is used up.
+	SetMutationQuota(context.Context, *SetMutationQuotaRequest) (*Domain, error)
+	// GetDomainStatus returns the sequencing lag and tree health of a domain.
+	GetDomainStatus(context.Context, *GetDomainStatusRequest) (*DomainStatus, error)
+	// WatchDomains streams changes to the configuration of domains, so that
+	// servers can react to new and deleted domains without polling ListDomains.
+	WatchDomains(*WatchDomainsRequest, KeyTransparencyAdmin_WatchDomainsServer) error
+	// EvaluateKeyPolicy reports which recent mutations of a domain a proposed
+	// key policy would reject. Nothing is changed; use it to assess a policy
+	// before setting it with SetKeyPolicy.
+	EvaluateKeyPolicy(context.Context, *EvaluateKeyPolicyRequest) (*EvaluateKeyPolicyResponse, error)
+	// SetEndorsementPolicy replaces the endorsers that co-sign the map roots of
+	// a domain. The policy applies to map roots created after the call.
+	SetEndorsementPolicy(context.Context, *SetEndorsementPolicyRequest) (*Domain, error)
+	// SetDomainIntervals changes the minimum and maximum time between the epochs
+	// of a domain. The sequencer applies the new intervals without a restart.
+	SetDomainIntervals(context.Context, *SetDomainIntervalsRequest) (*Domain, error)
+	// AnnounceDomainMigration publishes a signed announcement that a domain
+	// moves to another key server or other keys at a map revision. GetDomain
+	// returns the announcement, and clients follow it once.
+	AnnounceDomainMigration(context.Context, *AnnounceDomainMigrationRequest) (*Domain, error)
+	// RebuildDomainMap replays the full mutation history of a domain into a new
+	// map and checks each revision against the map roots published in the SMH
+	// log. It fails with DATA_LOSS at the first revision whose root hash does
+	// not match. The map of the domain is not changed.
+	RebuildDomainMap(context.Context, *RebuildDomainMapRequest) (*RebuildDomainMapResponse, error)
+	// GetReconciliation returns the result of checking that the tree IDs of
+	// every active domain name active trees with the expected hash strategies,
+	// and that the latest map root of the domain is in its SMH log. Servers run
+	// the check at startup and do not serve degraded domains.
+	GetReconciliation(context.Context, *GetReconciliationRequest) (*Reconciliation, error)
+}
+
+func RegisterKeyTransparencyAdminServer(s *grpc.Server, srv KeyTransparencyAdminServer) {
+	s.RegisterService(&_KeyTransparencyAdmin_serviceDesc, srv)
+}
+
+func _KeyTransparencyAdmin_ListDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDomainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).ListDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ListDomains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).ListDomains(ctx, req.(*ListDomainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_GetDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).GetDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).GetDomain(ctx, req.(*GetDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_CreateDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).CreateDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/CreateDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).CreateDomain(ctx, req.(*CreateDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_DeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).DeleteDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/DeleteDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).DeleteDomain(ctx, req.(*DeleteDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_UndeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).UndeleteDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/UndeleteDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).UndeleteDomain(ctx, req.(*UndeleteDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_AddAppVRF_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddAppVRFRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).AddAppVRF(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/AddAppVRF",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).AddAppVRF(ctx, req.(*AddAppVRFRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_UpdateDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).UpdateDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/UpdateDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).UpdateDomain(ctx, req.(*UpdateDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_RotateDomainVRF_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateDomainVRFRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).RotateDomainVRF(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/RotateDomainVRF",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).RotateDomainVRF(ctx, req.(*RotateDomainVRFRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_RotateTreeKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateTreeKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).RotateTreeKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/RotateTreeKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).RotateTreeKeys(ctx, req.(*RotateTreeKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_PurgeEntryData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeEntryDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).PurgeEntryData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/PurgeEntryData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).PurgeEntryData(ctx, req.(*PurgeEntryDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_ListAuditEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).ListAuditEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ListAuditEntries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).ListAuditEntries(ctx, req.(*ListAuditEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_ExportDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).ExportDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ExportDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).ExportDomain(ctx, req.(*ExportDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_ImportDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).ImportDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ImportDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).ImportDomain(ctx, req.(*ImportDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_SetKeyPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetKeyPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).SetKeyPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/SetKeyPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).SetKeyPolicy(ctx, req.(*SetKeyPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type keyTransparencyAdminClient struct {
-	cc *grpc.ClientConn
+func _KeyTransparencyAdmin_FreezeDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreezeDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).FreezeDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/FreezeDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).FreezeDomain(ctx, req.(*FreezeDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func NewKeyTransparencyAdminClient(cc *grpc.ClientConn) KeyTransparencyAdminClient {
-	return &keyTransparencyAdminClient{cc}
+func _KeyTransparencyAdmin_UnfreezeDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfreezeDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).UnfreezeDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/UnfreezeDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).UnfreezeDomain(ctx, req.(*UnfreezeDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *keyTransparencyAdminClient) ListDomains(ctx context.Context, in *ListDomainsRequest, opts ...grpc.CallOption) (*ListDomainsResponse, error) {
-	out := new(ListDomainsResponse)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/ListDomains", in, out, c.cc, opts...)
-	if err != nil {
+func _KeyTransparencyAdmin_ForceEpoch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceEpochRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).ForceEpoch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ForceEpoch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).ForceEpoch(ctx, req.(*ForceEpochRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *keyTransparencyAdminClient) GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
-	out := new(Domain)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomain", in, out, c.cc, opts...)
-	if err != nil {
+func _KeyTransparencyAdmin_SetAppListing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppListingRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).SetAppListing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/SetAppListing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).SetAppListing(ctx, req.(*SetAppListingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *keyTransparencyAdminClient) CreateDomain(ctx context.Context, in *CreateDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
-	out := new(Domain)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/CreateDomain", in, out, c.cc, opts...)
-	if err != nil {
+func _KeyTransparencyAdmin_SetMutationQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMutationQuotaRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).SetMutationQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/SetMutationQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).SetMutationQuota(ctx, req.(*SetMutationQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *keyTransparencyAdminClient) DeleteDomain(ctx context.Context, in *DeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
-	out := new(google_protobuf4.Empty)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/DeleteDomain", in, out, c.cc, opts...)
-	if err != nil {
+func _KeyTransparencyAdmin_GetDomainStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDomainStatusRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).GetDomainStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomainStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).GetDomainStatus(ctx, req.(*GetDomainStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *keyTransparencyAdminClient) UndeleteDomain(ctx context.Context, in *UndeleteDomainRequest, opts ...grpc.CallOption) (*google_protobuf4.Empty, error) {
-	out := new(google_protobuf4.Empty)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyAdmin/UndeleteDomain", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func _KeyTransparencyAdmin_WatchDomains_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDomainsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return out, nil
+	return srv.(KeyTransparencyAdminServer).WatchDomains(m, &keyTransparencyAdminWatchDomainsServer{stream})
 }
 
-// Server API for KeyTransparencyAdmin service
+type KeyTransparencyAdmin_WatchDomainsServer interface {
+	Send(*DomainEvent) error
+	grpc.ServerStream
+}
 
-type KeyTransparencyAdminServer interface {
-	// ListDomains returns a list of all domains this Key Transparency server
-	// operates on.
-	ListDomains(context.Context, *ListDomainsRequest) (*ListDomainsResponse, error)
-	// GetDomain returns the confiuration information for a given domain.
-	GetDomain(context.Context, *GetDomainRequest) (*Domain, error)
-	// CreateDomain creates a new Trillian log/map pair.  A unique domainId must
-	// be provided.  To create a new domain with the same name as a previously
-	// deleted domain, a user must wait X days until the domain is garbage
-	// collected.
-	CreateDomain(context.Context, *CreateDomainRequest) (*Domain, error)
-	// DeleteDomain marks a domain as deleted.  Domains will be garbage collected
-	// after X days.
-	DeleteDomain(context.Context, *DeleteDomainRequest) (*google_protobuf4.Empty, error)
-	// UndeleteDomain marks a previously deleted domain as active if it has not
-	// already been garbage collected.
-	UndeleteDomain(context.Context, *UndeleteDomainRequest) (*google_protobuf4.Empty, error)
+type keyTransparencyAdminWatchDomainsServer struct {
+	grpc.ServerStream
 }
 
-func RegisterKeyTransparencyAdminServer(s *grpc.Server, srv KeyTransparencyAdminServer) {
-	s.RegisterService(&_KeyTransparencyAdmin_serviceDesc, srv)
+func (x *keyTransparencyAdminWatchDomainsServer) Send(m *DomainEvent) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func _KeyTransparencyAdmin_ListDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListDomainsRequest)
+func _KeyTransparencyAdmin_EvaluateKeyPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateKeyPolicyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyTransparencyAdminServer).ListDomains(ctx, in)
+		return srv.(KeyTransparencyAdminServer).EvaluateKeyPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/ListDomains",
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/EvaluateKeyPolicy",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyTransparencyAdminServer).ListDomains(ctx, req.(*ListDomainsRequest))
+		return srv.(KeyTransparencyAdminServer).EvaluateKeyPolicy(ctx, req.(*EvaluateKeyPolicyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyTransparencyAdmin_GetDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetDomainRequest)
+func _KeyTransparencyAdmin_SetEndorsementPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEndorsementPolicyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyTransparencyAdminServer).GetDomain(ctx, in)
+		return srv.(KeyTransparencyAdminServer).SetEndorsementPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/GetDomain",
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/SetEndorsementPolicy",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyTransparencyAdminServer).GetDomain(ctx, req.(*GetDomainRequest))
+		return srv.(KeyTransparencyAdminServer).SetEndorsementPolicy(ctx, req.(*SetEndorsementPolicyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyTransparencyAdmin_CreateDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateDomainRequest)
+func _KeyTransparencyAdmin_SetDomainIntervals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDomainIntervalsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyTransparencyAdminServer).CreateDomain(ctx, in)
+		return srv.(KeyTransparencyAdminServer).SetDomainIntervals(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/CreateDomain",
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/SetDomainIntervals",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyTransparencyAdminServer).CreateDomain(ctx, req.(*CreateDomainRequest))
+		return srv.(KeyTransparencyAdminServer).SetDomainIntervals(ctx, req.(*SetDomainIntervalsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyTransparencyAdmin_DeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteDomainRequest)
+func _KeyTransparencyAdmin_AnnounceDomainMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnounceDomainMigrationRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyTransparencyAdminServer).DeleteDomain(ctx, in)
+		return srv.(KeyTransparencyAdminServer).AnnounceDomainMigration(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/DeleteDomain",
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/AnnounceDomainMigration",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyTransparencyAdminServer).DeleteDomain(ctx, req.(*DeleteDomainRequest))
+		return srv.(KeyTransparencyAdminServer).AnnounceDomainMigration(ctx, req.(*AnnounceDomainMigrationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyTransparencyAdmin_UndeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UndeleteDomainRequest)
+func _KeyTransparencyAdmin_RebuildDomainMap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebuildDomainMapRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyTransparencyAdminServer).UndeleteDomain(ctx, in)
+		return srv.(KeyTransparencyAdminServer).RebuildDomainMap(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/UndeleteDomain",
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/RebuildDomainMap",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyTransparencyAdminServer).UndeleteDomain(ctx, req.(*UndeleteDomainRequest))
+		return srv.(KeyTransparencyAdminServer).RebuildDomainMap(ctx, req.(*RebuildDomainMapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyAdmin_GetReconciliation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReconciliationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyAdminServer).GetReconciliation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyAdmin/GetReconciliation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyAdminServer).GetReconciliation(ctx, req.(*GetReconciliationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -457,52 +3440,483 @@ var _KeyTransparencyAdmin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UndeleteDomain",
 			Handler:    _KeyTransparencyAdmin_UndeleteDomain_Handler,
 		},
+		{
+			MethodName: "AddAppVRF",
+			Handler:    _KeyTransparencyAdmin_AddAppVRF_Handler,
+		},
+		{
+			MethodName: "UpdateDomain",
+			Handler:    _KeyTransparencyAdmin_UpdateDomain_Handler,
+		},
+		{
+			MethodName: "RotateDomainVRF",
+			Handler:    _KeyTransparencyAdmin_RotateDomainVRF_Handler,
+		},
+		{
+			MethodName: "RotateTreeKeys",
+			Handler:    _KeyTransparencyAdmin_RotateTreeKeys_Handler,
+		},
+		{
+			MethodName: "PurgeEntryData",
+			Handler:    _KeyTransparencyAdmin_PurgeEntryData_Handler,
+		},
+		{
+			MethodName: "ListAuditEntries",
+			Handler:    _KeyTransparencyAdmin_ListAuditEntries_Handler,
+		},
+		{
+			MethodName: "ExportDomain",
+			Handler:    _KeyTransparencyAdmin_ExportDomain_Handler,
+		},
+		{
+			MethodName: "ImportDomain",
+			Handler:    _KeyTransparencyAdmin_ImportDomain_Handler,
+		},
+		{
+			MethodName: "SetKeyPolicy",
+			Handler:    _KeyTransparencyAdmin_SetKeyPolicy_Handler,
+		},
+		{
+			MethodName: "FreezeDomain",
+			Handler:    _KeyTransparencyAdmin_FreezeDomain_Handler,
+		},
+		{
+			MethodName: "UnfreezeDomain",
+			Handler:    _KeyTransparencyAdmin_UnfreezeDomain_Handler,
+		},
+		{
+			MethodName: "ForceEpoch",
+			Handler:    _KeyTransparencyAdmin_ForceEpoch_Handler,
+		},
+		{
+			MethodName: "SetAppListing",
+			Handler:    _KeyTransparencyAdmin_SetAppListing_Handler,
+		},
+		{
+			MethodName: "SetMutationQuota",
+			Handler:    _KeyTransparencyAdmin_SetMutationQuota_Handler,
+		},
+		{
+			MethodName: "GetDomainStatus",
+			Handler:    _KeyTransparencyAdmin_GetDomainStatus_Handler,
+		},
+		{
+			MethodName: "EvaluateKeyPolicy",
+			Handler:    _KeyTransparencyAdmin_EvaluateKeyPolicy_Handler,
+		},
+		{
+			MethodName: "SetEndorsementPolicy",
+			Handler:    _KeyTransparencyAdmin_SetEndorsementPolicy_Handler,
+		},
+		{
+			MethodName: "SetDomainIntervals",
+			Handler:    _KeyTransparencyAdmin_SetDomainIntervals_Handler,
+		},
+		{
+			MethodName: "AnnounceDomainMigration",
+			Handler:    _KeyTransparencyAdmin_AnnounceDomainMigration_Handler,
+		},
+		{
+			MethodName: "RebuildDomainMap",
+			Handler:    _KeyTransparencyAdmin_RebuildDomainMap_Handler,
+		},
+		{
+			MethodName: "GetReconciliation",
+			Handler:    _KeyTransparencyAdmin_GetReconciliation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDomains",
+			Handler:       _KeyTransparencyAdmin_WatchDomains_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v1/keytransparency_proto/admin.proto",
+}
+
+// Client API for KeyTransparencyConfig service
+
+type KeyTransparencyConfigClient interface {
+	// GetRuntimeConfig returns the runtime configuration of a domain.
+	GetRuntimeConfig(ctx context.Context, in *GetRuntimeConfigRequest, opts ...grpc.CallOption) (*RuntimeConfig, error)
+	// WatchRuntimeConfig streams the runtime configuration of domains each
+	// time it changes.
+	WatchRuntimeConfig(ctx context.Context, in *WatchRuntimeConfigRequest, opts ...grpc.CallOption) (KeyTransparencyConfig_WatchRuntimeConfigClient, error)
+}
+
+type keyTransparencyConfigClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewKeyTransparencyConfigClient(cc *grpc.ClientConn) KeyTransparencyConfigClient {
+	return &keyTransparencyConfigClient{cc}
+}
+
+func (c *keyTransparencyConfigClient) GetRuntimeConfig(ctx context.Context, in *GetRuntimeConfigRequest, opts ...grpc.CallOption) (*RuntimeConfig, error) {
+	out := new(RuntimeConfig)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparencyConfig/GetRuntimeConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyConfigClient) WatchRuntimeConfig(ctx context.Context, in *WatchRuntimeConfigRequest, opts ...grpc.CallOption) (KeyTransparencyConfig_WatchRuntimeConfigClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparencyConfig_serviceDesc.Streams[0], c.cc, "/google.keytransparency.v1.KeyTransparencyConfig/WatchRuntimeConfig", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyConfigWatchRuntimeConfigClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparencyConfig_WatchRuntimeConfigClient interface {
+	Recv() (*RuntimeConfigEvent, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyConfigWatchRuntimeConfigClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyConfigWatchRuntimeConfigClient) Recv() (*RuntimeConfigEvent, error) {
+	m := new(RuntimeConfigEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for KeyTransparencyConfig service
+
+type KeyTransparencyConfigServer interface {
+	// GetRuntimeConfig returns the runtime configuration of a domain.
+	GetRuntimeConfig(context.Context, *GetRuntimeConfigRequest) (*RuntimeConfig, error)
+	// WatchRuntimeConfig streams the runtime configuration of domains each
+	// time it changes.
+	WatchRuntimeConfig(*WatchRuntimeConfigRequest, KeyTransparencyConfig_WatchRuntimeConfigServer) error
+}
+
+func RegisterKeyTransparencyConfigServer(s *grpc.Server, srv KeyTransparencyConfigServer) {
+	s.RegisterService(&_KeyTransparencyConfig_serviceDesc, srv)
+}
+
+func _KeyTransparencyConfig_GetRuntimeConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRuntimeConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyConfigServer).GetRuntimeConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparencyConfig/GetRuntimeConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyConfigServer).GetRuntimeConfig(ctx, req.(*GetRuntimeConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparencyConfig_WatchRuntimeConfig_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRuntimeConfigRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeyTransparencyConfigServer).WatchRuntimeConfig(m, &keyTransparencyConfigWatchRuntimeConfigServer{stream})
+}
+
+type KeyTransparencyConfig_WatchRuntimeConfigServer interface {
+	Send(*RuntimeConfigEvent) error
+	grpc.ServerStream
+}
+
+type keyTransparencyConfigWatchRuntimeConfigServer struct {
+	grpc.ServerStream
+}
+
+func (x *keyTransparencyConfigWatchRuntimeConfigServer) Send(m *RuntimeConfigEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _KeyTransparencyConfig_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "google.keytransparency.v1.KeyTransparencyConfig",
+	HandlerType: (*KeyTransparencyConfigServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRuntimeConfig",
+			Handler:    _KeyTransparencyConfig_GetRuntimeConfig_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRuntimeConfig",
+			Handler:       _KeyTransparencyConfig_WatchRuntimeConfig_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "v1/keytransparency_proto/admin.proto",
 }
 
 func init() { proto.RegisterFile("v1/keytransparency_proto/admin.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 618 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xd1, 0x6e, 0xd3, 0x3c,
-	0x18, 0x55, 0xda, 0x7f, 0xeb, 0xea, 0xf4, 0xaf, 0x86, 0x0b, 0x23, 0xcd, 0x10, 0x64, 0x81, 0x8b,
-	0xaa, 0x88, 0x84, 0x16, 0x24, 0xa4, 0xc1, 0x0d, 0xac, 0x13, 0x9a, 0xc6, 0x05, 0x8a, 0xc6, 0x0d,
-	0x37, 0x95, 0xdb, 0x78, 0x5d, 0x44, 0x12, 0x07, 0xc7, 0xe9, 0x16, 0x01, 0x17, 0x20, 0xde, 0x60,
-	0xaf, 0xc0, 0x35, 0x2f, 0xc3, 0x2b, 0xf0, 0x20, 0x28, 0x8e, 0x53, 0xb2, 0xb4, 0xcd, 0x5a, 0x71,
-	0x95, 0x38, 0xdf, 0x77, 0x7c, 0xce, 0xe7, 0x73, 0x5a, 0x83, 0x07, 0xd3, 0x9e, 0xf9, 0x01, 0xc7,
-	0x8c, 0x22, 0x3f, 0x0c, 0x10, 0xc5, 0xfe, 0x38, 0x1e, 0x06, 0x94, 0x30, 0x62, 0x22, 0xdb, 0x73,
-	0x7c, 0x83, 0xbf, 0xc3, 0xf6, 0x84, 0x90, 0x89, 0x8b, 0x8d, 0x42, 0xa7, 0x31, 0xed, 0xa9, 0x77,
-	0xd2, 0x92, 0x89, 0x02, 0xc7, 0x44, 0xbe, 0x4f, 0x18, 0x62, 0x0e, 0xf1, 0xc3, 0x14, 0xa8, 0xee,
-	0x8a, 0x2a, 0x5f, 0x8d, 0xa2, 0x53, 0x13, 0x7b, 0x01, 0x8b, 0x45, 0xf1, 0x6e, 0xb1, 0x68, 0x47,
-	0x94, 0xa3, 0x45, 0xbd, 0xc9, 0xa8, 0xe3, 0xba, 0x0e, 0xca, 0xd6, 0xea, 0x98, 0xc6, 0x01, 0x23,
-	0x89, 0xde, 0x30, 0x18, 0x89, 0x47, 0x5a, 0xd3, 0x7f, 0x54, 0xc0, 0xe6, 0x80, 0x78, 0xc8, 0xf1,
-	0xe1, 0x2e, 0xa8, 0xdb, 0xfc, 0x6d, 0xe8, 0xd8, 0x8a, 0xa4, 0x49, 0x9d, 0xba, 0xb5, 0x95, 0x7e,
-	0x38, 0xb2, 0xa1, 0x06, 0xaa, 0x2e, 0x99, 0x28, 0x15, 0x4d, 0xea, 0xc8, 0xfd, 0xa6, 0x31, 0x63,
-	0x38, 0xa1, 0x18, 0x5b, 0x49, 0x29, 0xe9, 0xf0, 0x50, 0xa0, 0x54, 0x17, 0x77, 0x78, 0x28, 0x80,
-	0xf7, 0x41, 0x75, 0x4a, 0x4f, 0x95, 0xff, 0x78, 0xc7, 0x0d, 0x43, 0xe8, 0x78, 0x1b, 0x8d, 0x5c,
-	0x67, 0x7c, 0x8c, 0x63, 0x2b, 0xa9, 0xc2, 0x17, 0xa0, 0xe1, 0x25, 0x12, 0x7c, 0x86, 0xe9, 0x14,
-	0xb9, 0xca, 0x06, 0xef, 0x6e, 0x1b, 0xe2, 0x24, 0xb3, 0x99, 0x8d, 0x81, 0x98, 0xd9, 0x92, 0x3d,
-	0xc7, 0x3f, 0x12, 0xdd, 0x1c, 0x8d, 0x2e, 0xfe, 0xa2, 0x37, 0xaf, 0x47, 0xa3, 0x8b, 0x19, 0x5a,
-	0x01, 0x35, 0x1b, 0xbb, 0x98, 0x61, 0x5b, 0xa9, 0x69, 0x52, 0x67, 0xcb, 0xca, 0x96, 0xfa, 0x33,
-	0x00, 0xdf, 0x38, 0x21, 0x4b, 0x4f, 0x2a, 0xb4, 0xf0, 0xc7, 0x08, 0x87, 0x0c, 0xee, 0x81, 0x46,
-	0x78, 0x46, 0xce, 0x87, 0x19, 0x48, 0xe2, 0x20, 0x39, 0xf9, 0x36, 0x10, 0x40, 0x0b, 0xb4, 0xae,
-	0x00, 0xc3, 0x80, 0xf8, 0x21, 0x86, 0xcf, 0x41, 0x2d, 0x3d, 0xda, 0x50, 0x91, 0xb4, 0x6a, 0x47,
-	0xee, 0xef, 0x19, 0x4b, 0xa3, 0x62, 0xa4, 0x60, 0x2b, 0x43, 0xe8, 0x16, 0xd8, 0x7e, 0x8d, 0xc5,
-	0x96, 0x99, 0x94, 0x52, 0xf3, 0x8a, 0x3a, 0x2b, 0xf3, 0x3a, 0x7f, 0x4a, 0xa0, 0x75, 0x40, 0x31,
-	0x62, 0x78, 0x8d, 0x7d, 0x8b, 0x5e, 0x55, 0xfe, 0xc9, 0xab, 0xea, 0x3a, 0x5e, 0xe9, 0x7d, 0xd0,
-	0x4a, 0xb5, 0xaf, 0xae, 0x57, 0x7f, 0x0a, 0x6e, 0xbd, 0xf3, 0xed, 0x35, 0x51, 0xfd, 0xcb, 0x0d,
-	0x70, 0xf3, 0x18, 0xc7, 0x27, 0x39, 0x57, 0x5e, 0x26, 0xbf, 0x71, 0xf8, 0x55, 0x02, 0x72, 0xce,
-	0x5c, 0xf8, 0xa8, 0xc4, 0xc3, 0xf9, 0xf4, 0xa8, 0xc6, 0xaa, 0xed, 0x69, 0x66, 0xf4, 0xd6, 0xb7,
-	0x5f, 0xbf, 0x2f, 0x2b, 0xff, 0x43, 0xd9, 0x9c, 0xf6, 0x4c, 0x91, 0x05, 0xf8, 0x19, 0xd4, 0x67,
-	0x59, 0x80, 0x0f, 0x4b, 0x76, 0x2c, 0x26, 0x46, 0xbd, 0x3e, 0x71, 0xfa, 0x3d, 0xce, 0xd8, 0x86,
-	0xb7, 0x73, 0x8c, 0xe6, 0xa7, 0xd9, 0x49, 0x7d, 0x81, 0x31, 0x68, 0xe4, 0x43, 0x03, 0xcb, 0x46,
-	0x5a, 0x90, 0xae, 0x55, 0x34, 0xec, 0x70, 0x0d, 0xdb, 0x7a, 0x7e, 0xea, 0x7d, 0xa9, 0x0b, 0xcf,
-	0x41, 0x23, 0xef, 0x7f, 0x29, 0xf5, 0x82, 0xa0, 0xa8, 0x3b, 0x73, 0x39, 0x3b, 0x4c, 0xfe, 0x62,
-	0xb3, 0x99, 0xbb, 0x4b, 0x67, 0xfe, 0x2e, 0x81, 0xe6, 0xd5, 0x14, 0xc1, 0xc7, 0x25, 0xdc, 0x0b,
-	0x03, 0xb7, 0x94, 0xbd, 0xc3, 0xd9, 0xf5, 0xae, 0xb6, 0x84, 0x7d, 0x3f, 0x12, 0xdb, 0xbd, 0x3a,
-	0x7c, 0x7f, 0x30, 0x71, 0xd8, 0x59, 0x34, 0x32, 0xc6, 0xc4, 0x33, 0xc5, 0x8d, 0x50, 0xe0, 0x37,
-	0xc7, 0x84, 0xa6, 0x37, 0xcc, 0xb2, 0xdb, 0x6a, 0xb4, 0xc9, 0x1f, 0x4f, 0xfe, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0x12, 0x5a, 0xb9, 0x4f, 0xd0, 0x06, 0x00, 0x00,
+	// 3977 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x5b, 0xdd, 0x73, 0xdb, 0x56,
+	0x76, 0x5f, 0x90, 0xfa, 0x20, 0x0f, 0x49, 0x89, 0xba, 0xd4, 0x07, 0x44, 0x27, 0xb1, 0x02, 0xaf,
+	0x63, 0xc7, 0x49, 0x48, 0x5b, 0xb6, 0x33, 0x6b, 0xbb, 0x93, 0x44, 0xb1, 0xe8, 0xc4, 0xb5, 0x95,
+	0x68, 0x21, 0x3b, 0xdb, 0xa6, 0x9d, 0x72, 0x20, 0xe2, 0x8a, 0xc2, 0x0a, 0x04, 0x10, 0x00, 0x94,
+	0x45, 0xa7, 0x99, 0xee, 0xf6, 0x63, 0x3b, 0xd3, 0x8f, 0xe9, 0xb4, 0xdb, 0x6d, 0x67, 0xa7, 0x7d,
+	0xd9, 0xce, 0x74, 0xfa, 0xb0, 0x33, 0x7d, 0x69, 0x3b, 0xfd, 0x0b, 0xfa, 0xda, 0x97, 0xfe, 0x0b,
+	0x7d, 0x6d, 0x5f, 0xfa, 0xd6, 0xa7, 0xce, 0xb9, 0xf7, 0x02, 0x04, 0x41, 0x10, 0x04, 0x2d, 0x6d,
+	0xdb, 0x17, 0x9b, 0xf7, 0xe3, 0x9c, 0x7b, 0xce, 0xbd, 0xe7, 0x9c, 0xfb, 0xbb, 0xe7, 0x40, 0xf0,
+	0xed, 0xd3, 0x5b, 0xcd, 0x13, 0x3a, 0xf0, 0x5d, 0xcd, 0xf2, 0x1c, 0xcd, 0xa5, 0x56, 0x67, 0xd0,
+	0x76, 0x5c, 0xdb, 0xb7, 0x9b, 0x9a, 0xde, 0x33, 0xac, 0x06, 0xfb, 0x4d, 0x36, 0xbb, 0xb6, 0xdd,
+	0x35, 0x69, 0x23, 0x36, 0xb3, 0x71, 0x7a, 0xab, 0xfe, 0x1a, 0x1f, 0x6a, 0x6a, 0x8e, 0xd1, 0xd4,
+	0x2c, 0xcb, 0xf6, 0x35, 0xdf, 0xb0, 0x2d, 0x8f, 0x13, 0xd6, 0x05, 0x61, 0x93, 0xb5, 0x0e, 0xfb,
+	0x47, 0x4d, 0xcd, 0x1a, 0x88, 0xa1, 0x4b, 0xf1, 0x21, 0xda, 0x73, 0xfc, 0x60, 0xf0, 0x8d, 0xf8,
+	0xa0, 0xde, 0x77, 0x19, 0x63, 0x31, 0xbe, 0x15, 0x1f, 0x3f, 0x32, 0xa8, 0xa9, 0xb7, 0x7b, 0x9a,
+	0x77, 0x22, 0x66, 0x5c, 0x8e, 0xcf, 0xf0, 0x8d, 0x1e, 0xf5, 0x7c, 0xad, 0xe7, 0x88, 0x09, 0x4b,
+	0xbe, 0x6b, 0x98, 0xa6, 0xa1, 0x05, 0x2c, 0xeb, 0x1d, 0x77, 0xe0, 0xf8, 0x36, 0xee, 0x86, 0xe7,
+	0x1c, 0x8a, 0xff, 0xc4, 0x98, 0x2c, 0xc6, 0x3c, 0xa3, 0xeb, 0x1c, 0xf2, 0x7f, 0xf9, 0x88, 0xf2,
+	0xa3, 0x25, 0x58, 0xd8, 0xb5, 0x7b, 0x9a, 0x61, 0x91, 0x4b, 0x50, 0xd4, 0xd9, 0xaf, 0xb6, 0xa1,
+	0xcb, 0xd2, 0x96, 0x74, 0xbd, 0xa8, 0x16, 0x78, 0xc7, 0x63, 0x9d, 0x6c, 0x41, 0xde, 0xb4, 0xbb,
+	0x72, 0x6e, 0x4b, 0xba, 0x5e, 0xda, 0x5e, 0x6a, 0x84, 0x6b, 0x3f, 0x73, 0x29, 0x55, 0x71, 0x08,
+	0x67, 0xf4, 0x34, 0x47, 0xce, 0x27, 0xcf, 0xe8, 0x69, 0x0e, 0xb9, 0x02, 0xf9, 0x53, 0xf7, 0x48,
+	0x9e, 0x63, 0x33, 0x56, 0x1a, 0x42, 0xc2, 0xfd, 0xfe, 0xa1, 0x69, 0x74, 0x9e, 0xd0, 0x81, 0x8a,
+	0xa3, 0xe4, 0x97, 0xa0, 0xdc, 0x43, 0x11, 0x2c, 0x9f, 0xba, 0xa7, 0x9a, 0x29, 0xcf, 0xb3, 0xd9,
+	0x9b, 0x0d, 0x71, 0x82, 0xc1, 0x76, 0x34, 0x76, 0xc5, 0x86, 0xaa, 0xa5, 0x9e, 0x61, 0x3d, 0x16,
+	0xb3, 0x19, 0xb5, 0x76, 0x36, 0xa4, 0x5e, 0x98, 0x4e, 0xad, 0x9d, 0x85, 0xd4, 0x32, 0x2c, 0xea,
+	0xd4, 0xa4, 0x3e, 0xd5, 0xe5, 0xc5, 0x2d, 0xe9, 0x7a, 0x41, 0x0d, 0x9a, 0xe4, 0x31, 0x14, 0x34,
+	0xc7, 0x69, 0x9f, 0xba, 0x47, 0x9e, 0x5c, 0xd8, 0xca, 0x5f, 0x2f, 0x6d, 0x37, 0x1a, 0x13, 0x6d,
+	0xaa, 0xc1, 0x37, 0xb4, 0xb1, 0xe3, 0x38, 0x5f, 0xb8, 0x47, 0x5e, 0xcb, 0xf2, 0xdd, 0x81, 0xba,
+	0xa8, 0xf1, 0x16, 0xb9, 0x03, 0x65, 0xc7, 0xa5, 0xa7, 0x86, 0xdd, 0xf7, 0x90, 0x9f, 0x5c, 0x9c,
+	0xb4, 0x1d, 0xa5, 0x60, 0xda, 0x17, 0xee, 0x11, 0xf9, 0x65, 0xa8, 0x45, 0xa9, 0xda, 0xf4, 0xcc,
+	0x31, 0xdc, 0x81, 0x0c, 0x8c, 0xb8, 0x3e, 0xa6, 0xdf, 0xb3, 0xc0, 0x58, 0xd4, 0x95, 0x08, 0x97,
+	0x16, 0x23, 0x22, 0x0f, 0x01, 0x4e, 0xe8, 0xa0, 0xed, 0xd8, 0xa6, 0xd1, 0x19, 0xc8, 0x25, 0xc6,
+	0xe2, 0xdb, 0x29, 0xea, 0x3c, 0xa1, 0x83, 0x7d, 0x36, 0x57, 0x2d, 0x9e, 0x04, 0x3f, 0xc9, 0x3a,
+	0x2c, 0x1c, 0xb9, 0xf6, 0x4b, 0x6a, 0xc9, 0x65, 0xb6, 0x55, 0xa2, 0x45, 0x2e, 0x43, 0x09, 0x77,
+	0xca, 0x34, 0x3c, 0xdf, 0xb0, 0xba, 0x72, 0x85, 0x0d, 0x82, 0xe6, 0x38, 0x4f, 0x79, 0x0f, 0xf9,
+	0x1c, 0x96, 0x7a, 0x7d, 0xee, 0x65, 0xed, 0xaf, 0xfa, 0xb6, 0xaf, 0xc9, 0x4b, 0x4c, 0x82, 0xeb,
+	0x29, 0x12, 0xec, 0x09, 0x82, 0xef, 0xe2, 0x7c, 0xb5, 0xd2, 0x8b, 0x36, 0xc9, 0x53, 0xa8, 0xe0,
+	0x8e, 0x68, 0x66, 0xd7, 0x76, 0x0d, 0xff, 0xb8, 0x27, 0x2f, 0x6f, 0x49, 0xd7, 0x97, 0xb6, 0xaf,
+	0xa5, 0xf0, 0xfb, 0xc2, 0x3d, 0xda, 0x09, 0xa6, 0xab, 0xe5, 0xd3, 0x48, 0x8b, 0x3c, 0x80, 0x12,
+	0x3f, 0xf4, 0x36, 0x3a, 0x9c, 0x5c, 0x9d, 0xba, 0xc1, 0xc0, 0xa7, 0x63, 0x07, 0xd9, 0x85, 0xea,
+	0xb1, 0xe6, 0xea, 0xed, 0x28, 0x87, 0x95, 0xa9, 0x1c, 0x96, 0x90, 0x66, 0x77, 0xc8, 0x45, 0x85,
+	0x8a, 0x67, 0x74, 0x2d, 0xaa, 0xb7, 0x3b, 0xb6, 0x75, 0x64, 0x74, 0x65, 0xc2, 0x58, 0xbc, 0x97,
+	0xa2, 0xd0, 0x01, 0x9b, 0xcf, 0xed, 0xee, 0x21, 0x23, 0x52, 0xcb, 0x9c, 0x07, 0x6f, 0x91, 0x2b,
+	0x50, 0xf1, 0x5d, 0x4a, 0xbd, 0x76, 0x60, 0xe0, 0x35, 0x76, 0x30, 0x65, 0xd6, 0xb9, 0x2b, 0xac,
+	0xfc, 0xd7, 0x80, 0x50, 0x4b, 0xb7, 0x5d, 0x8f, 0xf6, 0xa8, 0xe5, 0x07, 0x06, 0xb2, 0xca, 0x56,
+	0x7f, 0x37, 0x65, 0xf5, 0xd6, 0x90, 0x48, 0x18, 0xca, 0x0a, 0x8d, 0x77, 0x91, 0x16, 0x2c, 0x98,
+	0xda, 0x21, 0x35, 0x3d, 0x79, 0x6d, 0x2b, 0x3f, 0x45, 0x1d, 0xe1, 0x40, 0x4f, 0xd9, 0x7c, 0xee,
+	0x3f, 0x82, 0x18, 0xed, 0xcb, 0xb4, 0xbb, 0xed, 0x43, 0xad, 0x73, 0x42, 0x2d, 0x5d, 0x5e, 0x67,
+	0x71, 0x0a, 0x4c, 0xbb, 0xfb, 0x31, 0xef, 0x21, 0x9f, 0x41, 0xb1, 0x67, 0x74, 0xb9, 0x7b, 0xcb,
+	0x1b, 0x4c, 0xf6, 0x9b, 0x19, 0x77, 0x6e, 0x2f, 0xa0, 0x53, 0x87, 0x2c, 0xc8, 0x33, 0xa8, 0xe2,
+	0x82, 0xe8, 0x31, 0xae, 0xb8, 0x1d, 0x64, 0x99, 0xb1, 0xbd, 0x91, 0xc2, 0x16, 0xa3, 0x1e, 0x3a,
+	0xb1, 0xa0, 0x50, 0x97, 0x4c, 0xbb, 0x1b, 0x69, 0x23, 0xd7, 0x9e, 0xe6, 0x8c, 0x72, 0xdd, 0x9c,
+	0x9d, 0x6b, 0x4f, 0x73, 0xa2, 0x5c, 0x9f, 0x43, 0x35, 0x88, 0xbb, 0xe1, 0x0e, 0xd5, 0x33, 0x70,
+	0xe5, 0x24, 0x62, 0x07, 0xd5, 0x65, 0x7f, 0xb4, 0x83, 0xfc, 0x0a, 0x90, 0x61, 0xc0, 0x68, 0x1f,
+	0x1b, 0x9e, 0x6f, 0xbb, 0x03, 0xf9, 0xd2, 0x56, 0x7e, 0x0a, 0xe3, 0x30, 0x70, 0x3c, 0x3c, 0xd6,
+	0xac, 0x2e, 0x55, 0xab, 0x61, 0xf8, 0xf8, 0x94, 0xf3, 0xa8, 0xef, 0x41, 0x39, 0x1a, 0x25, 0x49,
+	0x15, 0xf2, 0x27, 0x74, 0x20, 0x6e, 0x1f, 0xfc, 0x49, 0xae, 0xc1, 0xfc, 0xa9, 0x66, 0xf6, 0xa9,
+	0x9c, 0x9b, 0x14, 0x27, 0xf9, 0xf8, 0xfd, 0xdc, 0x77, 0xa4, 0xfa, 0x3d, 0x28, 0x45, 0x6c, 0x26,
+	0x81, 0xdb, 0x6a, 0x94, 0x5b, 0x31, 0x42, 0xaa, 0x1c, 0xc0, 0x72, 0x6c, 0x1f, 0x02, 0x53, 0xd3,
+	0x74, 0xdd, 0xa5, 0x9e, 0x27, 0x4b, 0xa1, 0xa9, 0xed, 0xf0, 0x1e, 0x9c, 0x80, 0x87, 0x18, 0x4c,
+	0xe0, 0x3c, 0xa1, 0xa7, 0x39, 0x62, 0x82, 0xf2, 0x5f, 0x12, 0x2c, 0xc7, 0xce, 0x6c, 0x24, 0xfe,
+	0x07, 0xd2, 0xa5, 0xc7, 0xff, 0x27, 0x74, 0x40, 0x6e, 0x02, 0x38, 0x6c, 0x84, 0xd1, 0x4c, 0xdc,
+	0x8b, 0xa2, 0x13, 0xfc, 0xc4, 0x40, 0xc6, 0x2c, 0x4b, 0x84, 0xa1, 0xfc, 0xf4, 0x40, 0xc6, 0xa7,
+	0x63, 0x07, 0x12, 0xdb, 0xa7, 0xd4, 0x35, 0x35, 0xa7, 0x8d, 0x36, 0x34, 0x37, 0x9d, 0x58, 0x4c,
+	0x6f, 0x59, 0xba, 0xf2, 0x9f, 0x12, 0x2c, 0xc7, 0x1c, 0x2a, 0x1d, 0x5c, 0xd4, 0xa1, 0x80, 0xaa,
+	0x7a, 0xe8, 0x04, 0xa8, 0x5a, 0x5e, 0x0d, 0xdb, 0xb8, 0xc7, 0x16, 0x7d, 0x11, 0xee, 0x71, 0x9e,
+	0xef, 0xb1, 0x45, 0x5f, 0x04, 0x87, 0xf0, 0x11, 0x60, 0xab, 0xcd, 0x99, 0x09, 0x49, 0xdf, 0x9c,
+	0x1a, 0x5b, 0xd4, 0xa2, 0x45, 0x5f, 0xf0, 0x9f, 0xe4, 0x43, 0xa8, 0x68, 0x96, 0x65, 0xf7, 0xad,
+	0x8e, 0xd8, 0xab, 0xf9, 0xa9, 0xea, 0x96, 0x03, 0x02, 0xec, 0x52, 0x4e, 0x60, 0x2d, 0x31, 0x8c,
+	0x90, 0xd7, 0xa2, 0xb1, 0x08, 0xb5, 0x2e, 0x47, 0x23, 0xcb, 0x1d, 0x28, 0x62, 0x8c, 0xd6, 0xfc,
+	0xbe, 0x1b, 0x98, 0xf7, 0x7a, 0x83, 0x83, 0xb3, 0x5d, 0xa3, 0x6b, 0xf8, 0x9a, 0x69, 0x0e, 0x38,
+	0x5f, 0x75, 0x38, 0x51, 0xf9, 0x1d, 0x09, 0xca, 0xd1, 0x40, 0x4f, 0xee, 0xc1, 0x82, 0x50, 0x5e,
+	0xca, 0xaa, 0xbc, 0x20, 0x20, 0xdf, 0x81, 0x62, 0x08, 0x2b, 0xe5, 0xdc, 0x54, 0xad, 0x87, 0x93,
+	0x95, 0x43, 0x20, 0xe3, 0x77, 0x0e, 0x82, 0x02, 0x71, 0x65, 0x71, 0x65, 0x45, 0xeb, 0x15, 0x35,
+	0xfd, 0x0d, 0x20, 0x08, 0x1a, 0xf8, 0x0a, 0x9e, 0x4a, 0xbf, 0xea, 0x53, 0xcf, 0x27, 0x6f, 0x42,
+	0xd9, 0x3b, 0xb6, 0x5f, 0x84, 0x17, 0x99, 0xc4, 0x2e, 0xb2, 0x12, 0xf6, 0x05, 0xf7, 0xd8, 0x55,
+	0x58, 0x62, 0xb7, 0x45, 0xdb, 0xa3, 0x26, 0xed, 0xf8, 0xb6, 0x2b, 0x5c, 0xb3, 0xc2, 0x7a, 0x0f,
+	0x44, 0xa7, 0xa2, 0x42, 0x6d, 0x84, 0xbf, 0xe7, 0xd8, 0x96, 0x87, 0xb6, 0xbf, 0xc8, 0xb7, 0x07,
+	0x5d, 0x3e, 0x9f, 0x6d, 0x43, 0x03, 0x0a, 0x45, 0x85, 0xea, 0x27, 0x54, 0xb0, 0x0c, 0x24, 0x4e,
+	0xb5, 0xfd, 0xb8, 0x3a, 0xb9, 0x31, 0x75, 0x94, 0x3f, 0x5f, 0x80, 0xda, 0x43, 0x97, 0x6a, 0x3e,
+	0x9d, 0x81, 0x6f, 0x1c, 0x47, 0xe7, 0xce, 0x85, 0xa3, 0xf3, 0x33, 0xe1, 0xe8, 0x0f, 0xa0, 0x80,
+	0x81, 0xd3, 0x73, 0x68, 0x47, 0x38, 0xe4, 0x95, 0x29, 0x97, 0xda, 0x81, 0x43, 0x3b, 0xea, 0xa2,
+	0x69, 0x77, 0xf1, 0x07, 0xd2, 0x63, 0x5c, 0x65, 0xf4, 0xf3, 0x33, 0xd0, 0xf7, 0x34, 0x87, 0xd1,
+	0xaf, 0xc1, 0x02, 0xae, 0x6f, 0xe8, 0x0c, 0xff, 0xe7, 0xd5, 0x79, 0xd3, 0xee, 0x3e, 0xd6, 0xb1,
+	0x1b, 0xd9, 0x1a, 0x1c, 0xdd, 0xe7, 0xd5, 0xf9, 0x9e, 0xe6, 0xb0, 0x9d, 0x5a, 0x46, 0xfc, 0xe8,
+	0xb8, 0xc6, 0x29, 0x46, 0x4b, 0x8c, 0xaf, 0x05, 0xb6, 0xe8, 0xea, 0x98, 0xba, 0x3b, 0xd6, 0x40,
+	0x45, 0xb0, 0xb9, 0xcf, 0xe7, 0x62, 0x98, 0x1d, 0x43, 0x9f, 0xc5, 0xf3, 0xa0, 0xcf, 0x2b, 0x50,
+	0x39, 0xd5, 0x4c, 0x43, 0x47, 0x41, 0x6c, 0xcb, 0xe4, 0x00, 0xbf, 0xa0, 0x96, 0x83, 0xce, 0xcf,
+	0x2d, 0x73, 0x40, 0xd4, 0x10, 0x49, 0x95, 0x98, 0x7d, 0xde, 0x4f, 0x59, 0x2b, 0xc1, 0x6e, 0xb2,
+	0xc0, 0xaa, 0xf2, 0x18, 0xac, 0x4a, 0x82, 0x16, 0x95, 0x73, 0x43, 0x8b, 0xf3, 0xdc, 0xd8, 0xdb,
+	0x50, 0xe3, 0x1e, 0x92, 0xdd, 0x2b, 0x94, 0x3b, 0xb0, 0xf6, 0xdc, 0xd2, 0x67, 0xa5, 0x7a, 0x04,
+	0xd5, 0x1d, 0x5d, 0x47, 0xa0, 0xa2, 0x3e, 0xca, 0xe4, 0x7c, 0x6b, 0xb0, 0x80, 0x8f, 0x20, 0x43,
+	0x0f, 0xa4, 0xd6, 0x1c, 0xe7, 0xb1, 0xae, 0xfc, 0x75, 0x0e, 0x0a, 0x81, 0xb5, 0x92, 0x07, 0x50,
+	0x39, 0xd6, 0xbc, 0xe3, 0xb6, 0xe7, 0xbb, 0x9a, 0x4f, 0xbb, 0x5c, 0xe9, 0xa5, 0xed, 0xf5, 0xe1,
+	0xcb, 0xf9, 0x53, 0xcd, 0x3b, 0x3e, 0x10, 0xa3, 0x6a, 0xf9, 0x38, 0xd2, 0x22, 0x5f, 0x42, 0x2d,
+	0x8c, 0x93, 0x11, 0xdb, 0xcb, 0x31, 0x16, 0x6f, 0x27, 0x87, 0xd6, 0xc6, 0x41, 0x40, 0x31, 0xb4,
+	0x3e, 0xe2, 0x8d, 0xf5, 0x91, 0x9b, 0x50, 0x40, 0xb4, 0xc7, 0xbc, 0x8f, 0xfb, 0xfd, 0x5a, 0x00,
+	0x34, 0x50, 0x70, 0xe3, 0xc8, 0xe8, 0x70, 0x9f, 0x5f, 0x3c, 0xa1, 0x03, 0xa6, 0x4a, 0x0b, 0x56,
+	0x30, 0x5a, 0xb8, 0xb6, 0xed, 0xb7, 0x83, 0x44, 0x87, 0x3c, 0x37, 0x2d, 0x64, 0x2c, 0xf7, 0xb4,
+	0x33, 0xd5, 0xb6, 0xfd, 0xa0, 0x43, 0xf9, 0x97, 0x1c, 0xd4, 0x9e, 0x3b, 0xfa, 0x6c, 0x71, 0x2e,
+	0x71, 0xed, 0xdc, 0xac, 0x6b, 0x47, 0x7c, 0x2a, 0x3f, 0xd5, 0xa7, 0x12, 0x64, 0x4c, 0xf4, 0xa9,
+	0x07, 0x50, 0xea, 0xb3, 0xa9, 0x2c, 0xaf, 0x33, 0x11, 0x44, 0x3d, 0x32, 0xa8, 0xa9, 0xef, 0x69,
+	0xde, 0x89, 0x0a, 0x7c, 0x3a, 0xfe, 0x3e, 0x8f, 0x63, 0x7c, 0x1f, 0xd6, 0x19, 0xda, 0x14, 0x22,
+	0x66, 0x35, 0xda, 0xdb, 0xb0, 0x28, 0x40, 0xdc, 0xf4, 0xfd, 0x0b, 0x66, 0x2a, 0x7f, 0x27, 0xc1,
+	0x1a, 0x5f, 0x4c, 0xe0, 0x5c, 0x2f, 0xd3, 0x5a, 0xaf, 0x83, 0x40, 0x9b, 0xed, 0x20, 0xab, 0x54,
+	0x50, 0x8b, 0xbc, 0xe7, 0xa9, 0xdd, 0x8d, 0x0c, 0x07, 0x29, 0xa5, 0x70, 0x78, 0x4f, 0x73, 0xa2,
+	0x92, 0xce, 0x65, 0x96, 0xf4, 0x07, 0x12, 0xac, 0xed, 0xf7, 0xdd, 0x2e, 0x65, 0x1b, 0xba, 0xab,
+	0xf9, 0xda, 0x39, 0x5c, 0x99, 0x6c, 0xc0, 0x62, 0xdf, 0xa3, 0x2e, 0xf6, 0x73, 0x48, 0xba, 0x80,
+	0xcd, 0x18, 0x96, 0x9d, 0x1b, 0xc5, 0xb2, 0x8a, 0x01, 0x1b, 0x08, 0x38, 0x76, 0xfa, 0xba, 0xe1,
+	0xa3, 0x14, 0x06, 0xcd, 0xb6, 0x5b, 0xab, 0x30, 0xef, 0xf9, 0x9a, 0xeb, 0x0b, 0x70, 0xcc, 0x1b,
+	0x48, 0xe2, 0x68, 0x5d, 0xda, 0xf6, 0x8c, 0x97, 0x1c, 0xde, 0xcf, 0xab, 0x05, 0xec, 0x38, 0x30,
+	0x5e, 0x52, 0xe5, 0xe7, 0x12, 0x40, 0xb8, 0xce, 0x00, 0xa5, 0xf2, 0x70, 0x25, 0xab, 0x43, 0x19,
+	0xf7, 0xbc, 0x1a, 0xb6, 0x11, 0xb4, 0xf5, 0xa8, 0x7f, 0x6c, 0x07, 0x1a, 0x8a, 0x16, 0xf6, 0x77,
+	0x34, 0xd3, 0xa4, 0x6e, 0xa0, 0x21, 0x6f, 0x91, 0x06, 0xcc, 0x31, 0x94, 0x3c, 0xfd, 0x51, 0xc0,
+	0xe6, 0x21, 0xc2, 0x71, 0xb9, 0x96, 0x6d, 0x8c, 0x61, 0xec, 0x46, 0x2f, 0xab, 0x25, 0xd1, 0x87,
+	0x41, 0x4e, 0x79, 0x09, 0xf2, 0xf8, 0xc6, 0x08, 0x38, 0xf6, 0x21, 0x2c, 0x52, 0xde, 0x25, 0xe0,
+	0xd8, 0xd5, 0x14, 0xd7, 0x1c, 0xaa, 0xac, 0x06, 0x54, 0x68, 0x4c, 0x16, 0x3d, 0xf3, 0xdb, 0xd1,
+	0x2d, 0x2c, 0x62, 0xcf, 0x01, 0x76, 0x28, 0xff, 0x51, 0x08, 0xf0, 0xf4, 0xc7, 0x7d, 0x4b, 0x37,
+	0xe9, 0x2f, 0x3e, 0x0f, 0x9a, 0x00, 0x38, 0xe6, 0xb2, 0x03, 0x8e, 0xff, 0xcb, 0x04, 0xa9, 0x0d,
+	0xab, 0x22, 0x0d, 0x1a, 0x95, 0xde, 0x93, 0x17, 0xd9, 0xc1, 0x7c, 0x30, 0x15, 0x27, 0xf3, 0x1d,
+	0x16, 0x89, 0xd1, 0xa1, 0x4e, 0x22, 0x6e, 0xae, 0x68, 0xf1, 0x7e, 0xb2, 0x07, 0xf2, 0x48, 0xda,
+	0x33, 0x2b, 0x48, 0x5b, 0x8b, 0x64, 0x3d, 0x23, 0x7b, 0x37, 0x21, 0x8b, 0x5a, 0x7c, 0x95, 0x2c,
+	0xea, 0x03, 0x28, 0xd1, 0x33, 0xc7, 0x76, 0x7d, 0xfe, 0x66, 0x9c, 0x9e, 0x89, 0x05, 0x3e, 0x1d,
+	0x3b, 0x2e, 0x26, 0x05, 0xfb, 0x24, 0xbc, 0xb3, 0xca, 0x6c, 0xff, 0x6f, 0x67, 0xdd, 0xff, 0x0c,
+	0x00, 0xb0, 0x92, 0x09, 0x00, 0x2e, 0xfd, 0xa2, 0x72, 0x4b, 0xcb, 0x17, 0x90, 0x5b, 0xfa, 0x12,
+	0xd6, 0x93, 0x0d, 0x2d, 0xe1, 0x32, 0xbd, 0x31, 0x9a, 0x65, 0x4a, 0x36, 0xaa, 0x8b, 0x49, 0x34,
+	0xc5, 0x5e, 0xce, 0x22, 0xe8, 0xac, 0xc3, 0xc2, 0x21, 0xfb, 0x15, 0xbc, 0x9c, 0x79, 0xeb, 0x15,
+	0x5f, 0xce, 0xdb, 0x50, 0x6b, 0x31, 0x63, 0x9b, 0x01, 0xe4, 0xfe, 0x16, 0xd4, 0x1e, 0xf7, 0xc6,
+	0x69, 0x5a, 0x23, 0x82, 0x65, 0xcf, 0x42, 0x73, 0xbd, 0x42, 0x3d, 0xde, 0x84, 0x72, 0x87, 0x3d,
+	0x45, 0xda, 0x2c, 0xe3, 0x1c, 0x3c, 0x73, 0x79, 0x1f, 0x86, 0x46, 0x0f, 0x2f, 0xe8, 0x62, 0x78,
+	0xaa, 0x68, 0x17, 0x9a, 0x69, 0xda, 0x2f, 0xa8, 0x3e, 0xc4, 0xb7, 0xfc, 0x06, 0x98, 0x09, 0xe0,
+	0xae, 0x08, 0x26, 0x61, 0x8f, 0x47, 0x36, 0xf1, 0x75, 0x79, 0xc6, 0x03, 0x57, 0x8e, 0x5d, 0x9b,
+	0x8b, 0x3d, 0xed, 0x0c, 0xad, 0x43, 0x71, 0x61, 0x39, 0x66, 0x57, 0x23, 0xf7, 0xb9, 0x14, 0xcb,
+	0x4d, 0x8d, 0x7a, 0x71, 0xee, 0x95, 0xbc, 0x58, 0x79, 0x01, 0xb5, 0x03, 0xea, 0x0f, 0x87, 0xb2,
+	0x00, 0x82, 0x0b, 0x59, 0x78, 0x1b, 0x6a, 0x8f, 0x5c, 0x4a, 0x5f, 0xce, 0xfc, 0x7e, 0x3a, 0x9a,
+	0x95, 0xea, 0x26, 0xac, 0x3c, 0xb2, 0xdd, 0x0e, 0x6d, 0x39, 0x76, 0xe7, 0x38, 0x13, 0xc5, 0x1e,
+	0xac, 0x1e, 0x50, 0x7f, 0x27, 0xac, 0x1a, 0x65, 0xda, 0x15, 0x19, 0x91, 0x82, 0x76, 0x68, 0x86,
+	0x59, 0x94, 0xa0, 0xa9, 0x7c, 0x0d, 0x95, 0x91, 0x12, 0x12, 0x69, 0x40, 0x2d, 0x28, 0x22, 0x79,
+	0x6d, 0x87, 0xba, 0x6d, 0x8a, 0xa2, 0x31, 0x8e, 0xf3, 0xea, 0x4a, 0x38, 0xb4, 0x4f, 0x5d, 0x26,
+	0x33, 0xb9, 0x0f, 0xf5, 0xd1, 0xf9, 0x0c, 0xfc, 0xe1, 0x0f, 0x5d, 0x1b, 0x08, 0x2b, 0x5a, 0x8f,
+	0x92, 0x3d, 0xf7, 0xa8, 0xbb, 0x4f, 0xdd, 0x5d, 0x6d, 0xa0, 0xfc, 0xbe, 0x04, 0x1b, 0x07, 0xd4,
+	0x1f, 0xad, 0x61, 0x65, 0xd1, 0x67, 0xbc, 0x52, 0x96, 0x3b, 0x57, 0xa5, 0x4c, 0x51, 0x61, 0x65,
+	0xac, 0x54, 0x43, 0xae, 0xc2, 0x1c, 0x73, 0x05, 0x0e, 0xae, 0x12, 0x72, 0xca, 0x6c, 0x18, 0x03,
+	0xd4, 0x57, 0x7d, 0xdb, 0xed, 0xf7, 0x84, 0xb6, 0xa2, 0xa5, 0xfc, 0xa5, 0x04, 0x97, 0x0e, 0xa8,
+	0x3f, 0xc6, 0x37, 0x93, 0x86, 0xc9, 0x05, 0xa7, 0xdc, 0x85, 0x14, 0x9c, 0x94, 0x7f, 0x94, 0x60,
+	0xf3, 0x20, 0xc8, 0xc5, 0x05, 0x10, 0xc6, 0xfb, 0xff, 0x9e, 0x3c, 0x53, 0xfe, 0x58, 0x82, 0x37,
+	0x76, 0x44, 0x76, 0x39, 0x5e, 0x96, 0xca, 0x22, 0xfb, 0x48, 0xfd, 0x2b, 0x77, 0xee, 0xfa, 0x97,
+	0x72, 0x17, 0xd6, 0xc3, 0x8c, 0xe6, 0x81, 0xaf, 0xf9, 0xfd, 0x4c, 0x5b, 0xa8, 0xfc, 0xc3, 0x1c,
+	0x94, 0xa3, 0x44, 0xe9, 0x42, 0xbf, 0x03, 0x2b, 0x0e, 0xb5, 0x74, 0xc3, 0xea, 0xb6, 0x43, 0x2f,
+	0x12, 0x50, 0xbd, 0x2a, 0x06, 0x02, 0xcb, 0xf6, 0x88, 0x0a, 0x1b, 0xb6, 0xa9, 0xe3, 0x7b, 0x22,
+	0x4e, 0x93, 0xa1, 0xca, 0xb1, 0xc6, 0x49, 0xf7, 0x47, 0x99, 0xe2, 0xfd, 0x84, 0xb9, 0xc1, 0xd8,
+	0xd3, 0x0d, 0xcb, 0x3b, 0xaa, 0xe8, 0x22, 0x1f, 0x40, 0x85, 0x4d, 0xb1, 0x6d, 0x01, 0xf9, 0xa6,
+	0x97, 0x09, 0x18, 0xbd, 0x6d, 0x73, 0xcc, 0xf7, 0x29, 0xd4, 0x90, 0xac, 0xed, 0x19, 0x58, 0x68,
+	0x08, 0x58, 0x4d, 0x47, 0xe0, 0x55, 0xa4, 0x3a, 0x40, 0xa2, 0x3d, 0xce, 0x8d, 0x28, 0x50, 0x41,
+	0xac, 0x86, 0x37, 0x29, 0x7f, 0xfd, 0xf1, 0x7c, 0x26, 0x02, 0x38, 0x96, 0x5e, 0x32, 0x5e, 0x52,
+	0xf2, 0x01, 0x9f, 0x33, 0x94, 0xb6, 0x30, 0x5d, 0x5a, 0xd3, 0xee, 0x86, 0xd2, 0xde, 0x83, 0xa5,
+	0xe1, 0x1a, 0xf8, 0xf0, 0x16, 0x89, 0xcd, 0xda, 0xe8, 0x8b, 0x06, 0x0f, 0x97, 0xaa, 0xe5, 0x60,
+	0x65, 0x6c, 0x21, 0x29, 0x6a, 0x17, 0x21, 0x85, 0x14, 0xd2, 0x9e, 0xe6, 0x84, 0x2d, 0xa5, 0x09,
+	0xb5, 0xef, 0x69, 0x7e, 0xe7, 0x38, 0x96, 0xf3, 0x97, 0x61, 0xd1, 0xb0, 0x0c, 0xdf, 0xd0, 0x4c,
+	0x91, 0xee, 0x0f, 0x9a, 0xca, 0x7f, 0x4b, 0x50, 0xe2, 0x93, 0x5b, 0xa7, 0xd4, 0xf2, 0xc9, 0x87,
+	0x30, 0xe7, 0x0f, 0x1c, 0x2a, 0x92, 0x69, 0xef, 0x4c, 0x45, 0xc4, 0x8c, 0xaa, 0xf1, 0x6c, 0xe0,
+	0x50, 0x95, 0x11, 0x46, 0xaa, 0x29, 0xb9, 0x59, 0xab, 0x29, 0xc1, 0xc3, 0x38, 0x9f, 0xed, 0x61,
+	0xac, 0x7c, 0x08, 0x73, 0xb8, 0x30, 0xa9, 0x42, 0xf9, 0xf9, 0x67, 0x4f, 0x3e, 0xfb, 0xfc, 0x7b,
+	0x9f, 0xb5, 0x9f, 0xfd, 0xea, 0x7e, 0xab, 0xfa, 0x2d, 0x52, 0x82, 0xc5, 0x87, 0x6a, 0x6b, 0xe7,
+	0x59, 0x6b, 0xb7, 0x2a, 0x61, 0xe3, 0xf9, 0xfe, 0x2e, 0x6b, 0xe4, 0xb0, 0xb1, 0xdb, 0x7a, 0xda,
+	0xc2, 0x46, 0x5e, 0xf9, 0x99, 0x04, 0x72, 0x0b, 0x91, 0x25, 0x47, 0xb7, 0xff, 0xcb, 0x00, 0x02,
+	0x93, 0xd5, 0x18, 0xe7, 0x86, 0x0e, 0xcb, 0x93, 0x10, 0x18, 0xfc, 0x42, 0x67, 0x55, 0x7e, 0x1d,
+	0xaa, 0x2a, 0xfd, 0x3e, 0xed, 0xf8, 0x54, 0x0f, 0x9d, 0x2d, 0x0d, 0x53, 0xad, 0xc2, 0xbc, 0x61,
+	0xe9, 0xf4, 0x8c, 0x09, 0x55, 0x56, 0x79, 0x03, 0x6f, 0x1f, 0x97, 0x6a, 0x9e, 0xf0, 0xf0, 0xa2,
+	0x2a, 0x5a, 0xca, 0xbf, 0x4a, 0xb0, 0x99, 0xb0, 0x03, 0x22, 0x75, 0xf0, 0x1a, 0x14, 0xa9, 0x18,
+	0xd4, 0xc5, 0xdd, 0x3e, 0xec, 0x20, 0x9f, 0xa0, 0x14, 0x5c, 0x32, 0x39, 0xc7, 0x2e, 0xbf, 0x34,
+	0x73, 0x89, 0x2b, 0xa1, 0x86, 0xc4, 0x58, 0x6e, 0x3a, 0x32, 0x5c, 0xcf, 0x1f, 0x46, 0x8f, 0x3c,
+	0x53, 0xaa, 0xc2, 0x7a, 0xc3, 0xf8, 0x71, 0x05, 0x2a, 0xa6, 0xe6, 0xf9, 0xf1, 0x18, 0x53, 0x36,
+	0xb5, 0xe1, 0x24, 0xe5, 0xbb, 0xb0, 0xa1, 0xd2, 0xc3, 0xbe, 0x61, 0x06, 0x21, 0x59, 0x73, 0x32,
+	0x1d, 0xe8, 0x26, 0x26, 0x6d, 0xa9, 0xc3, 0xf2, 0x65, 0x02, 0xfc, 0x60, 0x7b, 0x4f, 0x73, 0x94,
+	0x9f, 0x4a, 0x20, 0x8f, 0xf3, 0x14, 0x5b, 0x34, 0xac, 0x89, 0x48, 0xd1, 0x9a, 0x48, 0x5a, 0x45,
+	0xf6, 0x12, 0x14, 0x59, 0x54, 0x61, 0xc9, 0x9c, 0x3c, 0x3b, 0xa5, 0x02, 0x76, 0x60, 0x26, 0x87,
+	0xbc, 0x07, 0xe4, 0x94, 0xba, 0xc6, 0x91, 0x41, 0xf5, 0x50, 0x51, 0x4f, 0x68, 0xba, 0x12, 0x8c,
+	0x04, 0xda, 0x7a, 0xca, 0x4d, 0x90, 0x3f, 0xa1, 0xbe, 0x4a, 0x3b, 0xb6, 0xd5, 0x31, 0x4c, 0x63,
+	0xe4, 0x96, 0x5b, 0x85, 0x79, 0x97, 0xba, 0x7d, 0x4b, 0xb8, 0x3c, 0x6f, 0x28, 0x9f, 0xc3, 0x6a,
+	0x80, 0x3c, 0xa3, 0x44, 0x53, 0x0b, 0xcc, 0x8e, 0x6b, 0x1f, 0x9a, 0xb4, 0xe7, 0xb1, 0xa3, 0x2e,
+	0xaa, 0x61, 0x5b, 0xf9, 0x43, 0x09, 0x96, 0x62, 0xbc, 0x02, 0x47, 0x96, 0x32, 0x66, 0xb8, 0x1e,
+	0x0f, 0x2b, 0x86, 0xdc, 0x90, 0x9a, 0xd3, 0x83, 0xc6, 0xa8, 0xca, 0x61, 0xfd, 0xf0, 0x9f, 0x17,
+	0xa0, 0xa2, 0xf6, 0x2d, 0x64, 0x2b, 0x6a, 0xaa, 0xd3, 0xb2, 0x93, 0xa2, 0xd2, 0x95, 0x4b, 0xae,
+	0x74, 0xe5, 0xa3, 0xa7, 0x1a, 0x7b, 0xe3, 0xcf, 0x65, 0x7a, 0xe3, 0xcf, 0x9f, 0xff, 0x8d, 0x1f,
+	0x87, 0x53, 0x0b, 0xe7, 0x82, 0x53, 0x8b, 0x33, 0xa5, 0xac, 0x86, 0xdf, 0xa9, 0x15, 0xd2, 0xbe,
+	0x53, 0x2b, 0x66, 0xf8, 0x4e, 0x0d, 0xce, 0xf7, 0x9d, 0xda, 0x85, 0xe4, 0x7c, 0x92, 0x11, 0x73,
+	0xf9, 0x62, 0x3e, 0xd1, 0x8a, 0x7c, 0xff, 0x58, 0x19, 0xfd, 0xfe, 0xf1, 0x69, 0x98, 0x6a, 0x5a,
+	0x62, 0x06, 0x7e, 0x27, 0x2d, 0x52, 0x46, 0xcd, 0x37, 0x31, 0xd7, 0x24, 0xc3, 0xe2, 0x29, 0x75,
+	0x59, 0x70, 0x59, 0x66, 0x36, 0x18, 0x34, 0xcf, 0x93, 0x57, 0x79, 0x1f, 0x36, 0x30, 0x94, 0x44,
+	0x17, 0xcf, 0x04, 0x54, 0x9f, 0xc1, 0x26, 0x83, 0x1c, 0x89, 0x94, 0xaf, 0x03, 0x84, 0x94, 0xfc,
+	0x89, 0x54, 0x54, 0x8b, 0x01, 0xa9, 0x17, 0xc5, 0x25, 0xb9, 0x51, 0x5c, 0xf2, 0x23, 0x09, 0xc8,
+	0x08, 0x47, 0x0e, 0x4f, 0x3e, 0x1a, 0xf9, 0x40, 0x22, 0xdd, 0x98, 0x46, 0x05, 0x12, 0x74, 0x61,
+	0x6c, 0xca, 0x65, 0x8b, 0x4d, 0x37, 0xae, 0x42, 0x39, 0x5a, 0x6f, 0x26, 0x05, 0x98, 0xdb, 0xdf,
+	0xbe, 0xfb, 0x3e, 0x07, 0x19, 0xad, 0xdd, 0xed, 0xbb, 0x77, 0x6f, 0xdd, 0xab, 0x4a, 0xdb, 0x7f,
+	0x7f, 0x19, 0x56, 0x9f, 0xd0, 0xc1, 0xb3, 0x88, 0x0c, 0x3b, 0x7a, 0xcf, 0xb0, 0xc8, 0x0f, 0x25,
+	0x28, 0x45, 0xbe, 0x92, 0x20, 0x69, 0xf9, 0x9f, 0xf1, 0xaf, 0x35, 0xea, 0x8d, 0xac, 0xd3, 0xf9,
+	0x7d, 0xa4, 0xd4, 0x7e, 0xfb, 0xdf, 0xfe, 0xfd, 0xc7, 0xb9, 0x0a, 0x29, 0x35, 0x4f, 0x6f, 0x35,
+	0x45, 0x50, 0x24, 0xbf, 0x09, 0xc5, 0xf0, 0x09, 0x42, 0xd2, 0x2e, 0xe9, 0xf8, 0xa7, 0x17, 0xf5,
+	0xe9, 0xe8, 0x4d, 0xb9, 0xcc, 0x56, 0xdc, 0x24, 0x1b, 0x91, 0x15, 0x9b, 0x5f, 0x87, 0xa7, 0xfe,
+	0x0d, 0x19, 0x40, 0x39, 0x5a, 0x45, 0x27, 0x8d, 0xd9, 0xca, 0xed, 0x59, 0x64, 0x58, 0x67, 0x32,
+	0x54, 0x95, 0xa8, 0xd6, 0xf7, 0xa5, 0x1b, 0xe4, 0x05, 0x94, 0xa3, 0x25, 0xee, 0xd4, 0xa5, 0x13,
+	0x6a, 0xe1, 0xf5, 0xf5, 0x31, 0xf3, 0x68, 0xe1, 0x47, 0xea, 0x81, 0xce, 0x37, 0x26, 0xea, 0xfc,
+	0xbb, 0x12, 0x2c, 0x8d, 0x16, 0xca, 0x49, 0xda, 0x23, 0x32, 0xb1, 0xa6, 0x3e, 0x71, 0xf5, 0xeb,
+	0x6c, 0x75, 0xe5, 0xc6, 0xd6, 0x84, 0xd5, 0xef, 0xf7, 0x05, 0x3b, 0xf2, 0xa7, 0x12, 0x14, 0xc3,
+	0xca, 0x7b, 0xea, 0xc9, 0xc7, 0xeb, 0xf3, 0x59, 0x76, 0xfd, 0x0e, 0x93, 0xa3, 0xa1, 0xbc, 0x3d,
+	0x41, 0x8e, 0xa6, 0xe6, 0x38, 0x5e, 0xf3, 0x6b, 0x5e, 0xfe, 0xfb, 0xa6, 0x79, 0xea, 0x1e, 0xe1,
+	0x99, 0xfc, 0x9e, 0x04, 0xe5, 0x68, 0x05, 0x38, 0xf5, 0x50, 0x12, 0x4a, 0xc5, 0x59, 0x24, 0x53,
+	0x98, 0x64, 0xaf, 0x6d, 0x4f, 0x3a, 0x1f, 0x94, 0xe3, 0x2f, 0x24, 0x58, 0x8e, 0x95, 0x79, 0xc9,
+	0xad, 0xb4, 0x70, 0x92, 0x58, 0x12, 0xce, 0x22, 0xcd, 0x7b, 0x4c, 0x9a, 0x6b, 0x8a, 0x32, 0x69,
+	0x9f, 0x70, 0x67, 0x78, 0x75, 0x16, 0x05, 0xfb, 0x29, 0x02, 0xaa, 0x91, 0x92, 0x70, 0xaa, 0xed,
+	0x24, 0x56, 0x8f, 0xb3, 0x88, 0xb5, 0xcd, 0xc4, 0x7a, 0x57, 0xb9, 0x36, 0x49, 0x2c, 0x7c, 0x80,
+	0x62, 0xee, 0x2a, 0x22, 0xdb, 0xdf, 0x48, 0xb0, 0x34, 0x5a, 0x04, 0x4e, 0x95, 0x2d, 0xb1, 0x5e,
+	0x3c, 0xd1, 0xae, 0x1f, 0x31, 0x81, 0x3e, 0x52, 0x1e, 0x64, 0xb3, 0x27, 0x4c, 0x21, 0x7a, 0xcd,
+	0xaf, 0x45, 0x19, 0xf9, 0x9b, 0xfb, 0x0e, 0x2e, 0x26, 0x84, 0xac, 0xc6, 0xcb, 0xa1, 0x64, 0x7b,
+	0x4a, 0x20, 0x4d, 0x28, 0x2a, 0xd7, 0x6f, 0xcf, 0x44, 0x23, 0x22, 0xf0, 0x55, 0xa6, 0xc5, 0x65,
+	0xf2, 0xfa, 0x44, 0x2d, 0x90, 0x8a, 0xfc, 0x44, 0x82, 0x72, 0xb4, 0xc6, 0x90, 0xea, 0x06, 0x09,
+	0xc5, 0x88, 0xfa, 0x6c, 0x85, 0x04, 0xe5, 0x2d, 0x26, 0xd6, 0x16, 0x79, 0x63, 0x92, 0x4b, 0xf0,
+	0xea, 0x1a, 0xf9, 0x81, 0x04, 0xe5, 0xc7, 0xbd, 0x8c, 0x72, 0x25, 0x14, 0x3c, 0xb2, 0x58, 0xde,
+	0xeb, 0x4c, 0x96, 0x0d, 0x85, 0x44, 0xc3, 0xb5, 0xc1, 0x78, 0xe1, 0xf9, 0xfd, 0x89, 0x04, 0xe5,
+	0x68, 0x4a, 0x3f, 0x55, 0x84, 0x84, 0xdc, 0x7f, 0x16, 0x11, 0xde, 0x65, 0x22, 0xbc, 0x55, 0x7f,
+	0x73, 0xd2, 0x29, 0x9d, 0xd0, 0x01, 0x07, 0x87, 0x28, 0xd1, 0x1f, 0x49, 0x50, 0x8e, 0xe6, 0xfa,
+	0x53, 0x25, 0x4a, 0x28, 0x0a, 0x64, 0x91, 0xe8, 0x6d, 0x26, 0xd1, 0x15, 0x65, 0xe2, 0x01, 0xf1,
+	0xb2, 0x01, 0x8a, 0xf3, 0x67, 0xec, 0x76, 0x89, 0x96, 0x11, 0xa6, 0xdc, 0x2e, 0x47, 0xaf, 0x26,
+	0xd2, 0x3b, 0x4c, 0xa4, 0xab, 0x4a, 0xca, 0x45, 0x33, 0x14, 0xea, 0x87, 0x12, 0xc0, 0xb0, 0x4a,
+	0x41, 0xd2, 0xc0, 0xf4, 0x58, 0x31, 0x63, 0x62, 0x48, 0x98, 0x16, 0x3a, 0xef, 0x1f, 0x85, 0xac,
+	0xc4, 0xc6, 0x54, 0x46, 0xea, 0x1e, 0xa4, 0x99, 0x6e, 0x3a, 0x63, 0x15, 0x92, 0x19, 0xe2, 0x79,
+	0x5d, 0x49, 0x89, 0x53, 0xe2, 0x5d, 0x84, 0x42, 0xfd, 0x58, 0x82, 0x6a, 0xbc, 0x7e, 0x91, 0x1a,
+	0x8e, 0x26, 0x14, 0x3b, 0xb2, 0x88, 0x26, 0xa0, 0x41, 0x7d, 0x62, 0xf0, 0x61, 0x2f, 0x31, 0x94,
+	0xea, 0x27, 0x12, 0x2c, 0xc7, 0xf2, 0xd2, 0xa9, 0xd7, 0x5f, 0x72, 0x0e, 0xbb, 0x7e, 0x6d, 0xaa,
+	0x4c, 0x7c, 0xfe, 0xd4, 0xf8, 0xd3, 0xf4, 0xb8, 0x0c, 0xf8, 0x79, 0x76, 0x34, 0x85, 0x99, 0xea,
+	0x6a, 0x09, 0xb9, 0xce, 0xfa, 0x5b, 0xd9, 0x72, 0x96, 0xca, 0x26, 0x13, 0xa8, 0x46, 0x56, 0xa2,
+	0x41, 0xe8, 0x05, 0x32, 0xbc, 0x29, 0x91, 0x7f, 0x92, 0x60, 0x65, 0x2c, 0x2f, 0x46, 0xd2, 0xee,
+	0x83, 0x49, 0x79, 0xc4, 0xfa, 0x9d, 0xd9, 0x88, 0xc4, 0x2d, 0x72, 0x97, 0x49, 0xd7, 0x54, 0x6e,
+	0x4c, 0x8f, 0x4f, 0x41, 0x46, 0x0e, 0x4f, 0xf5, 0x67, 0x12, 0x2b, 0xfc, 0x8d, 0x57, 0xa9, 0xde,
+	0x4f, 0xb7, 0xb7, 0x49, 0xe5, 0xa7, 0x2c, 0x36, 0xd7, 0x60, 0xa2, 0x5e, 0xaf, 0x5f, 0x99, 0x24,
+	0x6a, 0xe4, 0xa1, 0x8c, 0x32, 0xfe, 0x95, 0x04, 0x64, 0xbc, 0xae, 0x44, 0xee, 0xa4, 0x4b, 0x98,
+	0x5c, 0x86, 0xba, 0x90, 0x50, 0x1f, 0x64, 0x46, 0xd8, 0x93, 0xe1, 0x6f, 0x25, 0xd8, 0x98, 0x50,
+	0x3e, 0x22, 0xf7, 0xd2, 0x00, 0x74, 0x6a, 0xc9, 0xe9, 0x42, 0xe4, 0x0c, 0x6b, 0x4a, 0x28, 0xe7,
+	0xcf, 0x25, 0xa8, 0xc6, 0xb3, 0x92, 0xa9, 0x51, 0x65, 0x42, 0x5a, 0xb4, 0x7e, 0x7b, 0x26, 0x1a,
+	0x61, 0x9e, 0xe2, 0xcc, 0x95, 0x89, 0x67, 0xde, 0xd3, 0x9c, 0xfb, 0x2e, 0xa7, 0x16, 0x57, 0xfa,
+	0xca, 0x58, 0xa2, 0x32, 0xd5, 0x9f, 0x26, 0xa5, 0x35, 0xeb, 0x6f, 0xa7, 0xca, 0x1b, 0xa5, 0x50,
+	0xea, 0x4c, 0xca, 0x55, 0xc2, 0x70, 0x86, 0x3b, 0x32, 0xb6, 0xfd, 0x07, 0x39, 0x58, 0x8b, 0x3d,
+	0xd8, 0x45, 0xc2, 0xd0, 0x65, 0x7f, 0x82, 0x30, 0x9a, 0x44, 0xdc, 0x9e, 0x22, 0x69, 0x42, 0xee,
+	0xa3, 0x9e, 0x39, 0x37, 0xa1, 0x7c, 0x8b, 0x7c, 0x03, 0x64, 0x3c, 0x89, 0x92, 0xea, 0x12, 0x13,
+	0x73, 0x2e, 0xa9, 0xc0, 0x70, 0x3c, 0xa5, 0xa2, 0x7c, 0xeb, 0xa6, 0xf4, 0x71, 0xeb, 0xcb, 0x87,
+	0x5d, 0xc3, 0x3f, 0xee, 0x1f, 0x36, 0x3a, 0x76, 0xaf, 0xc9, 0xc9, 0xe3, 0x7f, 0x16, 0xde, 0xec,
+	0xd8, 0x2e, 0xff, 0x33, 0xef, 0x49, 0x7f, 0x32, 0x7e, 0xb8, 0xc0, 0xfe, 0xbb, 0xfd, 0x3f, 0x03,
+	0x00, 0x55, 0x20, 0xec, 0x4e, 0x55, 0x3e, 0x00, 0x00,
 }
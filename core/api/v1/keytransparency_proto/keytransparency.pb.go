@@ -32,13 +32,92 @@ It has these top-level messages:
 	Epoch
 	ListMutationsRequest
 	ListMutationsResponse
+	GetServerCapabilitiesRequest
+	ServerCapabilities
+	EpochProof
+	UserProofArchive
+	GetServerVersionRequest
+	ServerVersion
+	GetDomainStatsRequest
+	DomainStats
+	GetLogConsistencyChainRequest
+	LogConsistencyProof
+	LogConsistencyChain
+	ListUserAppsRequest
+	ListUserAppsResponse
+	QuotaViolation
+	TrustAnchor
+	WatchKeyChangesRequest
+	KeyChangeEvent
+	SetKeyChangeWebhookRequest
+	SetKeyChangeWebhookResponse
+	GetEntryAtRevisionRequest
+	Permalink
+	SignedPermalink
+	TransparencySnapshot
+	SnapshotEntry
+	CreatePermalinkRequest
+	CreatePermalinkResponse
+	MapRootEndorsement
+	WatchEntryRequest
+	ProofBundle
+	ProfileKey
+	KeyProfile
 	Domain
+	TrillianBackend
+	TreeKeyRotation
+	DomainMigration
+	SignedDomainMigration
+	DomainConfig
+	SignedDomainConfig
 	ListDomainsRequest
 	ListDomainsResponse
 	GetDomainRequest
 	CreateDomainRequest
 	DeleteDomainRequest
 	UndeleteDomainRequest
+	AddAppVRFRequest
+	TreeSpec
+	UpdateDomainRequest
+	RotateDomainVRFRequest
+	RotateTreeKeysRequest
+	PurgeEntryDataRequest
+	ListAuditEntriesRequest
+	AuditEntry
+	ListAuditEntriesResponse
+	DomainBundle
+	SignedDomainBundle
+	ExportDomainRequest
+	ImportDomainRequest
+	KeyPolicy
+	KeyPolicyChange
+	SetKeyPolicyRequest
+	FreezeDomainRequest
+	UnfreezeDomainRequest
+	ForceEpochRequest
+	SetAppListingRequest
+	MutationQuota
+	SetMutationQuotaRequest
+	EndorsementPolicy
+	SetEndorsementPolicyRequest
+	SetDomainIntervalsRequest
+	AnnounceDomainMigrationRequest
+	GetDomainStatusRequest
+	DomainStatus
+	WatchDomainsRequest
+	DomainEvent
+	EvaluateKeyPolicyRequest
+	RejectedMutation
+	EvaluateKeyPolicyResponse
+	RebuildDomainMapRequest
+	RebuildDomainMapResponse
+	GetReconciliationRequest
+	DomainReconciliation
+	Reconciliation
+	RuntimeConfig
+	GetRuntimeConfigRequest
+	WatchRuntimeConfigRequest
+	RuntimeConfigEvent
 */
 package keytransparency_proto
 
@@ -46,6 +125,8 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import _ "google.golang.org/genproto/googleapis/api/annotations"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/duration"
+import google_protobuf2 "github.com/golang/protobuf/ptypes/timestamp"
 import keyspb "github.com/google/trillian/crypto/keyspb"
 import sigpb "github.com/google/trillian/crypto/sigpb"
 import trillian "github.com/google/trillian"
@@ -140,6 +221,18 @@ type Entry struct {
 	// current epochs. The first proves ownership of new epoch key, and the
 	// second proves that the correct owner is making this change.
 	Signatures map[string]*sigpb.DigitallySigned `protobuf:"bytes,2,rep,name=signatures" json:"signatures,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// mutation_type names the semantics of this mutation. An empty value is
+	// equivalent to "update", which replaces the commitment and authorized keys.
+	// The other standard types are "reset", "revoke", "freeze" and "policy".
+	// Domains may accept additional types.
+	MutationType string `protobuf:"bytes,9,opt,name=mutation_type,json=mutationType" json:"mutation_type,omitempty"`
+	// recovery_keys are the keys that may reset the authorized keys of this
+	// entry with a "reset" mutation. They cannot sign any other mutation.
+	RecoveryKeys []*keyspb.PublicKey `protobuf:"bytes,10,rep,name=recovery_keys,json=recoveryKeys" json:"recovery_keys,omitempty"`
+	// previous_index is set by the first update of an entry after a domain VRF
+	// rotation. It is the index of the entry under the previous VRF, and
+	// previous is the hash of the leaf stored there.
+	PreviousIndex []byte `protobuf:"bytes,11,opt,name=previous_index,json=previousIndex,proto3" json:"previous_index,omitempty"`
 }
 
 func (m *Entry) Reset()                    { *m = Entry{} }
@@ -182,6 +275,27 @@ func (m *Entry) GetSignatures() map[string]*sigpb.DigitallySigned {
 	return nil
 }
 
+func (m *Entry) GetMutationType() string {
+	if m != nil {
+		return m.MutationType
+	}
+	return ""
+}
+
+func (m *Entry) GetRecoveryKeys() []*keyspb.PublicKey {
+	if m != nil {
+		return m.RecoveryKeys
+	}
+	return nil
+}
+
+func (m *Entry) GetPreviousIndex() []byte {
+	if m != nil {
+		return m.PreviousIndex
+	}
+	return nil
+}
+
 // MutationProof contains the information necessary to compute the new leaf value.
 // It contains a) the old leaf value with it's inclusion proof and b) the mutation.
 // The new leaf value is computed via:
@@ -192,6 +306,10 @@ type MutationProof struct {
 	Mutation *Entry `protobuf:"bytes,1,opt,name=mutation" json:"mutation,omitempty"`
 	// leaf_proof contains the leaf and its inclusion proof for a particular map revision.
 	LeafProof *trillian1.MapLeafInclusion `protobuf:"bytes,2,opt,name=leaf_proof,json=leafProof" json:"leaf_proof,omitempty"`
+	// previous_leaf_proof contains the leaf at mutation.previous_index and its
+	// inclusion proof for the same map revision. It is only set for mutations
+	// with a previous_index.
+	PreviousLeafProof *trillian1.MapLeafInclusion `protobuf:"bytes,3,opt,name=previous_leaf_proof,json=previousLeafProof" json:"previous_leaf_proof,omitempty"`
 }
 
 func (m *MutationProof) Reset()                    { *m = MutationProof{} }
@@ -213,10 +331,24 @@ func (m *MutationProof) GetLeafProof() *trillian1.MapLeafInclusion {
 	return nil
 }
 
+func (m *MutationProof) GetPreviousLeafProof() *trillian1.MapLeafInclusion {
+	if m != nil {
+		return m.PreviousLeafProof
+	}
+	return nil
+}
+
 // MapperMetadata tracks the mutations that have been mapped so far. It is
 // embedded in the Trillian SignedMapHead.
 type MapperMetadata struct {
 	HighestFullyCompletedSeq int64 `protobuf:"varint,1,opt,name=highest_fully_completed_seq,json=highestFullyCompletedSeq" json:"highest_fully_completed_seq,omitempty"`
+	// sequencer_version identifies the build of the sequencer that created
+	// this epoch. It is covered by the map root's signature.
+	SequencerVersion *ServerVersion `protobuf:"bytes,2,opt,name=sequencer_version,json=sequencerVersion" json:"sequencer_version,omitempty"`
+	// migration_hash is the SHA-256 hash of the serialized
+	// SignedDomainMigration that the domain announced when this epoch was
+	// created. It is empty if no migration was announced.
+	MigrationHash []byte `protobuf:"bytes,3,opt,name=migration_hash,json=migrationHash,proto3" json:"migration_hash,omitempty"`
 }
 
 func (m *MapperMetadata) Reset()                    { *m = MapperMetadata{} }
@@ -231,6 +363,20 @@ func (m *MapperMetadata) GetHighestFullyCompletedSeq() int64 {
 	return 0
 }
 
+func (m *MapperMetadata) GetSequencerVersion() *ServerVersion {
+	if m != nil {
+		return m.SequencerVersion
+	}
+	return nil
+}
+
+func (m *MapperMetadata) GetMigrationHash() []byte {
+	if m != nil {
+		return m.MigrationHash
+	}
+	return nil
+}
+
 // UserProfile is the data that a client would like to store on the server.
 type UserProfile struct {
 	// data is the public key data for the user.
@@ -315,6 +461,18 @@ type GetEntryResponse struct {
 	LogConsistency [][]byte `protobuf:"bytes,6,rep,name=log_consistency,json=logConsistency,proto3" json:"log_consistency,omitempty"`
 	// log_inclusion proves that smr is part of log_root at index=srm.MapRevision.
 	LogInclusion [][]byte `protobuf:"bytes,7,rep,name=log_inclusion,json=logInclusion,proto3" json:"log_inclusion,omitempty"`
+	// committed_purged indicates that the committed profile data for this
+	// entry has been purged from the server. The commitment in leaf_proof is
+	// unchanged, but committed is not returned.
+	CommittedPurged bool `protobuf:"varint,8,opt,name=committed_purged,json=committedPurged" json:"committed_purged,omitempty"`
+	// endorsements are co-signatures of smr by the domain's endorsers. They are
+	// only returned for domains that require endorsement.
+	Endorsements []*MapRootEndorsement `protobuf:"bytes,9,rep,name=endorsements" json:"endorsements,omitempty"`
+	// current_vrf_proof is set while the entry is still stored under its index
+	// from before a domain VRF rotation, in which case vrf_proof is a proof
+	// under the previous VRF. It proves the entry's index under the current
+	// VRF, which the next update of the entry moves it to.
+	CurrentVrfProof []byte `protobuf:"bytes,10,opt,name=current_vrf_proof,json=currentVrfProof,proto3" json:"current_vrf_proof,omitempty"`
 }
 
 func (m *GetEntryResponse) Reset()                    { *m = GetEntryResponse{} }
@@ -371,13 +529,35 @@ func (m *GetEntryResponse) GetLogInclusion() [][]byte {
 	return nil
 }
 
+func (m *GetEntryResponse) GetCommittedPurged() bool {
+	if m != nil {
+		return m.CommittedPurged
+	}
+	return false
+}
+
+func (m *GetEntryResponse) GetEndorsements() []*MapRootEndorsement {
+	if m != nil {
+		return m.Endorsements
+	}
+	return nil
+}
+
+func (m *GetEntryResponse) GetCurrentVrfProof() []byte {
+	if m != nil {
+		return m.CurrentVrfProof
+	}
+	return nil
+}
+
 // ListEntryHistoryRequest gets a list of historical keys for a user.
 type ListEntryHistoryRequest struct {
 	// domain_id identifies the domain in which the user and application live.
 	DomainId string `protobuf:"bytes,6,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
 	// user_id is the user identifier.
 	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId" json:"user_id,omitempty"`
-	// start is the starting epoch.
+	// start is the starting epoch. It is ignored if page_token is set.
+	// Deprecated: use page_token.
 	Start int64 `protobuf:"varint,2,opt,name=start" json:"start,omitempty"`
 	// page_size is the maximum number of entries to return.
 	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
@@ -386,6 +566,13 @@ type ListEntryHistoryRequest struct {
 	// first_tree_size is the tree_size of the currently trusted log root.
 	// Omitting this field will omit the log consistency proof from the response.
 	FirstTreeSize int64 `protobuf:"varint,5,opt,name=first_tree_size,json=firstTreeSize" json:"first_tree_size,omitempty"`
+	// page_token continues a listing. To request the next page, pass
+	// next_page_token from the previous response with the same
+	// newest_first. To start at the beginning, omit page_token.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	// newest_first lists the history from the latest epoch back to epoch 0
+	// rather than from epoch 0 forward.
+	NewestFirst bool `protobuf:"varint,8,opt,name=newest_first,json=newestFirst" json:"newest_first,omitempty"`
 }
 
 func (m *ListEntryHistoryRequest) Reset()                    { *m = ListEntryHistoryRequest{} }
@@ -435,13 +622,31 @@ func (m *ListEntryHistoryRequest) GetFirstTreeSize() int64 {
 	return 0
 }
 
+func (m *ListEntryHistoryRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListEntryHistoryRequest) GetNewestFirst() bool {
+	if m != nil {
+		return m.NewestFirst
+	}
+	return false
+}
+
 // ListEntryHistoryResponse requests a paginated history of keys for a user.
 type ListEntryHistoryResponse struct {
 	// values represents the list of keys this user_id has contained over time.
 	Values []*GetEntryResponse `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
 	// next_start is the next page token to query for pagination.
 	// next_start is 0 when there are no more results to fetch.
+	// Deprecated: use next_page_token.
 	NextStart int64 `protobuf:"varint,2,opt,name=next_start,json=nextStart" json:"next_start,omitempty"`
+	// next_page_token is the page token of the next page. It is empty when
+	// there are no more results to fetch.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *ListEntryHistoryResponse) Reset()                    { *m = ListEntryHistoryResponse{} }
@@ -463,6 +668,13 @@ func (m *ListEntryHistoryResponse) GetNextStart() int64 {
 	return 0
 }
 
+func (m *ListEntryHistoryResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // UpdateEntryRequest updates a user's profile.
 type UpdateEntryRequest struct {
 	// domain_id identifies the domain in which the user and application live.
@@ -615,6 +827,9 @@ type Epoch struct {
 	LogConsistency [][]byte `protobuf:"bytes,4,rep,name=log_consistency,json=logConsistency,proto3" json:"log_consistency,omitempty"`
 	// log_inclusion proves that smr is part of log_root at index=srm.MapRevision.
 	LogInclusion [][]byte `protobuf:"bytes,5,rep,name=log_inclusion,json=logInclusion,proto3" json:"log_inclusion,omitempty"`
+	// endorsements are co-signatures of smr by the domain's endorsers. They are
+	// only returned for domains that require endorsement.
+	Endorsements []*MapRootEndorsement `protobuf:"bytes,6,rep,name=endorsements" json:"endorsements,omitempty"`
 }
 
 func (m *Epoch) Reset()                    { *m = Epoch{} }
@@ -657,6 +872,13 @@ func (m *Epoch) GetLogInclusion() [][]byte {
 	return nil
 }
 
+func (m *Epoch) GetEndorsements() []*MapRootEndorsement {
+	if m != nil {
+		return m.Endorsements
+	}
+	return nil
+}
+
 // ListMutationsRequest requests the mutations that created a given epoch.
 type ListMutationsRequest struct {
 	// domain_id is the domain identifier.
@@ -733,193 +955,1511 @@ func (m *ListMutationsResponse) GetNextPageToken() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*Committed)(nil), "google.keytransparency.v1.Committed")
-	proto.RegisterType((*EntryUpdate)(nil), "google.keytransparency.v1.EntryUpdate")
-	proto.RegisterType((*Entry)(nil), "google.keytransparency.v1.Entry")
-	proto.RegisterType((*MutationProof)(nil), "google.keytransparency.v1.MutationProof")
-	proto.RegisterType((*MapperMetadata)(nil), "google.keytransparency.v1.MapperMetadata")
-	proto.RegisterType((*UserProfile)(nil), "google.keytransparency.v1.UserProfile")
-	proto.RegisterType((*GetEntryRequest)(nil), "google.keytransparency.v1.GetEntryRequest")
-	proto.RegisterType((*GetEntryResponse)(nil), "google.keytransparency.v1.GetEntryResponse")
-	proto.RegisterType((*ListEntryHistoryRequest)(nil), "google.keytransparency.v1.ListEntryHistoryRequest")
-	proto.RegisterType((*ListEntryHistoryResponse)(nil), "google.keytransparency.v1.ListEntryHistoryResponse")
-	proto.RegisterType((*UpdateEntryRequest)(nil), "google.keytransparency.v1.UpdateEntryRequest")
-	proto.RegisterType((*UpdateEntryResponse)(nil), "google.keytransparency.v1.UpdateEntryResponse")
-	proto.RegisterType((*GetEpochRequest)(nil), "google.keytransparency.v1.GetEpochRequest")
-	proto.RegisterType((*GetLatestEpochRequest)(nil), "google.keytransparency.v1.GetLatestEpochRequest")
-	proto.RegisterType((*Epoch)(nil), "google.keytransparency.v1.Epoch")
-	proto.RegisterType((*ListMutationsRequest)(nil), "google.keytransparency.v1.ListMutationsRequest")
-	proto.RegisterType((*ListMutationsResponse)(nil), "google.keytransparency.v1.ListMutationsResponse")
+// GetServerCapabilitiesRequest requests information about the server.
+type GetServerCapabilitiesRequest struct {
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *GetServerCapabilitiesRequest) Reset()                    { *m = GetServerCapabilitiesRequest{} }
+func (m *GetServerCapabilitiesRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetServerCapabilitiesRequest) ProtoMessage()               {}
+func (*GetServerCapabilitiesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+// ServerCapabilities describes the server and its view of the current time.
+type ServerCapabilities struct {
+	// server_time is the server's wall clock time when the response was created.
+	// Clients compare this against their local clock to detect clock skew.
+	ServerTime *google_protobuf2.Timestamp `protobuf:"bytes,1,opt,name=server_time,json=serverTime" json:"server_time,omitempty"`
+}
 
-// Client API for KeyTransparency service
+func (m *ServerCapabilities) Reset()                    { *m = ServerCapabilities{} }
+func (m *ServerCapabilities) String() string            { return proto.CompactTextString(m) }
+func (*ServerCapabilities) ProtoMessage()               {}
+func (*ServerCapabilities) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
-type KeyTransparencyClient interface {
-	// GetDomain returns the information needed to verify the specified domain.
-	GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error)
-	// GetEpoch returns a SignedMapRoot by the by the requested revision number
-	// along with its inclusion proof in the log and the log's consistency proofs.
-	GetEpoch(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (*Epoch, error)
-	// GetLatestEpoch returns the latest SignedMapRoot along with its inclusion
-	// proof in the log and the log's consistency proofs.
-	GetLatestEpoch(ctx context.Context, in *GetLatestEpochRequest, opts ...grpc.CallOption) (*Epoch, error)
-	// GetEpochStream streams new epochs from a requested starting point
-	// and continues as new epochs are created.
-	GetEpochStream(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (KeyTransparency_GetEpochStreamClient, error)
-	// ListMutations returns a list of mutations in a specific epoch.
-	ListMutations(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (*ListMutationsResponse, error)
-	// ListMutationsStream is a streaming list of mutations in a specific epoch.
-	ListMutationsStream(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (KeyTransparency_ListMutationsStreamClient, error)
-	// GetEntry returns a user's entry in the Merkle Tree.
-	//
-	// Entries contain signed commitments to a profile, which is also returned.
-	// TODO(gbelvin): Replace with GetUser
-	GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error)
-	// ListEntryHistory returns a list of historic GetEntry values.
-	//
-	// Clients verify their account history by observing correct values for their
-	// account over time.
-	ListEntryHistory(ctx context.Context, in *ListEntryHistoryRequest, opts ...grpc.CallOption) (*ListEntryHistoryResponse, error)
-	// UpdateEntry updates a user's profile.
-	//
-	// Returns the current user profile.
-	// Clients must retry until this function returns a proof containing the desired value.
-	UpdateEntry(ctx context.Context, in *UpdateEntryRequest, opts ...grpc.CallOption) (*UpdateEntryResponse, error)
+func (m *ServerCapabilities) GetServerTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.ServerTime
+	}
+	return nil
 }
 
-type keyTransparencyClient struct {
-	cc *grpc.ClientConn
+// EpochProof contains the proof material relevant to a single user in one epoch.
+type EpochProof struct {
+	// entry is the user's entry, or proof of absence, at this epoch along with
+	// the map root and the log proofs for that map root.
+	Entry *GetEntryResponse `protobuf:"bytes,1,opt,name=entry" json:"entry,omitempty"`
+	// mutations are the mutations in this epoch that modified the user's index.
+	Mutations []*MutationProof `protobuf:"bytes,2,rep,name=mutations" json:"mutations,omitempty"`
 }
 
-func NewKeyTransparencyClient(cc *grpc.ClientConn) KeyTransparencyClient {
-	return &keyTransparencyClient{cc}
-}
+func (m *EpochProof) Reset()                    { *m = EpochProof{} }
+func (m *EpochProof) String() string            { return proto.CompactTextString(m) }
+func (*EpochProof) ProtoMessage()               {}
+func (*EpochProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
-func (c *keyTransparencyClient) GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
-	out := new(Domain)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetDomain", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *EpochProof) GetEntry() *GetEntryResponse {
+	if m != nil {
+		return m.Entry
 	}
-	return out, nil
+	return nil
 }
 
-func (c *keyTransparencyClient) GetEpoch(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (*Epoch, error) {
-	out := new(Epoch)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetEpoch", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *EpochProof) GetMutations() []*MutationProof {
+	if m != nil {
+		return m.Mutations
 	}
-	return out, nil
+	return nil
 }
 
-func (c *keyTransparencyClient) GetLatestEpoch(ctx context.Context, in *GetLatestEpochRequest, opts ...grpc.CallOption) (*Epoch, error) {
-	out := new(Epoch)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetLatestEpoch", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+// UserProofArchive is a portable, self contained record of a user's history
+// that can be verified offline.
+type UserProofArchive struct {
+	// domain contains the public keys and tree parameters used for verification.
+	Domain *Domain `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
+	// app_id is the application the user belongs to.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user identifier.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// epochs contains the proofs for each epoch, in increasing order.
+	Epochs []*EpochProof `protobuf:"bytes,4,rep,name=epochs" json:"epochs,omitempty"`
+}
+
+func (m *UserProofArchive) Reset()                    { *m = UserProofArchive{} }
+func (m *UserProofArchive) String() string            { return proto.CompactTextString(m) }
+func (*UserProofArchive) ProtoMessage()               {}
+func (*UserProofArchive) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *UserProofArchive) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
 	}
-	return out, nil
+	return nil
 }
 
-func (c *keyTransparencyClient) GetEpochStream(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (KeyTransparency_GetEpochStreamClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[0], c.cc, "/google.keytransparency.v1.KeyTransparency/GetEpochStream", opts...)
-	if err != nil {
-		return nil, err
+func (m *UserProofArchive) GetAppId() string {
+	if m != nil {
+		return m.AppId
 	}
-	x := &keyTransparencyGetEpochStreamClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+	return ""
+}
+
+func (m *UserProofArchive) GetUserId() string {
+	if m != nil {
+		return m.UserId
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	return ""
+}
+
+func (m *UserProofArchive) GetEpochs() []*EpochProof {
+	if m != nil {
+		return m.Epochs
 	}
-	return x, nil
+	return nil
 }
 
-type KeyTransparency_GetEpochStreamClient interface {
-	Recv() (*Epoch, error)
-	grpc.ClientStream
+// GetServerVersionRequest requests the version of the server.
+type GetServerVersionRequest struct {
 }
 
-type keyTransparencyGetEpochStreamClient struct {
-	grpc.ClientStream
+func (m *GetServerVersionRequest) Reset()                    { *m = GetServerVersionRequest{} }
+func (m *GetServerVersionRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetServerVersionRequest) ProtoMessage()               {}
+func (*GetServerVersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+// ServerVersion identifies a server build.
+type ServerVersion struct {
+	// version is the release version the binary was built from.
+	Version string `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
+	// binary_digest is the SHA256 digest of the server binary.
+	BinaryDigest []byte `protobuf:"bytes,2,opt,name=binary_digest,json=binaryDigest,proto3" json:"binary_digest,omitempty"`
 }
 
-func (x *keyTransparencyGetEpochStreamClient) Recv() (*Epoch, error) {
-	m := new(Epoch)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *ServerVersion) Reset()                    { *m = ServerVersion{} }
+func (m *ServerVersion) String() string            { return proto.CompactTextString(m) }
+func (*ServerVersion) ProtoMessage()               {}
+func (*ServerVersion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *ServerVersion) GetVersion() string {
+	if m != nil {
+		return m.Version
 	}
-	return m, nil
+	return ""
 }
 
-func (c *keyTransparencyClient) ListMutations(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (*ListMutationsResponse, error) {
-	out := new(ListMutationsResponse)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/ListMutations", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ServerVersion) GetBinaryDigest() []byte {
+	if m != nil {
+		return m.BinaryDigest
 	}
-	return out, nil
+	return nil
 }
 
-func (c *keyTransparencyClient) ListMutationsStream(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (KeyTransparency_ListMutationsStreamClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[1], c.cc, "/google.keytransparency.v1.KeyTransparency/ListMutationsStream", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &keyTransparencyListMutationsStreamClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+// GetDomainStatsRequest requests aggregate statistics for a domain.
+type GetDomainStatsRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// epochs is the number of most recent epochs to aggregate over.
+	// The server may cap this value.
+	Epochs int32 `protobuf:"varint,2,opt,name=epochs" json:"epochs,omitempty"`
 }
 
-type KeyTransparency_ListMutationsStreamClient interface {
-	Recv() (*MutationProof, error)
-	grpc.ClientStream
+func (m *GetDomainStatsRequest) Reset()                    { *m = GetDomainStatsRequest{} }
+func (m *GetDomainStatsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetDomainStatsRequest) ProtoMessage()               {}
+func (*GetDomainStatsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *GetDomainStatsRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
 }
 
-type keyTransparencyListMutationsStreamClient struct {
-	grpc.ClientStream
+func (m *GetDomainStatsRequest) GetEpochs() int32 {
+	if m != nil {
+		return m.Epochs
+	}
+	return 0
 }
 
-func (x *keyTransparencyListMutationsStreamClient) Recv() (*MutationProof, error) {
-	m := new(MutationProof)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+// DomainStats contains aggregate, anonymized statistics about a domain.
+// Counts smaller than threshold are reported as zero so that individual
+// users cannot be singled out.
+type DomainStats struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// start_epoch is the first epoch included in the statistics.
+	StartEpoch int64 `protobuf:"varint,2,opt,name=start_epoch,json=startEpoch" json:"start_epoch,omitempty"`
+	// end_epoch is the last epoch included in the statistics.
+	EndEpoch int64 `protobuf:"varint,3,opt,name=end_epoch,json=endEpoch" json:"end_epoch,omitempty"`
+	// active_entries is the number of distinct entries updated in the window.
+	ActiveEntries int64 `protobuf:"varint,4,opt,name=active_entries,json=activeEntries" json:"active_entries,omitempty"`
+	// mutations is the number of mutations applied in the window.
+	Mutations int64 `protobuf:"varint,5,opt,name=mutations" json:"mutations,omitempty"`
+	// mutations_per_hour is the average update rate over the window.
+	MutationsPerHour float64 `protobuf:"fixed64,6,opt,name=mutations_per_hour,json=mutationsPerHour" json:"mutations_per_hour,omitempty"`
+	// key_algorithms counts the authorized keys in updated entries by algorithm.
+	KeyAlgorithms map[string]int64 `protobuf:"bytes,7,rep,name=key_algorithms,json=keyAlgorithms" json:"key_algorithms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// threshold is the minimum count that is reported.
+	Threshold int64 `protobuf:"varint,8,opt,name=threshold" json:"threshold,omitempty"`
+	// total_indexes is the number of distinct indexes in the map.
+	// Like total_mutations and epochs, it is counted as epochs are created:
+	// for domains that predate these totals, the epochs created before the
+	// server started counting are not included.
+	TotalIndexes int64 `protobuf:"varint,9,opt,name=total_indexes,json=totalIndexes" json:"total_indexes,omitempty"`
+	// total_mutations is the number of mutations processed since the domain
+	// was created.
+	TotalMutations int64 `protobuf:"varint,10,opt,name=total_mutations,json=totalMutations" json:"total_mutations,omitempty"`
+	// epochs is the number of epochs created since the domain was created.
+	Epochs int64 `protobuf:"varint,11,opt,name=epochs" json:"epochs,omitempty"`
+	// average_epoch_size is the mean number of mutations per epoch.
+	AverageEpochSize float64 `protobuf:"fixed64,12,opt,name=average_epoch_size,json=averageEpochSize" json:"average_epoch_size,omitempty"`
+}
+
+func (m *DomainStats) Reset()                    { *m = DomainStats{} }
+func (m *DomainStats) String() string            { return proto.CompactTextString(m) }
+func (*DomainStats) ProtoMessage()               {}
+func (*DomainStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *DomainStats) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
 	}
-	return m, nil
+	return ""
 }
 
-func (c *keyTransparencyClient) GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error) {
-	out := new(GetEntryResponse)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetEntry", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *DomainStats) GetStartEpoch() int64 {
+	if m != nil {
+		return m.StartEpoch
 	}
-	return out, nil
+	return 0
 }
 
-func (c *keyTransparencyClient) ListEntryHistory(ctx context.Context, in *ListEntryHistoryRequest, opts ...grpc.CallOption) (*ListEntryHistoryResponse, error) {
-	out := new(ListEntryHistoryResponse)
-	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/ListEntryHistory", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *DomainStats) GetEndEpoch() int64 {
+	if m != nil {
+		return m.EndEpoch
 	}
-	return out, nil
+	return 0
+}
+
+func (m *DomainStats) GetActiveEntries() int64 {
+	if m != nil {
+		return m.ActiveEntries
+	}
+	return 0
+}
+
+func (m *DomainStats) GetMutations() int64 {
+	if m != nil {
+		return m.Mutations
+	}
+	return 0
+}
+
+func (m *DomainStats) GetMutationsPerHour() float64 {
+	if m != nil {
+		return m.MutationsPerHour
+	}
+	return 0
+}
+
+func (m *DomainStats) GetKeyAlgorithms() map[string]int64 {
+	if m != nil {
+		return m.KeyAlgorithms
+	}
+	return nil
+}
+
+func (m *DomainStats) GetThreshold() int64 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+func (m *DomainStats) GetTotalIndexes() int64 {
+	if m != nil {
+		return m.TotalIndexes
+	}
+	return 0
+}
+
+func (m *DomainStats) GetTotalMutations() int64 {
+	if m != nil {
+		return m.TotalMutations
+	}
+	return 0
+}
+
+func (m *DomainStats) GetEpochs() int64 {
+	if m != nil {
+		return m.Epochs
+	}
+	return 0
+}
+
+func (m *DomainStats) GetAverageEpochSize() float64 {
+	if m != nil {
+		return m.AverageEpochSize
+	}
+	return 0
+}
+
+// GetLogConsistencyChainRequest requests consistency proofs linking a series
+// of log roots held by the client to the latest log root.
+type GetLogConsistencyChainRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// tree_sizes are the sizes of the log roots held by the client, in
+	// increasing order.
+	TreeSizes []int64 `protobuf:"varint,2,rep,packed,name=tree_sizes,json=treeSizes" json:"tree_sizes,omitempty"`
+}
+
+func (m *GetLogConsistencyChainRequest) Reset()                    { *m = GetLogConsistencyChainRequest{} }
+func (m *GetLogConsistencyChainRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetLogConsistencyChainRequest) ProtoMessage()               {}
+func (*GetLogConsistencyChainRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *GetLogConsistencyChainRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *GetLogConsistencyChainRequest) GetTreeSizes() []int64 {
+	if m != nil {
+		return m.TreeSizes
+	}
+	return nil
+}
+
+// LogConsistencyProof proves that the log at second_tree_size is an append
+// only extension of the log at first_tree_size.
+type LogConsistencyProof struct {
+	// first_tree_size is the size of the older log root.
+	FirstTreeSize int64 `protobuf:"varint,1,opt,name=first_tree_size,json=firstTreeSize" json:"first_tree_size,omitempty"`
+	// second_tree_size is the size of the newer log root.
+	SecondTreeSize int64 `protobuf:"varint,2,opt,name=second_tree_size,json=secondTreeSize" json:"second_tree_size,omitempty"`
+	// hashes is the consistency proof.
+	Hashes [][]byte `protobuf:"bytes,3,rep,name=hashes,proto3" json:"hashes,omitempty"`
+}
+
+func (m *LogConsistencyProof) Reset()                    { *m = LogConsistencyProof{} }
+func (m *LogConsistencyProof) String() string            { return proto.CompactTextString(m) }
+func (*LogConsistencyProof) ProtoMessage()               {}
+func (*LogConsistencyProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *LogConsistencyProof) GetFirstTreeSize() int64 {
+	if m != nil {
+		return m.FirstTreeSize
+	}
+	return 0
+}
+
+func (m *LogConsistencyProof) GetSecondTreeSize() int64 {
+	if m != nil {
+		return m.SecondTreeSize
+	}
+	return 0
+}
+
+func (m *LogConsistencyProof) GetHashes() [][]byte {
+	if m != nil {
+		return m.Hashes
+	}
+	return nil
+}
+
+// LogConsistencyChain links consecutive log roots to the latest log root.
+type LogConsistencyChain struct {
+	// log_root is the latest log root.
+	LogRoot *trillian.SignedLogRoot `protobuf:"bytes,1,opt,name=log_root,json=logRoot" json:"log_root,omitempty"`
+	// proofs contains one proof for each consecutive pair of requested tree
+	// sizes, followed by a proof from the last requested size to log_root.
+	Proofs []*LogConsistencyProof `protobuf:"bytes,2,rep,name=proofs" json:"proofs,omitempty"`
+}
+
+func (m *LogConsistencyChain) Reset()                    { *m = LogConsistencyChain{} }
+func (m *LogConsistencyChain) String() string            { return proto.CompactTextString(m) }
+func (*LogConsistencyChain) ProtoMessage()               {}
+func (*LogConsistencyChain) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *LogConsistencyChain) GetLogRoot() *trillian.SignedLogRoot {
+	if m != nil {
+		return m.LogRoot
+	}
+	return nil
+}
+
+func (m *LogConsistencyChain) GetProofs() []*LogConsistencyProof {
+	if m != nil {
+		return m.Proofs
+	}
+	return nil
+}
+
+// ListUserAppsRequest lists the apps that a user has entries for.
+type ListUserAppsRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// user_id is the user whose apps are listed. It must match the
+	// authenticated caller.
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+}
+
+func (m *ListUserAppsRequest) Reset()                    { *m = ListUserAppsRequest{} }
+func (m *ListUserAppsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListUserAppsRequest) ProtoMessage()               {}
+func (*ListUserAppsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *ListUserAppsRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *ListUserAppsRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+// ListUserAppsResponse contains the apps that a user has entries for.
+type ListUserAppsResponse struct {
+	// app_ids are the apps that the key server has accepted updates for.
+	// Clients should verify the entry of each app with GetEntry.
+	AppIds []string `protobuf:"bytes,1,rep,name=app_ids,json=appIds" json:"app_ids,omitempty"`
+}
+
+func (m *ListUserAppsResponse) Reset()                    { *m = ListUserAppsResponse{} }
+func (m *ListUserAppsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListUserAppsResponse) ProtoMessage()               {}
+func (*ListUserAppsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *ListUserAppsResponse) GetAppIds() []string {
+	if m != nil {
+		return m.AppIds
+	}
+	return nil
+}
+
+// QuotaViolation is attached to the RESOURCE_EXHAUSTED errors returned by
+// UpdateEntry when a mutation quota of the domain is used up.
+type QuotaViolation struct {
+	// quota is the name of the exhausted MutationQuota field.
+	Quota string `protobuf:"bytes,1,opt,name=quota" json:"quota,omitempty"`
+	// limit is the configured value of the quota.
+	Limit int32 `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+	// retry_after is how long the client should wait before retrying.
+	RetryAfter *google_protobuf1.Duration `protobuf:"bytes,3,opt,name=retry_after,json=retryAfter" json:"retry_after,omitempty"`
+}
+
+func (m *QuotaViolation) Reset()                    { *m = QuotaViolation{} }
+func (m *QuotaViolation) String() string            { return proto.CompactTextString(m) }
+func (*QuotaViolation) ProtoMessage()               {}
+func (*QuotaViolation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *QuotaViolation) GetQuota() string {
+	if m != nil {
+		return m.Quota
+	}
+	return ""
+}
+
+func (m *QuotaViolation) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *QuotaViolation) GetRetryAfter() *google_protobuf1.Duration {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return nil
+}
+
+// TrustAnchor is the domain material a client pins when it first uses a
+// domain. All later responses are verified against it.
+type TrustAnchor struct {
+	// domain contains the public keys and tree parameters that were pinned.
+	Domain *Domain `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
+	// log_root is the log root that was verified when the domain was pinned.
+	LogRoot *trillian.SignedLogRoot `protobuf:"bytes,2,opt,name=log_root,json=logRoot" json:"log_root,omitempty"`
+	// fingerprint is the numeric fingerprint of domain the user confirmed.
+	Fingerprint string `protobuf:"bytes,3,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	// pinned_time is when the user confirmed the fingerprint, or when the
+	// client followed migration.
+	PinnedTime *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=pinned_time,json=pinnedTime" json:"pinned_time,omitempty"`
+	// address is the address of the key server that serves the domain. The
+	// address the client was configured with is used if it is empty.
+	Address string `protobuf:"bytes,5,opt,name=address" json:"address,omitempty"`
+	// start_revision is the first map revision that is verified with domain.
+	StartRevision int64 `protobuf:"varint,6,opt,name=start_revision,json=startRevision" json:"start_revision,omitempty"`
+	// migration is the announcement that the client followed to pin domain.
+	Migration *SignedDomainMigration `protobuf:"bytes,7,opt,name=migration" json:"migration,omitempty"`
+	// history holds the anchors that were replaced by migrations, newest
+	// first. Each verifies the map revisions from its start_revision up to the
+	// start_revision of the anchor that replaced it.
+	History []*TrustAnchor `protobuf:"bytes,8,rep,name=history" json:"history,omitempty"`
+}
+
+func (m *TrustAnchor) Reset()                    { *m = TrustAnchor{} }
+func (m *TrustAnchor) String() string            { return proto.CompactTextString(m) }
+func (*TrustAnchor) ProtoMessage()               {}
+func (*TrustAnchor) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *TrustAnchor) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *TrustAnchor) GetLogRoot() *trillian.SignedLogRoot {
+	if m != nil {
+		return m.LogRoot
+	}
+	return nil
+}
+
+func (m *TrustAnchor) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+func (m *TrustAnchor) GetPinnedTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.PinnedTime
+	}
+	return nil
+}
+
+func (m *TrustAnchor) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TrustAnchor) GetStartRevision() int64 {
+	if m != nil {
+		return m.StartRevision
+	}
+	return 0
+}
+
+func (m *TrustAnchor) GetMigration() *SignedDomainMigration {
+	if m != nil {
+		return m.Migration
+	}
+	return nil
+}
+
+func (m *TrustAnchor) GetHistory() []*TrustAnchor {
+	if m != nil {
+		return m.History
+	}
+	return nil
+}
+
+// WatchKeyChangesRequest subscribes the authenticated user to changes of
+// their own entry.
+type WatchKeyChangesRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user whose entry is watched. It must be the authenticated
+	// user.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// start_epoch is the first epoch to report changes from. Changes published
+	// after the request are reported if it is 0.
+	StartEpoch int64 `protobuf:"varint,4,opt,name=start_epoch,json=startEpoch" json:"start_epoch,omitempty"`
+}
+
+func (m *WatchKeyChangesRequest) Reset()                    { *m = WatchKeyChangesRequest{} }
+func (m *WatchKeyChangesRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchKeyChangesRequest) ProtoMessage()               {}
+func (*WatchKeyChangesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *WatchKeyChangesRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *WatchKeyChangesRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *WatchKeyChangesRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *WatchKeyChangesRequest) GetStartEpoch() int64 {
+	if m != nil {
+		return m.StartEpoch
+	}
+	return 0
+}
+
+// KeyChangeEvent reports that a user's entry changed in a published epoch.
+type KeyChangeEvent struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user whose entry changed.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// epoch is the epoch that published the change.
+	Epoch int64 `protobuf:"varint,4,opt,name=epoch" json:"epoch,omitempty"`
+	// mutation is the change that was applied to the entry. Clients should
+	// verify it with GetEntry before acting on it.
+	Mutation *Entry `protobuf:"bytes,5,opt,name=mutation" json:"mutation,omitempty"`
+	// epoch_time is when the epoch was created.
+	EpochTime *google_protobuf2.Timestamp `protobuf:"bytes,6,opt,name=epoch_time,json=epochTime" json:"epoch_time,omitempty"`
+}
+
+func (m *KeyChangeEvent) Reset()                    { *m = KeyChangeEvent{} }
+func (m *KeyChangeEvent) String() string            { return proto.CompactTextString(m) }
+func (*KeyChangeEvent) ProtoMessage()               {}
+func (*KeyChangeEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *KeyChangeEvent) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *KeyChangeEvent) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *KeyChangeEvent) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *KeyChangeEvent) GetEpoch() int64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *KeyChangeEvent) GetMutation() *Entry {
+	if m != nil {
+		return m.Mutation
+	}
+	return nil
+}
+
+func (m *KeyChangeEvent) GetEpochTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.EpochTime
+	}
+	return nil
+}
+
+// SetKeyChangeWebhookRequest registers a webhook that receives a
+// KeyChangeEvent, as JSON, for every change of the authenticated user's
+// entry.
+type SetKeyChangeWebhookRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user whose entry is watched. It must be the authenticated
+	// user.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// url is the https URL events are POSTed to. An empty url removes the
+	// webhook.
+	Url string `protobuf:"bytes,4,opt,name=url" json:"url,omitempty"`
+}
+
+func (m *SetKeyChangeWebhookRequest) Reset()                    { *m = SetKeyChangeWebhookRequest{} }
+func (m *SetKeyChangeWebhookRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetKeyChangeWebhookRequest) ProtoMessage()               {}
+func (*SetKeyChangeWebhookRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *SetKeyChangeWebhookRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *SetKeyChangeWebhookRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *SetKeyChangeWebhookRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *SetKeyChangeWebhookRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+// SetKeyChangeWebhookResponse is the response to SetKeyChangeWebhook.
+type SetKeyChangeWebhookResponse struct {
+	// start_epoch is the first epoch whose changes will be delivered.
+	StartEpoch int64 `protobuf:"varint,1,opt,name=start_epoch,json=startEpoch" json:"start_epoch,omitempty"`
+}
+
+func (m *SetKeyChangeWebhookResponse) Reset()                    { *m = SetKeyChangeWebhookResponse{} }
+func (m *SetKeyChangeWebhookResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetKeyChangeWebhookResponse) ProtoMessage()               {}
+func (*SetKeyChangeWebhookResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *SetKeyChangeWebhookResponse) GetStartEpoch() int64 {
+	if m != nil {
+		return m.StartEpoch
+	}
+	return 0
+}
+
+// GetEntryAtRevisionRequest requests a user's entry as of a past map
+// revision.
+type GetEntryAtRevisionRequest struct {
+	// domain_id identifies the domain in which the user and application live.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// user_id is the user identifier.
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// app_id is the identifier for the application.
+	AppId string `protobuf:"bytes,3,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// revision is the map revision to read the entry at.
+	Revision int64 `protobuf:"varint,4,opt,name=revision" json:"revision,omitempty"`
+	// first_tree_size is the tree_size of the currently trusted log root.
+	// Omitting this field will omit the log consistency proof from the response.
+	FirstTreeSize int64 `protobuf:"varint,5,opt,name=first_tree_size,json=firstTreeSize" json:"first_tree_size,omitempty"`
+}
+
+func (m *GetEntryAtRevisionRequest) Reset()                    { *m = GetEntryAtRevisionRequest{} }
+func (m *GetEntryAtRevisionRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetEntryAtRevisionRequest) ProtoMessage()               {}
+func (*GetEntryAtRevisionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *GetEntryAtRevisionRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *GetEntryAtRevisionRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *GetEntryAtRevisionRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *GetEntryAtRevisionRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *GetEntryAtRevisionRequest) GetFirstTreeSize() int64 {
+	if m != nil {
+		return m.FirstTreeSize
+	}
+	return 0
+}
+
+// Permalink identifies the value of an entry at a map revision.
+type Permalink struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id identifies the user.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// revision is the map revision the entry was read at.
+	Revision int64 `protobuf:"varint,4,opt,name=revision" json:"revision,omitempty"`
+	// proof_digest is the SHA256 hash of the map root hash at revision
+	// followed by the leaf value of the entry.
+	ProofDigest []byte `protobuf:"bytes,5,opt,name=proof_digest,json=proofDigest,proto3" json:"proof_digest,omitempty"`
+	// issue_time is when the permalink was created.
+	IssueTime *google_protobuf2.Timestamp `protobuf:"bytes,6,opt,name=issue_time,json=issueTime" json:"issue_time,omitempty"`
+}
+
+func (m *Permalink) Reset()                    { *m = Permalink{} }
+func (m *Permalink) String() string            { return proto.CompactTextString(m) }
+func (*Permalink) ProtoMessage()               {}
+func (*Permalink) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *Permalink) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *Permalink) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *Permalink) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Permalink) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *Permalink) GetProofDigest() []byte {
+	if m != nil {
+		return m.ProofDigest
+	}
+	return nil
+}
+
+func (m *Permalink) GetIssueTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.IssueTime
+	}
+	return nil
+}
+
+// SignedPermalink is a Permalink signed by the key server. Permalink tokens
+// are the unpadded base64url encoding of a serialized SignedPermalink.
+type SignedPermalink struct {
+	// permalink is a serialized Permalink.
+	Permalink []byte `protobuf:"bytes,1,opt,name=permalink,proto3" json:"permalink,omitempty"`
+	// signature is the key server's signature of permalink.
+	Signature *sigpb.DigitallySigned `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *SignedPermalink) Reset()                    { *m = SignedPermalink{} }
+func (m *SignedPermalink) String() string            { return proto.CompactTextString(m) }
+func (*SignedPermalink) ProtoMessage()               {}
+func (*SignedPermalink) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *SignedPermalink) GetPermalink() []byte {
+	if m != nil {
+		return m.Permalink
+	}
+	return nil
+}
+
+func (m *SignedPermalink) GetSignature() *sigpb.DigitallySigned {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// TransparencySnapshot holds the verified entries of selected users at a
+// single epoch, together with everything needed to verify them offline. It
+// is meant to be published on static sites.
+type TransparencySnapshot struct {
+	// domain contains the public keys and tree parameters used for verification.
+	Domain *Domain `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
+	// epoch is the map root of the snapshot and its log proofs.
+	Epoch *Epoch `protobuf:"bytes,2,opt,name=epoch" json:"epoch,omitempty"`
+	// entries are the entries of the selected users at epoch.
+	Entries []*SnapshotEntry `protobuf:"bytes,3,rep,name=entries" json:"entries,omitempty"`
+	// create_time is when the snapshot was generated.
+	CreateTime *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=create_time,json=createTime" json:"create_time,omitempty"`
+}
+
+func (m *TransparencySnapshot) Reset()                    { *m = TransparencySnapshot{} }
+func (m *TransparencySnapshot) String() string            { return proto.CompactTextString(m) }
+func (*TransparencySnapshot) ProtoMessage()               {}
+func (*TransparencySnapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *TransparencySnapshot) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *TransparencySnapshot) GetEpoch() *Epoch {
+	if m != nil {
+		return m.Epoch
+	}
+	return nil
+}
+
+func (m *TransparencySnapshot) GetEntries() []*SnapshotEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *TransparencySnapshot) GetCreateTime() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+// SnapshotEntry is the entry of one user in a TransparencySnapshot.
+type SnapshotEntry struct {
+	// app_id is the application the user belongs to.
+	AppId string `protobuf:"bytes,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user identifier.
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// entry is the user's entry, or proof of absence, and its proofs.
+	Entry *GetEntryResponse `protobuf:"bytes,3,opt,name=entry" json:"entry,omitempty"`
+}
+
+func (m *SnapshotEntry) Reset()                    { *m = SnapshotEntry{} }
+func (m *SnapshotEntry) String() string            { return proto.CompactTextString(m) }
+func (*SnapshotEntry) ProtoMessage()               {}
+func (*SnapshotEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *SnapshotEntry) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *SnapshotEntry) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *SnapshotEntry) GetEntry() *GetEntryResponse {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+// CreatePermalinkRequest requests a permalink to a user's entry.
+type CreatePermalinkRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id identifies the user.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// revision is the map revision to link to. The latest revision is used if
+	// it is 0.
+	Revision int64 `protobuf:"varint,4,opt,name=revision" json:"revision,omitempty"`
+}
+
+func (m *CreatePermalinkRequest) Reset()                    { *m = CreatePermalinkRequest{} }
+func (m *CreatePermalinkRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreatePermalinkRequest) ProtoMessage()               {}
+func (*CreatePermalinkRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *CreatePermalinkRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *CreatePermalinkRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *CreatePermalinkRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *CreatePermalinkRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+// CreatePermalinkResponse contains a signed permalink.
+type CreatePermalinkResponse struct {
+	// token is the encoded SignedPermalink.
+	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	// permalink is the content of token.
+	Permalink *Permalink `protobuf:"bytes,2,opt,name=permalink" json:"permalink,omitempty"`
+}
+
+func (m *CreatePermalinkResponse) Reset()                    { *m = CreatePermalinkResponse{} }
+func (m *CreatePermalinkResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreatePermalinkResponse) ProtoMessage()               {}
+func (*CreatePermalinkResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *CreatePermalinkResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *CreatePermalinkResponse) GetPermalink() *Permalink {
+	if m != nil {
+		return m.Permalink
+	}
+	return nil
+}
+
+// MapRootEndorsement is an endorser's signature over a SignedMapRoot. The
+// signed message is the log leaf of the map root, the JSON encoding of the
+// SignedMapRoot.
+type MapRootEndorsement struct {
+	// key_id is the SHA-256 hash of the DER encoded public key of the endorser.
+	KeyId []byte `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// signature is the endorser's signature over the map root.
+	Signature *sigpb.DigitallySigned `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *MapRootEndorsement) Reset()                    { *m = MapRootEndorsement{} }
+func (m *MapRootEndorsement) String() string            { return proto.CompactTextString(m) }
+func (*MapRootEndorsement) ProtoMessage()               {}
+func (*MapRootEndorsement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *MapRootEndorsement) GetKeyId() []byte {
+	if m != nil {
+		return m.KeyId
+	}
+	return nil
+}
+
+func (m *MapRootEndorsement) GetSignature() *sigpb.DigitallySigned {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// WatchEntryRequest subscribes to changes of a user's entry.
+type WatchEntryRequest struct {
+	// domain_id identifies the domain.
+	DomainId string `protobuf:"bytes,1,opt,name=domain_id,json=domainId" json:"domain_id,omitempty"`
+	// app_id identifies the application.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user whose entry is watched.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// first_tree_size is the tree size of the client's trusted log root. The
+	// first response proves consistency from it, and each later response proves
+	// consistency from the log root of the previous one.
+	FirstTreeSize int64 `protobuf:"varint,4,opt,name=first_tree_size,json=firstTreeSize" json:"first_tree_size,omitempty"`
+	// start_epoch is the first epoch to report changes from. Changes published
+	// after the request are reported if it is 0.
+	StartEpoch int64 `protobuf:"varint,5,opt,name=start_epoch,json=startEpoch" json:"start_epoch,omitempty"`
+}
+
+func (m *WatchEntryRequest) Reset()                    { *m = WatchEntryRequest{} }
+func (m *WatchEntryRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchEntryRequest) ProtoMessage()               {}
+func (*WatchEntryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *WatchEntryRequest) GetDomainId() string {
+	if m != nil {
+		return m.DomainId
+	}
+	return ""
+}
+
+func (m *WatchEntryRequest) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *WatchEntryRequest) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *WatchEntryRequest) GetFirstTreeSize() int64 {
+	if m != nil {
+		return m.FirstTreeSize
+	}
+	return 0
+}
+
+func (m *WatchEntryRequest) GetStartEpoch() int64 {
+	if m != nil {
+		return m.StartEpoch
+	}
+	return 0
+}
+
+// ProofBundle is a self contained proof of a user's entry that can be
+// verified offline, e.g. as evidence in a dispute or on another device.
+type ProofBundle struct {
+	// domain contains the public keys and tree parameters used for verification.
+	Domain *Domain `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
+	// app_id is the application the user belongs to.
+	AppId string `protobuf:"bytes,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	// user_id is the user identifier.
+	UserId string `protobuf:"bytes,3,opt,name=user_id,json=userId" json:"user_id,omitempty"`
+	// entry is the user's entry and its proofs.
+	Entry *GetEntryResponse `protobuf:"bytes,4,opt,name=entry" json:"entry,omitempty"`
+	// trusted_root is the log root the exporting client trusted. entry proves
+	// that its log root is consistent with trusted_root.
+	TrustedRoot *trillian.SignedLogRoot `protobuf:"bytes,5,opt,name=trusted_root,json=trustedRoot" json:"trusted_root,omitempty"`
+}
+
+func (m *ProofBundle) Reset()                    { *m = ProofBundle{} }
+func (m *ProofBundle) String() string            { return proto.CompactTextString(m) }
+func (*ProofBundle) ProtoMessage()               {}
+func (*ProofBundle) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *ProofBundle) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *ProofBundle) GetAppId() string {
+	if m != nil {
+		return m.AppId
+	}
+	return ""
+}
+
+func (m *ProofBundle) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *ProofBundle) GetEntry() *GetEntryResponse {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+func (m *ProofBundle) GetTrustedRoot() *trillian.SignedLogRoot {
+	if m != nil {
+		return m.TrustedRoot
+	}
+	return nil
+}
+
+// ProfileKey is a public key published in a user profile.
+type ProfileKey struct {
+	// key_id identifies the key, e.g. by the device that holds it. It is
+	// unique within a profile.
+	KeyId string `protobuf:"bytes,1,opt,name=key_id,json=keyId" json:"key_id,omitempty"`
+	// public_key is the key.
+	PublicKey *keyspb.PublicKey `protobuf:"bytes,2,opt,name=public_key,json=publicKey" json:"public_key,omitempty"`
+	// uses lists what the key may be used for, e.g. "encryption" or "signing".
+	Uses []string `protobuf:"bytes,3,rep,name=uses" json:"uses,omitempty"`
+	// expiration is when the key stops being valid. Keys without an
+	// expiration do not expire.
+	Expiration *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=expiration" json:"expiration,omitempty"`
+}
+
+func (m *ProfileKey) Reset()                    { *m = ProfileKey{} }
+func (m *ProfileKey) String() string            { return proto.CompactTextString(m) }
+func (*ProfileKey) ProtoMessage()               {}
+func (*ProfileKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *ProfileKey) GetKeyId() string {
+	if m != nil {
+		return m.KeyId
+	}
+	return ""
+}
+
+func (m *ProfileKey) GetPublicKey() *keyspb.PublicKey {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *ProfileKey) GetUses() []string {
+	if m != nil {
+		return m.Uses
+	}
+	return nil
+}
+
+func (m *ProfileKey) GetExpiration() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Expiration
+	}
+	return nil
+}
+
+// KeyProfile is a profile that holds a set of public keys of a user, e.g.
+// one for each of the user's devices.
+type KeyProfile struct {
+	// keys are the public keys of the user.
+	Keys []*ProfileKey `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *KeyProfile) Reset()                    { *m = KeyProfile{} }
+func (m *KeyProfile) String() string            { return proto.CompactTextString(m) }
+func (*KeyProfile) ProtoMessage()               {}
+func (*KeyProfile) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *KeyProfile) GetKeys() []*ProfileKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Committed)(nil), "google.keytransparency.v1.Committed")
+	proto.RegisterType((*EntryUpdate)(nil), "google.keytransparency.v1.EntryUpdate")
+	proto.RegisterType((*Entry)(nil), "google.keytransparency.v1.Entry")
+	proto.RegisterType((*MutationProof)(nil), "google.keytransparency.v1.MutationProof")
+	proto.RegisterType((*MapperMetadata)(nil), "google.keytransparency.v1.MapperMetadata")
+	proto.RegisterType((*UserProfile)(nil), "google.keytransparency.v1.UserProfile")
+	proto.RegisterType((*GetEntryRequest)(nil), "google.keytransparency.v1.GetEntryRequest")
+	proto.RegisterType((*GetEntryResponse)(nil), "google.keytransparency.v1.GetEntryResponse")
+	proto.RegisterType((*ListEntryHistoryRequest)(nil), "google.keytransparency.v1.ListEntryHistoryRequest")
+	proto.RegisterType((*ListEntryHistoryResponse)(nil), "google.keytransparency.v1.ListEntryHistoryResponse")
+	proto.RegisterType((*UpdateEntryRequest)(nil), "google.keytransparency.v1.UpdateEntryRequest")
+	proto.RegisterType((*UpdateEntryResponse)(nil), "google.keytransparency.v1.UpdateEntryResponse")
+	proto.RegisterType((*GetEpochRequest)(nil), "google.keytransparency.v1.GetEpochRequest")
+	proto.RegisterType((*GetLatestEpochRequest)(nil), "google.keytransparency.v1.GetLatestEpochRequest")
+	proto.RegisterType((*Epoch)(nil), "google.keytransparency.v1.Epoch")
+	proto.RegisterType((*ListMutationsRequest)(nil), "google.keytransparency.v1.ListMutationsRequest")
+	proto.RegisterType((*ListMutationsResponse)(nil), "google.keytransparency.v1.ListMutationsResponse")
+	proto.RegisterType((*GetServerCapabilitiesRequest)(nil), "google.keytransparency.v1.GetServerCapabilitiesRequest")
+	proto.RegisterType((*ServerCapabilities)(nil), "google.keytransparency.v1.ServerCapabilities")
+	proto.RegisterType((*EpochProof)(nil), "google.keytransparency.v1.EpochProof")
+	proto.RegisterType((*UserProofArchive)(nil), "google.keytransparency.v1.UserProofArchive")
+	proto.RegisterType((*GetServerVersionRequest)(nil), "google.keytransparency.v1.GetServerVersionRequest")
+	proto.RegisterType((*ServerVersion)(nil), "google.keytransparency.v1.ServerVersion")
+	proto.RegisterType((*GetDomainStatsRequest)(nil), "google.keytransparency.v1.GetDomainStatsRequest")
+	proto.RegisterType((*DomainStats)(nil), "google.keytransparency.v1.DomainStats")
+	proto.RegisterType((*GetLogConsistencyChainRequest)(nil), "google.keytransparency.v1.GetLogConsistencyChainRequest")
+	proto.RegisterType((*LogConsistencyProof)(nil), "google.keytransparency.v1.LogConsistencyProof")
+	proto.RegisterType((*LogConsistencyChain)(nil), "google.keytransparency.v1.LogConsistencyChain")
+	proto.RegisterType((*ListUserAppsRequest)(nil), "google.keytransparency.v1.ListUserAppsRequest")
+	proto.RegisterType((*ListUserAppsResponse)(nil), "google.keytransparency.v1.ListUserAppsResponse")
+	proto.RegisterType((*QuotaViolation)(nil), "google.keytransparency.v1.QuotaViolation")
+	proto.RegisterType((*TrustAnchor)(nil), "google.keytransparency.v1.TrustAnchor")
+	proto.RegisterType((*WatchKeyChangesRequest)(nil), "google.keytransparency.v1.WatchKeyChangesRequest")
+	proto.RegisterType((*KeyChangeEvent)(nil), "google.keytransparency.v1.KeyChangeEvent")
+	proto.RegisterType((*SetKeyChangeWebhookRequest)(nil), "google.keytransparency.v1.SetKeyChangeWebhookRequest")
+	proto.RegisterType((*SetKeyChangeWebhookResponse)(nil), "google.keytransparency.v1.SetKeyChangeWebhookResponse")
+	proto.RegisterType((*GetEntryAtRevisionRequest)(nil), "google.keytransparency.v1.GetEntryAtRevisionRequest")
+	proto.RegisterType((*Permalink)(nil), "google.keytransparency.v1.Permalink")
+	proto.RegisterType((*SignedPermalink)(nil), "google.keytransparency.v1.SignedPermalink")
+	proto.RegisterType((*TransparencySnapshot)(nil), "google.keytransparency.v1.TransparencySnapshot")
+	proto.RegisterType((*SnapshotEntry)(nil), "google.keytransparency.v1.SnapshotEntry")
+	proto.RegisterType((*CreatePermalinkRequest)(nil), "google.keytransparency.v1.CreatePermalinkRequest")
+	proto.RegisterType((*CreatePermalinkResponse)(nil), "google.keytransparency.v1.CreatePermalinkResponse")
+	proto.RegisterType((*MapRootEndorsement)(nil), "google.keytransparency.v1.MapRootEndorsement")
+	proto.RegisterType((*WatchEntryRequest)(nil), "google.keytransparency.v1.WatchEntryRequest")
+	proto.RegisterType((*ProofBundle)(nil), "google.keytransparency.v1.ProofBundle")
+	proto.RegisterType((*ProfileKey)(nil), "google.keytransparency.v1.ProfileKey")
+	proto.RegisterType((*KeyProfile)(nil), "google.keytransparency.v1.KeyProfile")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for KeyTransparency service
+
+type KeyTransparencyClient interface {
+	// GetDomain returns the information needed to verify the specified domain.
+	GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error)
+	// GetEpoch returns a SignedMapRoot by the by the requested revision number
+	// along with its inclusion proof in the log and the log's consistency proofs.
+	GetEpoch(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (*Epoch, error)
+	// GetLatestEpoch returns the latest SignedMapRoot along with its inclusion
+	// proof in the log and the log's consistency proofs.
+	GetLatestEpoch(ctx context.Context, in *GetLatestEpochRequest, opts ...grpc.CallOption) (*Epoch, error)
+	// GetEpochStream streams new epochs from a requested starting point
+	// and continues as new epochs are created.
+	GetEpochStream(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (KeyTransparency_GetEpochStreamClient, error)
+	// ListMutations returns a list of mutations in a specific epoch.
+	ListMutations(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (*ListMutationsResponse, error)
+	// ListMutationsStream is a streaming list of mutations in a specific epoch.
+	ListMutationsStream(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (KeyTransparency_ListMutationsStreamClient, error)
+	// GetEntry returns a user's entry in the Merkle Tree.
+	//
+	// Entries contain signed commitments to a profile, which is also returned.
+	// TODO(gbelvin): Replace with GetUser
+	GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error)
+	// ListEntryHistory returns a list of historic GetEntry values.
+	//
+	// Clients verify their account history by observing correct values for their
+	// account over time.
+	ListEntryHistory(ctx context.Context, in *ListEntryHistoryRequest, opts ...grpc.CallOption) (*ListEntryHistoryResponse, error)
+	// UpdateEntry updates a user's profile.
+	//
+	// Returns the current user profile.
+	// Clients must retry until this function returns a proof containing the desired value.
+	UpdateEntry(ctx context.Context, in *UpdateEntryRequest, opts ...grpc.CallOption) (*UpdateEntryResponse, error)
+	// GetServerCapabilities returns the server's current time and capabilities.
+	//
+	// Clients use server_time to detect local clock skew before relying on
+	// freshness checks.
+	GetServerCapabilities(ctx context.Context, in *GetServerCapabilitiesRequest, opts ...grpc.CallOption) (*ServerCapabilities, error)
+	// GetServerVersion returns the version and binary digest of the server.
+	GetServerVersion(ctx context.Context, in *GetServerVersionRequest, opts ...grpc.CallOption) (*ServerVersion, error)
+	// GetDomainStats returns aggregate, anonymized statistics about a domain.
+	GetDomainStats(ctx context.Context, in *GetDomainStatsRequest, opts ...grpc.CallOption) (*DomainStats, error)
+	// GetLogConsistencyChain returns the consistency proofs between consecutive
+	// log roots in a single response.
+	//
+	// Monitors and clients catching up on many epochs use this to verify all of
+	// the log roots they hold in one round trip.
+	GetLogConsistencyChain(ctx context.Context, in *GetLogConsistencyChainRequest, opts ...grpc.CallOption) (*LogConsistencyChain, error)
+	// ListUserApps returns the apps that the authenticated user has entries
+	// for, so that users can audit which applications hold keys under their
+	// identity.
+	//
+	// Returns FAILED_PRECONDITION unless app listing is enabled for the domain.
+	ListUserApps(ctx context.Context, in *ListUserAppsRequest, opts ...grpc.CallOption) (*ListUserAppsResponse, error)
+	// WatchKeyChanges streams the changes to the authenticated user's own
+	// entry as they are published, so that unexpected key changes are noticed
+	// quickly.
+	WatchKeyChanges(ctx context.Context, in *WatchKeyChangesRequest, opts ...grpc.CallOption) (KeyTransparency_WatchKeyChangesClient, error)
+	// SetKeyChangeWebhook registers a webhook that is notified of changes to
+	// the authenticated user's own entry, for owners whose devices are often
+	// offline.
+	//
+	// Returns UNIMPLEMENTED if the server does not deliver webhooks.
+	SetKeyChangeWebhook(ctx context.Context, in *SetKeyChangeWebhookRequest, opts ...grpc.CallOption) (*SetKeyChangeWebhookResponse, error)
+	// GetEntryAtRevision returns a user's entry as of a past map revision,
+	// together with proofs linking it to the latest log root.
+	GetEntryAtRevision(ctx context.Context, in *GetEntryAtRevisionRequest, opts ...grpc.CallOption) (*GetEntryResponse, error)
+	// CreatePermalink returns a signed token that refers to a user's entry at
+	// a map revision. Anyone can resolve the token later with
+	// GetEntryAtRevision and check that the entry still verifies.
+	//
+	// Returns UNIMPLEMENTED if the server has no signing key.
+	CreatePermalink(ctx context.Context, in *CreatePermalinkRequest, opts ...grpc.CallOption) (*CreatePermalinkResponse, error)
+	// WatchEntry streams a user's entry, with proofs, each time it changes in
+	// a published epoch, so that clients do not have to poll GetEntry.
+	WatchEntry(ctx context.Context, in *WatchEntryRequest, opts ...grpc.CallOption) (KeyTransparency_WatchEntryClient, error)
+}
+
+type keyTransparencyClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewKeyTransparencyClient(cc *grpc.ClientConn) KeyTransparencyClient {
+	return &keyTransparencyClient{cc}
+}
+
+func (c *keyTransparencyClient) GetDomain(ctx context.Context, in *GetDomainRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetEpoch(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (*Epoch, error) {
+	out := new(Epoch)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetEpoch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetLatestEpoch(ctx context.Context, in *GetLatestEpochRequest, opts ...grpc.CallOption) (*Epoch, error) {
+	out := new(Epoch)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetLatestEpoch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetEpochStream(ctx context.Context, in *GetEpochRequest, opts ...grpc.CallOption) (KeyTransparency_GetEpochStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[0], c.cc, "/google.keytransparency.v1.KeyTransparency/GetEpochStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyGetEpochStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparency_GetEpochStreamClient interface {
+	Recv() (*Epoch, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyGetEpochStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyGetEpochStreamClient) Recv() (*Epoch, error) {
+	m := new(Epoch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keyTransparencyClient) ListMutations(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (*ListMutationsResponse, error) {
+	out := new(ListMutationsResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/ListMutations", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) ListMutationsStream(ctx context.Context, in *ListMutationsRequest, opts ...grpc.CallOption) (KeyTransparency_ListMutationsStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[1], c.cc, "/google.keytransparency.v1.KeyTransparency/ListMutationsStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyListMutationsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparency_ListMutationsStreamClient interface {
+	Recv() (*MutationProof, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyListMutationsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyListMutationsStreamClient) Recv() (*MutationProof, error) {
+	m := new(MutationProof)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keyTransparencyClient) GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error) {
+	out := new(GetEntryResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetEntry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) ListEntryHistory(ctx context.Context, in *ListEntryHistoryRequest, opts ...grpc.CallOption) (*ListEntryHistoryResponse, error) {
+	out := new(ListEntryHistoryResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/ListEntryHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (c *keyTransparencyClient) UpdateEntry(ctx context.Context, in *UpdateEntryRequest, opts ...grpc.CallOption) (*UpdateEntryResponse, error) {
@@ -931,6 +2471,142 @@ func (c *keyTransparencyClient) UpdateEntry(ctx context.Context, in *UpdateEntry
 	return out, nil
 }
 
+func (c *keyTransparencyClient) GetServerCapabilities(ctx context.Context, in *GetServerCapabilitiesRequest, opts ...grpc.CallOption) (*ServerCapabilities, error) {
+	out := new(ServerCapabilities)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetServerCapabilities", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetServerVersion(ctx context.Context, in *GetServerVersionRequest, opts ...grpc.CallOption) (*ServerVersion, error) {
+	out := new(ServerVersion)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetServerVersion", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetDomainStats(ctx context.Context, in *GetDomainStatsRequest, opts ...grpc.CallOption) (*DomainStats, error) {
+	out := new(DomainStats)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetDomainStats", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetLogConsistencyChain(ctx context.Context, in *GetLogConsistencyChainRequest, opts ...grpc.CallOption) (*LogConsistencyChain, error) {
+	out := new(LogConsistencyChain)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetLogConsistencyChain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) ListUserApps(ctx context.Context, in *ListUserAppsRequest, opts ...grpc.CallOption) (*ListUserAppsResponse, error) {
+	out := new(ListUserAppsResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/ListUserApps", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) WatchKeyChanges(ctx context.Context, in *WatchKeyChangesRequest, opts ...grpc.CallOption) (KeyTransparency_WatchKeyChangesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[2], c.cc, "/google.keytransparency.v1.KeyTransparency/WatchKeyChanges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyWatchKeyChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparency_WatchKeyChangesClient interface {
+	Recv() (*KeyChangeEvent, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyWatchKeyChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyWatchKeyChangesClient) Recv() (*KeyChangeEvent, error) {
+	m := new(KeyChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keyTransparencyClient) SetKeyChangeWebhook(ctx context.Context, in *SetKeyChangeWebhookRequest, opts ...grpc.CallOption) (*SetKeyChangeWebhookResponse, error) {
+	out := new(SetKeyChangeWebhookResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/SetKeyChangeWebhook", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) GetEntryAtRevision(ctx context.Context, in *GetEntryAtRevisionRequest, opts ...grpc.CallOption) (*GetEntryResponse, error) {
+	out := new(GetEntryResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/GetEntryAtRevision", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) CreatePermalink(ctx context.Context, in *CreatePermalinkRequest, opts ...grpc.CallOption) (*CreatePermalinkResponse, error) {
+	out := new(CreatePermalinkResponse)
+	err := grpc.Invoke(ctx, "/google.keytransparency.v1.KeyTransparency/CreatePermalink", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyTransparencyClient) WatchEntry(ctx context.Context, in *WatchEntryRequest, opts ...grpc.CallOption) (KeyTransparency_WatchEntryClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KeyTransparency_serviceDesc.Streams[3], c.cc, "/google.keytransparency.v1.KeyTransparency/WatchEntry", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keyTransparencyWatchEntryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeyTransparency_WatchEntryClient interface {
+	Recv() (*GetEntryResponse, error)
+	grpc.ClientStream
+}
+
+type keyTransparencyWatchEntryClient struct {
+	grpc.ClientStream
+}
+
+func (x *keyTransparencyWatchEntryClient) Recv() (*GetEntryResponse, error) {
+	m := new(GetEntryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for KeyTransparency service
 
 type KeyTransparencyServer interface {
@@ -964,6 +2640,49 @@ type KeyTransparencyServer interface {
 	// Returns the current user profile.
 	// Clients must retry until this function returns a proof containing the desired value.
 	UpdateEntry(context.Context, *UpdateEntryRequest) (*UpdateEntryResponse, error)
+	// GetServerCapabilities returns the server's current time and capabilities.
+	//
+	// Clients use server_time to detect local clock skew before relying on
+	// freshness checks.
+	GetServerCapabilities(context.Context, *GetServerCapabilitiesRequest) (*ServerCapabilities, error)
+	// GetServerVersion returns the version and binary digest of the server.
+	GetServerVersion(context.Context, *GetServerVersionRequest) (*ServerVersion, error)
+	// GetDomainStats returns aggregate, anonymized statistics about a domain.
+	GetDomainStats(context.Context, *GetDomainStatsRequest) (*DomainStats, error)
+	// GetLogConsistencyChain returns the consistency proofs between consecutive
+	// log roots in a single response.
+	//
+	// Monitors and clients catching up on many epochs use this to verify all of
+	// the log roots they hold in one round trip.
+	GetLogConsistencyChain(context.Context, *GetLogConsistencyChainRequest) (*LogConsistencyChain, error)
+	// ListUserApps returns the apps that the authenticated user has entries
+	// for, so that users can audit which applications hold keys under their
+	// identity.
+	//
+	// Returns FAILED_PRECONDITION unless app listing is enabled for the domain.
+	ListUserApps(context.Context, *ListUserAppsRequest) (*ListUserAppsResponse, error)
+	// WatchKeyChanges streams the changes to the authenticated user's own
+	// entry as they are published, so that unexpected key changes are noticed
+	// quickly.
+	WatchKeyChanges(*WatchKeyChangesRequest, KeyTransparency_WatchKeyChangesServer) error
+	// SetKeyChangeWebhook registers a webhook that is notified of changes to
+	// the authenticated user's own entry, for owners whose devices are often
+	// offline.
+	//
+	// Returns UNIMPLEMENTED if the server does not deliver webhooks.
+	SetKeyChangeWebhook(context.Context, *SetKeyChangeWebhookRequest) (*SetKeyChangeWebhookResponse, error)
+	// GetEntryAtRevision returns a user's entry as of a past map revision,
+	// together with proofs linking it to the latest log root.
+	GetEntryAtRevision(context.Context, *GetEntryAtRevisionRequest) (*GetEntryResponse, error)
+	// CreatePermalink returns a signed token that refers to a user's entry at
+	// a map revision. Anyone can resolve the token later with
+	// GetEntryAtRevision and check that the entry still verifies.
+	//
+	// Returns UNIMPLEMENTED if the server has no signing key.
+	CreatePermalink(context.Context, *CreatePermalinkRequest) (*CreatePermalinkResponse, error)
+	// WatchEntry streams a user's entry, with proofs, each time it changes in
+	// a published epoch, so that clients do not have to poll GetEntry.
+	WatchEntry(*WatchEntryRequest, KeyTransparency_WatchEntryServer) error
 }
 
 func RegisterKeyTransparencyServer(s *grpc.Server, srv KeyTransparencyServer) {
@@ -1138,6 +2857,192 @@ func _KeyTransparency_UpdateEntry_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KeyTransparency_GetServerCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).GetServerCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/GetServerCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).GetServerCapabilities(ctx, req.(*GetServerCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_GetServerVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).GetServerVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/GetServerVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).GetServerVersion(ctx, req.(*GetServerVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_GetDomainStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDomainStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).GetDomainStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/GetDomainStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).GetDomainStats(ctx, req.(*GetDomainStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_GetLogConsistencyChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogConsistencyChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).GetLogConsistencyChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/GetLogConsistencyChain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).GetLogConsistencyChain(ctx, req.(*GetLogConsistencyChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_ListUserApps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserAppsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).ListUserApps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/ListUserApps",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).ListUserApps(ctx, req.(*ListUserAppsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_WatchKeyChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchKeyChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeyTransparencyServer).WatchKeyChanges(m, &keyTransparencyWatchKeyChangesServer{stream})
+}
+
+type KeyTransparency_WatchKeyChangesServer interface {
+	Send(*KeyChangeEvent) error
+	grpc.ServerStream
+}
+
+type keyTransparencyWatchKeyChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *keyTransparencyWatchKeyChangesServer) Send(m *KeyChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _KeyTransparency_SetKeyChangeWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetKeyChangeWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).SetKeyChangeWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/SetKeyChangeWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).SetKeyChangeWebhook(ctx, req.(*SetKeyChangeWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_GetEntryAtRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEntryAtRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).GetEntryAtRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/GetEntryAtRevision",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).GetEntryAtRevision(ctx, req.(*GetEntryAtRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_CreatePermalink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePermalinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyTransparencyServer).CreatePermalink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/google.keytransparency.v1.KeyTransparency/CreatePermalink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyTransparencyServer).CreatePermalink(ctx, req.(*CreatePermalinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyTransparency_WatchEntry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEntryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeyTransparencyServer).WatchEntry(m, &keyTransparencyWatchEntryServer{stream})
+}
+
+type KeyTransparency_WatchEntryServer interface {
+	Send(*GetEntryResponse) error
+	grpc.ServerStream
+}
+
+type keyTransparencyWatchEntryServer struct {
+	grpc.ServerStream
+}
+
+func (x *keyTransparencyWatchEntryServer) Send(m *GetEntryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _KeyTransparency_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "google.keytransparency.v1.KeyTransparency",
 	HandlerType: (*KeyTransparencyServer)(nil),
@@ -1170,6 +3075,38 @@ var _KeyTransparency_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateEntry",
 			Handler:    _KeyTransparency_UpdateEntry_Handler,
 		},
+		{
+			MethodName: "GetServerCapabilities",
+			Handler:    _KeyTransparency_GetServerCapabilities_Handler,
+		},
+		{
+			MethodName: "GetServerVersion",
+			Handler:    _KeyTransparency_GetServerVersion_Handler,
+		},
+		{
+			MethodName: "GetDomainStats",
+			Handler:    _KeyTransparency_GetDomainStats_Handler,
+		},
+		{
+			MethodName: "GetLogConsistencyChain",
+			Handler:    _KeyTransparency_GetLogConsistencyChain_Handler,
+		},
+		{
+			MethodName: "ListUserApps",
+			Handler:    _KeyTransparency_ListUserApps_Handler,
+		},
+		{
+			MethodName: "SetKeyChangeWebhook",
+			Handler:    _KeyTransparency_SetKeyChangeWebhook_Handler,
+		},
+		{
+			MethodName: "GetEntryAtRevision",
+			Handler:    _KeyTransparency_GetEntryAtRevision_Handler,
+		},
+		{
+			MethodName: "CreatePermalink",
+			Handler:    _KeyTransparency_CreatePermalink_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1182,6 +3119,16 @@ var _KeyTransparency_serviceDesc = grpc.ServiceDesc{
 			Handler:       _KeyTransparency_ListMutationsStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchKeyChanges",
+			Handler:       _KeyTransparency_WatchKeyChanges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEntry",
+			Handler:       _KeyTransparency_WatchEntry_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "v1/keytransparency_proto/keytransparency.proto",
 }
@@ -1189,91 +3136,203 @@ var _KeyTransparency_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("v1/keytransparency_proto/keytransparency.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1370 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xcd, 0x6f, 0x1b, 0x45,
-	0x14, 0xd7, 0xda, 0xb1, 0x63, 0x3f, 0xe7, 0xa3, 0x4c, 0xd3, 0x66, 0xeb, 0x52, 0x48, 0x97, 0xd2,
-	0x86, 0x42, 0xbd, 0x89, 0x2b, 0xd4, 0x36, 0xa2, 0xaa, 0x68, 0x9a, 0x96, 0x28, 0x89, 0x88, 0x36,
-	0xad, 0x84, 0x10, 0x92, 0x35, 0xf1, 0x8e, 0x9d, 0x51, 0xd7, 0x3b, 0xdb, 0x9d, 0xb1, 0x55, 0x37,
-	0x84, 0x03, 0x12, 0xd0, 0x5b, 0x0f, 0xfd, 0x0f, 0x38, 0x73, 0xe3, 0xc4, 0x05, 0x09, 0xee, 0x1c,
-	0x10, 0xdc, 0xb8, 0xf2, 0x87, 0xa0, 0xf9, 0xf0, 0x67, 0x6c, 0xc7, 0x0e, 0x88, 0x8b, 0x77, 0xe7,
-	0xcd, 0x7b, 0x6f, 0x7e, 0xf3, 0x7b, 0x1f, 0x33, 0x5e, 0x28, 0x34, 0x56, 0xdd, 0xa7, 0xa4, 0x29,
-	0x62, 0x1c, 0xf2, 0x08, 0xc7, 0x24, 0x2c, 0x37, 0x4b, 0x51, 0xcc, 0x04, 0xeb, 0x97, 0x16, 0x94,
-	0x14, 0x5d, 0xa8, 0x32, 0x56, 0x0d, 0x48, 0xa1, 0x7f, 0xb6, 0xb1, 0x9a, 0x7f, 0x53, 0x4f, 0xb9,
-	0x38, 0xa2, 0x2e, 0x0e, 0x43, 0x26, 0xb0, 0xa0, 0x2c, 0xe4, 0xda, 0x30, 0x9f, 0x2f, 0xc7, 0xcd,
-	0x48, 0xbb, 0xe5, 0xd1, 0xbe, 0x79, 0x98, 0x39, 0xdb, 0xcc, 0x71, 0x5a, 0x8d, 0xf6, 0xf5, 0xaf,
-	0x99, 0x99, 0x13, 0x31, 0x0d, 0x02, 0x8a, 0x43, 0x33, 0x3e, 0xdf, 0x1a, 0x97, 0x6a, 0x38, 0x2a,
-	0xe1, 0x88, 0x1a, 0xf9, 0x95, 0xa1, 0xdb, 0xc0, 0x7e, 0x8d, 0x1a, 0x6b, 0x67, 0x15, 0xb2, 0xeb,
-	0xac, 0x56, 0xa3, 0x42, 0x10, 0x1f, 0x9d, 0x81, 0xe4, 0x53, 0xd2, 0xb4, 0xad, 0x25, 0x6b, 0x79,
-	0xc6, 0x93, 0xaf, 0x08, 0xc1, 0x94, 0x8f, 0x05, 0xb6, 0x13, 0x4a, 0xa4, 0xde, 0x9d, 0x57, 0x16,
-	0xe4, 0x36, 0x42, 0x11, 0x37, 0x9f, 0x44, 0x3e, 0x16, 0x04, 0x7d, 0x04, 0x99, 0x5a, 0x5d, 0xef,
-	0x4c, 0xe9, 0xe5, 0x8a, 0x4b, 0x85, 0xa1, 0x94, 0x14, 0x94, 0xa5, 0xd7, 0xb6, 0x40, 0xf7, 0x21,
-	0x5b, 0x6e, 0x01, 0xb0, 0x93, 0xca, 0xfc, 0xca, 0x08, 0xf3, 0x36, 0x58, 0xaf, 0x63, 0xe6, 0xfc,
-	0x9c, 0x80, 0x94, 0xf2, 0x8b, 0x16, 0x20, 0x45, 0x43, 0x9f, 0x3c, 0x57, 0x9e, 0x66, 0x3c, 0x3d,
-	0x40, 0x6f, 0x01, 0x68, 0xe5, 0x1a, 0x09, 0x85, 0x9d, 0x56, 0x53, 0x5d, 0x12, 0xb4, 0x06, 0xf3,
-	0xb8, 0x2e, 0x0e, 0x58, 0x4c, 0x5f, 0x10, 0xbf, 0x24, 0xe3, 0x60, 0x4f, 0x2f, 0x25, 0x97, 0x73,
-	0xc5, 0x37, 0x0a, 0x26, 0x28, 0xbb, 0xf5, 0xfd, 0x80, 0x96, 0xb7, 0x48, 0xd3, 0x9b, 0xeb, 0x68,
-	0x6e, 0x91, 0x26, 0x47, 0x79, 0xc8, 0x44, 0x31, 0x69, 0x50, 0x56, 0xe7, 0x76, 0x46, 0x79, 0x6e,
-	0x8f, 0xd1, 0x2e, 0x00, 0xa7, 0xd5, 0x10, 0x8b, 0x7a, 0x4c, 0xb8, 0x9d, 0x50, 0x2e, 0x57, 0x4e,
-	0xe2, 0xa6, 0xb0, 0xd7, 0x36, 0xd1, 0x5c, 0x75, 0xf9, 0xc8, 0x3f, 0x81, 0xf9, 0xbe, 0xe9, 0xee,
-	0xa0, 0x65, 0x75, 0xd0, 0x3e, 0x80, 0x54, 0x03, 0x07, 0x75, 0x62, 0xa2, 0x71, 0xbe, 0xa0, 0xd3,
-	0xe7, 0x01, 0xad, 0x52, 0x81, 0x83, 0xa0, 0x29, 0x3d, 0x10, 0xdf, 0xd3, 0x4a, 0x6b, 0x89, 0xdb,
-	0x96, 0xf3, 0xd2, 0x82, 0xd9, 0x1d, 0x13, 0x91, 0xdd, 0x98, 0xb1, 0x4a, 0x4f, 0x50, 0xad, 0x89,
-	0x83, 0x7a, 0x07, 0x20, 0x20, 0xb8, 0x22, 0xf3, 0x8d, 0x55, 0x0c, 0x8c, 0x7c, 0xa1, 0x9d, 0xb8,
-	0x3b, 0x38, 0xda, 0x26, 0xb8, 0xb2, 0x19, 0x96, 0x83, 0x3a, 0xa7, 0x2c, 0xf4, 0xb2, 0x52, 0x5b,
-	0x2d, 0xec, 0x7c, 0x0a, 0x73, 0x3b, 0x38, 0x8a, 0x48, 0xbc, 0x43, 0x04, 0x96, 0xf9, 0x86, 0xee,
-	0xc2, 0xc5, 0x03, 0x5a, 0x3d, 0x20, 0x5c, 0x94, 0x2a, 0xf5, 0x20, 0x68, 0x96, 0xca, 0xac, 0x16,
-	0x05, 0x44, 0x10, 0xbf, 0xc4, 0xc9, 0x33, 0x85, 0x2e, 0xe9, 0xd9, 0x46, 0xe5, 0xa1, 0xd4, 0x58,
-	0x6f, 0x29, 0xec, 0x91, 0x67, 0xce, 0x65, 0xc8, 0x3d, 0xe1, 0x24, 0xde, 0x8d, 0x59, 0x85, 0x06,
-	0xa4, 0x9d, 0xd1, 0x56, 0x57, 0x46, 0x7f, 0x6b, 0xc1, 0xfc, 0x23, 0x22, 0xf4, 0x2e, 0xc8, 0xb3,
-	0x3a, 0xe1, 0x02, 0x5d, 0x84, 0xac, 0xcf, 0x6a, 0x98, 0x86, 0x25, 0xea, 0xdb, 0x53, 0x8a, 0xdc,
-	0x8c, 0x16, 0x6c, 0xfa, 0x68, 0x11, 0xa6, 0xeb, 0x9c, 0xc4, 0x72, 0x4a, 0xf3, 0x9e, 0x96, 0xc3,
-	0x4d, 0x1f, 0x9d, 0x83, 0x34, 0x8e, 0x22, 0x29, 0x4f, 0x28, 0x79, 0x0a, 0x47, 0xd1, 0xa6, 0x8f,
-	0xae, 0xc2, 0x7c, 0x85, 0xc6, 0x5c, 0x94, 0x44, 0x4c, 0x48, 0x89, 0xd3, 0x17, 0x44, 0x25, 0x68,
-	0xd2, 0x9b, 0x55, 0xe2, 0xc7, 0x31, 0x21, 0x7b, 0xf4, 0x05, 0x71, 0xfe, 0x4a, 0xc0, 0x99, 0x0e,
-	0x10, 0x1e, 0xb1, 0x90, 0x13, 0x89, 0xa4, 0x11, 0xb7, 0xb8, 0xd4, 0xb0, 0x33, 0x8d, 0x58, 0xd3,
-	0xd5, 0x5b, 0x3e, 0x89, 0x53, 0x95, 0x4f, 0x5f, 0xb4, 0x92, 0x13, 0x44, 0x0b, 0xbd, 0x07, 0x49,
-	0x5e, 0x8b, 0x15, 0x3f, 0xb9, 0xe2, 0x62, 0xc7, 0x46, 0xa7, 0xd8, 0x0e, 0x8e, 0x3c, 0xc6, 0x84,
-	0x27, 0x75, 0x50, 0x11, 0x32, 0x01, 0xab, 0x96, 0x62, 0xc6, 0x84, 0x9d, 0x1a, 0xac, 0xbf, 0xcd,
-	0xaa, 0x4a, 0x7f, 0x3a, 0xd0, 0x2f, 0xe8, 0x1a, 0xcc, 0x4b, 0x9b, 0x32, 0x0b, 0x39, 0xe5, 0x42,
-	0x6e, 0xc2, 0x4e, 0x2f, 0x25, 0x97, 0x67, 0xbc, 0xb9, 0x80, 0x55, 0xd7, 0x3b, 0x52, 0xf4, 0x0e,
-	0xcc, 0x4a, 0x45, 0xda, 0xc2, 0xa8, 0xea, 0x77, 0xc6, 0x9b, 0x09, 0x58, 0xb5, 0x8d, 0xdb, 0xf9,
-	0xc5, 0x82, 0xc5, 0x6d, 0xca, 0x35, 0xbd, 0x9f, 0x50, 0x2e, 0xd8, 0x90, 0x70, 0xa7, 0xc7, 0x0d,
-	0xf7, 0x02, 0xa4, 0xb8, 0xc0, 0xb1, 0x50, 0xcc, 0x27, 0x3d, 0x3d, 0x90, 0xbe, 0x22, 0x5c, 0xed,
-	0x8a, 0x73, 0xca, 0xcb, 0x48, 0x81, 0x0c, 0x71, 0x57, 0x86, 0x4c, 0x9d, 0x90, 0x21, 0xa9, 0x41,
-	0x19, 0xf2, 0x15, 0xd8, 0xc7, 0xb7, 0x60, 0x12, 0x65, 0x1d, 0xd2, 0xaa, 0xa4, 0xb9, 0x6d, 0xa9,
-	0x56, 0xf3, 0xfe, 0x88, 0x44, 0xe8, 0xcf, 0x32, 0xcf, 0x98, 0xa2, 0x4b, 0x00, 0x21, 0x79, 0x2e,
-	0x4a, 0xdd, 0xfb, 0xca, 0x4a, 0xc9, 0x9e, 0x14, 0x38, 0x7f, 0x5a, 0x80, 0x74, 0xdf, 0x1f, 0x5e,
-	0x2d, 0xa9, 0xff, 0xa7, 0x5a, 0xd0, 0x26, 0xcc, 0x10, 0x09, 0xa2, 0x54, 0x57, 0x80, 0x4c, 0x16,
-	0x5e, 0x3d, 0xa9, 0x4f, 0x69, 0xf8, 0x5e, 0x8e, 0x74, 0x06, 0xce, 0x67, 0x70, 0xb6, 0x67, 0x57,
-	0x86, 0xd1, 0x8f, 0x21, 0xd5, 0x29, 0xbb, 0x09, 0x09, 0xd5, 0x96, 0x4e, 0xa0, 0x5b, 0x4b, 0xc4,
-	0xca, 0x07, 0x63, 0x91, 0xb5, 0x00, 0x29, 0x22, 0x95, 0x4d, 0x5f, 0xd3, 0x83, 0x41, 0x94, 0x24,
-	0x06, 0xa5, 0xc7, 0x17, 0x70, 0xee, 0x11, 0x11, 0xdb, 0x58, 0x10, 0x3e, 0x62, 0x4d, 0xab, 0x6f,
-	0xcd, 0x71, 0xbd, 0xff, 0x6e, 0x41, 0x4a, 0x79, 0x1d, 0xed, 0xce, 0x34, 0x85, 0xc4, 0x84, 0x4d,
-	0x21, 0x79, 0xfa, 0xa6, 0x30, 0x35, 0x5e, 0x53, 0x48, 0x0d, 0x68, 0x0a, 0xdf, 0x58, 0xb0, 0x20,
-	0x2b, 0xaa, 0x75, 0xfc, 0xf1, 0x7f, 0x11, 0xa5, 0x4b, 0x00, 0xaa, 0xf0, 0x05, 0x7b, 0x4a, 0x42,
-	0xb5, 0x9f, 0xac, 0xa7, 0x5a, 0xc1, 0x63, 0x29, 0xe8, 0xed, 0x0b, 0x53, 0xbd, 0x7d, 0xc1, 0xf9,
-	0xce, 0x82, 0x73, 0x7d, 0x38, 0x4c, 0x12, 0x3e, 0x84, 0x6c, 0xeb, 0x60, 0xe5, 0xaa, 0xfd, 0xe5,
-	0x8a, 0xcb, 0x23, 0x12, 0xb1, 0xe7, 0x1c, 0xf7, 0x3a, 0xa6, 0x32, 0xca, 0xaa, 0xb2, 0xbb, 0x20,
-	0x4e, 0x2b, 0x88, 0xb3, 0x52, 0xbc, 0xdb, 0x82, 0x59, 0xfc, 0x2d, 0x07, 0xf3, 0x5b, 0xa4, 0xf9,
-	0xb8, 0xcb, 0x2f, 0xfa, 0x12, 0xb2, 0x8f, 0x88, 0x78, 0xa0, 0xb6, 0x8f, 0x4e, 0x28, 0x03, 0xad,
-	0x65, 0x68, 0xcc, 0x5f, 0x1e, 0xa1, 0xac, 0x35, 0x9d, 0xb7, 0xbf, 0xfe, 0xe3, 0xef, 0xd7, 0x89,
-	0x0b, 0x68, 0xd1, 0x6d, 0xac, 0xba, 0x9a, 0x62, 0xee, 0x1e, 0xb6, 0xc9, 0x3f, 0x42, 0x2f, 0x2d,
-	0xc8, 0xb4, 0x8a, 0x08, 0x5d, 0x3f, 0xa1, 0x08, 0xbb, 0xb2, 0x3e, 0x3f, 0xf2, 0xce, 0x22, 0x15,
-	0x9d, 0x82, 0x5a, 0x7b, 0x19, 0x5d, 0x1d, 0xb2, 0xb6, 0xab, 0x22, 0xcb, 0xdd, 0x43, 0xf5, 0x3c,
-	0x42, 0xaf, 0x2d, 0x98, 0xeb, 0xad, 0x30, 0xb4, 0x32, 0x1a, 0xd0, 0xf1, 0x62, 0x1c, 0x03, 0xd6,
-	0x0d, 0x05, 0xeb, 0x1a, 0x7a, 0x77, 0x34, 0xac, 0xb5, 0x40, 0x39, 0x47, 0xaf, 0x34, 0x2a, 0x65,
-	0xbb, 0x27, 0x62, 0x82, 0x6b, 0xff, 0x31, 0x4d, 0xe3, 0xe2, 0xe1, 0x6a, 0xf1, 0x15, 0x0b, 0xfd,
-	0x60, 0xc1, 0x6c, 0x4f, 0x3a, 0x23, 0x77, 0xc4, 0x22, 0x83, 0x0a, 0x30, 0xbf, 0x32, 0xbe, 0x81,
-	0xae, 0x14, 0xe7, 0xb6, 0x42, 0x59, 0x44, 0x2b, 0xe3, 0x05, 0xd3, 0xed, 0xd4, 0xc6, 0x8f, 0x16,
-	0x9c, 0xed, 0xf1, 0x69, 0x58, 0x9c, 0x18, 0xf4, 0xd8, 0x95, 0xe9, 0xdc, 0x53, 0x60, 0xef, 0xa0,
-	0x5b, 0x93, 0x82, 0xed, 0x90, 0xfc, 0xbd, 0xa9, 0x0b, 0xf5, 0x3f, 0xe0, 0xfa, 0x58, 0x87, 0x93,
-	0x46, 0x39, 0xc9, 0x41, 0xe6, 0xdc, 0x55, 0x40, 0x6f, 0xa1, 0x0f, 0x87, 0x01, 0xc5, 0x51, 0xc4,
-	0xdd, 0x43, 0x7d, 0x92, 0x1f, 0xb9, 0xf2, 0x6c, 0xe7, 0xee, 0xa1, 0x39, 0xf1, 0x8f, 0xd0, 0xaf,
-	0x16, 0x9c, 0xe9, 0xbf, 0xb2, 0xa0, 0xe2, 0x09, 0xbc, 0x0e, 0xb8, 0xa2, 0xe5, 0x6f, 0x4e, 0x64,
-	0x63, 0xc0, 0x6f, 0x28, 0xf0, 0xf7, 0xd0, 0xdd, 0x53, 0x81, 0x77, 0x0f, 0x0c, 0xde, 0x9f, 0x2c,
-	0xc8, 0x75, 0x5d, 0x10, 0xd0, 0x8d, 0x11, 0x58, 0x8e, 0x5f, 0x8f, 0xf2, 0x85, 0x71, 0xd5, 0x0d,
-	0xea, 0x2d, 0x85, 0x7a, 0x23, 0x7f, 0x3a, 0xca, 0xd7, 0x7a, 0xae, 0x45, 0xf7, 0x37, 0x3e, 0x5f,
-	0xaf, 0x52, 0x71, 0x50, 0xdf, 0x2f, 0x94, 0x59, 0xcd, 0x35, 0x5f, 0x24, 0xfa, 0x80, 0xb8, 0x65,
-	0x16, 0xeb, 0xcf, 0x14, 0xc3, 0xbe, 0x1a, 0xec, 0xa7, 0xd5, 0xe3, 0xe6, 0x3f, 0x01, 0x00, 0x00,
-	0xff, 0xff, 0x73, 0x13, 0x0f, 0xfd, 0x1f, 0x11, 0x00, 0x00,
+	// 3153 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x5a, 0xdd, 0x6f, 0x1b, 0xc7,
+	0xb5, 0xc7, 0x92, 0x22, 0x25, 0x1e, 0x52, 0x1f, 0x1e, 0x29, 0x12, 0xcd, 0xd8, 0xb1, 0xbc, 0x89,
+	0x1d, 0xc5, 0x71, 0x48, 0x59, 0xf9, 0xb4, 0x72, 0x7d, 0x13, 0x59, 0x96, 0x1d, 0x45, 0x72, 0xa0,
+	0xac, 0xec, 0xe4, 0xe2, 0xde, 0x0b, 0xb0, 0x2b, 0xee, 0x88, 0x5c, 0x68, 0xb9, 0xbb, 0x9e, 0x19,
+	0x32, 0x66, 0x5c, 0xa3, 0x68, 0x8a, 0xb4, 0x45, 0x5f, 0x82, 0x20, 0x0f, 0x45, 0x5f, 0x8b, 0xa6,
+	0x40, 0x81, 0x20, 0x45, 0xd1, 0xa7, 0x02, 0x45, 0x51, 0x04, 0xe8, 0x53, 0xd1, 0x97, 0xb6, 0xe8,
+	0x5f, 0xd0, 0xc7, 0xbe, 0xf7, 0xb5, 0x98, 0x8f, 0xfd, 0x20, 0xc5, 0x8f, 0x15, 0x95, 0xe4, 0xc5,
+	0xe6, 0x9c, 0x39, 0x67, 0xf6, 0x9c, 0x33, 0xe7, 0xfc, 0xce, 0xcc, 0x19, 0x41, 0xb9, 0x7d, 0xad,
+	0x72, 0x84, 0x3b, 0x8c, 0x98, 0x2e, 0xf5, 0x4d, 0x82, 0xdd, 0x5a, 0xa7, 0xea, 0x13, 0x8f, 0x79,
+	0xbd, 0xd4, 0xb2, 0xa0, 0xa2, 0xb3, 0x75, 0xcf, 0xab, 0x3b, 0xb8, 0xdc, 0x3b, 0xdb, 0xbe, 0x56,
+	0x3a, 0x27, 0xa7, 0x2a, 0xa6, 0x6f, 0x57, 0x4c, 0xd7, 0xf5, 0x98, 0xc9, 0x6c, 0xcf, 0xa5, 0x52,
+	0xb0, 0xf4, 0x94, 0x9a, 0x15, 0xa3, 0x83, 0xd6, 0x61, 0xc5, 0x6a, 0x11, 0xc1, 0xa0, 0xe6, 0x2f,
+	0xf4, 0xce, 0x33, 0xbb, 0x89, 0x29, 0x33, 0x9b, 0xbe, 0x62, 0x28, 0xd5, 0x48, 0xc7, 0x97, 0x7a,
+	0x51, 0xff, 0x40, 0xfd, 0xa7, 0xe6, 0x8a, 0x6a, 0x8e, 0xda, 0x75, 0xff, 0x40, 0xfe, 0xab, 0x66,
+	0x66, 0x18, 0xb1, 0x1d, 0xc7, 0x36, 0x83, 0xcf, 0x2c, 0x06, 0xe3, 0x6a, 0xd3, 0xf4, 0xab, 0xa6,
+	0x6f, 0x2b, 0xfa, 0x33, 0x03, 0xfd, 0x60, 0x5a, 0x4d, 0x5b, 0x49, 0xeb, 0xd7, 0x20, 0xb7, 0xe9,
+	0x35, 0x9b, 0x36, 0x63, 0xd8, 0x42, 0x73, 0x90, 0x3e, 0xc2, 0x9d, 0xa2, 0xb6, 0xac, 0xad, 0x14,
+	0x0c, 0xfe, 0x13, 0x21, 0x98, 0xb0, 0x4c, 0x66, 0x16, 0x53, 0x82, 0x24, 0x7e, 0xeb, 0x9f, 0x68,
+	0x90, 0xdf, 0x72, 0x19, 0xe9, 0xdc, 0xf7, 0x2d, 0x93, 0x61, 0xf4, 0x5f, 0x30, 0xd5, 0x6c, 0x49,
+	0xd7, 0x08, 0xbe, 0xfc, 0xda, 0x72, 0x79, 0xa0, 0x4f, 0xcb, 0x42, 0xd2, 0x08, 0x25, 0xd0, 0x4d,
+	0xc8, 0xd5, 0x02, 0x05, 0x8a, 0x69, 0x21, 0xfe, 0xcc, 0x10, 0xf1, 0x50, 0x59, 0x23, 0x12, 0xd3,
+	0xbf, 0x4a, 0x43, 0x46, 0xac, 0x8b, 0x16, 0x20, 0x63, 0xbb, 0x16, 0x7e, 0x28, 0x56, 0x2a, 0x18,
+	0x72, 0x80, 0x9e, 0x02, 0x90, 0xcc, 0x4d, 0xec, 0xb2, 0x62, 0x56, 0x4c, 0xc5, 0x28, 0x68, 0x1d,
+	0x66, 0xcd, 0x16, 0x6b, 0x78, 0xc4, 0xfe, 0x10, 0x5b, 0x55, 0xbe, 0x0f, 0xc5, 0xc9, 0xe5, 0xf4,
+	0x4a, 0x7e, 0xed, 0x4c, 0x59, 0x6d, 0xca, 0x5e, 0xeb, 0xc0, 0xb1, 0x6b, 0x3b, 0xb8, 0x63, 0xcc,
+	0x44, 0x9c, 0x3b, 0xb8, 0x43, 0x51, 0x09, 0xa6, 0x7c, 0x82, 0xdb, 0xb6, 0xd7, 0xa2, 0xc5, 0x29,
+	0xb1, 0x72, 0x38, 0x46, 0x7b, 0x00, 0xd4, 0xae, 0xbb, 0x26, 0x6b, 0x11, 0x4c, 0x8b, 0x29, 0xb1,
+	0xe4, 0xea, 0x28, 0xdf, 0x94, 0xf7, 0x43, 0x11, 0xe9, 0xab, 0xd8, 0x1a, 0xe8, 0x69, 0x98, 0x0e,
+	0x3c, 0x57, 0x65, 0x1d, 0x1f, 0x17, 0x73, 0xcb, 0xda, 0x4a, 0xce, 0x28, 0x04, 0xc4, 0x7b, 0x1d,
+	0x1f, 0xa3, 0x57, 0x60, 0x9a, 0xe0, 0x9a, 0xd7, 0xc6, 0xa4, 0x23, 0x8d, 0x81, 0x41, 0xc6, 0x14,
+	0x02, 0x3e, 0x61, 0xca, 0x25, 0x98, 0x09, 0x54, 0xaf, 0x4a, 0x2f, 0xe6, 0x85, 0x41, 0xd3, 0x01,
+	0x75, 0x9b, 0x13, 0x4b, 0xf7, 0x61, 0xb6, 0x47, 0xc5, 0x78, 0xe0, 0xe4, 0x64, 0xe0, 0x5c, 0x85,
+	0x4c, 0xdb, 0x74, 0x5a, 0x58, 0x45, 0xc4, 0x62, 0x59, 0x86, 0xf0, 0x2d, 0xbb, 0x6e, 0x33, 0xd3,
+	0x71, 0x3a, 0x7c, 0x05, 0x6c, 0x19, 0x92, 0x69, 0x3d, 0xf5, 0x9a, 0xa6, 0xff, 0x5d, 0x83, 0xe9,
+	0xbb, 0xca, 0x8c, 0x3d, 0xe2, 0x79, 0x87, 0x5d, 0x81, 0xa5, 0x9d, 0x38, 0xb0, 0xae, 0x03, 0x38,
+	0xd8, 0x3c, 0xe4, 0x31, 0xef, 0x1d, 0x2a, 0x35, 0x4a, 0xe5, 0x30, 0x79, 0xee, 0x9a, 0xfe, 0x2e,
+	0x36, 0x0f, 0xb7, 0xdd, 0x9a, 0xd3, 0xa2, 0xb6, 0xe7, 0x1a, 0x39, 0xce, 0x2d, 0x3f, 0xfc, 0x36,
+	0xcc, 0x87, 0x8e, 0x88, 0xad, 0x91, 0x1e, 0xb9, 0xc6, 0x99, 0x40, 0x6c, 0x37, 0x58, 0x4b, 0xff,
+	0x8b, 0x06, 0x33, 0x77, 0x4d, 0xdf, 0xc7, 0xe4, 0x2e, 0x66, 0x26, 0x4f, 0x20, 0x74, 0x03, 0x9e,
+	0x6c, 0xd8, 0xf5, 0x06, 0xa6, 0xac, 0x7a, 0xd8, 0x72, 0x9c, 0x4e, 0xb5, 0xe6, 0x35, 0x7d, 0x07,
+	0x33, 0x6c, 0x55, 0x29, 0x7e, 0x20, 0x4c, 0x4d, 0x1b, 0x45, 0xc5, 0x72, 0x9b, 0x73, 0x6c, 0x06,
+	0x0c, 0xfb, 0xf8, 0x01, 0xba, 0x0f, 0x67, 0x28, 0x7e, 0xd0, 0xc2, 0x6e, 0x0d, 0x93, 0x6a, 0x1b,
+	0x13, 0x1a, 0x25, 0xde, 0xca, 0x10, 0xff, 0xec, 0x63, 0xd2, 0xc6, 0xe4, 0x3d, 0xc9, 0x6f, 0xcc,
+	0x85, 0x4b, 0x28, 0x0a, 0xdf, 0xfd, 0xa6, 0x5d, 0x97, 0x08, 0x56, 0x6d, 0x98, 0xb4, 0xa1, 0x72,
+	0x68, 0x3a, 0xa4, 0xbe, 0x65, 0xd2, 0x86, 0x7e, 0x11, 0xf2, 0xf7, 0x29, 0x26, 0x7b, 0xc4, 0x3b,
+	0xb4, 0x1d, 0x1c, 0x02, 0x84, 0x16, 0x03, 0x88, 0x1f, 0x6a, 0x30, 0x7b, 0x07, 0x33, 0xb9, 0x21,
+	0xfc, 0x33, 0x94, 0xa1, 0x27, 0x21, 0x67, 0x79, 0x4d, 0xd3, 0x76, 0xab, 0xb6, 0x55, 0x9c, 0x10,
+	0x71, 0x32, 0x25, 0x09, 0xdb, 0x16, 0x5a, 0x82, 0xc9, 0x16, 0xc5, 0x84, 0x4f, 0xc9, 0x10, 0xca,
+	0xf2, 0xe1, 0xb6, 0x85, 0x9e, 0x80, 0xac, 0xe9, 0xfb, 0x9c, 0x9e, 0x12, 0xf4, 0x8c, 0xe9, 0xfb,
+	0xdb, 0x16, 0xba, 0x0c, 0xb3, 0x87, 0x36, 0xa1, 0xac, 0xca, 0x08, 0xc6, 0x55, 0x6a, 0x7f, 0x88,
+	0x85, 0xae, 0x69, 0x63, 0x5a, 0x90, 0xef, 0x11, 0x8c, 0xf7, 0xed, 0x0f, 0xb1, 0xfe, 0xf1, 0x04,
+	0xcc, 0x45, 0x8a, 0x50, 0xdf, 0x73, 0x29, 0xe6, 0x9a, 0xb4, 0x49, 0xb0, 0xa5, 0x52, 0xed, 0xa9,
+	0x36, 0x51, 0x3b, 0xdf, 0x85, 0x46, 0xa9, 0xb1, 0xd0, 0xa8, 0x27, 0xf0, 0xd2, 0x27, 0x09, 0xbc,
+	0xe7, 0x20, 0x4d, 0x9b, 0x44, 0xf8, 0x27, 0xbf, 0xb6, 0x14, 0xc9, 0xc8, 0x6c, 0xb9, 0x6b, 0xfa,
+	0x86, 0xe7, 0x31, 0x83, 0xf3, 0xa0, 0x35, 0x98, 0x72, 0xbc, 0x7a, 0x95, 0x78, 0x1e, 0x2b, 0x66,
+	0xfa, 0xf3, 0xef, 0x7a, 0x75, 0xc1, 0x3f, 0xe9, 0xc8, 0x1f, 0xe8, 0x59, 0x98, 0xe5, 0x32, 0x35,
+	0xcf, 0xa5, 0x36, 0x65, 0xdc, 0x88, 0x62, 0x76, 0x39, 0xbd, 0x52, 0x30, 0x66, 0x1c, 0xaf, 0xbe,
+	0x19, 0x51, 0x39, 0xcc, 0x70, 0x46, 0x3b, 0xd0, 0x51, 0xc0, 0x61, 0xc1, 0x28, 0x38, 0x5e, 0x3d,
+	0xd4, 0x1b, 0x3d, 0x07, 0x73, 0xa1, 0xd1, 0x55, 0xbf, 0x45, 0xea, 0xd8, 0x12, 0x08, 0x38, 0x65,
+	0xcc, 0x86, 0xf4, 0x3d, 0x41, 0x46, 0xef, 0x42, 0x01, 0xbb, 0x96, 0x47, 0x28, 0xe6, 0x78, 0x4b,
+	0x8b, 0x39, 0x01, 0x48, 0x2f, 0x0c, 0xf1, 0xac, 0xb2, 0x75, 0x2b, 0x92, 0x32, 0xba, 0x96, 0x40,
+	0x57, 0xe0, 0x4c, 0xad, 0x45, 0x08, 0x76, 0x59, 0x35, 0xda, 0x4e, 0x10, 0xdb, 0x39, 0xab, 0x26,
+	0xde, 0x53, 0xbb, 0xaa, 0xff, 0x20, 0x05, 0x4b, 0xbb, 0x36, 0x95, 0x81, 0xf0, 0x96, 0x4d, 0x99,
+	0x37, 0x20, 0x30, 0xb3, 0x49, 0x03, 0x73, 0x01, 0x32, 0x94, 0x99, 0x84, 0x89, 0x18, 0x49, 0x1b,
+	0x72, 0xc0, 0xd7, 0xf2, 0xcd, 0x7a, 0x2c, 0x22, 0x33, 0xc6, 0x14, 0x27, 0xf0, 0x60, 0x8c, 0xc5,
+	0xf2, 0xc4, 0x88, 0x58, 0xce, 0xf4, 0x89, 0x65, 0x74, 0x1e, 0x40, 0xac, 0xcd, 0xbc, 0x23, 0xcc,
+	0xf7, 0x83, 0x2f, 0x21, 0xbe, 0x76, 0x8f, 0x13, 0xd0, 0x45, 0x28, 0xb8, 0xf8, 0x03, 0x01, 0x29,
+	0x5c, 0x4c, 0x6d, 0x44, 0x5e, 0xd2, 0x6e, 0x73, 0x92, 0xfe, 0x4b, 0x0d, 0x8a, 0xc7, 0xbd, 0xa0,
+	0xb2, 0x62, 0x13, 0xb2, 0x02, 0x8a, 0x69, 0x51, 0x13, 0x7b, 0xf3, 0xfc, 0x90, 0xbd, 0xe9, 0x4d,
+	0x29, 0x43, 0x89, 0x72, 0x1d, 0x5d, 0xfc, 0x90, 0x55, 0xe3, 0xae, 0xc9, 0x71, 0xca, 0xbe, 0x70,
+	0xcf, 0x65, 0x98, 0x15, 0xd3, 0x31, 0x3b, 0xd2, 0xc2, 0x8e, 0x69, 0x4e, 0xde, 0x0b, 0x6c, 0xe1,
+	0x95, 0x00, 0xc9, 0xb3, 0xc5, 0x60, 0x08, 0xc9, 0x7c, 0x3b, 0x10, 0x82, 0xb6, 0x79, 0xe4, 0x32,
+	0xd2, 0xa9, 0xb6, 0x84, 0x42, 0x2a, 0x35, 0x2f, 0x8f, 0xaa, 0x43, 0x52, 0x7d, 0x23, 0x8f, 0xa3,
+	0x81, 0xfe, 0x3f, 0x30, 0xdf, 0x65, 0x95, 0xf2, 0xfc, 0x06, 0x64, 0x22, 0x2c, 0x3a, 0xa1, 0xe3,
+	0xa5, 0xa4, 0xee, 0x48, 0xbc, 0xf5, 0xbd, 0x5a, 0x23, 0x91, 0xb3, 0x16, 0x20, 0x83, 0x39, 0xb3,
+	0x2a, 0x35, 0x72, 0xd0, 0xcf, 0x25, 0xa9, 0x7e, 0xa8, 0xfa, 0xff, 0xf0, 0xc4, 0x1d, 0xcc, 0x76,
+	0x4d, 0x86, 0xe9, 0x90, 0x6f, 0x6a, 0x3d, 0xdf, 0x4c, 0xba, 0xfa, 0x2f, 0x52, 0x90, 0x11, 0xab,
+	0x0e, 0x5f, 0x4e, 0x21, 0x65, 0xea, 0x84, 0x48, 0x99, 0x1e, 0x1f, 0x29, 0x27, 0x92, 0x21, 0x65,
+	0xa6, 0x0f, 0x52, 0xf6, 0xc2, 0x5f, 0xf6, 0xd4, 0xf0, 0xa7, 0x7f, 0xac, 0xc1, 0x02, 0x4f, 0xe6,
+	0xe0, 0xc4, 0x44, 0x4f, 0xb1, 0xf1, 0xdd, 0xd0, 0x92, 0xee, 0x85, 0x96, 0x2e, 0x54, 0x9b, 0xe8,
+	0x46, 0x35, 0xfd, 0x47, 0x1a, 0x3c, 0xd1, 0xa3, 0x87, 0x8a, 0xeb, 0xdb, 0x90, 0x0b, 0xce, 0x62,
+	0x81, 0xc5, 0xc3, 0x8e, 0x27, 0x5d, 0x47, 0x3f, 0x23, 0x12, 0xed, 0x87, 0x1a, 0x93, 0xfd, 0x50,
+	0xe3, 0x29, 0x38, 0x77, 0x07, 0x33, 0x79, 0xca, 0xd9, 0x34, 0x7d, 0xf3, 0xc0, 0x76, 0x6c, 0x66,
+	0xe3, 0xc0, 0x31, 0xfa, 0xbb, 0x80, 0x8e, 0x4f, 0xa2, 0xd7, 0x21, 0x4f, 0x05, 0xb5, 0xca, 0x6f,
+	0x67, 0x2a, 0x07, 0x4b, 0x81, 0x9e, 0xc1, 0xd5, 0xad, 0x7c, 0x2f, 0xb8, 0xba, 0x19, 0x20, 0xd9,
+	0x39, 0x41, 0xff, 0xa9, 0x06, 0x20, 0x62, 0x55, 0x56, 0xef, 0x0d, 0xc8, 0x88, 0x7c, 0x1f, 0x2b,
+	0x93, 0x85, 0x64, 0xb7, 0xd3, 0x52, 0x63, 0x3b, 0x4d, 0xff, 0xbd, 0x06, 0x73, 0xea, 0x98, 0xe6,
+	0x1d, 0x6e, 0x90, 0x5a, 0xc3, 0x6e, 0x63, 0x74, 0x1d, 0xb2, 0x32, 0x12, 0x94, 0x82, 0x17, 0x87,
+	0xac, 0x7c, 0x4b, 0x30, 0x1a, 0x4a, 0x60, 0x10, 0x8a, 0xc6, 0x50, 0x37, 0xdd, 0x85, 0xba, 0x37,
+	0x20, 0x2b, 0x62, 0x8b, 0x8a, 0xb4, 0xc9, 0xaf, 0x5d, 0x1a, 0x06, 0x98, 0xa1, 0x07, 0x0d, 0x25,
+	0xa4, 0x9f, 0x85, 0xa5, 0x70, 0x2f, 0x83, 0x13, 0xab, 0xda, 0xc6, 0x77, 0x60, 0xba, 0x8b, 0x8e,
+	0x8a, 0x30, 0x19, 0x1c, 0x82, 0x25, 0x48, 0x04, 0x43, 0x9e, 0x9b, 0x07, 0xb6, 0x6b, 0x92, 0x4e,
+	0xd5, 0xb2, 0xeb, 0x98, 0x32, 0x75, 0x8b, 0x2d, 0x48, 0xe2, 0x2d, 0x41, 0xd3, 0x77, 0x05, 0x9a,
+	0x49, 0x73, 0xf7, 0x99, 0xc9, 0x68, 0x22, 0x34, 0x5b, 0x0c, 0xed, 0x4b, 0x89, 0x84, 0x08, 0x14,
+	0xff, 0xcd, 0x04, 0xe4, 0x63, 0x6b, 0x0d, 0x5f, 0xe4, 0x02, 0xe4, 0x45, 0xa5, 0xac, 0xca, 0x9c,
+	0x94, 0x70, 0x08, 0x82, 0x14, 0x22, 0x20, 0x76, 0x2d, 0x35, 0x2d, 0xcb, 0xd3, 0x14, 0x76, 0x2d,
+	0x39, 0x79, 0x09, 0x66, 0xcc, 0x1a, 0xb3, 0xdb, 0xb8, 0xca, 0x43, 0xc7, 0xc6, 0x54, 0xe4, 0x66,
+	0xda, 0x98, 0x96, 0xd4, 0x2d, 0x49, 0x44, 0xe7, 0xe2, 0x11, 0x25, 0x4f, 0x16, 0x11, 0x01, 0x5d,
+	0x05, 0x14, 0x0e, 0xaa, 0x3e, 0x26, 0xd5, 0x86, 0xd7, 0x22, 0xe2, 0x18, 0xa4, 0x19, 0x73, 0xe1,
+	0xcc, 0x1e, 0x26, 0x6f, 0x79, 0x2d, 0x82, 0xbe, 0x03, 0x33, 0x47, 0xb8, 0x53, 0x35, 0x9d, 0xba,
+	0x47, 0x6c, 0xd6, 0x68, 0x06, 0xd7, 0xe4, 0xeb, 0x23, 0x03, 0x49, 0x78, 0xa3, 0xbc, 0x83, 0x3b,
+	0x1b, 0xa1, 0xac, 0x8c, 0xff, 0xe9, 0xa3, 0x38, 0x8d, 0x6b, 0xcb, 0x1a, 0x04, 0xd3, 0x86, 0xe7,
+	0xc8, 0xc3, 0x64, 0xda, 0x88, 0x08, 0x7c, 0x43, 0x99, 0xc7, 0x4c, 0x47, 0xde, 0x4e, 0x31, 0x15,
+	0xb7, 0xdf, 0xb4, 0x51, 0x10, 0xc4, 0x6d, 0x49, 0xe3, 0xd0, 0x2d, 0x99, 0x22, 0xb3, 0x41, 0xb0,
+	0xcd, 0x08, 0x72, 0x08, 0x54, 0xb1, 0x3d, 0xcc, 0x8b, 0x79, 0x35, 0xe2, 0x3e, 0x31, 0xdb, 0x98,
+	0x70, 0xb8, 0x11, 0x14, 0x09, 0x7c, 0x05, 0xe9, 0x13, 0x35, 0x23, 0xb6, 0x80, 0x03, 0x60, 0xe9,
+	0x4d, 0x40, 0xc7, 0xcd, 0xea, 0x73, 0x21, 0x5e, 0x88, 0x5f, 0x88, 0xd3, 0xf1, 0x8b, 0xef, 0xff,
+	0xc1, 0x79, 0x5e, 0x4f, 0xbb, 0xea, 0xca, 0x66, 0x83, 0x67, 0x5f, 0x92, 0x48, 0x3c, 0x0f, 0x10,
+	0x56, 0x54, 0x09, 0x19, 0xdc, 0x65, 0xaa, 0x9a, 0x52, 0xfd, 0x7b, 0x30, 0xdf, 0xbd, 0xb2, 0x84,
+	0xaa, 0x3e, 0xd5, 0x58, 0xeb, 0x77, 0xfc, 0x59, 0x81, 0x39, 0x8a, 0x6b, 0x9e, 0x6b, 0x1d, 0x2b,
+	0xdb, 0x33, 0x92, 0x1e, 0x72, 0x2e, 0x42, 0x96, 0x5f, 0x1a, 0x31, 0x2d, 0xa6, 0x45, 0x05, 0x54,
+	0x23, 0xfd, 0x53, 0x0d, 0xe6, 0xfb, 0xd8, 0xd6, 0x55, 0x95, 0xb5, 0x84, 0x55, 0xf9, 0x36, 0x64,
+	0xc5, 0x81, 0x27, 0x80, 0xc6, 0xf2, 0x90, 0xb8, 0xeb, 0x63, 0xb5, 0xa1, 0xa4, 0xf5, 0x1d, 0x98,
+	0xe7, 0x35, 0x8b, 0x03, 0xe4, 0x86, 0xef, 0x27, 0xcb, 0xf8, 0x18, 0xd4, 0xa5, 0xe2, 0x50, 0xa7,
+	0x57, 0x60, 0xa1, 0x7b, 0x31, 0x55, 0xff, 0x96, 0x60, 0x52, 0x42, 0xa6, 0x3c, 0x52, 0xe7, 0x8c,
+	0xac, 0xc0, 0x4c, 0xaa, 0x3f, 0x84, 0x99, 0x77, 0x5b, 0x1e, 0x33, 0xdf, 0xb3, 0x3d, 0x47, 0xb6,
+	0x2a, 0x16, 0x20, 0xf3, 0x80, 0x53, 0xd4, 0x47, 0xe5, 0x80, 0x53, 0x1d, 0xbb, 0x69, 0x33, 0x05,
+	0x31, 0x72, 0x80, 0xd6, 0x21, 0x4f, 0x30, 0x3f, 0x90, 0x9a, 0x87, 0x0c, 0x13, 0x75, 0xa0, 0x39,
+	0x7b, 0xac, 0x60, 0xdd, 0x52, 0xbd, 0x48, 0x03, 0x04, 0xf7, 0x06, 0x67, 0xd6, 0xbf, 0x4c, 0x43,
+	0xfe, 0x1e, 0x69, 0x51, 0xb6, 0xe1, 0xd6, 0x1a, 0x1e, 0x39, 0x4d, 0x41, 0x88, 0x6f, 0x5f, 0x2a,
+	0xe1, 0xf6, 0x2d, 0x43, 0xfe, 0xd0, 0x76, 0xeb, 0x98, 0xf8, 0xc4, 0x76, 0x99, 0xaa, 0x18, 0x71,
+	0x12, 0xaf, 0xc6, 0xbe, 0xed, 0xba, 0xd8, 0x92, 0xd5, 0x78, 0x62, 0x74, 0x35, 0x96, 0xec, 0x9c,
+	0xc0, 0x0b, 0x81, 0x69, 0x59, 0x04, 0x53, 0xaa, 0xce, 0x3d, 0xc1, 0x90, 0x43, 0xa5, 0x04, 0x5a,
+	0xde, 0x9d, 0x11, 0x95, 0x22, 0x2b, 0x83, 0x5d, 0x50, 0x0d, 0x45, 0x44, 0xef, 0x40, 0x2e, 0xec,
+	0x75, 0x88, 0x33, 0xc6, 0xf0, 0x6e, 0x9d, 0xb4, 0x52, 0xfa, 0xe5, 0x6e, 0x20, 0x67, 0x44, 0x4b,
+	0xa0, 0x37, 0x61, 0xb2, 0x21, 0xaf, 0x59, 0xc5, 0xa9, 0xe5, 0xf4, 0x88, 0x6b, 0x43, 0x6c, 0x5f,
+	0x8c, 0x40, 0x8c, 0x77, 0x52, 0x16, 0xdf, 0x37, 0x59, 0xad, 0xb1, 0x83, 0x79, 0xda, 0xb8, 0x75,
+	0x9c, 0x2c, 0x58, 0x4f, 0x5a, 0xae, 0x7b, 0x2a, 0xd1, 0x44, 0x6f, 0x25, 0xd2, 0xff, 0xa5, 0xc1,
+	0x4c, 0xa8, 0xc3, 0x56, 0x1b, 0xbb, 0x5f, 0xb3, 0x02, 0xe1, 0xc1, 0x74, 0x22, 0x7e, 0x30, 0x8d,
+	0x37, 0x00, 0x33, 0xe3, 0x34, 0x00, 0x25, 0x7e, 0x8b, 0x58, 0xca, 0x8e, 0x8c, 0xa5, 0x9c, 0xe0,
+	0x16, 0x07, 0xbb, 0x47, 0x50, 0xda, 0xc7, 0x2c, 0x34, 0xf8, 0x7d, 0x7c, 0xd0, 0xf0, 0xbc, 0xa3,
+	0x6f, 0xc4, 0xf5, 0x73, 0x90, 0x6e, 0x11, 0x47, 0xf5, 0x04, 0xf8, 0x4f, 0xfd, 0xbf, 0xe1, 0xc9,
+	0xbe, 0x1f, 0x57, 0xb8, 0xd2, 0xb3, 0x57, 0xda, 0xb1, 0xbd, 0xfa, 0x42, 0x83, 0xb3, 0xc1, 0xf9,
+	0x72, 0x23, 0x8c, 0xee, 0x53, 0x81, 0x5c, 0xcc, 0xaa, 0x74, 0xdc, 0xaa, 0x12, 0x4c, 0x85, 0x29,
+	0x25, 0x77, 0x2e, 0x1c, 0x27, 0x6d, 0x6c, 0xe8, 0x7f, 0xd5, 0x20, 0xb7, 0x87, 0x49, 0xd3, 0x74,
+	0x6c, 0xf7, 0xe8, 0xeb, 0xf5, 0xed, 0x30, 0xf5, 0x2e, 0x42, 0x41, 0x54, 0x83, 0xe0, 0x6c, 0x98,
+	0x11, 0x67, 0xc3, 0xbc, 0xa0, 0xc9, 0xa3, 0x21, 0x0f, 0x20, 0x9b, 0xd2, 0x16, 0x4e, 0x1c, 0x40,
+	0x82, 0x5b, 0x04, 0x10, 0x96, 0x3d, 0x72, 0x6c, 0x45, 0x96, 0x9d, 0x83, 0x9c, 0x1f, 0x0c, 0x54,
+	0xdf, 0x31, 0x22, 0xa0, 0x97, 0x20, 0x17, 0xb6, 0xf9, 0x47, 0xf4, 0xcc, 0x23, 0x46, 0xfd, 0x27,
+	0x29, 0x58, 0xb8, 0x17, 0x4b, 0x83, 0x7d, 0xd7, 0xf4, 0x69, 0xc3, 0x63, 0xa7, 0x41, 0xf6, 0x57,
+	0x82, 0x54, 0x4c, 0xf0, 0x96, 0xc3, 0xf9, 0x82, 0x64, 0xbd, 0x09, 0x93, 0xc1, 0x41, 0x34, 0x3d,
+	0xf2, 0xe2, 0x12, 0x28, 0x2a, 0x73, 0x36, 0x10, 0xe4, 0xf8, 0x5f, 0x23, 0xd8, 0x64, 0x38, 0x31,
+	0xfe, 0x4b, 0x76, 0xe1, 0xf3, 0x8f, 0x34, 0x98, 0xee, 0x5a, 0x37, 0x16, 0x2f, 0xda, 0x80, 0x78,
+	0xe9, 0x8e, 0xf2, 0xf0, 0x02, 0x97, 0x1e, 0xf7, 0x02, 0xa7, 0x7f, 0x5f, 0x83, 0xc5, 0x4d, 0xa1,
+	0x53, 0xb8, 0xf3, 0xdf, 0x08, 0x6c, 0x0c, 0x09, 0x6d, 0x9d, 0xc2, 0xd2, 0x31, 0x15, 0x14, 0x78,
+	0x2c, 0x40, 0x46, 0x5e, 0xa1, 0x95, 0x43, 0xc4, 0x80, 0x77, 0xbd, 0xa3, 0xd0, 0x1c, 0xdd, 0xf5,
+	0x8e, 0x96, 0x8d, 0xc4, 0x74, 0x13, 0xd0, 0xf1, 0xa6, 0x05, 0x37, 0x8b, 0xdf, 0x18, 0x94, 0xc1,
+	0x05, 0x23, 0x73, 0x84, 0x3b, 0xdb, 0xd6, 0x98, 0xd1, 0xfe, 0x2b, 0x0d, 0xce, 0x88, 0x6a, 0x38,
+	0xb8, 0x2d, 0x78, 0x5a, 0xb7, 0xf6, 0x01, 0xad, 0x89, 0x7e, 0xe7, 0xe2, 0x1e, 0x10, 0xce, 0x1c,
+	0x03, 0xe1, 0x7f, 0x6b, 0x90, 0x17, 0x87, 0xce, 0x9b, 0x2d, 0xd7, 0x72, 0xbe, 0xd5, 0xbb, 0x77,
+	0x18, 0xc5, 0x13, 0x63, 0xb7, 0x21, 0xd6, 0xa1, 0xc0, 0xf8, 0x79, 0x04, 0x5b, 0x89, 0x1e, 0x18,
+	0xf2, 0x8a, 0x99, 0x0f, 0xf4, 0xcf, 0x35, 0x00, 0xf5, 0x3a, 0xb4, 0x83, 0x3b, 0x3d, 0x11, 0x90,
+	0x0b, 0x22, 0x60, 0x15, 0xc0, 0x17, 0xcf, 0x90, 0xfc, 0x85, 0x52, 0x85, 0x40, 0x9f, 0x07, 0xca,
+	0x9c, 0x1f, 0xfc, 0xe4, 0x2f, 0x4d, 0x2d, 0xaa, 0xc0, 0x25, 0x67, 0x88, 0xdf, 0x68, 0x1d, 0x00,
+	0x3f, 0xf4, 0x6d, 0x75, 0x64, 0x4b, 0x00, 0x17, 0x11, 0xb7, 0x7e, 0x07, 0x60, 0x07, 0x77, 0x82,
+	0x77, 0xac, 0xeb, 0x30, 0xc1, 0x3f, 0x5e, 0xd4, 0x46, 0xb6, 0x2b, 0x22, 0xdb, 0x0c, 0x21, 0xb2,
+	0xf6, 0xc7, 0xb3, 0x30, 0xbb, 0x83, 0x3b, 0x71, 0x1c, 0x46, 0xdf, 0x85, 0x5c, 0xd8, 0x55, 0x40,
+	0x23, 0x76, 0x40, 0x6d, 0xb7, 0x0c, 0xe7, 0xd2, 0xe8, 0xc0, 0xd0, 0x2f, 0x7c, 0xf4, 0xb7, 0x7f,
+	0x7e, 0x96, 0x3a, 0x8b, 0x96, 0x2a, 0xed, 0x6b, 0x15, 0x19, 0x24, 0xb4, 0xf2, 0x28, 0x4c, 0x82,
+	0xc7, 0xe8, 0xc7, 0x1a, 0x4c, 0x05, 0x0d, 0x61, 0x74, 0x65, 0xc4, 0xfe, 0xc7, 0x3a, 0xb8, 0xa5,
+	0x91, 0x60, 0xaf, 0x97, 0xc5, 0xb7, 0x57, 0xd0, 0xe5, 0x01, 0xdf, 0xae, 0xc8, 0x4b, 0x74, 0xe5,
+	0x91, 0xf8, 0xff, 0x31, 0xfa, 0x4c, 0x83, 0x99, 0xee, 0x6e, 0x31, 0x5a, 0x1d, 0xae, 0xd0, 0xf1,
+	0xc6, 0x72, 0x02, 0xb5, 0x5e, 0x10, 0x6a, 0x3d, 0x8b, 0x2e, 0x0d, 0x57, 0x6b, 0xdd, 0x11, 0x8b,
+	0xa3, 0x4f, 0xa4, 0x56, 0xf2, 0x16, 0xcf, 0x08, 0x36, 0x9b, 0x5f, 0xb3, 0x9b, 0x92, 0xea, 0x43,
+	0xc5, 0xc7, 0x57, 0x35, 0xf4, 0x85, 0x06, 0xd3, 0x5d, 0x7d, 0x54, 0x54, 0x19, 0x76, 0xb9, 0xed,
+	0xd3, 0xf9, 0x2d, 0xad, 0x26, 0x17, 0x90, 0xd9, 0xae, 0xbf, 0x26, 0xb4, 0x5c, 0x43, 0xab, 0xc9,
+	0x36, 0xb3, 0x12, 0xf5, 0x8d, 0x7e, 0xab, 0xc1, 0x7c, 0xd7, 0x9a, 0xca, 0x8b, 0x27, 0x56, 0x3a,
+	0x71, 0x77, 0x53, 0x7f, 0x43, 0x28, 0x7b, 0x1d, 0xbd, 0x7a, 0x52, 0x65, 0x23, 0x27, 0xff, 0x5c,
+	0xe5, 0x85, 0xc0, 0xb8, 0x2b, 0x89, 0x70, 0x51, 0x6a, 0x79, 0x12, 0x0c, 0xd5, 0x6f, 0x08, 0x45,
+	0x5f, 0x45, 0x2f, 0x0f, 0x52, 0xd4, 0xf4, 0x7d, 0x5a, 0x79, 0x24, 0x31, 0xfd, 0x71, 0x85, 0xa3,
+	0x36, 0xad, 0x3c, 0x52, 0x58, 0xfe, 0x18, 0x7d, 0xa5, 0xc1, 0x5c, 0xef, 0x33, 0x1d, 0x5a, 0x1b,
+	0xe1, 0xd7, 0x3e, 0x2f, 0x9b, 0xa5, 0x17, 0x4f, 0x24, 0xa3, 0x94, 0xdf, 0x12, 0xca, 0xbf, 0x81,
+	0x6e, 0x8c, 0xa5, 0x7c, 0x45, 0x5d, 0x5c, 0xd1, 0xef, 0x34, 0xc8, 0xc7, 0x1e, 0xbb, 0xd0, 0xb0,
+	0xb7, 0x8e, 0xe3, 0x4f, 0x7d, 0xa5, 0x72, 0x52, 0x76, 0xa5, 0xf5, 0x8e, 0xd0, 0x7a, 0xab, 0x34,
+	0x9e, 0xcb, 0xd7, 0xbb, 0x9e, 0xf8, 0xd0, 0xcf, 0x34, 0xd1, 0x12, 0xee, 0xf3, 0x58, 0xf0, 0xea,
+	0xf0, 0x30, 0x18, 0xf8, 0xf6, 0x50, 0x7a, 0x61, 0xe4, 0xdf, 0x65, 0xc4, 0xa5, 0xf4, 0xa2, 0x30,
+	0x07, 0xa1, 0x39, 0x6e, 0x4e, 0x2d, 0xae, 0xc1, 0xc7, 0x9a, 0xf8, 0x8b, 0x86, 0xee, 0x0e, 0xf8,
+	0x5a, 0x12, 0xb5, 0xba, 0xdb, 0xe8, 0xa5, 0xc4, 0x7f, 0x29, 0xa2, 0xcf, 0x0b, 0x65, 0xa6, 0x51,
+	0x9e, 0x2b, 0x13, 0xb4, 0xd6, 0x3f, 0x95, 0x00, 0x1a, 0x6f, 0x75, 0xaf, 0x26, 0xa9, 0x72, 0xf1,
+	0x0e, 0x7b, 0xe9, 0x72, 0xb2, 0xb6, 0xb1, 0x7e, 0x49, 0x68, 0x70, 0x01, 0x9d, 0x1f, 0xb4, 0xbb,
+	0x54, 0x28, 0xf0, 0x6b, 0x0d, 0x16, 0xfb, 0x37, 0x52, 0xd1, 0x6b, 0x23, 0x4a, 0xce, 0xc0, 0xde,
+	0x6b, 0x29, 0x79, 0x8b, 0x51, 0x88, 0xe9, 0xcf, 0x0b, 0x5d, 0x2f, 0xa1, 0xa7, 0x07, 0xe9, 0x1a,
+	0x7b, 0x52, 0x44, 0x9f, 0x6b, 0x50, 0x88, 0xf7, 0x0e, 0x51, 0x79, 0x44, 0xca, 0xf6, 0x74, 0x2c,
+	0x4b, 0x95, 0xc4, 0xfc, 0x2a, 0x51, 0x5e, 0x12, 0xea, 0x95, 0xd1, 0xd5, 0x41, 0xea, 0xf5, 0x26,
+	0x34, 0x4f, 0x1c, 0xf4, 0x07, 0x0d, 0x66, 0x7b, 0xda, 0x50, 0xe8, 0xda, 0x90, 0x4f, 0xf7, 0x6f,
+	0x59, 0x95, 0x9e, 0x1b, 0x22, 0xd2, 0xdd, 0x5c, 0x0a, 0x12, 0x1a, 0x6d, 0x8e, 0x07, 0x43, 0x35,
+	0xf9, 0xe1, 0xf5, 0x0f, 0xb8, 0x22, 0xab, 0x1a, 0xfa, 0x87, 0x06, 0xf3, 0x7d, 0x7a, 0x2a, 0xe8,
+	0xe5, 0xa1, 0x59, 0x30, 0xa8, 0x01, 0x54, 0x7a, 0xe5, 0xa4, 0x62, 0xca, 0xfb, 0x7b, 0xc2, 0xaa,
+	0xb7, 0x4b, 0x5b, 0xa7, 0xb2, 0xaa, 0xf2, 0x81, 0x5c, 0x76, 0x5d, 0xbb, 0x82, 0xfe, 0xac, 0x01,
+	0x3a, 0xde, 0xeb, 0x41, 0x2f, 0x25, 0xa8, 0x57, 0xc7, 0x5a, 0x43, 0x27, 0xab, 0x72, 0x86, 0xb0,
+	0x65, 0x17, 0xbd, 0x3d, 0x9e, 0x2d, 0xc1, 0x65, 0x95, 0x56, 0x1e, 0x05, 0x3f, 0x1f, 0xa3, 0x3f,
+	0x69, 0x30, 0xdb, 0x73, 0x73, 0x1d, 0x1a, 0x67, 0xfd, 0x2f, 0xda, 0xa5, 0xb5, 0x93, 0x88, 0x74,
+	0x57, 0x10, 0xfd, 0xcd, 0xf1, 0xcc, 0x09, 0xef, 0xc1, 0x94, 0xef, 0xca, 0x97, 0x1a, 0x40, 0x74,
+	0x51, 0x45, 0x57, 0x47, 0xa5, 0xca, 0xf8, 0x67, 0x8d, 0x4d, 0xa1, 0xf6, 0x0d, 0xf4, 0xfa, 0x78,
+	0x85, 0x4f, 0xe5, 0xc7, 0xcd, 0xad, 0xff, 0xdd, 0xac, 0xdb, 0xac, 0xd1, 0x3a, 0x28, 0xd7, 0xbc,
+	0x66, 0x45, 0xfd, 0xdd, 0x72, 0xcf, 0xf7, 0x2b, 0x35, 0x8f, 0xc8, 0x3f, 0x85, 0x1e, 0xf4, 0x87,
+	0xc5, 0x07, 0x59, 0xf1, 0xdf, 0x8b, 0xff, 0x19, 0x00, 0x95, 0x85, 0x4a, 0x0b, 0x83, 0x2d, 0x00,
+	0x00,
 }
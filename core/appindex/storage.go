@@ -0,0 +1,44 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package appindex records which apps each user has entries for.
+//
+// Entries are stored in the map under VRF derived indexes, so the map itself
+// cannot be used to find the apps of a user. The key server records the app
+// of every accepted update so that users can audit which applications hold
+// keys under their identity. The index is a hint: clients verify each listed
+// entry against the map before trusting it.
+//
+// Domains that held entries before their updates were recorded have an
+// incomplete index. Such domains are never marked complete, so HasApp can
+// only be trusted for domains where Complete returns true.
+package appindex
+
+import "context"
+
+// Storage records the apps that users have submitted updates for.
+type Storage interface {
+	// Add records that userID has an entry for appID in domainID.
+	Add(ctx context.Context, domainID, userID, appID string) error
+	// List returns the sorted appIDs that userID has entries for in
+	// domainID.
+	List(ctx context.Context, domainID, userID string) ([]string, error)
+	// HasApp returns true if any user has an entry for appID in domainID.
+	HasApp(ctx context.Context, domainID, appID string) (bool, error)
+	// SetComplete records that every update to domainID is recorded, ie.
+	// that domainID had no entries when recording began.
+	SetComplete(ctx context.Context, domainID string) error
+	// Complete returns true if SetComplete was called for domainID.
+	Complete(ctx context.Context, domainID string) (bool, error)
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records the administrative operations performed on domains.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Entry records a single admin RPC.
+type Entry struct {
+	// DomainID is the domain the RPC operated on.
+	DomainID string
+	// Sequence is the position of the entry in the domain's audit log.
+	// It is assigned by Storage.Append.
+	Sequence int64
+	// Method is the name of the RPC, e.g. "CreateDomain".
+	Method string
+	// Caller identifies the client that made the RPC.
+	Caller string
+	// Time is when the RPC was received.
+	Time time.Time
+	// RequestHash is the SHA256 hash of the serialized request.
+	RequestHash []byte
+}
+
+// Storage is an append-only log of audit entries.
+type Storage interface {
+	// Append adds e to the end of the audit log of e.DomainID and sets
+	// e.Sequence. Entries cannot be modified or removed once appended.
+	Append(ctx context.Context, e *Entry) error
+	// List returns up to count entries of domainID, in order, starting at
+	// sequence start.
+	List(ctx context.Context, domainID string, start int64, count int32) ([]*Entry, error)
+}
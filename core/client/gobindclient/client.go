@@ -81,7 +81,7 @@ func AddKtServer(ktURL string, insecureTLS bool, ktTLSCertPEM []byte, domainInfo
 
 	cc, err := dial(ktURL, insecureTLS, ktTLSCertPEM)
 	if err != nil {
-		return fmt.Errorf("Error Dialing %v: %v", ktURL, err)
+		return fmt.Errorf("Error Dialing %v: %w", ktURL, err)
 	}
 
 	ktClient := pb.NewKeyTransparencyClient(cc)
@@ -90,7 +90,7 @@ func AddKtServer(ktURL string, insecureTLS bool, ktTLSCertPEM []byte, domainInfo
 	defer cancel()
 	config, err := ktClient.GetDomain(ctx, &pb.GetDomainRequest{})
 	if err != nil {
-		return fmt.Errorf("Error getting config: %v", err)
+		return fmt.Errorf("Error getting config: %w", err)
 	}
 
 	if len(domainInfoHash) == 0 {
@@ -98,11 +98,11 @@ func AddKtServer(ktURL string, insecureTLS bool, ktTLSCertPEM []byte, domainInfo
 	} else {
 		cj, err := objecthash.CommonJSONify(config)
 		if err != nil {
-			return fmt.Errorf("CommonJSONify(): %v", err)
+			return fmt.Errorf("CommonJSONify(): %w", err)
 		}
 		got, err := objecthash.ObjectHash(cj)
 		if err != nil {
-			return fmt.Errorf("ObjectHash(): %v", err)
+			return fmt.Errorf("ObjectHash(): %w", err)
 		}
 		if !bytes.Equal(got[:], domainInfoHash) {
 			return fmt.Errorf("The KtServer %v returned a domainInfoResponse inconsistent with the provided domainInfoHash", ktURL)
@@ -111,7 +111,7 @@ func AddKtServer(ktURL string, insecureTLS bool, ktTLSCertPEM []byte, domainInfo
 
 	client, err := grpcc.NewFromConfig(ktClient, config)
 	if err != nil {
-		return fmt.Errorf("Error adding the KtServer: %v", err)
+		return fmt.Errorf("Error adding the KtServer: %w", err)
 	}
 
 	clients[ktURL] = client
@@ -129,13 +129,13 @@ func GetEntry(ktURL, userID, appID string) ([]byte, error) {
 	defer cancel()
 	entry, smr, err := client.GetEntry(ctx, userID, appID)
 	if err != nil {
-		return nil, fmt.Errorf("GetEntry failed: %v", err)
+		return nil, fmt.Errorf("GetEntry failed: %w", err)
 	}
 	// TODO(amarcedone): Consider returning or persisting smr it to verify consistency over time
 	_ = smr
 	//encodedSmr, err := proto.Marshal(smr)
 	//if err != nil {
-	//	return nil, fmt.Errorf("GetEntry failed: error serializing smr: %v", err)
+	//	return nil, fmt.Errorf("GetEntry failed: error serializing smr: %w", err)
 	//}
 
 	return entry, nil
@@ -0,0 +1,154 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gossip exchanges the log roots that clients have verified, so that
+// a key server that shows different clients different logs is detected.
+package gossip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+// Verifier verifies the log roots of a domain. *grpcc.Client implements it.
+type Verifier interface {
+	// VerifyLogRoot verifies that root is signed by the log of the domain.
+	VerifyLogRoot(root *trillian.SignedLogRoot) error
+	// VerifyLogRoots verifies that roots, ordered by tree size, are
+	// consistent with each other and with the server's latest log root.
+	VerifyLogRoots(ctx context.Context, roots []*trillian.SignedLogRoot, opts ...grpc.CallOption) (*trillian.SignedLogRoot, error)
+}
+
+// Peer is another client, or a relay, that log roots are exchanged with.
+type Peer interface {
+	// Exchange sends root, the latest log root of domainID verified by the
+	// caller, and returns the latest log root of domainID known to the
+	// peer, or nil if it knows none.
+	Exchange(ctx context.Context, domainID string, root *trillian.SignedLogRoot) (*trillian.SignedLogRoot, error)
+}
+
+// SplitViewError occurs when two log roots of a domain are validly signed
+// but inconsistent, which proves that the log was forked.
+type SplitViewError struct {
+	DomainID string
+	// Local is the log root held by the client.
+	Local *trillian.SignedLogRoot
+	// Observed is the log root that is inconsistent with Local.
+	Observed *trillian.SignedLogRoot
+	// Err describes the inconsistency.
+	Err error
+}
+
+// Error implements error.
+func (e *SplitViewError) Error() string {
+	return fmt.Sprintf("split view of %v: log roots of sizes %v and %v are inconsistent: %v",
+		e.DomainID, e.Local.GetTreeSize(), e.Observed.GetTreeSize(), e.Err)
+}
+
+// Unwrap returns the inconsistency.
+func (e *SplitViewError) Unwrap() error { return e.Err }
+
+// Gossiper keeps the latest verified log root of a domain and checks that
+// the log roots observed by others are consistent with it.
+type Gossiper struct {
+	domainID string
+	v        Verifier
+
+	mu     sync.Mutex
+	latest *trillian.SignedLogRoot
+}
+
+// New returns a Gossiper of the log roots of domainID that starts from
+// latest, which may be nil.
+func New(domainID string, v Verifier, latest *trillian.SignedLogRoot) *Gossiper {
+	return &Gossiper{domainID: domainID, v: v, latest: latest}
+}
+
+// Latest returns the latest log root the Gossiper has verified.
+func (g *Gossiper) Latest() *trillian.SignedLogRoot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.latest
+}
+
+// Observe checks root, a log root of the domain obtained from any source,
+// against the latest log root, and keeps it if it is newer. It returns a
+// *SplitViewError if the two roots are inconsistent. Roots that are not
+// signed by the log are rejected without being compared.
+func (g *Gossiper) Observe(ctx context.Context, root *trillian.SignedLogRoot, opts ...grpc.CallOption) error {
+	if root.GetTreeSize() == 0 {
+		return nil
+	}
+	if err := g.v.VerifyLogRoot(root); err != nil {
+		return fmt.Errorf("VerifyLogRoot(): %w", err)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	latest := g.latest
+	switch {
+	case latest.GetTreeSize() == 0:
+	case root.GetTreeSize() == latest.GetTreeSize():
+		if !bytes.Equal(root.GetRootHash(), latest.GetRootHash()) {
+			return g.splitView(root, errors.New("different root hashes"))
+		}
+		return nil
+	default:
+		roots := []*trillian.SignedLogRoot{latest, root}
+		if root.GetTreeSize() < latest.GetTreeSize() {
+			roots = []*trillian.SignedLogRoot{root, latest}
+		}
+		if _, err := g.v.VerifyLogRoots(ctx, roots, opts...); err != nil {
+			if errors.Is(err, kterrors.ErrVerification) {
+				return g.splitView(root, err)
+			}
+			return fmt.Errorf("VerifyLogRoots(): %w", err)
+		}
+	}
+	if root.GetTreeSize() > latest.GetTreeSize() {
+		g.latest = root
+	}
+	return nil
+}
+
+func (g *Gossiper) splitView(observed *trillian.SignedLogRoot, err error) error {
+	return &SplitViewError{DomainID: g.domainID, Local: g.latest, Observed: observed, Err: err}
+}
+
+// Gossip exchanges the latest log root with each of peers and observes the
+// roots they return. It stops at the first split view it detects. Otherwise
+// the first error, if any, is returned once every peer has been tried.
+func (g *Gossiper) Gossip(ctx context.Context, peers []Peer, opts ...grpc.CallOption) error {
+	var firstErr error
+	for _, p := range peers {
+		root, err := p.Exchange(ctx, g.domainID, g.Latest())
+		if err == nil {
+			err = g.Observe(ctx, root, opts...)
+		}
+		if _, ok := err.(*SplitViewError); ok {
+			return err
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
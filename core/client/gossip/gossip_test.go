@@ -0,0 +1,129 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossip
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+// fakeVerifier accepts roots whose hash does not start with "x", and
+// considers roots consistent if their hashes start with the same letter.
+type fakeVerifier struct {
+	offline bool
+}
+
+func (fakeVerifier) VerifyLogRoot(root *trillian.SignedLogRoot) error {
+	if root.GetRootHash()[0] == 'x' {
+		return kterrors.Wrap(kterrors.ErrVerification, "VerifyLogRoot", errors.New("bad signature"))
+	}
+	return nil
+}
+
+func (v fakeVerifier) VerifyLogRoots(_ context.Context, roots []*trillian.SignedLogRoot, _ ...grpc.CallOption) (*trillian.SignedLogRoot, error) {
+	if v.offline {
+		return nil, errors.New("offline")
+	}
+	for i := 1; i < len(roots); i++ {
+		if roots[i-1].GetTreeSize() >= roots[i].GetTreeSize() {
+			return nil, errors.New("roots out of order")
+		}
+		if roots[i-1].GetRootHash()[0] != roots[i].GetRootHash()[0] {
+			return nil, kterrors.Wrap(kterrors.ErrVerification, "VerifyConsistencyChain", errors.New("inconsistent"))
+		}
+	}
+	return roots[len(roots)-1], nil
+}
+
+func root(size int64, hash string) *trillian.SignedLogRoot {
+	return &trillian.SignedLogRoot{TreeSize: size, RootHash: []byte(hash)}
+}
+
+func TestObserve(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc      string
+		latest    *trillian.SignedLogRoot
+		observed  *trillian.SignedLogRoot
+		offline   bool
+		wantErr   bool
+		wantSplit bool
+		wantSize  int64
+	}{
+		{desc: "first root", observed: root(5, "a5"), wantSize: 5},
+		{desc: "empty", latest: root(5, "a5"), observed: nil, wantSize: 5},
+		{desc: "same", latest: root(5, "a5"), observed: root(5, "a5"), wantSize: 5},
+		{desc: "newer", latest: root(5, "a5"), observed: root(7, "a7"), wantSize: 7},
+		{desc: "older", latest: root(5, "a5"), observed: root(3, "a3"), wantSize: 5},
+		{desc: "bad signature", latest: root(5, "a5"), observed: root(7, "x7"), wantErr: true, wantSize: 5},
+		{desc: "same size fork", latest: root(5, "a5"), observed: root(5, "b5"), wantErr: true, wantSplit: true, wantSize: 5},
+		{desc: "newer fork", latest: root(5, "a5"), observed: root(7, "b7"), wantErr: true, wantSplit: true, wantSize: 5},
+		{desc: "older fork", latest: root(5, "a5"), observed: root(3, "b3"), wantErr: true, wantSplit: true, wantSize: 5},
+		{desc: "offline", latest: root(5, "a5"), observed: root(7, "a7"), offline: true, wantErr: true, wantSize: 5},
+	} {
+		g := New("domain", fakeVerifier{offline: tc.offline}, tc.latest)
+		err := g.Observe(ctx, tc.observed)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: Observe(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		if _, got := err.(*SplitViewError); got != tc.wantSplit {
+			t.Errorf("%v: Observe(): %v, want split view %v", tc.desc, err, tc.wantSplit)
+		}
+		if got := g.Latest().GetTreeSize(); got != tc.wantSize {
+			t.Errorf("%v: Latest(): size %v, want %v", tc.desc, got, tc.wantSize)
+		}
+	}
+}
+
+func TestGossipThroughRelay(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(Handler(NewRelay()))
+	defer srv.Close()
+	peers := []Peer{&HTTPPeer{URL: srv.URL}}
+
+	alice := New("domain", fakeVerifier{}, root(5, "a5"))
+	bob := New("domain", fakeVerifier{}, root(7, "a7"))
+	mallory := New("domain", fakeVerifier{}, root(9, "b9"))
+
+	if err := alice.Gossip(ctx, peers); err != nil {
+		t.Fatalf("alice.Gossip(): %v", err)
+	}
+	if err := bob.Gossip(ctx, peers); err != nil {
+		t.Fatalf("bob.Gossip(): %v", err)
+	}
+	if err := alice.Gossip(ctx, peers); err != nil {
+		t.Fatalf("alice.Gossip(): %v", err)
+	}
+	if got := alice.Latest().GetTreeSize(); got != 7 {
+		t.Errorf("alice.Latest(): size %v, want 7", got)
+	}
+
+	// A client shown a forked log detects it.
+	if err := mallory.Gossip(ctx, peers); err == nil {
+		t.Errorf("mallory.Gossip(): nil, want split view")
+	}
+	if err := alice.Gossip(ctx, peers); err == nil {
+		t.Errorf("alice.Gossip(after fork): nil, want split view")
+	} else if _, ok := err.(*SplitViewError); !ok {
+		t.Errorf("alice.Gossip(after fork): %v, want *SplitViewError", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossip
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// maxRootSize bounds the size of the log roots accepted by a relay.
+const maxRootSize = 64 << 10
+
+// Relay is a Peer that remembers the largest log root of each domain it has
+// been sent. Clients that do not know each other gossip through a shared
+// relay. A relay does not verify roots; clients verify the roots it returns.
+type Relay struct {
+	mu    sync.Mutex
+	roots map[string]*trillian.SignedLogRoot
+}
+
+// NewRelay returns an empty relay.
+func NewRelay() *Relay {
+	return &Relay{roots: make(map[string]*trillian.SignedLogRoot)}
+}
+
+// Exchange implements Peer. It returns the root held before root was sent.
+func (r *Relay) Exchange(_ context.Context, domainID string, root *trillian.SignedLogRoot) (*trillian.SignedLogRoot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	held := r.roots[domainID]
+	if root.GetTreeSize() > held.GetTreeSize() {
+		r.roots[domainID] = root
+	}
+	return held, nil
+}
+
+// Handler serves p over HTTP. Log roots are exchanged by POSTing a
+// serialized SignedLogRoot to /{domain_id}. The response holds the
+// serialized log root of the peer, and is empty if the peer has none.
+func Handler(p Peer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		domainID, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/"))
+		if err != nil || domainID == "" {
+			http.Error(w, "missing domain", http.StatusNotFound)
+			return
+		}
+		b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRootSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		root := &trillian.SignedLogRoot{}
+		if err := proto.Unmarshal(b, root); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		held, err := p.Exchange(r.Context(), domainID, root)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if held == nil {
+			return
+		}
+		out, err := proto.Marshal(held)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(out)
+	})
+}
+
+// HTTPPeer is a Peer served by Handler at URL.
+type HTTPPeer struct {
+	URL string
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Exchange implements Peer.
+func (p *HTTPPeer) Exchange(ctx context.Context, domainID string, root *trillian.SignedLogRoot) (*trillian.SignedLogRoot, error) {
+	if root == nil {
+		root = &trillian.SignedLogRoot{}
+	}
+	b, err := proto.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost,
+		strings.TrimSuffix(p.URL, "/")+"/"+url.PathEscape(domainID), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	cli := p.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	resp, err := cli.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRootSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gossip peer %v: %v: %s", p.URL, resp.Status, bytes.TrimSpace(body))
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	held := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(body, held); err != nil {
+		return nil, fmt.Errorf("gossip peer %v: %w", p.URL, err)
+	}
+	return held, nil
+}
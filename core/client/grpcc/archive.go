@@ -0,0 +1,175 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ExportHistory assembles every epoch's proof material for a user in the range
+// [start, end] into an archive that can be verified offline with VerifyArchive.
+// Each epoch contains the user's entry or proof of absence, the map root and its
+// log proofs, and the mutations in that epoch that modified the user's index.
+// All proof material is verified before the archive is returned.
+func (c *Client) ExportHistory(ctx context.Context, userID, appID string, start, end int64, opts ...grpc.CallOption) (*pb.UserProofArchive, error) {
+	if start < 0 {
+		return nil, fmt.Errorf("start=%v, want >= 0", start)
+	}
+	domain, err := c.servedDomain(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	archive := &pb.UserProofArchive{
+		Domain: domain,
+		AppId:  appID,
+		UserId: userID,
+	}
+	epochsWant := end - start + 1
+	var treeSize int64
+	for int64(len(archive.Epochs)) < epochsWant {
+		// Each page proves the consistency of its log root with the
+		// log root of the previous page. FirstTreeSize is left unset
+		// for the first page so that the archive can be verified
+		// without reference to any other log root.
+		resp, err := c.cli.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+			DomainId:      c.domainID,
+			UserId:        userID,
+			AppId:         appID,
+			Start:         start,
+			PageSize:      min(int32(end-start+1), pageSize),
+			FirstTreeSize: treeSize,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range resp.GetValues() {
+			index, err := c.kt.Index(v.GetVrfProof(), c.domainID, appID, userID)
+			if err != nil {
+				return nil, err
+			}
+			mutations, err := c.indexMutations(ctx, v.GetSmr().GetMapRevision(), index, opts...)
+			if err != nil {
+				return nil, err
+			}
+			archive.Epochs = append(archive.Epochs, &pb.EpochProof{
+				Entry:     v,
+				Mutations: mutations,
+			})
+			treeSize = v.GetLogRoot().GetTreeSize()
+		}
+		if resp.NextStart == 0 {
+			break // No more data.
+		}
+		start = resp.NextStart // Fetch the next block of results.
+	}
+	if int64(len(archive.Epochs)) < epochsWant {
+		return nil, ErrIncomplete
+	}
+	if _, err := c.kt.VerifyArchive(ctx, c.domainID, archive); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// ExportProof packages the current entry of userID in appID, the log root
+// the client trusts and the domain config into a bundle that can be verified
+// offline with kt.VerifyBundle. The entry is verified before it is exported.
+func (c *Client) ExportProof(ctx context.Context, userID, appID string, opts ...grpc.CallOption) (*pb.ProofBundle, error) {
+	domain, err := c.servedDomain(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	trusted := c.trusted
+	e, err := c.cli.GetEntry(ctx, &pb.GetEntryRequest{
+		DomainId:      c.domainID,
+		UserId:        userID,
+		AppId:         appID,
+		FirstTreeSize: trusted.TreeSize,
+	}, opts...)
+	if err != nil {
+		return nil, rpcError("GetEntry", err)
+	}
+	if err := checkStale("GetEntry", &trusted, e.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &trusted, e)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, err
+	}
+	b := &pb.ProofBundle{
+		Domain: domain,
+		AppId:  appID,
+		UserId: userID,
+		Entry:  e,
+	}
+	if trusted.TreeSize > 0 {
+		b.TrustedRoot = &trusted
+	}
+	return b, nil
+}
+
+// indexMutations returns the mutations in epoch that modified index.
+func (c *Client) indexMutations(ctx context.Context, epoch int64, index []byte, opts ...grpc.CallOption) ([]*pb.MutationProof, error) {
+	if epoch < 1 {
+		return nil, nil // The first epoch has no mutations.
+	}
+	var mutations []*pb.MutationProof
+	token := ""
+	for {
+		resp, err := c.cli.ListMutations(ctx, &pb.ListMutationsRequest{
+			DomainId:  c.domainID,
+			Epoch:     epoch,
+			PageToken: token,
+		}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("ListMutations(%v): %w", epoch, err)
+		}
+		for _, m := range resp.GetMutations() {
+			if bytes.Equal(m.GetMutation().GetIndex(), index) {
+				mutations = append(mutations, m)
+			}
+		}
+		token = resp.GetNextPageToken()
+		if token == "" {
+			return mutations, nil
+		}
+	}
+}
+
+// VerifyArchive verifies all the proofs in a UserProofArchive without contacting
+// the server, under the keys of config, the domain the caller trusts, rather
+// than those of the domain in the archive. See kt.Verifier.VerifyArchive for
+// the checks. It returns the latest log root of the archive, which callers
+// should check for consistency with the log roots they trust.
+func VerifyArchive(ctx context.Context, config *pb.Domain, archive *pb.UserProofArchive) (*trillian.SignedLogRoot, error) {
+	if got, want := archive.GetDomain().GetDomainId(), config.GetDomainId(); got != want {
+		return nil, fmt.Errorf("archive of domain %q, want %q", got, want)
+	}
+	c, err := NewFromConfig(nil, config)
+	if err != nil {
+		return nil, err
+	}
+	return c.kt.VerifyArchive(ctx, config.GetDomainId(), archive)
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	"github.com/google/keytransparency/core/client/kt"
+	kterrors "github.com/google/keytransparency/core/errors"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// AuditLog verifies that the log leaves at indexes start to end-1 are the
+// map roots of revisions start to end-1, in order. Each map root is proven
+// to be the log leaf at the index of its revision, so a log that
+// equivocates by inserting extra leaves fails the audit at the first index
+// whose leaf is not the map root of that revision.
+func (c *Client) AuditLog(ctx context.Context, start, end int64, opts ...grpc.CallOption) error {
+	trusted := c.trusted
+	newest := &trusted
+	var prev *trillian.SignedMapRoot
+	for revision := start; revision < end; revision++ {
+		epoch, err := c.cli.GetEpoch(ctx, &pb.GetEpochRequest{
+			DomainId:      c.domainID,
+			Epoch:         revision,
+			FirstTreeSize: trusted.TreeSize,
+		}, opts...)
+		if err != nil {
+			return rpcError(fmt.Sprintf("GetEpoch(%v)", revision), err)
+		}
+		root, err := c.kt.VerifyEpoch(ctx, &trusted, epoch)
+		if err != nil {
+			return fmt.Errorf("epoch %v: %w", revision, err)
+		}
+		if err := checkRevision(revision, prev, epoch.GetSmr()); err != nil {
+			return kterrors.Wrap(ErrVerification, "AuditLog", err)
+		}
+		if root.GetTreeSize() > newest.GetTreeSize() {
+			newest = root
+		}
+		prev = epoch.GetSmr()
+	}
+	return c.updateTrusted(ctx, newest)
+}
+
+// checkRevision returns an error unless smr is the map root of revision
+// and, if prev is the map root of the previous revision, is newer than prev.
+func checkRevision(revision int64, prev, smr *trillian.SignedMapRoot) error {
+	if got := smr.GetMapRevision(); got != revision {
+		return fmt.Errorf("log index %v holds revision %v: %w", revision, got, kt.ErrRevisionIndex)
+	}
+	if prev != nil && smr.GetTimestampNanos() <= prev.GetTimestampNanos() {
+		return fmt.Errorf("revision %v is not newer than revision %v", revision, prev.GetMapRevision())
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+
+	"github.com/google/keytransparency/core/client/kt"
+)
+
+func TestCheckRevision(t *testing.T) {
+	smr := func(revision, ts int64) *trillian.SignedMapRoot {
+		return &trillian.SignedMapRoot{MapRevision: revision, TimestampNanos: ts}
+	}
+	for _, tc := range []struct {
+		desc      string
+		revision  int64
+		prev, smr *trillian.SignedMapRoot
+		wantErr   bool
+		wantIndex bool
+	}{
+		{desc: "first", revision: 3, smr: smr(3, 10)},
+		{desc: "next", revision: 4, prev: smr(3, 10), smr: smr(4, 11)},
+		{desc: "extra leaf", revision: 4, prev: smr(3, 10), smr: smr(3, 10), wantErr: true, wantIndex: true},
+		{desc: "wrong revision", revision: 4, smr: smr(5, 10), wantErr: true, wantIndex: true},
+		{desc: "not newer", revision: 4, prev: smr(3, 10), smr: smr(4, 10), wantErr: true},
+	} {
+		err := checkRevision(tc.revision, tc.prev, tc.smr)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: checkRevision(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		if got := errors.Is(err, kt.ErrRevisionIndex); got != tc.wantIndex {
+			t.Errorf("%v: checkRevision(): %v, want ErrRevisionIndex %v", tc.desc, err, tc.wantIndex)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BudgetError occurs when the responses downloaded by an operation exceed
+// its proof-size budget.
+type BudgetError struct {
+	// Budget is the maximum number of bytes the operation could download.
+	Budget int64
+	// Used is the number of bytes accounted for when the budget was
+	// exceeded. Responses that were rejected by the transport for being
+	// larger than the remaining budget are not included.
+	Used int64
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("proof size budget exceeded: used %v of %v bytes", e.Used, e.Budget)
+}
+
+// Bandwidth accounts for the bytes downloaded by client operations.
+// Attach a Bandwidth to a context with WithBandwidth to measure and limit
+// the responses of the calls made with that context. A Bandwidth may be used
+// by several operations at once.
+type Bandwidth struct {
+	// Budget is the maximum number of bytes that may be downloaded.
+	// Zero means unlimited.
+	Budget int64
+	// Used is the number of bytes downloaded so far. Read it with Spent
+	// while operations that use the Bandwidth are running.
+	Used int64
+
+	mu sync.Mutex
+	// reserved is the part of the budget set aside for responses that are
+	// being downloaded concurrently.
+	reserved int64
+}
+
+type bandwidthKey struct{}
+
+// WithBandwidth returns a context that accounts downloaded bytes to b.
+func WithBandwidth(ctx context.Context, b *Bandwidth) context.Context {
+	return context.WithValue(ctx, bandwidthKey{}, b)
+}
+
+// bandwidthFrom returns the Bandwidth attached to ctx, or an unlimited
+// Bandwidth if there is none.
+func bandwidthFrom(ctx context.Context) *Bandwidth {
+	if b, ok := ctx.Value(bandwidthKey{}).(*Bandwidth); ok && b != nil {
+		return b
+	}
+	return &Bandwidth{}
+}
+
+// Spent returns the number of bytes downloaded so far.
+func (b *Bandwidth) Spent() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Used
+}
+
+// unreserved returns the budget that is neither used nor reserved.
+// b.mu must be held.
+func (b *Bandwidth) unreserved() int64 {
+	if remaining := b.Budget - b.Used - b.reserved; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// limit returns opts with the size of the next response limited to n bytes,
+// so that oversized responses are rejected before they are fully
+// downloaded.
+func limit(opts []grpc.CallOption, n int64) []grpc.CallOption {
+	if n > math.MaxInt32 {
+		n = math.MaxInt32
+	}
+	return append(opts[:len(opts):len(opts)], grpc.MaxCallRecvMsgSize(int(n)))
+}
+
+// callOpts limits the size of the next response to the remaining budget.
+// It is meant for calls that are made one at a time; concurrent calls
+// reserve their part of the budget with reserve.
+func (b *Bandwidth) callOpts(opts []grpc.CallOption) []grpc.CallOption {
+	if b.Budget <= 0 {
+		return opts
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return limit(opts, b.unreserved())
+}
+
+// reserve sets aside an equal share of the remaining budget for one of
+// shares responses that are downloaded concurrently, and limits the size of
+// the response to it. The returned reservation must be passed to settle
+// once the response has been received, so that all responses together
+// never exceed the budget.
+func (b *Bandwidth) reserve(opts []grpc.CallOption, shares int) ([]grpc.CallOption, int64) {
+	if b.Budget <= 0 {
+		return opts, 0
+	}
+	if shares < 1 {
+		shares = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.unreserved() / int64(shares)
+	b.reserved += r
+	return limit(opts, r), r
+}
+
+// settle releases reservation, as returned by reserve, and accounts resp
+// like account.
+func (b *Bandwidth) settle(reservation int64, resp proto.Message, err error) error {
+	b.mu.Lock()
+	b.reserved -= reservation
+	b.mu.Unlock()
+	return b.account(resp, err)
+}
+
+// tooLargePrefix starts the message of the error with which the transport
+// rejects responses larger than the limit set by limit.
+const tooLargePrefix = "grpc: received message larger than max"
+
+// account records the size of resp. It returns a BudgetError if the budget
+// is exceeded, and err otherwise. Servers also fail calls with
+// ResourceExhausted when their quotas are exhausted; those errors are
+// returned unchanged.
+func (b *Bandwidth) account(resp proto.Message, err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		st, ok := status.FromError(err)
+		if b.Budget > 0 && ok && st.Code() == codes.ResourceExhausted &&
+			strings.HasPrefix(st.Message(), tooLargePrefix) {
+			return &BudgetError{Budget: b.Budget, Used: b.Used}
+		}
+		return err
+	}
+	b.Used += int64(proto.Size(resp))
+	if b.Budget > 0 && b.Used > b.Budget {
+		return &BudgetError{Budget: b.Budget, Used: b.Used}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestBandwidthAccount(t *testing.T) {
+	resp := &pb.GetEntryResponse{VrfProof: make([]byte, 100)}
+	size := int64(proto.Size(resp))
+	errOther := errors.New("other")
+	errTooLarge := status.Errorf(codes.ResourceExhausted, "grpc: received message larger than max (%d vs. %d)", size, size-1)
+	errQuota := status.Error(codes.ResourceExhausted, "Quota updates of 10 exceeded")
+	for _, tc := range []struct {
+		desc       string
+		budget     int64
+		used       int64
+		err        error
+		wantUsed   int64
+		wantBudget bool
+		wantErr    error
+	}{
+		{desc: "unlimited", wantUsed: size},
+		{desc: "within budget", budget: size, wantUsed: size},
+		{desc: "over budget", budget: size, used: 1, wantUsed: size + 1, wantBudget: true},
+		{desc: "transport limit", budget: size, err: errTooLarge, wantBudget: true},
+		{desc: "transport limit without budget", err: errTooLarge, wantErr: errTooLarge},
+		{desc: "server quota", budget: size, err: errQuota, wantErr: errQuota},
+		{desc: "other error", budget: size, err: errOther, wantErr: errOther},
+	} {
+		b := &Bandwidth{Budget: tc.budget, Used: tc.used}
+		err := b.account(resp, tc.err)
+		if _, ok := err.(*BudgetError); ok != tc.wantBudget {
+			t.Errorf("%v: account(): %v, want BudgetError: %v", tc.desc, err, tc.wantBudget)
+		}
+		if !tc.wantBudget && tc.wantErr == nil && err != nil {
+			t.Errorf("%v: account(): %v, want nil", tc.desc, err)
+		}
+		if tc.wantErr != nil && (err == nil || err.Error() != tc.wantErr.Error()) {
+			t.Errorf("%v: account(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+		if tc.err == nil && b.Used != tc.wantUsed {
+			t.Errorf("%v: Used: %v, want %v", tc.desc, b.Used, tc.wantUsed)
+		}
+	}
+}
+
+func TestBandwidthFrom(t *testing.T) {
+	if b := bandwidthFrom(context.Background()); b == nil || b.Budget != 0 {
+		t.Errorf("bandwidthFrom(background): %v, want unlimited", b)
+	}
+	want := &Bandwidth{Budget: 10}
+	if got := bandwidthFrom(WithBandwidth(context.Background(), want)); got != want {
+		t.Errorf("bandwidthFrom(): %v, want %v", got, want)
+	}
+	if got := len(want.callOpts(nil)); got != 1 {
+		t.Errorf("callOpts(): %v options, want 1", got)
+	}
+	if got := len((&Bandwidth{}).callOpts(nil)); got != 0 {
+		t.Errorf("callOpts(unlimited): %v options, want 0", got)
+	}
+}
+
+func TestBandwidthReserve(t *testing.T) {
+	b := &Bandwidth{Budget: 100, Used: 20}
+	var reservations []int64
+	for shares := 4; shares > 0; shares-- {
+		_, r := b.reserve(nil, shares)
+		reservations = append(reservations, r)
+	}
+	var total int64
+	for _, r := range reservations {
+		if r != 20 {
+			t.Errorf("reserve(): %v, want 20", r)
+		}
+		total += r
+	}
+	if total > b.Budget-b.Used {
+		t.Errorf("reserved %v bytes, want at most %v", total, b.Budget-b.Used)
+	}
+	if _, r := b.reserve(nil, 1); r != 0 {
+		t.Errorf("reserve(exhausted): %v, want 0", r)
+	}
+
+	// Reservations are released as responses arrive, concurrently.
+	resp := &pb.GetEntryResponse{VrfProof: make([]byte, 5)}
+	var wg sync.WaitGroup
+	for _, r := range reservations {
+		wg.Add(1)
+		go func(r int64) {
+			defer wg.Done()
+			if err := b.settle(r, resp, nil); err != nil {
+				t.Errorf("settle(): %v", err)
+			}
+		}(r)
+	}
+	wg.Wait()
+	if got, want := b.Spent(), 20+4*int64(proto.Size(resp)); got != want {
+		t.Errorf("Spent(): %v, want %v", got, want)
+	}
+	if _, r := b.reserve(nil, 1); r != b.Budget-b.Spent() {
+		t.Errorf("reserve(): %v, want the %v bytes left", r, b.Budget-b.Spent())
+	}
+}
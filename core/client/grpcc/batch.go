@@ -0,0 +1,129 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/keytransparency/core/client/kt"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// DefaultBatchParallelism is the number of entries BatchGetEntries fetches
+// and verifies concurrently by default.
+const DefaultBatchParallelism = 8
+
+// BatchResult is the outcome of looking up one user's entry in a batch.
+type BatchResult struct {
+	UserID string
+	// Profile is the user's profile, or nil if the user has no entry.
+	Profile []byte
+	// Smr is the verified map root the entry was read from.
+	Smr *trillian.SignedMapRoot
+	// Err is ErrPurged if the profile has been purged, and the reason the
+	// entry could not be fetched or verified otherwise.
+	Err error
+}
+
+// BatchGetEntries looks up the entries of userIDs for appID like GetEntry.
+// Up to BatchParallelism entries are fetched and verified at a time. Log
+// roots shared by several entries are verified once. A result is returned
+// for each of userIDs, in order; an error is only returned if ctx is done
+// before all entries have been fetched.
+// The size of the responses is accounted to the Bandwidth attached to ctx.
+// The transport limits each response to an equal share of the budget that
+// is left when it is requested.
+func (c *Client) BatchGetEntries(ctx context.Context, appID string, userIDs []string, opts ...grpc.CallOption) ([]*BatchResult, error) {
+	parallelism := c.BatchParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	bw := bandwidthFrom(ctx)
+	// Every entry proves consistency with the same trusted root.
+	trusted := c.trusted
+
+	responses := make([]*pb.GetEntryResponse, len(userIDs))
+	errs := make([]error, len(userIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				callOpts, reserved := bw.reserve(opts, parallelism)
+				resp, err := c.cli.GetEntry(ctx, &pb.GetEntryRequest{
+					DomainId:      c.domainID,
+					UserId:        userIDs[i],
+					AppId:         appID,
+					FirstTreeSize: trusted.TreeSize,
+				}, callOpts...)
+				responses[i], errs[i] = resp, bw.settle(reserved, resp, err)
+			}
+		}()
+	}
+	for i := range userIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]*BatchResult, len(userIDs))
+	var entries []*kt.BatchEntry
+	var fetched []*BatchResult
+	for i, userID := range userIDs {
+		results[i] = &BatchResult{UserID: userID}
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			continue
+		}
+		entries = append(entries, &kt.BatchEntry{AppID: appID, UserID: userID, Response: responses[i]})
+		fetched = append(fetched, results[i])
+	}
+
+	var newest *trillian.SignedLogRoot
+	for i, err := range c.kt.VerifyBatchParallel(ctx, c.domainID, &trusted, entries, parallelism) {
+		e, r := entries[i].Response, fetched[i]
+		if err == nil {
+			err = c.checkRoots(ctx, e)
+		}
+		switch {
+		case err != nil:
+			r.Err = err
+			continue
+		case e.GetCommittedPurged():
+			r.Err = ErrPurged
+		case e.GetCommitted() != nil:
+			r.Profile = e.GetCommitted().GetData()
+		}
+		r.Smr = e.GetSmr()
+		if e.GetLogRoot().GetTreeSize() > newest.GetTreeSize() {
+			newest = e.GetLogRoot()
+		}
+	}
+	if newest != nil {
+		if err := c.updateTrusted(ctx, newest); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var (
+	// ErrNotPinned occurs when a TrustStore holds no anchor for a domain.
+	// The domain must be bootstrapped with StartBootstrap first.
+	ErrNotPinned = errors.New("domain has not been bootstrapped")
+	// ErrFingerprintMismatch occurs when the fingerprint a user confirmed
+	// is not the fingerprint of the domain being pinned.
+	ErrFingerprintMismatch = errors.New("fingerprint does not match domain")
+)
+
+// TrustStore persists the trust anchors of bootstrapped domains.
+type TrustStore interface {
+	// Load returns the anchor pinned for domainID, or ErrNotPinned.
+	Load(ctx context.Context, domainID string) (*pb.TrustAnchor, error)
+	// Save pins anchor, replacing any anchor pinned for the same domain.
+	Save(ctx context.Context, anchor *pb.TrustAnchor) error
+}
+
+// Bootstrap is a trust on first use ceremony that has not been completed.
+// It holds domain material fetched from a server and verified to be self
+// consistent, but not yet trusted. Applications show Fingerprint to the user,
+// who compares it with a value obtained out of band, and call Confirm only if
+// the two match.
+type Bootstrap struct {
+	// Fingerprint is the fingerprint of the domain to be pinned.
+	Fingerprint Fingerprint
+
+	cli     pb.KeyTransparencyClient
+	domain  *pb.Domain
+	logRoot *trillian.SignedLogRoot
+}
+
+// StartBootstrap fetches domainID's config and current roots from the server
+// and verifies the roots against the keys in the config. Nothing is trusted
+// or persisted until Confirm is called.
+func StartBootstrap(ctx context.Context, ktClient pb.KeyTransparencyClient, domainID string, opts ...grpc.CallOption) (*Bootstrap, error) {
+	domain, err := ktClient.GetDomain(ctx, &pb.GetDomainRequest{DomainId: domainID}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetDomain(%v): %w", domainID, err)
+	}
+	if got := domain.GetDomainId(); got != domainID {
+		return nil, fmt.Errorf("GetDomain(%v) returned domain %v", domainID, got)
+	}
+	c, err := NewFromConfig(ktClient, domain)
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := ktClient.GetLatestEpoch(ctx, &pb.GetLatestEpochRequest{DomainId: domainID}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestEpoch(%v): %w", domainID, err)
+	}
+	if _, err := c.kt.VerifyEpoch(ctx, &trillian.SignedLogRoot{}, epoch); err != nil {
+		return nil, fmt.Errorf("VerifyEpoch(): %w", err)
+	}
+	return &Bootstrap{
+		Fingerprint: DomainFingerprint(domain),
+		cli:         ktClient,
+		domain:      domain,
+		logRoot:     epoch.GetLogRoot(),
+	}, nil
+}
+
+// Confirm pins the domain in store and returns a client that trusts it.
+// numeric is the numeric fingerprint the user confirmed; it guards against
+// confirming a ceremony other than the one that was shown.
+func (b *Bootstrap) Confirm(ctx context.Context, store TrustStore, numeric string) (*Client, error) {
+	if numeric != b.Fingerprint.Numeric {
+		return nil, ErrFingerprintMismatch
+	}
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	anchor := &pb.TrustAnchor{
+		Domain:      b.domain,
+		LogRoot:     b.logRoot,
+		Fingerprint: numeric,
+		PinnedTime:  now,
+	}
+	if err := store.Save(ctx, anchor); err != nil {
+		return nil, fmt.Errorf("saving trust anchor: %w", err)
+	}
+	return newFromAnchor(b.cli, anchor)
+}
+
+// NewFromTrustStore returns a client for a domain pinned in store. It returns
+// ErrNotPinned if the domain has not been bootstrapped. The pinned config is
+// used as is; the server is not asked for its config again.
+func NewFromTrustStore(ctx context.Context, ktClient pb.KeyTransparencyClient, store TrustStore, domainID string) (*Client, error) {
+	anchor, err := store.Load(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	return newFromAnchor(ktClient, anchor)
+}
+
+func newFromAnchor(ktClient pb.KeyTransparencyClient, anchor *pb.TrustAnchor) (*Client, error) {
+	if got, want := DomainFingerprint(anchor.GetDomain()).Numeric, anchor.GetFingerprint(); got != want {
+		return nil, ErrFingerprintMismatch
+	}
+	c, err := NewFromConfig(ktClient, anchor.GetDomain())
+	if err != nil {
+		return nil, err
+	}
+	if root := anchor.GetLogRoot(); root != nil {
+		c.trusted = *root
+	}
+	return c, nil
+}
+
+// FileTrustStore keeps one trust anchor per domain in a directory.
+type FileTrustStore struct {
+	Dir string
+}
+
+func (s FileTrustStore) name(domainID string) string {
+	return url.PathEscape(domainID) + ".anchor"
+}
+
+// Load implements TrustStore.
+func (s FileTrustStore) Load(_ context.Context, domainID string) (*pb.TrustAnchor, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, s.name(domainID)))
+	if os.IsNotExist(err) {
+		return nil, ErrNotPinned
+	} else if err != nil {
+		return nil, err
+	}
+	anchor := &pb.TrustAnchor{}
+	if err := proto.Unmarshal(b, anchor); err != nil {
+		return nil, fmt.Errorf("reading trust anchor for %v: %w", domainID, err)
+	}
+	return anchor, nil
+}
+
+// Save implements TrustStore. The anchor is written to a temporary file that
+// is renamed into place, so a crash never leaves a partial anchor behind.
+func (s FileTrustStore) Save(_ context.Context, anchor *pb.TrustAnchor) error {
+	b, err := proto.Marshal(anchor)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Dir, s.name(anchor.GetDomain().GetDomainId()), b)
+}
@@ -0,0 +1,164 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func testDomain(id string, vrf []byte) *pb.Domain {
+	return &pb.Domain{
+		DomainId: id,
+		Log:      &trillian.Tree{TreeId: 1, PublicKey: &keyspb.PublicKey{Der: []byte("log")}},
+		Map:      &trillian.Tree{TreeId: 2, PublicKey: &keyspb.PublicKey{Der: []byte("map")}},
+		Vrf:      &keyspb.PublicKey{Der: vrf},
+	}
+}
+
+func TestDomainFingerprint(t *testing.T) {
+	d := testDomain("domain", []byte("vrf"))
+	fp := DomainFingerprint(d)
+	if !regexp.MustCompile(`^\d{5}( \d{5}){5}$`).MatchString(fp.Numeric) {
+		t.Errorf("Numeric: %q, want six groups of five digits", fp.Numeric)
+	}
+	if got := len(strings.Split(fp.Emoji, " ")); got != 7 {
+		t.Errorf("Emoji: %q has %v emoji, want 7", fp.Emoji, got)
+	}
+	if got := len(strings.Split(fp.Words, ", ")); got != 7 {
+		t.Errorf("Words: %q has %v words, want 7", fp.Words, got)
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		change func(d *pb.Domain)
+	}{
+		{desc: "domain id", change: func(d *pb.Domain) { d.DomainId = "other" }},
+		{desc: "vrf", change: func(d *pb.Domain) { d.Vrf.Der = []byte("vrf2") }},
+		{desc: "vrf algorithm", change: func(d *pb.Domain) { d.VrfAlgorithm = pb.VrfAlgorithm_ED25519 }},
+		{desc: "log key", change: func(d *pb.Domain) { d.Log.PublicKey.Der = []byte("log2") }},
+		{desc: "log hash strategy", change: func(d *pb.Domain) { d.Log.HashStrategy = trillian.HashStrategy_OBJECT_RFC6962_SHA256 }},
+		{desc: "map key", change: func(d *pb.Domain) { d.Map.PublicKey.Der = []byte("map2") }},
+		{desc: "map tree", change: func(d *pb.Domain) { d.Map.TreeId = 3 }},
+		{desc: "app vrf", change: func(d *pb.Domain) {
+			d.AppVrfs = map[string]*keyspb.PublicKey{"app": {Der: []byte("app")}}
+		}},
+		{desc: "app vrf key", change: func(d *pb.Domain) {
+			d.AppVrfs = map[string]*keyspb.PublicKey{"app": {Der: []byte("app2")}}
+		}},
+		{desc: "previous vrf", change: func(d *pb.Domain) { d.PreviousVrf = &keyspb.PublicKey{Der: []byte("old")} }},
+		{desc: "previous vrf expiry", change: func(d *pb.Domain) {
+			d.PreviousVrfExpiry = &tspb.Timestamp{Seconds: 2}
+		}},
+		{desc: "previous log key", change: func(d *pb.Domain) {
+			d.LogKeyRotation.PreviousKey = &keyspb.PublicKey{Der: []byte("old")}
+		}},
+		{desc: "log overlap end", change: func(d *pb.Domain) {
+			d.LogKeyRotation.OverlapEnd = &tspb.Timestamp{Seconds: 2}
+		}},
+		{desc: "previous map key", change: func(d *pb.Domain) {
+			d.MapKeyRotation.PreviousKey = &keyspb.PublicKey{Der: []byte("old")}
+		}},
+		{desc: "map overlap end", change: func(d *pb.Domain) {
+			d.MapKeyRotation.OverlapEnd = &tspb.Timestamp{Nanos: 1}
+		}},
+		{desc: "endorsement quorum", change: func(d *pb.Domain) { d.EndorsementPolicy.Quorum = 0 }},
+		{desc: "endorser", change: func(d *pb.Domain) {
+			d.EndorsementPolicy.Keys[1] = &keyspb.PublicKey{Der: []byte("e3")}
+		}},
+		{desc: "endorser removed", change: func(d *pb.Domain) {
+			d.EndorsementPolicy.Keys = d.EndorsementPolicy.Keys[:1]
+			d.EndorsementPolicy.Quorum = 1
+		}},
+	} {
+		pinned := testDomain("domain", []byte("vrf"))
+		pinned.AppVrfs = map[string]*keyspb.PublicKey{"other": {Der: []byte("other")}}
+		pinned.PreviousVrf = &keyspb.PublicKey{Der: []byte("prev")}
+		pinned.PreviousVrfExpiry = &tspb.Timestamp{Seconds: 1}
+		pinned.LogKeyRotation = &pb.TreeKeyRotation{
+			PreviousKey: &keyspb.PublicKey{Der: []byte("prevlog")},
+			PublicKey:   pinned.Log.PublicKey,
+			OverlapEnd:  &tspb.Timestamp{Seconds: 1},
+		}
+		pinned.MapKeyRotation = &pb.TreeKeyRotation{
+			PreviousKey: &keyspb.PublicKey{Der: []byte("prevmap")},
+			PublicKey:   pinned.Map.PublicKey,
+			OverlapEnd:  &tspb.Timestamp{Seconds: 1},
+		}
+		pinned.EndorsementPolicy = &pb.EndorsementPolicy{
+			Keys:   []*keyspb.PublicKey{{Der: []byte("e1")}, {Der: []byte("e2")}},
+			Quorum: 2,
+		}
+		changed := proto.Clone(pinned).(*pb.Domain)
+		tc.change(changed)
+		if got, want := DomainFingerprint(changed), DomainFingerprint(pinned); got.Numeric == want.Numeric || got.Emoji == want.Emoji {
+			t.Errorf("%v: DomainFingerprint() did not change", tc.desc)
+		}
+	}
+}
+
+func TestFileTrustStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "truststore")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := FileTrustStore{Dir: dir}
+
+	if _, err := store.Load(ctx, "a/b"); err != ErrNotPinned {
+		t.Errorf("Load(): %v, want %v", err, ErrNotPinned)
+	}
+	for _, vrf := range []string{"vrf1", "vrf2"} {
+		d := testDomain("a/b", []byte(vrf))
+		anchor := &pb.TrustAnchor{
+			Domain:      d,
+			LogRoot:     &trillian.SignedLogRoot{TreeSize: 5},
+			Fingerprint: DomainFingerprint(d).Numeric,
+		}
+		if err := store.Save(ctx, anchor); err != nil {
+			t.Fatalf("Save(): %v", err)
+		}
+		got, err := store.Load(ctx, "a/b")
+		if err != nil {
+			t.Fatalf("Load(): %v", err)
+		}
+		if !proto.Equal(got, anchor) {
+			t.Errorf("Load(): %v, want %v", got, anchor)
+		}
+	}
+}
+
+func TestConfirmFingerprint(t *testing.T) {
+	ctx := context.Background()
+	b := &Bootstrap{
+		Fingerprint: DomainFingerprint(testDomain("domain", []byte("vrf"))),
+		domain:      testDomain("domain", []byte("vrf")),
+	}
+	if _, err := b.Confirm(ctx, FileTrustStore{Dir: "/nonexistent"}, "00000 00000"); err != ErrFingerprintMismatch {
+		t.Errorf("Confirm(wrong fingerprint): %v, want %v", err, ErrFingerprintMismatch)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// entryKey identifies an entry at a map revision.
+type entryKey struct {
+	domainID string
+	appID    string
+	userID   string
+	revision int64
+}
+
+// DefaultCacheMaxAge is how long an EntryCache serves an entry by default.
+const DefaultCacheMaxAge = time.Minute
+
+// cachedResponse is a verified entry and the time it was cached.
+type cachedResponse struct {
+	e     *pb.GetEntryResponse
+	added time.Time
+}
+
+// EntryCache holds verified entries by the map revision they were read at.
+// Clients that use an EntryCache answer GetEntry from it, without a round
+// trip, until the trusted log root advances to a newer map revision or the
+// entry is older than MaxAge. Since the trusted log root only advances when
+// an entry is read from the server, MaxAge bounds how long a client may go
+// without learning of newer revisions. An EntryCache may be shared by the
+// clients of several domains.
+type EntryCache struct {
+	// MaxAge is how long an entry is served after it was cached.
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[entryKey]cachedResponse
+}
+
+// NewEntryCache returns an empty EntryCache that serves entries for
+// DefaultCacheMaxAge.
+func NewEntryCache() *EntryCache {
+	return &EntryCache{
+		MaxAge:  DefaultCacheMaxAge,
+		entries: make(map[entryKey]cachedResponse),
+	}
+}
+
+// get returns the entry cached for key, unless it was cached more than MaxAge
+// before now.
+func (c *EntryCache) get(key entryKey, now time.Time) (*pb.GetEntryResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if now.Sub(r.added) > c.MaxAge {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return r.e, true
+}
+
+func (c *EntryCache) put(key entryKey, e *pb.GetEntryResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedResponse{e: e, added: now}
+}
+
+// invalidate removes the entries of domainID that were read before map
+// revision.
+func (c *EntryCache) invalidate(domainID string, revision int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.domainID == domainID && k.revision < revision {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// UseEntryCache makes the client keep the entries it verifies in cache, and
+// answer GetEntry from it while the trusted log root stays at the map
+// revision the entry was read at, the entry is younger than the MaxAge of
+// cache and its map root passes the client's freshness check.
+func (c *Client) UseEntryCache(cache *EntryCache) {
+	c.cache = cache
+}
+
+// cachedEntry returns the cached entry of userID in appID at the latest map
+// revision in the trusted log root, if it is still fresh at now.
+func (c *Client) cachedEntry(userID, appID string, now time.Time) (*pb.GetEntryResponse, bool) {
+	if c.cache == nil || c.trusted.TreeSize == 0 {
+		return nil, false
+	}
+	e, ok := c.cache.get(entryKey{
+		domainID: c.domainID,
+		appID:    appID,
+		userID:   userID,
+		revision: c.trusted.TreeSize - 1,
+	}, now)
+	if !ok || c.checkFreshness(e, now) != nil {
+		return nil, false
+	}
+	return e, true
+}
+
+// cacheEntry caches e, which must have been verified, if it is at the latest
+// map revision in the trusted log root.
+func (c *Client) cacheEntry(userID, appID string, e *pb.GetEntryResponse) {
+	revision := e.GetSmr().GetMapRevision()
+	if c.cache == nil || revision != c.trusted.TreeSize-1 {
+		return
+	}
+	c.cache.put(entryKey{
+		domainID: c.domainID,
+		appID:    appID,
+		userID:   userID,
+		revision: revision,
+	}, e, time.Now())
+}
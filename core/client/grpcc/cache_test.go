@@ -0,0 +1,119 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// offlineServer fails every call.
+type offlineServer struct {
+	pb.KeyTransparencyClient
+	calls int
+}
+
+func (s *offlineServer) GetEntry(context.Context, *pb.GetEntryRequest, ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	s.calls++
+	return nil, errors.New("offline")
+}
+
+func TestEntryCache(t *testing.T) {
+	ctx := context.Background()
+	srv := &offlineServer{}
+	c := &Client{cli: srv, domainID: "domain", trusted: trillian.SignedLogRoot{TreeSize: 5}}
+	c.UseEntryCache(NewEntryCache())
+	for _, tc := range []struct {
+		userID   string
+		revision int64
+		e        *pb.GetEntryResponse
+	}{
+		{userID: "alice", revision: 4, e: &pb.GetEntryResponse{Committed: &pb.Committed{Data: []byte("alice")}}},
+		{userID: "bob", revision: 4, e: &pb.GetEntryResponse{CommittedPurged: true}},
+		{userID: "carol", revision: 4, e: &pb.GetEntryResponse{}},
+		{userID: "dave", revision: 3, e: &pb.GetEntryResponse{Committed: &pb.Committed{Data: []byte("dave")}}},
+	} {
+		tc.e.Smr = &trillian.SignedMapRoot{MapRevision: tc.revision}
+		c.cacheEntry(tc.userID, "app", tc.e)
+	}
+
+	for _, tc := range []struct {
+		userID    string
+		want      string
+		wantErr   error
+		wantCalls int
+	}{
+		{userID: "alice", want: "alice"},
+		{userID: "bob", wantErr: ErrPurged},
+		{userID: "carol"},
+		{userID: "dave", wantCalls: 1}, // Not at the trusted revision.
+	} {
+		srv.calls = 0
+		got, smr, err := c.GetEntry(ctx, tc.userID, "app")
+		if srv.calls != tc.wantCalls {
+			t.Errorf("GetEntry(%v): %v calls, want %v", tc.userID, srv.calls, tc.wantCalls)
+		}
+		if tc.wantCalls > 0 {
+			continue
+		}
+		if string(got) != tc.want || err != tc.wantErr || smr.GetMapRevision() != 4 {
+			t.Errorf("GetEntry(%v): %s, %v, %v, want %s, revision 4, %v", tc.userID, got, smr, err, tc.want, tc.wantErr)
+		}
+	}
+
+	// Entries are dropped once the trusted root advances.
+	if err := c.updateTrusted(ctx, &trillian.SignedLogRoot{TreeSize: 6}); err != nil {
+		t.Fatalf("updateTrusted(): %v", err)
+	}
+	if got := len(c.cache.entries); got != 0 {
+		t.Errorf("updateTrusted(): %v cached entries, want 0", got)
+	}
+	srv.calls = 0
+	if _, _, err := c.GetEntry(ctx, "alice", "app"); err == nil || srv.calls != 1 {
+		t.Errorf("GetEntry(after update): %v, %v calls, want error from 1 call", err, srv.calls)
+	}
+}
+
+func TestEntryCacheFreshness(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		desc         string
+		smrTime      time.Time
+		maxStaleness time.Duration
+		at           time.Time
+		want         bool
+	}{
+		{desc: "fresh", smrTime: now, at: now, want: true},
+		{desc: "cached too long", smrTime: now, at: now.Add(DefaultCacheMaxAge + time.Second)},
+		{desc: "stale map root", smrTime: now.Add(-2 * time.Hour), maxStaleness: time.Hour, at: now},
+		{desc: "staleness disabled", smrTime: now.Add(-2 * time.Hour), at: now, want: true},
+	} {
+		c := &Client{domainID: "domain", trusted: trillian.SignedLogRoot{TreeSize: 5}, MaxStaleness: tc.maxStaleness}
+		c.UseEntryCache(NewEntryCache())
+		c.cacheEntry("alice", "app", &pb.GetEntryResponse{
+			Smr: &trillian.SignedMapRoot{MapRevision: 4, TimestampNanos: tc.smrTime.UnixNano()},
+		})
+		if _, got := c.cachedEntry("alice", "app", tc.at); got != tc.want {
+			t.Errorf("%v: cachedEntry(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
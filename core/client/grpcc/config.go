@@ -0,0 +1,80 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// MaxConfigAge is the age after which a signed domain config is no longer
+// accepted.
+const MaxConfigAge = 24 * time.Hour
+
+var (
+	// ErrUnsignedConfig occurs when a domain config that must be signed is
+	// not.
+	ErrUnsignedConfig = errors.New("domain config is not signed")
+	// ErrConfigSignature occurs when a domain config is not signed by any
+	// trusted config key.
+	ErrConfigSignature = errors.New("domain config signature is not trusted")
+	// ErrStaleConfig occurs when a signed domain config is older than
+	// MaxConfigAge or was signed in the future.
+	ErrStaleConfig = errors.New("domain config is stale")
+)
+
+// VerifyConfig checks that the signed config of d is signed by one of keys
+// no more than MaxConfigAge before now, and returns the domain it contains.
+// Only the returned domain is authenticated; the other fields of d are not.
+func VerifyConfig(d *pb.Domain, keys []crypto.PublicKey, now time.Time) (*pb.Domain, error) {
+	signed := d.GetSignedConfig()
+	if signed == nil {
+		return nil, ErrUnsignedConfig
+	}
+	trusted := false
+	for _, k := range keys {
+		if err := tcrypto.Verify(k, signed.GetConfig(), signed.GetSignature()); err == nil {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return nil, ErrConfigSignature
+	}
+
+	var config pb.DomainConfig
+	if err := proto.Unmarshal(signed.GetConfig(), &config); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal(DomainConfig): %w", err)
+	}
+	ts, err := ptypes.Timestamp(config.GetTimestamp())
+	if err != nil {
+		return nil, fmt.Errorf("invalid config timestamp: %w", err)
+	}
+	if now.Sub(ts) > MaxConfigAge || ts.Sub(now) > DefaultMaxClockSkew {
+		return nil, ErrStaleConfig
+	}
+	if got, want := config.GetDomain().GetDomainId(), d.GetDomainId(); got != want {
+		return nil, fmt.Errorf("signed config is for domain %v, want %v", got, want)
+	}
+	return config.GetDomain(), nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// signedDomain returns a copy of d carrying a config signed by signer at ts.
+func signedDomain(t *testing.T, signer *tcrypto.Signer, d *pb.Domain, ts time.Time) *pb.Domain {
+	tsPB, err := ptypes.TimestampProto(ts)
+	if err != nil {
+		t.Fatalf("TimestampProto(): %v", err)
+	}
+	config, err := proto.Marshal(&pb.DomainConfig{Domain: d, Timestamp: tsPB})
+	if err != nil {
+		t.Fatalf("proto.Marshal(): %v", err)
+	}
+	sig, err := signer.Sign(config)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	ret := proto.Clone(d).(*pb.Domain)
+	ret.SignedConfig = &pb.SignedDomainConfig{Config: config, Signature: sig}
+	return ret
+}
+
+func newConfigSigner(t *testing.T) *tcrypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	return tcrypto.NewSHA256Signer(key)
+}
+
+func TestVerifyConfig(t *testing.T) {
+	now := time.Now()
+	signer, other := newConfigSigner(t), newConfigSigner(t)
+	keys := []crypto.PublicKey{other.Public(), signer.Public()}
+	d := testDomain("domain", []byte("vrf"))
+
+	for _, tc := range []struct {
+		desc    string
+		config  func() *pb.Domain
+		wantErr error
+	}{
+		{desc: "valid", config: func() *pb.Domain { return signedDomain(t, signer, d, now.Add(-time.Hour)) }},
+		{desc: "unsigned", config: func() *pb.Domain { return d }, wantErr: ErrUnsignedConfig},
+		{desc: "untrusted key", wantErr: ErrConfigSignature, config: func() *pb.Domain {
+			return signedDomain(t, newConfigSigner(t), d, now)
+		}},
+		{desc: "tampered", wantErr: ErrConfigSignature, config: func() *pb.Domain {
+			c := signedDomain(t, signer, d, now)
+			c.SignedConfig.Config = append(c.SignedConfig.Config, 0)
+			return c
+		}},
+		{desc: "stale", wantErr: ErrStaleConfig, config: func() *pb.Domain {
+			return signedDomain(t, signer, d, now.Add(-MaxConfigAge-time.Minute))
+		}},
+		{desc: "future", wantErr: ErrStaleConfig, config: func() *pb.Domain {
+			return signedDomain(t, signer, d, now.Add(time.Hour))
+		}},
+	} {
+		got, err := VerifyConfig(tc.config(), keys, now)
+		if err != tc.wantErr {
+			t.Errorf("%v: VerifyConfig(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !proto.Equal(got, d) {
+			t.Errorf("%v: VerifyConfig(): %v, want %v", tc.desc, got, d)
+		}
+	}
+
+	// Unsigned fields are not trusted.
+	c := signedDomain(t, signer, d, now)
+	c.Vrf.Der = []byte("attacker vrf")
+	got, err := VerifyConfig(c, keys, now)
+	if err != nil {
+		t.Fatalf("VerifyConfig(): %v", err)
+	}
+	if !proto.Equal(got, d) {
+		t.Errorf("VerifyConfig(): %v, want the signed domain %v", got, d)
+	}
+
+	// The signed config must be for the requested domain.
+	c = signedDomain(t, signer, testDomain("other", []byte("vrf")), now)
+	c.DomainId = "domain"
+	if _, err := VerifyConfig(c, keys, now); err == nil {
+		t.Errorf("VerifyConfig(other domain): nil, want error")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ErrConflict occurs when the entry an update modifies was changed by
+// another update while CompareAndSwap is set. Errors of this kind are
+// *ConflictError.
+var ErrConflict = errors.New("entry changed by a conflicting update")
+
+// ConflictError describes the entry written by the update that conflicted
+// with a mutation, so that the caller can decide whether to merge or abort.
+type ConflictError struct {
+	// Mutation is the update that was not applied. It is unchanged.
+	Mutation *entry.Mutation
+	// Profile is the profile data of the competing entry.
+	Profile []byte
+	// Purged is true if the profile data of the competing entry was purged.
+	Purged bool
+	// Leaf is the leaf value of the competing entry. Mutation.Rebase(Leaf)
+	// followed by Retry overwrites the competing entry.
+	Leaf []byte
+	// Smr is the verified map root the competing entry was read at.
+	Smr *trillian.SignedMapRoot
+}
+
+// newConflictError returns the conflict of m with the verified entry e.
+func newConflictError(m *entry.Mutation, e *pb.GetEntryResponse) *ConflictError {
+	return &ConflictError{
+		Mutation: m,
+		Profile:  e.GetCommitted().GetData(),
+		Purged:   e.GetCommittedPurged(),
+		Leaf:     e.GetLeafProof().GetLeaf().GetLeafValue(),
+		Smr:      e.GetSmr(),
+	}
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v at revision %v", ErrConflict, e.Smr.GetMapRevision())
+}
+
+// Is returns true for ErrConflict.
+func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
@@ -0,0 +1,59 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestConflictError(t *testing.T) {
+	m := &entry.Mutation{}
+	for _, tc := range []struct {
+		e           *pb.GetEntryResponse
+		wantProfile string
+		wantPurged  bool
+	}{
+		{e: &pb.GetEntryResponse{
+			Committed: &pb.Committed{Data: []byte("other device")},
+			LeafProof: &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{LeafValue: []byte("leaf")}},
+			Smr:       &trillian.SignedMapRoot{MapRevision: 3},
+		}, wantProfile: "other device"},
+		{e: &pb.GetEntryResponse{
+			CommittedPurged: true,
+			LeafProof:       &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{LeafValue: []byte("leaf")}},
+			Smr:             &trillian.SignedMapRoot{MapRevision: 3},
+		}, wantPurged: true},
+	} {
+		var err error = newConflictError(m, tc.e)
+		if !errors.Is(err, ErrConflict) || errors.Is(err, ErrPreviousChanged) {
+			t.Errorf("newConflictError(): %v, want only %v", err, ErrConflict)
+		}
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("errors.As(%v): false, want *ConflictError", err)
+		}
+		if conflict.Mutation != m || string(conflict.Profile) != tc.wantProfile ||
+			conflict.Purged != tc.wantPurged || string(conflict.Leaf) != "leaf" ||
+			conflict.Smr.GetMapRevision() != 3 {
+			t.Errorf("newConflictError(): %+v, want profile %q, purged %v at revision 3", conflict, tc.wantProfile, tc.wantPurged)
+		}
+	}
+}
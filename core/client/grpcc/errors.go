@@ -0,0 +1,68 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"fmt"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+// Kinds of failure returned by the client. Callers test for them with
+// errors.Is.
+var (
+	// ErrVerification occurs when a response fails verification.
+	ErrVerification = kterrors.ErrVerification
+	// ErrStaleRoot occurs when the server's log root is older than the
+	// trusted log root, e.g. because the server is a lagging replica.
+	ErrStaleRoot = kterrors.ErrStaleRoot
+	// ErrNotSequenced occurs when an update has been queued but is not yet
+	// visible. ErrRetry is of this kind.
+	ErrNotSequenced = kterrors.ErrNotSequenced
+	// ErrQuotaExceeded occurs when the server rejects a request because the
+	// caller exceeded its quota.
+	ErrQuotaExceeded = kterrors.ErrQuotaExceeded
+)
+
+// rpcError returns err, the failure of the call op, as an error of the kind
+// that its gRPC status code maps to. Budget errors are returned unchanged.
+func rpcError(op string, err error) error {
+	if _, ok := err.(*BudgetError); ok {
+		return err
+	}
+	var kind error
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		kind = ErrQuotaExceeded
+	case codes.OutOfRange:
+		kind = ErrStaleRoot
+	default:
+		return fmt.Errorf("%v: %w", op, err)
+	}
+	return kterrors.Wrap(kind, op, err)
+}
+
+// checkStale returns ErrStaleRoot if root is older than trusted.
+func checkStale(op string, trusted, root *trillian.SignedLogRoot) error {
+	if root.GetTreeSize() >= trusted.GetTreeSize() {
+		return nil
+	}
+	return kterrors.Wrap(ErrStaleRoot, op,
+		fmt.Errorf("log root of size %v, trusted size %v", root.GetTreeSize(), trusted.GetTreeSize()))
+}
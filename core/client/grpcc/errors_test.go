@@ -0,0 +1,71 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestRPCError(t *testing.T) {
+	budget := &BudgetError{Budget: 10, Used: 20}
+	for _, tc := range []struct {
+		err  error
+		want error
+	}{
+		{err: status.Errorf(codes.ResourceExhausted, "quota"), want: ErrQuotaExceeded},
+		{err: status.Errorf(codes.OutOfRange, "behind"), want: ErrStaleRoot},
+		{err: status.Errorf(codes.Internal, "oops"), want: nil},
+		{err: budget, want: budget},
+	} {
+		got := rpcError("op", tc.err)
+		for _, kind := range []error{ErrQuotaExceeded, ErrStaleRoot, ErrVerification, ErrNotSequenced} {
+			if errors.Is(got, kind) != (kind == tc.want) {
+				t.Errorf("rpcError(%v): %v, want kind %v", tc.err, got, tc.want)
+			}
+		}
+		if !errors.Is(got, tc.err) {
+			t.Errorf("rpcError(%v): %v, want it wrapped", tc.err, got)
+		}
+	}
+}
+
+// staleServer serves entries with a log root of a fixed size.
+type staleServer struct {
+	pb.KeyTransparencyClient
+	treeSize int64
+}
+
+func (s *staleServer) GetEntry(context.Context, *pb.GetEntryRequest, ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	return &pb.GetEntryResponse{LogRoot: &trillian.SignedLogRoot{TreeSize: s.treeSize}}, nil
+}
+
+func TestGetEntryStaleRoot(t *testing.T) {
+	c := &Client{cli: &staleServer{treeSize: 4}, trusted: trillian.SignedLogRoot{TreeSize: 5}}
+	if _, _, err := c.GetEntry(context.Background(), "alice", "app"); !errors.Is(err, ErrStaleRoot) {
+		t.Errorf("GetEntry(): %v, want %v", err, ErrStaleRoot)
+	}
+	if !errors.Is(ErrRetry, ErrNotSequenced) {
+		t.Errorf("errors.Is(ErrRetry, ErrNotSequenced): false, want true")
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// DefaultFailoverBackoff is how long an endpoint that failed is avoided.
+const DefaultFailoverBackoff = 30 * time.Second
+
+// ErrNoEndpoints occurs when a Failover has no endpoints.
+var ErrNoEndpoints = errors.New("failover: no endpoints")
+
+// Endpoint is one front end of a key server.
+type Endpoint struct {
+	// Name identifies the endpoint, e.g. by its address.
+	Name string
+	// Client sends calls to the endpoint.
+	Client pb.KeyTransparencyClient
+}
+
+// endpointState is the health of an endpoint.
+type endpointState struct {
+	Endpoint
+	failedAt time.Time
+	conn     *grpc.ClientConn
+}
+
+// Failover is a pb.KeyTransparencyClient that sends each call to the first
+// healthy endpoint in order of preference. Calls that fail because an
+// endpoint is unavailable are retried on the next endpoint. An endpoint that
+// fails is avoided for Backoff, after which it is tried again, so that calls
+// return to a preferred endpoint once it recovers. If no endpoint is healthy,
+// all are tried.
+//
+// A single Client should be created over a Failover, rather than one Client
+// per endpoint, so that every endpoint is verified against the same trusted
+// log root. An endpoint that serves a view of the log inconsistent with the
+// roots served by the others then fails verification.
+type Failover struct {
+	// Backoff is how long an endpoint that failed is avoided.
+	Backoff time.Duration
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	now       func() time.Time
+}
+
+// NewFailover returns a Failover over endpoints, in order of preference.
+func NewFailover(endpoints ...Endpoint) *Failover {
+	f := &Failover{Backoff: DefaultFailoverBackoff, now: time.Now}
+	for _, e := range endpoints {
+		f.endpoints = append(f.endpoints, &endpointState{Endpoint: e})
+	}
+	return f
+}
+
+// DialFailover connects to addrs, in order of preference, and returns a
+// Failover over them. Connections are established in the background and
+// reconnect automatically after failures. Close releases them.
+func DialFailover(addrs []string, opts ...grpc.DialOption) (*Failover, error) {
+	f := NewFailover()
+	for _, addr := range addrs {
+		cc, err := grpc.Dial(addr, opts...)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.endpoints = append(f.endpoints, &endpointState{
+			Endpoint: Endpoint{Name: addr, Client: pb.NewKeyTransparencyClient(cc)},
+			conn:     cc,
+		})
+	}
+	return f, nil
+}
+
+// Close closes the connections opened by DialFailover.
+func (f *Failover) Close() error {
+	var firstErr error
+	for _, e := range f.endpoints {
+		if e.conn == nil {
+			continue
+		}
+		if err := e.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Healthy returns the names of the endpoints that are not being avoided.
+func (f *Failover) Healthy() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for _, e := range f.endpoints {
+		if f.healthy(e) {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
+
+// healthy returns true if e has not failed within the backoff.
+// f.mu must be held.
+func (f *Failover) healthy(e *endpointState) bool {
+	return e.failedAt.IsZero() || f.now().Sub(e.failedAt) >= f.Backoff
+}
+
+// order returns the endpoints to try, healthy ones first.
+func (f *Failover) order() []*endpointState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var healthy, unhealthy []*endpointState
+	for _, e := range f.endpoints {
+		if f.healthy(e) {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// mark records whether a call to e succeeded.
+func (f *Failover) mark(e *endpointState, failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if failed {
+		e.failedAt = f.now()
+	} else {
+		e.failedAt = time.Time{}
+	}
+}
+
+// call runs fn with the client of each endpoint in turn until it does not
+// fail with an error that warrants failover. The last error is returned.
+func (f *Failover) call(ctx context.Context, fn func(pb.KeyTransparencyClient) error) error {
+	err := ErrNoEndpoints
+	for _, e := range f.order() {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		err = fn(e.Client)
+		if !isUnavailable(err) {
+			f.mark(e, false)
+			return err
+		}
+		Vlog.Printf("Endpoint %v unavailable: %v", e.Name, err)
+		f.mark(e, true)
+	}
+	return err
+}
+
+// isUnavailable returns true if err shows that an endpoint could not serve a
+// call, as opposed to an error in the call itself.
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// GetDomain implements pb.KeyTransparencyClient.
+func (f *Failover) GetDomain(ctx context.Context, in *pb.GetDomainRequest, opts ...grpc.CallOption) (*pb.Domain, error) {
+	var resp *pb.Domain
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetDomain(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetEpoch implements pb.KeyTransparencyClient.
+func (f *Failover) GetEpoch(ctx context.Context, in *pb.GetEpochRequest, opts ...grpc.CallOption) (*pb.Epoch, error) {
+	var resp *pb.Epoch
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetEpoch(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetLatestEpoch implements pb.KeyTransparencyClient.
+func (f *Failover) GetLatestEpoch(ctx context.Context, in *pb.GetLatestEpochRequest, opts ...grpc.CallOption) (*pb.Epoch, error) {
+	var resp *pb.Epoch
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetLatestEpoch(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetEpochStream implements pb.KeyTransparencyClient.
+func (f *Failover) GetEpochStream(ctx context.Context, in *pb.GetEpochRequest, opts ...grpc.CallOption) (pb.KeyTransparency_GetEpochStreamClient, error) {
+	var resp pb.KeyTransparency_GetEpochStreamClient
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetEpochStream(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// ListMutations implements pb.KeyTransparencyClient.
+func (f *Failover) ListMutations(ctx context.Context, in *pb.ListMutationsRequest, opts ...grpc.CallOption) (*pb.ListMutationsResponse, error) {
+	var resp *pb.ListMutationsResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.ListMutations(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// ListMutationsStream implements pb.KeyTransparencyClient.
+func (f *Failover) ListMutationsStream(ctx context.Context, in *pb.ListMutationsRequest, opts ...grpc.CallOption) (pb.KeyTransparency_ListMutationsStreamClient, error) {
+	var resp pb.KeyTransparency_ListMutationsStreamClient
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.ListMutationsStream(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetEntry implements pb.KeyTransparencyClient.
+func (f *Failover) GetEntry(ctx context.Context, in *pb.GetEntryRequest, opts ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	var resp *pb.GetEntryResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetEntry(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// ListEntryHistory implements pb.KeyTransparencyClient.
+func (f *Failover) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRequest, opts ...grpc.CallOption) (*pb.ListEntryHistoryResponse, error) {
+	var resp *pb.ListEntryHistoryResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.ListEntryHistory(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// UpdateEntry implements pb.KeyTransparencyClient.
+func (f *Failover) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest, opts ...grpc.CallOption) (*pb.UpdateEntryResponse, error) {
+	var resp *pb.UpdateEntryResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.UpdateEntry(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetServerCapabilities implements pb.KeyTransparencyClient.
+func (f *Failover) GetServerCapabilities(ctx context.Context, in *pb.GetServerCapabilitiesRequest, opts ...grpc.CallOption) (*pb.ServerCapabilities, error) {
+	var resp *pb.ServerCapabilities
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetServerCapabilities(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetServerVersion implements pb.KeyTransparencyClient.
+func (f *Failover) GetServerVersion(ctx context.Context, in *pb.GetServerVersionRequest, opts ...grpc.CallOption) (*pb.ServerVersion, error) {
+	var resp *pb.ServerVersion
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetServerVersion(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetDomainStats implements pb.KeyTransparencyClient.
+func (f *Failover) GetDomainStats(ctx context.Context, in *pb.GetDomainStatsRequest, opts ...grpc.CallOption) (*pb.DomainStats, error) {
+	var resp *pb.DomainStats
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetDomainStats(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetLogConsistencyChain implements pb.KeyTransparencyClient.
+func (f *Failover) GetLogConsistencyChain(ctx context.Context, in *pb.GetLogConsistencyChainRequest, opts ...grpc.CallOption) (*pb.LogConsistencyChain, error) {
+	var resp *pb.LogConsistencyChain
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetLogConsistencyChain(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// ListUserApps implements pb.KeyTransparencyClient.
+func (f *Failover) ListUserApps(ctx context.Context, in *pb.ListUserAppsRequest, opts ...grpc.CallOption) (*pb.ListUserAppsResponse, error) {
+	var resp *pb.ListUserAppsResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.ListUserApps(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// WatchKeyChanges implements pb.KeyTransparencyClient.
+func (f *Failover) WatchKeyChanges(ctx context.Context, in *pb.WatchKeyChangesRequest, opts ...grpc.CallOption) (pb.KeyTransparency_WatchKeyChangesClient, error) {
+	var resp pb.KeyTransparency_WatchKeyChangesClient
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.WatchKeyChanges(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// SetKeyChangeWebhook implements pb.KeyTransparencyClient.
+func (f *Failover) SetKeyChangeWebhook(ctx context.Context, in *pb.SetKeyChangeWebhookRequest, opts ...grpc.CallOption) (*pb.SetKeyChangeWebhookResponse, error) {
+	var resp *pb.SetKeyChangeWebhookResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.SetKeyChangeWebhook(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// GetEntryAtRevision implements pb.KeyTransparencyClient.
+func (f *Failover) GetEntryAtRevision(ctx context.Context, in *pb.GetEntryAtRevisionRequest, opts ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	var resp *pb.GetEntryResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.GetEntryAtRevision(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// CreatePermalink implements pb.KeyTransparencyClient.
+func (f *Failover) CreatePermalink(ctx context.Context, in *pb.CreatePermalinkRequest, opts ...grpc.CallOption) (*pb.CreatePermalinkResponse, error) {
+	var resp *pb.CreatePermalinkResponse
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.CreatePermalink(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+// WatchEntry implements pb.KeyTransparencyClient.
+func (f *Failover) WatchEntry(ctx context.Context, in *pb.WatchEntryRequest, opts ...grpc.CallOption) (pb.KeyTransparency_WatchEntryClient, error) {
+	var resp pb.KeyTransparency_WatchEntryClient
+	err := f.call(ctx, func(cli pb.KeyTransparencyClient) (err error) {
+		resp, err = cli.WatchEntry(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
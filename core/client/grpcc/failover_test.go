@@ -0,0 +1,94 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// frontEnd serves GetServerVersion, or fails with err.
+type frontEnd struct {
+	pb.KeyTransparencyClient
+	version string
+	err     error
+	calls   int
+}
+
+func (s *frontEnd) GetServerVersion(context.Context, *pb.GetServerVersionRequest, ...grpc.CallOption) (*pb.ServerVersion, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &pb.ServerVersion{Version: s.version}, nil
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	unavailable := status.Error(codes.Unavailable, "down")
+	primary := &frontEnd{version: "primary", err: unavailable}
+	secondary := &frontEnd{version: "secondary"}
+	f := NewFailover(Endpoint{Name: "primary", Client: primary}, Endpoint{Name: "secondary", Client: secondary})
+	f.now = func() time.Time { return now }
+
+	for _, tc := range []struct {
+		desc         string
+		advance      time.Duration
+		primaryErr   error
+		secondaryErr error
+		want         string
+		wantCode     codes.Code
+		wantHealthy  []string
+		wantPrimary  int
+	}{
+		{desc: "primary down", primaryErr: unavailable, want: "secondary", wantHealthy: []string{"secondary"}, wantPrimary: 1},
+		{desc: "primary avoided", primaryErr: unavailable, want: "secondary", wantHealthy: []string{"secondary"}},
+		{desc: "primary recovered", advance: DefaultFailoverBackoff, want: "primary", wantHealthy: []string{"primary", "secondary"}, wantPrimary: 1},
+		{desc: "call error", primaryErr: status.Error(codes.InvalidArgument, "bad"), wantCode: codes.InvalidArgument, wantHealthy: []string{"primary", "secondary"}, wantPrimary: 1},
+		{desc: "all down", primaryErr: unavailable, secondaryErr: unavailable, wantCode: codes.Unavailable, wantPrimary: 1},
+		{desc: "unhealthy tried", primaryErr: unavailable, want: "secondary", wantHealthy: []string{"secondary"}, wantPrimary: 1},
+	} {
+		now = now.Add(tc.advance)
+		primary.err, secondary.err, primary.calls = tc.primaryErr, tc.secondaryErr, 0
+		v, err := f.GetServerVersion(ctx, &pb.GetServerVersionRequest{})
+		if got := status.Code(err); got != tc.wantCode {
+			t.Errorf("%v: GetServerVersion(): %v, want %v", tc.desc, err, tc.wantCode)
+		}
+		if got := v.GetVersion(); got != tc.want {
+			t.Errorf("%v: GetServerVersion(): %v, want %v", tc.desc, got, tc.want)
+		}
+		if got := f.Healthy(); !reflect.DeepEqual(got, tc.wantHealthy) {
+			t.Errorf("%v: Healthy(): %v, want %v", tc.desc, got, tc.wantHealthy)
+		}
+		if primary.calls != tc.wantPrimary {
+			t.Errorf("%v: primary called %v times, want %v", tc.desc, primary.calls, tc.wantPrimary)
+		}
+	}
+}
+
+func TestFailoverNoEndpoints(t *testing.T) {
+	if _, err := NewFailover().GetServerVersion(context.Background(), &pb.GetServerVersionRequest{}); err != ErrNoEndpoints {
+		t.Errorf("GetServerVersion(): %v, want %v", err, ErrNoEndpoints)
+	}
+}
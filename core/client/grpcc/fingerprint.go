@@ -0,0 +1,141 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Fingerprint is a short rendering of a domain's identity that people can
+// compare out of band, e.g. against a value published by the operator or
+// shown on another device. All three forms encode the same digest.
+type Fingerprint struct {
+	// Numeric is six groups of five digits.
+	Numeric string
+	// Emoji is seven emoji.
+	Emoji string
+	// Words names the emoji, for people who cannot see them.
+	Words string
+}
+
+// sasEmoji is the emoji table of the Matrix short authentication string
+// scheme, chosen to be easy to tell apart and to name.
+var sasEmoji = [64]struct{ emoji, word string }{
+	{"🐶", "dog"}, {"🐱", "cat"}, {"🦁", "lion"}, {"🐎", "horse"},
+	{"🦄", "unicorn"}, {"🐷", "pig"}, {"🐘", "elephant"}, {"🐰", "rabbit"},
+	{"🐼", "panda"}, {"🐓", "rooster"}, {"🐧", "penguin"}, {"🐢", "turtle"},
+	{"🐟", "fish"}, {"🐙", "octopus"}, {"🦋", "butterfly"}, {"🌷", "flower"},
+	{"🌳", "tree"}, {"🌵", "cactus"}, {"🍄", "mushroom"}, {"🌏", "globe"},
+	{"🌙", "moon"}, {"☁️", "cloud"}, {"🔥", "fire"}, {"🍌", "banana"},
+	{"🍎", "apple"}, {"🍓", "strawberry"}, {"🌽", "corn"}, {"🍕", "pizza"},
+	{"🎂", "cake"}, {"❤️", "heart"}, {"😀", "smiley"}, {"🤖", "robot"},
+	{"🎩", "hat"}, {"👓", "glasses"}, {"🔧", "spanner"}, {"🎅", "santa"},
+	{"👍", "thumbs up"}, {"☂️", "umbrella"}, {"⌛", "hourglass"}, {"⏰", "clock"},
+	{"🎁", "gift"}, {"💡", "light bulb"}, {"📕", "book"}, {"✏️", "pencil"},
+	{"📎", "paperclip"}, {"✂️", "scissors"}, {"🔒", "lock"}, {"🔑", "key"},
+	{"🔨", "hammer"}, {"☎️", "telephone"}, {"🏁", "flag"}, {"🚂", "train"},
+	{"🚲", "bicycle"}, {"✈️", "aeroplane"}, {"🚀", "rocket"}, {"🏆", "trophy"},
+	{"⚽", "ball"}, {"🎸", "guitar"}, {"🎺", "trumpet"}, {"🔔", "bell"},
+	{"⚓", "anchor"}, {"🎧", "headphones"}, {"📁", "folder"}, {"📌", "pin"},
+}
+
+// DomainFingerprint returns the fingerprint of the parts of a domain that
+// clients pin: every field that kt.OptionsFromDomain trusts. These are its
+// identifier, the parameters and keys of its log and map trees and their
+// previous keys, its VRF keys, and its endorsement policy. Log roots are
+// excluded because they change every epoch; they are authenticated by the
+// pinned log key instead. Clients must be bootstrapped again to trust app VRF
+// keys or key rotations added after the domain was pinned.
+func DomainFingerprint(d *pb.Domain) Fingerprint {
+	h := sha256.New()
+	write := func(b []byte) {
+		var l [8]byte
+		binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+		h.Write(l[:])
+		h.Write(b)
+	}
+	writeInt := func(i int64) {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		write(b[:])
+	}
+	writeTime := func(ts *tspb.Timestamp) {
+		writeInt(ts.GetSeconds())
+		writeInt(int64(ts.GetNanos()))
+	}
+	writeRotation := func(r *pb.TreeKeyRotation) {
+		write(r.GetPreviousKey().GetDer())
+		write(r.GetPublicKey().GetDer())
+		writeTime(r.GetRotateTime())
+		writeTime(r.GetOverlapEnd())
+	}
+	write([]byte("keytransparency-domain-fingerprint-v1"))
+	write([]byte(d.GetDomainId()))
+	writeInt(d.GetLog().GetTreeId())
+	writeInt(int64(d.GetLog().GetHashStrategy()))
+	write(d.GetLog().GetPublicKey().GetDer())
+	writeInt(d.GetMap().GetTreeId())
+	writeInt(int64(d.GetMap().GetHashStrategy()))
+	write(d.GetMap().GetPublicKey().GetDer())
+	writeInt(int64(d.GetVrfAlgorithm()))
+	write(d.GetVrf().GetDer())
+	appIDs := make([]string, 0, len(d.GetAppVrfs()))
+	for appID := range d.GetAppVrfs() {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Strings(appIDs)
+	writeInt(int64(len(appIDs)))
+	for _, appID := range appIDs {
+		write([]byte(appID))
+		write(d.GetAppVrfs()[appID].GetDer())
+	}
+	write(d.GetPreviousVrf().GetDer())
+	writeTime(d.GetPreviousVrfExpiry())
+	writeRotation(d.GetLogKeyRotation())
+	writeRotation(d.GetMapKeyRotation())
+	writeInt(int64(d.GetEndorsementPolicy().GetQuorum()))
+	writeInt(int64(len(d.GetEndorsementPolicy().GetKeys())))
+	for _, k := range d.GetEndorsementPolicy().GetKeys() {
+		write(k.GetDer())
+	}
+	digest := h.Sum(nil)
+
+	groups := make([]string, 6)
+	for i := range groups {
+		// Five bytes per group keep the bias of the reduction negligible.
+		chunk := append([]byte{0, 0, 0}, digest[5*i:5*i+5]...)
+		groups[i] = fmt.Sprintf("%05d", binary.BigEndian.Uint64(chunk)%100000)
+	}
+
+	bits := binary.BigEndian.Uint64(digest[:8])
+	emoji := make([]string, 7)
+	words := make([]string, 7)
+	for i := range emoji {
+		e := sasEmoji[bits>>uint(58-6*i)&0x3f]
+		emoji[i], words[i] = e.emoji, e.word
+	}
+	return Fingerprint{
+		Numeric: strings.Join(groups, " "),
+		Emoji:   strings.Join(emoji, " "),
+		Words:   strings.Join(words, ", "),
+	}
+}
@@ -17,9 +17,9 @@
 package grpcc
 
 import (
-	"bytes"
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -27,17 +27,16 @@ import (
 	"time"
 
 	"github.com/google/keytransparency/core/client/kt"
+	"github.com/google/keytransparency/core/client/profile"
 	"github.com/google/keytransparency/core/crypto/signatures"
-	"github.com/google/keytransparency/core/crypto/vrf"
-	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	kterrors "github.com/google/keytransparency/core/errors"
 	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/trillian"
-	"github.com/google/trillian/client"
-	"github.com/google/trillian/crypto/keys/der"
 	"github.com/google/trillian/crypto/keyspb"
-	"github.com/google/trillian/merkle/hashers"
 
 	"google.golang.org/grpc"
 
@@ -49,17 +48,23 @@ const (
 	// keys. Assuming 2 keys per profile (each of size 2048-bit), a page of
 	// size 16 will contain about 8KB of data.
 	pageSize = 16
-	// TODO: Public keys of trusted monitors.
 )
 
 var (
 	// ErrRetry occurs when an update request has been submitted, but the
 	// results of the update are not visible on the server yet. The client
 	// must retry until the request is visible.
-	ErrRetry = errors.New("update not present on server yet")
+	ErrRetry = kterrors.ErrRetry
+	// ErrPreviousChanged occurs when the entry an update modifies was changed
+	// by another update. The mutation has been rebased onto the new entry and
+	// may be retried.
+	ErrPreviousChanged = errors.New("entry changed by another update")
 	// ErrIncomplete occurs when the server indicates that requested epochs
 	// are not available.
-	ErrIncomplete = errors.New("incomplete account history")
+	ErrIncomplete = kterrors.ErrIncomplete
+	// ErrPurged occurs when the entry exists but its profile data has been
+	// purged from the server.
+	ErrPurged = errors.New("profile data purged")
 	// Vlog is the verbose logger. By default it outputs to /dev/null.
 	Vlog = log.New(ioutil.Discard, "", 0)
 )
@@ -83,79 +88,176 @@ type Client struct {
 	mutator    mutator.Func
 	RetryCount int
 	RetryDelay time.Duration
-	trusted    trillian.SignedLogRoot
+	// MaxRebases is the number of times Update will rebase and resubmit a
+	// mutation when the entry is concurrently changed by another update that
+	// leaves the authorized keys unchanged. Zero keeps the default, under
+	// which the mutation is rebased onto any change and resubmitted as one of
+	// its RetryCount retries.
+	MaxRebases int
+	// CompareAndSwap makes updates fail with a *ConflictError, instead of
+	// being rebased, when the entry they modify is changed by another
+	// update.
+	CompareAndSwap bool
+	// MaxClockSkew is the tolerated difference between the local clock and
+	// the server's clock. Zero disables skew detection.
+	MaxClockSkew time.Duration
+	// MaxStaleness is the maximum age of the latest map root served by the
+	// server. Older roots are rejected with a *FreshnessError. Zero disables
+	// the check. NewFromConfig sets it from the domain's max_interval.
+	MaxStaleness time.Duration
+	// Profiles holds the profile types used by GetTypedEntry and
+	// UpdateTyped.
+	Profiles *profile.Registry
+	// HistoryParallelism is the number of pages ListHistory fetches
+	// concurrently. Values below 2 fetch one page at a time.
+	HistoryParallelism int
+	// BatchParallelism is the number of entries BatchGetEntries fetches
+	// and verifies concurrently. Values below 2 handle one at a time.
+	BatchParallelism int
+	trusted          trillian.SignedLogRoot
+	roots            TrustedRootStore
+	cache            *EntryCache
+	monitors         *MonitorQuorum
 }
 
-// NewFromConfig creates a new client from a config
-func NewFromConfig(ktClient pb.KeyTransparencyClient, config *pb.Domain) (*Client, error) {
-	// Log Hasher.
-	logHasher, err := hashers.NewLogHasher(config.GetLog().GetHashStrategy())
-	if err != nil {
-		return nil, fmt.Errorf("Failed creating LogHasher: %v", err)
+// NewFromConfig creates a new client from a config.
+// If configKeys are given, config must carry a signed config from one of them,
+// and only the signed config is trusted.
+func NewFromConfig(ktClient pb.KeyTransparencyClient, config *pb.Domain, configKeys ...crypto.PublicKey) (*Client, error) {
+	if len(configKeys) > 0 {
+		signed, err := VerifyConfig(config, configKeys, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("VerifyConfig(): %w", err)
+		}
+		config = signed
 	}
 
-	// Log Key
-	logPubKey, err := der.UnmarshalPublicKey(config.GetLog().GetPublicKey().GetDer())
+	opts, err := kt.OptionsFromDomain(config)
 	if err != nil {
-		return nil, fmt.Errorf("Failed parsing Log public key: %v", err)
+		return nil, err
 	}
-
-	// Map Hasher
-	mapHasher, err := hashers.NewMapHasher(config.GetMap().GetHashStrategy())
+	c, err := New(ktClient, config.DomainId, opts)
 	if err != nil {
-		return nil, fmt.Errorf("Failed creating MapHasher: %v", err)
+		return nil, err
 	}
-
-	// Map Key
-	mapPubKey, err := der.UnmarshalPublicKey(config.GetMap().GetPublicKey().GetDer())
-	if err != nil {
-		return nil, fmt.Errorf("Failed parsing Map public key: %v", err)
+	// A new epoch is due every max_interval.
+	if config.GetMaxInterval() != nil {
+		maxInterval, err := ptypes.Duration(config.GetMaxInterval())
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing max interval: %w", err)
+		}
+		c.MaxStaleness = maxInterval + c.MaxClockSkew
 	}
+	// TODO(gbelvin): set retry delay.
+	return c, nil
+}
 
-	// VRF key
-	vrfPubKey, err := p256.NewVRFVerifierFromRawKey(config.GetVrf().GetDer())
+// New creates a new client that verifies responses with a verifier built
+// from opts.
+func New(ktClient pb.KeyTransparencyClient, domainID string, opts kt.Options) (*Client, error) {
+	v, err := kt.New(opts)
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing vrf public key: %v", err)
+		return nil, fmt.Errorf("kt.New(): %w", err)
 	}
-
-	// TODO(gbelvin): set retry delay.
-	logVerifier := client.NewLogVerifier(logHasher, logPubKey)
-	return New(ktClient, config.DomainId, vrfPubKey, mapPubKey, mapHasher, logVerifier), nil
+	return &Client{
+		cli:          ktClient,
+		domainID:     domainID,
+		kt:           v,
+		mutator:      entry.New(),
+		RetryCount:   1,
+		RetryDelay:   3 * time.Second,
+		MaxClockSkew: DefaultMaxClockSkew,
+		Profiles:     profile.NewRegistry(),
+
+		HistoryParallelism: DefaultHistoryParallelism,
+		BatchParallelism:   DefaultBatchParallelism,
+	}, nil
 }
 
-// New creates a new client.
-func New(ktClient pb.KeyTransparencyClient,
-	domainID string,
-	vrf vrf.PublicKey,
-	mapPubKey crypto.PublicKey,
-	mapHasher hashers.MapHasher,
-	logVerifier client.LogVerifier) *Client {
-	return &Client{
-		cli:        ktClient,
-		domainID:   domainID,
-		kt:         kt.New(vrf, mapHasher, mapPubKey, logVerifier),
-		mutator:    entry.New(),
-		RetryCount: 1,
-		RetryDelay: 3 * time.Second,
+// servedDomain returns the config of the domain served by the server. Its
+// endorsement policy must not be weaker than the policy the client enforces;
+// the other fields are not trusted.
+func (c *Client) servedDomain(ctx context.Context, opts ...grpc.CallOption) (*pb.Domain, error) {
+	domain, err := c.cli.GetDomain(ctx, &pb.GetDomainRequest{DomainId: c.domainID}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetDomain(%v): %w", c.domainID, err)
 	}
+	if err := c.kt.VerifyEndorsementPolicy(domain.GetEndorsementPolicy()); err != nil {
+		return nil, err
+	}
+	return domain, nil
 }
 
 // GetEntry returns an entry if it exists, and nil if it does not.
+// ErrPurged is returned along with the map root if the entry exists but its
+// data has been purged.
+// The entry is served from the client's EntryCache, if any, while the trusted
+// log root has not advanced since it was verified and the entry is still
+// fresh.
+// The size of the response is accounted to the Bandwidth attached to ctx.
 func (c *Client) GetEntry(ctx context.Context, userID, appID string, opts ...grpc.CallOption) ([]byte, *trillian.SignedMapRoot, error) {
+	e, err := c.verifiedEntry(ctx, userID, appID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entryProfile(e)
+}
+
+// verifiedEntry returns the verified entry of userID in appID from the
+// EntryCache or, if it is not cached or no longer fresh, from the server.
+// Cached entries passed the monitor checks when they were read, at the same
+// map root.
+func (c *Client) verifiedEntry(ctx context.Context, userID, appID string, opts ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	if e, ok := c.cachedEntry(userID, appID, time.Now()); ok {
+		return e, nil
+	}
+	bw := bandwidthFrom(ctx)
 	e, err := c.cli.GetEntry(ctx, &pb.GetEntryRequest{
 		DomainId:      c.domainID,
 		UserId:        userID,
 		AppId:         appID,
 		FirstTreeSize: c.trusted.TreeSize,
-	}, opts...)
+	}, bw.callOpts(opts)...)
+	if err := bw.account(e, err); err != nil {
+		return nil, rpcError("GetEntry", err)
+	}
+
+	if err := checkStale("GetEntry", &c.trusted, e.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, e)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	if err := c.checkRoots(ctx, e); err != nil {
+		return nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, err
+	}
+	c.cacheEntry(userID, appID, e)
+	return e, nil
+}
 
-	if err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, e); err != nil {
-		return nil, nil, err
+// checkRoots runs the checks that every entry the client verifies must pass
+// in addition to its proofs, whichever call read it: the map root of e must
+// not have been created in the future, the latest root of e must be fresh,
+// and enough trusted monitors must have signed the map root.
+func (c *Client) checkRoots(ctx context.Context, e *pb.GetEntryResponse) error {
+	if err := c.verifyRootTime(e.GetSmr()); err != nil {
+		return err
+	}
+	if err := c.checkFreshness(e, time.Now()); err != nil {
+		return err
 	}
+	return c.checkMonitors(ctx, e.GetSmr())
+}
 
+// entryProfile returns the profile in e, which must have been verified.
+func entryProfile(e *pb.GetEntryResponse) ([]byte, *trillian.SignedMapRoot, error) {
+	if e.GetCommittedPurged() {
+		return nil, e.GetSmr(), ErrPurged
+	}
 	// Empty case.
 	if e.GetCommitted() == nil {
 		return nil, e.GetSmr(), nil
@@ -164,6 +266,37 @@ func (c *Client) GetEntry(ctx context.Context, userID, appID string, opts ...grp
 	return e.GetCommitted().GetData(), e.GetSmr(), nil
 }
 
+// ListUserApps returns the verified profile of each app that userID has an
+// entry for, keyed by appID. The server must have app listing enabled for the
+// domain, and the caller must be authenticated as userID. Apps whose entries
+// have not yet been sequenced are omitted, and purged apps map to nil.
+func (c *Client) ListUserApps(ctx context.Context, userID string, opts ...grpc.CallOption) (map[string][]byte, error) {
+	bw := bandwidthFrom(ctx)
+	resp, err := c.cli.ListUserApps(ctx, &pb.ListUserAppsRequest{
+		DomainId: c.domainID,
+		UserId:   userID,
+	}, bw.callOpts(opts)...)
+	if err := bw.account(resp, err); err != nil {
+		return nil, err
+	}
+
+	// The server's list of apps is only a hint. Each entry is verified
+	// against the map before it is returned.
+	profiles := make(map[string][]byte)
+	for _, appID := range resp.GetAppIds() {
+		profile, _, err := c.GetEntry(ctx, userID, appID, opts...)
+		switch {
+		case errors.Is(err, ErrPurged):
+			profiles[appID] = nil
+		case err != nil:
+			return nil, fmt.Errorf("GetEntry(%v): %w", appID, err)
+		case profile != nil:
+			profiles[appID] = profile
+		}
+	}
+	return profiles, nil
+}
+
 func min(x, y int32) int32 {
 	if x < y {
 		return x
@@ -173,122 +306,363 @@ func min(x, y int32) int32 {
 
 // ListHistory returns a list of profiles starting and ending at given epochs.
 // It also filters out all identical consecutive profiles.
+// Up to HistoryParallelism pages are fetched concurrently, but entries are
+// verified in order, and the remaining fetches are cancelled as soon as one
+// fails verification.
 func (c *Client) ListHistory(ctx context.Context, userID, appID string, start, end int64, opts ...grpc.CallOption) (map[*trillian.SignedMapRoot][]byte, error) {
+	profiles, _, err := c.ResumeHistory(ctx, userID, appID, start, end, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// ResumeHistory is like ListHistory, but continues the download recorded in
+// token if token is not empty. If the download is interrupted, the profiles
+// verified so far are returned along with the error and a token that resumes
+// the download after them. The token must be used with the same user, app and
+// epochs.
+func (c *Client) ResumeHistory(ctx context.Context, userID, appID string, start, end int64, token string, opts ...grpc.CallOption) (map[*trillian.SignedMapRoot][]byte, string, error) {
 	if start < 0 {
-		return nil, fmt.Errorf("start=%v, want >= 0", start)
+		return nil, "", fmt.Errorf("start=%v, want >= 0", start)
 	}
-	var currentProfile []byte
+	// next is the next epoch to verify and current the hash of the last
+	// profile returned.
+	next, current := start, sha256.Sum256(nil)
+	if token != "" {
+		var err error
+		if next, current, err = parseHistoryToken(token); err != nil {
+			return nil, "", err
+		}
+		if next < start || next > end+1 {
+			return nil, "", fmt.Errorf("token resumes at epoch %v, want in [%v, %v]", next, start, end+1)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Pages are requested ahead of verification, so every page proves
+	// consistency with the root trusted when the first one was requested.
+	trusted := c.trusted
+	verifier := c.kt.NewHistoryVerifier(c.domainID, appID, userID, &trusted)
+	pages := c.newHistoryFetcher(ctx, userID, appID, next, end, trusted.TreeSize, opts)
+
 	profiles := make(map[*trillian.SignedMapRoot][]byte)
-	epochsReceived := int64(0)
-	epochsWant := end - start + 1
-	for epochsReceived < epochsWant {
-		resp, err := c.cli.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
-			DomainId: c.domainID,
-			UserId:   userID,
-			AppId:    appID,
-			Start:    start,
-			PageSize: min(int32((end-start)+1), pageSize),
-		}, opts...)
+	interrupted := func(err error) (map[*trillian.SignedMapRoot][]byte, string, error) {
+		return profiles, historyToken(next, current), err
+	}
+	for next <= end {
+		resp, err := pages.next()
 		if err != nil {
-			return nil, err
+			return interrupted(err)
+		}
+		if resp == nil {
+			break // No more data.
 		}
-		epochsReceived += int64(len(resp.GetValues()))
 
-		for i, v := range resp.GetValues() {
-			Vlog.Printf("Processing entry for %v, epoch %v", userID, start+int64(i))
-			err = c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, v)
-			if err != nil {
-				return nil, err
+		for _, v := range resp.GetValues() {
+			// Stop promptly if the caller is no longer interested.
+			if err := ctx.Err(); err != nil {
+				return interrupted(err)
+			}
+			Vlog.Printf("Processing entry for %v, epoch %v", userID, next)
+			if err := checkStale("ListHistory", &trusted, v.GetLogRoot()); err != nil {
+				return interrupted(err)
+			}
+			if err := verifier.Verify(ctx, v); err != nil {
+				return interrupted(err)
+			}
+			if err := c.checkRoots(ctx, v); err != nil {
+				return interrupted(err)
+			}
+			if err := c.updateTrusted(ctx, v.GetLogRoot()); err != nil {
+				return interrupted(err)
+			}
+			next++
+
+			// Purged profiles are omitted from the history.
+			if v.GetCommittedPurged() {
+				continue
 			}
 
 			// Compress profiles that are equal through time.  All
 			// nil profiles before the first profile are ignored.
 			profile := v.GetCommitted().GetData()
-			if bytes.Equal(currentProfile, profile) {
+			hash := sha256.Sum256(profile)
+			if hash == current {
 				continue
 			}
 
-			// Append the slice and update currentProfile.
+			// Append the slice and update current.
 			profiles[v.GetSmr()] = profile
-			currentProfile = profile
+			current = hash
 		}
-		if resp.NextStart == 0 {
-			break // No more data.
-		}
-		start = resp.NextStart // Fetch the next block of results.
 	}
 
-	if epochsReceived < epochsWant {
-		return nil, ErrIncomplete
+	if next <= end {
+		return interrupted(ErrIncomplete)
 	}
 
-	return profiles, nil
+	return profiles, "", nil
+}
+
+// UpdateResult describes the outcome of submitting a mutation.
+type UpdateResult struct {
+	// Mutation is the mutation that was submitted.
+	Mutation *entry.Mutation
+	// Revision is the map revision the mutation was observed in.
+	Revision int64
+	// Smr is the verified map root the mutation was observed in.
+	Smr *trillian.SignedMapRoot
+	// Duration is the time spent submitting the mutation, including retries.
+	Duration time.Duration
+	// Retries is the number of times the mutation was resubmitted.
+	Retries int
+	// Rebases is the number of times the mutation was rebased onto an entry
+	// written by another update.
+	Rebases int
+	// BytesDownloaded is the size of the responses received, including
+	// retries.
+	BytesDownloaded int64
 }
 
 // Update creates an UpdateEntryRequest for a user, attempt to submit it multiple
 // times depending on RetryCount.
+// A result containing the mutation is returned along with ErrRetry if the
+// mutation was not yet visible after all retries.
+// If the entry is changed by another update in the meantime, the mutation is
+// rebased and resubmitted. With MaxRebases set, it is resubmitted up to
+// MaxRebases times before ErrPreviousChanged is returned.
+// With CompareAndSwap, a *ConflictError is returned instead.
 func (c *Client) Update(ctx context.Context, appID, userID string, profileData []byte,
 	signers []signatures.Signer, authorizedKeys []*keyspb.PublicKey,
-	opts ...grpc.CallOption) (*entry.Mutation, error) {
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.update(ctx, appID, userID, profileData, localSigners(signers), authorizedKeys, nil, opts...)
+}
+
+// update implements Update, UpdateRemote, SetRecoveryKeys and ResetAccount.
+// edit, if not nil, changes the mutation before it is submitted.
+func (c *Client) update(ctx context.Context, appID, userID string, profileData []byte,
+	sign signFunc, authorizedKeys []*keyspb.PublicKey, edit func(*entry.Mutation) error,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	start := time.Now()
+	bw := bandwidthFrom(ctx)
+	used := bw.Spent()
 	getResp, err := c.cli.GetEntry(ctx, &pb.GetEntryRequest{
 		DomainId:      c.domainID,
 		UserId:        userID,
 		AppId:         appID,
 		FirstTreeSize: c.trusted.TreeSize,
-	}, opts...)
-	if err != nil {
-		return nil, fmt.Errorf("GetEntry(%v): %v", userID, err)
+	}, bw.callOpts(opts)...)
+	if err := bw.account(getResp, err); err != nil {
+		return nil, rpcError(fmt.Sprintf("GetEntry(%v)", userID), err)
 	}
 	Vlog.Printf("Got current entry...")
 
-	if err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, getResp); err != nil {
-		return nil, fmt.Errorf("VerifyGetEntryResponse(): %v", err)
+	if err := checkStale("Update", &c.trusted, getResp.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, getResp)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyGetEntryResponse(): %w", err)
+	}
+	if err := c.checkRoots(ctx, getResp); err != nil {
+		return nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, err
 	}
 
 	m, err := c.kt.NewMutation(c.domainID, appID, userID, profileData, authorizedKeys,
 		getResp.GetVrfProof(), getResp.GetLeafProof().GetLeaf().GetLeafValue())
 	if err != nil {
-		return nil, fmt.Errorf("CreateUpdateEntryRequest: %v", err)
+		return nil, fmt.Errorf("CreateUpdateEntryRequest: %w", err)
+	}
+	// Entries stored under their index from before a domain VRF rotation
+	// move to their current index.
+	if p := getResp.GetCurrentVrfProof(); len(p) != 0 {
+		index, err := c.kt.CurrentIndex(p, appID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("CurrentIndex(): %w", err)
+		}
+		m.MoveIndex(index)
+	}
+	if edit != nil {
+		if err := edit(m); err != nil {
+			return nil, err
+		}
 	}
 
-	err = c.Retry(ctx, m, signers, opts...)
+	result, err := c.submit(ctx, m, sign, opts...)
+	result.Duration = time.Since(start)
+	result.BytesDownloaded = bw.Spent() - used
+	return result, err
+}
+
+// submit sends m with Retry until it is visible. It is resubmitted up to
+// RetryCount times while it is not yet visible or, by default, after it is
+// rebased onto an entry written by another update. With MaxRebases set,
+// rebases are counted separately, up to MaxRebases.
+func (c *Client) submit(ctx context.Context, m *entry.Mutation, sign signFunc, opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.resubmit(ctx, m, func() (*UpdateResult, error) {
+		return c.retry(ctx, m, sign, opts...)
+	})
+}
+
+// resubmit implements submit, sending m with send. The returned result is
+// never nil and counts the retries and rebases.
+func (c *Client) resubmit(ctx context.Context, m *entry.Mutation, send func() (*UpdateResult, error)) (*UpdateResult, error) {
+	result, err := send()
 	// Retry submitting until an inclusion proof is returned.
-	for i := 0; err == ErrRetry && i < c.RetryCount; i++ {
-		time.Sleep(c.RetryDelay)
-		err = c.Retry(ctx, m, signers, opts...)
+	retries, rebases := 0, 0
+	for {
+		if errors.Is(err, ErrPreviousChanged) && rebases < c.MaxRebases {
+			// Resubmit the rebased mutation immediately.
+			rebases++
+		} else if errors.Is(err, ErrRetry) && retries < c.RetryCount {
+			retries++
+			if err = sleep(ctx, c.RetryDelay); err != nil {
+				break
+			}
+		} else {
+			break
+		}
+		result, err = send()
+	}
+	if result == nil {
+		result = &UpdateResult{Mutation: m}
+	}
+	result.Retries = retries
+	result.Rebases = rebases
+	return result, err
+}
+
+// sleep waits for d, or returns ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return m, err
 }
 
 // Retry takes take a mutation, signs, and sends it again, and updates the back pointer with the current leaf value.
-func (c *Client) Retry(ctx context.Context, m *entry.Mutation, signers []signatures.Signer, opts ...grpc.CallOption) error {
-	req, err := m.SerializeAndSign(signers, c.trusted.TreeSize)
+// The returned result describes the map root the server responded with. If the
+// mutation is not visible in that map root, ErrRetry is returned.
+// If the entry was changed by another update, the mutation is rebased onto
+// the new entry and ErrRetry is returned. With MaxRebases set, the mutation
+// is only rebased if the authorized keys are unchanged, in which case
+// ErrPreviousChanged is returned; otherwise entry.ErrIncompatiblePrevious is
+// returned and the mutation must be recreated. With CompareAndSwap, a
+// *ConflictError holding the competing entry is returned instead and the
+// mutation is left unchanged.
+func (c *Client) Retry(ctx context.Context, m *entry.Mutation, signers []signatures.Signer, opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.retry(ctx, m, localSigners(signers), opts...)
+}
+
+// retry implements Retry, signing m with sign.
+func (c *Client) retry(ctx context.Context, m *entry.Mutation, sign signFunc, opts ...grpc.CallOption) (*UpdateResult, error) {
+	start := time.Now()
+	req, err := sign(ctx, m, c.trusted.TreeSize)
 	if err != nil {
-		return fmt.Errorf("SerializeAndSign(): %v", err)
+		return nil, err
 	}
 
 	Vlog.Printf("Sending Update request...")
-	updateResp, err := c.cli.UpdateEntry(ctx, req, opts...)
-	if err != nil {
-		return fmt.Errorf("cli.UpdateEntry(): %v", err)
+	bw := bandwidthFrom(ctx)
+	updateResp, err := c.cli.UpdateEntry(ctx, req, bw.callOpts(opts)...)
+	if err := bw.account(updateResp, err); err != nil {
+		return nil, rpcError("cli.UpdateEntry()", err)
 	}
 	Vlog.Printf("Got current entry...")
 
 	// Validate response.
-	if err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, req.AppId, req.UserId, &c.trusted, updateResp.GetProof()); err != nil {
-		return fmt.Errorf("VerifyGetEntryResponse(): %v", err)
+	if err := checkStale("Retry", &c.trusted, updateResp.GetProof().GetLogRoot()); err != nil {
+		return nil, err
+	}
+	root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, req.AppId, req.UserId, &c.trusted, updateResp.GetProof())
+	if err != nil {
+		return nil, fmt.Errorf("VerifyGetEntryResponse(): %w", err)
+	}
+	if err := c.checkRoots(ctx, updateResp.GetProof()); err != nil {
+		return nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, err
 	}
 
 	cntLeaf := updateResp.GetProof().GetLeafProof().GetLeaf().GetLeafValue()
 	equal, err := m.Check(cntLeaf)
 	if err != nil {
-		return fmt.Errorf("mutation.Check(): %v", err)
+		return nil, fmt.Errorf("mutation.Check(): %w", err)
+	}
+	changed, err := m.PreviousChanged(cntLeaf)
+	if err != nil {
+		return nil, fmt.Errorf("mutation.PreviousChanged(): %w", err)
+	}
+	smr := updateResp.GetProof().GetSmr()
+	result := &UpdateResult{
+		Mutation:        m,
+		Revision:        smr.GetMapRevision(),
+		Smr:             smr,
+		Duration:        time.Since(start),
+		BytesDownloaded: int64(proto.Size(updateResp)),
+	}
+	if equal {
+		return result, nil
 	}
-	if err := m.SetPrevious(cntLeaf, false); err != nil {
-		return fmt.Errorf("mutation.SetPrevious(): %v", err)
+	if changed {
+		if c.CompareAndSwap {
+			return result, newConflictError(m, updateResp.GetProof())
+		}
+		if c.MaxRebases <= 0 {
+			if err := m.SetPrevious(cntLeaf, false); err != nil {
+				return result, fmt.Errorf("mutation.SetPrevious(): %w", err)
+			}
+			return result, ErrRetry
+		}
+		if err := m.Rebase(cntLeaf); err != nil {
+			return result, err
+		}
+		return result, ErrPreviousChanged
+	}
+	return result, ErrRetry
+}
+
+// VerifyLogRoots verifies, in a single request, that each of roots is
+// consistent with the next and that the last is consistent with the server's
+// latest log root. roots must be ordered by tree size. The server's latest log
+// root is returned.
+func (c *Client) VerifyLogRoots(ctx context.Context, roots []*trillian.SignedLogRoot, opts ...grpc.CallOption) (*trillian.SignedLogRoot, error) {
+	sizes := make([]int64, 0, len(roots))
+	for _, r := range roots {
+		sizes = append(sizes, r.GetTreeSize())
 	}
-	if !equal {
-		return ErrRetry
+	bw := bandwidthFrom(ctx)
+	chain, err := c.cli.GetLogConsistencyChain(ctx, &pb.GetLogConsistencyChainRequest{
+		DomainId:  c.domainID,
+		TreeSizes: sizes,
+	}, bw.callOpts(opts)...)
+	if err := bw.account(chain, err); err != nil {
+		if _, ok := err.(*BudgetError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("GetLogConsistencyChain(): %w", err)
 	}
-	return nil
+	if err := c.kt.VerifyConsistencyChain(ctx, roots, chain); err != nil {
+		return nil, fmt.Errorf("VerifyConsistencyChain(): %w", err)
+	}
+	return chain.GetLogRoot(), nil
+}
+
+// VerifyLogRoot verifies that root is signed by the log of the domain. It
+// does not check that root is consistent with the trusted log root.
+func (c *Client) VerifyLogRoot(root *trillian.SignedLogRoot) error {
+	return c.kt.VerifyLogRoot(root)
+}
+
+// TrustedRoot returns the latest log root the client verified.
+func (c *Client) TrustedRoot() *trillian.SignedLogRoot {
+	root := c.trusted
+	return &root
 }
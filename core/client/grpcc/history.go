@@ -0,0 +1,170 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// DefaultHistoryParallelism is the number of history pages ListHistory
+// fetches concurrently by default.
+const DefaultHistoryParallelism = 4
+
+// historyPage is the result of fetching one page of an entry history.
+type historyPage struct {
+	resp *pb.ListEntryHistoryResponse
+	err  error
+}
+
+// pendingPage is a page that has been requested but not yet returned.
+type pendingPage struct {
+	start int64
+	size  int32
+	done  <-chan historyPage
+}
+
+// historyFetcher fetches the pages of an entry history ahead of the caller,
+// up to parallelism pages at a time, and returns them in order. Each page
+// reserves an equal share of the budget of bw that is left when it is
+// requested, and is accounted as soon as it arrives, including pages that
+// are discarded.
+type historyFetcher struct {
+	ctx         context.Context
+	cli         pb.KeyTransparencyClient
+	bw          *Bandwidth
+	opts        []grpc.CallOption
+	domainID    string
+	userID      string
+	appID       string
+	parallelism int
+	start, end  int64 // The next epoch to request and the last epoch.
+	treeSize    int64 // The size of the trusted log root.
+	pending     []pendingPage
+}
+
+func (c *Client) newHistoryFetcher(ctx context.Context, userID, appID string, start, end, treeSize int64, opts []grpc.CallOption) *historyFetcher {
+	parallelism := c.HistoryParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &historyFetcher{
+		ctx:         ctx,
+		cli:         c.cli,
+		bw:          bandwidthFrom(ctx),
+		opts:        opts,
+		domainID:    c.domainID,
+		userID:      userID,
+		appID:       appID,
+		parallelism: parallelism,
+		start:       start,
+		end:         end,
+		treeSize:    treeSize,
+	}
+}
+
+// prefetch requests pages until parallelism pages are pending or the end of
+// the range has been requested.
+func (f *historyFetcher) prefetch() {
+	for len(f.pending) < f.parallelism && f.start <= f.end {
+		size := min(int32(f.end-f.start+1), pageSize)
+		req := &pb.ListEntryHistoryRequest{
+			DomainId:      f.domainID,
+			UserId:        f.userID,
+			AppId:         f.appID,
+			Start:         f.start,
+			PageSize:      size,
+			FirstTreeSize: f.treeSize,
+		}
+		opts, reserved := f.bw.reserve(f.opts, f.parallelism-len(f.pending))
+		done := make(chan historyPage, 1)
+		go func() {
+			resp, err := f.cli.ListEntryHistory(f.ctx, req, opts...)
+			done <- historyPage{resp: resp, err: f.bw.settle(reserved, resp, err)}
+		}()
+		f.pending = append(f.pending, pendingPage{start: f.start, size: size, done: done})
+		f.start += int64(size)
+	}
+}
+
+// next returns the next page of the history, or nil once the server has
+// no more data or the whole range has been returned.
+func (f *historyFetcher) next() (*pb.ListEntryHistoryResponse, error) {
+	if err := f.ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.prefetch()
+	if len(f.pending) == 0 {
+		return nil, nil
+	}
+	p := f.pending[0]
+	f.pending = f.pending[1:]
+	var page historyPage
+	select {
+	case page = <-p.done:
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	}
+	if page.err != nil {
+		return nil, rpcError("ListEntryHistory", page.err)
+	}
+
+	switch next := page.resp.GetNextStart(); {
+	case next == 0:
+		// No more data. Later pages, if any were requested, would
+		// only fail.
+		f.pending = nil
+		f.start = f.end + 1
+	case next != p.start+int64(p.size):
+		// The server returned a short page, which moves every later
+		// page. The pages fetched ahead are discarded.
+		f.pending = nil
+		f.start = next
+	}
+	return page.resp, nil
+}
+
+// historyToken returns a token that resumes a history download at epoch next,
+// after a profile with SHA-256 hash current.
+func historyToken(next int64, current [sha256.Size]byte) string {
+	return fmt.Sprintf("%d.%x", next, current)
+}
+
+// parseHistoryToken returns the epoch and profile hash recorded in token.
+func parseHistoryToken(token string) (int64, [sha256.Size]byte, error) {
+	var current [sha256.Size]byte
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return 0, current, fmt.Errorf("malformed history token %q", token)
+	}
+	next, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, current, fmt.Errorf("malformed history token %q: %w", token, err)
+	}
+	hash, err := hex.DecodeString(parts[1])
+	if err != nil || len(hash) != sha256.Size {
+		return 0, current, fmt.Errorf("malformed history token %q", token)
+	}
+	copy(current[:], hash)
+	return next, current, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// historyServer serves entry histories of epochs 0 to current, at most
+// maxPage entries per page. Later pages are served first.
+type historyServer struct {
+	pb.KeyTransparencyClient
+	current int64
+	maxPage int32
+
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+}
+
+func (s *historyServer) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRequest, opts ...grpc.CallOption) (*pb.ListEntryHistoryResponse, error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxSeen {
+		s.maxSeen = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	if in.GetStart() > s.current {
+		return nil, errors.New("start past the current epoch")
+	}
+	select {
+	case <-time.After(time.Duration(100-in.GetStart()) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	size := in.GetPageSize()
+	if s.maxPage > 0 && size > s.maxPage {
+		size = s.maxPage
+	}
+	resp := &pb.ListEntryHistoryResponse{NextStart: in.GetStart() + int64(size)}
+	for e := in.GetStart(); e < resp.NextStart && e <= s.current; e++ {
+		resp.Values = append(resp.Values, &pb.GetEntryResponse{Smr: &trillian.SignedMapRoot{MapRevision: e}})
+	}
+	if resp.NextStart > s.current {
+		resp.NextStart = 0
+	}
+	return resp, nil
+}
+
+func TestHistoryFetcher(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		current     int64
+		maxPage     int32
+		parallelism int
+		start, end  int64
+		wantEpochs  int64
+	}{
+		{desc: "sequential", current: 50, parallelism: 1, start: 0, end: 50, wantEpochs: 51},
+		{desc: "concurrent", current: 50, parallelism: 4, start: 3, end: 50, wantEpochs: 48},
+		{desc: "short pages", current: 50, maxPage: 5, parallelism: 4, start: 0, end: 40, wantEpochs: 41},
+		{desc: "past current", current: 20, parallelism: 4, start: 0, end: 90, wantEpochs: 21},
+	} {
+		srv := &historyServer{current: tc.current, maxPage: tc.maxPage}
+		c := &Client{cli: srv, HistoryParallelism: tc.parallelism}
+		pages := c.newHistoryFetcher(context.Background(), "user", "app", tc.start, tc.end, 0, nil)
+		want := tc.start
+		for {
+			resp, err := pages.next()
+			if err != nil {
+				t.Fatalf("%v: next(): %v", tc.desc, err)
+			}
+			if resp == nil {
+				break
+			}
+			for _, v := range resp.GetValues() {
+				if got := v.GetSmr().GetMapRevision(); got != want {
+					t.Fatalf("%v: next(): epoch %v, want %v", tc.desc, got, want)
+				}
+				want++
+			}
+		}
+		if got := want - tc.start; got != tc.wantEpochs {
+			t.Errorf("%v: received %v epochs, want %v", tc.desc, got, tc.wantEpochs)
+		}
+		if srv.maxSeen > tc.parallelism {
+			t.Errorf("%v: %v concurrent requests, want at most %v", tc.desc, srv.maxSeen, tc.parallelism)
+		}
+	}
+}
+
+func TestHistoryFetcherCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &historyServer{current: 50}
+	c := &Client{cli: srv, HistoryParallelism: 4}
+	pages := c.newHistoryFetcher(ctx, "user", "app", 0, 50, 0, nil)
+	if _, err := pages.next(); err != nil {
+		t.Fatalf("next(): %v", err)
+	}
+	cancel()
+	if _, err := pages.next(); err != context.Canceled {
+		t.Errorf("next(cancelled): %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestHistoryToken(t *testing.T) {
+	current := sha256.Sum256([]byte("profile"))
+	next, hash, err := parseHistoryToken(historyToken(12, current))
+	if err != nil {
+		t.Fatalf("parseHistoryToken(): %v", err)
+	}
+	if next != 12 || hash != current {
+		t.Errorf("parseHistoryToken(): %v, %x, want 12, %x", next, hash, current)
+	}
+	for _, token := range []string{"12", "x.00", "12.00", "12.zz"} {
+		if _, _, err := parseHistoryToken(token); err == nil {
+			t.Errorf("parseHistoryToken(%q): nil, want error", token)
+		}
+	}
+}
+
+func TestResumeHistoryToken(t *testing.T) {
+	srv := &historyServer{current: 10}
+	c := &Client{cli: srv, domainID: "domain"}
+	token := historyToken(4, sha256.Sum256(nil))
+	for _, tc := range []struct {
+		start, end int64
+		wantErr    bool
+	}{
+		{start: 0, end: 2, wantErr: true},  // Past the end.
+		{start: 5, end: 10, wantErr: true}, // Before the start.
+	} {
+		if _, _, err := c.ResumeHistory(context.Background(), "user", "app", tc.start, tc.end, token); (err != nil) != tc.wantErr {
+			t.Errorf("ResumeHistory(%v, %v): %v, wantErr %v", tc.start, tc.end, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/endorsement"
+	"github.com/google/keytransparency/core/migration"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+var (
+	// ErrMigrationNotLogged occurs when a migration announcement is not
+	// recorded in a logged map root of the domain it migrates. Servers
+	// record announcements in the next epoch, so following can be retried.
+	ErrMigrationNotLogged = errors.New("migration is not logged")
+	// ErrNoAnchor occurs when a map revision precedes all pinned anchors.
+	ErrNoAnchor = errors.New("no trust anchor for revision")
+)
+
+// FollowMigration asks the server for the config of the domain pinned in
+// anchor and follows the migration it announces, if any. The announcement
+// must be signed with the map key of the pinned domain and recorded in a map
+// root of the pinned domain that is in its log, so that a server cannot show
+// an announcement to some clients only. A served endorsement policy that is
+// weaker than the pinned one fails verification. The new anchor is saved to
+// store and returned; anchor itself is returned if there is no migration to
+// follow.
+//
+// A migration is followed once. Announcements that do not move the start
+// revision of the anchor forward are ignored, as are announcements that were
+// already followed, which servers may keep publishing. The replaced anchor
+// is kept in the history of the new one so that revisions before the
+// migration can still be verified with NewFromAnchorAt.
+func FollowMigration(ctx context.Context, ktClient pb.KeyTransparencyClient, store TrustStore, anchor *pb.TrustAnchor, opts ...grpc.CallOption) (*pb.TrustAnchor, error) {
+	domainID := anchor.GetDomain().GetDomainId()
+	domain, err := ktClient.GetDomain(ctx, &pb.GetDomainRequest{DomainId: domainID}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetDomain(%v): %w", domainID, err)
+	}
+	// The pinned endorsement policy holds until a migration replaces it.
+	if err := endorsement.CheckNotWeaker(domain.GetEndorsementPolicy(), anchor.GetDomain().GetEndorsementPolicy()); err != nil {
+		return nil, kterrors.Wrap(kterrors.ErrVerification, "FollowMigration", err)
+	}
+	next, err := migrate(anchor, domain.GetMigration(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if next == anchor {
+		return anchor, nil
+	}
+	if err := verifyLogged(ctx, ktClient, anchor, next.GetMigration(), next.GetStartRevision(), opts...); err != nil {
+		return nil, err
+	}
+	if err := store.Save(ctx, next); err != nil {
+		return nil, fmt.Errorf("saving trust anchor: %w", err)
+	}
+	return next, nil
+}
+
+// migrate returns the anchor that results from following signed from
+// anchor at time now. It returns anchor if signed is nil or has already been
+// followed.
+func migrate(anchor *pb.TrustAnchor, signed *pb.SignedDomainMigration, now time.Time) (*pb.TrustAnchor, error) {
+	if signed == nil || followed(anchor, signed) {
+		return anchor, nil
+	}
+	m, err := migration.Verify(signed, anchor.GetDomain())
+	if err != nil {
+		return nil, fmt.Errorf("migration.Verify(): %w", err)
+	}
+	if m.GetRevision() <= anchor.GetStartRevision() {
+		return anchor, nil
+	}
+	pinned, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := proto.Clone(anchor).(*pb.TrustAnchor)
+	prev.History = nil
+	next := &pb.TrustAnchor{
+		Domain:        m.GetNewDomain(),
+		Fingerprint:   DomainFingerprint(m.GetNewDomain()).Numeric,
+		PinnedTime:    pinned,
+		Address:       anchor.GetAddress(),
+		StartRevision: m.GetRevision(),
+		Migration:     signed,
+		History:       append([]*pb.TrustAnchor{prev}, anchor.GetHistory()...),
+	}
+	if addr := m.GetNewAddress(); addr != "" {
+		next.Address = addr
+	}
+	// The trusted log root carries over if the log does not change.
+	if proto.Equal(m.GetNewDomain().GetLog(), anchor.GetDomain().GetLog()) {
+		next.LogRoot = anchor.GetLogRoot()
+	}
+	return next, nil
+}
+
+// verifyLogged checks that signed, a migration of the domain pinned in anchor
+// at revision, is recorded in the latest map root before revision. The map
+// root is verified with the keys and log root of anchor.
+func verifyLogged(ctx context.Context, ktClient pb.KeyTransparencyClient, anchor *pb.TrustAnchor, signed *pb.SignedDomainMigration, revision int64, opts ...grpc.CallOption) error {
+	c, err := newFromAnchor(ktClient, anchor)
+	if err != nil {
+		return err
+	}
+	epoch, err := ktClient.GetLatestEpoch(ctx, &pb.GetLatestEpochRequest{
+		DomainId:      c.domainID,
+		FirstTreeSize: c.trusted.GetTreeSize(),
+	}, opts...)
+	if err != nil {
+		return fmt.Errorf("GetLatestEpoch(%v): %w", c.domainID, err)
+	}
+	// Map roots from revision on are signed with the new keys.
+	if epoch.GetSmr().GetMapRevision() >= revision {
+		epoch, err = ktClient.GetEpoch(ctx, &pb.GetEpochRequest{
+			DomainId:      c.domainID,
+			Epoch:         revision - 1,
+			FirstTreeSize: c.trusted.GetTreeSize(),
+		}, opts...)
+		if err != nil {
+			return fmt.Errorf("GetEpoch(%v, %v): %w", c.domainID, revision-1, err)
+		}
+	}
+	if _, err := c.kt.VerifyEpoch(ctx, &c.trusted, epoch); err != nil {
+		return fmt.Errorf("VerifyEpoch(): %w", err)
+	}
+	return checkLogged(epoch.GetSmr(), signed)
+}
+
+// checkLogged returns ErrMigrationNotLogged unless the hash of signed is
+// recorded in the metadata of smr.
+func checkLogged(smr *trillian.SignedMapRoot, signed *pb.SignedDomainMigration) error {
+	var meta pb.MapperMetadata
+	if smr.GetMetadata() != nil {
+		if err := ptypes.UnmarshalAny(smr.GetMetadata(), &meta); err != nil {
+			return fmt.Errorf("ptypes.UnmarshalAny(): %w", err)
+		}
+	}
+	want, err := migration.Hash(signed)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(meta.GetMigrationHash(), want) {
+		return ErrMigrationNotLogged
+	}
+	return nil
+}
+
+// followed returns true if signed is the migration that led to anchor or to
+// one of the anchors in its history.
+func followed(anchor *pb.TrustAnchor, signed *pb.SignedDomainMigration) bool {
+	if proto.Equal(signed, anchor.GetMigration()) {
+		return true
+	}
+	for _, h := range anchor.GetHistory() {
+		if h.GetMigration() != nil && proto.Equal(signed, h.GetMigration()) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnchorAt returns the anchor in anchor or its history that verifies map
+// revision. It returns ErrNoAnchor if revision precedes all of them.
+func AnchorAt(anchor *pb.TrustAnchor, revision int64) (*pb.TrustAnchor, error) {
+	if revision >= anchor.GetStartRevision() {
+		return anchor, nil
+	}
+	for _, h := range anchor.GetHistory() {
+		if revision >= h.GetStartRevision() {
+			return h, nil
+		}
+	}
+	return nil, ErrNoAnchor
+}
+
+// NewFromAnchorAt returns a client that verifies map revision with the keys
+// that were pinned for it, which differ from the current keys of anchor if
+// the domain migrated after revision.
+func NewFromAnchorAt(ktClient pb.KeyTransparencyClient, anchor *pb.TrustAnchor, revision int64) (*Client, error) {
+	at, err := AnchorAt(anchor, revision)
+	if err != nil {
+		return nil, err
+	}
+	return newFromAnchor(ktClient, at)
+}
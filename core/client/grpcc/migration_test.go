@@ -0,0 +1,181 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/migration"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// migratingDomain returns a test domain whose map is signed by a new key,
+// and a signer holding the key.
+func migratingDomain(t *testing.T, vrf string) (*pb.Domain, *tcrypto.Signer) {
+	signer := newConfigSigner(t)
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	d := testDomain("domain", []byte(vrf))
+	d.Map.PublicKey = &keyspb.PublicKey{Der: der}
+	return d, signer
+}
+
+func signMigration(t *testing.T, signer *tcrypto.Signer, m *pb.DomainMigration) *pb.SignedDomainMigration {
+	signed, err := migration.Sign(signer, m)
+	if err != nil {
+		t.Fatalf("migration.Sign(): %v", err)
+	}
+	return signed
+}
+
+func TestMigrate(t *testing.T) {
+	now := time.Now()
+	first, firstSigner := migratingDomain(t, "vrf1")
+	second, secondSigner := migratingDomain(t, "vrf2")
+	third, _ := migratingDomain(t, "vrf3")
+	logRoot := &trillian.SignedLogRoot{TreeSize: 5}
+	anchor := &pb.TrustAnchor{
+		Domain:      first,
+		LogRoot:     logRoot,
+		Fingerprint: DomainFingerprint(first).Numeric,
+	}
+
+	toSecond := signMigration(t, firstSigner, &pb.DomainMigration{
+		DomainId:   "domain",
+		Revision:   10,
+		NewAddress: "new:443",
+		NewDomain:  second,
+	})
+	migrated, err := migrate(anchor, toSecond, now)
+	if err != nil {
+		t.Fatalf("migrate(): %v", err)
+	}
+	if !proto.Equal(migrated.GetDomain(), second) || migrated.GetStartRevision() != 10 || migrated.GetAddress() != "new:443" {
+		t.Errorf("migrate(): %v, want domain %v from revision 10 at new:443", migrated, second)
+	}
+	if got, want := migrated.GetFingerprint(), DomainFingerprint(second).Numeric; got != want {
+		t.Errorf("Fingerprint: %v, want %v", got, want)
+	}
+	if !proto.Equal(migrated.GetLogRoot(), logRoot) {
+		t.Errorf("LogRoot: %v, want the log root of the unchanged log %v", migrated.GetLogRoot(), logRoot)
+	}
+	if len(migrated.GetHistory()) != 1 || !proto.Equal(migrated.GetHistory()[0], anchor) {
+		t.Errorf("History: %v, want [%v]", migrated.GetHistory(), anchor)
+	}
+
+	// Each migration is followed once.
+	for _, signed := range []*pb.SignedDomainMigration{nil, toSecond} {
+		if got, err := migrate(migrated, signed, now); err != nil || got != migrated {
+			t.Errorf("migrate(followed): %v, %v, want unchanged anchor", got, err)
+		}
+	}
+	if _, err := migrate(migrated, signMigration(t, firstSigner, &pb.DomainMigration{
+		DomainId:  "domain",
+		Revision:  20,
+		NewDomain: third,
+	}), now); err == nil {
+		t.Errorf("migrate(signed by replaced key): nil, want error")
+	}
+	if got, err := migrate(migrated, signMigration(t, secondSigner, &pb.DomainMigration{
+		DomainId:  "domain",
+		Revision:  10,
+		NewDomain: third,
+	}), now); err != nil || got != migrated {
+		t.Errorf("migrate(same revision): %v, %v, want unchanged anchor", got, err)
+	}
+
+	// Migrations can be chained.
+	chained, err := migrate(migrated, signMigration(t, secondSigner, &pb.DomainMigration{
+		DomainId:  "domain",
+		Revision:  20,
+		NewDomain: third,
+	}), now)
+	if err != nil {
+		t.Fatalf("migrate(chained): %v", err)
+	}
+	if got := chained.GetAddress(); got != "new:443" {
+		t.Errorf("Address: %v, want new:443", got)
+	}
+	if got, err := migrate(chained, toSecond, now); err != nil || got != chained {
+		t.Errorf("migrate(earlier migration): %v, %v, want unchanged anchor", got, err)
+	}
+	for _, tc := range []struct {
+		revision int64
+		want     *pb.Domain
+		wantErr  error
+	}{
+		{revision: -1, wantErr: ErrNoAnchor},
+		{revision: 0, want: first},
+		{revision: 9, want: first},
+		{revision: 10, want: second},
+		{revision: 19, want: second},
+		{revision: 20, want: third},
+		{revision: 100, want: third},
+	} {
+		got, err := AnchorAt(chained, tc.revision)
+		if err != tc.wantErr {
+			t.Errorf("AnchorAt(%v): %v, want %v", tc.revision, err, tc.wantErr)
+			continue
+		}
+		if !proto.Equal(got.GetDomain(), tc.want) {
+			t.Errorf("AnchorAt(%v): %v, want %v", tc.revision, got.GetDomain(), tc.want)
+		}
+	}
+}
+
+func TestCheckLogged(t *testing.T) {
+	signed := &pb.SignedDomainMigration{Migration: []byte("migration"), Signature: &sigpb.DigitallySigned{Signature: []byte("sig")}}
+	other := &pb.SignedDomainMigration{Migration: []byte("other"), Signature: &sigpb.DigitallySigned{Signature: []byte("sig")}}
+	smr := func(t *testing.T, announced *pb.SignedDomainMigration) *trillian.SignedMapRoot {
+		meta := &pb.MapperMetadata{}
+		if announced != nil {
+			h, err := migration.Hash(announced)
+			if err != nil {
+				t.Fatalf("migration.Hash(): %v", err)
+			}
+			meta.MigrationHash = h
+		}
+		a, err := ptypes.MarshalAny(meta)
+		if err != nil {
+			t.Fatalf("MarshalAny(): %v", err)
+		}
+		return &trillian.SignedMapRoot{Metadata: a}
+	}
+	for _, tc := range []struct {
+		desc    string
+		smr     *trillian.SignedMapRoot
+		wantErr error
+	}{
+		{desc: "logged", smr: smr(t, signed)},
+		{desc: "other migration", smr: smr(t, other), wantErr: ErrMigrationNotLogged},
+		{desc: "no migration", smr: smr(t, nil), wantErr: ErrMigrationNotLogged},
+		{desc: "no metadata", smr: &trillian.SignedMapRoot{}, wantErr: ErrMigrationNotLogged},
+	} {
+		if err := checkLogged(tc.smr, signed); err != tc.wantErr {
+			t.Errorf("%v: checkLogged(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+
+	"github.com/google/trillian"
+
+	"github.com/google/keytransparency/core/client/kt"
+
+	mpb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+// ErrMonitorQuorum occurs when fewer trusted monitors than required vouch
+// for a map root.
+var ErrMonitorQuorum = kt.ErrMonitorQuorum
+
+// TrustedMonitor is a monitor whose signature on a map root the client
+// accepts as evidence that the monitor verified the map root.
+type TrustedMonitor struct {
+	Client mpb.MonitorClient
+	// PublicKey verifies the map roots signed by the monitor.
+	PublicKey crypto.PublicKey
+}
+
+// MonitorQuorum is the set of monitors that must agree on a map root before
+// the client accepts it.
+type MonitorQuorum struct {
+	// KtURL is the address of the key server as known to the monitors.
+	KtURL    string
+	Monitors []TrustedMonitor
+	// Threshold is the number of monitors that must have signed the map
+	// root.
+	Threshold int
+}
+
+// UseMonitors makes the client accept map roots only once quorum.Threshold
+// of the monitors in quorum have verified and signed them. A nil quorum
+// disables the check.
+func (c *Client) UseMonitors(quorum *MonitorQuorum) {
+	c.monitors = quorum
+}
+
+// checkMonitors returns an error unless enough trusted monitors have signed
+// smr.
+func (c *Client) checkMonitors(ctx context.Context, smr *trillian.SignedMapRoot) error {
+	q := c.monitors
+	if q == nil {
+		return nil
+	}
+	agreed := 0
+	for _, m := range q.Monitors {
+		if agreed >= q.Threshold {
+			break
+		}
+		state, err := m.Client.GetStateByRevision(ctx, &mpb.GetStateRequest{
+			KtUrl:    q.KtURL,
+			DomainId: c.domainID,
+			Epoch:    smr.GetMapRevision(),
+		})
+		if err != nil {
+			Vlog.Printf("GetStateByRevision(%v): %v", smr.GetMapRevision(), err)
+			continue
+		}
+		if err := kt.VerifyMonitorSignature(m.PublicKey, state.GetSmr(), smr); err != nil {
+			Vlog.Printf("Monitor state for revision %v: %v", smr.GetMapRevision(), err)
+			continue
+		}
+		agreed++
+	}
+	if agreed < q.Threshold {
+		return kterrors.Wrap(ErrVerification, "checkMonitors",
+			fmt.Errorf("%w: %v of %v at revision %v", ErrMonitorQuorum, agreed, q.Threshold, smr.GetMapRevision()))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	mpb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// fakeMonitor returns the same state for every revision.
+type fakeMonitor struct {
+	mpb.MonitorClient
+	state *mpb.State
+	err   error
+}
+
+func (m *fakeMonitor) GetStateByRevision(context.Context, *mpb.GetStateRequest, ...grpc.CallOption) (*mpb.State, error) {
+	return m.state, m.err
+}
+
+// signedMonitor returns a trusted monitor that vouches for smr.
+func signedMonitor(t *testing.T, smr trillian.SignedMapRoot) TrustedMonitor {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(): %v", err)
+	}
+	smr.Signature = nil
+	sig, err := tcrypto.NewSHA256Signer(key).SignObject(smr)
+	if err != nil {
+		t.Fatalf("SignObject(): %v", err)
+	}
+	smr.Signature = sig
+	return TrustedMonitor{
+		Client:    &fakeMonitor{state: &mpb.State{Smr: &smr}},
+		PublicKey: key.Public(),
+	}
+}
+
+func TestCheckMonitors(t *testing.T) {
+	ctx := context.Background()
+	smr := &trillian.SignedMapRoot{MapRevision: 3, RootHash: []byte("root")}
+	good := signedMonitor(t, *smr)
+	forked := signedMonitor(t, trillian.SignedMapRoot{MapRevision: 3, RootHash: []byte("fork")})
+	wrongKey := signedMonitor(t, *smr)
+	wrongKey.PublicKey = good.PublicKey
+	failed := TrustedMonitor{Client: &fakeMonitor{state: &mpb.State{}}, PublicKey: good.PublicKey}
+	offline := TrustedMonitor{Client: &fakeMonitor{err: errors.New("offline")}, PublicKey: good.PublicKey}
+
+	for _, tc := range []struct {
+		desc     string
+		monitors []TrustedMonitor
+		k        int
+		wantErr  bool
+	}{
+		{desc: "none required", k: 0},
+		{desc: "one of one", monitors: []TrustedMonitor{good}, k: 1},
+		{desc: "two of four", monitors: []TrustedMonitor{offline, good, failed, signedMonitor(t, *smr)}, k: 2},
+		{desc: "too few", monitors: []TrustedMonitor{good, offline, failed}, k: 2, wantErr: true},
+		{desc: "fork", monitors: []TrustedMonitor{forked}, k: 1, wantErr: true},
+		{desc: "wrong key", monitors: []TrustedMonitor{wrongKey}, k: 1, wantErr: true},
+	} {
+		c := &Client{domainID: "domain"}
+		c.UseMonitors(&MonitorQuorum{KtURL: "kt", Monitors: tc.monitors, Threshold: tc.k})
+		err := c.checkMonitors(ctx, smr)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: checkMonitors(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		if err != nil && (!errors.Is(err, ErrMonitorQuorum) || !errors.Is(err, ErrVerification)) {
+			t.Errorf("%v: checkMonitors(): %v, want %v", tc.desc, err, ErrMonitorQuorum)
+		}
+	}
+}
+
+func TestCheckRoots(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	smr := trillian.SignedMapRoot{MapRevision: 3, RootHash: []byte("root"), TimestampNanos: now.UnixNano()}
+	good := signedMonitor(t, smr)
+	future := smr
+	future.TimestampNanos = now.Add(time.Hour).UnixNano()
+	stale := smr
+	stale.TimestampNanos = now.Add(-2 * time.Hour).UnixNano()
+
+	for _, tc := range []struct {
+		desc     string
+		smr      trillian.SignedMapRoot
+		monitors []TrustedMonitor
+		wantErr  bool
+	}{
+		{desc: "valid", smr: smr, monitors: []TrustedMonitor{good}},
+		{desc: "future", smr: future, monitors: []TrustedMonitor{signedMonitor(t, future)}, wantErr: true},
+		{desc: "stale", smr: stale, monitors: []TrustedMonitor{signedMonitor(t, stale)}, wantErr: true},
+		{desc: "no monitor", smr: smr, wantErr: true},
+	} {
+		c := &Client{domainID: "domain", MaxClockSkew: time.Minute, MaxStaleness: time.Hour}
+		c.UseMonitors(&MonitorQuorum{KtURL: "kt", Monitors: tc.monitors, Threshold: 1})
+		smr := tc.smr
+		if err := c.checkRoots(ctx, &pb.GetEntryResponse{Smr: &smr}); (err != nil) != tc.wantErr {
+			t.Errorf("%v: checkRoots(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
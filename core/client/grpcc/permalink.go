@@ -0,0 +1,98 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/google/keytransparency/core/permalink"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ErrPermalinkMismatch occurs when the entry a permalink refers to no longer
+// matches the digest in the permalink.
+var ErrPermalinkMismatch = errors.New("entry does not match permalink")
+
+// CreatePermalink returns a signed token that refers to the entry of userID
+// at revision, or at the latest revision if revision is 0.
+func (c *Client) CreatePermalink(ctx context.Context, userID, appID string, revision int64, opts ...grpc.CallOption) (string, error) {
+	bw := bandwidthFrom(ctx)
+	resp, err := c.cli.CreatePermalink(ctx, &pb.CreatePermalinkRequest{
+		DomainId: c.domainID,
+		AppId:    appID,
+		UserId:   userID,
+		Revision: revision,
+	}, bw.callOpts(opts)...)
+	if err := bw.account(resp, err); err != nil {
+		return "", err
+	}
+	return resp.GetToken(), nil
+}
+
+// ResolvePermalink checks that token is signed by one of keys, fetches the
+// entry it refers to, and verifies that the entry still matches the token.
+// The profile of the entry is returned along with the map root it was read
+// at. ErrPermalinkMismatch is returned if the server now reports a different
+// entry at the revision than when the token was created.
+func (c *Client) ResolvePermalink(ctx context.Context, token string, keys []crypto.PublicKey, opts ...grpc.CallOption) (*pb.Permalink, []byte, *trillian.SignedMapRoot, error) {
+	p, err := permalink.Verify(token, keys)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if got, want := p.GetDomainId(), c.domainID; got != want {
+		return nil, nil, nil, fmt.Errorf("permalink is for domain %v, want %v", got, want)
+	}
+
+	bw := bandwidthFrom(ctx)
+	e, err := c.cli.GetEntryAtRevision(ctx, &pb.GetEntryAtRevisionRequest{
+		DomainId:      c.domainID,
+		AppId:         p.GetAppId(),
+		UserId:        p.GetUserId(),
+		Revision:      p.GetRevision(),
+		FirstTreeSize: c.trusted.TreeSize,
+	}, bw.callOpts(opts)...)
+	if err := bw.account(e, err); err != nil {
+		return nil, nil, nil, err
+	}
+	root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, p.GetAppId(), p.GetUserId(), &c.trusted, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := c.checkRoots(ctx, e); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, nil, nil, err
+	}
+	if got, want := e.GetSmr().GetMapRevision(), p.GetRevision(); got != want {
+		return nil, nil, nil, fmt.Errorf("entry is at revision %v, want %v", got, want)
+	}
+	digest := permalink.Digest(e.GetSmr().GetRootHash(), e.GetLeafProof().GetLeaf().GetLeafValue())
+	if !bytes.Equal(digest, p.GetProofDigest()) {
+		return nil, nil, nil, ErrPermalinkMismatch
+	}
+	if e.GetCommittedPurged() {
+		return p, nil, e.GetSmr(), ErrPurged
+	}
+	return p, e.GetCommitted().GetData(), e.GetSmr(), nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// QueuedUpdate is a signed update that has not been submitted yet.
+type QueuedUpdate struct {
+	// Request is the signed update.
+	Request *pb.UpdateEntryRequest
+	// Previous is the leaf value of the entry the update modifies.
+	Previous []byte
+}
+
+// UpdateStore persists the updates held by an UpdateQueue, so that updates
+// made while offline survive restarts.
+type UpdateStore interface {
+	// LoadUpdates returns the queued updates of domainID, oldest first.
+	LoadUpdates(ctx context.Context, domainID string) ([]*QueuedUpdate, error)
+	// SaveUpdates atomically replaces the queued updates of domainID.
+	SaveUpdates(ctx context.Context, domainID string, updates []*QueuedUpdate) error
+}
+
+// UpdateQueue holds the updates made while the key server is unreachable
+// and submits them, in order, once it can be reached again.
+type UpdateQueue struct {
+	c     *Client
+	store UpdateStore
+
+	mu      sync.Mutex
+	updates []*QueuedUpdate
+}
+
+// NewUpdateQueue returns a queue of updates to the domain of the client that
+// holds the updates saved in store.
+func (c *Client) NewUpdateQueue(ctx context.Context, store UpdateStore) (*UpdateQueue, error) {
+	updates, err := store.LoadUpdates(ctx, c.domainID)
+	if err != nil {
+		return nil, fmt.Errorf("loading queued updates: %w", err)
+	}
+	return &UpdateQueue{c: c, store: store, updates: updates}, nil
+}
+
+// Len returns the number of queued updates.
+func (q *UpdateQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.updates)
+}
+
+// Add signs an update of the entry of userID in appID and queues it. The
+// update modifies the entry in the EntryCache of the client if it is cached,
+// so that updates can be made offline, and the entry on the server otherwise.
+func (q *UpdateQueue) Add(ctx context.Context, appID, userID string, profileData []byte,
+	signers []signatures.Signer, authorizedKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := q.c
+	e, err := c.verifiedEntry(ctx, userID, appID, opts...)
+	if err != nil {
+		return err
+	}
+	prev := e.GetLeafProof().GetLeaf().GetLeafValue()
+	m, err := c.kt.NewMutation(c.domainID, appID, userID, profileData, authorizedKeys,
+		e.GetVrfProof(), prev)
+	if err != nil {
+		return fmt.Errorf("CreateUpdateEntryRequest: %w", err)
+	}
+	req, err := m.SerializeAndSign(signers, c.trusted.TreeSize)
+	if err != nil {
+		return fmt.Errorf("SerializeAndSign(): %w", err)
+	}
+	updates := append(q.updates[:len(q.updates):len(q.updates)], &QueuedUpdate{Request: req, Previous: prev})
+	if err := q.store.SaveUpdates(ctx, c.domainID, updates); err != nil {
+		return fmt.Errorf("saving queued updates: %w", err)
+	}
+	q.updates = updates
+	return nil
+}
+
+// Flush submits the queued updates in order, and removes each one once it is
+// visible on the server. Updates whose entry was changed by another update
+// in the meantime are rebased and signed again with signers. With MaxRebases
+// set, an update that cannot be rebased because the authorized keys of the
+// entry changed is removed, and its error returned. Flush stops at the first other failure,
+// such as the server being unreachable, and keeps the updates that are left.
+func (q *UpdateQueue) Flush(ctx context.Context, signers []signatures.Signer, opts ...grpc.CallOption) ([]*UpdateResult, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var results []*UpdateResult
+	for len(q.updates) > 0 {
+		u := q.updates[0]
+		m, err := entry.FromRequest(u.Request, u.Previous)
+		if err != nil {
+			return results, fmt.Errorf("entry.FromRequest(): %w", err)
+		}
+		result, err := q.c.submit(ctx, m, localSigners(signers), opts...)
+		if err != nil && !errors.Is(err, entry.ErrIncompatiblePrevious) {
+			return results, err
+		}
+		if err := q.store.SaveUpdates(ctx, q.c.domainID, q.updates[1:]); err != nil {
+			return results, fmt.Errorf("saving queued updates: %w", err)
+		}
+		q.updates = q.updates[1:]
+		if err != nil {
+			return results, fmt.Errorf("update of %v/%v: %w", u.Request.GetAppId(), u.Request.GetUserId(), err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FileUpdateStore keeps the queued updates of each domain in a file in a
+// directory.
+type FileUpdateStore struct {
+	Dir string
+}
+
+// savedUpdate is the encoding of a QueuedUpdate in a FileUpdateStore.
+type savedUpdate struct {
+	Request  []byte `json:"request"`
+	Previous []byte `json:"previous"`
+}
+
+func (s FileUpdateStore) name(domainID string) string {
+	return url.PathEscape(domainID) + ".updates"
+}
+
+// LoadUpdates implements UpdateStore.
+func (s FileUpdateStore) LoadUpdates(_ context.Context, domainID string) ([]*QueuedUpdate, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, s.name(domainID)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var saved []savedUpdate
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return nil, fmt.Errorf("reading queued updates for %v: %w", domainID, err)
+	}
+	updates := make([]*QueuedUpdate, 0, len(saved))
+	for _, u := range saved {
+		req := &pb.UpdateEntryRequest{}
+		if err := proto.Unmarshal(u.Request, req); err != nil {
+			return nil, fmt.Errorf("reading queued updates for %v: %w", domainID, err)
+		}
+		updates = append(updates, &QueuedUpdate{Request: req, Previous: u.Previous})
+	}
+	return updates, nil
+}
+
+// SaveUpdates implements UpdateStore.
+func (s FileUpdateStore) SaveUpdates(_ context.Context, domainID string, updates []*QueuedUpdate) error {
+	saved := make([]savedUpdate, 0, len(updates))
+	for _, u := range updates {
+		req, err := proto.Marshal(u.Request)
+		if err != nil {
+			return err
+		}
+		saved = append(saved, savedUpdate{Request: req, Previous: u.Previous})
+	}
+	b, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Dir, s.name(domainID), b)
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestFileUpdateStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "updates")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := FileUpdateStore{Dir: dir}
+
+	if got, err := store.LoadUpdates(ctx, "domain"); err != nil || len(got) != 0 {
+		t.Errorf("LoadUpdates(empty): %v, %v, want none", got, err)
+	}
+	updates := []*QueuedUpdate{
+		{Request: &pb.UpdateEntryRequest{DomainId: "domain", UserId: "alice", AppId: "app"}, Previous: []byte("prev")},
+		{Request: &pb.UpdateEntryRequest{DomainId: "domain", UserId: "bob", AppId: "app"}},
+	}
+	if err := store.SaveUpdates(ctx, "domain", updates); err != nil {
+		t.Fatalf("SaveUpdates(): %v", err)
+	}
+	got, err := store.LoadUpdates(ctx, "domain")
+	if err != nil {
+		t.Fatalf("LoadUpdates(): %v", err)
+	}
+	if len(got) != len(updates) {
+		t.Fatalf("LoadUpdates(): %v updates, want %v", len(got), len(updates))
+	}
+	for i, u := range updates {
+		if !proto.Equal(got[i].Request, u.Request) || string(got[i].Previous) != string(u.Previous) {
+			t.Errorf("LoadUpdates()[%v]: %v, want %v", i, got[i], u)
+		}
+	}
+	if got, err := store.LoadUpdates(ctx, "other"); err != nil || len(got) != 0 {
+		t.Errorf("LoadUpdates(other): %v, %v, want none", got, err)
+	}
+}
+
+func TestUpdateQueueOffline(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "updates")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store := FileUpdateStore{Dir: dir}
+	saved := &QueuedUpdate{Request: &pb.UpdateEntryRequest{DomainId: "domain", UserId: "alice", AppId: "app"}}
+	if err := store.SaveUpdates(ctx, "domain", []*QueuedUpdate{saved}); err != nil {
+		t.Fatalf("SaveUpdates(): %v", err)
+	}
+
+	c := &Client{cli: &offlineServer{}, domainID: "domain", trusted: trillian.SignedLogRoot{TreeSize: 5}}
+	q, err := c.NewUpdateQueue(ctx, store)
+	if err != nil {
+		t.Fatalf("NewUpdateQueue(): %v", err)
+	}
+	if got := q.Len(); got != 1 {
+		t.Errorf("NewUpdateQueue(): %v updates, want 1", got)
+	}
+
+	// Without a cached entry, updates cannot be made offline.
+	if err := q.Add(ctx, "app", "bob", []byte("bob"), nil, nil); err == nil {
+		t.Errorf("Add(uncached): nil, want error")
+	}
+	// Updates that cannot be submitted stay queued.
+	if _, err := q.Flush(ctx, nil); err == nil {
+		t.Errorf("Flush(): nil, want error")
+	}
+	if got := q.Len(); got != 1 {
+		t.Errorf("Len(): %v, want 1", got)
+	}
+	if got, err := store.LoadUpdates(ctx, "domain"); err != nil || len(got) != 1 {
+		t.Errorf("LoadUpdates(): %v, %v, want 1 update", got, err)
+	}
+}
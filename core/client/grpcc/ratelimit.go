@@ -0,0 +1,149 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// The RPCs that a RateLimiter limits.
+const (
+	RPCGetEntry         = "GetEntry"
+	RPCUpdateEntry      = "UpdateEntry"
+	RPCListEntryHistory = "ListEntryHistory"
+)
+
+// RateLimit is the rate at which an RPC may be called.
+type RateLimit struct {
+	// PerSecond is the sustained number of calls per second. Zero means
+	// unlimited.
+	PerSecond float64
+	// Burst is the number of calls that may be made at once after the RPC
+	// has not been called for a while. It is at least 1.
+	Burst int
+}
+
+// bucket is the token bucket of one RPC.
+type bucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits the rate of calls to each RPC with a token bucket, so
+// that clients embedded in other applications do not overwhelm the key
+// server, e.g. when they all reconnect after a network partition. Calls over
+// the limit wait until they are allowed.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that limits the RPCs in limits, keyed
+// by RPC name such as RPCGetEntry. Other RPCs are not limited.
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	r := &RateLimiter{buckets: make(map[string]*bucket), now: time.Now}
+	for rpc, l := range limits {
+		if l.Burst < 1 {
+			l.Burst = 1
+		}
+		r.buckets[rpc] = &bucket{limit: l, tokens: float64(l.Burst)}
+	}
+	return r
+}
+
+// Wait blocks until a call to rpc is allowed, or returns ctx.Err() if ctx is
+// done first. A nil RateLimiter allows every call.
+func (r *RateLimiter) Wait(ctx context.Context, rpc string) error {
+	for {
+		delay := r.reserve(rpc)
+		if delay == 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token for a call to rpc and returns 0, or returns how long
+// to wait until a token is available.
+func (r *RateLimiter) reserve(rpc string) time.Duration {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.buckets[rpc]
+	if !ok || b.limit.PerSecond <= 0 {
+		return 0
+	}
+	now := r.now()
+	if !b.last.IsZero() {
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.PerSecond)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.PerSecond * float64(time.Second))
+}
+
+// LimitRate makes the client wait for r before each call to GetEntry,
+// UpdateEntry and ListEntryHistory. A nil r removes the limit.
+func (c *Client) LimitRate(r *RateLimiter) {
+	if l, ok := c.cli.(*rateLimitedClient); ok {
+		l.limiter = r
+		return
+	}
+	c.cli = &rateLimitedClient{KeyTransparencyClient: c.cli, limiter: r}
+}
+
+// rateLimitedClient waits for limiter before the calls it limits.
+type rateLimitedClient struct {
+	pb.KeyTransparencyClient
+	limiter *RateLimiter
+}
+
+func (l *rateLimitedClient) GetEntry(ctx context.Context, in *pb.GetEntryRequest, opts ...grpc.CallOption) (*pb.GetEntryResponse, error) {
+	if err := l.limiter.Wait(ctx, RPCGetEntry); err != nil {
+		return nil, err
+	}
+	return l.KeyTransparencyClient.GetEntry(ctx, in, opts...)
+}
+
+func (l *rateLimitedClient) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest, opts ...grpc.CallOption) (*pb.UpdateEntryResponse, error) {
+	if err := l.limiter.Wait(ctx, RPCUpdateEntry); err != nil {
+		return nil, err
+	}
+	return l.KeyTransparencyClient.UpdateEntry(ctx, in, opts...)
+}
+
+func (l *rateLimitedClient) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRequest, opts ...grpc.CallOption) (*pb.ListEntryHistoryResponse, error) {
+	if err := l.limiter.Wait(ctx, RPCListEntryHistory); err != nil {
+		return nil, err
+	}
+	return l.KeyTransparencyClient.ListEntryHistory(ctx, in, opts...)
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Now()
+	r := NewRateLimiter(map[string]RateLimit{
+		RPCGetEntry:    {PerSecond: 2, Burst: 2},
+		RPCUpdateEntry: {PerSecond: 1},
+	})
+	r.now = func() time.Time { return now }
+
+	for i, tc := range []struct {
+		advance time.Duration
+		rpc     string
+		want    time.Duration
+	}{
+		{rpc: RPCGetEntry},
+		{rpc: RPCGetEntry},
+		{rpc: RPCGetEntry, want: 500 * time.Millisecond},
+		{advance: 250 * time.Millisecond, rpc: RPCGetEntry, want: 250 * time.Millisecond},
+		{advance: 250 * time.Millisecond, rpc: RPCGetEntry},
+		{advance: time.Hour, rpc: RPCGetEntry},
+		{rpc: RPCGetEntry},
+		{rpc: RPCGetEntry, want: 500 * time.Millisecond},
+		{rpc: RPCUpdateEntry},
+		{rpc: RPCUpdateEntry, want: time.Second},
+		{rpc: RPCListEntryHistory},
+		{rpc: RPCListEntryHistory},
+	} {
+		now = now.Add(tc.advance)
+		if got := r.reserve(tc.rpc); got != tc.want {
+			t.Errorf("%v: reserve(%v): %v, want %v", i, tc.rpc, got, tc.want)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	r := NewRateLimiter(map[string]RateLimit{RPCGetEntry: {PerSecond: 0.001}})
+	if err := r.Wait(context.Background(), RPCGetEntry); err != nil {
+		t.Fatalf("Wait(): %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Wait(ctx, RPCGetEntry); err != context.DeadlineExceeded {
+		t.Errorf("Wait(limited): %v, want %v", err, context.DeadlineExceeded)
+	}
+	var nilLimiter *RateLimiter
+	if err := nilLimiter.Wait(ctx, RPCGetEntry); err != nil {
+		t.Errorf("nil.Wait(): %v", err)
+	}
+}
+
+func TestLimitRate(t *testing.T) {
+	srv := &offlineServer{}
+	c := &Client{cli: srv}
+	c.LimitRate(NewRateLimiter(map[string]RateLimit{RPCGetEntry: {PerSecond: 0.001}}))
+	c.LimitRate(NewRateLimiter(map[string]RateLimit{RPCGetEntry: {PerSecond: 0.001}}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		c.cli.GetEntry(ctx, &pb.GetEntryRequest{})
+	}
+	if srv.calls != 1 {
+		t.Errorf("GetEntry reached the server %v times, want 1", srv.calls)
+	}
+}
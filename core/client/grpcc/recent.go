@@ -0,0 +1,139 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	"github.com/google/keytransparency/core/client/kt"
+	kterrors "github.com/google/keytransparency/core/errors"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ListRecentChanges returns up to max of the most recent changes of the
+// profile of userID in appID, newest first. The history is read backwards
+// from the latest epoch, one page at a time, so only as much of it is
+// downloaded and verified as is needed to find max changes. Like
+// ListHistory, purged profiles are omitted and the absence of a profile
+// before the first one is not a change.
+func (c *Client) ListRecentChanges(ctx context.Context, userID, appID string, max int, opts ...grpc.CallOption) ([]*EntryChange, error) {
+	if max < 1 {
+		return nil, fmt.Errorf("max=%v, want > 0", max)
+	}
+	trusted := c.trusted
+	bw := bandwidthFrom(ctx)
+
+	var changes []*EntryChange
+	// newer is the last profile seen, which is a change unless the profile
+	// at the epoch before it is the same.
+	var newer *pb.GetEntryResponse
+	token := ""
+	for {
+		// The log roots of a page are verified with a single consistency
+		// chain, so the page needs no consistency proof per entry.
+		resp, err := c.cli.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+			DomainId:    c.domainID,
+			UserId:      userID,
+			AppId:       appID,
+			PageSize:    pageSize,
+			PageToken:   token,
+			NewestFirst: true,
+		}, bw.callOpts(opts)...)
+		if err := bw.account(resp, err); err != nil {
+			return nil, rpcError("ListEntryHistory", err)
+		}
+		for _, v := range resp.GetValues() {
+			if err := checkStale("ListRecentChanges", &trusted, v.GetLogRoot()); err != nil {
+				return nil, err
+			}
+		}
+		root, err := c.verifyHistoryChain(ctx, userID, appID, &trusted, resp.GetValues(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range resp.GetValues() {
+			if err := c.checkRoots(ctx, v); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.updateTrusted(ctx, root); err != nil {
+			return nil, err
+		}
+		trusted = *root
+
+		for _, v := range resp.GetValues() {
+			if v.GetCommittedPurged() {
+				continue
+			}
+			if newer != nil && !sameProfile(newer, v) {
+				if changes = append(changes, entryChange(newer)); len(changes) == max {
+					return changes, nil
+				}
+			}
+			newer = v
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+	// The profile before epoch 0 is empty.
+	if newer != nil && !sameProfile(newer, nil) {
+		changes = append(changes, entryChange(newer))
+	}
+	return changes, nil
+}
+
+// verifyHistoryChain verifies responses, a history of the entry of userID in
+// appID, with a single consistency chain from trusted through their log
+// roots. It returns the log root to trust from now on.
+func (c *Client) verifyHistoryChain(ctx context.Context, userID, appID string, trusted *trillian.SignedLogRoot,
+	responses []*pb.GetEntryResponse, opts []grpc.CallOption) (*trillian.SignedLogRoot, error) {
+	roots, err := kt.HistoryRoots(trusted, responses)
+	if err != nil {
+		return nil, kterrors.Wrap(ErrVerification, "HistoryRoots", err)
+	}
+	var chain *pb.LogConsistencyChain
+	if len(roots) > 0 {
+		sizes := make([]int64, 0, len(roots))
+		for _, r := range roots {
+			sizes = append(sizes, r.GetTreeSize())
+		}
+		bw := bandwidthFrom(ctx)
+		chain, err = c.cli.GetLogConsistencyChain(ctx, &pb.GetLogConsistencyChainRequest{
+			DomainId:  c.domainID,
+			TreeSizes: sizes,
+		}, bw.callOpts(opts)...)
+		if err := bw.account(chain, err); err != nil {
+			return nil, rpcError("GetLogConsistencyChain", err)
+		}
+	}
+	return c.kt.VerifyHistoryChain(ctx, c.domainID, appID, userID, trusted, responses, chain)
+}
+
+// sameProfile returns true if a and b hold the same profile data.
+func sameProfile(a, b *pb.GetEntryResponse) bool {
+	return bytes.Equal(a.GetCommitted().GetData(), b.GetCommitted().GetData())
+}
+
+// entryChange returns the change that set the profile of e.
+func entryChange(e *pb.GetEntryResponse) *EntryChange {
+	return &EntryChange{Profile: e.GetCommitted().GetData(), SMR: e.GetSmr()}
+}
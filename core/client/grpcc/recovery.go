@@ -0,0 +1,53 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc"
+)
+
+// SetRecoveryKeys updates the profile of userID in appID like Update, and
+// designates recoveryKeys as the keys that may later reset the entry with
+// ResetAccount. The authorized keys are unchanged. Recovery keys cannot sign
+// updates, so they must not be authorized keys. No recovery keys disables
+// resets.
+func (c *Client) SetRecoveryKeys(ctx context.Context, appID, userID string, profileData []byte,
+	signers []signatures.Signer, recoveryKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.update(ctx, appID, userID, profileData, localSigners(signers), nil,
+		func(m *entry.Mutation) error {
+			m.SetRecoveryKeys(recoveryKeys)
+			return nil
+		}, opts...)
+}
+
+// ResetAccount is an emergency revocation of all the authorized keys of userID
+// in appID, e.g. after they are lost or compromised. The authorized keys are
+// replaced with authorizedKeys and the profile is cleared. The reset is signed
+// by recoverySigners, which must hold a recovery key designated with
+// SetRecoveryKeys. The recovery keys stay unchanged.
+func (c *Client) ResetAccount(ctx context.Context, appID, userID string,
+	recoverySigners []signatures.Signer, authorizedKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.update(ctx, appID, userID, nil, localSigners(recoverySigners), nil,
+		func(m *entry.Mutation) error {
+			return m.ResetAuthorizedKeys(authorizedKeys)
+		}, opts...)
+}
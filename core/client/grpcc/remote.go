@@ -0,0 +1,85 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// signFunc signs m into a request for a client that trusts a log root of
+// size trustedTreeSize.
+type signFunc func(ctx context.Context, m *entry.Mutation, trustedTreeSize int64) (*pb.UpdateEntryRequest, error)
+
+// localSigners returns a signFunc that signs with signers.
+func localSigners(signers []signatures.Signer) signFunc {
+	return func(_ context.Context, m *entry.Mutation, trustedTreeSize int64) (*pb.UpdateEntryRequest, error) {
+		req, err := m.SerializeAndSign(signers, trustedTreeSize)
+		if err != nil {
+			return nil, fmt.Errorf("SerializeAndSign(): %w", err)
+		}
+		return req, nil
+	}
+}
+
+// remoteSigners returns a signFunc that asks all of signers for their
+// signatures concurrently.
+func remoteSigners(signers []signatures.RemoteSigner) signFunc {
+	return func(ctx context.Context, m *entry.Mutation, trustedTreeSize int64) (*pb.UpdateEntryRequest, error) {
+		e := m.Serialize()
+		type signature struct {
+			keyID string
+			sig   *sigpb.DigitallySigned
+			err   error
+		}
+		sigc := make(chan signature, len(signers))
+		for _, s := range signers {
+			go func(s signatures.RemoteSigner) {
+				sig, err := s.Sign(ctx, e)
+				sigc <- signature{keyID: s.KeyID(), sig: sig, err: err}
+			}(s)
+		}
+		sigs := make(map[string]*sigpb.DigitallySigned, len(signers))
+		for range signers {
+			s := <-sigc
+			if s.err != nil {
+				return nil, fmt.Errorf("Sign(%v): %w", s.keyID, s.err)
+			}
+			sigs[s.keyID] = s.sig
+		}
+		req, err := m.AttachSignatures(sigs, trustedTreeSize)
+		if err != nil {
+			return nil, fmt.Errorf("AttachSignatures(): %w", err)
+		}
+		return req, nil
+	}
+}
+
+// UpdateRemote is Update for signers whose keys never leave the hardware or
+// service that holds them. The signers are asked for their signatures
+// concurrently, and again whenever the mutation is rebased.
+func (c *Client) UpdateRemote(ctx context.Context, appID, userID string, profileData []byte,
+	signers []signatures.RemoteSigner, authorizedKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	return c.update(ctx, appID, userID, profileData, remoteSigners(signers), authorizedKeys, nil, opts...)
+}
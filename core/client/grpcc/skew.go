@@ -0,0 +1,127 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// DefaultMaxClockSkew is the default tolerance between the local clock and
+// the server's clock.
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// ClockSkewError occurs when the local clock and the server's clock disagree by
+// more than the configured tolerance. Freshness checks made against the local
+// clock cannot be trusted while this condition holds.
+type ClockSkewError struct {
+	// Skew is the server's time minus the local time.
+	Skew time.Duration
+	// Tolerance is the maximum skew the client was configured to accept.
+	Tolerance time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	return fmt.Sprintf("clock skew of %v exceeds tolerance of %v", e.Skew, e.Tolerance)
+}
+
+// checkSkew returns a *ClockSkewError if serverTime and localTime differ by more
+// than c.MaxClockSkew. A MaxClockSkew of 0 disables the check.
+func (c *Client) checkSkew(serverTime, localTime time.Time) error {
+	if c.MaxClockSkew <= 0 {
+		return nil
+	}
+	skew := serverTime.Sub(localTime)
+	if skew > c.MaxClockSkew || -skew > c.MaxClockSkew {
+		return &ClockSkewError{Skew: skew, Tolerance: c.MaxClockSkew}
+	}
+	return nil
+}
+
+// CheckClockSkew asks the server for its current time and compares it against
+// the local clock. The returned duration is the server's time minus the local
+// time, corrected for half of the round trip time. If the skew exceeds
+// MaxClockSkew, a *ClockSkewError is returned along with the skew.
+func (c *Client) CheckClockSkew(ctx context.Context, opts ...grpc.CallOption) (time.Duration, error) {
+	sent := time.Now()
+	resp, err := c.cli.GetServerCapabilities(ctx, &pb.GetServerCapabilitiesRequest{}, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("GetServerCapabilities(): %w", err)
+	}
+	received := time.Now()
+	serverTime, err := ptypes.Timestamp(resp.GetServerTime())
+	if err != nil {
+		return 0, fmt.Errorf("ptypes.Timestamp(): %w", err)
+	}
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := serverTime.Sub(local)
+	return skew, c.checkSkew(serverTime, local)
+}
+
+// verifyRootTime ensures that a map root was not created in the future as
+// judged by the local clock, which would indicate that the local clock is
+// behind the server's.
+func (c *Client) verifyRootTime(smr *trillian.SignedMapRoot) error {
+	rootTime := time.Unix(0, smr.GetTimestampNanos())
+	now := time.Now()
+	if rootTime.Before(now) {
+		return nil
+	}
+	return c.checkSkew(rootTime, now)
+}
+
+// FreshnessError occurs when the latest map root served by the server is
+// older than the client accepts, which indicates that the server stopped
+// creating epochs or is replaying old roots to the client (a freeze attack).
+type FreshnessError struct {
+	// Age is the age of the map root, as judged by the local clock.
+	Age time.Duration
+	// MaxStaleness is the maximum age the client was configured to accept.
+	MaxStaleness time.Duration
+}
+
+func (e *FreshnessError) Error() string {
+	return fmt.Sprintf("map root is %v old, more than the allowed %v", e.Age, e.MaxStaleness)
+}
+
+// Is returns true for ErrStaleRoot.
+func (e *FreshnessError) Is(target error) bool { return target == ErrStaleRoot }
+
+// checkFreshness returns a *FreshnessError if the latest root the server
+// proved in e was created more than c.MaxStaleness before now. The latest
+// root is the newer of the map root and the log root of e, so that entries
+// read at past revisions are judged by the log root the server served them
+// with. A MaxStaleness of 0 disables the check.
+func (c *Client) checkFreshness(e *pb.GetEntryResponse, now time.Time) error {
+	if c.MaxStaleness <= 0 {
+		return nil
+	}
+	latest := e.GetSmr().GetTimestampNanos()
+	if t := e.GetLogRoot().GetTimestampNanos(); t > latest {
+		latest = t
+	}
+	age := now.Sub(time.Unix(0, latest))
+	if age > c.MaxStaleness {
+		return &FreshnessError{Age: age, MaxStaleness: c.MaxStaleness}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// clockServer reports time as its current time.
+type clockServer struct {
+	pb.KeyTransparencyClient
+	time time.Time
+}
+
+func (s *clockServer) GetServerCapabilities(context.Context, *pb.GetServerCapabilitiesRequest, ...grpc.CallOption) (*pb.ServerCapabilities, error) {
+	ts, err := ptypes.TimestampProto(s.time)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ServerCapabilities{ServerTime: ts}, nil
+}
+
+func TestCheckSkew(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		tolerance time.Duration
+		skew      time.Duration
+		wantErr   bool
+	}{
+		{tolerance: 0, skew: 24 * time.Hour},
+		{tolerance: time.Minute, skew: 30 * time.Second},
+		{tolerance: time.Minute, skew: -30 * time.Second},
+		{tolerance: time.Minute, skew: 2 * time.Minute, wantErr: true},
+		{tolerance: time.Minute, skew: -2 * time.Minute, wantErr: true},
+	} {
+		c := &Client{MaxClockSkew: tc.tolerance}
+		err := c.checkSkew(now.Add(tc.skew), now)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("checkSkew(skew %v, tolerance %v): %v, wantErr %v", tc.skew, tc.tolerance, err, tc.wantErr)
+		}
+		if skewErr, ok := err.(*ClockSkewError); err != nil && (!ok || skewErr.Skew != tc.skew) {
+			t.Errorf("checkSkew(skew %v): %v, want *ClockSkewError with skew %v", tc.skew, err, tc.skew)
+		}
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		skew    time.Duration
+		wantErr bool
+	}{
+		{skew: 0},
+		{skew: time.Hour, wantErr: true},
+		{skew: -time.Hour, wantErr: true},
+	} {
+		c := &Client{
+			cli:          &clockServer{time: time.Now().Add(tc.skew)},
+			MaxClockSkew: DefaultMaxClockSkew,
+		}
+		skew, err := c.CheckClockSkew(ctx)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("CheckClockSkew(skew %v): %v, wantErr %v", tc.skew, err, tc.wantErr)
+		}
+		if diff := skew - tc.skew; diff > time.Second || diff < -time.Second {
+			t.Errorf("CheckClockSkew(skew %v): %v", tc.skew, skew)
+		}
+	}
+}
+
+func TestVerifyRootTime(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		age     time.Duration
+		wantErr bool
+	}{
+		{age: time.Hour},
+		{age: -time.Second},
+		{age: -time.Hour, wantErr: true},
+	} {
+		c := &Client{MaxClockSkew: time.Minute}
+		smr := &trillian.SignedMapRoot{TimestampNanos: now.Add(-tc.age).UnixNano()}
+		if err := c.verifyRootTime(smr); (err != nil) != tc.wantErr {
+			t.Errorf("verifyRootTime(age %v): %v, wantErr %v", tc.age, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckFreshness(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		maxStaleness time.Duration
+		age, logAge  time.Duration
+		wantErr      bool
+	}{
+		{maxStaleness: 0, age: 24 * time.Hour},
+		{maxStaleness: time.Hour, age: time.Minute},
+		{maxStaleness: time.Hour, age: -time.Minute},
+		{maxStaleness: time.Hour, age: 2 * time.Hour, wantErr: true},
+		// Entries at past revisions are judged by their log root.
+		{maxStaleness: time.Hour, age: 2 * time.Hour, logAge: time.Minute},
+		{maxStaleness: time.Hour, age: 3 * time.Hour, logAge: 2 * time.Hour, wantErr: true},
+	} {
+		c := &Client{MaxStaleness: tc.maxStaleness}
+		e := &pb.GetEntryResponse{Smr: &trillian.SignedMapRoot{TimestampNanos: now.Add(-tc.age).UnixNano()}}
+		if tc.logAge != 0 {
+			e.LogRoot = &trillian.SignedLogRoot{TimestampNanos: now.Add(-tc.logAge).UnixNano()}
+		}
+		err := c.checkFreshness(e, now)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("checkFreshness(age %v, log age %v, max %v): %v, wantErr %v", tc.age, tc.logAge, tc.maxStaleness, err, tc.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrStaleRoot) {
+			t.Errorf("checkFreshness(age %v): %v, want %v", tc.age, err, ErrStaleRoot)
+		}
+	}
+}
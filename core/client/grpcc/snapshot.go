@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// SnapshotUser selects the entry of a user for ExportSnapshot.
+type SnapshotUser struct {
+	AppID  string
+	UserID string
+}
+
+// ExportSnapshot assembles the entries of users at the latest epoch into a
+// snapshot that can be verified offline with VerifySnapshot, for instance
+// from a static site. Every entry is read at the same epoch, and all proof
+// material is verified before it is added to the snapshot. The snapshot
+// proves the consistency of its log roots with the log root the client
+// trusts, which is then updated, so that each snapshot a client exports can
+// be verified against the log root of the one before it.
+func (c *Client) ExportSnapshot(ctx context.Context, users []SnapshotUser, opts ...grpc.CallOption) (*pb.TransparencySnapshot, error) {
+	domain, err := c.servedDomain(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	trusted := c.trusted
+	epoch, err := c.cli.GetLatestEpoch(ctx, &pb.GetLatestEpochRequest{
+		DomainId:      c.domainID,
+		FirstTreeSize: trusted.TreeSize,
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestEpoch(%v): %w", c.domainID, err)
+	}
+	if err := checkStale("GetLatestEpoch", &trusted, epoch.GetLogRoot()); err != nil {
+		return nil, err
+	}
+	root, err := c.kt.VerifyEpoch(ctx, &trusted, epoch)
+	if err != nil {
+		return nil, err
+	}
+	revision := epoch.GetSmr().GetMapRevision()
+	snapshot := &pb.TransparencySnapshot{
+		Domain:     domain,
+		Epoch:      epoch,
+		CreateTime: ptypes.TimestampNow(),
+	}
+	for _, u := range users {
+		e, err := c.cli.GetEntryAtRevision(ctx, &pb.GetEntryAtRevisionRequest{
+			DomainId:      c.domainID,
+			UserId:        u.UserID,
+			AppId:         u.AppID,
+			Revision:      revision,
+			FirstTreeSize: trusted.TreeSize,
+		}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("GetEntryAtRevision(%v/%v, %v): %w", u.AppID, u.UserID, revision, err)
+		}
+		if _, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, u.AppID, u.UserID, &trusted, e); err != nil {
+			return nil, fmt.Errorf("%v/%v: %w", u.AppID, u.UserID, err)
+		}
+		snapshot.Entries = append(snapshot.Entries, &pb.SnapshotEntry{
+			AppId:  u.AppID,
+			UserId: u.UserID,
+			Entry:  e,
+		})
+	}
+	if err := checkSnapshotRoots(snapshot); err != nil {
+		return nil, err
+	}
+	if err := c.updateTrusted(ctx, root); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// VerifySnapshot verifies all the proofs in a TransparencySnapshot without
+// contacting the server, and that every entry was read at the epoch of the
+// snapshot. The proofs are verified under the keys of config, the domain the
+// caller trusts, rather than those of the domain in the snapshot. The log
+// roots of the snapshot must be consistent with trusted, typically the log
+// root of the previous snapshot of the same site, unless trusted is empty.
+// It returns the log root of the epoch of the snapshot, which callers should
+// trust from now on.
+func VerifySnapshot(ctx context.Context, config *pb.Domain, trusted *trillian.SignedLogRoot, snapshot *pb.TransparencySnapshot) (*trillian.SignedLogRoot, error) {
+	domainID := config.GetDomainId()
+	if got := snapshot.GetDomain().GetDomainId(); got != domainID {
+		return nil, fmt.Errorf("snapshot of domain %q, want %q", got, domainID)
+	}
+	c, err := NewFromConfig(nil, config)
+	if err != nil {
+		return nil, err
+	}
+	if trusted.GetTreeSize() > 0 {
+		if err := c.kt.VerifyLogRoot(trusted); err != nil {
+			return nil, fmt.Errorf("trusted root: %w", err)
+		}
+	} else {
+		trusted = &trillian.SignedLogRoot{}
+	}
+	root, err := c.kt.VerifyEpoch(ctx, trusted, snapshot.GetEpoch())
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snapshot.GetEntries() {
+		if _, err := c.kt.VerifyGetEntryResponse(ctx, domainID, s.GetAppId(), s.GetUserId(), trusted, s.GetEntry()); err != nil {
+			return nil, fmt.Errorf("%v/%v: %w", s.GetAppId(), s.GetUserId(), err)
+		}
+	}
+	if err := checkSnapshotRoots(snapshot); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkSnapshotRoots returns an error if an entry of snapshot was not read at
+// the map root of the snapshot's epoch.
+func checkSnapshotRoots(snapshot *pb.TransparencySnapshot) error {
+	smr := snapshot.GetEpoch().GetSmr()
+	for _, s := range snapshot.GetEntries() {
+		if !proto.Equal(s.GetEntry().GetSmr(), smr) {
+			return fmt.Errorf("%v/%v: read at revision %v, want %v",
+				s.GetAppId(), s.GetUserId(), s.GetEntry().GetSmr().GetMapRevision(), smr.GetMapRevision())
+		}
+	}
+	return nil
+}
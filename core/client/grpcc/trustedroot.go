@@ -0,0 +1,167 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// TrustedRootStore persists the log root a client trusts, so that a client
+// that restarts keeps checking the consistency of the log from where it
+// stopped instead of trusting the next root it is shown.
+type TrustedRootStore interface {
+	// LoadRoot returns the trusted log root of domainID, or nil if none
+	// has been saved.
+	LoadRoot(ctx context.Context, domainID string) (*trillian.SignedLogRoot, error)
+	// SaveRoot atomically replaces the trusted log root of domainID.
+	SaveRoot(ctx context.Context, domainID string, root *trillian.SignedLogRoot) error
+}
+
+// UseTrustedRootStore makes the client trust the log root saved in store
+// if it is newer than the root the client trusts, and save every newer log
+// root it verifies to store.
+func (c *Client) UseTrustedRootStore(ctx context.Context, store TrustedRootStore) error {
+	root, err := store.LoadRoot(ctx, c.domainID)
+	if err != nil {
+		return fmt.Errorf("loading trusted log root: %w", err)
+	}
+	if root.GetTreeSize() > c.trusted.TreeSize {
+		c.trusted = *root
+	}
+	c.roots = store
+	return nil
+}
+
+// updateTrusted trusts root if it is newer than the trusted root. root must
+// have been verified to be consistent with the trusted root. The root is
+// saved before it is trusted, so the store never falls behind the client.
+// Cached entries of earlier map revisions are dropped.
+func (c *Client) updateTrusted(ctx context.Context, root *trillian.SignedLogRoot) error {
+	if root.GetTreeSize() <= c.trusted.TreeSize {
+		return nil
+	}
+	if c.roots != nil {
+		if err := c.roots.SaveRoot(ctx, c.domainID, root); err != nil {
+			return fmt.Errorf("saving trusted log root: %w", err)
+		}
+	}
+	c.trusted = *root
+	if c.cache != nil {
+		c.cache.invalidate(c.domainID, root.GetTreeSize()-1)
+	}
+	return nil
+}
+
+// FileRootStore keeps the trusted log root of each domain in a file in a
+// directory.
+type FileRootStore struct {
+	Dir string
+}
+
+func (s FileRootStore) name(domainID string) string {
+	return url.PathEscape(domainID) + ".root"
+}
+
+// LoadRoot implements TrustedRootStore.
+func (s FileRootStore) LoadRoot(_ context.Context, domainID string) (*trillian.SignedLogRoot, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, s.name(domainID)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	root := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(b, root); err != nil {
+		return nil, fmt.Errorf("reading trusted log root for %v: %w", domainID, err)
+	}
+	return root, nil
+}
+
+// SaveRoot implements TrustedRootStore.
+func (s FileRootStore) SaveRoot(_ context.Context, domainID string, root *trillian.SignedLogRoot) error {
+	b, err := proto.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Dir, s.name(domainID), b)
+}
+
+// writeFileAtomic writes b to a temporary file in dir that is renamed to
+// name, so a crash never leaves a partial file behind.
+func writeFileAtomic(dir, name string, b []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+// Keychain is the secret store of the platform, such as the macOS Keychain
+// or the Android Keystore, as exposed by the application.
+type Keychain interface {
+	// Get returns the item stored for service and account, or nil if
+	// there is none.
+	Get(service, account string) ([]byte, error)
+	// Set replaces the item stored for service and account.
+	Set(service, account string, item []byte) error
+}
+
+// KeychainRootStore keeps the trusted log root of each domain as an item of
+// a Keychain. Items are stored under Service with the domain as the account.
+type KeychainRootStore struct {
+	Keychain Keychain
+	Service  string
+}
+
+// LoadRoot implements TrustedRootStore.
+func (s KeychainRootStore) LoadRoot(_ context.Context, domainID string) (*trillian.SignedLogRoot, error) {
+	b, err := s.Keychain.Get(s.Service, domainID)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	root := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(b, root); err != nil {
+		return nil, fmt.Errorf("reading trusted log root for %v: %w", domainID, err)
+	}
+	return root, nil
+}
+
+// SaveRoot implements TrustedRootStore.
+func (s KeychainRootStore) SaveRoot(_ context.Context, domainID string, root *trillian.SignedLogRoot) error {
+	b, err := proto.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return s.Keychain.Set(s.Service, domainID, b)
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+)
+
+// mapKeychain is a Keychain held in memory.
+type mapKeychain map[string][]byte
+
+func (k mapKeychain) Get(service, account string) ([]byte, error) {
+	return k[service+"/"+account], nil
+}
+
+func (k mapKeychain) Set(service, account string, item []byte) error {
+	k[service+"/"+account] = item
+	return nil
+}
+
+// failingRootStore fails to save roots.
+type failingRootStore struct {
+	TrustedRootStore
+}
+
+func (failingRootStore) SaveRoot(context.Context, string, *trillian.SignedLogRoot) error {
+	return errors.New("disk full")
+}
+
+func TestTrustedRootStores(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rootstore")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		desc  string
+		store TrustedRootStore
+	}{
+		{desc: "file", store: FileRootStore{Dir: dir}},
+		{desc: "keychain", store: KeychainRootStore{Keychain: mapKeychain{}, Service: "kt"}},
+	} {
+		if root, err := tc.store.LoadRoot(ctx, "a/b"); err != nil || root != nil {
+			t.Errorf("%v: LoadRoot(unsaved): %v, %v, want nil", tc.desc, root, err)
+		}
+		for _, size := range []int64{5, 3} {
+			want := &trillian.SignedLogRoot{TreeSize: size, RootHash: []byte("root")}
+			if err := tc.store.SaveRoot(ctx, "a/b", want); err != nil {
+				t.Fatalf("%v: SaveRoot(): %v", tc.desc, err)
+			}
+			got, err := tc.store.LoadRoot(ctx, "a/b")
+			if err != nil {
+				t.Fatalf("%v: LoadRoot(): %v", tc.desc, err)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("%v: LoadRoot(): %v, want %v", tc.desc, got, want)
+			}
+		}
+	}
+}
+
+func TestUpdateTrusted(t *testing.T) {
+	ctx := context.Background()
+	store := KeychainRootStore{Keychain: mapKeychain{}, Service: "kt"}
+	if err := store.SaveRoot(ctx, "domain", &trillian.SignedLogRoot{TreeSize: 5}); err != nil {
+		t.Fatalf("SaveRoot(): %v", err)
+	}
+
+	c := &Client{domainID: "domain", trusted: trillian.SignedLogRoot{TreeSize: 3}}
+	if err := c.UseTrustedRootStore(ctx, store); err != nil {
+		t.Fatalf("UseTrustedRootStore(): %v", err)
+	}
+	if got, want := c.trusted.TreeSize, int64(5); got != want {
+		t.Errorf("UseTrustedRootStore(): trusted size %v, want %v", got, want)
+	}
+
+	for _, tc := range []struct {
+		size int64
+		want int64
+	}{
+		{size: 4, want: 5},
+		{size: 8, want: 8},
+	} {
+		if err := c.updateTrusted(ctx, &trillian.SignedLogRoot{TreeSize: tc.size}); err != nil {
+			t.Fatalf("updateTrusted(%v): %v", tc.size, err)
+		}
+		saved, err := store.LoadRoot(ctx, "domain")
+		if err != nil {
+			t.Fatalf("LoadRoot(): %v", err)
+		}
+		if c.trusted.TreeSize != tc.want || saved.GetTreeSize() != tc.want {
+			t.Errorf("updateTrusted(%v): trusted size %v, saved size %v, want %v",
+				tc.size, c.trusted.TreeSize, saved.GetTreeSize(), tc.want)
+		}
+	}
+
+	c.roots = failingRootStore{}
+	if err := c.updateTrusted(ctx, &trillian.SignedLogRoot{TreeSize: 10}); err == nil {
+		t.Errorf("updateTrusted(unsaved): nil, want error")
+	}
+	if got, want := c.trusted.TreeSize, int64(8); got != want {
+		t.Errorf("updateTrusted(unsaved): trusted size %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/client/profile"
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// GetTypedEntry returns the profile of userID in appID parsed as the message
+// type registered for appID in c.Profiles. The profile is only parsed after
+// the entry has been verified. A nil message is returned if there is no
+// entry, and profile.ErrUnknownApp if appID has no registered type.
+func (c *Client) GetTypedEntry(ctx context.Context, userID, appID string, opts ...grpc.CallOption) (proto.Message, *trillian.SignedMapRoot, error) {
+	if _, err := c.Profiles.New(appID); err != nil {
+		return nil, nil, err
+	}
+	data, smr, err := c.GetEntry(ctx, userID, appID, opts...)
+	if err != nil || data == nil {
+		return nil, smr, err
+	}
+	m, err := c.Profiles.Unmarshal(appID, data)
+	if err != nil {
+		return nil, smr, err
+	}
+	return m, smr, nil
+}
+
+// UpdateTyped validates and serializes p with the type registered for appID
+// in c.Profiles, and submits it like Update.
+func (c *Client) UpdateTyped(ctx context.Context, appID, userID string, p proto.Message,
+	signers []signatures.Signer, authorizedKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	data, err := c.Profiles.Marshal(appID, p)
+	if err != nil {
+		return nil, err
+	}
+	return c.Update(ctx, appID, userID, data, signers, authorizedKeys, opts...)
+}
+
+// GetProfile returns the verified profile of userID in appID as a
+// pb.KeyProfile. An empty profile is returned if there is no entry.
+func (c *Client) GetProfile(ctx context.Context, userID, appID string, opts ...grpc.CallOption) (*pb.KeyProfile, *trillian.SignedMapRoot, error) {
+	data, smr, err := c.GetEntry(ctx, userID, appID, opts...)
+	if err != nil {
+		return nil, smr, err
+	}
+	p := &pb.KeyProfile{}
+	if err := proto.Unmarshal(data, p); err != nil {
+		return nil, smr, err
+	}
+	if err := profile.ValidateKeyProfile(p); err != nil {
+		return nil, smr, err
+	}
+	return p, smr, nil
+}
+
+// UpdateProfile reads the verified profile of userID in appID, applies edit
+// to a copy of it, and submits the result like Update. Helpers such as
+// profile.AddKey and profile.RemoveKey can be used in edit. With
+// CompareAndSwap, a *ConflictError carries the profile written by another
+// device, which can be combined with this device's edit by profile.Merge.
+func (c *Client) UpdateProfile(ctx context.Context, appID, userID string, edit func(*pb.KeyProfile) (*pb.KeyProfile, error),
+	signers []signatures.Signer, authorizedKeys []*keyspb.PublicKey,
+	opts ...grpc.CallOption) (*UpdateResult, error) {
+	current, _, err := c.GetProfile(ctx, userID, appID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p, err := edit(proto.Clone(current).(*pb.KeyProfile))
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.ValidateKeyProfile(p); err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return c.Update(ctx, appID, userID, data, signers, authorizedKeys, opts...)
+}
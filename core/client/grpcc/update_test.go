@@ -0,0 +1,75 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/keytransparency/core/mutator/entry"
+)
+
+func TestResubmit(t *testing.T) {
+	errOther := errors.New("other")
+	for _, tc := range []struct {
+		desc        string
+		retryCount  int
+		maxRebases  int
+		errs        []error // Returned by successive sends.
+		wantSends   int
+		wantRetries int
+		wantRebases int
+		wantErr     error
+	}{
+		{desc: "visible", errs: []error{nil}, wantSends: 1},
+		{desc: "retried", retryCount: 3, errs: []error{ErrRetry, ErrRetry, nil}, wantSends: 3, wantRetries: 2},
+		{desc: "out of retries", retryCount: 1, errs: []error{ErrRetry, ErrRetry, nil}, wantSends: 2, wantRetries: 1, wantErr: ErrRetry},
+		{desc: "rebased", retryCount: 1, maxRebases: 2, errs: []error{ErrPreviousChanged, ErrRetry, ErrPreviousChanged, nil}, wantSends: 4, wantRetries: 1, wantRebases: 2},
+		{desc: "out of rebases", maxRebases: 1, errs: []error{ErrPreviousChanged, ErrPreviousChanged, nil}, wantSends: 2, wantRebases: 1, wantErr: ErrPreviousChanged},
+		{desc: "rebased without MaxRebases", errs: []error{ErrPreviousChanged, nil}, wantSends: 1, wantErr: ErrPreviousChanged},
+		{desc: "failed", retryCount: 3, errs: []error{errOther}, wantSends: 1, wantErr: errOther},
+	} {
+		c := &Client{RetryCount: tc.retryCount, MaxRebases: tc.maxRebases}
+		m := &entry.Mutation{}
+		sends := 0
+		result, err := c.resubmit(context.Background(), m, func() (*UpdateResult, error) {
+			err := tc.errs[sends]
+			sends++
+			if err == errOther {
+				return nil, err
+			}
+			return &UpdateResult{Mutation: m, Revision: int64(sends)}, err
+		})
+		if err != tc.wantErr {
+			t.Errorf("%v: resubmit(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+		if result == nil || result.Mutation != m {
+			t.Errorf("%v: resubmit(): %+v, want a result for the mutation", tc.desc, result)
+			continue
+		}
+		if sends != tc.wantSends {
+			t.Errorf("%v: sent %v times, want %v", tc.desc, sends, tc.wantSends)
+		}
+		if result.Retries != tc.wantRetries || result.Rebases != tc.wantRebases {
+			t.Errorf("%v: resubmit(): %v retries and %v rebases, want %v and %v",
+				tc.desc, result.Retries, result.Rebases, tc.wantRetries, tc.wantRebases)
+		}
+		// The result describes the last map root the server responded with.
+		if err != errOther && result.Revision != int64(sends) {
+			t.Errorf("%v: Revision: %v, want %v", tc.desc, result.Revision, sends)
+		}
+	}
+}
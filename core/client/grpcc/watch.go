@@ -0,0 +1,91 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// EntryChange is a verified change of a watched entry.
+type EntryChange struct {
+	// Profile is the profile of the entry after the change. It is nil if the
+	// entry was purged.
+	Profile []byte
+	// Purged is true if the entry was purged.
+	Purged bool
+	// SMR is the map root of the epoch that published the change.
+	SMR *trillian.SignedMapRoot
+}
+
+// WatchEntry calls f with each change of the entry of userID in appID that is
+// published from epoch start on, or after the call if start is 0. Each change
+// is verified, and the trusted log root advanced, before f is called.
+// WatchEntry returns when ctx is done, the server ends the stream, or f
+// returns an error.
+func (c *Client) WatchEntry(ctx context.Context, userID, appID string, start int64, f func(*EntryChange) error, opts ...grpc.CallOption) error {
+	bw := bandwidthFrom(ctx)
+	stream, err := c.cli.WatchEntry(ctx, &pb.WatchEntryRequest{
+		DomainId:      c.domainID,
+		AppId:         appID,
+		UserId:        userID,
+		FirstTreeSize: c.trusted.TreeSize,
+		StartEpoch:    start,
+	}, bw.callOpts(opts)...)
+	if err != nil {
+		return err
+	}
+
+	last := start - 1
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err := bw.account(e, err); err != nil {
+			return err
+		}
+		root, err := c.kt.VerifyGetEntryResponse(ctx, c.domainID, appID, userID, &c.trusted, e)
+		if err != nil {
+			return err
+		}
+		if err := c.checkRoots(ctx, e); err != nil {
+			return err
+		}
+		if err := c.updateTrusted(ctx, root); err != nil {
+			return err
+		}
+		// Changes are reported once, in the order they were published.
+		revision := e.GetSmr().GetMapRevision()
+		if revision <= last {
+			return fmt.Errorf("change at revision %v, want > %v", revision, last)
+		}
+		last = revision
+
+		change := &EntryChange{SMR: e.GetSmr(), Purged: e.GetCommittedPurged()}
+		if !change.Purged {
+			change.Profile = e.GetCommitted().GetData()
+		}
+		if err := f(change); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// VerifyArchive verifies archive, the exported history of an entry, under the
+// keys of v without contacting the server. Consecutive epochs with the same log
+// root were read in one page of the history, and the log root of every page
+// must be consistent with the log root of the page before it, so that the
+// archive shows a single view of the log. The log root of the first page is
+// trusted on first use. Mutations are verified against the map root of the
+// preceding epoch when that epoch is also present in the archive.
+//
+// It returns the log root of the last page, which callers should check for
+// consistency with the log roots they trust.
+func (v *Verifier) VerifyArchive(ctx context.Context, domainID string, archive *pb.UserProofArchive) (*trillian.SignedLogRoot, error) {
+	appID, userID := archive.GetAppId(), archive.GetUserId()
+	// root is the log root of the current page, which the log root of the
+	// next page must be consistent with.
+	root := &trillian.SignedLogRoot{}
+	var h *HistoryVerifier
+	var prev *trillian.SignedMapRoot
+	for _, e := range archive.GetEpochs() {
+		entry := e.GetEntry()
+		epoch := entry.GetSmr().GetMapRevision()
+		if prev != nil && epoch <= prev.GetMapRevision() {
+			return nil, verificationError("VerifyArchive",
+				fmt.Errorf("epoch %v follows epoch %v", epoch, prev.GetMapRevision()))
+		}
+		if h == nil || !h.verifiedLogRoot(entry) {
+			// The first epoch of a new page.
+			h = v.NewHistoryVerifier(domainID, appID, userID, root)
+		}
+		if err := h.Verify(ctx, entry); err != nil {
+			return nil, fmt.Errorf("epoch %v: %w", epoch, err)
+		}
+		root = entry.GetLogRoot()
+		if prev != nil && prev.GetMapRevision() == epoch-1 {
+			index, err := v.Index(entry.GetVrfProof(), domainID, appID, userID)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range e.GetMutations() {
+				if err := v.VerifyMutationProof(index, prev, m); err != nil {
+					return nil, fmt.Errorf("epoch %v: mutation: %w", epoch, err)
+				}
+			}
+		}
+		prev = entry.GetSmr()
+	}
+	return root, nil
+}
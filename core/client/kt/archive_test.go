@@ -0,0 +1,135 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/fake"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/pem"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// chainLogVerifier records the tree sizes of the log roots it verifies, and
+// rejects log roots that are smaller than the trusted root.
+type chainLogVerifier struct {
+	client.LogVerifier
+	sizes [][2]int64
+}
+
+func (c *chainLogVerifier) VerifyRoot(trusted, newRoot *trillian.SignedLogRoot, consistency [][]byte) error {
+	c.sizes = append(c.sizes, [2]int64{trusted.GetTreeSize(), newRoot.GetTreeSize()})
+	if newRoot.GetTreeSize() < trusted.GetTreeSize() {
+		return fmt.Errorf("log root of size %v is older than the trusted root of size %v",
+			newRoot.GetTreeSize(), trusted.GetTreeSize())
+	}
+	return nil
+}
+
+// testArchive returns a verifier and an archive of the entry of user0 in
+// epochs 1 to 4, read in pages with log roots of tree sizes 5, 5, 7 and 7.
+func testArchive(t *testing.T) (*Verifier, *chainLogVerifier, *pb.UserProofArchive) {
+	signer, err := pem.UnmarshalPrivateKey(testPrivKey1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapPub, err := pem.UnmarshalPublicKey(testPubKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newTestMap(t, []string{"user0"}, 10)
+	logVerifier := &chainLogVerifier{LogVerifier: fake.NewFakeTrillianLogVerifier()}
+	v, err := New(Options{
+		VRF:         m.vrfPub,
+		MapPubKey:   mapPub,
+		LogVerifier: logVerifier,
+	})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	archive := &pb.UserProofArchive{AppId: "app", UserId: "user0"}
+	for i, size := range []int64{5, 5, 7, 7} {
+		smr := sign(signer, &trillian.SignedMapRoot{
+			RootHash:    m.root,
+			MapId:       testMapID,
+			MapRevision: int64(i + 1),
+		})
+		e := m.response("user0", smr)
+		e.LogRoot = &trillian.SignedLogRoot{TreeSize: size, RootHash: []byte(fmt.Sprint(size))}
+		e.LogConsistency = [][]byte{[]byte("proof")}
+		archive.Epochs = append(archive.Epochs, &pb.EpochProof{Entry: e})
+	}
+	return v, logVerifier, archive
+}
+
+func TestVerifyArchive(t *testing.T) {
+	ctx := context.Background()
+	v, logVerifier, archive := testArchive(t)
+	root, err := v.VerifyArchive(ctx, domainID, archive)
+	if err != nil {
+		t.Fatalf("VerifyArchive(): %v", err)
+	}
+	if got, want := root.GetTreeSize(), int64(7); got != want {
+		t.Errorf("VerifyArchive(): log root of size %v, want %v", got, want)
+	}
+	// Each page is verified against the log root of the page before it.
+	if got, want := logVerifier.sizes, [][2]int64{{0, 5}, {5, 7}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyArchive(): verified log roots %v, want %v", got, want)
+	}
+}
+
+func TestVerifyArchiveTampered(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc   string
+		tamper func(a *pb.UserProofArchive)
+	}{
+		{desc: "leaf", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[2].Entry.LeafProof.Leaf.LeafValue = []byte("tampered")
+		}},
+		{desc: "user", tamper: func(a *pb.UserProofArchive) {
+			a.UserId = "mallory"
+		}},
+		{desc: "map root signature", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[1].Entry.Smr.RootHash = []byte("tampered")
+		}},
+		{desc: "older log root", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[2].Entry.LogRoot = &trillian.SignedLogRoot{TreeSize: 4, RootHash: []byte("4")}
+		}},
+		{desc: "epochs out of order", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[1], a.Epochs[2] = a.Epochs[2], a.Epochs[1]
+		}},
+		{desc: "repeated epoch", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[1] = proto.Clone(a.Epochs[0]).(*pb.EpochProof)
+		}},
+		{desc: "foreign mutation", tamper: func(a *pb.UserProofArchive) {
+			a.Epochs[1].Mutations = []*pb.MutationProof{{Mutation: &pb.Entry{Index: []byte("other")}}}
+		}},
+	} {
+		v, _, archive := testArchive(t)
+		tc.tamper(archive)
+		if _, err := v.VerifyArchive(ctx, domainID, archive); err == nil {
+			t.Errorf("%v: VerifyArchive(): nil, want error", tc.desc)
+		}
+	}
+}
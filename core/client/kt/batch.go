@@ -0,0 +1,195 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/hashers"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// BatchEntry is the response to a lookup of one user's entry.
+type BatchEntry struct {
+	AppID    string
+	UserID   string
+	Response *pb.GetEntryResponse
+}
+
+// VerifyBatch verifies each of responses like VerifyGetEntryResponse.
+//
+// Responses that share a map root share work: the map root signature and the
+// log proofs are verified once per distinct root, and interior nodes of the
+// map inclusion proofs that are common to several entries, i.e. the nodes
+// above the longest common prefix of their indexes, are hashed once.
+//
+// The first failure is returned along with the position of its entry.
+func (v *Verifier) VerifyBatch(ctx context.Context, domainID string,
+	trusted *trillian.SignedLogRoot, responses []*BatchEntry) error {
+	caches := make(map[string]*cachingHasher)
+	var verified []*pb.GetEntryResponse
+	for i, e := range responses {
+		in := e.Response
+		if err := v.verifyLeaf(ctx, cachedHasher(caches, v.hasher, in), domainID, e.AppID, e.UserID, in, nil); err != nil {
+			return verificationError(fmt.Sprintf("VerifyBatch: entry %v (%v/%v)", i, e.AppID, e.UserID), err)
+		}
+		if findRoots(verified, in) >= 0 {
+			continue
+		}
+		if err := v.verifyRoots(ctx, trusted, in, nil); err != nil {
+			return verificationError(fmt.Sprintf("VerifyBatch: entry %v (%v/%v)", i, e.AppID, e.UserID), err)
+		}
+		verified = append(verified, in)
+	}
+	return nil
+}
+
+// VerifyBatchParallel verifies each of responses like VerifyBatch, but does
+// not stop at the first failure. The roots of responses are verified once
+// per distinct root, and the leaves of responses by up to workers
+// goroutines. The error of each entry is returned at its position, and is
+// nil if the entry verified.
+func (v *Verifier) VerifyBatchParallel(ctx context.Context, domainID string,
+	trusted *trillian.SignedLogRoot, responses []*BatchEntry, workers int) []error {
+	errs := make([]error, len(responses))
+	var verified []*pb.GetEntryResponse
+	var rootErrs []error
+	for i, e := range responses {
+		j := findRoots(verified, e.Response)
+		if j < 0 {
+			verified = append(verified, e.Response)
+			rootErrs = append(rootErrs, v.verifyRoots(ctx, trusted, e.Response, nil))
+			j = len(verified) - 1
+		}
+		if rootErrs[j] != nil {
+			errs[i] = verificationError(fmt.Sprintf("VerifyBatchParallel: entry %v (%v/%v)", i, e.AppID, e.UserID), rootErrs[j])
+		}
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker caches the interior nodes it hashes.
+			caches := make(map[string]*cachingHasher)
+			for i := range jobs {
+				e := responses[i]
+				h := cachedHasher(caches, v.hasher, e.Response)
+				if err := v.verifyLeaf(ctx, h, domainID, e.AppID, e.UserID, e.Response, nil); err != nil {
+					errs[i] = verificationError(fmt.Sprintf("VerifyBatchParallel: entry %v (%v/%v)", i, e.AppID, e.UserID), err)
+				}
+			}
+		}()
+	}
+	for i := range responses {
+		if errs[i] == nil {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
+
+// cachedHasher returns the hasher in caches for the map root of in, adding
+// one if there is none.
+func cachedHasher(caches map[string]*cachingHasher, hasher hashers.MapHasher, in *pb.GetEntryResponse) *cachingHasher {
+	key := strconv.FormatInt(in.GetSmr().GetMapId(), 10) + "/" + string(in.GetSmr().GetRootHash())
+	h, ok := caches[key]
+	if !ok {
+		h = newCachingHasher(hasher)
+		caches[key] = h
+	}
+	return h
+}
+
+// findRoots returns the position of the first of verified that has the same
+// map root, log root and log proofs as in, or -1 if there is none.
+func findRoots(verified []*pb.GetEntryResponse, in *pb.GetEntryResponse) int {
+	for i, r := range verified {
+		if proto.Equal(r.GetSmr(), in.GetSmr()) &&
+			proto.Equal(r.GetLogRoot(), in.GetLogRoot()) &&
+			equalHashes(r.GetLogConsistency(), in.GetLogConsistency()) &&
+			equalHashes(r.GetLogInclusion(), in.GetLogInclusion()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// equalHashes returns true if a and b contain the same hashes.
+func equalHashes(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// cachingHasher memoizes the interior node hashes of a MapHasher. Proofs for
+// indexes with a common prefix hash the same children above the point where
+// the indexes diverge, so those hashes are only computed once.
+type cachingHasher struct {
+	hashers.MapHasher
+	children map[string][]byte
+	empty    map[string][]byte
+}
+
+func newCachingHasher(h hashers.MapHasher) *cachingHasher {
+	return &cachingHasher{
+		MapHasher: h,
+		children:  make(map[string][]byte),
+		empty:     make(map[string][]byte),
+	}
+}
+
+// HashChildren returns the cached hash of l and r, computing it if needed.
+func (c *cachingHasher) HashChildren(l, r []byte) []byte {
+	key := strconv.Itoa(len(l)) + ":" + string(l) + string(r)
+	if h, ok := c.children[key]; ok {
+		return h
+	}
+	h := c.MapHasher.HashChildren(l, r)
+	c.children[key] = h
+	return h
+}
+
+// HashEmpty returns the cached hash of the empty subtree at index and
+// height, computing it if needed.
+func (c *cachingHasher) HashEmpty(treeID int64, index []byte, height int) []byte {
+	key := fmt.Sprintf("%d/%d/%x", treeID, height, index)
+	if h, ok := c.empty[key]; ok {
+		return h
+	}
+	h := c.MapHasher.HashEmpty(treeID, index, height)
+	c.empty[key] = h
+	return h
+}
@@ -0,0 +1,286 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/google/keytransparency/core/crypto/commitments"
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/mutator/entry"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/coniks"
+	"github.com/google/trillian/storage"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+const testMapID = 1
+
+// testMap is a sparse Merkle tree holding the entries of users and random
+// other leaves.
+type testMap struct {
+	vrfPriv vrf.PrivateKey
+	vrfPub  vrf.PublicKey
+	root    []byte
+	nodes   map[string][]byte
+	leaves  map[string][]byte
+	nonces  map[string][]byte
+	proofs  map[string][]byte
+}
+
+// newTestMap returns a map containing the entries of users, which are
+// present if their position is even, and n random leaves.
+func newTestMap(tb testing.TB, users []string, n int) *testMap {
+	vrfPriv, vrfPub := p256.GenerateKey()
+	m := &testMap{
+		vrfPriv: vrfPriv,
+		vrfPub:  vrfPub,
+		nodes:   make(map[string][]byte),
+		leaves:  make(map[string][]byte),
+		nonces:  make(map[string][]byte),
+		proofs:  make(map[string][]byte),
+	}
+	var leaves []merkle.HStar2LeafHash
+	add := func(index, value []byte) {
+		nID := storage.NewNodeIDFromPrefixSuffix(index, storage.Suffix{}, coniks.Default.BitLen())
+		h, err := coniks.Default.HashLeaf(testMapID, index, value)
+		if err != nil {
+			tb.Fatalf("HashLeaf(): %v", err)
+		}
+		leaves = append(leaves, merkle.HStar2LeafHash{Index: nID.BigInt(), LeafHash: h})
+	}
+	for i, user := range users {
+		index, proof := vrfPriv.Evaluate(vrf.UniqueID(user, "app"))
+		m.proofs[user] = proof
+		if i%2 != 0 {
+			continue
+		}
+		nonce, err := commitments.GenCommitmentKey()
+		if err != nil {
+			tb.Fatalf("GenCommitmentKey(): %v", err)
+		}
+		leaf, err := entry.ToLeafValue(&pb.Entry{
+			Index:      index[:],
+			Commitment: commitments.Commit(user, "app", []byte(user), nonce),
+		})
+		if err != nil {
+			tb.Fatalf("ToLeafValue(): %v", err)
+		}
+		m.leaves[user] = leaf
+		m.nonces[user] = nonce
+		add(index[:], leaf)
+	}
+	for i := 0; i < n; i++ {
+		index := make([]byte, 32)
+		if _, err := rand.Read(index); err != nil {
+			tb.Fatalf("rand.Read(): %v", err)
+		}
+		add(index, index)
+	}
+
+	hs2 := merkle.NewHStar2(testMapID, coniks.Default)
+	root, err := hs2.HStar2Nodes([]byte{}, coniks.Default.BitLen(), leaves,
+		func(int, *big.Int) ([]byte, error) { return nil, nil },
+		func(depth int, index *big.Int, h []byte) error {
+			nID := storage.NewNodeIDFromBigInt(depth, index, coniks.Default.BitLen())
+			m.nodes[nID.String()] = h
+			return nil
+		})
+	if err != nil {
+		tb.Fatalf("HStar2Nodes(): %v", err)
+	}
+	m.root = root
+	return m
+}
+
+// response returns the GetEntryResponse for user at root smr.
+func (m *testMap) response(user string, smr *trillian.SignedMapRoot) *pb.GetEntryResponse {
+	index, _ := m.vrfPriv.Evaluate(vrf.UniqueID(user, "app"))
+	nID := storage.NewNodeIDFromPrefixSuffix(index[:], storage.Suffix{}, coniks.Default.BitLen())
+	var inclusion [][]byte
+	for _, sib := range nID.Siblings() {
+		inclusion = append(inclusion, m.nodes[sib.String()])
+	}
+	resp := &pb.GetEntryResponse{
+		VrfProof: m.proofs[user],
+		LeafProof: &trillian.MapLeafInclusion{
+			Leaf:      &trillian.MapLeaf{Index: index[:], LeafValue: m.leaves[user]},
+			Inclusion: inclusion,
+		},
+		Smr:     smr,
+		LogRoot: &trillian.SignedLogRoot{TreeSize: smr.GetMapRevision() + 1},
+	}
+	if m.leaves[user] != nil {
+		resp.Committed = &pb.Committed{Key: m.nonces[user], Data: []byte(user)}
+	}
+	return resp
+}
+
+// batch returns the entries of users in a map with n other leaves.
+func batch(tb testing.TB, users []string, n int) (*Verifier, []*BatchEntry) {
+	signer, err := pem.UnmarshalPrivateKey(testPrivKey1, "")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	mapPub, err := pem.UnmarshalPublicKey(testPubKey1)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	m := newTestMap(tb, users, n)
+	v, err := New(Options{
+		VRF:         m.vrfPub,
+		MapPubKey:   mapPub,
+		LogVerifier: fake.NewFakeTrillianLogVerifier(),
+	})
+	if err != nil {
+		tb.Fatalf("New(): %v", err)
+	}
+	smr := sign(signer, &trillian.SignedMapRoot{
+		RootHash:    m.root,
+		MapId:       testMapID,
+		MapRevision: 1,
+	})
+	entries := make([]*BatchEntry, 0, len(users))
+	for _, user := range users {
+		entries = append(entries, &BatchEntry{AppID: "app", UserID: user, Response: m.response(user, smr)})
+	}
+	return v, entries
+}
+
+func users(n int) []string {
+	u := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		u = append(u, fmt.Sprintf("user%d", i))
+	}
+	return u
+}
+
+func TestVerifyBatch(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		desc    string
+		edit    func(entries []*BatchEntry)
+		wantErr bool
+	}{
+		{desc: "valid", edit: func([]*BatchEntry) {}},
+		{desc: "wrong leaf", wantErr: true, edit: func(e []*BatchEntry) {
+			e[3].Response.LeafProof.Leaf.LeafValue = e[2].Response.LeafProof.Leaf.LeafValue
+		}},
+		{desc: "wrong proof", wantErr: true, edit: func(e []*BatchEntry) {
+			inclusion := e[4].Response.LeafProof.Inclusion
+			for i, h := range inclusion {
+				if h != nil {
+					inclusion[i] = append([]byte{}, h...)
+					inclusion[i][0] ^= 1
+					break
+				}
+			}
+		}},
+		{desc: "wrong user", wantErr: true, edit: func(e []*BatchEntry) {
+			e[5].UserID = "mallory"
+		}},
+	} {
+		v, entries := batch(t, users(8), 100)
+		tc.edit(entries)
+		err := v.VerifyBatch(ctx, domainID, &trillian.SignedLogRoot{}, entries)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: VerifyBatch(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		// VerifyBatch must agree with VerifyGetEntryResponse.
+		var single error
+		for _, e := range entries {
+			if _, single = v.VerifyGetEntryResponse(ctx, domainID, e.AppID, e.UserID, &trillian.SignedLogRoot{}, e.Response); single != nil {
+				break
+			}
+		}
+		if got, want := err != nil, single != nil; got != want {
+			t.Errorf("%v: VerifyBatch(): %v, VerifyGetEntryResponse(): %v", tc.desc, err, single)
+		}
+	}
+}
+
+func TestVerifyBatchParallel(t *testing.T) {
+	ctx := context.Background()
+	v, entries := batch(t, users(8), 100)
+	entries[3].Response.LeafProof.Leaf.LeafValue = entries[2].Response.LeafProof.Leaf.LeafValue
+	entries[5].UserID = "mallory"
+	for _, workers := range []int{0, 1, 4} {
+		errs := v.VerifyBatchParallel(ctx, domainID, &trillian.SignedLogRoot{}, entries, workers)
+		if got, want := len(errs), len(entries); got != want {
+			t.Fatalf("VerifyBatchParallel(%v): %v errors, want %v", workers, got, want)
+		}
+		for i, err := range errs {
+			if got, want := err != nil, i == 3 || i == 5; got != want {
+				t.Errorf("VerifyBatchParallel(%v): entry %v: %v, wantErr %v", workers, i, err, want)
+			}
+		}
+	}
+}
+
+func TestCachingHasher(t *testing.T) {
+	h := newCachingHasher(coniks.Default)
+	l, r := []byte("left"), []byte("right")
+	index := make([]byte, coniks.Default.Size())
+	for i := 0; i < 2; i++ {
+		if got, want := h.HashChildren(l, r), coniks.Default.HashChildren(l, r); string(got) != string(want) {
+			t.Errorf("HashChildren(): %x, want %x", got, want)
+		}
+		if got, want := h.HashChildren(nil, append(l, r...)), coniks.Default.HashChildren(nil, append(l, r...)); string(got) != string(want) {
+			t.Errorf("HashChildren(nil, _): %x, want %x", got, want)
+		}
+		if got, want := h.HashEmpty(testMapID, index, 3), coniks.Default.HashEmpty(testMapID, index, 3); string(got) != string(want) {
+			t.Errorf("HashEmpty(): %x, want %x", got, want)
+		}
+	}
+	if got, want := len(h.children), 2; got != want {
+		t.Errorf("len(children): %v, want %v", got, want)
+	}
+}
+
+func BenchmarkVerifyEntries(b *testing.B) {
+	ctx := context.Background()
+	for _, size := range []int{8, 64} {
+		v, entries := batch(b, users(size), 10000)
+		trusted := &trillian.SignedLogRoot{}
+		b.Run(fmt.Sprintf("single/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, e := range entries {
+					if _, err := v.VerifyGetEntryResponse(ctx, domainID, e.AppID, e.UserID, trusted, e.Response); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batch/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := v.VerifyBatch(ctx, domainID, trusted, entries); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
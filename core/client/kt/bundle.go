@@ -0,0 +1,204 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/crypto/vrf/ed25519"
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// vrfVerifier parses a VRF public key of the algorithm declared by the domain.
+func vrfVerifier(alg pb.VrfAlgorithm, k *keyspb.PublicKey) (vrf.PublicKey, error) {
+	switch alg {
+	case pb.VrfAlgorithm_P256:
+		return p256.NewVRFVerifierFromRawKey(k.GetDer())
+	case pb.VrfAlgorithm_ED25519:
+		return ed25519.NewVRFVerifierFromRawKey(k.GetDer())
+	default:
+		return nil, fmt.Errorf("unsupported VRF algorithm %v", alg)
+	}
+}
+
+// OptionsFromDomain returns the options of a Verifier of the responses of
+// the domain described by config, whose hash strategies must be allowed by
+// DefaultHashPolicy.
+func OptionsFromDomain(config *pb.Domain) (Options, error) {
+	return OptionsFromDomainPolicy(config, DefaultHashPolicy)
+}
+
+// OptionsFromDomainPolicy is like OptionsFromDomain, but accepts the hash
+// strategies allowed by policy.
+func OptionsFromDomainPolicy(config *pb.Domain, policy HashPolicy) (Options, error) {
+	// Log Hasher.
+	logHasher, err := policy.logHasher(config.GetLog().GetHashStrategy())
+	if err != nil {
+		return Options{}, fmt.Errorf("Failed creating LogHasher: %w", err)
+	}
+
+	// Log Key
+	logPubKey, err := der.UnmarshalPublicKey(config.GetLog().GetPublicKey().GetDer())
+	if err != nil {
+		return Options{}, fmt.Errorf("Failed parsing Log public key: %w", err)
+	}
+
+	// Map Hasher
+	mapHasher, err := policy.mapHasher(config.GetMap().GetHashStrategy())
+	if err != nil {
+		return Options{}, fmt.Errorf("Failed creating MapHasher: %w", err)
+	}
+
+	// Map Key
+	mapPubKey, err := der.UnmarshalPublicKey(config.GetMap().GetPublicKey().GetDer())
+	if err != nil {
+		return Options{}, fmt.Errorf("Failed parsing Map public key: %w", err)
+	}
+
+	// VRF key
+	vrfPubKey, err := vrfVerifier(config.GetVrfAlgorithm(), config.GetVrf())
+	if err != nil {
+		return Options{}, fmt.Errorf("Error parsing vrf public key: %w", err)
+	}
+
+	// App scoped VRF keys.
+	appVRFs := make(map[string]vrf.PublicKey, len(config.GetAppVrfs()))
+	for appID, k := range config.GetAppVrfs() {
+		appVRF, err := vrfVerifier(config.GetVrfAlgorithm(), k)
+		if err != nil {
+			return Options{}, fmt.Errorf("Error parsing vrf public key for app %v: %w", appID, err)
+		}
+		appVRFs[appID] = appVRF
+	}
+
+	opts := Options{
+		VRF:         vrfPubKey,
+		AppVRFs:     appVRFs,
+		MapHasher:   mapHasher,
+		MapPubKey:   mapPubKey,
+		LogVerifier: client.NewLogVerifier(logHasher, logPubKey),
+
+		EndorsementPolicy: config.GetEndorsementPolicy(),
+	}
+
+	// Previous VRF key, published while a VRF rotation is in progress.
+	if prev := config.GetPreviousVrf(); prev != nil {
+		prevVRF, err := vrfVerifier(config.GetVrfAlgorithm(), prev)
+		if err != nil {
+			return Options{}, fmt.Errorf("Error parsing previous vrf public key: %w", err)
+		}
+		expiry, err := ptypes.Timestamp(config.GetPreviousVrfExpiry())
+		if err != nil {
+			return Options{}, fmt.Errorf("Error parsing previous vrf expiry: %w", err)
+		}
+		opts.PreviousVRF = prevVRF
+		opts.PreviousVRFExpiry = expiry
+	}
+
+	// Previous tree signing keys, accepted until the end of their overlap.
+	// Rotations are trusted because config is: they are covered by the
+	// fingerprint of a pinned domain and by the signature of a signed one.
+	if r := config.GetMapKeyRotation(); r.GetPreviousKey() != nil {
+		expiry, err := rotationExpiry(config.GetMap(), r)
+		if err != nil {
+			return Options{}, fmt.Errorf("Map key rotation: %w", err)
+		}
+		prevMapPubKey, err := der.UnmarshalPublicKey(r.GetPreviousKey().GetDer())
+		if err != nil {
+			return Options{}, fmt.Errorf("Failed parsing previous Map public key: %w", err)
+		}
+		opts.PreviousMapPubKey = prevMapPubKey
+		opts.PreviousMapKeyExpiry = expiry
+	}
+	if r := config.GetLogKeyRotation(); r.GetPreviousKey() != nil {
+		expiry, err := rotationExpiry(config.GetLog(), r)
+		if err != nil {
+			return Options{}, fmt.Errorf("Log key rotation: %w", err)
+		}
+		prevLogPubKey, err := der.UnmarshalPublicKey(r.GetPreviousKey().GetDer())
+		if err != nil {
+			return Options{}, fmt.Errorf("Failed parsing previous Log public key: %w", err)
+		}
+		opts.PreviousLogVerifier = client.NewLogVerifier(logHasher, prevLogPubKey)
+		opts.PreviousLogKeyExpiry = expiry
+	}
+
+	return opts, nil
+}
+
+// VerifyBundle verifies a ProofBundle without contacting the server. The
+// entry must verify under the keys of config, the domain the caller trusts,
+// rather than those of the bundled domain, and its log root must be consistent
+// with the bundled trusted root, if any.
+func VerifyBundle(ctx context.Context, config *pb.Domain, b *pb.ProofBundle) error {
+	if got, want := b.GetDomain().GetDomainId(), config.GetDomainId(); got != want {
+		return verificationError("VerifyBundle", fmt.Errorf("bundle of domain %q, want %q", got, want))
+	}
+	_, err := verifyOffline(ctx, config, b.GetTrustedRoot(), b.GetAppId(), b.GetUserId(), b.GetEntry())
+	return err
+}
+
+// VerifySerialized verifies a GetEntryResponse of userID in appID that was
+// stored in its wire format, without contacting the server. domainConfig is
+// a serialized Domain, and trustedRoot a serialized SignedLogRoot that the
+// log root of the response must be consistent with, or empty on first use.
+// It returns the log root to trust from now on, like VerifyGetEntryResponse.
+func VerifySerialized(domainConfig, trustedRoot, rawResponse []byte, appID, userID string) (*trillian.SignedLogRoot, error) {
+	config := &pb.Domain{}
+	if err := proto.Unmarshal(domainConfig, config); err != nil {
+		return nil, fmt.Errorf("parsing domain config: %w", err)
+	}
+	root := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(trustedRoot, root); err != nil {
+		return nil, fmt.Errorf("parsing trusted root: %w", err)
+	}
+	in := &pb.GetEntryResponse{}
+	if err := proto.Unmarshal(rawResponse, in); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	return verifyOffline(context.Background(), config, root, appID, userID, in)
+}
+
+// verifyOffline verifies in under the keys of config. The log root of in
+// must be consistent with trustedRoot unless trustedRoot is empty.
+func verifyOffline(ctx context.Context, config *pb.Domain, trustedRoot *trillian.SignedLogRoot,
+	appID, userID string, in *pb.GetEntryResponse) (*trillian.SignedLogRoot, error) {
+	opts, err := OptionsFromDomain(config)
+	if err != nil {
+		return nil, err
+	}
+	v, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	trusted := &trillian.SignedLogRoot{}
+	if trustedRoot.GetTreeSize() > 0 {
+		if err := v.VerifyLogRoot(trustedRoot); err != nil {
+			return nil, fmt.Errorf("trusted root: %w", err)
+		}
+		trusted = trustedRoot
+	}
+	return v.VerifyGetEntryResponse(ctx, config.GetDomainId(), appID, userID, trusted, in)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestVerifyBundleMalformed(t *testing.T) {
+	for _, tc := range []struct {
+		desc   string
+		config *pb.Domain
+		b      *pb.ProofBundle
+	}{
+		{desc: "empty", config: &pb.Domain{}, b: &pb.ProofBundle{}},
+		{desc: "bad log key", config: &pb.Domain{
+			DomainId: "domain",
+			Log: &trillian.Tree{
+				HashStrategy: trillian.HashStrategy_RFC6962_SHA256,
+				PublicKey:    &keyspb.PublicKey{Der: []byte("log")},
+			},
+		}, b: &pb.ProofBundle{Domain: &pb.Domain{DomainId: "domain"}}},
+		{desc: "unsupported VRF", config: &pb.Domain{
+			DomainId:     "domain",
+			VrfAlgorithm: pb.VrfAlgorithm(99),
+		}, b: &pb.ProofBundle{Domain: &pb.Domain{DomainId: "domain"}}},
+		{desc: "other domain", config: &pb.Domain{DomainId: "domain"},
+			b: &pb.ProofBundle{Domain: &pb.Domain{DomainId: "other"}}},
+	} {
+		if err := VerifyBundle(context.Background(), tc.config, tc.b); err == nil {
+			t.Errorf("%v: VerifyBundle(): nil, want error", tc.desc)
+		}
+	}
+	if _, err := vrfVerifier(pb.VrfAlgorithm(99), &keyspb.PublicKey{}); err == nil {
+		t.Errorf("vrfVerifier(99): nil, want error")
+	}
+}
+
+func TestVerifySerializedMalformed(t *testing.T) {
+	config, err := proto.Marshal(&pb.Domain{DomainId: "domain", VrfAlgorithm: pb.VrfAlgorithm(99)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := []byte{0xff, 0xff}
+	for _, tc := range []struct {
+		desc                      string
+		config, trusted, response []byte
+	}{
+		{desc: "bad config", config: garbage},
+		{desc: "bad trusted root", config: config, trusted: garbage},
+		{desc: "bad response", config: config, response: garbage},
+		{desc: "unsupported VRF", config: config},
+	} {
+		if _, err := VerifySerialized(tc.config, tc.trusted, tc.response, "app", "alice"); err == nil {
+			t.Errorf("%v: VerifySerialized(): nil, want error", tc.desc)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/coniks"
+	"github.com/google/trillian/merkle/hashers"
+
+	// Register the hash functions of the additional map hashers.
+	_ "golang.org/x/crypto/blake2b"
+	_ "golang.org/x/crypto/sha3"
+)
+
+// ErrHashStrategy occurs when a domain config declares a hash strategy that
+// the client has not allowed.
+var ErrHashStrategy = errors.New("hash strategy not allowed")
+
+// HashPolicy pins the hash strategies a client accepts from the domain
+// config, so that a malicious config cannot downgrade the hashing of the
+// log or the map.
+type HashPolicy struct {
+	// Log lists the accepted log hash strategies.
+	Log []trillian.HashStrategy
+	// Map lists the accepted map hash strategies.
+	Map []trillian.HashStrategy
+	// MapHasher, if set, hashes map leaves instead of the hasher of the
+	// map hash strategy of the config, which must still be in Map. It
+	// supports maps built with a hasher that Trillian has no hash strategy
+	// for, such as ConiksHasher(crypto.SHA3_256).
+	MapHasher hashers.MapHasher
+}
+
+// DefaultHashPolicy accepts the hash strategies that key servers create
+// trees with.
+var DefaultHashPolicy = HashPolicy{
+	Log: []trillian.HashStrategy{
+		trillian.HashStrategy_RFC6962_SHA256,
+		trillian.HashStrategy_OBJECT_RFC6962_SHA256,
+	},
+	Map: []trillian.HashStrategy{trillian.HashStrategy_CONIKS_SHA512_256},
+}
+
+// allowed returns ErrHashStrategy if s is not in strategies.
+func allowed(tree string, s trillian.HashStrategy, strategies []trillian.HashStrategy) error {
+	for _, a := range strategies {
+		if s == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v hash strategy %v: %w", tree, s, ErrHashStrategy)
+}
+
+// logHasher returns the log hasher of s if the policy allows it.
+func (p HashPolicy) logHasher(s trillian.HashStrategy) (hashers.LogHasher, error) {
+	if err := allowed("log", s, p.Log); err != nil {
+		return nil, err
+	}
+	return hashers.NewLogHasher(s)
+}
+
+// mapHasher returns the map hasher of s if the policy allows it.
+func (p HashPolicy) mapHasher(s trillian.HashStrategy) (hashers.MapHasher, error) {
+	if err := allowed("map", s, p.Map); err != nil {
+		return nil, err
+	}
+	if p.MapHasher != nil {
+		return p.MapHasher, nil
+	}
+	return hashers.NewMapHasher(s)
+}
+
+// ConiksHasher returns a CONIKS map hasher that uses h. SHA-256,
+// SHA-512/256, SHA3-256 and BLAKE2b-256 are supported.
+func ConiksHasher(h crypto.Hash) (hashers.MapHasher, error) {
+	switch h {
+	case crypto.SHA256, crypto.SHA512_256, crypto.SHA3_256, crypto.BLAKE2b_256:
+	default:
+		return nil, fmt.Errorf("unsupported map hash function %v", h)
+	}
+	if !h.Available() {
+		return nil, fmt.Errorf("map hash function %v is not linked into the binary", h)
+	}
+	return coniks.New(h), nil
+}
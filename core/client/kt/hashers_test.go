@@ -0,0 +1,81 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+)
+
+func TestHashPolicy(t *testing.T) {
+	sha3, err := ConiksHasher(crypto.SHA3_256)
+	if err != nil {
+		t.Fatalf("ConiksHasher(SHA3_256): %v", err)
+	}
+	pinned := HashPolicy{
+		Map:       []trillian.HashStrategy{trillian.HashStrategy_CONIKS_SHA512_256},
+		MapHasher: sha3,
+	}
+	for _, tc := range []struct {
+		desc     string
+		policy   HashPolicy
+		strategy trillian.HashStrategy
+		wantErr  error
+	}{
+		{desc: "default", policy: DefaultHashPolicy, strategy: trillian.HashStrategy_CONIKS_SHA512_256},
+		{desc: "downgrade", policy: DefaultHashPolicy, strategy: trillian.HashStrategy_TEST_MAP_HASHER, wantErr: ErrHashStrategy},
+		{desc: "unknown", policy: DefaultHashPolicy, strategy: trillian.HashStrategy_UNKNOWN_HASH_STRATEGY, wantErr: ErrHashStrategy},
+		{desc: "pinned", policy: pinned, strategy: trillian.HashStrategy_CONIKS_SHA512_256},
+		{desc: "empty policy", policy: HashPolicy{}, strategy: trillian.HashStrategy_CONIKS_SHA512_256, wantErr: ErrHashStrategy},
+	} {
+		h, err := tc.policy.mapHasher(tc.strategy)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: mapHasher(%v): %v, want %v", tc.desc, tc.strategy, err, tc.wantErr)
+		}
+		if tc.policy.MapHasher != nil && err == nil && h != tc.policy.MapHasher {
+			t.Errorf("%v: mapHasher(%v): %v, want pinned hasher", tc.desc, tc.strategy, h)
+		}
+	}
+
+	if _, err := DefaultHashPolicy.logHasher(trillian.HashStrategy_CONIKS_SHA512_256); !errors.Is(err, ErrHashStrategy) {
+		t.Errorf("logHasher(CONIKS_SHA512_256): %v, want %v", err, ErrHashStrategy)
+	}
+}
+
+func TestConiksHasher(t *testing.T) {
+	for _, tc := range []struct {
+		h       crypto.Hash
+		wantErr bool
+	}{
+		{h: crypto.SHA256},
+		{h: crypto.SHA512_256},
+		{h: crypto.SHA3_256},
+		{h: crypto.BLAKE2b_256},
+		{h: crypto.SHA1, wantErr: true},
+		{h: crypto.MD5, wantErr: true},
+	} {
+		h, err := ConiksHasher(tc.h)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("ConiksHasher(%v): %v, wantErr %v", tc.h, err, tc.wantErr)
+			continue
+		}
+		if err == nil && h.Size() != 32 {
+			t.Errorf("ConiksHasher(%v).Size(): %v, want 32", tc.h, h.Size())
+		}
+	}
+}
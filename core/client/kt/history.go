@@ -0,0 +1,164 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// HistoryVerifier verifies the responses of an entry history one by one.
+// The responses of a history prove the same log root as long as the log does
+// not grow, so the consistency of each distinct log root with the trusted
+// root is only verified once.
+type HistoryVerifier struct {
+	v        *Verifier
+	domainID string
+	appID    string
+	userID   string
+	trusted  *trillian.SignedLogRoot
+	verified []*pb.GetEntryResponse // Responses with distinct verified log roots.
+	count    int
+}
+
+// NewHistoryVerifier returns a HistoryVerifier for the history of the entry
+// of userID in appID, whose log roots must be consistent with trusted.
+func (v *Verifier) NewHistoryVerifier(domainID, appID, userID string, trusted *trillian.SignedLogRoot) *HistoryVerifier {
+	return &HistoryVerifier{
+		v:        v,
+		domainID: domainID,
+		appID:    appID,
+		userID:   userID,
+		trusted:  trusted,
+	}
+}
+
+// Verify verifies in like VerifyGetEntryResponse.
+func (h *HistoryVerifier) Verify(ctx context.Context, in *pb.GetEntryResponse) error {
+	op := fmt.Sprintf("HistoryVerifier: entry %v", h.count)
+	h.count++
+	if err := h.v.verifyLeaf(ctx, h.v.hasher, h.domainID, h.appID, h.userID, in, nil); err != nil {
+		return verificationError(op, err)
+	}
+	if err := h.v.verifyMapRoot(ctx, in); err != nil {
+		return verificationError(op, err)
+	}
+	if !h.verifiedLogRoot(in) {
+		if err := h.v.verifyLogRoot(ctx, h.trusted, in); err != nil {
+			return verificationError(op, err)
+		}
+		h.verified = append(h.verified, in)
+	}
+	return verificationError(op, h.v.verifyLogInclusion(in))
+}
+
+// verifiedLogRoot returns true if the log root of in and its consistency
+// proof have already been verified.
+func (h *HistoryVerifier) verifiedLogRoot(in *pb.GetEntryResponse) bool {
+	for _, r := range h.verified {
+		if proto.Equal(r.GetLogRoot(), in.GetLogRoot()) &&
+			equalHashes(r.GetLogConsistency(), in.GetLogConsistency()) {
+			return true
+		}
+	}
+	return false
+}
+
+// HistoryRoots returns the log roots that VerifyHistoryChain needs a
+// consistency chain for: trusted, unless it is empty, followed by the
+// distinct log roots of responses that are newer than trusted, ordered by
+// tree size. Two different log roots of the same size are an error.
+func HistoryRoots(trusted *trillian.SignedLogRoot, responses []*pb.GetEntryResponse) ([]*trillian.SignedLogRoot, error) {
+	bySize := make(map[int64]*trillian.SignedLogRoot)
+	for _, in := range responses {
+		root := in.GetLogRoot()
+		if root.GetTreeSize() <= trusted.GetTreeSize() {
+			continue
+		}
+		if r, ok := bySize[root.GetTreeSize()]; ok {
+			if !proto.Equal(r, root) {
+				return nil, fmt.Errorf("two log roots of size %v", root.GetTreeSize())
+			}
+			continue
+		}
+		bySize[root.GetTreeSize()] = root
+	}
+	var roots []*trillian.SignedLogRoot
+	if trusted.GetTreeSize() > 0 {
+		roots = append(roots, trusted)
+	}
+	for _, r := range bySize {
+		roots = append(roots, r)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].GetTreeSize() < roots[j].GetTreeSize()
+	})
+	return roots, nil
+}
+
+// VerifyHistoryChain verifies responses, a history of the entry of userID in
+// appID, as a whole. Instead of checking the consistency proof of each
+// response against trusted, it checks that the log roots of HistoryRoots
+// form a chain in which each log root is consistent with the previous one,
+// using the single consistency chain. A response whose log root is not
+// newer than trusted must have the same log root as trusted. If trusted is
+// empty, the oldest log root of responses is trusted on first use.
+//
+// It returns the root to trust from now on: the log root of chain, which is
+// consistent with every log root of responses, or trusted if no chain was
+// needed.
+func (v *Verifier) VerifyHistoryChain(ctx context.Context, domainID, appID, userID string,
+	trusted *trillian.SignedLogRoot, responses []*pb.GetEntryResponse, chain *pb.LogConsistencyChain) (*trillian.SignedLogRoot, error) {
+	roots, err := HistoryRoots(trusted, responses)
+	if err != nil {
+		return nil, verificationError("VerifyHistoryChain", err)
+	}
+	if len(roots) > 0 {
+		if trusted.GetTreeSize() == 0 {
+			if err := v.logVerifier.VerifyRoot(&trillian.SignedLogRoot{}, roots[0], nil); err != nil {
+				return nil, verificationError("VerifyHistoryChain", err)
+			}
+		}
+		if err := v.verifyConsistencyChain(ctx, roots, chain); err != nil {
+			return nil, verificationError("VerifyHistoryChain", err)
+		}
+	}
+	for i, in := range responses {
+		op := fmt.Sprintf("VerifyHistoryChain: entry %v", i)
+		if root := in.GetLogRoot(); root.GetTreeSize() <= trusted.GetTreeSize() && !proto.Equal(root, trusted) {
+			return nil, verificationError(op, fmt.Errorf("log root of size %v, want the trusted root of size %v",
+				root.GetTreeSize(), trusted.GetTreeSize()))
+		}
+		if err := v.verifyLeaf(ctx, v.hasher, domainID, appID, userID, in, nil); err != nil {
+			return nil, verificationError(op, err)
+		}
+		if err := v.verifyMapRoot(ctx, in); err != nil {
+			return nil, verificationError(op, err)
+		}
+		if err := v.verifyLogInclusion(in); err != nil {
+			return nil, verificationError(op, err)
+		}
+	}
+	if len(roots) == 0 {
+		return trusted, nil
+	}
+	return chain.GetLogRoot(), nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// countingLogVerifier counts the log roots it verifies.
+type countingLogVerifier struct {
+	client.LogVerifier
+	roots int
+}
+
+func (c *countingLogVerifier) VerifyRoot(trusted, newRoot *trillian.SignedLogRoot, consistency [][]byte) error {
+	c.roots++
+	return c.LogVerifier.VerifyRoot(trusted, newRoot, consistency)
+}
+
+func TestHistoryVerifier(t *testing.T) {
+	ctx := context.Background()
+	v, entries := batch(t, []string{"user0"}, 10)
+	logVerifier := &countingLogVerifier{LogVerifier: v.logVerifier}
+	v.logVerifier = logVerifier
+
+	var history []*pb.GetEntryResponse
+	for _, size := range []int64{5, 5, 5, 6, 6} {
+		e := proto.Clone(entries[0].Response).(*pb.GetEntryResponse)
+		e.LogRoot = &trillian.SignedLogRoot{TreeSize: size}
+		e.LogConsistency = [][]byte{[]byte("proof")}
+		history = append(history, e)
+	}
+	h := v.NewHistoryVerifier(domainID, "app", "user0", &trillian.SignedLogRoot{})
+	for i, e := range history {
+		if err := h.Verify(ctx, e); err != nil {
+			t.Fatalf("Verify(%v): %v", i, err)
+		}
+	}
+	if got, want := logVerifier.roots, 2; got != want {
+		t.Errorf("Verify(): verified %v log roots, want %v", got, want)
+	}
+
+	// Entries are still verified when their log root is not.
+	tampered := proto.Clone(history[0]).(*pb.GetEntryResponse)
+	tampered.LeafProof.Leaf.LeafValue = []byte("tampered")
+	if err := h.Verify(ctx, tampered); err == nil {
+		t.Errorf("Verify(tampered): nil, want error")
+	}
+}
+
+func TestHistoryRoots(t *testing.T) {
+	root := func(size int64, hash string) *trillian.SignedLogRoot {
+		return &trillian.SignedLogRoot{TreeSize: size, RootHash: []byte(hash)}
+	}
+	resp := func(r *trillian.SignedLogRoot) *pb.GetEntryResponse {
+		return &pb.GetEntryResponse{LogRoot: r}
+	}
+	for _, tc := range []struct {
+		desc      string
+		trusted   *trillian.SignedLogRoot
+		responses []*pb.GetEntryResponse
+		want      []int64
+		wantErr   bool
+	}{
+		{desc: "empty trusted", trusted: &trillian.SignedLogRoot{},
+			responses: []*pb.GetEntryResponse{resp(root(3, "c")), resp(root(2, "b"))}, want: []int64{2, 3}},
+		{desc: "trusted first", trusted: root(1, "a"),
+			responses: []*pb.GetEntryResponse{resp(root(3, "c")), resp(root(2, "b"))}, want: []int64{1, 2, 3}},
+		{desc: "not newer", trusted: root(2, "b"),
+			responses: []*pb.GetEntryResponse{resp(root(1, "a")), resp(root(2, "b"))}, want: []int64{2}},
+		{desc: "duplicates", trusted: root(1, "a"),
+			responses: []*pb.GetEntryResponse{resp(root(2, "b")), resp(root(2, "b"))}, want: []int64{1, 2}},
+		{desc: "same size", trusted: root(1, "a"),
+			responses: []*pb.GetEntryResponse{resp(root(2, "b")), resp(root(2, "x"))}, wantErr: true},
+	} {
+		roots, err := HistoryRoots(tc.trusted, tc.responses)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: HistoryRoots(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		var got []int64
+		for _, r := range roots {
+			got = append(got, r.GetTreeSize())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: HistoryRoots(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
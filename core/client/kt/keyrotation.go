@@ -0,0 +1,104 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// ErrKeyRotation occurs when a domain config records a rotation of a tree
+// signing key that does not lead to the current key of the tree.
+var ErrKeyRotation = errors.New("key rotation does not match tree")
+
+// rotationExpiry returns the end of the overlap window of r, the most recent
+// rotation of the signing key of tree. r must have replaced a different key
+// with the current key of tree, and its overlap must end after the rotation.
+func rotationExpiry(tree *trillian.Tree, r *pb.TreeKeyRotation) (time.Time, error) {
+	current := tree.GetPublicKey().GetDer()
+	switch {
+	case !bytes.Equal(r.GetPublicKey().GetDer(), current):
+		return time.Time{}, fmt.Errorf("%w: rotated to another key", ErrKeyRotation)
+	case bytes.Equal(r.GetPreviousKey().GetDer(), current):
+		return time.Time{}, fmt.Errorf("%w: previous key is the current key", ErrKeyRotation)
+	}
+	rotateTime, err := ptypes.Timestamp(r.GetRotateTime())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid rotate time: %w", err)
+	}
+	overlapEnd, err := ptypes.Timestamp(r.GetOverlapEnd())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid overlap end: %w", err)
+	}
+	if overlapEnd.Before(rotateTime) {
+		return time.Time{}, fmt.Errorf("%w: overlap ends before rotation", ErrKeyRotation)
+	}
+	return overlapEnd, nil
+}
+
+// SetPreviousMapKey sets the map key that was replaced by a key rotation.
+// Map roots signed with pk are accepted until expiry.
+func (v *Verifier) SetPreviousMapKey(pk crypto.PublicKey, expiry time.Time) {
+	v.prevMapPubKey = pk
+	v.prevMapExpiry = expiry
+}
+
+// verifyMapRootSignature verifies that sig is a signature of smr by the map
+// key, or by the previous map key during its overlap window.
+func (v *Verifier) verifyMapRootSignature(smr trillian.SignedMapRoot, sig *sigpb.DigitallySigned) error {
+	err := tcrypto.VerifyObject(v.mapPubKey, smr, sig)
+	if err == nil || v.prevMapPubKey == nil || !time.Now().Before(v.prevMapExpiry) {
+		return err
+	}
+	if tcrypto.VerifyObject(v.prevMapPubKey, smr, sig) != nil {
+		return err
+	}
+	Vlog.Printf("✓ Signed Map Head signed with the previous map key.")
+	return nil
+}
+
+// rotatedLogVerifier verifies log roots signed with the current log key, or
+// with the previous log key until expiry. Inclusion proofs do not depend on
+// the signing key and are verified by the current verifier.
+type rotatedLogVerifier struct {
+	client.LogVerifier
+	prev   client.LogVerifier
+	expiry time.Time
+}
+
+// VerifyRoot verifies newRoot with the current verifier, falling back to the
+// previous verifier during the overlap window.
+func (r *rotatedLogVerifier) VerifyRoot(trusted, newRoot *trillian.SignedLogRoot, consistency [][]byte) error {
+	err := r.LogVerifier.VerifyRoot(trusted, newRoot, consistency)
+	if err == nil || !time.Now().Before(r.expiry) {
+		return err
+	}
+	if r.prev.VerifyRoot(trusted, newRoot, consistency) != nil {
+		return err
+	}
+	Vlog.Printf("✓ Log root signed with the previous log key.")
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2016 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/fake"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	"github.com/google/trillian/crypto/keyspb"
+
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// failingLogVerifier rejects all log roots.
+type failingLogVerifier struct {
+	client.LogVerifier
+}
+
+func (failingLogVerifier) VerifyRoot(trusted, newRoot *trillian.SignedLogRoot, consistency [][]byte) error {
+	return errors.New("log root not signed by this key")
+}
+
+func TestPreviousMapKey(t *testing.T) {
+	vrfPub, err := p256.NewVRFVerifierFromPEM(VRFPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys [3]*ecdsa.PrivateKey
+	for i := range keys {
+		if keys[i], err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current, previous, other := keys[0], keys[1], keys[2]
+
+	for _, tc := range []struct {
+		desc    string
+		signer  *ecdsa.PrivateKey
+		expiry  time.Time
+		wantErr bool
+	}{
+		{desc: "current key", signer: current},
+		{desc: "previous key", signer: previous, expiry: time.Now().Add(time.Hour)},
+		{desc: "previous key expired", signer: previous, expiry: time.Now().Add(-time.Hour), wantErr: true},
+		{desc: "other key", signer: other, expiry: time.Now().Add(time.Hour), wantErr: true},
+	} {
+		v, err := New(Options{
+			VRF:         vrfPub,
+			MapPubKey:   current.Public(),
+			LogVerifier: fake.NewFakeTrillianLogVerifier(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.expiry.IsZero() {
+			v.SetPreviousMapKey(previous.Public(), tc.expiry)
+		}
+		smr := sign(tc.signer, &trillian.SignedMapRoot{MapId: 1, MapRevision: 2, RootHash: []byte("root")})
+		unsigned := *smr
+		unsigned.Signature = nil
+		if err := v.verifyMapRootSignature(unsigned, smr.GetSignature()); (err != nil) != tc.wantErr {
+			t.Errorf("%v: verifyMapRootSignature(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestRotatedLogVerifier(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		expiry  time.Time
+		wantErr bool
+	}{
+		{desc: "during overlap", expiry: time.Now().Add(time.Hour)},
+		{desc: "after overlap", expiry: time.Now().Add(-time.Hour), wantErr: true},
+	} {
+		r := &rotatedLogVerifier{
+			LogVerifier: failingLogVerifier{},
+			prev:        fake.NewFakeTrillianLogVerifier(),
+			expiry:      tc.expiry,
+		}
+		if err := r.VerifyRoot(nil, &trillian.SignedLogRoot{}, nil); (err != nil) != tc.wantErr {
+			t.Errorf("%v: VerifyRoot(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestRotationExpiry(t *testing.T) {
+	tree := &trillian.Tree{PublicKey: &keyspb.PublicKey{Der: []byte("current")}}
+	for _, tc := range []struct {
+		desc     string
+		rotation *pb.TreeKeyRotation
+		want     time.Time
+		wantErr  error
+	}{
+		{
+			desc: "valid",
+			rotation: &pb.TreeKeyRotation{
+				PreviousKey: &keyspb.PublicKey{Der: []byte("previous")},
+				PublicKey:   &keyspb.PublicKey{Der: []byte("current")},
+				RotateTime:  &tspb.Timestamp{Seconds: 10},
+				OverlapEnd:  &tspb.Timestamp{Seconds: 20},
+			},
+			want: time.Unix(20, 0),
+		},
+		{
+			desc: "rotated to another key",
+			rotation: &pb.TreeKeyRotation{
+				PreviousKey: &keyspb.PublicKey{Der: []byte("previous")},
+				PublicKey:   &keyspb.PublicKey{Der: []byte("other")},
+				RotateTime:  &tspb.Timestamp{Seconds: 10},
+				OverlapEnd:  &tspb.Timestamp{Seconds: 20},
+			},
+			wantErr: ErrKeyRotation,
+		},
+		{
+			desc: "previous key is current",
+			rotation: &pb.TreeKeyRotation{
+				PreviousKey: &keyspb.PublicKey{Der: []byte("current")},
+				PublicKey:   &keyspb.PublicKey{Der: []byte("current")},
+				RotateTime:  &tspb.Timestamp{Seconds: 10},
+				OverlapEnd:  &tspb.Timestamp{Seconds: 20},
+			},
+			wantErr: ErrKeyRotation,
+		},
+		{
+			desc: "overlap ends before rotation",
+			rotation: &pb.TreeKeyRotation{
+				PreviousKey: &keyspb.PublicKey{Der: []byte("previous")},
+				PublicKey:   &keyspb.PublicKey{Der: []byte("current")},
+				RotateTime:  &tspb.Timestamp{Seconds: 20},
+				OverlapEnd:  &tspb.Timestamp{Seconds: 10},
+			},
+			wantErr: ErrKeyRotation,
+		},
+	} {
+		got, err := rotationExpiry(tree, tc.rotation)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: rotationExpiry(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !got.Equal(tc.want) {
+			t.Errorf("%v: rotationExpiry(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// ErrMonitorQuorum occurs when fewer trusted monitors than required have
+// signed a map root.
+var ErrMonitorQuorum = errors.New("too few trusted monitors vouch for map root")
+
+// VerifyMonitorSignature returns nil if signed is smr, signed by the monitor
+// key pubKey instead of the key server.
+func VerifyMonitorSignature(pubKey crypto.PublicKey, signed, smr *trillian.SignedMapRoot) error {
+	if signed == nil {
+		return errors.New("map root not verified by monitor")
+	}
+	root := *signed
+	root.Signature = nil
+	want := *smr
+	want.Signature = nil
+	if !proto.Equal(&root, &want) {
+		return errors.New("monitor signed a different map root")
+	}
+	return tcrypto.VerifyObject(pubKey, root, signed.GetSignature())
+}
+
+// VerifyMonitoredMapRoot verifies the signature of the key server on smr,
+// and that the monitor quorum of v has signed smr. monitorRoots are the map
+// roots that monitors returned for the revision of smr, in any order. Each
+// monitor key counts once, however many of monitorRoots it signed.
+func (v *Verifier) VerifyMonitoredMapRoot(smr *trillian.SignedMapRoot, monitorRoots []*trillian.SignedMapRoot) error {
+	unsigned := *smr
+	unsigned.Signature = nil
+	if err := v.verifyMapRootSignature(unsigned, smr.GetSignature()); err != nil {
+		return verificationError("VerifyMonitoredMapRoot", fmt.Errorf("sig.Verify(SMR): %w", err))
+	}
+	agreed := 0
+	for _, k := range v.monitorKeys {
+		for _, signed := range monitorRoots {
+			if VerifyMonitorSignature(k, signed, smr) == nil {
+				agreed++
+				break
+			}
+		}
+	}
+	if agreed < v.monitorQuorum {
+		return verificationError("VerifyMonitoredMapRoot",
+			fmt.Errorf("%w: %v of %v at revision %v", ErrMonitorQuorum, agreed, v.monitorQuorum, smr.GetMapRevision()))
+	}
+	Vlog.Printf("✓ Map root signed by %v trusted monitors.", agreed)
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	kterrors "github.com/google/keytransparency/core/errors"
+	"github.com/google/keytransparency/core/fake"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keys/pem"
+)
+
+func TestVerifyMonitoredMapRoot(t *testing.T) {
+	vrfPub, err := p256.NewVRFVerifierFromPEM(VRFPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := pem.UnmarshalPrivateKey(testPrivKey1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	smr := sign(server, &trillian.SignedMapRoot{MapId: 1, MapRevision: 2, RootHash: []byte("root")})
+	// monitorRoot returns smr signed by s.
+	monitorRoot := func(s crypto.Signer, smr trillian.SignedMapRoot) *trillian.SignedMapRoot {
+		smr.Signature = nil
+		return sign(s, &smr)
+	}
+	var keys []crypto.PublicKey
+	var roots []*trillian.SignedMapRoot
+	for i := 0; i < 3; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("ecdsa.GenerateKey(): %v", err)
+		}
+		keys = append(keys, key.Public())
+		roots = append(roots, monitorRoot(key, *smr))
+	}
+	other := *smr
+	other.RootHash = []byte("other")
+	forged := *smr
+	forged.Signature = roots[0].Signature
+
+	for _, tc := range []struct {
+		desc    string
+		quorum  int
+		smr     *trillian.SignedMapRoot
+		roots   []*trillian.SignedMapRoot
+		wantErr error
+	}{
+		{desc: "no quorum", quorum: 0, smr: smr},
+		{desc: "quorum", quorum: 2, smr: smr, roots: roots[1:]},
+		{desc: "same monitor twice", quorum: 2, smr: smr, roots: []*trillian.SignedMapRoot{roots[0], roots[0]}, wantErr: ErrMonitorQuorum},
+		{desc: "different root", quorum: 1, smr: smr, roots: []*trillian.SignedMapRoot{monitorRoot(server, other)}, wantErr: ErrMonitorQuorum},
+		{desc: "server signature", quorum: 1, smr: &forged, roots: roots, wantErr: kterrors.ErrVerification},
+	} {
+		v, err := New(Options{
+			VRF:           vrfPub,
+			MapPubKey:     server.Public(),
+			LogVerifier:   fake.NewFakeTrillianLogVerifier(),
+			MonitorKeys:   keys,
+			MonitorQuorum: tc.quorum,
+		})
+		if err != nil {
+			t.Fatalf("New(): %v", err)
+		}
+		err = v.VerifyMonitoredMapRoot(tc.smr, tc.roots)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: VerifyMonitoredMapRoot(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
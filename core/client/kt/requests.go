@@ -23,11 +23,28 @@ import (
 	"github.com/google/trillian/crypto/keyspb"
 )
 
-func (v *Verifier) index(vrfProof []byte, domainID, appID, userID string) ([]byte, error) {
+// Index verifies vrfProof and returns the map index for appID/userID.
+func (v *Verifier) Index(vrfProof []byte, domainID, appID, userID string) ([]byte, error) {
 	uid := vrf.UniqueID(userID, appID)
-	index, err := v.vrf.ProofToHash(uid, vrfProof)
+	index, err := v.vrfFor(appID).ProofToHash(uid, vrfProof)
 	if err != nil {
-		return nil, fmt.Errorf("vrf.ProofToHash(%v, %v): %v", appID, userID, err)
+		// The index may have been computed before a VRF rotation.
+		if prev, ok := v.prevVRFFor(appID); ok {
+			if index, perr := prev.ProofToHash(uid, vrfProof); perr == nil {
+				return index[:], nil
+			}
+		}
+		return nil, fmt.Errorf("vrf.ProofToHash(%v, %v): %w", appID, userID, err)
+	}
+	return index[:], nil
+}
+
+// CurrentIndex verifies that vrfProof was computed with the current VRF of
+// appID and returns the map index for appID/userID.
+func (v *Verifier) CurrentIndex(vrfProof []byte, appID, userID string) ([]byte, error) {
+	index, err := v.vrfFor(appID).ProofToHash(vrf.UniqueID(userID, appID), vrfProof)
+	if err != nil {
+		return nil, fmt.Errorf("vrf.ProofToHash(%v, %v): %w", appID, userID, err)
 	}
 	return index[:], nil
 }
@@ -39,10 +56,14 @@ func (v *Verifier) NewMutation(
 	vrfProof, oldLeaf []byte) (
 	*entry.Mutation, error) {
 
-	index, err := v.index(vrfProof, domainID, appID, userID)
+	index, err := v.Index(vrfProof, domainID, appID, userID)
 	if err != nil {
 		return nil, err
 	}
+	// Check the leaf before it is decoded and hashed with objecthash.
+	if err := v.checkLeaf(oldLeaf); err != nil {
+		return nil, err
+	}
 	mutation := entry.NewMutation(index, domainID, appID, userID)
 	if err := mutation.SetPrevious(oldLeaf, true); err != nil {
 		return nil, err
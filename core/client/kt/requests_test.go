@@ -0,0 +1,59 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+)
+
+func TestIndexPreviousVRF(t *testing.T) {
+	oldPriv, oldPub := p256.GenerateKey()
+	newPriv, newPub := p256.GenerateKey()
+	appPriv, appPub := p256.GenerateKey()
+	userID := "alice"
+
+	for _, tc := range []struct {
+		desc    string
+		appID   string
+		priv    vrf.PrivateKey
+		expiry  time.Time
+		wantErr bool
+	}{
+		{desc: "current key", appID: "app", priv: newPriv, expiry: time.Now().Add(time.Hour)},
+		{desc: "previous key", appID: "app", priv: oldPriv, expiry: time.Now().Add(time.Hour)},
+		{desc: "expired previous key", appID: "app", priv: oldPriv, expiry: time.Now().Add(-time.Hour), wantErr: true},
+		{desc: "app key", appID: "scoped", priv: appPriv, expiry: time.Now().Add(time.Hour)},
+		{desc: "previous key for scoped app", appID: "scoped", priv: oldPriv, expiry: time.Now().Add(time.Hour), wantErr: true},
+	} {
+		v := &Verifier{vrf: newPub}
+		v.SetAppVRF("scoped", appPub)
+		v.SetPreviousVRF(oldPub, tc.expiry)
+
+		want, proof := tc.priv.Evaluate(vrf.UniqueID(userID, tc.appID))
+		got, err := v.Index(proof, domainID, tc.appID, userID)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%v: Index(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !bytes.Equal(got, want[:]) {
+			t.Errorf("%v: Index(): %x, want %x", tc.desc, got, want)
+		}
+	}
+}
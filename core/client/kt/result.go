@@ -0,0 +1,130 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/trillian"
+)
+
+// Check names a step of the verification of a GetEntryResponse.
+type Check string
+
+// The checks of ExplainGetEntryResponse, in the order they run.
+const (
+	// CheckCommitment verifies that the profile opens the commitment in the
+	// map leaf.
+	CheckCommitment Check = "commitment"
+	// CheckVRF verifies that the map index is the VRF output for the user.
+	CheckVRF Check = "vrf"
+	// CheckMapInclusion verifies that the leaf is in the map root.
+	CheckMapInclusion Check = "map inclusion"
+	// CheckMapRootSignature verifies the signature and endorsements of the
+	// map root.
+	CheckMapRootSignature Check = "map root signature"
+	// CheckLogConsistency verifies that the log root is consistent with the
+	// trusted log root.
+	CheckLogConsistency Check = "log consistency"
+	// CheckLogInclusion verifies that the map root is in the log root.
+	CheckLogInclusion Check = "log inclusion"
+)
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Check Check
+	// Err is nil if the check passed.
+	Err      error
+	Duration time.Duration
+}
+
+// VerificationResult reports the checks that ran to verify a response.
+type VerificationResult struct {
+	Checks []CheckResult
+	// Root is the log root to trust after a successful verification, and
+	// nil if verification failed.
+	Root *trillian.SignedLogRoot
+}
+
+// Metrics receives the outcome of the checks that a Verifier runs, so that
+// client apps and monitors can export them to their monitoring system.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveCheck records that check c took d, and failed with err if
+	// err is not nil.
+	ObserveCheck(c Check, err error, d time.Duration)
+}
+
+// run runs f as check c, and records its outcome in r, which may be nil,
+// and in the metrics of v, if any.
+func (v *Verifier) run(r *VerificationResult, c Check, f func() error) error {
+	if r == nil && v.metrics == nil {
+		return f()
+	}
+	start := time.Now()
+	err := f()
+	d := time.Since(start)
+	if v.metrics != nil {
+		v.metrics.ObserveCheck(c, err, d)
+	}
+	if r != nil {
+		r.Checks = append(r.Checks, CheckResult{Check: c, Err: err, Duration: d})
+	}
+	return err
+}
+
+// Passed returns true if c ran and passed.
+func (r *VerificationResult) Passed(c Check) bool {
+	for _, cr := range r.Checks {
+		if cr.Check == c {
+			return cr.Err == nil
+		}
+	}
+	return false
+}
+
+// Failed returns the first check that failed, if any.
+func (r *VerificationResult) Failed() (CheckResult, bool) {
+	for _, cr := range r.Checks {
+		if cr.Err != nil {
+			return cr, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// Duration returns the total time spent in checks.
+func (r *VerificationResult) Duration() time.Duration {
+	var d time.Duration
+	for _, cr := range r.Checks {
+		d += cr.Duration
+	}
+	return d
+}
+
+// String returns one line per check, e.g. "✓ vrf (120µs)".
+func (r *VerificationResult) String() string {
+	var b strings.Builder
+	for _, cr := range r.Checks {
+		if cr.Err != nil {
+			fmt.Fprintf(&b, "✗ %v (%v): %v\n", cr.Check, cr.Duration, cr.Err)
+		} else {
+			fmt.Fprintf(&b, "✓ %v (%v)\n", cr.Check, cr.Duration)
+		}
+	}
+	return b.String()
+}
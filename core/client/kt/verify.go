@@ -15,6 +15,7 @@
 package kt
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"encoding/json"
@@ -22,23 +23,33 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"time"
 
 	"github.com/google/keytransparency/core/crypto/commitments"
 	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/endorsement"
+	kterrors "github.com/google/keytransparency/core/errors"
 	"github.com/google/keytransparency/core/mutator/entry"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/coniks"
 	"github.com/google/trillian/merkle/hashers"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
-	tcrypto "github.com/google/trillian/crypto"
 )
 
 var (
 	// ErrNilProof occurs when the provided GetEntryResponse contains a nil proof.
 	ErrNilProof = errors.New("nil proof")
+	// ErrInvalidPurge occurs when a GetEntryResponse reports purged data
+	// for an entry that does not exist, or reports purged data along with
+	// the data itself.
+	ErrInvalidPurge = errors.New("invalid purged entry")
+	// ErrRevisionIndex occurs when a map root is not at the log index of
+	// its revision. Map revision i is always the log leaf at index i.
+	ErrRevisionIndex = errors.New("map revision is not at its log index")
 
 	// Vlog is the verbose logger. By default it outputs to /dev/null.
 	Vlog = log.New(ioutil.Discard, "", 0)
@@ -47,22 +58,180 @@ var (
 // Verifier is a client helper library for verifying request and responses.
 type Verifier struct {
 	vrf         vrf.PublicKey
+	appVRFs     map[string]vrf.PublicKey
+	prevVRF     vrf.PublicKey
+	prevExpiry  time.Time
 	hasher      hashers.MapHasher
 	mapPubKey   crypto.PublicKey
 	logVerifier client.LogVerifier
+	// prevMapPubKey is the map key that was replaced by a key rotation. It
+	// verifies map roots until prevMapExpiry.
+	prevMapPubKey crypto.PublicKey
+	prevMapExpiry time.Time
+	// endorsementPolicy lists the endorsers that must have co-signed
+	// map roots. Map roots need no endorsements if its quorum is zero.
+	endorsementPolicy *pb.EndorsementPolicy
+	// leafLimits, if set, makes leaf values parse strictly.
+	leafLimits *entry.Limits
+	// monitorKeys verify the map roots signed by trusted monitors, of
+	// which monitorQuorum must have signed a map root.
+	monitorKeys   []crypto.PublicKey
+	monitorQuorum int
+	// metrics, if set, receives the outcome of every check.
+	metrics Metrics
+}
+
+// Options configures a Verifier.
+type Options struct {
+	// VRF verifies the indexes of the domain.
+	VRF vrf.PublicKey
+	// AppVRFs verify the indexes of specific apps, overriding VRF.
+	AppVRFs map[string]vrf.PublicKey
+	// PreviousVRF is the domain VRF that was replaced by a key rotation.
+	// Indexes computed with it are accepted until PreviousVRFExpiry.
+	PreviousVRF       vrf.PublicKey
+	PreviousVRFExpiry time.Time
+	// MapHasher hashes map leaves. Defaults to coniks.Default.
+	MapHasher hashers.MapHasher
+	// MapPubKey verifies map root signatures.
+	MapPubKey crypto.PublicKey
+	// LogVerifier verifies log roots and proofs.
+	LogVerifier client.LogVerifier
+	// PreviousMapPubKey is the map key that was replaced by a key rotation.
+	// Map roots signed with it are accepted until PreviousMapKeyExpiry.
+	PreviousMapPubKey    crypto.PublicKey
+	PreviousMapKeyExpiry time.Time
+	// PreviousLogVerifier verifies log roots signed with the log key that
+	// was replaced by a key rotation, until PreviousLogKeyExpiry.
+	PreviousLogVerifier  client.LogVerifier
+	PreviousLogKeyExpiry time.Time
+	// EndorsementPolicy, if set, requires map roots to be co-signed by a
+	// quorum of its endorsers.
+	EndorsementPolicy *pb.EndorsementPolicy
+	// LeafLimits, if set, makes the verifier reject leaf values that
+	// entry.FromLeafValueStrict rejects under these limits, before any
+	// other parsing of the leaf.
+	LeafLimits *entry.Limits
+	// MonitorKeys verify the map roots signed by trusted monitors.
+	// VerifyMonitoredMapRoot requires MonitorQuorum of them to have signed
+	// a map root.
+	MonitorKeys   []crypto.PublicKey
+	MonitorQuorum int
+	// Metrics, if set, receives the outcome and duration of every check
+	// of a GetEntryResponse.
+	Metrics Metrics
+}
+
+// validate returns an error if a required field is missing and fills in
+// defaults for the optional ones.
+func (o *Options) validate() error {
+	switch {
+	case o.VRF == nil:
+		return errors.New("kt: missing VRF")
+	case o.MapPubKey == nil:
+		return errors.New("kt: missing MapPubKey")
+	case o.LogVerifier == nil:
+		return errors.New("kt: missing LogVerifier")
+	case o.PreviousVRF != nil && o.PreviousVRFExpiry.IsZero():
+		return errors.New("kt: PreviousVRF without PreviousVRFExpiry")
+	case o.PreviousMapPubKey != nil && o.PreviousMapKeyExpiry.IsZero():
+		return errors.New("kt: PreviousMapPubKey without PreviousMapKeyExpiry")
+	case o.PreviousLogVerifier != nil && o.PreviousLogKeyExpiry.IsZero():
+		return errors.New("kt: PreviousLogVerifier without PreviousLogKeyExpiry")
+	case o.MonitorQuorum < 0 || o.MonitorQuorum > len(o.MonitorKeys):
+		return fmt.Errorf("kt: MonitorQuorum %v of %v MonitorKeys", o.MonitorQuorum, len(o.MonitorKeys))
+	}
+	if err := endorsement.CheckPolicy(o.EndorsementPolicy); err != nil {
+		return fmt.Errorf("kt: %w", err)
+	}
+	if o.MapHasher == nil {
+		o.MapHasher = coniks.Default
+	}
+	return nil
 }
 
 // New creates a new instance of the client verifier.
-func New(vrf vrf.PublicKey,
-	hasher hashers.MapHasher,
-	mapPubKey crypto.PublicKey,
-	logVerifier client.LogVerifier) *Verifier {
-	return &Verifier{
-		vrf:         vrf,
-		hasher:      hasher,
-		mapPubKey:   mapPubKey,
-		logVerifier: logVerifier,
+func New(opts Options) (*Verifier, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	v := &Verifier{
+		vrf:         opts.VRF,
+		hasher:      opts.MapHasher,
+		mapPubKey:   opts.MapPubKey,
+		logVerifier: opts.LogVerifier,
+		prevVRF:     opts.PreviousVRF,
+		prevExpiry:  opts.PreviousVRFExpiry,
+
+		prevMapPubKey: opts.PreviousMapPubKey,
+		prevMapExpiry: opts.PreviousMapKeyExpiry,
+
+		endorsementPolicy: opts.EndorsementPolicy,
+		leafLimits:        opts.LeafLimits,
+
+		monitorKeys:   opts.MonitorKeys,
+		monitorQuorum: opts.MonitorQuorum,
+
+		metrics: opts.Metrics,
+	}
+	if opts.PreviousLogVerifier != nil {
+		v.logVerifier = &rotatedLogVerifier{
+			LogVerifier: opts.LogVerifier,
+			prev:        opts.PreviousLogVerifier,
+			expiry:      opts.PreviousLogKeyExpiry,
+		}
+	}
+	for appID, pk := range opts.AppVRFs {
+		v.SetAppVRF(appID, pk)
+	}
+	return v, nil
+}
+
+// SetAppVRF sets the VRF used to verify the indexes of appID, overriding the
+// domain's VRF.
+func (v *Verifier) SetAppVRF(appID string, pk vrf.PublicKey) {
+	if v.appVRFs == nil {
+		v.appVRFs = make(map[string]vrf.PublicKey)
+	}
+	v.appVRFs[appID] = pk
+}
+
+// VerifyEndorsementPolicy returns a verification error if policy, an
+// endorsement policy served for the domain, is weaker than the policy the
+// verifier enforces. A server that serves a weaker policy than the one that
+// was pinned is trying to publish map roots without their endorsements.
+func (v *Verifier) VerifyEndorsementPolicy(policy *pb.EndorsementPolicy) error {
+	if err := endorsement.CheckNotWeaker(policy, v.endorsementPolicy); err != nil {
+		return verificationError("VerifyEndorsementPolicy", err)
+	}
+	return nil
+}
+
+// SetPreviousVRF sets the domain VRF that was replaced by a key rotation.
+// Indexes computed with pk are accepted until expiry.
+func (v *Verifier) SetPreviousVRF(pk vrf.PublicKey, expiry time.Time) {
+	v.prevVRF = pk
+	v.prevExpiry = expiry
+}
+
+// prevVRFFor returns the previous domain VRF if it may still be used to
+// compute indexes for appID.
+func (v *Verifier) prevVRFFor(appID string) (vrf.PublicKey, bool) {
+	if _, ok := v.appVRFs[appID]; ok {
+		return nil, false
+	}
+	if v.prevVRF == nil || !time.Now().Before(v.prevExpiry) {
+		return nil, false
+	}
+	return v.prevVRF, true
+}
+
+// vrfFor returns the VRF used to compute indexes for appID.
+func (v *Verifier) vrfFor(appID string) vrf.PublicKey {
+	if pk, ok := v.appVRFs[appID]; ok {
+		return pk
 	}
+	return v.vrf
 }
 
 // VerifyGetEntryResponse verifies GetEntryResponse:
@@ -72,79 +241,332 @@ func New(vrf vrf.PublicKey,
 //  - Verify signature.
 //  - Verify consistency proof from log.Root().
 //  - Verify inclusion proof.
+//
+// Verification stops with ctx.Err() if ctx is done before a step starts.
+// Other failures wrap kterrors.ErrVerification.
+//
+// On success it returns the log root to trust from now on: the log root of
+// in if it is newer than trusted, and trusted otherwise. The verifier keeps
+// no state, so callers must store the returned root themselves.
 func (v *Verifier) VerifyGetEntryResponse(ctx context.Context, domainID, appID, userID string,
-	trusted *trillian.SignedLogRoot, in *pb.GetEntryResponse) error {
-	// Unpack the merkle tree leaf value.
-	e, err := entry.FromLeafValue(in.GetLeafProof().GetLeaf().GetLeafValue())
+	trusted *trillian.SignedLogRoot, in *pb.GetEntryResponse) (*trillian.SignedLogRoot, error) {
+	r, err := v.ExplainGetEntryResponse(ctx, domainID, appID, userID, trusted, in)
+	return r.Root, err
+}
+
+// ExplainGetEntryResponse verifies in like VerifyGetEntryResponse, and also
+// returns a report of the checks that ran, in order, with their outcomes and
+// durations. The report ends with the first check that failed.
+func (v *Verifier) ExplainGetEntryResponse(ctx context.Context, domainID, appID, userID string,
+	trusted *trillian.SignedLogRoot, in *pb.GetEntryResponse) (*VerificationResult, error) {
+	r := &VerificationResult{}
+	if err := v.verifyLeaf(ctx, v.hasher, domainID, appID, userID, in, r); err != nil {
+		return r, verificationError("VerifyGetEntryResponse", err)
+	}
+	if err := v.verifyRoots(ctx, trusted, in, r); err != nil {
+		return r, verificationError("VerifyGetEntryResponse", err)
+	}
+	r.Root = newerRoot(trusted, in.GetLogRoot())
+	return r, nil
+}
+
+// newerRoot returns root if it is newer than trusted, and trusted otherwise.
+func newerRoot(trusted, root *trillian.SignedLogRoot) *trillian.SignedLogRoot {
+	if root.GetTreeSize() > trusted.GetTreeSize() {
+		return root
+	}
+	return trusted
+}
+
+// verificationError marks err as a verification failure of op. Context
+// errors are returned unchanged since they say nothing about the response.
+func verificationError(op string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return kterrors.Wrap(kterrors.ErrVerification, op, err)
+}
+
+// verifyLeaf verifies the commitment, the VRF and the map inclusion proof of
+// in. Map proofs are hashed with hasher. The checks are recorded in r, which
+// may be nil.
+func (v *Verifier) verifyLeaf(ctx context.Context, hasher hashers.MapHasher,
+	domainID, appID, userID string, in *pb.GetEntryResponse, r *VerificationResult) error {
+	if err := v.run(r, CheckCommitment, func() error {
+		return v.verifyCommitment(appID, userID, in)
+	}); err != nil {
+		return err
+	}
+	Vlog.Printf("✓ Commitment verified.")
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var index []byte
+	if err := v.run(r, CheckVRF, func() (err error) {
+		index, err = v.Index(in.GetVrfProof(), domainID, appID, userID)
+		return err
+	}); err != nil {
+		Vlog.Printf("✗ VRF verification failed.")
+		return err
+	}
+	Vlog.Printf("✓ VRF verified.")
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := v.run(r, CheckMapInclusion, func() error {
+		leafProof := in.GetLeafProof()
+		if leafProof == nil {
+			return ErrNilProof
+		}
+		leaf := leafProof.GetLeaf().GetLeafValue()
+		proof := leafProof.GetInclusion()
+		expectedRoot := in.GetSmr().GetRootHash()
+		mapID := in.GetSmr().GetMapId()
+		if err := merkle.VerifyMapInclusionProof(mapID, index[:], leaf, expectedRoot, proof, hasher); err != nil {
+			Vlog.Printf("✗ Sparse tree proof verification failed.")
+			return fmt.Errorf("VerifyMapInclusionProof(): %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	Vlog.Printf("✓ Sparse tree proof verified.")
+	return nil
+}
+
+// verifyCommitment verifies that the profile data of in opens the commitment
+// in its leaf.
+func (v *Verifier) verifyCommitment(appID, userID string, in *pb.GetEntryResponse) error {
+	// Unpack the commitment from the merkle tree leaf value. The rest of the
+	// entry is authenticated by the map inclusion proof and not needed here.
+	commitment, exists, err := v.leafCommitment(in.GetLeafProof().GetLeaf().GetLeafValue())
 	if err != nil {
 		return err
 	}
 
+	// Purged data can only be reported for an existing entry. The commitment
+	// in the leaf is still verified against the map root below.
+	if in.GetCommittedPurged() && (!exists || in.GetCommitted() != nil) {
+		Vlog.Printf("✗ Purged entry verification failed.")
+		return ErrInvalidPurge
+	}
+
 	// If this is not a proof of absence, verify the connection between
 	// profileData and the commitment in the merkle tree leaf.
 	if in.GetCommitted() != nil {
-		commitment := e.GetCommitment()
 		data := in.GetCommitted().GetData()
 		nonce := in.GetCommitted().GetKey()
 		if err := commitments.Verify(userID, appID, commitment, data, nonce); err != nil {
 			Vlog.Printf("✗ Commitment verification failed.")
-			return fmt.Errorf("commitments.Verify(%v, %v, %v, %v, %v): %v", userID, appID, commitment, data, nonce, err)
+			return fmt.Errorf("commitments.Verify(%v, %v, %v, %v, %v): %w", userID, appID, commitment, data, nonce, err)
 		}
 	}
-	Vlog.Printf("✓ Commitment verified.")
+	return nil
+}
 
-	index, err := v.index(in.GetVrfProof(), domainID, appID, userID)
-	if err != nil {
-		Vlog.Printf("✗ VRF verification failed.")
-		return err
+// leafCommitment returns the commitment of the Entry encoded in value, like
+// entry.CommitmentFromLeafValue, after checking value against the leaf
+// limits of v, if any.
+func (v *Verifier) leafCommitment(value []byte) ([]byte, bool, error) {
+	if err := v.checkLeaf(value); err != nil {
+		return nil, false, err
 	}
-	Vlog.Printf("✓ VRF verified.")
+	return entry.CommitmentFromLeafValue(value)
+}
 
-	leafProof := in.GetLeafProof()
-	if leafProof == nil {
-		return ErrNilProof
+// checkLeaf parses value strictly if v has leaf limits.
+func (v *Verifier) checkLeaf(value []byte) error {
+	if v.leafLimits == nil {
+		return nil
 	}
+	_, err := entry.FromLeafValueStrict(value, *v.leafLimits)
+	return err
+}
 
-	leaf := leafProof.GetLeaf().GetLeafValue()
-	proof := leafProof.GetInclusion()
-	expectedRoot := in.GetSmr().GetRootHash()
-	mapID := in.GetSmr().GetMapId()
-	if err := merkle.VerifyMapInclusionProof(mapID, index[:], leaf, expectedRoot, proof, v.hasher); err != nil {
-		Vlog.Printf("✗ Sparse tree proof verification failed.")
-		return fmt.Errorf("VerifyMapInclusionProof(): %v", err)
+// verifyRoots verifies the signature of the map root of in, the log root
+// of in against trusted, and the inclusion of the map root in the log. The
+// checks are recorded in r, which may be nil.
+func (v *Verifier) verifyRoots(ctx context.Context, trusted *trillian.SignedLogRoot, in *pb.GetEntryResponse, r *VerificationResult) error {
+	if err := v.run(r, CheckMapRootSignature, func() error {
+		return v.verifyMapRoot(ctx, in)
+	}); err != nil {
+		return err
 	}
-	Vlog.Printf("✓ Sparse tree proof verified.")
+	if err := v.run(r, CheckLogConsistency, func() error {
+		return v.verifyLogRoot(ctx, trusted, in)
+	}); err != nil {
+		return err
+	}
+	return v.run(r, CheckLogInclusion, func() error {
+		return v.verifyLogInclusion(in)
+	})
+}
 
+// verifyMapRoot verifies the signature and endorsements of the map root of in.
+func (v *Verifier) verifyMapRoot(ctx context.Context, in *pb.GetEntryResponse) error {
 	// SignedMapRoot contains its own signature. To verify, we need to create a local
 	// copy of the object and return the object to the state it was in when signed
 	// by removing the signature from the object.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	smr := *in.GetSmr()
 	smr.Signature = nil // Remove the signature from the object to be verified.
-	if err := tcrypto.VerifyObject(v.mapPubKey, smr, in.GetSmr().GetSignature()); err != nil {
+	if err := v.verifyMapRootSignature(smr, in.GetSmr().GetSignature()); err != nil {
 		Vlog.Printf("✗ Signed Map Head signature verification failed.")
-		return fmt.Errorf("sig.Verify(SMR): %v", err)
+		return fmt.Errorf("sig.Verify(SMR): %w", err)
 	}
 	Vlog.Printf("✓ Signed Map Head signature verified.")
+	return endorsement.Verify(in.GetSmr(), in.GetEndorsements(), v.endorsementPolicy)
+}
 
+// verifyLogRoot verifies the consistency of the log root of in with trusted.
+func (v *Verifier) verifyLogRoot(ctx context.Context, trusted *trillian.SignedLogRoot, in *pb.GetEntryResponse) error {
 	// Verify consistency proof between root and newroot.
 	// TODO(gdbelvin): Gossip root.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := v.logVerifier.VerifyRoot(trusted, in.GetLogRoot(), in.GetLogConsistency()); err != nil {
-		return fmt.Errorf("VerifyRoot(%v, %v): %v", in.GetLogRoot(), in.GetLogConsistency(), err)
+		return fmt.Errorf("VerifyRoot(%v, %v): %w", in.GetLogRoot(), in.GetLogConsistency(), err)
 	}
 	Vlog.Printf("✓ Log root updated.")
-	trusted = in.GetLogRoot()
+	return nil
+}
 
-	// Verify inclusion proof.
+// verifyLogInclusion verifies the inclusion of the map root of in in the log
+// root of in, which must have been verified.
+func (v *Verifier) verifyLogInclusion(in *pb.GetEntryResponse) error {
+	if err := checkRevisionIndex(in.GetSmr(), in.GetLogRoot()); err != nil {
+		return err
+	}
 	b, err := json.Marshal(in.GetSmr())
 	if err != nil {
-		return fmt.Errorf("json.Marshal(): %v", err)
+		return fmt.Errorf("json.Marshal(): %w", err)
 	}
 	logLeafIndex := in.GetSmr().GetMapRevision()
-	if err := v.logVerifier.VerifyInclusionAtIndex(trusted, b, logLeafIndex,
+	if err := v.logVerifier.VerifyInclusionAtIndex(in.GetLogRoot(), b, logLeafIndex,
 		in.GetLogInclusion()); err != nil {
-		return fmt.Errorf("VerifyInclusionAtIndex(%s, %v, _): %v",
+		return fmt.Errorf("VerifyInclusionAtIndex(%s, %v, _): %w",
 			b, in.GetSmr().GetMapRevision(), err)
 	}
 	Vlog.Printf("✓ Log inclusion proof verified.")
 	return nil
 }
+
+// checkRevisionIndex returns ErrRevisionIndex if the log index of the
+// revision of smr is not in the log described by root.
+func checkRevisionIndex(smr *trillian.SignedMapRoot, root *trillian.SignedLogRoot) error {
+	if rev := smr.GetMapRevision(); rev < 0 || rev >= root.GetTreeSize() {
+		return fmt.Errorf("revision %v in a log of size %v: %w", rev, root.GetTreeSize(), ErrRevisionIndex)
+	}
+	return nil
+}
+
+// VerifyMutationProof verifies that the leaf a mutation operated on was
+// included at index in the map root of the previous epoch, prevSMR.
+func (v *Verifier) VerifyMutationProof(index []byte, prevSMR *trillian.SignedMapRoot, in *pb.MutationProof) error {
+	if !bytes.Equal(in.GetMutation().GetIndex(), index) {
+		return verificationError("VerifyMutationProof",
+			fmt.Errorf("mutation index %x, want %x", in.GetMutation().GetIndex(), index))
+	}
+	leafProof := in.GetLeafProof()
+	if leafProof == nil {
+		return verificationError("VerifyMutationProof", ErrNilProof)
+	}
+	if err := merkle.VerifyMapInclusionProof(prevSMR.GetMapId(), index,
+		leafProof.GetLeaf().GetLeafValue(), prevSMR.GetRootHash(),
+		leafProof.GetInclusion(), v.hasher); err != nil {
+		return verificationError("VerifyMutationProof", fmt.Errorf("VerifyMapInclusionProof(): %w", err))
+	}
+	return nil
+}
+
+// VerifyEpoch verifies the signatures on the map and log roots of an epoch,
+// that the log root is consistent with trusted, and that the map root is
+// included in the log root. An empty trusted root accepts any correctly
+// signed log root, which is only appropriate on first use of a domain.
+// On success it returns the log root to trust from now on, like
+// VerifyGetEntryResponse.
+func (v *Verifier) VerifyEpoch(ctx context.Context, trusted *trillian.SignedLogRoot, in *pb.Epoch) (*trillian.SignedLogRoot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := v.verifyEpoch(trusted, in); err != nil {
+		return nil, verificationError("VerifyEpoch", err)
+	}
+	return newerRoot(trusted, in.GetLogRoot()), nil
+}
+
+// verifyEpoch implements VerifyEpoch.
+func (v *Verifier) verifyEpoch(trusted *trillian.SignedLogRoot, in *pb.Epoch) error {
+	smr := *in.GetSmr()
+	smr.Signature = nil
+	if err := v.verifyMapRootSignature(smr, in.GetSmr().GetSignature()); err != nil {
+		Vlog.Printf("✗ Signed Map Head signature verification failed.")
+		return fmt.Errorf("sig.Verify(SMR): %w", err)
+	}
+	if err := endorsement.Verify(in.GetSmr(), in.GetEndorsements(), v.endorsementPolicy); err != nil {
+		return err
+	}
+	if err := v.logVerifier.VerifyRoot(trusted, in.GetLogRoot(), in.GetLogConsistency()); err != nil {
+		return fmt.Errorf("VerifyRoot(%v, %v): %w", in.GetLogRoot(), in.GetLogConsistency(), err)
+	}
+	if err := checkRevisionIndex(in.GetSmr(), in.GetLogRoot()); err != nil {
+		return err
+	}
+	b, err := json.Marshal(in.GetSmr())
+	if err != nil {
+		return fmt.Errorf("json.Marshal(): %w", err)
+	}
+	if err := v.logVerifier.VerifyInclusionAtIndex(in.GetLogRoot(), b, in.GetSmr().GetMapRevision(),
+		in.GetLogInclusion()); err != nil {
+		return fmt.Errorf("VerifyInclusionAtIndex(%v): %w", in.GetSmr().GetMapRevision(), err)
+	}
+	Vlog.Printf("✓ Epoch %v verified.", in.GetSmr().GetMapRevision())
+	return nil
+}
+
+// VerifyConsistencyChain verifies that each of roots, which must be ordered by
+// tree size, is consistent with the next one, and that the last of roots is
+// consistent with chain.LogRoot. Verification stops with ctx.Err() if ctx is
+// done before all proofs have been verified.
+func (v *Verifier) VerifyConsistencyChain(ctx context.Context, roots []*trillian.SignedLogRoot, chain *pb.LogConsistencyChain) error {
+	return verificationError("VerifyConsistencyChain", v.verifyConsistencyChain(ctx, roots, chain))
+}
+
+// VerifyLogRoot verifies the signature of root, without checking that it is
+// consistent with any other log root.
+func (v *Verifier) VerifyLogRoot(root *trillian.SignedLogRoot) error {
+	return verificationError("VerifyLogRoot", v.logVerifier.VerifyRoot(&trillian.SignedLogRoot{}, root, nil))
+}
+
+// verifyConsistencyChain implements VerifyConsistencyChain.
+func (v *Verifier) verifyConsistencyChain(ctx context.Context, roots []*trillian.SignedLogRoot, chain *pb.LogConsistencyChain) error {
+	proofs := chain.GetProofs()
+	if got, want := len(proofs), len(roots); got != want {
+		return fmt.Errorf("len(proofs): %v, want %v", got, want)
+	}
+	for i, proof := range proofs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next := chain.GetLogRoot()
+		if i+1 < len(roots) {
+			next = roots[i+1]
+		}
+		if proof.GetFirstTreeSize() != roots[i].GetTreeSize() ||
+			proof.GetSecondTreeSize() != next.GetTreeSize() {
+			return fmt.Errorf("proof %v covers tree sizes (%v, %v), want (%v, %v)", i,
+				proof.GetFirstTreeSize(), proof.GetSecondTreeSize(),
+				roots[i].GetTreeSize(), next.GetTreeSize())
+		}
+		if err := v.logVerifier.VerifyRoot(roots[i], next, proof.GetHashes()); err != nil {
+			return fmt.Errorf("VerifyRoot(%v, %v): %w", roots[i].GetTreeSize(), next.GetTreeSize(), err)
+		}
+	}
+	Vlog.Printf("✓ Log consistency chain of %v roots verified.", len(roots))
+	return nil
+}
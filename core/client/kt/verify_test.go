@@ -17,15 +17,26 @@ package kt
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/endorsement"
+	kterrors "github.com/google/keytransparency/core/errors"
 	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/mutator/entry"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/merkle/hashers"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 
@@ -98,7 +109,15 @@ func TestVerifyGetEntryResponse(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	v := New(vrfPub, mapHasher, mapPub, fake.NewFakeTrillianLogVerifier())
+	v, err := New(Options{
+		VRF:         vrfPub,
+		MapHasher:   mapHasher,
+		MapPubKey:   mapPub,
+		LogVerifier: fake.NewFakeTrillianLogVerifier(),
+	})
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
 
 	for _, tc := range []struct {
 		desc          string
@@ -125,6 +144,7 @@ func TestVerifyGetEntryResponse(t *testing.T) {
 					MapRevision:    1,
 					Metadata:       mustMetadataAsAny(t, &pb.MapperMetadata{}),
 				}),
+				LogRoot: &trillian.SignedLogRoot{TreeSize: 2},
 			},
 			wantErr: false,
 		},
@@ -149,13 +169,297 @@ func TestVerifyGetEntryResponse(t *testing.T) {
 					MapRevision:    2,
 					Metadata:       mustMetadataAsAny(t, &pb.MapperMetadata{HighestFullyCompletedSeq: 1}),
 				}),
+				LogRoot: &trillian.SignedLogRoot{TreeSize: 3},
 			},
 		},
 	} {
-		err := v.VerifyGetEntryResponse(ctx, domainID, tc.appID, tc.userID, tc.trusted, tc.in)
+		_, err := v.VerifyGetEntryResponse(ctx, domainID, tc.appID, tc.userID, tc.trusted, tc.in)
 		if got, want := err != nil, tc.wantErr; got != want {
 			t.Errorf("VerifyGetEntryResponse(%v, %v, %v, %v): %t, wantErr %t (err=%v)",
 				tc.userID, tc.appID, tc.trusted, tc.in, got, want, err)
 		}
 	}
 }
+
+func TestNewOptions(t *testing.T) {
+	vrfPub, err := p256.NewVRFVerifierFromPEM(VRFPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapPub, err := pem.UnmarshalPublicKey(testPubKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := func() Options {
+		return Options{
+			VRF:         vrfPub,
+			MapPubKey:   mapPub,
+			LogVerifier: fake.NewFakeTrillianLogVerifier(),
+		}
+	}
+	for _, tc := range []struct {
+		desc    string
+		edit    func(o *Options)
+		wantErr bool
+	}{
+		{desc: "valid", edit: func(o *Options) {}},
+		{desc: "no vrf", edit: func(o *Options) { o.VRF = nil }, wantErr: true},
+		{desc: "no map key", edit: func(o *Options) { o.MapPubKey = nil }, wantErr: true},
+		{desc: "no log verifier", edit: func(o *Options) { o.LogVerifier = nil }, wantErr: true},
+		{desc: "previous vrf without expiry", edit: func(o *Options) { o.PreviousVRF = vrfPub }, wantErr: true},
+		{desc: "previous map key without expiry", edit: func(o *Options) { o.PreviousMapPubKey = mapPub }, wantErr: true},
+		{desc: "previous log verifier without expiry", edit: func(o *Options) { o.PreviousLogVerifier = fake.NewFakeTrillianLogVerifier() }, wantErr: true},
+		{desc: "unsatisfiable endorsement policy", edit: func(o *Options) { o.EndorsementPolicy = &pb.EndorsementPolicy{Quorum: 1} }, wantErr: true},
+	} {
+		opts := valid()
+		tc.edit(&opts)
+		v, err := New(opts)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: New(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && v.hasher == nil {
+			t.Errorf("%v: New() did not default MapHasher", tc.desc)
+		}
+	}
+}
+
+func TestNewerRoot(t *testing.T) {
+	old := &trillian.SignedLogRoot{TreeSize: 2}
+	cur := &trillian.SignedLogRoot{TreeSize: 3}
+	for _, tc := range []struct {
+		desc          string
+		trusted, root *trillian.SignedLogRoot
+		want          *trillian.SignedLogRoot
+	}{
+		{desc: "first use", trusted: nil, root: cur, want: cur},
+		{desc: "advance", trusted: old, root: cur, want: cur},
+		{desc: "same", trusted: cur, root: cur, want: cur},
+		{desc: "stale", trusted: cur, root: old, want: cur},
+	} {
+		if got := newerRoot(tc.trusted, tc.root); got != tc.want {
+			t.Errorf("%v: newerRoot(%v, %v): %v, want %v", tc.desc, tc.trusted, tc.root, got, tc.want)
+		}
+	}
+}
+
+func TestCheckRevisionIndex(t *testing.T) {
+	for _, tc := range []struct {
+		revision, treeSize int64
+		wantErr            bool
+	}{
+		{revision: 0, treeSize: 1},
+		{revision: 4, treeSize: 5},
+		{revision: 1, treeSize: 5},
+		{revision: 5, treeSize: 5, wantErr: true},
+		{revision: 0, treeSize: 0, wantErr: true},
+		{revision: -1, treeSize: 5, wantErr: true},
+	} {
+		err := checkRevisionIndex(&trillian.SignedMapRoot{MapRevision: tc.revision},
+			&trillian.SignedLogRoot{TreeSize: tc.treeSize})
+		if got := err != nil; got != tc.wantErr || (got && !errors.Is(err, ErrRevisionIndex)) {
+			t.Errorf("checkRevisionIndex(%v, %v): %v, wantErr %v", tc.revision, tc.treeSize, err, tc.wantErr)
+		}
+	}
+}
+
+func TestVerifyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v := &Verifier{}
+
+	if _, err := v.VerifyGetEntryResponse(ctx, domainID, "app", "alice", &trillian.SignedLogRoot{}, &pb.GetEntryResponse{}); err != context.Canceled {
+		t.Errorf("VerifyGetEntryResponse(): %v, want %v", err, context.Canceled)
+	}
+	chain := &pb.LogConsistencyChain{Proofs: []*pb.LogConsistencyProof{{}}}
+	if err := v.VerifyConsistencyChain(ctx, []*trillian.SignedLogRoot{{}}, chain); err != context.Canceled {
+		t.Errorf("VerifyConsistencyChain(): %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestVerifyInvalidPurge(t *testing.T) {
+	ctx := context.Background()
+	v := &Verifier{}
+	leaf, err := proto.Marshal(&pb.Entry{Commitment: []byte("commitment")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc string
+		in   *pb.GetEntryResponse
+	}{
+		{
+			desc: "absent entry",
+			in: &pb.GetEntryResponse{
+				CommittedPurged: true,
+				LeafProof:       &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{}},
+			},
+		},
+		{
+			desc: "purged data returned",
+			in: &pb.GetEntryResponse{
+				CommittedPurged: true,
+				Committed:       &pb.Committed{Key: []byte("key"), Data: []byte("data")},
+				LeafProof:       &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{LeafValue: leaf}},
+			},
+		},
+	} {
+		_, err := v.VerifyGetEntryResponse(ctx, domainID, "app", "alice", nil, tc.in)
+		if !errors.Is(err, ErrInvalidPurge) || !errors.Is(err, kterrors.ErrVerification) {
+			t.Errorf("%v: VerifyGetEntryResponse(): %v, want %v", tc.desc, err, ErrInvalidPurge)
+		}
+	}
+}
+
+func TestVerifyStrictLeaf(t *testing.T) {
+	ctx := context.Background()
+	leaf, err := proto.Marshal(&pb.Entry{Commitment: []byte("commitment")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Field 12 is not a field of Entry.
+	leaf = append(leaf, 12<<3|2, 1, 0)
+	in := &pb.GetEntryResponse{
+		LeafProof: &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{LeafValue: leaf}},
+	}
+
+	v := &Verifier{leafLimits: &entry.DefaultLimits}
+	if _, err := v.VerifyGetEntryResponse(ctx, domainID, "app", "alice", nil, in); !errors.Is(err, entry.ErrUnknownField) {
+		t.Errorf("VerifyGetEntryResponse(): %v, want %v", err, entry.ErrUnknownField)
+	}
+}
+
+func TestExplainGetEntryResponse(t *testing.T) {
+	ctx := context.Background()
+	v := &Verifier{}
+	in := &pb.GetEntryResponse{
+		CommittedPurged: true,
+		LeafProof:       &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{}},
+	}
+
+	r, err := v.ExplainGetEntryResponse(ctx, domainID, "app", "alice", nil, in)
+	if !errors.Is(err, ErrInvalidPurge) || !errors.Is(err, kterrors.ErrVerification) {
+		t.Fatalf("ExplainGetEntryResponse(): %v, want %v", err, ErrInvalidPurge)
+	}
+	if got, want := len(r.Checks), 1; got != want {
+		t.Fatalf("ExplainGetEntryResponse(): %v checks, want %v:\n%v", got, want, r)
+	}
+	failed, ok := r.Failed()
+	if !ok || failed.Check != CheckCommitment || !errors.Is(failed.Err, ErrInvalidPurge) {
+		t.Errorf("Failed(): %v, %v, want %v", failed, ok, CheckCommitment)
+	}
+	for _, c := range []Check{CheckCommitment, CheckVRF, CheckLogInclusion} {
+		if r.Passed(c) {
+			t.Errorf("Passed(%v): true, want false", c)
+		}
+	}
+}
+
+// recordingMetrics records the checks it observes.
+type recordingMetrics struct {
+	checks []CheckResult
+}
+
+func (m *recordingMetrics) ObserveCheck(c Check, err error, d time.Duration) {
+	m.checks = append(m.checks, CheckResult{Check: c, Err: err, Duration: d})
+}
+
+func TestVerifierMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := &recordingMetrics{}
+	v := &Verifier{metrics: m}
+	in := &pb.GetEntryResponse{
+		CommittedPurged: true,
+		LeafProof:       &trillian.MapLeafInclusion{Leaf: &trillian.MapLeaf{}},
+	}
+
+	if _, err := v.VerifyGetEntryResponse(ctx, domainID, "app", "alice", nil, in); err == nil {
+		t.Fatalf("VerifyGetEntryResponse(): nil, want error")
+	}
+	if got, want := len(m.checks), 1; got != want {
+		t.Fatalf("ObserveCheck() called %v times, want %v", got, want)
+	}
+	if got := m.checks[0]; got.Check != CheckCommitment || !errors.Is(got.Err, ErrInvalidPurge) {
+		t.Errorf("ObserveCheck(%v, %v), want (%v, %v)", got.Check, got.Err, CheckCommitment, ErrInvalidPurge)
+	}
+}
+
+func TestVerifyEpochEndorsements(t *testing.T) {
+	ctx := context.Background()
+	vrfPub, err := p256.NewVRFVerifierFromPEM(VRFPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapPub, err := pem.UnmarshalPublicKey(testPubKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := pem.UnmarshalPrivateKey(testPrivKey1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	endorser, err := endorsement.NewSigner(tcrypto.NewSHA256Signer(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := New(Options{
+		VRF:               vrfPub,
+		MapPubKey:         mapPub,
+		LogVerifier:       fake.NewFakeTrillianLogVerifier(),
+		EndorsementPolicy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{{Der: der}}, Quorum: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	smr := sign(signer, &trillian.SignedMapRoot{MapId: 1, MapRevision: 2, RootHash: []byte("root")})
+	e, err := endorser.Endorse(ctx, domainID, smr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc         string
+		endorsements []*pb.MapRootEndorsement
+		wantErr      error
+	}{
+		{desc: "endorsed", endorsements: []*pb.MapRootEndorsement{e}},
+		{desc: "missing", wantErr: endorsement.ErrQuorum},
+	} {
+		_, err := v.VerifyEpoch(ctx, nil, &pb.Epoch{
+			Smr:          smr,
+			LogRoot:      &trillian.SignedLogRoot{TreeSize: 3},
+			Endorsements: tc.endorsements,
+		})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: VerifyEpoch(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+	}
+
+	// A server must not weaken the pinned policy.
+	for _, tc := range []struct {
+		desc    string
+		policy  *pb.EndorsementPolicy
+		wantErr bool
+	}{
+		{desc: "pinned", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{{Der: der}}, Quorum: 1}},
+		{desc: "quorum 0", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{{Der: der}}}, wantErr: true},
+		{desc: "unset", wantErr: true},
+	} {
+		err := v.VerifyEndorsementPolicy(tc.policy)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: VerifyEndorsementPolicy(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		if err != nil && !errors.Is(err, kterrors.ErrVerification) {
+			t.Errorf("%v: VerifyEndorsementPolicy(): %v, want %v", tc.desc, err, kterrors.ErrVerification)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ktdebug
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/trillian"
+)
+
+// MapInclusionDOT writes the path of a map inclusion proof as a DOT graph.
+// Runs of nodes with empty siblings are collapsed into a single edge.
+func MapInclusionDOT(w io.Writer, inclusion *trillian.MapLeafInclusion, root []byte) {
+	leaf := inclusion.GetLeaf()
+	bits := len(inclusion.GetInclusion())
+	fmt.Fprintf(w, "digraph map_inclusion {\n")
+	fmt.Fprintf(w, "  node [shape=box, fontname=monospace];\n")
+	fmt.Fprintf(w, "  root [label=\"root\\n%x\"];\n", short(root))
+	parent := "root"
+	above := bits // The height of parent.
+	siblings := mapSiblings(leaf.GetIndex(), inclusion.GetInclusion())
+	for i := len(siblings) - 1; i >= 0; i-- {
+		s := siblings[i]
+		node := parent
+		if s.height+1 < above {
+			node = fmt.Sprintf("n%d", s.height+1)
+			fmt.Fprintf(w, "  %v [label=\"height %d\"];\n", node, s.height+1)
+			pathEdge(w, parent, node, above-s.height-1)
+		}
+		fmt.Fprintf(w, "  s%d [label=\"sibling %s\\n%x\", style=filled, fillcolor=lightgrey];\n", s.height, s.side, short(s.hash))
+		fmt.Fprintf(w, "  %v -> s%d [style=dashed];\n", node, s.height)
+		parent, above = node, s.height+1
+	}
+	fmt.Fprintf(w, "  leaf [label=\"leaf %x\\n%x\", shape=ellipse];\n", short(leaf.GetIndex()), short(leaf.GetLeafHash()))
+	pathEdge(w, parent, "leaf", above)
+	fmt.Fprintf(w, "}\n")
+}
+
+// pathEdge writes an edge between two nodes on a map path that are levels
+// apart.
+func pathEdge(w io.Writer, from, to string, levels int) {
+	if levels > 1 {
+		fmt.Fprintf(w, "  %v -> %v [label=\"%d levels\"];\n", from, to, levels)
+		return
+	}
+	fmt.Fprintf(w, "  %v -> %v;\n", from, to)
+}
+
+// LogInclusionDOT writes a log inclusion proof as a DOT graph of the
+// subtrees its hashes cover.
+func LogInclusionDOT(w io.Writer, index, treeSize int64, proof [][]byte) {
+	fmt.Fprintf(w, "digraph log_inclusion {\n")
+	fmt.Fprintf(w, "  node [shape=box, fontname=monospace];\n")
+	fmt.Fprintf(w, "  root [label=\"root\\nsize %d\"];\n", treeSize)
+	fmt.Fprintf(w, "  target [label=\"leaf %d\", shape=ellipse];\n", index)
+	writeLogDOT(w, inclusionRanges(index, 0, treeSize), proof)
+	fmt.Fprintf(w, "}\n")
+}
+
+// LogConsistencyDOT writes a log consistency proof as a DOT graph of the
+// subtrees its hashes cover.
+func LogConsistencyDOT(w io.Writer, first, second int64, proof [][]byte) {
+	fmt.Fprintf(w, "digraph log_consistency {\n")
+	fmt.Fprintf(w, "  node [shape=box, fontname=monospace];\n")
+	fmt.Fprintf(w, "  root [label=\"root\\nsize %d\"];\n", second)
+	fmt.Fprintf(w, "  target [label=\"old root\\nsize %d\", shape=ellipse];\n", first)
+	var ranges []leafRange
+	if first > 0 && first < second {
+		ranges = consistencyRanges(first, 0, second, true)
+	}
+	writeLogDOT(w, ranges, proof)
+	fmt.Fprintf(w, "}\n")
+}
+
+// writeLogDOT links each proof hash to the root and to the target of the
+// proof. Hashes are listed from the target up, as in the proof.
+func writeLogDOT(w io.Writer, ranges []leafRange, proof [][]byte) {
+	for i, hash := range proof {
+		label := "unexpected"
+		if i < len(ranges) {
+			label = ranges[i].String()
+		}
+		fmt.Fprintf(w, "  p%d [label=\"%d: %v\\n%x\", style=filled, fillcolor=lightgrey];\n", i, i, label, short(hash))
+		fmt.Fprintf(w, "  root -> p%d;\n", i)
+	}
+	if len(proof) > 0 {
+		fmt.Fprintf(w, "  p0 -> target [style=dashed];\n")
+	}
+}
+
+// short returns the prefix of h shown in graph labels.
+func short(h []byte) []byte {
+	if len(h) > hashPrefix {
+		return h[:hashPrefix]
+	}
+	return h
+}
@@ -0,0 +1,238 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ktdebug renders proofs returned by the key server as annotated
+// text and as DOT graphs of their Merkle paths. It is meant for debugging
+// verification failures and performs no verification itself.
+package ktdebug
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// hashPrefix is the number of bytes of a hash shown in DOT graph labels.
+const hashPrefix = 4
+
+// Entry writes the proofs of resp. firstTreeSize is the size of the log
+// root the request was made relative to, which the consistency proof in
+// resp starts from.
+func Entry(w io.Writer, resp *pb.GetEntryResponse, firstTreeSize int64) {
+	fmt.Fprintf(w, "Entry\n")
+	fmt.Fprintf(w, "  committed:        %v\n", committed(resp))
+	fmt.Fprintf(w, "  endorsements:     %v\n", len(resp.GetEndorsements()))
+	VRFProof(w, resp.GetVrfProof())
+	MapInclusion(w, resp.GetLeafProof())
+	MapRoot(w, resp.GetSmr())
+	LogRoot(w, resp.GetLogRoot())
+	LogInclusion(w, resp.GetSmr().GetMapRevision(), resp.GetLogRoot().GetTreeSize(), resp.GetLogInclusion())
+	LogConsistency(w, firstTreeSize, resp.GetLogRoot().GetTreeSize(), resp.GetLogConsistency())
+}
+
+func committed(resp *pb.GetEntryResponse) string {
+	switch {
+	case resp.GetCommittedPurged():
+		return "purged"
+	case resp.GetCommitted() == nil:
+		return "none"
+	default:
+		return fmt.Sprintf("%v bytes of data, key %x", len(resp.GetCommitted().GetData()), resp.GetCommitted().GetKey())
+	}
+}
+
+// VRFProof writes a VRF proof.
+func VRFProof(w io.Writer, proof []byte) {
+	fmt.Fprintf(w, "VRF proof (%v bytes)\n", len(proof))
+	for i := 0; i < len(proof); i += 32 {
+		end := i + 32
+		if end > len(proof) {
+			end = len(proof)
+		}
+		fmt.Fprintf(w, "  %4d: %x\n", i, proof[i:end])
+	}
+}
+
+// MapRoot writes a signed map root.
+func MapRoot(w io.Writer, smr *trillian.SignedMapRoot) {
+	fmt.Fprintf(w, "Map root\n")
+	if smr == nil {
+		fmt.Fprintf(w, "  missing\n")
+		return
+	}
+	fmt.Fprintf(w, "  map:              %v\n", smr.GetMapId())
+	fmt.Fprintf(w, "  revision:         %v\n", smr.GetMapRevision())
+	fmt.Fprintf(w, "  timestamp:        %v\n", timestamp(smr.GetTimestampNanos()))
+	fmt.Fprintf(w, "  root hash:        %x\n", smr.GetRootHash())
+	fmt.Fprintf(w, "  metadata:         %v\n", smr.GetMetadata().GetTypeUrl())
+	fmt.Fprintf(w, "  signature:        %v over %v, %v bytes\n",
+		smr.GetSignature().GetSignatureAlgorithm(), smr.GetSignature().GetHashAlgorithm(),
+		len(smr.GetSignature().GetSignature()))
+}
+
+// LogRoot writes a signed log root.
+func LogRoot(w io.Writer, root *trillian.SignedLogRoot) {
+	fmt.Fprintf(w, "Log root\n")
+	if root == nil {
+		fmt.Fprintf(w, "  missing\n")
+		return
+	}
+	fmt.Fprintf(w, "  tree size:        %v\n", root.GetTreeSize())
+	fmt.Fprintf(w, "  timestamp:        %v\n", timestamp(root.GetTimestampNanos()))
+	fmt.Fprintf(w, "  root hash:        %x\n", root.GetRootHash())
+	fmt.Fprintf(w, "  signature:        %v over %v, %v bytes\n",
+		root.GetSignature().GetSignatureAlgorithm(), root.GetSignature().GetHashAlgorithm(),
+		len(root.GetSignature().GetSignature()))
+}
+
+func timestamp(nanos int64) string {
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+}
+
+// MapInclusion writes a sparse Merkle tree inclusion proof. Empty siblings,
+// which stand for the hash of an empty subtree, are summarized.
+func MapInclusion(w io.Writer, inclusion *trillian.MapLeafInclusion) {
+	leaf := inclusion.GetLeaf()
+	fmt.Fprintf(w, "Map inclusion\n")
+	fmt.Fprintf(w, "  index:            %x\n", leaf.GetIndex())
+	fmt.Fprintf(w, "  leaf hash:        %x\n", leaf.GetLeafHash())
+	fmt.Fprintf(w, "  leaf value:       %v bytes\n", len(leaf.GetLeafValue()))
+	siblings := mapSiblings(leaf.GetIndex(), inclusion.GetInclusion())
+	fmt.Fprintf(w, "  siblings:         %v of %v are not empty\n", len(siblings), len(inclusion.GetInclusion()))
+	for _, s := range siblings {
+		fmt.Fprintf(w, "    height %3d %-5v %x\n", s.height, s.side, s.hash)
+	}
+	if bits := 8 * len(leaf.GetIndex()); bits != len(inclusion.GetInclusion()) {
+		fmt.Fprintf(w, "  warning:          %v siblings for a %v bit index\n", len(inclusion.GetInclusion()), bits)
+	}
+}
+
+// mapSibling is a non-empty sibling on the path from a map leaf to the root.
+type mapSibling struct {
+	height int // Zero for the sibling of the leaf.
+	side   string
+	hash   []byte
+}
+
+// mapSiblings returns the non-empty entries of proof, which holds the
+// sibling of each node on the path from the leaf at index to the root,
+// starting with the sibling of the leaf.
+func mapSiblings(index []byte, proof [][]byte) []mapSibling {
+	var siblings []mapSibling
+	for height, hash := range proof {
+		if len(hash) == 0 {
+			continue
+		}
+		side := "?"
+		if depth := len(proof) - 1 - height; depth/8 < len(index) {
+			if index[depth/8]>>uint(7-depth%8)&1 == 0 {
+				side = "right"
+			} else {
+				side = "left"
+			}
+		}
+		siblings = append(siblings, mapSibling{height: height, side: side, hash: hash})
+	}
+	return siblings
+}
+
+// LogInclusion writes a proof that leaf index is included in a log of
+// treeSize leaves, labelling each hash with the leaves it covers.
+func LogInclusion(w io.Writer, index, treeSize int64, proof [][]byte) {
+	fmt.Fprintf(w, "Log inclusion of leaf %v in tree of size %v\n", index, treeSize)
+	writeLogProof(w, inclusionRanges(index, 0, treeSize), proof)
+}
+
+// LogConsistency writes a proof that the log of size first is a prefix of
+// the log of size second, labelling each hash with the leaves it covers.
+func LogConsistency(w io.Writer, first, second int64, proof [][]byte) {
+	fmt.Fprintf(w, "Log consistency from size %v to %v\n", first, second)
+	var ranges []leafRange
+	if first > 0 && first < second {
+		ranges = consistencyRanges(first, 0, second, true)
+	}
+	writeLogProof(w, ranges, proof)
+}
+
+func writeLogProof(w io.Writer, ranges []leafRange, proof [][]byte) {
+	for i, hash := range proof {
+		label := "unexpected"
+		if i < len(ranges) {
+			label = ranges[i].String()
+		}
+		fmt.Fprintf(w, "  %3d %-16v %x\n", i, label, hash)
+	}
+	if len(proof) < len(ranges) {
+		fmt.Fprintf(w, "  missing:          %v hashes\n", len(ranges)-len(proof))
+	}
+}
+
+// leafRange is the subtree of a log that covers leaves [begin, end).
+type leafRange struct {
+	begin, end int64
+}
+
+func (r leafRange) String() string {
+	if r.end-r.begin == 1 {
+		return fmt.Sprintf("leaf %v", r.begin)
+	}
+	return fmt.Sprintf("leaves %v-%v", r.begin, r.end-1)
+}
+
+// split returns the size of the left subtree of a tree of n > 1 leaves,
+// which is the largest power of two smaller than n.
+func split(n int64) int64 {
+	k := int64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// inclusionRanges returns the subtrees whose hashes make up the inclusion
+// proof of leaf m in leaves [begin, end), in proof order (RFC 6962, 2.1.1).
+func inclusionRanges(m, begin, end int64) []leafRange {
+	n := end - begin
+	if m < begin || m >= end || n <= 1 {
+		return nil
+	}
+	k := split(n)
+	if m-begin < k {
+		return append(inclusionRanges(m, begin, begin+k), leafRange{begin + k, end})
+	}
+	return append(inclusionRanges(m, begin+k, end), leafRange{begin, begin + k})
+}
+
+// consistencyRanges returns the subtrees whose hashes make up the
+// consistency proof between the first m leaves of [begin, end) and all of
+// them, in proof order (RFC 6962, 2.1.2). complete is true while the first
+// m leaves form a subtree whose hash the verifier already knows.
+func consistencyRanges(m, begin, end int64, complete bool) []leafRange {
+	n := end - begin
+	if m == n {
+		if complete {
+			return nil
+		}
+		return []leafRange{{begin, end}}
+	}
+	k := split(n)
+	if m <= k {
+		return append(consistencyRanges(m, begin, begin+k, complete), leafRange{begin + k, end})
+	}
+	return append(consistencyRanges(m-k, begin+k, end, false), leafRange{begin, begin + k})
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ktdebug
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestInclusionRanges(t *testing.T) {
+	for _, tc := range []struct {
+		index, size int64
+		want        []leafRange
+	}{
+		{index: 0, size: 1},
+		{index: 0, size: 2, want: []leafRange{{1, 2}}},
+		{index: 3, size: 7, want: []leafRange{{2, 3}, {0, 2}, {4, 7}}},
+		{index: 6, size: 7, want: []leafRange{{4, 6}, {0, 4}}},
+		{index: 7, size: 7},
+	} {
+		if got := inclusionRanges(tc.index, 0, tc.size); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("inclusionRanges(%v, %v): %v, want %v", tc.index, tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestConsistencyRanges(t *testing.T) {
+	// Examples from RFC 6962, section 2.1.3.
+	for _, tc := range []struct {
+		first, second int64
+		want          []leafRange
+	}{
+		{first: 3, second: 7, want: []leafRange{{2, 3}, {3, 4}, {0, 2}, {4, 7}}},
+		{first: 4, second: 7, want: []leafRange{{4, 7}}},
+		{first: 6, second: 7, want: []leafRange{{4, 6}, {6, 7}, {0, 4}}},
+	} {
+		if got := consistencyRanges(tc.first, 0, tc.second, true); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("consistencyRanges(%v, %v): %v, want %v", tc.first, tc.second, got, tc.want)
+		}
+	}
+}
+
+func TestMapSiblings(t *testing.T) {
+	index := []byte{0x80}
+	proof := make([][]byte, 8)
+	proof[0] = []byte{1} // Sibling of the leaf, whose last index bit is 0.
+	proof[7] = []byte{2} // Sibling of the root's child, whose bit is 1.
+	want := []mapSibling{
+		{height: 0, side: "right", hash: []byte{1}},
+		{height: 7, side: "left", hash: []byte{2}},
+	}
+	if got := mapSiblings(index, proof); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapSiblings(): %v, want %v", got, want)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	proof := make([][]byte, 8)
+	proof[3] = []byte{0xaa, 0xbb}
+	resp := &pb.GetEntryResponse{
+		VrfProof: []byte{1, 2, 3},
+		LeafProof: &trillian.MapLeafInclusion{
+			Leaf:      &trillian.MapLeaf{Index: []byte{0x0f}, LeafHash: []byte{0xcc}},
+			Inclusion: proof,
+		},
+		Smr:          &trillian.SignedMapRoot{MapRevision: 3, RootHash: []byte{0xdd}},
+		LogRoot:      &trillian.SignedLogRoot{TreeSize: 7},
+		LogInclusion: [][]byte{{1}, {2}, {3}},
+	}
+	var text bytes.Buffer
+	Entry(&text, resp, 0)
+	for _, want := range []string{
+		"1 of 8 are not empty",
+		"height   3 left  aabb",
+		"Log inclusion of leaf 3 in tree of size 7",
+		"leaves 0-1",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Entry(): %q does not contain %q", text.String(), want)
+		}
+	}
+
+	var dot bytes.Buffer
+	MapInclusionDOT(&dot, resp.GetLeafProof(), resp.GetSmr().GetRootHash())
+	for _, want := range []string{
+		"root -> n4 [label=\"4 levels\"];",
+		"n4 -> s3 [style=dashed];",
+		"n4 -> leaf [label=\"4 levels\"];",
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("MapInclusionDOT(): %q does not contain %q", dot.String(), want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ktmetrics exports the metrics of kt.Verifier to Prometheus.
+package ktmetrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/google/keytransparency/core/client/kt"
+)
+
+// Results of a check, as reported in the result label.
+const (
+	resultPass = "pass"
+	resultFail = "fail"
+)
+
+// Prometheus implements kt.Metrics with a counter of the checks that passed
+// and failed, and a histogram of their latency, both labeled by check.
+type Prometheus struct {
+	checks  *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewPrometheus returns metrics named after namespace, e.g. "kt_client" or
+// "kt_monitor", registered with r.
+func NewPrometheus(r prometheus.Registerer, namespace string) (*Prometheus, error) {
+	p := &Prometheus{
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "verification_checks",
+			Help:      "Number of verification checks run, by check and result.",
+		}, []string{"check", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "verification_check_seconds",
+			Help:      "Seconds spent in verification checks, by check.",
+			Buckets:   []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, math.Inf(1)},
+		}, []string{"check"}),
+	}
+	if err := r.Register(p.checks); err != nil {
+		return nil, err
+	}
+	if err := r.Register(p.latency); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ObserveCheck implements kt.Metrics.
+func (p *Prometheus) ObserveCheck(c kt.Check, err error, d time.Duration) {
+	result := resultPass
+	if err != nil {
+		result = resultFail
+	}
+	p.checks.WithLabelValues(string(c), result).Inc()
+	p.latency.WithLabelValues(string(c)).Observe(d.Seconds())
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ktmetrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/google/keytransparency/core/client/kt"
+)
+
+func TestPrometheus(t *testing.T) {
+	r := prometheus.NewRegistry()
+	p, err := NewPrometheus(r, "kt_test")
+	if err != nil {
+		t.Fatalf("NewPrometheus(): %v", err)
+	}
+	p.ObserveCheck(kt.CheckVRF, nil, time.Millisecond)
+	p.ObserveCheck(kt.CheckVRF, nil, time.Millisecond)
+	p.ObserveCheck(kt.CheckVRF, errors.New("bad proof"), time.Millisecond)
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatalf("Gather(): %v", err)
+	}
+	counts := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "kt_test_verification_checks" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			counts[labels["check"]+"/"+labels["result"]] = m.GetCounter().GetValue()
+		}
+	}
+	for key, want := range map[string]float64{"vrf/pass": 2, "vrf/fail": 1} {
+		if got := counts[key]; got != want {
+			t.Errorf("verification_checks{%v}: %v, want %v", key, got, want)
+		}
+	}
+
+	if _, err := NewPrometheus(r, "kt_test"); err == nil {
+		t.Errorf("NewPrometheus() registered twice: nil, want error")
+	}
+}
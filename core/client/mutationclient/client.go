@@ -22,6 +22,7 @@ package mutationclient
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/golang/glog"
@@ -36,10 +37,28 @@ import (
 // size 16 will contain about 8KB of data.
 const pageSize = 16
 
+const (
+	// maxBackoff is the default ratio between MaxPollPeriod and the poll
+	// period.
+	maxBackoff = 16
+	// defaultMaxErrors is the default number of consecutive transient errors
+	// tolerated by StreamEpochs.
+	defaultMaxErrors = 5
+)
+
 // Client queries the server side mutations API.
 type Client struct {
 	client     pb.KeyTransparencyClient
 	pollPeriod time.Duration
+	// MaxPollPeriod bounds the time between polls when no new epochs are
+	// available or when requests fail.
+	MaxPollPeriod time.Duration
+	// MaxErrors is the number of consecutive transient errors StreamEpochs
+	// tolerates before it gives up.
+	MaxErrors int
+	// UseStream makes StreamEpochs receive epochs from GetEpochStream, and
+	// fall back to polling if the server does not implement it.
+	UseStream bool
 }
 
 // EpochMutations contains all the mutations needed to advance
@@ -52,37 +71,147 @@ type EpochMutations struct {
 // New initializes a new mutations API monitoring client.
 func New(client pb.KeyTransparencyClient, pollPeriod time.Duration) *Client {
 	return &Client{
-		client:     client,
-		pollPeriod: pollPeriod,
+		client:        client,
+		pollPeriod:    pollPeriod,
+		MaxPollPeriod: maxBackoff * pollPeriod,
+		MaxErrors:     defaultMaxErrors,
+	}
+}
+
+// backoff computes the time to wait between polls. The wait doubles every
+// time nothing new is found, up to max, and is reset once the client makes
+// progress.
+type backoff struct {
+	min, max, cur time.Duration
+}
+
+// next returns the time to wait before the next poll and increases the wait
+// for the following one.
+func (b *backoff) next() time.Duration {
+	d := b.cur
+	if d < b.min {
+		d = b.min
+	}
+	b.cur = 2 * d
+	if b.cur > b.max {
+		b.cur = b.max
+	}
+	return d
+}
+
+// reset returns to polling every min.
+func (b *backoff) reset() {
+	b.cur = b.min
+}
+
+// isTransient returns true for errors that are likely to go away on retry.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait returns nil after d, or ctx.Err() if ctx is done first.
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
 }
 
 // StreamEpochs repeatedly fetches epochs and sends them to out until GetEpoch
-// returns an error other than NotFound or until ctx.Done is closed.  When
-// GetEpoch returns NotFound, it waits one pollPeriod before trying again.
+// returns a permanent error, until more than MaxErrors consecutive transient
+// errors occur, or until ctx.Done is closed.
+//
+// While the client is behind, epochs are fetched back to back. When GetEpoch
+// returns NotFound or a transient error, the time between polls doubles from
+// the poll period up to MaxPollPeriod.
+//
+// If UseStream is set, epochs are received from GetEpochStream instead, and
+// polling resumes if the server does not implement it or the stream ends.
 func (c *Client) StreamEpochs(ctx context.Context, domainID string, startEpoch int64, out chan<- *pb.Epoch) error {
 	defer close(out)
-	wait := time.NewTicker(c.pollPeriod).C
-	for i := startEpoch; ; {
-		// time out if we exceed the poll period:
+	next := startEpoch
+	if c.UseStream {
+		var err error
+		next, err = c.receiveEpochs(ctx, domainID, startEpoch, next, out)
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			glog.Infof("GetEpochStream is not supported, polling GetEpoch instead")
+		case err != nil && !isTransient(err):
+			return err
+		}
+	}
+	return c.pollEpochs(ctx, domainID, startEpoch, next, out)
+}
+
+// receiveEpochs sends epochs received from GetEpochStream to out, starting at
+// epoch next. It returns the next epoch to fetch, and io.EOF if the server
+// closed the stream.
+func (c *Client) receiveEpochs(ctx context.Context, domainID string, startEpoch, next int64, out chan<- *pb.Epoch) (int64, error) {
+	stream, err := c.client.GetEpochStream(ctx, &pb.GetEpochRequest{
+		DomainId:      domainID,
+		Epoch:         next,
+		FirstTreeSize: startEpoch,
+	})
+	if err != nil {
+		return next, err
+	}
+	for {
+		epoch, err := stream.Recv()
+		if err == io.EOF {
+			return next, nil
+		} else if err != nil {
+			return next, err
+		}
+		select {
+		case <-ctx.Done():
+			return next, ctx.Err()
+		case out <- epoch:
+			next++
+		}
+	}
+}
+
+// pollEpochs sends epochs fetched with GetEpoch to out, starting at epoch i.
+func (c *Client) pollEpochs(ctx context.Context, domainID string, startEpoch, i int64, out chan<- *pb.Epoch) error {
+	poll := &backoff{min: c.pollPeriod, max: c.MaxPollPeriod}
+	failures := 0
+	for {
 		epoch, err := c.client.GetEpoch(ctx, &pb.GetEpochRequest{
 			DomainId:      domainID,
 			Epoch:         i,
 			FirstTreeSize: startEpoch,
 		})
-		// If this epoch was not found, wait and retry.
-		if s, _ := status.FromError(err); s.Code() == codes.NotFound {
+		switch {
+		case status.Code(err) == codes.NotFound:
+			// This epoch does not exist yet. Wait and retry.
+			failures = 0
 			glog.Infof("Waiting for a new epoch to appear")
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-wait:
-				continue
+			if err := wait(ctx, poll.next()); err != nil {
+				return err
 			}
-		} else if err != nil {
+			continue
+		case isTransient(err) && failures < c.MaxErrors:
+			failures++
+			glog.Warningf("GetEpoch(%v,%v): %v, retrying", domainID, i, err)
+			if err := wait(ctx, poll.next()); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
 			glog.Warningf("GetEpoch(%v,%v): %v", domainID, i, err)
 			return err
 		}
+		failures = 0
+		poll.reset()
 
 		select {
 		case <-ctx.Done():
@@ -105,7 +234,7 @@ func (c *Client) EpochMutations(ctx context.Context, epoch *pb.Epoch) ([]*pb.Mut
 			PageToken: token,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("GetMutations(%v, %v): %v", epoch.GetDomainId(), pageSize, err)
+			return nil, fmt.Errorf("GetMutations(%v, %v): %w", epoch.GetDomainId(), pageSize, err)
 		}
 		mutations = append(mutations, resp.GetMutations()...)
 		token = resp.GetNextPageToken()
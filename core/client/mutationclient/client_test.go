@@ -0,0 +1,110 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutationclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// fakeEpochs serves epochs [0, latest] and then fails with err.
+type fakeEpochs struct {
+	pb.KeyTransparencyClient
+	latest    int64
+	err       error
+	streamErr error
+}
+
+func (f *fakeEpochs) GetEpoch(ctx context.Context, in *pb.GetEpochRequest, opts ...grpc.CallOption) (*pb.Epoch, error) {
+	if in.GetEpoch() > f.latest {
+		return nil, f.err
+	}
+	return &pb.Epoch{
+		DomainId: in.GetDomainId(),
+		Smr:      &trillian.SignedMapRoot{MapRevision: in.GetEpoch()},
+	}, nil
+}
+
+func (f *fakeEpochs) GetEpochStream(ctx context.Context, in *pb.GetEpochRequest, opts ...grpc.CallOption) (pb.KeyTransparency_GetEpochStreamClient, error) {
+	return nil, f.streamErr
+}
+
+func TestStreamEpochs(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		err       error
+		streamErr error
+		useStream bool
+		wantCode  codes.Code
+	}{
+		{desc: "permanent error", err: status.Error(codes.Internal, "boom"), wantCode: codes.Internal},
+		{desc: "transient errors", err: status.Error(codes.Unavailable, "down"), wantCode: codes.Unavailable},
+		{desc: "no new epochs", err: status.Error(codes.NotFound, "none"), wantCode: codes.DeadlineExceeded},
+		{desc: "stream unimplemented", err: status.Error(codes.Internal, "boom"), useStream: true,
+			streamErr: status.Error(codes.Unimplemented, "no"), wantCode: codes.Internal},
+		{desc: "stream failed", err: status.Error(codes.Internal, "boom"), useStream: true,
+			streamErr: status.Error(codes.PermissionDenied, "no"), wantCode: codes.PermissionDenied},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		f := &fakeEpochs{latest: 3, err: tc.err, streamErr: tc.streamErr}
+		c := New(f, time.Millisecond)
+		c.MaxErrors = 2
+		c.UseStream = tc.useStream
+
+		out := make(chan *pb.Epoch)
+		errc := make(chan error)
+		go func() { errc <- c.StreamEpochs(ctx, "domain", 1, out) }()
+		var got []int64
+		for e := range out {
+			got = append(got, e.GetSmr().GetMapRevision())
+		}
+		err := <-errc
+		cancel()
+
+		if err == context.DeadlineExceeded {
+			err = status.Error(codes.DeadlineExceeded, err.Error())
+		}
+		if status.Code(err) != tc.wantCode {
+			t.Errorf("%v: StreamEpochs(): %v, want %v", tc.desc, err, tc.wantCode)
+		}
+		if tc.wantCode == codes.PermissionDenied {
+			continue
+		}
+		if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+			t.Errorf("%v: StreamEpochs() sent epochs %v, want [1 2 3]", tc.desc, got)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := &backoff{min: time.Second, max: 5 * time.Second}
+	for _, want := range []time.Duration{1, 2, 4, 5, 5} {
+		if got := b.next(); got != want*time.Second {
+			t.Errorf("next(): %v, want %v", got, want*time.Second)
+		}
+	}
+	b.reset()
+	if got := b.next(); got != time.Second {
+		t.Errorf("next() after reset(): %v, want %v", got, time.Second)
+	}
+}
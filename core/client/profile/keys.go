@@ -0,0 +1,134 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// RegisterKeyProfile sets the profile type of appID to pb.KeyProfile,
+// validated with ValidateKeyProfile.
+func RegisterKeyProfile(r *Registry, appID string) {
+	r.Register(appID, &pb.KeyProfile{}, ValidateKeyProfile)
+}
+
+// ValidateKeyProfile returns an error unless m is a *pb.KeyProfile whose
+// keys have distinct, non-empty key IDs and a public key.
+func ValidateKeyProfile(m proto.Message) error {
+	p, ok := m.(*pb.KeyProfile)
+	if !ok {
+		return ErrWrongType
+	}
+	ids := make(map[string]bool)
+	for _, k := range p.GetKeys() {
+		switch {
+		case k.GetKeyId() == "":
+			return errors.New("key without key_id")
+		case ids[k.GetKeyId()]:
+			return fmt.Errorf("duplicate key_id %q", k.GetKeyId())
+		case len(k.GetPublicKey().GetDer()) == 0:
+			return fmt.Errorf("key %q without public_key", k.GetKeyId())
+		}
+		ids[k.GetKeyId()] = true
+	}
+	return nil
+}
+
+// FindKey returns the key of p with keyID, or nil.
+func FindKey(p *pb.KeyProfile, keyID string) *pb.ProfileKey {
+	for _, k := range p.GetKeys() {
+		if k.GetKeyId() == keyID {
+			return k
+		}
+	}
+	return nil
+}
+
+// AddKey returns a copy of p with k added, replacing the key with the same
+// key ID, if any.
+func AddKey(p *pb.KeyProfile, k *pb.ProfileKey) *pb.KeyProfile {
+	out := RemoveKey(p, k.GetKeyId())
+	out.Keys = append(out.Keys, proto.Clone(k).(*pb.ProfileKey))
+	return out
+}
+
+// RemoveKey returns a copy of p without the key with keyID.
+func RemoveKey(p *pb.KeyProfile, keyID string) *pb.KeyProfile {
+	out := &pb.KeyProfile{}
+	for _, k := range p.GetKeys() {
+		if k.GetKeyId() != keyID {
+			out.Keys = append(out.Keys, proto.Clone(k).(*pb.ProfileKey))
+		}
+	}
+	return out
+}
+
+// ValidKeys returns the keys of p that may be used for use and have not
+// expired at now. Keys with an unparsable expiration are skipped.
+func ValidKeys(p *pb.KeyProfile, use string, now time.Time) []*pb.ProfileKey {
+	var keys []*pb.ProfileKey
+	for _, k := range p.GetKeys() {
+		if !hasUse(k, use) {
+			continue
+		}
+		if k.GetExpiration() != nil {
+			exp, err := ptypes.Timestamp(k.GetExpiration())
+			if err != nil || !now.Before(exp) {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func hasUse(k *pb.ProfileKey, use string) bool {
+	for _, u := range k.GetUses() {
+		if u == use {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns the profile that applies the changes made by one device,
+// from base to local, to remote, the profile written concurrently by another
+// device from the same base. Keys added or changed in local are added to
+// remote, replacing remote's version, and keys removed in local are removed
+// from remote. Other keys of remote are kept.
+func Merge(base, local, remote *pb.KeyProfile) *pb.KeyProfile {
+	out := &pb.KeyProfile{}
+	if remote != nil {
+		out = proto.Clone(remote).(*pb.KeyProfile)
+	}
+	for _, k := range base.GetKeys() {
+		if FindKey(local, k.GetKeyId()) == nil {
+			out = RemoveKey(out, k.GetKeyId())
+		}
+	}
+	for _, k := range local.GetKeys() {
+		if b := FindKey(base, k.GetKeyId()); b == nil || !proto.Equal(b, k) {
+			out = AddKey(out, k)
+		}
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func key(id, der string, uses ...string) *pb.ProfileKey {
+	return &pb.ProfileKey{KeyId: id, PublicKey: &keyspb.PublicKey{Der: []byte(der)}, Uses: uses}
+}
+
+func keyIDs(p *pb.KeyProfile) []string {
+	var ids []string
+	for _, k := range p.GetKeys() {
+		ids = append(ids, k.GetKeyId())
+	}
+	return ids
+}
+
+func TestValidateKeyProfile(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		m       proto.Message
+		wantErr bool
+	}{
+		{desc: "empty", m: &pb.KeyProfile{}},
+		{desc: "valid", m: &pb.KeyProfile{Keys: []*pb.ProfileKey{key("a", "1"), key("b", "2")}}},
+		{desc: "wrong type", m: &pb.Committed{}, wantErr: true},
+		{desc: "no id", m: &pb.KeyProfile{Keys: []*pb.ProfileKey{key("", "1")}}, wantErr: true},
+		{desc: "no key", m: &pb.KeyProfile{Keys: []*pb.ProfileKey{key("a", "")}}, wantErr: true},
+		{desc: "duplicate", m: &pb.KeyProfile{Keys: []*pb.ProfileKey{key("a", "1"), key("a", "2")}}, wantErr: true},
+	} {
+		if err := ValidateKeyProfile(tc.m); (err != nil) != tc.wantErr {
+			t.Errorf("%v: ValidateKeyProfile(): %v, want error %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestAddRemoveKey(t *testing.T) {
+	p := &pb.KeyProfile{Keys: []*pb.ProfileKey{key("a", "1")}}
+	added := AddKey(p, key("b", "2"))
+	replaced := AddKey(added, key("a", "3"))
+	removed := RemoveKey(replaced, "b")
+
+	if got := len(p.GetKeys()); got != 1 {
+		t.Errorf("AddKey() modified its input: %v keys, want 1", got)
+	}
+	if got, want := keyIDs(added), []string{"a", "b"}; !equalIDs(got, want) {
+		t.Errorf("AddKey(): %v, want %v", got, want)
+	}
+	if got := FindKey(replaced, "a"); !proto.Equal(got, key("a", "3")) || len(replaced.GetKeys()) != 2 {
+		t.Errorf("AddKey(existing): %v, want the key replaced", replaced)
+	}
+	if got, want := keyIDs(removed), []string{"a"}; !equalIDs(got, want) {
+		t.Errorf("RemoveKey(): %v, want %v", got, want)
+	}
+}
+
+func TestValidKeys(t *testing.T) {
+	now := time.Now()
+	expired, err := ptypes.TimestampProto(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("TimestampProto(): %v", err)
+	}
+	later, err := ptypes.TimestampProto(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("TimestampProto(): %v", err)
+	}
+	old := key("old", "1", "encrypt")
+	old.Expiration = expired
+	current := key("current", "2", "encrypt", "sign")
+	current.Expiration = later
+	p := &pb.KeyProfile{Keys: []*pb.ProfileKey{old, current, key("forever", "3", "sign")}}
+
+	for _, tc := range []struct {
+		use  string
+		want []string
+	}{
+		{use: "encrypt", want: []string{"current"}},
+		{use: "sign", want: []string{"current", "forever"}},
+		{use: "auth"},
+	} {
+		if got := keyIDs(&pb.KeyProfile{Keys: ValidKeys(p, tc.use, now)}); !equalIDs(got, tc.want) {
+			t.Errorf("ValidKeys(%v): %v, want %v", tc.use, got, tc.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &pb.KeyProfile{Keys: []*pb.ProfileKey{key("phone", "1"), key("laptop", "2")}}
+	for _, tc := range []struct {
+		desc   string
+		local  *pb.KeyProfile
+		remote *pb.KeyProfile
+		want   *pb.KeyProfile
+	}{
+		{
+			desc:   "concurrent additions",
+			local:  AddKey(base, key("tablet", "3")),
+			remote: AddKey(base, key("desktop", "4")),
+			want:   &pb.KeyProfile{Keys: []*pb.ProfileKey{key("phone", "1"), key("laptop", "2"), key("desktop", "4"), key("tablet", "3")}},
+		},
+		{
+			desc:   "local removal",
+			local:  RemoveKey(base, "laptop"),
+			remote: AddKey(base, key("desktop", "4")),
+			want:   &pb.KeyProfile{Keys: []*pb.ProfileKey{key("phone", "1"), key("desktop", "4")}},
+		},
+		{
+			desc:   "remote removal kept",
+			local:  AddKey(base, key("tablet", "3")),
+			remote: RemoveKey(base, "phone"),
+			want:   &pb.KeyProfile{Keys: []*pb.ProfileKey{key("laptop", "2"), key("tablet", "3")}},
+		},
+		{
+			desc:   "local rotation",
+			local:  AddKey(base, key("phone", "5")),
+			remote: AddKey(base, key("phone", "6")),
+			want:   &pb.KeyProfile{Keys: []*pb.ProfileKey{key("laptop", "2"), key("phone", "5")}},
+		},
+		{
+			desc:  "no remote",
+			local: AddKey(base, key("tablet", "3")),
+			want:  &pb.KeyProfile{Keys: []*pb.ProfileKey{key("tablet", "3")}},
+		},
+	} {
+		if got := Merge(base, tc.local, tc.remote); !proto.Equal(got, tc.want) {
+			t.Errorf("%v: Merge(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
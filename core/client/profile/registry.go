@@ -0,0 +1,125 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package profile maps apps to the protobuf message types of their profiles,
+// so that clients can read and write profiles as messages rather than bytes.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrUnknownApp occurs when no profile type is registered for an app.
+	ErrUnknownApp = errors.New("profile: no type registered for app")
+	// ErrWrongType occurs when a profile is not of the type registered for
+	// its app.
+	ErrWrongType = errors.New("profile: wrong message type for app")
+)
+
+// ValidateFunc returns an error if a profile is not valid for its app.
+type ValidateFunc func(proto.Message) error
+
+// entry is the profile type of one app.
+type entry struct {
+	typ      reflect.Type
+	validate ValidateFunc
+}
+
+// Registry maps appIDs to the protobuf message types of their profiles.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]entry
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]entry)}
+}
+
+// Register sets the profile type of appID to the type of prototype, replacing
+// any previously registered type. Profiles are checked with validate, which
+// may be nil, after they are parsed and before they are marshaled.
+func (r *Registry) Register(appID string, prototype proto.Message, validate ValidateFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[appID] = entry{typ: reflect.TypeOf(prototype), validate: validate}
+}
+
+// lookup returns the profile type of appID.
+func (r *Registry) lookup(appID string) (entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.types[appID]
+	if !ok {
+		return entry{}, ErrUnknownApp
+	}
+	return e, nil
+}
+
+// New returns an empty profile of the type registered for appID.
+func (r *Registry) New(appID string) (proto.Message, error) {
+	e, err := r.lookup(appID)
+	if err != nil {
+		return nil, err
+	}
+	return reflect.New(e.typ.Elem()).Interface().(proto.Message), nil
+}
+
+// Unmarshal parses and validates the profile data of appID.
+func (r *Registry) Unmarshal(appID string, data []byte) (proto.Message, error) {
+	e, err := r.lookup(appID)
+	if err != nil {
+		return nil, err
+	}
+	m := reflect.New(e.typ.Elem()).Interface().(proto.Message)
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("profile: parsing %v profile: %w", appID, err)
+	}
+	if err := e.check(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Marshal validates and serializes a profile of appID.
+func (r *Registry) Marshal(appID string, m proto.Message) ([]byte, error) {
+	e, err := r.lookup(appID)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(m) != e.typ {
+		return nil, ErrWrongType
+	}
+	if err := e.check(m); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+// check runs the validator of e on m.
+func (e entry) check(m proto.Message) error {
+	if e.validate == nil {
+		return nil
+	}
+	if err := e.validate(m); err != nil {
+		return fmt.Errorf("profile: invalid profile: %w", err)
+	}
+	return nil
+}
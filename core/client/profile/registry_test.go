@@ -0,0 +1,86 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// requireKey rejects commitments without a key.
+func requireKey(m proto.Message) error {
+	if len(m.(*pb.Committed).GetKey()) == 0 {
+		return errors.New("missing key")
+	}
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("app", &pb.Committed{}, requireKey)
+	r.Register("quota", &pb.MutationQuota{}, nil)
+
+	valid := &pb.Committed{Key: []byte("key"), Data: []byte("data")}
+	invalid, err := proto.Marshal(&pb.Committed{Data: []byte("data")})
+	if err != nil {
+		t.Fatalf("proto.Marshal(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		appID   string
+		m       proto.Message
+		wantErr error
+	}{
+		{desc: "valid", appID: "app", m: valid},
+		{desc: "no validator", appID: "quota", m: &pb.MutationQuota{MutationsPerEpoch: 2}},
+		{desc: "unknown app", appID: "other", m: valid, wantErr: ErrUnknownApp},
+		{desc: "wrong type", appID: "quota", m: valid, wantErr: ErrWrongType},
+	} {
+		data, err := r.Marshal(tc.appID, tc.m)
+		if err != tc.wantErr {
+			t.Errorf("%v: Marshal(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		got, err := r.Unmarshal(tc.appID, data)
+		if err != nil {
+			t.Errorf("%v: Unmarshal(): %v", tc.desc, err)
+			continue
+		}
+		if !proto.Equal(got, tc.m) {
+			t.Errorf("%v: Unmarshal(): %v, want %v", tc.desc, got, tc.m)
+		}
+	}
+
+	if _, err := r.Marshal("app", &pb.Committed{}); err == nil {
+		t.Errorf("Marshal(invalid): nil, want error")
+	}
+	if _, err := r.Unmarshal("app", invalid); err == nil {
+		t.Errorf("Unmarshal(invalid): nil, want error")
+	}
+	if _, err := r.Unmarshal("app", []byte{0xff}); err == nil {
+		t.Errorf("Unmarshal(garbage): nil, want error")
+	}
+	if m, err := r.New("quota"); err != nil {
+		t.Errorf("New(): %v", err)
+	} else if _, ok := m.(*pb.MutationQuota); !ok {
+		t.Errorf("New(): %T, want *pb.MutationQuota", m)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot renders transparency snapshots as static sites.
+//
+// A site contains the snapshot itself, as a binary protobuf for offline
+// verification with the client and as JSON for other tools, and an HTML page
+// that summarizes it. Every proof needed to verify the entries is precomputed
+// in the snapshot, so the site can be served by any static host without query
+// infrastructure.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/mutator/entry"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Names of the files of a site.
+const (
+	ProtoFile = "snapshot.pb"
+	JSONFile  = "snapshot.json"
+	IndexFile = "index.html"
+)
+
+// WriteSite writes the files of a static site for s to dir, creating dir if
+// needed. Existing files are replaced. s should have been verified, for
+// instance by generating it with grpcc.Client.ExportSnapshot.
+func WriteSite(dir string, s *pb.TransparencySnapshot) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := proto.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("proto.Marshal(): %w", err)
+	}
+	js, err := (&jsonpb.Marshaler{Indent: "  "}).MarshalToString(s)
+	if err != nil {
+		return fmt.Errorf("jsonpb.Marshal(): %w", err)
+	}
+	index, err := renderIndex(s)
+	if err != nil {
+		return err
+	}
+	for name, data := range map[string][]byte{
+		ProtoFile: b,
+		JSONFile:  []byte(js),
+		IndexFile: index,
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("WriteFile(%v): %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot reads the snapshot of the site in dir.
+func ReadSnapshot(dir string) (*pb.TransparencySnapshot, error) {
+	path := filepath.Join(dir, ProtoFile)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile(%v): %w", path, err)
+	}
+	s := &pb.TransparencySnapshot{}
+	if err := proto.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal(): %w", err)
+	}
+	return s, nil
+}
+
+// entryView is an entry of the snapshot as shown on the index page.
+type entryView struct {
+	AppID, UserID string
+	Status        string
+	Commitment    string
+}
+
+// indexView is the data of the index page.
+type indexView struct {
+	DomainID     string
+	Revision     int64
+	MapRoot      string
+	LogSize      int64
+	LogRoot      string
+	RootTime     time.Time
+	CreateTime   time.Time
+	Endorsed     int
+	Entries      []entryView
+	ProtoFile    string
+	JSONFile     string
+	MapKey       string
+	LogKey       string
+	VRFKey       string
+	VRFAlgorithm pb.VrfAlgorithm
+}
+
+func renderIndex(s *pb.TransparencySnapshot) ([]byte, error) {
+	smr := s.GetEpoch().GetSmr()
+	created, err := ptypes.Timestamp(s.GetCreateTime())
+	if err != nil {
+		return nil, fmt.Errorf("invalid create_time: %w", err)
+	}
+	v := indexView{
+		DomainID:     s.GetDomain().GetDomainId(),
+		Revision:     smr.GetMapRevision(),
+		MapRoot:      hex.EncodeToString(smr.GetRootHash()),
+		LogSize:      s.GetEpoch().GetLogRoot().GetTreeSize(),
+		LogRoot:      hex.EncodeToString(s.GetEpoch().GetLogRoot().GetRootHash()),
+		RootTime:     time.Unix(0, smr.GetTimestampNanos()).UTC(),
+		CreateTime:   created.UTC(),
+		Endorsed:     len(s.GetEpoch().GetEndorsements()),
+		ProtoFile:    ProtoFile,
+		JSONFile:     JSONFile,
+		MapKey:       hex.EncodeToString(s.GetDomain().GetMap().GetPublicKey().GetDer()),
+		LogKey:       hex.EncodeToString(s.GetDomain().GetLog().GetPublicKey().GetDer()),
+		VRFKey:       hex.EncodeToString(s.GetDomain().GetVrf().GetDer()),
+		VRFAlgorithm: s.GetDomain().GetVrfAlgorithm(),
+	}
+	for _, e := range s.GetEntries() {
+		ev := entryView{AppID: e.GetAppId(), UserID: e.GetUserId()}
+		switch {
+		case e.GetEntry().GetCommittedPurged():
+			ev.Status = "purged"
+		case e.GetEntry().GetCommitted() == nil:
+			ev.Status = "absent"
+		default:
+			leaf, err := entry.FromLeafValue(e.GetEntry().GetLeafProof().GetLeaf().GetLeafValue())
+			if err != nil {
+				return nil, fmt.Errorf("%v/%v: %w", e.GetAppId(), e.GetUserId(), err)
+			}
+			ev.Status = "present"
+			ev.Commitment = hex.EncodeToString(leaf.GetCommitment())
+		}
+		v.Entries = append(v.Entries, ev)
+	}
+	var buf bytes.Buffer
+	if err := indexTmpl.Execute(&buf, v); err != nil {
+		return nil, fmt.Errorf("rendering %v: %w", IndexFile, err)
+	}
+	return buf.Bytes(), nil
+}
+
+var indexTmpl = template.Must(template.New(IndexFile).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Key Transparency snapshot of {{.DomainID}} at revision {{.Revision}}</title>
+</head>
+<body>
+<h1>Key Transparency snapshot of {{.DomainID}}</h1>
+<p>Generated {{.CreateTime.Format "2006-01-02 15:04:05 MST"}}.</p>
+
+<h2>Map root</h2>
+<table>
+<tr><th>Revision</th><td>{{.Revision}}</td></tr>
+<tr><th>Root hash</th><td><code>{{.MapRoot}}</code></td></tr>
+<tr><th>Signed</th><td>{{.RootTime.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th>Log size</th><td>{{.LogSize}}</td></tr>
+<tr><th>Log root hash</th><td><code>{{.LogRoot}}</code></td></tr>
+{{if .Endorsed}}<tr><th>Endorsements</th><td>{{.Endorsed}}</td></tr>{{end}}
+</table>
+
+<h2>Entries</h2>
+<table>
+<tr><th>App</th><th>User</th><th>Status</th><th>Commitment</th></tr>
+{{range .Entries}}<tr><td>{{.AppID}}</td><td>{{.UserID}}</td><td>{{.Status}}</td><td><code>{{.Commitment}}</code></td></tr>
+{{end}}</table>
+
+<h2>Verification</h2>
+<p>The snapshot contains the proofs of every entry above. To verify it
+without contacting the server, download <a href="{{.ProtoFile}}">{{.ProtoFile}}</a>
+and run:</p>
+<pre>keytransparency-client verify-snapshot {{.ProtoFile}}</pre>
+<p>The same data is available as <a href="{{.JSONFile}}">JSON</a>. Verification
+trusts the keys of the domain below; compare them with the keys you expect.</p>
+<table>
+<tr><th>Map public key</th><td><code>{{.MapKey}}</code></td></tr>
+<tr><th>Log public key</th><td><code>{{.LogKey}}</code></td></tr>
+<tr><th>VRF public key ({{.VRFAlgorithm}})</th><td><code>{{.VRFKey}}</code></td></tr>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,76 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestWriteSite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &pb.TransparencySnapshot{
+		Domain: &pb.Domain{DomainId: "domain"},
+		Epoch: &pb.Epoch{
+			Smr: &trillian.SignedMapRoot{MapRevision: 7, RootHash: []byte("root")},
+		},
+		Entries: []*pb.SnapshotEntry{
+			{AppId: "app", UserId: "alice", Entry: &pb.GetEntryResponse{}},
+			{AppId: "app", UserId: "bob", Entry: &pb.GetEntryResponse{CommittedPurged: true}},
+		},
+		CreateTime: ptypes.TimestampNow(),
+	}
+	if err := WriteSite(dir, s); err != nil {
+		t.Fatalf("WriteSite(): %v", err)
+	}
+
+	got, err := ReadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("ReadSnapshot(): %v", err)
+	}
+	if !proto.Equal(got, s) {
+		t.Errorf("ReadSnapshot(): %v, want %v", got, s)
+	}
+	index, err := ioutil.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	for _, want := range []string{"alice", "absent", "bob", "purged", ProtoFile} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("%v does not contain %q", IndexFile, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, JSONFile)); err != nil {
+		t.Errorf("Stat(%v): %v", JSONFile, err)
+	}
+
+	if err := WriteSite(dir, &pb.TransparencySnapshot{}); err == nil {
+		t.Errorf("WriteSite(no create_time): nil, want error")
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"crypto/sha512"
 	"encoding/binary"
 	"errors"
+	"hash"
+	"sync"
 )
 
 const (
@@ -42,8 +44,41 @@ var (
 	fixedKey = []byte{0x19, 0x6e, 0x7e, 0x52, 0x84, 0xa7, 0xef, 0x93, 0x0e, 0xcb, 0x9a, 0x19, 0x78, 0x74, 0x97, 0x55}
 	// ErrInvalidCommitment occurs when the commitment doesn't match the profile.
 	ErrInvalidCommitment = errors.New("invalid commitment")
+	// macs holds keyed HMACs and scratch space for reuse, since keying an
+	// HMAC and framing the message dominate the cost of verifying a
+	// commitment.
+	macs = sync.Pool{New: func() interface{} {
+		return &committer{mac: hmac.New(hashAlgo, fixedKey)}
+	}}
 )
 
+// committer computes commitments.
+type committer struct {
+	mac hash.Hash
+	buf []byte
+	sum [sha512.Size256]byte
+}
+
+// commit returns the commitment to data under userID and appID. The result
+// is only valid until c is reused.
+func (c *committer) commit(userID, appID string, data, nonce []byte) []byte {
+	var l [4]byte
+	b := append(c.buf[:0], prefix...)
+	b = append(b, nonce...)
+	binary.BigEndian.PutUint32(l[:], uint32(len(userID)))
+	b = append(b, l[:]...)
+	b = append(b, userID...)
+	binary.BigEndian.PutUint32(l[:], uint32(len(appID)))
+	b = append(b, l[:]...)
+	b = append(b, appID...)
+	c.buf = b
+
+	c.mac.Reset()
+	c.mac.Write(b)
+	c.mac.Write(data)
+	return c.mac.Sum(c.sum[:0])
+}
+
 // GenCommitmentKey generates a commitment key for use in Commit. This key must
 // be kept secret in order to prevent an adversary from learning what data has
 // been committed to by a commitment. To unseal and verify a commitment,
@@ -65,23 +100,16 @@ func GenCommitmentKey() ([]byte, error) {
 
 // Commit makes a cryptographic commitment under a specific userID to data.
 func Commit(userID, appID string, data, nonce []byte) []byte {
-	mac := hmac.New(hashAlgo, fixedKey)
-	mac.Write([]byte(prefix))
-	mac.Write(nonce)
-
-	// Message
-	binary.Write(mac, binary.BigEndian, uint32(len(userID)))
-	mac.Write([]byte(userID))
-	binary.Write(mac, binary.BigEndian, uint32(len(appID)))
-	mac.Write([]byte(appID))
-	mac.Write(data)
-
-	return mac.Sum(nil)
+	c := macs.Get().(*committer)
+	defer macs.Put(c)
+	return append([]byte(nil), c.commit(userID, appID, data, nonce)...)
 }
 
 // Verify customizes a commitment with a userID.
 func Verify(userID, appID string, commitment, data, nonce []byte) error {
-	if got, want := Commit(userID, appID, data, nonce),
+	c := macs.Get().(*committer)
+	defer macs.Put(c)
+	if got, want := c.commit(userID, appID, data, nonce),
 		commitment; !hmac.Equal(got, want) {
 		return ErrInvalidCommitment
 	}
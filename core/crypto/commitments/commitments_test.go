@@ -76,3 +76,26 @@ func dh(h string) []byte {
 	}
 	return result
 }
+
+func TestVerifyAllocs(t *testing.T) {
+	data := []byte("data")
+	c := Commit("alice", "app", data, zeroKey)
+	if got := testing.AllocsPerRun(100, func() {
+		if err := Verify("alice", "app", c, data, zeroKey); err != nil {
+			t.Fatal(err)
+		}
+	}); got > 0 {
+		t.Errorf("Verify() allocs: %v, want 0", got)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	data := []byte("data")
+	c := Commit("alice", "app", data, zeroKey)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Verify("alice", "app", c, data, zeroKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
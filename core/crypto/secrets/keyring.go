@@ -0,0 +1,128 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/crypto/sigpb"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// KeyVersion describes a key the Keyring has signed with.
+type KeyVersion struct {
+	// PublicKey is the DER encoded SubjectPublicKeyInfo of the key.
+	PublicKey []byte
+	// Activated is when the Keyring started signing with the key.
+	Activated time.Time
+	// Retired is when the Keyring stopped signing with the key. It is zero
+	// for the current key.
+	Retired time.Time
+}
+
+// Keyring signs with the key currently held by a Provider and remembers the
+// keys it signed with before, so that signatures made before a rotation can
+// still be verified.
+type Keyring struct {
+	provider Provider
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	signer   *tcrypto.Signer
+	versions []KeyVersion
+}
+
+// NewKeyring returns a Keyring signing with the current key of p.
+func NewKeyring(ctx context.Context, p Provider) (*Keyring, error) {
+	k := &Keyring{provider: p, now: time.Now}
+	if _, err := k.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Refresh fetches the key from the provider and starts signing with it if it
+// differs from the current key. It reports whether the key was rotated.
+func (k *Keyring) Refresh(ctx context.Context) (bool, error) {
+	key, err := k.provider.PrivateKey(ctx)
+	if err != nil {
+		return false, fmt.Errorf("secrets: fetching key: %w", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return false, fmt.Errorf("secrets: marshaling public key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if n := len(k.versions); n > 0 {
+		if bytes.Equal(k.versions[n-1].PublicKey, der) {
+			return false, nil
+		}
+		k.versions[n-1].Retired = k.now()
+	}
+	k.signer = tcrypto.NewSHA256Signer(key)
+	k.versions = append(k.versions, KeyVersion{PublicKey: der, Activated: k.now()})
+	return true, nil
+}
+
+// Run refreshes the key every period until ctx is done. Errors are logged and
+// signing continues with the current key.
+func (k *Keyring) Run(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		rotated, err := k.Refresh(ctx)
+		if err != nil {
+			glog.Errorf("Refreshing signing key: %v", err)
+			continue
+		}
+		if rotated {
+			glog.Infof("Rotated signing key")
+		}
+	}
+}
+
+// Signer returns a signer for the current key.
+func (k *Keyring) Signer() *tcrypto.Signer {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signer
+}
+
+// SignObject signs obj with the current key.
+func (k *Keyring) SignObject(obj interface{}) (*sigpb.DigitallySigned, error) {
+	return k.Signer().SignObject(obj)
+}
+
+// Keys returns the keys the Keyring has signed with, oldest first. The last
+// one is the current key.
+func (k *Keyring) Keys() []KeyVersion {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return append([]KeyVersion(nil), k.versions...)
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	key1, _ := newKey(t)
+	key2, _ := newKey(t)
+
+	var current crypto.Signer = key1
+	var fetchErr error
+	p := ProviderFunc(func(context.Context) (crypto.Signer, error) {
+		return current, fetchErr
+	})
+	k, err := NewKeyring(ctx, p)
+	if err != nil {
+		t.Fatalf("NewKeyring(): %v", err)
+	}
+	t0 := time.Unix(1000, 0)
+	k.now = func() time.Time { return t0 }
+
+	for _, tc := range []struct {
+		desc        string
+		key         crypto.Signer
+		fetchErr    error
+		wantRotated bool
+		wantErr     bool
+		wantKeys    int
+	}{
+		{desc: "unchanged", key: key1, wantKeys: 1},
+		{desc: "rotated", key: key2, wantRotated: true, wantKeys: 2},
+		{desc: "fetch error", key: key1, fetchErr: errors.New("unavailable"), wantErr: true, wantKeys: 2},
+		{desc: "rotated back", key: key1, wantRotated: true, wantKeys: 3},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			current, fetchErr = tc.key, tc.fetchErr
+			rotated, err := k.Refresh(ctx)
+			if got, want := err != nil, tc.wantErr; got != want {
+				t.Fatalf("Refresh(): %v, want err: %v", err, want)
+			}
+			if got, want := rotated, tc.wantRotated; got != want {
+				t.Errorf("Refresh(): %v, want %v", got, want)
+			}
+			keys := k.Keys()
+			if got, want := len(keys), tc.wantKeys; got != want {
+				t.Fatalf("len(Keys()): %v, want %v", got, want)
+			}
+			for i, v := range keys {
+				if current := i == len(keys)-1; v.Retired.IsZero() != current {
+					t.Errorf("Keys()[%v].Retired: %v, want zero: %v", i, v.Retired, current)
+				}
+			}
+
+			// Signatures must be made with the current key.
+			der, err := x509.MarshalPKIXPublicKey(k.Signer().Public())
+			if err != nil {
+				t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+			}
+			if !bytes.Equal(der, keys[len(keys)-1].PublicKey) {
+				t.Errorf("Signer() does not use the current key")
+			}
+			obj := struct{ A string }{"a"}
+			sig, err := k.SignObject(obj)
+			if err != nil {
+				t.Fatalf("SignObject(): %v", err)
+			}
+			if err := tcrypto.VerifyObject(k.Signer().Public(), obj, sig); err != nil {
+				t.Errorf("VerifyObject(): %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build pkcs11
+
+package secrets
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/google/trillian/crypto/keys/pkcs11"
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+func init() {
+	Register("pkcs11", openPKCS11)
+}
+
+// PKCS11 returns a Provider for a key held by a hardware security module.
+// The private key never leaves the module.
+func PKCS11(modulePath string, config *keyspb.PKCS11Config) Provider {
+	return ProviderFunc(func(context.Context) (crypto.Signer, error) {
+		return pkcs11.FromConfig(modulePath, config)
+	})
+}
+
+func openPKCS11(u *url.URL, _ string) (Provider, error) {
+	q := u.Query()
+	pubPath := q.Get("pubkey")
+	if u.Path == "" || q.Get("token") == "" || pubPath == "" {
+		return nil, errors.New("secrets: pkcs11 spec must set a module path, token and pubkey")
+	}
+	pub, err := ioutil.ReadFile(pubPath)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: reading pkcs11 public key: %w", err)
+	}
+	return PKCS11(u.Path, &keyspb.PKCS11Config{
+		TokenLabel: q.Get("token"),
+		Pin:        q.Get("pin"),
+		PublicKey:  string(pub),
+	}), nil
+}
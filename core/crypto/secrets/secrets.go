@@ -0,0 +1,139 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets loads the private keys that sign monitor and sequencer
+// artifacts from pluggable secret stores.
+//
+// Providers are selected with a spec string:
+//
+//	path/to/key.pem                   PEM file (also file:///path/to/key.pem)
+//	env:NAME                          PEM in environment variable NAME
+//	vault://host:port/secret/path#key PEM in field key of a Vault secret
+//	pkcs11:///path/to/module.so?token=label&pin=1234&pubkey=pub.pem (pkcs11 build tag)
+//
+// Other packages add providers with Register.
+package secrets
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian/crypto/keys/pem"
+)
+
+var (
+	// ErrUnknownScheme occurs when a spec names a scheme with no provider.
+	ErrUnknownScheme = errors.New("secrets: unknown provider scheme")
+	// ErrNotFound occurs when the secret store has no key at the location.
+	ErrNotFound = errors.New("secrets: key not found")
+)
+
+// Provider fetches a private key from a secret store. Providers read the
+// store on every call so that a key replaced in the store is picked up by
+// the next call.
+type Provider interface {
+	// PrivateKey returns the key currently held by the store.
+	PrivateKey(ctx context.Context) (crypto.Signer, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context) (crypto.Signer, error)
+
+// PrivateKey calls f(ctx).
+func (f ProviderFunc) PrivateKey(ctx context.Context) (crypto.Signer, error) {
+	return f(ctx)
+}
+
+// Opener returns the Provider described by u. password decrypts the key if
+// it is stored as an encrypted PEM.
+type Opener func(u *url.URL, password string) (Provider, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{
+		"file":  openFile,
+		"env":   openEnv,
+		"vault": openVault,
+	}
+)
+
+// Register makes a provider available to Open under scheme. It replaces any
+// provider previously registered under the same scheme.
+func Register(scheme string, o Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	openers[scheme] = o
+}
+
+// Open returns the Provider for spec. A spec without a scheme is a path to a
+// PEM file.
+func Open(spec, password string) (Provider, error) {
+	if !strings.Contains(spec, ":") {
+		return File(spec, password), nil
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: invalid spec %q: %w", spec, err)
+	}
+	openersMu.RLock()
+	o, ok := openers[u.Scheme]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%v: %q", ErrUnknownScheme, u.Scheme)
+	}
+	return o(u, password)
+}
+
+// File returns a Provider that reads a PEM encoded key from path.
+func File(path, password string) Provider {
+	return ProviderFunc(func(context.Context) (crypto.Signer, error) {
+		b, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		} else if err != nil {
+			return nil, err
+		}
+		return pem.UnmarshalPrivateKey(string(b), password)
+	})
+}
+
+func openFile(u *url.URL, password string) (Provider, error) {
+	return File(u.Path, password), nil
+}
+
+// Env returns a Provider that reads a PEM encoded key from the environment
+// variable name.
+func Env(name, password string) Provider {
+	return ProviderFunc(func(context.Context) (crypto.Signer, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			return nil, ErrNotFound
+		}
+		return pem.UnmarshalPrivateKey(v, password)
+	})
+}
+
+func openEnv(u *url.URL, password string) (Provider, error) {
+	if u.Opaque == "" {
+		return nil, errors.New("secrets: env spec must name a variable")
+	}
+	return Env(u.Opaque, password), nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newKey returns a new P256 key and its PEM encoding.
+func newKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey(): %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	key, keyPEM := newKey(t)
+
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("TempDir(): %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(path, []byte(keyPEM), 0600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	const envName = "SECRETS_TEST_KEY"
+	os.Setenv(envName, keyPEM)
+	defer os.Unsetenv(envName)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("X-Vault-Token"), "token"; got != want {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/v1key":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"key": keyPEM},
+			})
+		case "/v1/secret/data/v2key":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]string{"signing": keyPEM},
+					"metadata": map[string]int{"version": 3},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer vault.Close()
+	os.Setenv("VAULT_TOKEN", "token")
+	defer os.Unsetenv("VAULT_TOKEN")
+	vaultHost := vault.Listener.Addr().String()
+
+	for _, tc := range []struct {
+		spec    string
+		openErr bool
+		wantErr error
+	}{
+		{spec: path},
+		{spec: "file://" + path},
+		{spec: "env:" + envName},
+		{spec: "vault://" + vaultHost + "/secret/v1key?insecure=true"},
+		{spec: "vault://" + vaultHost + "/secret/data/v2key?insecure=true#signing"},
+		{spec: filepath.Join(dir, "missing.pem"), wantErr: ErrNotFound},
+		{spec: "env:SECRETS_TEST_MISSING", wantErr: ErrNotFound},
+		{spec: "vault://" + vaultHost + "/secret/missing?insecure=true", wantErr: ErrNotFound},
+		{spec: "vault://" + vaultHost + "/secret/v1key?insecure=true#missing", wantErr: ErrNotFound},
+		{spec: "env:", openErr: true},
+		{spec: "vault:///secret/v1key", openErr: true},
+		{spec: "pkcs11:///lib/module.so", openErr: true},
+		{spec: "unknown://foo", openErr: true},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			p, err := Open(tc.spec, "")
+			if got, want := err != nil, tc.openErr; got != want {
+				t.Fatalf("Open(): %v, want err: %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			signer, err := p.PrivateKey(ctx)
+			if got, want := err, tc.wantErr; got != want {
+				t.Fatalf("PrivateKey(): %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := signer.Public(), crypto.PublicKey(&key.PublicKey); !reflect.DeepEqual(got, want) {
+				t.Errorf("PrivateKey().Public(): %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	var got *url.URL
+	Register("test", func(u *url.URL, password string) (Provider, error) {
+		got = u
+		return Env(u.Opaque, password), nil
+	})
+	if _, err := Open("test:foo", ""); err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	if got == nil || got.Opaque != "foo" {
+		t.Errorf("Opener called with %v, want test:foo", got)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/trillian/crypto/keys/pem"
+)
+
+// VaultConfig locates a key stored in a HashiCorp Vault secret.
+type VaultConfig struct {
+	// Addr is the base URL of the Vault server, e.g. https://vault:8200.
+	Addr string
+	// Token authenticates to Vault. Defaults to $VAULT_TOKEN.
+	Token string
+	// Path is the secret's path, e.g. secret/data/monitor.
+	Path string
+	// Field is the field of the secret holding the PEM encoded key.
+	// Defaults to "key".
+	Field string
+	// Password decrypts the PEM encoded key.
+	Password string
+	// Client sends requests to Vault. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Vault returns a Provider that reads a PEM encoded key from Vault. Both
+// version 1 and version 2 of the key/value secrets engine are supported.
+func Vault(c VaultConfig) Provider {
+	if c.Token == "" {
+		c.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if c.Field == "" {
+		c.Field = "key"
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	return ProviderFunc(func(ctx context.Context) (crypto.Signer, error) {
+		v, err := c.read(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pem.UnmarshalPrivateKey(v, c.Password)
+	})
+}
+
+// read returns the configured field of the secret.
+func (c VaultConfig) read(ctx context.Context) (string, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(c.Addr, "/")+"/v1/"+strings.TrimPrefix(c.Path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrNotFound
+	default:
+		return "", fmt.Errorf("secrets: vault returned %v", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("secrets: decoding vault response: %w", err)
+	}
+	data := secret.Data
+	// KV version 2 nests the secret's fields under data.data.
+	if nested, ok := data["data"]; ok {
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return "", fmt.Errorf("secrets: decoding vault response: %w", err)
+		}
+	}
+	raw, ok := data[c.Field]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var v string
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("secrets: vault field %v is not a string", c.Field)
+	}
+	return v, nil
+}
+
+// openVault opens vault://host:port/path#field specs. The query parameter
+// insecure=true selects plain HTTP.
+func openVault(u *url.URL, password string) (Provider, error) {
+	if u.Host == "" || u.Path == "" {
+		return nil, errors.New("secrets: vault spec must set a host and a secret path")
+	}
+	scheme := "https"
+	if u.Query().Get("insecure") == "true" {
+		scheme = "http"
+	}
+	return Vault(VaultConfig{
+		Addr:     scheme + "://" + u.Host,
+		Path:     u.Path,
+		Field:    u.Fragment,
+		Password: password,
+	}), nil
+}
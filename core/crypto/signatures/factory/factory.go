@@ -52,7 +52,7 @@ func NewSignerFromPEM(pemKey []byte) (signatures.Signer, error) {
 func NewVerifierFromBytes(b []byte) (signatures.Verifier, error) {
 	k, err := x509.ParsePKIXPublicKey(b)
 	if err != nil {
-		return nil, fmt.Errorf("factory: could not parse public key: %v", err)
+		return nil, fmt.Errorf("factory: could not parse public key: %w", err)
 	}
 
 	switch pkType := k.(type) {
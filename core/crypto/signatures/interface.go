@@ -16,6 +16,7 @@
 package signatures
 
 import (
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/sha256"
@@ -61,6 +62,33 @@ type Signer interface {
 	PublicKeyPEM() ([]byte, error)
 }
 
+// RemoteSigner generates signatures with a single key that is held
+// elsewhere, such as in an HSM, a secure enclave or a signing service, so
+// that the private key never leaves it.
+type RemoteSigner interface {
+	// Sign asks for a digital signature of data. It blocks until the
+	// signature is available or ctx is done.
+	Sign(ctx context.Context, data interface{}) (*sigpb.DigitallySigned, error)
+	// KeyID returns the ID of the associated public key.
+	KeyID() string
+}
+
+// AsRemote returns a RemoteSigner that signs with s, so that local and
+// remote signers can be used together.
+func AsRemote(s Signer) RemoteSigner {
+	return localSigner{s}
+}
+
+type localSigner struct {
+	s Signer
+}
+
+func (l localSigner) Sign(_ context.Context, data interface{}) (*sigpb.DigitallySigned, error) {
+	return l.s.Sign(data)
+}
+
+func (l localSigner) KeyID() string { return l.s.KeyID() }
+
 // Verifier represents an object that can verify signatures with a single key.
 type Verifier interface {
 	// Verify checks the digital signature associated applied to data.
@@ -0,0 +1,401 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ed25519 implements a verifiable random function using edwards25519.
+package ed25519
+
+// ECVRF-EDWARDS25519-SHA512-TAI from
+// https://tools.ietf.org/html/draft-irtf-cfrg-vrf-06
+// Keys are Ed25519 keys from RFC 8032, encoded as in RFC 8410. The index is
+// the first 32 bytes of the 64 byte VRF output.
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/trillian/crypto/keyspb"
+
+	"filippo.io/edwards25519"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// SeedSize is the size of a private key seed.
+	SeedSize = 32
+	// encodedLen is the length of an encoded point or scalar.
+	encodedLen = 32
+	// ProofSize is the size of a proof: Gamma, c and s.
+	ProofSize = encodedLen + cLen + encodedLen
+
+	// cLen is the length of the challenge c.
+	cLen = 16
+	// suite identifies ECVRF-EDWARDS25519-SHA512-TAI in hashes.
+	suite = 0x03
+)
+
+var (
+	// oid identifies Ed25519 keys in PKIX and PKCS #8 structures.
+	oid = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+	// ErrInvalidKey occurs when a key is not a valid Ed25519 key.
+	ErrInvalidKey = errors.New("invalid Ed25519 key")
+	// ErrWrongKeyType occurs when a key is not an Ed25519 key.
+	ErrWrongKeyType = errors.New("not an Ed25519 key")
+	// ErrNoPEMFound occurs when attempting to parse a non PEM data structure.
+	ErrNoPEMFound = errors.New("no PEM block found")
+	// ErrInvalidVRF occurs when the VRF does not validate.
+	ErrInvalidVRF = errors.New("invalid VRF proof")
+)
+
+// PublicKey holds a public VRF key.
+type PublicKey struct {
+	y   *edwards25519.Point
+	enc []byte
+}
+
+// PrivateKey holds a private VRF key.
+type PrivateKey struct {
+	PublicKey
+	seed   []byte
+	x      *edwards25519.Scalar
+	prefix []byte
+}
+
+// GenerateKey generates a fresh keypair for this VRF
+func GenerateKey() (vrf.PrivateKey, vrf.PublicKey) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand.Reader, seed); err != nil {
+		return nil, nil
+	}
+	k, err := NewVRFSigner(seed)
+	if err != nil {
+		return nil, nil
+	}
+	return k, k.Public().(*PublicKey)
+}
+
+// NewVRFSigner creates a signer object from the seed of an Ed25519 private key.
+func NewVRFSigner(seed []byte) (vrf.PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, ErrInvalidKey
+	}
+	// Derive the secret scalar and nonce prefix as in RFC 8032 section 5.1.5.
+	h := sha512.Sum512(seed)
+	x, err := new(edwards25519.Scalar).SetBytesWithClamping(h[:32])
+	if err != nil {
+		return nil, err
+	}
+	y := new(edwards25519.Point).ScalarBaseMult(x)
+	return &PrivateKey{
+		PublicKey: PublicKey{y: y, enc: y.Bytes()},
+		seed:      append([]byte(nil), seed...),
+		x:         x,
+		prefix:    append([]byte(nil), h[32:]...),
+	}, nil
+}
+
+// NewVRFVerifier creates a verifier object from an encoded Ed25519 public key.
+func NewVRFVerifier(pub []byte) (vrf.PublicKey, error) {
+	y, ok := decodePoint(pub)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	// Reject keys of small order, which would make proofs trivial to forge.
+	if isIdentity(new(edwards25519.Point).MultByCofactor(y)) {
+		return nil, ErrInvalidKey
+	}
+	return &PublicKey{y: y, enc: append([]byte(nil), pub...)}, nil
+}
+
+// Public returns the corresponding public key.
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return &k.PublicKey
+}
+
+// Bytes returns the 32 byte encoding of the public key.
+func (pk *PublicKey) Bytes() []byte {
+	return append([]byte(nil), pk.enc...)
+}
+
+// Evaluate returns the verifiable unpredictable function evaluated at m
+func (k *PrivateKey) Evaluate(m []byte) (index [32]byte, proof []byte) {
+	nilIndex := [32]byte{}
+	h, ok := hashToCurve(k.enc, m)
+	if !ok {
+		return nilIndex, nil
+	}
+
+	// VRF_k(m) = [x]H
+	gamma := new(edwards25519.Point).ScalarMult(k.x, h)
+
+	// Nonce k = SHA512(prefix || H) mod q, as in RFC 8032.
+	kh := sha512.New()
+	kh.Write(k.prefix)
+	kh.Write(h.Bytes())
+	nonce, err := new(edwards25519.Scalar).SetUniformBytes(kh.Sum(nil))
+	if err != nil {
+		return nilIndex, nil
+	}
+
+	// c = H2(H, Gamma, [k]B, [k]H)
+	c := hashPoints(h, gamma,
+		new(edwards25519.Point).ScalarBaseMult(nonce),
+		new(edwards25519.Point).ScalarMult(nonce, h))
+
+	// s = k + c*x mod q
+	s := new(edwards25519.Scalar).MultiplyAdd(challenge(c), k.x, nonce)
+
+	var buf bytes.Buffer
+	buf.Write(gamma.Bytes())
+	buf.Write(c)
+	buf.Write(s.Bytes())
+	return output(gamma), buf.Bytes()
+}
+
+// ProofToHash asserts that proof is correct for m and outputs index.
+func (pk *PublicKey) ProofToHash(m, proof []byte) (index [32]byte, err error) {
+	nilIndex := [32]byte{}
+	if got, want := len(proof), ProofSize; got != want {
+		return nilIndex, ErrInvalidVRF
+	}
+	gamma, ok := decodePoint(proof[:encodedLen])
+	if !ok {
+		return nilIndex, ErrInvalidVRF
+	}
+	c := proof[encodedLen : encodedLen+cLen]
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(proof[encodedLen+cLen:])
+	if err != nil {
+		return nilIndex, ErrInvalidVRF
+	}
+	h, ok := hashToCurve(pk.enc, m)
+	if !ok {
+		return nilIndex, ErrInvalidVRF
+	}
+
+	// [s]B - [c]Y = [k + cx]B - [cx]B = [k]B
+	// [s]H - [c]Gamma = [k + cx]H - [cx]H = [k]H
+	ci := challenge(c)
+	u := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(ci, new(edwards25519.Point).Negate(pk.y), s)
+	v := new(edwards25519.Point).VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{s, ci},
+		[]*edwards25519.Point{h, new(edwards25519.Point).Negate(gamma)})
+
+	if !hmac.Equal(c, hashPoints(h, gamma, u, v)) {
+		return nilIndex, ErrInvalidVRF
+	}
+	return output(gamma), nil
+}
+
+// hashToCurve hashes m to a point using try and increment.
+func hashToCurve(pub, m []byte) (*edwards25519.Point, bool) {
+	h := sha512.New()
+	for ctr := 0; ctr < 256; ctr++ {
+		h.Reset()
+		h.Write([]byte{suite, 0x01})
+		h.Write(pub)
+		h.Write(m)
+		h.Write([]byte{byte(ctr)})
+		if pt, ok := decodePoint(h.Sum(nil)[:encodedLen]); ok {
+			// Clear the cofactor so the point is in the prime order subgroup.
+			if pt.MultByCofactor(pt); !isIdentity(pt) {
+				return pt, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// hashPoints returns the challenge c computed from pts.
+func hashPoints(pts ...*edwards25519.Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x02})
+	for _, pt := range pts {
+		h.Write(pt.Bytes())
+	}
+	return h.Sum(nil)[:cLen]
+}
+
+// challenge returns the challenge c as a scalar.
+func challenge(c []byte) *edwards25519.Scalar {
+	b := make([]byte, encodedLen)
+	copy(b, c)
+	// c is shorter than the group order, so it is always canonical.
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// output returns the VRF output for gamma, truncated to 32 bytes.
+func output(gamma *edwards25519.Point) [32]byte {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x03})
+	h.Write(new(edwards25519.Point).MultByCofactor(gamma).Bytes())
+	var index [32]byte
+	copy(index[:], h.Sum(nil))
+	return index
+}
+
+// decodePoint decodes a point as specified in RFC 8032 section 5.1.3.
+func decodePoint(b []byte) (*edwards25519.Point, bool) {
+	pt, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil {
+		return nil, false
+	}
+	// SetBytes also accepts the non-canonical encodings that RFC 8032
+	// rejects: those with y >= p, or with x = 0 and the sign bit set.
+	if !bytes.Equal(pt.Bytes(), b) {
+		return nil, false
+	}
+	return pt, true
+}
+
+// isIdentity returns true if pt is the neutral element.
+func isIdentity(pt *edwards25519.Point) bool {
+	return pt.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// ASN.1 structures from RFC 8410.
+type algorithmIdentifier struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+type publicKeyInfo struct {
+	Algorithm algorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type privateKeyInfo struct {
+	Version    int
+	Algorithm  algorithmIdentifier
+	PrivateKey []byte
+}
+
+// MarshalPKIXPublicKey returns the DER encoded SubjectPublicKeyInfo of pk.
+func MarshalPKIXPublicKey(pk *PublicKey) ([]byte, error) {
+	return asn1.Marshal(publicKeyInfo{
+		Algorithm: algorithmIdentifier{Algorithm: oid},
+		PublicKey: asn1.BitString{Bytes: pk.enc, BitLength: 8 * len(pk.enc)},
+	})
+}
+
+// MarshalPKCS8PrivateKey returns the DER encoded PKCS #8 form of k.
+func MarshalPKCS8PrivateKey(k *PrivateKey) ([]byte, error) {
+	seed, err := asn1.Marshal(k.seed)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(privateKeyInfo{
+		Algorithm:  algorithmIdentifier{Algorithm: oid},
+		PrivateKey: seed,
+	})
+}
+
+// NewVRFSignerFromRawKey returns the private key from DER encoded PKCS #8 bytes.
+func NewVRFSignerFromRawKey(b []byte) (vrf.PrivateKey, error) {
+	var info privateKeyInfo
+	if rest, err := asn1.Unmarshal(b, &info); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, ErrInvalidKey
+	}
+	if !info.Algorithm.Algorithm.Equal(oid) {
+		return nil, ErrWrongKeyType
+	}
+	var seed []byte
+	if _, err := asn1.Unmarshal(info.PrivateKey, &seed); err != nil {
+		return nil, err
+	}
+	return NewVRFSigner(seed)
+}
+
+// NewVRFVerifierFromRawKey returns the public key from DER encoded
+// SubjectPublicKeyInfo bytes.
+func NewVRFVerifierFromRawKey(b []byte) (vrf.PublicKey, error) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(b, &info); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, ErrInvalidKey
+	}
+	if !info.Algorithm.Algorithm.Equal(oid) {
+		return nil, ErrWrongKeyType
+	}
+	return NewVRFVerifier(info.PublicKey.RightAlign())
+}
+
+// IsPKIXPublicKey returns true if b is a DER encoded SubjectPublicKeyInfo with
+// the Ed25519 algorithm identifier. The key itself is not validated.
+func IsPKIXPublicKey(b []byte) bool {
+	var info publicKeyInfo
+	if _, err := asn1.Unmarshal(b, &info); err != nil {
+		return false
+	}
+	return info.Algorithm.Algorithm.Equal(oid)
+}
+
+// NewVRFSignerFromPEM creates a vrf private key from a PEM data structure.
+func NewVRFSignerFromPEM(b []byte) (vrf.PrivateKey, error) {
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, ErrNoPEMFound
+	}
+	return NewVRFSignerFromRawKey(p.Bytes)
+}
+
+// NewVRFVerifierFromPEM creates a vrf public key from a PEM data structure.
+func NewVRFVerifierFromPEM(b []byte) (vrf.PublicKey, error) {
+	p, _ := pem.Decode(b)
+	if p == nil {
+		return nil, ErrNoPEMFound
+	}
+	return NewVRFVerifierFromRawKey(p.Bytes)
+}
+
+// GenerateWrappedKey generates a fresh private key wrapped in a
+// keyspb.PrivateKey, suitable for NewFromWrappedKey.
+func GenerateWrappedKey() (*keyspb.PrivateKey, error) {
+	k, _ := GenerateKey()
+	if k == nil {
+		return nil, errors.New("ed25519: failed to generate key")
+	}
+	der, err := MarshalPKCS8PrivateKey(k.(*PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	return &keyspb.PrivateKey{Der: der}, nil
+}
+
+// NewFromWrappedKey creates a VRF signer object from a wrapped private key.
+// Only keyspb.PrivateKey is supported, as Trillian's key handlers do not
+// support Ed25519.
+func NewFromWrappedKey(ctx context.Context, wrapped proto.Message) (vrf.PrivateKey, error) {
+	switch k := wrapped.(type) {
+	case *keyspb.PrivateKey:
+		return NewVRFSignerFromRawKey(k.GetDer())
+	default:
+		return nil, fmt.Errorf("wrapped key type: %T, want keyspb.PrivateKey", k)
+	}
+}
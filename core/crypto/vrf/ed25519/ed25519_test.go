@@ -0,0 +1,243 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ed25519
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+func TestVectors(t *testing.T) {
+	// Example 10 of draft-irtf-cfrg-vrf-06, which uses the key of RFC 8032
+	// test 1.
+	k, err := NewVRFSigner(h2b("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	if err != nil {
+		t.Fatalf("NewVRFSigner(): %v", err)
+	}
+	pub := k.Public().(*PublicKey)
+	if got, want := pub.Bytes(), h2b("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"); !bytes.Equal(got, want) {
+		t.Errorf("Public(): %x, want %x", got, want)
+	}
+	for _, tc := range []struct {
+		m     []byte
+		index [32]byte
+		proof []byte
+	}{
+		{
+			m:     nil,
+			index: h2i("a64c292ec45f6b252828aff9a02a0fe88d2fcc7f5fc61bb328f03f4c6c0657a9"),
+			proof: h2b("9275df67a68c8745c0ff97b48201ee6db447f7c93b23ae24cdc2400f52fdb08a1a6ac7ec71bf9c9c76e96ee4675ebff60625af28718501047bfd87b810c2d2139b73c23bd69de66360953a642c2a330a"),
+		},
+	} {
+		index, proof := k.Evaluate(tc.m)
+		if got, want := index, tc.index; got != want {
+			t.Errorf("Evaluate(%s).Index: %x, want %x", tc.m, got, want)
+		}
+		if got, want := proof, tc.proof; !bytes.Equal(got, want) {
+			t.Errorf("Evaluate(%s).Proof: %x, want %x", tc.m, got, want)
+		}
+		index2, err := pub.ProofToHash(tc.m, tc.proof)
+		if err != nil {
+			t.Errorf("ProofToHash(%s): %v", tc.m, err)
+		}
+		if got, want := index2, tc.index; got != want {
+			t.Errorf("ProofToHash(%s): %x, want %x", tc.m, got, want)
+		}
+	}
+}
+
+func TestVRF(t *testing.T) {
+	k, pk := GenerateKey()
+
+	m1 := []byte("data1")
+	m2 := []byte("data2")
+	m3 := []byte("data2")
+	index1, proof1 := k.Evaluate(m1)
+	index2, proof2 := k.Evaluate(m2)
+	index3, proof3 := k.Evaluate(m3)
+	for _, tc := range []struct {
+		m     []byte
+		index [32]byte
+		proof []byte
+		err   error
+	}{
+		{m1, index1, proof1, nil},
+		{m2, index2, proof2, nil},
+		{m3, index3, proof3, nil},
+		{m3, index3, proof2, nil},
+		{m3, index3, proof1, ErrInvalidVRF},
+	} {
+		index, err := pk.ProofToHash(tc.m, tc.proof)
+		if got, want := err, tc.err; got != want {
+			t.Errorf("ProofToHash(%s, %x): %v, want %v", tc.m, tc.proof, got, want)
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := index, tc.index; got != want {
+			t.Errorf("ProofToHash(%s, %x): %x, want %x", tc.m, tc.proof, got, want)
+		}
+	}
+}
+
+func TestOtherKey(t *testing.T) {
+	k, _ := GenerateKey()
+	_, other := GenerateKey()
+
+	m := []byte("data")
+	_, proof := k.Evaluate(m)
+	if _, err := other.ProofToHash(m, proof); err != ErrInvalidVRF {
+		t.Errorf("ProofToHash() with another key: %v, want %v", err, ErrInvalidVRF)
+	}
+}
+
+func TestTruncateProof(t *testing.T) {
+	k, pk := GenerateKey()
+
+	data := []byte("data")
+	_, proof := k.Evaluate(data)
+	for i := 1; i < len(proof); i++ {
+		if _, err := pk.ProofToHash(data, proof[:len(proof)-i]); err == nil {
+			t.Errorf("Verify unexpectedly succeeded after truncating %v bytes from the end of proof", i)
+		}
+		if _, err := pk.ProofToHash(data, proof[i:]); err == nil {
+			t.Errorf("Verify unexpectedly succeeded after truncating %v bytes from the beginning of proof", i)
+		}
+	}
+}
+
+func TestBitFlip(t *testing.T) {
+	k, pk := GenerateKey()
+
+	data := []byte("data")
+	_, proof := k.Evaluate(data)
+	for i := 0; i < len(proof)*8; i++ {
+		flipped := append([]byte(nil), proof...)
+		flipped[i/8] ^= 1 << uint(i%8)
+		if _, err := pk.ProofToHash(data, flipped); err == nil {
+			t.Errorf("Verify unexpectedly succeeded after flipping bit %v of vrf", i)
+		}
+	}
+}
+
+func TestNewVRFVerifier(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		pub     []byte
+		wantErr bool
+	}{
+		{desc: "valid", pub: h2b("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")},
+		{desc: "identity", pub: h2b("0100000000000000000000000000000000000000000000000000000000000000"), wantErr: true},
+		{desc: "small order", pub: h2b("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"), wantErr: true},
+		{desc: "not on curve", pub: h2b("0200000000000000000000000000000000000000000000000000000000000000"), wantErr: true},
+		{desc: "short", pub: h2b("d75a980182b10ab7d54bfed3c964073a"), wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewVRFVerifier(tc.pub)
+			if got, want := err != nil, tc.wantErr; got != want {
+				t.Errorf("NewVRFVerifier(%x): %v, want err: %v", tc.pub, err, want)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	k, _ := GenerateKey()
+	priv := k.(*PrivateKey)
+
+	privDER, err := MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey(): %v", err)
+	}
+	pubDER, err := MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	signer, err := NewVRFSignerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+	if err != nil {
+		t.Fatalf("NewVRFSignerFromPEM(): %v", err)
+	}
+	verifier, err := NewVRFVerifierFromPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatalf("NewVRFVerifierFromPEM(): %v", err)
+	}
+
+	m := []byte("M")
+	index, proof := signer.Evaluate(m)
+	index2, err := verifier.ProofToHash(m, proof)
+	if err != nil {
+		t.Fatalf("ProofToHash(): %v", err)
+	}
+	if index != index2 {
+		t.Errorf("ProofToHash(): %x, want %x", index2, index)
+	}
+
+	// Keys of other algorithms are rejected.
+	// openssl ec -in p256-key.pem -pubout -outform der | xxd -p
+	p256DER := h2b("3059301306072a8648ce3d020106082a8648ce3d03010703420004531" +
+		"5f8da8c49e6fa2235f6e3a33f1482c1aa8756c3d4d5caf66f158af3e6412a1851d54580d6af" +
+		"72c15a421a21922084b61639d466ba5dafa0827f9e4d9b5ff5")
+	if _, err := NewVRFVerifierFromRawKey(p256DER); err != ErrWrongKeyType {
+		t.Errorf("NewVRFVerifierFromRawKey(P256): %v, want %v", err, ErrWrongKeyType)
+	}
+	if !IsPKIXPublicKey(pubDER) || IsPKIXPublicKey(p256DER) {
+		t.Errorf("IsPKIXPublicKey() does not distinguish Ed25519 keys")
+	}
+}
+
+func TestNewFromWrappedKey(t *testing.T) {
+	ctx := context.Background()
+	wrapped, err := GenerateWrappedKey()
+	if err != nil {
+		t.Fatalf("GenerateWrappedKey(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		wrapped *keyspb.PrivateKey
+		wantErr bool
+	}{
+		{desc: "Ed25519", wrapped: wrapped},
+		{desc: "garbage", wrapped: &keyspb.PrivateKey{Der: []byte("foo")}, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewFromWrappedKey(ctx, tc.wrapped)
+			if got, want := err != nil, tc.wantErr; got != want {
+				t.Errorf("NewFromWrappedKey(): %v, want err: %v", err, want)
+			}
+		})
+	}
+	if _, err := NewFromWrappedKey(ctx, &keyspb.PEMKeyFile{}); err == nil {
+		t.Errorf("NewFromWrappedKey(PEMKeyFile): nil, want err")
+	}
+}
+
+func h2i(h string) [32]byte {
+	var i [32]byte
+	copy(i[:], h2b(h))
+	return i
+}
+
+func h2b(h string) []byte {
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		panic("Invalid hex")
+	}
+	return b
+}
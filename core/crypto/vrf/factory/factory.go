@@ -0,0 +1,62 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package factory creates VRF keys of any supported algorithm.
+package factory
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/crypto/vrf/ed25519"
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// NewFromWrappedKey creates a VRF signer object from a wrapped private key.
+// Ed25519 keys are recognized by their PKCS #8 encoding; all other keys are
+// unwrapped by Trillian and must be P256 keys.
+func NewFromWrappedKey(ctx context.Context, wrapped proto.Message) (vrf.PrivateKey, error) {
+	if k, ok := wrapped.(*keyspb.PrivateKey); ok {
+		if priv, err := ed25519.NewVRFSignerFromRawKey(k.GetDer()); err == nil {
+			return priv, nil
+		}
+	}
+	return p256.NewFromWrappedKey(ctx, wrapped)
+}
+
+// NewVRFVerifierFromRawKey returns the public key from DER encoded
+// SubjectPublicKeyInfo bytes.
+func NewVRFVerifierFromRawKey(b []byte) (vrf.PublicKey, error) {
+	if ed25519.IsPKIXPublicKey(b) {
+		return ed25519.NewVRFVerifierFromRawKey(b)
+	}
+	return p256.NewVRFVerifierFromRawKey(b)
+}
+
+// ToPublicProto returns the DER encoding of the public key of a VRF signer.
+func ToPublicProto(pub crypto.PublicKey) (*keyspb.PublicKey, error) {
+	if k, ok := pub.(*ed25519.PublicKey); ok {
+		b, err := ed25519.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return &keyspb.PublicKey{Der: b}, nil
+	}
+	return der.ToPublicProto(pub)
+}
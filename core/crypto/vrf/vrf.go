@@ -16,7 +16,6 @@
 package vrf
 
 import (
-	"bytes"
 	"crypto"
 	"encoding/binary"
 )
@@ -43,10 +42,10 @@ type PublicKey interface {
 
 // UniqueID computes a unique string for a domain, userID and appID combo.
 func UniqueID(userID, appID string) []byte {
-	b := new(bytes.Buffer)
-	binary.Write(b, binary.BigEndian, uint32(len(userID)))
-	b.WriteString(userID)
-	binary.Write(b, binary.BigEndian, uint32(len(appID)))
-	b.WriteString(appID)
-	return b.Bytes()
+	b := make([]byte, 4, 8+len(userID)+len(appID))
+	binary.BigEndian.PutUint32(b, uint32(len(userID)))
+	b = append(b, userID...)
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-4:], uint32(len(appID)))
+	return append(b, appID...)
 }
@@ -58,3 +58,9 @@ func dh(h string) []byte {
 	}
 	return b
 }
+
+func TestUniqueIDAllocs(t *testing.T) {
+	if got := testing.AllocsPerRun(100, func() { UniqueID("alice", "app") }); got > 1 {
+		t.Errorf("UniqueID() allocs: %v, want <= 1", got)
+	}
+}
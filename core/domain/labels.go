@@ -0,0 +1,147 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxLabels is the maximum number of labels of a domain.
+	MaxLabels = 64
+	// maxLabelLen is the maximum length of label keys and values.
+	maxLabelLen = 63
+)
+
+// ValidateLabels returns an error if labels cannot be stored. Keys are 1 to
+// 63 characters long and values at most 63. Both consist of ASCII letters,
+// digits, '-', '_' and '.', and keys start with a letter or digit.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("%v labels, want at most %v", len(labels), MaxLabels)
+	}
+	for k, v := range labels {
+		if err := validateLabelKey(k); err != nil {
+			return err
+		}
+		if err := validateLabelValue(v); err != nil {
+			return fmt.Errorf("label %q: %v", k, err)
+		}
+	}
+	return nil
+}
+
+func validateLabelKey(k string) error {
+	if k == "" {
+		return fmt.Errorf("empty label key")
+	}
+	if !isAlphanumeric(k[0]) {
+		return fmt.Errorf("label key %q must start with a letter or digit", k)
+	}
+	if err := validateLabelValue(k); err != nil {
+		return fmt.Errorf("label key %q: %v", k, err)
+	}
+	return nil
+}
+
+func validateLabelValue(v string) error {
+	if len(v) > maxLabelLen {
+		return fmt.Errorf("longer than %v bytes", maxLabelLen)
+	}
+	for i := 0; i < len(v); i++ {
+		if c := v[i]; !isAlphanumeric(c) && c != '-' && c != '_' && c != '.' {
+			return fmt.Errorf("contains %q", c)
+		}
+	}
+	return nil
+}
+
+func isAlphanumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// requirement is a single condition of a Selector.
+type requirement struct {
+	key   string
+	value string
+	// exists requirements only check the presence of key.
+	exists bool
+	// negate inverts the requirement.
+	negate bool
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.key]
+	if !r.exists {
+		ok = ok && v == r.value
+	}
+	return ok != r.negate
+}
+
+// Selector selects domains by their labels.
+type Selector []requirement
+
+// ParseSelector parses a comma separated list of requirements. Each
+// requirement is one of:
+//
+//	key=value   the domain has label key with value
+//	key!=value  the domain does not have label key with value
+//	key         the domain has label key
+//	!key        the domain does not have label key
+//
+// A domain matches the selector if it meets every requirement. The empty
+// selector matches every domain.
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	if strings.TrimSpace(s) == "" {
+		return sel, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		var r requirement
+		switch {
+		case strings.Contains(part, "!="):
+			kv := strings.SplitN(part, "!=", 2)
+			r = requirement{key: kv[0], value: kv[1], negate: true}
+		case strings.Contains(part, "="):
+			kv := strings.SplitN(part, "=", 2)
+			r = requirement{key: kv[0], value: kv[1]}
+		case strings.HasPrefix(part, "!"):
+			r = requirement{key: part[1:], exists: true, negate: true}
+		default:
+			r = requirement{key: part, exists: true}
+		}
+		r.key, r.value = strings.TrimSpace(r.key), strings.TrimSpace(r.value)
+		if err := validateLabelKey(r.key); err != nil {
+			return nil, fmt.Errorf("selector %q: %v", part, err)
+		}
+		if err := validateLabelValue(r.value); err != nil {
+			return nil, fmt.Errorf("selector %q: %v", part, err)
+		}
+		sel = append(sel, r)
+	}
+	return sel, nil
+}
+
+// Matches returns true if labels meet every requirement of s.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateLabels(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxLabels; i++ {
+		tooMany[fmt.Sprintf("l%v", i)] = ""
+	}
+	for _, tc := range []struct {
+		desc    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{desc: "nil"},
+		{desc: "valid", labels: map[string]string{"env": "prod", "team.name": "key-transparency_1", "empty": ""}},
+		{desc: "empty key", labels: map[string]string{"": "v"}, wantErr: true},
+		{desc: "key start", labels: map[string]string{"-env": "v"}, wantErr: true},
+		{desc: "key chars", labels: map[string]string{"e/nv": "v"}, wantErr: true},
+		{desc: "value chars", labels: map[string]string{"env": "a b"}, wantErr: true},
+		{desc: "long key", labels: map[string]string{strings.Repeat("k", 64): ""}, wantErr: true},
+		{desc: "long value", labels: map[string]string{"k": strings.Repeat("v", 64)}, wantErr: true},
+		{desc: "too many", labels: tooMany, wantErr: true},
+	} {
+		if err := ValidateLabels(tc.labels); (err != nil) != tc.wantErr {
+			t.Errorf("%v: ValidateLabels(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "kt"}
+	for _, tc := range []struct {
+		selector string
+		want     bool
+		wantErr  bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: " env = prod , team=kt ", want: true},
+		{selector: "env=dev", want: false},
+		{selector: "env!=dev", want: true},
+		{selector: "env!=prod", want: false},
+		{selector: "team", want: true},
+		{selector: "region", want: false},
+		{selector: "!region", want: true},
+		{selector: "!env", want: false},
+		{selector: "region!=us", want: true},
+		{selector: "env=prod,region", want: false},
+		{selector: "=prod", wantErr: true},
+		{selector: "env=prod,", wantErr: true},
+		{selector: "env=a b", wantErr: true},
+	} {
+		sel, err := ParseSelector(tc.selector)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseSelector(%q): %v, wantErr %v", tc.selector, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := sel.Matches(labels); got != tc.want {
+			t.Errorf("ParseSelector(%q).Matches(): %v, want %v", tc.selector, got, tc.want)
+		}
+	}
+}
@@ -17,33 +17,216 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/crypto/vrf/ed25519"
+	kterrors "github.com/google/keytransparency/core/errors"
 	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
+// ErrFrozen occurs when a write is attempted on a frozen domain.
+var ErrFrozen = kterrors.ErrFrozen
+
+// ErrAppHasEntries occurs when a VRF is added for an app that already has
+// entries under the indexes of the domain VRF.
+var ErrAppHasEntries = errors.New("app has entries")
+
 // Domain stores configuration information for a single Key Transparency instance.
 type Domain struct {
 	DomainID string
 	MapID    int64
 	LogID    int64
 	VRF      *keyspb.PublicKey
+	// LogBackend names the smhlog backend that hosts the log LogID. It is
+	// empty for logs of the default Trillian instance.
+	LogBackend string
+	// LogAddress and MapAddress locate the Trillian services that host the
+	// trees of the domain. They are empty for trees of the Trillian services
+	// the servers are configured with.
+	LogAddress, MapAddress string
 
 	VRFPriv                  proto.Message
 	MinInterval, MaxInterval time.Duration
+	// AppVRFs holds VRF keys that are scoped to individual apps.
+	// Apps without an entry use the domain's VRF.
+	AppVRFs map[string]*AppVRF
+	// PrevVRF holds the domain VRF key pair that was replaced by the most
+	// recent rotation. It is nil if the domain VRF was never rotated.
+	PrevVRF *RotatedVRF
+	// KeyPolicy restricts the authorized keys of entries. It is nil if the
+	// domain has no policy.
+	KeyPolicy *pb.KeyPolicy
+	// KeyPolicyHistory lists the key policies of the domain with the first
+	// revision each applies to, oldest first.
+	KeyPolicyHistory []*pb.KeyPolicyChange
+	// EndorsementPolicy lists the endorsers that must co-sign map roots. It
+	// is nil if the domain has no policy.
+	EndorsementPolicy *pb.EndorsementPolicy
+	// Migration announces that the domain moves to another key server or
+	// other keys. It is nil if no migration has been announced.
+	Migration *pb.SignedDomainMigration
+	// LogKeyRotation and MapKeyRotation record the most recent rotation of
+	// the signing key of the log and map trees. They are nil if the key of
+	// the tree was never rotated.
+	LogKeyRotation, MapKeyRotation *pb.TreeKeyRotation
+	// Frozen domains serve reads but accept no new mutations. Their
+	// epochs are empty.
+	Frozen bool
+	// AppListing allows users to list the apps they have entries for.
+	AppListing bool
+	// MutationQuota limits the rate of updates. It is nil if the domain has
+	// no quota.
+	MutationQuota *pb.MutationQuota
+	// Labels are free-form key/value pairs that organize domains. They
+	// have no effect on the behavior of the domain.
+	Labels map[string]string
+	// Stats are lifetime totals maintained by the sequencer.
+	Stats Stats
 	// TODO(gbelvin): specify mutation function
 	Deleted bool
+	// DeleteTime is when the domain was deleted. It is zero if the domain is
+	// not deleted or was deleted before deletion times were recorded.
+	DeleteTime time.Time
+	// TreesDeleted is true once the log and map trees of a deleted domain
+	// have been deleted in Trillian.
+	TreesDeleted bool
+}
+
+// Stats are lifetime statistics of a domain's map. They are counted by the
+// sequencer as it creates epochs and are not backfilled, so for domains created
+// before a server recorded them they only cover the epochs created since.
+type Stats struct {
+	// Revision is the last map revision counted.
+	Revision int64
+	// Epochs is the number of epochs created.
+	Epochs int64
+	// Mutations is the number of mutations sequenced into epochs.
+	Mutations int64
+	// Indexes is the number of distinct indexes in the map.
+	Indexes int64
+}
+
+// AverageEpochSize returns the mean number of mutations per epoch.
+func (s Stats) AverageEpochSize() float64 {
+	if s.Epochs == 0 {
+		return 0
+	}
+	return float64(s.Mutations) / float64(s.Epochs)
+}
+
+// AppVRF is a VRF key pair used to compute the indexes of a single app.
+type AppVRF struct {
+	VRF     *keyspb.PublicKey
+	VRFPriv proto.Message
+}
+
+// RotatedVRF is a retired VRF key pair that remains valid for indexes until
+// Expiry so that clients can migrate to the new key.
+type RotatedVRF struct {
+	VRF     *keyspb.PublicKey
+	VRFPriv proto.Message
+	Expiry  time.Time
+}
+
+// VRFFor returns the VRF key pair used to compute indexes for appID.
+func (d *Domain) VRFFor(appID string) (*keyspb.PublicKey, proto.Message) {
+	if a, ok := d.AppVRFs[appID]; ok {
+		return a.VRF, a.VRFPriv
+	}
+	return d.VRF, d.VRFPriv
+}
+
+// VRFAlgorithm returns the algorithm of the domain's VRF keys.
+func (d *Domain) VRFAlgorithm() pb.VrfAlgorithm {
+	if ed25519.IsPKIXPublicKey(d.VRF.GetDer()) {
+		return pb.VrfAlgorithm_ED25519
+	}
+	return pb.VrfAlgorithm_P256
+}
+
+// HardDeleteTime returns the end of the retention window of a deleted
+// domain. After it the domain can no longer be undeleted and may be garbage
+// collected. It returns the zero time if the deletion time is unknown.
+func (d *Domain) HardDeleteTime(retention time.Duration) time.Time {
+	if !d.Deleted || d.DeleteTime.IsZero() {
+		return time.Time{}
+	}
+	return d.DeleteTime.Add(retention)
+}
+
+// HardDeletable returns true if d is deleted and its retention window ended
+// before now.
+func (d *Domain) HardDeletable(now time.Time, retention time.Duration) bool {
+	end := d.HardDeleteTime(retention)
+	return !end.IsZero() && !now.Before(end)
+}
+
+// PrevVRFFor returns the retired VRF key pair that may still be used to
+// compute indexes for appID at time now. Apps with their own VRF are not
+// affected by domain VRF rotations.
+func (d *Domain) PrevVRFFor(appID string, now time.Time) (*keyspb.PublicKey, proto.Message, bool) {
+	if _, ok := d.AppVRFs[appID]; ok {
+		return nil, nil, false
+	}
+	if d.PrevVRF == nil || !now.Before(d.PrevVRF.Expiry) {
+		return nil, nil, false
+	}
+	return d.PrevVRF.VRF, d.PrevVRF.VRFPriv, true
 }
 
 // Storage is an interface for storing multi-tenant configuration information.
 type Storage interface {
 	// List returns the full list of domains.
 	List(ctx context.Context, deleted bool) ([]*Domain, error)
-	// Write stores a new instance to storage.
+	// Write stores a new instance to storage, including its labels.
 	Write(ctx context.Context, d *Domain) error
-	// Read a configuration from storage.
+	// Read a configuration from storage. The error wraps
+	// kterrors.ErrNotFound if the domain does not exist.
 	Read(ctx context.Context, domainID string, showDeleted bool) (*Domain, error)
-	// Delete and undelete.
+	// Delete and undelete. Deleting records the current time as the
+	// deletion time of the domain; undeleting clears it.
 	SetDelete(ctx context.Context, domainID string, isDeleted bool) error
+	// SetIntervals replaces the minimum and maximum time between the epochs
+	// of the domain.
+	SetIntervals(ctx context.Context, domainID string, minInterval, maxInterval time.Duration) error
+	// SetTreesDeleted records whether the Trillian trees of the domain have
+	// been deleted.
+	SetTreesDeleted(ctx context.Context, domainID string, deleted bool) error
+	// AddAppVRF stores a VRF key pair that is scoped to appID. It returns
+	// ErrAppHasEntries if updates for appID have been recorded in the
+	// appindex.
+	AddAppVRF(ctx context.Context, domainID, appID string, vrf *keyspb.PublicKey, vrfPriv proto.Message) error
+	// RotateVRF replaces the domain VRF key pair. The replaced key pair is
+	// kept as the previous VRF until overlapEnd.
+	RotateVRF(ctx context.Context, domainID string, vrf *keyspb.PublicKey, vrfPriv proto.Message, overlapEnd time.Time) error
+	// SetKeyPolicy replaces the key policy of the domain. A nil policy
+	// removes it. The change is added to the key policy history as applying
+	// from revision on.
+	SetKeyPolicy(ctx context.Context, domainID string, policy *pb.KeyPolicy, revision int64) error
+	// SetEndorsementPolicy replaces the endorsement policy of the domain. A
+	// nil policy removes it.
+	SetEndorsementPolicy(ctx context.Context, domainID string, policy *pb.EndorsementPolicy) error
+	// SetMigration replaces the migration announcement of the domain. A nil
+	// announcement removes it.
+	SetMigration(ctx context.Context, domainID string, migration *pb.SignedDomainMigration) error
+	// SetTreeKeyRotation records the most recent rotation of the signing key
+	// of treeID, which must be the log or map tree of the domain.
+	SetTreeKeyRotation(ctx context.Context, domainID string, treeID int64, rotation *pb.TreeKeyRotation) error
+	// SetLabels replaces the labels of the domain.
+	SetLabels(ctx context.Context, domainID string, labels map[string]string) error
+	// SetFrozen freezes or unfreezes the domain.
+	SetFrozen(ctx context.Context, domainID string, frozen bool) error
+	// SetAppListing enables or disables app listing for the domain.
+	SetAppListing(ctx context.Context, domainID string, enabled bool) error
+	// SetMutationQuota replaces the mutation quota of the domain. A nil
+	// quota removes it.
+	SetMutationQuota(ctx context.Context, domainID string, quota *pb.MutationQuota) error
+	// RecordEpoch adds an epoch at map revision to the domain's stats.
+	// Revisions at or below the last recorded revision are ignored, so an
+	// epoch is counted once even if it is recorded again.
+	RecordEpoch(ctx context.Context, domainID string, revision, mutations, newIndexes int64) error
 }
@@ -0,0 +1,38 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package election decides which of several sequencers creates the epochs of
+// a domain.
+package election
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotLeader occurs when another sequencer leads a domain.
+var ErrNotLeader = errors.New("another sequencer leads the domain")
+
+// Election grants sequencers leases on the leadership of domains. A
+// sequencer only creates the epochs of domains it leads, so that sequencers
+// can be replicated without creating conflicting epochs.
+type Election interface {
+	// Acquire makes this sequencer the leader of domainID for a lease term,
+	// or renews its lease. It returns ErrNotLeader if another sequencer
+	// holds a lease that has not expired.
+	Acquire(ctx context.Context, domainID string) error
+	// Resign ends the lease of this sequencer on domainID, if it has one,
+	// so that another sequencer can take over.
+	Resign(ctx context.Context, domainID string) error
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package endorsement co-signs map roots with independent endorsers.
+//
+// A domain with an endorsement policy only appends a map root to its log
+// once a quorum of the endorsers in the policy have signed it. Endorsers are
+// meant to be run separately from the sequencer, for instance by replicas in
+// other regions, so that a single compromised signer cannot publish a map
+// root on its own. Endorsers sign the log leaf of the map root, which is the
+// JSON encoding of the SignedMapRoot.
+package endorsement
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+var (
+	// ErrQuorum occurs when fewer endorsers than the quorum of a policy
+	// have signed a map root.
+	ErrQuorum = errors.New("endorsement: quorum not reached")
+	// ErrWeakerPolicy occurs when a policy can be satisfied with fewer
+	// endorsements by trusted endorsers than the trusted policy requires.
+	ErrWeakerPolicy = errors.New("endorsement: policy is weaker than trusted policy")
+)
+
+// Endorser co-signs map roots.
+type Endorser interface {
+	// Endorse returns a signature over smr, or an error if the endorser
+	// declines to sign it.
+	Endorse(ctx context.Context, domainID string, smr *trillian.SignedMapRoot) (*pb.MapRootEndorsement, error)
+}
+
+// Storage stores the endorsements of map roots.
+type Storage interface {
+	// Write saves the endorsements of the map root of domainID at revision.
+	Write(ctx context.Context, domainID string, revision int64, endorsements []*pb.MapRootEndorsement) error
+	// Read returns the endorsements of the map root of domainID at revision.
+	// It returns no endorsements if none have been written.
+	Read(ctx context.Context, domainID string, revision int64) ([]*pb.MapRootEndorsement, error)
+}
+
+// KeyID returns the identifier of an endorser's public key.
+func KeyID(pub *keyspb.PublicKey) []byte {
+	h := sha256.Sum256(pub.GetDer())
+	return h[:]
+}
+
+// Message returns the bytes that endorsers sign for smr.
+func Message(smr *trillian.SignedMapRoot) ([]byte, error) {
+	return json.Marshal(smr)
+}
+
+// Signer is an Endorser that signs every map root with a local key.
+type Signer struct {
+	signer *tcrypto.Signer
+	keyID  []byte
+}
+
+// NewSigner returns an Endorser that signs with signer.
+func NewSigner(signer *tcrypto.Signer) (*Signer, error) {
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("endorsement: cannot encode public key: %w", err)
+	}
+	return &Signer{
+		signer: signer,
+		keyID:  KeyID(&keyspb.PublicKey{Der: der}),
+	}, nil
+}
+
+// Endorse implements Endorser.
+func (s *Signer) Endorse(ctx context.Context, domainID string, smr *trillian.SignedMapRoot) (*pb.MapRootEndorsement, error) {
+	msg, err := Message(smr)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.signer.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MapRootEndorsement{KeyId: s.keyID, Signature: sig}, nil
+}
+
+// Collect asks every endorser to sign smr and returns the valid endorsements
+// from the endorsers in policy. It returns ErrQuorum if fewer than the quorum
+// of policy could be collected. Endorsers that fail are logged and ignored.
+func Collect(ctx context.Context, endorsers []Endorser, domainID string, smr *trillian.SignedMapRoot, policy *pb.EndorsementPolicy) ([]*pb.MapRootEndorsement, error) {
+	msg, err := Message(smr)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseKeys(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ret []*pb.MapRootEndorsement
+	)
+	for _, e := range endorsers {
+		wg.Add(1)
+		go func(e Endorser) {
+			defer wg.Done()
+			end, err := e.Endorse(ctx, domainID, smr)
+			if err != nil {
+				glog.Warningf("Endorse(%v, %v): %v", domainID, smr.GetMapRevision(), err)
+				return
+			}
+			if err := verifyOne(keys, msg, end); err != nil {
+				glog.Warningf("Endorsement of %v/%v by %x: %v", domainID, smr.GetMapRevision(), end.GetKeyId(), err)
+				return
+			}
+			mu.Lock()
+			ret = append(ret, end)
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+
+	ret = dedupe(ret)
+	if got, want := len(ret), int(policy.GetQuorum()); got < want {
+		return nil, fmt.Errorf("%w: %v of %v endorsements for revision %v", ErrQuorum, got, want, smr.GetMapRevision())
+	}
+	return ret, nil
+}
+
+// Verify returns nil if at least the quorum of policy of distinct endorsers
+// in policy have signed smr. Endorsements by unknown keys and invalid
+// endorsements are ignored.
+func Verify(smr *trillian.SignedMapRoot, endorsements []*pb.MapRootEndorsement, policy *pb.EndorsementPolicy) error {
+	if policy.GetQuorum() <= 0 {
+		return nil
+	}
+	msg, err := Message(smr)
+	if err != nil {
+		return err
+	}
+	keys, err := parseKeys(policy)
+	if err != nil {
+		return err
+	}
+	valid := make([]*pb.MapRootEndorsement, 0, len(endorsements))
+	for _, e := range endorsements {
+		if verifyOne(keys, msg, e) == nil {
+			valid = append(valid, e)
+		}
+	}
+	if got, want := len(dedupe(valid)), int(policy.GetQuorum()); got < want {
+		return fmt.Errorf("%w: %v of %v valid endorsements", ErrQuorum, got, want)
+	}
+	return nil
+}
+
+// CheckPolicy returns an error if policy cannot be satisfied.
+func CheckPolicy(policy *pb.EndorsementPolicy) error {
+	if q := policy.GetQuorum(); q < 0 || int(q) > len(policy.GetKeys()) {
+		return fmt.Errorf("endorsement: quorum %v out of range for %v keys", q, len(policy.GetKeys()))
+	}
+	keys, err := parseKeys(policy)
+	if err != nil {
+		return err
+	}
+	if len(keys) != len(policy.GetKeys()) {
+		return errors.New("endorsement: duplicate keys")
+	}
+	return nil
+}
+
+// CheckNotWeaker returns ErrWeakerPolicy unless every quorum of policy
+// includes at least trusted's quorum of endorsers listed in trusted.
+// Endorsers that are not in trusted count against policy since anyone may
+// hold their keys.
+func CheckNotWeaker(policy, trusted *pb.EndorsementPolicy) error {
+	if trusted.GetQuorum() <= 0 {
+		return nil
+	}
+	known := make(map[string]bool, len(trusted.GetKeys()))
+	for _, k := range trusted.GetKeys() {
+		known[string(KeyID(k))] = true
+	}
+	unknown := 0
+	for _, k := range policy.GetKeys() {
+		if !known[string(KeyID(k))] {
+			unknown++
+		}
+	}
+	if got, want := int(policy.GetQuorum())-unknown, int(trusted.GetQuorum()); got < want {
+		return fmt.Errorf("%w: %v of %v trusted endorsements required", ErrWeakerPolicy, got, want)
+	}
+	return nil
+}
+
+// parseKeys returns the keys of policy by key ID.
+func parseKeys(policy *pb.EndorsementPolicy) (map[string]crypto.PublicKey, error) {
+	keys := make(map[string]crypto.PublicKey, len(policy.GetKeys()))
+	for _, k := range policy.GetKeys() {
+		pub, err := x509.ParsePKIXPublicKey(k.GetDer())
+		if err != nil {
+			return nil, fmt.Errorf("endorsement: cannot parse key: %w", err)
+		}
+		keys[string(KeyID(k))] = pub
+	}
+	return keys, nil
+}
+
+// verifyOne verifies that e is a signature over msg by one of keys.
+func verifyOne(keys map[string]crypto.PublicKey, msg []byte, e *pb.MapRootEndorsement) error {
+	pub, ok := keys[string(e.GetKeyId())]
+	if !ok {
+		return errors.New("unknown endorser")
+	}
+	return tcrypto.Verify(pub, msg, e.GetSignature())
+}
+
+// dedupe keeps the first endorsement of each key.
+func dedupe(endorsements []*pb.MapRootEndorsement) []*pb.MapRootEndorsement {
+	seen := make(map[string]bool, len(endorsements))
+	ret := endorsements[:0]
+	for _, e := range endorsements {
+		if seen[string(e.GetKeyId())] {
+			continue
+		}
+		seen[string(e.GetKeyId())] = true
+		ret = append(ret, e)
+	}
+	return ret
+}
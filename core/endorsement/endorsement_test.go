@@ -0,0 +1,152 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endorsement
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// failingEndorser declines to sign anything.
+type failingEndorser struct{}
+
+func (failingEndorser) Endorse(context.Context, string, *trillian.SignedMapRoot) (*pb.MapRootEndorsement, error) {
+	return nil, errors.New("unavailable")
+}
+
+func newSigner(t *testing.T) (*Signer, *keyspb.PublicKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	s, err := NewSigner(tcrypto.NewSHA256Signer(key))
+	if err != nil {
+		t.Fatalf("NewSigner(): %v", err)
+	}
+	return s, &keyspb.PublicKey{Der: der}
+}
+
+func TestCollectVerify(t *testing.T) {
+	ctx := context.Background()
+	a, aPub := newSigner(t)
+	b, bPub := newSigner(t)
+	outsider, _ := newSigner(t)
+	_, cPub := newSigner(t)
+	policy := &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{aPub, bPub, cPub}, Quorum: 2}
+	smr := &trillian.SignedMapRoot{MapId: 1, MapRevision: 5, RootHash: []byte("root")}
+
+	for _, tc := range []struct {
+		desc      string
+		endorsers []Endorser
+		want      int
+		wantErr   error
+	}{
+		{desc: "all", endorsers: []Endorser{a, b}, want: 2},
+		{desc: "failures ignored", endorsers: []Endorser{a, failingEndorser{}, b}, want: 2},
+		{desc: "duplicates", endorsers: []Endorser{a, a, b}, want: 2},
+		{desc: "outsider", endorsers: []Endorser{a, outsider}, wantErr: ErrQuorum},
+		{desc: "too few", endorsers: []Endorser{a, failingEndorser{}}, wantErr: ErrQuorum},
+	} {
+		got, err := Collect(ctx, tc.endorsers, "domain", smr, policy)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: Collect(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(got) != tc.want {
+			t.Errorf("%v: Collect(): %v endorsements, want %v", tc.desc, len(got), tc.want)
+		}
+		if err := Verify(smr, got, policy); err != nil {
+			t.Errorf("%v: Verify(): %v", tc.desc, err)
+		}
+		other := &trillian.SignedMapRoot{MapId: 1, MapRevision: 6, RootHash: []byte("root")}
+		if err := Verify(other, got, policy); !errors.Is(err, ErrQuorum) {
+			t.Errorf("%v: Verify(other root): %v, want %v", tc.desc, err, ErrQuorum)
+		}
+	}
+	if err := Verify(smr, nil, &pb.EndorsementPolicy{}); err != nil {
+		t.Errorf("Verify(no policy): %v", err)
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	_, aPub := newSigner(t)
+	_, bPub := newSigner(t)
+	for _, tc := range []struct {
+		desc    string
+		policy  *pb.EndorsementPolicy
+		wantErr bool
+	}{
+		{desc: "nil", policy: nil},
+		{desc: "valid", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{aPub, bPub}, Quorum: 2}},
+		{desc: "quorum too large", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{aPub}, Quorum: 2}, wantErr: true},
+		{desc: "negative quorum", policy: &pb.EndorsementPolicy{Quorum: -1}, wantErr: true},
+		{desc: "duplicate keys", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{aPub, aPub}, Quorum: 1}, wantErr: true},
+		{desc: "bad key", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{{Der: []byte("bad")}}, Quorum: 1}, wantErr: true},
+	} {
+		if err := CheckPolicy(tc.policy); (err != nil) != tc.wantErr {
+			t.Errorf("%v: CheckPolicy(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckNotWeaker(t *testing.T) {
+	a := &keyspb.PublicKey{Der: []byte("a")}
+	b := &keyspb.PublicKey{Der: []byte("b")}
+	c := &keyspb.PublicKey{Der: []byte("c")}
+	trusted := &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b}, Quorum: 2}
+	for _, tc := range []struct {
+		desc    string
+		policy  *pb.EndorsementPolicy
+		trusted *pb.EndorsementPolicy
+		wantErr bool
+	}{
+		{desc: "same", policy: trusted, trusted: trusted},
+		{desc: "nothing trusted", policy: nil, trusted: nil},
+		{desc: "unset", policy: nil, trusted: trusted, wantErr: true},
+		{desc: "quorum 0", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b}}, trusted: trusted, wantErr: true},
+		{desc: "lower quorum", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b}, Quorum: 1}, trusted: trusted, wantErr: true},
+		{desc: "unknown endorser", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, c}, Quorum: 2}, trusted: trusted, wantErr: true},
+		{desc: "extra endorser", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b, c}, Quorum: 3}, trusted: trusted},
+		{desc: "stronger", policy: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b}, Quorum: 2},
+			trusted: &pb.EndorsementPolicy{Keys: []*keyspb.PublicKey{a, b}, Quorum: 1}},
+	} {
+		err := CheckNotWeaker(tc.policy, tc.trusted)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: CheckNotWeaker(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrWeakerPolicy) {
+			t.Errorf("%v: CheckNotWeaker(): %v, want %v", tc.desc, err, ErrWeakerPolicy)
+		}
+	}
+}
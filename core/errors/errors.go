@@ -0,0 +1,105 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errors defines the kinds of failure shared across Key Transparency
+// packages.
+//
+// Packages keep their own specific sentinel errors, such as
+// kt.ErrNilProof, and wrap them in an *Error that carries one of the kinds
+// below. Callers test for either with the standard library:
+//
+//	if errors.Is(err, kterrors.ErrVerification) { ... }
+//	if errors.Is(err, kt.ErrNilProof) { ... }
+//
+// rather than by comparing error strings.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure.
+var (
+	// ErrVerification occurs when a response from the key server fails
+	// cryptographic verification.
+	ErrVerification = errors.New("verification failed")
+	// ErrNotFound occurs when a requested domain, entry or record does not
+	// exist in storage.
+	ErrNotFound = errors.New("not found")
+	// ErrStorage occurs when a storage backend fails.
+	ErrStorage = errors.New("storage failure")
+	// ErrQueue occurs when a mutation cannot be queued or dequeued.
+	ErrQueue = errors.New("mutation queue failure")
+	// ErrFrozen occurs when a write is attempted on a frozen domain.
+	ErrFrozen = errors.New("domain is frozen")
+	// ErrStaleRoot occurs when the key server's log root is older than the
+	// log root the client already trusts.
+	ErrStaleRoot = errors.New("stale log root")
+	// ErrNotSequenced occurs when a mutation has been queued but is not yet
+	// included in a published epoch.
+	ErrNotSequenced = errors.New("not yet sequenced")
+	// ErrQuotaExceeded occurs when a request is rejected because the caller
+	// exceeded its quota.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Client outcomes that callers are expected to handle.
+var (
+	// ErrRetry occurs when an update request has been submitted, but the
+	// results of the update are not visible on the server yet. The client
+	// must retry until the request is visible. ErrRetry is of kind
+	// ErrNotSequenced.
+	ErrRetry error = &Error{Kind: ErrNotSequenced, Err: errors.New("update not present on server yet")}
+	// ErrIncomplete occurs when the server indicates that requested epochs
+	// are not available.
+	ErrIncomplete = errors.New("incomplete account history")
+)
+
+// Error is a failure of kind Kind during operation Op.
+type Error struct {
+	// Kind is one of the kinds of failure of this package.
+	Kind error
+	// Op names the operation that failed.
+	Op string
+	// Err is the underlying error. It may be nil.
+	Err error
+}
+
+// Wrap returns err as an *Error of kind during op. It returns nil if err is
+// nil.
+func Wrap(kind error, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return fmt.Sprintf("%v: %v", e.Op, e.Kind)
+	case e.Op == "":
+		return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+	default:
+		return fmt.Sprintf("%v: %v: %v", e.Op, e.Kind, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool { return e.Kind == target }
@@ -0,0 +1,88 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	errProof := errors.New("bad proof")
+	for _, tc := range []struct {
+		desc     string
+		err      error
+		targets  []error
+		excluded []error
+	}{
+		{
+			desc:     "kind and cause",
+			err:      Wrap(ErrVerification, "VerifyGetEntryResponse", errProof),
+			targets:  []error{ErrVerification, errProof},
+			excluded: []error{ErrNotFound},
+		},
+		{
+			desc:     "wrapped again",
+			err:      fmt.Errorf("GetEntry(): %w", Wrap(ErrNotFound, "Read", sql.ErrNoRows)),
+			targets:  []error{ErrNotFound, sql.ErrNoRows},
+			excluded: []error{ErrVerification, ErrStorage},
+		},
+	} {
+		for _, target := range tc.targets {
+			if !errors.Is(tc.err, target) {
+				t.Errorf("%v: errors.Is(%v, %v): false, want true", tc.desc, tc.err, target)
+			}
+		}
+		for _, target := range tc.excluded {
+			if errors.Is(tc.err, target) {
+				t.Errorf("%v: errors.Is(%v, %v): true, want false", tc.desc, tc.err, target)
+			}
+		}
+		var e *Error
+		if !errors.As(tc.err, &e) {
+			t.Errorf("%v: errors.As(%v, *Error): false, want true", tc.desc, tc.err)
+		}
+	}
+	if err := Wrap(ErrStorage, "Write", nil); err != nil {
+		t.Errorf("Wrap(nil): %v, want nil", err)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	for _, tc := range []struct {
+		err  *Error
+		want string
+	}{
+		{err: &Error{Kind: ErrStorage, Op: "Write", Err: errors.New("disk full")}, want: "Write: storage failure: disk full"},
+		{err: &Error{Kind: ErrStorage, Err: errors.New("disk full")}, want: "storage failure: disk full"},
+		{err: &Error{Kind: ErrFrozen, Op: "ForceEpoch"}, want: "ForceEpoch: domain is frozen"},
+	} {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("Error(): %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestErrRetry(t *testing.T) {
+	err := fmt.Errorf("Update(): %w", ErrRetry)
+	if !errors.Is(err, ErrRetry) || !errors.Is(err, ErrNotSequenced) {
+		t.Errorf("errors.Is(%v, ErrRetry/ErrNotSequenced): false, want true", err)
+	}
+	if errors.Is(err, ErrVerification) {
+		t.Errorf("errors.Is(%v, ErrVerification): true, want false", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+)
+
+// ACLStorage implements acl.Storage
+type ACLStorage struct {
+	grants map[string]map[string]bool
+}
+
+// NewACLStorage returns a fake acl.Storage
+func NewACLStorage() *ACLStorage {
+	return &ACLStorage{
+		grants: make(map[string]map[string]bool),
+	}
+}
+
+// Domains returns the domains identity has been granted.
+func (a *ACLStorage) Domains(ctx context.Context, identity string) ([]string, error) {
+	ret := []string{}
+	for d := range a.grants[identity] {
+		ret = append(ret, d)
+	}
+	return ret, nil
+}
+
+// Grant allows identity to operate on domainID.
+func (a *ACLStorage) Grant(ctx context.Context, identity, domainID string) error {
+	if a.grants[identity] == nil {
+		a.grants[identity] = make(map[string]bool)
+	}
+	a.grants[identity][domainID] = true
+	return nil
+}
+
+// Revoke removes a grant.
+func (a *ACLStorage) Revoke(ctx context.Context, identity, domainID string) error {
+	delete(a.grants[identity], domainID)
+	return nil
+}
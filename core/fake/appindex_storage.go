@@ -0,0 +1,76 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// AppIndexStorage implements appindex.Storage
+type AppIndexStorage struct {
+	apps     map[string]map[string]bool
+	complete map[string]bool
+}
+
+// NewAppIndexStorage returns a fake appindex.Storage
+func NewAppIndexStorage() *AppIndexStorage {
+	return &AppIndexStorage{
+		apps:     make(map[string]map[string]bool),
+		complete: make(map[string]bool),
+	}
+}
+
+// Add records that userID has an entry for appID.
+func (a *AppIndexStorage) Add(ctx context.Context, domainID, userID, appID string) error {
+	k := domainID + "/" + userID
+	if a.apps[k] == nil {
+		a.apps[k] = make(map[string]bool)
+	}
+	a.apps[k][appID] = true
+	return nil
+}
+
+// List returns the apps that userID has entries for.
+func (a *AppIndexStorage) List(ctx context.Context, domainID, userID string) ([]string, error) {
+	var appIDs []string
+	for appID := range a.apps[domainID+"/"+userID] {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Strings(appIDs)
+	return appIDs, nil
+}
+
+// HasApp returns true if any user has an entry for appID.
+func (a *AppIndexStorage) HasApp(ctx context.Context, domainID, appID string) (bool, error) {
+	for k, apps := range a.apps {
+		if strings.HasPrefix(k, domainID+"/") && apps[appID] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetComplete records that every update to domainID is recorded.
+func (a *AppIndexStorage) SetComplete(ctx context.Context, domainID string) error {
+	a.complete[domainID] = true
+	return nil
+}
+
+// Complete returns true if SetComplete was called for domainID.
+func (a *AppIndexStorage) Complete(ctx context.Context, domainID string) (bool, error) {
+	return a.complete[domainID], nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+
+	"github.com/google/keytransparency/core/audit"
+)
+
+// AuditStorage implements audit.Storage
+type AuditStorage struct {
+	entries map[string][]*audit.Entry
+}
+
+// NewAuditStorage returns a fake audit.Storage
+func NewAuditStorage() *AuditStorage {
+	return &AuditStorage{
+		entries: make(map[string][]*audit.Entry),
+	}
+}
+
+// Append adds e to the audit log of its domain.
+func (a *AuditStorage) Append(ctx context.Context, e *audit.Entry) error {
+	e.Sequence = int64(len(a.entries[e.DomainID]))
+	a.entries[e.DomainID] = append(a.entries[e.DomainID], e)
+	return nil
+}
+
+// List returns up to count entries of domainID starting at start.
+func (a *AuditStorage) List(ctx context.Context, domainID string, start int64, count int32) ([]*audit.Entry, error) {
+	entries := a.entries[domainID]
+	if start >= int64(len(entries)) {
+		return []*audit.Entry{}, nil
+	}
+	end := start + int64(count)
+	if end > int64(len(entries)) {
+		end = int64(len(entries))
+	}
+	return entries[start:end], nil
+}
@@ -17,8 +17,15 @@ package fake
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/keytransparency/core/domain"
+	kterrors "github.com/google/keytransparency/core/errors"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
 // DomainStorage implements domain.Storage
@@ -52,7 +59,7 @@ func (a *DomainStorage) Write(ctx context.Context, d *domain.Domain) error {
 func (a *DomainStorage) Read(ctx context.Context, ID string, showDeleted bool) (*domain.Domain, error) {
 	d, ok := a.domains[ID]
 	if !ok {
-		return nil, fmt.Errorf("Domain %v not found", ID)
+		return nil, fmt.Errorf("Domain %v: %w", ID, kterrors.ErrNotFound)
 	}
 	return d, nil
 }
@@ -64,5 +71,165 @@ func (a *DomainStorage) SetDelete(ctx context.Context, ID string, isDeleted bool
 		return fmt.Errorf("Domain %v not found", ID)
 	}
 	a.domains[ID].Deleted = isDeleted
+	a.domains[ID].DeleteTime = time.Time{}
+	if isDeleted {
+		a.domains[ID].DeleteTime = time.Now()
+	}
+	return nil
+}
+
+// SetTreesDeleted records whether the trees of a domain have been deleted.
+func (a *DomainStorage) SetTreesDeleted(ctx context.Context, ID string, deleted bool) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.TreesDeleted = deleted
+	return nil
+}
+
+// AddAppVRF adds a VRF that is scoped to appID.
+func (a *DomainStorage) AddAppVRF(ctx context.Context, ID, appID string, vrf *keyspb.PublicKey, vrfPriv proto.Message) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	if _, ok := d.AppVRFs[appID]; ok {
+		return fmt.Errorf("App %v already has a VRF", appID)
+	}
+	if d.AppVRFs == nil {
+		d.AppVRFs = make(map[string]*domain.AppVRF)
+	}
+	d.AppVRFs[appID] = &domain.AppVRF{VRF: vrf, VRFPriv: vrfPriv}
+	return nil
+}
+
+// RotateVRF replaces the domain VRF and keeps the old one until overlapEnd.
+func (a *DomainStorage) RotateVRF(ctx context.Context, ID string, vrf *keyspb.PublicKey, vrfPriv proto.Message, overlapEnd time.Time) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.PrevVRF = &domain.RotatedVRF{VRF: d.VRF, VRFPriv: d.VRFPriv, Expiry: overlapEnd}
+	d.VRF, d.VRFPriv = vrf, vrfPriv
+	return nil
+}
+
+// SetIntervals replaces the epoch intervals of a domain.
+func (a *DomainStorage) SetIntervals(ctx context.Context, ID string, minInterval, maxInterval time.Duration) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.MinInterval, d.MaxInterval = minInterval, maxInterval
+	return nil
+}
+
+// SetKeyPolicy replaces the key policy of a domain and records the change.
+func (a *DomainStorage) SetKeyPolicy(ctx context.Context, ID string, policy *pb.KeyPolicy, revision int64) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.KeyPolicy = policy
+	// A change at the revision of the last one replaces it.
+	if n := len(d.KeyPolicyHistory); n > 0 && d.KeyPolicyHistory[n-1].GetRevision() == revision {
+		d.KeyPolicyHistory = d.KeyPolicyHistory[:n-1]
+	}
+	d.KeyPolicyHistory = append(d.KeyPolicyHistory, &pb.KeyPolicyChange{Revision: revision, KeyPolicy: policy})
+	return nil
+}
+
+// SetEndorsementPolicy replaces the endorsement policy of a domain.
+func (a *DomainStorage) SetEndorsementPolicy(ctx context.Context, ID string, policy *pb.EndorsementPolicy) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.EndorsementPolicy = policy
+	return nil
+}
+
+// SetMigration replaces the migration announcement of a domain.
+func (a *DomainStorage) SetMigration(ctx context.Context, ID string, migration *pb.SignedDomainMigration) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.Migration = migration
+	return nil
+}
+
+// SetTreeKeyRotation records the most recent key rotation of a tree of a
+// domain.
+func (a *DomainStorage) SetTreeKeyRotation(ctx context.Context, ID string, treeID int64, rotation *pb.TreeKeyRotation) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	switch treeID {
+	case d.LogID:
+		d.LogKeyRotation = rotation
+	case d.MapID:
+		d.MapKeyRotation = rotation
+	default:
+		return fmt.Errorf("Tree %v is not a tree of domain %v", treeID, ID)
+	}
+	return nil
+}
+
+// SetLabels replaces the labels of a domain.
+func (a *DomainStorage) SetLabels(ctx context.Context, ID string, labels map[string]string) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.Labels = labels
+	return nil
+}
+
+// SetFrozen freezes or unfreezes a domain.
+func (a *DomainStorage) SetFrozen(ctx context.Context, ID string, frozen bool) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.Frozen = frozen
+	return nil
+}
+
+// SetAppListing enables or disables app listing for a domain.
+func (a *DomainStorage) SetAppListing(ctx context.Context, ID string, enabled bool) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.AppListing = enabled
+	return nil
+}
+
+// SetMutationQuota replaces the mutation quota of a domain.
+func (a *DomainStorage) SetMutationQuota(ctx context.Context, ID string, quota *pb.MutationQuota) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	d.MutationQuota = quota
+	return nil
+}
+
+// RecordEpoch adds an epoch to the stats of a domain.
+func (a *DomainStorage) RecordEpoch(ctx context.Context, ID string, revision, mutations, newIndexes int64) error {
+	d, ok := a.domains[ID]
+	if !ok {
+		return fmt.Errorf("Domain %v not found", ID)
+	}
+	if revision <= d.Stats.Revision {
+		return nil
+	}
+	d.Stats.Revision = revision
+	d.Stats.Epochs++
+	d.Stats.Mutations += mutations
+	d.Stats.Indexes += newIndexes
 	return nil
 }
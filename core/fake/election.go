@@ -0,0 +1,57 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+
+	"github.com/google/keytransparency/core/election"
+)
+
+// Election implements election.Election.
+type Election struct {
+	// Leading holds the domains this sequencer leads.
+	Leading map[string]bool
+	// Others holds the domains another sequencer leads.
+	Others map[string]bool
+	// Acquired and Resigned count the calls to Acquire and Resign.
+	Acquired, Resigned int
+}
+
+// NewElection returns a fake election.Election.
+func NewElection() *Election {
+	return &Election{
+		Leading: make(map[string]bool),
+		Others:  make(map[string]bool),
+	}
+}
+
+// Acquire makes this sequencer the leader of domainID unless another
+// sequencer leads it.
+func (e *Election) Acquire(_ context.Context, domainID string) error {
+	e.Acquired++
+	if e.Others[domainID] {
+		return election.ErrNotLeader
+	}
+	e.Leading[domainID] = true
+	return nil
+}
+
+// Resign ends the leadership of domainID.
+func (e *Election) Resign(_ context.Context, domainID string) error {
+	e.Resigned++
+	delete(e.Leading, domainID)
+	return nil
+}
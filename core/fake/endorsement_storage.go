@@ -0,0 +1,49 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// EndorsementStorage implements endorsement.Storage
+type EndorsementStorage struct {
+	endorsements map[string][]*pb.MapRootEndorsement
+}
+
+// NewEndorsementStorage returns a fake endorsement.Storage
+func NewEndorsementStorage() *EndorsementStorage {
+	return &EndorsementStorage{
+		endorsements: make(map[string][]*pb.MapRootEndorsement),
+	}
+}
+
+func endorsementKey(domainID string, revision int64) string {
+	return fmt.Sprintf("%v/%v", domainID, revision)
+}
+
+// Write saves the endorsements of a map root.
+func (e *EndorsementStorage) Write(ctx context.Context, domainID string, revision int64, endorsements []*pb.MapRootEndorsement) error {
+	e.endorsements[endorsementKey(domainID, revision)] = endorsements
+	return nil
+}
+
+// Read returns the endorsements of a map root.
+func (e *EndorsementStorage) Read(ctx context.Context, domainID string, revision int64) ([]*pb.MapRootEndorsement, error) {
+	return e.endorsements[endorsementKey(domainID, revision)], nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/keytransparency/core/keychange"
+)
+
+// KeyChangeStorage implements keychange.Storage
+type KeyChangeStorage struct {
+	hooks map[string]keychange.Webhook
+}
+
+// NewKeyChangeStorage returns a fake keychange.Storage
+func NewKeyChangeStorage() *KeyChangeStorage {
+	return &KeyChangeStorage{
+		hooks: make(map[string]keychange.Webhook),
+	}
+}
+
+func webhookKey(domainID, appID, userID string) string {
+	return domainID + "/" + appID + "/" + userID
+}
+
+// Write adds a webhook, replacing any webhook for the same entry.
+func (k *KeyChangeStorage) Write(ctx context.Context, w *keychange.Webhook) error {
+	k.hooks[webhookKey(w.DomainID, w.AppID, w.UserID)] = *w
+	return nil
+}
+
+// Delete removes the webhook of an entry.
+func (k *KeyChangeStorage) Delete(ctx context.Context, domainID, appID, userID string) error {
+	delete(k.hooks, webhookKey(domainID, appID, userID))
+	return nil
+}
+
+// List returns copies of the webhooks of a domain.
+func (k *KeyChangeStorage) List(ctx context.Context, domainID string) ([]*keychange.Webhook, error) {
+	var hooks []*keychange.Webhook
+	for _, w := range k.hooks {
+		if w.DomainID == domainID {
+			c := w
+			hooks = append(hooks, &c)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return webhookKey("", hooks[i].AppID, hooks[i].UserID) < webhookKey("", hooks[j].AppID, hooks[j].UserID)
+	})
+	return hooks, nil
+}
+
+// SetLastEpoch records the last delivered epoch of a webhook.
+func (k *KeyChangeStorage) SetLastEpoch(ctx context.Context, domainID, appID, userID string, epoch int64) error {
+	key := webhookKey(domainID, appID, userID)
+	if w, ok := k.hooks[key]; ok {
+		w.LastEpoch = epoch
+		k.hooks[key] = w
+	}
+	return nil
+}
@@ -16,23 +16,26 @@ package fake
 
 import (
 	"github.com/google/keytransparency/core/monitorstorage"
+
+	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 )
 
 // MonitorStorage is an in-memory store for the monitoring results.
 type MonitorStorage struct {
-	store  map[int64]*monitorstorage.Result
+	store  map[int64]*pb.VerificationResult
 	latest int64
+	state  *monitorstorage.State
 }
 
 // NewMonitorStorage returns an in-memory implementation of monitorstorage.Interface.
 func NewMonitorStorage() *MonitorStorage {
 	return &MonitorStorage{
-		store: make(map[int64]*monitorstorage.Result),
+		store: make(map[int64]*pb.VerificationResult),
 	}
 }
 
 // Set stores the given data as a MonitoringResult which can be retrieved by Get.
-func (s *MonitorStorage) Set(epoch int64, r *monitorstorage.Result) error {
+func (s *MonitorStorage) Set(epoch int64, r *pb.VerificationResult) error {
 	if _, ok := s.store[epoch]; ok {
 		return monitorstorage.ErrAlreadyStored
 	}
@@ -42,7 +45,7 @@ func (s *MonitorStorage) Set(epoch int64, r *monitorstorage.Result) error {
 }
 
 // Get returns the Result for the given epoch. It returns ErrNotFound if the epoch does not exist.
-func (s *MonitorStorage) Get(epoch int64) (*monitorstorage.Result, error) {
+func (s *MonitorStorage) Get(epoch int64) (*pb.VerificationResult, error) {
 	if result, ok := s.store[epoch]; ok {
 		return result, nil
 	}
@@ -53,3 +56,17 @@ func (s *MonitorStorage) Get(epoch int64) (*monitorstorage.Result, error) {
 func (s *MonitorStorage) LatestEpoch() int64 {
 	return s.latest
 }
+
+// SetState saves the monitor's progress.
+func (s *MonitorStorage) SetState(state *monitorstorage.State) error {
+	s.state = state
+	return nil
+}
+
+// State returns the progress saved by SetState, or ErrNotFound.
+func (s *MonitorStorage) State() (*monitorstorage.State, error) {
+	if s.state == nil {
+		return nil, monitorstorage.ErrNotFound
+	}
+	return s.state, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"fmt"
+)
+
+// PurgeStorage implements purge.Storage
+type PurgeStorage struct {
+	purged map[string]int64
+}
+
+// NewPurgeStorage returns a fake purge.Storage
+func NewPurgeStorage() *PurgeStorage {
+	return &PurgeStorage{
+		purged: make(map[string]int64),
+	}
+}
+
+func purgeKey(domainID string, index []byte) string {
+	return fmt.Sprintf("%v/%x", domainID, index)
+}
+
+// Purge marks the data at index purged through revision.
+func (p *PurgeStorage) Purge(ctx context.Context, domainID string, index []byte, revision int64) error {
+	k := purgeKey(domainID, index)
+	if r, ok := p.purged[k]; !ok || revision > r {
+		p.purged[k] = revision
+	}
+	return nil
+}
+
+// PurgedThrough returns the revision through which index has been purged.
+func (p *PurgeStorage) PurgedThrough(ctx context.Context, domainID string, index []byte) (int64, error) {
+	if r, ok := p.purged[purgeKey(domainID, index)]; ok {
+		return r, nil
+	}
+	return -1, nil
+}
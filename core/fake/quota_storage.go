@@ -0,0 +1,69 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"time"
+)
+
+type quotaRecord struct {
+	domainID, userID string
+	revision         int64
+	ts               time.Time
+}
+
+// QuotaStorage implements quota.Storage
+type QuotaStorage struct {
+	records []quotaRecord
+}
+
+// NewQuotaStorage returns a fake quota.Storage
+func NewQuotaStorage() *QuotaStorage {
+	return &QuotaStorage{}
+}
+
+// Record counts a mutation.
+func (q *QuotaStorage) Record(ctx context.Context, domainID, userID string, revision int64, ts time.Time) error {
+	q.records = append(q.records, quotaRecord{domainID: domainID, userID: userID, revision: revision, ts: ts})
+	return nil
+}
+
+// CountRevision returns the number of mutations recorded for revision.
+func (q *QuotaStorage) CountRevision(ctx context.Context, domainID string, revision int64) (int64, error) {
+	var n int64
+	for _, r := range q.records {
+		if r.domainID == domainID && r.revision == revision {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// CountUser returns the number of mutations recorded for userID since since.
+func (q *QuotaStorage) CountUser(ctx context.Context, domainID, userID string, since time.Time) (int64, time.Time, error) {
+	var n int64
+	var oldest time.Time
+	for _, r := range q.records {
+		if r.domainID != domainID || r.userID != userID || r.ts.Before(since) {
+			continue
+		}
+		if n == 0 || r.ts.Before(oldest) {
+			oldest = r.ts
+		}
+		n++
+	}
+	return n, oldest, nil
+}
@@ -22,9 +22,11 @@ import (
 	tpb "github.com/google/trillian"
 )
 
-// LogServer only stores tree size.
+// LogServer only stores tree size and leaves.
 type LogServer struct {
 	TreeSize int64
+	// Leaves are the queued leaves, in order.
+	Leaves []*tpb.LogLeaf
 }
 
 // NewTrillianLogClient returns a fake trillian log client.
@@ -32,8 +34,9 @@ func NewTrillianLogClient() *LogServer {
 	return &LogServer{}
 }
 
-// QueueLeaf increments the size of the tree.
-func (l *LogServer) QueueLeaf(context.Context, *tpb.QueueLeafRequest, ...grpc.CallOption) (*tpb.QueueLeafResponse, error) {
+// QueueLeaf stores the leaf and increments the size of the tree.
+func (l *LogServer) QueueLeaf(_ context.Context, in *tpb.QueueLeafRequest, _ ...grpc.CallOption) (*tpb.QueueLeafResponse, error) {
+	l.Leaves = append(l.Leaves, in.GetLeaf())
 	l.TreeSize++
 	return nil, nil
 }
@@ -87,7 +90,11 @@ func (*LogServer) GetEntryAndProof(context.Context, *tpb.GetEntryAndProofRequest
 	panic("not implemented")
 }
 
-// GetLeavesByRange is not implemented.
-func (*LogServer) GetLeavesByRange(context.Context, *tpb.GetLeavesByRangeRequest, ...grpc.CallOption) (*tpb.GetLeavesByRangeResponse, error) {
-	panic("not implemented")
+// GetLeavesByRange returns the stored leaves in the range.
+func (l *LogServer) GetLeavesByRange(_ context.Context, in *tpb.GetLeavesByRangeRequest, _ ...grpc.CallOption) (*tpb.GetLeavesByRangeResponse, error) {
+	resp := &tpb.GetLeavesByRangeResponse{}
+	for i := in.GetStartIndex(); i < in.GetStartIndex()+in.GetCount() && i < int64(len(l.Leaves)); i++ {
+		resp.Leaves = append(resp.Leaves, l.Leaves[i])
+	}
+	return resp, nil
 }
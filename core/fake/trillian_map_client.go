@@ -66,14 +66,14 @@ func (m *MapServer) GetLeavesByRevision(ctx context.Context, in *tpb.GetMapLeave
 	}, nil
 }
 
-// SetLeaves is not thread safe. It will store the root metadata.
+// SetLeaves is not thread safe. It will store and return the root metadata.
 func (m *MapServer) SetLeaves(ctx context.Context, in *tpb.SetMapLeavesRequest, opts ...grpc.CallOption) (*tpb.SetMapLeavesResponse, error) {
 	m.revision++
 	m.roots[m.revision] = &tpb.SignedMapRoot{
 		Metadata:    in.GetMetadata(),
 		MapRevision: m.revision,
 	}
-	return nil, nil
+	return &tpb.SetMapLeavesResponse{MapRoot: m.roots[m.revision]}, nil
 }
 
 // GetSignedMapRoot returns the current map root.
@@ -14,10 +14,16 @@
 
 // Package integration exports a set of unit tests that can be run by impl/integration
 // or any other specific instantiation of KeyTransparency.
+//
+// AllTests is a conformance suite: an implementation passes it by providing an
+// Env that describes its optional features in Capabilities and running every
+// test with NamedTestFn.Run. Tests that need a missing capability are skipped.
 package integration
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/keytransparency/core/client/grpcc"
@@ -28,10 +34,70 @@ import (
 
 // Env holds a complete testing environment for end-to-end tests.
 type Env struct {
-	Client   *grpcc.Client
-	Cli      pb.KeyTransparencyClient
-	Domain   *pb.Domain
+	Client *grpcc.Client
+	Cli    pb.KeyTransparencyClient
+	Domain *pb.Domain
+	// Receiver sequences queued mutations. It must only create epochs when
+	// flushed so that tests control exactly when epochs are created.
 	Receiver mutator.Receiver
+	// Capabilities lists the optional features of the server under test.
+	Capabilities Capabilities
+}
+
+// Capabilities are the optional features of a KeyTransparency server.
+type Capabilities struct {
+	// Streaming servers implement GetEpochStream and ListMutationsStream.
+	Streaming bool
+	// Batch servers implement the batch variants of the read APIs.
+	Batch bool
+	// Auth servers reject updates that are not authenticated as the user
+	// being updated with the credentials of WithOutgoingFakeAuth.
+	Auth bool
+}
+
+// missing returns the names of the capabilities in want that c lacks.
+func (c Capabilities) missing(want Capabilities) []string {
+	var ret []string
+	if want.Streaming && !c.Streaming {
+		ret = append(ret, "Streaming")
+	}
+	if want.Batch && !c.Batch {
+		ret = append(ret, "Batch")
+	}
+	if want.Auth && !c.Auth {
+		ret = append(ret, "Auth")
+	}
+	return ret
+}
+
+// AdvanceEpoch sequences all queued mutations into a new epoch. It returns
+// once the new epoch is visible to clients, so tests never need to wait for
+// the sequencer.
+func (e *Env) AdvanceEpoch(ctx context.Context) error {
+	before, err := e.latestEpoch(ctx)
+	if err != nil {
+		return err
+	}
+	if err := e.Receiver.Flush(ctx); err != nil {
+		return fmt.Errorf("Flush(): %w", err)
+	}
+	after, err := e.latestEpoch(ctx)
+	if err != nil {
+		return err
+	}
+	if after != before+1 {
+		return fmt.Errorf("epoch %v after flush, want %v", after, before+1)
+	}
+	return nil
+}
+
+// latestEpoch returns the most recent epoch served by the key server.
+func (e *Env) latestEpoch(ctx context.Context) (int64, error) {
+	resp, err := e.Cli.GetEntry(ctx, &pb.GetEntryRequest{DomainId: e.Domain.GetDomainId()})
+	if err != nil {
+		return 0, fmt.Errorf("GetEntry(): %w", err)
+	}
+	return resp.GetSmr().GetMapRevision(), nil
 }
 
 // NamedTestFn is a binding between a readable test name (used for a Go subtest)
@@ -39,6 +105,16 @@ type Env struct {
 type NamedTestFn struct {
 	Name string
 	Fn   func(context.Context, *Env, *testing.T)
+	// Requires lists the capabilities the test depends on.
+	Requires Capabilities
+}
+
+// Run runs the test in env, or skips it if env lacks a required capability.
+func (n NamedTestFn) Run(ctx context.Context, env *Env, t *testing.T) {
+	if missing := env.Capabilities.missing(n.Requires); len(missing) > 0 {
+		t.Skipf("%v requires capabilities: %v", n.Name, strings.Join(missing, ", "))
+	}
+	n.Fn(ctx, env, t)
 }
 
 // AllTests contains all the integration tests.
@@ -46,9 +122,11 @@ type NamedTestFn struct {
 // This is done so that tests can be run in different environments in a portable way.
 var AllTests = []NamedTestFn{
 	// Client Tests
-	{"TestEmptyGetAndUpdate", TestEmptyGetAndUpdate},
-	{"TestUpdateValidation", TestUpdateValidation},
-	{"TestListHistory", TestListHistory},
+	{Name: "TestEmptyGetAndUpdate", Fn: TestEmptyGetAndUpdate},
+	{Name: "TestUpdateValidation", Fn: TestUpdateValidation, Requires: Capabilities{Auth: true}},
+	{Name: "TestListUserApps", Fn: TestListUserApps, Requires: Capabilities{Auth: true}},
+	{Name: "TestListHistory", Fn: TestListHistory},
 	// Monitor Tests
-	{"TestMonitor", TestMonitor},
+	{Name: "TestMonitor", Fn: TestMonitor},
+	{Name: "TestEpochStream", Fn: TestEpochStream, Requires: Capabilities{Streaming: true}},
 }
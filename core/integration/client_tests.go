@@ -184,13 +184,19 @@ func TestEmptyGetAndUpdate(ctx context.Context, env *Env, t *testing.T) {
 			}
 			// Update profile.
 			if tc.insert {
-				m, err := env.Client.Update(tc.ctx, appID, tc.userID, primaryKey, tc.signers, tc.authorizedKeys)
+				r, err := env.Client.Update(tc.ctx, appID, tc.userID, primaryKey, tc.signers, tc.authorizedKeys)
 				if got, want := err, grpcc.ErrRetry; got != want {
 					t.Fatalf("Update(%v): %v, want %v", tc.userID, got, want)
 				}
-				env.Receiver.Flush(tc.ctx)
-				if err := env.Client.Retry(tc.ctx, m, tc.signers); err != nil {
-					t.Errorf("Retry(%v): %v, want nil", m, err)
+				if err := env.AdvanceEpoch(tc.ctx); err != nil {
+					t.Fatalf("AdvanceEpoch(): %v", err)
+				}
+				retried, err := env.Client.Retry(tc.ctx, r.Mutation, tc.signers)
+				if err != nil {
+					t.Fatalf("Retry(%v): %v, want nil", r.Mutation, err)
+				}
+				if got, want := retried.Revision, r.Revision; got <= want {
+					t.Errorf("Retry(%v).Revision: %v, want > %v", r.Mutation, got, want)
 				}
 			}
 		})
@@ -202,7 +208,7 @@ func TestEmptyGetAndUpdate(ctx context.Context, env *Env, t *testing.T) {
 func (e *Env) checkProfile(userID, appID string, want bool) error {
 	profile, _, err := e.Client.GetEntry(context.Background(), userID, appID)
 	if err != nil {
-		return fmt.Errorf("GetEntry(%v): %v, want nil", userID, err)
+		return fmt.Errorf("GetEntry(%v): %w, want nil", userID, err)
 	}
 	if got := profile != nil; got != want {
 		return fmt.Errorf("GetEntry(%v): %v, want %v", userID, profile, want)
@@ -238,16 +244,18 @@ func TestUpdateValidation(ctx context.Context, env *Env, t *testing.T) {
 		{true, WithOutgoingFakeAuth(ctx, "dave"), "dave", profile, signers, authorizedKeys},
 		{true, WithOutgoingFakeAuth(ctx, "eve"), "eve", profile, signers, authorizedKeys},
 	} {
-		m, err := env.Client.Update(tc.ctx, appID, tc.userID, tc.profile, tc.signers, tc.authorizedKeys)
+		r, err := env.Client.Update(tc.ctx, appID, tc.userID, tc.profile, tc.signers, tc.authorizedKeys)
 
 		if tc.want {
 			// The first update response is always a retry.
 			if got, want := err, grpcc.ErrRetry; got != want {
 				t.Fatalf("Update(%v): %v, want %v", tc.userID, got, want)
 			}
-			env.Receiver.Flush(tc.ctx)
-			if err := env.Client.Retry(tc.ctx, m, signers); err != nil {
-				t.Errorf("Retry(%v): %v, want nil", m, err)
+			if err := env.AdvanceEpoch(tc.ctx); err != nil {
+				t.Fatalf("AdvanceEpoch(): %v", err)
+			}
+			if _, err := env.Client.Retry(tc.ctx, r.Mutation, signers); err != nil {
+				t.Errorf("Retry(%v): %v, want nil", r.Mutation, err)
 			}
 		} else {
 			if got, want := err, grpcc.ErrRetry; got == want {
@@ -257,6 +265,47 @@ func TestUpdateValidation(ctx context.Context, env *Env, t *testing.T) {
 	}
 }
 
+// TestListUserApps verifies that users can list the apps they have entries
+// for and that other users cannot.
+func TestListUserApps(ctx context.Context, env *Env, t *testing.T) {
+	if !env.Domain.GetAppListing() {
+		t.Skip("App listing is disabled for the domain")
+	}
+	env.Client.RetryCount = 0
+	userID := "frank"
+	uctx := WithOutgoingFakeAuth(ctx, userID)
+	signers := []signatures.Signer{createSigner(t, testPrivKey1)}
+	authorizedKeys := []*keyspb.PublicKey{getAuthorizedKey(testPubKey1)}
+
+	want := map[string][]byte{
+		"app1": []byte("profile1"),
+		"app2": []byte("profile2"),
+	}
+	for appID, profile := range want {
+		r, err := env.Client.Update(uctx, appID, userID, profile, signers, authorizedKeys)
+		if err != grpcc.ErrRetry {
+			t.Fatalf("Update(%v): %v, want %v", appID, err, grpcc.ErrRetry)
+		}
+		if err := env.AdvanceEpoch(uctx); err != nil {
+			t.Fatalf("AdvanceEpoch(): %v", err)
+		}
+		if _, err := env.Client.Retry(uctx, r.Mutation, signers); err != nil {
+			t.Fatalf("Retry(%v): %v", appID, err)
+		}
+	}
+
+	got, err := env.Client.ListUserApps(uctx, userID)
+	if err != nil {
+		t.Fatalf("ListUserApps(): %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListUserApps(): %s, want %s", got, want)
+	}
+	if _, err := env.Client.ListUserApps(WithOutgoingFakeAuth(ctx, "mallory"), userID); err == nil {
+		t.Errorf("ListUserApps() by another user: nil, want error")
+	}
+}
+
 // TestListHistory verifies that repeated history values get collapsed properly.
 func TestListHistory(ctx context.Context, env *Env, t *testing.T) {
 	userID := "bob"
@@ -329,7 +378,9 @@ func (e *Env) setupHistory(ctx context.Context, domain *pb.Domain, userID string
 				return fmt.Errorf("Update(%v, %v)=(_, %v), want (_, %v)", userID, i, got, want)
 			}
 		}
-		e.Receiver.Flush(ctx)
+		if err := e.AdvanceEpoch(ctx); err != nil {
+			return err
+		}
 	}
 	return nil
 }
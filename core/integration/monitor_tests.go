@@ -17,8 +17,8 @@ package integration
 import (
 	"context"
 	"testing"
-	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/keytransparency/core/client/grpcc"
 	"github.com/google/keytransparency/core/crypto/signatures"
 	"github.com/google/keytransparency/core/fake"
@@ -64,9 +64,15 @@ func TestMonitor(ctx context.Context, env *Env, t *testing.T) {
 	}
 	store := fake.NewMonitorStorage()
 	// TODO(ismail): setup and use a real logVerifier instead:
-	mon, err := monitor.New(env.Cli, fake.NewFakeTrillianLogVerifier(),
-		mapTree.TreeId, mapHasher, mapPubKey,
-		crypto.NewSHA256Signer(signer), store)
+	mon, err := monitor.New(monitor.Options{
+		Client:      env.Cli,
+		LogVerifier: fake.NewFakeTrillianLogVerifier(),
+		MapID:       mapTree.TreeId,
+		MapHasher:   mapHasher,
+		MapPubKey:   mapPubKey,
+		Signer:      crypto.NewSHA256Signer(signer),
+		Store:       store,
+	})
 	if err != nil {
 		t.Fatalf("Couldn't create monitor: %v", err)
 	}
@@ -111,14 +117,14 @@ func TestMonitor(ctx context.Context, env *Env, t *testing.T) {
 			}
 		}
 
-		env.Receiver.Flush(ctx)
+		if err := env.AdvanceEpoch(ctx); err != nil {
+			t.Fatalf("AdvanceEpoch(): %v", err)
+		}
 
 		domainID := env.Domain.DomainId
-		cctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
-		if err := mon.ProcessLoop(cctx, domainID, tc.queryEpoch-1, 40*time.Millisecond); err != context.DeadlineExceeded {
+		if err := mon.ProcessEpochs(ctx, domainID, tc.queryEpoch-1, tc.queryEpoch); err != nil {
 			t.Errorf("Monitor could not process mutations: %v", err)
 		}
-		cancel()
 
 		mresp, err := store.Get(tc.queryEpoch)
 		if err != nil {
@@ -130,3 +136,46 @@ func TestMonitor(ctx context.Context, env *Env, t *testing.T) {
 		}
 	}
 }
+
+// TestEpochStream verifies that GetEpochStream returns the epochs served by
+// GetEpoch.
+func TestEpochStream(ctx context.Context, env *Env, t *testing.T) {
+	for i := 0; i < 2; i++ {
+		if err := env.AdvanceEpoch(ctx); err != nil {
+			t.Fatalf("AdvanceEpoch(): %v", err)
+		}
+	}
+	latest, err := env.latestEpoch(ctx)
+	if err != nil {
+		t.Fatalf("latestEpoch(): %v", err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	domainID := env.Domain.DomainId
+	stream, err := env.Cli.GetEpochStream(cctx, &pb.GetEpochRequest{
+		DomainId:      domainID,
+		Epoch:         1,
+		FirstTreeSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("GetEpochStream(): %v", err)
+	}
+	for i := int64(1); i <= latest; i++ {
+		got, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv(epoch %v): %v", i, err)
+		}
+		want, err := env.Cli.GetEpoch(ctx, &pb.GetEpochRequest{
+			DomainId:      domainID,
+			Epoch:         i,
+			FirstTreeSize: 1,
+		})
+		if err != nil {
+			t.Fatalf("GetEpoch(%v): %v", i, err)
+		}
+		if !proto.Equal(got.GetSmr(), want.GetSmr()) {
+			t.Errorf("Streamed epoch %v: %v, want %v", i, got.GetSmr(), want.GetSmr())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keychange stores the webhooks that users register to be notified
+// of changes to their own entries.
+//
+// Identity owners whose devices are offline cannot watch their entries, so
+// the key server delivers an event to the owner's webhook for every
+// published change instead. Events are hints: owners verify each change
+// against the map before acting on it.
+package keychange
+
+import "context"
+
+// Webhook is a URL that is notified of changes to a single entry.
+type Webhook struct {
+	DomainID string
+	AppID    string
+	UserID   string
+	// Index is the map index of the entry.
+	Index []byte
+	URL   string
+	// LastEpoch is the last epoch whose changes were delivered.
+	LastEpoch int64
+}
+
+// Storage stores webhooks.
+type Storage interface {
+	// Write adds w, replacing any webhook for the same entry.
+	Write(ctx context.Context, w *Webhook) error
+	// Delete removes the webhook of an entry, if there is one.
+	Delete(ctx context.Context, domainID, appID, userID string) error
+	// List returns the webhooks of domainID.
+	List(ctx context.Context, domainID string) ([]*Webhook, error)
+	// SetLastEpoch records that the changes up to epoch were delivered.
+	SetLastEpoch(ctx context.Context, domainID, appID, userID string, epoch int64) error
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google/keytransparency/core/authentication"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ListUserApps returns the apps that the authenticated user has entries for.
+// Users may only list their own apps, and only in domains with app listing
+// enabled.
+func (s *Server) ListUserApps(ctx context.Context, in *pb.ListUserAppsRequest) (*pb.ListUserAppsResponse, error) {
+	if in.GetDomainId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Please specify a domain_id")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		glog.Errorf("adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	if !d.AppListing {
+		return nil, status.Errorf(codes.FailedPrecondition, "App listing is disabled for domain %v", d.DomainID)
+	}
+
+	sctx, err := s.auth.ValidateCreds(ctx)
+	switch err {
+	case nil:
+		break // Authentication succeeded.
+	case authentication.ErrMissingAuth:
+		return nil, status.Errorf(codes.Unauthenticated, "Missing authentication header")
+	default:
+		glog.Warningf("Auth failed: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+	// The list of apps reveals which services a user is registered with, so
+	// it is only disclosed to the user themselves.
+	if sctx.Identity() != in.GetUserId() {
+		return nil, status.Errorf(codes.PermissionDenied, "Unauthorized")
+	}
+
+	appIDs, err := s.apps.List(ctx, d.DomainID, in.GetUserId())
+	if err != nil {
+		glog.Errorf("apps.List(%v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot list apps")
+	}
+	return &pb.ListUserAppsResponse{AppIds: appIDs}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/authentication"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestListUserApps(t *testing.T) {
+	ctx := context.Background()
+	domains := fake.NewDomainStorage()
+	for _, d := range []*domain.Domain{
+		{DomainID: "listing", AppListing: true},
+		{DomainID: "nolisting"},
+	} {
+		if err := domains.Write(ctx, d); err != nil {
+			t.Fatalf("Write(%v): %v", d.DomainID, err)
+		}
+	}
+	apps := fake.NewAppIndexStorage()
+	for _, appID := range []string{"app2", "app1"} {
+		if err := apps.Add(ctx, "listing", "alice", appID); err != nil {
+			t.Fatalf("Add(): %v", err)
+		}
+	}
+	srv := &Server{
+		domains: domains,
+		apps:    apps,
+		auth:    authentication.NewFake(),
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		caller   string // Authenticated identity. Empty for none.
+		domainID string
+		userID   string
+		want     []string
+		wantCode codes.Code
+	}{
+		{desc: "own apps", caller: "alice", domainID: "listing", userID: "alice", want: []string{"app1", "app2"}},
+		{desc: "no apps", caller: "bob", domainID: "listing", userID: "bob"},
+		{desc: "other user", caller: "bob", domainID: "listing", userID: "alice", wantCode: codes.PermissionDenied},
+		{desc: "unauthenticated", domainID: "listing", userID: "alice", wantCode: codes.Unauthenticated},
+		{desc: "disabled", caller: "alice", domainID: "nolisting", userID: "alice", wantCode: codes.FailedPrecondition},
+		{desc: "missing domain", caller: "alice", userID: "alice", wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cctx := ctx
+			if tc.caller != "" {
+				cctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "FakeCredential "+tc.caller))
+			}
+			resp, err := srv.ListUserApps(cctx, &pb.ListUserAppsRequest{
+				DomainId: tc.domainID,
+				UserId:   tc.userID,
+			})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("ListUserApps(): %v, want %v", err, tc.wantCode)
+			}
+			if got := resp.GetAppIds(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ListUserApps(): %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
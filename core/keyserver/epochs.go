@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/smhlog"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 	tpb "github.com/google/trillian"
@@ -97,12 +98,17 @@ func (s *Server) getEpochByRevision(ctx context.Context, d *domain.Domain, first
 	if err != nil {
 		return nil, err
 	}
+	endorsements, err := s.mapRootEndorsements(ctx, d, respEpoch)
+	if err != nil {
+		return nil, err
+	}
 	return &pb.Epoch{
 		DomainId:       d.DomainID,
 		Smr:            resp.GetMapRoot(),
 		LogRoot:        logProof.LogRoot,
 		LogConsistency: logProof.LogConsistency.GetHashes(),
 		LogInclusion:   logProof.LogInclusion.GetHashes(),
+		Endorsements:   endorsements,
 	}, nil
 }
 
@@ -148,6 +154,9 @@ func (s *Server) ListMutations(ctx context.Context, in *pb.ListMutationsRequest)
 	for i, p := range proofs {
 		mutations[i].LeafProof = p
 	}
+	if err := s.previousLeafProofs(ctx, d, mutations, in.Epoch-1); err != nil {
+		return nil, err
+	}
 
 	nextPageToken := ""
 	if len(mutations) == int(in.PageSize) {
@@ -164,6 +173,62 @@ func (*Server) ListMutationsStream(in *pb.ListMutationsRequest, stream pb.KeyTra
 	return status.Error(codes.Unimplemented, "ListMutationStream is unimplemented")
 }
 
+// GetLogConsistencyChain returns consistency proofs between each consecutive
+// pair of requested tree sizes and from the last requested size to the latest
+// log root.
+func (s *Server) GetLogConsistencyChain(ctx context.Context, in *pb.GetLogConsistencyChainRequest) (*pb.LogConsistencyChain, error) {
+	// Lookup log and map info.
+	d, err := s.domains.Read(ctx, in.DomainId, false)
+	if err != nil {
+		glog.Errorf("GetLogConsistencyChain(): adminstorage.Read(%v): %v", in.DomainId, err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	sth, err := s.latestLogRoot(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGetLogConsistencyChainRequest(in, sth.GetTreeSize()); err != nil {
+		glog.Errorf("validateGetLogConsistencyChainRequest(%v): %v", in, err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+
+	smhLog, err := s.smhLog(d)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int64, 0, len(in.GetTreeSizes())+1)
+	sizes = append(sizes, in.GetTreeSizes()...)
+	sizes = append(sizes, sth.GetTreeSize())
+	proofs := make([]*pb.LogConsistencyProof, 0, len(sizes)-1)
+	for i := 1; i < len(sizes); i++ {
+		first, second := sizes[i-1], sizes[i]
+		proof := &pb.LogConsistencyProof{
+			FirstTreeSize:  first,
+			SecondTreeSize: second,
+		}
+		// Identical tree sizes need no proof.
+		if first != second {
+			resp, err := smhLog.Log.GetConsistencyProof(ctx,
+				&tpb.GetConsistencyProofRequest{
+					LogId:          d.LogID,
+					FirstTreeSize:  first,
+					SecondTreeSize: second,
+				})
+			if err != nil {
+				glog.Errorf("GetLogConsistencyChain(): log.GetConsistency(%v, %v, %v): %v",
+					d.LogID, first, second, err)
+				return nil, status.Errorf(codes.Internal, "Cannot fetch log consistency proof")
+			}
+			proof.Hashes = resp.GetProof().GetHashes()
+		}
+		proofs = append(proofs, proof)
+	}
+	return &pb.LogConsistencyChain{
+		LogRoot: sth,
+		Proofs:  proofs,
+	}, nil
+}
+
 // logProof holds the proof for a signed map root up to signed log root.
 type logProof struct {
 	LogRoot        *tpb.SignedLogRoot
@@ -179,8 +244,12 @@ func (s *Server) logProofs(ctx context.Context, d *domain.Domain, firstTreeSize
 	}
 
 	// Inclusion proof.
+	smhLog, err := s.smhLog(d)
+	if err != nil {
+		return nil, err
+	}
 	secondTreeSize := logRoot.GetTreeSize()
-	logInclusion, err := s.tlog.GetInclusionProof(ctx,
+	logInclusion, err := smhLog.Log.GetInclusionProof(ctx,
 		&tpb.GetInclusionProofRequest{
 			LogId: d.LogID,
 			// SignedMapRoot must be in the log at MapRevision.
@@ -198,9 +267,37 @@ func (s *Server) logProofs(ctx context.Context, d *domain.Domain, firstTreeSize
 	}, nil
 }
 
+// mapRootEndorsements returns the endorsements of the map root of d at
+// revision. It returns none if the domain has no endorsement policy.
+func (s *Server) mapRootEndorsements(ctx context.Context, d *domain.Domain, revision int64) ([]*pb.MapRootEndorsement, error) {
+	if s.endorsements == nil || d.EndorsementPolicy.GetQuorum() <= 0 {
+		return nil, nil
+	}
+	endorsements, err := s.endorsements.Read(ctx, d.DomainID, revision)
+	if err != nil {
+		glog.Errorf("endorsements.Read(%v, %v): %v", d.DomainID, revision, err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch map root endorsements")
+	}
+	return endorsements, nil
+}
+
+// smhLog returns the backend of the SMH log of d.
+func (s *Server) smhLog(d *domain.Domain) (*smhlog.Backend, error) {
+	backend, err := s.logs.Get(d.LogBackend)
+	if err != nil {
+		glog.Errorf("smhLog(%v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot find log of %v", d.DomainID)
+	}
+	return backend, nil
+}
+
 func (s *Server) latestLogRoot(ctx context.Context, d *domain.Domain) (*tpb.SignedLogRoot, error) {
+	smhLog, err := s.smhLog(d)
+	if err != nil {
+		return nil, err
+	}
 	// Fresh Root.
-	logRoot, err := s.tlog.GetLatestSignedLogRoot(ctx,
+	logRoot, err := smhLog.Log.GetLatestSignedLogRoot(ctx,
 		&tpb.GetLatestSignedLogRootRequest{
 			LogId: d.LogID,
 		})
@@ -221,9 +318,18 @@ func (s *Server) latestLogRootProof(ctx context.Context, d *domain.Domain, first
 	}
 	// Consistency proof.
 	secondTreeSize := sth.GetTreeSize()
+	if firstTreeSize > secondTreeSize {
+		// The client has seen a newer log root than this server.
+		return nil, nil, status.Errorf(codes.OutOfRange,
+			"first_tree_size %v is beyond the log size %v", firstTreeSize, secondTreeSize)
+	}
 	var logConsistency *tpb.GetConsistencyProofResponse
 	if firstTreeSize != 0 {
-		logConsistency, err = s.tlog.GetConsistencyProof(ctx,
+		smhLog, err := s.smhLog(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		logConsistency, err = smhLog.Log.GetConsistencyProof(ctx,
 			&tpb.GetConsistencyProofRequest{
 				LogId:          d.LogID,
 				FirstTreeSize:  firstTreeSize,
@@ -266,6 +372,30 @@ func parseToken(token string) (int64, error) {
 	return seq, nil
 }
 
+// previousLeafProofs sets the previous leaf proofs of mutations that move
+// entries from their index under the previous domain VRF.
+func (s *Server) previousLeafProofs(ctx context.Context, d *domain.Domain, mutations []*pb.MutationProof, epoch int64) error {
+	var indexes [][]byte
+	var moved []*pb.MutationProof
+	for _, m := range mutations {
+		if p := m.GetMutation().GetPreviousIndex(); len(p) != 0 {
+			indexes = append(indexes, p)
+			moved = append(moved, m)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	proofs, err := s.inclusionProofs(ctx, d, indexes, epoch)
+	if err != nil {
+		return err
+	}
+	for i, p := range proofs {
+		moved[i].PreviousLeafProof = p
+	}
+	return nil
+}
+
 func (s *Server) inclusionProofs(ctx context.Context, d *domain.Domain, indexes [][]byte, epoch int64) ([]*tpb.MapLeafInclusion, error) {
 	getResp, err := s.tmap.GetLeavesByRevision(ctx, &tpb.GetMapLeavesByRevisionRequest{
 		MapId:    d.MapID,
@@ -280,5 +410,13 @@ func (s *Server) inclusionProofs(ctx context.Context, d *domain.Domain, indexes
 		glog.Errorf("inclusionProofs(): GetLeavesByRevision() len: %v, want %v", got, want)
 		return nil, status.Error(codes.Internal, "Failed fetching map leaf")
 	}
+	// Proofs only need the leaf values. The committed data in ExtraData is
+	// left out, so that data withheld by a purge is only ever served, or
+	// withheld, by GetEntry.
+	for _, l := range getResp.GetMapLeafInclusion() {
+		if l.GetLeaf() != nil {
+			l.Leaf.ExtraData = nil
+		}
+	}
 	return getResp.GetMapLeafInclusion(), nil
 }
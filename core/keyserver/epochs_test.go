@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/keytransparency/core/domain"
 	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/smhlog"
 	"github.com/google/trillian/testonly/integration"
 
 	"github.com/golang/protobuf/proto"
@@ -114,7 +115,7 @@ func TestListMutations(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			srv := &Server{
 				domains:   fakeAdmin,
-				tlog:      logEnv.Log,
+				logs:      smhlog.Backends{smhlog.Default: {Log: logEnv.Log}},
 				tmap:      fakeMap,
 				mutations: fakeMutations,
 			}
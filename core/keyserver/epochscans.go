@@ -0,0 +1,112 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// epochScanCacheSize is the number of scanned epochs kept in memory.
+const epochScanCacheSize = 64
+
+// epochScans shares the scans of the mutations of an epoch between the
+// watchers of a domain, so that the cost of WatchEntry and WatchKeyChanges
+// grows with the number of epochs rather than the number of watchers.
+// Concurrent requests for an epoch wait for a single scan, and the most
+// recently scanned epochs are cached. The zero value is ready to use.
+type epochScans struct {
+	mu    sync.Mutex
+	scans map[epochKey]*epochScan
+	order []epochKey // In the order scans were started.
+}
+
+type epochKey struct {
+	domainID string
+	epoch    int64
+}
+
+// epochScan is a scan of an epoch, complete once done is closed. The
+// mutations are shared and must not be modified.
+type epochScan struct {
+	done      chan struct{}
+	mutations map[string][]*pb.Entry
+	err       error
+}
+
+// get returns the mutations of epoch in domainID, calling scan unless another
+// caller already did. Failed scans are not cached: callers that waited for
+// one scan the epoch again.
+func (c *epochScans) get(ctx context.Context, domainID string, epoch int64,
+	scan func() (map[string][]*pb.Entry, error)) (map[string][]*pb.Entry, error) {
+	key := epochKey{domainID: domainID, epoch: epoch}
+	for {
+		e, owner := c.start(key)
+		if owner {
+			e.mutations, e.err = scan()
+			if e.err != nil {
+				c.forget(key, e)
+			}
+			close(e.done)
+			return e.mutations, e.err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.done:
+		}
+		if e.err == nil {
+			return e.mutations, nil
+		}
+	}
+}
+
+// start returns the scan of key, and whether the caller must perform it.
+func (c *epochScans) start(key epochKey) (*epochScan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.scans[key]; ok {
+		return e, false
+	}
+	if c.scans == nil {
+		c.scans = make(map[epochKey]*epochScan)
+	}
+	e := &epochScan{done: make(chan struct{})}
+	c.scans[key] = e
+	c.order = append(c.order, key)
+	for len(c.order) > epochScanCacheSize {
+		delete(c.scans, c.order[0])
+		c.order = c.order[1:]
+	}
+	return e, true
+}
+
+// forget removes the failed scan e of key.
+func (c *epochScans) forget(key epochKey, e *epochScan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.scans[key] != e {
+		return
+	}
+	delete(c.scans, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
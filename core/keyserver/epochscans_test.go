@@ -0,0 +1,111 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestEpochScansShared(t *testing.T) {
+	ctx := context.Background()
+	var c epochScans
+	var mu sync.Mutex
+	scans := 0
+	release := make(chan struct{})
+	scan := func() (map[string][]*pb.Entry, error) {
+		mu.Lock()
+		scans++
+		mu.Unlock()
+		<-release
+		return map[string][]*pb.Entry{"alice": {{}}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := c.get(ctx, "domain", 1, scan)
+			if err != nil || len(m["alice"]) != 1 {
+				t.Errorf("get(): %v, %v, want alice's mutation", m, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	// Later watchers are served from the cache.
+	if _, err := c.get(ctx, "domain", 1, scan); err != nil {
+		t.Errorf("get(): %v", err)
+	}
+	if scans != 1 {
+		t.Errorf("epoch scanned %v times, want 1", scans)
+	}
+}
+
+func TestEpochScansErrors(t *testing.T) {
+	ctx := context.Background()
+	var c epochScans
+	scans := 0
+	fail := errors.New("unavailable")
+	scan := func() (map[string][]*pb.Entry, error) {
+		scans++
+		if scans == 1 {
+			return nil, fail
+		}
+		return map[string][]*pb.Entry{}, nil
+	}
+	if _, err := c.get(ctx, "domain", 1, scan); err != fail {
+		t.Errorf("get(): %v, want %v", err, fail)
+	}
+	if _, err := c.get(ctx, "domain", 1, scan); err != nil {
+		t.Errorf("get() after a failure: %v", err)
+	}
+	if scans != 2 {
+		t.Errorf("epoch scanned %v times, want 2", scans)
+	}
+}
+
+func TestEpochScansEviction(t *testing.T) {
+	ctx := context.Background()
+	var c epochScans
+	scans := make(map[int64]int)
+	get := func(epoch int64) {
+		if _, err := c.get(ctx, "domain", epoch, func() (map[string][]*pb.Entry, error) {
+			scans[epoch]++
+			return nil, nil
+		}); err != nil {
+			t.Fatalf("get(%v): %v", epoch, err)
+		}
+	}
+	for epoch := int64(0); epoch <= epochScanCacheSize; epoch++ {
+		get(epoch)
+	}
+	if got := len(c.scans); got != epochScanCacheSize {
+		t.Errorf("cached %v epochs, want %v", got, epochScanCacheSize)
+	}
+	get(epochScanCacheSize) // Cached.
+	get(0)                  // Evicted.
+	if got := scans[0]; got != 2 {
+		t.Errorf("epoch 0 scanned %v times, want 2", got)
+	}
+	if got := scans[epochScanCacheSize]; got != 1 {
+		t.Errorf("epoch %v scanned %v times, want 1", epochScanCacheSize, got)
+	}
+}
@@ -0,0 +1,479 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/authentication"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/keychange"
+	"github.com/google/keytransparency/core/mutator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+const (
+	// defaultKeyChangeInterval is how often WatchKeyChanges checks for new
+	// epochs.
+	defaultKeyChangeInterval = 5 * time.Second
+	// maxWebhookEpochs bounds the epochs scanned for a domain in one
+	// delivery pass, so that webhooks far behind catch up gradually.
+	maxWebhookEpochs = int64(100)
+	// webhookTimeout bounds the delivery of a single event.
+	webhookTimeout = 10 * time.Second
+	// maxWatchEpochs is how far behind the latest published epoch a watch
+	// may start. Older changes are read with ListEntryHistory.
+	maxWatchEpochs = int64(1000)
+)
+
+// authenticateOwner returns an error unless the caller is userID. Key change
+// events reveal when a user's keys change, so they are only disclosed to the
+// user themselves.
+func (s *Server) authenticateOwner(ctx context.Context, userID string) error {
+	sctx, err := s.auth.ValidateCreds(ctx)
+	switch err {
+	case nil:
+		break // Authentication succeeded.
+	case authentication.ErrMissingAuth:
+		return status.Errorf(codes.Unauthenticated, "Missing authentication header")
+	default:
+		glog.Warningf("Auth failed: %v", err)
+		return status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+	if sctx.Identity() != userID {
+		return status.Errorf(codes.PermissionDenied, "Unauthorized")
+	}
+	return nil
+}
+
+// WatchKeyChanges streams the changes to the caller's own entry as epochs are
+// published. Epochs are published once their map root is in the log.
+func (s *Server) WatchKeyChanges(in *pb.WatchKeyChangesRequest, stream pb.KeyTransparency_WatchKeyChangesServer) error {
+	ctx := stream.Context()
+	if err := validateWatchKeyChangesRequest(in); err != nil {
+		glog.Errorf("validateWatchKeyChangesRequest(%v): %v", in, err)
+		return status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		glog.Errorf("WatchKeyChanges(): adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	if err := s.authenticateOwner(ctx, in.GetUserId()); err != nil {
+		return err
+	}
+	index, _, err := s.indexFunc(ctx, d, in.GetAppId(), in.GetUserId())
+	if err != nil {
+		return err
+	}
+
+	last, err := s.watchStart(ctx, d, in.GetStartEpoch())
+	if err != nil {
+		return err
+	}
+	interval := s.keyChangeInterval
+	if interval == 0 {
+		interval = defaultKeyChangeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		published, err := s.publishedEpoch(ctx, d)
+		if err != nil {
+			return err
+		}
+		for ; last < published; last++ {
+			epoch := last + 1
+			mutations, err := s.epochMutations(ctx, d, epoch)
+			if err != nil {
+				return err
+			}
+			changes := mutations[string(index[:])]
+			if len(changes) == 0 {
+				continue
+			}
+			epochTime, err := s.epochTime(ctx, d, epoch)
+			if err != nil {
+				return err
+			}
+			for _, e := range keyChangeEvents(d.DomainID, in.GetAppId(), in.GetUserId(), epoch, epochTime, changes) {
+				if err := stream.Send(e); err != nil {
+					return err
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchStart returns the epoch after which a watch from startEpoch begins. A
+// zero startEpoch watches the epochs published from now on. Watches may not
+// start more than maxWatchEpochs before the latest published epoch, so that a
+// single request cannot make the server scan the whole history of a domain.
+func (s *Server) watchStart(ctx context.Context, d *domain.Domain, startEpoch int64) (int64, error) {
+	published, err := s.publishedEpoch(ctx, d)
+	if err != nil {
+		return 0, err
+	}
+	if startEpoch == 0 {
+		return published, nil
+	}
+	if published-startEpoch >= maxWatchEpochs {
+		return 0, status.Errorf(codes.OutOfRange,
+			"start_epoch %v is more than %v epochs old, use ListEntryHistory", startEpoch, maxWatchEpochs)
+	}
+	return startEpoch - 1, nil
+}
+
+// WatchEntry streams a user's entry each time it changes in a published
+// epoch. Each response carries the proofs of GetEntry at the epoch of the
+// change. The log consistency of the first response is proven from the tree
+// size in the request, and that of each later response from the log root of
+// the response before it.
+func (s *Server) WatchEntry(in *pb.WatchEntryRequest, stream pb.KeyTransparency_WatchEntryServer) error {
+	ctx := stream.Context()
+	if err := validateWatchEntryRequest(in); err != nil {
+		glog.Errorf("validateWatchEntryRequest(%v): %v", in, err)
+		return status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		glog.Errorf("WatchEntry(): adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	index, _, err := s.indexFunc(ctx, d, in.GetAppId(), in.GetUserId())
+	if err != nil {
+		return err
+	}
+
+	treeSize := in.GetFirstTreeSize()
+	last, err := s.watchStart(ctx, d, in.GetStartEpoch())
+	if err != nil {
+		return err
+	}
+	interval := s.keyChangeInterval
+	if interval == 0 {
+		interval = defaultKeyChangeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		published, err := s.publishedEpoch(ctx, d)
+		if err != nil {
+			return err
+		}
+		var changed []int64
+		for ; last < published; last++ {
+			epoch := last + 1
+			mutations, err := s.epochMutations(ctx, d, epoch)
+			if err != nil {
+				return err
+			}
+			if len(mutations[string(index[:])]) > 0 {
+				changed = append(changed, epoch)
+			}
+		}
+		if len(changed) > 0 {
+			if treeSize, err = s.sendEntries(ctx, stream, d, in.GetUserId(), in.GetAppId(), changed, treeSize); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendEntries sends the entry of a user at each of epochs, proven against the
+// latest log root. Only the first response proves the consistency of the log
+// root with treeSize, as the later ones share its log root. sendEntries
+// returns the tree size of the log root that was sent.
+func (s *Server) sendEntries(ctx context.Context, stream pb.KeyTransparency_WatchEntryServer, d *domain.Domain, userID, appID string, epochs []int64, treeSize int64) (int64, error) {
+	sth, consistencyProof, err := s.latestLogRootProof(ctx, d, treeSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, epoch := range epochs {
+		entryProof, err := s.getEntryByRevision(ctx, sth, d, userID, appID, epoch)
+		if err != nil {
+			return 0, err
+		}
+		resp := &pb.GetEntryResponse{LogRoot: sth}
+		if i == 0 {
+			resp.LogConsistency = consistencyProof.GetHashes()
+		}
+		proto.Merge(resp, entryProof)
+		if err := stream.Send(resp); err != nil {
+			return 0, err
+		}
+	}
+	return sth.GetTreeSize(), nil
+}
+
+// SetKeyChangeWebhook registers or removes the webhook of the caller's own
+// entry. Webhooks are notified of the changes published after registration.
+func (s *Server) SetKeyChangeWebhook(ctx context.Context, in *pb.SetKeyChangeWebhookRequest) (*pb.SetKeyChangeWebhookResponse, error) {
+	if s.webhooks == nil {
+		return nil, status.Errorf(codes.Unimplemented, "Key change webhooks are not enabled")
+	}
+	if err := validateSetKeyChangeWebhookRequest(in); err != nil {
+		glog.Errorf("validateSetKeyChangeWebhookRequest(%v): %v", in, err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		glog.Errorf("SetKeyChangeWebhook(): adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	if err := s.authenticateOwner(ctx, in.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	if in.GetUrl() != "" {
+		r := s.resolver
+		if r == nil {
+			r = net.DefaultResolver
+		}
+		if err := checkWebhookHost(ctx, r, in.GetUrl()); err != nil {
+			glog.Warningf("SetKeyChangeWebhook(%v): %v", in.GetUrl(), err)
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid webhook: %v", err)
+		}
+	}
+	if in.GetUrl() == "" {
+		if err := s.webhooks.Delete(ctx, d.DomainID, in.GetAppId(), in.GetUserId()); err != nil {
+			glog.Errorf("webhooks.Delete(%v): %v", d.DomainID, err)
+			return nil, status.Errorf(codes.Internal, "Cannot remove webhook")
+		}
+		return &pb.SetKeyChangeWebhookResponse{}, nil
+	}
+	index, _, err := s.indexFunc(ctx, d, in.GetAppId(), in.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	published, err := s.publishedEpoch(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Write(ctx, &keychange.Webhook{
+		DomainID:  d.DomainID,
+		AppID:     in.GetAppId(),
+		UserID:    in.GetUserId(),
+		Index:     index[:],
+		URL:       in.GetUrl(),
+		LastEpoch: published,
+	}); err != nil {
+		glog.Errorf("webhooks.Write(%v): %v", d.DomainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot save webhook")
+	}
+	return &pb.SetKeyChangeWebhookResponse{StartEpoch: published + 1}, nil
+}
+
+// RunKeyChangeWebhooks delivers key change events to the registered webhooks
+// every interval until ctx is done. Events are delivered at least once: a
+// webhook that fails is retried from the same epoch in the next pass.
+func (s *Server) RunKeyChangeWebhooks(ctx context.Context, interval time.Duration, client *http.Client) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		domains, err := s.domains.List(ctx, false)
+		if err != nil {
+			glog.Errorf("RunKeyChangeWebhooks: adminstorage.List(): %v", err)
+			continue
+		}
+		for _, d := range domains {
+			if err := s.deliverWebhooks(ctx, client, d); err != nil {
+				glog.Errorf("RunKeyChangeWebhooks: domain %v: %v", d.DomainID, err)
+			}
+		}
+	}
+}
+
+// deliverWebhooks delivers the changes published in d since each webhook of d
+// was last notified. The mutations of each epoch are read once for all
+// webhooks.
+func (s *Server) deliverWebhooks(ctx context.Context, client *http.Client, d *domain.Domain) error {
+	hooks, err := s.webhooks.List(ctx, d.DomainID)
+	if err != nil {
+		return fmt.Errorf("webhooks.List(): %w", err)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	published, err := s.publishedEpoch(ctx, d)
+	if err != nil {
+		return err
+	}
+	first := published + 1
+	prev := make([]int64, len(hooks))
+	for i, w := range hooks {
+		prev[i] = w.LastEpoch
+		if w.LastEpoch+1 < first {
+			first = w.LastEpoch + 1
+		}
+	}
+	if published-first >= maxWebhookEpochs {
+		published = first + maxWebhookEpochs - 1
+	}
+
+	failed := make([]bool, len(hooks))
+	for epoch := first; epoch <= published; epoch++ {
+		mutations, err := s.epochMutations(ctx, d, epoch)
+		if err != nil {
+			return err
+		}
+		var epochTime *tspb.Timestamp
+		for i, w := range hooks {
+			if failed[i] || w.LastEpoch >= epoch {
+				continue
+			}
+			if changes := mutations[string(w.Index)]; len(changes) > 0 {
+				if epochTime == nil {
+					if epochTime, err = s.epochTime(ctx, d, epoch); err != nil {
+						return err
+					}
+				}
+				if err := postKeyChanges(ctx, client, w.URL,
+					keyChangeEvents(d.DomainID, w.AppID, w.UserID, epoch, epochTime, changes)); err != nil {
+					glog.Warningf("Webhook of %v/%v/%v: %v", d.DomainID, w.AppID, w.UserID, err)
+					failed[i] = true
+					continue
+				}
+			}
+			w.LastEpoch = epoch
+		}
+	}
+
+	for i, w := range hooks {
+		if w.LastEpoch == prev[i] {
+			continue
+		}
+		if err := s.webhooks.SetLastEpoch(ctx, d.DomainID, w.AppID, w.UserID, w.LastEpoch); err != nil {
+			return fmt.Errorf("webhooks.SetLastEpoch(): %w", err)
+		}
+	}
+	return nil
+}
+
+// postKeyChanges POSTs each of events to url as JSON.
+func postKeyChanges(ctx context.Context, client *http.Client, url string, events []*pb.KeyChangeEvent) error {
+	m := &jsonpb.Marshaler{}
+	for _, e := range events {
+		var body bytes.Buffer
+		if err := m.Marshal(&body, e); err != nil {
+			return err
+		}
+		req, err := http.NewRequest(http.MethodPost, url, &body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		cctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+		resp, err := client.Do(req.WithContext(cctx))
+		cancel()
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("POST %v: %v", url, resp.Status)
+		}
+	}
+	return nil
+}
+
+// publishedEpoch returns the latest epoch whose map root is in the log.
+func (s *Server) publishedEpoch(ctx context.Context, d *domain.Domain) (int64, error) {
+	sth, err := s.latestLogRoot(ctx, d)
+	if err != nil {
+		return 0, err
+	}
+	return mapRevisionFor(sth)
+}
+
+// epochMutations returns the mutations applied in epoch by map index. The
+// result is shared with other callers and must not be modified.
+func (s *Server) epochMutations(ctx context.Context, d *domain.Domain, epoch int64) (map[string][]*pb.Entry, error) {
+	return s.scans.get(ctx, d.DomainID, epoch, func() (map[string][]*pb.Entry, error) {
+		return s.scanEpoch(ctx, d, epoch)
+	})
+}
+
+// scanEpoch reads the mutations applied in epoch by map index.
+func (s *Server) scanEpoch(ctx context.Context, d *domain.Domain, epoch int64) (map[string][]*pb.Entry, error) {
+	ret := make(map[string][]*pb.Entry)
+	if err := mutator.ReadRevision(ctx, s.mutations, d.DomainID, epoch, maxPageSize, func(e *pb.Entry) bool {
+		ret[string(e.GetIndex())] = append(ret[string(e.GetIndex())], e)
+		return true
+	}); err != nil {
+		glog.Errorf("scanEpoch(): %v", err)
+		return nil, status.Errorf(codes.Internal, "Reading mutations failed")
+	}
+	return ret, nil
+}
+
+// epochTime returns when epoch was created.
+func (s *Server) epochTime(ctx context.Context, d *domain.Domain, epoch int64) (*tspb.Timestamp, error) {
+	resp, err := s.tmap.GetSignedMapRootByRevision(ctx, &tpb.GetSignedMapRootByRevisionRequest{
+		MapId:    d.MapID,
+		Revision: epoch,
+	})
+	if err != nil {
+		glog.Errorf("GetSignedMapRootByRevision(%v, %v): %v", d.MapID, epoch, err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch map root")
+	}
+	return ptypes.TimestampProto(time.Unix(0, resp.GetMapRoot().GetTimestampNanos()))
+}
+
+// keyChangeEvents returns an event for each of the changes to an entry in
+// epoch.
+func keyChangeEvents(domainID, appID, userID string, epoch int64, epochTime *tspb.Timestamp, changes []*pb.Entry) []*pb.KeyChangeEvent {
+	events := make([]*pb.KeyChangeEvent, 0, len(changes))
+	for _, e := range changes {
+		events = append(events, &pb.KeyChangeEvent{
+			DomainId:  domainID,
+			AppId:     appID,
+			UserId:    userID,
+			Epoch:     epoch,
+			Mutation:  e,
+			EpochTime: epochTime,
+		})
+	}
+	return events
+}
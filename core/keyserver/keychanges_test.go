@@ -0,0 +1,232 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/authentication"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/keychange"
+	"github.com/google/keytransparency/core/smhlog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// userIndex uses the user ID as the index so that tests can predict indexes.
+func userIndex(_ context.Context, _ *domain.Domain, _, userID string) ([32]byte, []byte, error) {
+	var index [32]byte
+	copy(index[:], userID)
+	return index, nil, nil
+}
+
+// fakeResolver resolves hosts from a map.
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ip, ok := r[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %v", host)
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+func TestSetKeyChangeWebhook(t *testing.T) {
+	ctx := context.Background()
+	domains := fake.NewDomainStorage()
+	if err := domains.Write(ctx, &domain.Domain{DomainID: "domain"}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	index, _, _ := userIndex(ctx, nil, "app", "alice")
+	webhooks := fake.NewKeyChangeStorage()
+	srv := &Server{
+		logs:      smhlog.Backends{smhlog.Default: {Log: &fake.LogServer{TreeSize: 4}}},
+		domains:   domains,
+		auth:      authentication.NewFake(),
+		webhooks:  webhooks,
+		indexFunc: userIndex,
+		resolver: fakeResolver{
+			"example.com":          "93.184.216.34",
+			"internal.example.com": "10.0.0.1",
+		},
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		caller   string // Authenticated identity. Empty for none.
+		userID   string
+		url      string
+		wantCode codes.Code
+		want     []*keychange.Webhook
+	}{
+		{desc: "register", caller: "alice", userID: "alice", url: "https://example.com/alice",
+			want: []*keychange.Webhook{{DomainID: "domain", AppID: "app", UserID: "alice",
+				Index: index[:], URL: "https://example.com/alice", LastEpoch: 3}}},
+		{desc: "other user", caller: "bob", userID: "alice", url: "https://example.com/bob", wantCode: codes.PermissionDenied},
+		{desc: "unauthenticated", userID: "alice", url: "https://example.com/alice", wantCode: codes.Unauthenticated},
+		{desc: "http", caller: "alice", userID: "alice", url: "http://example.com/alice", wantCode: codes.InvalidArgument},
+		{desc: "loopback", caller: "alice", userID: "alice", url: "https://127.0.0.1/alice", wantCode: codes.InvalidArgument},
+		{desc: "metadata server", caller: "alice", userID: "alice", url: "https://169.254.169.254/", wantCode: codes.InvalidArgument},
+		{desc: "private host", caller: "alice", userID: "alice", url: "https://internal.example.com/alice", wantCode: codes.InvalidArgument},
+		{desc: "unresolvable", caller: "alice", userID: "alice", url: "https://unknown.example.com/alice", wantCode: codes.InvalidArgument},
+		{desc: "remove", caller: "alice", userID: "alice"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cctx := ctx
+			if tc.caller != "" {
+				cctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "FakeCredential "+tc.caller))
+			}
+			resp, err := srv.SetKeyChangeWebhook(cctx, &pb.SetKeyChangeWebhookRequest{
+				DomainId: "domain",
+				AppId:    "app",
+				UserId:   tc.userID,
+				Url:      tc.url,
+			})
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("SetKeyChangeWebhook(): %v, want %v", err, tc.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if tc.url != "" && resp.GetStartEpoch() != 4 {
+				t.Errorf("SetKeyChangeWebhook(): StartEpoch %v, want 4", resp.GetStartEpoch())
+			}
+			got, err := webhooks.List(ctx, "domain")
+			if err != nil {
+				t.Fatalf("List(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("List(): %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	ctx := context.Background()
+	d := &domain.Domain{DomainID: "domain"}
+	mutations := fake.NewMutationStorage()
+	for epoch, users := range [][]string{
+		1: {"alice", "bob"},
+		2: {"bob"},
+		3: {"alice", "carol"},
+	} {
+		var entries []*pb.Entry
+		for _, u := range users {
+			entries = append(entries, &pb.Entry{Index: []byte(u)})
+		}
+		if err := mutations.WriteBatch(ctx, d.DomainID, int64(epoch), entries); err != nil {
+			t.Fatalf("WriteBatch(): %v", err)
+		}
+	}
+
+	received := make(map[string][]int64)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/carol" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var e struct {
+			UserID string `json:"userId"`
+			Epoch  string `json:"epoch"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("Decode(): %v", err)
+		}
+		var epoch int64
+		if err := json.Unmarshal([]byte(e.Epoch), &epoch); err != nil {
+			t.Errorf("epoch %q: %v", e.Epoch, err)
+		}
+		received[e.UserID] = append(received[e.UserID], epoch)
+	}))
+	defer hook.Close()
+
+	webhooks := fake.NewKeyChangeStorage()
+	for _, w := range []*keychange.Webhook{
+		{UserID: "alice", LastEpoch: 0},
+		{UserID: "bob", LastEpoch: 1},
+		{UserID: "carol", LastEpoch: 2},
+	} {
+		w.DomainID = d.DomainID
+		w.AppID = "app"
+		w.Index = []byte(w.UserID)
+		w.URL = hook.URL + "/" + w.UserID
+		if err := webhooks.Write(ctx, w); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	srv := &Server{
+		logs:      smhlog.Backends{smhlog.Default: {Log: &fake.LogServer{TreeSize: 4}}},
+		tmap:      fake.NewTrillianMapClient(),
+		mutations: mutations,
+		webhooks:  webhooks,
+	}
+	if err := srv.deliverWebhooks(ctx, hook.Client(), d); err != nil {
+		t.Fatalf("deliverWebhooks(): %v", err)
+	}
+
+	if want := map[string][]int64{"alice": {1, 3}, "bob": {2}}; !reflect.DeepEqual(received, want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+	hooks, err := webhooks.List(ctx, d.DomainID)
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	want := map[string]int64{"alice": 3, "bob": 3, "carol": 2}
+	for _, w := range hooks {
+		if got := w.LastEpoch; got != want[w.UserID] {
+			t.Errorf("%v: LastEpoch %v, want %v", w.UserID, got, want[w.UserID])
+		}
+	}
+}
+
+func TestWatchStart(t *testing.T) {
+	ctx := context.Background()
+	published := 2 * maxWatchEpochs
+	srv := &Server{
+		logs: smhlog.Backends{smhlog.Default: {Log: &fake.LogServer{TreeSize: published + 1}}},
+	}
+	for _, tc := range []struct {
+		start    int64
+		want     int64
+		wantCode codes.Code
+	}{
+		{start: 0, want: published},
+		{start: published, want: published - 1},
+		{start: published - maxWatchEpochs + 1, want: published - maxWatchEpochs},
+		{start: published - maxWatchEpochs, wantCode: codes.OutOfRange},
+		{start: 1, wantCode: codes.OutOfRange},
+	} {
+		got, err := srv.watchStart(ctx, &domain.Domain{DomainID: "domain"}, tc.start)
+		if gotCode := status.Code(err); gotCode != tc.wantCode {
+			t.Errorf("watchStart(%v): %v, want %v", tc.start, err, tc.wantCode)
+			continue
+		}
+		if err == nil && got != tc.want {
+			t.Errorf("watchStart(%v): %v, want %v", tc.start, got, tc.want)
+		}
+	}
+}
@@ -16,66 +16,191 @@
 package keyserver
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/google/keytransparency/core/appindex"
 	"github.com/google/keytransparency/core/authentication"
 	"github.com/google/keytransparency/core/authorization"
 	"github.com/google/keytransparency/core/crypto/vrf"
-	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/crypto/vrf/factory"
 	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/endorsement"
+	kterrors "github.com/google/keytransparency/core/errors"
+	"github.com/google/keytransparency/core/keychange"
 	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/purge"
+	"github.com/google/keytransparency/core/quota"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/keytransparency/core/version"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian/crypto/keyspb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	authzpb "github.com/google/keytransparency/core/api/type/type_proto"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 	tpb "github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
 )
 
+// errFrozen is returned when a client writes to a frozen domain.
+var errFrozen = status.Error(codes.FailedPrecondition, domain.ErrFrozen.Error())
+
 // Server holds internal state for the key server.
 type Server struct {
-	tlog      tpb.TrillianLogClient
 	tmap      tpb.TrillianMapClient
-	logAdmin  tpb.TrillianAdminClient
 	mapAdmin  tpb.TrillianAdminClient
 	auth      authentication.Authenticator
 	authz     authorization.Authorization
 	mutator   mutator.Func
 	domains   domain.Storage
+	purged    purge.Storage
+	apps      appindex.Storage
+	quotas    quota.Storage
 	queue     mutator.MutationQueue
 	mutations mutator.MutationStorage
 	indexFunc indexFunc
+	// configSigner signs the domain configs returned by GetDomain and the
+	// permalinks returned by CreatePermalink. Configs are not signed and
+	// permalinks are disabled if it is nil.
+	configSigner *tcrypto.Signer
+	// webhooks stores the key change webhooks of users. Webhooks are
+	// disabled if it is nil.
+	webhooks keychange.Storage
+	// endorsements stores the endorsements of map roots. Map roots are
+	// served without endorsements if it is nil.
+	endorsements endorsement.Storage
+	// logs are the backends that map roots are appended to.
+	logs smhlog.Backends
+	// keyChangeInterval is how often WatchKeyChanges checks for new epochs.
+	keyChangeInterval time.Duration
+	// scans shares the mutations of recent epochs between watchers.
+	scans epochScans
+	// stats caches the results of GetDomainStats.
+	stats statsCache
+	// resolver resolves the hosts of webhooks. net.DefaultResolver is used
+	// if it is nil.
+	resolver resolver
+}
+
+// Options configures a Server.
+type Options struct {
+	// Log and Map serve the trees of domains.
+	Log tpb.TrillianLogClient
+	Map tpb.TrillianMapClient
+	// LogAdmin and MapAdmin return the metadata of the trees.
+	LogAdmin tpb.TrillianAdminClient
+	MapAdmin tpb.TrillianAdminClient
+	// LogBackends are the alternate services that domains anchor map roots
+	// in. Log and LogAdmin are the default backend.
+	LogBackends smhlog.Backends
+	// Mutator verifies the mutations of entry updates. Defaults to
+	// entry.NewRegistry().
+	Mutator mutator.Func
+	// Auth authenticates the callers of UpdateEntry.
+	Auth authentication.Authenticator
+	// Authz authorizes the callers of UpdateEntry.
+	Authz authorization.Authorization
+	// Domains stores the configuration of domains.
+	Domains domain.Storage
+	// Purged records which committed profile data has been purged.
+	Purged purge.Storage
+	// Apps records the apps that users have entries for.
+	Apps appindex.Storage
+	// Quotas counts the mutations of users and revisions.
+	Quotas quota.Storage
+	// Queue receives the mutations of entry updates.
+	Queue mutator.MutationQueue
+	// Mutations reads the mutations applied in each epoch.
+	Mutations mutator.MutationStorage
+	// ConfigSigner signs the domain configs returned by GetDomain and the
+	// permalinks returned by CreatePermalink. Configs are not signed and
+	// permalinks are disabled if it is nil.
+	ConfigSigner *tcrypto.Signer
+	// Webhooks stores the key change webhooks of users. Webhooks are
+	// disabled if it is nil.
+	Webhooks keychange.Storage
+	// Endorsements stores the endorsements of map roots. Map roots are
+	// served without endorsements if it is nil.
+	Endorsements endorsement.Storage
+	// KeyChangeInterval is how often WatchKeyChanges checks for new epochs.
+	// Defaults to 5 seconds.
+	KeyChangeInterval time.Duration
+}
+
+// validate returns an error if a required dependency is missing and fills in
+// defaults for the optional ones.
+func (o *Options) validate() error {
+	switch {
+	case o.Log == nil:
+		return errors.New("keyserver: missing Log client")
+	case o.Map == nil:
+		return errors.New("keyserver: missing Map client")
+	case o.LogAdmin == nil:
+		return errors.New("keyserver: missing LogAdmin client")
+	case o.MapAdmin == nil:
+		return errors.New("keyserver: missing MapAdmin client")
+	case o.Auth == nil:
+		return errors.New("keyserver: missing Auth")
+	case o.Authz == nil:
+		return errors.New("keyserver: missing Authz")
+	case o.Domains == nil:
+		return errors.New("keyserver: missing Domains storage")
+	case o.Purged == nil:
+		return errors.New("keyserver: missing Purged storage")
+	case o.Apps == nil:
+		return errors.New("keyserver: missing Apps storage")
+	case o.Quotas == nil:
+		return errors.New("keyserver: missing Quotas storage")
+	case o.Queue == nil:
+		return errors.New("keyserver: missing Queue")
+	case o.Mutations == nil:
+		return errors.New("keyserver: missing Mutations storage")
+	case o.KeyChangeInterval < 0:
+		return fmt.Errorf("keyserver: negative KeyChangeInterval %v", o.KeyChangeInterval)
+	}
+	if o.Mutator == nil {
+		o.Mutator = entry.NewRegistry()
+	}
+	if o.KeyChangeInterval == 0 {
+		o.KeyChangeInterval = defaultKeyChangeInterval
+	}
+	return nil
 }
 
 // New creates a new instance of the key server.
-func New(tlog tpb.TrillianLogClient,
-	tmap tpb.TrillianMapClient,
-	logAdmin tpb.TrillianAdminClient,
-	mapAdmin tpb.TrillianAdminClient,
-	mutator mutator.Func,
-	auth authentication.Authenticator,
-	authz authorization.Authorization,
-	domains domain.Storage,
-	queue mutator.MutationQueue,
-	mutations mutator.MutationStorage) *Server {
+func New(opts Options) (*Server, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
 	return &Server{
-		tlog:      tlog,
-		tmap:      tmap,
-		logAdmin:  logAdmin,
-		mapAdmin:  mapAdmin,
-		mutator:   mutator,
-		auth:      auth,
-		authz:     authz,
-		domains:   domains,
-		queue:     queue,
-		mutations: mutations,
+		tmap:      opts.Map,
+		mapAdmin:  opts.MapAdmin,
+		mutator:   opts.Mutator,
+		auth:      opts.Auth,
+		authz:     opts.Authz,
+		domains:   opts.Domains,
+		purged:    opts.Purged,
+		apps:      opts.Apps,
+		quotas:    opts.Quotas,
+		queue:     opts.Queue,
+		mutations: opts.Mutations,
 		indexFunc: indexFromVRF,
-	}
+
+		configSigner:      opts.ConfigSigner,
+		webhooks:          opts.Webhooks,
+		endorsements:      opts.Endorsements,
+		logs:              opts.LogBackends.WithDefault(opts.Log, opts.LogAdmin),
+		keyChangeInterval: opts.KeyChangeInterval,
+	}, nil
 }
 
 // GetEntry returns a user's profile and proof that there is only one object for
@@ -131,26 +256,42 @@ func (s *Server) getEntryByRevision(ctx context.Context, sth *tpb.SignedLogRoot,
 	if err != nil {
 		return nil, err
 	}
-
-	getResp, err := s.tmap.GetLeavesByRevision(ctx, &tpb.GetMapLeavesByRevisionRequest{
-		MapId:    d.MapID,
-		Index:    [][]byte{index[:]},
-		Revision: revision,
-	})
+	getResp, err := s.mapLeaf(ctx, d, index, revision)
 	if err != nil {
-		glog.Errorf("GetLeavesByRevision(%v, rev: %v): %v", d.MapID, revision, err)
-		return nil, status.Errorf(codes.Internal, "Failed fetching map leaf")
+		return nil, err
 	}
-	if got, want := len(getResp.MapLeafInclusion), 1; got != want {
-		glog.Errorf("GetLeavesByRevision() len: %v, want %v", got, want)
-		return nil, status.Errorf(codes.Internal, "Failed fetching map leaf")
+	// While the domain VRF is being rotated, entries that have not been
+	// updated since the rotation are still stored under their old index.
+	var currentProof []byte
+	if getResp.MapLeafInclusion[0].Leaf.LeafValue == nil {
+		prevIndex, prevProof, ok, err := indexFromPrevVRF(ctx, d, appID, userID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			prevResp, err := s.mapLeaf(ctx, d, prevIndex, revision)
+			if err != nil {
+				return nil, err
+			}
+			if prevResp.MapLeafInclusion[0].Leaf.LeafValue != nil {
+				currentProof = proof
+				index, proof, getResp = prevIndex, prevProof, prevResp
+			}
+		}
 	}
 	neighbors := getResp.MapLeafInclusion[0].Inclusion
 	leaf := getResp.MapLeafInclusion[0].Leaf.LeafValue
 	extraData := getResp.MapLeafInclusion[0].Leaf.ExtraData
 
 	var committed *pb.Committed
+	var committedPurged bool
 	if leaf != nil {
+		committedPurged, err = s.isPurged(ctx, d, index, revision, leaf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if leaf != nil && !committedPurged {
 		if extraData == nil {
 			return nil, status.Errorf(codes.Internal, "Missing commitment data")
 		}
@@ -162,7 +303,11 @@ func (s *Server) getEntryByRevision(ctx context.Context, sth *tpb.SignedLogRoot,
 
 	// SignedMapHead to SignedLogRoot inclusion proof.
 	secondTreeSize := sth.GetTreeSize()
-	logInclusion, err := s.tlog.GetInclusionProof(ctx,
+	smhLog, err := s.smhLog(d)
+	if err != nil {
+		return nil, err
+	}
+	logInclusion, err := smhLog.Log.GetInclusionProof(ctx,
 		&tpb.GetInclusionProofRequest{
 			LogId: d.LogID,
 			// SignedMapRoot must be placed in the log at MapRevision.
@@ -175,6 +320,10 @@ func (s *Server) getEntryByRevision(ctx context.Context, sth *tpb.SignedLogRoot,
 			d.LogID, getResp.GetMapRoot().GetMapRevision(), secondTreeSize, err)
 		return nil, status.Errorf(codes.Internal, "Cannot fetch log inclusion proof")
 	}
+	endorsements, err := s.mapRootEndorsements(ctx, d, getResp.GetMapRoot().GetMapRevision())
+	if err != nil {
+		return nil, err
+	}
 
 	return &pb.GetEntryResponse{
 		VrfProof:  proof,
@@ -185,11 +334,54 @@ func (s *Server) getEntryByRevision(ctx context.Context, sth *tpb.SignedLogRoot,
 				LeafValue: leaf,
 			},
 		},
-		Smr:          getResp.GetMapRoot(),
-		LogInclusion: logInclusion.GetProof().GetHashes(),
+		Smr:             getResp.GetMapRoot(),
+		LogInclusion:    logInclusion.GetProof().GetHashes(),
+		CommittedPurged: committedPurged,
+		Endorsements:    endorsements,
+		CurrentVrfProof: currentProof,
 	}, nil
 }
 
+// isPurged reports whether the committed data of leaf, stored at index and
+// revision, has been purged. Data that has not changed since the purged
+// revision remains purged in later revisions.
+func (s *Server) isPurged(ctx context.Context, d *domain.Domain, index [32]byte, revision int64, leaf []byte) (bool, error) {
+	through, err := s.purged.PurgedThrough(ctx, d.DomainID, index[:])
+	if err != nil {
+		glog.Errorf("purge.PurgedThrough(%v, %x): %v", d.DomainID, index, err)
+		return false, status.Errorf(codes.Internal, "Cannot read purge status")
+	}
+	switch {
+	case through < 0:
+		return false, nil
+	case revision <= through:
+		return true, nil
+	}
+	purgedResp, err := s.mapLeaf(ctx, d, index, through)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(purgedResp.MapLeafInclusion[0].Leaf.LeafValue, leaf), nil
+}
+
+// mapLeaf returns the map leaf at index and revision along with its inclusion proof.
+func (s *Server) mapLeaf(ctx context.Context, d *domain.Domain, index [32]byte, revision int64) (*tpb.GetMapLeavesResponse, error) {
+	getResp, err := s.tmap.GetLeavesByRevision(ctx, &tpb.GetMapLeavesByRevisionRequest{
+		MapId:    d.MapID,
+		Index:    [][]byte{index[:]},
+		Revision: revision,
+	})
+	if err != nil {
+		glog.Errorf("GetLeavesByRevision(%v, rev: %v): %v", d.MapID, revision, err)
+		return nil, status.Errorf(codes.Internal, "Failed fetching map leaf")
+	}
+	if got, want := len(getResp.MapLeafInclusion), 1; got != want {
+		glog.Errorf("GetLeavesByRevision() len: %v, want %v", got, want)
+		return nil, status.Errorf(codes.Internal, "Failed fetching map leaf")
+	}
+	return getResp, nil
+}
+
 // ListEntryHistory returns a list of EntryProofs covering a period of time.
 func (s *Server) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRequest) (*pb.ListEntryHistoryResponse, error) {
 	// Lookup log and map info.
@@ -214,18 +406,33 @@ func (s *Server) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRe
 		return nil, err
 	}
 
+	// Page tokens hold the next epoch to return. Listings newest first
+	// start at the current epoch.
+	if in.GetPageToken() != "" {
+		if in.Start, err = parseHistoryToken(in.GetPageToken(), in); err != nil {
+			return nil, err
+		}
+	} else if in.GetNewestFirst() {
+		in.Start = currentEpoch
+	}
 	if err := validateListEntryHistoryRequest(in, currentEpoch); err != nil {
 		glog.Errorf("validateListEntryHistoryRequest(%v, %v): %v", in, currentEpoch, err)
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid request")
 	}
 
 	// TODO(gbelvin): fetch all history from trillian at once.
-	// Get all GetEntryResponse for all epochs in the range [start, start + in.PageSize].
+	// Get all GetEntryResponse for all epochs in the range [start, start + in.PageSize],
+	// or [start - in.PageSize, start] in reverse order when listing newest first.
+	step := int64(1)
+	if in.GetNewestFirst() {
+		step = -1
+	}
 	responses := make([]*pb.GetEntryResponse, in.PageSize)
 	for i := range responses {
-		resp, err := s.getEntryByRevision(ctx, sth, d, in.UserId, in.AppId, in.Start+int64(i))
+		epoch := in.Start + step*int64(i)
+		resp, err := s.getEntryByRevision(ctx, sth, d, in.UserId, in.AppId, epoch)
 		if err != nil {
-			glog.Errorf("getEntry failed for epoch %v: %v", in.Start+int64(i), err)
+			glog.Errorf("getEntry failed for epoch %v: %v", epoch, err)
 			return nil, status.Errorf(codes.Internal, "GetEntry failed")
 		}
 		proto.Merge(resp, &pb.GetEntryResponse{
@@ -236,14 +443,23 @@ func (s *Server) ListEntryHistory(ctx context.Context, in *pb.ListEntryHistoryRe
 		responses[i] = resp
 	}
 
-	nextStart := in.Start + int64(in.PageSize)
-	if nextStart > currentEpoch {
-		nextStart = 0
+	next := in.Start + step*int64(in.PageSize)
+	var nextStart int64
+	nextPageToken := ""
+	if next >= 0 && next <= currentEpoch {
+		if nextPageToken, err = newHistoryToken(in, next); err != nil {
+			glog.Errorf("newHistoryToken(): %v", err)
+			return nil, status.Errorf(codes.Internal, "Cannot create page token")
+		}
+		if !in.GetNewestFirst() {
+			nextStart = next
+		}
 	}
 
 	return &pb.ListEntryHistoryResponse{
-		Values:    responses,
-		NextStart: nextStart,
+		Values:        responses,
+		NextStart:     nextStart,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
@@ -253,13 +469,22 @@ func (s *Server) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest) (*p
 	if in.DomainId == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "Please specify a domain_id")
 	}
+	// Reject malformed mutations before doing any other work.
+	if err := validateEntryUpdate(in); err != nil {
+		glog.Warningf("Malformed UpdateEntryRequest: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
 	// Lookup log and map info.
 	domain, err := s.domains.Read(ctx, in.DomainId, false)
 	if err != nil {
 		glog.Errorf("adminstorage.Read(%v): %v", in.DomainId, err)
 		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
 	}
-	vrfPriv, err := p256.NewFromWrappedKey(ctx, domain.VRFPriv)
+	if domain.Frozen {
+		return nil, errFrozen
+	}
+	_, wrapped := domain.VRFFor(in.GetAppId())
+	vrfPriv, err := factory.NewFromWrappedKey(ctx, wrapped)
 	if err != nil {
 		return nil, err
 	}
@@ -284,9 +509,20 @@ func (s *Server) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest) (*p
 	// - Index to Key equality in SignedKV.
 	// - Correct profile commitment.
 	// - Correct key formats.
-	if err := validateUpdateEntryRequest(in, vrfPriv); err != nil {
+	err = validateUpdateEntryRequest(in, vrfPriv)
+	if err == ErrWrongIndex {
+		// Accept indexes computed with the previous VRF during a rotation.
+		if _, prevWrapped, ok := domain.PrevVRFFor(in.GetAppId(), time.Now()); ok {
+			prevPriv, perr := factory.NewFromWrappedKey(ctx, prevWrapped)
+			if perr != nil {
+				return nil, perr
+			}
+			err = validateUpdateEntryRequest(in, prevPriv)
+		}
+	}
+	if err != nil {
 		glog.Warningf("Invalid UpdateEntryRequest: %v", err)
-		return nil, status.Errorf(codes.InvalidArgument, "Invalid request")
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
 	}
 
 	// Query for the current epoch.
@@ -308,22 +544,66 @@ func (s *Server) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest) (*p
 	// - Correct signatures internal to the update.
 	// - Hash of current data matches the expectation in the mutation.
 
-	// The very first mutation will have resp.LeafProof.MapLeaf.LeafValue=nil.
-	oldLeafB := resp.GetLeafProof().GetLeaf().GetLeafValue()
+	// The very first mutation will have a nil oldLeafB.
+	oldLeafB, err := s.previousLeaf(ctx, domain, in, resp.GetSmr().GetMapRevision())
+	if err != nil {
+		return nil, err
+	}
 	oldEntry, err := entry.FromLeafValue(oldLeafB)
 	if err != nil {
 		glog.Errorf("entry.FromLeafValue: %v", err)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid previous leaf value")
 	}
-	if _, err := s.mutator.Mutate(oldEntry, in.GetEntryUpdate().GetMutation()); err == mutator.ErrReplay {
+	mutate := mutator.ForDomain(s.mutator, domain.DomainID)
+	if _, err := mutate.Mutate(oldEntry, in.GetEntryUpdate().GetMutation()); err == mutator.ErrReplay {
 		glog.Warningf("Discarding request due to replay")
 		// Return the response. The client should handle the replay case
 		// by comparing the returned response with the request. Check
 		// Retry() in client/client.go.
 		return &pb.UpdateEntryResponse{Proof: resp}, nil
 	} else if err != nil {
+		// Reject mutations the sequencer would drop so that the client
+		// learns about them now rather than an epoch later.
 		glog.Warningf("Invalid mutation: %v", err)
-		return nil, status.Errorf(codes.InvalidArgument, "Invalid mutation")
+		return nil, status.Errorf(mutationErrorCode(err), "Invalid mutation: %v", err)
+	}
+	if err := entry.CheckKeyPolicy(domain.KeyPolicy, in.GetEntryUpdate().GetMutation()); err != nil {
+		glog.Warningf("Key policy violation: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid mutation: %v", err)
+	}
+
+	// Enforce and charge the domain's mutation quota. Mutations are charged
+	// before they are queued, so a failed write may use up quota but the
+	// quota is never exceeded.
+	now := time.Now()
+	revision := resp.GetSmr().GetMapRevision()
+	if err := s.checkQuota(ctx, domain, in.GetUserId(), revision, now); err != nil {
+		glog.Warningf("checkQuota(%v): %v", in.GetUserId(), err)
+		return nil, err
+	}
+	if err := s.quotas.Record(ctx, domain.DomainID, in.GetUserId(), revision, now); err != nil {
+		glog.Errorf("quotas.Record failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "Mutation write error")
+	}
+
+	// Record the app before queueing so that ListUserApps never omits an
+	// app that may hold keys for the user.
+	if err := s.apps.Add(ctx, domain.DomainID, in.GetUserId(), in.GetAppId()); err != nil {
+		glog.Errorf("apps.Add failed: %v", err)
+		return nil, status.Errorf(codes.Internal, "Mutation write error")
+	}
+	// AddAppVRF refuses apps that are recorded, but it may have added a VRF
+	// for this app between the domain read above and apps.Add. The index
+	// would then be one that the app's VRF no longer reaches.
+	if _, ok := domain.AppVRFs[in.GetAppId()]; !ok {
+		d, err := s.domains.Read(ctx, domain.DomainID, false)
+		if err != nil {
+			glog.Errorf("adminstorage.Read(%v): %v", domain.DomainID, err)
+			return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+		}
+		if _, ok := d.AppVRFs[in.GetAppId()]; ok {
+			return nil, status.Errorf(codes.Aborted, "VRF of app %v changed, please retry", in.GetAppId())
+		}
 	}
 
 	// Save mutation to the database.
@@ -334,6 +614,22 @@ func (s *Server) UpdateEntry(ctx context.Context, in *pb.UpdateEntryRequest) (*p
 	return &pb.UpdateEntryResponse{Proof: resp}, nil
 }
 
+// mutationErrorCode maps errors returned by the mutator to gRPC status codes.
+func mutationErrorCode(err error) codes.Code {
+	switch err {
+	case mutator.ErrUnauthorized, mutator.ErrInvalidSig:
+		return codes.PermissionDenied
+	case mutator.ErrPreviousHash:
+		// The client must refetch the current entry and try again.
+		return codes.FailedPrecondition
+	case mutator.ErrLocked:
+		// Only a reset can change a revoked or frozen entry.
+		return codes.FailedPrecondition
+	default:
+		return codes.InvalidArgument
+	}
+}
+
 // GetDomain returns all info tied to the specified domain.
 //
 // This API to get all necessary data needed to verify a particular
@@ -345,7 +641,7 @@ func (s *Server) GetDomain(ctx context.Context, in *pb.GetDomainRequest) (*pb.Do
 		return nil, status.Errorf(codes.InvalidArgument, "Please specify a domain_id")
 	}
 	domain, err := s.domains.Read(ctx, in.DomainId, false)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, kterrors.ErrNotFound) {
 		glog.Errorf("adminstorage.Read(%v): %v", in.DomainId, err)
 		return nil, status.Errorf(codes.NotFound, "Domain %v not found", in.DomainId)
 	} else if err != nil {
@@ -353,7 +649,11 @@ func (s *Server) GetDomain(ctx context.Context, in *pb.GetDomainRequest) (*pb.Do
 		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info for %v", in.DomainId)
 	}
 
-	logTree, err := s.logAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: domain.LogID})
+	smhLog, err := s.smhLog(domain)
+	if err != nil {
+		return nil, err
+	}
+	logTree, err := smhLog.Tree(ctx, domain.LogID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal,
 			"Cannot fetch log info for %v: %v", in.DomainId, err)
@@ -364,23 +664,178 @@ func (s *Server) GetDomain(ctx context.Context, in *pb.GetDomainRequest) (*pb.Do
 			"Cannot fetch map info for %v: %v", in.DomainId, err)
 	}
 
-	return &pb.Domain{
-		DomainId: domain.DomainID,
-		Log:      logTree,
-		Map:      mapTree,
-		Vrf:      domain.VRF,
-	}, nil
+	var appVRFs map[string]*keyspb.PublicKey
+	if len(domain.AppVRFs) > 0 {
+		appVRFs = make(map[string]*keyspb.PublicKey, len(domain.AppVRFs))
+		for appID, a := range domain.AppVRFs {
+			appVRFs[appID] = a.VRF
+		}
+	}
+
+	info := &pb.Domain{
+		DomainId:      domain.DomainID,
+		Log:           logTree,
+		Map:           mapTree,
+		Vrf:           domain.VRF,
+		AppVrfs:       appVRFs,
+		KeyPolicy:     domain.KeyPolicy,
+		Frozen:        domain.Frozen,
+		AppListing:    domain.AppListing,
+		MutationQuota: domain.MutationQuota,
+		VrfAlgorithm:  domain.VRFAlgorithm(),
+		Migration:     domain.Migration,
+
+		EndorsementPolicy: domain.EndorsementPolicy,
+		LogKeyRotation:    domain.LogKeyRotation,
+		MapKeyRotation:    domain.MapKeyRotation,
+		KeyPolicyHistory:  domain.KeyPolicyHistory,
+	}
+	// Publish the previous VRF so that clients can verify indexes that
+	// were computed before the most recent rotation.
+	if p := domain.PrevVRF; p != nil && time.Now().Before(p.Expiry) {
+		expiry, err := ptypes.TimestampProto(p.Expiry)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Invalid VRF expiry for %v", in.DomainId)
+		}
+		info.PreviousVrf = p.VRF
+		info.PreviousVrfExpiry = expiry
+	}
+	if s.configSigner != nil {
+		signed, err := signConfig(s.configSigner, info, time.Now())
+		if err != nil {
+			glog.Errorf("signConfig(%v): %v", in.DomainId, err)
+			return nil, status.Errorf(codes.Internal, "Cannot sign config for %v", in.DomainId)
+		}
+		info.SignedConfig = signed
+	}
+	return info, nil
+}
+
+// signConfig signs the configuration d as of time now.
+func signConfig(signer *tcrypto.Signer, d *pb.Domain, now time.Time) (*pb.SignedDomainConfig, error) {
+	ts, err := ptypes.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	config, err := proto.Marshal(&pb.DomainConfig{Domain: d, Timestamp: ts})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(config)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedDomainConfig{Config: config, Signature: sig}, nil
+}
+
+// GetServerCapabilities returns the server's current time so that clients can
+// detect skew between their local clock and the server's.
+func (s *Server) GetServerCapabilities(ctx context.Context, in *pb.GetServerCapabilitiesRequest) (*pb.ServerCapabilities, error) {
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		glog.Errorf("ptypes.TimestampProto(): %v", err)
+		return nil, status.Errorf(codes.Internal, "Cannot encode server time")
+	}
+	return &pb.ServerCapabilities{ServerTime: now}, nil
+}
+
+// GetServerVersion returns the version and binary digest of the server.
+func (s *Server) GetServerVersion(ctx context.Context, in *pb.GetServerVersionRequest) (*pb.ServerVersion, error) {
+	v, err := version.Proto()
+	if err != nil {
+		glog.Errorf("version.Proto(): %v", err)
+		return nil, status.Errorf(codes.Internal, "Cannot determine server version")
+	}
+	return v, nil
 }
 
 // indexFunc computes an index and proof for domain/app/user
 type indexFunc func(ctx context.Context, d *domain.Domain, appID, userID string) ([32]byte, []byte, error)
 
-// index returns the index and proof for domain/app/user
+// index returns the index and proof for domain/app/user using the app's VRF
+// if it has one, and the domain's VRF otherwise.
 func indexFromVRF(ctx context.Context, d *domain.Domain, appID, userID string) ([32]byte, []byte, error) {
-	vrfPriv, err := p256.NewFromWrappedKey(ctx, d.VRFPriv)
+	_, wrapped := d.VRFFor(appID)
+	vrfPriv, err := factory.NewFromWrappedKey(ctx, wrapped)
 	if err != nil {
 		return [32]byte{}, nil, err
 	}
 	index, proof := vrfPriv.Evaluate(vrf.UniqueID(userID, appID))
 	return index, proof, nil
 }
+
+// previousLeaf returns the value of the leaf that the mutation in in modifies
+// at revision.
+func (s *Server) previousLeaf(ctx context.Context, d *domain.Domain, in *pb.UpdateEntryRequest, revision int64) ([]byte, error) {
+	index, _, err := s.indexFunc(ctx, d, in.GetAppId(), in.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.mapLeaf(ctx, d, index, revision)
+	if err != nil {
+		return nil, err
+	}
+	leaf := resp.MapLeafInclusion[0].Leaf.LeafValue
+	idx, _, ok, err := indexFromPrevVRF(ctx, d, in.GetAppId(), in.GetUserId(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var prevIndex, prevLeaf []byte
+	if ok {
+		prevResp, err := s.mapLeaf(ctx, d, idx, revision)
+		if err != nil {
+			return nil, err
+		}
+		prevIndex, prevLeaf = idx[:], prevResp.MapLeafInclusion[0].Leaf.LeafValue
+	}
+	oldLeaf, err := chooseLeaf(in.GetEntryUpdate().GetMutation(), index[:], leaf, prevIndex, prevLeaf)
+	if err != nil {
+		glog.Warningf("Invalid UpdateEntryRequest: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
+	}
+	return oldLeaf, nil
+}
+
+// chooseLeaf returns the leaf that the mutation m modifies, given the entry's
+// index and leaf under the current VRF and, while the domain VRF is being
+// rotated, its index and leaf under the previous VRF.
+//
+// Entries stay under their previous index until they are updated with their
+// current index. That update moves the entry, and it must set previous_index
+// and chain to the leaf stored there. Otherwise anyone could create an entry
+// at the empty current index that shadows the real one.
+func chooseLeaf(m *pb.Entry, index, leaf, prevIndex, prevLeaf []byte) ([]byte, error) {
+	moving := leaf == nil && prevLeaf != nil
+	switch {
+	case bytes.Equal(m.GetIndex(), index) && moving:
+		if !bytes.Equal(m.GetPreviousIndex(), prevIndex) {
+			return nil, ErrPreviousIndex
+		}
+		return prevLeaf, nil
+	case len(m.GetPreviousIndex()) != 0:
+		return nil, ErrPreviousIndex
+	case bytes.Equal(m.GetIndex(), index):
+		return leaf, nil
+	case bytes.Equal(m.GetIndex(), prevIndex) && moving:
+		return prevLeaf, nil
+	default:
+		// Entries that have moved and new entries use the current VRF.
+		return nil, ErrWrongIndex
+	}
+}
+
+// indexFromPrevVRF returns the index and proof for domain/app/user computed
+// with the domain's previous VRF. ok is false if the previous VRF is not
+// valid for appID at time now.
+func indexFromPrevVRF(ctx context.Context, d *domain.Domain, appID, userID string, now time.Time) (index [32]byte, proof []byte, ok bool, err error) {
+	_, wrapped, ok := d.PrevVRFFor(appID, now)
+	if !ok {
+		return [32]byte{}, nil, false, nil
+	}
+	vrfPriv, err := factory.NewFromWrappedKey(ctx, wrapped)
+	if err != nil {
+		return [32]byte{}, nil, false, err
+	}
+	index, proof = vrfPriv.Evaluate(vrf.UniqueID(userID, appID))
+	return index, proof, true, nil
+}
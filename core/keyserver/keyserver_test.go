@@ -15,18 +15,94 @@
 package keyserver
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/authentication"
+	"github.com/google/keytransparency/core/authorization"
 	"github.com/google/keytransparency/core/domain"
 	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/trillian/crypto/sigpb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
 )
 
+func TestNewOptions(t *testing.T) {
+	// The admin, authorization and queue clients are never called by New.
+	admin := struct{ tpb.TrillianAdminClient }{}
+	valid := func() Options {
+		return Options{
+			Log:       fake.NewTrillianLogClient(),
+			Map:       fake.NewTrillianMapClient(),
+			LogAdmin:  admin,
+			MapAdmin:  admin,
+			Auth:      authentication.NewFake(),
+			Authz:     struct{ authorization.Authorization }{},
+			Domains:   fake.NewDomainStorage(),
+			Purged:    fake.NewPurgeStorage(),
+			Apps:      fake.NewAppIndexStorage(),
+			Quotas:    fake.NewQuotaStorage(),
+			Queue:     struct{ mutator.MutationQueue }{},
+			Mutations: fake.NewMutationStorage(),
+		}
+	}
+	for _, tc := range []struct {
+		desc    string
+		edit    func(o *Options)
+		wantErr bool
+	}{
+		{desc: "valid", edit: func(o *Options) {}},
+		{desc: "no log", edit: func(o *Options) { o.Log = nil }, wantErr: true},
+		{desc: "no map", edit: func(o *Options) { o.Map = nil }, wantErr: true},
+		{desc: "no log admin", edit: func(o *Options) { o.LogAdmin = nil }, wantErr: true},
+		{desc: "no map admin", edit: func(o *Options) { o.MapAdmin = nil }, wantErr: true},
+		{desc: "no auth", edit: func(o *Options) { o.Auth = nil }, wantErr: true},
+		{desc: "no authz", edit: func(o *Options) { o.Authz = nil }, wantErr: true},
+		{desc: "no domains", edit: func(o *Options) { o.Domains = nil }, wantErr: true},
+		{desc: "no purged", edit: func(o *Options) { o.Purged = nil }, wantErr: true},
+		{desc: "no apps", edit: func(o *Options) { o.Apps = nil }, wantErr: true},
+		{desc: "no quotas", edit: func(o *Options) { o.Quotas = nil }, wantErr: true},
+		{desc: "no queue", edit: func(o *Options) { o.Queue = nil }, wantErr: true},
+		{desc: "no mutations", edit: func(o *Options) { o.Mutations = nil }, wantErr: true},
+		{desc: "negative interval", edit: func(o *Options) { o.KeyChangeInterval = -time.Second }, wantErr: true},
+	} {
+		opts := valid()
+		tc.edit(&opts)
+		svr, err := New(opts)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: New(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if svr.mutator == nil {
+			t.Errorf("%v: New() did not default Mutator", tc.desc)
+		}
+		if svr.keyChangeInterval != defaultKeyChangeInterval {
+			t.Errorf("%v: keyChangeInterval: %v, want %v", tc.desc, svr.keyChangeInterval, defaultKeyChangeInterval)
+		}
+		if _, err := svr.logs.Get(smhlog.Default); err != nil {
+			t.Errorf("%v: logs.Get(default): %v", tc.desc, err)
+		}
+	}
+}
+
 func TestLatestRevision(t *testing.T) {
 	ctx := context.Background()
 	mapID := int64(2)
@@ -51,7 +127,8 @@ func TestLatestRevision(t *testing.T) {
 
 	srv := &Server{
 		domains: fakeAdmin,
-		tlog:    fakeLog,
+		purged:  fake.NewPurgeStorage(),
+		logs:    smhlog.Backends{smhlog.Default: {Log: fakeLog}},
 		tmap:    fakeMap,
 		indexFunc: func(context.Context, *domain.Domain, string, string) ([32]byte, []byte, error) {
 			return [32]byte{}, []byte(""), nil
@@ -101,3 +178,270 @@ func TestLatestRevision(t *testing.T) {
 	}
 
 }
+
+func TestGetEntryAtRevision(t *testing.T) {
+	ctx := context.Background()
+	fakeAdmin := fake.NewDomainStorage()
+	fakeLog := fake.NewTrillianLogClient()
+	fakeLog.TreeSize = 4 // Revision 3.
+	if err := fakeAdmin.Write(ctx, &domain.Domain{
+		DomainID:    domainID,
+		MapID:       2,
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}); err != nil {
+		t.Fatalf("admin.Write(): %v", err)
+	}
+	fakeMap := fake.NewTrillianMapClient()
+	for i := 0; i < 3; i++ {
+		fakeMap.SetLeaves(ctx, nil)
+	}
+	srv := &Server{
+		domains: fakeAdmin,
+		purged:  fake.NewPurgeStorage(),
+		logs:    smhlog.Backends{smhlog.Default: {Log: fakeLog}},
+		tmap:    fakeMap,
+		indexFunc: func(context.Context, *domain.Domain, string, string) ([32]byte, []byte, error) {
+			return [32]byte{}, []byte(""), nil
+		},
+	}
+
+	for _, tc := range []struct {
+		revision int64
+		wantErr  codes.Code
+		wantRev  int64
+	}{
+		{revision: 1, wantRev: 1},
+		{revision: 3, wantRev: 3},
+		{revision: 4, wantErr: codes.InvalidArgument},
+		{revision: -1, wantErr: codes.InvalidArgument},
+	} {
+		resp, err := srv.GetEntryAtRevision(ctx, &pb.GetEntryAtRevisionRequest{
+			DomainId: domainID,
+			Revision: tc.revision,
+		})
+		if got := status.Code(err); got != tc.wantErr {
+			t.Errorf("GetEntryAtRevision(revision %v): %v, want %v", tc.revision, err, tc.wantErr)
+			continue
+		}
+		if err == nil && resp.GetSmr().GetMapRevision() != tc.wantRev {
+			t.Errorf("GetEntryAtRevision(revision %v).Rev: %v, want %v", tc.revision, resp.GetSmr().GetMapRevision(), tc.wantRev)
+		}
+		if err == nil && resp.GetLogRoot().GetTreeSize() != 4 {
+			t.Errorf("GetEntryAtRevision(revision %v): log root size %v, want the latest log root", tc.revision, resp.GetLogRoot().GetTreeSize())
+		}
+	}
+	// Negative revisions are rejected before storage is read.
+	if _, err := srv.GetEntryAtRevision(ctx, &pb.GetEntryAtRevisionRequest{
+		DomainId: "unknown",
+		Revision: -1,
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetEntryAtRevision(unknown domain, revision -1): %v, want %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestListEntryHistoryPages(t *testing.T) {
+	ctx := context.Background()
+	fakeAdmin := fake.NewDomainStorage()
+	fakeLog := fake.NewTrillianLogClient()
+	fakeLog.TreeSize = 4 // Revision 3.
+	if err := fakeAdmin.Write(ctx, &domain.Domain{
+		DomainID:    domainID,
+		MapID:       2,
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}); err != nil {
+		t.Fatalf("admin.Write(): %v", err)
+	}
+	fakeMap := fake.NewTrillianMapClient()
+	for i := 0; i < 3; i++ {
+		fakeMap.SetLeaves(ctx, nil)
+	}
+	srv := &Server{
+		domains: fakeAdmin,
+		purged:  fake.NewPurgeStorage(),
+		logs:    smhlog.Backends{smhlog.Default: {Log: fakeLog}},
+		tmap:    fakeMap,
+		indexFunc: func(context.Context, *domain.Domain, string, string) ([32]byte, []byte, error) {
+			return [32]byte{}, []byte(""), nil
+		},
+	}
+
+	for _, tc := range []struct {
+		desc        string
+		newestFirst bool
+		pageSize    int32
+		want        [][]int64
+	}{
+		{desc: "oldest first", pageSize: 3, want: [][]int64{{0, 1, 2}, {3}}},
+		{desc: "newest first", newestFirst: true, pageSize: 2, want: [][]int64{{3, 2}, {1, 0}}},
+		{desc: "newest first short page", newestFirst: true, pageSize: 3, want: [][]int64{{3, 2, 1}, {0}}},
+	} {
+		token := ""
+		for i, want := range tc.want {
+			resp, err := srv.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+				DomainId:    domainID,
+				PageSize:    tc.pageSize,
+				PageToken:   token,
+				NewestFirst: tc.newestFirst,
+			})
+			if err != nil {
+				t.Fatalf("%v: ListEntryHistory(page %v): %v", tc.desc, i, err)
+			}
+			var got []int64
+			for _, v := range resp.GetValues() {
+				got = append(got, v.GetSmr().GetMapRevision())
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%v: ListEntryHistory(page %v): revisions %v, want %v", tc.desc, i, got, want)
+			}
+			token = resp.GetNextPageToken()
+			if last := i == len(tc.want)-1; last != (token == "") {
+				t.Errorf("%v: ListEntryHistory(page %v).NextPageToken: %q, want empty %v", tc.desc, i, token, last)
+			}
+		}
+	}
+
+	if _, err := srv.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+		DomainId:  domainID,
+		PageToken: "bad",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListEntryHistory(bad token): %v, want %v", err, codes.InvalidArgument)
+	}
+
+	// Tokens cannot be replayed with the opposite ordering.
+	resp, err := srv.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+		DomainId:    domainID,
+		PageSize:    2,
+		NewestFirst: true,
+	})
+	if err != nil {
+		t.Fatalf("ListEntryHistory(): %v", err)
+	}
+	if _, err := srv.ListEntryHistory(ctx, &pb.ListEntryHistoryRequest{
+		DomainId:  domainID,
+		PageSize:  2,
+		PageToken: resp.GetNextPageToken(),
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListEntryHistory(newest first token): %v, want %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestFrozenDomain(t *testing.T) {
+	ctx := context.Background()
+	fakeAdmin := fake.NewDomainStorage()
+	fakeLog := fake.NewTrillianLogClient()
+	fakeLog.TreeSize = 2
+	if err := fakeAdmin.Write(ctx, &domain.Domain{
+		DomainID:    domainID,
+		MapID:       2,
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}); err != nil {
+		t.Fatalf("admin.Write(): %v", err)
+	}
+	if err := fakeAdmin.SetFrozen(ctx, domainID, true); err != nil {
+		t.Fatalf("admin.SetFrozen(): %v", err)
+	}
+	fakeMap := fake.NewTrillianMapClient()
+	fakeMap.SetLeaves(ctx, nil)
+	srv := &Server{
+		domains: fakeAdmin,
+		purged:  fake.NewPurgeStorage(),
+		logs:    smhlog.Backends{smhlog.Default: {Log: fakeLog}},
+		tmap:    fakeMap,
+		indexFunc: func(context.Context, *domain.Domain, string, string) ([32]byte, []byte, error) {
+			return [32]byte{}, []byte(""), nil
+		},
+	}
+
+	_, err := srv.UpdateEntry(ctx, &pb.UpdateEntryRequest{
+		DomainId: domainID,
+		UserId:   "joe",
+		AppId:    "app",
+		EntryUpdate: &pb.EntryUpdate{
+			Mutation: &pb.Entry{
+				Previous:   make([]byte, sha256.Size),
+				Signatures: map[string]*sigpb.DigitallySigned{"key": {}},
+			},
+			Committed: &pb.Committed{},
+		},
+	})
+	if got, want := status.Code(err), codes.FailedPrecondition; got != want {
+		t.Errorf("UpdateEntry(): %v, want %v", err, want)
+	}
+	if _, err := srv.GetEntry(ctx, &pb.GetEntryRequest{DomainId: domainID}); err != nil {
+		t.Errorf("GetEntry(): %v, want nil", err)
+	}
+}
+
+func TestChooseLeaf(t *testing.T) {
+	index, prevIndex := []byte("new"), []byte("old")
+	for _, tc := range []struct {
+		desc           string
+		m              *pb.Entry
+		leaf, prevLeaf []byte
+		noRotation     bool
+		want           []byte
+		wantErr        error
+	}{
+		{desc: "update", m: &pb.Entry{Index: index}, leaf: []byte("a"), noRotation: true, want: []byte("a")},
+		{desc: "create", m: &pb.Entry{Index: index}, noRotation: true},
+		{desc: "previous index without rotation", m: &pb.Entry{Index: index, PreviousIndex: prevIndex},
+			noRotation: true, wantErr: ErrPreviousIndex},
+		{desc: "create during rotation", m: &pb.Entry{Index: index}},
+		{desc: "moved", m: &pb.Entry{Index: index}, leaf: []byte("b"), prevLeaf: []byte("a"), want: []byte("b")},
+		{desc: "move", m: &pb.Entry{Index: index, PreviousIndex: prevIndex}, prevLeaf: []byte("a"), want: []byte("a")},
+		{desc: "shadow", m: &pb.Entry{Index: index}, prevLeaf: []byte("a"), wantErr: ErrPreviousIndex},
+		{desc: "move from other index", m: &pb.Entry{Index: index, PreviousIndex: []byte("other")},
+			prevLeaf: []byte("a"), wantErr: ErrPreviousIndex},
+		{desc: "moved twice", m: &pb.Entry{Index: index, PreviousIndex: prevIndex},
+			leaf: []byte("b"), prevLeaf: []byte("a"), wantErr: ErrPreviousIndex},
+		{desc: "old index", m: &pb.Entry{Index: prevIndex}, prevLeaf: []byte("a"), want: []byte("a")},
+		{desc: "old index after move", m: &pb.Entry{Index: prevIndex}, leaf: []byte("b"), prevLeaf: []byte("a"),
+			wantErr: ErrWrongIndex},
+		{desc: "create at old index", m: &pb.Entry{Index: prevIndex}, wantErr: ErrWrongIndex},
+		{desc: "other index", m: &pb.Entry{Index: []byte("other")}, leaf: []byte("a"), wantErr: ErrWrongIndex},
+	} {
+		prev := prevIndex
+		if tc.noRotation {
+			prev = nil
+		}
+		got, err := chooseLeaf(tc.m, index, tc.leaf, prev, tc.prevLeaf)
+		if err != tc.wantErr {
+			t.Errorf("%v: chooseLeaf(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("%v: chooseLeaf(): %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestSignConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	signer := tcrypto.NewSHA256Signer(key)
+	d := &pb.Domain{DomainId: domainID, Frozen: true}
+	now := time.Now()
+
+	signed, err := signConfig(signer, d, now)
+	if err != nil {
+		t.Fatalf("signConfig(): %v", err)
+	}
+	if err := tcrypto.Verify(signer.Public(), signed.GetConfig(), signed.GetSignature()); err != nil {
+		t.Errorf("Verify(): %v", err)
+	}
+	var config pb.DomainConfig
+	if err := proto.Unmarshal(signed.GetConfig(), &config); err != nil {
+		t.Fatalf("proto.Unmarshal(): %v", err)
+	}
+	if !proto.Equal(config.GetDomain(), d) {
+		t.Errorf("signConfig(): domain %v, want %v", config.GetDomain(), d)
+	}
+	if ts, err := ptypes.Timestamp(config.GetTimestamp()); err != nil || !ts.Equal(now) {
+		t.Errorf("signConfig(): timestamp %v, %v, want %v", ts, err, now)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// historyToken is the position of a ListEntryHistory listing together with
+// the request it continues. Clients receive it as an opaque page token.
+type historyToken struct {
+	DomainID    string `json:"d"`
+	AppID       string `json:"a"`
+	UserID      string `json:"u"`
+	NewestFirst bool   `json:"n,omitempty"`
+	// Start is the first epoch of the next page.
+	Start int64 `json:"s"`
+}
+
+// newHistoryToken returns the page token of the page of in that starts at
+// epoch start.
+func newHistoryToken(in *pb.ListEntryHistoryRequest, start int64) (string, error) {
+	b, err := json.Marshal(historyToken{
+		DomainID:    in.GetDomainId(),
+		AppID:       in.GetAppId(),
+		UserID:      in.GetUserId(),
+		NewestFirst: in.GetNewestFirst(),
+		Start:       start,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// parseHistoryToken returns the first epoch of the page that token refers
+// to. Tokens are only accepted by requests for the same entry and ordering as
+// the request that they were returned for.
+func parseHistoryToken(token string, in *pb.ListEntryHistoryRequest) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		glog.Errorf("parseHistoryToken(%v): %v", token, err)
+		return 0, status.Errorf(codes.InvalidArgument, "Invalid page token")
+	}
+	var t historyToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		glog.Errorf("parseHistoryToken(%v): %v", token, err)
+		return 0, status.Errorf(codes.InvalidArgument, "Invalid page token")
+	}
+	if t.DomainID != in.GetDomainId() || t.AppID != in.GetAppId() ||
+		t.UserID != in.GetUserId() || t.NewestFirst != in.GetNewestFirst() {
+		return 0, status.Errorf(codes.InvalidArgument, "Page token does not match the request")
+	}
+	return t.Start, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestHistoryToken(t *testing.T) {
+	in := &pb.ListEntryHistoryRequest{DomainId: "domain", AppId: "app", UserId: "alice"}
+	token, err := newHistoryToken(in, 5)
+	if err != nil {
+		t.Fatalf("newHistoryToken(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		token   string
+		edit    func(*pb.ListEntryHistoryRequest)
+		want    int64
+		wantErr codes.Code
+	}{
+		{desc: "same request", token: token, want: 5},
+		{desc: "other page size", token: token, edit: func(r *pb.ListEntryHistoryRequest) { r.PageSize = 3 }, want: 5},
+		{desc: "other ordering", token: token, edit: func(r *pb.ListEntryHistoryRequest) { r.NewestFirst = true },
+			wantErr: codes.InvalidArgument},
+		{desc: "other user", token: token, edit: func(r *pb.ListEntryHistoryRequest) { r.UserId = "bob" },
+			wantErr: codes.InvalidArgument},
+		{desc: "other app", token: token, edit: func(r *pb.ListEntryHistoryRequest) { r.AppId = "other" },
+			wantErr: codes.InvalidArgument},
+		{desc: "epoch number", token: "5", wantErr: codes.InvalidArgument},
+		{desc: "not json", token: base64.RawURLEncoding.EncodeToString([]byte("5")), wantErr: codes.InvalidArgument},
+	} {
+		req := proto.Clone(in).(*pb.ListEntryHistoryRequest)
+		if tc.edit != nil {
+			tc.edit(req)
+		}
+		got, err := parseHistoryToken(tc.token, req)
+		if status.Code(err) != tc.wantErr {
+			t.Errorf("%v: parseHistoryToken(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%v: parseHistoryToken(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/permalink"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// GetEntryAtRevision returns a user's entry as of a past map revision,
+// together with proofs linking it to the latest log root.
+func (s *Server) GetEntryAtRevision(ctx context.Context, in *pb.GetEntryAtRevisionRequest) (*pb.GetEntryResponse, error) {
+	domainID := in.GetDomainId()
+	if domainID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Please specify a domain_id")
+	}
+	if in.GetRevision() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Revision is %v, want >= 0", in.GetRevision())
+	}
+	d, err := s.domains.Read(ctx, domainID, false)
+	if err != nil {
+		glog.Errorf("adminstorage.Read(%v): %v", domainID, err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+
+	sth, consistencyProof, err := s.latestLogRootProof(ctx, d, in.GetFirstTreeSize())
+	if err != nil {
+		return nil, err
+	}
+	current, err := mapRevisionFor(sth)
+	if err != nil {
+		glog.Errorf("latestRevision(log %v, sth%v): %v", d.LogID, sth, err)
+		return nil, err
+	}
+	if in.GetRevision() > current {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Revision is %v, want <= %v", in.GetRevision(), current)
+	}
+
+	entryProof, err := s.getEntryByRevision(ctx, sth, d, in.GetUserId(), in.GetAppId(), in.GetRevision())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.GetEntryResponse{
+		LogRoot:        sth,
+		LogConsistency: consistencyProof.GetHashes(),
+	}
+	proto.Merge(resp, entryProof)
+	return resp, nil
+}
+
+// CreatePermalink returns a signed token that refers to a user's entry at a
+// map revision.
+func (s *Server) CreatePermalink(ctx context.Context, in *pb.CreatePermalinkRequest) (*pb.CreatePermalinkResponse, error) {
+	if s.configSigner == nil {
+		return nil, status.Errorf(codes.Unimplemented, "Permalinks are not enabled")
+	}
+	if in.GetRevision() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"Revision is %v, want >= 0", in.GetRevision())
+	}
+	revision := in.GetRevision()
+	if revision == 0 {
+		d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+		if err != nil {
+			glog.Errorf("adminstorage.Read(%v): %v", in.GetDomainId(), err)
+			return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+		}
+		sth, err := s.latestLogRoot(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		if revision, err = mapRevisionFor(sth); err != nil {
+			glog.Errorf("latestRevision(log %v, sth%v): %v", d.LogID, sth, err)
+			return nil, err
+		}
+	}
+
+	e, err := s.GetEntryAtRevision(ctx, &pb.GetEntryAtRevisionRequest{
+		DomainId: in.GetDomainId(),
+		AppId:    in.GetAppId(),
+		UserId:   in.GetUserId(),
+		Revision: revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+	now, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Cannot encode issue time")
+	}
+	p := &pb.Permalink{
+		DomainId:    in.GetDomainId(),
+		AppId:       in.GetAppId(),
+		UserId:      in.GetUserId(),
+		Revision:    revision,
+		ProofDigest: permalink.Digest(e.GetSmr().GetRootHash(), e.GetLeafProof().GetLeaf().GetLeafValue()),
+		IssueTime:   now,
+	}
+	token, err := permalink.Sign(s.configSigner, p)
+	if err != nil {
+		glog.Errorf("permalink.Sign(%v): %v", p, err)
+		return nil, status.Errorf(codes.Internal, "Cannot sign permalink")
+	}
+	return &pb.CreatePermalinkResponse{Token: token, Permalink: p}, nil
+}
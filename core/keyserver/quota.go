@@ -0,0 +1,77 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/quota"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// checkQuota returns a RESOURCE_EXHAUSTED error if accepting another mutation
+// for userID at time now would exceed the mutation quota of d. revision is
+// the latest map revision.
+func (s *Server) checkQuota(ctx context.Context, d *domain.Domain, userID string, revision int64, now time.Time) error {
+	q := d.MutationQuota
+	if limit := q.GetMutationsPerEpoch(); limit > 0 {
+		n, err := s.quotas.CountRevision(ctx, d.DomainID, revision)
+		if err != nil {
+			glog.Errorf("quotas.CountRevision(%v, %v): %v", d.DomainID, revision, err)
+			return status.Errorf(codes.Internal, "Cannot check quota")
+		}
+		if n >= int64(limit) {
+			// A new epoch starts no sooner than MinInterval.
+			return quotaError("mutations_per_epoch", limit, d.MinInterval)
+		}
+	}
+	if limit := q.GetMutationsPerUserPerDay(); limit > 0 {
+		n, oldest, err := s.quotas.CountUser(ctx, d.DomainID, userID, now.Add(-quota.UserWindow))
+		if err != nil {
+			glog.Errorf("quotas.CountUser(%v): %v", d.DomainID, err)
+			return status.Errorf(codes.Internal, "Cannot check quota")
+		}
+		if n >= int64(limit) {
+			// Capacity returns when the oldest counted mutation
+			// leaves the window.
+			return quotaError("mutations_per_user_per_day", limit, oldest.Add(quota.UserWindow).Sub(now))
+		}
+	}
+	return nil
+}
+
+// quotaError returns a RESOURCE_EXHAUSTED error with a pb.QuotaViolation
+// detail describing the exhausted quota.
+func quotaError(name string, limit int32, retryAfter time.Duration) error {
+	st := status.Newf(codes.ResourceExhausted, "Quota %v of %v exceeded", name, limit)
+	detailed, err := st.WithDetails(&pb.QuotaViolation{
+		Quota:      name,
+		Limit:      limit,
+		RetryAfter: ptypes.DurationProto(retryAfter),
+	})
+	if err != nil {
+		glog.Errorf("WithDetails(): %v", err)
+		return st.Err()
+	}
+	return detailed.Err()
+}
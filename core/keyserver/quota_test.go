@@ -0,0 +1,104 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestCheckQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000000, 0)
+	d := &domain.Domain{
+		DomainID:    domainID,
+		MinInterval: time.Second,
+		MutationQuota: &pb.MutationQuota{
+			MutationsPerEpoch:      3,
+			MutationsPerUserPerDay: 2,
+		},
+	}
+	quotas := fake.NewQuotaStorage()
+	for _, r := range []struct {
+		userID   string
+		revision int64
+		ts       time.Time
+	}{
+		{"alice", 1, now.Add(-25 * time.Hour)}, // Outside the window.
+		{"alice", 2, now.Add(-2 * time.Hour)},
+		{"alice", 2, now.Add(-time.Hour)},
+		{"bob", 3, now.Add(-time.Hour)},
+		{"carol", 3, now.Add(-time.Hour)},
+	} {
+		if err := quotas.Record(ctx, domainID, r.userID, r.revision, r.ts); err != nil {
+			t.Fatalf("Record(): %v", err)
+		}
+	}
+	srv := &Server{quotas: quotas}
+
+	for _, tc := range []struct {
+		desc       string
+		d          *domain.Domain
+		userID     string
+		revision   int64
+		wantQuota  string // Empty if the mutation is allowed.
+		retryAfter time.Duration
+	}{
+		{desc: "no quota", d: &domain.Domain{DomainID: domainID}, userID: "alice", revision: 2},
+		{desc: "allowed", d: d, userID: "bob", revision: 3},
+		{desc: "user", d: d, userID: "alice", revision: 3, wantQuota: "mutations_per_user_per_day", retryAfter: 22 * time.Hour},
+		{desc: "epoch", d: &domain.Domain{
+			DomainID:      domainID,
+			MinInterval:   time.Second,
+			MutationQuota: &pb.MutationQuota{MutationsPerEpoch: 2},
+		}, userID: "dave", revision: 3, wantQuota: "mutations_per_epoch", retryAfter: time.Second},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := srv.checkQuota(ctx, tc.d, tc.userID, tc.revision, now)
+			if tc.wantQuota == "" {
+				if err != nil {
+					t.Fatalf("checkQuota(): %v, want nil", err)
+				}
+				return
+			}
+			st := status.Convert(err)
+			if st.Code() != codes.ResourceExhausted {
+				t.Fatalf("checkQuota(): %v, want %v", err, codes.ResourceExhausted)
+			}
+			if len(st.Details()) != 1 {
+				t.Fatalf("Details(): %v, want one QuotaViolation", st.Details())
+			}
+			v, ok := st.Details()[0].(*pb.QuotaViolation)
+			if !ok {
+				t.Fatalf("Details()[0]: %T, want *pb.QuotaViolation", st.Details()[0])
+			}
+			if v.GetQuota() != tc.wantQuota {
+				t.Errorf("Quota: %v, want %v", v.GetQuota(), tc.wantQuota)
+			}
+			if got, err := ptypes.Duration(v.GetRetryAfter()); err != nil || got != tc.retryAfter {
+				t.Errorf("RetryAfter: %v, %v, want %v", got, err, tc.retryAfter)
+			}
+		})
+	}
+}
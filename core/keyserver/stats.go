@@ -0,0 +1,297 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/keyspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/mutator"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+var (
+	// Number of epochs aggregated when the request does not specify one.
+	defaultStatsEpochs = int32(100)
+	// Maximum number of epochs aggregated per request to bound server work.
+	maxStatsEpochs = int32(1000)
+	// Counts smaller than statsThreshold are suppressed so that the
+	// statistics cannot be used to learn about individual users.
+	statsThreshold = int64(10)
+	// Bucket that small key algorithm counts are folded into.
+	otherAlgorithm = "other"
+	// How long computed statistics are served before they are recomputed.
+	statsCacheTTL = time.Minute
+	// Maximum number of statistics computed at the same time. Requests
+	// that would exceed it fail with ResourceExhausted.
+	maxStatsComputations = 2
+)
+
+// statsCache holds recently computed statistics and bounds the number of
+// statistics computed concurrently, as each computation reads every mutation
+// of up to maxStatsEpochs epochs. The zero value is ready to use.
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[statsKey]statsEntry
+	running int
+}
+
+// statsKey identifies the statistics of a request.
+type statsKey struct {
+	domainID string
+	epochs   int32
+}
+
+type statsEntry struct {
+	stats    *pb.DomainStats
+	computed time.Time
+}
+
+// get returns a copy of the statistics of key if they were computed less than
+// statsCacheTTL before now.
+func (c *statsCache) get(key statsKey, now time.Time) *pb.DomainStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.Sub(e.computed) >= statsCacheTTL {
+		return nil
+	}
+	return proto.Clone(e.stats).(*pb.DomainStats)
+}
+
+// put caches stats as the statistics of key, and forgets expired statistics.
+func (c *statsCache) put(key statsKey, stats *pb.DomainStats, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[statsKey]statsEntry)
+	}
+	for k, e := range c.entries {
+		if now.Sub(e.computed) >= statsCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = statsEntry{stats: proto.Clone(stats).(*pb.DomainStats), computed: now}
+}
+
+// acquire reserves one of the maxStatsComputations slots. It returns false if
+// none is free.
+func (c *statsCache) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running >= maxStatsComputations {
+		return false
+	}
+	c.running++
+	return true
+}
+
+// release frees a slot reserved by acquire.
+func (c *statsCache) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running--
+}
+
+// GetDomainStats returns aggregate statistics computed from the mutations
+// applied in the most recent epochs of a domain. Statistics are cached for
+// statsCacheTTL, so they may lag behind the latest epochs.
+func (s *Server) GetDomainStats(ctx context.Context, in *pb.GetDomainStatsRequest) (*pb.DomainStats, error) {
+	if in.GetEpochs() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "epochs must be >= 0")
+	}
+	epochs := in.GetEpochs()
+	switch {
+	case epochs == 0:
+		epochs = defaultStatsEpochs
+	case epochs > maxStatsEpochs:
+		epochs = maxStatsEpochs
+	}
+	key := statsKey{domainID: in.GetDomainId(), epochs: epochs}
+	if stats := s.stats.get(key, time.Now()); stats != nil {
+		return stats, nil
+	}
+	if !s.stats.acquire() {
+		return nil, status.Errorf(codes.ResourceExhausted, "Too many statistics requests, retry later")
+	}
+	defer s.stats.release()
+
+	d, err := s.domains.Read(ctx, in.GetDomainId(), false)
+	if err != nil {
+		glog.Errorf("GetDomainStats(): adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info")
+	}
+	sth, err := s.latestLogRoot(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	end, err := mapRevisionFor(sth)
+	if err != nil {
+		glog.Errorf("mapRevisionFor(log %v, sth%v): %v", d.LogID, sth, err)
+		return nil, err
+	}
+	// Revision 0 is the empty map and has no mutations.
+	start := end - int64(epochs) + 1
+	if start < 1 {
+		start = 1
+	}
+
+	agg := newStatsAggregator()
+	for epoch := start; epoch <= end; epoch++ {
+		if err := s.aggregateEpoch(ctx, d, epoch, agg); err != nil {
+			return nil, err
+		}
+	}
+
+	stats := agg.stats(statsThreshold)
+	stats.DomainId = d.DomainID
+	stats.StartEpoch = start
+	stats.EndEpoch = end
+	lifetimeStats(stats, d.Stats, statsThreshold)
+	if start <= end && stats.Mutations > 0 {
+		elapsed, err := s.elapsed(ctx, d, start-1, end)
+		if err != nil {
+			return nil, err
+		}
+		if elapsed > 0 {
+			stats.MutationsPerHour = float64(stats.Mutations) / elapsed.Hours()
+		}
+	}
+	s.stats.put(key, stats, time.Now())
+	return stats, nil
+}
+
+// aggregateEpoch adds all the mutations in epoch to agg.
+func (s *Server) aggregateEpoch(ctx context.Context, d *domain.Domain, epoch int64, agg *statsAggregator) error {
+	if err := mutator.ReadRevision(ctx, s.mutations, d.DomainID, epoch, maxPageSize, func(e *pb.Entry) bool {
+		agg.add(e)
+		return true
+	}); err != nil {
+		glog.Errorf("GetDomainStats(): %v", err)
+		return status.Error(codes.Internal, "Reading mutations failed")
+	}
+	return nil
+}
+
+// elapsed returns the time between the map roots at revisions from and to.
+func (s *Server) elapsed(ctx context.Context, d *domain.Domain, from, to int64) (time.Duration, error) {
+	var nanos [2]int64
+	for i, rev := range []int64{from, to} {
+		resp, err := s.tmap.GetSignedMapRootByRevision(ctx, &tpb.GetSignedMapRootByRevisionRequest{
+			MapId:    d.MapID,
+			Revision: rev,
+		})
+		if err != nil {
+			glog.Errorf("GetDomainStats(): GetSignedMapRootByRevision(%v, %v): %v", d.MapID, rev, err)
+			return 0, status.Errorf(codes.Internal, "Cannot fetch map root")
+		}
+		nanos[i] = resp.GetMapRoot().GetTimestampNanos()
+	}
+	return time.Duration(nanos[1] - nanos[0]), nil
+}
+
+// statsAggregator accumulates raw counts before thresholds are applied.
+type statsAggregator struct {
+	indexes    map[string]bool
+	mutations  int64
+	algorithms map[string]int64
+}
+
+func newStatsAggregator() *statsAggregator {
+	return &statsAggregator{
+		indexes:    make(map[string]bool),
+		algorithms: make(map[string]int64),
+	}
+}
+
+// add counts a single mutation.
+func (a *statsAggregator) add(e *pb.Entry) {
+	a.mutations++
+	a.indexes[string(e.GetIndex())] = true
+	for _, k := range e.GetAuthorizedKeys() {
+		a.algorithms[keyAlgorithm(k)]++
+	}
+}
+
+// stats returns the aggregated statistics, suppressing every count smaller
+// than threshold. Small key algorithm counts are folded into a single bucket
+// which is itself suppressed if it remains below threshold.
+func (a *statsAggregator) stats(threshold int64) *pb.DomainStats {
+	stats := &pb.DomainStats{
+		KeyAlgorithms: make(map[string]int64),
+		Threshold:     threshold,
+	}
+	if n := int64(len(a.indexes)); n >= threshold {
+		stats.ActiveEntries = n
+	}
+	if a.mutations >= threshold {
+		stats.Mutations = a.mutations
+	}
+	var other int64
+	for alg, n := range a.algorithms {
+		if n >= threshold {
+			stats.KeyAlgorithms[alg] = n
+		} else {
+			other += n
+		}
+	}
+	if other >= threshold {
+		stats.KeyAlgorithms[otherAlgorithm] += other
+	}
+	return stats
+}
+
+// lifetimeStats adds the totals maintained by the sequencer to stats. Like the
+// windowed counts, totals smaller than threshold are suppressed.
+func lifetimeStats(stats *pb.DomainStats, totals domain.Stats, threshold int64) {
+	stats.Epochs = totals.Epochs
+	if totals.Indexes >= threshold {
+		stats.TotalIndexes = totals.Indexes
+	}
+	if totals.Mutations >= threshold {
+		stats.TotalMutations = totals.Mutations
+		stats.AverageEpochSize = totals.AverageEpochSize()
+	}
+}
+
+// keyAlgorithm returns a short description of the algorithm of a public key.
+func keyAlgorithm(k *keyspb.PublicKey) string {
+	pk, err := x509.ParsePKIXPublicKey(k.GetDer())
+	if err != nil {
+		return otherAlgorithm
+	}
+	switch pk := pk.(type) {
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA-%v", pk.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%v", pk.N.BitLen())
+	default:
+		return otherAlgorithm
+	}
+}
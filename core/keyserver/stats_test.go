@@ -0,0 +1,139 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestStatsThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		desc          string
+		users         int
+		updates       int
+		algs          []string
+		wantEntries   int64
+		wantMutations int64
+		wantAlgs      map[string]int64
+	}{
+		{desc: "empty", wantAlgs: map[string]int64{}},
+		{desc: "below threshold", users: 3, updates: 3, algs: []string{"junk"},
+			wantAlgs: map[string]int64{}},
+		{desc: "mutations only", users: 5, updates: 2, algs: []string{"junk"},
+			wantMutations: 10, wantAlgs: map[string]int64{"other": 10}},
+		{desc: "all reported", users: 10, updates: 1, algs: []string{"junk", "junk"},
+			wantEntries: 10, wantMutations: 10, wantAlgs: map[string]int64{"other": 20}},
+	} {
+		agg := newStatsAggregator()
+		for u := 0; u < tc.users; u++ {
+			for i := 0; i < tc.updates; i++ {
+				var keys []*keyspb.PublicKey
+				for _, a := range tc.algs {
+					keys = append(keys, &keyspb.PublicKey{Der: []byte(a)})
+				}
+				agg.add(&pb.Entry{
+					Index:          []byte(fmt.Sprintf("user%v", u)),
+					AuthorizedKeys: keys,
+				})
+			}
+		}
+		stats := agg.stats(10)
+		if got, want := stats.ActiveEntries, tc.wantEntries; got != want {
+			t.Errorf("%v: ActiveEntries: %v, want %v", tc.desc, got, want)
+		}
+		if got, want := stats.Mutations, tc.wantMutations; got != want {
+			t.Errorf("%v: Mutations: %v, want %v", tc.desc, got, want)
+		}
+		if got, want := stats.KeyAlgorithms, tc.wantAlgs; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: KeyAlgorithms: %v, want %v", tc.desc, got, want)
+		}
+	}
+}
+
+func TestLifetimeStats(t *testing.T) {
+	for _, tc := range []struct {
+		desc   string
+		totals domain.Stats
+		want   pb.DomainStats
+	}{
+		{desc: "empty"},
+		{desc: "below threshold", totals: domain.Stats{Epochs: 3, Mutations: 6, Indexes: 2},
+			want: pb.DomainStats{Epochs: 3}},
+		{desc: "mutations only", totals: domain.Stats{Epochs: 4, Mutations: 20, Indexes: 5},
+			want: pb.DomainStats{Epochs: 4, TotalMutations: 20, AverageEpochSize: 5}},
+		{desc: "all reported", totals: domain.Stats{Epochs: 10, Mutations: 25, Indexes: 12},
+			want: pb.DomainStats{Epochs: 10, TotalMutations: 25, TotalIndexes: 12, AverageEpochSize: 2.5}},
+	} {
+		var got pb.DomainStats
+		lifetimeStats(&got, tc.totals, 10)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: lifetimeStats(): %+v, want %+v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	var c statsCache
+	now := time.Unix(1000, 0)
+	key := statsKey{domainID: "domain", epochs: 100}
+	if got := c.get(key, now); got != nil {
+		t.Errorf("get() before put(): %v, want nil", got)
+	}
+	c.put(key, &pb.DomainStats{Mutations: 20}, now)
+
+	got := c.get(key, now.Add(statsCacheTTL-time.Second))
+	if got.GetMutations() != 20 {
+		t.Errorf("get(): %v, want cached stats", got)
+	}
+	// Callers may modify the returned statistics.
+	got.Mutations = 30
+	if got := c.get(key, now); got.GetMutations() != 20 {
+		t.Errorf("get() after modifying a result: %v, want 20 mutations", got)
+	}
+	if got := c.get(statsKey{domainID: "domain", epochs: 10}, now); got != nil {
+		t.Errorf("get(other epochs): %v, want nil", got)
+	}
+	if got := c.get(key, now.Add(statsCacheTTL)); got != nil {
+		t.Errorf("get() after statsCacheTTL: %v, want nil", got)
+	}
+	c.put(statsKey{domainID: "other"}, &pb.DomainStats{}, now.Add(statsCacheTTL))
+	if got := len(c.entries); got != 1 {
+		t.Errorf("put() kept %v entries, want expired entries removed", got)
+	}
+}
+
+func TestStatsCacheAcquire(t *testing.T) {
+	var c statsCache
+	for i := 0; i < maxStatsComputations; i++ {
+		if !c.acquire() {
+			t.Fatalf("acquire() %v: false, want true", i)
+		}
+	}
+	if c.acquire() {
+		t.Errorf("acquire() with all slots taken: true, want false")
+	}
+	c.release()
+	if !c.acquire() {
+		t.Errorf("acquire() after release(): false, want true")
+	}
+}
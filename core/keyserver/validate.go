@@ -18,12 +18,15 @@ package keyserver
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/keytransparency/core/crypto/commitments"
 	"github.com/google/keytransparency/core/crypto/vrf"
+	"github.com/google/keytransparency/core/mutator"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
@@ -45,11 +48,34 @@ var (
 	// ErrWrongIndex occurs when the index in key value does not match the
 	// output of VRF.
 	ErrWrongIndex = errors.New("index does not match VRF")
+	// ErrPreviousIndex occurs when the previous index of a mutation is not
+	// the index of the entry it moves from the previous domain VRF.
+	ErrPreviousIndex = errors.New("previous index does not match VRF")
 	// ErrInvalidStart occurs when the start epoch of ListEntryHistoryRequest
 	// is not valid (not in [1, currentEpoch]).
 	ErrInvalidStart = errors.New("invalid start epoch")
 	// ErrInvalidPageSize occurs when the page size is < 0.
 	ErrInvalidPageSize = errors.New("Invalid page size")
+	// ErrInvalidTreeSizes occurs when the tree sizes of a
+	// GetLogConsistencyChainRequest are missing, out of order, or larger
+	// than the current log, or when the tree size of a WatchEntryRequest is
+	// negative.
+	ErrInvalidTreeSizes = errors.New("invalid tree sizes")
+	// ErrNoUserID occurs when the user id is missing.
+	ErrNoUserID = errors.New("missing UserID")
+	// ErrNoMutation occurs when the entry update or its mutation is missing.
+	ErrNoMutation = errors.New("missing mutation")
+	// ErrNoSignatures occurs when a mutation carries no signatures.
+	ErrNoSignatures = errors.New("mutation is not signed")
+	// ErrPreviousLen occurs when the previous entry hash in a mutation is
+	// not a hash.
+	ErrPreviousLen = errors.New("mutation.previous is not a valid hash")
+	// ErrInvalidWebhook occurs when a key change webhook is not an absolute
+	// https URL.
+	ErrInvalidWebhook = errors.New("webhook must be an https URL")
+	// ErrResetData occurs when a reset or revoke mutation commits to
+	// profile data rather than clearing the profile.
+	ErrResetData = errors.New("reset must commit to an empty profile")
 )
 
 // validateKey verifies:
@@ -68,6 +94,35 @@ func validateKey(userID, appID string, key []byte) error {
 	return nil
 }
 
+// validateEntryUpdate verifies the structure of an UpdateEntryRequest so that
+// malformed mutations are rejected before they are queued:
+// - UserID and AppID are present.
+// - The mutation and its commitment are present.
+// - The mutation carries at least one signature.
+// - The mutation's pointer to the previous entry is a hash.
+func validateEntryUpdate(in *pb.UpdateEntryRequest) error {
+	if in.GetUserId() == "" {
+		return ErrNoUserID
+	}
+	if in.GetAppId() == "" {
+		return ErrNoAppID
+	}
+	m := in.GetEntryUpdate().GetMutation()
+	if m == nil {
+		return ErrNoMutation
+	}
+	if in.GetEntryUpdate().GetCommitted() == nil {
+		return ErrNoCommitted
+	}
+	if len(m.GetSignatures()) == 0 {
+		return ErrNoSignatures
+	}
+	if got, want := len(m.GetPrevious()), sha256.Size; got != want {
+		return ErrPreviousLen
+	}
+	return nil
+}
+
 // validateUpdateEntryRequest verifies
 // - Commitment in SignedEntryUpdate matches the serialized profile.
 // - Profile is a valid.
@@ -91,13 +146,22 @@ func validateUpdateEntryRequest(in *pb.UpdateEntryRequest, vrfPriv vrf.PrivateKe
 	if err := commitments.Verify(in.UserId, in.AppId, entry.Commitment, committed.Data, committed.Key); err != nil {
 		return err
 	}
+	// Resets, signed by recovery keys, and revocations may only clear the
+	// profile.
+	if t := mutator.TypeOf(entry); t == mutator.TypeReset || t == mutator.TypeRevoke {
+		if len(committed.GetData()) != 0 {
+			return ErrResetData
+		}
+		return nil
+	}
 
 	return validateKey(in.GetUserId(), in.GetAppId(), committed.GetData())
 }
 
 // validateListEntryHistoryRequest ensures that start epoch is in range [1,
 // currentEpoch] and sets the page size if it is 0 or larger than what the server
-// can return (due to reaching currentEpoch).
+// can return (due to reaching currentEpoch, or epoch 0 when listing newest
+// first).
 func validateListEntryHistoryRequest(in *pb.ListEntryHistoryRequest, currentEpoch int64) error {
 	if in.Start < 0 || in.Start > currentEpoch {
 		return ErrInvalidStart
@@ -111,9 +175,31 @@ func validateListEntryHistoryRequest(in *pb.ListEntryHistoryRequest, currentEpoc
 	case in.PageSize > maxPageSize:
 		in.PageSize = maxPageSize
 	}
-	// Ensure in.PageSize does not exceed currentEpoch.
-	if in.Start+int64(in.PageSize) > currentEpoch {
-		in.PageSize = int32(currentEpoch - in.Start + 1)
+	// Ensure in.PageSize does not exceed currentEpoch, or epoch 0.
+	remaining := currentEpoch - in.Start + 1
+	if in.NewestFirst {
+		remaining = in.Start + 1
+	}
+	if int64(in.PageSize) > remaining {
+		in.PageSize = int32(remaining)
+	}
+	return nil
+}
+
+// validateGetLogConsistencyChainRequest ensures that tree sizes are present,
+// positive, in increasing order, no larger than currentTreeSize, and that there
+// are not too many of them.
+func validateGetLogConsistencyChainRequest(in *pb.GetLogConsistencyChainRequest, currentTreeSize int64) error {
+	sizes := in.GetTreeSizes()
+	if len(sizes) == 0 || len(sizes) > int(maxPageSize) {
+		return ErrInvalidTreeSizes
+	}
+	prev := int64(0)
+	for _, size := range sizes {
+		if size < 1 || size < prev || size > currentTreeSize {
+			return ErrInvalidTreeSizes
+		}
+		prev = size
 	}
 	return nil
 }
@@ -140,3 +226,52 @@ func validateListMutationsRequest(in *pb.ListMutationsRequest) error {
 	}
 	return nil
 }
+
+// validateWatchKeyChangesRequest ensures that the entry is identified and that
+// the start epoch is not negative.
+func validateWatchKeyChangesRequest(in *pb.WatchKeyChangesRequest) error {
+	switch {
+	case in.GetAppId() == "":
+		return ErrNoAppID
+	case in.GetUserId() == "":
+		return ErrNoUserID
+	case in.GetStartEpoch() < 0:
+		return ErrInvalidStart
+	}
+	return nil
+}
+
+// validateWatchEntryRequest ensures that the watched entry is identified and
+// that the start epoch and tree size are not negative.
+func validateWatchEntryRequest(in *pb.WatchEntryRequest) error {
+	switch {
+	case in.GetAppId() == "":
+		return ErrNoAppID
+	case in.GetUserId() == "":
+		return ErrNoUserID
+	case in.GetStartEpoch() < 0:
+		return ErrInvalidStart
+	case in.GetFirstTreeSize() < 0:
+		return ErrInvalidTreeSizes
+	}
+	return nil
+}
+
+// validateSetKeyChangeWebhookRequest ensures that the entry is identified and
+// that the webhook, if any, is an https URL. Events reveal when a user's keys
+// change, so they are not sent in the clear.
+func validateSetKeyChangeWebhookRequest(in *pb.SetKeyChangeWebhookRequest) error {
+	switch {
+	case in.GetAppId() == "":
+		return ErrNoAppID
+	case in.GetUserId() == "":
+		return ErrNoUserID
+	case in.GetUrl() == "":
+		return nil
+	}
+	u, err := url.Parse(in.GetUrl())
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return ErrInvalidWebhook
+	}
+	return nil
+}
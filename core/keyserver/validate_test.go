@@ -15,6 +15,7 @@
 package keyserver
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"strings"
 	"testing"
@@ -22,6 +23,8 @@ import (
 	"github.com/google/keytransparency/core/crypto/commitments"
 	"github.com/google/keytransparency/core/crypto/vrf"
 	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/trillian/crypto/sigpb"
 
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
@@ -117,3 +120,111 @@ func TestValidateUpdateEntryRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateResetRequest(t *testing.T) {
+	userID := "joe"
+	appID := PGPAppID
+	vrfPriv, _ := p256.GenerateKey()
+	index, _ := vrfPriv.Evaluate(vrf.UniqueID(userID, appID))
+	nonce, err := commitments.GenCommitmentKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		data []byte
+		want error
+	}{
+		{data: nil},
+		{data: []byte("bar"), want: ErrResetData},
+	} {
+		req := &pb.UpdateEntryRequest{
+			UserId: userID,
+			AppId:  appID,
+			EntryUpdate: &pb.EntryUpdate{
+				Mutation: &pb.Entry{
+					Index:        index[:],
+					Commitment:   commitments.Commit(userID, appID, tc.data, nonce),
+					MutationType: mutator.TypeReset,
+				},
+				Committed: &pb.Committed{Key: nonce, Data: tc.data},
+			},
+		}
+		if err := validateUpdateEntryRequest(req, vrfPriv); err != tc.want {
+			t.Errorf("validateUpdateEntryRequest(%s): %v, want %v", tc.data, err, tc.want)
+		}
+	}
+}
+
+func TestValidateEntryUpdate(t *testing.T) {
+	previous := make([]byte, sha256.Size)
+	sigs := map[string]*sigpb.DigitallySigned{"key": {}}
+	for _, tc := range []struct {
+		desc string
+		req  *pb.UpdateEntryRequest
+		want error
+	}{
+		{desc: "missing user", req: &pb.UpdateEntryRequest{AppId: "app"}, want: ErrNoUserID},
+		{desc: "missing app", req: &pb.UpdateEntryRequest{UserId: "joe"}, want: ErrNoAppID},
+		{desc: "missing mutation", req: &pb.UpdateEntryRequest{UserId: "joe", AppId: "app",
+			EntryUpdate: &pb.EntryUpdate{}}, want: ErrNoMutation},
+		{desc: "missing committed", req: &pb.UpdateEntryRequest{UserId: "joe", AppId: "app",
+			EntryUpdate: &pb.EntryUpdate{
+				Mutation: &pb.Entry{Previous: previous, Signatures: sigs},
+			}}, want: ErrNoCommitted},
+		{desc: "unsigned", req: &pb.UpdateEntryRequest{UserId: "joe", AppId: "app",
+			EntryUpdate: &pb.EntryUpdate{
+				Mutation:  &pb.Entry{Previous: previous},
+				Committed: &pb.Committed{},
+			}}, want: ErrNoSignatures},
+		{desc: "bad previous", req: &pb.UpdateEntryRequest{UserId: "joe", AppId: "app",
+			EntryUpdate: &pb.EntryUpdate{
+				Mutation:  &pb.Entry{Previous: []byte("short"), Signatures: sigs},
+				Committed: &pb.Committed{},
+			}}, want: ErrPreviousLen},
+		{desc: "valid", req: &pb.UpdateEntryRequest{UserId: "joe", AppId: "app",
+			EntryUpdate: &pb.EntryUpdate{
+				Mutation:  &pb.Entry{Previous: previous, Signatures: sigs},
+				Committed: &pb.Committed{},
+			}}, want: nil},
+	} {
+		if got := validateEntryUpdate(tc.req); got != tc.want {
+			t.Errorf("%v: validateEntryUpdate(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestValidateGetLogConsistencyChainRequest(t *testing.T) {
+	for _, tc := range []struct {
+		sizes []int64
+		want  error
+	}{
+		{sizes: nil, want: ErrInvalidTreeSizes},
+		{sizes: []int64{0}, want: ErrInvalidTreeSizes},
+		{sizes: []int64{3, 2}, want: ErrInvalidTreeSizes},
+		{sizes: []int64{2, 11}, want: ErrInvalidTreeSizes},
+		{sizes: []int64{1, 1, 5, 10}, want: nil},
+	} {
+		req := &pb.GetLogConsistencyChainRequest{TreeSizes: tc.sizes}
+		if got := validateGetLogConsistencyChainRequest(req, 10); got != tc.want {
+			t.Errorf("validateGetLogConsistencyChainRequest(%v): %v, want %v", tc.sizes, got, tc.want)
+		}
+	}
+}
+
+func TestValidateWatchEntryRequest(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		req  *pb.WatchEntryRequest
+		want error
+	}{
+		{desc: "valid", req: &pb.WatchEntryRequest{AppId: "app", UserId: "alice", FirstTreeSize: 4, StartEpoch: 2}, want: nil},
+		{desc: "no app", req: &pb.WatchEntryRequest{UserId: "alice"}, want: ErrNoAppID},
+		{desc: "no user", req: &pb.WatchEntryRequest{AppId: "app"}, want: ErrNoUserID},
+		{desc: "negative start", req: &pb.WatchEntryRequest{AppId: "app", UserId: "alice", StartEpoch: -1}, want: ErrInvalidStart},
+		{desc: "negative tree size", req: &pb.WatchEntryRequest{AppId: "app", UserId: "alice", FirstTreeSize: -1}, want: ErrInvalidTreeSizes},
+	} {
+		if got := validateWatchEntryRequest(tc.req); got != tc.want {
+			t.Errorf("%v: validateWatchEntryRequest(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
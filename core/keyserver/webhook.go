@@ -0,0 +1,130 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateWebhook occurs when a key change webhook resolves to an address
+// that is not publicly routable. Webhooks are posted to by the key server, so
+// such addresses would expose the server's own network to its users.
+var ErrPrivateWebhook = errors.New("webhook must resolve to a public address")
+
+// privateNets are the IPv4 and IPv6 ranges that are not globally routable and
+// are not covered by the net.IP predicates used in publicIP.
+var privateNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",      // This network.
+		"10.0.0.0/8",     // Private.
+		"100.64.0.0/10",  // Carrier grade NAT.
+		"172.16.0.0/12",  // Private.
+		"192.0.0.0/24",   // IETF protocol assignments.
+		"192.168.0.0/16", // Private.
+		"198.18.0.0/15",  // Benchmarking.
+		"fc00::/7",       // Unique local.
+	} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// publicIP returns true if ip is a globally routable unicast address.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolver looks up the addresses of a host. *net.Resolver implements it.
+type resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// checkWebhookHost returns ErrPrivateWebhook if any address of the host of
+// rawURL is not public. It rejects webhooks early; NewWebhookClient enforces
+// the same rule when events are posted, after the host is resolved again.
+func checkWebhookHost(ctx context.Context, r resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidWebhook
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return ErrPrivateWebhook
+		}
+		return nil
+	}
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %v: %w", host, err)
+	}
+	for _, a := range addrs {
+		if !publicIP(a.IP) {
+			return ErrPrivateWebhook
+		}
+	}
+	return nil
+}
+
+// NewWebhookClient returns an HTTP client for RunKeyChangeWebhooks that only
+// connects to public addresses. The check is made on the address being
+// dialed, so it also covers redirects and hosts whose DNS records change
+// after the webhook was registered. Proxies are not used.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("dial %v: %w", address, ErrPrivateWebhook)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration signs and checks domain migration announcements.
+//
+// A migration announces that a domain is served by another key server, with
+// other keys, from a map revision on. It is signed with the map key of the
+// domain before the migration, which clients have already pinned, so that
+// clients can move their trust to the new keys without another trust on
+// first use ceremony.
+//
+// The signature covers the serialized migration prefixed with a signing
+// context, so that it cannot be confused with a map root signed by the same
+// key. Sequencers record the hash of the announcement in each map root, which
+// is logged, so that all clients see the same announcement.
+package migration
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/keys/der"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// ErrSignature occurs when a migration is not signed by the map key of the
+// domain it migrates.
+var ErrSignature = errors.New("migration signature is not trusted")
+
+// signingContext is prepended to migrations before they are signed.
+const signingContext = "keytransparency domain migration v1\x00"
+
+// signedBytes returns the bytes that the signature of migration covers.
+func signedBytes(migration []byte) []byte {
+	return append([]byte(signingContext), migration...)
+}
+
+// Hash returns the hash of signed that sequencers record in map roots.
+func Hash(signed *pb.SignedDomainMigration) ([]byte, error) {
+	b, err := proto.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(b)
+	return h[:], nil
+}
+
+// Sign signs m with signer, which must hold the private map key of the
+// domain before the migration.
+func Sign(signer *tcrypto.Signer, m *pb.DomainMigration) (*pb.SignedDomainMigration, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(signedBytes(b))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedDomainMigration{Migration: b, Signature: sig}, nil
+}
+
+// Verify checks that signed is a well formed migration of old signed by the
+// map key of old, and returns the migration it contains.
+func Verify(signed *pb.SignedDomainMigration, old *pb.Domain) (*pb.DomainMigration, error) {
+	key, err := der.UnmarshalPublicKey(old.GetMap().GetPublicKey().GetDer())
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing Map public key: %w", err)
+	}
+	if err := tcrypto.Verify(key, signedBytes(signed.GetMigration()), signed.GetSignature()); err != nil {
+		return nil, ErrSignature
+	}
+	var m pb.DomainMigration
+	if err := proto.Unmarshal(signed.GetMigration(), &m); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal(DomainMigration): %w", err)
+	}
+	switch {
+	case m.GetDomainId() != old.GetDomainId():
+		return nil, fmt.Errorf("migration is for domain %v, want %v", m.GetDomainId(), old.GetDomainId())
+	case m.GetNewDomain().GetDomainId() != old.GetDomainId():
+		return nil, fmt.Errorf("migration moves domain %v to domain %v", old.GetDomainId(), m.GetNewDomain().GetDomainId())
+	case m.GetRevision() <= 0:
+		return nil, fmt.Errorf("migration revision %v, want > 0", m.GetRevision())
+	}
+	return &m, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// newDomain returns a domain whose map is signed by a new key, and a signer
+// holding the key.
+func newDomain(t *testing.T, domainID string) (*pb.Domain, *tcrypto.Signer) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(): %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	d := &pb.Domain{
+		DomainId: domainID,
+		Map:      &trillian.Tree{TreeId: 2, PublicKey: &keyspb.PublicKey{Der: pubDER}},
+	}
+	return d, tcrypto.NewSHA256Signer(key)
+}
+
+func TestSignVerify(t *testing.T) {
+	old, signer := newDomain(t, "domain")
+	next, nextSigner := newDomain(t, "domain")
+	other, _ := newDomain(t, "other")
+
+	for _, tc := range []struct {
+		desc    string
+		m       *pb.DomainMigration
+		signer  *tcrypto.Signer
+		wantErr bool
+	}{
+		{desc: "valid", m: &pb.DomainMigration{DomainId: "domain", Revision: 10, NewAddress: "new:443", NewDomain: next}, signer: signer},
+		{desc: "signed by new keys", m: &pb.DomainMigration{DomainId: "domain", Revision: 10, NewDomain: next}, signer: nextSigner, wantErr: true},
+		{desc: "other domain", m: &pb.DomainMigration{DomainId: "other", Revision: 10, NewDomain: next}, signer: signer, wantErr: true},
+		{desc: "renamed", m: &pb.DomainMigration{DomainId: "domain", Revision: 10, NewDomain: other}, signer: signer, wantErr: true},
+		{desc: "no revision", m: &pb.DomainMigration{DomainId: "domain", NewDomain: next}, signer: signer, wantErr: true},
+	} {
+		signed, err := Sign(tc.signer, tc.m)
+		if err != nil {
+			t.Fatalf("%v: Sign(): %v", tc.desc, err)
+		}
+		got, err := Verify(signed, old)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%v: Verify(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !proto.Equal(got, tc.m) {
+			t.Errorf("%v: Verify(): %v, want %v", tc.desc, got, tc.m)
+		}
+	}
+
+	signed, err := Sign(signer, &pb.DomainMigration{DomainId: "domain", Revision: 10, NewDomain: next})
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	signed.Migration = append(signed.Migration, 0)
+	if _, err := Verify(signed, old); err != ErrSignature {
+		t.Errorf("Verify(tampered): %v, want %v", err, ErrSignature)
+	}
+}
+
+func TestVerifyContext(t *testing.T) {
+	old, signer := newDomain(t, "domain")
+	next, _ := newDomain(t, "domain")
+	b, err := proto.Marshal(&pb.DomainMigration{DomainId: "domain", Revision: 10, NewDomain: next})
+	if err != nil {
+		t.Fatalf("proto.Marshal(): %v", err)
+	}
+	// A signature over the bare migration, such as one made by a signer of
+	// other messages, is not a migration signature.
+	sig, err := signer.Sign(b)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	signed := &pb.SignedDomainMigration{Migration: b, Signature: sig}
+	if _, err := Verify(signed, old); err != ErrSignature {
+		t.Errorf("Verify(no context): %v, want %v", err, ErrSignature)
+	}
+}
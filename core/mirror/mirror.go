@@ -0,0 +1,277 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror serves Trillian reads from a read-only mirror of the
+// primary Trillian instance.
+//
+// A mirror is only used for a log once its latest root has been verified to
+// be consistent with, and not too far behind, the latest root published by
+// the primary. The check is repeated in the background by LogClient.Run, and
+// the verified mirror root is served between checks. Reads the mirror cannot serve, and all writes, go to the
+// primary. Map reads are not checked against the primary; clients verify map
+// roots and leaves against the verified log root.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/trillian/merkle"
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/grpc"
+
+	tpb "github.com/google/trillian"
+)
+
+var (
+	// ErrStale occurs when the mirror is too far behind the primary.
+	ErrStale = errors.New("mirror: too far behind primary")
+	// ErrAhead occurs when the mirror has a larger tree than the primary.
+	ErrAhead = errors.New("mirror: ahead of primary")
+	// ErrDiverged occurs when the mirror's root is not consistent with the
+	// primary's root.
+	ErrDiverged = errors.New("mirror: inconsistent with primary")
+)
+
+// LogClient is a tpb.TrillianLogClient that serves reads from a mirror.
+type LogClient struct {
+	// TrillianLogClient is the primary. Methods that are not overridden
+	// below, including all writes, go to the primary.
+	tpb.TrillianLogClient
+	mirror tpb.TrillianLogClient
+	// maxLag is the largest number of leaves the mirror may be behind.
+	maxLag   int64
+	verifier merkle.LogVerifier
+
+	mu sync.Mutex
+	// verified holds the mirror root verified by the last check of each
+	// log that has been read, or nil if the check failed.
+	verified map[int64]*tpb.SignedLogRoot
+}
+
+// NewLogClient returns a log client that serves reads from mirror when its
+// latest root is consistent with primary and at most maxLag leaves behind.
+func NewLogClient(primary, mirror tpb.TrillianLogClient, maxLag int64) *LogClient {
+	return &LogClient{
+		TrillianLogClient: primary,
+		mirror:            mirror,
+		maxLag:            maxLag,
+		// Consistency proofs only contain interior nodes, which are hashed
+		// in the same way by all RFC 6962 based strategies.
+		verifier: merkle.NewLogVerifier(rfc6962.DefaultHasher),
+		verified: make(map[int64]*tpb.SignedLogRoot),
+	}
+}
+
+// GetLatestSignedLogRoot returns the mirror root that was verified by the
+// last check of the log, or the primary's latest root if that check failed.
+// The first read of a log checks it; later checks are made by Refresh.
+func (c *LogClient) GetLatestSignedLogRoot(ctx context.Context, in *tpb.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*tpb.GetLatestSignedLogRootResponse, error) {
+	root, watched := c.watchedRoot(in.GetLogId())
+	switch {
+	case !watched:
+		return c.check(ctx, in, opts...)
+	case root == nil:
+		return c.TrillianLogClient.GetLatestSignedLogRoot(ctx, in, opts...)
+	}
+	return &tpb.GetLatestSignedLogRootResponse{SignedLogRoot: root}, nil
+}
+
+// check verifies the mirror's latest root against the primary's latest root
+// and returns the mirror's root if it is fresh and consistent, and the
+// primary's root otherwise. The verified root is saved for reads until the
+// next check. The saved root is kept if the primary cannot be read.
+func (c *LogClient) check(ctx context.Context, in *tpb.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*tpb.GetLatestSignedLogRootResponse, error) {
+	primary, err := c.TrillianLogClient.GetLatestSignedLogRoot(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	mirror, err := c.mirror.GetLatestSignedLogRoot(ctx, in, opts...)
+	if err != nil {
+		glog.Warningf("mirror: GetLatestSignedLogRoot(%v): %v", in.GetLogId(), err)
+		c.setVerified(in.GetLogId(), nil)
+		return primary, nil
+	}
+	if err := c.verify(ctx, in.GetLogId(), mirror.GetSignedLogRoot(), primary.GetSignedLogRoot()); err != nil {
+		glog.Warningf("mirror: log %v: %v", in.GetLogId(), err)
+		c.setVerified(in.GetLogId(), nil)
+		return primary, nil
+	}
+	c.setVerified(in.GetLogId(), mirror.GetSignedLogRoot())
+	return mirror, nil
+}
+
+// Refresh checks the mirror's latest root of every log that has been read.
+func (c *LogClient) Refresh(ctx context.Context) {
+	c.mu.Lock()
+	logIDs := make([]int64, 0, len(c.verified))
+	for logID := range c.verified {
+		logIDs = append(logIDs, logID)
+	}
+	c.mu.Unlock()
+	for _, logID := range logIDs {
+		if _, err := c.check(ctx, &tpb.GetLatestSignedLogRootRequest{LogId: logID}); err != nil {
+			glog.Warningf("mirror: log %v: primary GetLatestSignedLogRoot(): %v", logID, err)
+		}
+	}
+}
+
+// Run calls Refresh every interval until ctx is done.
+func (c *LogClient) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		c.Refresh(ctx)
+	}
+}
+
+// verify checks that mirror is at most maxLag leaves behind primary and
+// consistent with it.
+func (c *LogClient) verify(ctx context.Context, logID int64, mirror, primary *tpb.SignedLogRoot) error {
+	switch lag := primary.GetTreeSize() - mirror.GetTreeSize(); {
+	case mirror.GetTreeSize() <= 0:
+		return ErrStale
+	case lag < 0:
+		return ErrAhead
+	case lag > c.maxLag:
+		return ErrStale
+	case lag == 0:
+		if !bytes.Equal(mirror.GetRootHash(), primary.GetRootHash()) {
+			return ErrDiverged
+		}
+		return nil
+	}
+	if prev := c.verifiedRoot(logID); prev != nil &&
+		prev.GetTreeSize() == mirror.GetTreeSize() &&
+		bytes.Equal(prev.GetRootHash(), mirror.GetRootHash()) {
+		return nil
+	}
+	resp, err := c.TrillianLogClient.GetConsistencyProof(ctx, &tpb.GetConsistencyProofRequest{
+		LogId:          logID,
+		FirstTreeSize:  mirror.GetTreeSize(),
+		SecondTreeSize: primary.GetTreeSize(),
+	})
+	if err != nil {
+		return fmt.Errorf("primary GetConsistencyProof(): %w", err)
+	}
+	if err := c.verifier.VerifyConsistencyProof(
+		mirror.GetTreeSize(), primary.GetTreeSize(),
+		mirror.GetRootHash(), primary.GetRootHash(),
+		resp.GetProof().GetHashes()); err != nil {
+		return ErrDiverged
+	}
+	return nil
+}
+
+func (c *LogClient) verifiedRoot(logID int64) *tpb.SignedLogRoot {
+	root, _ := c.watchedRoot(logID)
+	return root
+}
+
+// watchedRoot returns the verified mirror root of logID, and whether logID
+// has been checked.
+func (c *LogClient) watchedRoot(logID int64) (*tpb.SignedLogRoot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	root, ok := c.verified[logID]
+	return root, ok
+}
+
+// setVerified saves the verified mirror root of logID. A nil root sends
+// reads of logID to the primary until the next check.
+func (c *LogClient) setVerified(logID int64, root *tpb.SignedLogRoot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified[logID] = root
+}
+
+// servable returns true if the mirror can serve proofs for trees of treeSize.
+func (c *LogClient) servable(logID, treeSize int64) bool {
+	root := c.verifiedRoot(logID)
+	return root != nil && treeSize <= root.GetTreeSize()
+}
+
+// GetInclusionProof reads from the mirror if it has been verified to contain
+// the requested tree size.
+func (c *LogClient) GetInclusionProof(ctx context.Context, in *tpb.GetInclusionProofRequest, opts ...grpc.CallOption) (*tpb.GetInclusionProofResponse, error) {
+	if c.servable(in.GetLogId(), in.GetTreeSize()) {
+		resp, err := c.mirror.GetInclusionProof(ctx, in, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		glog.Warningf("mirror: GetInclusionProof(%v): %v", in.GetLogId(), err)
+	}
+	return c.TrillianLogClient.GetInclusionProof(ctx, in, opts...)
+}
+
+// GetConsistencyProof reads from the mirror if it has been verified to
+// contain the requested tree sizes.
+func (c *LogClient) GetConsistencyProof(ctx context.Context, in *tpb.GetConsistencyProofRequest, opts ...grpc.CallOption) (*tpb.GetConsistencyProofResponse, error) {
+	if c.servable(in.GetLogId(), in.GetSecondTreeSize()) {
+		resp, err := c.mirror.GetConsistencyProof(ctx, in, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		glog.Warningf("mirror: GetConsistencyProof(%v): %v", in.GetLogId(), err)
+	}
+	return c.TrillianLogClient.GetConsistencyProof(ctx, in, opts...)
+}
+
+// MapClient is a tpb.TrillianMapClient that serves reads from a mirror.
+type MapClient struct {
+	// TrillianMapClient is the primary. Methods that are not overridden
+	// below, including all writes, go to the primary.
+	tpb.TrillianMapClient
+	mirror tpb.TrillianMapClient
+}
+
+// NewMapClient returns a map client that reads map revisions from mirror,
+// falling back to primary for revisions the mirror cannot serve.
+func NewMapClient(primary, mirror tpb.TrillianMapClient) *MapClient {
+	return &MapClient{
+		TrillianMapClient: primary,
+		mirror:            mirror,
+	}
+}
+
+// GetLeavesByRevision reads from the mirror, falling back to the primary.
+func (c *MapClient) GetLeavesByRevision(ctx context.Context, in *tpb.GetMapLeavesByRevisionRequest, opts ...grpc.CallOption) (*tpb.GetMapLeavesResponse, error) {
+	resp, err := c.mirror.GetLeavesByRevision(ctx, in, opts...)
+	if err == nil {
+		return resp, nil
+	}
+	glog.V(2).Infof("mirror: GetLeavesByRevision(%v, %v): %v", in.GetMapId(), in.GetRevision(), err)
+	return c.TrillianMapClient.GetLeavesByRevision(ctx, in, opts...)
+}
+
+// GetSignedMapRootByRevision reads from the mirror, falling back to the
+// primary.
+func (c *MapClient) GetSignedMapRootByRevision(ctx context.Context, in *tpb.GetSignedMapRootByRevisionRequest, opts ...grpc.CallOption) (*tpb.GetSignedMapRootResponse, error) {
+	resp, err := c.mirror.GetSignedMapRootByRevision(ctx, in, opts...)
+	if err == nil && resp.GetMapRoot() != nil {
+		return resp, nil
+	}
+	glog.V(2).Infof("mirror: GetSignedMapRootByRevision(%v, %v): %v", in.GetMapId(), in.GetRevision(), err)
+	return c.TrillianMapClient.GetSignedMapRootByRevision(ctx, in, opts...)
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/grpc"
+
+	tpb "github.com/google/trillian"
+)
+
+// fakeLog serves a fixed root and consistency proof.
+type fakeLog struct {
+	tpb.TrillianLogClient
+	root        *tpb.SignedLogRoot
+	err         error
+	consistency [][]byte
+	inclusions  int
+	roots       int
+}
+
+func (f *fakeLog) GetLatestSignedLogRoot(context.Context, *tpb.GetLatestSignedLogRootRequest, ...grpc.CallOption) (*tpb.GetLatestSignedLogRootResponse, error) {
+	f.roots++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &tpb.GetLatestSignedLogRootResponse{SignedLogRoot: f.root}, nil
+}
+
+func (f *fakeLog) GetConsistencyProof(context.Context, *tpb.GetConsistencyProofRequest, ...grpc.CallOption) (*tpb.GetConsistencyProofResponse, error) {
+	return &tpb.GetConsistencyProofResponse{Proof: &tpb.Proof{Hashes: f.consistency}}, nil
+}
+
+func (f *fakeLog) GetInclusionProof(context.Context, *tpb.GetInclusionProofRequest, ...grpc.CallOption) (*tpb.GetInclusionProofResponse, error) {
+	f.inclusions++
+	return &tpb.GetInclusionProofResponse{}, nil
+}
+
+func TestGetLatestSignedLogRoot(t *testing.T) {
+	ctx := context.Background()
+	h1 := bytes.Repeat([]byte{1}, 32)
+	h2 := bytes.Repeat([]byte{2}, 32)
+	root1 := &tpb.SignedLogRoot{TreeSize: 1, RootHash: h1}
+	root2 := &tpb.SignedLogRoot{TreeSize: 2, RootHash: rfc6962.DefaultHasher.HashChildren(h1, h2)}
+	forked := &tpb.SignedLogRoot{TreeSize: 2, RootHash: h2}
+
+	for _, tc := range []struct {
+		desc        string
+		primary     *tpb.SignedLogRoot
+		mirror      *tpb.SignedLogRoot
+		mirrorErr   error
+		consistency [][]byte
+		maxLag      int64
+		wantMirror  bool
+	}{
+		{desc: "up to date", primary: root2, mirror: root2, wantMirror: true},
+		{desc: "consistent lag", primary: root2, mirror: root1, consistency: [][]byte{h2}, maxLag: 1, wantMirror: true},
+		{desc: "too stale", primary: root2, mirror: root1, consistency: [][]byte{h2}, maxLag: 0},
+		{desc: "inconsistent", primary: root2, mirror: root1, consistency: [][]byte{h1}, maxLag: 1},
+		{desc: "diverged", primary: root2, mirror: forked, maxLag: 1},
+		{desc: "ahead", primary: root1, mirror: root2, maxLag: 1},
+		{desc: "empty", primary: root1, mirror: &tpb.SignedLogRoot{}, maxLag: 1},
+		{desc: "unavailable", primary: root2, mirrorErr: errors.New("unavailable"), maxLag: 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			primary := &fakeLog{root: tc.primary, consistency: tc.consistency}
+			mirror := &fakeLog{root: tc.mirror, err: tc.mirrorErr}
+			c := NewLogClient(primary, mirror, tc.maxLag)
+
+			resp, err := c.GetLatestSignedLogRoot(ctx, &tpb.GetLatestSignedLogRootRequest{LogId: 1})
+			if err != nil {
+				t.Fatalf("GetLatestSignedLogRoot(): %v", err)
+			}
+			want := tc.primary
+			if tc.wantMirror {
+				want = tc.mirror
+			}
+			if got := resp.GetSignedLogRoot(); got != want {
+				t.Errorf("GetLatestSignedLogRoot(): %v, want %v", got, want)
+			}
+
+			if _, err := c.GetInclusionProof(ctx, &tpb.GetInclusionProofRequest{
+				LogId:    1,
+				TreeSize: resp.GetSignedLogRoot().GetTreeSize(),
+			}); err != nil {
+				t.Fatalf("GetInclusionProof(): %v", err)
+			}
+			if got, want := mirror.inclusions == 1, tc.wantMirror; got != want {
+				t.Errorf("GetInclusionProof() served by mirror: %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	h1 := bytes.Repeat([]byte{1}, 32)
+	h2 := bytes.Repeat([]byte{2}, 32)
+	root1 := &tpb.SignedLogRoot{TreeSize: 1, RootHash: h1}
+	root2 := &tpb.SignedLogRoot{TreeSize: 2, RootHash: rfc6962.DefaultHasher.HashChildren(h1, h2)}
+	forked := &tpb.SignedLogRoot{TreeSize: 2, RootHash: h2}
+
+	primary := &fakeLog{root: root1}
+	mirror := &fakeLog{root: root1}
+	c := NewLogClient(primary, mirror, 1)
+	req := &tpb.GetLatestSignedLogRootRequest{LogId: 1}
+	for _, step := range []struct {
+		desc    string
+		primary *tpb.SignedLogRoot
+		mirror  *tpb.SignedLogRoot
+		refresh bool
+		want    *tpb.SignedLogRoot
+	}{
+		{desc: "first read", primary: root1, mirror: root1, want: root1},
+		{desc: "cached", primary: root2, mirror: root2, want: root1},
+		{desc: "refreshed", primary: root2, mirror: root2, refresh: true, want: root2},
+		{desc: "diverged", primary: root2, mirror: forked, refresh: true, want: root2},
+		{desc: "still diverged", primary: root1, mirror: forked, want: root1},
+	} {
+		primary.root, mirror.root = step.primary, step.mirror
+		if step.refresh {
+			c.Refresh(ctx)
+		}
+		resp, err := c.GetLatestSignedLogRoot(ctx, req)
+		if err != nil {
+			t.Fatalf("%v: GetLatestSignedLogRoot(): %v", step.desc, err)
+		}
+		if got := resp.GetSignedLogRoot(); got != step.want {
+			t.Errorf("%v: GetLatestSignedLogRoot(): %v, want %v", step.desc, got, step.want)
+		}
+	}
+	// The first read and the two refreshes check the primary, and the two
+	// reads after the mirror diverged fall back to it.
+	if got, want := primary.roots, 5; got != want {
+		t.Errorf("primary GetLatestSignedLogRoot() calls: %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alert notifies operators of problems the monitor finds. Alerts are
+// sent to pluggable sinks, such as a webhook, email or PagerDuty, after
+// repeats of the same alert are suppressed.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Severity is how urgently an alert needs attention.
+type Severity int
+
+// Severities, from least to most urgent.
+const (
+	Info Severity = iota
+	Warning
+	Critical
+)
+
+// String returns the lower case name of s.
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// ParseSeverity returns the Severity named s, as returned by String.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{Info, Warning, Critical} {
+		if s == sev.String() {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
+// Alert is a problem found by the monitor.
+type Alert struct {
+	Severity Severity
+	// Key identifies the problem. Alerts with the same key are duplicates.
+	Key string
+	// Summary describes the problem in one line.
+	Summary string
+	// Details holds further information, such as the checks that failed.
+	Details string
+	// Time is when the problem was found.
+	Time time.Time
+}
+
+// Sink delivers alerts.
+type Sink interface {
+	Send(ctx context.Context, a *Alert) error
+}
+
+// Route sends the alerts of at least MinSeverity to Sink.
+type Route struct {
+	Sink        Sink
+	MinSeverity Severity
+}
+
+// DefaultSinkTimeout bounds the time a sink may take to deliver an alert.
+const DefaultSinkTimeout = 10 * time.Second
+
+// DefaultQueueSize is the number of alerts a Dispatcher queues for delivery.
+const DefaultQueueSize = 100
+
+// Dispatcher sends alerts to the sinks of its routes. An alert is dropped if
+// an alert with the same key was delivered within the deduplication window.
+type Dispatcher struct {
+	// SinkTimeout bounds each delivery of an alert to a sink.
+	SinkTimeout time.Duration
+
+	routes []Route
+	window time.Duration
+	now    func() time.Time
+	queue  chan *Alert
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewDispatcher returns a Dispatcher that sends alerts along routes and
+// suppresses duplicates for window. A zero window disables deduplication.
+func NewDispatcher(window time.Duration, routes ...Route) *Dispatcher {
+	return &Dispatcher{
+		SinkTimeout: DefaultSinkTimeout,
+		routes:      routes,
+		window:      window,
+		now:         time.Now,
+		queue:       make(chan *Alert, DefaultQueueSize),
+		sent:        make(map[string]time.Time),
+	}
+}
+
+// duplicate returns true if an alert with key was delivered within the
+// deduplication window.
+func (d *Dispatcher) duplicate(key string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.sent[key]
+	return ok && now.Sub(last) < d.window
+}
+
+// delivered records that an alert with key was delivered at now.
+func (d *Dispatcher) delivered(key string, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sent[key] = now
+	// Forget alerts that can no longer suppress anything.
+	for k, t := range d.sent {
+		if now.Sub(t) >= d.window {
+			delete(d.sent, k)
+		}
+	}
+}
+
+// Alert sends a to every route that accepts its severity, unless it is a
+// duplicate. All routes are tried; the first delivery error is returned. An
+// alert only suppresses its duplicates once every route delivered it, so an
+// alert that failed is sent again when it recurs.
+func (d *Dispatcher) Alert(ctx context.Context, a *Alert) error {
+	if a.Time.IsZero() {
+		a.Time = d.now()
+	}
+	if d.duplicate(a.Key, d.now()) {
+		glog.V(2).Infof("Suppressed duplicate alert %q", a.Key)
+		return nil
+	}
+	var firstErr error
+	for _, r := range d.routes {
+		if a.Severity < r.MinSeverity {
+			continue
+		}
+		if err := d.send(ctx, r.Sink, a); err != nil {
+			glog.Errorf("Failed to send alert %q: %v", a.Key, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("alert %q: %w", a.Key, err)
+			}
+		}
+	}
+	if firstErr == nil {
+		d.delivered(a.Key, d.now())
+	}
+	return firstErr
+}
+
+// send delivers a to sink within SinkTimeout.
+func (d *Dispatcher) send(ctx context.Context, sink Sink, a *Alert) error {
+	if d.SinkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.SinkTimeout)
+		defer cancel()
+	}
+	return sink.Send(ctx, a)
+}
+
+// Enqueue queues a for delivery by Run without waiting for it to be sent, so
+// that slow sinks do not hold up the caller. If the queue is full, a is
+// dropped and Enqueue returns false.
+func (d *Dispatcher) Enqueue(a *Alert) bool {
+	if a.Time.IsZero() {
+		a.Time = d.now()
+	}
+	select {
+	case d.queue <- a:
+		return true
+	default:
+		glog.Errorf("Alert queue full, dropped alert %q", a.Key)
+		return false
+	}
+}
+
+// Run delivers the alerts passed to Enqueue, one at a time, until ctx is
+// done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case a := <-d.queue:
+			d.Alert(ctx, a) // nolint: errcheck
+		}
+	}
+}
+
+// Drain delivers the alerts queued so far and returns, for instance before
+// the process exits.
+func (d *Dispatcher) Drain(ctx context.Context) {
+	for {
+		select {
+		case a := <-d.queue:
+			d.Alert(ctx, a) // nolint: errcheck
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordingSink records the keys of the alerts it is sent.
+type recordingSink struct {
+	keys []string
+	err  error
+}
+
+func (r *recordingSink) Send(ctx context.Context, a *Alert) error {
+	r.keys = append(r.keys, a.Key)
+	return r.err
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	all := &recordingSink{}
+	critical := &recordingSink{}
+	d := NewDispatcher(time.Minute,
+		Route{Sink: all, MinSeverity: Info},
+		Route{Sink: critical, MinSeverity: Critical})
+	now := time.Unix(1000, 0)
+	d.now = func() time.Time { return now }
+
+	for _, tc := range []struct {
+		a       *Alert
+		advance time.Duration
+	}{
+		{a: &Alert{Severity: Warning, Key: "a"}},
+		{a: &Alert{Severity: Warning, Key: "a"}, advance: 30 * time.Second}, // Duplicate.
+		{a: &Alert{Severity: Critical, Key: "b"}},
+		{a: &Alert{Severity: Warning, Key: "a"}, advance: time.Minute}, // Window passed.
+	} {
+		now = now.Add(tc.advance)
+		if err := d.Alert(ctx, tc.a); err != nil {
+			t.Errorf("Alert(%v): %v", tc.a.Key, err)
+		}
+	}
+	if got, want := len(all.keys), 3; got != want {
+		t.Errorf("all sink got %v alerts (%v), want %v", got, all.keys, want)
+	}
+	if got, want := len(critical.keys), 1; got != want {
+		t.Errorf("critical sink got %v alerts (%v), want %v", got, critical.keys, want)
+	}
+}
+
+func TestDispatcherErrors(t *testing.T) {
+	failing := &recordingSink{err: errors.New("unavailable")}
+	working := &recordingSink{}
+	d := NewDispatcher(0, Route{Sink: failing}, Route{Sink: working})
+	if err := d.Alert(context.Background(), &Alert{Key: "a"}); err == nil {
+		t.Errorf("Alert(): nil, want error")
+	}
+	if got := len(working.keys); got != 1 {
+		t.Errorf("working sink got %v alerts, want 1", got)
+	}
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{err: errors.New("unavailable")}
+	d := NewDispatcher(time.Hour, Route{Sink: sink})
+	if err := d.Alert(ctx, &Alert{Key: "a"}); err == nil {
+		t.Errorf("Alert(): nil, want error")
+	}
+	// A failed alert must not suppress its retry.
+	sink.err = nil
+	if err := d.Alert(ctx, &Alert{Key: "a"}); err != nil {
+		t.Errorf("Alert(): %v", err)
+	}
+	if err := d.Alert(ctx, &Alert{Key: "a"}); err != nil {
+		t.Errorf("Alert(): %v", err)
+	}
+	if got, want := len(sink.keys), 2; got != want {
+		t.Errorf("sink got %v alerts, want %v", got, want)
+	}
+}
+
+// blockingSink blocks until ctx is done.
+type blockingSink struct{}
+
+func (blockingSink) Send(ctx context.Context, a *Alert) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcherSinkTimeout(t *testing.T) {
+	d := NewDispatcher(0, Route{Sink: blockingSink{}})
+	d.SinkTimeout = 10 * time.Millisecond
+	if err := d.Alert(context.Background(), &Alert{Key: "a"}); err == nil {
+		t.Errorf("Alert(): nil, want timeout error")
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	sink := &recordingSink{}
+	d := NewDispatcher(0, Route{Sink: sink})
+	for i := 0; i < DefaultQueueSize; i++ {
+		if !d.Enqueue(&Alert{Key: "a"}) {
+			t.Fatalf("Enqueue(%v): false, want true", i)
+		}
+	}
+	if d.Enqueue(&Alert{Key: "a"}) {
+		t.Errorf("Enqueue() on a full queue: true, want false")
+	}
+	d.Drain(context.Background())
+	if got, want := len(sink.keys), DefaultQueueSize; got != want {
+		t.Errorf("sink got %v alerts, want %v", got, want)
+	}
+}
+
+func TestHeaderValue(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{in: "key changed", want: "key changed"},
+		{in: "a\r\nBcc: eve@example.com", want: "a  Bcc: eve@example.com"},
+		{in: "a\nb", want: "a b"},
+	} {
+		if got := headerValue(tc.in); got != tc.want {
+			t.Errorf("headerValue(%q): %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPagerDuty(t *testing.T) {
+	var got pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decode(): %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := &PagerDuty{RoutingKey: "key", Source: "monitor", URL: srv.URL}
+	if err := p.Send(context.Background(), &Alert{Severity: Critical, Key: "a", Summary: "s"}); err != nil {
+		t.Fatalf("Send(): %v", err)
+	}
+	if got.RoutingKey != "key" || got.DedupKey != "a" || got.Payload.Severity != "critical" {
+		t.Errorf("Send() posted %+v, want routing key, dedup key and severity set", got)
+	}
+}
+
+func TestWebhookStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status  int
+		wantErr bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusInternalServerError, wantErr: true},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		w := &Webhook{URL: srv.URL}
+		err := w.Send(context.Background(), &Alert{Key: "a"})
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("Send() with status %v: %v, wantErr %v", tc.status, err, tc.wantErr)
+		}
+		srv.Close()
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{Info, Warning, Critical} {
+		got, err := ParseSeverity(s.String())
+		if err != nil || got != s {
+			t.Errorf("ParseSeverity(%q): %v, %v, want %v", s, got, err, s)
+		}
+	}
+	if _, err := ParseSeverity("urgent"); err == nil {
+		t.Errorf("ParseSeverity(urgent): nil, want error")
+	}
+}
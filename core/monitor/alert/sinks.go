@@ -0,0 +1,226 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// PagerDutyURL is the endpoint of the PagerDuty Events API v2.
+const PagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// postJSON posts v as JSON to url and fails unless the response is a success.
+func postJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %v: %v", url, resp.Status)
+	}
+	return nil
+}
+
+// Webhook posts alerts as JSON to a URL.
+type Webhook struct {
+	URL string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// webhookAlert is the JSON body posted by Webhook.
+type webhookAlert struct {
+	Severity string    `json:"severity"`
+	Key      string    `json:"key"`
+	Summary  string    `json:"summary"`
+	Details  string    `json:"details,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Send posts a to the webhook.
+func (w *Webhook) Send(ctx context.Context, a *Alert) error {
+	return postJSON(ctx, w.Client, w.URL, &webhookAlert{
+		Severity: a.Severity.String(),
+		Key:      a.Key,
+		Summary:  a.Summary,
+		Details:  a.Details,
+		Time:     a.Time,
+	})
+}
+
+// Email mails alerts through an SMTP server.
+type Email struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	// Auth may be nil if the server does not require authentication.
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Send mails a. The connection to the SMTP server is closed if ctx is done
+// before the mail is sent.
+func (e *Email) Send(ctx context.Context, a *Alert) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %v\r\n", headerValue(e.From))
+	fmt.Fprintf(&msg, "To: %v\r\n", headerValue(strings.Join(e.To, ", ")))
+	fmt.Fprintf(&msg, "Subject: [%v] %v\r\n", a.Severity, headerValue(a.Summary))
+	fmt.Fprintf(&msg, "\r\n%v\r\n\r\n%v\r\n", a.Time.Format(time.RFC3339), a.Details)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	if err := e.send(c, host, msg.Bytes()); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// send sends msg through c, like smtp.SendMail.
+func (e *Email) send(c *smtp.Client, host string, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && e.Auth != nil {
+		if err := c.Auth(e.Auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// headerValue removes line breaks from s, so that it cannot end the mail
+// header it is written to and start another.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// PagerDuty triggers PagerDuty incidents for alerts. Alerts with the same key
+// are grouped into one incident.
+type PagerDuty struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+	// Source names the monitor in incidents.
+	Source string
+	// URL defaults to PagerDutyURL.
+	URL string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// pagerDutyEvent is an event of the PagerDuty Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	Timestamp     string `json:"timestamp"`
+	CustomDetails string `json:"custom_details,omitempty"`
+}
+
+// Send triggers an incident for a.
+func (p *PagerDuty) Send(ctx context.Context, a *Alert) error {
+	url := p.URL
+	if url == "" {
+		url = PagerDutyURL
+	}
+	return postJSON(ctx, p.Client, url, &pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    a.Key,
+		Payload: pagerDutyPayload{
+			Summary:       a.Summary,
+			Source:        p.Source,
+			Severity:      a.Severity.String(),
+			Timestamp:     a.Time.Format(time.RFC3339),
+			CustomDetails: a.Details,
+		},
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/monitor/alert"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// sendAlert queues a for delivery if alerting is enabled. Alerts are
+// delivered in the background, and delivery failures are logged by the
+// dispatcher, so that alerting never holds up monitoring.
+func (m *Monitor) sendAlert(a *alert.Alert) {
+	if m.alerts == nil {
+		return
+	}
+	m.alerts.Enqueue(a)
+}
+
+// alertErrors raises a critical alert for a revision that did not verify.
+func (m *Monitor) alertErrors(revision int64, errs []error) {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	m.sendAlert(&alert.Alert{
+		Severity: alert.Critical,
+		Key:      fmt.Sprintf("verification/%v", revision),
+		Summary:  fmt.Sprintf("map revision %v failed %v verification checks", revision, len(errs)),
+		Details:  strings.Join(msgs, "\n"),
+	})
+}
+
+// alertKeyChanges raises an alert for each mutation that changes the
+// authorized keys of a watched entry.
+func (m *Monitor) alertKeyChanges(revision int64, mutations []*pb.MutationProof) {
+	if len(m.watched) == 0 {
+		return
+	}
+	for _, mut := range mutations {
+		name, ok := m.watched[string(mut.GetLeafProof().GetLeaf().GetIndex())]
+		if !ok {
+			continue
+		}
+		// An undecodable old leaf is reported by verifyMutations; treat
+		// it as empty here.
+		oldLeaf, _ := entry.FromLeafValue(previousLeaf(mut).GetLeafValue())
+		if sameKeys(oldLeaf.GetAuthorizedKeys(), mut.GetMutation().GetAuthorizedKeys()) {
+			continue
+		}
+		m.sendAlert(&alert.Alert{
+			Severity: alert.Warning,
+			Key:      fmt.Sprintf("key_change/%v/%v", name, revision),
+			Summary:  fmt.Sprintf("keys of %v changed in map revision %v", name, revision),
+			Details: fmt.Sprintf("%v authorized keys before, %v after",
+				len(oldLeaf.GetAuthorizedKeys()), len(mut.GetMutation().GetAuthorizedKeys())),
+		})
+	}
+}
+
+// alertVersionChange raises an informational alert when the sequencer that
+// creates the map roots is upgraded or replaced.
+func (m *Monitor) alertVersionChange(revision int64, from, to *pb.ServerVersion) {
+	m.sendAlert(&alert.Alert{
+		Severity: alert.Info,
+		Key:      fmt.Sprintf("sequencer_version/%v", revision),
+		Summary: fmt.Sprintf("sequencer version changed from %v to %v in map revision %v",
+			from.GetVersion(), to.GetVersion(), revision),
+		Details: fmt.Sprintf("binary digest %x before, %x after", from.GetBinaryDigest(), to.GetBinaryDigest()),
+	})
+}
+
+// sameKeys returns true if a and b hold the same keys in the same order.
+func sameKeys(a, b []*keyspb.PublicKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchRootInterval raises an alert, every period until ctx is done, if the
+// latest map root processed is older than the map's max root duration. It
+// catches a key server that stops publishing, which the checks between
+// consecutive epochs cannot see.
+func (m *Monitor) watchRootInterval(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.checkRootInterval(now)
+		}
+	}
+}
+
+// checkRootInterval raises an alert if the latest map root processed is
+// older than the map's max root duration at now.
+func (m *Monitor) checkRootInterval(now time.Time) {
+	last := atomic.LoadInt64(&m.lastRootNanos)
+	if last == 0 {
+		return // No map root processed yet.
+	}
+	age := now.Sub(time.Unix(0, last))
+	if age <= m.maxRootDuration {
+		return
+	}
+	glog.Warningf("Latest map root is %v old, exceeding %v", age, m.maxRootDuration)
+	m.sendAlert(&alert.Alert{
+		Severity: alert.Warning,
+		Key:      "missed_root_interval",
+		Summary:  fmt.Sprintf("no map root within %v; latest is %v old", m.maxRootDuration, age),
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/monitor/alert"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// alertRecorder records the keys of the alerts it is sent.
+type alertRecorder []string
+
+func (r *alertRecorder) Send(ctx context.Context, a *alert.Alert) error {
+	*r = append(*r, a.Key)
+	return nil
+}
+
+func TestAlertKeyChanges(t *testing.T) {
+	key1 := &keyspb.PublicKey{Der: []byte("key1")}
+	key2 := &keyspb.PublicKey{Der: []byte("key2")}
+	oldLeaf, err := entry.ToLeafValue(&pb.Entry{AuthorizedKeys: []*keyspb.PublicKey{key1}})
+	if err != nil {
+		t.Fatalf("ToLeafValue(): %v", err)
+	}
+	mutation := func(index string, keys ...*keyspb.PublicKey) *pb.MutationProof {
+		return &pb.MutationProof{
+			Mutation: &pb.Entry{Index: []byte(index), AuthorizedKeys: keys},
+			LeafProof: &trillian.MapLeafInclusion{
+				Leaf: &trillian.MapLeaf{Index: []byte(index), LeafValue: oldLeaf},
+			},
+		}
+	}
+	for _, tc := range []struct {
+		desc string
+		mut  *pb.MutationProof
+		want int
+	}{
+		{desc: "changed", mut: mutation("alice", key2), want: 1},
+		{desc: "added", mut: mutation("alice", key1, key2), want: 1},
+		{desc: "unchanged", mut: mutation("alice", key1)},
+		{desc: "not watched", mut: mutation("bob", key2)},
+	} {
+		var rec alertRecorder
+		m := &Monitor{
+			alerts:  alert.NewDispatcher(0, alert.Route{Sink: &rec}),
+			watched: map[string]string{"alice": "alice@example.com"},
+		}
+		m.alertKeyChanges(1, []*pb.MutationProof{tc.mut})
+		m.alerts.Drain(context.Background())
+		if got := len(rec); got != tc.want {
+			t.Errorf("%v: alertKeyChanges() sent %v alerts, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestCheckRootInterval(t *testing.T) {
+	last := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		desc     string
+		lastRoot int64
+		now      time.Time
+		want     int
+	}{
+		{desc: "no root", now: last.Add(time.Hour)},
+		{desc: "fresh", lastRoot: last.UnixNano(), now: last.Add(time.Minute)},
+		{desc: "stale", lastRoot: last.UnixNano(), now: last.Add(time.Hour), want: 1},
+	} {
+		var rec alertRecorder
+		m := &Monitor{
+			alerts:          alert.NewDispatcher(0, alert.Route{Sink: &rec}),
+			maxRootDuration: 10 * time.Minute,
+			lastRootNanos:   tc.lastRoot,
+		}
+		m.checkRootInterval(tc.now)
+		m.alerts.Drain(context.Background())
+		if got := len(rec); got != tc.want {
+			t.Errorf("%v: checkRootInterval() sent %v alerts, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestRecordVersion(t *testing.T) {
+	smr := func(revision int64, version string) *trillian.SignedMapRoot {
+		meta, err := ptypes.MarshalAny(&pb.MapperMetadata{
+			SequencerVersion: &pb.ServerVersion{Version: version},
+		})
+		if err != nil {
+			t.Fatalf("MarshalAny(): %v", err)
+		}
+		return &trillian.SignedMapRoot{MapRevision: revision, Metadata: meta}
+	}
+	var rec alertRecorder
+	m := &Monitor{alerts: alert.NewDispatcher(0, alert.Route{Sink: &rec})}
+	for _, tc := range []struct {
+		smr  *trillian.SignedMapRoot
+		want []string
+	}{
+		{smr: smr(1, "v1")}, // The first version seen is not a change.
+		{smr: smr(2, "v1")},
+		{smr: smr(3, "v2"), want: []string{"sequencer_version/3"}},
+		{smr: smr(4, "v2")},
+	} {
+		rec = nil
+		m.recordVersion(tc.smr)
+		m.alerts.Drain(context.Background())
+		if !reflect.DeepEqual([]string(rec), tc.want) {
+			t.Errorf("recordVersion(%v) sent alerts %v, want %v", tc.smr.GetMapRevision(), rec, tc.want)
+		}
+	}
+}
@@ -17,82 +17,235 @@ package monitor
 import (
 	"context"
 	"crypto"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/keytransparency/core/client/mutationclient"
+	"github.com/google/keytransparency/core/monitor/alert"
 	"github.com/google/keytransparency/core/monitorstorage"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/keytransparency/core/mutator/entry"
 
 	"github.com/google/trillian"
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/sigpb"
 	"github.com/google/trillian/merkle/hashers"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/sync/errgroup"
 
+	mpb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
-	tcrypto "github.com/google/trillian/crypto"
 )
 
+// Signer signs the map roots the monitor has verified. It is implemented by
+// *crypto.Signer from Trillian and by *secrets.Keyring, which follows
+// rotations of the key in a secret store.
+type Signer interface {
+	SignObject(obj interface{}) (*sigpb.DigitallySigned, error)
+}
+
 // Monitor holds the internal state for a monitor accessing the mutations API
 // and for verifying its responses.
 type Monitor struct {
 	mClient     pb.KeyTransparencyClient
-	signer      *tcrypto.Signer
+	signer      Signer
 	trusted     *trillian.SignedLogRoot
 	mapID       int64
 	logVerifier client.LogVerifier
 	store       monitorstorage.Interface
 	mapHasher   hashers.MapHasher
 	mapPubKey   crypto.PublicKey
+	// mutators verifies the mutation types the monitor understands.
+	mutators *mutator.Registry
+	// maxRootDuration is the maximum time allowed between map roots.
+	// Zero disables the check.
+	maxRootDuration time.Duration
+	// keyPolicy is the domain's key policy that mutations must comply with.
+	// It is only used if keyPolicies is empty.
+	keyPolicy *pb.KeyPolicy
+	// keyPolicies lists the key policies of the domain with the first
+	// revision each applies to, oldest first.
+	keyPolicies []*pb.KeyPolicyChange
+	// version is the sequencer version of the last processed epoch.
+	version *pb.ServerVersion
+	// soak and throttle bound resource usage in soak mode.
+	soak     *SoakConfig
+	throttle *throttle
+	// alerts notifies operators of problems. It may be nil.
+	alerts *alert.Dispatcher
+	// watched maps the indexes of watched entries to their names.
+	watched map[string]string
+	// lastRootNanos is the timestamp of the latest map root processed. It
+	// is accessed atomically.
+	lastRootNanos int64
 }
 
 // NewFromConfig produces a new monitor from a Domain object.
 func NewFromConfig(mclient pb.KeyTransparencyClient,
 	config *pb.Domain,
-	signer *tcrypto.Signer,
+	signer Signer,
 	store monitorstorage.Interface) (*Monitor, error) {
 	logTree := config.GetLog()
 	mapTree := config.GetMap()
 	logHasher, err := hashers.NewLogHasher(logTree.GetHashStrategy())
 	if err != nil {
-		return nil, fmt.Errorf("could not initialize log hasher: %v", err)
+		return nil, fmt.Errorf("could not initialize log hasher: %w", err)
 	}
 	logPubKey, err := der.UnmarshalPublicKey(logTree.GetPublicKey().GetDer())
 	if err != nil {
-		return nil, fmt.Errorf("failed parsing log public key: %v", err)
+		return nil, fmt.Errorf("failed parsing log public key: %w", err)
 	}
 	mapHasher, err := hashers.NewMapHasher(mapTree.GetHashStrategy())
 	if err != nil {
-		return nil, fmt.Errorf("failed creating map hasher: %v", err)
+		return nil, fmt.Errorf("failed creating map hasher: %w", err)
 	}
 	mapPubKey, err := der.UnmarshalPublicKey(mapTree.GetPublicKey().GetDer())
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal map public key: %v", err)
+		return nil, fmt.Errorf("could not unmarshal map public key: %w", err)
+	}
+	maxRootDuration, err := ptypes.Duration(mapTree.GetMaxRootDuration())
+	if err != nil {
+		return nil, fmt.Errorf("invalid map max root duration: %w", err)
+	}
+	return New(Options{
+		Client:          mclient,
+		LogVerifier:     client.NewLogVerifier(logHasher, logPubKey),
+		MapID:           mapTree.TreeId,
+		MapHasher:       mapHasher,
+		MapPubKey:       mapPubKey,
+		MaxRootDuration: maxRootDuration,
+		KeyPolicy:       config.GetKeyPolicy(),
+		KeyPolicies:     config.GetKeyPolicyHistory(),
+		Signer:          signer,
+		Store:           store,
+	})
+}
+
+// Options configures a Monitor.
+type Options struct {
+	// Client fetches mutations and proofs from the key server.
+	Client pb.KeyTransparencyClient
+	// LogVerifier verifies the log roots and proofs served by the key server.
+	LogVerifier client.LogVerifier
+	// MapID, MapHasher and MapPubKey describe the domain's map tree.
+	MapID     int64
+	MapHasher hashers.MapHasher
+	MapPubKey crypto.PublicKey
+	// MaxRootDuration is the maximum time allowed between map roots.
+	// Zero disables the check.
+	MaxRootDuration time.Duration
+	// KeyPolicy is the key policy published by the domain. Mutations that
+	// violate it are reported and the map roots they appear in are not
+	// signed. It applies to every epoch if KeyPolicies is empty.
+	KeyPolicy *pb.KeyPolicy
+	// KeyPolicies lists the key policies published by the domain with the
+	// first revision each applies to. Each epoch is checked against the
+	// policy in force at its revision. ProcessLoop and ProcessEpochs refresh
+	// the list from the key server, so policy changes take effect without a
+	// restart.
+	KeyPolicies []*pb.KeyPolicyChange
+	// Mutators verifies the mutation types the monitor understands.
+	// Defaults to entry.NewRegistry(). Use Registry.ForDomain to include the
+	// types registered for the monitored domain only.
+	Mutators *mutator.Registry
+	// Signer signs the map roots the monitor has verified.
+	Signer Signer
+	// Store persists monitoring results.
+	Store monitorstorage.Interface
+	// Alerts, if set, is notified when an epoch fails verification, when
+	// the key server does not publish a map root within MaxRootDuration,
+	// and when the keys of a watched entry change.
+	Alerts *alert.Dispatcher
+	// Watched lists the entries whose key changes raise alerts.
+	Watched []Watched
+}
+
+// Watched is an entry whose key changes raise alerts.
+type Watched struct {
+	// Name identifies the entry in alerts, e.g. the user and app ID.
+	Name string
+	// Index is the map index of the entry.
+	Index []byte
+}
+
+// validate returns an error if a required field is missing and fills in
+// defaults for the optional ones.
+func (o *Options) validate() error {
+	switch {
+	case o.Client == nil:
+		return errors.New("monitor: missing Client")
+	case o.LogVerifier == nil:
+		return errors.New("monitor: missing LogVerifier")
+	case o.MapHasher == nil:
+		return errors.New("monitor: missing MapHasher")
+	case o.MapPubKey == nil:
+		return errors.New("monitor: missing MapPubKey")
+	case o.Signer == nil:
+		return errors.New("monitor: missing Signer")
+	case o.Store == nil:
+		return errors.New("monitor: missing Store")
+	case o.MaxRootDuration < 0:
+		return fmt.Errorf("monitor: negative MaxRootDuration %v", o.MaxRootDuration)
+	}
+	for _, w := range o.Watched {
+		if len(w.Index) == 0 {
+			return fmt.Errorf("monitor: watched entry %q has no index", w.Name)
+		}
+	}
+	if o.Mutators == nil {
+		o.Mutators = entry.NewRegistry()
 	}
-	logVerifier := client.NewLogVerifier(logHasher, logPubKey)
-	return New(mclient, logVerifier,
-		mapTree.TreeId, mapHasher, mapPubKey,
-		signer, store)
+	return nil
 }
 
 // New creates a new instance of the monitor.
-func New(mclient pb.KeyTransparencyClient,
-	logVerifier client.LogVerifier,
-	mapID int64, mapHasher hashers.MapHasher, mapPubKey crypto.PublicKey,
-	signer *tcrypto.Signer,
-	store monitorstorage.Interface) (*Monitor, error) {
+func New(opts Options) (*Monitor, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	watched := make(map[string]string)
+	for _, w := range opts.Watched {
+		watched[string(w.Index)] = w.Name
+	}
 	return &Monitor{
-		mClient:     mclient,
-		logVerifier: logVerifier,
-		mapID:       mapID,
-		mapHasher:   mapHasher,
-		mapPubKey:   mapPubKey,
-		signer:      signer,
-		store:       store,
+		mClient:         opts.Client,
+		logVerifier:     opts.LogVerifier,
+		mapID:           opts.MapID,
+		mapHasher:       opts.MapHasher,
+		mapPubKey:       opts.MapPubKey,
+		maxRootDuration: opts.MaxRootDuration,
+		keyPolicy:       opts.KeyPolicy,
+		keyPolicies:     opts.KeyPolicies,
+		mutators:        opts.Mutators,
+		signer:          opts.Signer,
+		store:           opts.Store,
+		alerts:          opts.Alerts,
+		watched:         watched,
 	}, nil
 }
 
+// SetMutators replaces the mutation types the monitor can verify. Mutations of
+// other types are reported and the map roots they appear in are not signed.
+func (m *Monitor) SetMutators(r *mutator.Registry) {
+	m.mutators = r
+}
+
+// SetAlerts enables alerting through d for the problems described in
+// Options.Alerts. Key changes of the watched entries also raise alerts.
+func (m *Monitor) SetAlerts(d *alert.Dispatcher, watched ...Watched) {
+	m.alerts = d
+	m.watched = make(map[string]string)
+	for _, w := range watched {
+		m.watched[string(w.Index)] = w.Name
+	}
+}
+
 // EpochPair is two adjacent epochs.
 type EpochPair struct {
 	A, B *pb.Epoch
@@ -121,57 +274,248 @@ func EpochPairs(ctx context.Context, epochs <-chan *pb.Epoch, pairs chan<- Epoch
 	return nil
 }
 
+// Names of the ProcessLoop stages, used in StageError.
+const (
+	StageStream  = "stream epochs"
+	StagePair    = "pair epochs"
+	StageProcess = "process epochs"
+)
+
+// StageError records which stage of ProcessLoop failed.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+// stageErr tags a non-nil err with the stage that produced it.
+func stageErr(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StageError{Stage: stage, Err: err}
+}
+
+// epochStreamer sends epochs to out until it fails or ctx is done. It must
+// close out before returning.
+type epochStreamer func(ctx context.Context, out chan<- *pb.Epoch) error
+
 // ProcessLoop continuously fetches mutations and processes them.
+// The returned error is a *StageError identifying the first stage to fail.
 func (m *Monitor) ProcessLoop(ctx context.Context, domainID string, startEpoch int64, period time.Duration) error {
 	mutCli := mutationclient.New(m.mClient, period)
-	cctx, cancel := context.WithCancel(ctx)
-	errc := make(chan error)
-	epochs := make(chan *pb.Epoch)
-	pairs := make(chan EpochPair)
-
-	go func(ctx context.Context) {
-		errc <- mutCli.StreamEpochs(ctx, domainID, startEpoch, epochs)
-	}(cctx)
-	go func(ctx context.Context) {
-		errc <- EpochPairs(ctx, epochs, pairs)
-	}(cctx)
-	defer cancel()
-
-	for pair := range pairs {
-		revision := pair.B.GetSmr().GetMapRevision()
+	stream := func(ctx context.Context, out chan<- *pb.Epoch) error {
+		return mutCli.StreamEpochs(ctx, domainID, startEpoch, out)
+	}
+	process := func(ctx context.Context, pair EpochPair) error {
 		mutations, err := mutCli.EpochMutations(ctx, pair.B)
 		if err != nil {
 			return err
 		}
+		m.refreshKeyPolicies(ctx, domainID)
+		return m.processPair(pair, mutations)
+	}
+	if m.alerts != nil && m.maxRootDuration > 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go m.watchRootInterval(ctx, period)
+	}
+	return processLoop(ctx, stream, process)
+}
 
-		var smr *trillian.SignedMapRoot
-		var errList []error
-		if errs := m.VerifyEpochMutations(pair.A, pair.B, mutations); len(errs) > 0 {
-			glog.Infof("Epoch %v did not verify: %v", revision, errs)
-			errList = errs
-		} else {
-			// Sign if successful.
-			smr, err = m.signMapRoot(pair.B.GetSmr())
+// Resume restores the trusted log root and the sequencer version saved in the
+// monitor's storage and returns the epoch to start processing from, so that a
+// restarted monitor continues where it stopped. If no progress was saved, it
+// returns the latest epoch with a stored result.
+func (m *Monitor) Resume() (int64, error) {
+	start := m.store.LatestEpoch()
+	state, err := m.store.State()
+	switch {
+	case errors.Is(err, monitorstorage.ErrNotFound):
+	case err != nil:
+		return 0, fmt.Errorf("monitorstorage.State(): %w", err)
+	default:
+		m.trusted = state.Trusted
+		// The result of a revision is stored before the progress is,
+		// so the storage may hold results past the saved revision. The
+		// saved log root is older than theirs, but remains a valid root
+		// to verify them from.
+		if state.Revision > start {
+			start = state.Revision
+		}
+	}
+	if r, err := m.store.Get(start); err == nil {
+		m.version = r.GetSequencerVersion()
+	}
+	return start, nil
+}
+
+// ProcessEpochs verifies the transitions from startEpoch through endEpoch.
+// Unlike ProcessLoop it does not wait for new epochs; it fails if any of the
+// epochs does not exist yet.
+func (m *Monitor) ProcessEpochs(ctx context.Context, domainID string, startEpoch, endEpoch int64) error {
+	mutCli := mutationclient.New(m.mClient, 0)
+	var prev *pb.Epoch
+	for i := startEpoch; i <= endEpoch; i++ {
+		epoch, err := m.mClient.GetEpoch(ctx, &pb.GetEpochRequest{
+			DomainId:      domainID,
+			Epoch:         i,
+			FirstTreeSize: startEpoch,
+		})
+		if err != nil {
+			return fmt.Errorf("GetEpoch(%v, %v): %w", domainID, i, err)
+		}
+		if prev != nil {
+			mutations, err := mutCli.EpochMutations(ctx, epoch)
 			if err != nil {
 				return err
 			}
+			m.refreshKeyPolicies(ctx, domainID)
+			if err := m.processPair(EpochPair{A: prev, B: epoch}, mutations); err != nil {
+				return err
+			}
 		}
+		prev = epoch
+	}
+	return nil
+}
 
-		// Save result.
-		if err := m.store.Set(revision, &monitorstorage.Result{
-			Smr:    smr,
-			Seen:   time.Now(),
-			Errors: errList,
-		}); err != nil {
-			return fmt.Errorf("monitorstorage.Set(%v, _): %v", revision, err)
+// refreshKeyPolicies fetches the key policy history of the domain. If the key
+// server cannot be reached, the known history is kept.
+func (m *Monitor) refreshKeyPolicies(ctx context.Context, domainID string) {
+	d, err := m.mClient.GetDomain(ctx, &pb.GetDomainRequest{DomainId: domainID})
+	if err != nil {
+		glog.Warningf("GetDomain(%v): %v; keeping the known key policies", domainID, err)
+		return
+	}
+	if h := d.GetKeyPolicyHistory(); len(h) > 0 {
+		m.keyPolicies = h
+	}
+}
+
+// keyPolicyAt returns the key policy in force at revision.
+func (m *Monitor) keyPolicyAt(revision int64) *pb.KeyPolicy {
+	if len(m.keyPolicies) == 0 {
+		return m.keyPolicy
+	}
+	var policy *pb.KeyPolicy
+	for _, c := range m.keyPolicies {
+		if c.GetRevision() > revision {
+			break
 		}
+		policy = c.GetKeyPolicy()
 	}
-	errA := <-errc
-	errB := <-errc
-	if err := errA; err != nil {
+	return policy
+}
+
+// processLoop runs the stream, pair, and process stages concurrently.
+// The first stage to fail cancels the others. Every stage returns promptly
+// once the shared context is cancelled, so processLoop never deadlocks.
+// Shutdown proceeds from upstream to downstream: closing epochs ends the pair
+// stage, which closes pairs and ends the process stage.
+func processLoop(ctx context.Context, stream epochStreamer, process func(context.Context, EpochPair) error) error {
+	g, gctx := errgroup.WithContext(ctx)
+	epochs := make(chan *pb.Epoch)
+	pairs := make(chan EpochPair)
+
+	g.Go(func() error {
+		return stageErr(StageStream, stream(gctx, epochs))
+	})
+	g.Go(func() error {
+		return stageErr(StagePair, EpochPairs(gctx, epochs, pairs))
+	})
+	g.Go(func() error {
+		for pair := range pairs {
+			if err := process(gctx, pair); err != nil {
+				return stageErr(StageProcess, err)
+			}
+		}
+		return nil
+	})
+	return g.Wait()
+}
+
+// processPair verifies and stores the transition from pair.A to pair.B.
+func (m *Monitor) processPair(pair EpochPair, mutations []*pb.MutationProof) error {
+	revision := pair.B.GetSmr().GetMapRevision()
+	var smr *trillian.SignedMapRoot
+	var errList []error
+	errs := m.VerifyEpochMutations(pair.A, pair.B, mutations)
+	// pair.A was verified earlier if the monitor resumed from it.
+	verr, err := m.verifyKnownRoot(pair.A.GetSmr())
+	if err != nil {
 		return err
 	}
-	return errB
+	if verr != nil {
+		errs = append(errs, verr)
+	}
+	if len(errs) > 0 {
+		glog.Infof("Epoch %v did not verify: %v", revision, errs)
+		errList = errs
+		m.alertErrors(revision, errs)
+	} else {
+		// Sign if successful.
+		var err error
+		smr, err = m.signMapRoot(pair.B.GetSmr())
+		if err != nil {
+			return err
+		}
+		m.commitRoot(pair.B.GetLogRoot())
+	}
+
+	m.alertKeyChanges(revision, mutations)
+	atomic.StoreInt64(&m.lastRootNanos, pair.B.GetSmr().GetTimestampNanos())
+	version := m.recordVersion(pair.B.GetSmr())
+	verrs, err := VerificationErrors(errList)
+	if err != nil {
+		return fmt.Errorf("epoch %v: %w", revision, err)
+	}
+
+	// Save result.
+	if err := m.store.Set(revision, &mpb.VerificationResult{
+		Revision:         revision,
+		Smr:              smr,
+		SeenTime:         ptypes.TimestampNow(),
+		Errors:           verrs,
+		SequencerVersion: version,
+	}); err != nil {
+		return fmt.Errorf("monitorstorage.Set(%v, _): %w", revision, err)
+	}
+	if err := m.store.SetState(&monitorstorage.State{Revision: revision, Trusted: m.trusted}); err != nil {
+		return fmt.Errorf("monitorstorage.SetState(%v): %w", revision, err)
+	}
+	if m.throttle != nil {
+		if err := m.throttle.adjust(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordVersion extracts the sequencer version from smr's metadata and reports
+// any change from the version of the previous epoch. The version is stored
+// with the epoch's result, and Resume reloads it, so that changes are also
+// detected across restarts of the monitor.
+func (m *Monitor) recordVersion(smr *trillian.SignedMapRoot) *pb.ServerVersion {
+	var meta pb.MapperMetadata
+	if smr.GetMetadata() != nil {
+		if err := ptypes.UnmarshalAny(smr.GetMetadata(), &meta); err != nil {
+			glog.Warningf("Epoch %v: could not parse map root metadata: %v", smr.GetMapRevision(), err)
+		}
+	}
+	version := meta.GetSequencerVersion()
+	if m.version != nil && !proto.Equal(m.version, version) {
+		glog.Infof("Epoch %v: sequencer version changed from %v (%x) to %v (%x)",
+			smr.GetMapRevision(),
+			m.version.GetVersion(), m.version.GetBinaryDigest(),
+			version.GetVersion(), version.GetBinaryDigest())
+		m.alertVersionChange(smr.GetMapRevision(), m.version, version)
+	}
+	m.version = version
+	return version
 }
 
 // VerifyEpochMutations validates that epochA + mutations = epochB.
@@ -185,10 +529,17 @@ func (m *Monitor) VerifyEpochMutations(epochA, epochB *pb.Epoch, mutations []*pb
 	// Fetch Previous root.
 	smrA := epochA.GetSmr()
 	smrB := epochB.GetSmr()
-	if errs := m.verifyMutations(mutations, smrA.GetRootHash(), smrB.GetRootHash(), smrB.GetMapId()); len(errs) > 0 {
-		glog.Errorf("Invalid Epoch %v Mutations: %v", revision, errs)
-		return errs
+	// A root published late or too early is reported, but the mutations
+	// are still verified so that a bad epoch is not hidden behind it.
+	var errs []error
+	if err := m.verifyRootInterval(smrA, smrB); err != nil {
+		glog.Errorf("Epoch %v: %v", revision, err)
+		errs = append(errs, err)
 	}
-	return nil
-
+	policy := m.keyPolicyAt(revision)
+	if merrs := m.verifyMutations(mutations, policy, smrA.GetRootHash(), smrB.GetRootHash(), smrB.GetMapId()); len(merrs) > 0 {
+		glog.Errorf("Invalid Epoch %v Mutations: %v", revision, merrs)
+		errs = append(errs, merrs...)
+	}
+	return errs
 }
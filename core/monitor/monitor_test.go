@@ -16,8 +16,17 @@ package monitor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/monitorstorage"
+	"google.golang.org/grpc/codes"
+
+	mpb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 	tpb "github.com/google/trillian"
 )
@@ -52,3 +61,294 @@ func TestEpochPairs(t *testing.T) {
 		}
 	}
 }
+
+// streamEpochs returns an epochStreamer that sends epochs [0, n) and then
+// either returns err or, if block is set, waits for ctx to be cancelled.
+func streamEpochs(n int, err error, block bool) epochStreamer {
+	return func(ctx context.Context, out chan<- *pb.Epoch) error {
+		defer close(out)
+		for i := 0; i < n; i++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- &pb.Epoch{Smr: &tpb.SignedMapRoot{MapRevision: int64(i)}}:
+			}
+		}
+		if block {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return err
+	}
+}
+
+func TestProcessLoop(t *testing.T) {
+	errStream := errors.New("stream failed")
+	errProcess := errors.New("process failed")
+	for _, tc := range []struct {
+		desc      string
+		epochs    int
+		streamErr error
+		block     bool
+		failAt    int64 // Revision of pair.B at which process fails. -1 never fails.
+		cancel    bool  // Cancel the parent context once processing starts.
+		wantStage string
+		wantErr   error
+		wantPairs int // -1 if the number of processed pairs is not deterministic.
+	}{
+		{desc: "clean shutdown", epochs: 4, failAt: -1, wantPairs: 3},
+		{desc: "no epochs", epochs: 0, failAt: -1},
+		{desc: "stream fails immediately", epochs: 0, streamErr: errStream, failAt: -1,
+			wantStage: StageStream, wantErr: errStream},
+		{desc: "stream fails after epochs", epochs: 3, streamErr: errStream, failAt: -1,
+			wantStage: StageStream, wantErr: errStream, wantPairs: -1},
+		{desc: "process fails on first pair", epochs: 10, block: true, failAt: 1,
+			wantStage: StageProcess, wantErr: errProcess},
+		{desc: "process fails while stream waits", epochs: 3, block: true, failAt: 2,
+			wantStage: StageProcess, wantErr: errProcess, wantPairs: 1},
+		{desc: "process fails on last pair", epochs: 3, failAt: 2,
+			wantStage: StageProcess, wantErr: errProcess, wantPairs: 1},
+		{desc: "parent cancelled", epochs: 2, block: true, failAt: -1, cancel: true,
+			wantErr: context.Canceled, wantPairs: 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			processed := 0
+			process := func(ctx context.Context, pair EpochPair) error {
+				if pair.B.GetSmr().GetMapRevision() == tc.failAt {
+					return errProcess
+				}
+				processed++
+				if tc.cancel {
+					cancel()
+				}
+				return nil
+			}
+
+			done := make(chan error)
+			go func() {
+				done <- processLoop(ctx, streamEpochs(tc.epochs, tc.streamErr, tc.block), process)
+			}()
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("processLoop() did not return: deadlock")
+			}
+
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("processLoop(): %v, want nil", err)
+				}
+			} else {
+				serr, ok := err.(*StageError)
+				if !ok {
+					t.Fatalf("processLoop(): %v, want *StageError", err)
+				}
+				if got, want := serr.Err, tc.wantErr; got != want {
+					t.Errorf("processLoop().Err: %v, want %v", got, want)
+				}
+				if tc.wantStage != "" && serr.Stage != tc.wantStage {
+					t.Errorf("processLoop().Stage: %v, want %v", serr.Stage, tc.wantStage)
+				}
+			}
+			if got, want := processed, tc.wantPairs; want >= 0 && got != want {
+				t.Errorf("processed %v pairs, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestVerifyRootInterval(t *testing.T) {
+	root := func(rev int64, at time.Duration) *tpb.SignedMapRoot {
+		return &tpb.SignedMapRoot{MapRevision: rev, TimestampNanos: int64(at)}
+	}
+	for _, tc := range []struct {
+		maxRootDuration time.Duration
+		a, b            *tpb.SignedMapRoot
+		wantErr         bool
+	}{
+		{maxRootDuration: 0, a: root(1, 0), b: root(2, time.Hour)},
+		{maxRootDuration: time.Minute, a: root(1, 0), b: root(2, time.Minute)},
+		{maxRootDuration: time.Minute, a: root(1, 0), b: root(2, time.Hour), wantErr: true},
+	} {
+		m := &Monitor{maxRootDuration: tc.maxRootDuration}
+		err := m.verifyRootInterval(tc.a, tc.b)
+		if got, want := err != nil, tc.wantErr; got != want {
+			t.Errorf("verifyRootInterval(%v, %v) with max %v: %v, wantErr %v",
+				tc.a.TimestampNanos, tc.b.TimestampNanos, tc.maxRootDuration, err, want)
+		}
+	}
+}
+
+func TestKeyPolicyAt(t *testing.T) {
+	p1 := &pb.KeyPolicy{MaxKeys: 1}
+	p2 := &pb.KeyPolicy{MaxKeys: 2}
+	history := []*pb.KeyPolicyChange{
+		{Revision: 3, KeyPolicy: p1},
+		{Revision: 5},
+		{Revision: 7, KeyPolicy: p2},
+	}
+	for _, tc := range []struct {
+		desc     string
+		policy   *pb.KeyPolicy
+		history  []*pb.KeyPolicyChange
+		revision int64
+		want     *pb.KeyPolicy
+	}{
+		{desc: "no history", policy: p2, revision: 1, want: p2},
+		{desc: "before first change", policy: p2, history: history, revision: 2},
+		{desc: "first change", history: history, revision: 3, want: p1},
+		{desc: "between changes", history: history, revision: 4, want: p1},
+		{desc: "removed", history: history, revision: 6},
+		{desc: "latest", history: history, revision: 9, want: p2},
+	} {
+		m := &Monitor{keyPolicy: tc.policy, keyPolicies: tc.history}
+		if got := m.keyPolicyAt(tc.revision); got != tc.want {
+			t.Errorf("%v: keyPolicyAt(%v): %v, want %v", tc.desc, tc.revision, got, tc.want)
+		}
+	}
+}
+
+func TestCommitRoot(t *testing.T) {
+	m := &Monitor{}
+	for _, tc := range []struct {
+		size, want int64
+	}{
+		{size: 2, want: 2},
+		{size: 1, want: 2}, // Never goes backwards.
+		{size: 3, want: 3},
+	} {
+		m.commitRoot(&tpb.SignedLogRoot{TreeSize: tc.size})
+		if got := m.trusted.GetTreeSize(); got != tc.want {
+			t.Errorf("commitRoot(%v): trusted size %v, want %v", tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestVerifyKnownRoot(t *testing.T) {
+	store := fake.NewMonitorStorage()
+	// Revision 1 was verified and signed, revision 2 failed verification.
+	if err := store.Set(1, &mpb.VerificationResult{
+		Revision: 1,
+		Smr:      &tpb.SignedMapRoot{MapRevision: 1, RootHash: []byte("root1")},
+	}); err != nil {
+		t.Fatalf("Set(1): %v", err)
+	}
+	if err := store.Set(2, &mpb.VerificationResult{Revision: 2}); err != nil {
+		t.Fatalf("Set(2): %v", err)
+	}
+	m := &Monitor{store: store}
+	for _, tc := range []struct {
+		desc     string
+		revision int64
+		root     string
+		wantErr  bool
+	}{
+		{desc: "same root", revision: 1, root: "root1"},
+		{desc: "changed root", revision: 1, root: "purged", wantErr: true},
+		{desc: "unverified revision", revision: 2, root: "root2"},
+		{desc: "unknown revision", revision: 3, root: "root3"},
+	} {
+		verr, err := m.verifyKnownRoot(&tpb.SignedMapRoot{MapRevision: tc.revision, RootHash: []byte(tc.root)})
+		if err != nil {
+			t.Errorf("%v: verifyKnownRoot(): %v", tc.desc, err)
+			continue
+		}
+		if got := verr != nil; got != tc.wantErr {
+			t.Errorf("%v: verifyKnownRoot(): %v, wantErr %v", tc.desc, verr, tc.wantErr)
+			continue
+		}
+		if verr != nil && !errors.Is(verr, ErrChangedMapRoot) {
+			t.Errorf("%v: verifyKnownRoot(): %v, want %v", tc.desc, verr, ErrChangedMapRoot)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	trusted := &tpb.SignedLogRoot{TreeSize: 5}
+	for _, tc := range []struct {
+		desc        string
+		state       *monitorstorage.State
+		results     []int64
+		want        int64
+		wantTrusted *tpb.SignedLogRoot
+	}{
+		{desc: "empty"},
+		{desc: "results only", results: []int64{1, 2}, want: 2},
+		{desc: "saved state", state: &monitorstorage.State{Revision: 4, Trusted: trusted},
+			results: []int64{3, 4}, want: 4, wantTrusted: trusted},
+		{desc: "result after state", state: &monitorstorage.State{Revision: 4, Trusted: trusted},
+			results: []int64{4, 5}, want: 5, wantTrusted: trusted},
+	} {
+		store := fake.NewMonitorStorage()
+		for _, r := range tc.results {
+			if err := store.Set(r, &mpb.VerificationResult{
+				Revision:         r,
+				SequencerVersion: &pb.ServerVersion{Version: fmt.Sprintf("v%v", r)},
+			}); err != nil {
+				t.Fatalf("Set(): %v", err)
+			}
+		}
+		if tc.state != nil {
+			if err := store.SetState(tc.state); err != nil {
+				t.Fatalf("SetState(): %v", err)
+			}
+		}
+		m := &Monitor{store: store}
+		got, err := m.Resume()
+		if err != nil {
+			t.Errorf("%v: Resume(): %v", tc.desc, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%v: Resume(): %v, want %v", tc.desc, got, tc.want)
+		}
+		if m.trusted != tc.wantTrusted {
+			t.Errorf("%v: Resume() trusted: %v, want %v", tc.desc, m.trusted, tc.wantTrusted)
+		}
+		if got, want := m.version.GetVersion(), fmt.Sprintf("v%v", tc.want); len(tc.results) > 0 && got != want {
+			t.Errorf("%v: Resume() version: %v, want %v", tc.desc, got, want)
+		}
+	}
+}
+
+func TestVerificationErrors(t *testing.T) {
+	smr := &tpb.SignedMapRoot{MapRevision: 2}
+	var nilEntry *pb.Entry
+	errs := []error{
+		newError(mpb.VerificationError_INVALID_MUTATION, "invalid mutation", smr, nilEntry),
+		ErrNotMatchingMapRoot,
+		errors.New("other"),
+	}
+	got, err := VerificationErrors(errs)
+	if err != nil {
+		t.Fatalf("VerificationErrors(): %v", err)
+	}
+	for i, tc := range []struct {
+		code         mpb.VerificationError_Code
+		evidence     int
+		statusCode   codes.Code
+		wantSentinel error
+	}{
+		{code: mpb.VerificationError_INVALID_MUTATION, evidence: 1, statusCode: codes.DataLoss, wantSentinel: ErrInvalidMutation},
+		{code: mpb.VerificationError_NOT_MATCHING_MAP_ROOT, statusCode: codes.DataLoss, wantSentinel: ErrNotMatchingMapRoot},
+		{code: mpb.VerificationError_UNKNOWN, statusCode: codes.Unknown},
+	} {
+		v := got[i]
+		if v.GetCode() != tc.code || len(v.GetEvidence()) != tc.evidence || v.GetMessage() != errs[i].Error() {
+			t.Errorf("VerificationErrors()[%v]: %v, want code %v with %v evidence", i, v, tc.code, tc.evidence)
+		}
+		if got := codes.Code(StatusProto(v).GetCode()); got != tc.statusCode {
+			t.Errorf("StatusProto(%v).Code: %v, want %v", tc.code, got, tc.statusCode)
+		}
+		if tc.wantSentinel != nil && !errors.Is(errs[i], tc.wantSentinel) {
+			t.Errorf("errors.Is(%v, %v): false, want true", errs[i], tc.wantSentinel)
+		}
+	}
+	var evidence tpb.SignedMapRoot
+	if err := ptypes.UnmarshalAny(got[0].GetEvidence()[0], &evidence); err != nil || evidence.GetMapRevision() != 2 {
+		t.Errorf("UnmarshalAny(evidence): %v, %v, want map root at revision 2", &evidence, err)
+	}
+}
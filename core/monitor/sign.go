@@ -27,7 +27,7 @@ func (m *Monitor) signMapRoot(in *tpb.SignedMapRoot) (*tpb.SignedMapRoot, error)
 
 	sig, err := m.signer.SignObject(smr)
 	if err != nil {
-		return nil, fmt.Errorf("SignObject(): %v", err)
+		return nil, fmt.Errorf("SignObject(): %w", err)
 	}
 	smr.Signature = sig
 
@@ -0,0 +1,141 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// ErrMemoryCeiling occurs when the monitor's heap exceeds
+// SoakConfig.MaxHeapBytes even after throttling and garbage collection.
+var ErrMemoryCeiling = errors.New("monitor exceeded memory ceiling")
+
+// SoakConfig bounds the resources used by a long running monitor so that it
+// can audit large domains on small machines. A monitor saves its progress in
+// its monitorstorage.Interface after every epoch, and Resume continues from
+// there after a restart.
+type SoakConfig struct {
+	// MaxHeapBytes is a hard ceiling on heap usage. 0 means no ceiling.
+	MaxHeapBytes uint64
+	// MaxProcs limits the number of CPUs the monitor uses. 0 means no limit.
+	MaxProcs int
+	// MaxConcurrency is the maximum number of proofs verified in parallel.
+	// The monitor lowers the effective concurrency as memory use approaches
+	// MaxHeapBytes and raises it again as memory is freed.
+	MaxConcurrency int
+}
+
+// SetSoakMode applies the resource limits in cfg to the monitor.
+func (m *Monitor) SetSoakMode(cfg SoakConfig) {
+	if cfg.MaxProcs > 0 {
+		runtime.GOMAXPROCS(cfg.MaxProcs)
+	}
+	if cfg.MaxConcurrency < 1 {
+		cfg.MaxConcurrency = 1
+	}
+	m.soak = &cfg
+	m.throttle = newThrottle(cfg.MaxConcurrency, cfg.MaxHeapBytes)
+}
+
+// throttle limits the number of concurrent verifications, adapting the limit
+// to the current heap usage.
+type throttle struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	inFlight int
+	limit    int
+	max      int
+	maxHeap  uint64
+}
+
+func newThrottle(max int, maxHeap uint64) *throttle {
+	t := &throttle{limit: max, max: max, maxHeap: maxHeap}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// acquire blocks until a verification slot is free.
+func (t *throttle) acquire() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.inFlight >= t.limit {
+		t.cond.Wait()
+	}
+	t.inFlight++
+}
+
+// release frees a verification slot.
+func (t *throttle) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	t.cond.Broadcast()
+}
+
+// adjust samples the heap and adapts the concurrency limit. It returns
+// ErrMemoryCeiling if the heap is over the ceiling even after a forced GC.
+func (t *throttle) adjust() error {
+	if t.maxHeap == 0 {
+		return nil
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	if ms.HeapAlloc > t.maxHeap {
+		debug.FreeOSMemory()
+		runtime.ReadMemStats(&ms)
+		if ms.HeapAlloc > t.maxHeap {
+			return ErrMemoryCeiling
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case ms.HeapAlloc > t.maxHeap/10*8 && t.limit > 1:
+		t.limit /= 2
+		glog.V(2).Infof("Heap at %v bytes, reducing verification concurrency to %v", ms.HeapAlloc, t.limit)
+	case ms.HeapAlloc < t.maxHeap/2 && t.limit < t.max:
+		t.limit++
+		t.cond.Broadcast()
+	}
+	return nil
+}
+
+// parallel runs fn(i) for i in [0, n), using the throttle to bound
+// concurrency. Without a throttle, fn is run sequentially.
+func (m *Monitor) parallel(n int, fn func(i int)) {
+	if m.throttle == nil {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		m.throttle.acquire()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer m.throttle.release()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
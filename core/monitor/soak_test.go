@@ -0,0 +1,82 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestThrottleAdjust(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		maxHeap   uint64
+		limit     int
+		wantLimit int
+		wantErr   error
+	}{
+		{desc: "no ceiling", limit: 2, wantLimit: 2},
+		{desc: "plenty of memory", maxHeap: 1 << 50, limit: 2, wantLimit: 3},
+		{desc: "at max", maxHeap: 1 << 50, limit: 4, wantLimit: 4},
+		{desc: "over ceiling", maxHeap: 1, limit: 2, wantLimit: 2, wantErr: ErrMemoryCeiling},
+	} {
+		th := newThrottle(4, tc.maxHeap)
+		th.limit = tc.limit
+		if err := th.adjust(); err != tc.wantErr {
+			t.Errorf("%v: adjust(): %v, want %v", tc.desc, err, tc.wantErr)
+		}
+		if got := th.limit; got != tc.wantLimit {
+			t.Errorf("%v: adjust(): limit %v, want %v", tc.desc, got, tc.wantLimit)
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		throttle *throttle
+		wantMax  int
+	}{
+		{desc: "sequential", wantMax: 1},
+		{desc: "throttled", throttle: newThrottle(3, 0), wantMax: 3},
+	} {
+		m := &Monitor{throttle: tc.throttle}
+		var mu sync.Mutex
+		var inFlight, max int
+		done := make([]bool, 20)
+		m.parallel(len(done), func(i int) {
+			mu.Lock()
+			inFlight++
+			if inFlight > max {
+				max = inFlight
+			}
+			mu.Unlock()
+
+			done[i] = true
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		})
+		for i, ok := range done {
+			if !ok {
+				t.Errorf("%v: parallel() did not run fn(%v)", tc.desc, i)
+			}
+		}
+		if max > tc.wantMax {
+			t.Errorf("%v: parallel() ran %v calls at once, want at most %v", tc.desc, max, tc.wantMax)
+		}
+	}
+}
@@ -21,16 +21,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"reflect"
+	"time"
 
+	"github.com/google/keytransparency/core/monitorstorage"
+	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/trillian"
 	"github.com/google/trillian/merkle"
 	"github.com/google/trillian/storage"
 
+	mpb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 	tcrypto "github.com/google/trillian/crypto"
 	statuspb "google.golang.org/genproto/googleapis/rpc/status"
@@ -53,69 +61,191 @@ var (
 	// ErrInvalidMutation occurs when verification failed because of an invalid
 	// mutation.
 	ErrInvalidMutation = errors.New("invalid mutation")
+	// ErrKeyPolicyViolation occurs when a mutation's authorized keys do not
+	// comply with the domain's key policy.
+	ErrKeyPolicyViolation = errors.New("key policy violation")
 	// ErrNotMatchingMapRoot occurs when the reconstructed root differs from the
 	// one we received from the server.
 	ErrNotMatchingMapRoot = errors.New("recreated root does not match")
+	// ErrStaleMapRoot occurs when consecutive map roots are further apart
+	// than the map's max root duration.
+	ErrStaleMapRoot = errors.New("map root not refreshed within max root duration")
+	// ErrUnverifiableMapRoot occurs when the map root cannot be recreated
+	// because the epoch contains mutations of an unknown type.
+	ErrUnverifiableMapRoot = errors.New("map root contains unknown mutation types")
+	// ErrChangedMapRoot occurs when the server serves a different map root
+	// for a revision that the monitor already verified.
+	ErrChangedMapRoot = errors.New("map root of verified revision changed")
 )
 
-// ErrList is a list of errors.
-type ErrList []error
+// sentinelCodes are the codes of the sentinel errors that verification
+// returns unwrapped.
+var sentinelCodes = map[error]mpb.VerificationError_Code{
+	ErrInconsistentProofs:         mpb.VerificationError_INCONSISTENT_PROOFS,
+	ErrInvalidLogConsistencyProof: mpb.VerificationError_INVALID_LOG_CONSISTENCY_PROOF,
+	ErrInvalidLogInclusion:        mpb.VerificationError_INVALID_LOG_INCLUSION,
+	ErrInvalidLogSignature:        mpb.VerificationError_INVALID_LOG_SIGNATURE,
+	ErrInvalidMapSignature:        mpb.VerificationError_INVALID_MAP_SIGNATURE,
+	ErrInvalidMutation:            mpb.VerificationError_INVALID_MUTATION,
+	ErrKeyPolicyViolation:         mpb.VerificationError_KEY_POLICY_VIOLATION,
+	ErrNotMatchingMapRoot:         mpb.VerificationError_NOT_MATCHING_MAP_ROOT,
+	ErrStaleMapRoot:               mpb.VerificationError_STALE_MAP_ROOT,
+	ErrUnverifiableMapRoot:        mpb.VerificationError_UNVERIFIABLE_MAP_ROOT,
+	ErrChangedMapRoot:             mpb.VerificationError_CHANGED_MAP_ROOT,
+}
 
-// AppendStatus adds a status errord, or the error about adding
-// the status if the latter is not nil.
-func (e *ErrList) AppendStatus(s *status.Status, err error) {
-	if err != nil {
-		*e = append(*e, err)
-	} else {
-		*e = append(*e, s.Err())
-	}
+// Error is a failed verification check, along with the data the check failed
+// on.
+type Error struct {
+	Code     mpb.VerificationError_Code
+	Message  string
+	Evidence []proto.Message
 }
 
-// AppendErr adds a generic error to the list.
-func (e *ErrList) appendErr(err ...error) {
-	*e = append(*e, err...)
+// newError returns an Error for a check that failed on evidence.
+func newError(code mpb.VerificationError_Code, msg string, evidence ...proto.Message) *Error {
+	return &Error{Code: code, Message: msg, Evidence: evidence}
+}
+
+func (e *Error) Error() string {
+	return e.Message
 }
 
-// Proto converts all the errors to statuspb.Status.
-// If the original error was not a status.Status, we use codes.Unknown.
-func (e *ErrList) Proto() []*statuspb.Status {
-	errs := make([]*statuspb.Status, 0, len(*e))
-	for _, err := range *e {
-		if s, ok := status.FromError(err); ok {
-			errs = append(errs, s.Proto())
+// Is reports whether target is the sentinel error for e's code.
+func (e *Error) Is(target error) bool {
+	code, ok := sentinelCodes[target]
+	return ok && code == e.Code
+}
+
+// Proto converts e to a VerificationError. Missing evidence is skipped.
+func (e *Error) Proto() (*mpb.VerificationError, error) {
+	v := &mpb.VerificationError{Code: e.Code, Message: e.Message}
+	for _, ev := range e.Evidence {
+		if ev == nil || reflect.ValueOf(ev).IsNil() {
 			continue
 		}
-		errs = append(errs, status.Newf(codes.Unknown, "%v", err).Proto())
+		a, err := ptypes.MarshalAny(ev)
+		if err != nil {
+			return nil, fmt.Errorf("ptypes.MarshalAny(%T): %w", ev, err)
+		}
+		v.Evidence = append(v.Evidence, a)
 	}
-	return errs
+	return v, nil
+}
+
+// VerificationErrors converts the errors returned by verification to
+// VerificationErrors. Errors that are neither an *Error nor a sentinel error
+// get the code UNKNOWN.
+func VerificationErrors(errs []error) ([]*mpb.VerificationError, error) {
+	ret := make([]*mpb.VerificationError, 0, len(errs))
+	for _, err := range errs {
+		var e *Error
+		if !errors.As(err, &e) {
+			code, ok := sentinelCodes[err]
+			if !ok {
+				code = mpb.VerificationError_UNKNOWN
+			}
+			e = newError(code, err.Error())
+		}
+		v, err := e.Proto()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
+
+// StatusProto converts v to a google.rpc.Status. Its evidence becomes the
+// status details.
+func StatusProto(v *mpb.VerificationError) *statuspb.Status {
+	var code codes.Code
+	switch v.GetCode() {
+	case mpb.VerificationError_UNKNOWN:
+		code = codes.Unknown
+	case mpb.VerificationError_KEY_POLICY_VIOLATION:
+		code = codes.FailedPrecondition
+	case mpb.VerificationError_UNKNOWN_MUTATION_TYPE, mpb.VerificationError_UNVERIFIABLE_MAP_ROOT:
+		code = codes.Unimplemented
+	default:
+		code = codes.DataLoss
+	}
+	return &statuspb.Status{
+		Code:    int32(code),
+		Message: v.GetMessage(),
+		Details: v.GetEvidence(),
+	}
+}
+
+// verifyKnownRoot checks that smr has the root hash of the map root the
+// monitor verified and signed for the same revision, if any. A purge only
+// withholds committed data from the server's responses, so it must not change
+// the roots of revisions that were already verified. The returned error is set
+// if the monitor's storage cannot be read.
+func (m *Monitor) verifyKnownRoot(smr *trillian.SignedMapRoot) (*Error, error) {
+	r, err := m.store.Get(smr.GetMapRevision())
+	if errors.Is(err, monitorstorage.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("monitorstorage.Get(%v): %w", smr.GetMapRevision(), err)
+	}
+	// Revisions that failed verification were not signed.
+	verified := r.GetSmr()
+	if verified == nil || bytes.Equal(verified.GetRootHash(), smr.GetRootHash()) {
+		return nil, nil
+	}
+	return newError(mpb.VerificationError_CHANGED_MAP_ROOT,
+		fmt.Sprintf("%v: revision %v has root %x, verified %x", ErrChangedMapRoot,
+			smr.GetMapRevision(), smr.GetRootHash(), verified.GetRootHash()),
+		verified, smr), nil
+}
+
+// commitRoot trusts root if it is newer than the trusted log root. root must
+// have been verified by VerifyEpoch, so an epoch that fails verification
+// never becomes the root later epochs are checked against.
+func (m *Monitor) commitRoot(root *trillian.SignedLogRoot) {
+	if root.GetTreeSize() > m.trusted.GetTreeSize() {
+		m.trusted = root
+	}
+}
+
+// ErrList is a list of errors.
+type ErrList []error
+
+// appendErr adds errors to the list.
+func (e *ErrList) appendErr(err ...error) {
+	*e = append(*e, err...)
 }
 
 // VerifyEpoch verifies that epoch is correctly signed and included in the append only log.
+// It does not change the trusted log root; see commitRoot.
 func (m *Monitor) VerifyEpoch(epoch *pb.Epoch) []error {
 	errs := ErrList{}
 
-	if m.trusted == nil {
-		m.trusted = epoch.GetLogRoot()
+	trusted := m.trusted
+	if trusted == nil {
+		trusted = epoch.GetLogRoot()
 	}
 
-	if err := m.logVerifier.VerifyRoot(m.trusted, epoch.GetLogRoot(), epoch.GetLogConsistency()); err != nil {
+	if err := m.logVerifier.VerifyRoot(trusted, epoch.GetLogRoot(), epoch.GetLogConsistency()); err != nil {
 		// this could be one of ErrInvalidLogSignature, ErrInvalidLogConsistencyProof
-		errs.AppendStatus(status.Newf(codes.DataLoss, "VerifyRoot: %v", err).WithDetails(m.trusted, epoch))
+		errs.appendErr(newError(mpb.VerificationError_INVALID_LOG_CONSISTENCY_PROOF,
+			fmt.Sprintf("VerifyRoot: %v", err), trusted, epoch))
 	}
-	// updated trusted log root
-	m.trusted = epoch.GetLogRoot()
 
 	b, err := json.Marshal(epoch.GetSmr())
 	if err != nil {
 		glog.Errorf("json.Marshal(): %v", err)
-		errs.AppendStatus(status.Newf(codes.DataLoss, "json.Marshal(): %v", err).WithDetails(epoch.GetSmr()))
+		errs.appendErr(newError(mpb.VerificationError_INVALID_LOG_INCLUSION,
+			fmt.Sprintf("json.Marshal(): %v", err), epoch.GetSmr()))
 	}
 	leafIndex := epoch.GetSmr().GetMapRevision()
 	treeSize := epoch.GetLogRoot().GetTreeSize()
 	err = m.logVerifier.VerifyInclusionAtIndex(epoch.GetLogRoot(), b, leafIndex, epoch.GetLogInclusion())
 	if err != nil {
 		glog.Errorf("m.logVerifier.VerifyInclusionAtIndex((%v, %v, _): %v", leafIndex, treeSize, err)
-		errs.AppendStatus(status.Newf(codes.DataLoss, "invalid log inclusion: %v", err).WithDetails(epoch))
+		errs.appendErr(newError(mpb.VerificationError_INVALID_LOG_INCLUSION,
+			fmt.Sprintf("invalid log inclusion: %v", err), epoch))
 	}
 
 	// copy of signed map root
@@ -125,53 +255,92 @@ func (m *Monitor) VerifyEpoch(epoch *pb.Epoch) []error {
 	// verify signature on map root:
 	if err := tcrypto.VerifyObject(m.mapPubKey, smr, epoch.GetSmr().GetSignature()); err != nil {
 		glog.Infof("couldn't verify signature on map root: %v", err)
-		errs.AppendStatus(status.Newf(codes.DataLoss, "invalid map signature: %v", err).WithDetails(&smr, epoch.GetSmr().GetSignature()))
+		errs.appendErr(newError(mpb.VerificationError_INVALID_MAP_SIGNATURE,
+			fmt.Sprintf("invalid map signature: %v", err), &smr, epoch.GetSmr().GetSignature()))
 	}
 
 	return errs
 }
 
-func (m *Monitor) verifyMutations(muts []*pb.MutationProof, oldRoot, expectedNewRoot []byte, mapID int64) []error {
+// verifyRootInterval ensures that b was signed within the map's max root
+// duration of a.
+func (m *Monitor) verifyRootInterval(a, b *trillian.SignedMapRoot) error {
+	if m.maxRootDuration <= 0 {
+		return nil
+	}
+	interval := time.Duration(b.GetTimestampNanos() - a.GetTimestampNanos())
+	if interval > m.maxRootDuration {
+		return newError(mpb.VerificationError_STALE_MAP_ROOT,
+			fmt.Sprintf("%v: %v between revisions %v and %v exceeds %v",
+				ErrStaleMapRoot, interval, a.GetMapRevision(), b.GetMapRevision(), m.maxRootDuration), a, b)
+	}
+	return nil
+}
+
+func (m *Monitor) verifyMutations(muts []*pb.MutationProof, policy *pb.KeyPolicy, oldRoot, expectedNewRoot []byte, mapID int64) []error {
 	errs := ErrList{}
-	mutator := entry.New()
+	unknownTypes := false
 	oldProofNodes := make(map[string][]byte)
 	newLeaves := make([]merkle.HStar2LeafHash, 0, len(muts))
 	glog.Infof("verifyMutations() called with %v mutations.", len(muts))
 
+	// verify that the provided leaves' inclusion proofs go to epoch e-1:
+	errs.appendErr(m.verifyLeafProofs(muts, oldRoot, mapID)...)
+
 	for _, mut := range muts {
-		oldLeaf, err := entry.FromLeafValue(mut.GetLeafProof().GetLeaf().GetLeafValue())
+		oldLeaf, err := entry.FromLeafValue(previousLeaf(mut).GetLeafValue())
 		if err != nil {
-			errs.AppendStatus(status.Newf(codes.DataLoss, "could not decode leaf: %v", err).WithDetails(mut.GetLeafProof().GetLeaf()))
+			errs.appendErr(newError(mpb.VerificationError_INVALID_LEAF,
+				fmt.Sprintf("could not decode leaf: %v", err), previousLeaf(mut)))
 		}
 
-		// verify that the provided leaf’s inclusion proof goes to epoch e-1:
 		index := mut.GetLeafProof().GetLeaf().GetIndex()
-		leaf := mut.GetLeafProof().GetLeaf().GetLeafValue()
-		if err := merkle.VerifyMapInclusionProof(mapID, index,
-			leaf, oldRoot, mut.GetLeafProof().GetInclusion(), m.mapHasher); err != nil {
-			glog.Infof("VerifyMapInclusionProof(%x): %v", index, err)
-			errs.AppendStatus(status.Newf(codes.DataLoss, "invalid  map inclusion proof: %v", err).WithDetails(mut.GetLeafProof()))
+
+		// Flag mutation types the monitor does not understand rather than
+		// reporting them as invalid.
+		mutationType := mutator.TypeOf(mut.GetMutation())
+		f, ok := m.mutators.Lookup(mutationType)
+		if !ok {
+			glog.Warningf("Unknown mutation type %q", mutationType)
+			errs.appendErr(newError(mpb.VerificationError_UNKNOWN_MUTATION_TYPE,
+				fmt.Sprintf("unknown mutation type %q", mutationType), mut.GetMutation()))
+			unknownTypes = true
+			continue
 		}
 
 		// compute the new leaf
-		newValue, err := mutator.Mutate(oldLeaf, mut.GetMutation())
+		newValue, err := f.Mutate(oldLeaf, mut.GetMutation())
 		if err != nil {
 			glog.Infof("Mutation did not verify: %v", err)
-			errs.AppendStatus(status.Newf(codes.DataLoss, "invalid mutation: %v", err).WithDetails(mut.GetMutation()))
+			errs.appendErr(newError(mpb.VerificationError_INVALID_MUTATION,
+				fmt.Sprintf("invalid mutation: %v", err), mut.GetMutation()))
+		}
+		// The key server must reject mutations that violate the key policy.
+		if err := entry.CheckKeyPolicy(policy, mut.GetMutation()); err != nil {
+			glog.Warningf("Mutation violates key policy: %v", err)
+			errs.appendErr(newError(mpb.VerificationError_KEY_POLICY_VIOLATION,
+				fmt.Sprintf("%v: %v", ErrKeyPolicyViolation, err), mut.GetMutation()))
 		}
 		newLeafnID := storage.NewNodeIDFromPrefixSuffix(index, storage.Suffix{}, m.mapHasher.BitLen())
 		newLeaf, err := entry.ToLeafValue(newValue)
 		if err != nil {
 			glog.Infof("Failed to serialize: %v", err)
-			errs.AppendStatus(status.Newf(codes.DataLoss, "failed to serialize: %v", err).WithDetails(newValue))
+			errs.appendErr(newError(mpb.VerificationError_INVALID_LEAF,
+				fmt.Sprintf("failed to serialize: %v", err), newValue))
 		}
 
+		// Roots are recomputed from leaf values alone. Committed profile
+		// data is not part of the leaf hash, so purging it on the server
+		// cannot change the roots the monitor verifies; verifyKnownRoot
+		// reports roots that change after they were verified.
+
 		// BUG(gdbelvin): Proto serializations are not idempotent.
 		// - Upgrade the hasher to use ObjectHash.
 		// - Use deep compare between the tree and the computed value.
 		newLeafHash, err := m.mapHasher.HashLeaf(mapID, index, newLeaf)
 		if err != nil {
-			errs.appendErr(err)
+			errs.appendErr(newError(mpb.VerificationError_INVALID_LEAF,
+				fmt.Sprintf("HashLeaf(): %v", err), mut.GetLeafProof().GetLeaf()))
 		}
 		newLeaves = append(newLeaves, merkle.HStar2LeafHash{
 			Index:    newLeafnID.BigInt(),
@@ -198,13 +367,64 @@ func (m *Monitor) verifyMutations(muts []*pb.MutationProof, oldRoot, expectedNew
 		}
 	}
 
-	if err := m.validateMapRoot(expectedNewRoot, mapID, newLeaves, oldProofNodes); err != nil {
+	if unknownTypes {
+		// Without the new value of every leaf the root cannot be recreated.
+		errs.appendErr(ErrUnverifiableMapRoot)
+	} else if err := m.validateMapRoot(expectedNewRoot, mapID, newLeaves, oldProofNodes); err != nil {
 		errs.appendErr(err)
 	}
 
 	return errs
 }
 
+// verifyLeafProofs verifies the inclusion proofs of the leaves that muts
+// operated on against oldRoot. Proofs are verified in parallel in soak mode.
+func (m *Monitor) verifyLeafProofs(muts []*pb.MutationProof, oldRoot []byte, mapID int64) []error {
+	results := make([]error, len(muts))
+	m.parallel(len(muts), func(i int) {
+		leafProof := muts[i].GetLeafProof()
+		index := leafProof.GetLeaf().GetIndex()
+		if err := merkle.VerifyMapInclusionProof(mapID, index,
+			leafProof.GetLeaf().GetLeafValue(), oldRoot, leafProof.GetInclusion(), m.mapHasher); err != nil {
+			glog.Infof("VerifyMapInclusionProof(%x): %v", index, err)
+			results[i] = newError(mpb.VerificationError_INVALID_MAP_INCLUSION,
+				fmt.Sprintf("invalid map inclusion proof: %v", err), leafProof)
+			return
+		}
+		// Entries that move after a VRF rotation modify the leaf at their
+		// previous index.
+		prevIndex := muts[i].GetMutation().GetPreviousIndex()
+		if len(prevIndex) == 0 {
+			return
+		}
+		prevProof := muts[i].GetPreviousLeafProof()
+		if err := merkle.VerifyMapInclusionProof(mapID, prevIndex,
+			prevProof.GetLeaf().GetLeafValue(), oldRoot, prevProof.GetInclusion(), m.mapHasher); err != nil {
+			glog.Infof("VerifyMapInclusionProof(%x): %v", prevIndex, err)
+			results[i] = newError(mpb.VerificationError_INVALID_MAP_INCLUSION,
+				fmt.Sprintf("invalid previous map inclusion proof: %v", err), prevProof)
+		}
+	})
+	errs := ErrList{}
+	for _, err := range results {
+		if err != nil {
+			errs.appendErr(err)
+		}
+	}
+	return errs
+}
+
+// previousLeaf returns the leaf that mut modifies. The first update of an
+// entry after a domain VRF rotation modifies the leaf at its previous index,
+// as long as nothing is stored at its new index yet.
+func previousLeaf(mut *pb.MutationProof) *trillian.MapLeaf {
+	leaf := mut.GetLeafProof().GetLeaf()
+	if leaf.GetLeafValue() == nil && len(mut.GetMutation().GetPreviousIndex()) != 0 {
+		return mut.GetPreviousLeafProof().GetLeaf()
+	}
+	return leaf
+}
+
 func (m *Monitor) validateMapRoot(expectedRoot []byte, mapID int64, mutatedLeaves []merkle.HStar2LeafHash, oldProofNodes map[string][]byte) error {
 	// compute the new root using local intermediate hashes from epoch e
 	// (above proof hashes):
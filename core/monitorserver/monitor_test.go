@@ -20,16 +20,148 @@ package monitorserver
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/crypto/secrets"
 	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 )
 
 func TestGetSignedMapRoot(t *testing.T) {
 	ctx := context.Background()
-	srv := New(fake.NewMonitorStorage())
+	srv := New(fake.NewMonitorStorage(), nil)
 	_, err := srv.GetState(ctx, nil)
 	if got, want := err, ErrNothingProcessed; got != want {
 		t.Errorf("GetSignedMapRoot(_, _): %v, want %v", got, want)
 	}
 }
+
+func TestGetStateByRevision(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewMonitorStorage()
+	verrs := []*pb.VerificationError{
+		{Code: pb.VerificationError_KEY_POLICY_VIOLATION, Message: "key policy violation"},
+		{Code: pb.VerificationError_NOT_MATCHING_MAP_ROOT, Message: "recreated root does not match"},
+	}
+	if err := store.Set(2, &pb.VerificationResult{Revision: 2, SeenTime: ptypes.TimestampNow(), Errors: verrs}); err != nil {
+		t.Fatalf("Set(): %v", err)
+	}
+	srv := New(store, nil)
+
+	if _, err := srv.GetStateByRevision(ctx, &pb.GetStateRequest{Epoch: 1}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetStateByRevision(1): %v, want %v", err, codes.NotFound)
+	}
+	state, err := srv.GetStateByRevision(ctx, &pb.GetStateRequest{Epoch: 2})
+	if err != nil {
+		t.Fatalf("GetStateByRevision(2): %v", err)
+	}
+	if got, want := len(state.GetVerificationErrors()), len(verrs); got != want {
+		t.Fatalf("len(VerificationErrors): %v, want %v", got, want)
+	}
+	for i, want := range []codes.Code{codes.FailedPrecondition, codes.DataLoss} {
+		if got := codes.Code(state.GetErrors()[i].GetCode()); got != want {
+			t.Errorf("Errors[%v].Code: %v, want %v", i, got, want)
+		}
+	}
+}
+
+type fakeKeys []secrets.KeyVersion
+
+func (f fakeKeys) Keys() []secrets.KeyVersion { return f }
+
+func TestGetSigningKeys(t *testing.T) {
+	ctx := context.Background()
+	t1, t2 := time.Unix(1000, 0), time.Unix(2000, 0)
+	for _, tc := range []struct {
+		desc        string
+		keys        KeySource
+		wantCode    codes.Code
+		wantRetired []bool
+	}{
+		{desc: "no key source", wantCode: codes.Unimplemented},
+		{desc: "one key", keys: fakeKeys{{PublicKey: []byte("a"), Activated: t1}}, wantRetired: []bool{false}},
+		{desc: "rotated", keys: fakeKeys{
+			{PublicKey: []byte("a"), Activated: t1, Retired: t2},
+			{PublicKey: []byte("b"), Activated: t2},
+		}, wantRetired: []bool{true, false}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			srv := New(fake.NewMonitorStorage(), tc.keys)
+			resp, err := srv.GetSigningKeys(ctx, &pb.GetSigningKeysRequest{})
+			if got, want := status.Code(err), tc.wantCode; got != want {
+				t.Fatalf("GetSigningKeys(): %v, want %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := len(resp.GetKeys()), len(tc.wantRetired); got != want {
+				t.Fatalf("len(GetSigningKeys().Keys): %v, want %v", got, want)
+			}
+			for i, k := range resp.GetKeys() {
+				if got, want := k.GetRetired() != nil, tc.wantRetired[i]; got != want {
+					t.Errorf("Keys[%v].Retired set: %v, want %v", i, got, want)
+				}
+				if k.GetActivated() == nil || len(k.GetPublicKey().GetDer()) == 0 {
+					t.Errorf("Keys[%v]: %v, want public key and activation time", i, k)
+				}
+			}
+		})
+	}
+}
+
+func TestListFailures(t *testing.T) {
+	ctx := context.Background()
+	store := fake.NewMonitorStorage()
+	verr := []*pb.VerificationError{{Code: pb.VerificationError_NOT_MATCHING_MAP_ROOT}}
+	for epoch, failed := range []bool{false, true, false, true, true} {
+		r := &pb.VerificationResult{Revision: int64(epoch)}
+		if failed {
+			r.Errors = verr
+		}
+		if err := store.Set(int64(epoch), r); err != nil {
+			t.Fatalf("Set(): %v", err)
+		}
+	}
+	srv := New(store, nil)
+
+	for _, tc := range []struct {
+		desc          string
+		req           *pb.ListFailuresRequest
+		wantCode      codes.Code
+		wantRevisions []int64
+		wantNext      int64
+	}{
+		{desc: "all", req: &pb.ListFailuresRequest{}, wantRevisions: []int64{1, 3, 4}},
+		{desc: "first page", req: &pb.ListFailuresRequest{PageSize: 2}, wantRevisions: []int64{1, 3}, wantNext: 4},
+		{desc: "next page", req: &pb.ListFailuresRequest{Start: 4, PageSize: 2}, wantRevisions: []int64{4}},
+		{desc: "past latest", req: &pb.ListFailuresRequest{Start: 5}},
+		{desc: "negative start", req: &pb.ListFailuresRequest{Start: -1}, wantCode: codes.InvalidArgument},
+		{desc: "negative page size", req: &pb.ListFailuresRequest{PageSize: -1}, wantCode: codes.InvalidArgument},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp, err := srv.ListFailures(ctx, tc.req)
+			if got, want := status.Code(err), tc.wantCode; got != want {
+				t.Fatalf("ListFailures(): %v, want %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			var revisions []int64
+			for _, r := range resp.GetResults() {
+				revisions = append(revisions, r.GetRevision())
+			}
+			if got, want := fmt.Sprint(revisions), fmt.Sprint(tc.wantRevisions); got != want {
+				t.Errorf("ListFailures() revisions: %v, want %v", got, want)
+			}
+			if got, want := resp.GetNextStart(), tc.wantNext; got != want {
+				t.Errorf("ListFailures().NextStart: %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -29,10 +29,13 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/crypto/secrets"
 	"github.com/google/keytransparency/core/monitor"
 	"github.com/google/keytransparency/core/monitorstorage"
+	"github.com/google/trillian/crypto/keyspb"
 
 	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
 )
 
 var (
@@ -41,16 +44,33 @@ var (
 	ErrNothingProcessed = errors.New("did not process any mutations yet")
 )
 
+const (
+	// defaultPageSize is the number of results ListFailures returns if the
+	// request does not set a page size.
+	defaultPageSize = int32(16)
+	// maxPageSize is the largest number of results ListFailures returns.
+	maxPageSize = int32(1024)
+)
+
+// KeySource lists the keys the monitor has signed map roots with.
+// *secrets.Keyring implements it.
+type KeySource interface {
+	Keys() []secrets.KeyVersion
+}
+
 // Server holds internal state for the monitor server. It serves monitoring
 // responses via a grpc and HTTP API.
 type Server struct {
 	storage monitorstorage.Interface
+	keys    KeySource
 }
 
-// New creates a new instance of the monitor server.
-func New(storage monitorstorage.Interface) *Server {
+// New creates a new instance of the monitor server. keys may be nil, in which
+// case GetSigningKeys is not available.
+func New(storage monitorstorage.Interface, keys KeySource) *Server {
 	return &Server{
 		storage: storage,
+		keys:    keys,
 	}
 }
 
@@ -83,19 +103,93 @@ func (s *Server) GetStateByRevision(ctx context.Context, in *pb.GetStateRequest)
 
 func (s *Server) getResponseByRevision(epoch int64) (*pb.State, error) {
 	r, err := s.storage.Get(epoch)
-	if err == monitorstorage.ErrNotFound {
+	if errors.Is(err, monitorstorage.ErrNotFound) {
 		return nil, status.Errorf(codes.NotFound, "Could not find monitoring response for epoch %d", epoch)
 	}
 
-	errs := monitor.ErrList(r.Errors)
-	seen, err := ptypes.TimestampProto(r.Seen)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "invalid timestamp: %v", err)
+		return nil, status.Errorf(codes.Internal, "could not read monitoring response for epoch %d: %v", epoch, err)
+	}
+
+	// Convert errors into rpc.Status for clients that predate
+	// verification_errors.
+	errs := make([]*statuspb.Status, 0, len(r.GetErrors()))
+	for _, v := range r.GetErrors() {
+		errs = append(errs, monitor.StatusProto(v))
 	}
-	// Convert errors into rpc.Status
 	return &pb.State{
-		Smr:      r.Smr,
-		SeenTime: seen,
-		Errors:   errs.Proto(),
+		Smr:                r.GetSmr(),
+		SeenTime:           r.GetSeenTime(),
+		Errors:             errs,
+		VerificationErrors: r.GetErrors(),
 	}, nil
 }
+
+// GetSigningKeys returns the keys the monitor signs map roots with, including
+// the keys it retired.
+func (s *Server) GetSigningKeys(ctx context.Context, in *pb.GetSigningKeysRequest) (*pb.GetSigningKeysResponse, error) {
+	if s.keys == nil {
+		return nil, status.Errorf(codes.Unimplemented, "monitor does not publish its signing keys")
+	}
+	versions := s.keys.Keys()
+	resp := &pb.GetSigningKeysResponse{Keys: make([]*pb.SigningKey, 0, len(versions))}
+	for _, v := range versions {
+		activated, err := ptypes.TimestampProto(v.Activated)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid timestamp: %v", err)
+		}
+		k := &pb.SigningKey{
+			PublicKey: &keyspb.PublicKey{Der: v.PublicKey},
+			Activated: activated,
+		}
+		if !v.Retired.IsZero() {
+			if k.Retired, err = ptypes.TimestampProto(v.Retired); err != nil {
+				return nil, status.Errorf(codes.Internal, "invalid timestamp: %v", err)
+			}
+		}
+		resp.Keys = append(resp.Keys, k)
+	}
+	return resp, nil
+}
+
+// ListFailures returns the monitor's results for the revisions from in.Start
+// onwards that failed verification, ordered by revision. Clients and other
+// monitors use them to repeat the failed checks.
+func (s *Server) ListFailures(ctx context.Context, in *pb.ListFailuresRequest) (*pb.ListFailuresResponse, error) {
+	if in.GetStart() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "start=%v, want >= 0", in.GetStart())
+	}
+	pageSize := in.GetPageSize()
+	switch {
+	case pageSize < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size=%v, want >= 0", pageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	resp := &pb.ListFailuresResponse{}
+	latest := s.storage.LatestEpoch()
+	for epoch := in.GetStart(); epoch <= latest; epoch++ {
+		if err := ctx.Err(); err != nil {
+			return nil, status.Errorf(codes.Canceled, "%v", err)
+		}
+		r, err := s.storage.Get(epoch)
+		if errors.Is(err, monitorstorage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "could not read monitoring response for epoch %d: %v", epoch, err)
+		}
+		if len(r.GetErrors()) == 0 {
+			continue
+		}
+		if int32(len(resp.Results)) == pageSize {
+			resp.NextStart = epoch
+			break
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
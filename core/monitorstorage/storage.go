@@ -17,9 +17,10 @@ package monitorstorage
 
 import (
 	"errors"
-	"time"
 
 	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
 )
 
 var (
@@ -31,33 +32,34 @@ var (
 	ErrNotFound = errors.New("data for epoch not found")
 )
 
-// Result describes the monitor's attempt to verify the complete transition from
-// SignedMapRoot (SMR) revision T to revision T+1, having been supplied the set
-// of mutations by which to transform SMR T into SMR T+1.
-//
-// Result contains the monitor's signature on SMR T if the monitor believes that
-// the transition from T to T+1 is fully correct. It also contains that time at which
-// the monitor observed SMR, and in cases where the verification did not succeed, a
-// list of errors describing the observed problems, and a collection of data by which
-// others can attempt the same verification.
-type Result struct {
-	// Smr contains the map root signed by the monitor in case all verifications
-	// have passed.
-	Smr *trillian.SignedMapRoot
-	// Seen is the timestamp at which the mutations response has been received.
-	Seen time.Time
-	// Errors contains a string representation of the verifications steps that
-	// failed.
-	Errors []error
+// State is the monitor's progress through a domain, which it persists so that
+// it can resume after a restart without verifying the history again.
+type State struct {
+	// Revision is the last map revision the monitor processed.
+	Revision int64
+	// Trusted is the latest log root the monitor verified.
+	Trusted *trillian.SignedLogRoot
 }
 
 // Interface is the interface that stores and retrieves monitoring results.
+//
+// A result describes the monitor's attempt to verify the complete transition
+// from SignedMapRoot (SMR) revision T to revision T+1, having been supplied the
+// set of mutations by which to transform SMR T into SMR T+1. It contains the
+// monitor's signature on SMR T+1 if the monitor believes that the transition
+// is fully correct, and otherwise the checks that failed along with the data
+// by which others can attempt the same verification.
 // TODO(gbelvin): make multi-tenant.
 type Interface interface {
 	// Set stores the monitoring result for a specific epoch.
-	Set(epoch int64, r *Result) error
+	Set(epoch int64, r *pb.VerificationResult) error
 	// Get retrieves the monitoring result for a specific epoch.
-	Get(epoch int64) (*Result, error)
+	Get(epoch int64) (*pb.VerificationResult, error)
 	// LatestEpoch returns the highest numbered epoch that has been processed.
 	LatestEpoch() int64
+	// SetState replaces the monitor's saved progress.
+	SetState(s *State) error
+	// State returns the monitor's saved progress. It returns ErrNotFound if
+	// no progress has been saved.
+	State() (*State, error)
 }
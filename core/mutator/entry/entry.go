@@ -16,6 +16,8 @@
 package entry
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/google/keytransparency/core/crypto/signatures"
@@ -45,6 +47,77 @@ func FromLeafValue(value []byte) (*pb.Entry, error) {
 	return nil, nil
 }
 
+// commitmentField is the field number of Entry.commitment.
+const commitmentField = 6
+
+// ErrMalformedLeaf occurs when a leaf value is not a valid Entry.
+var ErrMalformedLeaf = errors.New("malformed leaf value")
+
+// CommitmentFromLeafValue returns the commitment of the Entry encoded in
+// value without decoding the rest of the Entry. ok is false if value is nil.
+// The returned commitment aliases value.
+//
+// Only the top level of the Entry is parsed, so verifiers that just need the
+// commitment avoid the allocations of FromLeafValue.
+func CommitmentFromLeafValue(value []byte) (commitment []byte, ok bool, err error) {
+	if value == nil {
+		return nil, false, nil
+	}
+	if err := walkFields(value, func(key uint64, field []byte) error {
+		if key == commitmentField<<3|2 {
+			commitment = field
+		}
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+	return commitment, true, nil
+}
+
+// walkFields calls f with the key and, for length delimited fields, the
+// contents of each top level field of the message encoded in value. It
+// returns ErrMalformedLeaf if value is not a valid encoding.
+func walkFields(value []byte, f func(key uint64, field []byte) error) error {
+	for len(value) > 0 {
+		key, n := binary.Uvarint(value)
+		if n <= 0 || key>>3 == 0 { // Field numbers start at 1.
+			return ErrMalformedLeaf
+		}
+		value = value[n:]
+		var field []byte
+		switch key & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(value); n <= 0 {
+				return ErrMalformedLeaf
+			}
+			value = value[n:]
+		case 1: // fixed64
+			if len(value) < 8 {
+				return ErrMalformedLeaf
+			}
+			value = value[8:]
+		case 2: // length delimited
+			l, n := binary.Uvarint(value)
+			if n <= 0 || l > uint64(len(value)-n) {
+				return ErrMalformedLeaf
+			}
+			field = value[n : n+int(l)]
+			value = value[n+int(l):]
+		case 5: // fixed32
+			if len(value) < 4 {
+				return ErrMalformedLeaf
+			}
+			value = value[4:]
+		default:
+			return ErrMalformedLeaf
+		}
+		if err := f(key, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ToLeafValue converts the update object into a serialized object to store in the map.
 func ToLeafValue(update proto.Message) ([]byte, error) {
 	e, ok := update.(*pb.Entry)
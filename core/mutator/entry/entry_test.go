@@ -15,6 +15,7 @@
 package entry
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/google/keytransparency/core/crypto/dev"
@@ -110,3 +111,82 @@ func TestFromLeafValue(t *testing.T) {
 		}
 	}
 }
+
+func TestCommitmentFromLeafValue(t *testing.T) {
+	entry := &pb.Entry{
+		Index:          []byte{1},
+		Commitment:     []byte{1, 2},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+		Previous:       []byte{3},
+	}
+	entryB, err := proto.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		leafVal []byte
+		want    []byte
+		wantOK  bool
+		wantErr bool
+	}{
+		{desc: "empty", leafVal: []byte{}, wantOK: true},
+		{desc: "absent", leafVal: nil},
+		{desc: "invalid", leafVal: []byte{2, 2, 2, 2, 2, 2, 2}, wantErr: true},
+		{desc: "truncated", leafVal: entryB[:len(entryB)-1], wantErr: true},
+		{desc: "field 0", leafVal: append([]byte{0<<3 | 0, 1}, entryB...), wantErr: true},
+		{desc: "valid", leafVal: entryB, want: entry.Commitment, wantOK: true},
+	} {
+		got, ok, err := CommitmentFromLeafValue(tc.leafVal)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%v: CommitmentFromLeafValue(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if ok != tc.wantOK || !bytes.Equal(got, tc.want) {
+			t.Errorf("%v: CommitmentFromLeafValue(): %x, %v, want %x, %v", tc.desc, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestCommitmentFromLeafValueAllocs(t *testing.T) {
+	entryB, err := proto.Marshal(&pb.Entry{
+		Commitment:     []byte{1, 2},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testing.AllocsPerRun(100, func() {
+		if _, _, err := CommitmentFromLeafValue(entryB); err != nil {
+			t.Fatal(err)
+		}
+	}); got > 0 {
+		t.Errorf("CommitmentFromLeafValue() allocs: %v, want 0", got)
+	}
+}
+
+func BenchmarkFromLeafValue(b *testing.B) {
+	entryB, err := proto.Marshal(&pb.Entry{
+		Commitment:     []byte{1, 2},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("entry", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := FromLeafValue(entryB); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("commitment", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := CommitmentFromLeafValue(entryB); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build gofuzz
+
+package entry
+
+import "bytes"
+
+// Fuzz is the go-fuzz entry point for the leaf value parsers. A leaf value
+// that FromLeafValueStrict accepts must have the same commitment when
+// parsed by CommitmentFromLeafValue.
+func Fuzz(data []byte) int {
+	e, err := FromLeafValueStrict(data, DefaultLimits)
+	commitment, _, cErr := CommitmentFromLeafValue(data)
+	if err != nil {
+		return 0
+	}
+	if cErr != nil {
+		panic(cErr)
+	}
+	if !bytes.Equal(commitment, e.GetCommitment()) {
+		panic("commitments differ")
+	}
+	return 1
+}
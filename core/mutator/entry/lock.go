@@ -0,0 +1,149 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/mutator"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ErrKeptFields occurs when a revoke, freeze or policy mutation changes a
+// field of the entry that its type must keep.
+var ErrKeptFields = errors.New("mutation changes fields its type must keep")
+
+// RevokeMutator defines mutations that revoke all authorized keys of an
+// entry and commit to an empty profile, which the key server checks. The
+// recovery keys are kept, so that the entry can later be reset.
+type RevokeMutator struct{}
+
+// NewRevoke creates a new revoke mutator.
+func NewRevoke() *RevokeMutator {
+	return &RevokeMutator{}
+}
+
+// Mutate verifies that update is a valid revocation of oldValue and applies
+// it. OldValue and update are both Entry protos.
+func (*RevokeMutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
+	oldEntry, newEntry, err := checkKeyed(oldValue, update, mutator.TypeRevoke)
+	if err != nil {
+		return nil, err
+	}
+	if len(newEntry.GetAuthorizedKeys()) != 0 ||
+		!equalKeys(oldEntry.GetRecoveryKeys(), newEntry.GetRecoveryKeys()) {
+		glog.Warningf("revocation keeps authorized keys or changes recovery keys")
+		return nil, ErrKeptFields
+	}
+	return newEntry, nil
+}
+
+// FreezeMutator defines mutations that freeze an entry. A frozen entry keeps
+// its authorized keys, recovery keys and profile, and only accepts resets.
+type FreezeMutator struct{}
+
+// NewFreeze creates a new freeze mutator.
+func NewFreeze() *FreezeMutator {
+	return &FreezeMutator{}
+}
+
+// Mutate verifies that update is a valid freeze of oldValue and applies it.
+// OldValue and update are both Entry protos.
+func (*FreezeMutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
+	oldEntry, newEntry, err := checkKeyed(oldValue, update, mutator.TypeFreeze)
+	if err != nil {
+		return nil, err
+	}
+	if !equalKeys(oldEntry.GetAuthorizedKeys(), newEntry.GetAuthorizedKeys()) ||
+		!equalKeys(oldEntry.GetRecoveryKeys(), newEntry.GetRecoveryKeys()) ||
+		!bytes.Equal(oldEntry.GetCommitment(), newEntry.GetCommitment()) {
+		glog.Warningf("freeze changes the entry")
+		return nil, ErrKeptFields
+	}
+	return newEntry, nil
+}
+
+// PolicyMutator defines mutations that replace the recovery keys of an
+// entry, keeping its authorized keys and profile.
+type PolicyMutator struct{}
+
+// NewPolicy creates a new policy mutator.
+func NewPolicy() *PolicyMutator {
+	return &PolicyMutator{}
+}
+
+// Mutate verifies that update is a valid policy change of oldValue and
+// applies it. OldValue and update are both Entry protos.
+func (*PolicyMutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
+	oldEntry, newEntry, err := checkKeyed(oldValue, update, mutator.TypePolicy)
+	if err != nil {
+		return nil, err
+	}
+	if !equalKeys(oldEntry.GetAuthorizedKeys(), newEntry.GetAuthorizedKeys()) ||
+		!bytes.Equal(oldEntry.GetCommitment(), newEntry.GetCommitment()) {
+		glog.Warningf("policy change modifies authorized keys or profile")
+		return nil, ErrKeptFields
+	}
+	// Recovery keys may only sign resets.
+	if containsKey(newEntry.GetAuthorizedKeys(), newEntry.GetRecoveryKeys()) {
+		glog.Warningf("policy change authorizes a recovery key")
+		return nil, mutator.ErrRecoveryKey
+	}
+	return newEntry, nil
+}
+
+// checkKeyed verifies that update is a mutation of mutationType that follows
+// oldValue, which must not be locked, and is signed by one of the authorized
+// keys of oldValue. The checks that differ between types are left to the
+// caller.
+func checkKeyed(oldValue, update proto.Message, mutationType string) (oldEntry, newEntry *pb.Entry, err error) {
+	if proto.Size(update) > mutator.MaxMutationSize {
+		glog.Warningf("mutation (%v bytes) is larger than the maximum accepted size (%v bytes).", proto.Size(update), mutator.MaxMutationSize)
+		return nil, nil, mutator.ErrSize
+	}
+	newEntry, ok := update.(*pb.Entry)
+	if !ok {
+		glog.Warning("received proto.Message is not of type *pb.Entry.")
+		return nil, nil, fmt.Errorf("updateM.(*pb.Entry): _, %v", ok)
+	}
+	if t := mutator.TypeOf(newEntry); t != mutationType {
+		glog.Warningf("mutation type %q is not %q.", t, mutationType)
+		return nil, nil, mutator.ErrUnknownType
+	}
+	oldEntry, _ = oldValue.(*pb.Entry)
+	if mutator.Locked(oldEntry) {
+		glog.Warningf("%v of a revoked or frozen entry", mutationType)
+		return nil, nil, mutator.ErrLocked
+	}
+	if err := checkPrevious(oldEntry, newEntry); err != nil {
+		return nil, nil, err
+	}
+
+	verifiers, err := verifiersFromKeys(oldEntry.GetAuthorizedKeys())
+	if err != nil {
+		return nil, nil, err
+	}
+	kv := *newEntry
+	kv.Signatures = nil
+	if err := verifyAuthorizedKeys(kv, verifiers, newEntry.GetSignatures()); err != nil {
+		return nil, nil, err
+	}
+	return oldEntry, newEntry, nil
+}
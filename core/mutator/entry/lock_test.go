@@ -0,0 +1,133 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"testing"
+
+	"github.com/google/keytransparency/core/crypto/commitments"
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/keytransparency/core/mutator"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestLock(t *testing.T) {
+	authorized := signersFromPEMs(t, [][]byte{[]byte(testPrivKey1)})
+	recovery := signersFromPEMs(t, [][]byte{[]byte(testPrivKey2)})
+	nonce, err := commitments.GenCommitmentKey()
+	if err != nil {
+		t.Fatalf("GenCommitmentKey(): %v", err)
+	}
+	committed := &pb.Committed{Key: nonce, Data: []byte("profile")}
+	prev := &pb.Entry{
+		Index:          []byte("index"),
+		Commitment:     commitments.Commit("alice", "app1", committed.Data, nonce),
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+		RecoveryKeys:   mustPublicKeys([]string{testPubKey2}),
+		Previous:       nilHash[:],
+	}
+	frozen := *prev
+	frozen.MutationType = mutator.TypeFreeze
+	revoked := *prev
+	revoked.AuthorizedKeys = nil
+	revoked.MutationType = mutator.TypeRevoke
+
+	// newMutation returns a mutation of old made by change, then modified
+	// by edit.
+	newMutation := func(old *pb.Entry, change func(*Mutation) error, edit func(*pb.Entry)) *Mutation {
+		leaf, err := ToLeafValue(old)
+		if err != nil {
+			t.Fatalf("ToLeafValue(): %v", err)
+		}
+		m := NewMutation([]byte("index"), domainID, "app1", "alice")
+		if err := m.SetPrevious(leaf, true); err != nil {
+			t.Fatalf("SetPrevious(): %v", err)
+		}
+		if err := change(m); err != nil {
+			t.Fatalf("change(): %v", err)
+		}
+		if edit != nil {
+			edit(m.entry)
+		}
+		return m
+	}
+	revoke := func(m *Mutation) error { return m.Revoke() }
+	freeze := func(m *Mutation) error { return m.Freeze(committed) }
+	policy := func(m *Mutation) error { return m.ChangeRecoveryKeys(nil, committed) }
+	update := func(m *Mutation) error { return m.SetCommitment([]byte("new profile")) }
+	reset := func(m *Mutation) error { return m.ResetAuthorizedKeys(mustPublicKeys([]string{testPubKey1})) }
+
+	for _, tc := range []struct {
+		desc     string
+		old      *pb.Entry
+		mutation *Mutation
+		signers  []signatures.Signer
+		want     error
+	}{
+		{desc: "revoke", old: prev, mutation: newMutation(prev, revoke, nil), signers: authorized},
+		{desc: "revoke by recovery key", old: prev, mutation: newMutation(prev, revoke, nil), signers: recovery, want: mutator.ErrUnauthorized},
+		{desc: "revoke keeping keys", old: prev, mutation: newMutation(prev, revoke, func(e *pb.Entry) {
+			e.AuthorizedKeys = prev.AuthorizedKeys
+		}), signers: authorized, want: ErrKeptFields},
+		{desc: "freeze", old: prev, mutation: newMutation(prev, freeze, nil), signers: authorized},
+		{desc: "freeze changing profile", old: prev, mutation: newMutation(prev, freeze, func(e *pb.Entry) {
+			e.Commitment = []byte{1}
+		}), signers: authorized, want: ErrKeptFields},
+		{desc: "policy", old: prev, mutation: newMutation(prev, policy, nil), signers: authorized},
+		{desc: "policy changing keys", old: prev, mutation: newMutation(prev, policy, func(e *pb.Entry) {
+			e.AuthorizedKeys = mustPublicKeys([]string{testPubKey2})
+		}), signers: authorized, want: ErrKeptFields},
+		{desc: "policy authorizing recovery key", old: prev, mutation: newMutation(prev, policy, func(e *pb.Entry) {
+			e.RecoveryKeys = prev.AuthorizedKeys
+		}), signers: authorized, want: mutator.ErrRecoveryKey},
+		{desc: "update of frozen", old: &frozen, mutation: newMutation(&frozen, update, nil), signers: authorized, want: mutator.ErrLocked},
+		{desc: "policy of frozen", old: &frozen, mutation: newMutation(&frozen, policy, nil), signers: authorized, want: mutator.ErrLocked},
+		{desc: "update of revoked", old: &revoked, mutation: newMutation(&revoked, update, func(e *pb.Entry) {
+			e.AuthorizedKeys = mustPublicKeys([]string{testPubKey1})
+		}), signers: authorized, want: mutator.ErrLocked},
+		{desc: "reset of frozen", old: &frozen, mutation: newMutation(&frozen, reset, nil), signers: recovery},
+		{desc: "reset of revoked", old: &revoked, mutation: newMutation(&revoked, reset, nil), signers: recovery},
+	} {
+		m, err := tc.mutation.sign(tc.signers)
+		if err != nil {
+			t.Fatalf("%v: sign(): %v", tc.desc, err)
+		}
+		if _, got := NewRegistry().Mutate(tc.old, m); got != tc.want {
+			t.Errorf("%v: Mutate(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestKeepProfile(t *testing.T) {
+	prev := &pb.Entry{
+		Commitment:     commitments.Commit("alice", "app1", []byte("profile"), make([]byte, 16)),
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+	}
+	leaf, err := ToLeafValue(prev)
+	if err != nil {
+		t.Fatalf("ToLeafValue(): %v", err)
+	}
+	m := NewMutation([]byte("index"), domainID, "app1", "alice")
+	if err := m.SetPrevious(leaf, true); err != nil {
+		t.Fatalf("SetPrevious(): %v", err)
+	}
+	if err := m.Freeze(&pb.Committed{Key: make([]byte, 16), Data: []byte("other")}); err == nil {
+		t.Errorf("Freeze() with the wrong profile: nil, want error")
+	}
+	if err := m.ChangeRecoveryKeys(prev.AuthorizedKeys, &pb.Committed{Key: make([]byte, 16), Data: []byte("profile")}); err != mutator.ErrRecoveryKey {
+		t.Errorf("ChangeRecoveryKeys(authorized keys): %v, want %v", err, mutator.ErrRecoveryKey)
+	}
+}
@@ -15,6 +15,7 @@
 package entry
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
@@ -32,6 +33,13 @@ import (
 
 var nilHash, _ = objecthash.ObjectHash(nil)
 
+// ErrIncompatiblePrevious occurs when a mutation cannot be rebased onto a new
+// previous entry because the authorized keys of the entry have changed.
+var ErrIncompatiblePrevious = errors.New("previous entry has different authorized keys")
+
+// ErrNoRecoveryKeys occurs when resetting an entry that has no recovery keys.
+var ErrNoRecoveryKeys = errors.New("previous entry has no recovery keys")
+
 // Mutation provides APIs for manipulating entries.
 type Mutation struct {
 	domainID, appID, userID string
@@ -46,6 +54,8 @@ type Mutation struct {
 // - Create a new mutation for a user starting with the previous value with NewMutation.
 // - Change the value with SetCommitment and ReplaceAuthorizedKeys.
 // - Finalize the changes and create the mutation with SerializeAndSign.
+// Mutations signed by keys held remotely are finalized with Serialize and
+// AttachSignatures instead.
 func NewMutation(index []byte, domainID, appID, userID string) *Mutation {
 	return &Mutation{
 		domainID: domainID,
@@ -58,8 +68,32 @@ func NewMutation(index []byte, domainID, appID, userID string) *Mutation {
 	}
 }
 
+// FromRequest returns the mutation that the signed request req applies to the
+// entry with leaf value prevLeaf, so that a request that was saved can be
+// checked, rebased and signed again.
+func FromRequest(req *pb.UpdateEntryRequest, prevLeaf []byte) (*Mutation, error) {
+	prevEntry, err := FromLeafValue(prevLeaf)
+	if err != nil {
+		return nil, err
+	}
+	e := req.GetEntryUpdate().GetMutation()
+	if e == nil {
+		return nil, errors.New("request has no mutation")
+	}
+	return &Mutation{
+		domainID:  req.GetDomainId(),
+		appID:     req.GetAppId(),
+		userID:    req.GetUserId(),
+		data:      req.GetEntryUpdate().GetCommitted().GetData(),
+		nonce:     req.GetEntryUpdate().GetCommitted().GetKey(),
+		prevEntry: prevEntry,
+		entry:     proto.Clone(e).(*pb.Entry),
+	}, nil
+}
+
 // SetPrevious sets the previous hash.
-// If copyPrevious is true, AuthorizedKeys and Commitment are also copied.
+// If copyPrevious is true, AuthorizedKeys, Commitment and RecoveryKeys are
+// also copied.
 func (m *Mutation) SetPrevious(oldValue []byte, copyPrevious bool) error {
 	prevEntry, err := FromLeafValue(oldValue)
 	if err != nil {
@@ -80,10 +114,52 @@ func (m *Mutation) SetPrevious(oldValue []byte, copyPrevious bool) error {
 	if copyPrevious {
 		m.entry.AuthorizedKeys = prevEntry.GetAuthorizedKeys()
 		m.entry.Commitment = prevEntry.GetCommitment()
+		m.entry.RecoveryKeys = prevEntry.GetRecoveryKeys()
 	}
 	return nil
 }
 
+// MoveIndex moves the entry to index, which must be its index under the
+// current domain VRF. The entry's index under the previous VRF is recorded in
+// previous_index, and the leaf stored there stays the previous entry.
+func (m *Mutation) MoveIndex(index []byte) {
+	m.entry.PreviousIndex = m.entry.Index
+	m.entry.Index = index
+}
+
+// PreviousChanged returns true if newLeaf is neither the previous entry this
+// mutation modifies nor the entry this mutation produces.
+func (m *Mutation) PreviousChanged(newLeaf []byte) (bool, error) {
+	leafValue, err := FromLeafValue(newLeaf)
+	if err != nil {
+		return false, err
+	}
+	return !proto.Equal(leafValue, m.prevEntry) && !proto.Equal(leafValue, m.entry), nil
+}
+
+// Rebase updates the mutation to modify newLeaf rather than its current
+// previous entry. The authorized keys of newLeaf, or its recovery keys for a
+// reset, must match those of the current previous entry so that the
+// mutation's signers remain authorized.
+// The mutation must be signed again with SerializeAndSign after a rebase.
+func (m *Mutation) Rebase(newLeaf []byte) error {
+	leafValue, err := FromLeafValue(newLeaf)
+	if err != nil {
+		return err
+	}
+	oldKeys := m.prevEntry.GetAuthorizedKeys()
+	newKeys := leafValue.GetAuthorizedKeys()
+	if mutator.TypeOf(m.entry) == mutator.TypeReset {
+		// Resets are signed by recovery keys instead.
+		oldKeys = m.prevEntry.GetRecoveryKeys()
+		newKeys = leafValue.GetRecoveryKeys()
+	}
+	if !equalKeys(oldKeys, newKeys) {
+		return ErrIncompatiblePrevious
+	}
+	return m.SetPrevious(newLeaf, false)
+}
+
 // SetCommitment updates entry to be a commitment to data.
 func (m *Mutation) SetCommitment(data []byte) error {
 	// Commit to profile.
@@ -107,27 +183,134 @@ func (m *Mutation) ReplaceAuthorizedKeys(pubkeys []*keyspb.PublicKey) error {
 	return nil
 }
 
+// SetRecoveryKeys designates pubkeys as the keys that may reset the entry
+// with ResetAuthorizedKeys. No recovery keys disables resets.
+func (m *Mutation) SetRecoveryKeys(pubkeys []*keyspb.PublicKey) {
+	m.entry.RecoveryKeys = pubkeys
+}
+
+// ResetAuthorizedKeys turns the mutation into a reset, which replaces the
+// authorized keys of the entry with pubkeys and clears its profile. The reset
+// must be signed by a recovery key of the previous entry set by SetPrevious.
+// pubkeys must contain at least one key and none of the recovery keys.
+func (m *Mutation) ResetAuthorizedKeys(pubkeys []*keyspb.PublicKey) error {
+	if len(m.prevEntry.GetRecoveryKeys()) == 0 {
+		return ErrNoRecoveryKeys
+	}
+	if containsKey(pubkeys, m.prevEntry.GetRecoveryKeys()) {
+		return mutator.ErrRecoveryKey
+	}
+	if err := m.ReplaceAuthorizedKeys(pubkeys); err != nil {
+		return err
+	}
+	if err := m.SetCommitment(nil); err != nil {
+		return err
+	}
+	m.entry.RecoveryKeys = m.prevEntry.GetRecoveryKeys()
+	m.entry.MutationType = mutator.TypeReset
+	return nil
+}
+
+// Revoke turns the mutation into a revocation, which removes all authorized
+// keys of the entry and clears its profile. The revocation must be signed by
+// an authorized key of the previous entry set by SetPrevious. Only a reset by
+// one of the recovery keys can restore the entry.
+func (m *Mutation) Revoke() error {
+	if err := m.SetCommitment(nil); err != nil {
+		return err
+	}
+	m.entry.AuthorizedKeys = nil
+	m.entry.RecoveryKeys = m.prevEntry.GetRecoveryKeys()
+	m.entry.MutationType = mutator.TypeRevoke
+	return nil
+}
+
+// Freeze turns the mutation into a freeze, which keeps the previous entry set
+// by SetPrevious as it is until it is reset. committed opens the commitment
+// of the previous entry, as returned by GetEntry.
+func (m *Mutation) Freeze(committed *pb.Committed) error {
+	if err := m.keepProfile(committed); err != nil {
+		return err
+	}
+	m.entry.AuthorizedKeys = m.prevEntry.GetAuthorizedKeys()
+	m.entry.RecoveryKeys = m.prevEntry.GetRecoveryKeys()
+	m.entry.MutationType = mutator.TypeFreeze
+	return nil
+}
+
+// ChangeRecoveryKeys turns the mutation into a policy change, which replaces
+// the recovery keys of the previous entry set by SetPrevious with pubkeys and
+// keeps everything else. committed opens the commitment of the previous
+// entry, as returned by GetEntry.
+func (m *Mutation) ChangeRecoveryKeys(pubkeys []*keyspb.PublicKey, committed *pb.Committed) error {
+	if containsKey(m.prevEntry.GetAuthorizedKeys(), pubkeys) {
+		return mutator.ErrRecoveryKey
+	}
+	if err := m.keepProfile(committed); err != nil {
+		return err
+	}
+	m.entry.AuthorizedKeys = m.prevEntry.GetAuthorizedKeys()
+	m.entry.RecoveryKeys = pubkeys
+	m.entry.MutationType = mutator.TypePolicy
+	return nil
+}
+
+// keepProfile commits the mutation to the profile of the previous entry,
+// which committed opens.
+func (m *Mutation) keepProfile(committed *pb.Committed) error {
+	commitment := m.prevEntry.GetCommitment()
+	if err := commitments.Verify(m.userID, m.appID, commitment, committed.GetData(), committed.GetKey()); err != nil {
+		return err
+	}
+	m.data = committed.GetData()
+	m.nonce = committed.GetKey()
+	m.entry.Commitment = commitment
+	return nil
+}
+
 // SerializeAndSign produces the mutation.
 func (m *Mutation) SerializeAndSign(signers []signatures.Signer, trustedTreeSize int64) (*pb.UpdateEntryRequest, error) {
-	mutation, err := m.sign(signers)
+	sigs, err := signEntry(m.Serialize(), signers)
 	if err != nil {
 		return nil, err
 	}
+	return m.AttachSignatures(sigs, trustedTreeSize)
+}
+
+// Serialize returns the entry the mutation produces, without signatures.
+// Signers that hold their keys elsewhere, such as in an HSM, sign it, and
+// their signatures are then attached with AttachSignatures. The mutation must
+// not be changed in between.
+func (m *Mutation) Serialize() *pb.Entry {
+	e := proto.Clone(m.entry).(*pb.Entry)
+	e.Signatures = nil
+	return e
+}
 
-	// Check authorization.
-	skv := *mutation
-	skv.Signatures = nil
-	if err := verifyKeys(m.prevEntry.GetAuthorizedKeys(),
-		m.entry.GetAuthorizedKeys(),
-		skv,
-		mutation.GetSignatures()); err != nil {
-		return nil, fmt.Errorf("verifyKeys(prevauth: %v, newauth: %v, sig: %v): %v",
-			len(m.prevEntry.GetAuthorizedKeys()), len(m.entry.GetAuthorizedKeys()), len(mutation.GetSignatures()), err)
+// AttachSignatures produces the mutation signed with sigs, which are keyed by
+// the key ID of their signer. The signatures must be of the entry returned by
+// Serialize.
+func (m *Mutation) AttachSignatures(sigs map[string]*sigpb.DigitallySigned, trustedTreeSize int64) (*pb.UpdateEntryRequest, error) {
+	m.entry.Signatures = sigs
+	mutation := m.entry
+
+	// Check authorization. Resets are authorized by recovery keys instead,
+	// which the sanity check below verifies.
+	if mutator.TypeOf(mutation) == mutator.TypeUpdate {
+		skv := *mutation
+		skv.Signatures = nil
+		if err := verifyKeys(m.prevEntry.GetAuthorizedKeys(),
+			m.entry.GetAuthorizedKeys(),
+			skv,
+			mutation.GetSignatures()); err != nil {
+			return nil, fmt.Errorf("verifyKeys(prevauth: %v, newauth: %v, sig: %v): %v",
+				len(m.prevEntry.GetAuthorizedKeys()), len(m.entry.GetAuthorizedKeys()), len(mutation.GetSignatures()), err)
+		}
 	}
 
 	// Sanity check the mutation's correctness.
-	if _, err := New().Mutate(m.prevEntry, mutation); err != nil {
-		return nil, fmt.Errorf("presign mutation check: %v", err)
+	if _, err := NewRegistry().Mutate(m.prevEntry, mutation); err != nil {
+		return nil, fmt.Errorf("presign mutation check: %w", err)
 	}
 
 	return &pb.UpdateEntryRequest{
@@ -147,18 +330,25 @@ func (m *Mutation) SerializeAndSign(signers []signatures.Signer, trustedTreeSize
 
 // Sign produces the mutation
 func (m *Mutation) sign(signers []signatures.Signer) (*pb.Entry, error) {
-	m.entry.Signatures = nil
+	sigs, err := signEntry(m.Serialize(), signers)
+	if err != nil {
+		return nil, err
+	}
+	m.entry.Signatures = sigs
+	return m.entry, nil
+}
+
+// signEntry returns the signatures of e by signers, keyed by key ID.
+func signEntry(e *pb.Entry, signers []signatures.Signer) (map[string]*sigpb.DigitallySigned, error) {
 	sigs := make(map[string]*sigpb.DigitallySigned)
 	for _, signer := range signers {
-		sig, err := signer.Sign(m.entry)
+		sig, err := signer.Sign(e)
 		if err != nil {
 			return nil, err
 		}
 		sigs[signer.KeyID()] = sig
 	}
-
-	m.entry.Signatures = sigs
-	return m.entry, nil
+	return sigs, nil
 }
 
 // Check verifies that an update was successfully applied.
@@ -169,7 +359,7 @@ func (m *Mutation) Check(newLeaf []byte) (bool, error) {
 	// use an equality operation on the proto itself.
 	leafValue, err := FromLeafValue(newLeaf)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode current entry: %v", err)
+		return false, fmt.Errorf("failed to decode current entry: %w", err)
 	}
 
 	return proto.Equal(leafValue, m.entry), nil
@@ -15,13 +15,18 @@
 package entry
 
 import (
+	"context"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
 
 	"github.com/google/keytransparency/core/crypto/dev"
 	"github.com/google/keytransparency/core/crypto/signatures"
 	"github.com/google/keytransparency/core/crypto/signatures/factory"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
 const domainID = "default"
@@ -105,6 +110,57 @@ func TestCreateAndVerify(t *testing.T) {
 	}
 }
 
+func TestRebase(t *testing.T) {
+	mustLeaf := func(pubKeys []*keyspb.PublicKey, commitment string) []byte {
+		leaf, err := ToLeafValue(&pb.Entry{
+			AuthorizedKeys: pubKeys,
+			Commitment:     []byte(commitment),
+		})
+		if err != nil {
+			t.Fatalf("ToLeafValue(): %v", err)
+		}
+		return leaf
+	}
+	keys1 := mustPublicKeys([]string{testPubKey1})
+	keys2 := mustPublicKeys([]string{testPubKey2})
+	old := mustLeaf(keys1, "old")
+	for _, tc := range []struct {
+		desc        string
+		newLeaf     []byte
+		wantChanged bool
+		wantErr     error
+	}{
+		{desc: "unchanged", newLeaf: old, wantChanged: false},
+		{desc: "new commitment", newLeaf: mustLeaf(keys1, "new"), wantChanged: true},
+		{desc: "new keys", newLeaf: mustLeaf(keys2, "new"), wantChanged: true, wantErr: ErrIncompatiblePrevious},
+	} {
+		m := NewMutation([]byte("index"), domainID, "app1", "alice")
+		if err := m.SetPrevious(old, true); err != nil {
+			t.Fatalf("SetPrevious(): %v", err)
+		}
+		if err := m.SetCommitment([]byte("foo")); err != nil {
+			t.Fatalf("SetCommitment(): %v", err)
+		}
+		changed, err := m.PreviousChanged(tc.newLeaf)
+		if err != nil {
+			t.Errorf("%v: PreviousChanged(): %v", tc.desc, err)
+			continue
+		}
+		if got, want := changed, tc.wantChanged; got != want {
+			t.Errorf("%v: PreviousChanged(): %v, want %v", tc.desc, got, want)
+		}
+		if got, want := m.Rebase(tc.newLeaf), tc.wantErr; got != want {
+			t.Errorf("%v: Rebase(): %v, want %v", tc.desc, got, want)
+		}
+		if tc.wantErr != nil {
+			continue
+		}
+		if _, err := m.SerializeAndSign([]signatures.Signer{createSigner(t, testPrivKey1)}, 0); err != nil {
+			t.Errorf("%v: SerializeAndSign() after Rebase(): %v", tc.desc, err)
+		}
+	}
+}
+
 func createSigner(t *testing.T, privKey string) signatures.Signer {
 	signatures.Rand = dev.Zeros
 	signer, err := factory.NewSignerFromPEM([]byte(privKey))
@@ -113,3 +169,94 @@ func createSigner(t *testing.T, privKey string) signatures.Signer {
 	}
 	return signer
 }
+
+func TestFromRequest(t *testing.T) {
+	signers := []signatures.Signer{createSigner(t, testPrivKey1)}
+	m := NewMutation([]byte("index"), domainID, "app1", "alice")
+	if err := m.SetPrevious(nil, true); err != nil {
+		t.Fatalf("SetPrevious(): %v", err)
+	}
+	if err := m.SetCommitment([]byte("foo")); err != nil {
+		t.Fatalf("SetCommitment(): %v", err)
+	}
+	if err := m.ReplaceAuthorizedKeys(mustPublicKeys([]string{testPubKey1})); err != nil {
+		t.Fatalf("ReplaceAuthorizedKeys(): %v", err)
+	}
+	req, err := m.SerializeAndSign(signers, 0)
+	if err != nil {
+		t.Fatalf("SerializeAndSign(): %v", err)
+	}
+
+	saved, err := FromRequest(req, nil)
+	if err != nil {
+		t.Fatalf("FromRequest(): %v", err)
+	}
+	newLeaf, err := ToLeafValue(req.GetEntryUpdate().GetMutation())
+	if err != nil {
+		t.Fatalf("ToLeafValue(): %v", err)
+	}
+	if equal, err := saved.Check(newLeaf); err != nil || !equal {
+		t.Errorf("Check(): %v, %v, want true", equal, err)
+	}
+	again, err := saved.SerializeAndSign(signers, 0)
+	if err != nil {
+		t.Fatalf("SerializeAndSign(saved): %v", err)
+	}
+	if got, want := again.GetEntryUpdate().GetCommitted(), req.GetEntryUpdate().GetCommitted(); !proto.Equal(got, want) {
+		t.Errorf("SerializeAndSign(saved): committed %v, want %v", got, want)
+	}
+
+	if _, err := FromRequest(&pb.UpdateEntryRequest{}, nil); err == nil {
+		t.Errorf("FromRequest(no mutation): nil, want error")
+	}
+}
+
+func TestAttachSignatures(t *testing.T) {
+	signer := createSigner(t, testPrivKey1)
+	other := createSigner(t, testPrivKey2)
+	for _, tc := range []struct {
+		desc    string
+		signer  signatures.Signer
+		change  bool
+		wantErr bool
+	}{
+		{desc: "authorized", signer: signer},
+		{desc: "unauthorized", signer: other, wantErr: true},
+		{desc: "changed after Serialize", signer: signer, change: true, wantErr: true},
+	} {
+		m := NewMutation([]byte("index"), domainID, "app1", "alice")
+		if err := m.SetPrevious(nil, true); err != nil {
+			t.Fatalf("SetPrevious(): %v", err)
+		}
+		if err := m.SetCommitment([]byte("foo")); err != nil {
+			t.Fatalf("SetCommitment(): %v", err)
+		}
+		if err := m.ReplaceAuthorizedKeys(mustPublicKeys([]string{testPubKey1})); err != nil {
+			t.Fatalf("ReplaceAuthorizedKeys(): %v", err)
+		}
+		e := m.Serialize()
+		sig, err := signatures.AsRemote(tc.signer).Sign(context.Background(), e)
+		if err != nil {
+			t.Fatalf("Sign(): %v", err)
+		}
+		if tc.change {
+			if err := m.SetCommitment([]byte("bar")); err != nil {
+				t.Fatalf("SetCommitment(): %v", err)
+			}
+		}
+		req, err := m.AttachSignatures(map[string]*sigpb.DigitallySigned{tc.signer.KeyID(): sig}, 5)
+		if got := err != nil; got != tc.wantErr {
+			t.Errorf("%v: AttachSignatures(): %v, wantErr %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := req.GetFirstTreeSize(); got != 5 {
+			t.Errorf("%v: AttachSignatures().FirstTreeSize: %v, want 5", tc.desc, got)
+		}
+		if _, err := New().Mutate(nil, req.GetEntryUpdate().GetMutation()); err != nil {
+			t.Errorf("%v: Mutate(): %v", tc.desc, err)
+		}
+	}
+}
@@ -40,6 +40,19 @@ func New() *Mutator {
 	return &Mutator{}
 }
 
+// NewRegistry returns a mutator.Registry with the standard entry mutator
+// registered as mutator.TypeUpdate, and the reset, revoke, freeze and policy
+// mutators under their types.
+func NewRegistry() *mutator.Registry {
+	r := mutator.NewRegistry()
+	r.Register(mutator.TypeUpdate, New())
+	r.Register(mutator.TypeReset, NewReset())
+	r.Register(mutator.TypeRevoke, NewRevoke())
+	r.Register(mutator.TypeFreeze, NewFreeze())
+	r.Register(mutator.TypePolicy, NewPolicy())
+	return r
+}
+
 // Mutate verifies that this is a valid mutation for this item and applies
 // mutation to value. Repeated applications of Mutate on the same input produce
 // the same output. OldValue and update are both SignedKV protos.
@@ -55,6 +68,10 @@ func (*Mutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
 		glog.Warning("received proto.Message is not of type *pb.Entry.")
 		return nil, fmt.Errorf("updateM.(*pb.Entry): _, %v", ok)
 	}
+	if t := mutator.TypeOf(newEntry); t != mutator.TypeUpdate {
+		glog.Warningf("mutation type %q is not an entry update.", t)
+		return nil, mutator.ErrUnknownType
+	}
 	var oldEntry *pb.Entry
 	if oldValue != nil {
 		old, ok := oldValue.(*pb.Entry)
@@ -64,26 +81,13 @@ func (*Mutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
 		}
 		oldEntry = old
 	}
-
-	// Verify pointer to previous data.  The very first entry will have
-	// oldValue=nil, so its hash is the ObjectHash value of nil.
-	oej, err := objecthash.CommonJSONify(oldEntry)
-	if err != nil {
-		return nil, fmt.Errorf("CommonJSONify: %v", err)
-	}
-	prevEntryHash, err := objecthash.ObjectHash(oej)
-	if err != nil {
-		return nil, fmt.Errorf("ObjectHash: %v", err)
+	if mutator.Locked(oldEntry) {
+		glog.Warningf("update of a revoked or frozen entry")
+		return nil, mutator.ErrLocked
 	}
 
-	if !bytes.Equal(prevEntryHash[:], newEntry.GetPrevious()) {
-		// Check if this mutation is a replay.
-		if oldEntry != nil && proto.Equal(oldEntry, newEntry) {
-			glog.Warningf("mutation is a replay of an old one")
-			return nil, mutator.ErrReplay
-		}
-		glog.Warningf("previous entry hash (%v) does not match the hash provided in this mutation (%v)", prevEntryHash[:], newEntry.GetPrevious())
-		return nil, mutator.ErrPreviousHash
+	if err := checkPrevious(oldEntry, newEntry); err != nil {
+		return nil, err
 	}
 
 	// Ensure that the mutation has at least one authorized key to prevent
@@ -92,6 +96,11 @@ func (*Mutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
 		glog.Warningf("mutation should contain at least one authorized key")
 		return nil, mutator.ErrMissingKey
 	}
+	// Recovery keys may only sign resets.
+	if containsKey(newEntry.GetAuthorizedKeys(), newEntry.GetRecoveryKeys()) {
+		glog.Warningf("mutation authorizes a recovery key")
+		return nil, mutator.ErrRecoveryKey
+	}
 
 	kv := *newEntry
 	kv.Signatures = nil
@@ -106,6 +115,42 @@ func (*Mutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
 	return newEntry, nil
 }
 
+// checkPrevious verifies that newEntry points to oldEntry. The very first
+// entry will have oldEntry=nil, so its hash is the ObjectHash value of nil.
+func checkPrevious(oldEntry, newEntry *pb.Entry) error {
+	oej, err := objecthash.CommonJSONify(oldEntry)
+	if err != nil {
+		return fmt.Errorf("CommonJSONify: %w", err)
+	}
+	prevEntryHash, err := objecthash.ObjectHash(oej)
+	if err != nil {
+		return fmt.Errorf("ObjectHash: %w", err)
+	}
+
+	if !bytes.Equal(prevEntryHash[:], newEntry.GetPrevious()) {
+		// Check if this mutation is a replay.
+		if oldEntry != nil && proto.Equal(oldEntry, newEntry) {
+			glog.Warningf("mutation is a replay of an old one")
+			return mutator.ErrReplay
+		}
+		glog.Warningf("previous entry hash (%v) does not match the hash provided in this mutation (%v)", prevEntryHash[:], newEntry.GetPrevious())
+		return mutator.ErrPreviousHash
+	}
+	return nil
+}
+
+// containsKey returns true if any key of b is also in a.
+func containsKey(a, b []*keyspb.PublicKey) bool {
+	for _, kb := range b {
+		for _, ka := range a {
+			if bytes.Equal(ka.GetDer(), kb.GetDer()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // verifyKeys verifies both old and new authorized keys based on the following
 // criteria:
 //   1. At least one signature with a key in the previous entry should exist.
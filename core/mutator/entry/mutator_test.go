@@ -193,6 +193,21 @@ func TestCheckMutation(t *testing.T) {
 			},
 			signers: signersFromPEMs(t, [][]byte{[]byte(testPrivKey1)}),
 		},
+		{
+			desc: "Unknown mutation type",
+			old:  entryData1,
+			mutation: &Mutation{
+				entry: &tpb.Entry{
+					Index:          key,
+					Commitment:     []byte{2},
+					Previous:       hashEntry1[:],
+					AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+					MutationType:   "freeze",
+				},
+			},
+			signers: signersFromPEMs(t, [][]byte{[]byte(testPrivKey1)}),
+			err:     mutator.ErrUnknownType,
+		},
 	} {
 		m, err := tc.mutation.sign(tc.signers)
 		if err != nil {
@@ -0,0 +1,91 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+var (
+	// ErrKeyAlgorithm occurs when an authorized key uses a signature
+	// algorithm that the key policy does not allow.
+	ErrKeyAlgorithm = errors.New("entry: key algorithm not allowed by key policy")
+	// ErrTooManyKeys occurs when an entry has more authorized keys than the
+	// key policy allows.
+	ErrTooManyKeys = errors.New("entry: too many authorized keys for key policy")
+)
+
+// KeyAlgorithms are the signature algorithms of authorized keys that entries
+// can be verified with.
+var KeyAlgorithms = map[sigpb.DigitallySigned_SignatureAlgorithm]bool{
+	sigpb.DigitallySigned_ECDSA: true,
+	sigpb.DigitallySigned_RSA:   true,
+}
+
+// CheckKeyPolicy returns an error if the authorized keys of e do not comply
+// with policy. A nil policy allows all keys.
+func CheckKeyPolicy(policy *pb.KeyPolicy, e *pb.Entry) error {
+	keys := e.GetAuthorizedKeys()
+	if max := policy.GetMaxKeys(); max > 0 && len(keys) > int(max) {
+		return ErrTooManyKeys
+	}
+	allowed := policy.GetAllowedAlgorithms()
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, k := range keys {
+		alg, err := keyAlgorithm(k)
+		if err != nil {
+			return err
+		}
+		if !containsAlgorithm(allowed, alg) {
+			return ErrKeyAlgorithm
+		}
+	}
+	return nil
+}
+
+// keyAlgorithm returns the signature algorithm that k is used with.
+func keyAlgorithm(k *keyspb.PublicKey) (sigpb.DigitallySigned_SignatureAlgorithm, error) {
+	pub, err := x509.ParsePKIXPublicKey(k.GetDer())
+	if err != nil {
+		return sigpb.DigitallySigned_ANONYMOUS, fmt.Errorf("entry: could not parse authorized key: %w", err)
+	}
+	switch pub.(type) {
+	case *ecdsa.PublicKey:
+		return sigpb.DigitallySigned_ECDSA, nil
+	case *rsa.PublicKey:
+		return sigpb.DigitallySigned_RSA, nil
+	}
+	return sigpb.DigitallySigned_ANONYMOUS, ErrKeyAlgorithm
+}
+
+func containsAlgorithm(algs []sigpb.DigitallySigned_SignatureAlgorithm, alg sigpb.DigitallySigned_SignatureAlgorithm) bool {
+	for _, a := range algs {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
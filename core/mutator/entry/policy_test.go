@@ -0,0 +1,65 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestCheckKeyPolicy(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	rsaDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey(): %v", err)
+	}
+	ecdsaKeys := mustPublicKeys([]string{testPubKey1, testPubKey2})
+	rsaPub := &keyspb.PublicKey{Der: rsaDER}
+	ecdsaOnly := []sigpb.DigitallySigned_SignatureAlgorithm{sigpb.DigitallySigned_ECDSA}
+
+	for _, tc := range []struct {
+		desc   string
+		policy *pb.KeyPolicy
+		keys   []*keyspb.PublicKey
+		want   error
+	}{
+		{desc: "no policy", policy: nil, keys: append(ecdsaKeys, rsaPub)},
+		{desc: "empty policy", policy: &pb.KeyPolicy{}, keys: append(ecdsaKeys, rsaPub)},
+		{desc: "max keys", policy: &pb.KeyPolicy{MaxKeys: 2}, keys: ecdsaKeys},
+		{desc: "too many keys", policy: &pb.KeyPolicy{MaxKeys: 1}, keys: ecdsaKeys, want: ErrTooManyKeys},
+		{desc: "allowed algorithm", policy: &pb.KeyPolicy{AllowedAlgorithms: ecdsaOnly}, keys: ecdsaKeys},
+		{desc: "disallowed algorithm", policy: &pb.KeyPolicy{AllowedAlgorithms: ecdsaOnly}, keys: []*keyspb.PublicKey{ecdsaKeys[0], rsaPub}, want: ErrKeyAlgorithm},
+	} {
+		e := &pb.Entry{AuthorizedKeys: tc.keys}
+		if got := CheckKeyPolicy(tc.policy, e); got != tc.want {
+			t.Errorf("%v: CheckKeyPolicy(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+
+	e := &pb.Entry{AuthorizedKeys: []*keyspb.PublicKey{{Der: []byte("garbage")}}}
+	if err := CheckKeyPolicy(&pb.KeyPolicy{AllowedAlgorithms: ecdsaOnly}, e); err == nil {
+		t.Errorf("CheckKeyPolicy(unparsable key): nil, want error")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ResetMutator defines mutations that recover an entry whose authorized keys
+// are lost or compromised. A reset replaces the authorized keys of an entry
+// and commits to an empty profile, which the key server checks. It must be
+// signed by one of the recovery keys of the previous entry, and keeps those
+// recovery keys unchanged.
+type ResetMutator struct{}
+
+// NewReset creates a new reset mutator.
+func NewReset() *ResetMutator {
+	return &ResetMutator{}
+}
+
+// Mutate verifies that update is a valid reset of oldValue and applies it.
+// OldValue and update are both Entry protos.
+func (*ResetMutator) Mutate(oldValue, update proto.Message) (proto.Message, error) {
+	if proto.Size(update) > mutator.MaxMutationSize {
+		glog.Warningf("mutation (%v bytes) is larger than the maximum accepted size (%v bytes).", proto.Size(update), mutator.MaxMutationSize)
+		return nil, mutator.ErrSize
+	}
+	newEntry, ok := update.(*pb.Entry)
+	if !ok {
+		glog.Warning("received proto.Message is not of type *pb.Entry.")
+		return nil, fmt.Errorf("updateM.(*pb.Entry): _, %v", ok)
+	}
+	if t := mutator.TypeOf(newEntry); t != mutator.TypeReset {
+		glog.Warningf("mutation type %q is not a reset.", t)
+		return nil, mutator.ErrUnknownType
+	}
+	// Only entries that designated recovery keys can be reset.
+	oldEntry, _ := oldValue.(*pb.Entry)
+	if len(oldEntry.GetRecoveryKeys()) == 0 {
+		glog.Warningf("reset of an entry without recovery keys")
+		return nil, mutator.ErrUnauthorized
+	}
+
+	if err := checkPrevious(oldEntry, newEntry); err != nil {
+		return nil, err
+	}
+
+	if len(newEntry.GetAuthorizedKeys()) == 0 {
+		glog.Warningf("mutation should contain at least one authorized key")
+		return nil, mutator.ErrMissingKey
+	}
+	// A reset cannot replace the recovery keys that authorize it, nor
+	// authorize them to sign updates.
+	if !equalKeys(oldEntry.GetRecoveryKeys(), newEntry.GetRecoveryKeys()) ||
+		containsKey(newEntry.GetAuthorizedKeys(), newEntry.GetRecoveryKeys()) {
+		glog.Warningf("reset changes or authorizes recovery keys")
+		return nil, mutator.ErrRecoveryKey
+	}
+
+	verifiers, err := verifiersFromKeys(oldEntry.GetRecoveryKeys())
+	if err != nil {
+		return nil, err
+	}
+	kv := *newEntry
+	kv.Signatures = nil
+	if err := verifyAuthorizedKeys(kv, verifiers, newEntry.GetSignatures()); err != nil {
+		return nil, err
+	}
+	return newEntry, nil
+}
+
+// equalKeys returns true if a and b hold the same keys in the same order.
+func equalKeys(a, b []*keyspb.PublicKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
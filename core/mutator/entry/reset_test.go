@@ -0,0 +1,142 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"testing"
+
+	"github.com/google/keytransparency/core/crypto/signatures"
+	"github.com/google/keytransparency/core/mutator"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestReset(t *testing.T) {
+	authorized := signersFromPEMs(t, [][]byte{[]byte(testPrivKey1)})
+	recovery := signersFromPEMs(t, [][]byte{[]byte(testPrivKey2)})
+	prev := &pb.Entry{
+		Index:          []byte("index"),
+		Commitment:     []byte{1},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+		RecoveryKeys:   mustPublicKeys([]string{testPubKey2}),
+		Previous:       nilHash[:],
+	}
+	prevLeaf, err := ToLeafValue(prev)
+	if err != nil {
+		t.Fatalf("ToLeafValue(): %v", err)
+	}
+	noRecovery := *prev
+	noRecovery.RecoveryKeys = nil
+
+	// newReset returns a reset of prev, modified by edit.
+	newReset := func(edit func(*pb.Entry)) *Mutation {
+		m := NewMutation([]byte("index"), domainID, "app1", "alice")
+		if err := m.SetPrevious(prevLeaf, true); err != nil {
+			t.Fatalf("SetPrevious(): %v", err)
+		}
+		if err := m.ResetAuthorizedKeys(mustPublicKeys([]string{testPubKey1})); err != nil {
+			t.Fatalf("ResetAuthorizedKeys(): %v", err)
+		}
+		if edit != nil {
+			edit(m.entry)
+		}
+		return m
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		old      *pb.Entry
+		mutation *Mutation
+		signers  []signatures.Signer
+		want     error
+	}{
+		{desc: "signed by recovery key", old: prev, mutation: newReset(nil), signers: recovery},
+		{desc: "signed by authorized key", old: prev, mutation: newReset(nil), signers: authorized, want: mutator.ErrUnauthorized},
+		{desc: "no recovery keys", old: &noRecovery, mutation: newReset(nil), signers: recovery, want: mutator.ErrUnauthorized},
+		{desc: "no authorized keys", old: prev, mutation: newReset(func(e *pb.Entry) {
+			e.AuthorizedKeys = nil
+		}), signers: recovery, want: mutator.ErrMissingKey},
+		{desc: "recovery keys changed", old: prev, mutation: newReset(func(e *pb.Entry) {
+			e.RecoveryKeys = mustPublicKeys([]string{testPubKey1})
+		}), signers: recovery, want: mutator.ErrRecoveryKey},
+		{desc: "recovery key authorized", old: prev, mutation: newReset(func(e *pb.Entry) {
+			e.AuthorizedKeys = mustPublicKeys([]string{testPubKey2})
+		}), signers: recovery, want: mutator.ErrRecoveryKey},
+		{desc: "not a reset", old: prev, mutation: newReset(func(e *pb.Entry) {
+			e.MutationType = ""
+		}), signers: recovery, want: mutator.ErrUnauthorized},
+	} {
+		m, err := tc.mutation.sign(tc.signers)
+		if err != nil {
+			t.Fatalf("%v: sign(): %v", tc.desc, err)
+		}
+		if _, got := NewRegistry().Mutate(tc.old, m); got != tc.want {
+			t.Errorf("%v: Mutate(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestResetWithoutRecoveryKeys(t *testing.T) {
+	m := NewMutation([]byte("index"), domainID, "app1", "alice")
+	if err := m.SetPrevious(nil, true); err != nil {
+		t.Fatalf("SetPrevious(): %v", err)
+	}
+	if err := m.ResetAuthorizedKeys(mustPublicKeys([]string{testPubKey1})); err != ErrNoRecoveryKeys {
+		t.Errorf("ResetAuthorizedKeys(): %v, want %v", err, ErrNoRecoveryKeys)
+	}
+}
+
+func TestRecoveryKeysCannotUpdate(t *testing.T) {
+	prev := &pb.Entry{
+		Index:          []byte("index"),
+		Commitment:     []byte{1},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1}),
+		RecoveryKeys:   mustPublicKeys([]string{testPubKey2}),
+		Previous:       nilHash[:],
+	}
+	hash := mustObjectHash(t, *prev)
+	for _, tc := range []struct {
+		desc    string
+		entry   *pb.Entry
+		signers [][]byte
+		want    error
+	}{
+		{desc: "signed by authorized key", entry: &pb.Entry{
+			Commitment:     []byte{2},
+			AuthorizedKeys: prev.AuthorizedKeys,
+			RecoveryKeys:   prev.RecoveryKeys,
+		}, signers: [][]byte{[]byte(testPrivKey1)}},
+		{desc: "signed by recovery key", entry: &pb.Entry{
+			Commitment:     []byte{2},
+			AuthorizedKeys: prev.AuthorizedKeys,
+			RecoveryKeys:   prev.RecoveryKeys,
+		}, signers: [][]byte{[]byte(testPrivKey2)}, want: mutator.ErrUnauthorized},
+		{desc: "recovery key authorized", entry: &pb.Entry{
+			Commitment:     []byte{2},
+			AuthorizedKeys: mustPublicKeys([]string{testPubKey1, testPubKey2}),
+			RecoveryKeys:   prev.RecoveryKeys,
+		}, signers: [][]byte{[]byte(testPrivKey1)}, want: mutator.ErrRecoveryKey},
+	} {
+		tc.entry.Index = prev.Index
+		tc.entry.Previous = hash[:]
+		m, err := (&Mutation{entry: tc.entry}).sign(signersFromPEMs(t, tc.signers))
+		if err != nil {
+			t.Fatalf("%v: sign(): %v", tc.desc, err)
+		}
+		if _, got := NewRegistry().Mutate(prev, m); got != tc.want {
+			t.Errorf("%v: Mutate(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Limits bounds the leaf values that FromLeafValueStrict accepts.
+type Limits struct {
+	// MaxLeafSize is the largest leaf value, in bytes.
+	MaxLeafSize int
+	// MaxKeys is the largest number of authorized keys, and of recovery
+	// keys.
+	MaxKeys int
+	// MaxSignatures is the largest number of signatures.
+	MaxSignatures int
+}
+
+// DefaultLimits are well above the entries that clients write.
+var DefaultLimits = Limits{
+	MaxLeafSize:   64 << 10,
+	MaxKeys:       64,
+	MaxSignatures: 64,
+}
+
+var (
+	// ErrLeafLimit occurs when a leaf value exceeds the Limits of
+	// FromLeafValueStrict.
+	ErrLeafLimit = errors.New("leaf value exceeds limits")
+	// ErrUnknownField occurs when a leaf value has a field that Entry does
+	// not define, or repeats a field that Entry defines once.
+	ErrUnknownField = errors.New("unknown or repeated field in leaf value")
+)
+
+// entryFields maps the field numbers of Entry to whether they are repeated.
+// All fields of Entry are length delimited.
+var entryFields = map[uint64]bool{
+	2:  true,  // signatures
+	3:  false, // index
+	6:  false, // commitment
+	7:  true,  // authorized_keys
+	8:  false, // previous
+	9:  false, // mutation_type
+	10: true,  // recovery_keys
+	11: false, // previous_index
+}
+
+// FromLeafValueStrict is like FromLeafValue, but only accepts leaf values
+// within limits whose top level fields are all fields of Entry, each
+// singular field appearing at most once. Clients that parse leaf values
+// served by an untrusted server use it to bound the input that reaches the
+// protobuf and objecthash libraries.
+func FromLeafValueStrict(value []byte, limits Limits) (*pb.Entry, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if len(value) > limits.MaxLeafSize {
+		return nil, fmt.Errorf("%v bytes, want <= %v: %w", len(value), limits.MaxLeafSize, ErrLeafLimit)
+	}
+	var seen [12]bool
+	if err := walkFields(value, func(key uint64, _ []byte) error {
+		num := key >> 3
+		repeated, ok := entryFields[num]
+		switch {
+		case !ok || key&7 != 2:
+			return fmt.Errorf("field %v, wire type %v: %w", num, key&7, ErrUnknownField)
+		case !repeated && seen[num]:
+			return fmt.Errorf("field %v: %w", num, ErrUnknownField)
+		}
+		seen[num] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	e := new(pb.Entry)
+	if err := proto.Unmarshal(value, e); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedLeaf, err)
+	}
+	switch {
+	case len(e.GetAuthorizedKeys()) > limits.MaxKeys:
+		return nil, fmt.Errorf("%v authorized keys, want <= %v: %w", len(e.GetAuthorizedKeys()), limits.MaxKeys, ErrLeafLimit)
+	case len(e.GetRecoveryKeys()) > limits.MaxKeys:
+		return nil, fmt.Errorf("%v recovery keys, want <= %v: %w", len(e.GetRecoveryKeys()), limits.MaxKeys, ErrLeafLimit)
+	case len(e.GetSignatures()) > limits.MaxSignatures:
+		return nil, fmt.Errorf("%v signatures, want <= %v: %w", len(e.GetSignatures()), limits.MaxSignatures, ErrLeafLimit)
+	}
+	return e, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entry
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestFromLeafValueStrict(t *testing.T) {
+	entry := &pb.Entry{
+		Index:          []byte{1},
+		Commitment:     []byte{1, 2},
+		AuthorizedKeys: mustPublicKeys([]string{testPubKey1, testPubKey2}),
+		Previous:       []byte{3},
+		MutationType:   mutator.TypeUpdate,
+	}
+	entryB, err := proto.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := append(append([]byte{}, entryB...), 12<<3|2, 1, 0)
+	varint := append(append([]byte{}, entryB...), 6<<3|0, 1)
+	repeated := append(append([]byte{}, entryB...), 6<<3|2, 1, 0)
+	small := Limits{MaxLeafSize: len(entryB), MaxKeys: 1, MaxSignatures: 1}
+	for _, tc := range []struct {
+		desc    string
+		leafVal []byte
+		limits  Limits
+		wantErr error
+	}{
+		{desc: "absent", leafVal: nil, limits: DefaultLimits},
+		{desc: "valid", leafVal: entryB, limits: DefaultLimits},
+		{desc: "unknown field", leafVal: unknown, limits: DefaultLimits, wantErr: ErrUnknownField},
+		{desc: "wrong wire type", leafVal: varint, limits: DefaultLimits, wantErr: ErrUnknownField},
+		{desc: "repeated commitment", leafVal: repeated, limits: DefaultLimits, wantErr: ErrUnknownField},
+		{desc: "truncated", leafVal: entryB[:len(entryB)-1], limits: DefaultLimits, wantErr: ErrMalformedLeaf},
+		{desc: "too large", leafVal: entryB, limits: Limits{MaxLeafSize: len(entryB) - 1}, wantErr: ErrLeafLimit},
+		{desc: "too many keys", leafVal: entryB, limits: small, wantErr: ErrLeafLimit},
+	} {
+		e, err := FromLeafValueStrict(tc.leafVal, tc.limits)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: FromLeafValueStrict(): %v, want %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if err == nil && tc.leafVal != nil && !proto.Equal(e, entry) {
+			t.Errorf("%v: FromLeafValueStrict(): %v, want %v", tc.desc, e, entry)
+		}
+	}
+}
+
+// TestLeafParsersRandom feeds corrupted leaf values to the parsers, like the
+// Fuzz function does under go-fuzz.
+func TestLeafParsersRandom(t *testing.T) {
+	entryB, err := proto.Marshal(&pb.Entry{
+		Commitment:     []byte{1, 2},
+		AuthorizedKeys: []*keyspb.PublicKey{{Der: []byte("key")}},
+		RecoveryKeys:   []*keyspb.PublicKey{{Der: []byte("recovery")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		leaf := append([]byte{}, entryB[:r.Intn(len(entryB)+1)]...)
+		for j := r.Intn(4); j > 0 && len(leaf) > 0; j-- {
+			leaf[r.Intn(len(leaf))] = byte(r.Intn(256))
+		}
+		e, err := FromLeafValueStrict(leaf, DefaultLimits)
+		if err != nil {
+			continue
+		}
+		commitment, _, err := CommitmentFromLeafValue(leaf)
+		if err != nil || !bytes.Equal(commitment, e.GetCommitment()) {
+			t.Errorf("CommitmentFromLeafValue(%x): %x, %v, want %x", leaf, commitment, err, e.GetCommitment())
+		}
+	}
+}
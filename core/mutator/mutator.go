@@ -48,6 +48,9 @@ var (
 	// ErrUnauthorized occurs when the mutation has not been signed by a key in the
 	// previous entry.
 	ErrUnauthorized = errors.New("mutation: unauthorized")
+	// ErrRecoveryKey occurs when a mutation misuses the recovery keys of an
+	// entry, e.g. by authorizing a recovery key to sign updates.
+	ErrRecoveryKey = errors.New("mutation: invalid use of recovery key")
 )
 
 // Func verifies mutations and transforms values in the map.
@@ -81,8 +84,9 @@ type ReceiveFunc func([]*QueueMessage) error
 type Receiver interface {
 	// Close stops the receiver and returns only when all callbacks are complete.
 	Close()
-	// Flush sends any waiting queue items.
-	Flush(context.Context)
+	// Flush sends any waiting queue items and returns the error of the
+	// receive function, if any.
+	Flush(context.Context) error
 }
 
 // ReceiverOptions holds options for setting up a receiver.
@@ -92,6 +96,8 @@ type ReceiverOptions struct {
 	// Period is the typical amount of time between batches.
 	// If there are more than MaxBatchSize items, receiveFunc will be called
 	// repeatedly with no delay until the load decreases under MaxBatchSize.
+	// If Period is zero, batches are only sent by Flush and MaxPeriod is
+	// ignored. Tests use this to control exactly when batches are sent.
 	Period time.Duration
 	// MaxPeriod is the maximum allowed time between batches.
 	// If no data has been received in this period, an empty batch will be sent.
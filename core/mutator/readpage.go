@@ -0,0 +1,45 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutator
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ReadRevision calls fn for each mutation stored for revision of domainID, in
+// order, reading pageSize mutations at a time from s. It stops early, without
+// an error, if fn returns false.
+func ReadRevision(ctx context.Context, s MutationStorage, domainID string, revision int64, pageSize int32,
+	fn func(e *pb.Entry) bool) error {
+	var start int64
+	for {
+		max, entries, err := s.ReadPage(ctx, domainID, revision, start, pageSize)
+		if err != nil {
+			return fmt.Errorf("mutations.ReadPage(%v, %v, %v): %w", domainID, revision, start, err)
+		}
+		for _, e := range entries {
+			if !fn(e) {
+				return nil
+			}
+		}
+		if len(entries) < int(pageSize) {
+			return nil
+		}
+		start = max + 1
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// pagedStorage serves the mutations of a single revision in pages, using the
+// position of each mutation as its sequence number.
+type pagedStorage struct {
+	entries []*pb.Entry
+	reads   int
+	err     error
+}
+
+func (s *pagedStorage) ReadPage(ctx context.Context, domainID string, revision, start int64, pageSize int32) (int64, []*pb.Entry, error) {
+	s.reads++
+	if s.err != nil {
+		return 0, nil, s.err
+	}
+	end := start + int64(pageSize)
+	if end > int64(len(s.entries)) {
+		end = int64(len(s.entries))
+	}
+	return end - 1, s.entries[start:end], nil
+}
+
+func (s *pagedStorage) WriteBatch(ctx context.Context, domainID string, revision int64, mutations []*pb.Entry) error {
+	return nil
+}
+
+func TestReadRevision(t *testing.T) {
+	ctx := context.Background()
+	entries := make([]*pb.Entry, 5)
+	for i := range entries {
+		entries[i] = &pb.Entry{Index: []byte{byte(i)}}
+	}
+	for _, tc := range []struct {
+		desc      string
+		entries   []*pb.Entry
+		pageSize  int32
+		stopAfter int
+		want      int
+		wantReads int
+	}{
+		{desc: "empty", pageSize: 2, want: 0, wantReads: 1},
+		{desc: "partial page", entries: entries, pageSize: 2, want: 5, wantReads: 3},
+		{desc: "full pages", entries: entries[:4], pageSize: 2, want: 4, wantReads: 3},
+		{desc: "one page", entries: entries, pageSize: 10, want: 5, wantReads: 1},
+		{desc: "stop", entries: entries, pageSize: 2, stopAfter: 3, want: 3, wantReads: 2},
+	} {
+		s := &pagedStorage{entries: tc.entries}
+		var got []*pb.Entry
+		if err := ReadRevision(ctx, s, "domain", 1, tc.pageSize, func(e *pb.Entry) bool {
+			got = append(got, e)
+			return tc.stopAfter == 0 || len(got) < tc.stopAfter
+		}); err != nil {
+			t.Errorf("%v: ReadRevision(): %v", tc.desc, err)
+			continue
+		}
+		if len(got) != tc.want {
+			t.Errorf("%v: ReadRevision() read %v mutations, want %v", tc.desc, len(got), tc.want)
+		}
+		for i, e := range got {
+			if e != entries[i] {
+				t.Errorf("%v: ReadRevision() mutation %v out of order", tc.desc, i)
+			}
+		}
+		if s.reads != tc.wantReads {
+			t.Errorf("%v: ReadRevision() read %v pages, want %v", tc.desc, s.reads, tc.wantReads)
+		}
+	}
+}
+
+func TestReadRevisionError(t *testing.T) {
+	want := errors.New("unavailable")
+	s := &pagedStorage{err: want}
+	err := ReadRevision(context.Background(), s, "domain", 1, 2, func(*pb.Entry) bool { return true })
+	if !errors.Is(err, want) {
+		t.Errorf("ReadRevision(): %v, want %v", err, want)
+	}
+}
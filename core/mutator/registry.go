@@ -0,0 +1,159 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutator
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// TypeUpdate is the standard mutation type, which replaces the commitment and
+// authorized keys of an entry. Mutations without a type are of this type.
+const TypeUpdate = "update"
+
+// TypeReset is the mutation type that replaces the authorized keys of an
+// entry and clears its profile. It is signed by a recovery key of the entry.
+const TypeReset = "reset"
+
+// TypeRevoke is the mutation type that removes all authorized keys of an
+// entry and clears its profile. Only a reset can restore a revoked entry.
+const TypeRevoke = "revoke"
+
+// TypeFreeze is the mutation type that locks an entry as it is. Only a reset
+// can change a frozen entry.
+const TypeFreeze = "freeze"
+
+// TypePolicy is the mutation type that replaces the recovery keys of an
+// entry, which decide who may reset it, and nothing else.
+const TypePolicy = "policy"
+
+var (
+	// ErrUnknownType occurs when no Func is registered for a mutation's
+	// type.
+	ErrUnknownType = errors.New("mutation: unknown type")
+	// ErrLocked occurs when a mutation other than a reset modifies a
+	// revoked or frozen entry.
+	ErrLocked = errors.New("mutation: entry is revoked or frozen")
+)
+
+// Locked returns true if e is revoked or frozen.
+func Locked(e *pb.Entry) bool {
+	t := e.GetMutationType()
+	return t == TypeRevoke || t == TypeFreeze
+}
+
+// TypeOf returns the type of mutation.
+func TypeOf(mutation proto.Message) string {
+	if e, ok := mutation.(*pb.Entry); ok && e.GetMutationType() != "" {
+		return e.GetMutationType()
+	}
+	return TypeUpdate
+}
+
+// Registry dispatches each mutation to the Func registered for its type.
+// New mutation semantics are added by registering a Func under a new type
+// name, without changing the format of existing mutations. Types may be
+// registered for all domains, or for individual domains with
+// RegisterDomain.
+type Registry struct {
+	mu      sync.RWMutex
+	funcs   map[string]Func
+	domains map[string]map[string]Func
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		funcs:   make(map[string]Func),
+		domains: make(map[string]map[string]Func),
+	}
+}
+
+// Register sets f as the Func for mutations of mutationType, replacing any
+// previously registered Func.
+func (r *Registry) Register(mutationType string, f Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.funcs[mutationType] = f
+}
+
+// RegisterDomain sets f as the Func for mutations of mutationType in
+// domainID only. It takes precedence over a Func registered for all domains
+// under the same type.
+func (r *Registry) RegisterDomain(domainID, mutationType string, f Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.domains[domainID] == nil {
+		r.domains[domainID] = make(map[string]Func)
+	}
+	r.domains[domainID][mutationType] = f
+}
+
+// ForDomain returns a Registry of the types registered for all domains and
+// for domainID.
+func (r *Registry) ForDomain(domainID string) *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d := NewRegistry()
+	for t, f := range r.funcs {
+		d.funcs[t] = f
+	}
+	for t, f := range r.domains[domainID] {
+		d.funcs[t] = f
+	}
+	return d
+}
+
+// ForDomain returns the Func that applies the mutations of domainID. If f is
+// a *Registry, the types registered for domainID are included.
+func ForDomain(f Func, domainID string) Func {
+	if r, ok := f.(*Registry); ok {
+		return r.ForDomain(domainID)
+	}
+	return f
+}
+
+// Lookup returns the Func registered for mutationType.
+func (r *Registry) Lookup(mutationType string) (Func, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.funcs[mutationType]
+	return f, ok
+}
+
+// Types returns the names of the registered mutation types.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.funcs))
+	for t := range r.funcs {
+		types = append(types, t)
+	}
+	return types
+}
+
+// Mutate applies mutation to value using the Func registered for the
+// mutation's type. ErrUnknownType is returned if there is none.
+func (r *Registry) Mutate(value, mutation proto.Message) (proto.Message, error) {
+	f, ok := r.Lookup(TypeOf(mutation))
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	return f.Mutate(value, mutation)
+}
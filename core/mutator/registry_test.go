@@ -0,0 +1,94 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutator
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// typeFunc is a Func that records the type it was called for.
+type typeFunc string
+
+func (f typeFunc) Mutate(value, mutation proto.Message) (proto.Message, error) {
+	return &pb.Entry{MutationType: string(f)}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register(TypeUpdate, typeFunc(TypeUpdate))
+	r.Register("freeze", typeFunc("freeze"))
+	for _, tc := range []struct {
+		mutationType string
+		want         string
+		wantErr      error
+	}{
+		{mutationType: "", want: TypeUpdate},
+		{mutationType: TypeUpdate, want: TypeUpdate},
+		{mutationType: "freeze", want: "freeze"},
+		{mutationType: "policy", wantErr: ErrUnknownType},
+	} {
+		got, err := r.Mutate(nil, &pb.Entry{MutationType: tc.mutationType})
+		if err != tc.wantErr {
+			t.Errorf("Mutate(%q): %v, want %v", tc.mutationType, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.(*pb.Entry).GetMutationType() != tc.want {
+			t.Errorf("Mutate(%q) dispatched to %q, want %q", tc.mutationType, got.(*pb.Entry).GetMutationType(), tc.want)
+		}
+	}
+}
+
+func TestRegistryForDomain(t *testing.T) {
+	r := NewRegistry()
+	r.Register(TypeUpdate, typeFunc(TypeUpdate))
+	r.Register(TypeFreeze, typeFunc(TypeFreeze))
+	r.RegisterDomain("domain1", TypeFreeze, typeFunc("domain1 freeze"))
+	r.RegisterDomain("domain1", "custom", typeFunc("custom"))
+	for _, tc := range []struct {
+		domainID     string
+		mutationType string
+		want         string
+		wantErr      error
+	}{
+		{domainID: "domain1", mutationType: TypeUpdate, want: TypeUpdate},
+		{domainID: "domain1", mutationType: TypeFreeze, want: "domain1 freeze"},
+		{domainID: "domain1", mutationType: "custom", want: "custom"},
+		{domainID: "domain2", mutationType: TypeFreeze, want: TypeFreeze},
+		{domainID: "domain2", mutationType: "custom", wantErr: ErrUnknownType},
+	} {
+		got, err := ForDomain(r, tc.domainID).Mutate(nil, &pb.Entry{MutationType: tc.mutationType})
+		if err != tc.wantErr {
+			t.Errorf("%v: Mutate(%q): %v, want %v", tc.domainID, tc.mutationType, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.(*pb.Entry).GetMutationType() != tc.want {
+			t.Errorf("%v: Mutate(%q) dispatched to %q, want %q", tc.domainID, tc.mutationType, got.(*pb.Entry).GetMutationType(), tc.want)
+		}
+	}
+	// Funcs that are not registries apply to every domain.
+	if f := typeFunc(TypeUpdate); ForDomain(f, "domain1") != f {
+		t.Errorf("ForDomain(%v): changed the Func", f)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package permalink creates and checks permalink tokens.
+//
+// A permalink names the value of one entry at one map revision. The key
+// server signs it so that it can be quoted in tickets and reports, and anyone
+// holding the token can later fetch the entry at that revision and check that
+// it still matches the digest the token carries.
+package permalink
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+// ErrSignature occurs when a permalink is not signed by any trusted key.
+var ErrSignature = errors.New("permalink signature is not trusted")
+
+// Digest returns the proof digest of an entry whose leaf value is leafValue
+// in the map whose root hash is rootHash.
+func Digest(rootHash, leafValue []byte) []byte {
+	h := sha256.New()
+	h.Write(rootHash)
+	h.Write(leafValue)
+	return h.Sum(nil)
+}
+
+// Sign signs p and returns it encoded as a token.
+func Sign(signer *tcrypto.Signer, p *pb.Permalink) (string, error) {
+	b, err := proto.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	sig, err := signer.Sign(b)
+	if err != nil {
+		return "", err
+	}
+	signed, err := proto.Marshal(&pb.SignedPermalink{Permalink: b, Signature: sig})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(signed), nil
+}
+
+// Verify checks that token is signed by one of keys and returns the permalink
+// it contains.
+func Verify(token string, keys []crypto.PublicKey) (*pb.Permalink, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed permalink token: %w", err)
+	}
+	var signed pb.SignedPermalink
+	if err := proto.Unmarshal(b, &signed); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal(SignedPermalink): %w", err)
+	}
+	trusted := false
+	for _, k := range keys {
+		if err := tcrypto.Verify(k, signed.GetPermalink(), signed.GetSignature()); err == nil {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return nil, ErrSignature
+	}
+	var p pb.Permalink
+	if err := proto.Unmarshal(signed.GetPermalink(), &p); err != nil {
+		return nil, fmt.Errorf("proto.Unmarshal(Permalink): %w", err)
+	}
+	return &p, nil
+}
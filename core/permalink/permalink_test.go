@@ -0,0 +1,88 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permalink
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tcrypto "github.com/google/trillian/crypto"
+)
+
+func newSigner(t *testing.T) *tcrypto.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(): %v", err)
+	}
+	return tcrypto.NewSHA256Signer(key)
+}
+
+func TestSignVerify(t *testing.T) {
+	signer := newSigner(t)
+	other := newSigner(t)
+	p := &pb.Permalink{
+		DomainId:    "domain",
+		AppId:       "app",
+		UserId:      "alice",
+		Revision:    5,
+		ProofDigest: Digest([]byte("root"), []byte("leaf")),
+	}
+	token, err := Sign(signer, p)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		token   string
+		keys    []crypto.PublicKey
+		wantErr bool
+	}{
+		{desc: "trusted", token: token, keys: []crypto.PublicKey{signer.Public()}},
+		{desc: "second key", token: token, keys: []crypto.PublicKey{other.Public(), signer.Public()}},
+		{desc: "untrusted", token: token, keys: []crypto.PublicKey{other.Public()}, wantErr: true},
+		{desc: "no keys", token: token, wantErr: true},
+		{desc: "truncated", token: token[:len(token)-4], keys: []crypto.PublicKey{signer.Public()}, wantErr: true},
+		{desc: "not base64", token: "!!", keys: []crypto.PublicKey{signer.Public()}, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := Verify(tc.token, tc.keys)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Verify(): %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && !proto.Equal(got, p) {
+				t.Errorf("Verify(): %v, want %v", got, p)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	d := Digest([]byte("root"), []byte("leaf"))
+	if bytes.Equal(d, Digest([]byte("root"), []byte("other"))) {
+		t.Errorf("Digest() does not depend on the leaf value")
+	}
+	if bytes.Equal(d, Digest([]byte("other"), []byte("leaf"))) {
+		t.Errorf("Digest() does not depend on the root hash")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purge records which committed profile data has been purged.
+//
+// Purging withholds the committed data of an entry from API responses. The
+// commitments themselves, and therefore the map leaves and the map and log
+// roots, are not changed, so monitors that recompute roots from the mutation
+// log are unaffected.
+//
+// Purging is withholding, not deletion: the data remains in the ExtraData of
+// the Trillian map leaves, which cannot be rewritten without changing the
+// map. The key server leaves ExtraData out of mutation proofs and GetEntry
+// refuses to serve purged data, so the data is only reachable by reading the
+// Trillian map directly.
+package purge
+
+import "context"
+
+// Storage records the revisions through which the committed data of an index
+// has been purged.
+type Storage interface {
+	// Purge marks the committed data at index purged for all map revisions
+	// up to and including revision. The purged revision of an index never
+	// decreases.
+	Purge(ctx context.Context, domainID string, index []byte, revision int64) error
+	// PurgedThrough returns the latest map revision for which the committed
+	// data at index has been purged, or -1 if none has been purged.
+	PurgedThrough(ctx context.Context, domainID string, index []byte) (int64, error)
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quota records the mutations accepted by the key server so that the
+// mutation quotas of a domain can be enforced across key server replicas.
+package quota
+
+import (
+	"context"
+	"time"
+)
+
+// UserWindow is the period over which per user quotas are counted. Storage
+// implementations may discard the records of a user that are older than
+// UserWindow once a later revision is recorded.
+const UserWindow = 24 * time.Hour
+
+// Storage counts accepted mutations.
+type Storage interface {
+	// Record counts a mutation for userID that was accepted at time ts while
+	// revision was the latest map revision of domainID.
+	Record(ctx context.Context, domainID, userID string, revision int64, ts time.Time) error
+	// CountRevision returns the number of mutations accepted while revision
+	// was the latest map revision of domainID.
+	CountRevision(ctx context.Context, domainID string, revision int64) (int64, error)
+	// CountUser returns the number of mutations accepted for userID at or
+	// after since, and the time of the oldest of them.
+	CountUser(ctx context.Context, domainID, userID string, since time.Time) (int64, time.Time, error)
+}
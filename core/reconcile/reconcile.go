@@ -0,0 +1,315 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reconcile checks that the domains in domain storage agree with
+// their trees in Trillian.
+//
+// A domain whose tree IDs name missing, inactive or unverifiable trees, or
+// whose latest map root is missing from its SMH log, cannot be served
+// correctly. Servers check all domains at startup and either refuse to start
+// or stop serving the domains that are degraded.
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/smhlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// Modes select what servers do with degraded domains.
+const (
+	// Off skips the check.
+	Off = "off"
+	// Degrade stops serving degraded domains.
+	Degrade = "degrade"
+	// Strict refuses to start if any domain is degraded.
+	Strict = "strict"
+)
+
+// ParseMode returns an error if mode is not one of Off, Degrade and Strict.
+func ParseMode(mode string) error {
+	switch mode {
+	case Off, Degrade, Strict:
+		return nil
+	}
+	return fmt.Errorf("reconcile: unknown mode %q, want %v, %v or %v", mode, Off, Degrade, Strict)
+}
+
+// hashStrategies lists the hash strategies that clients can verify for each
+// tree type.
+var hashStrategies = map[tpb.TreeType]map[tpb.HashStrategy]bool{
+	tpb.TreeType_LOG: {
+		tpb.HashStrategy_RFC6962_SHA256:        true,
+		tpb.HashStrategy_OBJECT_RFC6962_SHA256: true,
+	},
+	tpb.TreeType_MAP: {
+		tpb.HashStrategy_CONIKS_SHA512_256: true,
+	},
+}
+
+// Trees are the Trillian services that host the trees of a domain.
+type Trees struct {
+	Map      tpb.TrillianMapClient
+	MapAdmin tpb.TrillianAdminClient
+	Log      *smhlog.Backend
+}
+
+// Resolver returns the services that host the trees of d.
+type Resolver func(d *domain.Domain) (*Trees, error)
+
+// Checker checks domains against their trees and remembers the domains that
+// are degraded. It is safe for concurrent use.
+type Checker struct {
+	domains domain.Storage
+	resolve Resolver
+
+	mu       sync.RWMutex
+	last     *pb.Reconciliation
+	degraded map[string]bool
+}
+
+// New returns a Checker of the active domains in domains.
+func New(domains domain.Storage, resolve Resolver) *Checker {
+	return &Checker{
+		domains:  domains,
+		resolve:  resolve,
+		degraded: make(map[string]bool),
+	}
+}
+
+// Reconcile checks all active domains and records the result. The error is
+// only set if the domains cannot be listed; problems with individual domains
+// are reported in the result.
+func (c *Checker) Reconcile(ctx context.Context) (*pb.Reconciliation, error) {
+	domains, err := c.domains.List(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile: listing domains: %w", err)
+	}
+	ret := &pb.Reconciliation{Time: ptypes.TimestampNow()}
+	degraded := make(map[string]bool)
+	for _, d := range domains {
+		var problems []string
+		trees, err := c.resolve(d)
+		if err != nil {
+			problems = []string{fmt.Sprintf("trees are not reachable: %v", err)}
+		} else {
+			problems = CheckDomain(ctx, d, trees)
+		}
+		if len(problems) > 0 {
+			glog.Errorf("Domain %v is degraded: %v", d.DomainID, problems)
+			degraded[d.DomainID] = true
+		}
+		ret.Domains = append(ret.Domains, &pb.DomainReconciliation{
+			DomainId: d.DomainID,
+			Problems: problems,
+		})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = ret
+	c.degraded = degraded
+	return ret, nil
+}
+
+// Result returns the result of the last call to Reconcile, or nil if there
+// was none.
+func (c *Checker) Result() *pb.Reconciliation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.last
+}
+
+// Degraded returns true if the last call to Reconcile found problems with
+// domainID.
+func (c *Checker) Degraded(domainID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.degraded[domainID]
+}
+
+// Run calls Reconcile every interval until ctx is done. Domains that have
+// been repaired are served again after the next check.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := c.Reconcile(ctx); err != nil {
+				glog.Errorf("Reconcile(): %v", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CountDegraded returns the number of degraded domains in r.
+func CountDegraded(r *pb.Reconciliation) int {
+	n := 0
+	for _, d := range r.GetDomains() {
+		if len(d.GetProblems()) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckDomain returns the mismatches between d and its trees.
+func CheckDomain(ctx context.Context, d *domain.Domain, trees *Trees) []string {
+	var problems []string
+	logTree, err := trees.Log.Tree(ctx, d.LogID)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("log tree %v: %v", d.LogID, err))
+	} else if p := checkTree(logTree, d.LogID, tpb.TreeType_LOG); p != "" {
+		problems = append(problems, p)
+	}
+	mapTree, err := trees.MapAdmin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: d.MapID})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("map tree %v: %v", d.MapID, err))
+	} else if p := checkTree(mapTree, d.MapID, tpb.TreeType_MAP); p != "" {
+		problems = append(problems, p)
+	}
+	if len(problems) > 0 {
+		// The roots of the wrong trees say nothing about the domain.
+		return problems
+	}
+	if p := checkLatestRoot(ctx, d, trees); p != "" {
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// checkTree describes why t cannot serve as the tree treeID of treeType, or
+// returns "" if it can.
+func checkTree(t *tpb.Tree, treeID int64, treeType tpb.TreeType) string {
+	switch {
+	case t.GetTreeId() != treeID:
+		return fmt.Sprintf("tree %v was returned for tree %v", t.GetTreeId(), treeID)
+	case t.GetTreeType() != treeType:
+		return fmt.Sprintf("tree %v is a %v, want %v", treeID, t.GetTreeType(), treeType)
+	case t.GetDeleted():
+		return fmt.Sprintf("tree %v is deleted", treeID)
+	case t.GetTreeState() != tpb.TreeState_ACTIVE:
+		return fmt.Sprintf("tree %v is %v, want %v", treeID, t.GetTreeState(), tpb.TreeState_ACTIVE)
+	case !hashStrategies[treeType][t.GetHashStrategy()]:
+		return fmt.Sprintf("tree %v uses unsupported hash strategy %v", treeID, t.GetHashStrategy())
+	}
+	return ""
+}
+
+// checkLatestRoot describes why the latest map root of d is not the last
+// leaf of its SMH log, or returns "" if it is.
+func checkLatestRoot(ctx context.Context, d *domain.Domain, trees *Trees) string {
+	mapRoot, err := trees.Map.GetSignedMapRoot(ctx, &tpb.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return fmt.Sprintf("GetSignedMapRoot(%v): %v", d.MapID, err)
+	}
+	smr := mapRoot.GetMapRoot()
+	logRoot, err := trees.Log.Log.GetLatestSignedLogRoot(ctx, &tpb.GetLatestSignedLogRootRequest{LogId: d.LogID})
+	if err != nil {
+		return fmt.Sprintf("GetLatestSignedLogRoot(%v): %v", d.LogID, err)
+	}
+	size := logRoot.GetSignedLogRoot().GetTreeSize()
+	if size == 0 {
+		return fmt.Sprintf("map revision %v is not in empty log %v", smr.GetMapRevision(), d.LogID)
+	}
+	resp, err := trees.Log.Log.GetLeavesByRange(ctx, &tpb.GetLeavesByRangeRequest{
+		LogId:      d.LogID,
+		StartIndex: size - 1,
+		Count:      1,
+	})
+	if err != nil {
+		return fmt.Sprintf("GetLeavesByRange(%v, %v, 1): %v", d.LogID, size-1, err)
+	}
+	if len(resp.GetLeaves()) != 1 {
+		return fmt.Sprintf("log %v has no leaf at index %v", d.LogID, size-1)
+	}
+	var logged tpb.SignedMapRoot
+	if err := json.Unmarshal(resp.GetLeaves()[0].GetLeafValue(), &logged); err != nil {
+		return fmt.Sprintf("leaf %v of log %v is not a map root: %v", size-1, d.LogID, err)
+	}
+	switch {
+	case logged.GetMapRevision() < smr.GetMapRevision():
+		return fmt.Sprintf("map revision %v is not in log %v, which ends at revision %v",
+			smr.GetMapRevision(), d.LogID, logged.GetMapRevision())
+	case logged.GetMapRevision() > smr.GetMapRevision():
+		return fmt.Sprintf("log %v ends at revision %v, ahead of map revision %v",
+			d.LogID, logged.GetMapRevision(), smr.GetMapRevision())
+	case !bytes.Equal(logged.GetRootHash(), smr.GetRootHash()):
+		return fmt.Sprintf("root hash %x of map revision %v differs from %x in log %v",
+			smr.GetRootHash(), smr.GetMapRevision(), logged.GetRootHash(), d.LogID)
+	}
+	return ""
+}
+
+// domainRequest is a request that names a domain.
+type domainRequest interface {
+	GetDomainId() string
+}
+
+// check returns an error if req names a degraded domain.
+func (c *Checker) check(req interface{}) error {
+	r, ok := req.(domainRequest)
+	if !ok || !c.Degraded(r.GetDomainId()) {
+		return nil
+	}
+	return status.Errorf(codes.Unavailable, "domain %v is degraded", r.GetDomainId())
+}
+
+// UnaryInterceptor rejects requests for degraded domains with Unavailable.
+func (c *Checker) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := c.check(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor rejects streams whose requests name degraded domains
+// with Unavailable.
+func (c *Checker) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &checkedStream{ServerStream: ss, c: c})
+	}
+}
+
+// checkedStream checks the messages received on a stream.
+type checkedStream struct {
+	grpc.ServerStream
+	c *Checker
+}
+
+func (s *checkedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.c.check(m)
+}
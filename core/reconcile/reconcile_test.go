@@ -0,0 +1,159 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/smhlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	tpb "github.com/google/trillian"
+)
+
+// treeAdmin serves GetTree from a fixed set of trees.
+type treeAdmin struct {
+	tpb.TrillianAdminClient
+	trees map[int64]*tpb.Tree
+}
+
+func (a *treeAdmin) GetTree(ctx context.Context, in *tpb.GetTreeRequest, opts ...grpc.CallOption) (*tpb.Tree, error) {
+	t, ok := a.trees[in.GetTreeId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", in.GetTreeId())
+	}
+	return t, nil
+}
+
+// testTrees returns the trees of a domain with log 1 and map 2, whose map
+// is at revision 2 and whose log holds the map roots of revisions 0 to 2.
+func testTrees(ctx context.Context, t *testing.T) (*Trees, *treeAdmin, *fake.LogServer) {
+	t.Helper()
+	admin := &treeAdmin{trees: map[int64]*tpb.Tree{
+		1: {TreeId: 1, TreeType: tpb.TreeType_LOG, TreeState: tpb.TreeState_ACTIVE, HashStrategy: tpb.HashStrategy_OBJECT_RFC6962_SHA256},
+		2: {TreeId: 2, TreeType: tpb.TreeType_MAP, TreeState: tpb.TreeState_ACTIVE, HashStrategy: tpb.HashStrategy_CONIKS_SHA512_256},
+	}}
+	tmap := fake.NewTrillianMapClient()
+	tlog := fake.NewTrillianLogClient()
+	for i := int64(0); i <= 2; i++ {
+		if i > 0 {
+			tmap.SetLeaves(ctx, &tpb.SetMapLeavesRequest{MapId: 2})
+		}
+		if err := smhlog.QueueRoot(ctx, tlog, 1, &tpb.SignedMapRoot{MapRevision: i}); err != nil {
+			t.Fatalf("QueueRoot(): %v", err)
+		}
+	}
+	return &Trees{Map: tmap, MapAdmin: admin, Log: &smhlog.Backend{Log: tlog, Admin: admin}}, admin, tlog
+}
+
+func TestCheckDomain(t *testing.T) {
+	ctx := context.Background()
+	d := &domain.Domain{DomainID: "domain", LogID: 1, MapID: 2}
+	for _, tc := range []struct {
+		desc   string
+		modify func(admin *treeAdmin, tlog *fake.LogServer)
+		want   int
+	}{
+		{desc: "consistent", modify: func(*treeAdmin, *fake.LogServer) {}},
+		{desc: "missing log", modify: func(a *treeAdmin, _ *fake.LogServer) { delete(a.trees, 1) }, want: 1},
+		{desc: "frozen map", modify: func(a *treeAdmin, _ *fake.LogServer) {
+			a.trees[2].TreeState = tpb.TreeState_FROZEN
+		}, want: 1},
+		{desc: "deleted trees", modify: func(a *treeAdmin, _ *fake.LogServer) {
+			a.trees[1].Deleted = true
+			a.trees[2].Deleted = true
+		}, want: 2},
+		{desc: "swapped trees", modify: func(a *treeAdmin, _ *fake.LogServer) {
+			a.trees[1].TreeType, a.trees[2].TreeType = a.trees[2].TreeType, a.trees[1].TreeType
+		}, want: 2},
+		{desc: "unsupported hash strategy", modify: func(a *treeAdmin, _ *fake.LogServer) {
+			a.trees[2].HashStrategy = tpb.HashStrategy_RFC6962_SHA256
+		}, want: 1},
+		{desc: "latest root not logged", modify: func(_ *treeAdmin, l *fake.LogServer) {
+			l.Leaves = l.Leaves[:2]
+			l.TreeSize = 2
+		}, want: 1},
+		{desc: "empty log", modify: func(_ *treeAdmin, l *fake.LogServer) {
+			l.Leaves = nil
+			l.TreeSize = 0
+		}, want: 1},
+		{desc: "log ahead of map", modify: func(_ *treeAdmin, l *fake.LogServer) {
+			smhlog.QueueRoot(ctx, l, 1, &tpb.SignedMapRoot{MapRevision: 3})
+		}, want: 1},
+		{desc: "different root hash", modify: func(_ *treeAdmin, l *fake.LogServer) {
+			l.Leaves = l.Leaves[:2]
+			l.TreeSize = 2
+			smhlog.QueueRoot(ctx, l, 1, &tpb.SignedMapRoot{MapRevision: 2, RootHash: []byte("other")})
+		}, want: 1},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			trees, admin, tlog := testTrees(ctx, t)
+			tc.modify(admin, tlog)
+			if got := CheckDomain(ctx, d, trees); len(got) != tc.want {
+				t.Errorf("CheckDomain(): %v, want %v problems", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	domains := fake.NewDomainStorage()
+	for _, d := range []*domain.Domain{
+		{DomainID: "healthy", LogID: 1, MapID: 2},
+		{DomainID: "degraded", LogID: 3, MapID: 4},
+	} {
+		if err := domains.Write(ctx, d); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	trees, _, _ := testTrees(ctx, t)
+	c := New(domains, func(*domain.Domain) (*Trees, error) { return trees, nil })
+	if c.Result() != nil || c.Degraded("degraded") {
+		t.Errorf("Checker reports a result before Reconcile")
+	}
+	r, err := c.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile(): %v", err)
+	}
+	if got, want := CountDegraded(r), 1; got != want {
+		t.Errorf("CountDegraded(): %v, want %v", got, want)
+	}
+	if c.Result() != r {
+		t.Errorf("Result(): %v, want %v", c.Result(), r)
+	}
+
+	interceptor := c.UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	for _, tc := range []struct {
+		req  interface{}
+		want codes.Code
+	}{
+		{req: &pb.GetEntryRequest{DomainId: "healthy"}, want: codes.OK},
+		{req: &pb.GetEntryRequest{DomainId: "degraded"}, want: codes.Unavailable},
+		{req: &pb.GetServerVersionRequest{}, want: codes.OK},
+	} {
+		_, err := interceptor(ctx, tc.req, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("interceptor(%v): %v, want %v", tc.req, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runtimeconfig distributes the runtime configuration of domains to
+// the replicas that serve them.
+//
+// Replicas fetch the configuration of their domains with GetRuntimeConfig
+// and follow changes with WatchRuntimeConfig, so that settings such as
+// intervals, quotas and policies can be changed centrally without
+// restarting them. Runtime configuration never contains private keys.
+package runtimeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// FromDomain returns the runtime configuration of d.
+func FromDomain(d *domain.Domain) (*pb.RuntimeConfig, error) {
+	c := &pb.RuntimeConfig{
+		DomainId:          d.DomainID,
+		LogId:             d.LogID,
+		MapId:             d.MapID,
+		LogBackend:        d.LogBackend,
+		MinInterval:       ptypes.DurationProto(d.MinInterval),
+		MaxInterval:       ptypes.DurationProto(d.MaxInterval),
+		Frozen:            d.Frozen,
+		AppListing:        d.AppListing,
+		MutationQuota:     d.MutationQuota,
+		KeyPolicy:         d.KeyPolicy,
+		EndorsementPolicy: d.EndorsementPolicy,
+		Deleted:           d.Deleted,
+		Labels:            d.Labels,
+	}
+	if d.LogAddress != "" || d.MapAddress != "" {
+		c.TrillianBackend = &pb.TrillianBackend{LogAddress: d.LogAddress, MapAddress: d.MapAddress}
+	}
+	return withVersion(c)
+}
+
+// removed returns the runtime configuration of a domain that is no longer in
+// storage.
+func removed(domainID string) (*pb.RuntimeConfig, error) {
+	return withVersion(&pb.RuntimeConfig{DomainId: domainID, Deleted: true})
+}
+
+// withVersion sets the version of c to a digest of its other fields.
+func withVersion(c *pb.RuntimeConfig) (*pb.RuntimeConfig, error) {
+	c.Version = ""
+	// Deterministic marshaling orders the labels.
+	var buf proto.Buffer
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(c); err != nil {
+		return nil, fmt.Errorf("proto.Marshal(RuntimeConfig): %w", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	c.Version = hex.EncodeToString(sum[:8])
+	return c, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimeconfig
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	kterrors "github.com/google/keytransparency/core/errors"
+)
+
+// defaultWatchInterval is how often WatchRuntimeConfig polls storage when no
+// interval is given to New.
+const defaultWatchInterval = 5 * time.Second
+
+// Server implements pb.KeyTransparencyConfigServer.
+type Server struct {
+	domains       domain.Storage
+	watchInterval time.Duration
+}
+
+// New returns a configuration server for domains. WatchRuntimeConfig checks
+// storage for changes every watchInterval.
+func New(domains domain.Storage, watchInterval time.Duration) *Server {
+	if watchInterval == 0 {
+		watchInterval = defaultWatchInterval
+	}
+	return &Server{
+		domains:       domains,
+		watchInterval: watchInterval,
+	}
+}
+
+// GetRuntimeConfig returns the runtime configuration of a domain. Deleted
+// domains are returned so that replicas learn to stop serving them.
+func (s *Server) GetRuntimeConfig(ctx context.Context, in *pb.GetRuntimeConfigRequest) (*pb.RuntimeConfig, error) {
+	if in.GetDomainId() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Please specify a domain_id")
+	}
+	d, err := s.domains.Read(ctx, in.GetDomainId(), true)
+	if errors.Is(err, kterrors.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "Domain %v not found", in.GetDomainId())
+	} else if err != nil {
+		glog.Errorf("adminstorage.Read(%v): %v", in.GetDomainId(), err)
+		return nil, status.Errorf(codes.Internal, "Cannot fetch domain info for %v", in.GetDomainId())
+	}
+	return FromDomain(d)
+}
+
+// WatchRuntimeConfig sends the runtime configuration of the watched domains
+// each time it changes. Storage is polled so that changes made through any
+// admin server are observed.
+func (s *Server) WatchRuntimeConfig(in *pb.WatchRuntimeConfigRequest, stream pb.KeyTransparencyConfig_WatchRuntimeConfigServer) error {
+	ctx := stream.Context()
+	watched := make(map[string]bool)
+	for _, id := range in.GetDomainIds() {
+		watched[id] = true
+	}
+
+	prev, err := s.snapshot(ctx, watched)
+	if err != nil {
+		return err
+	}
+	if in.GetInitial() {
+		if err := send(stream, initialConfigs(prev, watched)); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		next, err := s.snapshot(ctx, watched)
+		if err != nil {
+			// Keep watching through transient storage errors.
+			glog.Errorf("WatchRuntimeConfig: %v", err)
+			continue
+		}
+		changed, err := configChanges(prev, next)
+		if err != nil {
+			return err
+		}
+		if err := send(stream, changed); err != nil {
+			return err
+		}
+		prev = next
+	}
+}
+
+// snapshot returns the runtime configuration of the watched domains in
+// storage by ID, including deleted domains. All domains are watched if
+// watched is empty.
+func (s *Server) snapshot(ctx context.Context, watched map[string]bool) (map[string]*pb.RuntimeConfig, error) {
+	domains, err := s.domains.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]*pb.RuntimeConfig)
+	for _, d := range domains {
+		if len(watched) > 0 && !watched[d.DomainID] {
+			continue
+		}
+		c, err := FromDomain(d)
+		if err != nil {
+			return nil, err
+		}
+		ret[d.DomainID] = c
+	}
+	return ret, nil
+}
+
+// initialConfigs returns the configurations in snap that are sent when a
+// watch starts. Deleted domains are only sent if they are watched by name.
+func initialConfigs(snap map[string]*pb.RuntimeConfig, watched map[string]bool) []*pb.RuntimeConfig {
+	var ret []*pb.RuntimeConfig
+	for id, c := range snap {
+		if !c.GetDeleted() || watched[id] {
+			ret = append(ret, c)
+		}
+	}
+	sortConfigs(ret)
+	return ret
+}
+
+// configChanges returns the configurations in next whose version differs
+// from prev, ordered by domain ID. Domains that were removed from storage are
+// returned as deleted.
+func configChanges(prev, next map[string]*pb.RuntimeConfig) ([]*pb.RuntimeConfig, error) {
+	var ret []*pb.RuntimeConfig
+	for id, n := range next {
+		if p, ok := prev[id]; !ok || p.GetVersion() != n.GetVersion() {
+			ret = append(ret, n)
+		}
+	}
+	for id, p := range prev {
+		if _, ok := next[id]; ok || p.GetDeleted() {
+			continue
+		}
+		c, err := removed(id)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, c)
+	}
+	sortConfigs(ret)
+	return ret, nil
+}
+
+// sortConfigs orders configs by domain ID.
+func sortConfigs(configs []*pb.RuntimeConfig) {
+	sort.Slice(configs, func(i, j int) bool { return configs[i].GetDomainId() < configs[j].GetDomainId() })
+}
+
+// send sends an event on stream for each of configs.
+func send(stream pb.KeyTransparencyConfig_WatchRuntimeConfigServer, configs []*pb.RuntimeConfig) error {
+	if len(configs) == 0 {
+		return nil
+	}
+	now := ptypes.TimestampNow()
+	for _, c := range configs {
+		if err := stream.Send(&pb.RuntimeConfigEvent{Config: c, Time: now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimeconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// watchStream records the events sent by WatchRuntimeConfig.
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*pb.RuntimeConfigEvent
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(e *pb.RuntimeConfigEvent) error {
+	w.events = append(w.events, e)
+	return nil
+}
+
+func mustConfig(t *testing.T, d *domain.Domain) *pb.RuntimeConfig {
+	t.Helper()
+	c, err := FromDomain(d)
+	if err != nil {
+		t.Fatalf("FromDomain(%v): %v", d.DomainID, err)
+	}
+	return c
+}
+
+func TestFromDomain(t *testing.T) {
+	base := &domain.Domain{DomainID: "a", MinInterval: time.Second, Labels: map[string]string{"env": "prod", "team": "kt"}}
+	same := &domain.Domain{DomainID: "a", MinInterval: time.Second, Labels: map[string]string{"team": "kt", "env": "prod"}}
+	for _, tc := range []struct {
+		desc       string
+		d          *domain.Domain
+		sameAsBase bool
+	}{
+		{desc: "same", d: same, sameAsBase: true},
+		{desc: "interval", d: &domain.Domain{DomainID: "a", MinInterval: time.Minute, Labels: same.Labels}},
+		{desc: "frozen", d: &domain.Domain{DomainID: "a", MinInterval: time.Second, Labels: same.Labels, Frozen: true}},
+		{desc: "deleted", d: &domain.Domain{DomainID: "a", MinInterval: time.Second, Labels: same.Labels, Deleted: true}},
+		{desc: "backend", d: &domain.Domain{DomainID: "a", MinInterval: time.Second, Labels: same.Labels, MapAddress: "map:8090"}},
+	} {
+		got := mustConfig(t, tc.d).GetVersion() == mustConfig(t, base).GetVersion()
+		if got != tc.sameAsBase {
+			t.Errorf("%v: same version: %v, want %v", tc.desc, got, tc.sameAsBase)
+		}
+	}
+
+	if got := mustConfig(t, base).GetTrillianBackend(); got != nil {
+		t.Errorf("TrillianBackend: %v, want nil for the default Trillian services", got)
+	}
+	c := mustConfig(t, &domain.Domain{DomainID: "a", MapAddress: "map:8090"})
+	if got := c.GetTrillianBackend().GetMapAddress(); got != "map:8090" {
+		t.Errorf("MapAddress: %v, want map:8090", got)
+	}
+}
+
+func TestGetRuntimeConfig(t *testing.T) {
+	ctx := context.Background()
+	domains := fake.NewDomainStorage()
+	if err := domains.Write(ctx, &domain.Domain{DomainID: "gone", Deleted: true}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	svr := New(domains, 0)
+	for _, tc := range []struct {
+		domainID string
+		want     codes.Code
+	}{
+		{domainID: "", want: codes.InvalidArgument},
+		{domainID: "missing", want: codes.NotFound},
+		{domainID: "gone", want: codes.OK},
+	} {
+		c, err := svr.GetRuntimeConfig(ctx, &pb.GetRuntimeConfigRequest{DomainId: tc.domainID})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("GetRuntimeConfig(%q): %v, want %v", tc.domainID, err, tc.want)
+		}
+		if err == nil && !c.GetDeleted() {
+			t.Errorf("GetRuntimeConfig(%q).Deleted: false, want true", tc.domainID)
+		}
+	}
+}
+
+func TestConfigChanges(t *testing.T) {
+	active := mustConfig(t, &domain.Domain{DomainID: "a", MinInterval: time.Second})
+	updated := mustConfig(t, &domain.Domain{DomainID: "a", MinInterval: time.Minute})
+	deleted := mustConfig(t, &domain.Domain{DomainID: "a", MinInterval: time.Second, Deleted: true})
+	other := mustConfig(t, &domain.Domain{DomainID: "b"})
+	for _, tc := range []struct {
+		desc       string
+		prev, next map[string]*pb.RuntimeConfig
+		want       []string
+	}{
+		{desc: "unchanged", prev: map[string]*pb.RuntimeConfig{"a": active}, next: map[string]*pb.RuntimeConfig{"a": active}},
+		{desc: "created", next: map[string]*pb.RuntimeConfig{"a": active}, want: []string{"a"}},
+		{desc: "updated", prev: map[string]*pb.RuntimeConfig{"a": active}, next: map[string]*pb.RuntimeConfig{"a": updated}, want: []string{"a"}},
+		{desc: "deleted", prev: map[string]*pb.RuntimeConfig{"a": active}, next: map[string]*pb.RuntimeConfig{"a": deleted}, want: []string{"a"}},
+		{desc: "removed", prev: map[string]*pb.RuntimeConfig{"a": active}, want: []string{"a"}},
+		{desc: "removed deleted", prev: map[string]*pb.RuntimeConfig{"a": deleted}},
+		{desc: "ordered",
+			prev: map[string]*pb.RuntimeConfig{"a": active},
+			next: map[string]*pb.RuntimeConfig{"a": updated, "b": other},
+			want: []string{"a", "b"}},
+	} {
+		changes, err := configChanges(tc.prev, tc.next)
+		if err != nil {
+			t.Errorf("%v: configChanges(): %v", tc.desc, err)
+			continue
+		}
+		var got []string
+		for _, c := range changes {
+			got = append(got, c.GetDomainId())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: configChanges(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+	changes, err := configChanges(map[string]*pb.RuntimeConfig{"a": active}, nil)
+	if err != nil || len(changes) != 1 || !changes[0].GetDeleted() {
+		t.Errorf("configChanges(removed): %v, %v, want a deleted config", changes, err)
+	}
+}
+
+func TestWatchRuntimeConfigInitial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	domains := fake.NewDomainStorage()
+	for _, d := range []*domain.Domain{
+		{DomainID: "a"},
+		{DomainID: "b"},
+		{DomainID: "gone", Deleted: true},
+	} {
+		if err := domains.Write(ctx, d); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	svr := New(domains, time.Hour)
+
+	for _, tc := range []struct {
+		req  *pb.WatchRuntimeConfigRequest
+		want []string
+	}{
+		{req: &pb.WatchRuntimeConfigRequest{}},
+		{req: &pb.WatchRuntimeConfigRequest{Initial: true}, want: []string{"a", "b"}},
+		{req: &pb.WatchRuntimeConfigRequest{Initial: true, DomainIds: []string{"b", "gone"}}, want: []string{"b", "gone"}},
+	} {
+		stream := &watchStream{ctx: ctx}
+		if err := svr.WatchRuntimeConfig(tc.req, stream); err != context.Canceled {
+			t.Errorf("WatchRuntimeConfig(): %v, want %v", err, context.Canceled)
+		}
+		var got []string
+		for _, e := range stream.events {
+			got = append(got, e.GetConfig().GetDomainId())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("WatchRuntimeConfig(%v): %v, want %v", tc.req, got, tc.want)
+		}
+	}
+}
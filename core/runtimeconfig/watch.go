@@ -0,0 +1,68 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimeconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Watch calls apply with the runtime configuration of domainIDs, or of all
+// domains if domainIDs is empty, and again each time it changes, until ctx
+// is done. Broken streams are reopened after retryDelay. Each reopened stream
+// starts with the current configuration, so changes made while disconnected
+// are not missed. apply is not called again for a version it has already
+// seen.
+func Watch(ctx context.Context, client pb.KeyTransparencyConfigClient, domainIDs []string, retryDelay time.Duration, apply func(*pb.RuntimeConfig)) error {
+	versions := make(map[string]string)
+	for {
+		if err := watch(ctx, client, domainIDs, versions, apply); err != nil {
+			glog.Warningf("WatchRuntimeConfig(): %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// watch applies the configurations received on a single stream. versions
+// holds the last version applied for each domain.
+func watch(ctx context.Context, client pb.KeyTransparencyConfigClient, domainIDs []string, versions map[string]string, apply func(*pb.RuntimeConfig)) error {
+	stream, err := client.WatchRuntimeConfig(ctx, &pb.WatchRuntimeConfigRequest{
+		DomainIds: domainIDs,
+		Initial:   true,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		c := e.GetConfig()
+		if v, ok := versions[c.GetDomainId()]; ok && v == c.GetVersion() {
+			continue
+		}
+		versions[c.GetDomainId()] = c.GetVersion()
+		apply(c)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtimeconfig
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// fakeConfigClient serves one stream of events per call to
+// WatchRuntimeConfig and cancels the watch once all streams are served.
+type fakeConfigClient struct {
+	pb.KeyTransparencyConfigClient
+	streams [][]*pb.RuntimeConfigEvent
+	cancel  context.CancelFunc
+}
+
+func (c *fakeConfigClient) WatchRuntimeConfig(ctx context.Context, in *pb.WatchRuntimeConfigRequest, opts ...grpc.CallOption) (pb.KeyTransparencyConfig_WatchRuntimeConfigClient, error) {
+	if !in.GetInitial() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	events := c.streams[0]
+	c.streams = c.streams[1:]
+	if len(c.streams) == 0 {
+		c.cancel()
+	}
+	return &fakeConfigStream{events: events}, nil
+}
+
+// fakeConfigStream returns events and then breaks.
+type fakeConfigStream struct {
+	grpc.ClientStream
+	events []*pb.RuntimeConfigEvent
+}
+
+func (s *fakeConfigStream) Recv() (*pb.RuntimeConfigEvent, error) {
+	if len(s.events) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	e := s.events[0]
+	s.events = s.events[1:]
+	return e, nil
+}
+
+func TestWatch(t *testing.T) {
+	event := func(domainID, version string) *pb.RuntimeConfigEvent {
+		return &pb.RuntimeConfigEvent{Config: &pb.RuntimeConfig{DomainId: domainID, Version: version}}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &fakeConfigClient{
+		cancel: cancel,
+		streams: [][]*pb.RuntimeConfigEvent{
+			{event("a", "1"), event("b", "1"), event("a", "2")},
+			// The reopened stream starts with the current configuration.
+			{event("a", "2"), event("b", "1"), event("b", "2")},
+		},
+	}
+
+	var got []string
+	err := Watch(ctx, client, nil, time.Millisecond, func(c *pb.RuntimeConfig) {
+		got = append(got, c.GetDomainId()+"@"+c.GetVersion())
+	})
+	if err != context.Canceled {
+		t.Errorf("Watch(): %v, want %v", err, context.Canceled)
+	}
+	if want := []string{"a@1", "b@1", "a@2", "b@2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() applied %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// ReplayRevision applies the mutations that were stored for revision of
+// domainID to the map mapID of tmap, as the epoch that created revision
+// applied them to the map of the domain, and returns the new map root. The
+// commitments that accompanied the mutations in the queue are not stored, so
+// the replayed leaves have no extra data. Extra data is not part of map root
+// hashes.
+func (s *Sequencer) ReplayRevision(ctx context.Context, domainID string, tmap trillian.TrillianMapClient, mapID, revision int64) (*trillian.SignedMapRoot, error) {
+	var msgs []*mutator.QueueMessage
+	if err := mutator.ReadRevision(ctx, s.mutations, domainID, revision, MaxBatchSize, func(e *pb.Entry) bool {
+		msgs = append(msgs, &mutator.QueueMessage{Mutation: e})
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	indexes := make([][]byte, 0, len(msgs))
+	for _, m := range msgs {
+		indexes = append(indexes, m.Mutation.GetIndex())
+	}
+	getResp, err := tmap.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
+		MapId: mapID,
+		Index: indexes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaves(%v): %w", mapID, err)
+	}
+	leaves := make([]*trillian.MapLeaf, 0, len(getResp.GetMapLeafInclusion()))
+	for _, m := range getResp.GetMapLeafInclusion() {
+		leaves = append(leaves, m.GetLeaf())
+	}
+	newLeaves, err := s.applyMutations(domainID, msgs, leaves)
+	if err != nil {
+		return nil, err
+	}
+	// Metadata is not covered by the root hash.
+	metadata, err := epochMetadata(nil)
+	if err != nil {
+		return nil, err
+	}
+	setResp, err := tmap.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
+		MapId:    mapID,
+		Leaves:   newLeaves,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SetLeaves(%v): %w", mapID, err)
+	}
+	return setResp.GetMapRoot(), nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// acceptMutator accepts every mutation except those to the index "bad".
+type acceptMutator struct{}
+
+func (acceptMutator) Mutate(value, mutation proto.Message) (proto.Message, error) {
+	if string(mutation.(*pb.Entry).GetIndex()) == "bad" {
+		return nil, errors.New("rejected")
+	}
+	return mutation, nil
+}
+
+// recordingMap records the leaves set in a fake map.
+type recordingMap struct {
+	*fake.MapServer
+	set []*trillian.MapLeaf
+}
+
+func (m *recordingMap) SetLeaves(ctx context.Context, in *trillian.SetMapLeavesRequest, opts ...grpc.CallOption) (*trillian.SetMapLeavesResponse, error) {
+	m.set = in.GetLeaves()
+	return m.MapServer.SetLeaves(ctx, in, opts...)
+}
+
+func TestReplayRevision(t *testing.T) {
+	ctx := context.Background()
+	mutations := fake.NewMutationStorage()
+	applied := []*pb.Entry{{Index: []byte("a")}, {Index: []byte("bad")}, {Index: []byte("b")}}
+	if err := mutations.WriteBatch(ctx, "domain", 1, applied); err != nil {
+		t.Fatalf("WriteBatch(): %v", err)
+	}
+	s := New(fake.NewTrillianLogClient(), nil, acceptMutator{}, fake.NewDomainStorage(), mutations, nil, nil, nil, nil)
+	tmap := &recordingMap{MapServer: fake.NewTrillianMapClient()}
+
+	root, err := s.ReplayRevision(ctx, "domain", tmap, 2, 1)
+	if err != nil {
+		t.Fatalf("ReplayRevision(): %v", err)
+	}
+	if got := root.GetMapRevision(); got != 1 {
+		t.Errorf("ReplayRevision(): revision %v, want 1", got)
+	}
+	got := make(map[string]bool)
+	for _, l := range tmap.set {
+		got[string(l.GetIndex())] = true
+		if l.GetExtraData() != nil {
+			t.Errorf("leaf %s: extra data %x, want none", l.GetIndex(), l.GetExtraData())
+		}
+		if e, err := entry.FromLeafValue(l.GetLeafValue()); err != nil || string(e.GetIndex()) != string(l.GetIndex()) {
+			t.Errorf("leaf %s: %v, %v, want the replayed mutation", l.GetIndex(), e, err)
+		}
+	}
+	if len(got) != 2 || !got["a"] || !got["b"] {
+		t.Errorf("ReplayRevision() set leaves %v, want a and b", got)
+	}
+
+	if _, err := s.ReplayRevision(ctx, "domain", tmap, 2, 2); err == nil {
+		t.Errorf("ReplayRevision(missing revision): nil, want error")
+	}
+}
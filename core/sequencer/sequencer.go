@@ -17,18 +17,24 @@ package sequencer
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/election"
+	"github.com/google/keytransparency/core/endorsement"
+	"github.com/google/keytransparency/core/migration"
 	"github.com/google/keytransparency/core/mutator"
 	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/smhlog"
+	"github.com/google/keytransparency/core/version"
 
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/google/trillian"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -59,6 +65,9 @@ var (
 // MaxBatchSize limits the number of mutations that will be processed per epoch.
 const MaxBatchSize = int32(1000)
 
+// initTimeout bounds the initialization of receivers that have no minInterval.
+const initTimeout = 10 * time.Second
+
 func init() {
 	prometheus.MustRegister(mutationsCTR)
 	prometheus.MustRegister(indexCTR)
@@ -70,11 +79,27 @@ func init() {
 type Sequencer struct {
 	domains     domain.Storage
 	tmap        trillian.TrillianMapClient
-	tlog        trillian.TrillianLogClient
 	mutatorFunc mutator.Func
 	mutations   mutator.MutationStorage
 	queue       mutator.MutationQueue
-	receivers   map[string]mutator.Receiver
+	// endorsers co-sign the map roots of domains with an endorsement
+	// policy, and endorsements stores their signatures.
+	endorsers    []endorsement.Endorser
+	endorsements endorsement.Storage
+	// logs are the backends that map roots are appended to.
+	logs smhlog.Backends
+	// mu guards receivers, which ListenForNewDomains adds to while
+	// ForceEpoch reads them, and epochErrs.
+	mu        sync.Mutex
+	receivers map[string]mutator.Receiver
+	// epochErrs holds the error of the most recent failed epoch of each
+	// domain, for diagnosing stalls.
+	epochErrs map[string]error
+	// skip reports the domains that ListenForNewDomains must not start.
+	skip func(domainID string) bool
+	// election decides which sequencer creates the epochs of each domain.
+	// Without it, this sequencer creates the epochs of all domains.
+	election election.Election
 }
 
 // New creates a new instance of the signer.
@@ -83,25 +108,48 @@ func New(tlog trillian.TrillianLogClient,
 	mutatorFunc mutator.Func,
 	domains domain.Storage,
 	mutations mutator.MutationStorage,
-	queue mutator.MutationQueue) *Sequencer {
+	queue mutator.MutationQueue,
+	endorsers []endorsement.Endorser,
+	endorsements endorsement.Storage,
+	logs smhlog.Backends) *Sequencer {
 	return &Sequencer{
-		domains:     domains,
-		tmap:        tmap,
-		tlog:        tlog,
-		mutatorFunc: mutatorFunc,
-		mutations:   mutations,
-		queue:       queue,
-		receivers:   make(map[string]mutator.Receiver),
+		domains:      domains,
+		tmap:         tmap,
+		mutatorFunc:  mutatorFunc,
+		mutations:    mutations,
+		queue:        queue,
+		endorsers:    endorsers,
+		endorsements: endorsements,
+		logs:         logs.WithDefault(tlog, nil),
+		receivers:    make(map[string]mutator.Receiver),
+		epochErrs:    make(map[string]error),
 	}
 }
 
 // Close stops all receivers and releases resources.
 func (s *Sequencer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, r := range s.receivers {
 		r.Close()
 	}
 }
 
+// SkipDomains makes ListenForNewDomains not start receivers for the domains
+// that skip returns true for, such as domains whose trees do not match domain
+// storage. Receivers that are already running are not stopped. It must be
+// called before ListenForNewDomains.
+func (s *Sequencer) SkipDomains(skip func(domainID string) bool) {
+	s.skip = skip
+}
+
+// SetElection makes the sequencer only create the epochs of the domains it
+// leads in e. Leases are acquired and renewed before each epoch. It must be
+// called before ListenForNewDomains.
+func (s *Sequencer) SetElection(e election.Election) {
+	s.election = e
+}
+
 // ListenForNewDomains starts receivers for all domains and periodically checks for new domains.
 func (s *Sequencer) ListenForNewDomains(ctx context.Context, refresh time.Duration) error {
 	ticker := time.NewTicker(refresh)
@@ -112,12 +160,18 @@ func (s *Sequencer) ListenForNewDomains(ctx context.Context, refresh time.Durati
 		case <-ticker.C:
 			domains, err := s.domains.List(ctx, false)
 			if err != nil {
-				return fmt.Errorf("admin.List(): %v", err)
+				return fmt.Errorf("admin.List(): %w", err)
 			}
 			for _, d := range domains {
-				if _, ok := s.receivers[d.DomainID]; !ok {
+				if s.skip != nil && s.skip(d.DomainID) {
+					continue
+				}
+				if _, ok := s.receiver(d.DomainID); !ok {
 					glog.Infof("StartSigning domain: %v", d.DomainID)
-					s.receivers[d.DomainID] = s.NewReceiver(ctx, d, d.MinInterval, d.MaxInterval)
+					r := s.NewReceiver(ctx, d, d.MinInterval, d.MaxInterval)
+					s.mu.Lock()
+					s.receivers[d.DomainID] = r
+					s.mu.Unlock()
 				}
 			}
 		case <-ctx.Done():
@@ -126,10 +180,77 @@ func (s *Sequencer) ListenForNewDomains(ctx context.Context, refresh time.Durati
 	}
 }
 
+// receiver returns the receiver of domainID, if it has been started.
+func (s *Sequencer) receiver(domainID string) (mutator.Receiver, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.receivers[domainID]
+	return r, ok
+}
+
+// ForceEpoch creates a new epoch for domainID containing the queued
+// mutations, without waiting for the domain's MinInterval. It returns
+// domain.ErrFrozen if the domain is frozen, after creating an empty epoch if
+// one is due.
+func (s *Sequencer) ForceEpoch(ctx context.Context, domainID string) error {
+	r, ok := s.receiver(domainID)
+	if !ok {
+		return fmt.Errorf("domain %v is not being sequenced yet", domainID)
+	}
+	glog.Infof("Forcing new epoch for domain %v", domainID)
+	return r.Flush(ctx)
+}
+
+// UpdateIntervals restarts the receiver of domainID with the epoch intervals
+// currently in storage. Domains that are not being sequenced yet pick up
+// their intervals when their receiver is started.
+func (s *Sequencer) UpdateIntervals(ctx context.Context, domainID string) error {
+	if _, ok := s.receiver(domainID); !ok {
+		return nil
+	}
+	glog.Infof("Restarting receiver of domain %v with new intervals", domainID)
+	return s.restartReceiver(ctx, domainID, defaultCloseTimeout)
+}
+
+// restartReceiver stops the receiver of domainID and starts a new one with
+// the domain as currently stored. The new receiver is not started if the old
+// one does not stop within closeTimeout.
+func (s *Sequencer) restartReceiver(ctx context.Context, domainID string, closeTimeout time.Duration) error {
+	r, ok := s.receiver(domainID)
+	if !ok {
+		return fmt.Errorf("domain %v has no receiver", domainID)
+	}
+	closed := make(chan struct{})
+	go func() {
+		r.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(closeTimeout):
+		return errReceiverHung
+	}
+
+	d, err := s.domains.Read(ctx, domainID, false)
+	if err != nil {
+		return fmt.Errorf("domains.Read(): %w", err)
+	}
+	nr := s.NewReceiver(ctx, d, d.MinInterval, d.MaxInterval)
+	s.mu.Lock()
+	s.receivers[domainID] = nr
+	s.mu.Unlock()
+	return nil
+}
+
 // NewReceiver creates a new receiver for a domain.
 // New epochs will be created at least once per maxInterval and as often as minInterval.
+// If minInterval is zero, epochs are only created when the receiver is flushed.
 func (s *Sequencer) NewReceiver(ctx context.Context, domain *domain.Domain, minInterval, maxInterval time.Duration) mutator.Receiver {
-	cctx, cancel := context.WithTimeout(ctx, minInterval)
+	timeout := minInterval
+	if timeout == 0 {
+		timeout = initTimeout
+	}
+	cctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	rootResp, err := s.tmap.GetSignedMapRoot(cctx, &trillian.GetSignedMapRootRequest{
 		MapId: domain.MapID,
@@ -156,7 +277,11 @@ func (s *Sequencer) NewReceiver(ctx context.Context, domain *domain.Domain, minI
 	last := time.Unix(0, mapRoot.GetTimestampNanos())
 
 	return s.queue.NewReceiver(ctx, last, domain.DomainID, func(mutations []*mutator.QueueMessage) error {
-		return s.createEpoch(ctx, domain, mutations)
+		err := s.createEpoch(ctx, domain, mutations)
+		s.mu.Lock()
+		s.epochErrs[domain.DomainID] = err
+		s.mu.Unlock()
+		return err
 	}, mutator.ReceiverOptions{
 		MaxBatchSize: MaxBatchSize,
 		Period:       minInterval,
@@ -172,11 +297,13 @@ func toArray(b []byte) [32]byte {
 	return i
 }
 
-// applyMutations takes the set of mutations and applies them to given leafs.
+// applyMutations takes the set of mutations of domainID and applies them to
+// given leafs.
 // Multiple mutations for the same leaf will be applied to provided leaf.
 // The last valid mutation for each leaf is included in the output.
 // Returns a list of map leaves that should be updated.
-func (s *Sequencer) applyMutations(mutations []*mutator.QueueMessage, leaves []*trillian.MapLeaf) ([]*trillian.MapLeaf, error) {
+func (s *Sequencer) applyMutations(domainID string, mutations []*mutator.QueueMessage, leaves []*trillian.MapLeaf) ([]*trillian.MapLeaf, error) {
+	mutate := mutator.ForDomain(s.mutatorFunc, domainID)
 	// Put leaves in a map from index to leaf value.
 	leafMap := make(map[[32]byte]*trillian.MapLeaf)
 	for _, l := range leaves {
@@ -186,8 +313,13 @@ func (s *Sequencer) applyMutations(mutations []*mutator.QueueMessage, leaves []*
 	retMap := make(map[[32]byte]*trillian.MapLeaf)
 	for _, m := range mutations {
 		index := m.Mutation.GetIndex()
+		leaf := leafMap[toArray(index)]
+		if leaf.GetLeafValue() == nil && len(m.Mutation.GetPreviousIndex()) != 0 {
+			// The entry moves from its index under the previous VRF.
+			leaf = leafMap[toArray(m.Mutation.GetPreviousIndex())]
+		}
 		var oldValue *pb.Entry // If no map leaf was found, oldValue will be nil.
-		if leaf, ok := leafMap[toArray(index)]; ok {
+		if leaf != nil {
 			var err error
 			oldValue, err = entry.FromLeafValue(leaf.GetLeafValue())
 			if err != nil {
@@ -196,7 +328,7 @@ func (s *Sequencer) applyMutations(mutations []*mutator.QueueMessage, leaves []*
 			}
 		}
 
-		newValue, err := s.mutatorFunc.Mutate(oldValue, m.Mutation)
+		newValue, err := mutate.Mutate(oldValue, m.Mutation)
 		if err != nil {
 			glog.Warningf("Mutate(): %v", err)
 			continue // A bad mutation should not make the whole batch fail.
@@ -207,11 +339,14 @@ func (s *Sequencer) applyMutations(mutations []*mutator.QueueMessage, leaves []*
 			continue
 		}
 
-		// Serialize commitment.
-		extraData, err := proto.Marshal(m.ExtraData)
-		if err != nil {
-			glog.Warningf("Marshal(committed proto): %v", err)
-			continue
+		// Serialize commitment. Replayed mutations have none.
+		var extraData []byte
+		if m.ExtraData != nil {
+			extraData, err = proto.Marshal(m.ExtraData)
+			if err != nil {
+				glog.Warningf("Marshal(committed proto): %v", err)
+				continue
+			}
 		}
 
 		retMap[toArray(index)] = &trillian.MapLeaf{
@@ -228,24 +363,73 @@ func (s *Sequencer) applyMutations(mutations []*mutator.QueueMessage, leaves []*
 	return ret, nil
 }
 
+// readDomain returns the domain as currently stored. Receivers hold the
+// domain they were started with, so the domain is read again.
+func (s *Sequencer) readDomain(ctx context.Context, domainID string) (*domain.Domain, error) {
+	d, err := s.domains.Read(ctx, domainID, false)
+	if err != nil {
+		return nil, fmt.Errorf("adminstorage.Read(%v): %w", domainID, err)
+	}
+	return d, nil
+}
+
+// checkFrozen returns domain.ErrFrozen if d is frozen, and whether the epoch
+// should be skipped. Frozen domains sequence no mutations, which stay queued
+// until the domain is unfrozen, but still publish an empty epoch when one is
+// due within MinInterval of MaxInterval after last, so that the freshness
+// checks of clients and monitors keep passing.
+func checkFrozen(d *domain.Domain, last, now time.Time) (bool, error) {
+	if !d.Frozen {
+		return false, nil
+	}
+	due := d.MaxInterval > 0 && now.Sub(last) >= d.MaxInterval-d.MinInterval
+	return !due, domain.ErrFrozen
+}
+
 // createEpoch signs the current map head.
 func (s *Sequencer) createEpoch(ctx context.Context, domain *domain.Domain, msgs []*mutator.QueueMessage) error {
 	glog.Infof("CreateEpoch: starting sequencing run with %d mutations", len(msgs))
 	start := time.Now()
+	current, err := s.readDomain(ctx, domain.DomainID)
+	if err != nil {
+		return err
+	}
+	// Only the leader of the domain creates its epochs.
+	if s.election != nil {
+		if err := s.election.Acquire(ctx, domain.DomainID); err != nil {
+			return err
+		}
+	}
 	// Get the current root.
 	rootResp, err := s.tmap.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{
 		MapId: domain.MapID,
 	})
 	if err != nil {
-		return fmt.Errorf("GetSignedMapRoot(%v): %v", domain.MapID, err)
+		return fmt.Errorf("GetSignedMapRoot(%v): %w", domain.MapID, err)
 	}
 	revision := rootResp.GetMapRoot().GetMapRevision()
 	glog.V(3).Infof("CreateEpoch: Previous SignedMapRoot: {Revision: %v}", revision)
 
-	// Get current leaf values.
+	// Returning an error leaves the mutations in the queue until the domain
+	// is unfrozen.
+	last := time.Unix(0, rootResp.GetMapRoot().GetTimestampNanos())
+	skip, errFrozen := checkFrozen(current, last, start)
+	if skip {
+		return errFrozen
+	}
+	if errFrozen != nil {
+		glog.Infof("CreateEpoch: domain %v is frozen, creating an empty epoch", domain.DomainID)
+		msgs = nil
+	}
+
+	// Get current leaf values, including the leaves that entries move from
+	// after a VRF rotation.
 	indexes := make([][]byte, 0, len(msgs))
 	for _, m := range msgs {
 		indexes = append(indexes, m.Mutation.Index)
+		if p := m.Mutation.GetPreviousIndex(); len(p) != 0 {
+			indexes = append(indexes, p)
+		}
 	}
 	glog.V(2).Infof("CreateEpoch: len(mutations): %v, len(indexes): %v", len(msgs), len(indexes))
 	getResp, err := s.tmap.GetLeaves(ctx, &trillian.GetMapLeavesRequest{
@@ -267,17 +451,25 @@ func (s *Sequencer) createEpoch(ctx context.Context, domain *domain.Domain, msgs
 	}
 
 	// Apply mutations to values.
-	newLeaves, err := s.applyMutations(msgs, leaves)
+	newLeaves, err := s.applyMutations(domain.DomainID, msgs, leaves)
 	if err != nil {
 		return err
 	}
 	glog.V(2).Infof("CreateEpoch: applied %v mutations to %v leaves", len(msgs), len(leaves))
 
+	// Record the sequencer's version and the announced migration in the
+	// signed map root.
+	metadata, err := epochMetadata(current.Migration)
+	if err != nil {
+		return err
+	}
+
 	// Set new leaf values.
 	mapSetStart := time.Now()
 	setResp, err := s.tmap.SetLeaves(ctx, &trillian.SetMapLeavesRequest{
-		MapId:  domain.MapID,
-		Leaves: newLeaves,
+		MapId:    domain.MapID,
+		Leaves:   newLeaves,
+		Metadata: metadata,
 	})
 	mapSetEnd := time.Now()
 	if err != nil {
@@ -295,37 +487,95 @@ func (s *Sequencer) createEpoch(ctx context.Context, domain *domain.Domain, msgs
 		return err
 	}
 
+	// Map roots of domains with an endorsement policy are only published
+	// once a quorum of endorsers has signed them.
+	if domain.EndorsementPolicy.GetQuorum() > 0 {
+		if err := s.endorse(ctx, domain, setResp.GetMapRoot()); err != nil {
+			return err
+		}
+	}
+
 	// Put SignedMapHead in an append only log.
-	if err := queueLogLeaf(ctx, s.tlog, domain.LogID, setResp.GetMapRoot()); err != nil {
+	backend, err := s.logs.Get(domain.LogBackend)
+	if err != nil {
+		return err
+	}
+	if err := smhlog.QueueRoot(ctx, backend.Log, domain.LogID, setResp.GetMapRoot()); err != nil {
 		// TODO(gdbelvin): If the log doesn't do this, we need to generate an emergency alert.
 		return err
 	}
 
+	// Stats are informational, so failing to record them does not fail the
+	// epoch.
+	if err := s.domains.RecordEpoch(ctx, domain.DomainID, revision,
+		int64(len(msgs)), newIndexes(leaves, newLeaves)); err != nil {
+		glog.Errorf("CreateEpoch: RecordEpoch(%v, %v): %v", domain.DomainID, revision, err)
+	}
+
 	mutationsCTR.Add(float64(len(msgs)))
 	indexCTR.Add(float64(len(indexes)))
 	mapUpdateHist.Observe(mapSetEnd.Sub(mapSetStart).Seconds())
 	createEpochHist.Observe(time.Since(start).Seconds())
 	glog.Infof("CreatedEpoch: rev: %v, root: %x", revision, setResp.GetMapRoot().GetRootHash())
-	return nil
+	return errFrozen
+}
+
+// newIndexes returns the number of leaves in newLeaves that were empty in
+// leaves.
+func newIndexes(leaves, newLeaves []*trillian.MapLeaf) int64 {
+	existing := make(map[string]bool, len(leaves))
+	for _, l := range leaves {
+		if len(l.GetLeafValue()) > 0 {
+			existing[string(l.GetIndex())] = true
+		}
+	}
+	var n int64
+	for _, l := range newLeaves {
+		if len(l.GetLeafValue()) > 0 && !existing[string(l.GetIndex())] {
+			existing[string(l.GetIndex())] = true
+			n++
+		}
+	}
+	return n
+}
+
+// serverVersion returns the version of the running sequencer. Tests replace it.
+var serverVersion = version.Proto
+
+// epochMetadata returns the metadata that is embedded in each new map root.
+// Failing to hash the running binary does not stop the sequencer: the
+// version is then recorded without a digest. The hash of signed, the
+// migration announced for the domain, is logged with the map root so that
+// clients only follow an announcement that everyone can see.
+func epochMetadata(signed *pb.SignedDomainMigration) (*any.Any, error) {
+	v, err := serverVersion()
+	if err != nil {
+		glog.Errorf("version.Proto(): %v", err)
+		v = &pb.ServerVersion{Version: version.Version}
+	}
+	meta := &pb.MapperMetadata{SequencerVersion: v}
+	if signed != nil {
+		if meta.MigrationHash, err = migration.Hash(signed); err != nil {
+			return nil, fmt.Errorf("migration.Hash(): %w", err)
+		}
+	}
+	return ptypes.MarshalAny(meta)
 }
 
 // TODO(gdbelvin): Add leaf at a specific index. trillian#423
-func queueLogLeaf(ctx context.Context, tlog trillian.TrillianLogClient, logID int64, smr *trillian.SignedMapRoot) error {
-	smrJSON, err := json.Marshal(smr)
+// endorse collects and stores the endorsements of smr required by the
+// endorsement policy of domain.
+func (s *Sequencer) endorse(ctx context.Context, domain *domain.Domain, smr *trillian.SignedMapRoot) error {
+	if s.endorsements == nil {
+		return fmt.Errorf("domain %v requires endorsements but endorsement storage is not configured", domain.DomainID)
+	}
+	endorsements, err := endorsement.Collect(ctx, s.endorsers, domain.DomainID, smr, domain.EndorsementPolicy)
 	if err != nil {
 		return err
 	}
-	idHash := sha256.Sum256(smrJSON)
-
-	if _, err := tlog.QueueLeaf(ctx, &trillian.QueueLeafRequest{
-		LogId: logID,
-		Leaf: &trillian.LogLeaf{
-			LeafValue:        smrJSON,
-			LeafIdentityHash: idHash[:],
-		},
-	}); err != nil {
-		return fmt.Errorf("trillianLog.QueueLeaf(logID: %v, leaf: %v): %v",
-			logID, smrJSON, err)
+	if err := s.endorsements.Write(ctx, domain.DomainID, smr.GetMapRevision(), endorsements); err != nil {
+		return fmt.Errorf("endorsements.Write(%v, %v): %w", domain.DomainID, smr.GetMapRevision(), err)
 	}
+	glog.V(2).Infof("CreateEpoch: %v endorsements for revision %v", len(endorsements), smr.GetMapRevision())
 	return nil
 }
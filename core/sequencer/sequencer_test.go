@@ -0,0 +1,196 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequencer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/migration"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/keytransparency/core/mutator/entry"
+	"github.com/google/keytransparency/core/version"
+	"github.com/google/trillian"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+func TestNewIndexes(t *testing.T) {
+	leaf := func(index, value string) *trillian.MapLeaf {
+		return &trillian.MapLeaf{Index: []byte(index), LeafValue: []byte(value)}
+	}
+	for _, tc := range []struct {
+		desc              string
+		leaves, newLeaves []*trillian.MapLeaf
+		want              int64
+	}{
+		{desc: "empty"},
+		{desc: "created", leaves: []*trillian.MapLeaf{leaf("a", "")}, newLeaves: []*trillian.MapLeaf{leaf("a", "1")}, want: 1},
+		{desc: "updated", leaves: []*trillian.MapLeaf{leaf("a", "1")}, newLeaves: []*trillian.MapLeaf{leaf("a", "2")}},
+		{desc: "duplicate", newLeaves: []*trillian.MapLeaf{leaf("a", "1"), leaf("a", "2")}, want: 1},
+		{desc: "mixed",
+			leaves:    []*trillian.MapLeaf{leaf("a", "1"), leaf("b", "")},
+			newLeaves: []*trillian.MapLeaf{leaf("a", "2"), leaf("b", "1"), leaf("c", "1")},
+			want:      2},
+	} {
+		if got := newIndexes(tc.leaves, tc.newLeaves); got != tc.want {
+			t.Errorf("%v: newIndexes(): %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestCheckFrozen(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		desc     string
+		frozen   bool
+		max      time.Duration
+		age      time.Duration
+		wantSkip bool
+	}{
+		{desc: "not frozen", max: time.Hour, age: time.Second},
+		{desc: "frozen", frozen: true, max: time.Hour, age: time.Second, wantSkip: true},
+		{desc: "frozen due", frozen: true, max: time.Hour, age: time.Hour - time.Minute},
+		{desc: "frozen on demand", frozen: true, age: time.Hour, wantSkip: true},
+	} {
+		d := &domain.Domain{Frozen: tc.frozen, MinInterval: time.Minute, MaxInterval: tc.max}
+		skip, err := checkFrozen(d, now.Add(-tc.age), now)
+		if skip != tc.wantSkip {
+			t.Errorf("%v: checkFrozen(): skip %v, want %v", tc.desc, skip, tc.wantSkip)
+		}
+		if got, want := errors.Is(err, domain.ErrFrozen), tc.frozen; got != want {
+			t.Errorf("%v: checkFrozen(): %v, want frozen %v", tc.desc, err, want)
+		}
+	}
+}
+
+func TestEpochMetadata(t *testing.T) {
+	defer func(f func() (*pb.ServerVersion, error)) { serverVersion = f }(serverVersion)
+	for _, tc := range []struct {
+		desc      string
+		version   func() (*pb.ServerVersion, error)
+		migration *pb.SignedDomainMigration
+		want      *pb.ServerVersion
+	}{
+		{
+			desc: "digest",
+			version: func() (*pb.ServerVersion, error) {
+				return &pb.ServerVersion{Version: "v1", BinaryDigest: []byte("digest")}, nil
+			},
+			want: &pb.ServerVersion{Version: "v1", BinaryDigest: []byte("digest")},
+		},
+		{
+			desc: "no digest",
+			version: func() (*pb.ServerVersion, error) {
+				return nil, errors.New("unreadable executable")
+			},
+			want: &pb.ServerVersion{Version: version.Version},
+		},
+		{
+			desc: "migration",
+			version: func() (*pb.ServerVersion, error) {
+				return &pb.ServerVersion{Version: "v1"}, nil
+			},
+			migration: &pb.SignedDomainMigration{Migration: []byte("migration"), Signature: &sigpb.DigitallySigned{Signature: []byte("sig")}},
+			want:      &pb.ServerVersion{Version: "v1"},
+		},
+	} {
+		serverVersion = tc.version
+		metadata, err := epochMetadata(tc.migration)
+		if err != nil {
+			t.Errorf("%v: epochMetadata(): %v", tc.desc, err)
+			continue
+		}
+		var meta pb.MapperMetadata
+		if err := ptypes.UnmarshalAny(metadata, &meta); err != nil {
+			t.Fatalf("%v: UnmarshalAny(): %v", tc.desc, err)
+		}
+		if got := meta.GetSequencerVersion(); !proto.Equal(got, tc.want) {
+			t.Errorf("%v: epochMetadata(): version %v, want %v", tc.desc, got, tc.want)
+		}
+		var wantHash []byte
+		if tc.migration != nil {
+			if wantHash, err = migration.Hash(tc.migration); err != nil {
+				t.Fatalf("%v: migration.Hash(): %v", tc.desc, err)
+			}
+		}
+		if got := meta.GetMigrationHash(); !bytes.Equal(got, wantHash) {
+			t.Errorf("%v: epochMetadata(): migration hash %x, want %x", tc.desc, got, wantHash)
+		}
+	}
+}
+
+// oldCommitment is a mutator.Func whose new entries keep the commitment of
+// the entry they modify.
+type oldCommitment struct{}
+
+func (oldCommitment) Mutate(value, mutation proto.Message) (proto.Message, error) {
+	old, _ := value.(*pb.Entry)
+	return &pb.Entry{Index: mutation.(*pb.Entry).GetIndex(), Commitment: old.GetCommitment()}, nil
+}
+
+func TestApplyMutationsMove(t *testing.T) {
+	leaf := func(index, commitment string) *trillian.MapLeaf {
+		l := &trillian.MapLeaf{Index: []byte(index)}
+		if commitment != "" {
+			v, err := entry.ToLeafValue(&pb.Entry{Commitment: []byte(commitment)})
+			if err != nil {
+				t.Fatalf("ToLeafValue(): %v", err)
+			}
+			l.LeafValue = v
+		}
+		return l
+	}
+	s := &Sequencer{mutatorFunc: oldCommitment{}}
+	for _, tc := range []struct {
+		desc      string
+		leaves    []*trillian.MapLeaf
+		prevIndex string
+		want      string
+	}{
+		{desc: "create", leaves: []*trillian.MapLeaf{leaf("new", "")}},
+		{desc: "update", leaves: []*trillian.MapLeaf{leaf("new", "a")}, want: "a"},
+		{desc: "move", leaves: []*trillian.MapLeaf{leaf("new", ""), leaf("old", "a")}, prevIndex: "old", want: "a"},
+		{desc: "moved", leaves: []*trillian.MapLeaf{leaf("new", "b"), leaf("old", "a")}, prevIndex: "old", want: "b"},
+		{desc: "not rotated", leaves: []*trillian.MapLeaf{leaf("new", ""), leaf("old", "a")}},
+	} {
+		msgs := []*mutator.QueueMessage{{
+			Mutation: &pb.Entry{Index: []byte("new"), PreviousIndex: []byte(tc.prevIndex)},
+		}}
+		newLeaves, err := s.applyMutations("domain", msgs, tc.leaves)
+		if err != nil {
+			t.Errorf("%v: applyMutations(): %v", tc.desc, err)
+			continue
+		}
+		if got, want := len(newLeaves), 1; got != want {
+			t.Errorf("%v: applyMutations(): %v leaves, want %v", tc.desc, got, want)
+			continue
+		}
+		e, err := entry.FromLeafValue(newLeaves[0].GetLeafValue())
+		if err != nil {
+			t.Errorf("%v: FromLeafValue(): %v", tc.desc, err)
+			continue
+		}
+		if got := string(e.GetCommitment()); got != tc.want {
+			t.Errorf("%v: applyMutations(): commitment %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
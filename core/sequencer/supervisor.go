@@ -0,0 +1,335 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/keytransparency/core/election"
+	"github.com/google/keytransparency/core/monitor/alert"
+	"github.com/google/trillian"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	stallRestartsCTR = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "kt_signer_stall_restarts",
+		Help: "Number of receivers restarted because their domain stalled.",
+	})
+	stallAlertsCTR = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "kt_signer_stall_alerts",
+		Help: "Number of stalled domains escalated to the alerter.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(stallRestartsCTR)
+	prometheus.MustRegister(stallAlertsCTR)
+}
+
+// errReceiverHung occurs when a stalled receiver does not stop, in which case
+// it is not safe to start another one for the same domain.
+var errReceiverHung = errors.New("receiver did not stop")
+
+const (
+	defaultCheckInterval = time.Minute
+	defaultGrace         = time.Minute
+	defaultMaxRestarts   = 3
+	defaultCloseTimeout  = 30 * time.Second
+)
+
+// PendingCounter reports the mutations that are waiting to be sequenced.
+type PendingCounter interface {
+	// PendingMutations returns the number of queued mutations for domainID
+	// and the time the oldest of them was queued.
+	PendingMutations(ctx context.Context, domainID string) (int64, time.Time, error)
+}
+
+// Diagnosis describes a stalled domain to the on-call engineer.
+type Diagnosis struct {
+	DomainID string
+	// MapRevision and LastEpoch describe the latest map root.
+	MapRevision int64
+	LastEpoch   time.Time
+	// MaxInterval is the longest the domain should go without an epoch.
+	MaxInterval time.Duration
+	// PendingMutations is the number of queued mutations, or -1 if unknown.
+	PendingMutations int64
+	// OldestPending is when the oldest queued mutation was queued.
+	OldestPending time.Time
+	// Restarts is the number of times the receiver was restarted during
+	// this stall.
+	Restarts int
+	// EpochError is the error of the most recent failed epoch, if any.
+	EpochError error
+	// RemediationError is the reason the last remediation failed, if it did.
+	RemediationError error
+	// Resigned is true if the sequencer gave up the leadership of the
+	// domain so that another sequencer can take over.
+	Resigned bool
+	// Time is when the diagnosis was made.
+	Time time.Time
+}
+
+// String summarizes d on one line.
+func (d *Diagnosis) String() string {
+	return fmt.Sprintf("domain %v stalled at revision %v: no epoch for %v (max interval %v), %v pending mutations, %v restarts, resigned: %v, epoch error: %v, remediation error: %v",
+		d.DomainID, d.MapRevision, d.Time.Sub(d.LastEpoch), d.MaxInterval,
+		d.PendingMutations, d.Restarts, d.Resigned, d.EpochError, d.RemediationError)
+}
+
+// Alerter escalates stalls that could not be remediated automatically.
+type Alerter interface {
+	Alert(ctx context.Context, d *Diagnosis) error
+}
+
+// AlerterFunc adapts a function to the Alerter interface.
+type AlerterFunc func(ctx context.Context, d *Diagnosis) error
+
+// Alert calls f.
+func (f AlerterFunc) Alert(ctx context.Context, d *Diagnosis) error { return f(ctx, d) }
+
+// LogAlerter logs stalls at error level.
+var LogAlerter = AlerterFunc(func(_ context.Context, d *Diagnosis) error {
+	glog.Errorf("ALERT: %v", d)
+	return nil
+})
+
+// DispatcherAlerter returns an Alerter that sends stalls to the sinks of
+// dispatcher as critical alerts. Stalls are also logged with LogAlerter.
+func DispatcherAlerter(dispatcher *alert.Dispatcher) Alerter {
+	return AlerterFunc(func(ctx context.Context, d *Diagnosis) error {
+		LogAlerter(ctx, d) // nolint: errcheck
+		return dispatcher.Alert(ctx, &alert.Alert{
+			Severity: alert.Critical,
+			Key:      fmt.Sprintf("stall/%v/%v", d.DomainID, d.MapRevision),
+			Summary:  fmt.Sprintf("domain %v has had no epoch since revision %v", d.DomainID, d.MapRevision),
+			Details:  d.String(),
+			Time:     d.Time,
+		})
+	})
+}
+
+// SupervisorOptions configures a Supervisor.
+type SupervisorOptions struct {
+	// CheckInterval is how often domains are checked. Defaults to 1 minute.
+	CheckInterval time.Duration
+	// Grace is how long past its MaxInterval a domain may go without an
+	// epoch before it is considered stalled. Defaults to 1 minute.
+	Grace time.Duration
+	// MaxRestarts is the number of receiver restarts attempted before a
+	// stall is escalated. Defaults to 3.
+	MaxRestarts int
+	// CloseTimeout bounds how long a stalled receiver may take to stop.
+	// Defaults to 30 seconds.
+	CloseTimeout time.Duration
+	// Alerter is notified of stalls that were not remediated. Defaults to
+	// LogAlerter.
+	Alerter Alerter
+	// Pending adds the mutation backlog to diagnoses if it is set.
+	Pending PendingCounter
+}
+
+// stall tracks the remediation of a stalled domain.
+type stall struct {
+	lastEpoch time.Time
+	restarts  int
+	alerted   bool
+}
+
+// Supervisor detects domains whose sequencing has stalled, re-acquires their
+// leadership and restarts their receivers, and escalates to an Alerter if
+// that does not help. A receiver is only restarted once the stalled one has
+// stopped, so that two receivers never create epochs for the same domain.
+//
+// If the sequencer takes part in an election, only the leader of a domain
+// remediates its stalls; a leader that is gone is taken over once its lease
+// expires. A leader that escalates a stall resigns, so that another
+// sequencer can take over.
+type Supervisor struct {
+	s      *Sequencer
+	opts   SupervisorOptions
+	stalls map[string]*stall
+}
+
+// NewSupervisor returns a Supervisor for the domains sequenced by s.
+func NewSupervisor(s *Sequencer, opts SupervisorOptions) *Supervisor {
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	if opts.Grace == 0 {
+		opts.Grace = defaultGrace
+	}
+	if opts.MaxRestarts == 0 {
+		opts.MaxRestarts = defaultMaxRestarts
+	}
+	if opts.CloseTimeout == 0 {
+		opts.CloseTimeout = defaultCloseTimeout
+	}
+	if opts.Alerter == nil {
+		opts.Alerter = LogAlerter
+	}
+	return &Supervisor{s: s, opts: opts, stalls: make(map[string]*stall)}
+}
+
+// Run checks domains every CheckInterval until ctx is done.
+func (sv *Supervisor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(sv.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sv.check(ctx, time.Now())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// check remediates every domain that has stalled at time now.
+func (sv *Supervisor) check(ctx context.Context, now time.Time) {
+	sv.s.mu.Lock()
+	domainIDs := make([]string, 0, len(sv.s.receivers))
+	for id := range sv.s.receivers {
+		domainIDs = append(domainIDs, id)
+	}
+	sv.s.mu.Unlock()
+	sort.Strings(domainIDs)
+
+	for _, id := range domainIDs {
+		if err := sv.checkDomain(ctx, id, now); err != nil {
+			glog.Errorf("Supervisor: checking domain %v: %v", id, err)
+		}
+	}
+}
+
+// checkDomain restarts the receiver of domainID if it has stalled, and
+// escalates the stall once MaxRestarts restarts have not helped.
+func (sv *Supervisor) checkDomain(ctx context.Context, domainID string, now time.Time) error {
+	d, err := sv.s.domains.Read(ctx, domainID, false)
+	if err != nil {
+		return fmt.Errorf("domains.Read(): %w", err)
+	}
+	// Domains that are only sequenced on demand are not expected to create
+	// epochs. Frozen domains still create empty ones.
+	if d.MinInterval == 0 || d.MaxInterval == 0 {
+		delete(sv.stalls, domainID)
+		return nil
+	}
+	rootResp, err := sv.s.tmap.GetSignedMapRoot(ctx, &trillian.GetSignedMapRootRequest{MapId: d.MapID})
+	if err != nil {
+		return fmt.Errorf("GetSignedMapRoot(%v): %w", d.MapID, err)
+	}
+	root := rootResp.GetMapRoot()
+	lastEpoch := time.Unix(0, root.GetTimestampNanos())
+	if now.Sub(lastEpoch) <= d.MaxInterval+sv.opts.Grace {
+		if st, ok := sv.stalls[domainID]; ok {
+			glog.Infof("Supervisor: domain %v recovered after %v restarts", domainID, st.restarts)
+			delete(sv.stalls, domainID)
+		}
+		return nil
+	}
+
+	st, ok := sv.stalls[domainID]
+	if !ok || !st.lastEpoch.Equal(lastEpoch) {
+		st = &stall{lastEpoch: lastEpoch}
+		sv.stalls[domainID] = st
+	}
+	if st.alerted {
+		return nil
+	}
+
+	var remediationErr error
+	if st.restarts < sv.opts.MaxRestarts {
+		switch err := sv.reacquire(ctx, domainID); {
+		case errors.Is(err, election.ErrNotLeader):
+			glog.V(2).Infof("Supervisor: domain %v has had no epoch since %v, but another sequencer leads it", domainID, lastEpoch)
+			return nil
+		case err != nil:
+			remediationErr = err
+		default:
+			glog.Warningf("Supervisor: domain %v has had no epoch since %v, restarting its receiver", domainID, lastEpoch)
+			st.restarts++
+			stallRestartsCTR.Inc()
+			remediationErr = sv.restart(ctx, domainID)
+			if remediationErr == nil {
+				return nil
+			}
+		}
+	}
+
+	diag := &Diagnosis{
+		DomainID:         domainID,
+		MapRevision:      root.GetMapRevision(),
+		LastEpoch:        lastEpoch,
+		MaxInterval:      d.MaxInterval,
+		PendingMutations: -1,
+		Restarts:         st.restarts,
+		RemediationError: remediationErr,
+		Time:             now,
+	}
+	sv.s.mu.Lock()
+	diag.EpochError = sv.s.epochErrs[domainID]
+	sv.s.mu.Unlock()
+	if sv.opts.Pending != nil {
+		pending, oldest, err := sv.opts.Pending.PendingMutations(ctx, domainID)
+		if err != nil {
+			glog.Errorf("Supervisor: PendingMutations(%v): %v", domainID, err)
+		} else {
+			diag.PendingMutations, diag.OldestPending = pending, oldest
+		}
+	}
+	if e := sv.s.election; e != nil {
+		if err := e.Resign(ctx, domainID); err != nil {
+			glog.Errorf("Supervisor: Resign(%v): %v", domainID, err)
+		} else {
+			diag.Resigned = true
+		}
+	}
+	stallAlertsCTR.Inc()
+	if err := sv.opts.Alerter.Alert(ctx, diag); err != nil {
+		return fmt.Errorf("Alert(): %w", err)
+	}
+	st.alerted = true
+	return nil
+}
+
+// reacquire makes the sequencer the leader of domainID again, in case it lost
+// its lease. It returns election.ErrNotLeader if another sequencer leads the
+// domain, and nil if sequencers do not elect leaders.
+func (sv *Supervisor) reacquire(ctx context.Context, domainID string) error {
+	e := sv.s.election
+	if e == nil {
+		return nil
+	}
+	if err := e.Acquire(ctx, domainID); err != nil {
+		if errors.Is(err, election.ErrNotLeader) {
+			return err
+		}
+		return fmt.Errorf("re-acquiring leadership: %w", err)
+	}
+	return nil
+}
+
+// restart stops the receiver of domainID and starts a new one. The new
+// receiver is not started if the old one does not stop within CloseTimeout.
+func (sv *Supervisor) restart(ctx context.Context, domainID string) error {
+	return sv.s.restartReceiver(ctx, domainID, sv.opts.CloseTimeout)
+}
@@ -0,0 +1,225 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sequencer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/core/fake"
+	"github.com/google/keytransparency/core/monitor/alert"
+	"github.com/google/keytransparency/core/mutator"
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+)
+
+// stalledMap serves a map root created at a fixed time.
+type stalledMap struct {
+	trillian.TrillianMapClient
+	rootTime time.Time
+}
+
+func (m *stalledMap) GetSignedMapRoot(ctx context.Context, in *trillian.GetSignedMapRootRequest, opts ...grpc.CallOption) (*trillian.GetSignedMapRootResponse, error) {
+	return &trillian.GetSignedMapRootResponse{MapRoot: &trillian.SignedMapRoot{
+		MapRevision:    7,
+		TimestampNanos: m.rootTime.UnixNano(),
+	}}, nil
+}
+
+// testReceiver records whether it was closed. Close blocks while hang is
+// open.
+type testReceiver struct {
+	closed bool
+	hang   chan struct{}
+}
+
+func (r *testReceiver) Close() {
+	if r.hang != nil {
+		<-r.hang
+	}
+	r.closed = true
+}
+
+func (r *testReceiver) Flush(context.Context) error { return nil }
+
+// testQueue counts the receivers it starts and records the options of the
+// last one.
+type testQueue struct {
+	mutator.MutationQueue
+	started int
+	opts    mutator.ReceiverOptions
+}
+
+func (q *testQueue) NewReceiver(ctx context.Context, last time.Time, domainID string, receiveFunc mutator.ReceiveFunc, ropts mutator.ReceiverOptions) mutator.Receiver {
+	q.started++
+	q.opts = ropts
+	return &testReceiver{}
+}
+
+func TestSupervisor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	for _, tc := range []struct {
+		desc         string
+		rootAge      time.Duration
+		frozen       bool
+		hang         bool
+		elect        bool
+		otherLeader  bool
+		checks       int
+		wantRestarts int
+		wantAlerts   int
+	}{
+		{desc: "healthy", rootAge: time.Minute, checks: 5},
+		{desc: "frozen", rootAge: time.Minute, frozen: true, checks: 5},
+		{desc: "frozen stalled", rootAge: time.Hour, frozen: true, checks: 1, wantRestarts: 1},
+		{desc: "restarted", rootAge: time.Hour, checks: 1, wantRestarts: 1},
+		{desc: "escalated", rootAge: time.Hour, checks: 5, wantRestarts: 2, wantAlerts: 1},
+		{desc: "hung", rootAge: time.Hour, hang: true, checks: 5, wantAlerts: 1},
+		{desc: "leader", rootAge: time.Hour, elect: true, checks: 5, wantRestarts: 2, wantAlerts: 1},
+		{desc: "other leader", rootAge: time.Hour, elect: true, otherLeader: true, checks: 5},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			domains := fake.NewDomainStorage()
+			if err := domains.Write(ctx, &domain.Domain{
+				DomainID:    "domain",
+				MinInterval: time.Second,
+				MaxInterval: 10 * time.Minute,
+				Frozen:      tc.frozen,
+			}); err != nil {
+				t.Fatalf("Write(): %v", err)
+			}
+			queue := &testQueue{}
+			s := New(nil, &stalledMap{rootTime: now.Add(-tc.rootAge)}, nil, domains, nil, queue, nil, nil, nil)
+			first := &testReceiver{}
+			if tc.hang {
+				first.hang = make(chan struct{})
+				defer close(first.hang)
+			}
+			s.receivers["domain"] = first
+			e := fake.NewElection()
+			if tc.elect {
+				e.Others["domain"] = tc.otherLeader
+				s.SetElection(e)
+			}
+
+			var alerts []*Diagnosis
+			sv := NewSupervisor(s, SupervisorOptions{
+				MaxRestarts:  2,
+				CloseTimeout: 10 * time.Millisecond,
+				Alerter: AlerterFunc(func(_ context.Context, d *Diagnosis) error {
+					alerts = append(alerts, d)
+					return nil
+				}),
+			})
+			for i := 0; i < tc.checks; i++ {
+				sv.check(ctx, now)
+			}
+
+			if got := queue.started; got != tc.wantRestarts {
+				t.Errorf("restarted %v receivers, want %v", got, tc.wantRestarts)
+			}
+			if tc.wantRestarts > 0 && !first.closed {
+				t.Errorf("stalled receiver was not closed")
+			}
+			if got := len(alerts); got != tc.wantAlerts {
+				t.Fatalf("sent %v alerts, want %v", got, tc.wantAlerts)
+			}
+			for _, d := range alerts {
+				if d.DomainID != "domain" || d.MapRevision != 7 || d.PendingMutations != -1 {
+					t.Errorf("Alert(): %v", d)
+				}
+				if tc.hang && d.RemediationError != errReceiverHung {
+					t.Errorf("Alert(): RemediationError %v, want %v", d.RemediationError, errReceiverHung)
+				}
+				if d.Resigned != tc.elect {
+					t.Errorf("Alert(): Resigned %v, want %v", d.Resigned, tc.elect)
+				}
+			}
+			if tc.elect && !tc.otherLeader && e.Acquired != tc.wantRestarts {
+				t.Errorf("acquired leadership %v times, want %v", e.Acquired, tc.wantRestarts)
+			}
+			if tc.elect && e.Leading["domain"] == (tc.wantAlerts > 0 || tc.otherLeader) {
+				t.Errorf("Leading: %v after %v alerts", e.Leading["domain"], tc.wantAlerts)
+			}
+		})
+	}
+}
+
+func TestUpdateIntervals(t *testing.T) {
+	ctx := context.Background()
+	domains := fake.NewDomainStorage()
+	if err := domains.Write(ctx, &domain.Domain{
+		DomainID:    "domain",
+		MinInterval: time.Second,
+		MaxInterval: 10 * time.Minute,
+	}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	queue := &testQueue{}
+	s := New(nil, &stalledMap{rootTime: time.Now()}, nil, domains, nil, queue, nil, nil, nil)
+
+	// Domains without a receiver are left to ListenForNewDomains.
+	if err := s.UpdateIntervals(ctx, "domain"); err != nil {
+		t.Fatalf("UpdateIntervals(not started): %v", err)
+	}
+	if queue.started != 0 {
+		t.Errorf("UpdateIntervals(not started) started %v receivers, want 0", queue.started)
+	}
+
+	first := &testReceiver{}
+	s.receivers["domain"] = first
+	if err := domains.SetIntervals(ctx, "domain", 5*time.Second, time.Hour); err != nil {
+		t.Fatalf("SetIntervals(): %v", err)
+	}
+	if err := s.UpdateIntervals(ctx, "domain"); err != nil {
+		t.Fatalf("UpdateIntervals(): %v", err)
+	}
+	if !first.closed {
+		t.Errorf("old receiver was not closed")
+	}
+	if queue.started != 1 || queue.opts.Period != 5*time.Second || queue.opts.MaxPeriod != time.Hour {
+		t.Errorf("started %v receivers with %v-%v, want 1 with 5s-1h", queue.started, queue.opts.Period, queue.opts.MaxPeriod)
+	}
+}
+
+// recordingSink records the alerts it is sent.
+type recordingSink struct {
+	alerts []*alert.Alert
+}
+
+func (s *recordingSink) Send(_ context.Context, a *alert.Alert) error {
+	s.alerts = append(s.alerts, a)
+	return nil
+}
+
+func TestDispatcherAlerter(t *testing.T) {
+	sink := &recordingSink{}
+	alerter := DispatcherAlerter(alert.NewDispatcher(time.Hour, alert.Route{Sink: sink, MinSeverity: alert.Critical}))
+	d := &Diagnosis{DomainID: "domain", MapRevision: 7, Time: time.Now()}
+	for i := 0; i < 2; i++ {
+		if err := alerter.Alert(context.Background(), d); err != nil {
+			t.Fatalf("Alert(): %v", err)
+		}
+	}
+	if len(sink.alerts) != 1 {
+		t.Fatalf("sent %v alerts, want 1 after deduplication", len(sink.alerts))
+	}
+	if got := sink.alerts[0]; got.Severity != alert.Critical || got.Key != "stall/domain/7" || got.Details != d.String() {
+		t.Errorf("Alert(): %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sim models the behavior of a Key Transparency domain under load.
+//
+// The simulator replays the batching rules of the sequencer against a
+// synthetic stream of mutations so that operators can pick MinInterval,
+// MaxInterval and hardware sizing before deploying a domain.
+package sim
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/keytransparency/core/sequencer"
+)
+
+const (
+	// hashSize is the size of a node hash in map and log proofs.
+	hashSize = 32
+	// vrfProofSize is the size of a P256 VRF proof.
+	vrfProofSize = 81
+)
+
+var (
+	// ErrInvalidConfig occurs when a Config cannot be simulated.
+	ErrInvalidConfig = errors.New("sim: invalid config")
+)
+
+// Latency models the time taken by Trillian to create an epoch.
+type Latency struct {
+	// Epoch is the fixed cost of creating an epoch: reading the map root,
+	// signing the new map root and queueing it in the log.
+	Epoch time.Duration
+	// PerMutation is the cost of reading, mutating and writing one map leaf.
+	PerMutation time.Duration
+	// LogIntegration is the time between queueing a map root in the log
+	// and the log publishing a root that includes it.
+	LogIntegration time.Duration
+}
+
+// Config describes a domain and its workload.
+type Config struct {
+	// MutationsPerSecond is the mean arrival rate of mutations.
+	// Arrivals follow a Poisson process.
+	MutationsPerSecond float64
+	// Users is the number of distinct users that send mutations.
+	Users int64
+	// MinInterval and MaxInterval are the domain's epoch intervals.
+	MinInterval, MaxInterval time.Duration
+	// BatchSize is the maximum number of mutations per epoch.
+	// Defaults to sequencer.MaxBatchSize.
+	BatchSize int32
+	// Latency models Trillian.
+	Latency Latency
+	// MutationBytes is the storage used by one mutation, including the
+	// map leaf and the mutation log entry.
+	MutationBytes int64
+	// EpochBytes is the storage used by one epoch, including the signed
+	// map root and its log leaf.
+	EpochBytes int64
+	// Duration is the amount of simulated time.
+	Duration time.Duration
+	// Seed makes simulations reproducible.
+	Seed int64
+}
+
+// Result holds the projections of a simulation.
+type Result struct {
+	// Epochs is the number of epochs created.
+	Epochs int64
+	// EmptyEpochs is the number of epochs created without mutations.
+	EmptyEpochs int64
+	// Mutations is the number of mutations sequenced.
+	Mutations int64
+	// Pending is the number of mutations still queued at the end.
+	Pending int64
+	// MaxQueueDepth is the largest number of queued mutations.
+	MaxQueueDepth int64
+	// MeanLag, P99Lag and MaxLag describe the time between a mutation
+	// arriving and a log root that includes it being published.
+	MeanLag, P99Lag, MaxLag time.Duration
+	// Utilization is the fraction of time spent creating epochs.
+	Utilization float64
+	// StorageBytes is the projected storage used by the domain.
+	StorageBytes int64
+	// StorageBytesPerDay is StorageBytes normalized to one day.
+	StorageBytesPerDay float64
+	// ProofBytes is the projected size of a GetEntry proof at the end
+	// of the simulation.
+	ProofBytes int64
+}
+
+// Run simulates cfg and returns the projected behavior of the domain.
+func Run(cfg Config) (*Result, error) {
+	if cfg.MutationsPerSecond < 0 ||
+		cfg.MinInterval <= 0 ||
+		cfg.MaxInterval < cfg.MinInterval ||
+		cfg.Duration <= 0 ||
+		cfg.BatchSize < 0 {
+		return nil, ErrInvalidConfig
+	}
+	batchSize := cfg.BatchSize
+	if batchSize == 0 {
+		batchSize = sequencer.MaxBatchSize
+	}
+	users := cfg.Users
+	if users <= 0 {
+		users = 1
+	}
+
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+	arrivals := arrivalTimes(rnd, cfg.MutationsPerSecond, cfg.Duration)
+
+	r := &Result{}
+	var lags []time.Duration
+	var busy time.Duration
+	var queue []time.Duration // Arrival times of queued mutations.
+	next := 0                 // Next arrival to enqueue.
+	active := make(map[int64]bool)
+
+	// Like the queue receiver, the sequencer wakes up every MinInterval
+	// and creates an epoch if there are mutations, and creates an epoch
+	// regardless every MaxInterval. Ticks that occur while an epoch is
+	// being created are dropped.
+	now := time.Duration(0)
+	lastMax := time.Duration(0)
+	for now < cfg.Duration {
+		for next < len(arrivals) && arrivals[next] <= now {
+			queue = append(queue, arrivals[next])
+			next++
+		}
+		if d := int64(len(queue)); d > r.MaxQueueDepth {
+			r.MaxQueueDepth = d
+		}
+
+		force := now-lastMax >= cfg.MaxInterval
+		if len(queue) == 0 && !force {
+			now += cfg.MinInterval
+			continue
+		}
+		if force {
+			lastMax = now
+		}
+
+		n := len(queue)
+		if n > int(batchSize) {
+			n = int(batchSize)
+		}
+		batch := queue[:n]
+		queue = queue[n:]
+
+		elapsed := cfg.Latency.Epoch + time.Duration(n)*cfg.Latency.PerMutation
+		busy += elapsed
+		published := now + elapsed + cfg.Latency.LogIntegration
+		for _, a := range batch {
+			lags = append(lags, published-a)
+			active[rnd.Int63n(users)] = true
+		}
+		r.Epochs++
+		r.Mutations += int64(n)
+		if n == 0 {
+			r.EmptyEpochs++
+		}
+
+		if n == int(batchSize) {
+			// Continue sending until we drop below batch size.
+			now += elapsed
+			continue
+		}
+		// Wait for the next tick that is not dropped.
+		ticks := elapsed/cfg.MinInterval + 1
+		now += ticks * cfg.MinInterval
+	}
+	r.Pending = int64(len(queue) + len(arrivals) - next)
+
+	r.MeanLag, r.P99Lag, r.MaxLag = lagStats(lags)
+	r.Utilization = float64(busy) / float64(cfg.Duration)
+	if r.Utilization > 1 {
+		r.Utilization = 1
+	}
+	r.StorageBytes = r.Mutations*cfg.MutationBytes + r.Epochs*cfg.EpochBytes
+	r.StorageBytesPerDay = float64(r.StorageBytes) * float64(24*time.Hour) / float64(cfg.Duration)
+	r.ProofBytes = proofBytes(int64(len(active)), r.Epochs)
+	return r, nil
+}
+
+// arrivalTimes returns the arrival times of a Poisson process with the given
+// rate over duration.
+func arrivalTimes(rnd *rand.Rand, perSecond float64, duration time.Duration) []time.Duration {
+	if perSecond == 0 {
+		return nil
+	}
+	var ret []time.Duration
+	t := time.Duration(0)
+	for {
+		t += time.Duration(rnd.ExpFloat64() / perSecond * float64(time.Second))
+		if t >= duration {
+			return ret
+		}
+		ret = append(ret, t)
+	}
+}
+
+// lagStats returns the mean, 99th percentile and maximum of lags.
+func lagStats(lags []time.Duration) (mean, p99, max time.Duration) {
+	if len(lags) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(lags, func(i, j int) bool { return lags[i] < lags[j] })
+	var sum time.Duration
+	for _, l := range lags {
+		sum += l
+	}
+	mean = sum / time.Duration(len(lags))
+	p99 = lags[len(lags)*99/100]
+	return mean, p99, lags[len(lags)-1]
+}
+
+// proofBytes estimates the size of the proofs in a GetEntry response for a
+// map with the given number of leaves and a log with the given number of
+// epochs. A sparse merkle tree with n random leaves has about log2(n)
+// non-empty neighbors on the path to any leaf.
+func proofBytes(leaves, epochs int64) int64 {
+	return vrfProofSize + hashSize*(depth(leaves)+depth(epochs))
+}
+
+// depth returns ceil(log2(n)) for n > 1, and 0 otherwise.
+func depth(n int64) int64 {
+	if n <= 1 {
+		return 0
+	}
+	return int64(math.Ceil(math.Log2(float64(n))))
+}
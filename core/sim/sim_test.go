@@ -0,0 +1,149 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sim
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var latency = Latency{
+	Epoch:          200 * time.Millisecond,
+	PerMutation:    time.Millisecond,
+	LogIntegration: time.Second,
+}
+
+func TestRunInvalid(t *testing.T) {
+	valid := Config{
+		MinInterval: time.Second,
+		MaxInterval: time.Minute,
+		Duration:    time.Hour,
+	}
+	for _, tc := range []struct {
+		desc   string
+		modify func(c *Config)
+	}{
+		{desc: "negative rate", modify: func(c *Config) { c.MutationsPerSecond = -1 }},
+		{desc: "zero min interval", modify: func(c *Config) { c.MinInterval = 0 }},
+		{desc: "max below min", modify: func(c *Config) { c.MaxInterval = time.Millisecond }},
+		{desc: "zero duration", modify: func(c *Config) { c.Duration = 0 }},
+		{desc: "negative batch", modify: func(c *Config) { c.BatchSize = -1 }},
+	} {
+		cfg := valid
+		tc.modify(&cfg)
+		if _, err := Run(cfg); err != ErrInvalidConfig {
+			t.Errorf("%v: Run(): %v, want %v", tc.desc, err, ErrInvalidConfig)
+		}
+	}
+	if _, err := Run(valid); err != nil {
+		t.Errorf("Run(valid): %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for _, tc := range []struct {
+		desc       string
+		cfg        Config
+		check      func(r *Result) bool
+		wantStable bool
+	}{
+		{
+			desc: "idle domain",
+			cfg: Config{
+				MinInterval: time.Second,
+				MaxInterval: time.Minute,
+				Latency:     latency,
+				EpochBytes:  500,
+				Duration:    time.Hour,
+			},
+			check: func(r *Result) bool {
+				// Only the MaxInterval epochs are created.
+				return r.Epochs == r.EmptyEpochs && r.Epochs >= 59 && r.Epochs <= 60 &&
+					r.StorageBytes == r.Epochs*500
+			},
+			wantStable: true,
+		},
+		{
+			desc: "light load",
+			cfg: Config{
+				MutationsPerSecond: 10,
+				Users:              1000,
+				MinInterval:        time.Second,
+				MaxInterval:        time.Minute,
+				Latency:            latency,
+				Duration:           time.Hour,
+			},
+			check: func(r *Result) bool {
+				// A mutation waits at most one tick, one epoch and log integration.
+				return r.MaxLag < 3*time.Second && r.EmptyEpochs == 0
+			},
+			wantStable: true,
+		},
+		{
+			desc: "overload",
+			cfg: Config{
+				MutationsPerSecond: 2000,
+				MinInterval:        time.Second,
+				MaxInterval:        time.Minute,
+				BatchSize:          100,
+				Latency:            latency,
+				Duration:           10 * time.Minute,
+			},
+			check: func(r *Result) bool {
+				return r.Utilization > 0.99 && r.Pending > 0 && r.MaxLag > time.Minute
+			},
+			wantStable: false,
+		},
+	} {
+		r, err := Run(tc.cfg)
+		if err != nil {
+			t.Errorf("%v: Run(): %v", tc.desc, err)
+			continue
+		}
+		if !tc.check(r) {
+			t.Errorf("%v: Run(): %+v", tc.desc, r)
+		}
+		// A stable domain keeps up with arrivals.
+		if got := r.MaxQueueDepth < int64(tc.cfg.MutationsPerSecond*10)+1; got != tc.wantStable {
+			t.Errorf("%v: stable: %v, want %v", tc.desc, got, tc.wantStable)
+		}
+		// Runs are reproducible.
+		r2, err := Run(tc.cfg)
+		if err != nil {
+			t.Errorf("%v: Run(): %v", tc.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(r, r2) {
+			t.Errorf("%v: Run() not deterministic: %+v != %+v", tc.desc, r, r2)
+		}
+	}
+}
+
+func TestProofBytes(t *testing.T) {
+	for _, tc := range []struct {
+		leaves, epochs int64
+		want           int64
+	}{
+		{leaves: 0, epochs: 0, want: vrfProofSize},
+		{leaves: 1, epochs: 1, want: vrfProofSize},
+		{leaves: 2, epochs: 1, want: vrfProofSize + hashSize},
+		{leaves: 1024, epochs: 1000, want: vrfProofSize + 20*hashSize},
+	} {
+		if got := proofBytes(tc.leaves, tc.epochs); got != tc.want {
+			t.Errorf("proofBytes(%v, %v): %v, want %v", tc.leaves, tc.epochs, got, tc.want)
+		}
+	}
+}
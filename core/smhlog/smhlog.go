@@ -0,0 +1,203 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package smhlog abstracts the logs that signed map heads (SMHs) are
+// anchored in.
+//
+// The sequencer appends every map root of a domain to the domain's SMH log,
+// and clients verify that map roots are included in it. By default the log is
+// a tree of the Trillian instance that Key Transparency is deployed with. A
+// domain can instead name another backend, such as a Trillian instance run
+// by a different operator or a log personality that serves the Trillian log
+// API on top of another log. Clients verify the log with the hash strategy
+// and public key that the backend reports for the tree, so backends must sign
+// log roots in the Trillian format.
+package smhlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	tpb "github.com/google/trillian"
+)
+
+// Default is the name of the backend of domains that do not name one.
+const Default = ""
+
+// rootsPageSize is the number of leaves Roots reads at a time.
+const rootsPageSize = 1000
+
+// ErrUnknownBackend occurs when a domain names a backend that is not
+// configured.
+var ErrUnknownBackend = errors.New("smhlog: unknown backend")
+
+// Client is the part of the Trillian log API that is used to append, read and
+// prove the inclusion of SMHs. tpb.TrillianLogClient implements it.
+type Client interface {
+	QueueLeaf(ctx context.Context, in *tpb.QueueLeafRequest, opts ...grpc.CallOption) (*tpb.QueueLeafResponse, error)
+	GetLatestSignedLogRoot(ctx context.Context, in *tpb.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*tpb.GetLatestSignedLogRootResponse, error)
+	GetInclusionProof(ctx context.Context, in *tpb.GetInclusionProofRequest, opts ...grpc.CallOption) (*tpb.GetInclusionProofResponse, error)
+	GetConsistencyProof(ctx context.Context, in *tpb.GetConsistencyProofRequest, opts ...grpc.CallOption) (*tpb.GetConsistencyProofResponse, error)
+	GetLeavesByRange(ctx context.Context, in *tpb.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*tpb.GetLeavesByRangeResponse, error)
+}
+
+// Backend is a service that hosts SMH logs.
+type Backend struct {
+	// Log appends SMHs and serves proofs.
+	Log Client
+	// Admin manages the trees of the backend. It is nil if the trees are
+	// managed by another operator, in which case Trees describes them.
+	Admin tpb.TrillianAdminClient
+	// Trees holds the parameters of the logs of backends without Admin, by
+	// tree ID.
+	Trees map[int64]*tpb.Tree
+}
+
+// Managed returns true if Key Transparency manages the trees of b.
+func (b *Backend) Managed() bool {
+	return b.Admin != nil
+}
+
+// Tree returns the parameters of the log with logID, which clients use to
+// verify it.
+func (b *Backend) Tree(ctx context.Context, logID int64) (*tpb.Tree, error) {
+	if b.Admin != nil {
+		return b.Admin.GetTree(ctx, &tpb.GetTreeRequest{TreeId: logID})
+	}
+	t, ok := b.Trees[logID]
+	if !ok {
+		return nil, fmt.Errorf("smhlog: log %v is not configured", logID)
+	}
+	return t, nil
+}
+
+// Backends are the SMH log backends of a deployment, by name.
+type Backends map[string]*Backend
+
+// WithDefault returns a copy of b in which the Default backend is log and
+// admin, unless b already has a Default backend.
+func (b Backends) WithDefault(log Client, admin tpb.TrillianAdminClient) Backends {
+	ret := Backends{Default: {Log: log, Admin: admin}}
+	for name, backend := range b {
+		ret[name] = backend
+	}
+	return ret
+}
+
+// Get returns the backend called name.
+func (b Backends) Get(name string) (*Backend, error) {
+	backend, ok := b[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownBackend, name)
+	}
+	return backend, nil
+}
+
+// Names returns the names of the backends other than Default, in order.
+func (b Backends) Names() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		if name != Default {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseAddrs parses a comma separated list of name=address pairs naming the
+// Trillian instances that serve alternate backends.
+func ParseAddrs(s string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if s == "" {
+		return ret, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("smhlog: %q is not of the form name=address", pair)
+		}
+		if _, ok := ret[kv[0]]; ok {
+			return nil, fmt.Errorf("smhlog: backend %q listed twice", kv[0])
+		}
+		ret[kv[0]] = kv[1]
+	}
+	return ret, nil
+}
+
+// QueueRoot appends smr to the log with logID. The leaf is the JSON
+// encoding of smr.
+func QueueRoot(ctx context.Context, log Client, logID int64, smr *tpb.SignedMapRoot) error {
+	smrJSON, err := json.Marshal(smr)
+	if err != nil {
+		return err
+	}
+	idHash := sha256.Sum256(smrJSON)
+
+	if _, err := log.QueueLeaf(ctx, &tpb.QueueLeafRequest{
+		LogId: logID,
+		Leaf: &tpb.LogLeaf{
+			LeafValue:        smrJSON,
+			LeafIdentityHash: idHash[:],
+		},
+	}); err != nil {
+		return fmt.Errorf("trillianLog.QueueLeaf(logID: %v, leaf: %v): %w",
+			logID, smrJSON, err)
+	}
+	return nil
+}
+
+// Roots returns the map roots in the log with logID, by map revision. Only
+// leaves included in the latest log root are read.
+func Roots(ctx context.Context, log Client, logID int64) (map[int64]*tpb.SignedMapRoot, error) {
+	rootResp, err := log.GetLatestSignedLogRoot(ctx, &tpb.GetLatestSignedLogRootRequest{LogId: logID})
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestSignedLogRoot(%v): %w", logID, err)
+	}
+	size := rootResp.GetSignedLogRoot().GetTreeSize()
+	ret := make(map[int64]*tpb.SignedMapRoot)
+	for start := int64(0); start < size; {
+		count := size - start
+		if count > rootsPageSize {
+			count = rootsPageSize
+		}
+		resp, err := log.GetLeavesByRange(ctx, &tpb.GetLeavesByRangeRequest{
+			LogId:      logID,
+			StartIndex: start,
+			Count:      count,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("GetLeavesByRange(%v, %v, %v): %w", logID, start, count, err)
+		}
+		if len(resp.GetLeaves()) == 0 {
+			return nil, fmt.Errorf("smhlog: log %v has no leaf at index %v", logID, start)
+		}
+		for _, l := range resp.GetLeaves() {
+			var smr tpb.SignedMapRoot
+			if err := json.Unmarshal(l.GetLeafValue(), &smr); err != nil {
+				return nil, fmt.Errorf("smhlog: leaf %v of log %v: %w", start, logID, err)
+			}
+			ret[smr.GetMapRevision()] = &smr
+			start++
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smhlog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/fake"
+
+	tpb "github.com/google/trillian"
+)
+
+func TestBackends(t *testing.T) {
+	ctx := context.Background()
+	defaultLog := fake.NewTrillianLogClient()
+	other := &Backend{
+		Log:   fake.NewTrillianLogClient(),
+		Trees: map[int64]*tpb.Tree{1: {TreeId: 1}},
+	}
+	backends := Backends{"other": other}.WithDefault(defaultLog, nil)
+
+	if got, want := backends.Names(), []string{"other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names(): %v, want %v", got, want)
+	}
+	if b, err := backends.Get(Default); err != nil || b.Log != defaultLog {
+		t.Errorf("Get(Default): %v, %v, want the default log", b, err)
+	}
+	if _, err := backends.Get("missing"); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("Get(missing): %v, want %v", err, ErrUnknownBackend)
+	}
+	if other.Managed() {
+		t.Errorf("Managed(): true, want false")
+	}
+	if tree, err := other.Tree(ctx, 1); err != nil || tree.GetTreeId() != 1 {
+		t.Errorf("Tree(1): %v, %v, want tree 1", tree, err)
+	}
+	if _, err := other.Tree(ctx, 2); err == nil {
+		t.Errorf("Tree(2): nil, want error")
+	}
+}
+
+func TestParseAddrs(t *testing.T) {
+	for _, tc := range []struct {
+		addrs   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{addrs: "", want: map[string]string{}},
+		{addrs: "a=host:1", want: map[string]string{"a": "host:1"}},
+		{addrs: "a=host:1,b=host:2", want: map[string]string{"a": "host:1", "b": "host:2"}},
+		{addrs: "a", wantErr: true},
+		{addrs: "=host:1", wantErr: true},
+		{addrs: "a=", wantErr: true},
+		{addrs: "a=host:1,a=host:2", wantErr: true},
+	} {
+		got, err := ParseAddrs(tc.addrs)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseAddrs(%q): %v, wantErr %v", tc.addrs, err, tc.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseAddrs(%q): %v, want %v", tc.addrs, got, tc.want)
+		}
+	}
+}
+
+func TestQueueRoot(t *testing.T) {
+	log := fake.NewTrillianLogClient()
+	for i := int64(1); i <= 2; i++ {
+		if err := QueueRoot(context.Background(), log, 1, &tpb.SignedMapRoot{MapRevision: i}); err != nil {
+			t.Fatalf("QueueRoot(%v): %v", i, err)
+		}
+		if got := log.TreeSize; got != i {
+			t.Errorf("TreeSize: %v, want %v", got, i)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	ctx := context.Background()
+	log := fake.NewTrillianLogClient()
+	for i := int64(0); i <= 2; i++ {
+		if err := QueueRoot(ctx, log, 1, &tpb.SignedMapRoot{MapRevision: i, RootHash: []byte{byte(i)}}); err != nil {
+			t.Fatalf("QueueRoot(%v): %v", i, err)
+		}
+	}
+	roots, err := Roots(ctx, log, 1)
+	if err != nil {
+		t.Fatalf("Roots(): %v", err)
+	}
+	if got, want := len(roots), 3; got != want {
+		t.Errorf("Roots(): %v roots, want %v", got, want)
+	}
+	for i := int64(0); i <= 2; i++ {
+		if got := roots[i].GetRootHash(); !reflect.DeepEqual(got, []byte{byte(i)}) {
+			t.Errorf("Roots()[%v]: root hash %x, want %x", i, got, []byte{byte(i)})
+		}
+	}
+
+	// Leaves beyond the stored ones cannot be read.
+	log.TreeSize++
+	if _, err := Roots(ctx, log, 1); err == nil {
+		t.Errorf("Roots(missing leaf): nil, want error")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trillianpool shares gRPC connections to Trillian clusters.
+//
+// Domains can be hosted on Trillian clusters other than the one Key
+// Transparency is deployed with. The pool dials each cluster once, when a
+// domain hosted on it is first used, and reuses the connection for every
+// other domain on the same cluster.
+package trillianpool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	tpb "github.com/google/trillian"
+)
+
+// ErrClosed occurs when a closed pool is used.
+var ErrClosed = errors.New("trillianpool: pool is closed")
+
+// DialFunc connects to the Trillian cluster at addr.
+type DialFunc func(addr string) (*grpc.ClientConn, error)
+
+// Pool holds one connection per Trillian cluster, keyed by address.
+type Pool struct {
+	dial DialFunc
+
+	mu     sync.Mutex
+	conns  map[string]*grpc.ClientConn
+	closed bool
+}
+
+// New returns a pool that connects to clusters with dial.
+func New(dial DialFunc) *Pool {
+	return &Pool{
+		dial:  dial,
+		conns: make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Conn returns the connection to the cluster at addr, dialing it if the pool
+// has none. Failed dials are not cached.
+func (p *Pool) Conn(addr string) (*grpc.ClientConn, error) {
+	if addr == "" {
+		return nil, errors.New("trillianpool: missing address")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrClosed
+	}
+	if conn, ok := p.conns[addr]; ok {
+		return conn, nil
+	}
+	conn, err := p.dial(addr)
+	if err != nil {
+		return nil, fmt.Errorf("trillianpool: dial(%v): %w", addr, err)
+	}
+	p.conns[addr] = conn
+	return conn, nil
+}
+
+// Log returns a log client of the cluster at addr.
+func (p *Pool) Log(addr string) (tpb.TrillianLogClient, error) {
+	conn, err := p.Conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return tpb.NewTrillianLogClient(conn), nil
+}
+
+// Map returns a map client of the cluster at addr.
+func (p *Pool) Map(addr string) (tpb.TrillianMapClient, error) {
+	conn, err := p.Conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return tpb.NewTrillianMapClient(conn), nil
+}
+
+// Admin returns an admin client of the cluster at addr.
+func (p *Pool) Admin(addr string) (tpb.TrillianAdminClient, error) {
+	conn, err := p.Conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return tpb.NewTrillianAdminClient(conn), nil
+}
+
+// Addrs returns the addresses of the clusters the pool is connected to, in
+// order.
+func (p *Pool) Addrs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	addrs := make([]string, 0, len(p.conns))
+	for addr := range p.conns {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// Close closes every connection of the pool. The pool cannot be used
+// afterwards.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var firstErr error
+	for addr, conn := range p.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("trillianpool: Close(%v): %w", addr, err)
+		}
+		delete(p.conns, addr)
+	}
+	return firstErr
+}
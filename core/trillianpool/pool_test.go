@@ -0,0 +1,66 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trillianpool
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestConn(t *testing.T) {
+	dials := make(map[string]int)
+	p := New(func(addr string) (*grpc.ClientConn, error) {
+		dials[addr]++
+		if addr == "unreachable:1" {
+			return nil, errors.New("connection refused")
+		}
+		return grpc.Dial(addr, grpc.WithInsecure())
+	})
+
+	first, err := p.Conn("a:1")
+	if err != nil {
+		t.Fatalf("Conn(a:1): %v", err)
+	}
+	if again, err := p.Conn("a:1"); err != nil || again != first {
+		t.Errorf("Conn(a:1) again: %v, %v, want the pooled connection", again, err)
+	}
+	if _, err := p.Map("b:1"); err != nil {
+		t.Errorf("Map(b:1): %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := p.Admin("unreachable:1"); err == nil {
+			t.Errorf("Admin(unreachable:1): nil, want error")
+		}
+	}
+	if _, err := p.Log(""); err == nil {
+		t.Errorf("Log(\"\"): nil, want error")
+	}
+	if want := map[string]int{"a:1": 1, "b:1": 1, "unreachable:1": 2}; !reflect.DeepEqual(dials, want) {
+		t.Errorf("dials: %v, want %v", dials, want)
+	}
+	if got, want := p.Addrs(), []string{"a:1", "b:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Addrs(): %v, want %v", got, want)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if _, err := p.Conn("a:1"); err != ErrClosed {
+		t.Errorf("Conn(a:1) after Close(): %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version identifies the build of the running binary.
+package version
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"sync"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+// Version is the release version of this binary. It is set at build time with:
+// -ldflags "-X github.com/google/keytransparency/core/version.Version=<version>"
+var Version = "dev"
+
+var (
+	digestOnce sync.Once
+	digest     []byte
+	digestErr  error
+)
+
+// BinaryDigest returns the SHA256 digest of the running executable.
+func BinaryDigest() ([]byte, error) {
+	digestOnce.Do(func() {
+		digest, digestErr = hashExecutable()
+	})
+	return digest, digestErr
+}
+
+func hashExecutable() ([]byte, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Proto returns the version of the running binary.
+func Proto() (*pb.ServerVersion, error) {
+	d, err := BinaryDigest()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ServerVersion{
+		Version:      Version,
+		BinaryDigest: d,
+	}, nil
+}
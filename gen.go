@@ -15,3 +15,4 @@
 package keytranspraency
 
 //go:generate protoc -I=. --go_out=:$GOPATH/src impl/authorization/authz_proto/authz.proto
+//go:generate protoc -I=. --go_out=:$GOPATH/src impl/google/kms/kms_proto/kms.proto
@@ -0,0 +1,117 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+
+	kmspb "github.com/google/keytransparency/impl/google/kms/kms_proto"
+)
+
+// VRFKeyGen returns a keys.ProtoGenerator that generates private keys in
+// memory and returns them encrypted with the symmetric key cryptoKey as
+// *kmspb.EncryptedKey messages.
+func (c *Client) VRFKeyGen(cryptoKey string) keys.ProtoGenerator {
+	return func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+		k, err := der.NewProtoFromSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		ciphertext, err := c.Encrypt(ctx, cryptoKey, k.GetDer())
+		if err != nil {
+			return nil, err
+		}
+		return &kmspb.EncryptedKey{CryptoKey: cryptoKey, Ciphertext: ciphertext}, nil
+	}
+}
+
+// TreeKeyGen returns a keys.ProtoGenerator that creates a new asymmetric
+// signing key in keyRing for each call and returns it as a
+// *kmspb.CryptoKeyVersion message.
+func (c *Client) TreeKeyGen(keyRing string) keys.ProtoGenerator {
+	return func(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
+		alg, err := algorithm(spec)
+		if err != nil {
+			return nil, err
+		}
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		name, err := c.createSigningKey(ctx, keyRing, "kt-"+hex.EncodeToString(id), alg)
+		if err != nil {
+			return nil, err
+		}
+		return &kmspb.CryptoKeyVersion{Name: name}, nil
+	}
+}
+
+// algorithm returns the KMS algorithm for keys of spec. Trillian signs with
+// SHA-256 regardless of the key, so only SHA-256 algorithms are used.
+func algorithm(spec *keyspb.Specification) (string, error) {
+	switch p := spec.GetParams().(type) {
+	case *keyspb.Specification_EcdsaParams:
+		switch c := p.EcdsaParams.GetCurve(); c {
+		case keyspb.Specification_ECDSA_DEFAULT_CURVE, keyspb.Specification_ECDSA_P256:
+			return "EC_SIGN_P256_SHA256", nil
+		default:
+			return "", fmt.Errorf("kms: unsupported curve %v", c)
+		}
+	case *keyspb.Specification_RsaParams:
+		switch bits := p.RsaParams.GetBits(); bits {
+		case 0, 2048:
+			return "RSA_SIGN_PKCS1_2048_SHA256", nil
+		case 3072, 4096:
+			return fmt.Sprintf("RSA_SIGN_PKCS1_%d_SHA256", bits), nil
+		default:
+			return "", fmt.Errorf("kms: unsupported RSA key size %v", bits)
+		}
+	default:
+		return "", fmt.Errorf("kms: unsupported key specification %v", spec)
+	}
+}
+
+// RegisterHandlers lets keys.NewSigner resolve the keys generated by this
+// package using c. It must be called by every server that uses them,
+// including the Trillian servers that sign with tree keys.
+func RegisterHandlers(c *Client) {
+	keys.RegisterHandler(&kmspb.CryptoKeyVersion{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		k, ok := pb.(*kmspb.CryptoKeyVersion)
+		if !ok {
+			return nil, fmt.Errorf("kms: got %T, want *kmspb.CryptoKeyVersion", pb)
+		}
+		return c.NewSigner(ctx, k.GetName())
+	})
+	keys.RegisterHandler(&kmspb.EncryptedKey{}, func(ctx context.Context, pb proto.Message) (crypto.Signer, error) {
+		k, ok := pb.(*kmspb.EncryptedKey)
+		if !ok {
+			return nil, fmt.Errorf("kms: got %T, want *kmspb.EncryptedKey", pb)
+		}
+		plaintext, err := c.Decrypt(ctx, k.GetCryptoKey(), k.GetCiphertext())
+		if err != nil {
+			return nil, err
+		}
+		return der.UnmarshalPrivateKey(plaintext)
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kms keeps Key Transparency's private keys in Google Cloud KMS.
+//
+// Map and log signing keys are KMS asymmetric keys; signing happens inside
+// KMS and the private keys never leave it. VRF keys cannot be evaluated by
+// KMS, so they are encrypted with a KMS symmetric key before they are stored
+// and are only decrypted in memory when a VRF is evaluated.
+//
+// Servers that use keys produced by this package must call RegisterHandlers
+// so that Trillian's keys.NewSigner can resolve them.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// Scope is the OAuth scope needed to use Cloud KMS.
+const Scope = "https://www.googleapis.com/auth/cloudkms"
+
+// ErrNotEnabled occurs when a key version cannot be used, e.g. because it
+// has been disabled or destroyed.
+var ErrNotEnabled = errors.New("kms: key version is not enabled")
+
+// Client calls the Cloud KMS API.
+type Client struct {
+	hc       *http.Client
+	endpoint string
+	// pollInterval is the time between checks of a key version that is
+	// being generated.
+	pollInterval time.Duration
+}
+
+// New returns a Client that sends requests with hc, which must attach
+// credentials to them.
+func New(hc *http.Client) *Client {
+	return &Client{
+		hc:           hc,
+		endpoint:     "https://cloudkms.googleapis.com/v1/",
+		pollInterval: time.Second,
+	}
+}
+
+// NewDefault returns a Client using Application Default Credentials.
+func NewDefault(ctx context.Context) (*Client, error) {
+	hc, err := google.DefaultClient(ctx, Scope)
+	if err != nil {
+		return nil, fmt.Errorf("kms: default credentials: %w", err)
+	}
+	return New(hc), nil
+}
+
+// call sends in to the API method at path and decodes the response into out.
+func (c *Client) call(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("kms: %v %v: %v %v", method, path, resp.Status, e.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Encrypt encrypts plaintext with the symmetric key cryptoKey.
+func (c *Client) Encrypt(ctx context.Context, cryptoKey string, plaintext []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	req := map[string][]byte{"plaintext": plaintext}
+	if err := c.call(ctx, "POST", cryptoKey+":encrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt with the same key.
+func (c *Client) Decrypt(ctx context.Context, cryptoKey string, ciphertext []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	req := map[string][]byte{"ciphertext": ciphertext}
+	if err := c.call(ctx, "POST", cryptoKey+":decrypt", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// asymmetricSign signs digest, which was computed with hash, with the key
+// version name.
+func (c *Client) asymmetricSign(ctx context.Context, name, hash string, digest []byte) ([]byte, error) {
+	var resp struct {
+		Signature []byte `json:"signature"`
+	}
+	req := map[string]map[string]string{
+		"digest": {hash: base64.StdEncoding.EncodeToString(digest)},
+	}
+	if err := c.call(ctx, "POST", name+":asymmetricSign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// publicKeyPEM returns the PEM encoded public key of the key version name.
+func (c *Client) publicKeyPEM(ctx context.Context, name string) ([]byte, error) {
+	var resp struct {
+		PEM string `json:"pem"`
+	}
+	if err := c.call(ctx, "GET", name+"/publicKey", nil, &resp); err != nil {
+		return nil, err
+	}
+	return []byte(resp.PEM), nil
+}
+
+// createSigningKey creates the asymmetric key id in keyRing and waits for
+// its first version to be generated. The name of the version is returned.
+func (c *Client) createSigningKey(ctx context.Context, keyRing, id, algorithm string) (string, error) {
+	req := map[string]interface{}{
+		"purpose": "ASYMMETRIC_SIGN",
+		"versionTemplate": map[string]string{
+			"algorithm":       algorithm,
+			"protectionLevel": "HSM",
+		},
+	}
+	var key struct {
+		Name string `json:"name"`
+	}
+	if err := c.call(ctx, "POST", keyRing+"/cryptoKeys?cryptoKeyId="+id, req, &key); err != nil {
+		return "", err
+	}
+	name := key.Name + "/cryptoKeyVersions/1"
+	for {
+		var version struct {
+			State string `json:"state"`
+		}
+		if err := c.call(ctx, "GET", name, nil, &version); err != nil {
+			return "", err
+		}
+		switch version.State {
+		case "ENABLED":
+			return name, nil
+		case "PENDING_GENERATION":
+		default:
+			return "", fmt.Errorf("%v: %v is %v", ErrNotEnabled, name, version.State)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
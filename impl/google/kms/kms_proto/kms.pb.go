@@ -0,0 +1,102 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: impl/google/kms/kms_proto/kms.proto
+
+/*
+Package kms_proto is a generated protocol buffer package.
+
+It is generated from these files:
+	impl/google/kms/kms_proto/kms.proto
+
+It has these top-level messages:
+	CryptoKeyVersion
+	EncryptedKey
+*/
+package kms_proto
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// CryptoKeyVersion identifies an asymmetric signing key held by Google Cloud
+// KMS. The private key never leaves KMS.
+type CryptoKeyVersion struct {
+	// name is the resource name of the key version, e.g.
+	// projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *CryptoKeyVersion) Reset()                    { *m = CryptoKeyVersion{} }
+func (m *CryptoKeyVersion) String() string            { return proto.CompactTextString(m) }
+func (*CryptoKeyVersion) ProtoMessage()               {}
+func (*CryptoKeyVersion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *CryptoKeyVersion) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+// EncryptedKey is a DER encoded private key encrypted with a Google Cloud KMS
+// symmetric key. It is only decrypted in memory when the key is used.
+type EncryptedKey struct {
+	// crypto_key is the resource name of the KMS key that encrypted the key,
+	// e.g. projects/p/locations/l/keyRings/r/cryptoKeys/k.
+	CryptoKey string `protobuf:"bytes,1,opt,name=crypto_key,json=cryptoKey" json:"crypto_key,omitempty"`
+	// ciphertext is the encrypted private key.
+	Ciphertext []byte `protobuf:"bytes,2,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+}
+
+func (m *EncryptedKey) Reset()                    { *m = EncryptedKey{} }
+func (m *EncryptedKey) String() string            { return proto.CompactTextString(m) }
+func (*EncryptedKey) ProtoMessage()               {}
+func (*EncryptedKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *EncryptedKey) GetCryptoKey() string {
+	if m != nil {
+		return m.CryptoKey
+	}
+	return ""
+}
+
+func (m *EncryptedKey) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CryptoKeyVersion)(nil), "google.keytransparency.impl.kms.CryptoKeyVersion")
+	proto.RegisterType((*EncryptedKey)(nil), "google.keytransparency.impl.kms.EncryptedKey")
+}
+
+func init() { proto.RegisterFile("impl/google/kms/kms_proto/kms.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 194 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0xce, 0xcc, 0x2d, 0xc8,
+	0xd1, 0x4f, 0xcf, 0xcf, 0x4f, 0xcf, 0x49, 0xd5, 0xcf, 0xce, 0x2d, 0x06, 0xe1, 0xf8, 0x82, 0xa2,
+	0xfc, 0x92, 0x7c, 0x10, 0x4b, 0x0f, 0xcc, 0x12, 0x92, 0x87, 0xc8, 0xeb, 0x65, 0xa7, 0x56, 0x96,
+	0x14, 0x25, 0xe6, 0x15, 0x17, 0x24, 0x16, 0xa5, 0xe6, 0x25, 0x57, 0xea, 0x81, 0xf4, 0xea, 0x65,
+	0xe7, 0x16, 0x2b, 0xa9, 0x71, 0x09, 0x38, 0x17, 0x55, 0x16, 0x94, 0xe4, 0x7b, 0xa7, 0x56, 0x86,
+	0xa5, 0x16, 0x15, 0x67, 0xe6, 0xe7, 0x09, 0x09, 0x71, 0xb1, 0xe4, 0x25, 0xe6, 0xa6, 0x4a, 0x30,
+	0x2a, 0x30, 0x6a, 0x70, 0x06, 0x81, 0xd9, 0x4a, 0xbe, 0x5c, 0x3c, 0xae, 0x79, 0xc9, 0x20, 0x95,
+	0xa9, 0x29, 0xde, 0xa9, 0x95, 0x42, 0xb2, 0x5c, 0x5c, 0x60, 0x5e, 0x7e, 0x7c, 0x76, 0x6a, 0x25,
+	0x54, 0x25, 0x67, 0x32, 0xcc, 0x24, 0x21, 0x39, 0x2e, 0xae, 0xe4, 0xcc, 0x82, 0x8c, 0xd4, 0xa2,
+	0x92, 0xd4, 0x8a, 0x12, 0x09, 0x26, 0x05, 0x46, 0x0d, 0x9e, 0x20, 0x24, 0x11, 0x27, 0xdb, 0x28,
+	0xeb, 0xf4, 0xcc, 0x92, 0x8c, 0xd2, 0x24, 0xbd, 0xe4, 0xfc, 0x5c, 0xb8, 0x27, 0x50, 0x1d, 0xa9,
+	0x8f, 0xd3, 0x83, 0x49, 0x6c, 0x60, 0xca, 0x18, 0x30, 0x00, 0xa6, 0x83, 0x45, 0xe7, 0x04, 0x01,
+	0x00, 0x00,
+}
@@ -0,0 +1,265 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/crypto/signatures/factory"
+	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keyspb"
+
+	kmspb "github.com/google/keytransparency/impl/google/kms/kms_proto"
+)
+
+const keyRing = "projects/p/locations/global/keyRings/r"
+
+// fakeKMS implements the parts of the Cloud KMS REST API used by Client.
+// Symmetric encryption is a reversible tag; signing keys are generated in
+// memory.
+type fakeKMS struct {
+	mu      sync.Mutex
+	keys    map[string]*ecdsa.PrivateKey
+	pending map[string]bool
+}
+
+func newFakeKMS() (*Client, *fakeKMS, func()) {
+	f := &fakeKMS{keys: make(map[string]*ecdsa.PrivateKey), pending: make(map[string]bool)}
+	srv := httptest.NewServer(f)
+	c := New(srv.Client())
+	c.endpoint = srv.URL + "/v1/"
+	c.pollInterval = time.Millisecond
+	return c, f, srv.Close
+}
+
+func (f *fakeKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	var req map[string]json.RawMessage
+	json.NewDecoder(r.Body).Decode(&req)
+	field := func(name string) []byte {
+		var b []byte
+		json.Unmarshal(req[name], &b)
+		return b
+	}
+	reply := func(v interface{}) { json.NewEncoder(w).Encode(v) }
+
+	switch {
+	case strings.HasSuffix(path, ":encrypt"):
+		reply(map[string][]byte{"ciphertext": append([]byte("enc:"), field("plaintext")...)})
+	case strings.HasSuffix(path, ":decrypt"):
+		reply(map[string][]byte{"plaintext": bytes.TrimPrefix(field("ciphertext"), []byte("enc:"))})
+	case strings.HasSuffix(path, "/cryptoKeys"):
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name := path + "/" + r.URL.Query().Get("cryptoKeyId")
+		f.keys[name+"/cryptoKeyVersions/1"] = key
+		f.pending[name+"/cryptoKeyVersions/1"] = true
+		reply(map[string]string{"name": name})
+	case strings.HasSuffix(path, ":asymmetricSign"):
+		key, ok := f.keys[strings.TrimSuffix(path, ":asymmetricSign")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var digest struct {
+			SHA256 []byte `json:"sha256"`
+		}
+		json.Unmarshal(req["digest"], &digest)
+		sig, err := key.Sign(rand.Reader, digest.SHA256, crypto.SHA256)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		reply(map[string][]byte{"signature": sig})
+	case strings.HasSuffix(path, "/publicKey"):
+		key, ok := f.keys[strings.TrimSuffix(path, "/publicKey")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		der, _ := x509.MarshalPKIXPublicKey(key.Public())
+		reply(map[string]string{"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))})
+	default:
+		if _, ok := f.keys[path]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		state := "ENABLED"
+		if f.pending[path] {
+			state = "PENDING_GENERATION"
+			f.pending[path] = false
+		}
+		reply(map[string]string{"name": path, "state": state})
+	}
+}
+
+func TestVRFKeyGen(t *testing.T) {
+	ctx := context.Background()
+	c, _, done := newFakeKMS()
+	defer done()
+	RegisterHandlers(c)
+
+	spec := &keyspb.Specification{
+		Params: &keyspb.Specification_EcdsaParams{
+			EcdsaParams: &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256},
+		},
+	}
+	wrapped, err := c.VRFKeyGen(keyRing+"/cryptoKeys/vrf")(ctx, spec)
+	if err != nil {
+		t.Fatalf("VRFKeyGen(): %v", err)
+	}
+	k, ok := wrapped.(*kmspb.EncryptedKey)
+	if !ok {
+		t.Fatalf("VRFKeyGen(): %T, want *kmspb.EncryptedKey", wrapped)
+	}
+	if !bytes.HasPrefix(k.GetCiphertext(), []byte("enc:")) {
+		t.Errorf("VRFKeyGen() did not encrypt the key")
+	}
+	vrfPriv, err := p256.NewFromWrappedKey(ctx, wrapped)
+	if err != nil {
+		t.Fatalf("NewFromWrappedKey(): %v", err)
+	}
+	m := []byte("data")
+	index, proof := vrfPriv.Evaluate(m)
+	pub, err := p256.NewVRFVerifier(vrfPriv.Public().(*ecdsa.PublicKey))
+	if err != nil {
+		t.Fatalf("NewVRFVerifier(): %v", err)
+	}
+	if got, err := pub.ProofToHash(m, proof); err != nil || got != index {
+		t.Errorf("ProofToHash(): %x, %v, want %x", got, err, index)
+	}
+}
+
+func TestTreeKeyGen(t *testing.T) {
+	ctx := context.Background()
+	c, _, done := newFakeKMS()
+	defer done()
+	RegisterHandlers(c)
+
+	spec := &keyspb.Specification{
+		Params: &keyspb.Specification_EcdsaParams{
+			EcdsaParams: &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256},
+		},
+	}
+	key, err := c.TreeKeyGen(keyRing)(ctx, spec)
+	if err != nil {
+		t.Fatalf("TreeKeyGen(): %v", err)
+	}
+	if name := key.(*kmspb.CryptoKeyVersion).GetName(); !strings.HasPrefix(name, keyRing+"/cryptoKeys/kt-") {
+		t.Errorf("TreeKeyGen(): %v, want a key in %v", name, keyRing)
+	}
+	signer, err := keys.NewSigner(ctx, key)
+	if err != nil {
+		t.Fatalf("keys.NewSigner(): %v", err)
+	}
+	digest := sha256.Sum256([]byte("root"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	var ecSig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &ecSig); err != nil {
+		t.Fatalf("asn1.Unmarshal(): %v", err)
+	}
+	if !ecdsa.Verify(signer.Public().(*ecdsa.PublicKey), digest[:], ecSig.R, ecSig.S) {
+		t.Errorf("Sign() returned an invalid signature")
+	}
+}
+
+func TestKeySigner(t *testing.T) {
+	ctx := context.Background()
+	c, f, done := newFakeKMS()
+	defer done()
+	name, err := c.createSigningKey(ctx, keyRing, "user", "EC_SIGN_P256_SHA256")
+	if err != nil {
+		t.Fatalf("createSigningKey(): %v", err)
+	}
+	if f.pending[name] {
+		t.Errorf("createSigningKey() returned before the key was generated")
+	}
+	signer, err := c.NewKeySigner(ctx, name)
+	if err != nil {
+		t.Fatalf("NewKeySigner(): %v", err)
+	}
+	pub, err := signer.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey(): %v", err)
+	}
+	verifier, err := factory.NewVerifierFromKey(pub)
+	if err != nil {
+		t.Fatalf("NewVerifierFromKey(): %v", err)
+	}
+	obj := struct{ A string }{"a"}
+	sig, err := signer.Sign(obj)
+	if err != nil {
+		t.Fatalf("Sign(): %v", err)
+	}
+	if err := verifier.Verify(obj, sig); err != nil {
+		t.Errorf("Verify(): %v", err)
+	}
+	if got, want := signer.KeyID(), verifier.KeyID(); got != want {
+		t.Errorf("KeyID(): %v, want %v", got, want)
+	}
+	if _, err := signer.PrivateKeyPEM(); err != ErrNotExportable {
+		t.Errorf("PrivateKeyPEM(): %v, want %v", err, ErrNotExportable)
+	}
+}
+
+func TestAlgorithm(t *testing.T) {
+	for _, tc := range []struct {
+		spec    *keyspb.Specification
+		want    string
+		wantErr bool
+	}{
+		{spec: &keyspb.Specification{Params: &keyspb.Specification_EcdsaParams{EcdsaParams: &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P256}}}, want: "EC_SIGN_P256_SHA256"},
+		{spec: &keyspb.Specification{Params: &keyspb.Specification_EcdsaParams{EcdsaParams: &keyspb.Specification_ECDSA{Curve: keyspb.Specification_ECDSA_P384}}}, wantErr: true},
+		{spec: &keyspb.Specification{Params: &keyspb.Specification_RsaParams{RsaParams: &keyspb.Specification_RSA{}}}, want: "RSA_SIGN_PKCS1_2048_SHA256"},
+		{spec: &keyspb.Specification{Params: &keyspb.Specification_RsaParams{RsaParams: &keyspb.Specification_RSA{Bits: 4096}}}, want: "RSA_SIGN_PKCS1_4096_SHA256"},
+		{spec: &keyspb.Specification{Params: &keyspb.Specification_RsaParams{RsaParams: &keyspb.Specification_RSA{Bits: 1024}}}, wantErr: true},
+		{spec: &keyspb.Specification{}, wantErr: true},
+	} {
+		got, err := algorithm(tc.spec)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("algorithm(%v): %v, want err: %v", tc.spec, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("algorithm(%v): %v, want %v", tc.spec, got, tc.want)
+		}
+	}
+}
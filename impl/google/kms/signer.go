@@ -0,0 +1,165 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/keytransparency/core/crypto/signatures"
+
+	"github.com/benlaurie/objecthash/go/objecthash"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+)
+
+// ErrNotExportable occurs when the private key of a KMS key is requested.
+var ErrNotExportable = errors.New("kms: private key cannot be exported")
+
+// hashNames are the names Cloud KMS uses for digest algorithms.
+var hashNames = map[crypto.Hash]string{
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// Signer is a crypto.Signer whose private key is held by Cloud KMS.
+type Signer struct {
+	client *Client
+	name   string
+	public crypto.PublicKey
+}
+
+// NewSigner returns a Signer for the key version name. The public key is
+// fetched once, here.
+func (c *Client) NewSigner(ctx context.Context, name string) (*Signer, error) {
+	b, err := c.publicKeyPEM(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("kms: public key of %v is not PEM encoded", name)
+	}
+	pub, err := der.UnmarshalPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("kms: public key of %v: %w", name, err)
+	}
+	return &Signer{client: c, name: name, public: pub}, nil
+}
+
+// Public returns the public key.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign signs digest in KMS. rand is ignored. Only PKCS #1 v1.5 padding is
+// supported for RSA keys.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, errors.New("kms: RSA-PSS is not supported")
+	}
+	hash, ok := hashNames[opts.HashFunc()]
+	if !ok {
+		return nil, fmt.Errorf("kms: unsupported hash %v", opts.HashFunc())
+	}
+	// crypto.Signer has no context, so signing cannot be canceled.
+	return s.client.asymmetricSign(context.Background(), s.name, hash, digest)
+}
+
+// keySigner adapts Signer to the signatures.Signer interface used to sign
+// key updates.
+type keySigner struct {
+	*Signer
+	keyID string
+	alg   sigpb.DigitallySigned_SignatureAlgorithm
+}
+
+// NewKeySigner returns a signatures.Signer for the key version name, which
+// must be an EC_SIGN_P256_SHA256 or RSA_SIGN_PKCS1_*_SHA256 key.
+func (c *Client) NewKeySigner(ctx context.Context, name string) (signatures.Signer, error) {
+	s, err := c.NewSigner(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var alg sigpb.DigitallySigned_SignatureAlgorithm
+	switch s.Public().(type) {
+	case *ecdsa.PublicKey:
+		alg = sigpb.DigitallySigned_ECDSA
+	case *rsa.PublicKey:
+		alg = sigpb.DigitallySigned_RSA
+	default:
+		return nil, signatures.ErrWrongKeyType
+	}
+	id, err := signatures.KeyID(s.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{Signer: s, keyID: id, alg: alg}, nil
+}
+
+// Sign generates a digital signature object.
+func (s *keySigner) Sign(data interface{}) (*sigpb.DigitallySigned, error) {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := objecthash.CommonJSONHash(string(j))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Signer.Sign(nil, hash[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", signatures.ErrSign, err)
+	}
+	return &sigpb.DigitallySigned{
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: s.alg,
+		Signature:          sig,
+	}, nil
+}
+
+// PublicKey returns the signer public key as keyspb.PublicKey proto message.
+func (s *keySigner) PublicKey() (*keyspb.PublicKey, error) {
+	return der.ToPublicProto(s.Public())
+}
+
+// KeyID returns the ID of the associated public key.
+func (s *keySigner) KeyID() string {
+	return s.keyID
+}
+
+// PrivateKeyPEM always fails because KMS keys cannot be exported.
+func (s *keySigner) PrivateKeyPEM() ([]byte, error) {
+	return nil, ErrNotExportable
+}
+
+// PublicKeyPEM returns the PEM-formatted public key of this signer.
+func (s *keySigner) PublicKeyPEM() ([]byte, error) {
+	pub, err := x509.MarshalPKIXPublicKey(s.Public())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretmanager loads signing keys from Google Cloud Secret Manager.
+// Importing it registers the gcpsm scheme with the secrets package:
+//
+//	gcpsm:projects/my-project/secrets/monitor-key
+//	gcpsm:projects/my-project/secrets/monitor-key/versions/3
+//
+// Without a version the latest version is used, so adding a secret version
+// rotates the key.
+package secretmanager
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/keytransparency/core/crypto/secrets"
+	"github.com/google/trillian/crypto/keys/pem"
+
+	"golang.org/x/oauth2/google"
+)
+
+// Scope is the OAuth scope needed to access secrets.
+const Scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// endpoint is the base URL of the Secret Manager API.
+var endpoint = "https://secretmanager.googleapis.com/v1/"
+
+func init() {
+	secrets.Register("gcpsm", open)
+}
+
+func open(u *url.URL, password string) (secrets.Provider, error) {
+	if !strings.HasPrefix(u.Opaque, "projects/") || !strings.Contains(u.Opaque, "/secrets/") {
+		return nil, errors.New("secretmanager: spec must be gcpsm:projects/PROJECT/secrets/SECRET")
+	}
+	client, err := google.DefaultClient(context.Background(), Scope)
+	if err != nil {
+		return nil, fmt.Errorf("secretmanager: default credentials: %w", err)
+	}
+	return New(client, u.Opaque, password), nil
+}
+
+// New returns a Provider that reads a PEM encoded key from the secret name.
+// name is projects/PROJECT/secrets/SECRET, optionally followed by
+// /versions/VERSION. client must attach credentials to its requests.
+func New(client *http.Client, name, password string) secrets.Provider {
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	return secrets.ProviderFunc(func(ctx context.Context) (crypto.Signer, error) {
+		req, err := http.NewRequest("GET", endpoint+name+":access", nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			return nil, secrets.ErrNotFound
+		default:
+			return nil, fmt.Errorf("secretmanager: accessing %v: %v", name, resp.Status)
+		}
+
+		var version struct {
+			Payload struct {
+				Data string `json:"data"`
+			} `json:"payload"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+			return nil, fmt.Errorf("secretmanager: decoding response: %w", err)
+		}
+		data, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+		if err != nil {
+			return nil, fmt.Errorf("secretmanager: decoding payload: %w", err)
+		}
+		return pem.UnmarshalPrivateKey(string(data), password)
+	})
+}
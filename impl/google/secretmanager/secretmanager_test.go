@@ -0,0 +1,83 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/crypto/secrets"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey(): %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/projects/p/secrets/key/versions/latest:access",
+			"/v1/projects/p/secrets/key/versions/2:access":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":    r.URL.Path,
+				"payload": map[string]string{"data": base64.StdEncoding.EncodeToString(keyPEM)},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(e string) { endpoint = e }(endpoint)
+	endpoint = srv.URL + "/v1/"
+
+	for _, tc := range []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "projects/p/secrets/key"},
+		{name: "projects/p/secrets/key/versions/2"},
+		{name: "projects/p/secrets/missing", wantErr: secrets.ErrNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := New(srv.Client(), tc.name, "").PrivateKey(ctx)
+			if got, want := err, tc.wantErr; got != want {
+				t.Fatalf("PrivateKey(): %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := signer.Public(), &key.PublicKey; !reflect.DeepEqual(got, want) {
+				t.Errorf("PrivateKey().Public(): %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -22,12 +22,12 @@ import (
 	"net"
 	"time"
 
-	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/keytransparency/core/adminserver"
 	"github.com/google/keytransparency/core/authentication"
 	"github.com/google/keytransparency/core/client/grpcc"
-	"github.com/google/keytransparency/core/crypto/vrf/p256"
+	"github.com/google/keytransparency/core/client/kt"
+	"github.com/google/keytransparency/core/crypto/vrf/factory"
 	"github.com/google/keytransparency/core/fake"
 	"github.com/google/keytransparency/core/integration"
 	"github.com/google/keytransparency/core/keyserver"
@@ -36,11 +36,14 @@ import (
 	"github.com/google/keytransparency/core/sequencer"
 
 	"github.com/google/keytransparency/impl/authorization"
+	"github.com/google/keytransparency/impl/sql/appindex"
+	"github.com/google/keytransparency/impl/sql/audit"
 	"github.com/google/keytransparency/impl/sql/domain"
 	"github.com/google/keytransparency/impl/sql/mutationstorage"
+	"github.com/google/keytransparency/impl/sql/purge"
+	"github.com/google/keytransparency/impl/sql/quota"
 
 	"github.com/google/trillian/crypto/keys/der"
-	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/merkle/coniks"
 	"github.com/google/trillian/storage/testdb"
 
@@ -58,11 +61,11 @@ import (
 func Listen() (string, net.Listener, error) {
 	lis, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to listen: %v", err)
+		return "", nil, fmt.Errorf("failed to listen: %w", err)
 	}
 	_, port, err := net.SplitHostPort(lis.Addr().String())
 	if err != nil {
-		return "", nil, fmt.Errorf("Failed to parse listener address: %v", err)
+		return "", nil, fmt.Errorf("Failed to parse listener address: %w", err)
 	}
 	addr := "localhost:" + port
 	return addr, lis, nil
@@ -77,23 +80,19 @@ type Env struct {
 	db         *sql.DB
 }
 
-func vrfKeyGen(ctx context.Context, spec *keyspb.Specification) (proto.Message, error) {
-	return der.NewProtoFromSpec(spec)
-}
-
 // NewEnv sets up common resources for tests.
 func NewEnv() (*Env, error) {
 	ctx := context.Background()
 	domainID := fmt.Sprintf("domain %d", rand.Int()) // nolint: gas
 	db, err := testdb.New(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("env: failed to open database: %v", err)
+		return nil, fmt.Errorf("env: failed to open database: %w", err)
 	}
 
 	// Map server
 	mapEnv, err := maptest.NewMapEnv(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("env: failed to create trillian map server: %v", err)
+		return nil, fmt.Errorf("env: failed to create trillian map server: %w", err)
 	}
 
 	tlog := fake.NewTrillianLogClient()
@@ -101,53 +100,103 @@ func NewEnv() (*Env, error) {
 	// Configure domain, which creates new map and log trees.
 	domainStorage, err := domain.NewStorage(db)
 	if err != nil {
-		return nil, fmt.Errorf("env: failed to create domain storage: %v", err)
+		return nil, fmt.Errorf("env: failed to create domain storage: %w", err)
+	}
+	purgeStorage, err := purge.NewStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create purge storage: %w", err)
+	}
+	auditStorage, err := audit.NewStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create audit storage: %w", err)
+	}
+	appStorage, err := appindex.NewStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create app index storage: %w", err)
+	}
+	quotaStorage, err := quota.NewStorage(db)
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create quota storage: %w", err)
+	}
+	adminSvr, err := adminserver.New(adminserver.Options{
+		Log:      tlog,
+		Map:      mapEnv.Map,
+		LogAdmin: mapEnv.Admin,
+		MapAdmin: mapEnv.Admin,
+		Domains:  domainStorage,
+		Purged:   purgeStorage,
+		Audits:   auditStorage,
+		Apps:     appStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create admin server: %w", err)
 	}
-	adminSvr := adminserver.New(tlog, mapEnv.Map, mapEnv.Admin, mapEnv.Admin, domainStorage, vrfKeyGen)
 	domainPB, err := adminSvr.CreateDomain(ctx, &pb.CreateDomainRequest{
 		DomainId:    domainID,
 		MinInterval: ptypes.DurationProto(1 * time.Second),
 		MaxInterval: ptypes.DurationProto(5 * time.Second),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("env: CreateDomain(): %v", err)
+		return nil, fmt.Errorf("env: CreateDomain(): %w", err)
+	}
+	domainPB, err = adminSvr.SetAppListing(ctx, &pb.SetAppListingRequest{
+		DomainId: domainID,
+		Enabled:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("env: SetAppListing(): %w", err)
 	}
 
 	mapID := domainPB.Map.TreeId
 	logID := domainPB.Log.TreeId
 	mapPubKey, err := der.UnmarshalPublicKey(domainPB.Map.GetPublicKey().GetDer())
 	if err != nil {
-		return nil, fmt.Errorf("env: Failed to load signing keypair: %v", err)
+		return nil, fmt.Errorf("env: Failed to load signing keypair: %w", err)
 	}
-	vrfPub, err := p256.NewVRFVerifierFromRawKey(domainPB.Vrf.GetDer())
+	vrfPub, err := factory.NewVRFVerifierFromRawKey(domainPB.Vrf.GetDer())
 	if err != nil {
-		return nil, fmt.Errorf("env: Failed to load vrf pubkey: %v", err)
+		return nil, fmt.Errorf("env: Failed to load vrf pubkey: %w", err)
 	}
 
 	// Common data structures.
 	mutations, err := mutationstorage.New(db)
 	if err != nil {
-		return nil, fmt.Errorf("env: Failed to create mutations object: %v", err)
+		return nil, fmt.Errorf("env: Failed to create mutations object: %w", err)
 	}
 	auth := authentication.NewFake()
 	authz := authorization.New()
 
 	queue := mutator.MutationQueue(mutations)
-	server := keyserver.New(tlog, mapEnv.Map, mapEnv.Admin, mapEnv.Admin,
-		entry.New(), auth, authz, domainStorage, queue, mutations)
+	server, err := keyserver.New(keyserver.Options{
+		Log:       tlog,
+		Map:       mapEnv.Map,
+		LogAdmin:  mapEnv.Admin,
+		MapAdmin:  mapEnv.Admin,
+		Mutator:   entry.NewRegistry(),
+		Auth:      auth,
+		Authz:     authz,
+		Domains:   domainStorage,
+		Purged:    purgeStorage,
+		Apps:      appStorage,
+		Quotas:    quotaStorage,
+		Queue:     queue,
+		Mutations: mutations,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("env: keyserver.New(): %w", err)
+	}
 	gsvr := grpc.NewServer()
 	pb.RegisterKeyTransparencyServer(gsvr, server)
 
 	// Sequencer
-	seq := sequencer.New(tlog, mapEnv.Map, entry.New(), domainStorage, mutations, queue)
-	// Only sequence when explicitly asked with receiver.Flush()
+	seq := sequencer.New(tlog, mapEnv.Map, entry.NewRegistry(), domainStorage, mutations, queue, nil, nil, nil)
+	// Only sequence when explicitly asked with Env.AdvanceEpoch().
 	d := &domaindef.Domain{
 		DomainID: domainID,
 		LogID:    logID,
 		MapID:    mapID,
 	}
-	receiver := seq.NewReceiver(ctx, d, 60*time.Hour, 60*time.Hour)
-	receiver.Flush(ctx)
+	receiver := seq.NewReceiver(ctx, d, 0, 0)
 
 	addr, lis, err := Listen()
 	if err != nil {
@@ -158,24 +207,41 @@ func NewEnv() (*Env, error) {
 	// Client
 	cc, err := grpc.Dial(addr, grpc.WithInsecure())
 	if err != nil {
-		return nil, fmt.Errorf("Dial(%v) = %v", addr, err)
+		return nil, fmt.Errorf("Dial(%v) = %w", addr, err)
 	}
 	ktClient := pb.NewKeyTransparencyClient(cc)
-	client := grpcc.New(ktClient, domainID, vrfPub, mapPubKey, coniks.Default, fake.NewFakeTrillianLogVerifier())
+	client, err := grpcc.New(ktClient, domainID, kt.Options{
+		VRF:         vrfPub,
+		MapHasher:   coniks.Default,
+		MapPubKey:   mapPubKey,
+		LogVerifier: fake.NewFakeTrillianLogVerifier(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("env: failed to create client: %w", err)
+	}
 	client.RetryCount = 0
 
-	return &Env{
+	env := &Env{
 		Env: &integration.Env{
 			Client:   client,
 			Cli:      ktClient,
 			Domain:   domainPB,
 			Receiver: receiver,
+			// The key server authenticates with authentication.NewFake
+			// and does not implement the streaming or batch APIs.
+			Capabilities: integration.Capabilities{Auth: true},
 		},
 		mapEnv:     mapEnv,
 		grpcServer: gsvr,
 		grpcCC:     cc,
 		db:         db,
-	}, nil
+	}
+	// Start every test from epoch 1.
+	if err := env.AdvanceEpoch(ctx); err != nil {
+		env.Close()
+		return nil, fmt.Errorf("env: AdvanceEpoch(): %w", err)
+	}
+	return env, nil
 }
 
 // Close releases resources allocated by NewEnv.
@@ -38,7 +38,7 @@ func TestIntegration(t *testing.T) {
 				t.Fatalf("Could not create Env: %v", err)
 			}
 			defer env.Close()
-			test.Fn(ctx, env.Env, t)
+			test.Run(ctx, env.Env, t)
 		})
 	}
 }
@@ -0,0 +1,82 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package acl implements the acl.Storage interface.
+package acl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/keytransparency/core/acl"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS DomainACLs(
+  Identity              VARCHAR(255) NOT NULL,
+  DomainId              VARCHAR(40) NOT NULL,
+  PRIMARY KEY(Identity, DomainId)
+);`
+	readSQL   = `SELECT DomainId FROM DomainACLs WHERE Identity = ?;`
+	grantSQL  = `REPLACE INTO DomainACLs (Identity, DomainId) VALUES (?, ?);`
+	revokeSQL = `DELETE FROM DomainACLs WHERE Identity = ? AND DomainId = ?;`
+)
+
+// migrations create the DomainACLs table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE DomainACLs;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns an acl.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (acl.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "acl", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create ACL table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Domains(ctx context.Context, identity string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, readSQL, identity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ret := []string{}
+	for rows.Next() {
+		var domainID string
+		if err := rows.Scan(&domainID); err != nil {
+			return nil, err
+		}
+		ret = append(ret, domainID)
+	}
+	return ret, rows.Err()
+}
+
+func (s *storage) Grant(ctx context.Context, identity, domainID string) error {
+	_, err := s.db.ExecContext(ctx, grantSQL, identity, domainID)
+	return err
+}
+
+func (s *storage) Revoke(ctx context.Context, identity, domainID string) error {
+	_, err := s.db.ExecContext(ctx, revokeSQL, identity, domainID)
+	return err
+}
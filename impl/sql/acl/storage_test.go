@@ -0,0 +1,69 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	for _, g := range []struct{ identity, domainID string }{
+		{"alice", "domain1"},
+		{"alice", "domain2"},
+		{"alice", "domain2"}, // Granting twice is not an error.
+		{"bob", "domain1"},
+	} {
+		if err := s.Grant(ctx, g.identity, g.domainID); err != nil {
+			t.Fatalf("Grant(%v, %v): %v", g.identity, g.domainID, err)
+		}
+	}
+	if err := s.Revoke(ctx, "bob", "domain1"); err != nil {
+		t.Fatalf("Revoke(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		identity string
+		want     []string
+	}{
+		{identity: "alice", want: []string{"domain1", "domain2"}},
+		{identity: "bob", want: []string{}},
+		{identity: "carol", want: []string{}},
+	} {
+		got, err := s.Domains(ctx, tc.identity)
+		if err != nil {
+			t.Fatalf("Domains(%v): %v", tc.identity, err)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Domains(%v): %v, want %v", tc.identity, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package appindex implements the appindex.Storage interface.
+package appindex
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/keytransparency/core/appindex"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS UserApps(
+  DomainId              VARCHAR(40) NOT NULL,
+  UserId                VARCHAR(255) NOT NULL,
+  AppId                 VARCHAR(255) NOT NULL,
+  PRIMARY KEY(DomainId, UserId, AppId)
+);`
+	readSQL  = `SELECT AppId FROM UserApps WHERE DomainId = ? AND UserId = ? ORDER BY AppId ASC;`
+	writeSQL = `REPLACE INTO UserApps (DomainId, UserId, AppId) VALUES (?, ?, ?);`
+	hasSQL   = `SELECT 1 FROM UserApps WHERE DomainId = ? AND AppId = ? LIMIT 1;`
+
+	createCompleteSQL = `
+CREATE TABLE IF NOT EXISTS CompleteDomains(
+  DomainId              VARCHAR(40) NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	setCompleteSQL  = `REPLACE INTO CompleteDomains (DomainId) VALUES (?);`
+	readCompleteSQL = `SELECT 1 FROM CompleteDomains WHERE DomainId = ?;`
+)
+
+// migrations create the UserApps and CompleteDomains tables. Domains that
+// existed before version 2 are not marked complete.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE UserApps;`}},
+	{Version: 2, Up: []string{createCompleteSQL}, Down: []string{`DROP TABLE CompleteDomains;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns an appindex.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (appindex.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "appindex", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create user apps table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Add(ctx context.Context, domainID, userID, appID string) error {
+	_, err := s.db.ExecContext(ctx, writeSQL, domainID, userID, appID)
+	return err
+}
+
+func (s *storage) List(ctx context.Context, domainID, userID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, readSQL, domainID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var appIDs []string
+	for rows.Next() {
+		var appID string
+		if err := rows.Scan(&appID); err != nil {
+			return nil, err
+		}
+		appIDs = append(appIDs, appID)
+	}
+	return appIDs, rows.Err()
+}
+
+func (s *storage) HasApp(ctx context.Context, domainID, appID string) (bool, error) {
+	return s.exists(ctx, hasSQL, domainID, appID)
+}
+
+func (s *storage) SetComplete(ctx context.Context, domainID string) error {
+	_, err := s.db.ExecContext(ctx, setCompleteSQL, domainID)
+	return err
+}
+
+func (s *storage) Complete(ctx context.Context, domainID string) (bool, error) {
+	return s.exists(ctx, readCompleteSQL, domainID)
+}
+
+// exists returns true if query returns a row.
+func (s *storage) exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	var one int
+	switch err := s.db.QueryRowContext(ctx, query, args...).Scan(&one); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
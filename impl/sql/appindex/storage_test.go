@@ -0,0 +1,130 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appindex
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	for _, a := range []struct{ domainID, userID, appID string }{
+		{"domain", "alice", "app2"},
+		{"domain", "alice", "app1"},
+		{"domain", "alice", "app1"}, // Duplicates are ignored.
+		{"domain", "bob", "app3"},
+		{"domain2", "alice", "app4"},
+	} {
+		if err := s.Add(ctx, a.domainID, a.userID, a.appID); err != nil {
+			t.Fatalf("Add(%v, %v, %v): %v", a.domainID, a.userID, a.appID, err)
+		}
+	}
+	for _, tc := range []struct {
+		domainID, userID string
+		want             []string
+	}{
+		{domainID: "domain", userID: "alice", want: []string{"app1", "app2"}},
+		{domainID: "domain", userID: "bob", want: []string{"app3"}},
+		{domainID: "domain2", userID: "alice", want: []string{"app4"}},
+		{domainID: "domain2", userID: "bob", want: nil},
+	} {
+		got, err := s.List(ctx, tc.domainID, tc.userID)
+		if err != nil {
+			t.Fatalf("List(%v, %v): %v", tc.domainID, tc.userID, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("List(%v, %v): %v, want %v", tc.domainID, tc.userID, got, tc.want)
+		}
+	}
+}
+
+func TestHasApp(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	if err := s.Add(ctx, "domain", "alice", "app1"); err != nil {
+		t.Fatalf("Add(): %v", err)
+	}
+	for _, tc := range []struct {
+		domainID, appID string
+		want            bool
+	}{
+		{domainID: "domain", appID: "app1", want: true},
+		{domainID: "domain", appID: "app2", want: false},
+		{domainID: "domain2", appID: "app1", want: false},
+	} {
+		got, err := s.HasApp(ctx, tc.domainID, tc.appID)
+		if err != nil {
+			t.Fatalf("HasApp(%v, %v): %v", tc.domainID, tc.appID, err)
+		}
+		if got != tc.want {
+			t.Errorf("HasApp(%v, %v): %v, want %v", tc.domainID, tc.appID, got, tc.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	for i := 0; i < 2; i++ { // Marking twice is not an error.
+		if err := s.SetComplete(ctx, "domain"); err != nil {
+			t.Fatalf("SetComplete(): %v", err)
+		}
+	}
+	for _, tc := range []struct {
+		domainID string
+		want     bool
+	}{
+		{domainID: "domain", want: true},
+		{domainID: "domain2", want: false},
+	} {
+		got, err := s.Complete(ctx, tc.domainID)
+		if err != nil {
+			t.Fatalf("Complete(%v): %v", tc.domainID, err)
+		}
+		if got != tc.want {
+			t.Errorf("Complete(%v): %v, want %v", tc.domainID, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit implements the audit.Storage interface.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/keytransparency/core/audit"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS AuditLog(
+  DomainId              VARCHAR(40) NOT NULL,
+  Sequence              BIGINT NOT NULL,
+  Method                VARCHAR(64) NOT NULL,
+  Caller                VARCHAR(255) NOT NULL,
+  TimeNanos             BIGINT NOT NULL,
+  RequestHash           VARBINARY(32) NOT NULL,
+  PRIMARY KEY(DomainId, Sequence)
+);`
+	nextSQL  = `SELECT COALESCE(MAX(Sequence) + 1, 0) FROM AuditLog WHERE DomainId = ?;`
+	writeSQL = `INSERT INTO AuditLog
+(DomainId, Sequence, Method, Caller, TimeNanos, RequestHash)
+VALUES (?, ?, ?, ?, ?, ?);`
+	listSQL = `
+SELECT Sequence, Method, Caller, TimeNanos, RequestHash
+FROM AuditLog WHERE DomainId = ? AND Sequence >= ?
+ORDER BY Sequence ASC LIMIT ?;`
+)
+
+// migrations create the AuditLog table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE AuditLog;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns an audit.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (audit.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "audit", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create audit table: %w", err)
+	}
+	return s, nil
+}
+
+// Append adds e to the audit log. Concurrent appends to the same domain are
+// serialized by the primary key; the loser fails rather than overwriting.
+func (s *storage) Append(ctx context.Context, e *audit.Entry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var seq int64
+	if err := tx.QueryRowContext(ctx, nextSQL, e.DomainID).Scan(&seq); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, writeSQL,
+		e.DomainID, seq, e.Method, e.Caller, e.Time.UnixNano(), e.RequestHash); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	e.Sequence = seq
+	return nil
+}
+
+func (s *storage) List(ctx context.Context, domainID string, start int64, count int32) ([]*audit.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, listSQL, domainID, start, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []*audit.Entry{}
+	for rows.Next() {
+		var nanos int64
+		e := &audit.Entry{DomainID: domainID}
+		if err := rows.Scan(&e.Sequence, &e.Method, &e.Caller, &nanos, &e.RequestHash); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(0, nanos)
+		ret = append(ret, e)
+	}
+	return ret, rows.Err()
+}
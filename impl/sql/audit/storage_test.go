@@ -0,0 +1,79 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/audit"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAppendList(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+
+	now := time.Unix(0, 1517000000000000000)
+	var entries []*audit.Entry
+	for i, e := range []*audit.Entry{
+		{DomainID: "domain1", Method: "CreateDomain", Caller: "alice", RequestHash: []byte("hash1")},
+		{DomainID: "domain2", Method: "CreateDomain", Caller: "bob", RequestHash: []byte("hash2")},
+		{DomainID: "domain1", Method: "RotateDomainVRF", Caller: "alice", RequestHash: []byte("hash3")},
+		{DomainID: "domain1", Method: "DeleteDomain", Caller: "carol", RequestHash: []byte("hash4")},
+	} {
+		e.Time = now.Add(time.Duration(i) * time.Second)
+		if err := s.Append(ctx, e); err != nil {
+			t.Fatalf("Append(%v): %v", e, err)
+		}
+		entries = append(entries, e)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		domainID string
+		start    int64
+		count    int32
+		want     []*audit.Entry
+	}{
+		{desc: "all", domainID: "domain1", start: 0, count: 10, want: []*audit.Entry{entries[0], entries[2], entries[3]}},
+		{desc: "page", domainID: "domain1", start: 1, count: 1, want: []*audit.Entry{entries[2]}},
+		{desc: "other domain", domainID: "domain2", start: 0, count: 10, want: []*audit.Entry{entries[1]}},
+		{desc: "past end", domainID: "domain1", start: 3, count: 10, want: []*audit.Entry{}},
+		{desc: "unknown domain", domainID: "domain3", start: 0, count: 10, want: []*audit.Entry{}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := s.List(ctx, tc.domainID, tc.start, tc.count)
+			if err != nil {
+				t.Fatalf("List(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("List(): %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -25,7 +25,11 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/google/keytransparency/core/domain"
+	kterrors "github.com/google/keytransparency/core/errors"
+	"github.com/google/keytransparency/impl/sql/migrate"
 	"github.com/google/trillian/crypto/keyspb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
 const (
@@ -45,19 +49,200 @@ CREATE TABLE IF NOT EXISTS Domains(
 	writeSQL = `INSERT INTO Domains 
 (DomainId, MapId, LogId, VRFPublicKey, VRFPrivateKey, MinInterval, MaxInterval, Deleted) 
 VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
-	readSQL = `
-SELECT DomainId, MapId, LogId, VRFPublicKey, VRFPrivateKey, MinInterval, MaxInterval, Deleted
-FROM Domains WHERE DomainId = ? AND Deleted = 0;`
-	readDeletedSQL = `
-SELECT DomainId, MapId, LogId, VRFPublicKey, VRFPrivateKey, MinInterval, MaxInterval, Deleted
-FROM Domains WHERE DomainId = ?;`
-	listSQL = `
-SELECT DomainId, MapId, LogId, VRFPublicKey, VRFPrivateKey, MinInterval, MaxInterval, Deleted
-FROM Domains WHERE Deleted = 0;`
-	listDeletedSQL = `
-SELECT DomainId, MapId, LogId, VRFPublicKey, VRFPrivateKey, MinInterval, MaxInterval, Deleted
-FROM Domains;`
-	setDeletedSQL = `UPDATE Domains SET Deleted = ?, DeleteTimeMillis = ? WHERE DomainId = ?`
+	// selectDomainsSQL reads domains together with the side tables that
+	// hold at most one row per domain. The side tables with several rows
+	// per domain are read by readSideTables.
+	selectDomainsSQL = `
+SELECT d.DomainId, d.MapId, d.LogId, d.VRFPublicKey, d.VRFPrivateKey, d.MinInterval, d.MaxInterval, d.Deleted, d.DeleteTimeMillis,
+  p.VRFPublicKey, p.VRFPrivateKey, p.ExpiryNanos,
+  k.Policy, e.Policy, m.Migration, b.Backend, t.LogAddress, t.MapAddress,
+  f.DomainId, a.DomainId, x.DomainId, q.Quota,
+  s.Revision, s.Epochs, s.Mutations, s.Indexes
+FROM Domains d
+LEFT JOIN PrevVRFs p ON p.DomainId = d.DomainId
+LEFT JOIN KeyPolicies k ON k.DomainId = d.DomainId
+LEFT JOIN EndorsementPolicies e ON e.DomainId = d.DomainId
+LEFT JOIN DomainMigrations m ON m.DomainId = d.DomainId
+LEFT JOIN LogBackends b ON b.DomainId = d.DomainId
+LEFT JOIN TrillianBackends t ON t.DomainId = d.DomainId
+LEFT JOIN FrozenDomains f ON f.DomainId = d.DomainId
+LEFT JOIN AppListingDomains a ON a.DomainId = d.DomainId
+LEFT JOIN DeletedTrees x ON x.DomainId = d.DomainId
+LEFT JOIN MutationQuotas q ON q.DomainId = d.DomainId
+LEFT JOIN DomainStats s ON s.DomainId = d.DomainId`
+	readSQL        = selectDomainsSQL + ` WHERE d.DomainId = ? AND d.Deleted = 0;`
+	readDeletedSQL = selectDomainsSQL + ` WHERE d.DomainId = ?;`
+	listSQL        = selectDomainsSQL + ` WHERE d.Deleted = 0;`
+	listDeletedSQL = selectDomainsSQL + `;`
+	// whereDomainSQL restricts the rows read by readSideTables to a domain.
+	whereDomainSQL  = ` WHERE DomainId = ?`
+	setDeletedSQL   = `UPDATE Domains SET Deleted = ?, DeleteTimeMillis = ? WHERE DomainId = ?`
+	setIntervalsSQL = `UPDATE Domains SET MinInterval = ?, MaxInterval = ? WHERE DomainId = ?;`
+
+	createAppVRFsSQL = `
+CREATE TABLE IF NOT EXISTS AppVRFs(
+  DomainId              VARCHAR(40) NOT NULL,
+  AppId                 VARCHAR(40) NOT NULL,
+  VRFPublicKey          MEDIUMBLOB NOT NULL,
+  VRFPrivateKey         MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId, AppId)
+);`
+	// writeAppVRFSQL inserts the app VRF only if the UserApps table of the
+	// appindex package records no entries for the app. The check and the
+	// insert are one statement so that an entry recorded concurrently either
+	// blocks the insert or sees the new VRF.
+	writeAppVRFSQL = `INSERT INTO AppVRFs
+(DomainId, AppId, VRFPublicKey, VRFPrivateKey)
+SELECT ?, ?, ?, ? FROM (SELECT 1) AS One
+WHERE NOT EXISTS (SELECT 1 FROM UserApps WHERE DomainId = ? AND AppId = ?);`
+	selectAppVRFsSQL = `SELECT DomainId, AppId, VRFPublicKey, VRFPrivateKey FROM AppVRFs`
+
+	createPrevVRFsSQL = `
+CREATE TABLE IF NOT EXISTS PrevVRFs(
+  DomainId              VARCHAR(40) NOT NULL,
+  VRFPublicKey          MEDIUMBLOB NOT NULL,
+  VRFPrivateKey         MEDIUMBLOB NOT NULL,
+  ExpiryNanos           BIGINT NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	readVRFSQL      = `SELECT VRFPublicKey, VRFPrivateKey FROM Domains WHERE DomainId = ?;`
+	writeVRFSQL     = `UPDATE Domains SET VRFPublicKey = ?, VRFPrivateKey = ? WHERE DomainId = ?;`
+	writePrevVRFSQL = `REPLACE INTO PrevVRFs
+(DomainId, VRFPublicKey, VRFPrivateKey, ExpiryNanos)
+VALUES (?, ?, ?, ?);`
+
+	createKeyPoliciesSQL = `
+CREATE TABLE IF NOT EXISTS KeyPolicies(
+  DomainId              VARCHAR(40) NOT NULL,
+  Policy                MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeKeyPolicySQL  = `REPLACE INTO KeyPolicies (DomainId, Policy) VALUES (?, ?);`
+	deleteKeyPolicySQL = `DELETE FROM KeyPolicies WHERE DomainId = ?;`
+
+	createKeyPolicyHistorySQL = `
+CREATE TABLE IF NOT EXISTS KeyPolicyHistory(
+  DomainId              VARCHAR(40) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  Policy                MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId, Revision)
+);`
+	// Policies set before the history was recorded are taken to apply from
+	// the first revision.
+	backfillKeyPolicyHistorySQL = `
+REPLACE INTO KeyPolicyHistory (DomainId, Revision, Policy)
+SELECT DomainId, 0, Policy FROM KeyPolicies;`
+	writeKeyPolicyChangeSQL   = `REPLACE INTO KeyPolicyHistory (DomainId, Revision, Policy) VALUES (?, ?, ?);`
+	selectKeyPolicyHistorySQL = `SELECT DomainId, Revision, Policy FROM KeyPolicyHistory`
+	orderKeyPolicyHistorySQL  = ` ORDER BY DomainId, Revision`
+
+	createEndorsementPoliciesSQL = `
+CREATE TABLE IF NOT EXISTS EndorsementPolicies(
+  DomainId              VARCHAR(40) NOT NULL,
+  Policy                MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeEndorsementPolicySQL  = `REPLACE INTO EndorsementPolicies (DomainId, Policy) VALUES (?, ?);`
+	deleteEndorsementPolicySQL = `DELETE FROM EndorsementPolicies WHERE DomainId = ?;`
+
+	createDomainMigrationsSQL = `
+CREATE TABLE IF NOT EXISTS DomainMigrations(
+  DomainId              VARCHAR(40) NOT NULL,
+  Migration             MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeMigrationSQL  = `REPLACE INTO DomainMigrations (DomainId, Migration) VALUES (?, ?);`
+	deleteMigrationSQL = `DELETE FROM DomainMigrations WHERE DomainId = ?;`
+
+	createTreeKeyRotationsSQL = `
+CREATE TABLE IF NOT EXISTS TreeKeyRotations(
+  DomainId              VARCHAR(40) NOT NULL,
+  TreeId                BIGINT NOT NULL,
+  Rotation              MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId, TreeId)
+);`
+	writeTreeKeyRotationSQL   = `REPLACE INTO TreeKeyRotations (DomainId, TreeId, Rotation) VALUES (?, ?, ?);`
+	selectTreeKeyRotationsSQL = `SELECT DomainId, TreeId, Rotation FROM TreeKeyRotations`
+
+	createDomainLabelsSQL = `
+CREATE TABLE IF NOT EXISTS DomainLabels(
+  DomainId              VARCHAR(40) NOT NULL,
+  Label                 VARCHAR(63) NOT NULL,
+  Value                 VARCHAR(63) NOT NULL,
+  PRIMARY KEY(DomainId, Label)
+);`
+	writeLabelSQL   = `INSERT INTO DomainLabels (DomainId, Label, Value) VALUES (?, ?, ?);`
+	deleteLabelsSQL = `DELETE FROM DomainLabels WHERE DomainId = ?;`
+	selectLabelsSQL = `SELECT DomainId, Label, Value FROM DomainLabels`
+
+	createLogBackendsSQL = `
+CREATE TABLE IF NOT EXISTS LogBackends(
+  DomainId              VARCHAR(40) NOT NULL,
+  Backend               VARCHAR(40) NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeLogBackendSQL = `INSERT INTO LogBackends (DomainId, Backend) VALUES (?, ?);`
+
+	createTrillianBackendsSQL = `
+CREATE TABLE IF NOT EXISTS TrillianBackends(
+  DomainId              VARCHAR(40) NOT NULL,
+  LogAddress            VARCHAR(255) NOT NULL,
+  MapAddress            VARCHAR(255) NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeTrillianBackendSQL = `INSERT INTO TrillianBackends (DomainId, LogAddress, MapAddress) VALUES (?, ?, ?);`
+
+	createFrozenDomainsSQL = `
+CREATE TABLE IF NOT EXISTS FrozenDomains(
+  DomainId              VARCHAR(40) NOT NULL,
+  FreezeTimeMillis      BIGINT NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeFrozenSQL  = `REPLACE INTO FrozenDomains (DomainId, FreezeTimeMillis) VALUES (?, ?);`
+	deleteFrozenSQL = `DELETE FROM FrozenDomains WHERE DomainId = ?;`
+
+	createAppListingDomainsSQL = `
+CREATE TABLE IF NOT EXISTS AppListingDomains(
+  DomainId              VARCHAR(40) NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeAppListingSQL  = `REPLACE INTO AppListingDomains (DomainId) VALUES (?);`
+	deleteAppListingSQL = `DELETE FROM AppListingDomains WHERE DomainId = ?;`
+
+	createDeletedTreesSQL = `
+CREATE TABLE IF NOT EXISTS DeletedTrees(
+  DomainId              VARCHAR(40) NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeDeletedTreesSQL  = `REPLACE INTO DeletedTrees (DomainId) VALUES (?);`
+	deleteDeletedTreesSQL = `DELETE FROM DeletedTrees WHERE DomainId = ?;`
+
+	createMutationQuotasSQL = `
+CREATE TABLE IF NOT EXISTS MutationQuotas(
+  DomainId              VARCHAR(40) NOT NULL,
+  Quota                 MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	writeMutationQuotaSQL  = `REPLACE INTO MutationQuotas (DomainId, Quota) VALUES (?, ?);`
+	deleteMutationQuotaSQL = `DELETE FROM MutationQuotas WHERE DomainId = ?;`
+
+	createDomainStatsSQL = `
+CREATE TABLE IF NOT EXISTS DomainStats(
+  DomainId              VARCHAR(40) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  Epochs                BIGINT NOT NULL,
+  Mutations             BIGINT NOT NULL,
+  Indexes               BIGINT NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	insertStatsSQL = `
+INSERT INTO DomainStats (DomainId, Revision, Epochs, Mutations, Indexes)
+VALUES (?, ?, 1, ?, ?);`
+	updateStatsSQL = `
+UPDATE DomainStats
+SET Revision = ?, Epochs = Epochs + 1, Mutations = Mutations + ?, Indexes = Indexes + ?
+WHERE DomainId = ? AND Revision < ?;`
+	countStatsSQL = `SELECT COUNT(*) FROM DomainStats WHERE DomainId = ?;`
 )
 
 type storage struct {
@@ -76,10 +261,29 @@ func NewStorage(db *sql.DB) (domain.Storage, error) {
 	return s, nil
 }
 
+// migrations create the domain tables in the order they were introduced.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE Domains;`}},
+	{Version: 2, Up: []string{createAppVRFsSQL}, Down: []string{`DROP TABLE AppVRFs;`}},
+	{Version: 3, Up: []string{createPrevVRFsSQL}, Down: []string{`DROP TABLE PrevVRFs;`}},
+	{Version: 4, Up: []string{createKeyPoliciesSQL}, Down: []string{`DROP TABLE KeyPolicies;`}},
+	{Version: 5, Up: []string{createFrozenDomainsSQL}, Down: []string{`DROP TABLE FrozenDomains;`}},
+	{Version: 6, Up: []string{createAppListingDomainsSQL}, Down: []string{`DROP TABLE AppListingDomains;`}},
+	{Version: 7, Up: []string{createMutationQuotasSQL}, Down: []string{`DROP TABLE MutationQuotas;`}},
+	{Version: 8, Up: []string{createDomainStatsSQL}, Down: []string{`DROP TABLE DomainStats;`}},
+	{Version: 9, Up: []string{createDeletedTreesSQL}, Down: []string{`DROP TABLE DeletedTrees;`}},
+	{Version: 10, Up: []string{createEndorsementPoliciesSQL}, Down: []string{`DROP TABLE EndorsementPolicies;`}},
+	{Version: 11, Up: []string{createDomainLabelsSQL}, Down: []string{`DROP TABLE DomainLabels;`}},
+	{Version: 12, Up: []string{createLogBackendsSQL}, Down: []string{`DROP TABLE LogBackends;`}},
+	{Version: 13, Up: []string{createDomainMigrationsSQL}, Down: []string{`DROP TABLE DomainMigrations;`}},
+	{Version: 14, Up: []string{createTreeKeyRotationsSQL}, Down: []string{`DROP TABLE TreeKeyRotations;`}},
+	{Version: 15, Up: []string{createTrillianBackendsSQL}, Down: []string{`DROP TABLE TrillianBackends;`}},
+	{Version: 16, Up: []string{createKeyPolicyHistorySQL, backfillKeyPolicyHistorySQL}, Down: []string{`DROP TABLE KeyPolicyHistory;`}},
+}
+
 func (s *storage) create() error {
-	_, err := s.db.Exec(createSQL)
-	if err != nil {
-		return fmt.Errorf("Failed to create commitments tables: %v", err)
+	if err := migrate.Apply(context.Background(), s.db, "domain", migrations); err != nil {
+		return fmt.Errorf("Failed to create domain tables: %w", err)
 	}
 	return nil
 }
@@ -104,24 +308,21 @@ func (s *storage) List(ctx context.Context, showDeleted bool) ([]*domain.Domain,
 	defer rows.Close()
 
 	ret := []*domain.Domain{}
+	byID := make(map[string]*domain.Domain)
 	for rows.Next() {
-		var pubkey, anyData []byte
-		d := &domain.Domain{}
-		if err := rows.Scan(
-			&d.DomainID,
-			&d.MapID, &d.LogID,
-			&pubkey, &anyData,
-			&d.MinInterval, &d.MaxInterval,
-			&d.Deleted); err != nil {
-			return nil, err
-		}
-		// Unwrap protos.
-		d.VRF = &keyspb.PublicKey{Der: pubkey}
-		d.VRFPriv, err = unwrapAnyProto(anyData)
+		d, err := scanDomain(rows)
 		if err != nil {
 			return nil, err
 		}
 		ret = append(ret, d)
+		byID[d.DomainID] = d
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.readSideTables(ctx, byID, ""); err != nil {
+		return nil, err
 	}
 	return ret, nil
 }
@@ -136,19 +337,34 @@ func (s *storage) Write(ctx context.Context, d *domain.Domain) error {
 	if err != nil {
 		return err
 	}
-	// Prepare SQL.
-	writeStmt, err := s.db.PrepareContext(ctx, writeSQL)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer writeStmt.Close()
-	_, err = writeStmt.ExecContext(ctx,
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, writeSQL,
 		d.DomainID,
 		d.MapID, d.LogID,
 		d.VRF.Der, anyData,
 		d.MinInterval.Nanoseconds(), d.MaxInterval.Nanoseconds(),
-		false)
-	return err
+		false); err != nil {
+		return err
+	}
+	if err := writeLabels(ctx, tx, d.DomainID, d.Labels); err != nil {
+		return err
+	}
+	if d.LogBackend != "" {
+		if _, err := tx.ExecContext(ctx, writeLogBackendSQL, d.DomainID, d.LogBackend); err != nil {
+			return err
+		}
+	}
+	if d.LogAddress != "" || d.MapAddress != "" {
+		if _, err := tx.ExecContext(ctx, writeTrillianBackendSQL, d.DomainID, d.LogAddress, d.MapAddress); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (s *storage) Read(ctx context.Context, domainID string, showDeleted bool) (*domain.Domain, error) {
@@ -163,26 +379,475 @@ func (s *storage) Read(ctx context.Context, domainID string, showDeleted bool) (
 		return nil, err
 	}
 	defer readStmt.Close()
+	d, err := scanDomain(readStmt.QueryRowContext(ctx, domainID))
+	if err == sql.ErrNoRows {
+		return nil, kterrors.Wrap(kterrors.ErrNotFound, fmt.Sprintf("domain.Read(%v)", domainID), err)
+	} else if err != nil {
+		return nil, kterrors.Wrap(kterrors.ErrStorage, fmt.Sprintf("domain.Read(%v)", domainID), err)
+	}
+	byID := map[string]*domain.Domain{d.DomainID: d}
+	if err := s.readSideTables(ctx, byID, whereDomainSQL, domainID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDomain reads a domain from a row of selectDomainsSQL.
+func scanDomain(row rowScanner) (*domain.Domain, error) {
 	d := &domain.Domain{}
 	var pubkey, anyData []byte
-	if err := readStmt.QueryRowContext(ctx, domainID).Scan(
+	var deleteMillis sql.NullInt64
+	var prevPubkey, prevAnyData []byte
+	var prevExpiry sql.NullInt64
+	var keyPolicy, endorsementPolicy, migration, quota []byte
+	var logBackend, logAddress, mapAddress sql.NullString
+	var frozen, appListing, treesDeleted sql.NullString
+	var revision, epochs, mutations, indexes sql.NullInt64
+	if err := row.Scan(
 		&d.DomainID,
 		&d.MapID, &d.LogID,
 		&pubkey, &anyData,
 		&d.MinInterval, &d.MaxInterval,
-		&d.Deleted); err != nil {
+		&d.Deleted, &deleteMillis,
+		&prevPubkey, &prevAnyData, &prevExpiry,
+		&keyPolicy, &endorsementPolicy, &migration,
+		&logBackend, &logAddress, &mapAddress,
+		&frozen, &appListing, &treesDeleted, &quota,
+		&revision, &epochs, &mutations, &indexes); err != nil {
 		return nil, err
 	}
+	d.DeleteTime = deleteTime(deleteMillis)
 
 	// Unwrap protos.
+	var err error
 	d.VRF = &keyspb.PublicKey{Der: pubkey}
 	d.VRFPriv, err = unwrapAnyProto(anyData)
 	if err != nil {
 		return nil, err
 	}
+	// A domain whose VRF was never rotated has no previous VRF.
+	if prevAnyData != nil {
+		vrfPriv, err := unwrapAnyProto(prevAnyData)
+		if err != nil {
+			return nil, err
+		}
+		d.PrevVRF = &domain.RotatedVRF{
+			VRF:     &keyspb.PublicKey{Der: prevPubkey},
+			VRFPriv: vrfPriv,
+			Expiry:  time.Unix(0, prevExpiry.Int64),
+		}
+	}
+	if keyPolicy != nil {
+		d.KeyPolicy = &pb.KeyPolicy{}
+		if err := proto.Unmarshal(keyPolicy, d.KeyPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if endorsementPolicy != nil {
+		d.EndorsementPolicy = &pb.EndorsementPolicy{}
+		if err := proto.Unmarshal(endorsementPolicy, d.EndorsementPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if migration != nil {
+		d.Migration = &pb.SignedDomainMigration{}
+		if err := proto.Unmarshal(migration, d.Migration); err != nil {
+			return nil, err
+		}
+	}
+	if quota != nil {
+		d.MutationQuota = &pb.MutationQuota{}
+		if err := proto.Unmarshal(quota, d.MutationQuota); err != nil {
+			return nil, err
+		}
+	}
+	// Empty backends refer to the defaults.
+	d.LogBackend = logBackend.String
+	d.LogAddress = logAddress.String
+	d.MapAddress = mapAddress.String
+	d.Frozen = frozen.Valid
+	d.AppListing = appListing.Valid
+	d.TreesDeleted = treesDeleted.Valid
+	// Stats are left zero if no epochs have been recorded.
+	d.Stats = domain.Stats{
+		Revision:  revision.Int64,
+		Epochs:    epochs.Int64,
+		Mutations: mutations.Int64,
+		Indexes:   indexes.Int64,
+	}
 	return d, nil
 }
 
+// readSideTables populates the fields of the domains in byID that are stored
+// in tables with several rows per domain. where, with args, restricts the
+// rows read. Rows of other domains are ignored.
+func (s *storage) readSideTables(ctx context.Context, byID map[string]*domain.Domain, where string, args ...interface{}) error {
+	for _, read := range []func(context.Context, map[string]*domain.Domain, string, ...interface{}) error{
+		s.readAppVRFs,
+		s.readKeyPolicyHistory,
+		s.readTreeKeyRotations,
+		s.readLabels,
+	} {
+		if err := read(ctx, byID, where, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAppVRFs populates the AppVRFs of the domains in byID. They are left nil
+// for domains without app VRFs.
+func (s *storage) readAppVRFs(ctx context.Context, byID map[string]*domain.Domain, where string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, selectAppVRFsSQL+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var domainID, appID string
+		var pubkey, anyData []byte
+		if err := rows.Scan(&domainID, &appID, &pubkey, &anyData); err != nil {
+			return err
+		}
+		d, ok := byID[domainID]
+		if !ok {
+			continue
+		}
+		vrfPriv, err := unwrapAnyProto(anyData)
+		if err != nil {
+			return err
+		}
+		if d.AppVRFs == nil {
+			d.AppVRFs = make(map[string]*domain.AppVRF)
+		}
+		d.AppVRFs[appID] = &domain.AppVRF{
+			VRF:     &keyspb.PublicKey{Der: pubkey},
+			VRFPriv: vrfPriv,
+		}
+	}
+	return rows.Err()
+}
+
+// AddAppVRF stores a VRF key pair that is scoped to appID. The UserApps
+// table of the appindex package must be in the same database.
+func (s *storage) AddAppVRF(ctx context.Context, domainID, appID string, vrf *keyspb.PublicKey, vrfPriv proto.Message) error {
+	anyPB, err := ptypes.MarshalAny(vrfPriv)
+	if err != nil {
+		return err
+	}
+	anyData, err := proto.Marshal(anyPB)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.ExecContext(ctx, writeAppVRFSQL, domainID, appID, vrf.Der, anyData, domainID, appID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrAppHasEntries
+	}
+	return nil
+}
+
+// RotateVRF replaces the domain VRF key pair and keeps the replaced key pair
+// as the previous VRF until overlapEnd.
+func (s *storage) RotateVRF(ctx context.Context, domainID string, vrf *keyspb.PublicKey, vrfPriv proto.Message, overlapEnd time.Time) error {
+	anyPB, err := ptypes.MarshalAny(vrfPriv)
+	if err != nil {
+		return err
+	}
+	anyData, err := proto.Marshal(anyPB)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback()
+
+	var oldPubkey, oldAnyData []byte
+	if err := tx.QueryRowContext(ctx, readVRFSQL, domainID).Scan(&oldPubkey, &oldAnyData); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, writePrevVRFSQL,
+		domainID, oldPubkey, oldAnyData, overlapEnd.UnixNano()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, writeVRFSQL, vrf.Der, anyData, domainID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// readKeyPolicyHistory populates the KeyPolicyHistory of the domains in byID,
+// oldest change first.
+func (s *storage) readKeyPolicyHistory(ctx context.Context, byID map[string]*domain.Domain, where string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, selectKeyPolicyHistorySQL+where+orderKeyPolicyHistorySQL, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var domainID string
+		change := &pb.KeyPolicyChange{}
+		var data []byte
+		if err := rows.Scan(&domainID, &change.Revision, &data); err != nil {
+			return err
+		}
+		d, ok := byID[domainID]
+		if !ok {
+			continue
+		}
+		// An empty policy removed the restrictions.
+		if len(data) > 0 {
+			change.KeyPolicy = &pb.KeyPolicy{}
+			if err := proto.Unmarshal(data, change.KeyPolicy); err != nil {
+				return err
+			}
+		}
+		d.KeyPolicyHistory = append(d.KeyPolicyHistory, change)
+	}
+	return rows.Err()
+}
+
+// SetKeyPolicy replaces the key policy of a domain and records the change in
+// its history as applying from revision on. A nil policy removes it.
+func (s *storage) SetKeyPolicy(ctx context.Context, domainID string, policy *pb.KeyPolicy, revision int64) error {
+	data := []byte{}
+	if policy != nil {
+		var err error
+		if data, err = proto.Marshal(policy); err != nil {
+			return err
+		}
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback()
+	if policy == nil {
+		_, err = tx.ExecContext(ctx, deleteKeyPolicySQL, domainID)
+	} else {
+		_, err = tx.ExecContext(ctx, writeKeyPolicySQL, domainID, data)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, writeKeyPolicyChangeSQL, domainID, revision, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetEndorsementPolicy replaces the endorsement policy of a domain. A nil
+// policy removes it.
+func (s *storage) SetEndorsementPolicy(ctx context.Context, domainID string, policy *pb.EndorsementPolicy) error {
+	if policy == nil {
+		_, err := s.db.ExecContext(ctx, deleteEndorsementPolicySQL, domainID)
+		return err
+	}
+	data, err := proto.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, writeEndorsementPolicySQL, domainID, data)
+	return err
+}
+
+// SetMigration replaces the migration announcement of a domain. A nil
+// announcement removes it.
+func (s *storage) SetMigration(ctx context.Context, domainID string, migration *pb.SignedDomainMigration) error {
+	if migration == nil {
+		_, err := s.db.ExecContext(ctx, deleteMigrationSQL, domainID)
+		return err
+	}
+	data, err := proto.Marshal(migration)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, writeMigrationSQL, domainID, data)
+	return err
+}
+
+// readTreeKeyRotations populates the LogKeyRotation and MapKeyRotation of the
+// domains in byID.
+// Rotations of trees that no longer belong to the domain are ignored.
+func (s *storage) readTreeKeyRotations(ctx context.Context, byID map[string]*domain.Domain, where string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, selectTreeKeyRotationsSQL+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var domainID string
+		var treeID int64
+		var data []byte
+		if err := rows.Scan(&domainID, &treeID, &data); err != nil {
+			return err
+		}
+		d, ok := byID[domainID]
+		if !ok {
+			continue
+		}
+		rotation := &pb.TreeKeyRotation{}
+		if err := proto.Unmarshal(data, rotation); err != nil {
+			return err
+		}
+		switch treeID {
+		case d.LogID:
+			d.LogKeyRotation = rotation
+		case d.MapID:
+			d.MapKeyRotation = rotation
+		}
+	}
+	return rows.Err()
+}
+
+// SetTreeKeyRotation records the most recent key rotation of a tree of a
+// domain.
+func (s *storage) SetTreeKeyRotation(ctx context.Context, domainID string, treeID int64, rotation *pb.TreeKeyRotation) error {
+	data, err := proto.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, writeTreeKeyRotationSQL, domainID, treeID, data)
+	return err
+}
+
+// readLabels populates the Labels of the domains in byID. They are left nil
+// for domains without labels.
+func (s *storage) readLabels(ctx context.Context, byID map[string]*domain.Domain, where string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, selectLabelsSQL+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var domainID, label, value string
+		if err := rows.Scan(&domainID, &label, &value); err != nil {
+			return err
+		}
+		d, ok := byID[domainID]
+		if !ok {
+			continue
+		}
+		if d.Labels == nil {
+			d.Labels = make(map[string]string)
+		}
+		d.Labels[label] = value
+	}
+	return rows.Err()
+}
+
+// SetLabels replaces the labels of a domain.
+func (s *storage) SetLabels(ctx context.Context, domainID string, labels map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback is a no-op once the transaction has been committed.
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, deleteLabelsSQL, domainID); err != nil {
+		return err
+	}
+	if err := writeLabels(ctx, tx, domainID, labels); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeLabels inserts labels for domainID in tx.
+func writeLabels(ctx context.Context, tx *sql.Tx, domainID string, labels map[string]string) error {
+	for label, value := range labels {
+		if _, err := tx.ExecContext(ctx, writeLabelSQL, domainID, label, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetFrozen freezes or unfreezes a domain.
+func (s *storage) SetFrozen(ctx context.Context, domainID string, frozen bool) error {
+	if !frozen {
+		_, err := s.db.ExecContext(ctx, deleteFrozenSQL, domainID)
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, writeFrozenSQL, domainID, time.Now().UnixNano()/int64(time.Millisecond))
+	return err
+}
+
+// SetAppListing enables or disables app listing for a domain.
+func (s *storage) SetAppListing(ctx context.Context, domainID string, enabled bool) error {
+	stmt := deleteAppListingSQL
+	if enabled {
+		stmt = writeAppListingSQL
+	}
+	_, err := s.db.ExecContext(ctx, stmt, domainID)
+	return err
+}
+
+// SetTreesDeleted records whether the Trillian trees of a domain have been
+// deleted.
+func (s *storage) SetTreesDeleted(ctx context.Context, domainID string, deleted bool) error {
+	stmt := deleteDeletedTreesSQL
+	if deleted {
+		stmt = writeDeletedTreesSQL
+	}
+	_, err := s.db.ExecContext(ctx, stmt, domainID)
+	return err
+}
+
+// SetMutationQuota replaces the mutation quota of a domain. A nil quota
+// removes it.
+func (s *storage) SetMutationQuota(ctx context.Context, domainID string, quota *pb.MutationQuota) error {
+	if quota == nil {
+		_, err := s.db.ExecContext(ctx, deleteMutationQuotaSQL, domainID)
+		return err
+	}
+	data, err := proto.Marshal(quota)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, writeMutationQuotaSQL, domainID, data)
+	return err
+}
+
+// RecordEpoch adds an epoch to the stats of a domain. Epochs at or below the
+// last recorded revision are ignored.
+func (s *storage) RecordEpoch(ctx context.Context, domainID string, revision, mutations, newIndexes int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var count int
+	if err := tx.QueryRowContext(ctx, countStatsSQL, domainID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		_, err = tx.ExecContext(ctx, insertStatsSQL, domainID, revision, mutations, newIndexes)
+	} else {
+		_, err = tx.ExecContext(ctx, updateStatsSQL, revision, mutations, newIndexes, domainID, revision)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // unwrapAnyProto returns the proto object seralized inside a serialized any.Any
 func unwrapAnyProto(anyData []byte) (proto.Message, error) {
 	var anyPB any.Any
@@ -197,6 +862,34 @@ func unwrapAnyProto(anyData []byte) (proto.Message, error) {
 }
 
 func (s *storage) SetDelete(ctx context.Context, domainID string, isDeleted bool) error {
-	_, err := s.db.ExecContext(ctx, setDeletedSQL, isDeleted, time.Now().Unix(), domainID)
+	var deleteMillis sql.NullInt64
+	if isDeleted {
+		deleteMillis = sql.NullInt64{Int64: time.Now().UnixNano() / int64(time.Millisecond), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, setDeletedSQL, isDeleted, deleteMillis, domainID)
+	return err
+}
+
+// SetIntervals replaces the epoch intervals of a domain.
+func (s *storage) SetIntervals(ctx context.Context, domainID string, minInterval, maxInterval time.Duration) error {
+	_, err := s.db.ExecContext(ctx, setIntervalsSQL, minInterval.Nanoseconds(), maxInterval.Nanoseconds(), domainID)
 	return err
 }
+
+// legacySecondsLimit bounds the DeleteTimeMillis values written by versions
+// that stored seconds in that column. As milliseconds, they would all be in
+// 1973 or earlier.
+const legacySecondsLimit = 1e11
+
+// deleteTime converts a DeleteTimeMillis value to a time. NULL values, for
+// domains that are not deleted, are the zero time.
+func deleteTime(ms sql.NullInt64) time.Time {
+	switch {
+	case !ms.Valid || ms.Int64 == 0:
+		return time.Time{}
+	case ms.Int64 < legacySecondsLimit:
+		return time.Unix(ms.Int64, 0)
+	default:
+		return time.Unix(0, ms.Int64*int64(time.Millisecond))
+	}
+}
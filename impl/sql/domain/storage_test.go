@@ -21,11 +21,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/keytransparency/core/domain"
+	"github.com/google/keytransparency/impl/sql/appindex"
 
 	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
 func TestList(t *testing.T) {
@@ -84,6 +89,80 @@ func TestList(t *testing.T) {
 	}
 }
 
+// TestListSideTables checks that List attributes the rows of the side tables
+// to the right domains.
+func TestListSideTables(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	if _, err := appindex.NewStorage(db); err != nil {
+		t.Fatalf("appindex.NewStorage(): %v", err)
+	}
+	for i, id := range []string{"domain1", "domain2", "domain3"} {
+		d := &domain.Domain{
+			DomainID:    id,
+			MapID:       int64(2 * i),
+			LogID:       int64(2*i + 1),
+			VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+			VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+			MinInterval: 1 * time.Second,
+			MaxInterval: 5 * time.Second,
+			Labels:      map[string]string{"name": id},
+		}
+		if id == "domain2" {
+			d.LogAddress, d.MapAddress = "log:8090", "map:8090"
+		}
+		if err := admin.Write(ctx, d); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	// Only domain2 has rows in the other side tables.
+	if err := admin.AddAppVRF(ctx, "domain2", "app1",
+		&keyspb.PublicKey{Der: []byte("apppubkeybytes")},
+		&keyspb.PrivateKey{Der: []byte("appprivkeybytes")}); err != nil {
+		t.Fatalf("AddAppVRF(): %v", err)
+	}
+	if err := admin.SetKeyPolicy(ctx, "domain2", &pb.KeyPolicy{MaxKeys: 2}, 3); err != nil {
+		t.Fatalf("SetKeyPolicy(): %v", err)
+	}
+	if err := admin.SetFrozen(ctx, "domain2", true); err != nil {
+		t.Fatalf("SetFrozen(): %v", err)
+	}
+	if err := admin.RecordEpoch(ctx, "domain2", 1, 4, 2); err != nil {
+		t.Fatalf("RecordEpoch(): %v", err)
+	}
+
+	domains, err := admin.List(ctx, false)
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	if got, want := len(domains), 3; got != want {
+		t.Fatalf("List(): %v domains, want %v", got, want)
+	}
+	for _, got := range domains {
+		want, err := admin.Read(ctx, got.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(%v): %v", got.DomainID, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("List(): %+v, want %+v", got, want)
+		}
+		if frozen, want := got.Frozen, got.DomainID == "domain2"; frozen != want {
+			t.Errorf("%v: Frozen: %v, want %v", got.DomainID, frozen, want)
+		}
+		if name := got.Labels["name"]; name != got.DomainID {
+			t.Errorf("%v: Labels[name]: %v, want %v", got.DomainID, name, got.DomainID)
+		}
+	}
+}
+
 func TestWriteReadDelete(t *testing.T) {
 	ctx := context.Background()
 	db, err := sql.Open("sqlite3", ":memory:")
@@ -207,9 +286,695 @@ func TestWriteReadDelete(t *testing.T) {
 				return
 			}
 			tc.d.Deleted = tc.isDeleted
+			if got, want := !domain.DeleteTime.IsZero(), tc.isDeleted; got != want {
+				t.Errorf("DeleteTime: %v, want set: %v", domain.DeleteTime, want)
+			}
+			if d := time.Since(domain.DeleteTime); tc.isDeleted && (d < 0 || d > time.Minute) {
+				t.Errorf("DeleteTime: %v, want about now", domain.DeleteTime)
+			}
+			domain.DeleteTime = time.Time{}
 			if got, want := *domain, tc.d; !reflect.DeepEqual(got, want) {
 				t.Errorf("Domain: %v, want %v", got, want)
 			}
 		})
 	}
 }
+
+func TestDeleteTime(t *testing.T) {
+	now := time.Unix(1520000000, 123000000)
+	for _, tc := range []struct {
+		desc string
+		ms   sql.NullInt64
+		want time.Time
+	}{
+		{desc: "null"},
+		{desc: "millis", ms: sql.NullInt64{Int64: 1520000000123, Valid: true}, want: now},
+		{desc: "legacy seconds", ms: sql.NullInt64{Int64: 1520000000, Valid: true}, want: time.Unix(1520000000, 0)},
+	} {
+		if got := deleteTime(tc.ms); !got.Equal(tc.want) {
+			t.Errorf("%v: deleteTime(%v): %v, want %v", tc.desc, tc.ms, got, tc.want)
+		}
+	}
+}
+
+func TestAddAppVRF(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	apps, err := appindex.NewStorage(db)
+	if err != nil {
+		t.Fatalf("appindex.NewStorage(): %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	appVRF := &domain.AppVRF{
+		VRF:     &keyspb.PublicKey{Der: []byte("apppubkeybytes")},
+		VRFPriv: &keyspb.PrivateKey{Der: []byte("appprivkeybytes")},
+	}
+	if err := admin.AddAppVRF(ctx, d.DomainID, "app1", appVRF.VRF, appVRF.VRFPriv); err != nil {
+		t.Fatalf("AddAppVRF(): %v", err)
+	}
+	if err := admin.AddAppVRF(ctx, d.DomainID, "app1", appVRF.VRF, appVRF.VRFPriv); err == nil {
+		t.Errorf("AddAppVRF(): duplicate app succeeded, want err")
+	}
+	if err := apps.Add(ctx, d.DomainID, "alice", "app2"); err != nil {
+		t.Fatalf("apps.Add(): %v", err)
+	}
+	if err := admin.AddAppVRF(ctx, d.DomainID, "app2", appVRF.VRF, appVRF.VRFPriv); err != domain.ErrAppHasEntries {
+		t.Errorf("AddAppVRF(app with entries): %v, want %v", err, domain.ErrAppHasEntries)
+	}
+
+	got, err := admin.Read(ctx, d.DomainID, false)
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	if want := map[string]*domain.AppVRF{"app1": appVRF}; !reflect.DeepEqual(got.AppVRFs, want) {
+		t.Errorf("AppVRFs: %v, want %v", got.AppVRFs, want)
+	}
+	for _, tc := range []struct {
+		appID string
+		want  *keyspb.PublicKey
+	}{
+		{appID: "app1", want: appVRF.VRF},
+		{appID: "app2", want: d.VRF},
+	} {
+		if pub, _ := got.VRFFor(tc.appID); !reflect.DeepEqual(pub, tc.want) {
+			t.Errorf("VRFFor(%v): %v, want %v", tc.appID, pub, tc.want)
+		}
+	}
+}
+
+func TestRotateVRF(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if err := admin.RotateVRF(ctx, "nodomain", d.VRF, d.VRFPriv, time.Now()); err != sql.ErrNoRows {
+		t.Errorf("RotateVRF(nodomain): %v, want %v", err, sql.ErrNoRows)
+	}
+
+	expiry := time.Unix(1000, 0)
+	for _, newVRF := range []*domain.AppVRF{
+		{
+			VRF:     &keyspb.PublicKey{Der: []byte("pubkeybytes2")},
+			VRFPriv: &keyspb.PrivateKey{Der: []byte("privkeybytes2")},
+		},
+		{
+			VRF:     &keyspb.PublicKey{Der: []byte("pubkeybytes3")},
+			VRFPriv: &keyspb.PrivateKey{Der: []byte("privkeybytes3")},
+		},
+	} {
+		before, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if err := admin.RotateVRF(ctx, d.DomainID, newVRF.VRF, newVRF.VRFPriv, expiry); err != nil {
+			t.Fatalf("RotateVRF(): %v", err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !reflect.DeepEqual(got.VRF, newVRF.VRF) || !reflect.DeepEqual(got.VRFPriv, newVRF.VRFPriv) {
+			t.Errorf("VRF: %v, want %v", got.VRF, newVRF.VRF)
+		}
+		want := &domain.RotatedVRF{VRF: before.VRF, VRFPriv: before.VRFPriv, Expiry: expiry}
+		if !reflect.DeepEqual(got.PrevVRF, want) {
+			t.Errorf("PrevVRF: %v, want %v", got.PrevVRF, want)
+		}
+		if _, _, ok := got.PrevVRFFor("app", expiry.Add(-time.Second)); !ok {
+			t.Errorf("PrevVRFFor(before expiry): false, want true")
+		}
+		if _, _, ok := got.PrevVRFFor("app", expiry); ok {
+			t.Errorf("PrevVRFFor(expiry): true, want false")
+		}
+	}
+}
+
+func TestSetIntervals(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		min, max time.Duration
+	}{
+		{min: 2 * time.Second, max: 10 * time.Second},
+		{min: 0, max: 0},
+	} {
+		if err := admin.SetIntervals(ctx, d.DomainID, tc.min, tc.max); err != nil {
+			t.Fatalf("SetIntervals(): %v", err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if got.MinInterval != tc.min || got.MaxInterval != tc.max {
+			t.Errorf("Intervals: %v-%v, want %v-%v", got.MinInterval, got.MaxInterval, tc.min, tc.max)
+		}
+	}
+}
+
+func TestSetKeyPolicy(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	var history []*pb.KeyPolicyChange
+	for i, policy := range []*pb.KeyPolicy{
+		{MaxKeys: 2},
+		{AllowedAlgorithms: []sigpb.DigitallySigned_SignatureAlgorithm{sigpb.DigitallySigned_ECDSA}},
+		nil,
+	} {
+		revision := int64(10 * i)
+		if err := admin.SetKeyPolicy(ctx, d.DomainID, policy, revision); err != nil {
+			t.Fatalf("SetKeyPolicy(): %v", err)
+		}
+		history = append(history, &pb.KeyPolicyChange{Revision: revision, KeyPolicy: policy})
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !proto.Equal(got.KeyPolicy, policy) {
+			t.Errorf("KeyPolicy: %v, want %v", got.KeyPolicy, policy)
+		}
+		if len(got.KeyPolicyHistory) != len(history) {
+			t.Fatalf("KeyPolicyHistory: %v, want %v", got.KeyPolicyHistory, history)
+		}
+		for j, c := range got.KeyPolicyHistory {
+			if !proto.Equal(c, history[j]) {
+				t.Errorf("KeyPolicyHistory[%v]: %v, want %v", j, c, history[j])
+			}
+		}
+	}
+}
+
+func TestSetEndorsementPolicy(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, policy := range []*pb.EndorsementPolicy{
+		{Keys: []*keyspb.PublicKey{{Der: []byte("a")}, {Der: []byte("b")}}, Quorum: 2},
+		{Keys: []*keyspb.PublicKey{{Der: []byte("a")}}, Quorum: 1},
+		nil,
+	} {
+		if err := admin.SetEndorsementPolicy(ctx, d.DomainID, policy); err != nil {
+			t.Fatalf("SetEndorsementPolicy(): %v", err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !proto.Equal(got.EndorsementPolicy, policy) {
+			t.Errorf("EndorsementPolicy: %v, want %v", got.EndorsementPolicy, policy)
+		}
+	}
+}
+
+func TestSetMigration(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, migration := range []*pb.SignedDomainMigration{
+		{Migration: []byte("a"), Signature: &sigpb.DigitallySigned{Signature: []byte("sig a")}},
+		{Migration: []byte("b"), Signature: &sigpb.DigitallySigned{Signature: []byte("sig b")}},
+		nil,
+	} {
+		if err := admin.SetMigration(ctx, d.DomainID, migration); err != nil {
+			t.Fatalf("SetMigration(): %v", err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !proto.Equal(got.Migration, migration) {
+			t.Errorf("Migration: %v, want %v", got.Migration, migration)
+		}
+	}
+}
+
+func TestSetTreeKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	mapRotation := &pb.TreeKeyRotation{
+		PreviousKey: &keyspb.PublicKey{Der: []byte("old map key")},
+		PublicKey:   &keyspb.PublicKey{Der: []byte("new map key")},
+	}
+	logRotation := &pb.TreeKeyRotation{
+		PreviousKey: &keyspb.PublicKey{Der: []byte("old log key")},
+		PublicKey:   &keyspb.PublicKey{Der: []byte("new log key")},
+	}
+	for _, tc := range []struct {
+		treeID   int64
+		rotation *pb.TreeKeyRotation
+	}{
+		{treeID: d.MapID, rotation: mapRotation},
+		{treeID: d.LogID, rotation: logRotation},
+		{treeID: 3, rotation: logRotation},
+	} {
+		if err := admin.SetTreeKeyRotation(ctx, d.DomainID, tc.treeID, tc.rotation); err != nil {
+			t.Fatalf("SetTreeKeyRotation(%v): %v", tc.treeID, err)
+		}
+	}
+	for _, showDeleted := range []bool{false, true} {
+		got, err := admin.Read(ctx, d.DomainID, showDeleted)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !proto.Equal(got.MapKeyRotation, mapRotation) {
+			t.Errorf("MapKeyRotation: %v, want %v", got.MapKeyRotation, mapRotation)
+		}
+		if !proto.Equal(got.LogKeyRotation, logRotation) {
+			t.Errorf("LogKeyRotation: %v, want %v", got.LogKeyRotation, logRotation)
+		}
+	}
+}
+
+func TestTrillianBackend(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	for _, d := range []*domain.Domain{
+		{DomainID: "default"},
+		{DomainID: "remote", LogAddress: "log.cluster2:8090", MapAddress: "map.cluster2:8090"},
+		{DomainID: "remotemap", MapAddress: "map.cluster3:8090"},
+	} {
+		d.VRF = &keyspb.PublicKey{Der: []byte("pubkeybytes")}
+		d.VRFPriv = &keyspb.PrivateKey{Der: []byte("privkeybytes")}
+		if err := admin.Write(ctx, d); err != nil {
+			t.Fatalf("Write(%v): %v", d.DomainID, err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(%v): %v", d.DomainID, err)
+		}
+		if got.LogAddress != d.LogAddress || got.MapAddress != d.MapAddress {
+			t.Errorf("Read(%v): addresses %q, %q, want %q, %q",
+				d.DomainID, got.LogAddress, got.MapAddress, d.LogAddress, d.MapAddress)
+		}
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+		Labels:      map[string]string{"env": "prod"},
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	got, err := admin.Read(ctx, d.DomainID, false)
+	if err != nil {
+		t.Fatalf("Read(): %v", err)
+	}
+	if !reflect.DeepEqual(got.Labels, d.Labels) {
+		t.Errorf("Labels after Write(): %v, want %v", got.Labels, d.Labels)
+	}
+
+	for _, labels := range []map[string]string{
+		{"env": "dev", "team": "kt"},
+		{"team": ""},
+		nil,
+	} {
+		if err := admin.SetLabels(ctx, d.DomainID, labels); err != nil {
+			t.Fatalf("SetLabels(): %v", err)
+		}
+		domains, err := admin.List(ctx, false)
+		if err != nil {
+			t.Fatalf("List(): %v", err)
+		}
+		if len(domains) != 1 {
+			t.Fatalf("List(): %v domains, want 1", len(domains))
+		}
+		if got := domains[0].Labels; !reflect.DeepEqual(got, labels) {
+			t.Errorf("Labels: %v, want %v", got, labels)
+		}
+	}
+}
+
+func TestSetFrozen(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, frozen := range []bool{true, true, false, false} {
+		if err := admin.SetFrozen(ctx, d.DomainID, frozen); err != nil {
+			t.Fatalf("SetFrozen(%v): %v", frozen, err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if got.Frozen != frozen {
+			t.Errorf("Frozen: %v, want %v", got.Frozen, frozen)
+		}
+		domains, err := admin.List(ctx, false)
+		if err != nil {
+			t.Fatalf("List(): %v", err)
+		}
+		if len(domains) != 1 || domains[0].Frozen != frozen {
+			t.Errorf("List(): %v, want one domain with Frozen: %v", domains, frozen)
+		}
+	}
+}
+
+func TestSetAppListing(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, enabled := range []bool{true, true, false, false} {
+		if err := admin.SetAppListing(ctx, d.DomainID, enabled); err != nil {
+			t.Fatalf("SetAppListing(%v): %v", enabled, err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if got.AppListing != enabled {
+			t.Errorf("AppListing: %v, want %v", got.AppListing, enabled)
+		}
+	}
+}
+
+func TestSetTreesDeleted(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, deleted := range []bool{true, true, false, false} {
+		if err := admin.SetTreesDeleted(ctx, d.DomainID, deleted); err != nil {
+			t.Fatalf("SetTreesDeleted(%v): %v", deleted, err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, true)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if got.TreesDeleted != deleted {
+			t.Errorf("TreesDeleted: %v, want %v", got.TreesDeleted, deleted)
+		}
+	}
+}
+
+func TestSetMutationQuota(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, quota := range []*pb.MutationQuota{
+		{MutationsPerEpoch: 100},
+		{MutationsPerEpoch: 100, MutationsPerUserPerDay: 10},
+		nil,
+	} {
+		if err := admin.SetMutationQuota(ctx, d.DomainID, quota); err != nil {
+			t.Fatalf("SetMutationQuota(): %v", err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if !proto.Equal(got.MutationQuota, quota) {
+			t.Errorf("MutationQuota: %v, want %v", got.MutationQuota, quota)
+		}
+	}
+}
+
+func TestRecordEpoch(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	admin, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("Failed to create adminstorage: %v", err)
+	}
+	d := &domain.Domain{
+		DomainID:    "testdomain",
+		MapID:       1,
+		LogID:       2,
+		VRF:         &keyspb.PublicKey{Der: []byte("pubkeybytes")},
+		VRFPriv:     &keyspb.PrivateKey{Der: []byte("privkeybytes")},
+		MinInterval: 1 * time.Second,
+		MaxInterval: 5 * time.Second,
+	}
+	if err := admin.Write(ctx, d); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		revision, mutations, newIndexes int64
+		want                            domain.Stats
+	}{
+		{revision: 1, mutations: 3, newIndexes: 2, want: domain.Stats{Revision: 1, Epochs: 1, Mutations: 3, Indexes: 2}},
+		{revision: 2, mutations: 5, newIndexes: 1, want: domain.Stats{Revision: 2, Epochs: 2, Mutations: 8, Indexes: 3}},
+		// Recording a revision again does not count it twice.
+		{revision: 2, mutations: 5, newIndexes: 1, want: domain.Stats{Revision: 2, Epochs: 2, Mutations: 8, Indexes: 3}},
+		{revision: 1, mutations: 3, newIndexes: 2, want: domain.Stats{Revision: 2, Epochs: 2, Mutations: 8, Indexes: 3}},
+	} {
+		if err := admin.RecordEpoch(ctx, d.DomainID, tc.revision, tc.mutations, tc.newIndexes); err != nil {
+			t.Fatalf("RecordEpoch(%v): %v", tc.revision, err)
+		}
+		got, err := admin.Read(ctx, d.DomainID, false)
+		if err != nil {
+			t.Fatalf("Read(): %v", err)
+		}
+		if got.Stats != tc.want {
+			t.Errorf("RecordEpoch(%v): Stats %+v, want %+v", tc.revision, got.Stats, tc.want)
+		}
+	}
+}
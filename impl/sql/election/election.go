@@ -0,0 +1,104 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package election implements the election.Election interface with leases
+// stored in an SQL table.
+package election
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/keytransparency/core/election"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS Leases(
+  DomainId              VARCHAR(40) NOT NULL,
+  Holder                VARCHAR(255) NOT NULL,
+  Expiry                BIGINT NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	readSQL   = `SELECT Holder, Expiry FROM Leases WHERE DomainId = ?;`
+	insertSQL = `INSERT INTO Leases (DomainId, Holder, Expiry) VALUES (?, ?, ?);`
+	// updateSQL only takes over the lease that was read, so that two
+	// sequencers cannot both take over an expired lease.
+	updateSQL = `
+UPDATE Leases SET Holder = ?, Expiry = ?
+WHERE DomainId = ? AND Holder = ? AND Expiry = ?;`
+	resignSQL = `DELETE FROM Leases WHERE DomainId = ? AND Holder = ?;`
+)
+
+// migrations create the Leases table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE Leases;`}},
+}
+
+type leases struct {
+	db     *sql.DB
+	holder string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// New returns an election.Election in which holder, which must be unique to
+// each sequencer, holds leases of ttl. Sequencers renew their lease before
+// each epoch, so ttl must be longer than the max interval of the domains.
+func New(db *sql.DB, holder string, ttl time.Duration) (election.Election, error) {
+	l := &leases{db: db, holder: holder, ttl: ttl, now: time.Now}
+	if err := migrate.Apply(context.Background(), l.db, "election", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create Leases table: %w", err)
+	}
+	return l, nil
+}
+
+func (l *leases) Acquire(ctx context.Context, domainID string) error {
+	now := l.now()
+	expiry := now.Add(l.ttl).UnixNano()
+	var holder string
+	var oldExpiry int64
+	switch err := l.db.QueryRowContext(ctx, readSQL, domainID).Scan(&holder, &oldExpiry); {
+	case err == sql.ErrNoRows:
+		if _, err := l.db.ExecContext(ctx, insertSQL, domainID, l.holder, expiry); err != nil {
+			return fmt.Errorf("inserting lease of %v: %w", domainID, err)
+		}
+		return nil
+	case err != nil:
+		return err
+	case holder != l.holder && oldExpiry > now.UnixNano():
+		return election.ErrNotLeader
+	}
+	res, err := l.db.ExecContext(ctx, updateSQL, l.holder, expiry, domainID, holder, oldExpiry)
+	if err != nil {
+		return fmt.Errorf("updating lease of %v: %w", domainID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		// Another sequencer changed the lease since it was read.
+		return election.ErrNotLeader
+	}
+	return nil
+}
+
+func (l *leases) Resign(ctx context.Context, domainID string) error {
+	_, err := l.db.ExecContext(ctx, resignSQL, domainID, l.holder)
+	return err
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package election
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/keytransparency/core/election"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	now := time.Now()
+	newLeases := func(holder string) *leases {
+		e, err := New(db, holder, time.Minute)
+		if err != nil {
+			t.Fatalf("New(): %v", err)
+		}
+		l := e.(*leases)
+		l.now = func() time.Time { return now }
+		return l
+	}
+	a, b := newLeases("a"), newLeases("b")
+
+	for _, tc := range []struct {
+		desc    string
+		l       *leases
+		advance time.Duration
+		resign  *leases
+		want    error
+	}{
+		{desc: "first", l: a},
+		{desc: "renew", l: a, advance: 30 * time.Second},
+		{desc: "held", l: b, advance: 30 * time.Second, want: election.ErrNotLeader},
+		{desc: "expired", l: b, advance: 2 * time.Minute},
+		{desc: "taken over", l: a, want: election.ErrNotLeader},
+		{desc: "resigned", l: a, resign: b},
+	} {
+		now = now.Add(tc.advance)
+		if tc.resign != nil {
+			if err := tc.resign.Resign(ctx, "domain"); err != nil {
+				t.Fatalf("%v: Resign(): %v", tc.desc, err)
+			}
+		}
+		if err := tc.l.Acquire(ctx, "domain"); err != tc.want {
+			t.Errorf("%v: Acquire(): %v, want %v", tc.desc, err, tc.want)
+		}
+	}
+}
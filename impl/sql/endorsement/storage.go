@@ -0,0 +1,102 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package endorsement implements the endorsement.Storage interface.
+package endorsement
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/endorsement"
+	"github.com/google/keytransparency/impl/sql/migrate"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS Endorsements(
+  DomainId              VARCHAR(40) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  KeyId                 VARBINARY(32) NOT NULL,
+  Signature             MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId, Revision, KeyId)
+);`
+	readSQL = `
+SELECT KeyId, Signature FROM Endorsements
+WHERE DomainId = ? AND Revision = ?
+ORDER BY KeyId ASC;`
+	writeSQL = `REPLACE INTO Endorsements (DomainId, Revision, KeyId, Signature) VALUES (?, ?, ?, ?);`
+)
+
+// migrations create the Endorsements table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE Endorsements;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns an endorsement.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (endorsement.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "endorsement", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create endorsement table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Write(ctx context.Context, domainID string, revision int64, endorsements []*pb.MapRootEndorsement) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, e := range endorsements {
+		sig, err := proto.Marshal(e.GetSignature())
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, writeSQL, domainID, revision, e.GetKeyId(), sig); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *storage) Read(ctx context.Context, domainID string, revision int64) ([]*pb.MapRootEndorsement, error) {
+	rows, err := s.db.QueryContext(ctx, readSQL, domainID, revision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []*pb.MapRootEndorsement
+	for rows.Next() {
+		var keyID, data []byte
+		if err := rows.Scan(&keyID, &data); err != nil {
+			return nil, err
+		}
+		sig := &sigpb.DigitallySigned{}
+		if err := proto.Unmarshal(data, sig); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &pb.MapRootEndorsement{KeyId: keyID, Signature: sig})
+	}
+	return ret, rows.Err()
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endorsement
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/trillian/crypto/sigpb"
+
+	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEndorsements(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	a := &pb.MapRootEndorsement{KeyId: []byte("a"), Signature: &sigpb.DigitallySigned{Signature: []byte("siga")}}
+	b := &pb.MapRootEndorsement{KeyId: []byte("b"), Signature: &sigpb.DigitallySigned{Signature: []byte("sigb")}}
+	if err := s.Write(ctx, "domain", 1, []*pb.MapRootEndorsement{b, a}); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc     string
+		domainID string
+		revision int64
+		want     []*pb.MapRootEndorsement
+	}{
+		{desc: "written", domainID: "domain", revision: 1, want: []*pb.MapRootEndorsement{a, b}},
+		{desc: "other revision", domainID: "domain", revision: 2},
+		{desc: "other domain", domainID: "domain2", revision: 1},
+	} {
+		got, err := s.Read(ctx, tc.domainID, tc.revision)
+		if err != nil {
+			t.Fatalf("%v: Read(): %v", tc.desc, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%v: Read(): %v endorsements, want %v", tc.desc, len(got), len(tc.want))
+			continue
+		}
+		for i := range got {
+			if !proto.Equal(got[i], tc.want[i]) {
+				t.Errorf("%v: Read()[%v]: %v, want %v", tc.desc, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
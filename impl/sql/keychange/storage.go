@@ -0,0 +1,98 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keychange implements the keychange.Storage interface.
+package keychange
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/keytransparency/core/keychange"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS KeyChangeWebhooks(
+  DomainId              VARCHAR(40) NOT NULL,
+  AppId                 VARCHAR(255) NOT NULL,
+  UserId                VARCHAR(255) NOT NULL,
+  MapIndex              VARBINARY(32) NOT NULL,
+  Url                   VARCHAR(2048) NOT NULL,
+  LastEpoch             BIGINT NOT NULL,
+  PRIMARY KEY(DomainId, AppId, UserId)
+);`
+	writeSQL = `
+REPLACE INTO KeyChangeWebhooks (DomainId, AppId, UserId, MapIndex, Url, LastEpoch)
+VALUES (?, ?, ?, ?, ?, ?);`
+	deleteSQL = `DELETE FROM KeyChangeWebhooks WHERE DomainId = ? AND AppId = ? AND UserId = ?;`
+	listSQL   = `
+SELECT AppId, UserId, MapIndex, Url, LastEpoch
+FROM KeyChangeWebhooks WHERE DomainId = ? ORDER BY AppId, UserId ASC;`
+	setLastEpochSQL = `
+UPDATE KeyChangeWebhooks SET LastEpoch = ?
+WHERE DomainId = ? AND AppId = ? AND UserId = ?;`
+)
+
+// migrations create the KeyChangeWebhooks table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE KeyChangeWebhooks;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns a keychange.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (keychange.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "keychange", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create key change webhooks table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Write(ctx context.Context, w *keychange.Webhook) error {
+	_, err := s.db.ExecContext(ctx, writeSQL, w.DomainID, w.AppID, w.UserID, w.Index, w.URL, w.LastEpoch)
+	return err
+}
+
+func (s *storage) Delete(ctx context.Context, domainID, appID, userID string) error {
+	_, err := s.db.ExecContext(ctx, deleteSQL, domainID, appID, userID)
+	return err
+}
+
+func (s *storage) List(ctx context.Context, domainID string) ([]*keychange.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, listSQL, domainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hooks []*keychange.Webhook
+	for rows.Next() {
+		w := &keychange.Webhook{DomainID: domainID}
+		if err := rows.Scan(&w.AppID, &w.UserID, &w.Index, &w.URL, &w.LastEpoch); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+func (s *storage) SetLastEpoch(ctx context.Context, domainID, appID, userID string, epoch int64) error {
+	_, err := s.db.ExecContext(ctx, setLastEpochSQL, epoch, domainID, appID, userID)
+	return err
+}
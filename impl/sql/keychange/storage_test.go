@@ -0,0 +1,80 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keychange
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/google/keytransparency/core/keychange"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	alice := &keychange.Webhook{DomainID: "domain", AppID: "app", UserID: "alice",
+		Index: []byte("index1"), URL: "https://example.com/alice", LastEpoch: 3}
+	bob := &keychange.Webhook{DomainID: "domain", AppID: "app", UserID: "bob",
+		Index: []byte("index2"), URL: "https://example.com/bob", LastEpoch: 4}
+	other := &keychange.Webhook{DomainID: "domain2", AppID: "app", UserID: "alice",
+		Index: []byte("index3"), URL: "https://example.com/other"}
+	for _, w := range []*keychange.Webhook{alice, bob, other} {
+		if err := s.Write(ctx, w); err != nil {
+			t.Fatalf("Write(%v): %v", w.UserID, err)
+		}
+	}
+	// Writing a webhook again replaces it.
+	moved := *alice
+	moved.URL = "https://example.com/alice2"
+	if err := s.Write(ctx, &moved); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if err := s.SetLastEpoch(ctx, "domain", "app", "bob", 7); err != nil {
+		t.Fatalf("SetLastEpoch(): %v", err)
+	}
+	delivered := *bob
+	delivered.LastEpoch = 7
+	if err := s.Delete(ctx, "domain2", "app", "alice"); err != nil {
+		t.Fatalf("Delete(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		domainID string
+		want     []*keychange.Webhook
+	}{
+		{domainID: "domain", want: []*keychange.Webhook{&moved, &delivered}},
+		{domainID: "domain2", want: nil},
+	} {
+		got, err := s.List(ctx, tc.domainID)
+		if err != nil {
+			t.Fatalf("List(%v): %v", tc.domainID, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("List(%v): %v, want %v", tc.domainID, got, tc.want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/google/keytransparency/core/storage"
+	"github.com/google/keytransparency/impl/sql/migrate"
 
 	"github.com/golang/protobuf/proto"
 
@@ -41,6 +42,11 @@ PRIMARY KEY(InstanceID,DomainID,AppID)
 	setSQL = `INSERT INTO KeySets (InstanceID, DomainID, AppID, KeySet) VALUES (?, ?, ?, ?);`
 )
 
+// migrations create the KeySets table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{schema}, Down: []string{`DROP TABLE KeySets;`}},
+}
+
 // Storage stores keysets, backed by an SQL database.
 type Storage struct {
 	db *sql.DB
@@ -79,9 +85,8 @@ func (k *keyset) Proto() (*tpb.KeySet, error) {
 // New returns a storage.KeySets client backed by an SQL table.
 func New(db *sql.DB) (storage.KeySets, error) {
 	s := &Storage{db: db}
-	// Create schema.
-	if _, err := s.db.Exec(schema); err != nil {
-		return nil, fmt.Errorf("failed to create keyset table: %v", err)
+	if err := migrate.Apply(context.Background(), s.db, "keysets", migrations); err != nil {
+		return nil, fmt.Errorf("failed to create keyset table: %w", err)
 	}
 	return s, db.Ping()
 }
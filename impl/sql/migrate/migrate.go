@@ -0,0 +1,207 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate applies versioned schema migrations to SQL databases.
+//
+// Each store names a component and lists the migrations of its tables in
+// order. Stores call Apply when they are created, which upgrades the schema
+// of the component to the latest version. The current version of every
+// component is recorded in the SchemaVersions table, and a row in the
+// SchemaLocks table stops several processes from migrating the same component
+// at once.
+//
+// The first migrations of existing stores use CREATE TABLE IF NOT EXISTS so
+// that databases created before schemas were versioned are adopted as is.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	createVersionsSQL = `
+CREATE TABLE IF NOT EXISTS SchemaVersions(
+  Component             VARCHAR(40) NOT NULL,
+  Version               INTEGER NOT NULL,
+  PRIMARY KEY(Component)
+);`
+	createLocksSQL = `
+CREATE TABLE IF NOT EXISTS SchemaLocks(
+  Component             VARCHAR(40) NOT NULL,
+  ExpiryNanos           BIGINT NOT NULL,
+  PRIMARY KEY(Component)
+);`
+	readVersionSQL  = `SELECT Version FROM SchemaVersions WHERE Component = ?;`
+	writeVersionSQL = `REPLACE INTO SchemaVersions (Component, Version) VALUES (?, ?);`
+	lockSQL         = `INSERT INTO SchemaLocks (Component, ExpiryNanos) VALUES (?, ?);`
+	breakLockSQL    = `DELETE FROM SchemaLocks WHERE Component = ? AND ExpiryNanos < ?;`
+	unlockSQL       = `DELETE FROM SchemaLocks WHERE Component = ?;`
+)
+
+// lockRetry is how often a locked component is polled.
+const lockRetry = 100 * time.Millisecond
+
+var (
+	// LockTimeout is how long Apply and To wait for another process to
+	// finish migrating a component.
+	LockTimeout = time.Minute
+	// LockExpiry is how long a lock is held before other processes assume
+	// its holder died and take it over.
+	LockExpiry = 10 * time.Minute
+
+	// ErrLocked occurs when a component stays locked for LockTimeout.
+	ErrLocked = errors.New("migrate: schema is locked by another process")
+)
+
+// Migration is a versioned change to the schema of a component.
+type Migration struct {
+	// Version is the schema version after Up is applied. The migrations of
+	// a component are numbered consecutively from 1.
+	Version int
+	// Up upgrades the schema from Version-1 to Version.
+	Up []string
+	// Down reverts Up.
+	Down []string
+}
+
+// Apply upgrades the schema of component to the latest version in
+// migrations.
+func Apply(ctx context.Context, db *sql.DB, component string, migrations []Migration) error {
+	return To(ctx, db, component, migrations, len(migrations))
+}
+
+// To upgrades or downgrades the schema of component to version. Version 0
+// removes every table created by migrations.
+func To(ctx context.Context, db *sql.DB, component string, migrations []Migration, version int) error {
+	if err := check(migrations); err != nil {
+		return fmt.Errorf("migrate: %v: %w", component, err)
+	}
+	if version < 0 || version > len(migrations) {
+		return fmt.Errorf("migrate: %v: version %v out of range [0, %v]", component, version, len(migrations))
+	}
+	for _, stmt := range []string{createVersionsSQL, createLocksSQL} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: failed to create schema tables: %w", err)
+		}
+	}
+	if err := lock(ctx, db, component); err != nil {
+		return err
+	}
+	defer unlock(db, component)
+
+	current, err := Version(ctx, db, component)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("migrate: %v: schema version %v is newer than this binary supports (%v)",
+			component, current, len(migrations))
+	}
+	for current < version {
+		m := migrations[current]
+		if err := step(ctx, db, component, m.Up, m.Version); err != nil {
+			return fmt.Errorf("migrate: %v: upgrade to version %v: %w", component, m.Version, err)
+		}
+		glog.Infof("migrate: upgraded %v to schema version %v", component, m.Version)
+		current++
+	}
+	for current > version {
+		m := migrations[current-1]
+		if err := step(ctx, db, component, m.Down, m.Version-1); err != nil {
+			return fmt.Errorf("migrate: %v: downgrade from version %v: %w", component, m.Version, err)
+		}
+		glog.Infof("migrate: downgraded %v to schema version %v", component, m.Version-1)
+		current--
+	}
+	return nil
+}
+
+// Version returns the current schema version of component, or 0 if no
+// migrations have been applied.
+func Version(ctx context.Context, db *sql.DB, component string) (int, error) {
+	var version int
+	switch err := db.QueryRowContext(ctx, readVersionSQL, component).Scan(&version); {
+	case err == sql.ErrNoRows:
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	return version, nil
+}
+
+// check returns an error if migrations are not numbered consecutively from 1.
+func check(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return fmt.Errorf("migration %v has version %v, want %v", i, m.Version, i+1)
+		}
+	}
+	return nil
+}
+
+// step runs stmts and records version in a single transaction. Databases that
+// commit schema changes implicitly, such as MySQL, may apply part of a failed
+// step; migrations should therefore be written to be safely re-run.
+func step(ctx context.Context, db *sql.DB, component string, stmts []string, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, writeVersionSQL, component, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lock waits up to LockTimeout to insert the lock row of component. Locks
+// older than LockExpiry are broken.
+func lock(ctx context.Context, db *sql.DB, component string) error {
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		now := time.Now()
+		if _, err := db.ExecContext(ctx, breakLockSQL, component, now.UnixNano()); err != nil {
+			return err
+		}
+		// Inserting fails if another process holds the lock.
+		if _, err := db.ExecContext(ctx, lockSQL, component, now.Add(LockExpiry).UnixNano()); err == nil {
+			return nil
+		}
+		if now.After(deadline) {
+			return ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+func unlock(db *sql.DB, component string) {
+	if _, err := db.Exec(unlockSQL, component); err != nil {
+		glog.Errorf("migrate: failed to unlock %v: %v", component, err)
+	}
+}
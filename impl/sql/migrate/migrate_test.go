@@ -0,0 +1,158 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testMigrations = []Migration{
+	{
+		Version: 1,
+		Up:      []string{`CREATE TABLE IF NOT EXISTS Things(Id INTEGER NOT NULL, PRIMARY KEY(Id));`},
+		Down:    []string{`DROP TABLE Things;`},
+	},
+	{
+		Version: 2,
+		Up:      []string{`CREATE TABLE Widgets(Id INTEGER NOT NULL, PRIMARY KEY(Id));`},
+		Down:    []string{`DROP TABLE Widgets;`},
+	},
+}
+
+func newDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	return db
+}
+
+// tableExists returns true if table can be queried.
+func tableExists(db *sql.DB, table string) bool {
+	_, err := db.Exec("SELECT COUNT(*) FROM " + table)
+	return err == nil
+}
+
+func TestTo(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	defer db.Close()
+	// Tables created before versioning are adopted by version 1.
+	if _, err := db.Exec(testMigrations[0].Up[0]); err != nil {
+		t.Fatalf("Exec(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		version     int
+		wantThings  bool
+		wantWidgets bool
+	}{
+		{version: 2, wantThings: true, wantWidgets: true},
+		{version: 2, wantThings: true, wantWidgets: true}, // Idempotent.
+		{version: 1, wantThings: true, wantWidgets: false},
+		{version: 0, wantThings: false, wantWidgets: false},
+		{version: 2, wantThings: true, wantWidgets: true},
+	} {
+		if err := To(ctx, db, "test", testMigrations, tc.version); err != nil {
+			t.Fatalf("To(%v): %v", tc.version, err)
+		}
+		if got, err := Version(ctx, db, "test"); err != nil || got != tc.version {
+			t.Errorf("Version(): %v, %v, want %v", got, err, tc.version)
+		}
+		if got := tableExists(db, "Things"); got != tc.wantThings {
+			t.Errorf("To(%v): Things exists: %v, want %v", tc.version, got, tc.wantThings)
+		}
+		if got := tableExists(db, "Widgets"); got != tc.wantWidgets {
+			t.Errorf("To(%v): Widgets exists: %v, want %v", tc.version, got, tc.wantWidgets)
+		}
+	}
+	if got, err := Version(ctx, db, "other"); err != nil || got != 0 {
+		t.Errorf("Version(other): %v, %v, want 0", got, err)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	defer db.Close()
+	if err := Apply(ctx, db, "test", testMigrations); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+
+	for _, tc := range []struct {
+		desc       string
+		migrations []Migration
+		version    int
+	}{
+		{desc: "gap", migrations: []Migration{{Version: 1}, {Version: 3}}, version: 2},
+		{desc: "out of range", migrations: testMigrations, version: 3},
+		{desc: "newer schema", migrations: testMigrations[:1], version: 1},
+		{desc: "failed step", migrations: append(testMigrations[:2:2], Migration{
+			Version: 3,
+			Up:      []string{`NOT SQL;`},
+		}), version: 3},
+	} {
+		if err := To(ctx, db, "test", tc.migrations, tc.version); err == nil {
+			t.Errorf("%v: To(): nil, want error", tc.desc)
+		}
+	}
+	// A failed step leaves the schema at the last good version.
+	if got, err := Version(ctx, db, "test"); err != nil || got != 2 {
+		t.Errorf("Version(): %v, %v, want 2", got, err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	defer db.Close()
+	defer func(timeout, expiry time.Duration) {
+		LockTimeout, LockExpiry = timeout, expiry
+	}(LockTimeout, LockExpiry)
+	LockTimeout = 0
+
+	if err := Apply(ctx, db, "test", testMigrations[:1]); err != nil {
+		t.Fatalf("Apply(): %v", err)
+	}
+	// Another process holds the lock.
+	if _, err := db.Exec(lockSQL, "test", time.Now().Add(time.Hour).UnixNano()); err != nil {
+		t.Fatalf("Exec(): %v", err)
+	}
+	if err := Apply(ctx, db, "test", testMigrations); err != ErrLocked {
+		t.Errorf("Apply(): %v, want %v", err, ErrLocked)
+	}
+	// Other components are not affected.
+	if err := Apply(ctx, db, "other", nil); err != nil {
+		t.Errorf("Apply(other): %v", err)
+	}
+	// The holder died and its lock expired.
+	if _, err := db.Exec(unlockSQL, "test"); err != nil {
+		t.Fatalf("Exec(): %v", err)
+	}
+	if _, err := db.Exec(lockSQL, "test", time.Now().Add(-time.Second).UnixNano()); err != nil {
+		t.Fatalf("Exec(): %v", err)
+	}
+	if err := Apply(ctx, db, "test", testMigrations); err != nil {
+		t.Errorf("Apply() with expired lock: %v", err)
+	}
+	if got, err := Version(ctx, db, "test"); err != nil || got != 2 {
+		t.Errorf("Version(): %v, %v, want 2", got, err)
+	}
+}
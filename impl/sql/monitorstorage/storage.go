@@ -0,0 +1,152 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitorstorage implements the monitorstorage.Interface interface.
+package monitorstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/monitorstorage"
+	"github.com/google/keytransparency/impl/sql/migrate"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
+)
+
+const (
+	createResultsSQL = `
+CREATE TABLE IF NOT EXISTS MonitorResults(
+  DomainId              VARCHAR(40) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  Result                MEDIUMBLOB NOT NULL,
+  PRIMARY KEY(DomainId, Revision)
+);`
+	createStateSQL = `
+CREATE TABLE IF NOT EXISTS MonitorState(
+  DomainId              VARCHAR(40) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  LogRoot               BLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	insertResultSQL = `INSERT INTO MonitorResults (DomainId, Revision, Result) VALUES (?, ?, ?);`
+	countResultSQL  = `SELECT COUNT(*) FROM MonitorResults WHERE DomainId = ? AND Revision = ?;`
+	readResultSQL   = `SELECT Result FROM MonitorResults WHERE DomainId = ? AND Revision = ?;`
+	latestSQL       = `SELECT MAX(Revision) FROM MonitorResults WHERE DomainId = ?;`
+	writeStateSQL   = `REPLACE INTO MonitorState (DomainId, Revision, LogRoot) VALUES (?, ?, ?);`
+	readStateSQL    = `SELECT Revision, LogRoot FROM MonitorState WHERE DomainId = ?;`
+)
+
+// migrations create the MonitorResults and MonitorState tables.
+var migrations = []migrate.Migration{
+	{
+		Version: 1,
+		Up:      []string{createResultsSQL, createStateSQL},
+		Down:    []string{`DROP TABLE MonitorState;`, `DROP TABLE MonitorResults;`},
+	},
+}
+
+type storage struct {
+	db       *sql.DB
+	domainID string
+}
+
+// NewStorage returns a monitorstorage.Interface that keeps the results and
+// progress of the monitor of domainID in SQL tables, so that they survive
+// restarts of the monitor.
+func NewStorage(db *sql.DB, domainID string) (monitorstorage.Interface, error) {
+	s := &storage{db: db, domainID: domainID}
+	if err := migrate.Apply(context.Background(), s.db, "monitorstorage", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create monitor tables: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Set(epoch int64, r *pb.VerificationResult) error {
+	ctx := context.Background()
+	b, err := proto.Marshal(r)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, countResultSQL, s.domainID, epoch).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return monitorstorage.ErrAlreadyStored
+	}
+	if _, err := tx.ExecContext(ctx, insertResultSQL, s.domainID, epoch, b); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *storage) Get(epoch int64) (*pb.VerificationResult, error) {
+	var b []byte
+	switch err := s.db.QueryRow(readResultSQL, s.domainID, epoch).Scan(&b); {
+	case err == sql.ErrNoRows:
+		return nil, monitorstorage.ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+	r := &pb.VerificationResult{}
+	if err := proto.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *storage) LatestEpoch() int64 {
+	var latest sql.NullInt64
+	if err := s.db.QueryRow(latestSQL, s.domainID).Scan(&latest); err != nil {
+		glog.Errorf("Failed to read the latest monitored epoch: %v", err)
+		return 0
+	}
+	return latest.Int64
+}
+
+func (s *storage) SetState(state *monitorstorage.State) error {
+	b, err := proto.Marshal(state.Trusted)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(writeStateSQL, s.domainID, state.Revision, b)
+	return err
+}
+
+func (s *storage) State() (*monitorstorage.State, error) {
+	var revision int64
+	var b []byte
+	switch err := s.db.QueryRow(readStateSQL, s.domainID).Scan(&revision, &b); {
+	case err == sql.ErrNoRows:
+		return nil, monitorstorage.ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+	trusted := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(b, trusted); err != nil {
+		return nil, err
+	}
+	return &monitorstorage.State{Revision: revision, Trusted: trusted}, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitorstorage
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/monitorstorage"
+	"github.com/google/trillian"
+
+	pb "github.com/google/keytransparency/core/api/monitor/v1/monitor_proto"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newStorage(t *testing.T, db *sql.DB, domainID string) monitorstorage.Interface {
+	t.Helper()
+	s, err := NewStorage(db, domainID)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	return s
+}
+
+func TestResults(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s := newStorage(t, db, "domain")
+	other := newStorage(t, db, "other")
+
+	if got := s.LatestEpoch(); got != 0 {
+		t.Errorf("LatestEpoch(): %v, want 0", got)
+	}
+	if _, err := s.Get(1); err != monitorstorage.ErrNotFound {
+		t.Errorf("Get(1): %v, want ErrNotFound", err)
+	}
+	for _, epoch := range []int64{1, 2} {
+		if err := s.Set(epoch, &pb.VerificationResult{Revision: epoch}); err != nil {
+			t.Fatalf("Set(%v): %v", epoch, err)
+		}
+	}
+	if err := s.Set(2, &pb.VerificationResult{Revision: 2}); err != monitorstorage.ErrAlreadyStored {
+		t.Errorf("Set(2) again: %v, want ErrAlreadyStored", err)
+	}
+	got, err := s.Get(2)
+	if err != nil {
+		t.Fatalf("Get(2): %v", err)
+	}
+	if want := (&pb.VerificationResult{Revision: 2}); !proto.Equal(got, want) {
+		t.Errorf("Get(2): %v, want %v", got, want)
+	}
+	if got := s.LatestEpoch(); got != 2 {
+		t.Errorf("LatestEpoch(): %v, want 2", got)
+	}
+	if got := other.LatestEpoch(); got != 0 {
+		t.Errorf("LatestEpoch(other): %v, want 0", got)
+	}
+}
+
+func TestState(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s := newStorage(t, db, "domain")
+
+	if _, err := s.State(); err != monitorstorage.ErrNotFound {
+		t.Errorf("State(): %v, want ErrNotFound", err)
+	}
+	for _, revision := range []int64{3, 4} {
+		want := &monitorstorage.State{
+			Revision: revision,
+			Trusted:  &trillian.SignedLogRoot{TreeSize: revision + 1},
+		}
+		if err := s.SetState(want); err != nil {
+			t.Fatalf("SetState(%v): %v", revision, err)
+		}
+		// A new storage reads the state back, as after a restart.
+		got, err := newStorage(t, db, "domain").State()
+		if err != nil {
+			t.Fatalf("State(): %v", err)
+		}
+		if got.Revision != want.Revision || !proto.Equal(got.Trusted, want.Trusted) {
+			t.Errorf("State(): %+v, want %+v", got, want)
+		}
+	}
+	if _, err := newStorage(t, db, "other").State(); err != monitorstorage.ErrNotFound {
+		t.Errorf("State(other): %v, want ErrNotFound", err)
+	}
+}
@@ -23,6 +23,8 @@ import (
 
 	"github.com/golang/protobuf/proto"
 
+	"github.com/google/keytransparency/impl/sql/migrate"
+
 	pb "github.com/google/keytransparency/core/api/v1/keytransparency_proto"
 )
 
@@ -44,23 +46,27 @@ const (
 	deleteQueueExpr = `
 	DELETE FROM Queue
 	WHERE DomainID = ? AND Time = ?;`
+	pendingQueueExpr = `
+	SELECT COUNT(*), MIN(Time) FROM Queue
+	WHERE DomainID = ?;`
 )
 
 var (
-	createStmt = []string{
-		`CREATE TABLE IF NOT EXISTS Mutations (
+	// migrations create the Mutations table, then the Queue table.
+	migrations = []migrate.Migration{
+		{Version: 1, Up: []string{`CREATE TABLE IF NOT EXISTS Mutations (
 		DomainID VARCHAR(30)   NOT NULL,
 		Revision BIGINT        NOT NULL,
 		Sequence INTEGER       NOT NULL,
 		Mutation BLOB          NOT NULL,
 		PRIMARY KEY(DomainID, Revision, Sequence)
-	);`,
-		`CREATE TABLE IF NOT EXISTS Queue (
+	);`}, Down: []string{`DROP TABLE Mutations;`}},
+		{Version: 2, Up: []string{`CREATE TABLE IF NOT EXISTS Queue (
 		DomainID VARCHAR(30)   NOT NULL,
 		Time     BIGINT        NOT NULL,
 		Mutation BLOB          NOT NULL,
 		PRIMARY KEY(DomainID, Time)
-	);`,
+	);`}, Down: []string{`DROP TABLE Queue;`}},
 	}
 )
 
@@ -84,11 +90,8 @@ func New(db *sql.DB) (*Mutations, error) {
 
 // createTables creates new database tables.
 func (m *Mutations) createTables() error {
-	for _, stmt := range createStmt {
-		_, err := m.db.Exec(stmt)
-		if err != nil {
-			return fmt.Errorf("Failed to create mutation tables: %v", err)
-		}
+	if err := migrate.Apply(context.Background(), m.db, "mutations", migrations); err != nil {
+		return fmt.Errorf("Failed to create mutation tables: %w", err)
 	}
 	return nil
 }
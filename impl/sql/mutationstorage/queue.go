@@ -51,11 +51,14 @@ func (m *Mutations) NewReceiver(ctx context.Context, last time.Time, domainID st
 		domainID:    domainID,
 		opts:        rOpts,
 		more:        make(chan time.Time, 1),
-		ticker:      time.NewTicker(rOpts.Period),
-		maxTicker:   time.NewTicker(rOpts.MaxPeriod),
 		done:        make(chan interface{}),
 		recieveFunc: recieveFunc,
 	}
+	if rOpts.Period == 0 {
+		return r // Only send batches on Flush.
+	}
+	r.ticker = time.NewTicker(rOpts.Period)
+	r.maxTicker = time.NewTicker(rOpts.MaxPeriod)
 
 	go r.run(ctx, last)
 	r.running.Add(1)
@@ -73,6 +76,9 @@ type Receiver struct {
 	maxTicker   *time.Ticker
 	done        chan interface{}
 	running     sync.WaitGroup
+	// mu serializes batches so that Flush and the periodic batches do not
+	// send the same queue items twice.
+	mu sync.Mutex
 }
 
 // Close stops the receiver and returns only when all callbacks are complete.
@@ -82,8 +88,9 @@ func (r *Receiver) Close() {
 }
 
 // Flush sends any waiting queue items.
-func (r *Receiver) Flush(ctx context.Context) {
-	r.sendBatch(ctx, true)
+func (r *Receiver) Flush(ctx context.Context) error {
+	_, err := r.sendBatch(ctx, true)
+	return err
 }
 
 func (r *Receiver) run(ctx context.Context, last time.Time) {
@@ -94,18 +101,20 @@ func (r *Receiver) run(ctx context.Context, last time.Time) {
 	}
 
 	if time.Since(last) > (r.opts.MaxPeriod - r.opts.Period) {
-		r.sendBatch(ctx, true) // We will be overdue for an epoch soon.
+		// We will be overdue for an epoch soon.
+		r.sendBatch(ctx, true) // nolint: errcheck
 	}
 
 	for {
+		// Errors are logged by sendBatch and retried on the next tick.
 		var count int32
 		select {
 		case <-r.more:
-			count = r.sendBatch(ctx, false)
+			count, _ = r.sendBatch(ctx, false)
 		case <-r.ticker.C:
-			count = r.sendBatch(ctx, false)
+			count, _ = r.sendBatch(ctx, false)
 		case <-r.maxTicker.C:
-			count = r.sendBatch(ctx, true)
+			count, _ = r.sendBatch(ctx, true)
 		case <-ctx.Done():
 			return
 		case <-r.done:
@@ -119,19 +128,21 @@ func (r *Receiver) run(ctx context.Context, last time.Time) {
 }
 
 // sendBatch sends up to batchSize items to the receiver. Returns the number of sent items.
-func (r *Receiver) sendBatch(ctx context.Context, sendEmpty bool) int32 {
+func (r *Receiver) sendBatch(ctx context.Context, sendEmpty bool) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	ms, err := r.store.readQueue(ctx, r.domainID, r.opts.MaxBatchSize)
 	if err != nil {
 		glog.Errorf("readQueue(): %v", err)
-		return 0
+		return 0, err
 	}
 	if len(ms) == 0 && !sendEmpty {
-		return 0
+		return 0, nil
 	}
 
 	if err := r.recieveFunc(ms); err != nil {
 		glog.Infof("queue.SendBatch failed: %v", err)
-		return 0
+		return 0, err
 	}
 	// TODO(gbelvin): Do we need finer grained errors?
 	// We could put an ack'ed field in a QueueMessage object.
@@ -142,7 +153,7 @@ func (r *Receiver) sendBatch(ctx context.Context, sendEmpty bool) int32 {
 		glog.Errorf("deleteQueueMessages(%v, len(ms): %v): %v", r.domainID, len(ms), err)
 	}
 
-	return int32(len(ms))
+	return int32(len(ms)), nil
 }
 
 // readQueue reads all mutations that are still in the queue up to batchSize.
@@ -201,3 +212,17 @@ func (m *Mutations) deleteMessages(ctx context.Context, domainID string, mutatio
 	}
 	return retErr
 }
+
+// PendingMutations returns the number of mutations waiting in the queue for
+// domainID and the time the oldest of them was sent.
+func (m *Mutations) PendingMutations(ctx context.Context, domainID string) (int64, time.Time, error) {
+	var count int64
+	var oldest sql.NullInt64
+	if err := m.db.QueryRowContext(ctx, pendingQueueExpr, domainID).Scan(&count, &oldest); err != nil {
+		return 0, time.Time{}, err
+	}
+	if !oldest.Valid {
+		return 0, time.Time{}, nil
+	}
+	return count, time.Unix(0, oldest.Int64), nil
+}
@@ -188,3 +188,65 @@ func TestQueueSendBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestReceiverFlushOnly(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(newDB(t))
+	if err != nil {
+		t.Fatalf("Failed to create mutations: %v", err)
+	}
+	if err := fillQueue(ctx, m); err != nil {
+		t.Fatalf("Failed to write updates: %v", err)
+	}
+
+	var batches []int
+	r := m.NewReceiver(ctx, time.Unix(0, 0), domainID, func(msgs []*mutator.QueueMessage) error {
+		batches = append(batches, len(msgs))
+		return nil
+	}, mutator.ReceiverOptions{MaxBatchSize: 3})
+	defer r.Close()
+
+	// An overdue receiver would normally send a batch as soon as it starts.
+	if len(batches) != 0 {
+		t.Fatalf("receiveFunc called before Flush: %v", batches)
+	}
+	for i, want := range []int{3, 2, 0} {
+		if err := r.Flush(ctx); err != nil {
+			t.Fatalf("Flush(): %v", err)
+		}
+		if got := len(batches); got != i+1 {
+			t.Fatalf("receiveFunc called %v times after %v flushes", got, i+1)
+		}
+		if got := batches[i]; got != want {
+			t.Errorf("Flush(): batch of %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPendingMutations(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(newDB(t))
+	if err != nil {
+		t.Fatalf("Failed to create mutations: %v", err)
+	}
+	if n, _, err := m.PendingMutations(ctx, domainID); err != nil || n != 0 {
+		t.Fatalf("PendingMutations(): %v, %v, want 0, nil", n, err)
+	}
+	start := time.Now()
+	if err := fillQueue(ctx, m); err != nil {
+		t.Fatalf("Failed to write updates: %v", err)
+	}
+	n, oldest, err := m.PendingMutations(ctx, domainID)
+	if err != nil {
+		t.Fatalf("PendingMutations(): %v", err)
+	}
+	if n != 5 {
+		t.Errorf("PendingMutations(): %v, want 5", n)
+	}
+	if oldest.Before(start) || oldest.After(time.Now()) {
+		t.Errorf("PendingMutations(): oldest %v, want between %v and now", oldest, start)
+	}
+	if n, _, err := m.PendingMutations(ctx, "otherdomain"); err != nil || n != 0 {
+		t.Errorf("PendingMutations(otherdomain): %v, %v, want 0, nil", n, err)
+	}
+}
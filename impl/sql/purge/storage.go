@@ -0,0 +1,95 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purge implements the purge.Storage interface.
+package purge
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/keytransparency/core/purge"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS PurgedEntries(
+  DomainId              VARCHAR(40) NOT NULL,
+  Idx                   VARBINARY(32) NOT NULL,
+  Revision              BIGINT NOT NULL,
+  PRIMARY KEY(DomainId, Idx)
+);`
+	readSQL  = `SELECT Revision FROM PurgedEntries WHERE DomainId = ? AND Idx = ?;`
+	writeSQL = `REPLACE INTO PurgedEntries (DomainId, Idx, Revision) VALUES (?, ?, ?);`
+)
+
+// migrations create the PurgedEntries table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE PurgedEntries;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns a purge.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (purge.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "purge", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create purge table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Purge(ctx context.Context, domainID string, index []byte, revision int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := purgedThrough(ctx, tx, domainID, index)
+	if err != nil {
+		return err
+	}
+	if revision <= current {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, writeSQL, domainID, index, revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *storage) PurgedThrough(ctx context.Context, domainID string, index []byte) (int64, error) {
+	return purgedThrough(ctx, s.db, domainID, index)
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func purgedThrough(ctx context.Context, q queryer, domainID string, index []byte) (int64, error) {
+	var revision int64
+	switch err := q.QueryRowContext(ctx, readSQL, domainID, index).Scan(&revision); {
+	case err == sql.ErrNoRows:
+		return -1, nil
+	case err != nil:
+		return 0, err
+	}
+	return revision, nil
+}
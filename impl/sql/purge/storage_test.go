@@ -0,0 +1,66 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purge
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc     string
+		domainID string
+		index    []byte
+		purge    int64 // Revision to purge through. -1 to skip.
+		want     int64
+	}{
+		{desc: "not purged", domainID: "domain", index: []byte("index1"), purge: -1, want: -1},
+		{desc: "purge", domainID: "domain", index: []byte("index1"), purge: 5, want: 5},
+		{desc: "advance", domainID: "domain", index: []byte("index1"), purge: 7, want: 7},
+		{desc: "never decreases", domainID: "domain", index: []byte("index1"), purge: 3, want: 7},
+		{desc: "other index", domainID: "domain", index: []byte("index2"), purge: -1, want: -1},
+		{desc: "other domain", domainID: "domain2", index: []byte("index1"), purge: -1, want: -1},
+		{desc: "revision zero", domainID: "domain2", index: []byte("index1"), purge: 0, want: 0},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.purge >= 0 {
+				if err := s.Purge(ctx, tc.domainID, tc.index, tc.purge); err != nil {
+					t.Fatalf("Purge(): %v", err)
+				}
+			}
+			got, err := s.PurgedThrough(ctx, tc.domainID, tc.index)
+			if err != nil {
+				t.Fatalf("PurgedThrough(): %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("PurgedThrough(): %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quota implements the quota.Storage interface.
+package quota
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/keytransparency/core/quota"
+	"github.com/google/keytransparency/impl/sql/migrate"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS AcceptedMutations(
+  DomainId              VARCHAR(40) NOT NULL,
+  UserId                VARCHAR(255) NOT NULL,
+  TimeNanos             BIGINT NOT NULL,
+  Revision              BIGINT NOT NULL,
+  PRIMARY KEY(DomainId, UserId, TimeNanos)
+);`
+	writeSQL = `REPLACE INTO AcceptedMutations (DomainId, UserId, TimeNanos, Revision) VALUES (?, ?, ?, ?);`
+	pruneSQL = `DELETE FROM AcceptedMutations
+WHERE DomainId = ? AND UserId = ? AND TimeNanos < ? AND Revision < ?;`
+	countRevisionSQL = `SELECT COUNT(*) FROM AcceptedMutations WHERE DomainId = ? AND Revision = ?;`
+	countUserSQL     = `SELECT COUNT(*), MIN(TimeNanos) FROM AcceptedMutations
+WHERE DomainId = ? AND UserId = ? AND TimeNanos >= ?;`
+)
+
+// migrations create the AcceptedMutations table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE AcceptedMutations;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns a quota.Storage client backed by an SQL table.
+func NewStorage(db *sql.DB) (quota.Storage, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "quota", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create accepted mutations table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) Record(ctx context.Context, domainID, userID string, revision int64, ts time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	horizon := ts.Add(-quota.UserWindow).UnixNano()
+	if _, err := tx.ExecContext(ctx, pruneSQL, domainID, userID, horizon, revision); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, writeSQL, domainID, userID, ts.UnixNano(), revision); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *storage) CountRevision(ctx context.Context, domainID string, revision int64) (int64, error) {
+	var count int64
+	if err := s.db.QueryRowContext(ctx, countRevisionSQL, domainID, revision).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *storage) CountUser(ctx context.Context, domainID, userID string, since time.Time) (int64, time.Time, error) {
+	var count int64
+	var oldest sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, countUserSQL, domainID, userID, since.UnixNano()).Scan(&count, &oldest); err != nil {
+		return 0, time.Time{}, err
+	}
+	if !oldest.Valid {
+		return 0, time.Time{}, nil
+	}
+	return count, time.Unix(0, oldest.Int64), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCount(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	t0 := time.Unix(1000000, 0)
+	for _, r := range []struct {
+		domainID, userID string
+		revision         int64
+		ts               time.Time
+	}{
+		{"domain", "alice", 1, t0},
+		{"domain", "alice", 1, t0.Add(time.Hour)},
+		{"domain", "bob", 1, t0.Add(2 * time.Hour)},
+		{"domain", "alice", 2, t0.Add(3 * time.Hour)},
+		{"domain2", "alice", 1, t0.Add(4 * time.Hour)},
+	} {
+		if err := s.Record(ctx, r.domainID, r.userID, r.revision, r.ts); err != nil {
+			t.Fatalf("Record(): %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		domainID string
+		revision int64
+		want     int64
+	}{
+		{domainID: "domain", revision: 1, want: 3},
+		{domainID: "domain", revision: 2, want: 1},
+		{domainID: "domain", revision: 3, want: 0},
+		{domainID: "domain2", revision: 1, want: 1},
+	} {
+		got, err := s.CountRevision(ctx, tc.domainID, tc.revision)
+		if err != nil {
+			t.Fatalf("CountRevision(): %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("CountRevision(%v, %v): %v, want %v", tc.domainID, tc.revision, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		userID     string
+		since      time.Time
+		want       int64
+		wantOldest time.Time
+	}{
+		{userID: "alice", since: t0, want: 3, wantOldest: t0},
+		{userID: "alice", since: t0.Add(time.Minute), want: 2, wantOldest: t0.Add(time.Hour)},
+		{userID: "bob", since: t0, want: 1, wantOldest: t0.Add(2 * time.Hour)},
+		{userID: "carol", since: t0, want: 0},
+	} {
+		got, oldest, err := s.CountUser(ctx, "domain", tc.userID, tc.since)
+		if err != nil {
+			t.Fatalf("CountUser(): %v", err)
+		}
+		if got != tc.want || !oldest.Equal(tc.wantOldest) {
+			t.Errorf("CountUser(%v, %v): %v, %v, want %v, %v", tc.userID, tc.since, got, oldest, tc.want, tc.wantOldest)
+		}
+	}
+
+	// Records older than the window are pruned once a later revision is
+	// recorded.
+	if err := s.Record(ctx, "domain", "alice", 3, t0.Add(25*time.Hour)); err != nil {
+		t.Fatalf("Record(): %v", err)
+	}
+	if got, _, err := s.CountUser(ctx, "domain", "alice", t0); err != nil || got != 3 {
+		t.Errorf("CountUser() after prune: %v, %v, want 3, nil", got, err)
+	}
+}
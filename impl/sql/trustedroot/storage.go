@@ -0,0 +1,111 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trustedroot implements the grpcc.TrustedRootStore interface.
+package trustedroot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/keytransparency/core/client/grpcc"
+	"github.com/google/keytransparency/impl/sql/migrate"
+	"github.com/google/trillian"
+)
+
+const (
+	createSQL = `
+CREATE TABLE IF NOT EXISTS TrustedLogRoots(
+  DomainId              VARCHAR(40) NOT NULL,
+  TreeSize              BIGINT NOT NULL,
+  LogRoot               BLOB NOT NULL,
+  PRIMARY KEY(DomainId)
+);`
+	readSQL  = `SELECT TreeSize, LogRoot FROM TrustedLogRoots WHERE DomainId = ?;`
+	writeSQL = `REPLACE INTO TrustedLogRoots (DomainId, TreeSize, LogRoot) VALUES (?, ?, ?);`
+)
+
+// migrations create the TrustedLogRoots table.
+var migrations = []migrate.Migration{
+	{Version: 1, Up: []string{createSQL}, Down: []string{`DROP TABLE TrustedLogRoots;`}},
+}
+
+type storage struct {
+	db *sql.DB
+}
+
+// NewStorage returns a grpcc.TrustedRootStore backed by an SQL table.
+// Clients that share the table never move a trusted root back to a smaller
+// tree.
+func NewStorage(db *sql.DB) (grpcc.TrustedRootStore, error) {
+	s := &storage{db: db}
+	if err := migrate.Apply(context.Background(), s.db, "trustedroot", migrations); err != nil {
+		return nil, fmt.Errorf("Failed to create trusted root table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *storage) LoadRoot(ctx context.Context, domainID string) (*trillian.SignedLogRoot, error) {
+	_, root, err := read(ctx, s.db, domainID)
+	return root, err
+}
+
+func (s *storage) SaveRoot(ctx context.Context, domainID string, root *trillian.SignedLogRoot) error {
+	b, err := proto.Marshal(root)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, _, err := read(ctx, tx, domainID)
+	if err != nil {
+		return err
+	}
+	if root.GetTreeSize() <= current {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, writeSQL, domainID, root.GetTreeSize(), b); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// read returns the size and the trusted log root of domainID, or -1 and nil
+// if none has been saved.
+func read(ctx context.Context, q queryer, domainID string) (int64, *trillian.SignedLogRoot, error) {
+	var treeSize int64
+	var b []byte
+	switch err := q.QueryRowContext(ctx, readSQL, domainID).Scan(&treeSize, &b); {
+	case err == sql.ErrNoRows:
+		return -1, nil, nil
+	case err != nil:
+		return 0, nil, err
+	}
+	root := &trillian.SignedLogRoot{}
+	if err := proto.Unmarshal(b, root); err != nil {
+		return 0, nil, err
+	}
+	return treeSize, root, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustedroot
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/trillian"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open(): %v", err)
+	}
+	defer db.Close()
+	s, err := NewStorage(db)
+	if err != nil {
+		t.Fatalf("NewStorage(): %v", err)
+	}
+	if root, err := s.LoadRoot(ctx, "domain"); err != nil || root != nil {
+		t.Errorf("LoadRoot(unsaved): %v, %v, want nil", root, err)
+	}
+	for _, tc := range []struct {
+		save int64
+		want int64
+	}{
+		{save: 5, want: 5},
+		{save: 9, want: 9},
+		{save: 7, want: 9}, // Trusted roots never move back.
+	} {
+		if err := s.SaveRoot(ctx, "domain", &trillian.SignedLogRoot{TreeSize: tc.save}); err != nil {
+			t.Fatalf("SaveRoot(%v): %v", tc.save, err)
+		}
+		root, err := s.LoadRoot(ctx, "domain")
+		if err != nil {
+			t.Fatalf("LoadRoot(): %v", err)
+		}
+		if got := root.GetTreeSize(); got != tc.want {
+			t.Errorf("SaveRoot(%v): LoadRoot() has size %v, want %v", tc.save, got, tc.want)
+		}
+	}
+	if root, err := s.LoadRoot(ctx, "other"); err != nil || root != nil {
+		t.Errorf("LoadRoot(other): %v, %v, want nil", root, err)
+	}
+}